@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// CommandTool is a Claude permission prompt tool: it proposes the shell
+// commands the session's agent is about to run and waits for a human, or
+// the session's policy, to approve them. Other tools are allowed.
+type CommandTool struct {
+	baseURL   string
+	sessionID string
+	client    *http.Client
+	pollWait  time.Duration
+}
+
+func NewCommandTool() *CommandTool {
+	baseURL := os.Getenv("ORBITMESH_API_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:8080"
+	}
+	return &CommandTool{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		sessionID: os.Getenv("ORBITMESH_SESSION_ID"),
+		client:    &http.Client{Timeout: humanPollWait + 15*time.Second},
+		pollWait:  humanPollWait,
+	}
+}
+
+func registerCommandTools(server *mcp.Server, tool *CommandTool) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "approve_command",
+		Description: "Decide whether a tool call may run. Pass it to claude as --permission-prompt-tool; shell commands wait for approval in OrbitMesh",
+	}, tool.approve)
+}
+
+type ApproveCommandArgs struct {
+	ToolName  string         `json:"tool_name" jsonschema:"description=The tool the agent wants to call,required"`
+	Input     map[string]any `json:"input" jsonschema:"description=The tool call's input"`
+	ToolUseID string         `json:"tool_use_id,omitempty" jsonschema:"description=The tool call's ID"`
+}
+
+// permissionDecision is the result format Claude expects from a permission
+// prompt tool.
+type permissionDecision struct {
+	Behavior     string         `json:"behavior"`
+	UpdatedInput map[string]any `json:"updatedInput,omitempty"`
+	Message      string         `json:"message,omitempty"`
+}
+
+func (c *CommandTool) approve(ctx context.Context, req *mcp.CallToolRequest, args ApproveCommandArgs) (*mcp.CallToolResult, any, error) {
+	command, ok := domain.ToolCommand(args.ToolName, args.Input)
+	if !ok {
+		return commandResult(permissionDecision{Behavior: "allow", UpdatedInput: args.Input})
+	}
+
+	var cmd apiTypes.CommandResponse
+	if err := c.request(ctx, http.MethodPost, "", apiTypes.ProposeCommandRequest{Command: command, ToolCallID: args.ToolUseID}, &cmd); err != nil {
+		return commandResult(permissionDecision{Behavior: "deny", Message: fmt.Sprintf("approve_command failed: %v", err)})
+	}
+	for cmd.Status == apiTypes.CommandStatusProposed {
+		wait := "?wait=" + url.QueryEscape(c.pollWait.String())
+		if err := c.request(ctx, http.MethodGet, "/"+url.PathEscape(cmd.ID)+wait, nil, &cmd); err != nil {
+			return commandResult(permissionDecision{Behavior: "deny", Message: fmt.Sprintf("approve_command failed: %v", err)})
+		}
+	}
+	if cmd.Status == apiTypes.CommandStatusRejected {
+		message := "The command was rejected."
+		if cmd.Reason != "" {
+			message = "The command was rejected: " + cmd.Reason
+		}
+		return commandResult(permissionDecision{Behavior: "deny", Message: message})
+	}
+	return commandResult(permissionDecision{Behavior: "allow", UpdatedInput: args.Input})
+}
+
+// request calls the session's commands endpoint with suffix appended and
+// decodes the response into out.
+func (c *CommandTool) request(ctx context.Context, method, suffix string, payload, out any) error {
+	if c.sessionID == "" {
+		return fmt.Errorf("missing ORBITMESH_SESSION_ID")
+	}
+	endpoint := fmt.Sprintf("%s/api/sessions/%s/commands%s", c.baseURL, url.PathEscape(c.sessionID), suffix)
+
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("X-Orbitmesh-Internal", "command-mcp")
+	setAPIToken(httpReq)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiTypes.ErrorResponse
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("command request failed: %s", strings.TrimSpace(string(respBody)))
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func commandResult(decision permissionDecision) (*mcp.CallToolResult, any, error) {
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(data)},
+		},
+	}, nil, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// ExchangeTool reads and writes the session's exchange area, a key-value
+// store for handing structured results out of the session.
+type ExchangeTool struct {
+	baseURL   string
+	sessionID string
+	client    *http.Client
+}
+
+func NewExchangeTool() *ExchangeTool {
+	baseURL := os.Getenv("ORBITMESH_API_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:8080"
+	}
+	return &ExchangeTool{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		sessionID: os.Getenv("ORBITMESH_SESSION_ID"),
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func registerExchangeTools(server *mcp.Server, tool *ExchangeTool) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "exchange_list",
+		Description: "List all values in this session's exchange area",
+	}, tool.list)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "exchange_get",
+		Description: "Get one value from this session's exchange area",
+	}, tool.get)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "exchange_set",
+		Description: "Store a JSON value (e.g. a structured report) in this session's exchange area for humans and other tools to read",
+	}, tool.set)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "exchange_delete",
+		Description: "Delete a value from this session's exchange area",
+	}, tool.delete)
+}
+
+type ExchangeKeyArgs struct {
+	Key string `json:"key" jsonschema:"description=Entry key (letters digits . _ -),required"`
+}
+
+type ExchangeSetArgs struct {
+	Key   string `json:"key" jsonschema:"description=Entry key (letters digits . _ -),required"`
+	Value any    `json:"value" jsonschema:"description=Any JSON value,required"`
+}
+
+func (x *ExchangeTool) list(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+	body, err := x.request(ctx, http.MethodGet, "", nil)
+	return exchangeResult(body, err)
+}
+
+func (x *ExchangeTool) get(ctx context.Context, req *mcp.CallToolRequest, args ExchangeKeyArgs) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Key) == "" {
+		return exchangeResult(nil, fmt.Errorf("key is required"))
+	}
+	body, err := x.request(ctx, http.MethodGet, args.Key, nil)
+	return exchangeResult(body, err)
+}
+
+func (x *ExchangeTool) set(ctx context.Context, req *mcp.CallToolRequest, args ExchangeSetArgs) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Key) == "" {
+		return exchangeResult(nil, fmt.Errorf("key is required"))
+	}
+	value, err := json.Marshal(args.Value)
+	if err != nil {
+		return exchangeResult(nil, err)
+	}
+	body, err := x.request(ctx, http.MethodPut, args.Key, apiTypes.ExchangeSetRequest{Value: value})
+	return exchangeResult(body, err)
+}
+
+func (x *ExchangeTool) delete(ctx context.Context, req *mcp.CallToolRequest, args ExchangeKeyArgs) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Key) == "" {
+		return exchangeResult(nil, fmt.Errorf("key is required"))
+	}
+	_, err := x.request(ctx, http.MethodDelete, args.Key, nil)
+	if err == nil {
+		return exchangeResult([]byte(fmt.Sprintf("Deleted %s.", args.Key)), nil)
+	}
+	return exchangeResult(nil, err)
+}
+
+// request calls the session's exchange endpoint, or one entry's endpoint
+// when key is set, and returns the response body.
+func (x *ExchangeTool) request(ctx context.Context, method, key string, payload any) ([]byte, error) {
+	if x.sessionID == "" {
+		return nil, fmt.Errorf("missing ORBITMESH_SESSION_ID")
+	}
+	endpoint := fmt.Sprintf("%s/api/sessions/%s/exchange", x.baseURL, url.PathEscape(x.sessionID))
+	if key != "" {
+		endpoint += "/" + url.PathEscape(key)
+	}
+
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("X-Orbitmesh-Internal", "exchange-mcp")
+	setAPIToken(httpReq)
+
+	resp, err := x.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiTypes.ErrorResponse
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("%s", apiErr.Error)
+		}
+		return nil, fmt.Errorf("exchange request failed: %s", strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+func exchangeResult(body []byte, err error) (*mcp.CallToolResult, any, error) {
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Exchange request failed: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.TrimSpace(string(body))},
+		},
+	}, nil, nil
+}
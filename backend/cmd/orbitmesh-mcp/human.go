@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// humanPollWait is how long each poll for an answer is held open by the
+// server. The tool keeps polling until the question is answered or
+// cancelled.
+const humanPollWait = 25 * time.Second
+
+// HumanTool lets the session's agent ask a human a question and wait for
+// the answer.
+type HumanTool struct {
+	baseURL   string
+	sessionID string
+	client    *http.Client
+	pollWait  time.Duration
+}
+
+func NewHumanTool() *HumanTool {
+	baseURL := os.Getenv("ORBITMESH_API_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:8080"
+	}
+	return &HumanTool{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		sessionID: os.Getenv("ORBITMESH_SESSION_ID"),
+		client:    &http.Client{Timeout: humanPollWait + 15*time.Second},
+		pollWait:  humanPollWait,
+	}
+}
+
+func registerHumanTools(server *mcp.Server, tool *HumanTool) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "ask_human",
+		Description: "Ask a human a question and wait for their answer. Use it when you are blocked on a decision only a human can make; pass options to limit the answer to a fixed set of choices",
+	}, tool.ask)
+}
+
+type AskHumanArgs struct {
+	Question string   `json:"question" jsonschema:"description=The question to ask,required"`
+	Options  []string `json:"options,omitempty" jsonschema:"description=Allowed answers; leave empty for a free-form answer"`
+}
+
+func (h *HumanTool) ask(ctx context.Context, req *mcp.CallToolRequest, args AskHumanArgs) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Question) == "" {
+		return humanResult("", fmt.Errorf("question is required"))
+	}
+
+	var q apiTypes.QuestionResponse
+	if err := h.request(ctx, http.MethodPost, "", apiTypes.AskQuestionRequest{Question: args.Question, Options: args.Options}, &q); err != nil {
+		return humanResult("", err)
+	}
+	for q.Status == apiTypes.QuestionStatusPending {
+		wait := "?wait=" + url.QueryEscape(h.pollWait.String())
+		if err := h.request(ctx, http.MethodGet, "/"+url.PathEscape(q.ID)+wait, nil, &q); err != nil {
+			return humanResult("", err)
+		}
+	}
+	if q.Status != apiTypes.QuestionStatusAnswered {
+		return humanResult("", fmt.Errorf("the question was %s before it was answered", q.Status))
+	}
+	return humanResult(q.Answer, nil)
+}
+
+// request calls the session's questions endpoint with suffix appended and
+// decodes the response into out.
+func (h *HumanTool) request(ctx context.Context, method, suffix string, payload, out any) error {
+	if h.sessionID == "" {
+		return fmt.Errorf("missing ORBITMESH_SESSION_ID")
+	}
+	endpoint := fmt.Sprintf("%s/api/sessions/%s/questions%s", h.baseURL, url.PathEscape(h.sessionID), suffix)
+
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpReq.Header.Set("X-Orbitmesh-Internal", "human-mcp")
+	setAPIToken(httpReq)
+
+	resp, err := h.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiTypes.ErrorResponse
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("question request failed: %s", strings.TrimSpace(string(respBody)))
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+func humanResult(answer string, err error) (*mcp.CallToolResult, any, error) {
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("ask_human failed: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: answer},
+		},
+	}, nil, nil
+}
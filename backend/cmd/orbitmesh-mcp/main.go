@@ -25,11 +25,23 @@ const (
 
 type StrandTool struct {
 	projectDir string
+	// baseURL, sessionID and client reach the session's task change
+	// journal; see task_journal.go.
+	baseURL   string
+	sessionID string
+	client    *http.Client
 }
 
 func NewStrandTool() *StrandTool {
+	baseURL := os.Getenv("ORBITMESH_API_BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://127.0.0.1:8080"
+	}
 	return &StrandTool{
 		projectDir: os.Getenv("STRAND_PROJECT_DIR"),
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		sessionID:  os.Getenv("ORBITMESH_SESSION_ID"),
+		client:     &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
@@ -45,6 +57,14 @@ func (s *StrandTool) execStrand(args ...string) (string, error) {
 	return string(output), nil
 }
 
+// setAPIToken authenticates req with ORBITMESH_API_TOKEN, the API token
+// OrbitMesh hands a run's MCP servers through their env, if it is set.
+func setAPIToken(req *http.Request) {
+	if token := strings.TrimSpace(os.Getenv("ORBITMESH_API_TOKEN")); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 func main() {
 	if err := run(); err != nil {
 		log.Fatal(err)
@@ -71,6 +91,12 @@ func run() error {
 	case "dock":
 		dockTool := NewDockTool()
 		registerDockTools(server, dockTool)
+	case "exchange":
+		registerExchangeTools(server, NewExchangeTool())
+	case "human":
+		registerHumanTools(server, NewHumanTool())
+	case "commands":
+		registerCommandTools(server, NewCommandTool())
 	default:
 		tool := NewStrandTool()
 		registerStrandTools(server, tool)
@@ -119,6 +145,18 @@ func registerStrandTools(server *mcp.Server, tool *StrandTool) {
 		Name:        "claim_task",
 		Description: "Claim a task by marking it in progress",
 	}, tool.claimTask)
+
+	// Register edit_task tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "edit_task",
+		Description: "Change a task's title, priority, role or status",
+	}, tool.editTask)
+
+	// Register undo_last_change tool
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "undo_last_change",
+		Description: "Revert the latest task change (add, claim, complete or edit) made through these tools in this session; call again to undo further back",
+	}, tool.undoLastChange)
 }
 
 func registerDockTools(server *mcp.Server, tool *DockTool) {
@@ -134,7 +172,7 @@ func registerDockTools(server *mcp.Server, tool *DockTool) {
 
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "multi_edit_ui",
-		Description: "Edit multiple MCP input components on the live page",
+		Description: "Edit multiple MCP input components on the live page, all or nothing where possible, with per-field results",
 	}, tool.multiEdit)
 }
 
@@ -172,6 +210,14 @@ type ClaimTaskArgs struct {
 	TaskID string `json:"task_id" jsonschema:"description=The task ID to claim,required"`
 }
 
+type EditTaskArgs struct {
+	TaskID   string `json:"task_id" jsonschema:"description=The task ID to edit,required"`
+	Title    string `json:"title,omitempty" jsonschema:"description=New task title"`
+	Priority string `json:"priority,omitempty" jsonschema:"description=New priority: high medium or low,enum=high,enum=medium,enum=low"`
+	Role     string `json:"role,omitempty" jsonschema:"description=New role responsible for the task"`
+	Status   string `json:"status,omitempty" jsonschema:"description=New status (e.g. open in_progress blocked)"`
+}
+
 func (s *StrandTool) listTasks(ctx context.Context, req *mcp.CallToolRequest, args ListTasksArgs) (*mcp.CallToolResult, any, error) {
 	cmdArgs := []string{"list", "--format", "json"}
 
@@ -247,6 +293,15 @@ func (s *StrandTool) nextTask(ctx context.Context, req *mcp.CallToolRequest, arg
 		}, nil, nil
 	}
 
+	if taskID := extractTaskID(output); args.Claim && taskID != "" {
+		output += s.recordChange(ctx, apiTypes.TaskChangeRequest{
+			TaskID:  taskID,
+			Op:      "claim",
+			Summary: "Claimed as the next task",
+			After:   s.showTask(taskID),
+		})
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: output},
@@ -264,6 +319,7 @@ func (s *StrandTool) completeTask(ctx context.Context, req *mcp.CallToolRequest,
 		}, nil, nil
 	}
 
+	before := s.showTask(args.TaskID)
 	cmdArgs := []string{"complete", args.TaskID}
 
 	if args.Todo != nil {
@@ -300,9 +356,21 @@ func (s *StrandTool) completeTask(ctx context.Context, req *mcp.CallToolRequest,
 		}, nil, nil
 	}
 
+	change := apiTypes.TaskChangeRequest{
+		TaskID:  args.TaskID,
+		Op:      "complete",
+		Summary: args.Report,
+		Before:  before,
+		After:   s.showTask(args.TaskID),
+	}
+	if args.Todo != nil {
+		change.Todo = *args.Todo
+	}
+	note := s.recordChange(ctx, change)
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Task %s completed successfully.\n%s", args.TaskID, output)},
+			&mcp.TextContent{Text: fmt.Sprintf("Task %s completed successfully.\n%s%s", args.TaskID, output, note)},
 		},
 	}, nil, nil
 }
@@ -371,6 +439,12 @@ func (s *StrandTool) addTask(ctx context.Context, req *mcp.CallToolRequest, args
 	resultMsg := fmt.Sprintf("Task created successfully.\n%s", output)
 	if taskID != "" {
 		resultMsg = fmt.Sprintf("Task %s created successfully.\n%s", taskID, output)
+		resultMsg += s.recordChange(ctx, apiTypes.TaskChangeRequest{
+			TaskID:  taskID,
+			Op:      "add",
+			Summary: args.Title,
+			After:   s.showTask(taskID),
+		})
 	}
 
 	return &mcp.CallToolResult{
@@ -390,6 +464,7 @@ func (s *StrandTool) claimTask(ctx context.Context, req *mcp.CallToolRequest, ar
 		}, nil, nil
 	}
 
+	before := s.showTask(args.TaskID)
 	output, err := s.execStrand("claim", args.TaskID)
 	if err != nil {
 		return &mcp.CallToolResult{
@@ -400,9 +475,74 @@ func (s *StrandTool) claimTask(ctx context.Context, req *mcp.CallToolRequest, ar
 		}, nil, nil
 	}
 
+	note := s.recordChange(ctx, apiTypes.TaskChangeRequest{
+		TaskID: args.TaskID,
+		Op:     "claim",
+		Before: before,
+		After:  s.showTask(args.TaskID),
+	})
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Task %s claimed successfully.\n%s%s", args.TaskID, output, note)},
+		},
+	}, nil, nil
+}
+
+func (s *StrandTool) editTask(ctx context.Context, req *mcp.CallToolRequest, args EditTaskArgs) (*mcp.CallToolResult, any, error) {
+	if args.TaskID == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "task_id is required"},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	cmdArgs := []string{"edit", args.TaskID}
+	var changed []string
+	for _, f := range []struct{ name, value string }{
+		{"title", args.Title},
+		{"priority", args.Priority},
+		{"role", args.Role},
+		{"status", args.Status},
+	} {
+		if f.value != "" {
+			cmdArgs = append(cmdArgs, "--"+f.name, f.value)
+			changed = append(changed, f.name+": "+f.value)
+		}
+	}
+	if len(changed) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "at least one of title, priority, role or status is required"},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	before := s.showTask(args.TaskID)
+	output, err := s.execStrand(cmdArgs...)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error editing task %s: %v\nOutput: %s", args.TaskID, err, output)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	note := s.recordChange(ctx, apiTypes.TaskChangeRequest{
+		TaskID:  args.TaskID,
+		Op:      "edit",
+		Summary: strings.Join(changed, ", "),
+		Before:  before,
+		After:   s.showTask(args.TaskID),
+	})
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Task %s claimed successfully.\n%s", args.TaskID, output)},
+			&mcp.TextContent{Text: fmt.Sprintf("Task %s edited successfully.\n%s%s", args.TaskID, output, note)},
 		},
 	}, nil, nil
 }
@@ -487,7 +627,13 @@ type DispatchUIArgs struct {
 }
 
 type MultiEditArgs struct {
-	Fields any `json:"fields" jsonschema:"description=Field map or list for multi-edit,required"`
+	Edits []FieldEditArgs `json:"edits" jsonschema:"description=Edits to apply in order; all or none apply where the page can roll back,required"`
+}
+
+type FieldEditArgs struct {
+	ComponentID string  `json:"component_id" jsonschema:"description=Target MCP input component ID,required"`
+	Value       string  `json:"value" jsonschema:"description=New value for the component,required"`
+	Expected    *string `json:"expected,omitempty" jsonschema:"description=Optional: value the component must currently hold for the edits to apply"`
 }
 
 func (d *DockTool) listComponents(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
@@ -514,11 +660,33 @@ func (d *DockTool) dispatchAction(ctx context.Context, req *mcp.CallToolRequest,
 }
 
 func (d *DockTool) multiEdit(ctx context.Context, req *mcp.CallToolRequest, args MultiEditArgs) (*mcp.CallToolResult, any, error) {
+	if len(args.Edits) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: "edits is required"},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+	payload := apiTypes.DockMultiEditPayload{Edits: make([]apiTypes.DockFieldEdit, len(args.Edits))}
+	for i, edit := range args.Edits {
+		payload.Edits[i] = apiTypes.DockFieldEdit(edit)
+	}
 	resp, err := d.request(ctx, apiTypes.DockMCPRequest{
 		Kind:    "multi_edit",
-		Payload: args.Fields,
+		Payload: payload,
 	})
-	return dockResult(resp, err)
+	result, callResult, callErr := dockResult(resp, err)
+	if result.IsError {
+		return result, callResult, callErr
+	}
+
+	// Report a failed edit as a tool error, keeping the per-field outcomes.
+	var outcome apiTypes.DockMultiEditResult
+	if data, err := json.Marshal(resp.Result); err == nil && json.Unmarshal(data, &outcome) == nil && !outcome.OK {
+		result.IsError = true
+	}
+	return result, callResult, callErr
 }
 
 func (d *DockTool) request(ctx context.Context, request apiTypes.DockMCPRequest) (apiTypes.DockMCPResponse, error) {
@@ -538,6 +706,7 @@ func (d *DockTool) request(ctx context.Context, request apiTypes.DockMCPRequest)
 	}
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("X-Orbitmesh-Internal", "dock-mcp")
+	setAPIToken(httpReq)
 
 	resp, err := d.client.Do(httpReq)
 	if err != nil {
@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
 )
 
 func TestNewStrandTool(t *testing.T) {
@@ -271,3 +277,142 @@ func TestClaimTaskValidation(t *testing.T) {
 		t.Error("expected error result for missing task_id")
 	}
 }
+
+func TestEditTaskValidation(t *testing.T) {
+	tool := NewStrandTool()
+	ctx := context.Background()
+
+	for name, args := range map[string]EditTaskArgs{
+		"missing task_id": {Title: "New title"},
+		"nothing to edit": {TaskID: "T1a2b3c"},
+	} {
+		result, _, err := tool.editTask(ctx, &mcp.CallToolRequest{}, args)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if result == nil || !result.IsError {
+			t.Errorf("%s: expected error result", name)
+		}
+	}
+}
+
+func TestExchangeSet(t *testing.T) {
+	var gotPath, gotInternal, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.Method + " " + r.URL.Path
+		gotInternal = r.Header.Get("X-Orbitmesh-Internal")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		_, _ = w.Write([]byte(`{"value":{"ok":true},"updated_by":"agent"}`))
+	}))
+	defer srv.Close()
+
+	tool := &ExchangeTool{baseURL: srv.URL, sessionID: "s1", client: srv.Client()}
+	result, _, err := tool.set(context.Background(), nil, ExchangeSetArgs{Key: "report", Value: map[string]any{"ok": true}})
+	if err != nil || result.IsError {
+		t.Fatalf("set failed: %v %+v", err, result)
+	}
+	if gotPath != "PUT /api/sessions/s1/exchange/report" || gotInternal != "exchange-mcp" {
+		t.Fatalf("request = %s (internal %q)", gotPath, gotInternal)
+	}
+	if gotBody != `{"value":{"ok":true}}` {
+		t.Fatalf("body = %s", gotBody)
+	}
+
+	tool.sessionID = ""
+	if result, _, _ := tool.list(context.Background(), nil, struct{}{}); !result.IsError {
+		t.Fatal("expected an error without ORBITMESH_SESSION_ID")
+	}
+}
+
+func TestAskHumanPollsUntilAnswered(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.Header.Get("X-Orbitmesh-Internal") != "human-mcp" {
+			t.Errorf("missing internal header on %s", r.URL)
+		}
+		switch {
+		case r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"id":"q1","status":"pending"}`))
+		case len(requests) < 4:
+			_, _ = w.Write([]byte(`{"id":"q1","status":"pending"}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"q1","status":"answered","answer":"yes"}`))
+		}
+	}))
+	defer srv.Close()
+
+	tool := &HumanTool{baseURL: srv.URL, sessionID: "s1", client: srv.Client(), pollWait: time.Second}
+	result, _, err := tool.ask(context.Background(), nil, AskHumanArgs{Question: "Ship it?", Options: []string{"yes", "no"}})
+	if err != nil || result.IsError {
+		t.Fatalf("ask failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "yes" {
+		t.Fatalf("answer = %q", text)
+	}
+	if len(requests) != 4 || requests[0] != "POST /api/sessions/s1/questions" || requests[1] != "GET /api/sessions/s1/questions/q1?wait=1s" {
+		t.Fatalf("requests = %v", requests)
+	}
+}
+
+func TestAskHumanCancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"id":"q1","status":"pending"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"q1","status":"cancelled"}`))
+	}))
+	defer srv.Close()
+
+	tool := &HumanTool{baseURL: srv.URL, sessionID: "s1", client: srv.Client(), pollWait: time.Second}
+	if result, _, _ := tool.ask(context.Background(), nil, AskHumanArgs{Question: "Ship it?"}); !result.IsError {
+		t.Fatal("expected an error for a cancelled question")
+	}
+}
+
+func TestStrandToolJournalsAndUndoes(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		if r.Header.Get("X-Orbitmesh-Internal") != "tasks-mcp" {
+			t.Errorf("missing internal header on %s", r.URL)
+		}
+		if r.URL.Path == "/api/sessions/s1/task-changes/undo" {
+			_, _ = w.Write([]byte(`{"id":"c1","task_id":"T1a2b3c","op":"claim","at":"2026-01-01T00:00:00Z"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	tool := &StrandTool{projectDir: "/proj", baseURL: srv.URL, sessionID: "s1", client: srv.Client()}
+	if note := tool.recordChange(context.Background(), apiTypes.TaskChangeRequest{TaskID: "T1a2b3c", Op: "claim"}); note != "" {
+		t.Fatalf("recordChange: %s", note)
+	}
+	result, _, err := tool.undoLastChange(context.Background(), nil, UndoLastChangeArgs{})
+	if err != nil || result.IsError {
+		t.Fatalf("undo failed: %v %+v", err, result)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "Undid claim of task T1a2b3c." {
+		t.Fatalf("result = %q", text)
+	}
+	want := []string{
+		`POST /api/sessions/s1/task-changes {"task_id":"T1a2b3c","op":"claim","project_dir":"/proj"}`,
+		`POST /api/sessions/s1/task-changes/undo {}`,
+	}
+	if len(requests) != 2 || requests[0] != want[0] || requests[1] != want[1] {
+		t.Fatalf("requests = %v", requests)
+	}
+
+	tool.sessionID = ""
+	if note := tool.recordChange(context.Background(), apiTypes.TaskChangeRequest{TaskID: "T1a2b3c", Op: "claim"}); note != "" || len(requests) != 2 {
+		t.Fatal("nothing should be journaled without ORBITMESH_SESSION_ID")
+	}
+	if result, _, _ := tool.undoLastChange(context.Background(), nil, UndoLastChangeArgs{}); !result.IsError {
+		t.Fatal("expected an error without ORBITMESH_SESSION_ID")
+	}
+}
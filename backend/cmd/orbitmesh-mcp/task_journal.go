@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// The strand tools journal the task changes they make in OrbitMesh, so
+// undo_last_change can revert them. Without ORBITMESH_SESSION_ID nothing is
+// journaled.
+
+type UndoLastChangeArgs struct{}
+
+// showTask returns the task as `strand show --format json` prints it for
+// the journal, or "" if it can't be read or nothing is journaled.
+func (s *StrandTool) showTask(taskID string) string {
+	if s.sessionID == "" {
+		return ""
+	}
+	output, err := s.execStrand("show", "--format", "json", taskID)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// recordChange journals a task change and returns a note for the tool
+// result if that failed.
+func (s *StrandTool) recordChange(ctx context.Context, change apiTypes.TaskChangeRequest) string {
+	if s.sessionID == "" {
+		return ""
+	}
+	change.ProjectDir = s.projectDir
+	if s.projectDir == "" {
+		change.WorkingDir, _ = os.Getwd()
+	}
+	if err := s.journalRequest(ctx, "", change, nil); err != nil {
+		return fmt.Sprintf("\nNote: the change was not journaled and cannot be undone: %v", err)
+	}
+	return ""
+}
+
+func (s *StrandTool) undoLastChange(ctx context.Context, req *mcp.CallToolRequest, _ UndoLastChangeArgs) (*mcp.CallToolResult, any, error) {
+	var change apiTypes.TaskChange
+	if err := s.journalRequest(ctx, "/undo", struct{}{}, &change); err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error undoing the last change: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	text := fmt.Sprintf("Undid %s of task %s.", change.Op, change.TaskID)
+	if change.Summary != "" {
+		text += "\n" + change.Summary
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, nil, nil
+}
+
+// journalRequest posts payload to the session's task change journal, at
+// path below it, decoding the response into out when set.
+func (s *StrandTool) journalRequest(ctx context.Context, path string, payload, out any) error {
+	if s.sessionID == "" {
+		return fmt.Errorf("missing ORBITMESH_SESSION_ID")
+	}
+	endpoint := fmt.Sprintf("%s/api/sessions/%s/task-changes%s", s.baseURL, url.PathEscape(s.sessionID), path)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Orbitmesh-Internal", "tasks-mcp")
+	setAPIToken(httpReq)
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr apiTypes.ErrorResponse
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			if apiErr.Details != nil && apiErr.Details != "" {
+				return fmt.Errorf("%s: %v", apiErr.Error, apiErr.Details)
+			}
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("task journal request failed: %s", strings.TrimSpace(string(respBody)))
+	}
+	if out != nil {
+		return json.Unmarshal(respBody, out)
+	}
+	return nil
+}
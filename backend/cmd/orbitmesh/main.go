@@ -2,23 +2,37 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 
 	"github.com/ricochet1k/orbitmesh/internal/api"
+	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/provider"
 	"github.com/ricochet1k/orbitmesh/internal/provider/common/acp"
 	"github.com/ricochet1k/orbitmesh/internal/provider/common/claude"
 	"github.com/ricochet1k/orbitmesh/internal/provider/common/claudews"
+	"github.com/ricochet1k/orbitmesh/internal/provider/common/openai"
+	"github.com/ricochet1k/orbitmesh/internal/provider/demo"
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
 	"github.com/ricochet1k/orbitmesh/internal/provider/native"
 	"github.com/ricochet1k/orbitmesh/internal/provider/pty"
 	"github.com/ricochet1k/orbitmesh/internal/service"
@@ -28,7 +42,7 @@ import (
 
 const (
 	defaultPort     = "8080"
-	shutdownTimeout = 5 * time.Second
+	shutdownTimeout = 30 * time.Second // covers every shutdown phase at its default
 )
 
 func listenAddr() string {
@@ -38,35 +52,558 @@ func listenAddr() string {
 	return ":" + defaultPort
 }
 
+// cleanupPolicyFromEnv reads the stale-session janitor settings:
+// ORBITMESH_CLEANUP_MAX_IDLE_DAYS, ORBITMESH_CLEANUP_ACTION (archive|delete)
+// and ORBITMESH_CLEANUP_INTERVAL (a Go duration, default 1h when enabled).
+func cleanupPolicyFromEnv() service.CleanupPolicy {
+	policy := service.CleanupPolicy{Action: service.CleanupActionArchive}
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_CLEANUP_MAX_IDLE_DAYS")); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 {
+			log.Fatalf("invalid ORBITMESH_CLEANUP_MAX_IDLE_DAYS %q", raw)
+		}
+		policy.MaxIdle = time.Duration(days) * 24 * time.Hour
+	}
+	switch action := strings.TrimSpace(os.Getenv("ORBITMESH_CLEANUP_ACTION")); action {
+	case "", string(service.CleanupActionArchive):
+	case string(service.CleanupActionDelete):
+		policy.Action = service.CleanupActionDelete
+	default:
+		log.Fatalf("invalid ORBITMESH_CLEANUP_ACTION %q (want archive or delete)", action)
+	}
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_CLEANUP_INTERVAL")); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatalf("invalid ORBITMESH_CLEANUP_INTERVAL %q: %v", raw, err)
+		}
+		policy.Interval = interval
+	} else if policy.MaxIdle > 0 {
+		policy.Interval = time.Hour
+	}
+	return policy
+}
+
+// envBool reports whether the named variable is set to a true value.
+func envBool(name string) bool {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return false
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Fatalf("invalid %s %q", name, raw)
+	}
+	return v
+}
+
+// recoveryPolicyFromEnv reads the default startup recovery policy from
+// ORBITMESH_RECOVERY_POLICY.
+func recoveryPolicyFromEnv() service.RecoveryPolicy {
+	policy, err := service.ParseRecoveryPolicy(os.Getenv("ORBITMESH_RECOVERY_POLICY"))
+	if err != nil {
+		log.Fatalf("invalid ORBITMESH_RECOVERY_POLICY: %v", err)
+	}
+	return policy
+}
+
+// recoveryPoliciesFromEnv reads per-provider-type recovery policies from
+// ORBITMESH_RECOVERY_POLICIES, e.g. "claude=auto_resume,acp=auto_retry".
+func recoveryPoliciesFromEnv() map[string]service.RecoveryPolicy {
+	raw := strings.TrimSpace(os.Getenv("ORBITMESH_RECOVERY_POLICIES"))
+	if raw == "" {
+		return nil
+	}
+	policies := make(map[string]service.RecoveryPolicy)
+	for _, entry := range strings.Split(raw, ",") {
+		providerType, name, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		policy, err := service.ParseRecoveryPolicy(name)
+		if !ok || strings.TrimSpace(providerType) == "" || err != nil {
+			log.Fatalf("invalid ORBITMESH_RECOVERY_POLICIES entry %q", entry)
+		}
+		policies[strings.TrimSpace(providerType)] = policy
+	}
+	return policies
+}
+
+// taskSourceFromEnv reads linked tasks with the strand CLI, in
+// STRAND_PROJECT_DIR when set. ORBITMESH_DISABLE_TASK_CONTEXT turns task
+// context injection off.
+func taskSourceFromEnv() service.TaskSource {
+	if envBool("ORBITMESH_DISABLE_TASK_CONTEXT") {
+		return nil
+	}
+	return service.StrandTaskSource{ProjectDir: os.Getenv("STRAND_PROJECT_DIR")}
+}
+
+// warmPoolFromEnv reads the provider warm pool sizes from
+// ORBITMESH_WARM_POOL, e.g. "claude-ws=2,claude=1", and the idle TTL from
+// ORBITMESH_WARM_POOL_TTL (a Go duration).
+func warmPoolFromEnv() service.WarmPoolConfig {
+	var cfg service.WarmPoolConfig
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_WARM_POOL")); raw != "" {
+		cfg.Sizes = make(map[string]int)
+		for _, entry := range strings.Split(raw, ",") {
+			providerType, rawSize, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			size, err := strconv.Atoi(strings.TrimSpace(rawSize))
+			if !ok || strings.TrimSpace(providerType) == "" || err != nil || size < 0 {
+				log.Fatalf("invalid ORBITMESH_WARM_POOL entry %q", entry)
+			}
+			cfg.Sizes[strings.TrimSpace(providerType)] = size
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_WARM_POOL_TTL")); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("invalid ORBITMESH_WARM_POOL_TTL %q", raw)
+		}
+		cfg.TTL = ttl
+	}
+	return cfg
+}
+
+// guardrailsFromEnv reads the server's output guardrail actions from
+// ORBITMESH_GUARDRAILS, e.g. "secret=block,pii=flag". Categories left out
+// keep their defaults. Flagged and blocked output is quarantined under
+// baseDir.
+func guardrailsFromEnv(baseDir string) service.GuardrailConfig {
+	cfg := service.GuardrailConfig{Quarantine: storage.NewQuarantineStorage(baseDir)}
+	raw := strings.TrimSpace(os.Getenv("ORBITMESH_GUARDRAILS"))
+	if raw == "" {
+		return cfg
+	}
+	cfg.Policy.Actions = make(map[string]domain.GuardrailAction)
+	for _, entry := range strings.Split(raw, ",") {
+		category, name, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		action, err := domain.ParseGuardrailAction(name)
+		if !ok || strings.TrimSpace(category) == "" || action == "" || err != nil {
+			log.Fatalf("invalid ORBITMESH_GUARDRAILS entry %q", entry)
+		}
+		cfg.Policy.Actions[strings.TrimSpace(category)] = action
+	}
+	return cfg
+}
+
+// applyProjectPolicies loads the guardrail and tool policies, working hours
+// and watches saved on projects.
+func applyProjectPolicies(executor *service.AgentExecutor, projects *storage.ProjectStorage) {
+	list, err := projects.List()
+	if err != nil {
+		log.Printf("project policies: %v", err)
+		return
+	}
+	for _, p := range list {
+		if p.Guardrails != nil {
+			if err := executor.SetProjectGuardrails(p.ID, p.Guardrails); err != nil {
+				log.Printf("project %s guardrails: %v", p.ID, err)
+			}
+		}
+		if p.ToolPolicy != nil {
+			if err := executor.SetProjectToolPolicy(p.ID, p.ToolPolicy); err != nil {
+				log.Printf("project %s tool policy: %v", p.ID, err)
+			}
+		}
+		if p.CostBudget != nil {
+			if err := executor.SetProjectCostBudget(p.ID, p.CostBudget); err != nil {
+				log.Printf("project %s cost budget: %v", p.ID, err)
+			}
+		}
+		if p.WorkingHours != nil {
+			if err := executor.SetProjectWorkingHours(p.ID, p.WorkingHours); err != nil {
+				log.Printf("project %s working hours: %v", p.ID, err)
+			}
+		}
+		if p.Watch != nil {
+			if err := executor.SetProjectWatch(p.ID, p.Path, p.Watch); err != nil {
+				log.Printf("project %s watch: %v", p.ID, err)
+			}
+		}
+	}
+}
+
+// applyAgentPolicies loads the tool policies saved on agent configs.
+func applyAgentPolicies(executor *service.AgentExecutor, agents *storage.AgentConfigStorage) {
+	list, err := agents.List()
+	if err != nil {
+		log.Printf("agent policies: %v", err)
+		return
+	}
+	for _, a := range list {
+		if a.ToolPolicy != nil {
+			if err := executor.SetAgentToolPolicy(a.ID, a.ToolPolicy); err != nil {
+				log.Printf("agent %s tool policy: %v", a.ID, err)
+			}
+		}
+	}
+}
+
+// gitCredentialsFromEnv reads the delegated git credential settings:
+// ORBITMESH_GIT_HOST, ORBITMESH_GIT_PATH_PREFIX, ORBITMESH_GIT_USERNAME,
+// ORBITMESH_GIT_TOKEN or ORBITMESH_GIT_TOKEN_COMMAND, ORBITMESH_GIT_TOKEN_TTL
+// and ORBITMESH_GIT_CREDENTIAL_URL (default the local helper endpoint). The
+// upstream token is removed from the environment so provider processes,
+// which inherit it, never see it.
+func gitCredentialsFromEnv(addr string) service.GitCredentialConfig {
+	cfg := service.GitCredentialConfig{
+		Host:         strings.TrimSpace(os.Getenv("ORBITMESH_GIT_HOST")),
+		PathPrefix:   strings.TrimSpace(os.Getenv("ORBITMESH_GIT_PATH_PREFIX")),
+		Username:     strings.TrimSpace(os.Getenv("ORBITMESH_GIT_USERNAME")),
+		Token:        strings.TrimSpace(os.Getenv("ORBITMESH_GIT_TOKEN")),
+		TokenCommand: strings.TrimSpace(os.Getenv("ORBITMESH_GIT_TOKEN_COMMAND")),
+		HelperURL:    strings.TrimSpace(os.Getenv("ORBITMESH_GIT_CREDENTIAL_URL")),
+	}
+	_ = os.Unsetenv("ORBITMESH_GIT_TOKEN")
+	_ = os.Unsetenv("ORBITMESH_GIT_TOKEN_COMMAND")
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_GIT_TOKEN_TTL")); raw != "" {
+		ttl, err := time.ParseDuration(raw)
+		if err != nil || ttl <= 0 {
+			log.Fatalf("invalid ORBITMESH_GIT_TOKEN_TTL %q", raw)
+		}
+		cfg.TTL = ttl
+	}
+	if cfg.HelperURL == "" {
+		cfg.HelperURL = "http://127.0.0.1" + addr + "/api/git/credential"
+	}
+	if cfg.Host != "" && cfg.TokenCommand == "" {
+		log.Printf("ORBITMESH_GIT_TOKEN_COMMAND is not set: sessions get no git credentials, and ORBITMESH_GIT_TOKEN is never handed to them")
+	}
+	return cfg
+}
+
+// pullRequestForgesFromEnv reads ORBITMESH_GITHUB_API_URL,
+// ORBITMESH_GITHUB_TOKEN and ORBITMESH_GITHUB_TOKEN_COMMAND, and their
+// ORBITMESH_GITLAB_ counterparts: where pull requests are opened and the
+// token they are opened with.
+func pullRequestForgesFromEnv() map[string]service.PullRequestForge {
+	forges := make(map[string]service.PullRequestForge)
+	for provider, prefix := range map[string]string{
+		domain.PullRequestGitHub: "ORBITMESH_GITHUB_",
+		domain.PullRequestGitLab: "ORBITMESH_GITLAB_",
+	} {
+		forge := service.PullRequestForge{
+			APIURL:       strings.TrimSpace(os.Getenv(prefix + "API_URL")),
+			Token:        strings.TrimSpace(os.Getenv(prefix + "TOKEN")),
+			TokenCommand: strings.TrimSpace(os.Getenv(prefix + "TOKEN_COMMAND")),
+		}
+		_ = os.Unsetenv(prefix + "TOKEN")
+		_ = os.Unsetenv(prefix + "TOKEN_COMMAND")
+		if forge.APIURL != "" && !strings.HasPrefix(forge.APIURL, "https://") && !strings.HasPrefix(forge.APIURL, "http://") {
+			log.Fatalf("invalid %sAPI_URL %q", prefix, forge.APIURL)
+		}
+		forges[provider] = forge
+	}
+	return forges
+}
+
+// apiBaseURLFromEnv reads ORBITMESH_API_BASE_URL, the address agent
+// processes reach the API at, which defaults to the local listen address.
+// Runs on remote hosts or Kubernetes need it set to an address they can
+// reach.
+func apiBaseURLFromEnv(addr string) string {
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_API_BASE_URL")); raw != "" {
+		return raw
+	}
+	return "http://127.0.0.1" + addr
+}
+
+// workspaceDirFromEnv reads ORBITMESH_WORKSPACE_DIR, where sessions that
+// ask for a workspace get their checkouts, which defaults to
+// <baseDir>/workspaces.
+func workspaceDirFromEnv(baseDir string) string {
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_WORKSPACE_DIR")); raw != "" {
+		return raw
+	}
+	return filepath.Join(baseDir, "workspaces")
+}
+
+// embeddedClientFromEnv enables the desktop-client handshake when
+// ORBITMESH_EMBEDDED_CLIENT is set. The launch nonce comes from
+// ORBITMESH_EMBEDDED_NONCE (for shells that spawn the server) or is generated
+// and written, owner-readable only, to <baseDir>/embedded-client.nonce.
+func embeddedClientFromEnv(baseDir string) *api.EmbeddedClientAuth {
+	if !envBool("ORBITMESH_EMBEDDED_CLIENT") {
+		return nil
+	}
+	nonce := strings.TrimSpace(os.Getenv("ORBITMESH_EMBEDDED_NONCE"))
+	if nonce == "" {
+		var buf [32]byte
+		if _, err := rand.Read(buf[:]); err != nil {
+			log.Fatalf("embedded client nonce: %v", err)
+		}
+		nonce = hex.EncodeToString(buf[:])
+		path := filepath.Join(baseDir, "embedded-client.nonce")
+		if err := os.WriteFile(path, []byte(nonce), 0o600); err != nil {
+			log.Fatalf("embedded client nonce: %v", err)
+		}
+		log.Printf("embedded client mode: nonce written to %s", path)
+	}
+	return api.NewEmbeddedClientAuth(nonce)
+}
+
+// demoModeFromEnv enables the public playground restrictions when
+// ORBITMESH_DEMO_MODE is set. ORBITMESH_DEMO_MAX_SESSIONS_PER_IP caps session
+// creation per client, ORBITMESH_DEMO_TRUST_PROXY reads the client address
+// from X-Forwarded-For, and ORBITMESH_DEMO_WORKING_DIR is where sessions
+// work, by default a "demo" directory under baseDir.
+func demoModeFromEnv(baseDir string) *api.DemoMode {
+	if !envBool("ORBITMESH_DEMO_MODE") {
+		return nil
+	}
+	cfg := api.DemoModeConfig{
+		TrustProxy: envBool("ORBITMESH_DEMO_TRUST_PROXY"),
+		WorkingDir: strings.TrimSpace(os.Getenv("ORBITMESH_DEMO_WORKING_DIR")),
+	}
+	if cfg.WorkingDir == "" {
+		cfg.WorkingDir = filepath.Join(baseDir, "demo")
+	}
+	if err := os.MkdirAll(cfg.WorkingDir, 0o755); err != nil {
+		log.Fatalf("demo working dir: %v", err)
+	}
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_DEMO_MAX_SESSIONS_PER_IP")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			log.Fatalf("invalid ORBITMESH_DEMO_MAX_SESSIONS_PER_IP %q", raw)
+		}
+		cfg.MaxSessionsPerIP = n
+	}
+	return api.NewDemoMode(cfg)
+}
+
+// mirrorConfigFromEnv reads ORBITMESH_MIRROR_URL, the instance to mirror
+// read-only, with ORBITMESH_MIRROR_TOKEN and ORBITMESH_MIRROR_INTERVAL. It
+// returns nil when the instance is not a mirror.
+func mirrorConfigFromEnv() *api.MirrorConfig {
+	raw := strings.TrimSpace(os.Getenv("ORBITMESH_MIRROR_URL"))
+	if raw == "" {
+		return nil
+	}
+	cfg := &api.MirrorConfig{URL: raw, Token: strings.TrimSpace(os.Getenv("ORBITMESH_MIRROR_TOKEN"))}
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_MIRROR_INTERVAL")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid ORBITMESH_MIRROR_INTERVAL %q", raw)
+		}
+		cfg.Interval = d
+	}
+	return cfg
+}
+
+// embedRateLimitFromEnv reads ORBITMESH_EMBED_RATE_LIMIT, the requests per
+// minute each embed token and client address may make, defaulting to
+// api.DefaultEmbedRequestsPerMinute.
+func embedRateLimitFromEnv() int {
+	raw := strings.TrimSpace(os.Getenv("ORBITMESH_EMBED_RATE_LIMIT"))
+	if raw == "" {
+		return api.DefaultEmbedRequestsPerMinute
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		log.Fatalf("invalid ORBITMESH_EMBED_RATE_LIMIT %q", raw)
+	}
+	return n
+}
+
+// eventLogRotationFromEnv reads ORBITMESH_EVENT_LOG_SEGMENT_BYTES, the size
+// at which a session's event journal is sealed into a segment, and
+// ORBITMESH_EVENT_LOG_MAX_SEGMENTS, how many segments each session keeps.
+// Either may be 0 to disable rotation or keep every segment.
+func eventLogRotationFromEnv() (segmentSize int64, maxSegments int) {
+	segmentSize, maxSegments = storage.DefaultEventLogSegmentSize, storage.DefaultEventLogMaxSegments
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_EVENT_LOG_SEGMENT_BYTES")); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			log.Fatalf("invalid ORBITMESH_EVENT_LOG_SEGMENT_BYTES %q", raw)
+		}
+		segmentSize = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_EVENT_LOG_MAX_SEGMENTS")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			log.Fatalf("invalid ORBITMESH_EVENT_LOG_MAX_SEGMENTS %q", raw)
+		}
+		maxSegments = n
+	}
+	return segmentSize, maxSegments
+}
+
+// metricsTopSessionsFromEnv reads ORBITMESH_METRICS_TOP_SESSIONS, how many
+// of the costliest sessions GET /metrics labels individually, defaulting to
+// service.DefaultCostMetricsTopSessions.
+func metricsTopSessionsFromEnv() int {
+	raw := strings.TrimSpace(os.Getenv("ORBITMESH_METRICS_TOP_SESSIONS"))
+	if raw == "" {
+		return service.DefaultCostMetricsTopSessions
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		log.Fatalf("invalid ORBITMESH_METRICS_TOP_SESSIONS %q", raw)
+	}
+	return n
+}
+
+// sseTimingFromEnv reads the SSE heartbeat interval and client reconnection
+// delay from ORBITMESH_SSE_HEARTBEAT_INTERVAL and ORBITMESH_SSE_RETRY (Go
+// durations). Unset values are zero, keeping the handler's defaults.
+func sseTimingFromEnv() (heartbeat, retry time.Duration) {
+	for name, dst := range map[string]*time.Duration{
+		"ORBITMESH_SSE_HEARTBEAT_INTERVAL": &heartbeat,
+		"ORBITMESH_SSE_RETRY":              &retry,
+	} {
+		raw := strings.TrimSpace(os.Getenv(name))
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid %s %q", name, raw)
+		}
+		*dst = d
+	}
+	return heartbeat, retry
+}
+
+// shutdownTimeoutsFromEnv reads the per-phase executor shutdown timeouts
+// from ORBITMESH_SHUTDOWN_SUSPEND_TIMEOUT, ORBITMESH_SHUTDOWN_STOP_TIMEOUT
+// and ORBITMESH_SHUTDOWN_KILL_TIMEOUT (Go durations).
+func shutdownTimeoutsFromEnv() service.ShutdownTimeouts {
+	var timeouts service.ShutdownTimeouts
+	for name, dst := range map[string]*time.Duration{
+		"ORBITMESH_SHUTDOWN_SUSPEND_TIMEOUT": &timeouts.Suspend,
+		"ORBITMESH_SHUTDOWN_STOP_TIMEOUT":    &timeouts.Stop,
+		"ORBITMESH_SHUTDOWN_KILL_TIMEOUT":    &timeouts.Kill,
+	} {
+		raw := strings.TrimSpace(os.Getenv(name))
+		if raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid %s %q", name, raw)
+		}
+		*dst = d
+	}
+	return timeouts
+}
+
+// tracingFromEnv exports spans over OTLP/HTTP when
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set.
+// The exporter and sampler read the rest of their settings from the
+// standard OTEL_* variables; the service is named "orbitmesh" unless
+// OTEL_SERVICE_NAME says otherwise. It returns a func flushing spans at
+// shutdown.
+func tracingFromEnv() func(context.Context) error {
+	if strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")) == "" && strings.TrimSpace(os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")) == "" {
+		return func(context.Context) error { return nil }
+	}
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		log.Fatalf("otlp trace exporter: %v", err)
+	}
+	res := resource.Default()
+	if strings.TrimSpace(os.Getenv("OTEL_SERVICE_NAME")) == "" {
+		res, err = resource.Merge(res, resource.NewSchemaless(attribute.String("service.name", "orbitmesh")))
+		if err != nil {
+			log.Fatalf("otel resource: %v", err)
+		}
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown
+}
+
+// provisionDemoProviders saves provider configs for the demo providers so
+// they are selectable without setup.
+func provisionDemoProviders(providerStorage *storage.ProviderConfigStorage) {
+	for _, cfg := range []storage.ProviderConfig{
+		{ID: "demo-echo", Name: "Echo (demo)", Type: demo.EchoProviderType, IsActive: true},
+		{ID: "demo-replay", Name: "Replay (demo)", Type: demo.ReplayProviderType, IsActive: true},
+	} {
+		if _, err := providerStorage.Get(cfg.ID); err == nil {
+			continue
+		}
+		if err := providerStorage.Save(cfg); err != nil {
+			log.Fatalf("provision demo provider %s: %v", cfg.ID, err)
+		}
+	}
+}
+
+// runDemoResets wipes all sessions and demo quotas every night at midnight
+// UTC until ctx is done.
+func runDemoResets(ctx context.Context, executor *service.AgentExecutor, demoMode *api.DemoMode) {
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		removed, err := executor.ResetSessions(ctx)
+		if err != nil {
+			log.Printf("demo reset: %v", err)
+		}
+		demoMode.Reset()
+		log.Printf("demo reset: removed %d sessions", len(removed))
+	}
+}
+
+// routeProjectStorage points the session store at the storage roots of
+// projects that keep their own session history.
+func routeProjectStorage(store *storage.JSONFileStorage, projects *storage.ProjectStorage) {
+	list, err := projects.List()
+	if err != nil {
+		log.Printf("project storage roots: %v", err)
+		return
+	}
+	for _, p := range list {
+		if root := p.StorageRoot(); root != "" {
+			if err := store.SetProjectRoot(p.ID, root); err != nil {
+				log.Printf("project %s storage root: %v", p.ID, err)
+			}
+		}
+	}
+}
+
 func main() {
+	shutdownTracing := tracingFromEnv()
 	baseDir := storage.DefaultBaseDir()
 	store, err := storage.NewJSONFileStorage(baseDir)
 	if err != nil {
 		log.Fatalf("storage init: %v", err)
 	}
+	blobs := storage.NewBlobStore(baseDir)
+	store.SetBlobStore(blobs)
+	eventLog := storage.NewEventLogStorage(baseDir)
+	eventLog.SetBlobStore(blobs)
+	eventLog.SetRotation(eventLogRotationFromEnv())
 
 	providerStorage := storage.NewProviderConfigStorage(baseDir)
 	agentStorage := storage.NewAgentConfigStorage(baseDir)
 	projectStorage := storage.NewProjectStorage(baseDir)
+	routeProjectStorage(store, projectStorage)
+
+	demoMode := demoModeFromEnv(baseDir)
+	mirrorConfig := mirrorConfigFromEnv()
 
+	kube.AllowHostPath = envBool("ORBITMESH_KUBE_ALLOW_HOST_PATH")
+	commands := &commandApprovals{}
 	factory := provider.NewDefaultFactory()
-	factory.Register("adk", func(sessionID string, config session.Config) (session.Session, error) {
-		return native.NewADKSession(sessionID, adkConfigFromProvider(config)), nil
+	factory.Register(demo.EchoProviderType, func(sessionID string, config session.Config) (session.Session, error) {
+		return demo.NewEchoSession(sessionID), nil
 	})
-	factory.Register("pty", func(sessionID string, config session.Config) (session.Session, error) {
-		return pty.NewPTYProvider(sessionID), nil
-	})
-	factory.Register("claude", func(sessionID string, config session.Config) (session.Session, error) {
-		return claude.NewClaudeCodeProvider(sessionID), nil
-	})
-	factory.Register("claude-ws", func(sessionID string, config session.Config) (session.Session, error) {
-		// permHandler is nil → auto-allow all tools.
-		// Callers can wire a custom handler by constructing the provider directly.
-		return claudews.NewClaudeWSProvider(sessionID, nil), nil
-	})
-	factory.Register("acp", func(sessionID string, config session.Config) (session.Session, error) {
-		return acp.NewSession(sessionID, acpConfigFromProvider(config), config)
+	factory.Register(demo.ReplayProviderType, func(sessionID string, config session.Config) (session.Session, error) {
+		lines, delay := demo.ReplayConfig(config.Custom)
+		return demo.NewReplaySession(sessionID, lines, delay), nil
 	})
+	if demoMode != nil {
+		// Public playgrounds only get the in-process providers.
+		provisionDemoProviders(providerStorage)
+	} else {
+		registerAgentProviders(factory, commands)
+	}
 
 	broadcaster := service.NewEventBroadcaster(100)
 
@@ -77,18 +614,69 @@ func main() {
 		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
 			return factory.CreateSession(providerType, sessionID, config)
 		},
+		CleanupPolicy:     cleanupPolicyFromEnv(),
+		ToolStatsStorage:  storage.NewToolStatsStorage(baseDir),
+		ReadStateStorage:  storage.NewReadStateStorage(baseDir),
+		AuditLogStorage:   storage.NewAuditLogStorage(baseDir),
+		ProjectUsage:      storage.NewProjectUsageStorage(baseDir),
+		RecoveryReports:   storage.NewRecoveryReportStorage(baseDir),
+		GitCredentials:    gitCredentialsFromEnv(listenAddr()),
+		PullRequestForges: pullRequestForgesFromEnv(),
+		WorkingDirLock:    envBool("ORBITMESH_WORKDIR_LOCK"),
+		RecoveryPolicy:    recoveryPolicyFromEnv(),
+		RecoveryPolicies:  recoveryPoliciesFromEnv(),
+		WarmPool:          warmPoolFromEnv(),
+		Guardrails:        guardrailsFromEnv(baseDir),
+		EmbedTokens:       storage.NewEmbedTokenStorage(baseDir),
+		ShutdownTimeouts:  shutdownTimeoutsFromEnv(),
+		ShutdownReports:   storage.NewShutdownReportStorage(baseDir),
+		TaskSource:        taskSourceFromEnv(),
+		TaskJournal:       storage.NewTaskJournalStorage(baseDir),
+		EventLog:          eventLog,
+		Schedules:         storage.NewScheduleStorage(baseDir),
+		Missions:          storage.NewMissionStorage(baseDir),
+		Pipelines:         storage.NewPipelineStorage(baseDir),
+		Templates:         storage.NewTemplateStorage(baseDir),
+		ProviderConfigs:   providerStorage,
+		AgentConfigs:      agentStorage,
+		Projects:          projectStorage,
+		MissionUsage:      storage.NewMissionUsageStorage(baseDir),
+		ReadOnlyMirror:    mirrorConfig != nil,
+		APIBaseURL:        apiBaseURLFromEnv(listenAddr()),
+		WorkspaceDir:      workspaceDirFromEnv(baseDir),
 	})
-	if err := executor.Startup(context.Background()); err != nil {
-		log.Fatalf("executor startup recovery: %v", err)
-	}
-
+	commands.executor = executor
+	applyProjectPolicies(executor, projectStorage)
+	applyAgentPolicies(executor, agentStorage)
 	r := chi.NewRouter()
+	r.Use(api.TracingMiddleware)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(api.CORSMiddleware)
+	if embedded := embeddedClientFromEnv(baseDir); embedded != nil {
+		r.Use(embedded.Middleware)
+	}
 	r.Use(api.CSRFMiddleware)
+	if demoMode != nil {
+		r.Use(demoMode.Middleware)
+	}
+	var mirror *api.Mirror
+	if mirrorConfig != nil {
+		mirror, err = api.NewMirror(executor, *mirrorConfig)
+		if err != nil {
+			log.Fatalf("mirror: %v", err)
+		}
+		r.Use(mirror.Middleware)
+	}
 
 	handler := api.NewHandler(executor, broadcaster, store, providerStorage, agentStorage, projectStorage)
+	handler.SetEmbedRateLimit(embedRateLimitFromEnv())
+	handler.SetMetricsTopSessions(metricsTopSessionsFromEnv())
+	handler.SetSSETiming(sseTimingFromEnv())
+	handler.SetDevMode(envBool("ORBITMESH_DEV_MODE"))
+	if mirror != nil {
+		handler.SetMirror(mirror)
+	}
 	handler.Mount(r)
 	addr := listenAddr()
 
@@ -107,6 +695,19 @@ func main() {
 		}
 	}()
 
+	// Recovery runs once the server is up so its progress can be followed
+	// on the system.recovery topic and GET /api/v1/admin/recovery.
+	if err := executor.Startup(ctx); err != nil {
+		log.Fatalf("executor startup recovery: %v", err)
+	}
+
+	if demoMode != nil {
+		go runDemoResets(ctx, executor, demoMode)
+	}
+	if mirror != nil {
+		go mirror.Run(ctx)
+	}
+
 	<-ctx.Done()
 	stop()
 
@@ -119,10 +720,89 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatalf("server shutdown: %v", err)
 	}
+	if err := eventLog.Close(); err != nil {
+		log.Printf("event log close: %v", err)
+	}
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("tracing shutdown: %v", err)
+	}
 
 	fmt.Println("OrbitMesh shut down cleanly")
 }
 
+// commandApprovals holds agent tool calls for the executor's tool policies
+// and command and tool approval. The executor is set once it exists, before
+// any session can start.
+type commandApprovals struct {
+	executor *service.AgentExecutor
+}
+
+func (c *commandApprovals) permissionHandler(sessionID string) claudews.PermissionHandler {
+	return func(ctx context.Context, req claudews.CanUseToolRequest) (bool, map[string]any, string) {
+		if c.executor == nil || sessionID == "" {
+			return true, nil, ""
+		}
+		switch d := c.executor.EvaluateToolPolicy(sessionID, domain.NewToolPolicyCall(req.ToolUseID, req.ToolName, req.Input)); d.Outcome {
+		case domain.ToolPolicyDeny:
+			return false, nil, d.Reason()
+		case domain.ToolPolicyAllow:
+			return true, nil, ""
+		}
+		if command, ok := domain.ToolCommand(req.ToolName, req.Input); ok {
+			if allow, reason := c.executor.AwaitCommandApproval(ctx, sessionID, req.ToolUseID, command); !allow {
+				return false, nil, reason
+			}
+		}
+		return c.executor.AwaitToolApproval(ctx, sessionID, req.ToolUseID, req.ToolName, req.Input, req.Description)
+	}
+}
+
+// acpPermissionHandler decides ACP permission requests by the session's
+// tool policies, then its tool approval. Tools are named by ACP tool kind.
+func (c *commandApprovals) acpPermissionHandler(sessionID string) acp.PermissionHandler {
+	return func(ctx context.Context, req acp.PermissionRequest) (bool, string) {
+		if c.executor == nil || sessionID == "" {
+			return true, ""
+		}
+		call := domain.ToolPolicyCall{ToolCallID: req.ToolCallID, Tool: req.Kind, Command: req.Command, WritePaths: req.WritePaths}
+		switch d := c.executor.EvaluateToolPolicy(sessionID, call); d.Outcome {
+		case domain.ToolPolicyDeny:
+			return false, d.Reason()
+		case domain.ToolPolicyAllow:
+			return true, ""
+		}
+		allow, _, reason := c.executor.AwaitToolApproval(ctx, sessionID, req.ToolCallID, req.Kind, req.Input, req.Title)
+		return allow, reason
+	}
+}
+
+// registerAgentProviders registers the providers that run real agents.
+func registerAgentProviders(factory *provider.DefaultFactory, commands *commandApprovals) {
+	factory.Register("adk", func(sessionID string, config session.Config) (session.Session, error) {
+		return native.NewADKSession(sessionID, adkConfigFromProvider(config)), nil
+	})
+	factory.Register("pty", func(sessionID string, config session.Config) (session.Session, error) {
+		return pty.NewPTYProvider(sessionID), nil
+	})
+	factory.Register("claude", func(sessionID string, config session.Config) (session.Session, error) {
+		return claude.NewClaudeCodeProvider(sessionID), nil
+	})
+	factory.Register("claude-ws", func(sessionID string, config session.Config) (session.Session, error) {
+		// Tool policies decide first. Shell commands left undecided go
+		// through the session's command approval; other tools through its
+		// tool approval, if any.
+		return claudews.NewClaudeWSProvider(sessionID, commands.permissionHandler(sessionID)), nil
+	})
+	factory.Register("acp", func(sessionID string, config session.Config) (session.Session, error) {
+		cfg := acpConfigFromProvider(config)
+		cfg.PermissionHandler = commands.acpPermissionHandler(sessionID)
+		return acp.NewSession(sessionID, cfg, config)
+	})
+	factory.Register("openai", func(sessionID string, config session.Config) (session.Session, error) {
+		return openai.NewSession(sessionID, openaiConfigFromProvider(config)), nil
+	})
+}
+
 func acpConfigFromProvider(config session.Config) acp.Config {
 	cfg := acp.Config{}
 	if config.Custom == nil {
@@ -141,6 +821,27 @@ func acpConfigFromProvider(config session.Config) acp.Config {
 	return cfg
 }
 
+func openaiConfigFromProvider(config session.Config) openai.Config {
+	cfg := openai.Config{}
+	if config.Custom == nil {
+		return cfg
+	}
+	if model, ok := config.Custom["model"].(string); ok && model != "" {
+		cfg.Model = model
+	}
+	if baseURL, ok := config.Custom["base_url"].(string); ok && baseURL != "" {
+		cfg.BaseURL = baseURL
+	}
+	// Custom values decoded from JSON hold numbers as float64.
+	switch maxTurns := config.Custom["max_turns"].(type) {
+	case float64:
+		cfg.MaxTurns = int(maxTurns)
+	case int:
+		cfg.MaxTurns = maxTurns
+	}
+	return cfg
+}
+
 func adkConfigFromProvider(config session.Config) native.ADKConfig {
 	adkCfg := native.ADKConfig{}
 	if config.Custom == nil {
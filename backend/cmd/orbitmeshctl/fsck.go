@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// runFsck checks a stopped server's storage directory directly. On a running
+// server use GET /api/v1/admin/integrity instead, which leaves sessions with
+// live runs alone.
+func runFsck(args []string) error {
+	fs := flag.NewFlagSet("fsck", flag.ExitOnError)
+	dir := fs.String("dir", storage.DefaultBaseDir(), "OrbitMesh storage directory")
+	repair := fs.Bool("repair", false, "apply safe repairs (moves broken files under sessions/quarantine)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := storage.NewJSONFileStorage(*dir)
+	if err != nil {
+		return err
+	}
+	issues, err := store.CheckIntegrity(*repair, nil)
+	if err != nil {
+		return err
+	}
+
+	unrepaired := 0
+	for _, issue := range issues {
+		status := "found"
+		switch {
+		case issue.Repaired:
+			status = "repaired"
+		case issue.RepairError != "":
+			status = "repair failed: " + issue.RepairError
+		}
+		if !issue.Repaired {
+			unrepaired++
+		}
+		fmt.Printf("%s %s: %s [%s]\n", issue.Kind, issue.Path, issue.Detail, status)
+		if !*repair && issue.Repair != "" {
+			fmt.Printf("    repair: %s\n", issue.Repair)
+		}
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("no issues found")
+		return nil
+	}
+	if unrepaired > 0 {
+		return fmt.Errorf("%d of %d issues not repaired", unrepaired, len(issues))
+	}
+	fmt.Printf("%d issues repaired\n", len(issues))
+	return nil
+}
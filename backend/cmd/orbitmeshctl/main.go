@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
+)
+
+const (
+	defaultServer     = "http://localhost:8080"
+	reconnectDelay    = 3 * time.Second
+	dedupeRetention   = 24 * time.Hour
+	notificationTopic = "notifications"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "watch":
+		err = runWatch(os.Args[2:])
+	case "fsck":
+		err = runFsck(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: orbitmeshctl <command> [flags]
+
+commands:
+  watch    stream approval and failure notifications from a server
+  fsck     check a stopped server's storage for corruption (-repair to fix)`)
+}
+
+func serverFromEnv() string {
+	if raw := strings.TrimSpace(os.Getenv("ORBITMESH_URL")); raw != "" {
+		return raw
+	}
+	return defaultServer
+}
+
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	server := fs.String("server", serverFromEnv(), "OrbitMesh server URL")
+	notify := fs.Bool("notify", false, "raise native desktop notifications")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	wsURL, err := realtimeURL(*server)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := &watcher{notify: *notify, seen: make(map[string]time.Time)}
+	for {
+		if err := w.stream(ctx, wsURL); err != nil && ctx.Err() == nil {
+			log.Printf("watch: %v (reconnecting in %s)", err, reconnectDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func realtimeURL(server string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", server, err)
+	}
+	switch u.Scheme {
+	case "http", "":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/realtime"
+	return u.String(), nil
+}
+
+type watcher struct {
+	notify bool
+	// seen maps dedupe keys to when they were first shown; it survives
+	// reconnects so snapshot replays don't re-alert.
+	seen map[string]time.Time
+}
+
+func (w *watcher) stream(ctx context.Context, wsURL string) error {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	if err := conn.WriteJSON(realtimeTypes.ClientEnvelope{
+		Type:   realtimeTypes.ClientMessageTypeSubscribe,
+		Topics: []string{notificationTopic},
+	}); err != nil {
+		return err
+	}
+
+	for {
+		var env struct {
+			Type    realtimeTypes.ServerMessageType `json:"type"`
+			Topic   string                          `json:"topic"`
+			Payload json.RawMessage                 `json:"payload"`
+			Message string                          `json:"message"`
+		}
+		if err := conn.ReadJSON(&env); err != nil {
+			return err
+		}
+
+		switch env.Type {
+		case realtimeTypes.ServerMessageTypeSnapshot:
+			var snap realtimeTypes.NotificationsSnapshot
+			if err := json.Unmarshal(env.Payload, &snap); err != nil {
+				log.Printf("watch: bad snapshot: %v", err)
+				continue
+			}
+			for _, n := range snap.Notifications {
+				w.handle(n)
+			}
+		case realtimeTypes.ServerMessageTypeEvent:
+			var n realtimeTypes.Notification
+			if err := json.Unmarshal(env.Payload, &n); err != nil {
+				log.Printf("watch: bad event: %v", err)
+				continue
+			}
+			w.handle(n)
+		case realtimeTypes.ServerMessageTypeError:
+			return fmt.Errorf("server error: %s", env.Message)
+		}
+	}
+}
+
+func (w *watcher) handle(n realtimeTypes.Notification) {
+	now := time.Now()
+	for key, at := range w.seen {
+		if now.Sub(at) > dedupeRetention {
+			delete(w.seen, key)
+		}
+	}
+	if n.DedupeKey != "" {
+		if _, dup := w.seen[n.DedupeKey]; dup {
+			return
+		}
+		w.seen[n.DedupeKey] = now
+	}
+
+	fmt.Printf("%s [%s] %s", n.Timestamp.Local().Format(time.Kitchen), n.Kind, n.Title)
+	if n.Body != "" {
+		fmt.Printf(": %s", n.Body)
+	}
+	fmt.Println()
+
+	if w.notify {
+		if err := desktopNotify(n.Title, n.Body); err != nil {
+			log.Printf("watch: desktop notification failed: %v", err)
+		}
+	}
+}
+
+// desktopNotify raises a native notification using the platform's stock
+// tooling so the CLI carries no extra dependencies.
+func desktopNotify(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(`[reflection.assembly]::loadwithpartialname('System.Windows.Forms') | Out-Null; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information; `+
+			`$n.Visible = $true; $n.ShowBalloonTip(10000, %s, %s, 'Info'); Start-Sleep -Seconds 1`,
+			psQuote(title), psQuote(body))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=OrbitMesh", title, body)
+	}
+	return cmd.Run()
+}
+
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
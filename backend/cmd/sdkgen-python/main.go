@@ -0,0 +1,89 @@
+// Command sdkgen-python writes the OpenAPI spec of the API routes scripts
+// use to sdk/openapi.json, and generates the Python client package in
+// sdk/python from it. With -spec it generates the client from an existing
+// spec instead.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// sdkVersion versions the spec and the published client together.
+const sdkVersion = "0.1.0"
+
+func main() {
+	specPath := flag.String("spec", "", "generate the client from this OpenAPI spec instead of pkg/api")
+	flag.Parse()
+
+	repoRoot, err := findRepoRoot()
+	if err != nil {
+		panic(err)
+	}
+	sdkDir := filepath.Join(repoRoot, "sdk")
+
+	var doc *Document
+	if *specPath != "" {
+		data, err := os.ReadFile(*specPath)
+		if err != nil {
+			panic(err)
+		}
+		doc = &Document{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			panic(fmt.Errorf("parse %s: %w", *specPath, err))
+		}
+	} else {
+		doc = BuildSpec()
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		if err := writeFile(filepath.Join(sdkDir, "openapi.json"), append(data, '\n')); err != nil {
+			panic(err)
+		}
+	}
+
+	files, err := RenderPython(doc)
+	if err != nil {
+		panic(err)
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if err := writeFile(filepath.Join(sdkDir, "python", filepath.FromSlash(name)), files[name]); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", path)
+	return nil
+}
+
+func findRepoRoot() (string, error) {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "", fmt.Errorf("unable to resolve generator path")
+	}
+	backendDir := filepath.Clean(filepath.Join(filepath.Dir(file), "..", ".."))
+	repoRoot := filepath.Clean(filepath.Join(backendDir, ".."))
+	if _, err := os.Stat(filepath.Join(backendDir, "go.mod")); err != nil {
+		return "", fmt.Errorf("backend module not found: %w", err)
+	}
+	return repoRoot, nil
+}
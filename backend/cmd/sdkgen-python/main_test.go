@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildSpec_RefsResolve(t *testing.T) {
+	doc := BuildSpec()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	// Every $ref must name a component, and the spec must survive a round
+	// trip since -spec reads it back.
+	for _, part := range strings.Split(string(data), `"$ref":"`)[1:] {
+		ref, _, _ := strings.Cut(part, `"`)
+		if _, ok := doc.Components.Schemas[strings.TrimPrefix(ref, schemaPrefix)]; !ok {
+			t.Errorf("unresolved ref %s", ref)
+		}
+	}
+	var reread Document
+	if err := json.Unmarshal(data, &reread); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	session := reread.Components.Schemas["SessionStatusResponse"]
+	if session == nil || session.Properties["id"] == nil || session.Properties["metrics"] == nil {
+		t.Fatalf("expected embedded SessionResponse fields to be flattened, got %+v", session)
+	}
+	if op := reread.Paths["/api/sessions/{id}/events"]["get"]; op.Responses["200"].Content[streamContent].Schema == nil {
+		t.Fatalf("expected the events route to stream, got %+v", op)
+	}
+}
+
+func TestRenderPython(t *testing.T) {
+	files, err := RenderPython(BuildSpec())
+	if err != nil {
+		t.Fatalf("RenderPython: %v", err)
+	}
+	for _, name := range []string{"pyproject.toml", "orbitmesh/__init__.py", "orbitmesh/_base.py", "orbitmesh/client.py", "orbitmesh/aio.py", "orbitmesh/models.py", "orbitmesh/events.py"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+
+	client, aio := string(files["orbitmesh/client.py"]), string(files["orbitmesh/aio.py"])
+	for _, want := range []string{
+		"    def answer_question(\n        self,\n        id: str,\n        question_id: str,\n        body: models.AnswerQuestionRequest,\n    ) -> models.QuestionResponse:",
+		`f"/api/sessions/{_quote(id)}/questions/{_quote(question_id)}/answer"`,
+		"    def stream_session_events(\n        self,\n        id: str,\n        *,\n        types: Optional[List[str]] = None,\n        last_event_id: Optional[int] = None,",
+	} {
+		if !strings.Contains(client, want) {
+			t.Errorf("client.py is missing %q", want)
+		}
+	}
+	if !strings.Contains(aio, "    async def list_sessions(") || strings.Contains(aio, "async def stream_session_events(") {
+		t.Error("expected async request methods and plain stream methods in aio.py")
+	}
+	if !strings.Contains(string(files["orbitmesh/models.py"]), "class ErrorResponse(TypedDict):\n    code: str\n    details: NotRequired[Any]\n    error: str\n") {
+		t.Error("expected ErrorResponse to be rendered as a TypedDict")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"listSessions":  "list_sessions",
+		"questionID":    "question_id",
+		"MCPServers":    "mcp_servers",
+		"last_event_id": "last_event_id",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed all:templates
+var templates embed.FS
+
+// pyOp is an operation as the client templates render it.
+type pyOp struct {
+	Name       string
+	Method     string
+	Summary    string
+	Route      string
+	Path       string // f-string body with path parameters substituted
+	PathParams []pyParam
+	Query      []pyParam
+	Body       string // request body type, or "" without one
+	Result     string // response type, "None" without content
+	Stream     bool
+}
+
+type pyParam struct {
+	Name string // Python identifier
+	Key  string // name in the URL
+	Type string
+}
+
+type pyModel struct {
+	Name   string
+	Fields []pyField
+}
+
+type pyField struct {
+	Name     string
+	Type     string
+	Optional bool
+}
+
+// RenderPython returns the files of the Python package generated from doc,
+// keyed by their path relative to the package root.
+func RenderPython(doc *Document) (map[string][]byte, error) {
+	ops, err := pythonOps(doc)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]any{
+		"Version": doc.Info.Version,
+		"Models":  pythonModels(doc),
+		"Ops":     ops,
+	}
+
+	files := map[string][]byte{}
+	err = fs.WalkDir(templates, "templates", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		t, err := template.New(path.Base(name)).Delims("<%", "%>").ParseFS(templates, name)
+		if err != nil {
+			return err
+		}
+		out := strings.TrimSuffix(strings.TrimPrefix(name, "templates/"), ".tmpl")
+		if out != "orbitmesh/client.py" {
+			return renderFile(files, t, data, out)
+		}
+		// The sync and async clients share one template.
+		data["Async"] = false
+		if err := renderFile(files, t, data, out); err != nil {
+			return err
+		}
+		data["Async"] = true
+		return renderFile(files, t, data, "orbitmesh/aio.py")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func renderFile(files map[string][]byte, t *template.Template, data map[string]any, name string) error {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("render %s: %w", name, err)
+	}
+	files[name] = buf.Bytes()
+	return nil
+}
+
+func pythonModels(doc *Document) []pyModel {
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	models := make([]pyModel, 0, len(names))
+	for _, name := range names {
+		s := doc.Components.Schemas[name]
+		m := pyModel{Name: name}
+		fields := make([]string, 0, len(s.Properties))
+		for field := range s.Properties {
+			fields = append(fields, field)
+		}
+		slices.Sort(fields)
+		for _, field := range fields {
+			m.Fields = append(m.Fields, pyField{
+				Name:     field,
+				Type:     pyType(s.Properties[field], `"`, `"`),
+				Optional: !slices.Contains(s.Required, field),
+			})
+		}
+		models = append(models, m)
+	}
+	return models
+}
+
+func pythonOps(doc *Document) ([]pyOp, error) {
+	var ops []pyOp
+	for p, methods := range doc.Paths {
+		for method, op := range methods {
+			py := pyOp{
+				Name:    snakeCase(op.OperationID),
+				Method:  strings.ToUpper(method),
+				Summary: op.Summary,
+				Route:   p,
+				Path:    p,
+				Result:  "None",
+			}
+			for _, param := range op.Parameters {
+				pp := pyParam{Name: snakeCase(param.Name), Key: param.Name, Type: pyType(param.Schema, "models.", "")}
+				switch param.In {
+				case "path":
+					py.PathParams = append(py.PathParams, pp)
+					py.Path = strings.ReplaceAll(py.Path, "{"+param.Name+"}", "{_quote("+pp.Name+")}")
+				case "query":
+					py.Query = append(py.Query, pp)
+				default:
+					return nil, fmt.Errorf("%s: unsupported parameter location %q", op.OperationID, param.In)
+				}
+			}
+			if op.RequestBody != nil {
+				media, ok := op.RequestBody.Content[jsonContent]
+				if !ok {
+					return nil, fmt.Errorf("%s: request body is not JSON", op.OperationID)
+				}
+				py.Body = pyType(media.Schema, "models.", "")
+			}
+			for status, resp := range op.Responses {
+				if !strings.HasPrefix(status, "2") {
+					continue
+				}
+				if media, ok := resp.Content[streamContent]; ok {
+					py.Stream = true
+					py.Result = pyType(media.Schema, "models.", "")
+				} else if media, ok := resp.Content[jsonContent]; ok {
+					py.Result = pyType(media.Schema, "models.", "")
+				}
+			}
+			if py.Stream {
+				// Streams resume from last_event_id themselves.
+				py.Query = slices.DeleteFunc(py.Query, func(p pyParam) bool { return p.Key == "last_event_id" })
+			}
+			ops = append(ops, py)
+		}
+	}
+	slices.SortFunc(ops, func(a, b pyOp) int { return strings.Compare(a.Name, b.Name) })
+	return ops, nil
+}
+
+// pyType returns the Python annotation of a schema, naming component
+// schemas with refPrefix and refSuffix around them.
+func pyType(s *Schema, refPrefix, refSuffix string) string {
+	t := "Any"
+	switch {
+	case s.Ref != "":
+		t = refPrefix + strings.TrimPrefix(s.Ref, schemaPrefix) + refSuffix
+	case s.Type == "string":
+		t = "str"
+	case s.Type == "integer":
+		t = "int"
+	case s.Type == "number":
+		t = "float"
+	case s.Type == "boolean":
+		t = "bool"
+	case s.Type == "array" && s.Items != nil:
+		t = "List[" + pyType(s.Items, refPrefix, refSuffix) + "]"
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		t = "Dict[str, " + pyType(s.AdditionalProperties, refPrefix, refSuffix) + "]"
+	case s.Type == "object":
+		t = "Dict[str, Any]"
+	}
+	if s.Nullable && t != "Any" {
+		t = "Optional[" + t + "]"
+	}
+	return t
+}
+
+// snakeCase converts camelCase names such as "questionID" to snake_case.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && unicode.IsLower(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if prevLower || nextLower {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
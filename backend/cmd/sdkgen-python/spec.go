@@ -0,0 +1,399 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// Document is the subset of an OpenAPI 3 document the SDK generator
+// writes and reads.
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const (
+	jsonContent   = "application/json"
+	streamContent = "text/event-stream"
+	schemaPrefix  = "#/components/schemas/"
+)
+
+// endpoint is one API route the SDK covers. body and resp are zero values
+// of the pkg/api types it accepts and returns; a nil resp means no content.
+type endpoint struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	tag     string
+	query   []Parameter
+	body    any
+	resp    any
+	status  int
+	stream  bool
+}
+
+func query(name, typ string) Parameter {
+	return Parameter{Name: name, In: "query", Schema: &Schema{Type: typ}}
+}
+
+func queryList(name string) Parameter {
+	return Parameter{Name: name, In: "query", Schema: &Schema{Type: "array", Items: &Schema{Type: "string"}}}
+}
+
+// endpoints are the routes in the SDK, grouped as the API handler mounts
+// them. Add a route here when it should be callable from scripts.
+var endpoints = []endpoint{
+	{method: http.MethodGet, path: "/api/sessions", id: "listSessions", summary: "List sessions.", tag: "sessions",
+		query: []Parameter{query("project_id", "string"), query("pinned", "boolean"), query("mission_id", "string")}, resp: apiTypes.SessionListResponse{}},
+	{method: http.MethodPost, path: "/api/sessions", id: "createSession", summary: "Create a session.", tag: "sessions",
+		body: apiTypes.SessionRequest{}, resp: apiTypes.SessionResponse{}, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/api/sessions/dry-run", id: "dryRunSession", summary: "Resolve a session request without creating the session.", tag: "sessions",
+		body: apiTypes.SessionRequest{}, resp: apiTypes.SessionDryRunResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}", id: "getSession", summary: "Get a session with its live metrics.", tag: "sessions",
+		resp: apiTypes.SessionStatusResponse{}},
+	{method: http.MethodPatch, path: "/api/sessions/{id}", id: "updateSession", summary: "Update a session's title, pin or budget.", tag: "sessions",
+		body: apiTypes.SessionUpdateRequest{}, resp: apiTypes.SessionResponse{}},
+	{method: http.MethodDelete, path: "/api/sessions/{id}", id: "stopSession", summary: "Stop a session.", tag: "sessions",
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: "/api/sessions/{id}/cancel", id: "cancelSession", summary: "Cancel a session's current run.", tag: "sessions",
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: "/api/sessions/{id}/resume", id: "resumeSession", summary: "Resume a suspended session.", tag: "sessions",
+		body: apiTypes.ResumeSessionRequest{}, resp: apiTypes.SessionResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/plan/approve", id: "approvePlan", summary: "Approve the plan a session is waiting on.", tag: "sessions",
+		body: apiTypes.PlanApproveRequest{}, resp: apiTypes.SessionResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/attempts", id: "listRunAttempts", summary: "List a session's run attempts.", tag: "sessions",
+		resp: apiTypes.RunAttemptListResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/commits", id: "listSessionCommits", summary: "List the commits made on a session's branch.", tag: "sessions",
+		query: []Parameter{query("limit", "integer")}, resp: apiTypes.CommitListResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/diff", id: "getSessionDiff", summary: "Diff what a session changed in its working directory.", tag: "sessions",
+		resp: apiTypes.SessionDiffResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/pull-request", id: "openPullRequest", summary: "Push a session's branch and open a pull request for it.", tag: "sessions",
+		body: apiTypes.PullRequestRequest{}, resp: apiTypes.PullRequestResponse{}, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/api/sessions/{id}/input", id: "sendSessionInput", summary: "Send raw input to a session's terminal.", tag: "messages",
+		body: apiTypes.SessionInputRequest{}, status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/api/sessions/{id}/messages", id: "getSessionMessages", summary: "List a session's messages.", tag: "messages",
+		query: []Parameter{query("since", "string"), query("limit", "integer"), query("cursor", "string")}, resp: apiTypes.MessageListResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/messages", id: "sendSessionMessage", summary: "Send a message to a session, starting a run.", tag: "messages",
+		body: apiTypes.SendMessageRequest{}, resp: apiTypes.SendMessageResponse{}, status: http.StatusAccepted},
+	{method: http.MethodGet, path: "/api/sessions/{id}/queue", id: "listQueuedMessages", summary: "List messages queued for a session.", tag: "messages",
+		resp: apiTypes.QueuedMessageListResponse{}},
+	{method: http.MethodGet, path: "/api/search", id: "searchMessages", summary: "Search messages across sessions.", tag: "messages",
+		query: []Parameter{query("q", "string"), query("project_id", "string"), query("provider_type", "string"), query("limit", "integer")}, resp: apiTypes.MessageSearchResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/events", id: "streamSessionEvents", summary: "Stream a session's events.", tag: "events",
+		query: []Parameter{queryList("types"), query("last_event_id", "integer")}, resp: apiTypes.Event{}, stream: true},
+	{method: http.MethodGet, path: "/api/sessions/{id}/events/history", id: "getEventHistory", summary: "Read a session's persisted events.", tag: "events",
+		query: []Parameter{query("since_seq", "integer"), query("limit", "integer")}, resp: apiTypes.EventHistoryResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/activity", id: "getSessionActivity", summary: "Read a session's activity feed.", tag: "events",
+		query: []Parameter{query("cursor", "string"), query("limit", "integer")}, resp: apiTypes.ActivityHistoryResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/events", id: "streamSessionStates", summary: "Stream state changes of all sessions.", tag: "events",
+		resp: apiTypes.SessionStateEvent{}, stream: true},
+	{method: http.MethodGet, path: "/api/questions", id: "listPendingQuestions", summary: "List questions waiting for an answer.", tag: "approvals",
+		resp: apiTypes.QuestionListResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/questions", id: "listSessionQuestions", summary: "List a session's questions.", tag: "approvals",
+		resp: apiTypes.QuestionListResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/questions/{questionID}/answer", id: "answerQuestion", summary: "Answer a session's question.", tag: "approvals",
+		body: apiTypes.AnswerQuestionRequest{}, resp: apiTypes.QuestionResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/commands", id: "listSessionCommands", summary: "List commands a session proposed.", tag: "approvals",
+		resp: apiTypes.CommandListResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/commands/{commandID}/approve", id: "approveCommand", summary: "Approve a proposed command.", tag: "approvals",
+		resp: apiTypes.CommandResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/commands/{commandID}/reject", id: "rejectCommand", summary: "Reject a proposed command.", tag: "approvals",
+		body: apiTypes.RejectCommandRequest{}, resp: apiTypes.CommandResponse{}},
+	{method: http.MethodGet, path: "/api/sessions/{id}/approvals", id: "listToolApprovals", summary: "List a session's tool approvals.", tag: "approvals",
+		query: []Parameter{query("status", "string")}, resp: apiTypes.ToolApprovalListResponse{}},
+	{method: http.MethodPost, path: "/api/sessions/{id}/approvals/{approvalID}/decision", id: "decideToolApproval", summary: "Allow or deny a tool call.", tag: "approvals",
+		body: apiTypes.ToolApprovalDecisionRequest{}, resp: apiTypes.ToolApprovalResponse{}},
+	{method: http.MethodGet, path: "/api/v1/waits", id: "listWaits", summary: "List what sessions are waiting on.", tag: "approvals",
+		resp: apiTypes.WaitListResponse{}},
+	{method: http.MethodGet, path: "/api/v1/projects", id: "listProjects", summary: "List projects.", tag: "projects",
+		resp: apiTypes.ProjectListResponse{}},
+	{method: http.MethodPost, path: "/api/v1/projects", id: "createProject", summary: "Create a project.", tag: "projects",
+		body: apiTypes.ProjectRequest{}, resp: apiTypes.ProjectResponse{}, status: http.StatusCreated},
+	{method: http.MethodGet, path: "/api/v1/projects/{id}", id: "getProject", summary: "Get a project.", tag: "projects",
+		resp: apiTypes.ProjectResponse{}},
+	{method: http.MethodPut, path: "/api/v1/projects/{id}", id: "updateProject", summary: "Replace a project.", tag: "projects",
+		body: apiTypes.ProjectRequest{}, resp: apiTypes.ProjectResponse{}},
+	{method: http.MethodDelete, path: "/api/v1/projects/{id}", id: "deleteProject", summary: "Delete a project.", tag: "projects",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/api/v1/projects/{id}/usage", id: "getProjectUsage", summary: "Report a project's token and cost usage.", tag: "projects",
+		resp: apiTypes.ProjectUsageResponse{}},
+	{method: http.MethodGet, path: "/api/v1/providers", id: "listProviders", summary: "List provider configs.", tag: "providers",
+		resp: apiTypes.ProviderConfigListResponse{}},
+	{method: http.MethodGet, path: "/api/v1/providers/{id}", id: "getProvider", summary: "Get a provider config.", tag: "providers",
+		resp: apiTypes.ProviderConfigResponse{}},
+	{method: http.MethodGet, path: "/api/v1/agents", id: "listAgents", summary: "List agent configs.", tag: "agents",
+		resp: apiTypes.AgentConfigListResponse{}},
+	{method: http.MethodGet, path: "/api/v1/agents/{id}", id: "getAgent", summary: "Get an agent config.", tag: "agents",
+		resp: apiTypes.AgentConfigResponse{}},
+	{method: http.MethodGet, path: "/api/v1/schedules", id: "listSchedules", summary: "List schedules.", tag: "schedules",
+		resp: apiTypes.ScheduleListResponse{}},
+	{method: http.MethodPost, path: "/api/v1/schedules", id: "createSchedule", summary: "Create a schedule.", tag: "schedules",
+		body: apiTypes.ScheduleRequest{}, resp: apiTypes.ScheduleResponse{}, status: http.StatusCreated},
+	{method: http.MethodPost, path: "/api/v1/schedules/{id}/run", id: "runSchedule", summary: "Run a schedule now.", tag: "schedules",
+		resp: apiTypes.ScheduleRun{}},
+	{method: http.MethodGet, path: "/api/v1/missions", id: "listMissions", summary: "List missions.", tag: "missions",
+		resp: apiTypes.MissionListResponse{}},
+	{method: http.MethodPost, path: "/api/v1/missions", id: "createMission", summary: "Create a mission.", tag: "missions",
+		body: apiTypes.MissionRequest{}, resp: apiTypes.MissionResponse{}, status: http.StatusCreated},
+	{method: http.MethodGet, path: "/api/v1/missions/{id}", id: "getMission", summary: "Get a mission with its sessions and spend.", tag: "missions",
+		resp: apiTypes.MissionResponse{}},
+	{method: http.MethodPut, path: "/api/v1/missions/{id}", id: "updateMission", summary: "Replace a mission's settings.", tag: "missions",
+		body: apiTypes.MissionRequest{}, resp: apiTypes.MissionResponse{}},
+	{method: http.MethodDelete, path: "/api/v1/missions/{id}", id: "deleteMission", summary: "Delete a mission, keeping its sessions.", tag: "missions",
+		status: http.StatusNoContent},
+	{method: http.MethodPost, path: "/api/v1/missions/{id}/sessions", id: "addMissionSession", summary: "Add a session to a mission.", tag: "missions",
+		body: apiTypes.MissionSessionRequest{}, resp: apiTypes.MissionResponse{}},
+	{method: http.MethodDelete, path: "/api/v1/missions/{id}/sessions/{sessionID}", id: "removeMissionSession", summary: "Remove a session from a mission.", tag: "missions",
+		resp: apiTypes.MissionResponse{}},
+	{method: http.MethodGet, path: "/api/v1/missions/{id}/log", id: "getMissionLog", summary: "Read a mission's shared log.", tag: "missions",
+		resp: apiTypes.MissionLogResponse{}},
+	{method: http.MethodPost, path: "/api/v1/missions/{id}/log", id: "appendMissionLog", summary: "Add an entry to a mission's log.", tag: "missions",
+		body: apiTypes.MissionLogRequest{}, resp: apiTypes.MissionLogEntry{}, status: http.StatusCreated},
+	{method: http.MethodGet, path: "/api/v1/pipelines", id: "listPipelines", summary: "List pipelines.", tag: "pipelines",
+		resp: apiTypes.PipelineListResponse{}},
+	{method: http.MethodPost, path: "/api/v1/pipelines", id: "createPipeline", summary: "Create a pipeline.", tag: "pipelines",
+		body: apiTypes.PipelineRequest{}, resp: apiTypes.PipelineResponse{}, status: http.StatusCreated},
+	{method: http.MethodGet, path: "/api/v1/pipelines/{id}", id: "getPipeline", summary: "Get a pipeline with its latest run.", tag: "pipelines",
+		resp: apiTypes.PipelineResponse{}},
+	{method: http.MethodPut, path: "/api/v1/pipelines/{id}", id: "updatePipeline", summary: "Replace a pipeline's steps.", tag: "pipelines",
+		body: apiTypes.PipelineRequest{}, resp: apiTypes.PipelineResponse{}},
+	{method: http.MethodDelete, path: "/api/v1/pipelines/{id}", id: "deletePipeline", summary: "Delete a pipeline, keeping its sessions.", tag: "pipelines",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/api/v1/pipelines/{id}/runs", id: "listPipelineRuns", summary: "List a pipeline's latest runs.", tag: "pipelines",
+		resp: apiTypes.PipelineRunListResponse{}},
+	{method: http.MethodPost, path: "/api/v1/pipelines/{id}/runs", id: "startPipelineRun", summary: "Start a run of a pipeline.", tag: "pipelines",
+		body: apiTypes.PipelineRunRequest{}, resp: apiTypes.PipelineRunResponse{}, status: http.StatusAccepted},
+	{method: http.MethodGet, path: "/api/v1/pipelines/{id}/runs/{runID}", id: "getPipelineRun", summary: "Get a pipeline run and its steps.", tag: "pipelines",
+		resp: apiTypes.PipelineRunResponse{}},
+	{method: http.MethodGet, path: "/api/v1/templates", id: "listTemplates", summary: "List prompt templates.", tag: "templates",
+		resp: apiTypes.TemplateListResponse{}},
+	{method: http.MethodPost, path: "/api/v1/templates", id: "createTemplate", summary: "Create a prompt template.", tag: "templates",
+		body: apiTypes.TemplateRequest{}, resp: apiTypes.TemplateResponse{}, status: http.StatusCreated},
+	{method: http.MethodGet, path: "/api/v1/templates/{id}", id: "getTemplate", summary: "Get a prompt template.", tag: "templates",
+		resp: apiTypes.TemplateResponse{}},
+	{method: http.MethodPut, path: "/api/v1/templates/{id}", id: "updateTemplate", summary: "Replace a prompt template.", tag: "templates",
+		body: apiTypes.TemplateRequest{}, resp: apiTypes.TemplateResponse{}},
+	{method: http.MethodDelete, path: "/api/v1/templates/{id}", id: "deleteTemplate", summary: "Delete a prompt template.", tag: "templates",
+		status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/api/v1/tasks/tree", id: "getTaskTree", summary: "Get the task tree.", tag: "tasks",
+		resp: apiTypes.TaskTreeResponse{}},
+	{method: http.MethodGet, path: "/api/v1/tasks/{id}/history", id: "getTaskHistory", summary: "List the journaled changes of a task.", tag: "tasks",
+		resp: apiTypes.TaskHistoryResponse{}},
+	{method: http.MethodPost, path: "/api/v1/tasks/changes/undo", id: "undoTaskChange", summary: "Undo the latest journaled task change.", tag: "tasks",
+		body: apiTypes.TaskUndoRequest{}, resp: apiTypes.TaskChange{}},
+}
+
+// BuildSpec describes endpoints as an OpenAPI document, with the pkg/api
+// types they use as component schemas.
+func BuildSpec() *Document {
+	doc := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: "OrbitMesh API", Version: sdkVersion},
+		Paths:      map[string]map[string]Operation{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	schemas := schemaBuilder{schemas: doc.Components.Schemas}
+	schemas.schemaFor(reflect.TypeOf(apiTypes.ErrorResponse{}))
+
+	for _, ep := range endpoints {
+		op := Operation{
+			OperationID: ep.id,
+			Summary:     ep.summary,
+			Tags:        []string{ep.tag},
+			Responses:   map[string]Response{},
+		}
+		for _, name := range pathParams(ep.path) {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		op.Parameters = append(op.Parameters, ep.query...)
+		if ep.body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{jsonContent: {Schema: schemas.schemaFor(reflect.TypeOf(ep.body))}},
+			}
+		}
+
+		status := ep.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		resp := Response{Description: http.StatusText(status)}
+		if ep.resp != nil {
+			contentType := jsonContent
+			if ep.stream {
+				contentType = streamContent
+			}
+			resp.Content = map[string]MediaType{contentType: {Schema: schemas.schemaFor(reflect.TypeOf(ep.resp))}}
+		}
+		op.Responses[fmt.Sprint(status)] = resp
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{jsonContent: {Schema: &Schema{Ref: schemaPrefix + "ErrorResponse"}}},
+		}
+
+		if doc.Paths[ep.path] == nil {
+			doc.Paths[ep.path] = map[string]Operation{}
+		}
+		doc.Paths[ep.path][strings.ToLower(ep.method)] = op
+	}
+	return doc
+}
+
+// pathParams returns the {name} parameters of a chi route pattern.
+func pathParams(path string) []string {
+	var names []string
+	for _, part := range strings.Split(path, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			names = append(names, part[1:len(part)-1])
+		}
+	}
+	return names
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaBuilder converts Go types to schemas as encoding/json marshals
+// them, registering named structs as components.
+type schemaBuilder struct {
+	schemas map[string]*Schema
+}
+
+func (b schemaBuilder) schemaFor(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := *b.schemaFor(t.Elem())
+		s.Nullable = true
+		return &s
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.schemas[t.Name()]; !ok {
+			// Registered before its fields so recursive types terminate.
+			b.schemas[t.Name()] = &Schema{}
+			*b.schemas[t.Name()] = *b.structSchema(t)
+		}
+		return &Schema{Ref: schemaPrefix + t.Name()}
+	}
+	return &Schema{}
+}
+
+func (b schemaBuilder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(s, t)
+	return s
+}
+
+// addFields adds the JSON fields of struct t to s, flattening embedded
+// structs as encoding/json does.
+func (b schemaBuilder) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = b.schemaFor(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
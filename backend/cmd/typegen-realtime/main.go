@@ -29,6 +29,7 @@ func main() {
 				Path:             "github.com/ricochet1k/orbitmesh/pkg/realtime",
 				OutputPath:       outputPath,
 				PreserveComments: "none",
+				ExcludeFiles:     []string{"decode.go"},
 			},
 		},
 	})
@@ -29,7 +29,7 @@ func (h *Handler) getSessionActivity(w http.ResponseWriter, r *http.Request) {
 
 	if _, err := h.executor.GetSession(sessionID); err != nil {
 		if errors.Is(err, service.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to look up session", err.Error())
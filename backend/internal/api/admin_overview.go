@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// buildVersion returns the module version and VCS revision the server was
+// built from.
+func buildVersion() (version, revision string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown", ""
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			revision = s.Value
+		}
+	}
+	return info.Main.Version, revision
+}
+
+// getAdminOverview returns instance-level information for an operations
+// dashboard in one call.
+func (h *Handler) getAdminOverview(w http.ResponseWriter, r *http.Request) {
+	overview := h.executor.InstanceOverview()
+	resp := apiTypes.AdminOverviewResponse{
+		StartedAt:         overview.StartedAt,
+		UptimeSeconds:     int64(time.Since(overview.StartedAt).Seconds()),
+		ActiveRuns:        overview.ActiveRuns,
+		QueueDepth:        overview.QueueDepth,
+		DegradedProviders: make([]apiTypes.DegradedProvider, 0, len(overview.DegradedProviders)),
+		PendingApprovals:  overview.PendingApprovals,
+		PendingQuestions:  overview.PendingQuestions,
+	}
+	resp.Version, resp.Revision = buildVersion()
+	for _, p := range overview.DegradedProviders {
+		resp.DegradedProviders = append(resp.DegradedProviders, apiTypes.DegradedProvider{
+			ProviderType:        p.ProviderType,
+			ConsecutiveFailures: p.ConsecutiveFailures,
+			LastError:           p.LastError,
+			LastFailureAt:       p.LastFailureAt,
+		})
+	}
+	if reporter, ok := h.sessionStorage.(storage.UsageReporter); ok {
+		usage, err := reporter.Usage()
+		if err != nil {
+			log.Printf("admin overview: storage usage: %v", err)
+		} else {
+			resp.StorageBytes = usage
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestGetAdminOverview(t *testing.T) {
+	env := newTestEnv(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/overview", nil)
+	w := httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var raw map[string]json.RawMessage
+	_ = json.Unmarshal(w.Body.Bytes(), &raw)
+	if string(raw["degraded_providers"]) != "[]" {
+		t.Errorf("degraded_providers = %s, want []", raw["degraded_providers"])
+	}
+	var resp apiTypes.AdminOverviewResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Version == "" || resp.StartedAt.IsZero() || resp.ActiveRuns != 0 {
+		t.Errorf("overview = %+v", resp)
+	}
+}
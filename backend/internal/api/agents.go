@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"sort"
 
 	"github.com/go-chi/chi/v5"
 
@@ -15,7 +16,7 @@ import (
 
 func (h *Handler) listAgents(w http.ResponseWriter, r *http.Request) {
 	if h.agentStorage == nil {
-		writeError(w, http.StatusServiceUnavailable, "agent storage not configured", "")
+		writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, "agent storage not configured", "")
 		return
 	}
 	configs, err := h.agentStorage.List()
@@ -38,14 +39,14 @@ func (h *Handler) listAgents(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) getAgent(w http.ResponseWriter, r *http.Request) {
 	if h.agentStorage == nil {
-		writeError(w, http.StatusServiceUnavailable, "agent storage not configured", "")
+		writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, "agent storage not configured", "")
 		return
 	}
 	id := chi.URLParam(r, "id")
 
 	cfg, err := h.agentStorage.Get(id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "agent not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeAgentNotFound, "agent not found", err.Error())
 		return
 	}
 
@@ -54,9 +55,50 @@ func (h *Handler) getAgent(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(agentConfigToResponse(*cfg))
 }
 
+func (h *Handler) getAgentToolStats(w http.ResponseWriter, r *http.Request) {
+	if h.agentStorage == nil {
+		writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, "agent storage not configured", "")
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	if _, err := h.agentStorage.Get(id); err != nil {
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeAgentNotFound, "agent not found", err.Error())
+		return
+	}
+
+	stats := h.executor.AgentToolStats(id)
+	tools := make([]apiTypes.ToolStat, 0, len(stats))
+	for name, stat := range stats {
+		tool := apiTypes.ToolStat{
+			Name:       name,
+			Calls:      stat.Calls,
+			Failures:   stat.Failures,
+			LastUsedAt: stat.LastUsedAt,
+		}
+		if stat.Calls > 0 {
+			tool.FailureRate = float64(stat.Failures) / float64(stat.Calls)
+		}
+		if stat.LatencySamples > 0 {
+			tool.AvgLatencyMS = stat.TotalLatencyMS / stat.LatencySamples
+		}
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Calls != tools[j].Calls {
+			return tools[i].Calls > tools[j].Calls
+		}
+		return tools[i].Name < tools[j].Name
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(apiTypes.AgentToolStatsResponse{AgentID: id, Tools: tools})
+}
+
 func (h *Handler) createAgent(w http.ResponseWriter, r *http.Request) {
 	if h.agentStorage == nil {
-		writeError(w, http.StatusServiceUnavailable, "agent storage not configured", "")
+		writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, "agent storage not configured", "")
 		return
 	}
 	var req apiTypes.AgentConfigRequest
@@ -69,6 +111,14 @@ func (h *Handler) createAgent(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "name is required", "")
 		return
 	}
+	if err := session.ValidateFeatures(req.Features); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid features", err.Error())
+		return
+	}
+	if err := session.ValidateMCPServers(mcpServersFromAPI(req.MCPServers)); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid mcp_servers", err.Error())
+		return
+	}
 
 	id := req.ID
 	if id == "" {
@@ -76,11 +126,18 @@ func (h *Handler) createAgent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	cfg := storage.AgentConfig{
-		ID:           id,
-		Name:         req.Name,
-		SystemPrompt: req.SystemPrompt,
-		MCPServers:   mcpServersFromAPI(req.MCPServers),
-		Custom:       req.Custom,
+		ID:              id,
+		Name:            req.Name,
+		SystemPrompt:    req.SystemPrompt,
+		MCPServers:      mcpServersFromAPI(req.MCPServers),
+		Custom:          req.Custom,
+		CleanupCommands: cleanupCommandsFromAPI(req.CleanupCommands),
+		Features:        req.Features,
+		ToolPolicy:      toolPolicyFromAPI(req.ToolPolicy),
+	}
+	if err := h.executor.SetAgentToolPolicy(cfg.ID, cfg.ToolPolicy); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tool_policy", err.Error())
+		return
 	}
 
 	if err := h.agentStorage.Save(cfg); err != nil {
@@ -95,7 +152,7 @@ func (h *Handler) createAgent(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) updateAgent(w http.ResponseWriter, r *http.Request) {
 	if h.agentStorage == nil {
-		writeError(w, http.StatusServiceUnavailable, "agent storage not configured", "")
+		writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, "agent storage not configured", "")
 		return
 	}
 	id := chi.URLParam(r, "id")
@@ -110,13 +167,28 @@ func (h *Handler) updateAgent(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "name is required", "")
 		return
 	}
+	if err := session.ValidateFeatures(req.Features); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid features", err.Error())
+		return
+	}
+	if err := session.ValidateMCPServers(mcpServersFromAPI(req.MCPServers)); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid mcp_servers", err.Error())
+		return
+	}
 
 	cfg := storage.AgentConfig{
-		ID:           id,
-		Name:         req.Name,
-		SystemPrompt: req.SystemPrompt,
-		MCPServers:   mcpServersFromAPI(req.MCPServers),
-		Custom:       req.Custom,
+		ID:              id,
+		Name:            req.Name,
+		SystemPrompt:    req.SystemPrompt,
+		MCPServers:      mcpServersFromAPI(req.MCPServers),
+		Custom:          req.Custom,
+		CleanupCommands: cleanupCommandsFromAPI(req.CleanupCommands),
+		Features:        req.Features,
+		ToolPolicy:      toolPolicyFromAPI(req.ToolPolicy),
+	}
+	if err := h.executor.SetAgentToolPolicy(cfg.ID, cfg.ToolPolicy); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid tool_policy", err.Error())
+		return
 	}
 
 	if err := h.agentStorage.Save(cfg); err != nil {
@@ -131,15 +203,16 @@ func (h *Handler) updateAgent(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) deleteAgent(w http.ResponseWriter, r *http.Request) {
 	if h.agentStorage == nil {
-		writeError(w, http.StatusServiceUnavailable, "agent storage not configured", "")
+		writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, "agent storage not configured", "")
 		return
 	}
 	id := chi.URLParam(r, "id")
 
 	if err := h.agentStorage.Delete(id); err != nil {
-		writeError(w, http.StatusNotFound, "agent not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeAgentNotFound, "agent not found", err.Error())
 		return
 	}
+	_ = h.executor.SetAgentToolPolicy(id, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -161,11 +234,14 @@ func agentConfigToResponse(cfg storage.AgentConfig) apiTypes.AgentConfigResponse
 		}
 	}
 	return apiTypes.AgentConfigResponse{
-		ID:           cfg.ID,
-		Name:         cfg.Name,
-		SystemPrompt: cfg.SystemPrompt,
-		MCPServers:   servers,
-		Custom:       cfg.Custom,
+		ID:              cfg.ID,
+		Name:            cfg.Name,
+		SystemPrompt:    cfg.SystemPrompt,
+		MCPServers:      servers,
+		Custom:          cfg.Custom,
+		CleanupCommands: cfg.CleanupCommands,
+		Features:        cfg.Features,
+		ToolPolicy:      toolPolicyToAPI(cfg.ToolPolicy),
 	}
 }
 
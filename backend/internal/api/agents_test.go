@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -20,6 +21,8 @@ func newTestEnvWithAgents(t *testing.T) (*testEnv, *storage.AgentConfigStorage)
 		broadcaster: service.NewEventBroadcaster(100),
 	}
 	store := newInMemStore()
+	providerStorage := storage.NewProviderConfigStorage(t.TempDir())
+	agentStorage := storage.NewAgentConfigStorage(t.TempDir())
 	env.executor = service.NewAgentExecutor(service.ExecutorConfig{
 		Storage:         store,
 		TerminalStorage: store,
@@ -28,10 +31,9 @@ func newTestEnvWithAgents(t *testing.T) (*testEnv, *storage.AgentConfigStorage)
 			env.lastMock = newMockProvider()
 			return env.lastMock, nil
 		},
+		ProviderConfigs: providerStorage,
+		AgentConfigs:    agentStorage,
 	})
-
-	providerStorage := storage.NewProviderConfigStorage(t.TempDir())
-	agentStorage := storage.NewAgentConfigStorage(t.TempDir())
 	env.handler = NewHandler(env.executor, env.broadcaster, store, providerStorage, agentStorage, nil)
 	return env, agentStorage
 }
@@ -113,6 +115,36 @@ func TestCreateAgent_MissingName(t *testing.T) {
 	}
 }
 
+func TestCreateAgent_ToolPolicy(t *testing.T) {
+	env, _ := newTestEnvWithAgents(t)
+	r := env.router()
+
+	post := func(policy *apiTypes.ToolPolicy) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(apiTypes.AgentConfigRequest{Name: "Reviewer", ToolPolicy: policy})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/agents", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post(&apiTypes.ToolPolicy{DenyCommands: []string{"("}}); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid policy: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w := post(&apiTypes.ToolPolicy{Allow: []string{"Read"}, DenyCommands: []string{`^git push`}, Default: "deny"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.AgentConfigResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if resp.ToolPolicy == nil || resp.ToolPolicy.Default != "deny" || len(resp.ToolPolicy.DenyCommands) != 1 {
+		t.Fatalf("ToolPolicy: %+v", resp.ToolPolicy)
+	}
+}
+
 func TestGetAgent_OK(t *testing.T) {
 	env, agentStorage := newTestEnvWithAgents(t)
 	r := env.router()
@@ -273,6 +305,98 @@ func TestCreateSession_WithAgentID(t *testing.T) {
 	}
 }
 
+func TestCreateSession_WithAgentCleanupCommands(t *testing.T) {
+	env, agentStorage := newTestEnvWithAgents(t)
+	r := env.router()
+
+	_ = agentStorage.Save(storage.AgentConfig{
+		ID:              "agent_002",
+		Name:            "Service Runner",
+		CleanupCommands: []string{"docker compose down"},
+	})
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   t.TempDir(),
+		AgentID:      "agent_002",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	sess, err := env.executor.GetSession(resp.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if got := sess.CleanupCommands; len(got) != 1 || got[0] != "docker compose down" {
+		t.Errorf("CleanupCommands: got %q", got)
+	}
+}
+
+func TestCreateSession_WithAgentFeatures(t *testing.T) {
+	env, agentStorage := newTestEnvWithAgents(t)
+	r := env.router()
+
+	_ = agentStorage.Save(storage.AgentConfig{
+		ID:   "agent_003",
+		Name: "Offline Coder",
+		Features: map[string]bool{
+			session.FeatureNetworkTools: false,
+			session.FeatureVerboseTools: true,
+		},
+	})
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   t.TempDir(),
+		AgentID:      "agent_003",
+		Features:     map[string]bool{session.FeatureVerboseTools: false},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	want := map[string]bool{session.FeatureNetworkTools: false, session.FeatureVerboseTools: false}
+	if !maps.Equal(resp.Features, want) {
+		t.Errorf("Features: got %v, want %v", resp.Features, want)
+	}
+}
+
+func TestCreateSession_UnknownFeature(t *testing.T) {
+	env, _ := newTestEnvWithAgents(t)
+	r := env.router()
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   t.TempDir(),
+		Features:     map[string]bool{"teleport": true},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 func TestCreateSession_WithAgentID_NotFound(t *testing.T) {
 	env, _ := newTestEnvWithAgents(t)
 	r := env.router()
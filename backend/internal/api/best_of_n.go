@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// startBestOfN runs one prompt as several parallel candidate sessions, each
+// in its own git worktree of the session's working directory.
+func (h *Handler) startBestOfN(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.BestOfNRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	specs := make([]service.BestOfNCandidateSpec, 0, max(len(req.Candidates), req.N))
+	for _, c := range req.Candidates {
+		specs = append(specs, service.BestOfNCandidateSpec{ProviderType: c.ProviderType, ProviderID: c.ProviderID})
+	}
+	if len(specs) == 0 {
+		for i := 0; i < req.N && i <= service.MaxBestOfNCandidates; i++ {
+			specs = append(specs, service.BestOfNCandidateSpec{})
+		}
+	}
+
+	id := chi.URLParam(r, "id")
+	group, err := h.executor.StartBestOfN(r.Context(), id, req.Prompt, specs)
+	if err != nil {
+		writeBestOfNError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(bestOfNToAPI(group, nil))
+}
+
+// getBestOfN returns the session's best-of-N group and, while it runs, what
+// each candidate has changed so far.
+func (h *Handler) getBestOfN(w http.ResponseWriter, r *http.Request) {
+	group, comparisons, err := h.executor.CompareBestOfN(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeBestOfNError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(bestOfNToAPI(group, comparisons))
+}
+
+// selectBestOfN merges the chosen candidate into the session's working
+// directory. The choice may come from a user or from an evaluator agent
+// calling the API.
+func (h *Handler) selectBestOfN(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.BestOfNSelectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.SessionID == "" {
+		writeError(w, http.StatusBadRequest, "session_id is required", "")
+		return
+	}
+
+	group, err := h.executor.SelectBestOfN(r.Context(), chi.URLParam(r, "id"), req.SessionID, requestUser(r))
+	if err != nil {
+		writeBestOfNError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(bestOfNToAPI(group, nil))
+}
+
+func (h *Handler) discardBestOfN(w http.ResponseWriter, r *http.Request) {
+	group, err := h.executor.DiscardBestOfN(r.Context(), chi.URLParam(r, "id"), requestUser(r))
+	if err != nil {
+		writeBestOfNError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(bestOfNToAPI(group, nil))
+}
+
+func writeBestOfNError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBestOfN), errors.Is(err, service.ErrNotCandidate):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, service.ErrNoBestOfN):
+		writeError(w, http.StatusNotFound, err.Error(), "")
+	case errors.Is(err, service.ErrMergeConflict):
+		writeError(w, http.StatusConflict, "merge conflict", err.Error())
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+func bestOfNToAPI(group *domain.BestOfN, comparisons []service.BestOfNComparison) apiTypes.BestOfNResponse {
+	byID := make(map[string]service.BestOfNComparison, len(comparisons))
+	for _, c := range comparisons {
+		byID[c.SessionID] = c
+	}
+	resp := apiTypes.BestOfNResponse{
+		Prompt:     group.Prompt,
+		BaseRef:    group.BaseRef,
+		Status:     group.Status,
+		CreatedAt:  group.CreatedAt,
+		WinnerID:   group.WinnerID,
+		DecidedBy:  group.DecidedBy,
+		DecidedAt:  group.DecidedAt,
+		Candidates: make([]apiTypes.BestOfNCandidate, 0, len(group.Candidates)),
+	}
+	for _, c := range group.Candidates {
+		out := apiTypes.BestOfNCandidate{
+			SessionID:    c.SessionID,
+			ProviderType: c.ProviderType,
+			ProviderID:   c.ProviderID,
+			Branch:       c.Branch,
+		}
+		if cmp, ok := byID[c.SessionID]; ok {
+			out.State = cmp.State.String()
+			out.Files = cmp.Files
+			out.Insertions = cmp.Insertions
+			out.Deletions = cmp.Deletions
+			out.DiffStat = cmp.DiffStat
+			out.LastOutput = cmp.LastOutput
+			out.Error = cmp.Error
+		}
+		resp.Candidates = append(resp.Candidates, out)
+	}
+	return resp
+}
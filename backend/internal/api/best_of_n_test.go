@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestBestOfN_SelectMergesWinner(t *testing.T) {
+	repoDir, _ := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+	parent := createSession(t, r, "mock", repoDir)
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, "/api/sessions/"+parent.ID+path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodPost, "/best-of-n", apiTypes.BestOfNRequest{Prompt: "fix it", N: 1}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a single candidate, got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/best-of-n", apiTypes.BestOfNRequest{Prompt: "fix it", N: 2})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var group apiTypes.BestOfNResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &group)
+	if len(group.Candidates) != 2 || group.BaseRef == "" {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+	if w := do(http.MethodPost, "/best-of-n", apiTypes.BestOfNRequest{Prompt: "again", N: 2}); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 while a group runs, got %d", w.Code)
+	}
+
+	// Stand in for the agents: each candidate writes its own answer, then
+	// its run is cancelled.
+	worktrees := make([]string, 0, 2)
+	for i, c := range group.Candidates {
+		sess, err := env.executor.GetSession(c.SessionID)
+		if err != nil {
+			t.Fatalf("candidate session: %v", err)
+		}
+		dir := sess.Snapshot().WorkingDir
+		worktrees = append(worktrees, dir)
+		if err := os.WriteFile(filepath.Join(dir, "answer.txt"), []byte{byte('a' + i), '\n'}, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		waitForState(t, sess, domain.SessionStateRunning)
+		if err := env.executor.CancelRun(context.Background(), c.SessionID); err != nil {
+			t.Fatalf("CancelRun: %v", err)
+		}
+		waitForState(t, sess, domain.SessionStateIdle)
+	}
+
+	w = do(http.MethodGet, "/best-of-n", nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &group)
+	for _, c := range group.Candidates {
+		if len(c.Files) != 1 || c.Files[0] != "answer.txt" || c.Insertions != 1 {
+			t.Fatalf("expected each candidate to report answer.txt, got %+v", c)
+		}
+	}
+
+	winner := group.Candidates[1].SessionID
+	if w := do(http.MethodPost, "/best-of-n/select", apiTypes.BestOfNSelectRequest{SessionID: "nope"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a non-candidate, got %d", w.Code)
+	}
+	w = do(http.MethodPost, "/best-of-n/select", apiTypes.BestOfNSelectRequest{SessionID: winner})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &group)
+	if group.Status != domain.BestOfNMerged || group.WinnerID != winner {
+		t.Fatalf("unexpected group after select: %+v", group)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoDir, "answer.txt")); err != nil || string(data) != "b\n" {
+		t.Fatalf("expected the winner's answer merged into the repo, got %q (%v)", data, err)
+	}
+	for _, dir := range worktrees {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("expected worktree %s to be removed", dir)
+		}
+	}
+	if out := runGit(t, repoDir, "branch", "--list", "orbitmesh/*"); out != "" {
+		t.Errorf("expected candidate branches to be deleted, got %q", out)
+	}
+}
+
+func waitForState(t *testing.T, sess *domain.Session, want domain.SessionState) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for sess.GetState() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("session %s is %s, want %s", sess.ID, sess.GetState(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) getCleanupStatus(w http.ResponseWriter, r *http.Request) {
+	policy := h.executor.CleanupPolicy()
+	action := string(policy.Action)
+	if action == "" {
+		action = string(service.CleanupActionArchive)
+	}
+
+	resp := apiTypes.CleanupStatusResponse{
+		Policy: apiTypes.CleanupPolicy{
+			MaxIdleSeconds:  int64(policy.MaxIdle.Seconds()),
+			Action:          action,
+			IntervalSeconds: int64(policy.Interval.Seconds()),
+		},
+	}
+	if report := h.executor.LastCleanupReport(); report != nil {
+		converted := cleanupReportToAPI(report)
+		resp.LastReport = &converted
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// runCleanup triggers an immediate janitor pass. Pass ?dry_run=true to see
+// what would be removed without touching storage.
+func (h *Handler) runCleanup(w http.ResponseWriter, r *http.Request) {
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	report, err := h.executor.RunCleanup(r.Context(), dryRun)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "cleanup failed", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(cleanupReportToAPI(report))
+}
+
+// getRecoveryReport returns the current or most recent startup recovery
+// report.
+func (h *Handler) getRecoveryReport(w http.ResponseWriter, r *http.Request) {
+	report := presentation.RecoveryReport(h.executor.RecoveryReport())
+	if report == nil {
+		writeError(w, http.StatusNotFound, "no recovery report", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+// getWarmPoolStats reports provider warm pool activity.
+func (h *Handler) getWarmPoolStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(apiTypes.WarmPoolStats(h.executor.WarmPoolStats()))
+}
+
+func cleanupReportToAPI(report *service.CleanupReport) apiTypes.CleanupReport {
+	return apiTypes.CleanupReport{
+		StartedAt:           report.StartedAt,
+		FinishedAt:          report.FinishedAt,
+		DryRun:              report.DryRun,
+		ArchivedSessions:    report.ArchivedSessions,
+		DeletedSessions:     report.DeletedSessions,
+		OrphanedTerminals:   report.OrphanedTerminals,
+		ExpiredResumeTokens: report.ExpiredResumeTokens,
+		DanglingAttempts:    report.DanglingAttempts,
+		Errors:              report.Errors,
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// maxCommandWait caps how long GET .../commands/{commandID}?wait= holds a
+// request open for a decision.
+const maxCommandWait = time.Minute
+
+func commandToResponse(sessionID string, c domain.CommandRecord) apiTypes.CommandResponse {
+	return apiTypes.CommandResponse{
+		ID:          c.ID,
+		SessionID:   sessionID,
+		ToolCallID:  c.ToolCallID,
+		Command:     c.Command,
+		Status:      apiTypes.CommandStatus(c.Status),
+		ProposedAt:  c.ProposedAt,
+		Approval:    string(c.Approval),
+		DecidedBy:   c.DecidedBy,
+		DecidedAt:   c.DecidedAt,
+		Reason:      c.Reason,
+		StartedAt:   c.StartedAt,
+		CompletedAt: c.CompletedAt,
+		ExitCode:    c.ExitCode,
+		DurationMS:  c.DurationMS,
+	}
+}
+
+func writeCommand(w http.ResponseWriter, status int, sessionID string, c domain.CommandRecord) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(commandToResponse(sessionID, c))
+}
+
+func writeCommandError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCommand):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, service.ErrCommandNotFound):
+		writeError(w, http.StatusNotFound, "command not found", "")
+	case errors.Is(err, service.ErrCommandDecided):
+		writeError(w, http.StatusConflict, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+// listSessionCommands returns the shell commands the session's agent ran
+// or proposed, oldest first.
+func (h *Handler) listSessionCommands(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	commands, err := h.executor.SessionCommands(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	resp := apiTypes.CommandListResponse{Commands: make([]apiTypes.CommandResponse, 0, len(commands))}
+	for _, c := range commands {
+		resp.Commands = append(resp.Commands, commandToResponse(id, c))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) proposeCommand(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.ProposeCommandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	c, err := h.executor.ProposeCommand(id, req.ToolCallID, req.Command)
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	writeCommand(w, http.StatusCreated, id, c)
+}
+
+// getCommand returns a command. With ?wait=<duration> a proposed command is
+// held until it is decided or the wait runs out.
+func (h *Handler) getCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if raw := r.URL.Query().Get("wait"); raw != "" {
+		wait, err := time.ParseDuration(raw)
+		if err != nil || wait < 0 {
+			writeError(w, http.StatusBadRequest, "invalid wait duration", raw)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, min(wait, maxCommandWait))
+		defer cancel()
+	}
+
+	id := chi.URLParam(r, "id")
+	c, err := h.executor.WaitForCommand(ctx, id, chi.URLParam(r, "commandID"))
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	writeCommand(w, http.StatusOK, id, c)
+}
+
+func (h *Handler) approveCommand(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	c, err := h.executor.DecideCommand(id, chi.URLParam(r, "commandID"), true, "", requestUser(r))
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	writeCommand(w, http.StatusOK, id, c)
+}
+
+func (h *Handler) rejectCommand(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.RejectCommandRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+	}
+
+	id := chi.URLParam(r, "id")
+	c, err := h.executor.DecideCommand(id, chi.URLParam(r, "commandID"), false, req.Reason, requestUser(r))
+	if err != nil {
+		writeCommandError(w, err)
+		return
+	}
+	writeCommand(w, http.StatusOK, id, c)
+}
@@ -4,7 +4,10 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"net/http"
+	"strings"
 	"time"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
 )
 
 const (
@@ -12,10 +15,30 @@ const (
 	csrfHeaderName       = "X-CSRF-Token"
 	internalBypassHeader = "X-Orbitmesh-Internal"
 	internalBypassValue  = "dock-mcp"
+	// internalExchangeValue marks requests from the exchange MCP server,
+	// whose writes are attributed to the session's agent.
+	internalExchangeValue = "exchange-mcp"
+	// internalHumanValue marks requests from the ask_human MCP server.
+	internalHumanValue = "human-mcp"
+	// internalCommandValue marks requests from the approve_command MCP
+	// server.
+	internalCommandValue = "command-mcp"
+	// internalTaskValue marks requests from the strand MCP server, whose
+	// task changes are attributed to the session's agent.
+	internalTaskValue = "tasks-mcp"
+	// internalGitCredentialValue marks git credential helper requests,
+	// which authenticate with the session's delegated token instead.
+	internalGitCredentialValue = "git-credential"
 )
 
 func CSRFMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Embed endpoints are read-only and token-authenticated; framed
+		// widgets should not be handed a cookie.
+		if strings.HasPrefix(r.URL.Path, embedPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		token, err := r.Cookie(csrfCookieName)
 		if err != nil || token.Value == "" {
 			newToken := &http.Cookie{
@@ -31,13 +54,13 @@ func CSRFMiddleware(next http.Handler) http.Handler {
 		}
 
 		if isStateChangingMethod(r.Method) {
-			if r.Header.Get(internalBypassHeader) == internalBypassValue {
+			if internal := r.Header.Get(internalBypassHeader); internal == internalBypassValue || internal == internalExchangeValue || internal == internalHumanValue || internal == internalCommandValue || internal == internalTaskValue || internal == internalGitCredentialValue || isEmbeddedClient(r) {
 				next.ServeHTTP(w, r)
 				return
 			}
 			header := r.Header.Get(csrfHeaderName)
 			if header == "" || header != token.Value {
-				writeError(w, http.StatusForbidden, "invalid CSRF token", "csrf header mismatch")
+				writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeInvalidCSRFToken, "invalid CSRF token", "csrf header mismatch")
 				return
 			}
 		}
@@ -67,10 +90,14 @@ func generateCSRFToken() string {
 // for Server-Sent Events streams from frontend applications.
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, embedPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
 		// Set CORS headers to allow cross-origin requests from any origin
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-CSRF-Token, Last-Event-ID")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-CSRF-Token, X-Orbitmesh-Embedded-Token, Last-Event-ID")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		// Handle preflight requests
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// DefaultDemoSessionsPerIP is the per-client session cap used when
+// DemoModeConfig.MaxSessionsPerIP is zero.
+const DefaultDemoSessionsPerIP = 5
+
+// DemoModeConfig configures a public playground instance.
+type DemoModeConfig struct {
+	// MaxSessionsPerIP caps how many sessions one client address may create
+	// between resets.
+	MaxSessionsPerIP int
+	// TrustProxy takes the client address from X-Forwarded-For, for
+	// instances behind a reverse proxy. Leave it off otherwise, since the
+	// header is client-controlled.
+	TrustProxy bool
+	// WorkingDir is where every demo session works. It defaults to
+	// os.TempDir().
+	WorkingDir string
+}
+
+// DemoMode restricts the API for a public playground: besides reads, only
+// creating sessions and talking to them is allowed, sessions are pinned to
+// one working directory, and session creation is capped per client address.
+// Quotas last until Reset.
+type DemoMode struct {
+	cfg DemoModeConfig
+
+	mu      sync.Mutex
+	created map[string]int
+}
+
+// NewDemoMode returns a DemoMode with empty quotas.
+func NewDemoMode(cfg DemoModeConfig) *DemoMode {
+	if cfg.MaxSessionsPerIP <= 0 {
+		cfg.MaxSessionsPerIP = DefaultDemoSessionsPerIP
+	}
+	if cfg.WorkingDir == "" {
+		cfg.WorkingDir = os.TempDir()
+	}
+	return &DemoMode{cfg: cfg, created: make(map[string]int)}
+}
+
+// Reset clears the per-client session counts.
+func (d *DemoMode) Reset() {
+	d.mu.Lock()
+	d.created = make(map[string]int)
+	d.mu.Unlock()
+}
+
+// Middleware enforces the demo restrictions.
+func (d *DemoMode) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if demoRestricted(r) {
+			writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeDemoRestricted, "not available in demo mode", "")
+			return
+		}
+		if r.Method != http.MethodPost || r.URL.Path != "/api/sessions" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := d.restrictSessionRequest(r); err != nil {
+			writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeDemoRestricted, "not available in demo mode", err.Error())
+			return
+		}
+
+		client := d.clientAddr(r)
+		if !d.reserve(client) {
+			writeErrorCode(w, http.StatusTooManyRequests, apiTypes.ErrorCodeDemoSessionLimit, "demo session limit reached", "")
+			return
+		}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() >= http.StatusBadRequest {
+			d.release(client)
+		}
+	})
+}
+
+// reserve counts a session against client, failing if the cap is reached.
+// Creations that then fail are handed back with release.
+func (d *DemoMode) reserve(client string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.created[client] >= d.cfg.MaxSessionsPerIP {
+		return false
+	}
+	d.created[client]++
+	return true
+}
+
+func (d *DemoMode) release(client string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.created[client] > 0 {
+		d.created[client]--
+	}
+}
+
+func (d *DemoMode) clientAddr(r *http.Request) string {
+	if d.cfg.TrustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// restrictSessionRequest pins a session request to the demo working
+// directory, refusing the fields that would reach outside it. A body that
+// does not parse is left for the handler to reject.
+func (d *DemoMode) restrictSessionRequest(r *http.Request) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var req apiTypes.SessionRequest
+	if json.Unmarshal(body, &req) != nil {
+		return nil
+	}
+	switch {
+	case req.WorkingDir != "" && req.WorkingDir != d.cfg.WorkingDir:
+		return errors.New("working_dir cannot be set")
+	case req.ProjectID != "":
+		return errors.New("project_id cannot be set")
+	case req.Workspace != nil:
+		return errors.New("workspace cannot be set")
+	case req.Git != nil:
+		return errors.New("git cannot be set")
+	case len(req.MCPServers) > 0:
+		return errors.New("mcp_servers cannot be set")
+	}
+	req.WorkingDir = d.cfg.WorkingDir
+	if body, err = json.Marshal(req); err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return nil
+}
+
+// demoAllowedRoutes are the requests other than reads a demo client may
+// make: creating sessions and talking to them. A "*" matches one path
+// segment.
+var demoAllowedRoutes = []struct{ method, path string }{
+	{http.MethodPost, "/api/sessions"},
+	{http.MethodPost, "/api/sessions/*/messages"},
+	{http.MethodPost, "/api/sessions/*/input"},
+	{http.MethodPost, "/api/sessions/*/read"},
+	{http.MethodPost, "/api/sessions/*/cancel"},
+	{http.MethodPost, "/api/sessions/*/resume"},
+	{http.MethodPost, "/api/sessions/*/plan/approve"},
+	{http.MethodPost, "/api/sessions/*/questions/*/answer"},
+	{http.MethodPost, "/api/sessions/*/approvals/*/decision"},
+	{http.MethodDelete, "/api/sessions/*/queue/*"},
+	{http.MethodPost, "/api/sessions/*/pinned-messages"},
+	{http.MethodDelete, "/api/sessions/*/pinned-messages/*"},
+}
+
+// demoRestrictedReads are reads refused all the same: server
+// administration, and terminal websockets, which accept input.
+var demoRestrictedReads = []string{
+	"/api/v1/admin/",
+	"/metrics",
+	"/api/sessions/*/terminal/ws",
+}
+
+func demoRestricted(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		for _, pattern := range demoRestrictedReads {
+			if strings.HasSuffix(pattern, "/") && strings.HasPrefix(r.URL.Path, pattern) || demoRouteMatch(pattern, r.URL.Path) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, route := range demoAllowedRoutes {
+		if r.Method == route.method && demoRouteMatch(route.path, r.URL.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+// demoRouteMatch reports whether path matches pattern segment by segment.
+func demoRouteMatch(pattern, path string) bool {
+	want := strings.Split(pattern, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] == "*" && got[i] != "" {
+			continue
+		}
+		if want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestDemoMode_RestrictsDestructiveEndpoints(t *testing.T) {
+	srv := NewDemoMode(DemoModeConfig{}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/providers", http.StatusNoContent},
+		{http.MethodPost, "/api/v1/providers", http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/projects/p1", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/agents/a1", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/admin/cleanup/run", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/mcp/validate", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/import", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/s1/messages", http.StatusNoContent},
+		{http.MethodPost, "/api/sessions/s1/questions/q1/answer", http.StatusNoContent},
+		{http.MethodDelete, "/api/sessions/s1/pinned-messages/p1", http.StatusNoContent},
+		{http.MethodGet, "/api/sessions/s1/messages", http.StatusNoContent},
+		{http.MethodDelete, "/api/sessions/s1", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/bulk", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/dry-run", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/dev/seed", http.StatusForbidden},
+		{http.MethodPost, "/api/git/credential", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/s1/pull-request", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/s1/best-of-n", http.StatusForbidden},
+		{http.MethodPost, "/api/sessions/s1/commands/c1/approve", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/missions", http.StatusForbidden},
+		{http.MethodPost, "/api/v1/pipelines/p1/runs", http.StatusForbidden},
+		{http.MethodPut, "/api/v1/templates/t1", http.StatusForbidden},
+		{http.MethodGet, "/api/v1/admin/overview", http.StatusForbidden},
+		{http.MethodGet, "/api/sessions/s1/terminal/ws", http.StatusForbidden},
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Errorf("%s %s = %d, want %d", tc.method, tc.path, rec.Code, tc.want)
+			continue
+		}
+		if rec.Code == http.StatusForbidden {
+			var resp apiTypes.ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Code != apiTypes.ErrorCodeDemoRestricted {
+				t.Errorf("%s %s error = %+v, %v", tc.method, tc.path, resp, err)
+			}
+		}
+	}
+}
+
+func TestDemoMode_CapsSessionsPerIP(t *testing.T) {
+	fail := false
+	demo := NewDemoMode(DemoModeConfig{MaxSessionsPerIP: 2, TrustProxy: true})
+	srv := demo.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	create := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/sessions", nil)
+		req.RemoteAddr = "10.0.0.1:4000"
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// A failed creation does not use up the quota.
+	fail = true
+	if code := create("203.0.113.5"); code != http.StatusBadRequest {
+		t.Fatalf("failed create = %d", code)
+	}
+	fail = false
+	for i := range 2 {
+		if code := create("203.0.113.5, 10.0.0.1"); code != http.StatusCreated {
+			t.Fatalf("create %d = %d", i, code)
+		}
+	}
+	if code := create("203.0.113.5"); code != http.StatusTooManyRequests {
+		t.Fatalf("create over cap = %d, want 429", code)
+	}
+	if code := create("198.51.100.7"); code != http.StatusCreated {
+		t.Fatalf("other client create = %d", code)
+	}
+
+	demo.Reset()
+	if code := create("203.0.113.5"); code != http.StatusCreated {
+		t.Fatalf("create after reset = %d", code)
+	}
+}
+
+func TestDemoMode_PinsSessionWorkingDir(t *testing.T) {
+	var got apiTypes.SessionRequest
+	srv := NewDemoMode(DemoModeConfig{WorkingDir: "/srv/demo"}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	create := func(body string) int {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/sessions", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	if code := create(`{"provider_type":"echo"}`); code != http.StatusCreated || got.WorkingDir != "/srv/demo" || got.ProviderType != "echo" {
+		t.Fatalf("create = %d with %+v, want the demo working dir", code, got)
+	}
+	for _, body := range []string{
+		`{"provider_type":"echo","working_dir":"/etc"}`,
+		`{"provider_type":"echo","project_id":"p1"}`,
+		`{"provider_type":"echo","workspace":{"mode":"clone","repo":"https://example.com/r.git"}}`,
+		`{"provider_type":"echo","git":{}}`,
+	} {
+		if code := create(body); code != http.StatusForbidden {
+			t.Errorf("create %s = %d, want 403", body, code)
+		}
+	}
+}
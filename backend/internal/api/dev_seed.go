@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/demo"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const (
+	seedProjectID = "seed-project"
+	seedAgentID   = "seed-agent"
+	// seedReplayDelay paces the live seeded runs so they stay running long
+	// enough to look at.
+	seedReplayDelay = 20 * time.Second
+	// seedRunStartTimeout bounds the wait for a live seeded run to start
+	// before a question is asked on it.
+	seedRunStartTimeout = 5 * time.Second
+)
+
+// SetDevMode enables the development-only endpoints under /api/v1/dev.
+func (h *Handler) SetDevMode(enabled bool) {
+	h.devMode = enabled
+}
+
+// devSeed handles POST /api/v1/dev/seed. It creates a sample project and
+// agent, and sessions covering each state the UI shows: finished, failed and
+// interrupted runs with history, a terminal snapshot, a live run and a run
+// waiting on a question. Live runs use the replay demo provider, so no real
+// provider is needed. Every call adds a new set of sessions; the project and
+// agent are reused.
+func (h *Handler) devSeed(w http.ResponseWriter, r *http.Request) {
+	if !h.devMode {
+		writeError(w, http.StatusNotFound, "not found", "")
+		return
+	}
+
+	dir := filepath.Join(os.TempDir(), "orbitmesh-seed")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create seed working directory", err.Error())
+		return
+	}
+	resp := apiTypes.DevSeedResponse{ProjectIDs: []string{}, AgentIDs: []string{}, Sessions: []apiTypes.SessionResponse{}}
+	if h.projectStorage != nil {
+		now := time.Now().UTC()
+		if err := h.projectStorage.Save(domain.Project{ID: seedProjectID, Name: "Seed Project", Path: dir, CreatedAt: now, UpdatedAt: now}); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save seed project", err.Error())
+			return
+		}
+		resp.ProjectIDs = append(resp.ProjectIDs, seedProjectID)
+	}
+	if h.agentStorage != nil {
+		agent := storage.AgentConfig{
+			ID:           seedAgentID,
+			Name:         "Seed Agent",
+			SystemPrompt: "You are a careful engineer working on a sample repository.",
+		}
+		if err := h.agentStorage.Save(agent); err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to save seed agent", err.Error())
+			return
+		}
+		resp.AgentIDs = append(resp.AgentIDs, seedAgentID)
+	}
+
+	for _, bundle := range seedBundles(dir, time.Now().UTC()) {
+		sess, err := h.executor.ImportSessionBundle(r.Context(), generateID(), bundle)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to seed session", err.Error())
+			return
+		}
+		resp.Sessions = append(resp.Sessions, h.sessionResponseFor(sess.Snapshot(), requestUser(r)))
+	}
+
+	for _, live := range []struct {
+		title, prompt, question string
+	}{
+		{title: "Refactor the config loader", prompt: "Split config loading into parse and validate steps."},
+		{title: "Choose a migration strategy", prompt: "Plan the database migration.", question: "Should the migration run online or in a maintenance window?"},
+	} {
+		sess, err := h.seedLiveSession(r.Context(), dir, live.title, live.prompt, live.question)
+		if err != nil {
+			writeSessionError(w, err)
+			return
+		}
+		resp.Sessions = append(resp.Sessions, h.sessionResponseFor(sess.Snapshot(), requestUser(r)))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// seedLiveSession starts a slow replay run and, with a question, asks it
+// once the run is going.
+func (h *Handler) seedLiveSession(ctx context.Context, dir, title, prompt, question string) (*domain.Session, error) {
+	id := generateID()
+	_, err := h.executor.CreateSession(ctx, id, session.Config{
+		ProviderType: demo.ReplayProviderType,
+		AgentID:      seedAgentID,
+		WorkingDir:   dir,
+		ProjectID:    seedProjectID,
+		Title:        title,
+		Custom: map[string]any{
+			"replay_lines":    []any{"Reading the code…", "Making the change…", "Running the tests…", "Done."},
+			"replay_delay_ms": int(seedReplayDelay / time.Millisecond),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sess, err := h.executor.SendMessage(ctx, id, prompt, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if question == "" {
+		return sess, nil
+	}
+
+	deadline := time.Now().Add(seedRunStartTimeout)
+	for sess.GetState() != domain.SessionStateRunning && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if _, err := h.executor.AskHuman(id, question, []string{"Online", "Maintenance window"}); err != nil {
+		log.Printf("dev seed: asking question on %s: %v", id, err)
+	}
+	return sess, nil
+}
+
+// seedBundles builds the seeded sessions that have history but no live run.
+func seedBundles(dir string, now time.Time) []*service.SessionBundle {
+	at := func(ago time.Duration) time.Time { return now.Add(-ago) }
+	ended := func(ago time.Duration) *time.Time {
+		t := at(ago)
+		return &t
+	}
+	msg := func(kind domain.MessageKind, ago time.Duration, contents string) domain.Message {
+		return domain.Message{ID: fmt.Sprintf("seed_%s_%d", kind, at(ago).UnixNano()), Kind: kind, Contents: contents, Timestamp: at(ago)}
+	}
+	snapshot := func(title string, ago time.Duration, pinned bool) domain.SessionSnapshot {
+		return domain.SessionSnapshot{
+			ID:           "seed",
+			ProviderType: demo.ReplayProviderType,
+			AgentID:      seedAgentID,
+			Title:        title,
+			WorkingDir:   dir,
+			ProjectID:    seedProjectID,
+			CreatedAt:    at(ago),
+			UpdatedAt:    at(ago - 10*time.Minute),
+			Pinned:       pinned,
+		}
+	}
+	attempt := func(id string, ago time.Duration, reason, interruption string) *storage.RunAttemptMetadata {
+		return &storage.RunAttemptMetadata{
+			AttemptID:          id,
+			ProviderType:       demo.ReplayProviderType,
+			StartedAt:          at(ago),
+			EndedAt:            ended(ago - 10*time.Minute),
+			TerminalReason:     reason,
+			InterruptionReason: interruption,
+			HeartbeatAt:        at(ago - 10*time.Minute),
+		}
+	}
+
+	return []*service.SessionBundle{
+		{
+			Session: snapshot("Add pagination to the session list", 3*time.Hour, true),
+			Messages: []domain.Message{
+				msg(domain.MessageKindUser, 3*time.Hour, "Add cursor pagination to GET /api/sessions."),
+				msg(domain.MessageKindThought, 3*time.Hour-time.Minute, "I'll find the list handler and how sessions are sorted."),
+				msg(domain.MessageKindToolUse, 3*time.Hour-2*time.Minute, "read_file internal/api/handler.go"),
+				msg(domain.MessageKindPlan, 3*time.Hour-3*time.Minute, "1. Add a cursor parameter\n2. Sort by creation time\n3. Return next_cursor"),
+				msg(domain.MessageKindOutput, 2*time.Hour+50*time.Minute, "Pagination is in place.\n\n```go\nnext := sessions[len(sessions)-1].CreatedAt\n```\n\nAll tests pass."),
+			},
+			Attempts: []*storage.RunAttemptMetadata{attempt("seed-attempt-completed", 3*time.Hour, "completed", "")},
+		},
+		{
+			Session: snapshot("Upgrade the HTTP router", 2*time.Hour, false),
+			Messages: []domain.Message{
+				msg(domain.MessageKindUser, 2*time.Hour, "Upgrade the router to the latest major version."),
+				msg(domain.MessageKindToolUse, 2*time.Hour-time.Minute, "go get github.com/go-chi/chi/v5@latest"),
+				msg(domain.MessageKindDiagnostic, 2*time.Hour-2*time.Minute, "go: downloading github.com/go-chi/chi/v5\nverifying module: checksum mismatch\n"),
+				msg(domain.MessageKindError, 2*time.Hour-3*time.Minute, "provider exited with status 1"),
+			},
+			Attempts: []*storage.RunAttemptMetadata{attempt("seed-attempt-failed", 2*time.Hour, "failed", "")},
+		},
+		{
+			Session: snapshot("Write release notes", 90*time.Minute, false),
+			Messages: []domain.Message{
+				msg(domain.MessageKindUser, 90*time.Minute, "Draft release notes from the commits since the last tag."),
+				msg(domain.MessageKindOutput, 89*time.Minute, "Collecting commits…"),
+			},
+			Attempts: []*storage.RunAttemptMetadata{attempt("seed-attempt-interrupted", 90*time.Minute, "interrupted", "startup recovery: interrupted while running")},
+		},
+		{
+			Session: snapshot("Run the test suite", time.Hour, false),
+			Messages: []domain.Message{
+				msg(domain.MessageKindUser, time.Hour, "Run the full test suite."),
+				msg(domain.MessageKindOutput, 55*time.Minute, "All 214 tests passed."),
+			},
+			Attempts: []*storage.RunAttemptMetadata{attempt("seed-attempt-terminal", time.Hour, "completed", "")},
+			Terminal: &service.SessionBundleTerminal{
+				Kind:          domain.TerminalKindPTY,
+				CreatedAt:     at(time.Hour),
+				LastUpdatedAt: at(55 * time.Minute),
+				Rows:          6,
+				Cols:          80,
+				Lines: []string{
+					"$ go test ./...",
+					"ok  \tgithub.com/example/app/internal/api\t1.204s",
+					"ok  \tgithub.com/example/app/internal/service\t3.871s",
+					"ok  \tgithub.com/example/app/internal/storage\t0.412s",
+					"$",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/demo"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestDevSeed(t *testing.T) {
+	env := &testEnv{broadcaster: service.NewEventBroadcaster(100), store: newInMemStore()}
+	providers := storage.NewProviderConfigStorage(t.TempDir())
+	agents := storage.NewAgentConfigStorage(t.TempDir())
+	projects := storage.NewProjectStorage(t.TempDir())
+	env.executor = service.NewAgentExecutor(service.ExecutorConfig{
+		Storage:         env.store,
+		TerminalStorage: env.store,
+		Broadcaster:     env.broadcaster,
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			lines, delay := demo.ReplayConfig(config.Custom)
+			return demo.NewReplaySession(sessionID, lines, delay), nil
+		},
+		ProviderConfigs: providers,
+		AgentConfigs:    agents,
+		Projects:        projects,
+	})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = env.executor.Shutdown(ctx)
+	})
+	env.handler = NewHandler(env.executor, env.broadcaster, env.store, providers, agents, projects)
+	r := env.router()
+
+	seed := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/dev/seed", nil))
+		return w
+	}
+	if w := seed(); w.Code != http.StatusNotFound {
+		t.Fatalf("without dev mode: expected 404, got %d", w.Code)
+	}
+
+	env.handler.SetDevMode(true)
+	w := seed()
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.DevSeedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(resp.ProjectIDs) != 1 || len(resp.AgentIDs) != 1 || len(resp.Sessions) != 6 {
+		t.Fatalf("unexpected seed: %d projects, %d agents, %d sessions", len(resp.ProjectIDs), len(resp.AgentIDs), len(resp.Sessions))
+	}
+	if _, err := projects.Get(resp.ProjectIDs[0]); err != nil {
+		t.Fatalf("seed project not saved: %v", err)
+	}
+
+	states := map[domain.SessionState]int{}
+	for _, s := range resp.Sessions {
+		state, err := env.executor.DeriveSessionState(s.ID)
+		if err != nil {
+			t.Fatalf("derive %s: %v", s.ID, err)
+		}
+		states[state]++
+	}
+	if states[domain.SessionStateIdle] == 0 || states[domain.SessionStateRunning] == 0 || states[domain.SessionStateSuspended] < 2 {
+		t.Fatalf("expected idle, running and suspended sessions, got %v", states)
+	}
+	if len(env.executor.PendingQuestions()) != 1 {
+		t.Fatalf("expected one pending question, got %d", len(env.executor.PendingQuestions()))
+	}
+}
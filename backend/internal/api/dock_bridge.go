@@ -14,27 +14,77 @@ var (
 	ErrDockTimeout      = errors.New("dock request timed out")
 	ErrDockRequestGone  = errors.New("dock request not found")
 	ErrDockRequestEmpty = errors.New("dock request not available")
+	// ErrDockDisconnected is returned for requests to a dock whose browser
+	// tab has stopped polling and sending heartbeats.
+	ErrDockDisconnected = errors.New("dock disconnected")
 )
 
 const (
 	dockQueueSize      = 32
 	dockRequestTimeout = 30 * time.Second
+	// dockStaleAfter is how long a dock stays connected after its browser
+	// tab was last seen. Tabs send a heartbeat every 5 seconds.
+	dockStaleAfter = 15 * time.Second
 )
 
 type dockSessionBridge struct {
 	mu       sync.Mutex
 	requests chan apiTypes.DockMCPRequest
 	pending  map[string]chan apiTypes.DockMCPResponse
+	// polls counts the browser's long polls in flight; lastSeen is when it
+	// last polled or sent a heartbeat.
+	polls    int
+	lastSeen time.Time
+}
+
+// DockStatus is the connection health of a dock session's browser tab.
+type DockStatus struct {
+	Connected bool
+	Polling   bool
+	LastSeen  time.Time
+	Pending   int
 }
 
 type DockBridge struct {
 	mu       sync.Mutex
 	sessions map[string]*dockSessionBridge
+	// staleAfter and checkInterval are fields so tests can shorten them.
+	staleAfter    time.Duration
+	checkInterval time.Duration
 }
 
 func NewDockBridge() *DockBridge {
 	return &DockBridge{
-		sessions: make(map[string]*dockSessionBridge),
+		sessions:      make(map[string]*dockSessionBridge),
+		staleAfter:    dockStaleAfter,
+		checkInterval: time.Second,
+	}
+}
+
+// connected reports whether the browser tab is polling or was seen
+// recently. The caller holds entry.mu.
+func (b *DockBridge) connected(entry *dockSessionBridge) bool {
+	return entry.polls > 0 || (!entry.lastSeen.IsZero() && time.Since(entry.lastSeen) < b.staleAfter)
+}
+
+// Heartbeat records that the dock session's browser tab is still open.
+func (b *DockBridge) Heartbeat(sessionID string) {
+	entry := b.session(sessionID)
+	entry.mu.Lock()
+	entry.lastSeen = time.Now()
+	entry.mu.Unlock()
+}
+
+// Status reports the connection health of the dock session's browser tab.
+func (b *DockBridge) Status(sessionID string) DockStatus {
+	entry := b.session(sessionID)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	return DockStatus{
+		Connected: b.connected(entry),
+		Polling:   entry.polls > 0,
+		LastSeen:  entry.lastSeen,
+		Pending:   len(entry.pending),
 	}
 }
 
@@ -53,11 +103,18 @@ func (b *DockBridge) session(id string) *dockSessionBridge {
 	return entry
 }
 
+// Enqueue hands a request to the dock session's browser tab and waits for
+// its response. It fails fast with ErrDockDisconnected when the tab is not
+// connected, or stops being connected while the request waits.
 func (b *DockBridge) Enqueue(ctx context.Context, sessionID string, req apiTypes.DockMCPRequest) (apiTypes.DockMCPResponse, error) {
 	entry := b.session(sessionID)
 	respCh := make(chan apiTypes.DockMCPResponse, 1)
 
 	entry.mu.Lock()
+	if !b.connected(entry) {
+		entry.mu.Unlock()
+		return apiTypes.DockMCPResponse{}, ErrDockDisconnected
+	}
 	entry.pending[req.ID] = respCh
 	entry.mu.Unlock()
 
@@ -73,25 +130,60 @@ func (b *DockBridge) Enqueue(ctx context.Context, sessionID string, req apiTypes
 
 	timeoutCtx, cancel := context.WithTimeout(ctx, dockRequestTimeout)
 	defer cancel()
+	ticker := time.NewTicker(b.checkInterval)
+	defer ticker.Stop()
 
-	select {
-	case resp := <-respCh:
-		return resp, nil
-	case <-timeoutCtx.Done():
-		entry.mu.Lock()
-		delete(entry.pending, req.ID)
-		entry.mu.Unlock()
-		return apiTypes.DockMCPResponse{}, ErrDockTimeout
+	for {
+		select {
+		case resp := <-respCh:
+			return resp, nil
+		case <-ticker.C:
+			entry.mu.Lock()
+			alive := b.connected(entry)
+			if !alive {
+				delete(entry.pending, req.ID)
+			}
+			entry.mu.Unlock()
+			if !alive {
+				return apiTypes.DockMCPResponse{}, ErrDockDisconnected
+			}
+		case <-timeoutCtx.Done():
+			entry.mu.Lock()
+			delete(entry.pending, req.ID)
+			entry.mu.Unlock()
+			return apiTypes.DockMCPResponse{}, ErrDockTimeout
+		}
 	}
 }
 
+// Next waits for the dock session's next request. A browser tab waiting in
+// Next counts as connected. Requests their sender gave up on are dropped
+// rather than replayed to a tab that reconnects.
 func (b *DockBridge) Next(ctx context.Context, sessionID string) (apiTypes.DockMCPRequest, error) {
 	entry := b.session(sessionID)
-	select {
-	case req := <-entry.requests:
-		return req, nil
-	case <-ctx.Done():
-		return apiTypes.DockMCPRequest{}, ErrDockRequestEmpty
+	entry.mu.Lock()
+	entry.polls++
+	entry.lastSeen = time.Now()
+	entry.mu.Unlock()
+	defer func() {
+		entry.mu.Lock()
+		entry.polls--
+		entry.lastSeen = time.Now()
+		entry.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case req := <-entry.requests:
+			entry.mu.Lock()
+			_, waiting := entry.pending[req.ID]
+			entry.mu.Unlock()
+			if waiting {
+				return req, nil
+			}
+		case <-ctx.Done():
+			return apiTypes.DockMCPRequest{}, ErrDockRequestEmpty
+		}
 	}
 }
 
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestDockBridge_Liveness(t *testing.T) {
+	b := NewDockBridge()
+	b.staleAfter = 50 * time.Millisecond
+	b.checkInterval = 10 * time.Millisecond
+	ctx := context.Background()
+
+	if _, err := b.Enqueue(ctx, "s1", apiTypes.DockMCPRequest{ID: "r1", Kind: dockMCPKindList}); !errors.Is(err, ErrDockDisconnected) {
+		t.Fatalf("Enqueue without a tab = %v, want ErrDockDisconnected", err)
+	}
+	if status := b.Status("s1"); status.Connected || !status.LastSeen.IsZero() {
+		t.Fatalf("unexpected status before any heartbeat: %+v", status)
+	}
+
+	// A tab that polls and answers serves requests.
+	go func() {
+		req, err := b.Next(ctx, "s1")
+		if err == nil {
+			_ = b.Respond("s1", apiTypes.DockMCPResponse{ID: req.ID, Result: "ok"})
+		}
+	}()
+	waitForDock(t, b, "s1", true)
+	resp, err := b.Enqueue(ctx, "s1", apiTypes.DockMCPRequest{ID: "r2", Kind: dockMCPKindList})
+	if err != nil || resp.Result != "ok" {
+		t.Fatalf("Enqueue = %+v, %v", resp, err)
+	}
+
+	// A tab that stops polling and sending heartbeats fails the waiting
+	// request well before the request timeout.
+	b.Heartbeat("s1")
+	start := time.Now()
+	if _, err := b.Enqueue(ctx, "s1", apiTypes.DockMCPRequest{ID: "r3", Kind: dockMCPKindList}); !errors.Is(err, ErrDockDisconnected) {
+		t.Fatalf("Enqueue to a silent tab = %v, want ErrDockDisconnected", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("disconnect took %v", elapsed)
+	}
+	status := b.Status("s1")
+	if status.Connected || status.LastSeen.IsZero() || status.Pending != 0 {
+		t.Fatalf("unexpected status after disconnect: %+v", status)
+	}
+
+	// The abandoned request is not replayed to a tab that reconnects.
+	pollCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if req, err := b.Next(pollCtx, "s1"); !errors.Is(err, ErrDockRequestEmpty) {
+		t.Fatalf("Next after reconnect = %+v, %v, want ErrDockRequestEmpty", req, err)
+	}
+}
+
+func waitForDock(t *testing.T, b *DockBridge, sessionID string, polling bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Status(sessionID).Polling != polling {
+		if time.Now().After(deadline) {
+			t.Fatalf("dock polling never became %v", polling)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -36,7 +37,7 @@ func (h *Handler) requireDockSession(id string) (*domain.SessionSnapshot, bool)
 func (h *Handler) nextDockMCP(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if _, ok := h.requireDockSession(id); !ok {
-		writeError(w, http.StatusNotFound, "session not found", "")
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 		return
 	}
 
@@ -67,7 +68,7 @@ func (h *Handler) nextDockMCP(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) requestDockMCP(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if _, ok := h.requireDockSession(id); !ok {
-		writeError(w, http.StatusNotFound, "session not found", "")
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 		return
 	}
 
@@ -89,6 +90,20 @@ func (h *Handler) requestDockMCP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	req.ID = generateID()
+	if req.Kind == dockMCPKindMultiEdit {
+		payload, rejected, err := parseDockMultiEdit(req.Payload)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid multi_edit payload", err.Error())
+			return
+		}
+		if rejected != nil {
+			// Nothing reaches the page while any edit is malformed.
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(apiTypes.DockMCPResponse{ID: req.ID, Result: *rejected})
+			return
+		}
+		req.Payload = payload
+	}
 
 	resp, err := h.dockBridge.Enqueue(r.Context(), id, req)
 	if err != nil {
@@ -97,6 +112,8 @@ func (h *Handler) requestDockMCP(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusTooManyRequests, "dock queue full", err.Error())
 		case errors.Is(err, ErrDockTimeout):
 			writeError(w, http.StatusGatewayTimeout, "dock request timed out", err.Error())
+		case errors.Is(err, ErrDockDisconnected):
+			writeError(w, http.StatusServiceUnavailable, "dock disconnected", "the dock's browser tab is not connected")
 		default:
 			writeError(w, http.StatusInternalServerError, "dock request failed", err.Error())
 		}
@@ -110,7 +127,7 @@ func (h *Handler) requestDockMCP(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) respondDockMCP(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if _, ok := h.requireDockSession(id); !ok {
-		writeError(w, http.StatusNotFound, "session not found", "")
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 		return
 	}
 
@@ -135,3 +152,80 @@ func (h *Handler) respondDockMCP(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// dockHeartbeat records that the dock session's browser tab is still open.
+// Tabs send one every few seconds, so requests to a closed tab fail fast.
+func (h *Handler) dockHeartbeat(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := h.requireDockSession(id); !ok {
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
+		return
+	}
+	h.dockBridge.Heartbeat(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getDockStatus(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, ok := h.requireDockSession(id); !ok {
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
+		return
+	}
+	status := h.dockBridge.Status(id)
+	resp := apiTypes.DockStatusResponse{
+		Connected:       status.Connected,
+		Polling:         status.Polling,
+		PendingRequests: status.Pending,
+	}
+	if !status.LastSeen.IsZero() {
+		lastSeen := status.LastSeen.UTC()
+		resp.LastSeenAt = &lastSeen
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// parseDockMultiEdit decodes a multi_edit payload into the typed schema. A
+// payload that does not fit the schema is an error; edits that do but are
+// unusable are reported per field in the returned result, with the rest
+// marked skipped.
+func parseDockMultiEdit(raw any) (apiTypes.DockMultiEditPayload, *apiTypes.DockMultiEditResult, error) {
+	var payload apiTypes.DockMultiEditPayload
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return payload, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return payload, nil, err
+	}
+	if len(payload.Edits) == 0 {
+		return payload, nil, errors.New("edits must not be empty")
+	}
+
+	result := apiTypes.DockMultiEditResult{Results: make([]apiTypes.DockFieldEditResult, len(payload.Edits))}
+	seen := make(map[string]bool, len(payload.Edits))
+	valid := true
+	for i, edit := range payload.Edits {
+		id := strings.TrimSpace(edit.ComponentID)
+		payload.Edits[i].ComponentID = id
+		res := apiTypes.DockFieldEditResult{ComponentID: id, Status: apiTypes.DockEditSkipped}
+		switch {
+		case id == "":
+			res.Status, res.Error = apiTypes.DockEditFailed, "component_id is required"
+		case seen[id]:
+			res.Status, res.Error = apiTypes.DockEditFailed, "component is edited more than once"
+		}
+		seen[id] = true
+		if res.Status == apiTypes.DockEditFailed {
+			valid = false
+		}
+		result.Results[i] = res
+	}
+	if valid {
+		return payload, nil, nil
+	}
+	return payload, &result, nil
+}
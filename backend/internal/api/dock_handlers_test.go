@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestParseDockMultiEdit(t *testing.T) {
+	raw := map[string]any{"edits": []any{
+		map[string]any{"component_id": " title ", "value": "New", "expected": "Old"},
+		map[string]any{"component_id": "body", "value": "text"},
+	}}
+	payload, rejected, err := parseDockMultiEdit(raw)
+	if err != nil || rejected != nil {
+		t.Fatalf("parseDockMultiEdit = %+v, %v", rejected, err)
+	}
+	if got := payload.Edits[0]; got.ComponentID != "title" || got.Expected == nil || *got.Expected != "Old" {
+		t.Fatalf("unexpected first edit: %+v", got)
+	}
+
+	_, rejected, err = parseDockMultiEdit(map[string]any{"edits": []any{
+		map[string]any{"component_id": "title", "value": "a"},
+		map[string]any{"component_id": "", "value": "b"},
+		map[string]any{"component_id": "title", "value": "c"},
+	}})
+	if err != nil || rejected == nil {
+		t.Fatalf("expected per-field rejections, got %+v, %v", rejected, err)
+	}
+	want := []string{apiTypes.DockEditSkipped, apiTypes.DockEditFailed, apiTypes.DockEditFailed}
+	for i, res := range rejected.Results {
+		if res.Status != want[i] {
+			t.Errorf("Results[%d].Status = %q, want %q", i, res.Status, want[i])
+		}
+	}
+
+	for _, bad := range []any{nil, map[string]any{"fields": map[string]any{"title": "x"}}, map[string]any{"edits": "title"}} {
+		if _, _, err := parseDockMultiEdit(bad); err == nil {
+			t.Errorf("expected an error for payload %v", bad)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const (
+	// embedPathPrefix is served outside the main API: its CORS and rate
+	// limits are the embed token's, not CORSMiddleware's.
+	embedPathPrefix = "/embed/v1/"
+
+	// DefaultEmbedRequestsPerMinute is the per-token and per-client request
+	// budget of the embed endpoints.
+	DefaultEmbedRequestsPerMinute = 60
+
+	embedRecentLimit   = 10
+	embedSummaryLength = 200
+	// embedWidgetRefresh is how often the widget page reloads itself.
+	embedWidgetRefresh = 15
+)
+
+type embedTokenKey struct{}
+
+// SetEmbedRateLimit sets how many embed requests one token and one client
+// address may make per minute.
+func (h *Handler) SetEmbedRateLimit(perMinute int) {
+	h.embedLimiter = newEmbedRateLimiter(perMinute, time.Minute)
+}
+
+func (h *Handler) mountEmbed(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(h.embedMiddleware)
+		r.Get(embedPathPrefix+"status", h.embedStatus)
+		r.Get(embedPathPrefix+"activity", h.embedActivity)
+		r.Get(embedPathPrefix+"widget", h.embedWidget)
+		r.Options(embedPathPrefix+"*", func(w http.ResponseWriter, r *http.Request) {})
+	})
+}
+
+// embedMiddleware authenticates embed requests by token, from an
+// Authorization bearer header or the token query parameter for iframes, and
+// applies the token's origin allowlist and the embed rate limit.
+func (h *Handler) embedMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+
+		if r.Method == http.MethodOptions {
+			// The preflight carries no token, so it cannot be checked
+			// against the allowlist; the request that follows is.
+			if origin != "" {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Set("Access-Control-Allow-Methods", "GET")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+				w.Header().Set("Access-Control-Max-Age", "3600")
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if !h.embedLimiter.allow("client:"+embedClientAddr(r), time.Now()) {
+			writeEmbedRateLimited(w)
+			return
+		}
+		secret := r.URL.Query().Get("token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			secret = strings.TrimSpace(bearer)
+		}
+		token, err := h.executor.ResolveEmbedToken(secret)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "invalid embed token", "")
+			return
+		}
+		if origin != "" {
+			if !slices.Contains(token.Origins, strings.ToLower(origin)) {
+				writeError(w, http.StatusForbidden, "origin not allowed for this embed token", "")
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if !h.embedLimiter.allow("token:"+token.ID, time.Now()) {
+			writeEmbedRateLimited(w)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), embedTokenKey{}, token)))
+	})
+}
+
+func embedTokenFrom(r *http.Request) storage.EmbedToken {
+	token, _ := r.Context().Value(embedTokenKey{}).(storage.EmbedToken)
+	return token
+}
+
+func (h *Handler) embedStatus(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.executor.GetSession(embedTokenFrom(r).SessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(embedStatusOf(sess.Snapshot()))
+}
+
+func (h *Handler) embedActivity(w http.ResponseWriter, r *http.Request) {
+	digest, err := h.embedDigest(embedTokenFrom(r).SessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(digest)
+}
+
+// embedWidget renders the status and digest as a self-refreshing HTML page
+// for an iframe. It runs no script, and only the token's origins may frame
+// it.
+func (h *Handler) embedWidget(w http.ResponseWriter, r *http.Request) {
+	token := embedTokenFrom(r)
+	sess, err := h.executor.GetSession(token.SessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	digest, err := h.embedDigest(token.SessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	ancestors := "'none'"
+	if len(token.Origins) > 0 {
+		ancestors = strings.Join(token.Origins, " ")
+	}
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors "+ancestors)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = embedWidgetTemplate.Execute(w, map[string]any{
+		"Refresh":  embedWidgetRefresh,
+		"Status":   embedStatusOf(sess.Snapshot()),
+		"Activity": digest,
+	})
+}
+
+func embedStatusOf(snap domain.SessionSnapshot) apiTypes.EmbedStatusResponse {
+	return apiTypes.EmbedStatusResponse{
+		SessionID:   snap.ID,
+		Title:       snap.Title,
+		State:       snap.State.String(),
+		CurrentTask: snap.CurrentTask,
+		UpdatedAt:   snap.UpdatedAt,
+	}
+}
+
+// embedDigest counts the session's messages and summarizes the latest
+// output, tool calls, plans and errors. Redacted messages are skipped.
+func (h *Handler) embedDigest(sessionID string) (apiTypes.EmbedActivityResponse, error) {
+	sess, err := h.executor.GetSession(sessionID)
+	if err != nil {
+		return apiTypes.EmbedActivityResponse{}, err
+	}
+	messages := sess.Snapshot().Messages
+	if stored, err := h.sessionStorage.GetMessages(sessionID); err == nil {
+		messages = stored
+	}
+
+	digest := apiTypes.EmbedActivityResponse{SessionID: sessionID, Messages: len(messages), Recent: []apiTypes.EmbedActivityItem{}}
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		switch msg.Kind {
+		case domain.MessageKindToolUse:
+			digest.ToolCalls++
+		case domain.MessageKindError:
+			digest.Errors++
+		}
+		if digest.LastActivity == nil {
+			ts := msg.Timestamp
+			digest.LastActivity = &ts
+		}
+		if msg.Redacted || len(digest.Recent) == embedRecentLimit {
+			continue
+		}
+		switch msg.Kind {
+		case domain.MessageKindOutput, domain.MessageKindToolUse, domain.MessageKindPlan, domain.MessageKindError:
+		default:
+			continue
+		}
+		summary := strings.Join(strings.Fields(msg.Contents), " ")
+		if summary == "" {
+			continue
+		}
+		if r := []rune(summary); len(r) > embedSummaryLength {
+			summary = string(r[:embedSummaryLength]) + "…"
+		}
+		digest.Recent = append(digest.Recent, apiTypes.EmbedActivityItem{Kind: string(msg.Kind), Summary: summary, Timestamp: msg.Timestamp})
+	}
+	return digest, nil
+}
+
+func writeEmbedRateLimited(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "60")
+	writeError(w, http.StatusTooManyRequests, "embed rate limit exceeded", "")
+}
+
+func embedClientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// createEmbedToken mints an embed token for the session. The secret is only
+// in this response.
+func (h *Handler) createEmbedToken(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.EmbedTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	secret, token, err := h.executor.CreateEmbedToken(chi.URLParam(r, "id"), requestUser(r), service.EmbedTokenRequest{
+		Label:   req.Label,
+		Origins: req.Origins,
+		TTL:     time.Duration(req.TTLSeconds) * time.Second,
+	})
+	if err != nil {
+		writeEmbedTokenError(w, err)
+		return
+	}
+	resp := embedTokenToAPI(token)
+	resp.Token = secret
+	resp.WidgetURL = embedPathPrefix + "widget?token=" + secret
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) listEmbedTokens(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.executor.GetSession(id); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	tokens, err := h.executor.EmbedTokens(id)
+	if err != nil {
+		writeEmbedTokenError(w, err)
+		return
+	}
+	resp := apiTypes.EmbedTokenListResponse{Tokens: make([]apiTypes.EmbedToken, 0, len(tokens))}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, embedTokenToAPI(t))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) revokeEmbedToken(w http.ResponseWriter, r *http.Request) {
+	token, err := h.executor.RevokeEmbedToken(chi.URLParam(r, "id"), chi.URLParam(r, "tokenID"), requestUser(r))
+	if err != nil {
+		writeEmbedTokenError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(embedTokenToAPI(token))
+}
+
+func writeEmbedTokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidEmbedRequest):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, storage.ErrEmbedTokenNotFound):
+		writeError(w, http.StatusNotFound, err.Error(), "")
+	case errors.Is(err, service.ErrEmbedTokensDisabled):
+		writeError(w, http.StatusNotImplemented, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+func embedTokenToAPI(t storage.EmbedToken) apiTypes.EmbedToken {
+	return apiTypes.EmbedToken{
+		ID:        t.ID,
+		SessionID: t.SessionID,
+		Label:     t.Label,
+		Origins:   t.Origins,
+		CreatedBy: t.CreatedBy,
+		CreatedAt: t.CreatedAt,
+		ExpiresAt: t.ExpiresAt,
+		RevokedAt: t.RevokedAt,
+	}
+}
+
+// embedRateLimiter allows limit requests per key in each fixed window.
+type embedRateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu   sync.Mutex
+	hits map[string]*embedWindow
+}
+
+type embedWindow struct {
+	start time.Time
+	count int
+}
+
+func newEmbedRateLimiter(limit int, window time.Duration) *embedRateLimiter {
+	if limit <= 0 {
+		limit = DefaultEmbedRequestsPerMinute
+	}
+	return &embedRateLimiter{limit: limit, window: window, hits: make(map[string]*embedWindow)}
+}
+
+func (l *embedRateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	win := l.hits[key]
+	if win == nil || now.Sub(win.start) >= l.window {
+		if len(l.hits) > 10000 {
+			l.pruneUnlocked(now)
+		}
+		win = &embedWindow{start: now}
+		l.hits[key] = win
+	}
+	if win.count >= l.limit {
+		return false
+	}
+	win.count++
+	return true
+}
+
+func (l *embedRateLimiter) pruneUnlocked(now time.Time) {
+	for key, win := range l.hits {
+		if now.Sub(win.start) >= l.window {
+			delete(l.hits, key)
+		}
+	}
+}
+
+var embedWidgetTemplate = template.Must(template.New("widget").Funcs(template.FuncMap{
+	"ago": func(t time.Time) string {
+		d := time.Since(t).Round(time.Second)
+		if d < time.Minute {
+			return strconv.Itoa(int(d.Seconds())) + "s ago"
+		}
+		if d < time.Hour {
+			return strconv.Itoa(int(d.Minutes())) + "m ago"
+		}
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+}).Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><meta http-equiv="refresh" content="{{.Refresh}}">
+<title>{{or .Status.Title .Status.SessionID}}</title>
+<style>
+body{font:13px/1.4 system-ui,sans-serif;margin:8px;color:#1f2328}
+.state{display:inline-block;padding:0 6px;border-radius:4px;background:#eef;font-weight:600}
+.state-running{background:#dff6dd}.state-suspended{background:#fff1c2}
+ul{list-style:none;padding:0;margin:6px 0 0}li{margin:2px 0;color:#555}
+.kind{font-weight:600;color:#1f2328}small{color:#777}
+</style></head><body>
+<div><strong>{{or .Status.Title .Status.SessionID}}</strong> <span class="state state-{{.Status.State}}">{{.Status.State}}</span></div>
+{{with .Status.CurrentTask}}<div>{{.}}</div>{{end}}
+<small>{{.Activity.Messages}} messages · {{.Activity.ToolCalls}} tool calls · {{.Activity.Errors}} errors{{with .Activity.LastActivity}} · last activity {{ago .}}{{end}}</small>
+<ul>{{range .Activity.Recent}}<li><span class="kind">{{.Kind}}</span> {{.Summary}}</li>{{end}}</ul>
+</body></html>
+`))
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestEmbedTokens(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetEmbedRateLimit(5)
+	r := chi.NewRouter()
+	r.Use(CORSMiddleware)
+	env.handler.Mount(r)
+	created := createSession(t, r, "mock", t.TempDir())
+
+	do := func(method, path string, body any, header http.Header) *httptest.ResponseRecorder {
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	base := "/api/sessions/" + created.ID + "/embed-tokens"
+	if w := do(http.MethodPost, base, apiTypes.EmbedTokenRequest{Origins: []string{"https://wiki.example.com/page"}}, nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an origin with a path, got %d", w.Code)
+	}
+	w := do(http.MethodPost, base, apiTypes.EmbedTokenRequest{Label: "wiki", Origins: []string{"https://Wiki.example.com"}}, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var token apiTypes.EmbedToken
+	_ = json.Unmarshal(w.Body.Bytes(), &token)
+	if token.Token == "" || token.Origins[0] != "https://wiki.example.com" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	bearer := http.Header{"Authorization": {"Bearer " + token.Token}}
+	w = do(http.MethodGet, "/embed/v1/status", nil, bearer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var status apiTypes.EmbedStatusResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &status)
+	if status.SessionID != created.ID || status.State != "idle" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("expected no CORS grant without an Origin, got %q", got)
+	}
+
+	w = do(http.MethodGet, "/embed/v1/activity", nil, http.Header{"Authorization": bearer["Authorization"], "Origin": {"https://wiki.example.com"}})
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://wiki.example.com" {
+		t.Fatalf("expected the allowed origin to be granted, got %d %q", w.Code, w.Header().Get("Access-Control-Allow-Origin"))
+	}
+	if w := do(http.MethodGet, "/embed/v1/status", nil, http.Header{"Authorization": bearer["Authorization"], "Origin": {"https://evil.example.com"}}); w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another origin, got %d", w.Code)
+	}
+
+	w = do(http.MethodGet, token.WidgetURL, nil, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Security-Policy"), "frame-ancestors https://wiki.example.com") {
+		t.Fatalf("expected the widget framed only by the allowed origin, got %d %q", w.Code, w.Header().Get("Content-Security-Policy"))
+	}
+	env.handler.SetEmbedRateLimit(1)
+	do(http.MethodGet, "/embed/v1/status", nil, bearer)
+	if w := do(http.MethodGet, "/embed/v1/status", nil, bearer); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected the second request in a minute to be rate limited, got %d", w.Code)
+	}
+
+	if w := do(http.MethodDelete, base+"/"+token.ID, nil, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected revoke to succeed, got %d", w.Code)
+	}
+	env.handler.SetEmbedRateLimit(5)
+	if w := do(http.MethodGet, "/embed/v1/status", nil, bearer); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a revoked token to be refused, got %d", w.Code)
+	}
+	w = do(http.MethodGet, base, nil, nil)
+	var list apiTypes.EmbedTokenListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Tokens) != 1 || list.Tokens[0].RevokedAt == nil || list.Tokens[0].Token != "" {
+		t.Fatalf("expected the revoked token listed without its secret, got %+v", list.Tokens)
+	}
+}
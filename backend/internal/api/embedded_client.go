@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const (
+	embeddedHandshakePath   = "/api/embedded/handshake"
+	embeddedTokenHeaderName = "X-Orbitmesh-Embedded-Token"
+)
+
+type embeddedClientKey struct{}
+
+// EmbeddedClientAuth lets a packaged desktop client (Electron, Tauri) talk to
+// the local server without browser CSRF checks. The client is launched with a
+// one-time nonce, exchanges it over loopback for a client token, and sends
+// that token on every request. Browsers never learn the nonce, so ordinary
+// web pages keep the cookie-based CSRF protection.
+type EmbeddedClientAuth struct {
+	mu     sync.Mutex
+	nonce  string
+	tokens map[string]struct{}
+}
+
+// NewEmbeddedClientAuth returns an EmbeddedClientAuth that accepts a single
+// handshake with nonce.
+func NewEmbeddedClientAuth(nonce string) *EmbeddedClientAuth {
+	return &EmbeddedClientAuth{nonce: nonce, tokens: make(map[string]struct{})}
+}
+
+// Middleware serves the handshake endpoint and marks requests that carry a
+// valid client token as embedded. It must run before CSRFMiddleware.
+func (a *EmbeddedClientAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == embeddedHandshakePath && r.Method == http.MethodPost {
+			a.handshake(w, r)
+			return
+		}
+		if token := r.Header.Get(embeddedTokenHeaderName); token != "" && isLoopbackRequest(r) && a.validToken(token) {
+			r = r.WithContext(context.WithValue(r.Context(), embeddedClientKey{}, true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *EmbeddedClientAuth) handshake(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackRequest(r) {
+		writeError(w, http.StatusForbidden, "embedded handshake is loopback-only", "")
+		return
+	}
+	var req apiTypes.EmbeddedHandshakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	if a.nonce == "" || req.Nonce == "" || subtle.ConstantTimeCompare([]byte(req.Nonce), []byte(a.nonce)) != 1 {
+		a.mu.Unlock()
+		writeError(w, http.StatusForbidden, "invalid embedded client nonce", "")
+		return
+	}
+	// The nonce is single-use; a second client needs a server restart.
+	a.nonce = ""
+	token := generateCSRFToken()
+	a.tokens[token] = struct{}{}
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.EmbeddedHandshakeResponse{Token: token})
+}
+
+func (a *EmbeddedClientAuth) validToken(token string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, ok := a.tokens[token]
+	return ok
+}
+
+// isEmbeddedClient reports whether the request was authenticated by
+// EmbeddedClientAuth.
+func isEmbeddedClient(r *http.Request) bool {
+	embedded, _ := r.Context().Value(embeddedClientKey{}).(bool)
+	return embedded
+}
+
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func newEmbeddedTestServer(auth *EmbeddedClientAuth) http.Handler {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return auth.Middleware(CSRFMiddleware(ok))
+}
+
+func embeddedRequest(method, path, body, remoteAddr string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+	return req
+}
+
+func TestEmbeddedClientAuth_HandshakeBypassesCSRF(t *testing.T) {
+	srv := newEmbeddedTestServer(NewEmbeddedClientAuth("launch-nonce"))
+
+	// Browser-style request without a CSRF token is rejected.
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, embeddedRequest(http.MethodPost, "/api/sessions", "{}", "127.0.0.1:5000"))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("unauthenticated POST = %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, embeddedRequest(http.MethodPost, embeddedHandshakePath, `{"nonce":"wrong"}`, "127.0.0.1:5000"))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("bad nonce handshake = %d, want 403", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, embeddedRequest(http.MethodPost, embeddedHandshakePath, `{"nonce":"launch-nonce"}`, "[::1]:5000"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("handshake = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp apiTypes.EmbeddedHandshakeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Token == "" {
+		t.Fatalf("handshake response = %+v, %v", resp, err)
+	}
+
+	// The nonce is single-use.
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, embeddedRequest(http.MethodPost, embeddedHandshakePath, `{"nonce":"launch-nonce"}`, "127.0.0.1:5000"))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("replayed handshake = %d, want 403", rec.Code)
+	}
+
+	req := embeddedRequest(http.MethodPost, "/api/sessions", "{}", "127.0.0.1:5000")
+	req.Header.Set(embeddedTokenHeaderName, resp.Token)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("embedded POST = %d, want 204", rec.Code)
+	}
+
+	// A token presented from off-host does not bypass CSRF.
+	req = embeddedRequest(http.MethodPost, "/api/sessions", "{}", "192.0.2.1:5000")
+	req.Header.Set(embeddedTokenHeaderName, resp.Token)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("remote embedded POST = %d, want 403", rec.Code)
+	}
+}
+
+func TestEmbeddedClientAuth_HandshakeIsLoopbackOnly(t *testing.T) {
+	srv := newEmbeddedTestServer(NewEmbeddedClientAuth("launch-nonce"))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, embeddedRequest(http.MethodPost, embeddedHandshakePath, `{"nonce":"launch-nonce"}`, "192.0.2.1:5000"))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("remote handshake = %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// eventTypeFilter is a set of event type names a subscriber wants; a nil
+// filter admits every event.
+type eventTypeFilter map[string]struct{}
+
+func (f eventTypeFilter) allows(event domain.Event) bool {
+	if f == nil {
+		return true
+	}
+	_, ok := f[event.Type.String()]
+	return ok
+}
+
+// names returns the filter's event type names, sorted, or nil for a filter
+// that admits every event.
+func (f eventTypeFilter) names() []string {
+	if f == nil {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(f))
+}
+
+// parseEventTypeFilter validates event type names. Each value may itself be
+// a comma-separated list.
+func parseEventTypeFilter(values []string) (eventTypeFilter, error) {
+	var filter eventTypeFilter
+	for _, value := range values {
+		for name := range strings.SplitSeq(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !isKnownEventType(name) {
+				return nil, fmt.Errorf("unknown event type: %s", name)
+			}
+			if filter == nil {
+				filter = make(eventTypeFilter)
+			}
+			filter[name] = struct{}{}
+		}
+	}
+	return filter, nil
+}
+
+func isKnownEventType(name string) bool {
+	for t := domain.EventTypeStatusChange; t <= domain.EventTypeLiveness; t++ {
+		if t.String() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// eventTypeFilterFromRequest reads the "types" query parameter, given either
+// repeated or comma-separated.
+func eventTypeFilterFromRequest(r *http.Request) (eventTypeFilter, error) {
+	return parseEventTypeFilter(r.URL.Query()["types"])
+}
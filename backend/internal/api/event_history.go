@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// Event history page sizes.
+const (
+	defaultEventHistoryLimit = 500
+	maxEventHistoryLimit     = 5000
+)
+
+// getEventHistory replays a session's persisted events after ?since_seq=,
+// up to ?limit= of them.
+func (h *Handler) getEventHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	params := r.URL.Query()
+
+	var sinceSeq int64
+	if raw := params.Get("since_seq"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid since_seq", "since_seq must be a non-negative integer")
+			return
+		}
+		sinceSeq = n
+	}
+	limit := defaultEventHistoryLimit
+	if raw := params.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit", "limit must be a positive integer")
+			return
+		}
+		limit = min(n, maxEventHistoryLimit)
+	}
+
+	events, more, err := h.executor.EventHistory(id, sinceSeq, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrEventLogDisabled) {
+			writeError(w, http.StatusNotImplemented, err.Error(), "")
+			return
+		}
+		writeSessionError(w, err)
+		return
+	}
+
+	resp := apiTypes.EventHistoryResponse{
+		SessionID: id,
+		Events:    make([]apiTypes.HistoricalEvent, 0, len(events)),
+		More:      more,
+	}
+	for _, stored := range events {
+		resp.Events = append(resp.Events, apiTypes.HistoricalEvent{
+			Seq:   stored.Seq,
+			Event: domainEventToAPIEvent(stored.Event),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
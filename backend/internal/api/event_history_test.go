@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestGetEventHistory(t *testing.T) {
+	env := newTestEnv(t)
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: "/tmp"})
+	req := httptest.NewRequest("POST", "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	var createResp apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &createResp)
+	sessionID := createResp.ID
+
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "first", nil))
+	env.broadcaster.Broadcast(domain.NewLivenessEvent(sessionID, domain.LivenessData{}))
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "second", nil))
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "third", nil))
+
+	history := func(query string) (int, apiTypes.EventHistoryResponse) {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/sessions/%s/events/history%s", sessionID, query), nil)
+		w := httptest.NewRecorder()
+		env.router().ServeHTTP(w, req)
+		var resp apiTypes.EventHistoryResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, resp := history("?limit=2")
+	if code != http.StatusOK || len(resp.Events) != 2 || !resp.More {
+		t.Fatalf("first page = %d %+v, want 2 events and more", code, resp)
+	}
+	if resp.Events[0].Seq != 1 || resp.Events[0].Type != apiTypes.EventTypeOutput {
+		t.Fatalf("first event = %+v", resp.Events[0])
+	}
+	data, _ := resp.Events[0].Data.(map[string]any)
+	if data["content"] != "first" {
+		t.Fatalf("first event data = %+v", resp.Events[0].Data)
+	}
+
+	// Liveness events are not persisted, so the page continues at "third".
+	code, resp = history(fmt.Sprintf("?since_seq=%d", resp.Events[1].Seq))
+	if code != http.StatusOK || len(resp.Events) != 1 || resp.More {
+		t.Fatalf("second page = %d %+v, want 1 event", code, resp)
+	}
+	if data, _ := resp.Events[0].Data.(map[string]any); data["content"] != "third" {
+		t.Fatalf("second page event = %+v", resp.Events[0])
+	}
+
+	if code, _ := history("?since_seq=-1"); code != http.StatusBadRequest {
+		t.Fatalf("negative since_seq status = %d, want 400", code)
+	}
+	req = httptest.NewRequest("GET", "/api/sessions/missing/events/history", nil)
+	w = httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown session status = %d, want 404", w.Code)
+	}
+}
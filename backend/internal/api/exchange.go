@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// exchangeWriter names who a write to the exchange area comes from: the
+// session's agent when sent by the exchange MCP server, otherwise the user.
+func exchangeWriter(r *http.Request) string {
+	if r.Header.Get(internalBypassHeader) == internalExchangeValue {
+		return service.ExchangeAgent
+	}
+	return requestUser(r)
+}
+
+func exchangeEntryToResponse(entry domain.ExchangeEntry) apiTypes.ExchangeEntry {
+	return apiTypes.ExchangeEntry{
+		Value:     entry.Value,
+		UpdatedAt: entry.UpdatedAt,
+		UpdatedBy: entry.UpdatedBy,
+	}
+}
+
+func (h *Handler) listExchangeEntries(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.executor.GetSession(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	resp := apiTypes.ExchangeResponse{Entries: map[string]apiTypes.ExchangeEntry{}}
+	for key, entry := range sess.ExchangeEntries() {
+		resp.Entries[key] = exchangeEntryToResponse(entry)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) getExchangeEntry(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.executor.GetSession(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	entry, ok := sess.ExchangeEntries()[chi.URLParam(r, "key")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "exchange entry not found", "")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(exchangeEntryToResponse(entry))
+}
+
+func (h *Handler) setExchangeEntry(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.ExchangeSetRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 2*domain.MaxExchangeValueSize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	entry, err := h.executor.SetExchangeEntry(chi.URLParam(r, "id"), chi.URLParam(r, "key"), req.Value, exchangeWriter(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidExchangeKey), errors.Is(err, domain.ErrInvalidExchangeValue):
+			writeError(w, http.StatusBadRequest, err.Error(), "")
+		case errors.Is(err, domain.ErrExchangeFull):
+			writeError(w, http.StatusConflict, err.Error(), "")
+		default:
+			writeSessionError(w, err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(exchangeEntryToResponse(entry))
+}
+
+func (h *Handler) deleteExchangeEntry(w http.ResponseWriter, r *http.Request) {
+	deleted, err := h.executor.DeleteExchangeEntry(chi.URLParam(r, "id"), chi.URLParam(r, "key"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "exchange entry not found", "")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -166,11 +166,14 @@ func (h *Handler) getTerminalSnapshot(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "session id is required", "")
 		return
 	}
+	if !h.requireCapability(w, sessionID, "terminals", supportsTerminal) {
+		return
+	}
 	snapshot, err := h.executor.TerminalSnapshot(sessionID)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrSessionNotFound):
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 		case errors.Is(err, service.ErrTerminalNotSupported):
 			writeError(w, http.StatusBadRequest, "terminal snapshot not supported", "")
 		default:
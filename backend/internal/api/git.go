@@ -66,16 +66,17 @@ func parseLimit(raw string) int {
 	return limit
 }
 
-func gitLog(dir string, limit int) ([]apiTypes.CommitSummary, error) {
-	cmd := exec.Command(
-		"git",
+// gitLog lists the newest commits of revs, HEAD when none are given.
+func gitLog(dir string, limit int, revs ...string) ([]apiTypes.CommitSummary, error) {
+	args := []string{
 		"log",
 		"--no-color",
 		"--date=iso-strict",
 		"--pretty=format:%H%x1f%an%x1f%ae%x1f%ad%x1f%s",
 		"-n",
 		strconv.Itoa(limit),
-	)
+	}
+	cmd := exec.Command("git", append(append(args, revs...), "--")...)
 	cmd.Dir = dir
 	var out bytes.Buffer
 	cmd.Stdout = &out
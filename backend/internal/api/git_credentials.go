@@ -0,0 +1,77 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+)
+
+// maxGitCredentialRequestSize bounds a git credential helper request body.
+const maxGitCredentialRequestSize = 64 * 1024
+
+// gitCredential is the credential helper endpoint sessions' git calls
+// through. It speaks git's credential helper format: key=value lines in,
+// username and password lines out.
+func (h *Handler) gitCredential(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		writeError(w, http.StatusUnauthorized, "missing git credential token", "")
+		return
+	}
+	req, err := parseGitCredentialRequest(http.MaxBytesReader(w, r.Body, maxGitCredentialRequestSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid credential request", err.Error())
+		return
+	}
+
+	cred, err := h.executor.IssueGitCredential(r.Context(), token, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrGitCredentialUnauthorized):
+			writeError(w, http.StatusUnauthorized, err.Error(), "")
+		case errors.Is(err, service.ErrGitCredentialScope):
+			writeError(w, http.StatusForbidden, err.Error(), "")
+		default:
+			writeError(w, http.StatusBadGateway, "failed to get git credential", err.Error())
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintf(w, "username=%s\npassword=%s\n", cred.Username, cred.Password)
+}
+
+func parseGitCredentialRequest(body io.Reader) (service.GitCredentialRequest, error) {
+	var req service.GitCredentialRequest
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return req, fmt.Errorf("malformed line %q", line)
+		}
+		switch key {
+		case "protocol":
+			req.Protocol = value
+		case "host":
+			req.Host = value
+		case "path":
+			req.Path = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return req, err
+	}
+	if req.Protocol == "" || req.Host == "" {
+		return req, errors.New("protocol and host are required")
+	}
+	return req, nil
+}
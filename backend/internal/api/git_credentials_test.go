@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseGitCredentialRequest(t *testing.T) {
+	req, err := parseGitCredentialRequest(strings.NewReader("protocol=https\nhost=github.com\npath=acme/app.git\nwwwauth[]=Basic\n\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if req.String() != "https://github.com/acme/app.git" {
+		t.Fatalf("request = %+v", req)
+	}
+	if _, err := parseGitCredentialRequest(strings.NewReader("path=acme/app.git\n")); err == nil {
+		t.Fatal("a request without protocol and host should be rejected")
+	}
+}
+
+func TestGitCredentialEndpointRejectsUnknownToken(t *testing.T) {
+	env := newTestEnv(t)
+	router := env.router()
+
+	for _, auth := range []string{"", "Bearer forged"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/git/credential", strings.NewReader("protocol=https\nhost=github.com\n"))
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("auth %q: status = %d, want 401", auth, w.Code)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
@@ -16,7 +17,8 @@ func resolveGitDir() string {
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	if err := cmd.Run(); err == nil {
-		root := strings.TrimSpace(out.String())
+		// git prints forward slashes even on Windows.
+		root := filepath.FromSlash(strings.TrimSpace(out.String()))
 		if root != "" {
 			return root
 		}
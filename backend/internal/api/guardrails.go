@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// listQuarantine returns flagged and blocked agent output, limited to one
+// session with ?session_id= and to one status with ?status=.
+func (h *Handler) listQuarantine(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	items, err := h.executor.QuarantineItems(q.Get("session_id"), q.Get("status"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	resp := apiTypes.QuarantineListResponse{Items: make([]apiTypes.QuarantineItem, 0, len(items))}
+	for _, item := range items {
+		resp.Items = append(resp.Items, quarantineItemToAPI(item))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) resolveQuarantine(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.ResolveQuarantineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.Status != storage.QuarantineDismissed && req.Status != storage.QuarantineConfirmed {
+		writeError(w, http.StatusBadRequest, "status must be dismissed or confirmed", "")
+		return
+	}
+
+	item, err := h.executor.ResolveQuarantineItem(chi.URLParam(r, "id"), req.Status, requestUser(r))
+	if err != nil {
+		if errors.Is(err, storage.ErrQuarantineItemNotFound) {
+			writeError(w, http.StatusNotFound, err.Error(), "")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to resolve quarantine item", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(quarantineItemToAPI(item))
+}
+
+func quarantineItemToAPI(item storage.QuarantineItem) apiTypes.QuarantineItem {
+	findings := make([]apiTypes.QuarantineFinding, 0, len(item.Findings))
+	for _, f := range item.Findings {
+		findings = append(findings, apiTypes.QuarantineFinding{
+			Rule:     f.Rule,
+			Category: f.Category,
+			Action:   f.Action,
+			Match:    f.Match,
+		})
+	}
+	return apiTypes.QuarantineItem{
+		ID:         item.ID,
+		SessionID:  item.SessionID,
+		ProjectID:  item.ProjectID,
+		CreatedAt:  item.CreatedAt,
+		Source:     item.Source,
+		Action:     item.Action,
+		Findings:   findings,
+		Content:    item.Content,
+		Status:     item.Status,
+		ResolvedBy: item.ResolvedBy,
+		ResolvedAt: item.ResolvedAt,
+	}
+}
+
+func guardrailPolicyFromAPI(p *apiTypes.GuardrailPolicy) *domain.GuardrailPolicy {
+	if p == nil || (len(p.Actions) == 0 && len(p.Rules) == 0) {
+		return nil
+	}
+	out := &domain.GuardrailPolicy{}
+	if len(p.Actions) > 0 {
+		out.Actions = make(map[string]domain.GuardrailAction, len(p.Actions))
+		for category, action := range p.Actions {
+			out.Actions[category] = domain.GuardrailAction(action)
+		}
+	}
+	for _, r := range p.Rules {
+		out.Rules = append(out.Rules, domain.GuardrailRule{
+			Name:     r.Name,
+			Category: r.Category,
+			Pattern:  r.Pattern,
+			Action:   domain.GuardrailAction(r.Action),
+		})
+	}
+	return out
+}
+
+func guardrailPolicyToAPI(p *domain.GuardrailPolicy) *apiTypes.GuardrailPolicy {
+	if p == nil {
+		return nil
+	}
+	out := &apiTypes.GuardrailPolicy{}
+	if len(p.Actions) > 0 {
+		out.Actions = make(map[string]string, len(p.Actions))
+		for category, action := range p.Actions {
+			out.Actions[category] = string(action)
+		}
+	}
+	for _, r := range p.Rules {
+		out.Rules = append(out.Rules, apiTypes.GuardrailRule{
+			Name:     r.Name,
+			Category: r.Category,
+			Pattern:  r.Pattern,
+			Action:   string(r.Action),
+		})
+	}
+	return out
+}
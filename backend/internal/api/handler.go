@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,7 +20,6 @@ import (
 	"github.com/ricochet1k/orbitmesh/internal/service"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/storage"
-	"github.com/ricochet1k/orbitmesh/internal/terminal"
 	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
 	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
 )
@@ -35,6 +36,20 @@ type Handler struct {
 	dockBridge      *DockBridge
 	realtimeHub     *realtime.Hub
 	snapshotter     *realtime.SnapshotProvider
+	embedLimiter    *embedRateLimiter
+	devMode         bool
+
+	// topSessions is how many sessions GET /metrics labels individually;
+	// 0 means service.DefaultCostMetricsTopSessions.
+	topSessions int
+
+	// sseHeartbeat and sseRetry override DefaultSSEHeartbeatInterval and
+	// DefaultSSERetry when set.
+	sseHeartbeat time.Duration
+	sseRetry     time.Duration
+
+	// mirror is set when the instance is a read-only mirror of another.
+	mirror *Mirror
 }
 
 // NewHandler creates a Handler backed by the given executor and broadcaster.
@@ -50,6 +65,7 @@ func NewHandler(executor *service.AgentExecutor, broadcaster *service.EventBroad
 		dockBridge:      NewDockBridge(),
 		realtimeHub:     realtime.NewHub(),
 		snapshotter:     realtime.NewSnapshotProvider(executor, sessionStorage),
+		embedLimiter:    newEmbedRateLimiter(DefaultEmbedRequestsPerMinute, time.Minute),
 	}
 	h.startRealtimeBridge()
 	return h
@@ -57,8 +73,12 @@ func NewHandler(executor *service.AgentExecutor, broadcaster *service.EventBroad
 
 // Mount registers all API routes on the provided router.
 func (h *Handler) Mount(r chi.Router) {
+	r.Use(h.runTokenMiddleware)
 	r.Get("/api/v1/me/permissions", h.mePermissions)
 	r.Get("/api/v1/tasks/tree", h.tasksTree)
+	r.Get("/api/v1/tasks/changes", h.listTaskChanges)
+	r.Post("/api/v1/tasks/changes/undo", h.undoTaskChange)
+	r.Get("/api/v1/tasks/{id}/history", h.getTaskHistory)
 	r.Get("/api/v1/commits", h.listCommits)
 	r.Get("/api/v1/commits/{sha}", h.getCommit)
 	r.Get("/api/v1/extractor/config", h.getExtractorConfig)
@@ -67,22 +87,93 @@ func (h *Handler) Mount(r chi.Router) {
 	r.Get("/api/v1/terminals", h.listTerminals)
 	r.Get("/api/v1/terminals/{id}", h.getTerminal)
 	r.Get("/api/v1/terminals/{id}/snapshot", h.getTerminalSnapshotByID)
+	r.Get("/api/v1/sessions/suggest", h.suggestSessions)
 	r.Get("/api/sessions", h.listSessions)
 	r.Post("/api/sessions", h.createSession)
+	r.Post("/api/sessions/dry-run", h.dryRunSession)
+	r.Post("/api/sessions/import", h.importSessionBundle)
 	r.Get("/api/sessions/events", h.sseSessionEvents)
+	r.Get("/api/sessions/sync", h.syncSessions)
+	r.Post("/api/sessions/batch/cancel", h.batchCancelSessions)
+	r.Post("/api/sessions/batch/stop", h.batchStopSessions)
+	r.Post("/api/sessions/bulk", h.bulkSessions)
 	r.Get("/api/realtime", h.realtimeWebSocket)
 	r.Get("/api/sessions/{id}", h.getSession)
+	r.Patch("/api/sessions/{id}", h.updateSession)
+	r.Post("/api/sessions/{id}/read", h.markSessionRead)
+	r.Get("/api/sessions/{id}/exchange", h.listExchangeEntries)
+	r.Get("/api/sessions/{id}/exchange/{key}", h.getExchangeEntry)
+	r.Put("/api/sessions/{id}/exchange/{key}", h.setExchangeEntry)
+	r.Delete("/api/sessions/{id}/exchange/{key}", h.deleteExchangeEntry)
+	r.Post("/api/sessions/{id}/task-changes", h.recordTaskChange)
+	r.Post("/api/sessions/{id}/task-changes/undo", h.undoSessionTaskChange)
+	r.Get("/api/sessions/{id}/mission/log", h.getSessionMissionLog)
+	r.Post("/api/sessions/{id}/mission/log", h.appendSessionMissionLog)
+	r.Get("/api/search", h.searchMessages)
+	r.Get("/metrics", h.prometheusMetrics)
+	r.Get("/api/questions", h.listPendingQuestions)
+	r.Get("/api/v1/waits", h.listWaits)
+	r.Get("/api/sessions/{id}/questions", h.listSessionQuestions)
+	r.Post("/api/sessions/{id}/questions", h.askQuestion)
+	r.Get("/api/sessions/{id}/questions/{questionID}", h.getQuestion)
+	r.Post("/api/sessions/{id}/questions/{questionID}/answer", h.answerQuestion)
+	r.Get("/api/sessions/{id}/commands", h.listSessionCommands)
+	r.Post("/api/sessions/{id}/commands", h.proposeCommand)
+	r.Get("/api/sessions/{id}/commands/{commandID}", h.getCommand)
+	r.Post("/api/sessions/{id}/commands/{commandID}/approve", h.approveCommand)
+	r.Post("/api/sessions/{id}/commands/{commandID}/reject", h.rejectCommand)
+	r.Get("/api/sessions/{id}/approvals", h.listToolApprovals)
+	r.Post("/api/sessions/{id}/approvals/{approvalID}/decision", h.decideToolApproval)
 	r.Delete("/api/sessions/{id}", h.stopSession)
 	r.Post("/api/sessions/{id}/input", h.sendSessionInput)
 	r.Get("/api/sessions/{id}/messages", h.getSessionMessages)
 	r.Post("/api/sessions/{id}/messages", h.sendSessionMessage)
+	r.Post("/api/sessions/{id}/messages/redact", h.redactMessages)
+	r.Get("/api/sessions/{id}/messages/{messageID}/receipt", h.getMessageReceipt)
+	r.Get("/api/sessions/{id}/queue", h.listQueuedMessages)
+	r.Delete("/api/sessions/{id}/queue/{messageID}", h.removeQueuedMessage)
+	r.Get("/api/sessions/{id}/pinned-messages", h.listMessagePins)
+	r.Post("/api/sessions/{id}/pinned-messages", h.pinMessage)
+	r.Delete("/api/sessions/{id}/pinned-messages/{pinID}", h.unpinMessage)
+	r.Get("/api/audit", h.listAuditEntries)
+	r.Get("/api/guardrails/quarantine", h.listQuarantine)
+	r.Post("/api/guardrails/quarantine/{id}/resolve", h.resolveQuarantine)
+	r.Post("/api/git/credential", h.gitCredential)
 	r.Post("/api/sessions/{id}/cancel", h.cancelSession)
 	r.Post("/api/sessions/{id}/resume", h.resumeSession)
+	r.Get("/api/sessions/{id}/resume-tokens", h.listResumeTokens)
+	r.Post("/api/v1/resume-tokens/{token}/revoke", h.revokeResumeToken)
+	r.Post("/api/sessions/{id}/plan/approve", h.approvePlan)
+	r.Post("/api/sessions/{id}/pr-description", h.draftPRDescription)
+	r.Post("/api/sessions/{id}/pull-request", h.openPullRequest)
+	r.Post("/api/sessions/{id}/best-of-n", h.startBestOfN)
+	r.Get("/api/sessions/{id}/best-of-n", h.getBestOfN)
+	r.Post("/api/sessions/{id}/best-of-n/select", h.selectBestOfN)
+	r.Post("/api/sessions/{id}/best-of-n/discard", h.discardBestOfN)
+	r.Post("/api/sessions/{id}/handoff", h.startHandoff)
+	r.Get("/api/sessions/{id}/handoff", h.getHandoff)
+	r.Post("/api/sessions/{id}/takeover", h.startTakeover)
+	r.Get("/api/sessions/{id}/takeover", h.getTakeover)
+	r.Post("/api/sessions/{id}/takeover/hand-back", h.handBackTakeover)
+	r.Get("/api/sessions/{id}/embed-tokens", h.listEmbedTokens)
+	r.Post("/api/sessions/{id}/embed-tokens", h.createEmbedToken)
+	r.Delete("/api/sessions/{id}/embed-tokens/{tokenID}", h.revokeEmbedToken)
 	r.Get("/api/sessions/{id}/events", h.sseEvents)
+	r.Get("/api/sessions/{id}/events/history", h.getEventHistory)
 	r.Get("/api/sessions/{id}/activity", h.getSessionActivity)
+	r.Get("/api/sessions/{id}/bundle", h.exportSessionBundle)
+	r.Get("/api/sessions/{id}/export", h.exportSessionTranscript)
+	r.Get("/api/sessions/{id}/commits", h.listSessionCommits)
+	r.Get("/api/sessions/{id}/diff", h.getSessionDiff)
+	r.Get("/api/sessions/{id}/prompt-cache", h.getSessionPromptCache)
+	r.Get("/api/sessions/{id}/attempts", h.listRunAttempts)
+	r.Get("/api/sessions/{id}/attempts/{attemptID}/explain", h.explainRunAttempt)
+	r.Get("/api/sessions/{id}/timeline", h.getSessionTimeline)
 	r.Get("/api/sessions/{id}/dock/mcp/next", h.nextDockMCP)
 	r.Post("/api/sessions/{id}/dock/mcp/request", h.requestDockMCP)
 	r.Post("/api/sessions/{id}/dock/mcp/respond", h.respondDockMCP)
+	r.Post("/api/sessions/{id}/dock/heartbeat", h.dockHeartbeat)
+	r.Get("/api/sessions/{id}/dock/status", h.getDockStatus)
 	r.Get("/api/sessions/{id}/terminal/ws", h.terminalWebSocket)
 	r.Get("/api/v1/sessions/{id}/terminal/snapshot", h.getTerminalSnapshot)
 	r.Post("/api/v1/sessions/{id}/extractor/replay", h.replayExtractor)
@@ -94,6 +185,7 @@ func (h *Handler) Mount(r chi.Router) {
 	r.Get("/api/v1/agents", h.listAgents)
 	r.Post("/api/v1/agents", h.createAgent)
 	r.Get("/api/v1/agents/{id}", h.getAgent)
+	r.Get("/api/v1/agents/{id}/tool-stats", h.getAgentToolStats)
 	r.Put("/api/v1/agents/{id}", h.updateAgent)
 	r.Delete("/api/v1/agents/{id}", h.deleteAgent)
 	r.Get("/api/v1/projects", h.listProjects)
@@ -101,6 +193,47 @@ func (h *Handler) Mount(r chi.Router) {
 	r.Get("/api/v1/projects/{id}", h.getProject)
 	r.Put("/api/v1/projects/{id}", h.updateProject)
 	r.Delete("/api/v1/projects/{id}", h.deleteProject)
+	r.Get("/api/v1/projects/{id}/watch", h.getProjectWatch)
+	r.Get("/api/v1/projects/{id}/usage", h.getProjectUsage)
+	r.Get("/api/v1/schedules", h.listSchedules)
+	r.Post("/api/v1/schedules", h.createSchedule)
+	r.Get("/api/v1/schedules/{id}", h.getSchedule)
+	r.Put("/api/v1/schedules/{id}", h.updateSchedule)
+	r.Delete("/api/v1/schedules/{id}", h.deleteSchedule)
+	r.Post("/api/v1/schedules/{id}/run", h.runSchedule)
+	r.Get("/api/v1/missions", h.listMissions)
+	r.Post("/api/v1/missions", h.createMission)
+	r.Get("/api/v1/missions/{id}", h.getMission)
+	r.Put("/api/v1/missions/{id}", h.updateMission)
+	r.Delete("/api/v1/missions/{id}", h.deleteMission)
+	r.Post("/api/v1/missions/{id}/sessions", h.addMissionSession)
+	r.Delete("/api/v1/missions/{id}/sessions/{sessionID}", h.removeMissionSession)
+	r.Get("/api/v1/missions/{id}/log", h.getMissionLog)
+	r.Post("/api/v1/missions/{id}/log", h.appendMissionLog)
+	r.Get("/api/v1/pipelines", h.listPipelines)
+	r.Post("/api/v1/pipelines", h.createPipeline)
+	r.Get("/api/v1/pipelines/{id}", h.getPipeline)
+	r.Put("/api/v1/pipelines/{id}", h.updatePipeline)
+	r.Delete("/api/v1/pipelines/{id}", h.deletePipeline)
+	r.Get("/api/v1/pipelines/{id}/runs", h.listPipelineRuns)
+	r.Post("/api/v1/pipelines/{id}/runs", h.startPipelineRun)
+	r.Get("/api/v1/pipelines/{id}/runs/{runID}", h.getPipelineRun)
+	r.Get("/api/v1/templates", h.listTemplates)
+	r.Post("/api/v1/templates", h.createTemplate)
+	r.Get("/api/v1/templates/{id}", h.getTemplate)
+	r.Put("/api/v1/templates/{id}", h.updateTemplate)
+	r.Delete("/api/v1/templates/{id}", h.deleteTemplate)
+	r.Post("/api/v1/mcp/validate", h.validateMCPServer)
+	r.Get("/api/v1/admin/cleanup", h.getCleanupStatus)
+	r.Post("/api/v1/admin/cleanup/run", h.runCleanup)
+	r.Get("/api/v1/admin/recovery", h.getRecoveryReport)
+	r.Get("/api/v1/admin/mirror", h.getMirrorStatus)
+	r.Get("/api/v1/admin/integrity", h.checkIntegrity)
+	r.Post("/api/v1/admin/integrity/repair", h.repairIntegrity)
+	r.Get("/api/v1/admin/warm-pool", h.getWarmPoolStats)
+	r.Get("/api/v1/admin/overview", h.getAdminOverview)
+	r.Post("/api/v1/dev/seed", h.devSeed)
+	h.mountEmbed(r)
 }
 
 func (h *Handler) startRealtimeBridge() {
@@ -111,29 +244,86 @@ func (h *Handler) startRealtimeBridge() {
 	sub := h.broadcaster.Subscribe(generateID(), "")
 	if h.executor != nil {
 		h.executor.RegisterTerminalObserver(realtimeTerminalObserver{handler: h})
+		h.executor.RegisterRecoveryObserver(realtimeRecoveryObserver{handler: h})
+		h.publishSessionSync()
 	}
 	go func() {
 		for event := range sub.Events {
 			if event.SessionID != "" {
-				h.realtimeHub.Publish(realtime.TopicSessionsActivity(event.SessionID), realtimeTypes.ServerEnvelope{
+				h.realtimeHub.PublishVisible(realtime.TopicSessionsActivity(event.SessionID), event.Type.String(), eventVisibleTo(event), realtimeTypes.ServerEnvelope{
 					Type:    realtimeTypes.ServerMessageTypeEvent,
 					Topic:   realtime.TopicSessionsActivity(event.SessionID),
 					Payload: h.toRealtimeSessionActivityEvent(event),
 				})
 			}
+			// Only state changes and errors need the session; one snapshot
+			// serves both the notification and the state event.
+			if event.Type != domain.EventTypeStatusChange && event.Type != domain.EventTypeError {
+				continue
+			}
+			snap := h.realtimeSnapshot(event.SessionID)
+			h.publishRealtimeNotification(event, snap)
 			if event.Type != domain.EventTypeStatusChange {
 				continue
 			}
+			h.publishRealtimeWaits()
 			h.realtimeHub.Publish(realtime.TopicSessionsState, realtimeTypes.ServerEnvelope{
 				Type:    realtimeTypes.ServerMessageTypeEvent,
 				Topic:   realtime.TopicSessionsState,
-				Payload: h.toRealtimeSessionStateEvent(event),
+				Payload: h.toRealtimeSessionStateEvent(event, snap),
 			})
 		}
 	}()
 }
 
-func (h *Handler) toRealtimeSessionStateEvent(event domain.Event) realtimeTypes.SessionStateEvent {
+// realtimeSnapshot returns the snapshot of session id, or one holding just
+// the ID when it cannot be loaded.
+func (h *Handler) realtimeSnapshot(id string) domain.SessionSnapshot {
+	if sess, err := h.executor.GetSession(id); err == nil {
+		return sess.Snapshot()
+	}
+	return domain.SessionSnapshot{ID: id}
+}
+
+// publishRealtimeNotification republishes approval requests and failures on
+// the compact notifications topic.
+func (h *Handler) publishRealtimeNotification(event domain.Event, snap domain.SessionSnapshot) {
+	var (
+		notification realtimeTypes.Notification
+		ok           bool
+	)
+	switch event.Type {
+	case domain.EventTypeStatusChange:
+		if data, isStatus := event.StatusChange(); isStatus && data.NewState == domain.SessionStateSuspended {
+			snap.State = domain.SessionStateSuspended
+			notification, ok = realtime.ApprovalNotification(snap)
+		}
+	case domain.EventTypeError:
+		notification, ok = realtime.FailureNotification(snap, event)
+	}
+	if !ok {
+		return
+	}
+
+	h.realtimeHub.Publish(realtime.TopicNotifications, realtimeTypes.ServerEnvelope{
+		Type:    realtimeTypes.ServerMessageTypeEvent,
+		Topic:   realtime.TopicNotifications,
+		Payload: notification,
+	})
+}
+
+// publishRealtimeWaits republishes the pending waits of all sessions after
+// a session's state changed.
+func (h *Handler) publishRealtimeWaits() {
+	now := time.Now()
+	h.realtimeHub.Publish(realtime.TopicWaits, realtimeTypes.ServerEnvelope{
+		Type:    realtimeTypes.ServerMessageTypeEvent,
+		Topic:   realtime.TopicWaits,
+		Payload: presentation.Waits(h.executor.PendingWaits(now), now),
+	})
+}
+
+func (h *Handler) toRealtimeSessionStateEvent(event domain.Event, snap domain.SessionSnapshot) realtimeTypes.SessionStateEvent {
 	derived := domain.SessionStateIdle
 	if state, err := h.executor.DeriveSessionState(event.SessionID); err == nil {
 		derived = state
@@ -144,10 +334,13 @@ func (h *Handler) toRealtimeSessionStateEvent(event domain.Event) realtimeTypes.
 		Timestamp:    event.Timestamp,
 		SessionID:    event.SessionID,
 		DerivedState: derived.String(),
+		Pinned:       snap.Pinned,
+		UnreadCounts: h.executor.SessionUnreadCounts(snap),
 	}
 
 	if data, ok := event.Data.(domain.StatusChangeData); ok {
 		stateEvent.Reason = data.Reason
+		stateEvent.Notice = presentation.Notice(data.Notice)
 	}
 
 	return stateEvent
@@ -160,10 +353,67 @@ func (h *Handler) toRealtimeSessionActivityEvent(event domain.Event) realtimeTyp
 		Timestamp: apiEvent.Timestamp,
 		SessionID: apiEvent.SessionID,
 		Type:      string(apiEvent.Type),
-		Data:      apiEvent.Data,
+		Version:   realtimeTypes.PayloadVersion,
+		Data:      toRealtimeActivityData(apiEvent.Data),
 	}
 }
 
+// toRealtimeActivityData converts the data of an SSE event, as
+// convertEventData builds it, into its realtime payload.
+func toRealtimeActivityData(data any) realtimeTypes.SessionActivityData {
+	switch d := data.(type) {
+	case apiTypes.StatusChangeData:
+		return realtimeTypes.StatusChangePayload(d)
+	case apiTypes.OutputData:
+		return realtimeTypes.OutputPayload(d)
+	case apiTypes.MetricData:
+		return realtimeTypes.MetricPayload(d)
+	case apiTypes.ErrorData:
+		return realtimeTypes.ErrorPayload(d)
+	case apiTypes.MetadataData:
+		return realtimeTypes.MetadataPayload(d)
+	case apiTypes.ToolCallData:
+		payload := realtimeTypes.ToolCallPayload{
+			ID:       d.ID,
+			Name:     d.Name,
+			Status:   d.Status,
+			Title:    d.Title,
+			Input:    d.Input,
+			Output:   d.Output,
+			ParentID: d.ParentID,
+		}
+		if d.Usage != nil {
+			payload.Usage = &realtimeTypes.TokenUsagePayload{InputTokens: d.Usage.InputTokens, OutputTokens: d.Usage.OutputTokens}
+		}
+		return payload
+	case apiTypes.ThoughtData:
+		return realtimeTypes.ThoughtPayload(d)
+	case apiTypes.PlanData:
+		steps := make([]realtimeTypes.PlanStepPayload, len(d.Steps))
+		for i, step := range d.Steps {
+			steps[i] = realtimeTypes.PlanStepPayload(step)
+		}
+		return realtimeTypes.PlanPayload{Description: d.Description, Steps: steps}
+	case apiTypes.ProgressData:
+		return realtimeTypes.ProgressPayload(d)
+	case apiTypes.LivenessData:
+		return realtimeTypes.LivenessPayload(d)
+	}
+	return nil
+}
+
+type realtimeRecoveryObserver struct {
+	handler *Handler
+}
+
+func (o realtimeRecoveryObserver) OnRecoveryProgress(report storage.RecoveryReport) {
+	o.handler.realtimeHub.Publish(realtime.TopicSystemRecovery, realtimeTypes.ServerEnvelope{
+		Type:    realtimeTypes.ServerMessageTypeEvent,
+		Topic:   realtime.TopicSystemRecovery,
+		Payload: presentation.RecoveryReport(&report),
+	})
+}
+
 type realtimeTerminalObserver struct {
 	handler *Handler
 }
@@ -206,62 +456,17 @@ func (h *Handler) publishRealtimeTerminalEvent(sessionID string, event service.T
 }
 
 func toRealtimeTerminalOutputEvent(terminalID, sessionID string, event service.TerminalEvent) (realtimeTypes.TerminalOutputEvent, bool) {
-	update := event.Update
-	var (
-		messageType string
-		payload     any
-	)
-
-	switch update.Kind {
-	case terminal.UpdateSnapshot:
-		messageType = "terminal.snapshot"
-		if update.Snapshot != nil {
-			payload = map[string]any{
-				"rows":  update.Snapshot.Rows,
-				"cols":  update.Snapshot.Cols,
-				"lines": update.Snapshot.Lines,
-			}
-		}
-	case terminal.UpdateDiff:
-		messageType = "terminal.diff"
-		if update.Diff != nil {
-			payload = map[string]any{
-				"region": map[string]int{
-					"x":  update.Diff.Region.X,
-					"y":  update.Diff.Region.Y,
-					"x2": update.Diff.Region.X2,
-					"y2": update.Diff.Region.Y2,
-				},
-				"lines":  update.Diff.Lines,
-				"reason": update.Diff.Reason,
-			}
-		}
-	case terminal.UpdateCursor:
-		messageType = "terminal.cursor"
-		if update.Cursor != nil {
-			payload = map[string]int{"x": update.Cursor.X, "y": update.Cursor.Y}
-		}
-	case terminal.UpdateBell:
-		messageType = "terminal.bell"
-	case terminal.UpdateError:
-		messageType = "terminal.error"
-		if update.Error != nil {
-			payload = map[string]any{
-				"code":    update.Error.Code,
-				"message": update.Error.Message,
-				"resync":  update.Error.Resync,
-			}
-		}
-	default:
+	messageType, payload, ok := terminalEventPayload(event)
+	if !ok {
 		return realtimeTypes.TerminalOutputEvent{}, false
 	}
-
 	return realtimeTypes.TerminalOutputEvent{
 		TerminalID: terminalID,
 		SessionID:  sessionID,
 		Seq:        event.Seq,
 		Timestamp:  time.Now().UTC(),
 		Type:       messageType,
+		Version:    realtimeTypes.PayloadVersion,
 		Data:       payload,
 	}, true
 }
@@ -279,13 +484,20 @@ func (h *Handler) sendSessionInput(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "input is required", "")
 		return
 	}
+	if !h.requireCapability(w, id, "steering", func(c session.Capabilities) bool { return c.Steering }) {
+		return
+	}
 
-	if err := h.executor.SendInput(r.Context(), id, req.Input, req.ProviderID, req.ProviderType); err != nil {
+	if err := h.executor.SendInput(r.Context(), id, req.Input, req.ProviderID, req.ProviderType, requestUser(r)); err != nil {
 		if errors.Is(err, service.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", err.Error())
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", err.Error())
 			return
 		}
-		writeError(w, http.StatusInternalServerError, "failed to send input", err.Error())
+		if errors.Is(err, service.ErrTakenOver) {
+			writeSessionError(w, err)
+			return
+		}
+		writeErrorCode(w, http.StatusInternalServerError, serviceErrorCode(err), "failed to send input", err.Error())
 		return
 	}
 
@@ -301,221 +513,245 @@ func (h *Handler) sendSessionMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.TemplateID != "" {
+		content, ok := h.renderMessageTemplate(w, id, req)
+		if !ok {
+			return
+		}
+		req.Content = content
+	}
 	if strings.TrimSpace(req.Content) == "" {
 		writeError(w, http.StatusBadRequest, "content is required", "")
 		return
 	}
 
-	sess, err := h.executor.SendMessage(r.Context(), id, req.Content, req.ProviderID, req.ProviderType)
-	if err != nil {
-		if errors.Is(err, service.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", err.Error())
+	var opts service.SendMessageOptions
+	if req.Deadline != "" {
+		deadline, err := time.ParseDuration(req.Deadline)
+		if err != nil || deadline <= 0 {
+			writeError(w, http.StatusBadRequest, "deadline must be a positive duration", req.Deadline)
 			return
 		}
-		writeError(w, http.StatusInternalServerError, "failed to send message", err.Error())
+		opts.Deadline = deadline
+	}
+	switch req.Priority {
+	case "", "normal":
+	case "low":
+		opts.LowPriority = true
+	default:
+		writeError(w, http.StatusBadRequest, "priority must be normal or low", req.Priority)
 		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
-	snap := sess.Snapshot()
-	if err := json.NewEncoder(w).Encode(sessionToResponse(snap)); err != nil {
-		fmt.Fprintf(w, `{"error":"failed to encode response"}`)
+	if req.TokenBudget < 0 {
+		writeError(w, http.StatusBadRequest, "token_budget must not be negative", "")
+		return
 	}
-}
-
-func (h *Handler) createSession(w http.ResponseWriter, r *http.Request) {
-	var req apiTypes.SessionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+	if !domain.ValidBudgetAction(req.OnBudget) {
+		writeError(w, http.StatusBadRequest, "on_budget must be stop or continue", req.OnBudget)
 		return
 	}
-
-	sessionKind := strings.TrimSpace(req.SessionKind)
-	if sessionKind != "" && sessionKind != domain.SessionKindDock {
-		writeError(w, http.StatusBadRequest, "invalid session_kind", "")
+	opts.TokenBudget = req.TokenBudget
+	opts.BudgetAction = req.OnBudget
+	if len(req.MessageID) > maxMessageIDLength {
+		writeError(w, http.StatusBadRequest, "message_id is too long", "")
 		return
 	}
+	opts.MessageID = req.MessageID
+	if opts.MessageID == "" {
+		opts.MessageID = generateID()
+	}
+	opts.Actor = requestUser(r)
 
-	var providerConfig *storage.ProviderConfig
-	if req.ProviderID != "" {
-		cfg, err := h.providerStorage.Get(req.ProviderID)
-		if err != nil {
-			writeError(w, http.StatusNotFound, "provider not found", err.Error())
+	sess, err := h.executor.SendMessageWithOptions(r.Context(), id, req.Content, req.ProviderID, req.ProviderType, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", err.Error())
 			return
 		}
-		providerConfig = cfg
-		if req.ProviderType == "" {
-			req.ProviderType = cfg.Type
-		} else if req.ProviderType != cfg.Type {
-			writeError(w, http.StatusBadRequest, "provider_type does not match provider config", "")
+		if errors.Is(err, service.ErrTakenOver) || errors.Is(err, service.ErrImportedSession) {
+			writeSessionError(w, err)
 			return
 		}
-	}
-
-	// Resolve working directory: explicit > project path > git dir
-	workingDir := req.WorkingDir
-	projectID := req.ProjectID
-	if projectID != "" && h.projectStorage != nil {
-		proj, err := h.projectStorage.Get(projectID)
-		if err != nil {
-			writeError(w, http.StatusNotFound, "project not found", err.Error())
+		if errors.Is(err, domain.ErrMessageQueueFull) {
+			writeError(w, http.StatusConflict, err.Error(), "")
 			return
 		}
-		if workingDir == "" {
-			workingDir = proj.Path
+		var conflict *service.WorkingDirConflictError
+		if errors.As(err, &conflict) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(apiTypes.ErrorResponse{
+				Error: "working directory is in use by another session",
+				Code:  apiTypes.ErrorCodeWorkingDirBusy,
+				Details: apiTypes.WorkingDirConflict{
+					WorkingDir:           conflict.WorkingDir,
+					ConflictingSessionID: conflict.SessionID,
+				},
+			})
+			return
 		}
-	}
-	if workingDir == "" {
-		workingDir = h.gitDir
-	}
-	if workingDir == "" {
-		writeError(w, http.StatusBadRequest, "working_dir is required", "")
+		writeErrorCode(w, http.StatusInternalServerError, serviceErrorCode(err), "failed to send message", err.Error())
 		return
 	}
 
-	// Resolve optional agent config — merge its values as defaults (request fields take priority).
-	var agentConfig *storage.AgentConfig
-	if req.AgentID != "" && h.agentStorage != nil {
-		cfg, err := h.agentStorage.Get(req.AgentID)
-		if err != nil {
-			writeError(w, http.StatusNotFound, "agent not found", err.Error())
-			return
-		}
-		agentConfig = cfg
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	snap := sess.Snapshot()
+	resp := apiTypes.SendMessageResponse{SessionResponse: sessionToResponse(snap), MessageID: opts.MessageID}
+	if pos := sess.QueuePosition(opts.MessageID); pos > 0 {
+		resp.Queued = true
+		resp.QueuePosition = pos
 	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		fmt.Fprintf(w, `{"error":"failed to encode response"}`)
+	}
+}
 
-	id := generateID()
-
-	config := session.Config{
-		ProviderType: req.ProviderType,
-		AgentID:      req.AgentID,
-		WorkingDir:   workingDir,
-		ProjectID:    projectID,
-		Environment:  req.Environment,
-		SystemPrompt: req.SystemPrompt,
-		Custom:       req.Custom,
-		TaskID:       req.TaskID,
-		TaskTitle:    req.TaskTitle,
-		SessionKind:  sessionKind,
-		Title:        req.Title,
-	}
-
-	// Apply agent config defaults (agent values only fill gaps left by the request).
-	if agentConfig != nil {
-		if config.SystemPrompt == "" && agentConfig.SystemPrompt != "" {
-			config.SystemPrompt = agentConfig.SystemPrompt
-		}
-		if len(agentConfig.Custom) > 0 {
-			if config.Custom == nil {
-				config.Custom = map[string]any{}
-			}
-			for k, v := range agentConfig.Custom {
-				if _, ok := config.Custom[k]; !ok {
-					config.Custom[k] = v
-				}
-			}
-		}
-		// Agent MCP servers are only used when the request doesn't supply its own list
-		// and the session is not a dock session (dock servers are always overridden below).
-		if len(req.MCPServers) == 0 && sessionKind != domain.SessionKindDock && len(agentConfig.MCPServers) > 0 {
-			config.MCPServers = agentConfig.MCPServers
-		}
+func (h *Handler) createSession(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
 	}
 
-	if providerConfig != nil {
-		if len(providerConfig.Env) > 0 {
-			if config.Environment == nil {
-				config.Environment = map[string]string{}
-			}
-			for k, v := range providerConfig.Env {
-				if _, ok := config.Environment[k]; !ok {
-					config.Environment[k] = v
-				}
-			}
-		}
-		if providerConfig.APIKey != "" {
-			if config.Environment == nil {
-				config.Environment = map[string]string{}
-			}
-			envKey := ""
-			switch providerConfig.Type {
-			case "adk":
-				envKey = "GOOGLE_API_KEY"
-			case "anthropic", "claude", "claude-ws", "acp":
-				envKey = "ANTHROPIC_API_KEY"
-			case "openai":
-				envKey = "OPENAI_API_KEY"
-			}
-			if envKey != "" {
-				if _, ok := config.Environment[envKey]; !ok {
-					config.Environment[envKey] = providerConfig.APIKey
-				}
-			}
-		}
-		if len(providerConfig.Custom) > 0 {
-			if config.Custom == nil {
-				config.Custom = map[string]any{}
-			}
-			for k, v := range providerConfig.Custom {
-				if _, ok := config.Custom[k]; !ok {
-					config.Custom[k] = v
-				}
-			}
-		}
-		if providerConfig.Type == "pty" && len(providerConfig.Command) > 0 {
-			if config.Custom == nil {
-				config.Custom = map[string]any{}
-			}
-			if _, ok := config.Custom["command"]; !ok {
-				config.Custom["command"] = providerConfig.Command[0]
-			}
-			if len(providerConfig.Command) > 1 {
-				if _, ok := config.Custom["args"]; !ok {
-					config.Custom["args"] = providerConfig.Command[1:]
-				}
-			}
+	if req.MissionID != "" {
+		if _, err := h.executor.Mission(req.MissionID); err != nil {
+			writeMissionError(w, err)
+			return
 		}
 	}
-	if sessionKind == domain.SessionKindDock {
-		config.MCPServers = dockMCPServers(id)
-	} else if len(req.MCPServers) > 0 {
-		config.MCPServers = make([]session.MCPServerConfig, len(req.MCPServers))
-		for i, s := range req.MCPServers {
-			config.MCPServers[i] = session.MCPServerConfig{
-				Name:    s.Name,
-				Command: s.Command,
-				Args:    s.Args,
-				Env:     s.Env,
-			}
-		}
+	config, reqErr := h.resolveSessionRequest(req)
+	if reqErr != nil {
+		reqErr.write(w)
+		return
 	}
 
+	id := generateID()
 	session, err := h.executor.CreateSession(r.Context(), id, config)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrSessionExists):
-			writeError(w, http.StatusConflict, "session already exists", err.Error())
+			writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeSessionExists, "session already exists", err.Error())
 		case errors.Is(err, service.ErrProviderNotFound):
-			writeError(w, http.StatusBadRequest, "unknown provider type", err.Error())
+			writeErrorCode(w, http.StatusBadRequest, apiTypes.ErrorCodeProviderNotFound, "unknown provider type", err.Error())
+		case errors.Is(err, service.ErrInvalidWorkspace):
+			writeError(w, http.StatusBadRequest, "invalid workspace", err.Error())
+		case errors.Is(err, service.ErrInvalidGitBranch):
+			writeError(w, http.StatusBadRequest, "invalid git branch", err.Error())
 		default:
-			writeError(w, http.StatusInternalServerError, "failed to create session", err.Error())
+			writeErrorCode(w, http.StatusInternalServerError, serviceErrorCode(err), "failed to create session", err.Error())
 		}
 		return
 	}
+	if req.MissionID != "" {
+		if _, err := h.executor.AddMissionSession(req.MissionID, id, ""); err != nil {
+			writeMissionError(w, err)
+			return
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(sessionToResponse(session.Snapshot()))
 }
 
+// sessionRequestError is why a SessionRequest could not be resolved.
+type sessionRequestError struct {
+	status  int
+	code    apiTypes.ErrorCode
+	message string
+	details string
+}
+
+func (e *sessionRequestError) write(w http.ResponseWriter) {
+	code := e.code
+	if code == "" {
+		code = errorCodeForStatus(e.status)
+	}
+	writeErrorCode(w, e.status, code, e.message, e.details)
+}
+
+// resolveSessionRequest validates req and resolves it into a session config,
+// merging in its template, provider config, project and agent. Sessions
+// without a working directory or project run in the server's git directory.
+func (h *Handler) resolveSessionRequest(req apiTypes.SessionRequest) (session.Config, *sessionRequestError) {
+	sessionKind := strings.TrimSpace(req.SessionKind)
+	if sessionKind != "" && sessionKind != domain.SessionKindDock {
+		return session.Config{}, &sessionRequestError{status: http.StatusBadRequest, message: "invalid session_kind"}
+	}
+	toolApproval, err := parseToolApproval(req.ToolApproval)
+	if err != nil {
+		return session.Config{}, &sessionRequestError{status: http.StatusBadRequest, message: "invalid tool_approval", details: err.Error()}
+	}
+	workingDir := req.WorkingDir
+	if workingDir == "" && req.ProjectID == "" {
+		workingDir = h.gitDir
+	}
+
+	sreq := service.SessionRequest{
+		ProviderType:      req.ProviderType,
+		ProviderID:        req.ProviderID,
+		AgentID:           req.AgentID,
+		TemplateID:        req.TemplateID,
+		TemplateVariables: req.TemplateVariables,
+		WorkingDir:        workingDir,
+		ProjectID:         req.ProjectID,
+		Environment:       req.Environment,
+		SystemPrompt:      req.SystemPrompt,
+		Custom:            req.Custom,
+		TaskID:            req.TaskID,
+		TaskTitle:         req.TaskTitle,
+		SessionKind:       sessionKind,
+		Title:             req.Title,
+		RecoveryPolicy:    req.RecoveryPolicy,
+		PlanApproval:      req.PlanApproval,
+		ToolApproval:      toolApproval,
+		CostBudget:        costBudgetFromAPI(req.CostBudget),
+		Metadata:          req.Metadata,
+		Features:          req.Features,
+	}
+	if req.CommandApproval != nil {
+		sreq.CommandApproval = &domain.CommandApproval{AutoApprove: req.CommandApproval.AutoApprove}
+	}
+	for _, s := range req.MCPServers {
+		sreq.MCPServers = append(sreq.MCPServers, session.MCPServerConfig{Name: s.Name, Command: s.Command, Args: s.Args, Env: s.Env})
+	}
+	if ws := req.Workspace; ws != nil {
+		sreq.Workspace = &domain.Workspace{Mode: ws.Mode, Repo: ws.Repo, BaseRef: ws.BaseRef, Branch: ws.Branch}
+	}
+	if req.Git != nil {
+		sreq.Git = &domain.GitTracking{Branch: req.Git.Branch}
+	}
+
+	config, err := h.executor.ResolveSessionRequest(sreq)
+	if err != nil {
+		var reqErr *service.SessionRequestError
+		if !errors.As(err, &reqErr) {
+			return session.Config{}, templateRequestError(err)
+		}
+		var code apiTypes.ErrorCode
+		switch reqErr.Missing {
+		case "":
+			return session.Config{}, &sessionRequestError{status: http.StatusBadRequest, message: reqErr.Message, details: reqErr.Details}
+		case "provider":
+			code = apiTypes.ErrorCodeProviderNotFound
+		case "project":
+			code = apiTypes.ErrorCodeProjectNotFound
+		case "agent":
+			code = apiTypes.ErrorCodeAgentNotFound
+		}
+		return session.Config{}, &sessionRequestError{status: http.StatusNotFound, code: code, message: reqErr.Message, details: reqErr.Details}
+	}
+	return config, nil
+}
+
 func (h *Handler) getSession(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	session, err := h.executor.GetSession(id)
 	if err != nil {
 		if errors.Is(err, service.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to get session", err.Error())
@@ -530,12 +766,92 @@ func (h *Handler) getSession(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Enrich with live provider metrics when available.
+	user := requestUser(r)
+	waitSet, _ := h.executor.SessionWaitSet(id)
 	status, err := h.executor.GetSessionStatus(id)
 	if err != nil {
-		_ = json.NewEncoder(w).Encode(sessionToResponse(snap))
+		resp := h.sessionResponseFor(snap, user)
+		resp.WaitSet = waitSetToAPI(waitSet)
+		_ = json.NewEncoder(w).Encode(resp)
 		return
 	}
-	_ = json.NewEncoder(w).Encode(sessionToStatusResponse(snap, status))
+	resp := sessionToStatusResponse(snap, status)
+	h.applyReadState(&resp.SessionResponse, snap, user)
+	resp.WaitSet = waitSetToAPI(waitSet)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) updateSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req apiTypes.SessionUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if req.RecoveryPolicy != nil {
+		if _, err := service.ParseRecoveryPolicy(*req.RecoveryPolicy); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid recovery_policy", err.Error())
+			return
+		}
+	}
+	costBudget := costBudgetFromAPI(req.CostBudget)
+	if err := costBudget.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid cost_budget", err.Error())
+		return
+	}
+
+	session, err := h.executor.GetSession(id)
+	if req.Pinned != nil {
+		session, err = h.executor.SetSessionPinned(id, *req.Pinned)
+	}
+	if err == nil && req.RecoveryPolicy != nil {
+		policy, _ := service.ParseRecoveryPolicy(*req.RecoveryPolicy)
+		session, err = h.executor.SetSessionRecoveryPolicy(id, policy)
+	}
+	if err == nil && req.CostBudget != nil {
+		session, err = h.executor.SetSessionCostBudget(id, costBudget)
+	}
+	if err == nil && req.Metadata != nil {
+		session, err = h.executor.PatchSessionMetadata(id, req.Metadata)
+	}
+	if errors.Is(err, domain.ErrInvalidMetadata) {
+		writeError(w, http.StatusBadRequest, "invalid metadata", err.Error())
+		return
+	}
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	snap := session.Snapshot()
+	if derivedState, derr := h.executor.DeriveSessionState(id); derr == nil {
+		snap.State = derivedState
+	}
+
+	if req.Pinned != nil && h.realtimeHub != nil {
+		reason := "unpinned"
+		if snap.Pinned {
+			reason = "pinned"
+		}
+		h.realtimeHub.Publish(realtime.TopicSessionsState, realtimeTypes.ServerEnvelope{
+			Type:  realtimeTypes.ServerMessageTypeEvent,
+			Topic: realtime.TopicSessionsState,
+			Payload: realtimeTypes.SessionStateEvent{
+				Timestamp:    snap.UpdatedAt,
+				SessionID:    id,
+				DerivedState: snap.State.String(),
+				Reason:       reason,
+				Pinned:       snap.Pinned,
+				UnreadCounts: h.executor.SessionUnreadCounts(snap),
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(h.sessionResponseFor(snap, requestUser(r)))
 }
 
 func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request) {
@@ -544,25 +860,43 @@ func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request) {
 	// Optional filter: ?project_id=<id> (empty string = sessions with no project)
 	filterByProject := r.URL.Query().Has("project_id")
 	projectID := r.URL.Query().Get("project_id")
+	// Optional filter: ?pinned=true|false
+	filterByPinned := r.URL.Query().Has("pinned")
+	pinned := r.URL.Query().Get("pinned") == "true"
+	// Optional filter: ?metadata.<key>=<value>, exact matches
+	metadata := metadataFilter(r.URL.Query())
+	// Optional filter: ?mission_id=<id>
+	filterByMission := r.URL.Query().Has("mission_id")
+	missionID := r.URL.Query().Get("mission_id")
 
 	var filtered []*domain.Session
 	for _, s := range allSessions {
 		if filterByProject && s.ProjectID != projectID {
 			continue
 		}
+		if filterByPinned && s.IsPinned() != pinned {
+			continue
+		}
+		if !domain.MatchesMetadata(s.GetMetadata(), metadata) {
+			continue
+		}
+		if filterByMission && s.GetMissionID() != missionID {
+			continue
+		}
 		filtered = append(filtered, s)
 	}
 	if filtered == nil {
 		filtered = []*domain.Session{}
 	}
 
+	user := requestUser(r)
 	responses := make([]apiTypes.SessionResponse, len(filtered))
 	for i, s := range filtered {
 		snap := s.Snapshot()
 		if derivedState, err := h.executor.DeriveSessionState(s.ID); err == nil {
 			snap.State = derivedState
 		}
-		responses[i] = sessionToResponse(snap)
+		responses[i] = h.sessionResponseFor(snap, user)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -572,6 +906,23 @@ func (h *Handler) listSessions(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// metadataFilter collects the metadata.<key>=<value> parameters of a list or
+// search query.
+func metadataFilter(params url.Values) map[string]string {
+	var filter map[string]string
+	for name, values := range params {
+		key, ok := strings.CutPrefix(name, "metadata.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if filter == nil {
+			filter = make(map[string]string)
+		}
+		filter[key] = values[0]
+	}
+	return filter
+}
+
 func (h *Handler) listTerminals(w http.ResponseWriter, r *http.Request) {
 	terminals := h.executor.ListTerminals()
 	responses := make([]apiTypes.TerminalResponse, len(terminals))
@@ -589,7 +940,7 @@ func (h *Handler) getTerminal(w http.ResponseWriter, r *http.Request) {
 	term, err := h.executor.GetTerminal(id)
 	if err != nil {
 		if errors.Is(err, storage.ErrTerminalNotFound) {
-			writeError(w, http.StatusNotFound, "terminal not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeTerminalNotFound, "terminal not found", "")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to get terminal", err.Error())
@@ -609,14 +960,31 @@ func (h *Handler) stopSession(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+const defaultMessageLimit = 200
+const maxMessageLimit = 1000
+
 func (h *Handler) getSessionMessages(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	// Get all messages from storage
-	messages, err := h.sessionStorage.GetMessages(id)
+	offset, limit, paged, err := parseMessagePage(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid pagination", err.Error())
+		return
+	}
+
+	// Get the requested page, or all messages without one
+	var messages []domain.Message
+	var more bool
+	if !paged {
+		messages, err = h.sessionStorage.GetMessages(id)
+	} else if pager, ok := h.sessionStorage.(storage.MessagePager); ok {
+		messages, more, err = pager.GetMessagesPage(id, offset, limit)
+	} else if messages, err = h.sessionStorage.GetMessages(id); err == nil {
+		messages, more = storage.PageMessages(messages, offset, limit)
+	}
 	if err != nil {
 		if errors.Is(err, storage.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to get messages", err.Error())
@@ -633,6 +1001,14 @@ func (h *Handler) getSessionMessages(w http.ResponseWriter, r *http.Request) {
 		}
 		sinceTime = &t
 	}
+	profile, err := visibilityProfileFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid profile parameter", err.Error())
+		return
+	}
+	if profile != nil {
+		messages = profile.FilterMessages(messages)
+	}
 
 	// Convert messages to API format and filter by timestamp if needed
 	apiMessages := make([]apiTypes.Message, 0, len(messages))
@@ -646,14 +1022,43 @@ func (h *Handler) getSessionMessages(w http.ResponseWriter, r *http.Request) {
 			Kind:      string(msg.Kind),
 			Contents:  msg.Contents,
 			Timestamp: msg.Timestamp,
+			Redacted:  msg.Redacted,
+			Notice:    presentation.Notice(msg.Notice),
 		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(apiTypes.MessageListResponse{
-		Messages: apiMessages,
-	})
+	resp := apiTypes.MessageListResponse{Messages: apiMessages}
+	if more {
+		next := strconv.Itoa(offset + limit)
+		resp.NextCursor = &next
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// parseMessagePage reads the limit and cursor query parameters of the
+// messages endpoint. The cursor is the offset of the page's first message;
+// without either parameter every message is returned.
+func parseMessagePage(r *http.Request) (offset, limit int, paged bool, err error) {
+	query := r.URL.Query()
+	rawLimit, rawCursor := query.Get("limit"), query.Get("cursor")
+	if rawLimit == "" && rawCursor == "" {
+		return 0, 0, false, nil
+	}
+	limit = defaultMessageLimit
+	if rawLimit != "" {
+		if limit, err = strconv.Atoi(rawLimit); err != nil || limit <= 0 {
+			return 0, 0, false, errors.New("limit must be a positive integer")
+		}
+		limit = min(limit, maxMessageLimit)
+	}
+	if rawCursor != "" {
+		if offset, err = strconv.Atoi(rawCursor); err != nil || offset < 0 {
+			return 0, 0, false, errors.New("cursor must be a non-negative integer")
+		}
+	}
+	return offset, limit, true, nil
 }
 
 func (h *Handler) cancelSession(w http.ResponseWriter, r *http.Request) {
@@ -688,21 +1093,64 @@ func (h *Handler) resumeSession(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(sessionToResponse(sess.Snapshot()))
 }
 
+// requireCapability answers 501 if the session's provider lacks the named
+// capability, so unsupported requests fail before reaching the runner. It
+// reports whether the request may proceed; lookup errors are left for the
+// operation itself to surface.
+func (h *Handler) requireCapability(w http.ResponseWriter, id, name string, supported func(session.Capabilities) bool) bool {
+	caps, err := h.executor.SessionCapabilities(id)
+	if err != nil || supported(caps) {
+		return true
+	}
+	writeErrorCode(w, http.StatusNotImplemented, apiTypes.ErrorCodeCapabilityUnsupported,
+		"provider does not support "+name, service.ErrCapabilityUnsupported.Error())
+	return false
+}
+
 // writeSessionError maps common executor errors to HTTP responses.
 func writeSessionError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, service.ErrSessionNotFound):
-		writeError(w, http.StatusNotFound, "session not found", "")
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 	case errors.Is(err, service.ErrInvalidState):
-		writeError(w, http.StatusConflict, err.Error(), "")
+		writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeInvalidState, err.Error(), "")
 	case errors.Is(err, service.ErrInvalidResumeToken):
-		writeError(w, http.StatusUnauthorized, "invalid resume token", "")
+		writeErrorCode(w, http.StatusUnauthorized, apiTypes.ErrorCodeInvalidResumeToken, "invalid resume token", "")
 	case errors.Is(err, service.ErrExpiredResumeToken):
-		writeError(w, http.StatusGone, "expired resume token", "")
+		writeErrorCode(w, http.StatusGone, apiTypes.ErrorCodeExpiredResumeToken, "expired resume token", "")
 	case errors.Is(err, service.ErrRevokedResumeToken):
-		writeError(w, http.StatusGone, "revoked resume token", "")
+		writeErrorCode(w, http.StatusGone, apiTypes.ErrorCodeRevokedResumeToken, "revoked resume token", "")
+	case errors.Is(err, service.ErrReadOnlyMirror):
+		writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeReadOnlyMirror, err.Error(), "")
+	case errors.Is(err, service.ErrImportedSession):
+		writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeReadOnlySession, err.Error(), "")
+	case errors.Is(err, service.ErrTakenOver):
+		writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeSessionTakenOver, err.Error(), "")
 	default:
-		writeError(w, http.StatusInternalServerError, err.Error(), "")
+		writeErrorCode(w, http.StatusInternalServerError, serviceErrorCode(err), err.Error(), "")
+	}
+}
+
+// serviceErrorCode picks the most specific code for an executor error that
+// has no dedicated status mapping.
+func serviceErrorCode(err error) apiTypes.ErrorCode {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound):
+		return apiTypes.ErrorCodeSessionNotFound
+	case errors.Is(err, service.ErrSessionExists):
+		return apiTypes.ErrorCodeSessionExists
+	case errors.Is(err, service.ErrInvalidState):
+		return apiTypes.ErrorCodeInvalidState
+	case errors.Is(err, service.ErrProviderNotFound):
+		return apiTypes.ErrorCodeProviderUnavailable
+	case errors.Is(err, service.ErrWorkingDirBusy):
+		return apiTypes.ErrorCodeWorkingDirBusy
+	case errors.Is(err, service.ErrOperationTimeout):
+		return apiTypes.ErrorCodeOperationTimeout
+	case errors.Is(err, service.ErrExecutorShutdown):
+		return apiTypes.ErrorCodeShuttingDown
+	default:
+		return apiTypes.ErrorCodeInternal
 	}
 }
 
@@ -751,29 +1199,78 @@ func sessionToStatusResponse(s domain.SessionSnapshot, status session.Status) ap
 			TokensOut:      status.Metrics.TokensOut,
 			RequestCount:   status.Metrics.RequestCount,
 			LastActivityAt: status.Metrics.LastActivityAt,
+			ContextWindow:  contextWindowToAPI(s.ContextWindow),
 		},
 	}
 }
 
-func dockMCPServers(sessionID string) []session.MCPServerConfig {
-	return []session.MCPServerConfig{
-		{
-			Name:    "orbitmesh-mcp",
-			Command: "orbitmesh-mcp",
-			Args:    []string{"dock"},
-			Env: map[string]string{
-				"ORBITMESH_DOCK_SESSION_ID": sessionID,
-			},
-		},
+func contextWindowToAPI(u *domain.ContextWindowUsage) *apiTypes.ContextWindowUsage {
+	if u == nil {
+		return nil
+	}
+	return &apiTypes.ContextWindowUsage{
+		Model:       u.Model,
+		UsedTokens:  u.UsedTokens,
+		LimitTokens: u.LimitTokens,
+		Percent:     u.Fraction() * 100,
+		NearLimit:   u.NearLimit(),
+		UpdatedAt:   u.UpdatedAt,
 	}
 }
 
-func writeError(w http.ResponseWriter, code int, message, details string) {
+func waitSetToAPI(ws *service.WaitSet) *apiTypes.SessionWaitSet {
+	if ws == nil {
+		return nil
+	}
+	waits := make([]apiTypes.SessionWait, len(ws.Waits))
+	for i, wait := range ws.Waits {
+		waits[i] = apiTypes.SessionWait{
+			Ref:         wait.Ref,
+			Fulfilled:   wait.FulfilledAt != nil,
+			FulfilledAt: wait.FulfilledAt,
+		}
+	}
+	return &apiTypes.SessionWaitSet{Kind: ws.Kind, Quorum: ws.Quorum, Waits: waits}
+}
+
+// writeError writes an ErrorResponse with the generic code for status.
+func writeError(w http.ResponseWriter, status int, message, details string) {
+	writeErrorCode(w, status, errorCodeForStatus(status), message, details)
+}
+
+func writeErrorCode(w http.ResponseWriter, status int, code apiTypes.ErrorCode, message, details string) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	resp := apiTypes.ErrorResponse{Error: message}
+	w.WriteHeader(status)
+	resp := apiTypes.ErrorResponse{Error: message, Code: code}
 	if details != "" {
 		resp.Details = details
 	}
 	_ = json.NewEncoder(w).Encode(resp)
 }
+
+func errorCodeForStatus(status int) apiTypes.ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return apiTypes.ErrorCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return apiTypes.ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return apiTypes.ErrorCodeForbidden
+	case http.StatusNotFound:
+		return apiTypes.ErrorCodeNotFound
+	case http.StatusConflict:
+		return apiTypes.ErrorCodeConflict
+	case http.StatusGone:
+		return apiTypes.ErrorCodeGone
+	case http.StatusTooManyRequests:
+		return apiTypes.ErrorCodeRateLimited
+	case http.StatusNotImplemented:
+		return apiTypes.ErrorCodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return apiTypes.ErrorCodeUnavailable
+	case http.StatusGatewayTimeout:
+		return apiTypes.ErrorCodeTimeout
+	default:
+		return apiTypes.ErrorCodeInternal
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// startHandoff has the session's current provider summarize its working
+// context, then continues the session on another provider from that summary.
+// Given a target session instead, it hands that session a message.
+func (h *Handler) startHandoff(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.HandoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.TargetSessionID != "" {
+		h.handOffToSession(w, r, req)
+		return
+	}
+	if req.ProviderID != "" {
+		cfg, err := h.providerStorage.Get(req.ProviderID)
+		if err != nil {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProviderNotFound, "provider not found", err.Error())
+			return
+		}
+		if req.ProviderType == "" {
+			req.ProviderType = cfg.Type
+		} else if req.ProviderType != cfg.Type {
+			writeError(w, http.StatusBadRequest, "provider_type does not match provider config", "")
+			return
+		}
+	}
+
+	handoff, err := h.executor.StartHandoff(r.Context(), chi.URLParam(r, "id"), req.ProviderType, req.ProviderID, req.Instructions, requestUser(r))
+	if err != nil {
+		writeHandoffError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(handoffToAPI(handoff))
+}
+
+// handOffToSession posts the session's message to the target session.
+func (h *Handler) handOffToSession(w http.ResponseWriter, r *http.Request, req apiTypes.HandoffRequest) {
+	if req.ProviderType != "" || req.ProviderID != "" {
+		writeError(w, http.StatusBadRequest, "target_session_id cannot be combined with provider_type or provider_id", "")
+		return
+	}
+	msg, err := h.executor.SendToSession(r.Context(), chi.URLParam(r, "id"), req.TargetSessionID, req.Message, req.Instructions, requestUser(r))
+	if err != nil {
+		writeHandoffError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(apiTypes.HandoffMessageResponse{
+		SessionID:       msg.FromSessionID,
+		TargetSessionID: msg.ToSessionID,
+		MessageID:       msg.MessageID,
+		Content:         msg.Content,
+	})
+}
+
+// getHandoff returns the session's latest handoff.
+func (h *Handler) getHandoff(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.executor.GetSession(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	handoff := sess.GetHandoff()
+	if handoff == nil {
+		writeError(w, http.StatusNotFound, "session has no handoff", "")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(handoffToAPI(handoff))
+}
+
+func writeHandoffError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidHandoff):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, service.ErrProviderNotFound):
+		writeErrorCode(w, http.StatusBadRequest, apiTypes.ErrorCodeProviderNotFound, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+func handoffToAPI(h *domain.Handoff) apiTypes.HandoffResponse {
+	resp := apiTypes.HandoffResponse{
+		Status:           h.Status,
+		FromProviderType: h.FromProviderType,
+		FromProviderID:   h.FromProviderID,
+		ToProviderType:   h.ToProviderType,
+		ToProviderID:     h.ToProviderID,
+		Instructions:     h.Instructions,
+		RequestedBy:      h.RequestedBy,
+		RequestedAt:      h.RequestedAt,
+		CompletedAt:      h.CompletedAt,
+		Error:            h.Error,
+	}
+	if d := h.Document; d != nil {
+		resp.Document = &apiTypes.HandoffDocument{
+			Summary:       d.Summary,
+			OpenQuestions: d.OpenQuestions,
+			Decisions:     d.Decisions,
+			Files:         d.Files,
+			NextSteps:     d.NextSteps,
+		}
+	}
+	return resp
+}
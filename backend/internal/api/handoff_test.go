@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestHandoff_SummarizesAndStartsNextProvider(t *testing.T) {
+	var mu sync.Mutex
+	mocks := map[string]*mockProvider{}
+	env := &testEnv{broadcaster: service.NewEventBroadcaster(100), store: newInMemStore()}
+	env.executor = service.NewAgentExecutor(service.ExecutorConfig{
+		Storage:         env.store,
+		TerminalStorage: env.store,
+		Broadcaster:     env.broadcaster,
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			if providerType != "mock" && providerType != "mock-b" {
+				return nil, fmt.Errorf("unsupported provider: %s", providerType)
+			}
+			m := newMockProvider()
+			if sessionID != "" {
+				mu.Lock()
+				mocks[providerType] = m
+				mu.Unlock()
+			}
+			return m, nil
+		},
+	})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = env.executor.Shutdown(ctx)
+	})
+	env.handler = NewHandler(env.executor, env.broadcaster, env.store, storage.NewProviderConfigStorage(t.TempDir()), nil, nil)
+	r := env.router()
+	created := createSession(t, r, "mock", t.TempDir())
+
+	do := func(method string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, "/api/sessions/"+created.ID+"/handoff", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(http.MethodGet, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before any handoff, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, apiTypes.HandoffRequest{ProviderType: "mock"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for the current provider, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, apiTypes.HandoffRequest{ProviderType: "nope"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown provider, got %d", w.Code)
+	}
+
+	w := do(http.MethodPost, apiTypes.HandoffRequest{ProviderType: "mock-b", Instructions: "Finish the tests."})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.HandoffResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Status != domain.HandoffSummarizing || resp.FromProviderType != "mock" || resp.ToProviderType != "mock-b" {
+		t.Fatalf("unexpected handoff: %+v", resp)
+	}
+
+	sess, err := env.executor.GetSession(created.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	waitForState(t, sess, domain.SessionStateRunning)
+	mu.Lock()
+	from := mocks["mock"]
+	mu.Unlock()
+	from.events <- domain.NewOutputEvent(created.ID, "Added pagination.\n\n## Open questions\n- Cursor or offset?\n\n## Files\n- internal/api/handler.go\n", nil)
+	close(from.events)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sess.GetHandoff().Status == domain.HandoffSummarizing && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	w = do(http.MethodGet, nil)
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Status != domain.HandoffStarted || resp.Document == nil {
+		t.Fatalf("expected a started handoff with a document, got %+v", resp)
+	}
+	if resp.Document.Summary != "Added pagination." || len(resp.Document.OpenQuestions) != 1 || len(resp.Document.Files) != 1 {
+		t.Fatalf("unexpected document: %+v", resp.Document)
+	}
+	if got := sess.Snapshot().ProviderType; got != "mock-b" {
+		t.Fatalf("expected the session to switch to mock-b, got %q", got)
+	}
+
+	mu.Lock()
+	to := mocks["mock-b"]
+	mu.Unlock()
+	if to == nil {
+		t.Fatal("expected a run on mock-b")
+	}
+	to.mu.Lock()
+	input := to.lastInput
+	to.mu.Unlock()
+	for _, want := range []string{"Cursor or offset?", "internal/api/handler.go", "Finish the tests."} {
+		if !strings.Contains(input, want) {
+			t.Fatalf("expected the next run's prompt to contain %q, got %q", want, input)
+		}
+	}
+}
+
+func TestHandoff_ToSession(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	developer := createSession(t, r, "mock", t.TempDir())
+	reviewer := createSession(t, r, "mock", t.TempDir())
+
+	post := func(body apiTypes.HandoffRequest) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions/"+developer.ID+"/handoff", bytes.NewReader(data)))
+		return w
+	}
+	for name, req := range map[string]apiTypes.HandoffRequest{
+		"itself":          {TargetSessionID: developer.ID, Message: "hi"},
+		"missing target":  {TargetSessionID: "nope", Message: "hi"},
+		"no output":       {TargetSessionID: reviewer.ID},
+		"with a provider": {TargetSessionID: reviewer.ID, ProviderType: "mock", Message: "hi"},
+	} {
+		if w := post(req); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	w := post(apiTypes.HandoffRequest{TargetSessionID: reviewer.ID, Message: "Refactored the parser.", Instructions: "Review it."})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.HandoffMessageResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.MessageID == "" || resp.TargetSessionID != reviewer.ID || !strings.Contains(resp.Content, "Message from session "+developer.ID) ||
+		!strings.HasSuffix(resp.Content, "Refactored the parser.\n\nReview it.") {
+		t.Fatalf("unexpected handoff message: %+v", resp)
+	}
+
+	hasNotice := func(id, code string) bool {
+		sess, err := env.executor.GetSession(id)
+		if err != nil {
+			t.Fatalf("GetSession: %v", err)
+		}
+		for _, m := range sess.Snapshot().Messages {
+			if m.Notice != nil && m.Notice.Code == code && m.Notice.Params["message_id"] == resp.MessageID {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasNotice(developer.ID, domain.NoticeHandoffSent) || !hasNotice(reviewer.ID, domain.NoticeHandoffReceived) {
+		t.Fatal("expected the handoff recorded in both sessions' messages")
+	}
+	sess, _ := env.executor.GetSession(reviewer.ID)
+	found := false
+	for _, m := range sess.Snapshot().Messages {
+		found = found || (m.Kind == domain.MessageKindUser && m.Contents == resp.Content)
+	}
+	if !found {
+		t.Fatal("expected the reviewer to receive the message")
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// checkIntegrity reports broken storage invariants without changing
+// anything.
+func (h *Handler) checkIntegrity(w http.ResponseWriter, r *http.Request) {
+	h.runIntegrityCheck(w, false)
+}
+
+// repairIntegrity applies the safe repair for every issue found, except in
+// sessions with a live run.
+func (h *Handler) repairIntegrity(w http.ResponseWriter, r *http.Request) {
+	h.runIntegrityCheck(w, true)
+}
+
+func (h *Handler) runIntegrityCheck(w http.ResponseWriter, repair bool) {
+	report, err := h.executor.CheckStorageIntegrity(repair)
+	if err != nil {
+		if errors.Is(err, service.ErrIntegrityUnsupported) {
+			writeErrorCode(w, http.StatusServiceUnavailable, apiTypes.ErrorCodeStorageUnavailable, err.Error(), "")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "integrity check failed", err.Error())
+		return
+	}
+
+	issues := make([]apiTypes.IntegrityIssue, len(report.Issues))
+	for i, issue := range report.Issues {
+		issues[i] = apiTypes.IntegrityIssue(issue)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(apiTypes.IntegrityReport{
+		CheckedAt: report.CheckedAt,
+		Repair:    report.Repair,
+		Issues:    issues,
+	})
+}
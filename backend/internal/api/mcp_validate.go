@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const (
+	defaultMCPValidateTimeout = 10 * time.Second
+	maxMCPValidateTimeout     = 60 * time.Second
+	maxMCPValidateStderr      = 64 * 1024
+)
+
+// validateMCPServer launches the configured MCP server, performs the
+// initialize handshake and lists its tools. Failures are reported in the
+// response body with a 200 so callers can show stderr alongside the error.
+func (h *Handler) validateMCPServer(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.MCPValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	req.Server.Command = strings.TrimSpace(req.Server.Command)
+	if req.Server.Command == "" {
+		writeError(w, http.StatusBadRequest, "server.command is required", "")
+		return
+	}
+
+	timeout := defaultMCPValidateTimeout
+	if req.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(req.TimeoutSeconds)*time.Second, maxMCPValidateTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	resp := probeMCPServer(ctx, req.Server, req.WorkingDir)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func probeMCPServer(ctx context.Context, cfg apiTypes.MCPServerConfig, workingDir string) apiTypes.MCPValidateResponse {
+	start := time.Now()
+	resp := apiTypes.MCPValidateResponse{Tools: []apiTypes.MCPToolInfo{}}
+
+	stderr := &cappedBuffer{limit: maxMCPValidateStderr}
+	cmd := exec.CommandContext(ctx, cfg.Command, cfg.Args...)
+	cmd.Dir = workingDir
+	cmd.Stderr = stderr
+	cmd.Env = os.Environ()
+	for k, v := range cfg.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "orbitmesh-validate", Version: "1.0.0"}, nil)
+	finish := func(err error) apiTypes.MCPValidateResponse {
+		if err != nil {
+			resp.Error = err.Error()
+			if ctx.Err() == context.DeadlineExceeded {
+				resp.Error = "timed out: " + resp.Error
+			}
+		}
+		resp.OK = err == nil
+		resp.Stderr = stderr.String()
+		resp.DurationMS = time.Since(start).Milliseconds()
+		return resp
+	}
+
+	cs, err := client.Connect(ctx, &mcp.CommandTransport{Command: cmd, TerminateDuration: time.Second}, nil)
+	if err != nil {
+		// Reap the process so its stderr is fully copied before reporting.
+		if cmd.Process != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}
+		return finish(err)
+	}
+	defer cs.Close()
+
+	if init := cs.InitializeResult(); init != nil {
+		resp.ProtocolVersion = init.ProtocolVersion
+		if init.ServerInfo != nil {
+			resp.ServerName = init.ServerInfo.Name
+			resp.ServerVersion = init.ServerInfo.Version
+		}
+	}
+
+	tools, err := cs.ListTools(ctx, &mcp.ListToolsParams{})
+	if err != nil {
+		return finish(err)
+	}
+	for _, tool := range tools.Tools {
+		resp.Tools = append(resp.Tools, apiTypes.MCPToolInfo{Name: tool.Name, Description: tool.Description})
+	}
+	return finish(nil)
+}
+
+// cappedBuffer is a concurrency-safe writer that keeps at most limit bytes.
+type cappedBuffer struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
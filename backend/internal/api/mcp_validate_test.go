@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestValidateMCPServer_MissingCommand(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/mcp/validate", strings.NewReader(`{"server":{"name":"x"}}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestValidateMCPServer_ReportsStderrOnFailure(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	body := `{"server":{"name":"broken","command":"sh","args":["-c","echo missing API key >&2; exit 3"]},"timeout_seconds":5}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/mcp/validate", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.MCPValidateResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.OK {
+		t.Fatal("expected validation to fail")
+	}
+	if resp.Error == "" {
+		t.Error("expected an error message")
+	}
+	if !strings.Contains(resp.Stderr, "missing API key") {
+		t.Errorf("stderr = %q, want captured output", resp.Stderr)
+	}
+}
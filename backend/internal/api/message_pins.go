@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listMessagePins(w http.ResponseWriter, r *http.Request) {
+	pins, err := h.executor.MessagePins(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	resp := apiTypes.MessagePinListResponse{Pins: presentation.MessagePins(pins)}
+	if resp.Pins == nil {
+		resp.Pins = []apiTypes.MessagePin{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) pinMessage(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.PinMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.MessageID == "" {
+		writeError(w, http.StatusBadRequest, "message_id is required", "")
+		return
+	}
+
+	pin, err := h.executor.PinMessage(chi.URLParam(r, "id"), req.MessageID, req.Excerpt, req.Note, requestUser(r))
+	if err != nil {
+		writeMessagePinError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(presentation.MessagePins([]domain.MessagePin{pin})[0])
+}
+
+func (h *Handler) unpinMessage(w http.ResponseWriter, r *http.Request) {
+	if err := h.executor.UnpinMessage(chi.URLParam(r, "id"), chi.URLParam(r, "pinID")); err != nil {
+		writeMessagePinError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeMessagePinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrMessageNotFound), errors.Is(err, domain.ErrMessagePinNotFound):
+		writeError(w, http.StatusNotFound, err.Error(), "")
+	case errors.Is(err, domain.ErrInvalidMessagePin):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, domain.ErrTooManyMessagePins):
+		writeError(w, http.StatusConflict, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestMessagePinEndpoints(t *testing.T) {
+	env := newTestEnv(t)
+	router := env.router()
+	sessionID := createSession(t, router, "mock", "/tmp").ID
+
+	sess, err := env.executor.GetSession(sessionID)
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	sess.AppendMessage(domain.MessageKindOutput, "Final answer: 42")
+	if err := env.store.Save(sess); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	messageID := sess.Messages[0].ID
+
+	pin := func(req apiTypes.PinMessageRequest) (int, apiTypes.MessagePin) {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", fmt.Sprintf("/api/sessions/%s/pinned-messages", sessionID), bytes.NewReader(body)))
+		var resp apiTypes.MessagePin
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	if code, _ := pin(apiTypes.PinMessageRequest{MessageID: "missing"}); code != http.StatusNotFound {
+		t.Fatalf("pin unknown message status = %d, want 404", code)
+	}
+	if code, _ := pin(apiTypes.PinMessageRequest{MessageID: messageID, Excerpt: "43"}); code != http.StatusBadRequest {
+		t.Fatalf("pin foreign excerpt status = %d, want 400", code)
+	}
+	code, created := pin(apiTypes.PinMessageRequest{MessageID: messageID, Excerpt: "42", Note: "the answer"})
+	if code != http.StatusCreated || created.Excerpt != "42" || created.Kind != "output" {
+		t.Fatalf("pin = %d %+v", code, created)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/api/sessions/%s/pinned-messages", sessionID), nil))
+	var list apiTypes.MessagePinListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Pins) != 1 || list.Pins[0].ID != created.ID {
+		t.Fatalf("list = %d %+v", w.Code, list)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/api/sessions/"+sessionID, nil))
+	var view apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &view)
+	if len(view.MessagePins) != 1 || view.MessagePins[0].Note != "the answer" {
+		t.Fatalf("session view pins = %+v", view.MessagePins)
+	}
+
+	for _, want := range []int{http.StatusNoContent, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", fmt.Sprintf("/api/sessions/%s/pinned-messages/%s", sessionID, created.ID), nil))
+		if w.Code != want {
+			t.Fatalf("unpin status = %d, want %d", w.Code, want)
+		}
+	}
+}
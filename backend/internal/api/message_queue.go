@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listQueuedMessages(w http.ResponseWriter, r *http.Request) {
+	queued, err := h.executor.QueuedMessages(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.QueuedMessageListResponse{Messages: presentation.QueuedMessages(queued)})
+}
+
+func (h *Handler) removeQueuedMessage(w http.ResponseWriter, r *http.Request) {
+	if err := h.executor.RemoveQueuedMessage(chi.URLParam(r, "id"), chi.URLParam(r, "messageID")); err != nil {
+		if errors.Is(err, domain.ErrQueuedMessageNotFound) {
+			writeError(w, http.StatusNotFound, "queued message not found", err.Error())
+			return
+		}
+		writeSessionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
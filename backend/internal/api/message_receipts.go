@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// maxMessageIDLength caps client-chosen message IDs.
+const maxMessageIDLength = 128
+
+// getMessageReceipt reports whether a message sent to the session reached
+// its provider, so a client that lost the send response can reconcile.
+func (h *Handler) getMessageReceipt(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	receipt, err := h.executor.MessageReceipt(id, chi.URLParam(r, "messageID"))
+	if err != nil {
+		if errors.Is(err, service.ErrMessageNotFound) {
+			writeError(w, http.StatusNotFound, "message not found", "")
+			return
+		}
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.MessageReceiptResponse{
+		MessageID: receipt.MessageID,
+		SessionID: receipt.SessionID,
+		AttemptID: receipt.AttemptID,
+		SentAt:    receipt.SentAt,
+		Delivery:  receipt.Delivery,
+		Error:     receipt.Error,
+	})
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSendMessage_DeliveryReceipt(t *testing.T) {
+	env := newTestEnv(t)
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: "/tmp"})
+	req := httptest.NewRequest("POST", "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	var createResp apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &createResp)
+	sessionID := createResp.ID
+
+	send := func(messageID string) (int, apiTypes.SendMessageResponse) {
+		body, _ := json.Marshal(apiTypes.SendMessageRequest{Content: "hello", MessageID: messageID})
+		req := httptest.NewRequest("POST", fmt.Sprintf("/api/sessions/%s/messages", sessionID), bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		env.router().ServeHTTP(w, req)
+		var resp apiTypes.SendMessageResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	receipt := func(messageID string) (int, apiTypes.MessageReceiptResponse) {
+		req := httptest.NewRequest("GET", fmt.Sprintf("/api/sessions/%s/messages/%s/receipt", sessionID, messageID), nil)
+		w := httptest.NewRecorder()
+		env.router().ServeHTTP(w, req)
+		var resp apiTypes.MessageReceiptResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, resp := send("")
+	if code != http.StatusAccepted || resp.MessageID == "" || resp.ID != sessionID {
+		t.Fatalf("send = %d %+v, want 202 with a generated message_id", code, resp)
+	}
+
+	var got apiTypes.MessageReceiptResponse
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		code, got = receipt(resp.MessageID)
+		if code != http.StatusOK {
+			t.Fatalf("receipt status = %d, want 200", code)
+		}
+		if got.Delivery != "queued" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got.Delivery != "delivered" || got.SessionID != sessionID {
+		t.Fatalf("receipt = %+v, want delivered", got)
+	}
+
+	// Retrying the delivered message is accepted without sending it again.
+	if code, retry := send(resp.MessageID); code != http.StatusAccepted || retry.MessageID != resp.MessageID {
+		t.Fatalf("retry = %d %+v, want 202 with the same message_id", code, retry)
+	}
+
+	if code, _ := receipt("unknown"); code != http.StatusNotFound {
+		t.Fatalf("unknown receipt status = %d, want 404", code)
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+)
+
+// SetMetricsTopSessions sets how many of the costliest sessions GET
+// /metrics reports individually; the rest are summed under "other".
+func (h *Handler) SetMetricsTopSessions(n int) {
+	h.topSessions = n
+}
+
+// usageMetric is one labelled family of GET /metrics.
+type usageMetric struct {
+	name   string
+	label  string
+	help   string
+	totals []service.UsageTotal
+}
+
+// prometheusMetrics serves per-project, per-provider and per-session token
+// and cost gauges in the Prometheus text format.
+func (h *Handler) prometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	top := h.topSessions
+	if top <= 0 {
+		top = service.DefaultCostMetricsTopSessions
+	}
+	metrics := h.executor.CostMetrics(top)
+
+	var b strings.Builder
+	for _, m := range []usageMetric{
+		{"orbitmesh_project", "project", "the sessions of the project", metrics.Projects},
+		{"orbitmesh_provider", "provider", "the sessions of the provider type", metrics.Providers},
+		{"orbitmesh_session", "session", "the session, or all sessions outside the top " + strconv.Itoa(top) + " (\"other\")", metrics.Sessions},
+	} {
+		fmt.Fprintf(&b, "# HELP %s_tokens Tokens used by %s.\n# TYPE %s_tokens gauge\n", m.name, m.help, m.name)
+		for _, t := range m.totals {
+			fmt.Fprintf(&b, "%s_tokens{%s=\"%s\",direction=\"input\"} %d\n", m.name, m.label, promLabelValue(t.Label), t.InputTokens)
+			fmt.Fprintf(&b, "%s_tokens{%s=\"%s\",direction=\"output\"} %d\n", m.name, m.label, promLabelValue(t.Label), t.OutputTokens)
+		}
+		fmt.Fprintf(&b, "# HELP %s_cost_usd Cost in USD reported for %s.\n# TYPE %s_cost_usd gauge\n", m.name, m.help, m.name)
+		for _, t := range m.totals {
+			fmt.Fprintf(&b, "%s_cost_usd{%s=\"%s\"} %s\n", m.name, m.label, promLabelValue(t.Label), strconv.FormatFloat(t.CostUSD, 'g', -1, 64))
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promLabelValue(v string) string {
+	return promLabelEscaper.Replace(v)
+}
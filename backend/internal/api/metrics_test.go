@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetMetricsTopSessions(1)
+	r := env.router()
+
+	var ids []string
+	for _, usage := range []struct {
+		in, out int64
+		cost    float64
+	}{{1200, 300, 0.75}, {40, 4, 0}} {
+		resp := createSession(t, r, "mock", "/tmp")
+		sess, err := env.executor.GetSession(resp.ID)
+		if err != nil {
+			t.Fatalf("get session: %v", err)
+		}
+		sess.RecordUsage(usage.in, usage.out, usage.cost)
+		ids = append(ids, resp.ID)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("GET /metrics = %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE orbitmesh_project_tokens gauge",
+		`orbitmesh_project_tokens{project="none",direction="input"} 1240`,
+		`orbitmesh_provider_cost_usd{provider="mock"} 0.75`,
+		`orbitmesh_session_tokens{session="` + ids[0] + `",direction="output"} 300`,
+		`orbitmesh_session_tokens{session="other",direction="input"} 40`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/ricochet1k/orbitmesh/internal/realtime"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
+)
+
+// DefaultMirrorInterval is how often a mirror polls the instance it mirrors
+// while it cannot follow its realtime sessions.sync topic.
+const DefaultMirrorInterval = 30 * time.Second
+
+// MirrorConfig configures a read-only mirror of another instance.
+type MirrorConfig struct {
+	// URL is the base URL of the mirrored instance's API.
+	URL string
+	// Token, when set, is sent as a bearer token on every request, for
+	// instances behind an authenticating proxy.
+	Token string
+	// Interval is how often to poll while the realtime connection is down.
+	// Defaults to DefaultMirrorInterval.
+	Interval time.Duration
+	// Client makes the HTTP requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Mirror replicates the sessions of another instance into a read-only
+// executor: GET /api/sessions/sync reports which sessions changed and each
+// changed session's bundle replaces the local copy. Between syncs the mirror
+// follows the instance's sessions.sync realtime topic, falling back to
+// polling while it is unreachable.
+type Mirror struct {
+	executor *service.AgentExecutor
+	cfg      MirrorConfig
+	base     *url.URL
+
+	mu     sync.Mutex
+	status apiTypes.MirrorStatusResponse
+}
+
+// NewMirror returns a Mirror of the instance at cfg.URL. The executor must
+// be configured with ReadOnlyMirror.
+func NewMirror(executor *service.AgentExecutor, cfg MirrorConfig) (*Mirror, error) {
+	base, err := url.Parse(strings.TrimSuffix(cfg.URL, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid mirror URL %q", cfg.URL)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultMirrorInterval
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	return &Mirror{
+		executor: executor,
+		cfg:      cfg,
+		base:     base,
+		status:   apiTypes.MirrorStatusResponse{Upstream: base.Redacted()},
+	}, nil
+}
+
+// Status reports how far the mirror has caught up.
+func (m *Mirror) Status() apiTypes.MirrorStatusResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+// Run keeps the mirror in sync until ctx is done.
+func (m *Mirror) Run(ctx context.Context) {
+	for {
+		if err := m.Sync(ctx); err != nil {
+			m.recordError(err)
+		} else if err := m.follow(ctx); err != nil && ctx.Err() == nil {
+			m.recordError(err)
+		}
+
+		timer := time.NewTimer(m.cfg.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Sync applies the sessions changed upstream since the last sync. After a
+// reset, local sessions the instance no longer has are removed.
+func (m *Mirror) Sync(ctx context.Context) error {
+	m.mu.Lock()
+	since := m.status.Revision
+	m.mu.Unlock()
+
+	var delta apiTypes.SessionSyncResponse
+	path := "/api/sessions/sync"
+	if since > 0 {
+		path += "?since=" + strconv.FormatInt(since, 10)
+	}
+	if err := m.get(ctx, path, &delta); err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(delta.Sessions))
+	for _, s := range delta.Sessions {
+		keep[s.ID] = true
+		if local, err := m.executor.GetSession(s.ID); err == nil && local.Snapshot().UpdatedAt.Equal(s.UpdatedAt) {
+			continue
+		}
+		var bundle service.SessionBundle
+		if err := m.get(ctx, "/api/sessions/"+url.PathEscape(s.ID)+"/bundle", &bundle); err != nil {
+			if errors.Is(err, errMirrorNotFound) {
+				// Deleted since the sync; the next delta reports it.
+				continue
+			}
+			return err
+		}
+		if _, err := m.executor.MirrorSession(&bundle); err != nil {
+			return fmt.Errorf("mirror session %s: %w", s.ID, err)
+		}
+	}
+	deleted := delta.Deleted
+	if delta.Reset {
+		for _, sess := range m.executor.ListSessions() {
+			if !keep[sess.ID] {
+				deleted = append(deleted, sess.ID)
+			}
+		}
+	}
+	for _, id := range deleted {
+		if err := m.executor.UnmirrorSession(id); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+			return fmt.Errorf("remove mirrored session %s: %w", id, err)
+		}
+	}
+
+	m.mu.Lock()
+	m.status.Revision = delta.Revision
+	m.status.SyncedAt = time.Now().UTC()
+	m.status.Error = ""
+	m.mu.Unlock()
+	return nil
+}
+
+// follow syncs each time the upstream sessions.sync topic reports a newer
+// revision, until the connection drops or ctx is done.
+func (m *Mirror) follow(ctx context.Context) error {
+	wsURL := *m.base
+	wsURL.Scheme = "ws"
+	if m.base.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	}
+	wsURL.Path += "/api/realtime"
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL.String(), m.header())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	m.setConnected(true)
+	defer m.setConnected(false)
+
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+
+	if err := conn.WriteJSON(realtimeTypes.ClientEnvelope{
+		Type:   realtimeTypes.ClientMessageTypeSubscribe,
+		Topics: []string{realtime.TopicSessionsSync},
+	}); err != nil {
+		return err
+	}
+
+	for {
+		var env struct {
+			Type    realtimeTypes.ServerMessageType    `json:"type"`
+			Payload realtimeTypes.SessionsSyncSnapshot `json:"payload"`
+			Message string                             `json:"message"`
+		}
+		if err := conn.ReadJSON(&env); err != nil {
+			return err
+		}
+		switch env.Type {
+		case realtimeTypes.ServerMessageTypeSnapshot, realtimeTypes.ServerMessageTypeEvent:
+			// Events carry the whole delta, but syncing from the mirror's
+			// own revision also covers any it missed.
+			if env.Payload.Revision == m.Status().Revision {
+				continue
+			}
+			if err := m.Sync(ctx); err != nil {
+				return err
+			}
+		case realtimeTypes.ServerMessageTypeError:
+			return fmt.Errorf("realtime: %s", env.Message)
+		}
+	}
+}
+
+var errMirrorNotFound = errors.New("not found upstream")
+
+func (m *Mirror) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.base.String()+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = m.header()
+	resp, err := m.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errMirrorNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	return nil
+}
+
+func (m *Mirror) header() http.Header {
+	h := http.Header{}
+	if m.cfg.Token != "" {
+		h.Set("Authorization", "Bearer "+m.cfg.Token)
+	}
+	return h
+}
+
+func (m *Mirror) setConnected(connected bool) {
+	m.mu.Lock()
+	m.status.Connected = connected
+	m.mu.Unlock()
+}
+
+func (m *Mirror) recordError(err error) {
+	log.Printf("mirror %s: %v", m.base.Redacted(), err)
+	m.mu.Lock()
+	m.status.Error = err.Error()
+	m.mu.Unlock()
+}
+
+// Middleware refuses every request that could change state, and terminal
+// connections, which accept input.
+func (m *Mirror) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+		if !readOnly || strings.HasSuffix(r.URL.Path, "/terminal/ws") {
+			writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeReadOnlyMirror, "this instance is a read-only mirror", m.base.Redacted())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetMirror marks the instance as a read-only mirror reporting m's status.
+func (h *Handler) SetMirror(m *Mirror) {
+	h.mirror = m
+}
+
+func (h *Handler) getMirrorStatus(w http.ResponseWriter, r *http.Request) {
+	if h.mirror == nil {
+		writeError(w, http.StatusNotImplemented, "this instance is not a mirror", "")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(h.mirror.Status())
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func newMirrorEnv(t *testing.T, upstreamURL string) (*Mirror, *service.AgentExecutor, http.Handler) {
+	t.Helper()
+	store := newInMemStore()
+	broadcaster := service.NewEventBroadcaster(100)
+	executor := service.NewAgentExecutor(service.ExecutorConfig{
+		Storage:         store,
+		TerminalStorage: store,
+		Broadcaster:     broadcaster,
+		ReadOnlyMirror:  true,
+	})
+	t.Cleanup(func() { _ = executor.Shutdown(context.Background()) })
+
+	mirror, err := NewMirror(executor, MirrorConfig{URL: upstreamURL, Interval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewMirror: %v", err)
+	}
+	handler := NewHandler(executor, broadcaster, store, nil, nil, nil)
+	handler.SetMirror(mirror)
+	r := chi.NewRouter()
+	r.Use(mirror.Middleware)
+	handler.Mount(r)
+	return mirror, executor, r
+}
+
+func TestMirror_SyncReplicatesSessions(t *testing.T) {
+	upstream := newTestEnv(t)
+	srv := httptest.NewServer(upstream.router())
+	defer srv.Close()
+
+	first := createSession(t, upstream.router(), "mock", "/tmp/one")
+	second := createSession(t, upstream.router(), "mock", "/tmp/two")
+
+	mirror, executor, router := newMirrorEnv(t, srv.URL)
+	if err := mirror.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	for _, id := range []string{first.ID, second.ID} {
+		if _, err := executor.GetSession(id); err != nil {
+			t.Fatalf("session %s not mirrored: %v", id, err)
+		}
+	}
+
+	if err := upstream.executor.DeleteSession(context.Background(), first.ID, "test"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if err := mirror.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if _, err := executor.GetSession(first.ID); err == nil {
+		t.Errorf("deleted session %s still mirrored", first.ID)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/mirror", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var status apiTypes.MirrorStatusResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &status)
+	if w.Code != http.StatusOK || status.Revision != upstream.executor.SessionRevision() || status.Upstream != srv.URL {
+		t.Errorf("mirror status = %d %+v", w.Code, status)
+	}
+}
+
+func TestMirror_RefusesChanges(t *testing.T) {
+	upstream := newTestEnv(t)
+	srv := httptest.NewServer(upstream.router())
+	defer srv.Close()
+	created := createSession(t, upstream.router(), "mock", "/tmp/one")
+
+	mirror, executor, router := newMirrorEnv(t, srv.URL)
+	if err := mirror.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/sessions/"+created.ID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET mirrored session = %d: %s", w.Code, w.Body.String())
+	}
+
+	for _, path := range []string{"/api/sessions", "/api/sessions/" + created.ID + "/messages"} {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`{"content":"hi"}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var errResp apiTypes.ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &errResp)
+		if w.Code != http.StatusForbidden || errResp.Code != apiTypes.ErrorCodeReadOnlyMirror {
+			t.Errorf("POST %s = %d %+v, want 403 read_only_mirror", path, w.Code, errResp)
+		}
+	}
+
+	if _, err := executor.SendMessage(context.Background(), created.ID, "hi", "", ""); err == nil {
+		t.Error("mirror executor should not start runs")
+	}
+}
+
+func TestMirror_RunFollowsRealtime(t *testing.T) {
+	upstream := newTestEnv(t)
+	srv := httptest.NewServer(upstream.router())
+	defer srv.Close()
+
+	mirror, executor, _ := newMirrorEnv(t, srv.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mirror.Run(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !mirror.Status().Connected {
+		if time.Now().After(deadline) {
+			t.Fatalf("mirror never connected: %+v", mirror.Status())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	created := createSession(t, upstream.router(), "mock", "/tmp/one")
+	for {
+		if _, err := executor.GetSession(created.ID); err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("session %s never mirrored: %+v", created.ID, mirror.Status())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listMissions(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.executor.Missions()
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	resp := apiTypes.MissionListResponse{Missions: make([]apiTypes.MissionResponse, len(reports))}
+	for i, report := range reports {
+		resp.Missions[i] = missionToResponse(report)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) getMission(w http.ResponseWriter, r *http.Request) {
+	report, err := h.executor.Mission(chi.URLParam(r, "id"))
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(missionToResponse(report))
+}
+
+func (h *Handler) createMission(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.decodeMission(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.executor.CreateMission(m)
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	h.writeMission(w, http.StatusCreated, created.ID)
+}
+
+func (h *Handler) updateMission(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.decodeMission(w, r)
+	if !ok {
+		return
+	}
+	updated, err := h.executor.UpdateMission(chi.URLParam(r, "id"), m)
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	h.writeMission(w, http.StatusOK, updated.ID)
+}
+
+func (h *Handler) deleteMission(w http.ResponseWriter, r *http.Request) {
+	if err := h.executor.DeleteMission(chi.URLParam(r, "id")); err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addMissionSession moves a session into the mission.
+func (h *Handler) addMissionSession(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.MissionSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.SessionID == "" {
+		writeError(w, http.StatusBadRequest, "session_id is required", "")
+		return
+	}
+	id := chi.URLParam(r, "id")
+	if _, err := h.executor.AddMissionSession(id, req.SessionID, ""); err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	h.writeMission(w, http.StatusOK, id)
+}
+
+// removeMissionSession takes a session out of the mission.
+func (h *Handler) removeMissionSession(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.executor.RemoveMissionSession(id, chi.URLParam(r, "sessionID"), ""); err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	h.writeMission(w, http.StatusOK, id)
+}
+
+func (h *Handler) getMissionLog(w http.ResponseWriter, r *http.Request) {
+	h.writeMissionLog(w, chi.URLParam(r, "id"))
+}
+
+func (h *Handler) appendMissionLog(w http.ResponseWriter, r *http.Request) {
+	h.addMissionLogEntry(w, r, chi.URLParam(r, "id"), domain.MissionLogEntry{Author: requestUser(r)})
+}
+
+// getSessionMissionLog returns the log of the session's mission, so the
+// session's agent can read what its teammates wrote.
+func (h *Handler) getSessionMissionLog(w http.ResponseWriter, r *http.Request) {
+	missionID, ok := h.sessionMission(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+	h.writeMissionLog(w, missionID)
+}
+
+// appendSessionMissionLog adds an entry from the session to its mission's
+// log. Entries sent with the run's API token are the agent's.
+func (h *Handler) appendSessionMissionLog(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "id")
+	missionID, ok := h.sessionMission(w, sessionID)
+	if !ok {
+		return
+	}
+	author := requestUser(r)
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && service.IsRunAPIToken(strings.TrimSpace(bearer)) {
+		author = service.ExchangeAgent
+	}
+	h.addMissionLogEntry(w, r, missionID, domain.MissionLogEntry{Author: author, SessionID: sessionID})
+}
+
+// sessionMission returns the ID of the session's mission. It answers the
+// request itself when the session is in none.
+func (h *Handler) sessionMission(w http.ResponseWriter, sessionID string) (string, bool) {
+	sess, err := h.executor.GetSession(sessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return "", false
+	}
+	missionID := sess.GetMissionID()
+	if missionID == "" {
+		writeError(w, http.StatusNotFound, "session is not in a mission", "")
+		return "", false
+	}
+	return missionID, true
+}
+
+func (h *Handler) addMissionLogEntry(w http.ResponseWriter, r *http.Request, missionID string, entry domain.MissionLogEntry) {
+	var req apiTypes.MissionLogRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 2*domain.MaxMissionLogText)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	entry.Text = req.Text
+	added, err := h.executor.AppendMissionLog(missionID, entry)
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(missionLogEntryToAPI(added))
+}
+
+func (h *Handler) writeMissionLog(w http.ResponseWriter, missionID string) {
+	report, err := h.executor.Mission(missionID)
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+	resp := apiTypes.MissionLogResponse{Entries: make([]apiTypes.MissionLogEntry, len(report.Mission.Log))}
+	for i, entry := range report.Mission.Log {
+		resp.Entries[i] = missionLogEntryToAPI(entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writeMission answers with the mission's current report.
+func (h *Handler) writeMission(w http.ResponseWriter, status int, id string) {
+	report, err := h.executor.Mission(id)
+	if err != nil {
+		writeMissionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(missionToResponse(report))
+}
+
+// decodeMission reads a MissionRequest, checking that its project exists.
+// It answers the request itself when it fails.
+func (h *Handler) decodeMission(w http.ResponseWriter, r *http.Request) (domain.Mission, bool) {
+	var req apiTypes.MissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return domain.Mission{}, false
+	}
+	if req.ProjectID != "" && h.projectStorage != nil {
+		if _, err := h.projectStorage.Get(req.ProjectID); err != nil {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
+			return domain.Mission{}, false
+		}
+	}
+
+	return domain.Mission{
+		Name:       strings.TrimSpace(req.Name),
+		Goal:       req.Goal,
+		ProjectID:  req.ProjectID,
+		CostBudget: costBudgetFromAPI(req.CostBudget),
+	}, true
+}
+
+func writeMissionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrMissionsDisabled):
+		writeError(w, http.StatusNotImplemented, err.Error(), "")
+	case errors.Is(err, service.ErrInvalidMission):
+		writeError(w, http.StatusBadRequest, "invalid mission", err.Error())
+	case errors.Is(err, storage.ErrMissionNotFound):
+		writeError(w, http.StatusNotFound, "mission not found", err.Error())
+	case errors.Is(err, service.ErrSessionNotFound):
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
+	default:
+		writeError(w, http.StatusInternalServerError, "mission operation failed", err.Error())
+	}
+}
+
+func missionToResponse(report service.MissionReport) apiTypes.MissionResponse {
+	m := report.Mission
+	resp := apiTypes.MissionResponse{
+		ID:             m.ID,
+		Name:           m.Name,
+		Goal:           m.Goal,
+		ProjectID:      m.ProjectID,
+		CostBudget:     presentation.CostBudget(m.CostBudget),
+		Status:         report.Status,
+		Usage:          usageTotalsToAPI(report.Usage),
+		BudgetExceeded: report.Exceeded,
+		Sessions:       make([]apiTypes.MissionSession, len(report.Members)),
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+	for i, member := range report.Members {
+		resp.Sessions[i] = apiTypes.MissionSession{
+			SessionID: member.SessionID,
+			Title:     member.Title,
+			State:     apiTypes.SessionState(member.State.String()),
+			Usage:     usageTotalsToAPI(member.Usage),
+		}
+	}
+	return resp
+}
+
+func missionLogEntryToAPI(entry domain.MissionLogEntry) apiTypes.MissionLogEntry {
+	return apiTypes.MissionLogEntry{
+		At:        entry.At,
+		Author:    entry.Author,
+		SessionID: entry.SessionID,
+		Text:      entry.Text,
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestMissions_CRUDMembersAndLog(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		return w
+	}
+	if w := do(http.MethodPost, "/api/v1/missions", apiTypes.MissionRequest{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a mission without a name to be rejected, got %d", w.Code)
+	}
+	w := do(http.MethodPost, "/api/v1/missions", apiTypes.MissionRequest{Name: "launch", Goal: "ship v2", CostBudget: &apiTypes.CostBudget{LimitUSD: 10}})
+	var mission apiTypes.MissionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &mission)
+	if w.Code != http.StatusCreated || mission.Status != "pending" || mission.CostBudget == nil {
+		t.Fatalf("expected a pending mission, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: t.TempDir(), MissionID: mission.ID})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body)))
+	var member apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &member)
+	if w.Code != http.StatusCreated || member.MissionID != mission.ID {
+		t.Fatalf("expected the session to join the mission, got %d: %s", w.Code, w.Body.String())
+	}
+	other := createSession(t, r, "mock", t.TempDir())
+	w = do(http.MethodPost, "/api/v1/missions/"+mission.ID+"/sessions", apiTypes.MissionSessionRequest{SessionID: other.ID})
+	_ = json.Unmarshal(w.Body.Bytes(), &mission)
+	if w.Code != http.StatusOK || len(mission.Sessions) != 2 || mission.Status != "idle" {
+		t.Fatalf("expected an idle mission of two sessions, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodPost, "/api/sessions/"+member.ID+"/mission/log", apiTypes.MissionLogRequest{Text: "schema is ready"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected the session to write to its mission's log, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/sessions/"+member.ID+"/mission/log", apiTypes.MissionLogRequest{}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected an empty entry to be rejected, got %d", w.Code)
+	}
+	w = do(http.MethodGet, "/api/v1/missions/"+mission.ID+"/log", nil)
+	var log apiTypes.MissionLogResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &log)
+	if len(log.Entries) != 3 || log.Entries[2].Text != "schema is ready" || log.Entries[2].SessionID != member.ID {
+		t.Fatalf("expected two joins and the entry, got %+v", log.Entries)
+	}
+
+	w = do(http.MethodGet, "/api/sessions?mission_id="+mission.ID, nil)
+	var list apiTypes.SessionListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Sessions) != 2 {
+		t.Fatalf("expected the mission's sessions, got %+v", list.Sessions)
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/missions/"+mission.ID+"/sessions/"+other.ID, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodDelete, "/api/v1/missions/"+mission.ID, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/sessions/"+member.ID+"/mission/log", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected the session to leave a deleted mission, got %d", w.Code)
+	}
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listPipelines(w http.ResponseWriter, r *http.Request) {
+	pipelines, err := h.executor.Pipelines()
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	resp := apiTypes.PipelineListResponse{Pipelines: make([]apiTypes.PipelineResponse, len(pipelines))}
+	for i := range pipelines {
+		resp.Pipelines[i] = pipelineToResponse(&pipelines[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) getPipeline(w http.ResponseWriter, r *http.Request) {
+	p, err := h.executor.Pipeline(chi.URLParam(r, "id"))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(pipelineToResponse(p))
+}
+
+func (h *Handler) createPipeline(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.decodePipeline(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.executor.CreatePipeline(p)
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(pipelineToResponse(created))
+}
+
+func (h *Handler) updatePipeline(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.decodePipeline(w, r)
+	if !ok {
+		return
+	}
+	updated, err := h.executor.UpdatePipeline(chi.URLParam(r, "id"), p)
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(pipelineToResponse(updated))
+}
+
+func (h *Handler) deletePipeline(w http.ResponseWriter, r *http.Request) {
+	if err := h.executor.DeletePipeline(chi.URLParam(r, "id")); err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startPipelineRun starts a run of the pipeline. It answers once the first
+// steps' sessions are started; poll the run for the rest.
+func (h *Handler) startPipelineRun(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.PipelineRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	id := chi.URLParam(r, "id")
+	run, err := h.executor.StartPipelineRun(r.Context(), id, req.Input)
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(pipelineRunToResponse(id, run))
+}
+
+func (h *Handler) listPipelineRuns(w http.ResponseWriter, r *http.Request) {
+	p, err := h.executor.Pipeline(chi.URLParam(r, "id"))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	resp := apiTypes.PipelineRunListResponse{Runs: make([]apiTypes.PipelineRunResponse, 0, len(p.Runs))}
+	for i := len(p.Runs) - 1; i >= 0; i-- {
+		resp.Runs = append(resp.Runs, pipelineRunToResponse(p.ID, &p.Runs[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) getPipelineRun(w http.ResponseWriter, r *http.Request) {
+	p, err := h.executor.Pipeline(chi.URLParam(r, "id"))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	run := p.Run(chi.URLParam(r, "runID"))
+	if run == nil {
+		writeError(w, http.StatusNotFound, "pipeline run not found", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(pipelineRunToResponse(p.ID, run))
+}
+
+// decodePipeline reads a PipelineRequest, checking that its project exists
+// and defaulting its working directory to the project's path. It answers
+// the request itself when it fails.
+func (h *Handler) decodePipeline(w http.ResponseWriter, r *http.Request) (domain.Pipeline, bool) {
+	var req apiTypes.PipelineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return domain.Pipeline{}, false
+	}
+	workingDir := strings.TrimSpace(req.WorkingDir)
+	if req.ProjectID != "" && h.projectStorage != nil {
+		project, err := h.projectStorage.Get(req.ProjectID)
+		if err != nil {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
+			return domain.Pipeline{}, false
+		}
+		if workingDir == "" {
+			workingDir = project.Path
+		}
+	}
+	if workingDir == "" {
+		writeError(w, http.StatusBadRequest, "working_dir is required", "set working_dir or a project_id")
+		return domain.Pipeline{}, false
+	}
+
+	p := domain.Pipeline{
+		Name:       strings.TrimSpace(req.Name),
+		ProjectID:  req.ProjectID,
+		WorkingDir: workingDir,
+		Steps:      make([]domain.PipelineStep, len(req.Steps)),
+	}
+	for i, s := range req.Steps {
+		p.Steps[i] = domain.PipelineStep{
+			Name:         strings.TrimSpace(s.Name),
+			ProviderType: strings.TrimSpace(s.ProviderType),
+			ProviderID:   strings.TrimSpace(s.ProviderID),
+			AgentID:      strings.TrimSpace(s.AgentID),
+			Prompt:       s.Prompt,
+			Role:         s.Role,
+			DependsOn:    s.DependsOn,
+		}
+	}
+	return p, true
+}
+
+func writePipelineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPipelinesDisabled):
+		writeError(w, http.StatusNotImplemented, err.Error(), "")
+	case errors.Is(err, service.ErrInvalidPipeline):
+		writeError(w, http.StatusBadRequest, "invalid pipeline", err.Error())
+	case errors.Is(err, storage.ErrPipelineNotFound):
+		writeError(w, http.StatusNotFound, "pipeline not found", err.Error())
+	case errors.Is(err, service.ErrInvalidState):
+		writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeInvalidState, "pipeline is running", err.Error())
+	case errors.Is(err, service.ErrExecutorShutdown), errors.Is(err, service.ErrReadOnlyMirror):
+		writeSessionError(w, err)
+	default:
+		writeError(w, http.StatusInternalServerError, "pipeline operation failed", err.Error())
+	}
+}
+
+func pipelineToResponse(p *domain.Pipeline) apiTypes.PipelineResponse {
+	resp := apiTypes.PipelineResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		ProjectID:  p.ProjectID,
+		WorkingDir: p.WorkingDir,
+		Steps:      make([]apiTypes.PipelineStep, len(p.Steps)),
+		CreatedAt:  p.CreatedAt,
+		UpdatedAt:  p.UpdatedAt,
+	}
+	for i, s := range p.Steps {
+		resp.Steps[i] = apiTypes.PipelineStep{
+			Name:         s.Name,
+			ProviderType: s.ProviderType,
+			ProviderID:   s.ProviderID,
+			AgentID:      s.AgentID,
+			Prompt:       s.Prompt,
+			Role:         s.Role,
+			DependsOn:    s.DependsOn,
+		}
+	}
+	if n := len(p.Runs); n > 0 {
+		last := pipelineRunToResponse(p.ID, &p.Runs[n-1])
+		resp.LastRun = &last
+	}
+	return resp
+}
+
+func pipelineRunToResponse(pipelineID string, run *domain.PipelineRun) apiTypes.PipelineRunResponse {
+	resp := apiTypes.PipelineRunResponse{
+		ID:          run.ID,
+		PipelineID:  pipelineID,
+		Status:      run.Status,
+		Input:       run.Input,
+		Steps:       make([]apiTypes.PipelineStepRun, len(run.Steps)),
+		StartedAt:   run.StartedAt,
+		CompletedAt: optionalTime(run.CompletedAt),
+	}
+	for i, s := range run.Steps {
+		resp.Steps[i] = apiTypes.PipelineStepRun{
+			Name:        s.Name,
+			Status:      s.Status,
+			SessionID:   s.SessionID,
+			Output:      s.Output,
+			Error:       s.Error,
+			StartedAt:   optionalTime(s.StartedAt),
+			CompletedAt: optionalTime(s.CompletedAt),
+		}
+	}
+	return resp
+}
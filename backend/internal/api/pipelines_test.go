@@ -0,0 +1,136 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestPipeline_RunPassesOutputsDownstream(t *testing.T) {
+	agents := storage.NewAgentConfigStorage(t.TempDir())
+	if err := agents.Save(storage.AgentConfig{ID: "reviewer", Name: "reviewer", SystemPrompt: "You review plans."}); err != nil {
+		t.Fatal(err)
+	}
+	env := newTestEnvWithConfig(t, func(cfg *service.ExecutorConfig) { cfg.AgentConfigs = agents })
+	r := env.router()
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var data []byte
+		if body != nil {
+			data, _ = json.Marshal(body)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		return w
+	}
+	steps := []apiTypes.PipelineStep{
+		{Name: "plan", ProviderType: "mock", Prompt: "Plan: {{.Input}}", Role: "You are a planner."},
+		{Name: "review", ProviderType: "mock", AgentID: "reviewer", Prompt: "Review this plan:\n\n{{.Steps.plan.Output}}", DependsOn: []string{"plan"}},
+	}
+	for name, req := range map[string]apiTypes.PipelineRequest{
+		"cycle": {Name: "loop", WorkingDir: t.TempDir(), Steps: []apiTypes.PipelineStep{
+			{Name: "a", ProviderType: "mock", Prompt: "a", DependsOn: []string{"b"}},
+			{Name: "b", ProviderType: "mock", Prompt: "b", DependsOn: []string{"a"}},
+		}},
+		"no provider":      {Name: "bad", WorkingDir: t.TempDir(), Steps: []apiTypes.PipelineStep{{Name: "a", Prompt: "a"}}},
+		"unknown provider": {Name: "bad", WorkingDir: t.TempDir(), Steps: []apiTypes.PipelineStep{{Name: "a", ProviderType: "nope", Prompt: "a"}}},
+		"no working dir":   {Name: "bad", Steps: steps},
+	} {
+		if w := do(http.MethodPost, "/api/v1/pipelines", req); w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	w := do(http.MethodPost, "/api/v1/pipelines", apiTypes.PipelineRequest{Name: "plan and review", WorkingDir: t.TempDir(), Steps: steps})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var pipeline apiTypes.PipelineResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &pipeline)
+
+	w = do(http.MethodPost, "/api/v1/pipelines/"+pipeline.ID+"/runs", apiTypes.PipelineRunRequest{Input: "fix the flaky test"})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var run apiTypes.PipelineRunResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &run)
+	if run.Status != domain.PipelineStatusRunning || run.Steps[0].Status != domain.PipelineStatusRunning || run.Steps[1].Status != domain.PipelineStatusPending {
+		t.Fatalf("expected only the plan step running, got %+v", run)
+	}
+
+	runPath := "/api/v1/pipelines/" + pipeline.ID + "/runs/" + run.ID
+	poll := func(done func(apiTypes.PipelineRunResponse) bool) apiTypes.PipelineRunResponse {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			var got apiTypes.PipelineRunResponse
+			_ = json.Unmarshal(do(http.MethodGet, runPath, nil).Body.Bytes(), &got)
+			if done(got) {
+				return got
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for the run, last %+v", got)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// finishStep ends a step's run with output, returning its prompt.
+	finishStep := func(sessionID, output string) string {
+		t.Helper()
+		sess, err := env.executor.GetSession(sessionID)
+		if err != nil {
+			t.Fatalf("GetSession: %v", err)
+		}
+		waitForState(t, sess, domain.SessionStateRunning)
+		mock := env.lastMock
+		mock.mu.Lock()
+		input := mock.lastInput
+		mock.mu.Unlock()
+		mock.events <- domain.NewOutputEvent(sessionID, output, nil)
+		close(mock.events)
+		return input
+	}
+
+	if input := finishStep(run.Steps[0].SessionID, "1. Retry the request."); input != "Plan: fix the flaky test" {
+		t.Fatalf("unexpected plan prompt %q", input)
+	}
+	run = poll(func(got apiTypes.PipelineRunResponse) bool {
+		return got.Steps[1].Status == domain.PipelineStatusRunning
+	})
+	if run.Steps[0].Status != domain.PipelineStatusCompleted || run.Steps[0].Output != "1. Retry the request." {
+		t.Fatalf("expected the plan step completed with its output, got %+v", run.Steps[0])
+	}
+	if input := finishStep(run.Steps[1].SessionID, "Looks good."); !strings.HasSuffix(input, "1. Retry the request.") {
+		t.Fatalf("expected the review prompt to contain the plan, got %q", input)
+	}
+	run = poll(func(got apiTypes.PipelineRunResponse) bool { return got.Status != domain.PipelineStatusRunning })
+	if run.Status != domain.PipelineStatusCompleted || run.CompletedAt == nil || run.Steps[1].Output != "Looks good." {
+		t.Fatalf("expected a completed run, got %+v", run)
+	}
+
+	sess, _ := env.executor.GetSession(run.Steps[0].SessionID)
+	if kind := sess.Snapshot().Kind; kind != domain.SessionKindPipeline {
+		t.Fatalf("expected a pipeline session, got kind %q", kind)
+	}
+	if sess, _ := env.executor.GetSession(run.Steps[1].SessionID); sess.AgentID != "reviewer" || !strings.Contains(sess.PromptPrefix, "You review plans.") {
+		t.Fatalf("expected the review step to run as its agent, got %q / %q", sess.AgentID, sess.PromptPrefix)
+	}
+
+	var runs apiTypes.PipelineRunListResponse
+	_ = json.Unmarshal(do(http.MethodGet, "/api/v1/pipelines/"+pipeline.ID+"/runs", nil).Body.Bytes(), &runs)
+	if len(runs.Runs) != 1 || runs.Runs[0].ID != run.ID {
+		t.Fatalf("expected the run listed, got %+v", runs)
+	}
+	if w := do(http.MethodGet, "/api/v1/pipelines/"+pipeline.ID+"/runs/nope", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown run, got %d", w.Code)
+	}
+}
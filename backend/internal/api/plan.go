@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// approvePlan approves the plan a session is waiting on, optionally
+// replacing it, and starts the run that carries it out.
+func (h *Handler) approvePlan(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.PlanApproveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	sess, err := h.executor.ApprovePlan(r.Context(), chi.URLParam(r, "id"), req.Plan, requestUser(r))
+	if err != nil {
+		if errors.Is(err, service.ErrNoPendingPlan) {
+			writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeInvalidState, err.Error(), "")
+			return
+		}
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessionToResponse(sess.Snapshot()))
+}
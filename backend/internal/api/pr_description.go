@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const (
+	maxPRTitleLen   = 72
+	maxPRSummaryLen = 4000
+)
+
+// draftPRDescription drafts a pull request title and body from the session's
+// title, plan, final agent output and the diff of its working directory, and
+// opens it as a pull request when asked to push.
+func (h *Handler) draftPRDescription(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req apiTypes.PRDescriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	base := strings.TrimSpace(req.Base)
+	if base == "" {
+		base = "HEAD"
+	}
+	if strings.HasPrefix(base, "-") {
+		writeError(w, http.StatusBadRequest, "invalid base", "base must be a git revision")
+		return
+	}
+	sess, err := h.executor.GetSession(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	snap := sess.Snapshot()
+	messages := snap.Messages
+	if stored, err := h.sessionStorage.GetMessages(id); err == nil {
+		messages = stored
+	}
+
+	// The working directory may not be a git checkout; the draft then has
+	// no changes section.
+	diffStat, files := gitDiffStat(snap.WorkingDir, base)
+
+	title, body := composePRDescription(snap, messages, diffStat)
+	if req.Push {
+		h.createPullRequest(w, r, sess, apiTypes.PullRequestRequest{Title: title, Body: body})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.PRDescriptionResponse{
+		Title:    title,
+		Body:     body,
+		Base:     base,
+		DiffStat: diffStat,
+		Files:    files,
+	})
+}
+
+func composePRDescription(snap domain.SessionSnapshot, messages []domain.Message, diffStat string) (title, body string) {
+	var firstUser, lastOutput, lastPlan string
+	for _, msg := range messages {
+		contents := strings.TrimSpace(msg.Contents)
+		if contents == "" || contents == domain.RedactedTombstone {
+			continue
+		}
+		switch msg.Kind {
+		case domain.MessageKindUser:
+			if firstUser == "" {
+				firstUser = contents
+			}
+		case domain.MessageKindOutput:
+			lastOutput = contents
+		case domain.MessageKindPlan:
+			lastPlan = contents
+		}
+	}
+	plan := lastPlan
+	if snap.Plan != nil && snap.Plan.Content != "" {
+		plan = snap.Plan.Content
+	}
+
+	for _, candidate := range []string{snap.Title, snap.CurrentTask, firstUser} {
+		if line, _, _ := strings.Cut(strings.TrimSpace(candidate), "\n"); line != "" {
+			title = line
+			break
+		}
+	}
+	if title == "" {
+		title = "Changes from session " + snap.ID
+	}
+	if r := []rune(title); len(r) > maxPRTitleLen {
+		title = strings.TrimSpace(string(r[:maxPRTitleLen-1])) + "…"
+	}
+
+	var b strings.Builder
+	section := func(heading, text string) {
+		if text == "" {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s", heading, text)
+	}
+	summary := lastOutput
+	if r := []rune(summary); len(r) > maxPRSummaryLen {
+		summary = strings.TrimSpace(string(r[:maxPRSummaryLen])) + "…"
+	}
+	section("Summary", summary)
+	section("Plan", plan)
+	if diffStat != "" {
+		section("Changes", "```\n"+diffStat+"\n```")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "_Drafted from OrbitMesh session %s._", snap.ID)
+	return title, b.String()
+}
+
+// gitDiffStat returns the diffstat and changed files of dir against base,
+// including uncommitted changes, or nothing when dir is not a git checkout.
+func gitDiffStat(dir, base string) (string, []string) {
+	if dir == "" {
+		return "", nil
+	}
+	run := func(args ...string) (string, bool) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		if err := cmd.Run(); err != nil {
+			return "", false
+		}
+		return strings.TrimRight(out.String(), "\n"), true
+	}
+	stat, ok := run("diff", "--no-color", "--stat", base, "--")
+	if !ok {
+		return "", nil
+	}
+	names, _ := run("diff", "--name-only", base, "--")
+	var files []string
+	for _, name := range strings.Split(names, "\n") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return stat, files
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestDraftPRDescription(t *testing.T) {
+	repoDir, _ := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+	created := createSession(t, r, "mock", repoDir)
+
+	post := func(body apiTypes.PRDescriptionRequest) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+created.ID+"/pr-description", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(apiTypes.PRDescriptionRequest{Base: "HEAD~1"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.PRDescriptionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Title == "" || len(resp.Files) != 1 || resp.Files[0] != "demo.txt" {
+		t.Fatalf("unexpected draft: %+v", resp)
+	}
+	if !strings.Contains(resp.Body, "## Changes") || !strings.Contains(resp.Body, "demo.txt") {
+		t.Fatalf("expected the diffstat in the body, got %q", resp.Body)
+	}
+
+	if w := post(apiTypes.PRDescriptionRequest{Base: "--output=/tmp/x"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an option as base, got %d", w.Code)
+	}
+	if w := post(apiTypes.PRDescriptionRequest{Push: true}); w.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501 for push, got %d", w.Code)
+	}
+}
+
+func TestComposePRDescription(t *testing.T) {
+	snap := domain.SessionSnapshot{
+		ID:   "s1",
+		Plan: &domain.SessionPlan{Content: "1. Fix the parser"},
+	}
+	messages := []domain.Message{
+		{Kind: domain.MessageKindUser, Contents: "Fix the flaky parser test\nIt fails on CI."},
+		{Kind: domain.MessageKindOutput, Contents: "Working on it"},
+		{Kind: domain.MessageKindOutput, Contents: "Fixed the race in the parser."},
+		{Kind: domain.MessageKindOutput, Contents: domain.RedactedTombstone},
+	}
+
+	title, body := composePRDescription(snap, messages, "")
+	if title != "Fix the flaky parser test" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{"## Summary\n\nFixed the race in the parser.", "## Plan\n\n1. Fix the parser"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body %q does not contain %q", body, want)
+		}
+	}
+	if strings.Contains(body, "## Changes") {
+		t.Errorf("expected no changes section without a diff, got %q", body)
+	}
+}
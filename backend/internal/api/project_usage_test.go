@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestProjectCostBudgetAndUsage(t *testing.T) {
+	env := newTestEnvWithConfig(t, func(cfg *service.ExecutorConfig) {
+		cfg.Projects = storage.NewProjectStorage(t.TempDir())
+	})
+	r := env.router()
+
+	post := func(budget *apiTypes.CostBudget) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(apiTypes.ProjectRequest{Name: "p", Path: t.TempDir(), CostBudget: budget})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/projects", bytes.NewReader(body)))
+		return w
+	}
+	if w := post(&apiTypes.CostBudget{LimitUSD: 5, Action: "pause"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown action to be rejected, got %d", w.Code)
+	}
+	w := post(&apiTypes.CostBudget{LimitUSD: 5})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var project apiTypes.ProjectResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &project)
+	if project.CostBudget == nil || project.CostBudget.LimitUSD != 5 {
+		t.Fatalf("expected the budget in the response, got %+v", project.CostBudget)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/projects/"+project.ID+"/usage", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var usage apiTypes.ProjectUsageResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &usage); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if usage.ProjectID != project.ID || usage.CostBudget == nil || usage.BudgetExceeded != "" || usage.Sessions == nil {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+
+	// An update rejected for one setting must not apply the others.
+	body, _ := json.Marshal(apiTypes.ProjectRequest{
+		Name:         "p",
+		Path:         project.Path,
+		CostBudget:   &apiTypes.CostBudget{LimitUSD: 50},
+		PullRequests: &apiTypes.PullRequestConfig{Provider: "bitbucket", Repo: "acme/widgets"},
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v1/projects/"+project.ID, bytes.NewReader(body)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown pull request provider to be rejected, got %d", w.Code)
+	}
+	if budget := env.executor.ProjectUsage(project.ID).Budget; budget == nil || budget.LimitUSD != 5 {
+		t.Fatalf("expected the rejected update to leave the budget alone, got %+v", budget)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/projects/missing/usage", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown project, got %d", w.Code)
+	}
+}
+
+func TestUpdateSession_CostBudget(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	sess := createSession(t, r, "mock", t.TempDir())
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/sessions/"+sess.ID, bytes.NewBufferString(body)))
+		return w
+	}
+	if w := patch(`{"cost_budget":{"limit_tokens":-1}}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a negative limit to be rejected, got %d", w.Code)
+	}
+	w := patch(`{"cost_budget":{"limit_tokens":5000,"action":"cancel"}}`)
+	var resp apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.CostBudget == nil || resp.CostBudget.LimitTokens != 5000 {
+		t.Fatalf("expected the budget to be set, got %d: %s", w.Code, w.Body.String())
+	}
+	w = patch(`{"cost_budget":{}}`)
+	resp = apiTypes.SessionResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp.CostBudget != nil {
+		t.Fatalf("expected an empty budget to remove it, got %d: %s", w.Code, w.Body.String())
+	}
+}
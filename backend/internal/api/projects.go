@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
 	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
 )
 
@@ -37,7 +39,7 @@ func (h *Handler) getProject(w http.ResponseWriter, r *http.Request) {
 
 	p, err := h.projectStorage.Get(id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "project not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
 		return
 	}
 
@@ -72,17 +74,32 @@ func (h *Handler) createProject(w http.ResponseWriter, r *http.Request) {
 
 	now := time.Now()
 	p := domain.Project{
-		ID:        generateProjectID(),
-		Name:      req.Name,
-		Path:      req.Path,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:              generateProjectID(),
+		Name:            req.Name,
+		Path:            req.Path,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		CleanupCommands: cleanupCommandsFromAPI(req.CleanupCommands),
+		StorageDir:      strings.TrimSpace(req.StorageDir),
+		Guardrails:      guardrailPolicyFromAPI(req.Guardrails),
+		WorkingHours:    workingHoursFromAPI(req.WorkingHours),
+		Watch:           watchFromAPI(req.Watch),
+		ToolPolicy:      toolPolicyFromAPI(req.ToolPolicy),
+		CostBudget:      costBudgetFromAPI(req.CostBudget),
+		PullRequests:    pullRequestConfigFromAPI(req.PullRequests),
+	}
+	if !h.validProjectSettings(w, p) {
+		return
 	}
 
 	if err := h.projectStorage.Save(p); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to save project", err.Error())
 		return
 	}
+	if err := h.applyProjectSettings(p); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to apply project settings", err.Error())
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -94,7 +111,7 @@ func (h *Handler) updateProject(w http.ResponseWriter, r *http.Request) {
 
 	existing, err := h.projectStorage.Get(id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "project not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
 		return
 	}
 
@@ -122,17 +139,32 @@ func (h *Handler) updateProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	p := domain.Project{
-		ID:        id,
-		Name:      req.Name,
-		Path:      req.Path,
-		CreatedAt: existing.CreatedAt,
-		UpdatedAt: time.Now(),
+		ID:              id,
+		Name:            req.Name,
+		Path:            req.Path,
+		CreatedAt:       existing.CreatedAt,
+		UpdatedAt:       time.Now(),
+		CleanupCommands: cleanupCommandsFromAPI(req.CleanupCommands),
+		StorageDir:      strings.TrimSpace(req.StorageDir),
+		Guardrails:      guardrailPolicyFromAPI(req.Guardrails),
+		WorkingHours:    workingHoursFromAPI(req.WorkingHours),
+		Watch:           watchFromAPI(req.Watch),
+		ToolPolicy:      toolPolicyFromAPI(req.ToolPolicy),
+		CostBudget:      costBudgetFromAPI(req.CostBudget),
+		PullRequests:    pullRequestConfigFromAPI(req.PullRequests),
+	}
+	if !h.validProjectSettings(w, p) {
+		return
 	}
 
 	if err := h.projectStorage.Save(p); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to update project", err.Error())
 		return
 	}
+	if err := h.applyProjectSettings(p); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to apply project settings", err.Error())
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -143,7 +175,7 @@ func (h *Handler) deleteProject(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	if _, err := h.projectStorage.Get(id); err != nil {
-		writeError(w, http.StatusNotFound, "project not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
 		return
 	}
 
@@ -157,22 +189,269 @@ func (h *Handler) deleteProject(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete project", err.Error())
 		return
 	}
+	_ = h.executor.SetProjectStorageRoot(id, "")
+	_ = h.executor.SetProjectGuardrails(id, nil)
+	_ = h.executor.SetProjectToolPolicy(id, nil)
+	_ = h.executor.SetProjectCostBudget(id, nil)
+	_ = h.executor.SetProjectWorkingHours(id, nil)
+	_ = h.executor.SetProjectWatch(id, "", nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// validProjectSettings answers 400 if any of the project's settings are
+// invalid. Nothing is applied, so a rejected request leaves the policies of
+// the running project as they were.
+func (h *Handler) validProjectSettings(w http.ResponseWriter, p domain.Project) bool {
+	for _, check := range []struct {
+		message string
+		err     error
+	}{
+		{"invalid guardrails", p.Guardrails.Validate()},
+		{"invalid tool_policy", p.ToolPolicy.Validate()},
+		{"invalid cost_budget", p.CostBudget.Validate()},
+		{"invalid working_hours", p.WorkingHours.Validate()},
+		{"invalid watch", p.Watch.Validate()},
+		{"invalid pull_requests", p.PullRequests.Validate()},
+		{"storage_dir cannot be used", h.executor.ValidateProjectStorageRoot(p.StorageRoot())},
+	} {
+		if check.err != nil {
+			writeError(w, http.StatusBadRequest, check.message, check.err.Error())
+			return false
+		}
+	}
+	return true
+}
+
+// applyProjectSettings hands a saved project's storage root, policies,
+// budget, working hours and watch to the executor.
+func (h *Handler) applyProjectSettings(p domain.Project) error {
+	if err := h.executor.SetProjectStorageRoot(p.ID, p.StorageRoot()); err != nil {
+		return fmt.Errorf("storage_dir: %w", err)
+	}
+	if err := h.executor.SetProjectGuardrails(p.ID, p.Guardrails); err != nil {
+		return fmt.Errorf("guardrails: %w", err)
+	}
+	if err := h.executor.SetProjectToolPolicy(p.ID, p.ToolPolicy); err != nil {
+		return fmt.Errorf("tool_policy: %w", err)
+	}
+	if err := h.executor.SetProjectCostBudget(p.ID, p.CostBudget); err != nil {
+		return fmt.Errorf("cost_budget: %w", err)
+	}
+	if err := h.executor.SetProjectWorkingHours(p.ID, p.WorkingHours); err != nil {
+		return fmt.Errorf("working_hours: %w", err)
+	}
+	if err := h.executor.SetProjectWatch(p.ID, p.Path, p.Watch); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	return nil
+}
+
+// getProjectWatch returns the project's watch rules and what each last
+// triggered.
+func (h *Handler) getProjectWatch(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	p, err := h.projectStorage.Get(id)
+	if err != nil {
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
+		return
+	}
+
+	resp := apiTypes.WatchStatusResponse{Watch: watchToAPI(p.Watch), Triggers: []apiTypes.WatchTrigger{}}
+	for _, t := range h.executor.ProjectWatchTriggers(id) {
+		resp.Triggers = append(resp.Triggers, apiTypes.WatchTrigger{
+			Rule:      t.Rule,
+			SessionID: t.SessionID,
+			Files:     t.Files,
+			At:        t.At,
+			Error:     t.Error,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// getProjectUsage returns the tokens and cost the project's sessions used,
+// against the project's cost budget.
+func (h *Handler) getProjectUsage(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.projectStorage.Get(id); err != nil {
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
+		return
+	}
+
+	report := h.executor.ProjectUsage(id)
+	resp := apiTypes.ProjectUsageResponse{
+		ProjectID:      id,
+		Usage:          usageTotalsToAPI(report.Usage),
+		CostBudget:     presentation.CostBudget(report.Budget),
+		BudgetExceeded: report.Exceeded,
+		Sessions:       make([]apiTypes.SessionUsageSummary, len(report.Sessions)),
+	}
+	for i, s := range report.Sessions {
+		resp.Sessions[i] = apiTypes.SessionUsageSummary{SessionID: s.Label, UsageTotals: usageTotalsToAPI(s.UsageStats)}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func usageTotalsToAPI(u domain.UsageStats) apiTypes.UsageTotals {
+	return apiTypes.UsageTotals{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, CostUSD: u.CostUSD}
+}
+
 func generateProjectID() string {
 	var b [8]byte
 	_, _ = rand.Read(b[:])
 	return "proj_" + hex.EncodeToString(b[:])
 }
 
+// cleanupCommandsFromAPI trims cleanup commands and drops blank ones.
+func cleanupCommandsFromAPI(in []string) []string {
+	var out []string
+	for _, cmd := range in {
+		if cmd = strings.TrimSpace(cmd); cmd != "" {
+			out = append(out, cmd)
+		}
+	}
+	return out
+}
+
 func projectToResponse(p domain.Project) apiTypes.ProjectResponse {
 	return apiTypes.ProjectResponse{
-		ID:        p.ID,
-		Name:      p.Name,
-		Path:      p.Path,
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+		ID:              p.ID,
+		Name:            p.Name,
+		Path:            p.Path,
+		CleanupCommands: p.CleanupCommands,
+		StorageDir:      p.StorageDir,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+		Guardrails:      guardrailPolicyToAPI(p.Guardrails),
+		WorkingHours:    workingHoursToAPI(p.WorkingHours),
+		Watch:           watchToAPI(p.Watch),
+		ToolPolicy:      toolPolicyToAPI(p.ToolPolicy),
+		CostBudget:      presentation.CostBudget(p.CostBudget),
+		PullRequests:    pullRequestConfigToAPI(p.PullRequests),
+	}
+}
+
+func workingHoursFromAPI(w *apiTypes.WorkingHours) *domain.WorkingHours {
+	if w == nil {
+		return nil
+	}
+	return &domain.WorkingHours{
+		Days:         w.Days,
+		Start:        strings.TrimSpace(w.Start),
+		End:          strings.TrimSpace(w.End),
+		TimeZone:     strings.TrimSpace(w.TimeZone),
+		SessionKinds: w.SessionKinds,
+	}
+}
+
+func workingHoursToAPI(w *domain.WorkingHours) *apiTypes.WorkingHours {
+	if w == nil {
+		return nil
+	}
+	return &apiTypes.WorkingHours{
+		Days:         w.Days,
+		Start:        w.Start,
+		End:          w.End,
+		TimeZone:     w.TimeZone,
+		SessionKinds: w.SessionKinds,
+	}
+}
+
+func watchFromAPI(w *apiTypes.Watch) *domain.Watch {
+	if w == nil {
+		return nil
+	}
+	out := &domain.Watch{Rules: make([]domain.WatchRule, 0, len(w.Rules))}
+	for _, r := range w.Rules {
+		out.Rules = append(out.Rules, domain.WatchRule{
+			Name:         strings.TrimSpace(r.Name),
+			Globs:        r.Globs,
+			Prompt:       r.Prompt,
+			ProviderType: strings.TrimSpace(r.ProviderType),
+			ProviderID:   strings.TrimSpace(r.ProviderID),
+			AgentID:      strings.TrimSpace(r.AgentID),
+		})
+	}
+	return out
+}
+
+func watchToAPI(w *domain.Watch) *apiTypes.Watch {
+	if w == nil {
+		return nil
+	}
+	out := &apiTypes.Watch{Rules: make([]apiTypes.WatchRule, 0, len(w.Rules))}
+	for _, r := range w.Rules {
+		out.Rules = append(out.Rules, apiTypes.WatchRule{
+			Name:         r.Name,
+			Globs:        r.Globs,
+			Prompt:       r.Prompt,
+			ProviderType: r.ProviderType,
+			ProviderID:   r.ProviderID,
+			AgentID:      r.AgentID,
+		})
+	}
+	return out
+}
+
+func toolPolicyFromAPI(p *apiTypes.ToolPolicy) *domain.ToolPolicy {
+	if p == nil {
+		return nil
+	}
+	return &domain.ToolPolicy{
+		Allow:         p.Allow,
+		Deny:          p.Deny,
+		WritePaths:    p.WritePaths,
+		AllowCommands: p.AllowCommands,
+		DenyCommands:  p.DenyCommands,
+		Default:       domain.ToolPolicyOutcome(strings.TrimSpace(p.Default)),
+	}
+}
+
+func toolPolicyToAPI(p *domain.ToolPolicy) *apiTypes.ToolPolicy {
+	if p == nil {
+		return nil
+	}
+	return &apiTypes.ToolPolicy{
+		Allow:         p.Allow,
+		Deny:          p.Deny,
+		WritePaths:    p.WritePaths,
+		AllowCommands: p.AllowCommands,
+		DenyCommands:  p.DenyCommands,
+		Default:       string(p.Default),
+	}
+}
+
+// costBudgetFromAPI converts a cost budget; one without limits is none.
+func costBudgetFromAPI(b *apiTypes.CostBudget) *domain.CostBudget {
+	if b == nil || (b.LimitUSD == 0 && b.LimitTokens == 0) {
+		return nil
+	}
+	return &domain.CostBudget{LimitUSD: b.LimitUSD, LimitTokens: b.LimitTokens, Action: strings.TrimSpace(b.Action)}
+}
+
+func pullRequestConfigFromAPI(c *apiTypes.PullRequestConfig) *domain.PullRequestConfig {
+	if c == nil {
+		return nil
+	}
+	return &domain.PullRequestConfig{
+		Provider: strings.TrimSpace(c.Provider),
+		Repo:     strings.Trim(strings.TrimSpace(c.Repo), "/"),
+		Remote:   strings.TrimSpace(c.Remote),
+		Base:     strings.TrimSpace(c.Base),
+	}
+}
+
+func pullRequestConfigToAPI(c *domain.PullRequestConfig) *apiTypes.PullRequestConfig {
+	if c == nil {
+		return nil
+	}
+	return &apiTypes.PullRequestConfig{
+		Provider: c.Provider,
+		Repo:     c.Repo,
+		Remote:   c.Remote,
+		Base:     c.Base,
 	}
 }
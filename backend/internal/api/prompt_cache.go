@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) getSessionPromptCache(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	report, err := h.executor.PromptCacheReport(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.PromptCacheReport{
+		SessionID:           id,
+		PrefixHash:          report.PrefixHash,
+		PrefixLength:        report.PrefixLen,
+		Requests:            report.Requests,
+		InputTokens:         report.InputTokens,
+		CacheReadTokens:     report.CacheReadTokens,
+		CacheCreationTokens: report.CacheCreationTokens,
+		HitRate:             report.HitRate,
+		SavedInputTokens:    report.SavedInputTokens,
+	})
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
+	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/storage"
 	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
 )
@@ -21,7 +23,7 @@ func (h *Handler) listProviders(w http.ResponseWriter, r *http.Request) {
 
 	responses := make([]apiTypes.ProviderConfigResponse, len(configs))
 	for i, cfg := range configs {
-		responses[i] = providerConfigToResponse(cfg)
+		responses[i] = h.providerConfigToResponse(cfg)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -36,13 +38,13 @@ func (h *Handler) getProvider(w http.ResponseWriter, r *http.Request) {
 
 	cfg, err := h.providerStorage.Get(id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, "provider not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProviderNotFound, "provider not found", err.Error())
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(providerConfigToResponse(*cfg))
+	_ = json.NewEncoder(w).Encode(h.providerConfigToResponse(*cfg))
 }
 
 func (h *Handler) createProvider(w http.ResponseWriter, r *http.Request) {
@@ -66,6 +68,10 @@ func (h *Handler) createProvider(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "command is required for PTY provider", "")
 		return
 	}
+	if _, err := kube.FromCustom(req.Custom); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid kubernetes config", err.Error())
+		return
+	}
 
 	// Generate ID if not provided
 	id := req.ID
@@ -90,7 +96,7 @@ func (h *Handler) createProvider(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(providerConfigToResponse(cfg))
+	_ = json.NewEncoder(w).Encode(h.providerConfigToResponse(cfg))
 }
 
 func (h *Handler) updateProvider(w http.ResponseWriter, r *http.Request) {
@@ -116,6 +122,10 @@ func (h *Handler) updateProvider(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "command is required for PTY provider", "")
 		return
 	}
+	if _, err := kube.FromCustom(req.Custom); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid kubernetes config", err.Error())
+		return
+	}
 
 	cfg := storage.ProviderConfig{
 		ID:       id,
@@ -134,14 +144,14 @@ func (h *Handler) updateProvider(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(providerConfigToResponse(cfg))
+	_ = json.NewEncoder(w).Encode(h.providerConfigToResponse(cfg))
 }
 
 func (h *Handler) deleteProvider(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
 	if err := h.providerStorage.Delete(id); err != nil {
-		writeError(w, http.StatusNotFound, "provider not found", err.Error())
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProviderNotFound, "provider not found", err.Error())
 		return
 	}
 
@@ -154,8 +164,8 @@ func generateProviderID() string {
 	return "prov_" + hex.EncodeToString(b[:])
 }
 
-func providerConfigToResponse(cfg storage.ProviderConfig) apiTypes.ProviderConfigResponse {
-	return apiTypes.ProviderConfigResponse{
+func (h *Handler) providerConfigToResponse(cfg storage.ProviderConfig) apiTypes.ProviderConfigResponse {
+	resp := apiTypes.ProviderConfigResponse{
 		ID:       cfg.ID,
 		Name:     cfg.Name,
 		Type:     cfg.Type,
@@ -164,4 +174,21 @@ func providerConfigToResponse(cfg storage.ProviderConfig) apiTypes.ProviderConfi
 		Custom:   cfg.Custom,
 		IsActive: cfg.IsActive,
 	}
+	if h.executor != nil {
+		if caps, err := h.executor.ProviderCapabilities(cfg.Type); err == nil {
+			resp.Capabilities = capabilitiesToAPI(caps)
+		}
+	}
+	return resp
+}
+
+func capabilitiesToAPI(caps session.Capabilities) *apiTypes.ProviderCapabilities {
+	return &apiTypes.ProviderCapabilities{
+		Suspend:            caps.Suspend,
+		Interrupt:          caps.Interrupt,
+		Steering:           caps.Steering,
+		Terminal:           caps.Terminal,
+		Images:             caps.Images,
+		ResumeAfterRestart: caps.ResumeAfterRestart,
+	}
 }
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// openPullRequest pushes the session's branch and opens a pull request for
+// it on the GitHub or GitLab repository its project is configured with.
+func (h *Handler) openPullRequest(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.PullRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	sess, err := h.executor.GetSession(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" || strings.TrimSpace(req.Body) == "" {
+		snap := sess.Snapshot()
+		messages := snap.Messages
+		if stored, err := h.sessionStorage.GetMessages(snap.ID); err == nil {
+			messages = stored
+		}
+		base := "HEAD"
+		if g := sess.GetGitTracking(); g != nil {
+			base = g.BaseRef
+		} else if snap.StartCommit != "" {
+			base = snap.StartCommit
+		}
+		diffStat, _ := gitDiffStat(snap.WorkingDir, base)
+		title, body := composePRDescription(snap, messages, diffStat)
+		if strings.TrimSpace(req.Title) == "" {
+			req.Title = title
+		}
+		if strings.TrimSpace(req.Body) == "" {
+			req.Body = body
+		}
+	}
+	h.createPullRequest(w, r, sess, req)
+}
+
+// createPullRequest opens req for sess, adding the transcript and task
+// references to its body.
+func (h *Handler) createPullRequest(w http.ResponseWriter, r *http.Request, sess *domain.Session, req apiTypes.PullRequestRequest) {
+	var cfg *domain.PullRequestConfig
+	if sess.ProjectID != "" && h.projectStorage != nil {
+		if p, err := h.projectStorage.Get(sess.ProjectID); err == nil {
+			cfg = p.PullRequests
+		}
+	}
+	if cfg == nil {
+		writeError(w, http.StatusNotImplemented, "no pull request integration is configured", "the session's project has no pull_requests settings")
+		return
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body != "" {
+		body += "\n\n"
+	}
+	body += "---\n\n"
+	if sess.TaskID != "" {
+		body += fmt.Sprintf("Task: `%s`\n", sess.TaskID)
+	}
+	body += fmt.Sprintf("Transcript: %s/api/sessions/%s/export\n", requestBaseURL(r), sess.ID)
+
+	pr, err := h.executor.OpenPullRequest(r.Context(), sess.ID, service.PullRequestSpec{
+		Config: *cfg,
+		Title:  strings.TrimSpace(req.Title),
+		Body:   body,
+		Base:   strings.TrimSpace(req.Base),
+		Draft:  req.Draft,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPullRequest):
+			writeError(w, http.StatusBadRequest, "cannot open pull request", err.Error())
+		case errors.Is(err, service.ErrPullRequestFailed):
+			writeError(w, http.StatusBadGateway, "failed to open pull request", err.Error())
+		default:
+			writeSessionError(w, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(apiTypes.PullRequestResponse{
+		Provider: pr.Provider,
+		URL:      pr.URL,
+		Number:   pr.Number,
+		Branch:   pr.Branch,
+		Base:     pr.Base,
+		Title:    strings.TrimSpace(req.Title),
+		Body:     body,
+	})
+}
+
+// requestBaseURL is the address the request reached the API at, for links
+// back to it.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
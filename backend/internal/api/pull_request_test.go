@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestOpenPullRequest_GitHub(t *testing.T) {
+	repoDir, _ := setupGitRepo(t)
+	remoteDir := t.TempDir()
+	runGit(t, remoteDir, "init", "--bare")
+	runGit(t, repoDir, "remote", "add", "origin", remoteDir)
+	// An agent-written hook must not see the push credentials.
+	leaked := filepath.Join(t.TempDir(), "leaked")
+	hook := "#!/bin/sh\necho \"$ORBITMESH_PULL_REQUEST_TOKEN\" > " + leaked + "\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".git", "hooks", "pre-push"), []byte(hook), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		auth string
+		body map[string]any
+	}
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/widgets/pulls" {
+			http.NotFound(w, r)
+			return
+		}
+		got.auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got.body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"number":7,"html_url":"https://github.com/acme/widgets/pull/7"}`))
+	}))
+	defer github.Close()
+
+	env := newTestEnvWithConfig(t, func(cfg *service.ExecutorConfig) {
+		cfg.PullRequestForges = map[string]service.PullRequestForge{
+			"github": {APIURL: github.URL, Token: "secret-token"},
+		}
+		cfg.Projects = storage.NewProjectStorage(t.TempDir())
+	})
+	r := env.router()
+
+	postProject := func(cfg *apiTypes.PullRequestConfig) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(apiTypes.ProjectRequest{Name: "widgets", Path: repoDir, PullRequests: cfg})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/projects", bytes.NewReader(body)))
+		return w
+	}
+	if w := postProject(&apiTypes.PullRequestConfig{Provider: "bitbucket", Repo: "acme/widgets"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown provider to be rejected, got %d", w.Code)
+	}
+	w := postProject(&apiTypes.PullRequestConfig{
+		Provider: "github",
+		Repo:     "acme/widgets",
+		Base:     "main",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var project apiTypes.ProjectResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &project)
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		ProjectID:    project.ID,
+		TaskID:       "T-42",
+		Git:          &apiTypes.SessionGit{},
+	})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	// Stand in for the agent: commit on the session's branch.
+	if err := os.WriteFile(filepath.Join(repoDir, "fix.txt"), []byte("fixed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoDir, "add", "fix.txt")
+	runGit(t, repoDir, "commit", "-m", "fix the widget")
+	sha := runGit(t, repoDir, "rev-parse", "HEAD")
+
+	body, _ = json.Marshal(apiTypes.PullRequestRequest{Title: "Fix the widget"})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions/"+created.ID+"/pull-request", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var pr apiTypes.PullRequestResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &pr)
+	if pr.Number != 7 || pr.URL != "https://github.com/acme/widgets/pull/7" || pr.Branch != "orbitmesh/task/T-42" || pr.Base != "main" {
+		t.Fatalf("unexpected pull request: %+v", pr)
+	}
+
+	if got.auth != "Bearer secret-token" {
+		t.Fatalf("expected the forge's token, got %q", got.auth)
+	}
+	if got.body["title"] != "Fix the widget" || got.body["head"] != pr.Branch || got.body["base"] != "main" {
+		t.Fatalf("unexpected pull request payload: %+v", got.body)
+	}
+	prBody, _ := got.body["body"].(string)
+	if !strings.Contains(prBody, "Task: `T-42`") || !strings.Contains(prBody, "/api/sessions/"+created.ID+"/export") {
+		t.Fatalf("expected task and transcript references, got %q", prBody)
+	}
+	if pushed := runGit(t, remoteDir, "rev-parse", "refs/heads/"+pr.Branch); pushed != sha {
+		t.Fatalf("expected %s pushed to the remote, got %s", sha, pushed)
+	}
+	if _, err := os.Stat(leaked); !os.IsNotExist(err) {
+		t.Fatalf("expected the checkout's pre-push hook not to run, got %v", err)
+	}
+}
+
+func TestOpenPullRequest_NotConfigured(t *testing.T) {
+	repoDir, _ := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+	created := createSession(t, r, "mock", repoDir)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions/"+created.ID+"/pull-request", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// maxQuestionWait caps how long GET .../questions/{questionID}?wait= holds a
+// request open for an answer.
+const maxQuestionWait = time.Minute
+
+func questionToResponse(q service.HumanQuestion) apiTypes.QuestionResponse {
+	return apiTypes.QuestionResponse{
+		ID:         q.ID,
+		SessionID:  q.SessionID,
+		Question:   q.Question,
+		Options:    q.Options,
+		Status:     apiTypes.QuestionStatus(q.Status),
+		AskedAt:    q.AskedAt,
+		Answer:     q.Answer,
+		AnsweredBy: q.AnsweredBy,
+		AnsweredAt: q.AnsweredAt,
+	}
+}
+
+func writeQuestionList(w http.ResponseWriter, questions []service.HumanQuestion) {
+	resp := apiTypes.QuestionListResponse{Questions: make([]apiTypes.QuestionResponse, 0, len(questions))}
+	for _, q := range questions {
+		resp.Questions = append(resp.Questions, questionToResponse(q))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func writeQuestionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidQuestion), errors.Is(err, service.ErrInvalidAnswer):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, service.ErrQuestionNotFound):
+		writeError(w, http.StatusNotFound, "question not found", "")
+	case errors.Is(err, service.ErrQuestionClosed):
+		writeError(w, http.StatusConflict, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+func (h *Handler) listPendingQuestions(w http.ResponseWriter, r *http.Request) {
+	writeQuestionList(w, h.executor.PendingQuestions())
+}
+
+func (h *Handler) listSessionQuestions(w http.ResponseWriter, r *http.Request) {
+	questions, err := h.executor.SessionQuestions(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeQuestionList(w, questions)
+}
+
+func (h *Handler) askQuestion(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.AskQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	q, err := h.executor.AskHuman(chi.URLParam(r, "id"), req.Question, req.Options)
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(questionToResponse(q))
+}
+
+// getQuestion returns a question. With ?wait=<duration> a pending question
+// is held until it is answered or cancelled, or the wait runs out.
+func (h *Handler) getQuestion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if raw := r.URL.Query().Get("wait"); raw != "" {
+		wait, err := time.ParseDuration(raw)
+		if err != nil || wait < 0 {
+			writeError(w, http.StatusBadRequest, "invalid wait duration", raw)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, min(wait, maxQuestionWait))
+		defer cancel()
+	}
+
+	q, err := h.executor.WaitForAnswer(ctx, chi.URLParam(r, "id"), chi.URLParam(r, "questionID"))
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(questionToResponse(q))
+}
+
+func (h *Handler) answerQuestion(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.AnswerQuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	q, err := h.executor.AnswerQuestion(chi.URLParam(r, "id"), chi.URLParam(r, "questionID"), req.Answer, requestUser(r))
+	if err != nil {
+		writeQuestionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(questionToResponse(q))
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/realtime"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
+)
+
+// readerHeader names the human a request acts for when tracking read state.
+// Browsers cannot set headers on WebSocket upgrades, so the "user" query
+// parameter is accepted too.
+const readerHeader = "X-OrbitMesh-User"
+
+func requestUser(r *http.Request) string {
+	if user := strings.TrimSpace(r.Header.Get(readerHeader)); user != "" {
+		return user
+	}
+	if user := strings.TrimSpace(r.URL.Query().Get("user")); user != "" {
+		return user
+	}
+	return service.DefaultReader
+}
+
+// sessionResponseFor is sessionToResponse plus user's read state.
+func (h *Handler) sessionResponseFor(snap domain.SessionSnapshot, user string) apiTypes.SessionResponse {
+	resp := sessionToResponse(snap)
+	h.applyReadState(&resp, snap, user)
+	return resp
+}
+
+func (h *Handler) applyReadState(resp *apiTypes.SessionResponse, snap domain.SessionSnapshot, user string) {
+	mark, unread := h.executor.SessionReadState(snap, user)
+	resp.LastReadPosition = mark.Position
+	resp.UnreadCount = unread
+}
+
+// applyReadStateToSnapshot fills in user's read state on a sessions.state
+// snapshot, which the snapshot provider builds without knowing the client.
+func (h *Handler) applyReadStateToSnapshot(snapshot any, user string) {
+	state, ok := snapshot.(realtimeTypes.SessionsStateSnapshot)
+	if !ok {
+		return
+	}
+	for i := range state.Sessions {
+		sess, err := h.executor.GetSession(state.Sessions[i].ID)
+		if err != nil {
+			continue
+		}
+		h.applyReadState(&state.Sessions[i], sess.Snapshot(), user)
+	}
+}
+
+func (h *Handler) markSessionRead(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	var req apiTypes.SessionReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	position := -1
+	if req.Position != nil {
+		if *req.Position < 0 {
+			writeError(w, http.StatusBadRequest, "position must not be negative", "")
+			return
+		}
+		position = *req.Position
+	}
+
+	user := requestUser(r)
+	if _, err := h.executor.MarkSessionRead(id, user, position); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	sess, err := h.executor.GetSession(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	snap := sess.Snapshot()
+	if derivedState, derr := h.executor.DeriveSessionState(id); derr == nil {
+		snap.State = derivedState
+	}
+
+	if h.realtimeHub != nil {
+		h.realtimeHub.Publish(realtime.TopicSessionsState, realtimeTypes.ServerEnvelope{
+			Type:  realtimeTypes.ServerMessageTypeEvent,
+			Topic: realtime.TopicSessionsState,
+			Payload: realtimeTypes.SessionStateEvent{
+				Timestamp:    snap.UpdatedAt,
+				SessionID:    id,
+				DerivedState: snap.State.String(),
+				Reason:       "read",
+				Pinned:       snap.Pinned,
+				UnreadCounts: h.executor.SessionUnreadCounts(snap),
+			},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(h.sessionResponseFor(snap, user))
+}
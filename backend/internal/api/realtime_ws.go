@@ -20,6 +20,7 @@ func (h *Handler) realtimeWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user := requestUser(r)
 	client := realtime.NewClient(generateID(), conn)
 	h.realtimeHub.Register(client)
 	defer h.realtimeHub.Unregister(client.ID())
@@ -40,7 +41,7 @@ func (h *Handler) realtimeWebSocket(w http.ResponseWriter, r *http.Request) {
 
 		switch msg.Type {
 		case realtimeTypes.ClientMessageTypeSubscribe:
-			h.handleRealtimeSubscribe(client, msg.Topics)
+			h.handleRealtimeSubscribe(client, user, msg.Topics, msg.EventTypes, msg.Profile)
 		case realtimeTypes.ClientMessageTypeUnsubscribe:
 			h.handleRealtimeUnsubscribe(client, msg.Topics)
 		case realtimeTypes.ClientMessageTypePing:
@@ -53,7 +54,18 @@ func (h *Handler) realtimeWebSocket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *Handler) handleRealtimeSubscribe(client *realtime.Client, topics []string) {
+func (h *Handler) handleRealtimeSubscribe(client *realtime.Client, user string, topics, eventTypes []string, profileName string) {
+	filter, err := parseEventTypeFilter(eventTypes)
+	if err != nil {
+		h.sendRealtimeError(client, err.Error())
+		return
+	}
+	profile, err := parseVisibilityProfile(profileName)
+	if err != nil {
+		h.sendRealtimeError(client, err.Error())
+		return
+	}
+
 	valid := make([]string, 0, len(topics))
 	for _, topic := range topics {
 		if !realtime.IsSupportedTopic(topic) {
@@ -67,13 +79,16 @@ func (h *Handler) handleRealtimeSubscribe(client *realtime.Client, topics []stri
 		return
 	}
 
-	h.realtimeHub.Subscribe(client.ID(), valid)
+	h.realtimeHub.SubscribeFiltered(client.ID(), valid, filter.names())
+	h.realtimeHub.SetProfile(client.ID(), valid, profileName)
 	for _, topic := range valid {
 		snapshot, err := h.snapshotter.Snapshot(topic)
 		if err != nil {
 			h.sendRealtimeError(client, "failed to build snapshot")
 			continue
 		}
+		h.applyReadStateToSnapshot(snapshot, user)
+		snapshot = applyVisibilityToSnapshot(snapshot, profile)
 		if !client.Queue(realtimeTypes.ServerEnvelope{
 			Type:    realtimeTypes.ServerMessageTypeSnapshot,
 			Topic:   topic,
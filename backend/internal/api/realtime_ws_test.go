@@ -212,6 +212,12 @@ func TestRealtimeWebSocket_SessionsActivitySnapshotAndEvent(t *testing.T) {
 	if activityEvent.Type != "output" {
 		t.Fatalf("event type = %q, want output", activityEvent.Type)
 	}
+	if activityEvent.Version != realtimeTypes.PayloadVersion {
+		t.Fatalf("event version = %d, want %d", activityEvent.Version, realtimeTypes.PayloadVersion)
+	}
+	if _, ok := activityEvent.Data.(*realtimeTypes.OutputPayload); !ok {
+		t.Fatalf("event data = %T, want *realtime.OutputPayload", activityEvent.Data)
+	}
 }
 
 func TestRealtimeWebSocket_TerminalTopicsSnapshotAndEvent(t *testing.T) {
@@ -304,4 +310,185 @@ func TestRealtimeWebSocket_TerminalTopicsSnapshotAndEvent(t *testing.T) {
 	if outputEvent.Type != "terminal.diff" {
 		t.Fatalf("output type = %q, want terminal.diff", outputEvent.Type)
 	}
+	if outputEvent.Version != realtimeTypes.PayloadVersion {
+		t.Fatalf("output version = %d, want %d", outputEvent.Version, realtimeTypes.PayloadVersion)
+	}
+	if _, ok := outputEvent.Data.(*realtimeTypes.TerminalDiffPayload); !ok {
+		t.Fatalf("output data = %T, want *realtime.TerminalDiffPayload", outputEvent.Data)
+	}
+}
+
+func TestRealtimeWebSocket_NotificationsTopicPublishesFailures(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/realtime"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial realtime websocket: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(realtimeTypes.ClientEnvelope{
+		Type:   realtimeTypes.ClientMessageTypeSubscribe,
+		Topics: []string{"notifications"},
+	}); err != nil {
+		t.Fatalf("write subscribe message: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var snapshotMsg realtimeTypes.ServerEnvelope
+	if err := conn.ReadJSON(&snapshotMsg); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if snapshotMsg.Type != realtimeTypes.ServerMessageTypeSnapshot || snapshotMsg.Topic != "notifications" {
+		t.Fatalf("unexpected snapshot envelope: %+v", snapshotMsg)
+	}
+
+	env.broadcaster.Broadcast(domain.NewErrorEvent(sessionID, "provider crashed", "crash", nil))
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var eventMsg struct {
+		Type    realtimeTypes.ServerMessageType `json:"type"`
+		Payload realtimeTypes.Notification      `json:"payload"`
+	}
+	if err := conn.ReadJSON(&eventMsg); err != nil {
+		t.Fatalf("read event: %v", err)
+	}
+	if eventMsg.Type != realtimeTypes.ServerMessageTypeEvent {
+		t.Fatalf("event type = %q", eventMsg.Type)
+	}
+	n := eventMsg.Payload
+	if n.Kind != realtimeTypes.NotificationKindFailure || n.SessionID != sessionID || n.Body != "provider crashed" {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+	if !strings.HasPrefix(n.DedupeKey, "failure:"+sessionID+":") {
+		t.Fatalf("unexpected dedupe key %q", n.DedupeKey)
+	}
+}
+
+func TestRealtimeWebSocket_SessionsActivityEventTypeFilter(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/realtime"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial realtime websocket: %v", err)
+	}
+	defer conn.Close()
+
+	topic := "sessions.activity:" + sessionID
+	if err := conn.WriteJSON(realtimeTypes.ClientEnvelope{
+		Type:       realtimeTypes.ClientMessageTypeSubscribe,
+		Topics:     []string{topic},
+		EventTypes: []string{"metric, status_change"},
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var snapshotMsg realtimeTypes.ServerEnvelope
+	if err := conn.ReadJSON(&snapshotMsg); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if snapshotMsg.Type != realtimeTypes.ServerMessageTypeSnapshot {
+		t.Fatalf("snapshot type = %q", snapshotMsg.Type)
+	}
+
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "filtered out", nil))
+	env.broadcaster.Broadcast(domain.NewStatusChangeEvent(sessionID, domain.SessionStateIdle, domain.SessionStateRunning, "started", nil))
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var eventMsg realtimeTypes.ServerEnvelope
+	if err := conn.ReadJSON(&eventMsg); err != nil {
+		t.Fatalf("read activity event: %v", err)
+	}
+	eventBytes, err := json.Marshal(eventMsg.Payload)
+	if err != nil {
+		t.Fatalf("marshal activity event payload: %v", err)
+	}
+	var activityEvent realtimeTypes.SessionActivityEvent
+	if err := json.Unmarshal(eventBytes, &activityEvent); err != nil {
+		t.Fatalf("decode activity event payload: %v", err)
+	}
+	if activityEvent.Type != "status_change" {
+		t.Fatalf("event type = %q, want status_change", activityEvent.Type)
+	}
+}
+
+func TestRealtimeWebSocket_SessionsActivityVisibilityProfile(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/realtime"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial realtime websocket: %v", err)
+	}
+	defer conn.Close()
+
+	topic := "sessions.activity:" + sessionID
+	if err := conn.WriteJSON(realtimeTypes.ClientEnvelope{
+		Type:    realtimeTypes.ClientMessageTypeSubscribe,
+		Topics:  []string{topic},
+		Profile: "chat",
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var snapshotMsg realtimeTypes.ServerEnvelope
+	if err := conn.ReadJSON(&snapshotMsg); err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if snapshotMsg.Type != realtimeTypes.ServerMessageTypeSnapshot {
+		t.Fatalf("snapshot type = %q", snapshotMsg.Type)
+	}
+
+	env.broadcaster.Broadcast(domain.NewThoughtEvent(sessionID, "hidden reasoning", nil))
+	env.broadcaster.Broadcast(domain.NewMetadataEvent(sessionID, "model", "opus", nil))
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "visible", nil))
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var eventMsg realtimeTypes.ServerEnvelope
+	if err := conn.ReadJSON(&eventMsg); err != nil {
+		t.Fatalf("read activity event: %v", err)
+	}
+	eventBytes, err := json.Marshal(eventMsg.Payload)
+	if err != nil {
+		t.Fatalf("marshal activity event payload: %v", err)
+	}
+	var activityEvent realtimeTypes.SessionActivityEvent
+	if err := json.Unmarshal(eventBytes, &activityEvent); err != nil {
+		t.Fatalf("decode activity event payload: %v", err)
+	}
+	if activityEvent.Type != "output" {
+		t.Fatalf("event type = %q, want output", activityEvent.Type)
+	}
+
+	if err := conn.WriteJSON(realtimeTypes.ClientEnvelope{
+		Type:    realtimeTypes.ClientMessageTypeSubscribe,
+		Topics:  []string{topic},
+		Profile: "verbose",
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var errMsg realtimeTypes.ServerEnvelope
+	if err := conn.ReadJSON(&errMsg); err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if errMsg.Type != realtimeTypes.ServerMessageTypeError || !strings.Contains(errMsg.Message, "verbose") {
+		t.Fatalf("unknown profile reply = %+v", errMsg)
+	}
 }
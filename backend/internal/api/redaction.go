@@ -0,0 +1,60 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) redactMessages(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.RedactMessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if len(req.MessageIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "message_ids is required", "")
+		return
+	}
+
+	n, err := h.executor.RedactMessages(chi.URLParam(r, "id"), req.MessageIDs, req.Reason, requestUser(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrMessageNotFound) {
+			writeError(w, http.StatusNotFound, err.Error(), "")
+			return
+		}
+		writeSessionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.RedactMessagesResponse{Redacted: n})
+}
+
+// listAuditEntries returns the audit log, limited to one session with
+// ?session_id=.
+func (h *Handler) listAuditEntries(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.executor.AuditEntries(r.URL.Query().Get("session_id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	resp := apiTypes.AuditLogResponse{Entries: make([]apiTypes.AuditEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, apiTypes.AuditEntry{
+			Timestamp: entry.Timestamp,
+			Actor:     entry.Actor,
+			Action:    entry.Action,
+			SessionID: entry.SessionID,
+			Targets:   entry.Targets,
+			Reason:    entry.Reason,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
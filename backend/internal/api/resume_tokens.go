@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listResumeTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.executor.ResumeTokens(chi.URLParam(r, "id"))
+	if err != nil {
+		writeResumeTokenError(w, err)
+		return
+	}
+	now := time.Now()
+	resp := apiTypes.ResumeTokenListResponse{Tokens: make([]apiTypes.ResumeToken, 0, len(tokens))}
+	for _, t := range tokens {
+		resp.Tokens = append(resp.Tokens, resumeTokenToAPI(t, now))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) revokeResumeToken(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.RevokeResumeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	token, err := h.executor.RevokeResumeToken(chi.URLParam(r, "token"), req.Reason, requestUser(r))
+	if err != nil {
+		writeResumeTokenError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resumeTokenToAPI(token, time.Now()))
+}
+
+func writeResumeTokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, storage.ErrResumeTokenNotFound), errors.Is(err, storage.ErrInvalidSessionID):
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeNotFound, "resume token not found", "")
+	case errors.Is(err, service.ErrResumeTokensUnlisted):
+		writeError(w, http.StatusNotImplemented, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+func resumeTokenToAPI(t *storage.ResumeTokenMetadata, now time.Time) apiTypes.ResumeToken {
+	return apiTypes.ResumeToken{
+		TokenID:          t.TokenID,
+		SessionID:        t.SessionID,
+		AttemptID:        t.AttemptID,
+		Status:           service.ResumeTokenStatus(t, now),
+		CreatedAt:        t.CreatedAt,
+		ExpiresAt:        t.ExpiresAt,
+		ConsumedAt:       t.ConsumedAt,
+		RevokedAt:        t.RevokedAt,
+		RevocationReason: t.RevocationReason,
+	}
+}
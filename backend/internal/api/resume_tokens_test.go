@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestResumeTokens_ListAndRevoke(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	created := createSession(t, r, "mock", "/tmp")
+	other := createSession(t, r, "mock", "/tmp")
+	now := time.Now().UTC()
+	_ = env.store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:      "attempt-wait",
+		SessionID:      created.ID,
+		ProviderType:   "mock",
+		StartedAt:      now.Add(-time.Minute),
+		HeartbeatAt:    now,
+		TerminalReason: "interrupted",
+		WaitKind:       "tool_call",
+		WaitRef:        "tool-1",
+		ResumeTokenID:  "token-live",
+	})
+	consumed := now.Add(-time.Minute)
+	for _, token := range []*storage.ResumeTokenMetadata{
+		{TokenID: "token-old", SessionID: created.ID, AttemptID: "attempt-old", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour), ConsumedAt: &consumed, RevokedAt: &consumed, RevocationReason: "consumed"},
+		{TokenID: "token-live", SessionID: created.ID, AttemptID: "attempt-wait", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{TokenID: "token-other", SessionID: other.ID, AttemptID: "attempt-other", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	} {
+		_ = env.store.SaveResumeToken(token)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/resume-tokens", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", w.Code, w.Body.String())
+	}
+	var list apiTypes.ResumeTokenListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Tokens) != 2 || list.Tokens[0].TokenID != "token-old" || list.Tokens[1].TokenID != "token-live" {
+		t.Fatalf("tokens = %+v, want token-old then token-live", list.Tokens)
+	}
+	if list.Tokens[0].Status != "consumed" || list.Tokens[1].Status != "active" {
+		t.Errorf("statuses = %s, %s", list.Tokens[0].Status, list.Tokens[1].Status)
+	}
+
+	body, _ := json.Marshal(apiTypes.RevokeResumeTokenRequest{Reason: "leaked in a log"})
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/resume-tokens/token-live/revoke", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var revoked apiTypes.ResumeToken
+	_ = json.Unmarshal(w.Body.Bytes(), &revoked)
+	if w.Code != http.StatusOK || revoked.Status != "revoked" || revoked.RevocationReason != "leaked in a log" || revoked.RevokedAt == nil {
+		t.Fatalf("revoke = %d %+v", w.Code, revoked)
+	}
+
+	body, _ = json.Marshal(apiTypes.ResumeSessionRequest{TokenID: "token-live"})
+	req = httptest.NewRequest(http.MethodPost, "/api/sessions/"+created.ID+"/resume", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusGone {
+		t.Errorf("resume with revoked token = %d, want 410", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/resume-tokens/missing/revoke", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("revoke unknown token = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listRunAttempts(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	attempts, err := h.executor.RunAttempts(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	resp := apiTypes.RunAttemptListResponse{Attempts: make([]apiTypes.RunAttempt, 0, len(attempts))}
+	for _, a := range attempts {
+		ops := make([]apiTypes.ControlOperation, 0, len(a.Operations))
+		for _, op := range a.Operations {
+			ops = append(ops, apiTypes.ControlOperation{
+				Seq:         op.Seq,
+				Op:          op.Op,
+				RequestedAt: op.RequestedAt,
+				Outcome:     op.Outcome,
+				Error:       op.Error,
+				Replayed:    op.Replayed,
+			})
+		}
+		attempt := apiTypes.RunAttempt{
+			AttemptID:          a.AttemptID,
+			ProviderType:       a.ProviderType,
+			ProviderID:         a.ProviderID,
+			StartedAt:          a.StartedAt,
+			EndedAt:            a.EndedAt,
+			TerminalReason:     a.TerminalReason,
+			InterruptionReason: a.InterruptionReason,
+			WaitKind:           a.WaitKind,
+			WaitRef:            a.WaitRef,
+			Operations:         ops,
+			Delivery:           a.Delivery,
+			DeliveryError:      a.DeliveryError,
+			ContinuedFrom:      a.ContinuedFrom,
+		}
+		if h.executor.AttemptAbandoned(a) {
+			heartbeat := a.HeartbeatAt
+			attempt.Abandoned = true
+			attempt.HeartbeatAt = &heartbeat
+		}
+		resp.Attempts = append(resp.Attempts, attempt)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) explainRunAttempt(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	attemptID := chi.URLParam(r, "attemptID")
+
+	ex, err := h.executor.ExplainRun(id, attemptID)
+	if err != nil {
+		if errors.Is(err, storage.ErrRunAttemptNotFound) || errors.Is(err, storage.ErrInvalidSessionID) {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeNotFound, "run attempt not found", "")
+			return
+		}
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ex)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestListRunAttempts(t *testing.T) {
+	env := newTestEnv(t)
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: "/tmp"})
+	req := httptest.NewRequest("POST", "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	var createResp apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &createResp)
+	sessionID := createResp.ID
+
+	started := time.Now().UTC().Add(-time.Hour)
+	ended := started.Add(time.Minute)
+	_ = env.store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:      "a1",
+		SessionID:      sessionID,
+		ProviderType:   "mock",
+		ProviderID:     "mock-1",
+		StartedAt:      started,
+		EndedAt:        &ended,
+		TerminalReason: "failed",
+		Delivery:       "failed",
+		DeliveryError:  "provider exited",
+		HeartbeatAt:    ended,
+	})
+	heartbeat := started.Add(30 * time.Minute)
+	_ = env.store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:    "a2",
+		SessionID:    sessionID,
+		ProviderType: "mock",
+		StartedAt:    started.Add(10 * time.Minute),
+		WaitKind:     "tool_call",
+		WaitRef:      "call-1",
+		HeartbeatAt:  heartbeat,
+		BootID:       "earlier-boot",
+	})
+
+	req = httptest.NewRequest("GET", "/api/sessions/"+sessionID+"/attempts", nil)
+	w = httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.RunAttemptListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Attempts) != 2 {
+		t.Fatalf("attempts = %+v, want 2", resp.Attempts)
+	}
+	first, second := resp.Attempts[0], resp.Attempts[1]
+	if first.AttemptID != "a1" {
+		first, second = second, first
+	}
+	if first.TerminalReason != "failed" || first.ProviderID != "mock-1" || first.EndedAt == nil {
+		t.Errorf("first attempt = %+v", first)
+	}
+	if first.Abandoned || first.Delivery != "failed" || first.DeliveryError != "provider exited" {
+		t.Errorf("first attempt delivery = %+v", first)
+	}
+	if !second.Abandoned || second.HeartbeatAt == nil || !second.HeartbeatAt.Equal(heartbeat) || second.WaitKind != "tool_call" || second.WaitRef != "call-1" {
+		t.Errorf("second attempt should be abandoned at its heartbeat, got %+v", second)
+	}
+
+	req = httptest.NewRequest("GET", "/api/sessions/missing/attempts", nil)
+	w = httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown session status = %d, want 404", w.Code)
+	}
+}
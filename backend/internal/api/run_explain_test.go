@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestExplainRunAttempt(t *testing.T) {
+	env := newTestEnv(t)
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: "/tmp"})
+	req := httptest.NewRequest("POST", "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	var createResp apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &createResp)
+	sessionID := createResp.ID
+
+	started := time.Now().UTC().Add(-time.Minute)
+	_ = env.store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:      "a1",
+		SessionID:      sessionID,
+		ProviderType:   "mock",
+		StartedAt:      started,
+		TerminalReason: "failed",
+		HeartbeatAt:    started,
+	})
+	env.broadcaster.Broadcast(domain.NewToolCallEvent(sessionID, domain.ToolCallData{ID: "c1", Name: "Bash", Status: "running"}, nil))
+	env.broadcaster.Broadcast(domain.NewErrorEvent(sessionID, "rate limited", "provider_rate_limit", nil))
+	ended := time.Now().UTC().Add(time.Second)
+	_ = env.store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:      "a1",
+		SessionID:      sessionID,
+		ProviderType:   "mock",
+		StartedAt:      started,
+		EndedAt:        &ended,
+		TerminalReason: "failed",
+		HeartbeatAt:    ended,
+	})
+
+	req = httptest.NewRequest("GET", "/api/sessions/"+sessionID+"/attempts/a1/explain", nil)
+	w = httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	var ex service.RunExplanation
+	if err := json.Unmarshal(w.Body.Bytes(), &ex); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ex.Outcome != "failed" || ex.Config.ProviderType != "mock" || ex.Config.WorkingDir != "/tmp" {
+		t.Fatalf("explanation = %+v", ex)
+	}
+	if len(ex.Errors) != 1 || ex.Errors[0].Code != "provider_rate_limit" || ex.Errors[0].Source != "event" {
+		t.Fatalf("errors = %+v, want the coded error event", ex.Errors)
+	}
+	var kinds []string
+	for _, entry := range ex.Timeline {
+		kinds = append(kinds, entry.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != "tool_call" || kinds[1] != "error" {
+		t.Fatalf("timeline kinds = %v, want [tool_call error]", kinds)
+	}
+
+	req = httptest.NewRequest("GET", "/api/sessions/"+sessionID+"/attempts/missing/explain", nil)
+	w = httptest.NewRecorder()
+	env.router().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown attempt status = %d, want 404", w.Code)
+	}
+}
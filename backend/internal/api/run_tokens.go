@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+)
+
+// runTokenMiddleware checks requests that carry a run's API token, as
+// handed to MCP servers through {{.APIToken}}: the token must still be
+// live, and it only reaches its own session's endpoints.
+func (h *Handler) runTokenMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !service.IsRunAPIToken(strings.TrimSpace(bearer)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sessionID, ok := h.executor.ResolveRunAPIToken(strings.TrimSpace(bearer))
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid or expired API token", "")
+			return
+		}
+		if pathSessionID(r.URL.Path) != sessionID {
+			writeError(w, http.StatusForbidden, "API token is scoped to another session", "")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pathSessionID returns the session ID of a /api/sessions/{id} or
+// /api/v1/sessions/{id} path, or "" for any other path.
+func pathSessionID(path string) string {
+	for _, prefix := range []string{"/api/sessions/", "/api/v1/sessions/"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			id, _, _ := strings.Cut(rest, "/")
+			return id
+		}
+	}
+	return ""
+}
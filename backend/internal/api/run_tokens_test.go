@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestRunTokenMiddleware(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	sess := createSession(t, r, "mock", "/tmp")
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := get("/api/sessions/"+sess.ID, ""); code != http.StatusOK {
+		t.Fatalf("without token: got %d", code)
+	}
+	if code := get("/api/sessions/"+sess.ID, "omrun_forged"); code != http.StatusUnauthorized {
+		t.Fatalf("forged run token: got %d, want 401", code)
+	}
+	if code := get("/api/sessions/"+sess.ID, "some-other-bearer"); code != http.StatusOK {
+		t.Fatalf("unrelated bearer: got %d", code)
+	}
+}
+
+func TestPathSessionID(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/sessions/s1":                      "s1",
+		"/api/sessions/s1/dock/mcp/request":     "s1",
+		"/api/v1/sessions/s1/terminal/snapshot": "s1",
+		"/api/projects":                         "",
+		"/api/sessionsX/s1":                     "",
+	} {
+		if got := pathSessionID(path); got != want {
+			t.Errorf("pathSessionID(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestCreateSession_InvalidMCPServerEnv(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   "/tmp",
+		MCPServers:   []apiTypes.MCPServerConfig{{Name: "tools", Command: "tools-mcp", Env: map[string]string{"TOKEN": "{{.Secret}}"}}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
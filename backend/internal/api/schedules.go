@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := h.executor.Schedules()
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	resp := apiTypes.ScheduleListResponse{Schedules: make([]apiTypes.ScheduleResponse, len(schedules))}
+	for i, sched := range schedules {
+		resp.Schedules[i] = scheduleToResponse(sched)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) getSchedule(w http.ResponseWriter, r *http.Request) {
+	sched, err := h.executor.Schedule(chi.URLParam(r, "id"))
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(scheduleToResponse(*sched))
+}
+
+func (h *Handler) createSchedule(w http.ResponseWriter, r *http.Request) {
+	sched, ok := h.decodeSchedule(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.executor.CreateSchedule(sched)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(scheduleToResponse(*created))
+}
+
+func (h *Handler) updateSchedule(w http.ResponseWriter, r *http.Request) {
+	sched, ok := h.decodeSchedule(w, r)
+	if !ok {
+		return
+	}
+	updated, err := h.executor.UpdateSchedule(chi.URLParam(r, "id"), sched)
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(scheduleToResponse(*updated))
+}
+
+func (h *Handler) deleteSchedule(w http.ResponseWriter, r *http.Request) {
+	if err := h.executor.DeleteSchedule(chi.URLParam(r, "id")); err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runSchedule starts a session of the schedule now.
+func (h *Handler) runSchedule(w http.ResponseWriter, r *http.Request) {
+	run, err := h.executor.RunSchedule(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeScheduleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(scheduleRunToAPI(run))
+}
+
+// decodeSchedule reads a ScheduleRequest, resolving the working directory
+// from the project path and then the server's git directory. It answers
+// the request itself when it fails.
+func (h *Handler) decodeSchedule(w http.ResponseWriter, r *http.Request) (domain.Schedule, bool) {
+	var req apiTypes.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return domain.Schedule{}, false
+	}
+
+	workingDir := strings.TrimSpace(req.WorkingDir)
+	if req.ProjectID != "" && h.projectStorage != nil {
+		proj, err := h.projectStorage.Get(req.ProjectID)
+		if err != nil {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeProjectNotFound, "project not found", err.Error())
+			return domain.Schedule{}, false
+		}
+		if workingDir == "" {
+			workingDir = proj.Path
+		}
+	}
+	if workingDir == "" {
+		workingDir = h.gitDir
+	}
+
+	return domain.Schedule{
+		Name:         strings.TrimSpace(req.Name),
+		Cron:         strings.TrimSpace(req.Cron),
+		Interval:     strings.TrimSpace(req.Interval),
+		TimeZone:     req.TimeZone,
+		ProviderType: req.ProviderType,
+		ProviderID:   req.ProviderID,
+		AgentID:      req.AgentID,
+		ProjectID:    req.ProjectID,
+		WorkingDir:   workingDir,
+		Prompt:       req.Prompt,
+		Paused:       req.Paused,
+	}, true
+}
+
+func writeScheduleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSchedulesDisabled):
+		writeError(w, http.StatusNotImplemented, err.Error(), "")
+	case errors.Is(err, service.ErrInvalidSchedule):
+		writeError(w, http.StatusBadRequest, "invalid schedule", err.Error())
+	case errors.Is(err, storage.ErrScheduleNotFound):
+		writeError(w, http.StatusNotFound, "schedule not found", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "schedule operation failed", err.Error())
+	}
+}
+
+func scheduleToResponse(sched domain.Schedule) apiTypes.ScheduleResponse {
+	resp := apiTypes.ScheduleResponse{
+		ID:           sched.ID,
+		Name:         sched.Name,
+		Cron:         sched.Cron,
+		Interval:     sched.Interval,
+		TimeZone:     sched.TimeZone,
+		ProviderType: sched.ProviderType,
+		ProviderID:   sched.ProviderID,
+		AgentID:      sched.AgentID,
+		ProjectID:    sched.ProjectID,
+		WorkingDir:   sched.WorkingDir,
+		Prompt:       sched.Prompt,
+		Paused:       sched.Paused,
+		CreatedAt:    sched.CreatedAt,
+		NextRunAt:    optionalTime(sched.NextRunAt),
+		LastRunAt:    optionalTime(sched.LastRunAt),
+		Runs:         make([]apiTypes.ScheduleRun, len(sched.Runs)),
+	}
+	for i, run := range sched.Runs {
+		resp.Runs[i] = scheduleRunToAPI(run)
+	}
+	return resp
+}
+
+func scheduleRunToAPI(run domain.ScheduleRun) apiTypes.ScheduleRun {
+	return apiTypes.ScheduleRun{At: run.At, SessionID: run.SessionID, Error: run.Error, Skipped: run.Skipped}
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSchedulesCRUD(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, &buf))
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/schedules", apiTypes.ScheduleRequest{Name: "nightly", Cron: "not cron", ProviderType: "mock", WorkingDir: "/tmp", Prompt: "fix"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid cron: expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodPost, "/api/v1/schedules", apiTypes.ScheduleRequest{Name: "nightly", Cron: "0 3 * * *", ProviderType: "mock", WorkingDir: "/tmp", Prompt: "Run the test fixer"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created apiTypes.ScheduleResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.ID == "" || created.NextRunAt == nil || created.NextRunAt.Minute() != 0 {
+		t.Fatalf("unexpected schedule %+v", created)
+	}
+
+	w = do(http.MethodPost, "/api/v1/schedules/"+created.ID+"/run", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("run: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var run apiTypes.ScheduleRun
+	_ = json.Unmarshal(w.Body.Bytes(), &run)
+	if run.SessionID == "" || run.Error != "" {
+		t.Fatalf("unexpected run %+v", run)
+	}
+	if _, err := env.executor.GetSession(run.SessionID); err != nil {
+		t.Fatalf("run session missing: %v", err)
+	}
+
+	w = do(http.MethodPut, "/api/v1/schedules/"+created.ID, apiTypes.ScheduleRequest{Name: "nightly", Interval: "12h", ProviderType: "mock", WorkingDir: "/tmp", Prompt: "Run the test fixer", Paused: true})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var updated apiTypes.ScheduleResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &updated)
+	if updated.NextRunAt != nil || len(updated.Runs) != 1 || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("paused schedule should keep its runs and have no next run, got %+v", updated)
+	}
+
+	w = do(http.MethodGet, "/api/v1/schedules", nil)
+	var list apiTypes.ScheduleListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Schedules) != 1 || list.Schedules[0].Interval != "12h" {
+		t.Fatalf("list = %d %+v", w.Code, list)
+	}
+
+	if w = do(http.MethodDelete, "/api/v1/schedules/"+created.ID, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", w.Code)
+	}
+	if w = do(http.MethodGet, "/api/v1/schedules/"+created.ID, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("get deleted: expected 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// searchMessages searches the messages of every session for the words of
+// ?q=, optionally only in the sessions of ?project_id= or ?provider_type=.
+// Hits are newest first; ?limit= caps them.
+func (h *Handler) searchMessages(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := storage.MessageQuery{
+		Text:         strings.TrimSpace(params.Get("q")),
+		ProjectID:    params.Get("project_id"),
+		ProviderType: params.Get("provider_type"),
+		Metadata:     metadataFilter(params),
+	}
+	if len(storage.SearchTerms(query.Text)) == 0 {
+		writeError(w, http.StatusBadRequest, "q is required", "the query must contain a word")
+		return
+	}
+	if raw := params.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit", "limit must be a positive integer")
+			return
+		}
+		query.Limit = limit
+	}
+
+	var (
+		result storage.MessageSearchResult
+		err    error
+	)
+	if searcher, ok := h.sessionStorage.(storage.MessageSearcher); ok {
+		result, err = searcher.SearchMessages(query)
+	} else {
+		result, err = storage.ScanMessages(h.sessionStorage, query)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "search failed", err.Error())
+		return
+	}
+
+	resp := apiTypes.MessageSearchResponse{
+		Query: query.Text,
+		Hits:  make([]apiTypes.MessageSearchHit, 0, len(result.Hits)),
+		More:  result.More,
+	}
+	for _, hit := range result.Hits {
+		sess, err := h.executor.GetSession(hit.SessionID)
+		if err != nil {
+			// Deleted since it was indexed.
+			continue
+		}
+		snap := sess.Snapshot()
+		resp.Hits = append(resp.Hits, apiTypes.MessageSearchHit{
+			SessionID:    snap.ID,
+			SessionTitle: snap.Title,
+			SessionState: apiTypes.SessionState(snap.State.String()),
+			ProviderType: snap.ProviderType,
+			ProjectID:    snap.ProjectID,
+			Index:        hit.Index,
+			Message: apiTypes.Message{
+				ID:        hit.Message.ID,
+				Kind:      string(hit.Message.Kind),
+				Contents:  hit.Message.Contents,
+				Timestamp: hit.Message.Timestamp,
+				Notice:    presentation.Notice(hit.Message.Notice),
+			},
+			Snippet: hit.Snippet,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
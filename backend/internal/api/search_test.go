@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSearchMessages(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	ts := time.Now().UTC()
+	for _, s := range []struct{ id, project, contents string }{
+		{"search-a", "proj", "The deploy failed on a timeout"},
+		{"search-b", "", "Retrying the deploy after the timeout"},
+	} {
+		sess := domain.NewSession(s.id, "mock", "/tmp")
+		sess.ProjectID = s.project
+		sess.Messages = []domain.Message{
+			{ID: "m0", Kind: domain.MessageKindUser, Contents: "hello", Timestamp: ts},
+			{ID: "m1", Kind: domain.MessageKindOutput, Contents: s.contents, Timestamp: ts.Add(time.Second)},
+		}
+		ts = ts.Add(time.Minute)
+		if err := env.store.Save(sess); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	search := func(query string) (int, apiTypes.MessageSearchResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/search?"+query, nil))
+		var resp apiTypes.MessageSearchResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return w.Code, resp
+	}
+
+	code, resp := search("q=deploy+Timeout")
+	if code != http.StatusOK || len(resp.Hits) != 2 {
+		t.Fatalf("search = %d %+v, want 2 hits", code, resp)
+	}
+	if hit := resp.Hits[0]; hit.SessionID != "search-b" || hit.Index != 1 || hit.Message.ID != "m1" || hit.ProviderType != "mock" || hit.Snippet == "" {
+		t.Fatalf("first hit = %+v", hit)
+	}
+	if _, resp = search("q=deploy&project_id=proj"); len(resp.Hits) != 1 || resp.Hits[0].SessionID != "search-a" || resp.Hits[0].ProjectID != "proj" {
+		t.Fatalf("project search = %+v", resp.Hits)
+	}
+	if _, resp = search("q=deploy&limit=1"); len(resp.Hits) != 1 || !resp.More {
+		t.Fatalf("limited search = %+v", resp)
+	}
+	if _, resp = search("q=deploy&provider_type=claude"); len(resp.Hits) != 0 {
+		t.Fatalf("provider search = %+v, want no hits", resp.Hits)
+	}
+	if code, _ = search("q=+-+"); code != http.StatusBadRequest {
+		t.Fatalf("empty query = %d, want 400", code)
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// sessionBatchConcurrency bounds how many sessions a batch operates on at
+// once.
+const sessionBatchConcurrency = 8
+
+// batchCancelSessions cancels the current run of every selected session.
+func (h *Handler) batchCancelSessions(w http.ResponseWriter, r *http.Request) {
+	h.batchSessions(w, r, h.executor.CancelRun)
+}
+
+// batchStopSessions stops every selected session.
+func (h *Handler) batchStopSessions(w http.ResponseWriter, r *http.Request) {
+	h.batchSessions(w, r, h.executor.StopSession)
+}
+
+// bulkSessions applies the named operation to every selected session, so
+// scripts can manage many sessions in one call.
+func (h *Handler) bulkSessions(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.SessionBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	var op func(context.Context, string) error
+	switch req.Operation {
+	case apiTypes.SessionBulkStop:
+		op = h.executor.StopSession
+	case apiTypes.SessionBulkCancel:
+		op = h.executor.CancelRun
+	case apiTypes.SessionBulkDelete:
+		actor := requestUser(r)
+		op = func(ctx context.Context, id string) error {
+			return h.executor.DeleteSession(ctx, id, actor)
+		}
+	case apiTypes.SessionBulkResume:
+		op = func(ctx context.Context, id string) error {
+			_, err := h.executor.ResumeSession(ctx, id)
+			return err
+		}
+	case "":
+		writeError(w, http.StatusBadRequest, "operation is required", "")
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "unknown operation", "use stop, cancel, delete or resume")
+		return
+	}
+	h.runSessionBatch(w, r, req, op)
+}
+
+// batchSessions applies op to each selected session. A session that fails
+// does not stop the batch; its error is reported in its result.
+func (h *Handler) batchSessions(w http.ResponseWriter, r *http.Request, op func(context.Context, string) error) {
+	var req apiTypes.SessionBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	h.runSessionBatch(w, r, req, op)
+}
+
+// runSessionBatch runs op on the sessions req selects, up to
+// sessionBatchConcurrency at a time. Results keep the order of the IDs.
+func (h *Handler) runSessionBatch(w http.ResponseWriter, r *http.Request, req apiTypes.SessionBatchRequest, op func(context.Context, string) error) {
+	var ids []string
+	switch {
+	case len(req.SessionIDs) > 0 && req.Filter != nil:
+		writeError(w, http.StatusBadRequest, "session_ids and filter are mutually exclusive", "")
+		return
+	case len(req.SessionIDs) > 0:
+		ids = uniqueIDs(req.SessionIDs)
+	case req.Filter != nil:
+		var err error
+		if ids, err = h.filterSessionIDs(*req.Filter); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid filter", err.Error())
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "session_ids or filter is required", "")
+		return
+	}
+
+	resp := apiTypes.SessionBatchResponse{Results: make([]apiTypes.SessionBatchResult, len(ids))}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, sessionBatchConcurrency)
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			resp.Results[i] = apiTypes.SessionBatchResult{SessionID: id, OK: true}
+			if err := op(r.Context(), id); err != nil {
+				resp.Results[i] = apiTypes.SessionBatchResult{SessionID: id, Code: serviceErrorCode(err), Error: err.Error()}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, result := range resp.Results {
+		if result.OK {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// filterSessionIDs returns the IDs of the sessions matching every set field
+// of f. An empty filter is rejected rather than matching every session.
+func (h *Handler) filterSessionIDs(f apiTypes.SessionBatchFilter) ([]string, error) {
+	if f.ProjectID == "" && f.Tag == "" && f.State == "" {
+		return nil, errors.New("set at least one of project_id, tag or state")
+	}
+	var state domain.SessionState
+	if f.State != "" {
+		parsed, err := domain.ParseSessionState(string(f.State))
+		if err != nil {
+			return nil, err
+		}
+		state = parsed
+	}
+
+	ids := []string{}
+	for _, s := range h.executor.ListSessions() {
+		snap := s.Snapshot()
+		if f.ProjectID != "" && snap.ProjectID != f.ProjectID {
+			continue
+		}
+		if f.Tag != "" && !strings.EqualFold(snap.Kind, f.Tag) && !strings.EqualFold(snap.ProviderType, f.Tag) {
+			continue
+		}
+		if f.State != "" {
+			current := snap.State
+			if derived, err := h.executor.DeriveSessionState(snap.ID); err == nil {
+				current = derived
+			}
+			if current != state {
+				continue
+			}
+		}
+		ids = append(ids, snap.ID)
+	}
+	return ids, nil
+}
+
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func postSessionBatch(t *testing.T, r http.Handler, op string, req apiTypes.SessionBatchRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/sessions/batch/"+op, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httpReq)
+	return w
+}
+
+func TestBatchStopSessions_ByID(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	a := createSession(t, r, "mock", "/tmp/test-a")
+	b := createSession(t, r, "mock", "/tmp/test-b")
+
+	w := postSessionBatch(t, r, "stop", apiTypes.SessionBatchRequest{
+		SessionIDs: []string{a.ID, "does-not-exist", b.ID, a.ID},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionBatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if resp.Succeeded != 2 || resp.Failed != 1 || len(resp.Results) != 3 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	missing := resp.Results[1]
+	if missing.SessionID != "does-not-exist" || missing.OK || missing.Code != apiTypes.ErrorCodeSessionNotFound {
+		t.Errorf("missing session result: %+v", missing)
+	}
+}
+
+func TestBatchCancelSessions_ByFilter(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	created := createSession(t, r, "mock", "/tmp/test")
+
+	w := postSessionBatch(t, r, "cancel", apiTypes.SessionBatchRequest{
+		Filter: &apiTypes.SessionBatchFilter{Tag: "mock", State: apiTypes.SessionStateIdle},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionBatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].SessionID != created.ID {
+		t.Fatalf("expected only %s to be selected, got %+v", created.ID, resp.Results)
+	}
+
+	w = postSessionBatch(t, r, "cancel", apiTypes.SessionBatchRequest{
+		Filter: &apiTypes.SessionBatchFilter{Tag: "claude"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	resp = apiTypes.SessionBatchResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Results) != 0 {
+		t.Errorf("tag filter matched other providers: %+v", resp.Results)
+	}
+}
+
+func TestBatchSessions_InvalidRequest(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	for name, req := range map[string]apiTypes.SessionBatchRequest{
+		"empty":        {},
+		"empty filter": {Filter: &apiTypes.SessionBatchFilter{}},
+		"both":         {SessionIDs: []string{"x"}, Filter: &apiTypes.SessionBatchFilter{Tag: "mock"}},
+		"bad state":    {Filter: &apiTypes.SessionBatchFilter{State: "exploded"}},
+	} {
+		if w := postSessionBatch(t, r, "stop", req); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestBulkSessions(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	a := createSession(t, r, "mock", "/tmp/test-a")
+	b := createSession(t, r, "mock", "/tmp/test-b")
+
+	post := func(req apiTypes.SessionBatchRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/api/sessions/bulk", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	w := post(apiTypes.SessionBatchRequest{Operation: apiTypes.SessionBulkResume, SessionIDs: []string{a.ID}})
+	var resp apiTypes.SessionBatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if w.Code != http.StatusOK || resp.Failed != 1 || resp.Results[0].Code != apiTypes.ErrorCodeInvalidState {
+		t.Fatalf("resuming an idle session should fail per session: %d %+v", w.Code, resp)
+	}
+
+	w = post(apiTypes.SessionBatchRequest{Operation: apiTypes.SessionBulkDelete, SessionIDs: []string{a.ID, "does-not-exist", b.ID}})
+	resp = apiTypes.SessionBatchResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if w.Code != http.StatusOK || resp.Succeeded != 2 || resp.Failed != 1 {
+		t.Fatalf("unexpected delete response: %d %+v", w.Code, resp)
+	}
+	if got := []string{resp.Results[0].SessionID, resp.Results[1].SessionID, resp.Results[2].SessionID}; got[0] != a.ID || got[1] != "does-not-exist" || got[2] != b.ID {
+		t.Errorf("results out of order: %v", got)
+	}
+	for _, id := range []string{a.ID, b.ID} {
+		get := httptest.NewRequest(http.MethodGet, "/api/sessions/"+id, nil)
+		gw := httptest.NewRecorder()
+		r.ServeHTTP(gw, get)
+		if gw.Code != http.StatusNotFound {
+			t.Errorf("session %s still exists after delete: %d", id, gw.Code)
+		}
+	}
+
+	for name, req := range map[string]apiTypes.SessionBatchRequest{
+		"no operation":      {SessionIDs: []string{"x"}},
+		"unknown operation": {Operation: "explode", SessionIDs: []string{"x"}},
+	} {
+		if w := post(req); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const maxSessionBundleSize = 64 * 1024 * 1024
+
+func (h *Handler) exportSessionBundle(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	profile, err := visibilityProfileFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid profile parameter", err.Error())
+		return
+	}
+
+	bundle, err := h.executor.ExportSessionBundle(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	if profile != nil {
+		bundle.Messages = profile.FilterMessages(bundle.Messages)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+id+".json"))
+	_ = json.NewEncoder(w).Encode(bundle)
+}
+
+// importSessionBundle imports a session bundle, or with format=claude or
+// format=claude-ws, a Claude Code log; see importClaudeLog.
+func (h *Handler) importSessionBundle(w http.ResponseWriter, r *http.Request) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "bundle":
+	case "claude", "claude-ws":
+		h.importClaudeLog(w, r, format)
+		return
+	default:
+		writeError(w, http.StatusBadRequest, "format must be bundle, claude or claude-ws", format)
+		return
+	}
+
+	var bundle service.SessionBundle
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSessionBundleSize)).Decode(&bundle); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	sess, err := h.executor.ImportSessionBundle(r.Context(), generateID(), &bundle)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSessionBundle):
+			writeError(w, http.StatusBadRequest, "invalid session bundle", err.Error())
+		case errors.Is(err, service.ErrSessionExists):
+			writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeSessionExists, "session already exists", err.Error())
+		default:
+			writeError(w, http.StatusInternalServerError, "failed to import session", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(apiTypes.SessionImportResponse{
+		Session:         sessionToResponse(sess.Snapshot()),
+		SourceSessionID: bundle.Session.ID,
+	})
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSessionBundle_ExportImportRoundTrip(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	created := createSession(t, r, "mock", "/tmp/bundle")
+	sess, err := env.executor.GetSession(created.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	sess.AppendMessage(domain.MessageKindUser, "hello")
+	sess.AppendMessage(domain.MessageKindOutput, "world")
+	_ = env.store.Save(sess)
+
+	started := time.Now().Add(-time.Minute).UTC()
+	_ = env.store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:     "attempt1",
+		SessionID:     created.ID,
+		ProviderType:  "mock",
+		StartedAt:     started,
+		HeartbeatAt:   started,
+		ResumeTokenID: "tok",
+	})
+	_ = env.store.SaveTerminal(domain.NewTerminal(created.ID, created.ID, domain.TerminalKindPTY))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/bundle", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var bundle service.SessionBundle
+	if err := json.Unmarshal(w.Body.Bytes(), &bundle); err != nil {
+		t.Fatalf("unmarshal bundle: %v", err)
+	}
+	if len(bundle.Messages) != 2 || len(bundle.Attempts) != 1 || bundle.Terminal == nil {
+		t.Fatalf("unexpected bundle contents: messages=%d attempts=%d terminal=%v", len(bundle.Messages), len(bundle.Attempts), bundle.Terminal)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/sessions/import", bytes.NewReader(w.Body.Bytes()))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("import: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp apiTypes.SessionImportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal import response: %v", err)
+	}
+	if resp.SourceSessionID != created.ID {
+		t.Errorf("SourceSessionID = %q, want %q", resp.SourceSessionID, created.ID)
+	}
+	newID := resp.Session.ID
+	if newID == "" || newID == created.ID {
+		t.Fatalf("expected remapped session ID, got %q", newID)
+	}
+	if resp.Session.WorkingDir != "/tmp/bundle" {
+		t.Errorf("WorkingDir = %q", resp.Session.WorkingDir)
+	}
+
+	messages, err := env.store.GetMessages(newID)
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("expected 2 imported messages, got %d (err=%v)", len(messages), err)
+	}
+
+	attempts, _ := env.store.ListRunAttempts(newID)
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 imported attempt, got %d", len(attempts))
+	}
+	if attempts[0].EndedAt == nil || attempts[0].TerminalReason != "interrupted" || attempts[0].ResumeTokenID != "" {
+		t.Errorf("open attempt should be closed without token: %+v", attempts[0])
+	}
+
+	if _, err := env.store.LoadTerminal(newID); err != nil {
+		t.Errorf("expected imported terminal: %v", err)
+	}
+}
+
+func TestSessionBundle_ImportInvalid(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/import", bytes.NewReader([]byte(`{"version":1}`)))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestExportSessionTranscript(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	created := createSession(t, r, "mock", "/tmp/export")
+	sess, err := env.executor.GetSession(created.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	sess.AppendMessage(domain.MessageKindUser, "hello")
+	sess.AppendMessage(domain.MessageKindOutput, "world")
+	_ = env.store.Save(sess)
+
+	for format, want := range map[string]string{"": "text/markdown", "html": "text/html", "ndjson": "application/x-ndjson"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/export?format="+format, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("export %q: expected 200, got %d: %s", format, w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, want) {
+			t.Errorf("export %q: Content-Type = %q, want %s", format, ct, want)
+		}
+		if body := w.Body.String(); !strings.Contains(body, "hello") || !strings.Contains(body, "world") {
+			t.Errorf("export %q lacks the messages:\n%s", format, body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/export?format=pdf", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("export pdf: expected 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// listSessionCommits lists the commits made on the session's tracked
+// branch, newest first, as GET /api/v1/commits lists the repository's.
+func (h *Handler) listSessionCommits(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.executor.GetSession(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	g := sess.GetGitTracking()
+	if g == nil {
+		writeError(w, http.StatusNotFound, "session does not track a git branch", "")
+		return
+	}
+
+	limit := parseLimit(r.URL.Query().Get("limit"))
+	commits, err := gitLog(sess.WorkingDir, limit, g.BaseRef+".."+g.Branch)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to load commits", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.CommitListResponse{Commits: commits})
+}
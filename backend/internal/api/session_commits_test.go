@@ -0,0 +1,116 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSessionCommits_TracksBranch(t *testing.T) {
+	repoDir, head := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   repoDir,
+		TaskID:       "T-12 fix/bug",
+		Git:          &apiTypes.SessionGit{},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Git == nil || created.Git.Branch != "orbitmesh/task/T-12-fix-bug" || created.Git.BaseRef != head {
+		t.Fatalf("unexpected git tracking: %+v", created.Git)
+	}
+	if got := runGit(t, repoDir, "rev-parse", "--abbrev-ref", "HEAD"); got != created.Git.Branch {
+		t.Fatalf("working directory is on %q, want %q", got, created.Git.Branch)
+	}
+
+	sub := env.broadcaster.Subscribe("commits-test", created.ID)
+	defer env.broadcaster.Unsubscribe("commits-test")
+
+	// Stand in for the agent: commit during a run.
+	sess, err := env.executor.SendMessage(context.Background(), created.ID, "fix it", "", "")
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitForState(t, sess, domain.SessionStateRunning)
+	if err := os.WriteFile(filepath.Join(repoDir, "fix.txt"), []byte("fixed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoDir, "add", "fix.txt")
+	runGit(t, repoDir, "commit", "-m", "fix the bug")
+	sha := runGit(t, repoDir, "rev-parse", "HEAD")
+	if err := env.executor.CancelRun(context.Background(), created.ID); err != nil {
+		t.Fatalf("CancelRun: %v", err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for found := false; !found; {
+		select {
+		case event := <-sub.Events:
+			data, ok := event.Data.(domain.MetadataData)
+			if !ok || data.Key != domain.MetadataKeyGitCommit {
+				continue
+			}
+			value, _ := data.Value.(map[string]any)
+			if value["sha"] != sha || value["message"] != "fix the bug" {
+				t.Fatalf("unexpected git_commit event: %+v", value)
+			}
+			found = true
+		case <-deadline:
+			t.Fatal("timed out waiting for the git_commit event")
+		}
+	}
+	if g := sess.GetGitTracking(); len(g.Commits) != 1 || g.Commits[0].SHA != sha {
+		t.Fatalf("expected the commit to be recorded, got %+v", g)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/commits", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var list apiTypes.CommitListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Commits) != 1 || list.Commits[0].Sha != sha {
+		t.Fatalf("expected only the session's commit, got %+v", list.Commits)
+	}
+}
+
+func TestSessionCommits_Untracked(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	created := createSession(t, r, "mock", t.TempDir())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/commits", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: t.TempDir(), Git: &apiTypes.SessionGit{}})
+	req = httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 outside a git checkout, got %d: %s", w.Code, w.Body.String())
+	}
+}
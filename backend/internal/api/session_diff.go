@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// getSessionDiff returns what changed in the session's working directory
+// since the session was created, for reviewing an agent's changes.
+func (h *Handler) getSessionDiff(w http.ResponseWriter, r *http.Request) {
+	diff, err := h.executor.SessionDiff(r.Context(), chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, service.ErrNoDiffBase):
+		writeError(w, http.StatusNotFound, err.Error(), "")
+		return
+	case errors.Is(err, service.ErrSessionNotFound):
+		writeSessionError(w, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to diff working directory", err.Error())
+		return
+	}
+
+	resp := apiTypes.SessionDiffResponse{
+		BaseRef:    diff.BaseRef,
+		Files:      make([]apiTypes.DiffFileSummary, len(diff.Files)),
+		Insertions: diff.Insertions,
+		Deletions:  diff.Deletions,
+		Diff:       diff.Diff,
+		Truncated:  diff.Truncated,
+	}
+	for i, f := range diff.Files {
+		resp.Files[i] = apiTypes.DiffFileSummary{Path: f.Path, Insertions: f.Insertions, Deletions: f.Deletions, Binary: f.Binary}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
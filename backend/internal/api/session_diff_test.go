@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSessionDiff(t *testing.T) {
+	repoDir, head := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+	created := createSession(t, r, "mock", repoDir)
+
+	// Stand in for the agent: a commit, an edit and a new file.
+	if err := os.WriteFile(filepath.Join(repoDir, "committed.txt"), []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repoDir, "add", "committed.txt")
+	runGit(t, repoDir, "commit", "-m", "agent commit")
+	if err := os.WriteFile(filepath.Join(repoDir, "demo.txt"), []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, "new.txt"), []byte("new\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/diff", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var diff apiTypes.SessionDiffResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &diff)
+	if diff.BaseRef != head {
+		t.Fatalf("expected base %s, got %s", head, diff.BaseRef)
+	}
+	var paths []string
+	for _, f := range diff.Files {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, ",") != "committed.txt,demo.txt,new.txt" || diff.Insertions != 4 || diff.Deletions != 0 {
+		t.Fatalf("unexpected summary: %+v", diff)
+	}
+	if !strings.Contains(diff.Diff, "+three") || !strings.Contains(diff.Diff, "+new") || diff.Truncated {
+		t.Fatalf("unexpected diff:\n%s", diff.Diff)
+	}
+	if staged := runGit(t, repoDir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Fatalf("expected the repository's index to be left alone, got %q staged", staged)
+	}
+}
+
+func TestSessionDiff_NotGitCheckout(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	created := createSession(t, r, "mock", t.TempDir())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+created.ID+"/diff", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// dryRunSession resolves a SessionRequest as createSession would and
+// reports the config the session's first run would start with, without
+// creating anything. Requests createSession would reject fail the same way;
+// problems only the executor finds, such as an unknown provider type or a
+// spent cost budget, are listed in the response instead.
+func (h *Handler) dryRunSession(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.SessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.MissionID != "" {
+		if _, err := h.executor.Mission(req.MissionID); err != nil {
+			writeMissionError(w, err)
+			return
+		}
+	}
+	config, reqErr := h.resolveSessionRequest(req)
+	if reqErr != nil {
+		reqErr.write(w)
+		return
+	}
+
+	dryRun := h.executor.DryRunSession(config, req.MissionID)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessionDryRunToResponse(config, dryRun))
+}
+
+func sessionDryRunToResponse(config session.Config, dryRun service.SessionDryRun) apiTypes.SessionDryRunResponse {
+	run := dryRun.Config
+	resp := apiTypes.SessionDryRunResponse{
+		Valid:           len(dryRun.Problems) == 0,
+		ProviderType:    run.ProviderType,
+		AgentID:         config.AgentID,
+		ProjectID:       run.ProjectID,
+		WorkingDir:      run.WorkingDir,
+		SessionKind:     run.SessionKind,
+		SystemPrompt:    run.SystemPrompt,
+		Environment:     run.Environment,
+		Custom:          run.Custom,
+		Features:        run.Features,
+		RecoveryPolicy:  config.RecoveryPolicy,
+		PlanApproval:    config.PlanApproval,
+		CostBudget:      presentation.CostBudget(config.CostBudget),
+		CleanupCommands: config.CleanupCommands,
+		Problems:        dryRun.Problems,
+		Warnings:        dryRun.Warnings,
+	}
+	for _, s := range run.MCPServers {
+		resp.MCPServers = append(resp.MCPServers, apiTypes.MCPServerConfig{
+			Name:    s.Name,
+			Command: s.Command,
+			Args:    s.Args,
+			Env:     s.Env,
+		})
+	}
+	if dryRun.Capabilities != nil {
+		resp.Capabilities = capabilitiesToAPI(*dryRun.Capabilities)
+	}
+	return resp
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func postDryRun(t *testing.T, r http.Handler, req apiTypes.SessionRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/sessions/dry-run", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httpReq)
+	return w
+}
+
+func TestDryRunSession_ResolvesAgentWithoutCreating(t *testing.T) {
+	env, agentStorage := newTestEnvWithAgents(t)
+	r := env.router()
+	_ = agentStorage.Save(storage.AgentConfig{
+		ID:           "agent_abc",
+		Name:         "Agent",
+		SystemPrompt: "agent prompt",
+		Custom:       map[string]any{"model": "agent-model", "api_key": "hunter2"},
+		MCPServers: []session.MCPServerConfig{{
+			Name:    "exchange",
+			Command: "exchange-mcp",
+			Env:     map[string]string{"SESSION": "{{.SessionID}}", "ORBITMESH_API_TOKEN": "{{.APIToken}}"},
+		}},
+	})
+
+	w := postDryRun(t, r, apiTypes.SessionRequest{
+		ProviderType: "mock",
+		AgentID:      "agent_abc",
+		WorkingDir:   t.TempDir(),
+		Custom:       map[string]any{"model": "request-model"},
+		Environment:  map[string]string{"FOO": "bar"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionDryRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Valid || len(resp.Problems) != 0 {
+		t.Fatalf("valid = %v, problems = %v", resp.Valid, resp.Problems)
+	}
+	if resp.SystemPrompt != "agent prompt" {
+		t.Errorf("system_prompt = %q", resp.SystemPrompt)
+	}
+	if resp.Custom["model"] != "request-model" {
+		t.Errorf("model = %v, want the request's", resp.Custom["model"])
+	}
+	if resp.Custom["api_key"] != "[redacted]" {
+		t.Errorf("api_key = %v, want redacted", resp.Custom["api_key"])
+	}
+	if len(resp.MCPServers) != 1 {
+		t.Fatalf("mcp_servers = %+v", resp.MCPServers)
+	}
+	if got := resp.MCPServers[0].Env["SESSION"]; got != "dry-run" {
+		t.Errorf("rendered SESSION = %q", got)
+	}
+	if got := resp.MCPServers[0].Env["ORBITMESH_API_TOKEN"]; got != "[redacted]" {
+		t.Errorf("ORBITMESH_API_TOKEN = %q, want redacted", got)
+	}
+	if resp.Capabilities == nil {
+		t.Error("capabilities missing")
+	}
+	if !slices.ContainsFunc(resp.Warnings, func(w string) bool { return strings.Contains(w, "FOO") }) {
+		t.Errorf("warnings = %v, want the dropped FOO variable", resp.Warnings)
+	}
+	if sessions := env.executor.ListSessions(); len(sessions) != 0 {
+		t.Fatalf("dry run created %d sessions", len(sessions))
+	}
+}
+
+func TestDryRunSession_UnknownProviderIsAProblem(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	w := postDryRun(t, r, apiTypes.SessionRequest{ProviderType: "nope", WorkingDir: t.TempDir()})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionDryRunResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Valid || len(resp.Problems) == 0 || resp.Capabilities != nil {
+		t.Fatalf("valid = %v, problems = %v, capabilities = %v", resp.Valid, resp.Problems, resp.Capabilities)
+	}
+}
+
+func TestDryRunSession_RejectsWhatCreateRejects(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	w := postDryRun(t, r, apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: t.TempDir(), SessionKind: "bogus"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/transcript"
+)
+
+// exportSessionTranscript renders the session's full message history as
+// Markdown, HTML or NDJSON (?format=, Markdown by default), honouring a
+// visibility ?profile=.
+func (h *Handler) exportSessionTranscript(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	format, err := transcript.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid format parameter", err.Error())
+		return
+	}
+	profile, err := visibilityProfileFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid profile parameter", err.Error())
+		return
+	}
+
+	bundle, err := h.executor.ExportSessionBundle(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	messages := bundle.Messages
+	if profile != nil {
+		messages = profile.FilterMessages(messages)
+	}
+	snap := bundle.Session
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", "session-"+id+"."+format.Extension()))
+	err = transcript.Render(w, transcript.Transcript{
+		SessionID:    snap.ID,
+		Title:        snap.Title,
+		ProviderType: snap.ProviderType,
+		WorkingDir:   snap.WorkingDir,
+		CreatedAt:    snap.CreatedAt,
+		Usage:        snap.Usage,
+		Messages:     messages,
+	}, format)
+	if err != nil {
+		log.Printf("session %s: exporting transcript: %v", id, err)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/provider/common/claude"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// maxImportedTitleLength caps the title taken from an imported log's first
+// prompt.
+const maxImportedTitleLength = 80
+
+// importClaudeLog creates a read-only session from a Claude Code NDJSON
+// log, such as the stream-json output of a claude run, a claude-ws capture
+// or a Claude Code transcript. providerType is the provider the log came
+// from. The title query parameter names the session; it defaults to the
+// log's first prompt.
+func (h *Handler) importClaudeLog(w http.ResponseWriter, r *http.Request, providerType string) {
+	id := generateID()
+	parsed, err := claude.ReadLog(id, http.MaxBytesReader(w, r.Body, maxSessionBundleSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid Claude Code log", err.Error())
+		return
+	}
+
+	imported := service.ImportedLog{
+		ProviderType: providerType,
+		WorkingDir:   parsed.WorkingDir,
+		Title:        strings.TrimSpace(r.URL.Query().Get("title")),
+		Metadata:     map[string]string{},
+	}
+	if parsed.ConversationID != "" {
+		imported.Metadata["claude_session_id"] = parsed.ConversationID
+	}
+	if parsed.Model != "" {
+		imported.Metadata["model"] = parsed.Model
+	}
+	for _, entry := range parsed.Entries {
+		imported.Entries = append(imported.Entries, service.ImportedEntry{Prompt: entry.Prompt, Event: entry.Event, At: entry.At})
+		if imported.Title == "" && entry.Prompt != "" {
+			imported.Title = importedTitle(entry.Prompt)
+		}
+	}
+
+	sess, err := h.executor.ImportLog(id, imported)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSessionExists):
+			writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeSessionExists, "session already exists", err.Error())
+		case errors.Is(err, service.ErrReadOnlyMirror):
+			writeSessionError(w, err)
+		default:
+			writeErrorCode(w, http.StatusInternalServerError, serviceErrorCode(err), "failed to import session", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(apiTypes.SessionImportResponse{
+		Session:         sessionToResponse(sess.Snapshot()),
+		SourceSessionID: parsed.ConversationID,
+	})
+}
+
+// importedTitle is the first line of prompt, shortened to
+// maxImportedTitleLength runes.
+func importedTitle(prompt string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(prompt), "\n")
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) > maxImportedTitleLength {
+		return string(runes[:maxImportedTitleLength-1]) + "…"
+	}
+	return string(runes)
+}
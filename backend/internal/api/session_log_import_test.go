@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+const claudeStreamLog = `{"type":"system","subtype":"init","cwd":"/work","session_id":"claude-abc","model":"claude-opus","tools":[]}
+{"type":"user","message":{"role":"user","content":"Fix the failing test\nin main_test.go"}}
+{"type":"assistant","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"Fixed it."}],"usage":{"input_tokens":12,"output_tokens":3}}}
+{"type":"result","subtype":"success","total_cost_usd":0.01}
+`
+
+func TestImportClaudeLog(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/import?format=claude", strings.NewReader(claudeStreamLog))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("import: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionImportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.SourceSessionID != "claude-abc" {
+		t.Errorf("source_session_id = %q", resp.SourceSessionID)
+	}
+	got := resp.Session
+	if got.SessionKind != domain.SessionKindImported || got.ProviderType != "claude" || got.WorkingDir != "/work" {
+		t.Errorf("session = kind %q, provider %q, working dir %q", got.SessionKind, got.ProviderType, got.WorkingDir)
+	}
+	if got.Title != "Fix the failing test" {
+		t.Errorf("title = %q, want the first prompt's first line", got.Title)
+	}
+
+	messages, err := env.store.GetMessages(got.ID)
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	var user, output bool
+	for _, m := range messages {
+		user = user || m.Kind == domain.MessageKindUser && strings.HasPrefix(m.Contents, "Fix the failing test")
+		output = output || m.Kind == domain.MessageKindOutput && m.Contents == "Fixed it."
+	}
+	if !user || !output {
+		t.Fatalf("messages = %+v, want the prompt and the reply", messages)
+	}
+	sess, _ := env.executor.GetSession(got.ID)
+	if usage := sess.GetUsage(); usage.InputTokens != 12 || usage.CostUSD != 0.01 {
+		t.Errorf("usage = %+v", usage)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/sessions/"+got.ID+"/messages", strings.NewReader(`{"content":"more"}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), string(apiTypes.ErrorCodeReadOnlySession)) {
+		t.Fatalf("message to imported session: got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestImportClaudeLog_Invalid(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	for _, target := range []string{"/api/sessions/import?format=claude", "/api/sessions/import?format=nope"} {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader("not a log\n"))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", target, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestSessionMetadata_SetPatchAndFilter(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	create := func(metadata map[string]string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: t.TempDir(), Metadata: metadata})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body)))
+		return w
+	}
+	if w := create(map[string]string{"bad key": "x"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected an invalid key to be rejected, got %d", w.Code)
+	}
+	w := create(map[string]string{"ticket": "ENG-12", "ci.run": "881"})
+	var tagged apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &tagged)
+	if w.Code != http.StatusCreated || tagged.Metadata["ticket"] != "ENG-12" {
+		t.Fatalf("expected the metadata in the response, got %d: %s", w.Code, w.Body.String())
+	}
+	other := createSession(t, r, "mock", t.TempDir())
+
+	patch := func(id, body string) (int, apiTypes.SessionResponse) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/api/sessions/"+id, bytes.NewBufferString(body)))
+		var resp apiTypes.SessionResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	if code, resp := patch(tagged.ID, `{"metadata":{"ci.run":null,"owner":"dana"}}`); code != http.StatusOK ||
+		resp.Metadata["ci.run"] != "" || resp.Metadata["owner"] != "dana" || resp.Metadata["ticket"] != "ENG-12" {
+		t.Fatalf("expected null to remove a key and others to be kept, got %d %v", code, resp.Metadata)
+	}
+	if code, _ := patch(other.ID, `{"metadata":{"ticket":""}}`); code != http.StatusBadRequest {
+		t.Fatalf("expected an empty value to be rejected, got %d", code)
+	}
+	if code, _ := patch(other.ID, `{"metadata":{"ticket":"ENG-99"}}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/sessions?metadata.ticket=ENG-12", nil))
+	var list apiTypes.SessionListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Sessions) != 1 || list.Sessions[0].ID != tagged.ID {
+		t.Fatalf("expected only the tagged session, got %+v", list.Sessions)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/sessions?metadata.ticket=ENG-12&metadata.owner=sam", nil))
+	list = apiTypes.SessionListResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Sessions) != 0 {
+		t.Fatalf("expected every filter entry to have to match, got %+v", list.Sessions)
+	}
+}
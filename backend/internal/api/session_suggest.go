@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// suggestSessions serves command-palette typeahead: a prefix match on session
+// ID, title, current task and tags, answered from memory.
+func (h *Handler) suggestSessions(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	suggestions := h.executor.SuggestSessions(r.URL.Query().Get("q"), limit)
+	resp := apiTypes.SessionSuggestResponse{Suggestions: make([]apiTypes.SessionSuggestion, 0, len(suggestions))}
+	for _, s := range suggestions {
+		resp.Suggestions = append(resp.Suggestions, apiTypes.SessionSuggestion{
+			ID:           s.ID,
+			Title:        s.Title,
+			CurrentTask:  s.CurrentTask,
+			SessionKind:  s.Kind,
+			Pinned:       s.Pinned,
+			UpdatedAt:    s.UpdatedAt,
+			MatchedField: s.Field,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ricochet1k/orbitmesh/internal/realtime"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
+)
+
+// syncSessions returns the sessions changed since the client's last
+// revision, ?since=N; without one it returns the full list with reset set.
+func (h *Handler) syncSessions(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid since", "since must be a revision returned by an earlier sync")
+			return
+		}
+		since = n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(h.sessionSync(since, requestUser(r)))
+}
+
+// sessionSync builds the delta after revision since. An empty user leaves
+// out read state, as on the realtime topic shared by every client.
+func (h *Handler) sessionSync(since int64, user string) apiTypes.SessionSyncResponse {
+	changes := h.executor.SessionChangesSince(since)
+	resp := apiTypes.SessionSyncResponse{
+		Revision: changes.Revision,
+		Since:    since,
+		Reset:    changes.Reset,
+		Sessions: make([]apiTypes.SessionResponse, 0, len(changes.Changed)),
+		Deleted:  changes.Deleted,
+	}
+	for _, id := range changes.Changed {
+		sess, err := h.executor.GetSession(id)
+		if err != nil {
+			// Deleted after the change log was read.
+			if !changes.Reset {
+				resp.Deleted = append(resp.Deleted, id)
+			}
+			continue
+		}
+		snap := sess.Snapshot()
+		if derived, err := h.executor.DeriveSessionState(id); err == nil {
+			snap.State = derived
+		}
+		if user == "" {
+			resp.Sessions = append(resp.Sessions, sessionToResponse(snap))
+		} else {
+			resp.Sessions = append(resp.Sessions, h.sessionResponseFor(snap, user))
+		}
+	}
+	return resp
+}
+
+// publishSessionSync publishes a delta on the sessions.sync topic each time
+// sessions change, until the executor shuts down.
+func (h *Handler) publishSessionSync() {
+	wake, unsubscribe := h.executor.SubscribeSessionChanges()
+	go func() {
+		defer unsubscribe()
+		last := h.executor.SessionRevision()
+		for range wake {
+			delta := h.sessionSync(last, "")
+			if delta.Revision == last {
+				continue
+			}
+			last = delta.Revision
+			h.realtimeHub.Publish(realtime.TopicSessionsSync, realtimeTypes.ServerEnvelope{
+				Type:    realtimeTypes.ServerMessageTypeEvent,
+				Topic:   realtime.TopicSessionsSync,
+				Payload: delta,
+			})
+		}
+	}()
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) getSessionTimeline(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	tl, err := h.executor.SessionTimeline(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(apiTypes.SessionTimelineResponse{
+		SessionID:   tl.SessionID,
+		Start:       tl.Start,
+		End:         tl.End,
+		Runs:        timelineSpansToAPI(tl.Runs),
+		Suspensions: timelineSpansToAPI(tl.Suspensions),
+		ToolCalls:   timelineSpansToAPI(tl.ToolCalls),
+		HumanWaits:  timelineSpansToAPI(tl.HumanWaits),
+		Takeovers:   timelineSpansToAPI(tl.Takeovers),
+	})
+}
+
+func timelineSpansToAPI(spans []service.TimelineSpan) []apiTypes.TimelineSpan {
+	out := make([]apiTypes.TimelineSpan, len(spans))
+	for i, s := range spans {
+		out[i] = apiTypes.TimelineSpan{
+			ID:         s.ID,
+			Label:      s.Label,
+			Detail:     s.Detail,
+			Start:      s.Start,
+			End:        s.End,
+			DurationMS: s.Duration.Milliseconds(),
+			Lane:       s.Lane,
+		}
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestCreateSession_Workspace(t *testing.T) {
+	repoDir, head := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+
+	create := func(ws apiTypes.SessionWorkspace) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(apiTypes.SessionRequest{ProviderType: "mock", WorkingDir: repoDir, Workspace: &ws})
+		req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := create(apiTypes.SessionWorkspace{Mode: "copy"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown mode, got %d", w.Code)
+	}
+	if w := create(apiTypes.SessionWorkspace{Mode: "worktree", BaseRef: "no-such-ref"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown base_ref, got %d: %s", w.Code, w.Body.String())
+	}
+	for _, repo := range []string{"ext::sh -c touch% /tmp/pwned", "file://" + repoDir, "--upload-pack=touch /tmp/pwned", "relative/repo"} {
+		if w := create(apiTypes.SessionWorkspace{Mode: "clone", Repo: repo}); w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for repo %q, got %d: %s", repo, w.Code, w.Body.String())
+		}
+	}
+	if w := create(apiTypes.SessionWorkspace{Mode: "worktree", BaseRef: "--orphan"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a base_ref like an option, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Two sessions in one repository each get a checkout of their own.
+	var sessions []apiTypes.SessionResponse
+	for _, mode := range []string{"worktree", "clone"} {
+		w := create(apiTypes.SessionWorkspace{Mode: mode})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("%s: expected 201, got %d: %s", mode, w.Code, w.Body.String())
+		}
+		var resp apiTypes.SessionResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		ws := resp.Workspace
+		if ws == nil || ws.Path != resp.WorkingDir || ws.Repo != repoDir || ws.BaseRef != head {
+			t.Fatalf("%s: unexpected workspace %+v for working_dir %s", mode, ws, resp.WorkingDir)
+		}
+		if ws.Branch != "orbitmesh/session/"+resp.ID {
+			t.Fatalf("%s: unexpected branch %q", mode, ws.Branch)
+		}
+		if got := runGit(t, ws.Path, "rev-parse", "--abbrev-ref", "HEAD"); got != ws.Branch {
+			t.Fatalf("%s: checkout is on %q, want %q", mode, got, ws.Branch)
+		}
+		sessions = append(sessions, resp)
+	}
+
+	// The worktree's branch has no commits of its own, so it goes with the
+	// session.
+	worktree := sessions[0].Workspace
+	if err := env.executor.DeleteSession(context.Background(), sessions[0].ID, ""); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := os.Stat(worktree.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected the worktree to be removed, stat err = %v", err)
+	}
+	if branches := runGit(t, repoDir, "branch", "--list", worktree.Branch); branches != "" {
+		t.Fatalf("expected branch %s to be deleted, got %q", worktree.Branch, branches)
+	}
+
+	clone := sessions[1].Workspace
+	if err := os.WriteFile(filepath.Join(clone.Path, "scratch.txt"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := env.executor.DeleteSession(context.Background(), sessions[1].ID, ""); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := os.Stat(clone.Path); !os.IsNotExist(err) {
+		t.Fatalf("expected the clone to be removed, stat err = %v", err)
+	}
+	if status := runGit(t, repoDir, "status", "--porcelain"); strings.TrimSpace(status) != "" {
+		t.Fatalf("expected the repository to be untouched, got %q", status)
+	}
+}
+
+func TestCreateSession_WorkspaceKeepsCommittedBranch(t *testing.T) {
+	repoDir, _ := setupGitRepo(t)
+	env := newTestEnv(t)
+	r := env.router()
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   repoDir,
+		Workspace:    &apiTypes.SessionWorkspace{Mode: "worktree", Branch: "feature/agent"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+
+	if err := os.WriteFile(filepath.Join(resp.WorkingDir, "work.txt"), []byte("done\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, resp.WorkingDir, "add", "work.txt")
+	runGit(t, resp.WorkingDir, "commit", "-m", "agent work")
+
+	if err := env.executor.DeleteSession(context.Background(), resp.ID, ""); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := os.Stat(resp.WorkingDir); !os.IsNotExist(err) {
+		t.Fatalf("expected the worktree to be removed, stat err = %v", err)
+	}
+	if branches := runGit(t, repoDir, "branch", "--list", "feature/agent"); branches == "" {
+		t.Fatal("expected the branch with the session's commit to be kept")
+	}
+}
@@ -11,39 +11,106 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
 	"github.com/ricochet1k/orbitmesh/internal/service"
 	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
 )
 
-const sseHeartbeatInterval = 15 * time.Second
+const (
+	// DefaultSSEHeartbeatInterval is how often idle SSE streams send a
+	// heartbeat event, keeping proxies from closing them.
+	DefaultSSEHeartbeatInterval = 15 * time.Second
+	// DefaultSSERetry is the reconnection delay SSE streams ask clients to
+	// use with a retry: directive.
+	DefaultSSERetry = 3 * time.Second
+)
+
+// SetSSETiming sets the SSE heartbeat interval and the reconnection delay
+// sent to clients. Zero keeps the default.
+func (h *Handler) SetSSETiming(heartbeat, retry time.Duration) {
+	h.sseHeartbeat = heartbeat
+	h.sseRetry = retry
+}
 
-// sseEvents streams domain events for a session as Server-Sent Events.
+func (h *Handler) sseHeartbeatInterval() time.Duration {
+	if h.sseHeartbeat <= 0 {
+		return DefaultSSEHeartbeatInterval
+	}
+	return h.sseHeartbeat
+}
+
+// startSSE writes the headers of an SSE stream, followed by a retry:
+// directive telling the client how long to wait before reconnecting.
+func (h *Handler) startSSE(w http.ResponseWriter, flusher http.Flusher) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stops nginx from buffering the stream, which would hold back events
+	// and heartbeats alike.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	retry := h.sseRetry
+	if retry <= 0 {
+		retry = DefaultSSERetry
+	}
+	_, err := fmt.Fprintf(w, "retry: %d\n\n", retry.Milliseconds())
+	flusher.Flush()
+	return err
+}
+
+// sseEvents streams domain events for a session as Server-Sent Events. The
+// optional "types" query parameter limits the stream to those event types.
 // The subscription is registered before headers are flushed so that no
 // events are lost between the client seeing the 200 and the first broadcast.
+//
+// With the event journal enabled, each event's SSE id is its journal
+// offset, and a stream resumes after the offset in Last-Event-ID,
+// last_event_id or since_seq (since_seq=0 replays from the start),
+// replaying from the journal however far back that is. Without it, ids are
+// broadcast IDs and only the in-memory replay window is replayed.
 func (h *Handler) sseEvents(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 
 	if _, err := h.executor.GetSession(sessionID); err != nil {
 		if errors.Is(err, service.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to look up session", err.Error())
 		return
 	}
 
+	filter, err := eventTypeFilterFromRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid event type filter", err.Error())
+		return
+	}
+	sinceSeq, resume, err := parseSinceSeq(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid since_seq", err.Error())
+		return
+	}
+
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		writeError(w, http.StatusInternalServerError, "streaming not supported", "")
 		return
 	}
 
+	journaled := h.executor.EventLogEnabled()
 	lastEventID := parseLastEventID(r)
 
 	// Subscribe before writing headers — guarantees the subscription is
 	// active by the time the client receives the 200 response.
 	subID := generateID()
-	sub, replay := h.broadcaster.SubscribeWithReplay(subID, sessionID, lastEventID)
+	var sub *service.Subscriber
+	var replay []domain.Event
+	if journaled {
+		sub = h.broadcaster.Subscribe(subID, sessionID)
+	} else {
+		sub, replay = h.broadcaster.SubscribeWithReplay(subID, sessionID, lastEventID)
+	}
 	defer h.broadcaster.Unsubscribe(subID)
 
 	// Only replay events if Last-Event-ID was explicitly provided (lastEventID > 0).
@@ -52,21 +119,39 @@ func (h *Handler) sseEvents(w http.ResponseWriter, r *http.Request) {
 		replay = nil
 	}
 
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.WriteHeader(http.StatusOK)
-	flusher.Flush()
+	if err := h.startSSE(w, flusher); err != nil {
+		return
+	}
+
+	sseID := func(event domain.Event) int64 { return event.ID }
+	// sent is the journal offset of the last event sent once the stream
+	// has one; live events up to it were already replayed.
+	var sent int64
+	if journaled {
+		sseID = func(event domain.Event) int64 { return event.Seq }
+		if !resume && lastEventID > 0 {
+			sinceSeq, resume = lastEventID, true
+		}
+		if resume {
+			if sent, err = h.replayEventJournal(w, flusher, sessionID, sinceSeq, filter); err != nil {
+				return
+			}
+		}
+	}
+	tracking := journaled && resume
 
 	for _, event := range replay {
-		if err := writeSSEEvent(w, event); err != nil {
+		if !filter.allows(event) {
+			continue
+		}
+		if err := writeSSEEvent(w, event, sseID(event)); err != nil {
 			return
 		}
 		flusher.Flush()
 	}
 
 	ctx := r.Context()
-	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	heartbeat := time.NewTicker(h.sseHeartbeatInterval())
 	defer heartbeat.Stop()
 	for {
 		select {
@@ -76,7 +161,26 @@ func (h *Handler) sseEvents(w http.ResponseWriter, r *http.Request) {
 			if !ok {
 				return
 			}
-			if err := writeSSEEvent(w, event); err != nil {
+			if event.Seq != 0 && tracking {
+				if event.Seq <= sent {
+					continue
+				}
+				// Events the subscription dropped while the stream fell
+				// behind are read back from the journal.
+				if event.Seq > sent+1 {
+					if sent, err = h.replayEventJournal(w, flusher, sessionID, sent, filter); err != nil {
+						return
+					}
+					continue
+				}
+			}
+			if event.Seq != 0 && journaled {
+				sent, tracking = event.Seq, true
+			}
+			if !filter.allows(event) {
+				continue
+			}
+			if err := writeSSEEvent(w, event, sseID(event)); err != nil {
 				return
 			}
 			flusher.Flush()
@@ -89,6 +193,30 @@ func (h *Handler) sseEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// replayEventJournal writes the session's journaled events after sinceSeq
+// and returns the offset of the last one read.
+func (h *Handler) replayEventJournal(w http.ResponseWriter, flusher http.Flusher, sessionID string, sinceSeq int64, filter eventTypeFilter) (int64, error) {
+	for {
+		events, more, err := h.executor.EventHistory(sessionID, sinceSeq, maxEventHistoryLimit)
+		if err != nil {
+			return sinceSeq, err
+		}
+		for _, stored := range events {
+			sinceSeq = stored.Seq
+			if !filter.allows(stored.Event) {
+				continue
+			}
+			if err := writeSSEEvent(w, stored.Event, stored.Seq); err != nil {
+				return sinceSeq, err
+			}
+		}
+		flusher.Flush()
+		if !more {
+			return sinceSeq, nil
+		}
+	}
+}
+
 // sseSessionEvents streams global session-state change events across all sessions.
 func (h *Handler) sseSessionEvents(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
@@ -107,11 +235,9 @@ func (h *Handler) sseSessionEvents(w http.ResponseWriter, r *http.Request) {
 		replay = nil
 	}
 
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.WriteHeader(http.StatusOK)
-	flusher.Flush()
+	if err := h.startSSE(w, flusher); err != nil {
+		return
+	}
 
 	for _, event := range replay {
 		if event.Type != domain.EventTypeStatusChange {
@@ -125,7 +251,7 @@ func (h *Handler) sseSessionEvents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	heartbeat := time.NewTicker(h.sseHeartbeatInterval())
 	defer heartbeat.Stop()
 	for {
 		select {
@@ -152,19 +278,21 @@ func (h *Handler) sseSessionEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// writeSSEEvent serialises a single domain event in the SSE wire format:
+// writeSSEEvent serialises a single domain event in the SSE wire format,
+// with an id line when id is positive:
 //
+//	id: <id>\n
 //	event: <type>\n
 //	data: <json>\n
 //	\n
-func writeSSEEvent(w http.ResponseWriter, event domain.Event) error {
+func writeSSEEvent(w http.ResponseWriter, event domain.Event, id int64) error {
 	apiEvent := domainEventToAPIEvent(event)
 	data, err := json.Marshal(apiEvent)
 	if err != nil {
 		return err
 	}
-	if event.ID > 0 {
-		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, apiEvent.Type, data)
+	if id > 0 {
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, apiEvent.Type, data)
 		return err
 	}
 	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", apiEvent.Type, data)
@@ -207,6 +335,7 @@ func (h *Handler) toSessionStateEvent(event domain.Event) apiTypes.SessionStateE
 
 	if data, ok := event.Data.(domain.StatusChangeData); ok {
 		stateEvent.Reason = data.Reason
+		stateEvent.Notice = presentation.Notice(data.Notice)
 	}
 
 	return stateEvent
@@ -215,6 +344,7 @@ func (h *Handler) toSessionStateEvent(event domain.Event) apiTypes.SessionStateE
 func domainEventToAPIEvent(e domain.Event) apiTypes.Event {
 	return apiTypes.Event{
 		EventID:   e.ID,
+		Seq:       e.Seq,
 		Type:      apiTypes.EventType(e.Type.String()),
 		Timestamp: e.Timestamp,
 		SessionID: e.SessionID,
@@ -229,24 +359,37 @@ func convertEventData(e domain.Event) any {
 			OldState: d.OldState.String(),
 			NewState: d.NewState.String(),
 			Reason:   d.Reason,
+			Notice:   presentation.Notice(d.Notice),
 		}
 	case domain.OutputData:
 		return apiTypes.OutputData{Content: d.Content, IsDelta: d.IsDelta}
 	case domain.MetricData:
-		return apiTypes.MetricData{TokensIn: d.TokensIn, TokensOut: d.TokensOut, RequestCount: d.RequestCount}
+		return apiTypes.MetricData{
+			TokensIn:            d.TokensIn,
+			TokensOut:           d.TokensOut,
+			RequestCount:        d.RequestCount,
+			CacheReadTokens:     d.CacheReadTokens,
+			CacheCreationTokens: d.CacheCreationTokens,
+			CostUSD:             d.CostUSD,
+		}
 	case domain.ErrorData:
 		return apiTypes.ErrorData{Message: d.Message, Code: d.Code}
 	case domain.MetadataData:
 		return apiTypes.MetadataData{Key: d.Key, Value: d.Value}
 	case domain.ToolCallData:
-		return apiTypes.ToolCallData{
-			ID:     d.ID,
-			Name:   d.Name,
-			Status: d.Status,
-			Title:  d.Title,
-			Input:  d.Input,
-			Output: d.Output,
+		data := apiTypes.ToolCallData{
+			ID:       d.ID,
+			Name:     d.Name,
+			Status:   d.Status,
+			Title:    d.Title,
+			Input:    d.Input,
+			Output:   d.Output,
+			ParentID: d.ParentID,
+		}
+		if d.Usage != nil {
+			data.Usage = &apiTypes.TokenUsage{InputTokens: d.Usage.InputTokens, OutputTokens: d.Usage.OutputTokens}
 		}
+		return data
 	case domain.ThoughtData:
 		return apiTypes.ThoughtData{Content: d.Content}
 	case domain.PlanData:
@@ -255,11 +398,29 @@ func convertEventData(e domain.Event) any {
 			steps[i] = apiTypes.PlanStep{ID: s.ID, Description: s.Description, Status: s.Status}
 		}
 		return apiTypes.PlanData{Description: d.Description, Steps: steps}
+	case domain.ProgressData:
+		return apiTypes.ProgressData{Percent: d.Percent, Phase: d.Phase}
+	case domain.LivenessData:
+		return apiTypes.LivenessData{LastActivityAt: d.LastActivityAt, TokensLastMinute: d.TokensLastMinute}
 	default:
 		return d
 	}
 }
 
+// parseSinceSeq reads the since_seq query parameter, the journal offset a
+// session event stream resumes after, and whether it was given.
+func parseSinceSeq(r *http.Request) (int64, bool, error) {
+	raw := r.URL.Query().Get("since_seq")
+	if raw == "" {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false, errors.New("since_seq must be a non-negative integer")
+	}
+	return n, true, nil
+}
+
 func parseLastEventID(r *http.Request) int64 {
 	if header := r.Header.Get("Last-Event-ID"); header != "" {
 		if id, err := strconv.ParseInt(header, 10, 64); err == nil {
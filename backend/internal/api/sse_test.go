@@ -4,8 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -147,6 +149,36 @@ func TestSSE_Headers(t *testing.T) {
 	}
 }
 
+func TestSSE_RetryAndHeartbeatTiming(t *testing.T) {
+	env := newTestEnv(t)
+	env.handler.SetSSETiming(20*time.Millisecond, 1500*time.Millisecond)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(srv.URL + "/api/sessions/" + sessionID + "/events")
+	if err != nil {
+		t.Fatalf("SSE request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if xab := resp.Header.Get("X-Accel-Buffering"); xab != "no" {
+		t.Errorf("X-Accel-Buffering = %q, want no", xab)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	if !scanner.Scan() || scanner.Text() != "retry: 1500" {
+		t.Fatalf("first line = %q, want retry: 1500", scanner.Text())
+	}
+	for scanner.Scan() {
+		if scanner.Text() == "event: heartbeat" {
+			return
+		}
+	}
+	t.Fatalf("stream ended without a heartbeat: %v", scanner.Err())
+}
+
 // ---------------------------------------------------------------------------
 // single event delivery
 // ---------------------------------------------------------------------------
@@ -223,6 +255,55 @@ func TestSSE_MultipleEvents(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// event type filter
+// ---------------------------------------------------------------------------
+
+func TestSSE_EventTypeFilter(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+
+	resp, err := http.Get(srv.URL + "/api/sessions/" + sessionID + "/events?types=tool_call,error")
+	if err != nil {
+		t.Fatalf("SSE request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	events := readSSEEvents(resp)
+
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "skipped", nil))
+	env.broadcaster.Broadcast(domain.NewErrorEvent(sessionID, "oops", "ERR_TEST", nil))
+
+	select {
+	case ev := <-events:
+		if ev.Type != apiTypes.EventTypeError {
+			t.Errorf("Type = %q, want error", ev.Type)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for SSE event")
+	}
+}
+
+func TestSSE_EventTypeFilterRejectsUnknownType(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+
+	resp, err := http.Get(srv.URL + "/api/sessions/" + sessionID + "/events?types=bogus")
+	if err != nil {
+		t.Fatalf("SSE request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", resp.StatusCode)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // status-change event
 // ---------------------------------------------------------------------------
@@ -455,6 +536,56 @@ func TestSSE_ReplayWithLastEventID(t *testing.T) {
 	}
 }
 
+func TestSSE_ResumesFromJournalOffset(t *testing.T) {
+	env := newTestEnv(t)
+	srv := httptest.NewServer(env.router())
+	defer srv.Close()
+
+	sessionID := createSessionViaHTTP(t, srv.URL)
+	// More events than the in-memory replay window holds.
+	for i := range 150 {
+		env.broadcaster.Broadcast(domain.NewDeltaOutputEvent(sessionID, fmt.Sprintf("chunk %d", i), nil))
+	}
+	history, _, err := env.executor.EventHistory(sessionID, 0, 0)
+	if err != nil || len(history) < 150 {
+		t.Fatalf("history = %d events, %v", len(history), err)
+	}
+	first := history[len(history)-150].Seq
+
+	resp, err := http.Get(srv.URL + "/api/sessions/" + sessionID + "/events?since_seq=" + strconv.FormatInt(first, 10))
+	if err != nil {
+		t.Fatalf("SSE request: %v", err)
+	}
+	defer resp.Body.Close()
+	frames := readSSEMessages(resp)
+
+	want := first + 1
+	for i := 1; i < 150; i++ {
+		select {
+		case frame := <-frames:
+			if frame.ID != strconv.FormatInt(want, 10) {
+				t.Fatalf("frame %d id = %q, want journal offset %d", i, frame.ID, want)
+			}
+			want++
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out after %d replayed frames", i-1)
+		}
+	}
+
+	// Live events continue the offsets without repeating replayed ones.
+	env.broadcaster.Broadcast(domain.NewOutputEvent(sessionID, "live", nil))
+	select {
+	case frame := <-frames:
+		var ev apiTypes.Event
+		_ = json.Unmarshal([]byte(frame.Data), &ev)
+		if frame.ID != strconv.FormatInt(want, 10) || ev.Seq != want {
+			t.Fatalf("live frame id = %q, seq %d; want %d", frame.ID, ev.Seq, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for live event")
+	}
+}
+
 func TestSSE_GlobalSessionEvents_Headers(t *testing.T) {
 	env := newTestEnv(t)
 	srv := httptest.NewServer(env.router())
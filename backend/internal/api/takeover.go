@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// startTakeover puts the session in manual mode for the requesting user.
+func (h *Handler) startTakeover(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.TakeoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	takeover, err := h.executor.StartTakeover(r.Context(), chi.URLParam(r, "id"), requestUser(r), req.Reason)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(takeoverToAPI(*takeover))
+}
+
+// handBackTakeover ends the session's takeover and briefs the agent.
+func (h *Handler) handBackTakeover(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.HandBackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	takeover, err := h.executor.HandBack(r.Context(), chi.URLParam(r, "id"), requestUser(r), req.Note)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(takeoverToAPI(*takeover))
+}
+
+// getTakeover returns the session's latest takeover.
+func (h *Handler) getTakeover(w http.ResponseWriter, r *http.Request) {
+	sess, err := h.executor.GetSession(chi.URLParam(r, "id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	takeovers := sess.GetTakeovers()
+	if len(takeovers) == 0 {
+		writeError(w, http.StatusNotFound, "session has never been taken over", "")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(takeoverToAPI(takeovers[len(takeovers)-1]))
+}
+
+func takeoverToAPI(t domain.Takeover) apiTypes.TakeoverResponse {
+	return apiTypes.TakeoverResponse{
+		Active:        t.Active(),
+		By:            t.By,
+		Reason:        t.Reason,
+		StartedAt:     t.StartedAt,
+		EndedAt:       t.EndedAt,
+		HandedBackBy:  t.HandedBackBy,
+		Note:          t.Note,
+		Briefing:      t.Briefing,
+		BriefingError: t.BriefingError,
+	}
+}
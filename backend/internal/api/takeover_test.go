@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestTakeover_RefusesOthersUntilHandedBack(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	created := createSession(t, r, "mock", "/tmp")
+	base := "/api/sessions/" + created.ID
+
+	do := func(method, path, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if user != "" {
+			req.Header.Set(readerHeader, user)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, base+"/takeover", "alice", `{"reason":"debugging the build"}`)
+	var takeover apiTypes.TakeoverResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &takeover)
+	if w.Code != http.StatusCreated || !takeover.Active || takeover.By != "alice" || takeover.Reason != "debugging the build" {
+		t.Fatalf("takeover = %d %+v", w.Code, takeover)
+	}
+
+	w = do(http.MethodPost, base+"/messages", "bob", `{"content":"hi"}`)
+	var errResp apiTypes.ErrorResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &errResp)
+	if w.Code != http.StatusConflict || errResp.Code != apiTypes.ErrorCodeSessionTakenOver {
+		t.Fatalf("message from bob = %d %+v, want 409 session_taken_over", w.Code, errResp)
+	}
+
+	w = do(http.MethodGet, base, "", "")
+	var sess apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &sess)
+	if sess.TakenOverBy != "alice" {
+		t.Errorf("taken_over_by = %q, want alice", sess.TakenOverBy)
+	}
+
+	w = do(http.MethodPost, base+"/takeover/hand-back", "alice", `{"note":"fixed the makefile"}`)
+	takeover = apiTypes.TakeoverResponse{}
+	_ = json.Unmarshal(w.Body.Bytes(), &takeover)
+	if w.Code != http.StatusOK || takeover.Active || takeover.EndedAt == nil || !strings.Contains(takeover.Briefing, "fixed the makefile") {
+		t.Fatalf("hand-back = %d %+v", w.Code, takeover)
+	}
+
+	w = do(http.MethodPost, base+"/takeover/hand-back", "alice", "")
+	if w.Code != http.StatusConflict {
+		t.Errorf("second hand-back = %d, want 409", w.Code)
+	}
+	w = do(http.MethodGet, base+"/timeline", "", "")
+	var tl apiTypes.SessionTimelineResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &tl)
+	if len(tl.Takeovers) != 1 || tl.Takeovers[0].Label != "alice" {
+		t.Errorf("timeline takeovers = %+v", tl.Takeovers)
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// taskChangeActor names who undoes a task change: the session's agent when
+// asked by the strand MCP server, otherwise the user.
+func taskChangeActor(r *http.Request) string {
+	if r.Header.Get(internalBypassHeader) == internalTaskValue {
+		return service.TaskAgent
+	}
+	return requestUser(r)
+}
+
+func taskChangeToResponse(c domain.TaskChange) apiTypes.TaskChange {
+	return apiTypes.TaskChange{
+		ID:        c.ID,
+		SessionID: c.SessionID,
+		TaskChangeRequest: apiTypes.TaskChangeRequest{
+			TaskID:     c.TaskID,
+			Op:         c.Op,
+			Todo:       c.Todo,
+			WorkingDir: c.WorkingDir,
+			ProjectDir: c.ProjectDir,
+			Summary:    c.Summary,
+			Before:     c.Before,
+			After:      c.After,
+		},
+		At:       c.At,
+		UndoneAt: c.UndoneAt,
+		UndoneBy: c.UndoneBy,
+	}
+}
+
+func taskHistoryResponse(taskID string, changes []domain.TaskChange) apiTypes.TaskHistoryResponse {
+	resp := apiTypes.TaskHistoryResponse{TaskID: taskID, Changes: make([]apiTypes.TaskChange, len(changes))}
+	for i, c := range changes {
+		resp.Changes[i] = taskChangeToResponse(c)
+	}
+	return resp
+}
+
+// writeTaskChangeError maps task journal errors to HTTP responses.
+func writeTaskChangeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTaskChange):
+		writeError(w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, service.ErrNoTaskChange):
+		writeError(w, http.StatusNotFound, err.Error(), "")
+	case errors.Is(err, service.ErrTaskChangeNotUndoable):
+		writeError(w, http.StatusConflict, err.Error(), "")
+	default:
+		writeError(w, http.StatusBadGateway, "failed to undo task change", err.Error())
+	}
+}
+
+// recordTaskChange journals a task change the session's agent made through
+// the strand MCP tools.
+func (h *Handler) recordTaskChange(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.executor.GetSession(id); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	var req apiTypes.TaskChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	change, err := h.executor.RecordTaskChange(domain.TaskChange{
+		TaskID:     req.TaskID,
+		Op:         req.Op,
+		Todo:       req.Todo,
+		SessionID:  id,
+		WorkingDir: req.WorkingDir,
+		ProjectDir: req.ProjectDir,
+		Summary:    req.Summary,
+		Before:     req.Before,
+		After:      req.After,
+	})
+	if err != nil {
+		writeTaskChangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(taskChangeToResponse(change))
+}
+
+// undoSessionTaskChange undoes the latest task change of the session not
+// yet undone.
+func (h *Handler) undoSessionTaskChange(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.executor.GetSession(id); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	h.undoTaskChangeOf(w, r, id)
+}
+
+// undoTaskChange undoes the latest task change not yet undone, of any
+// session unless the request names one.
+func (h *Handler) undoTaskChange(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.TaskUndoRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+	}
+	h.undoTaskChangeOf(w, r, req.SessionID)
+}
+
+func (h *Handler) undoTaskChangeOf(w http.ResponseWriter, r *http.Request, sessionID string) {
+	change, err := h.executor.UndoLastTaskChange(r.Context(), sessionID, taskChangeActor(r))
+	if err != nil {
+		writeTaskChangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(taskChangeToResponse(change))
+}
+
+// listTaskChanges returns the task change journal, oldest first, optionally
+// limited to one session's changes.
+func (h *Handler) listTaskChanges(w http.ResponseWriter, r *http.Request) {
+	changes := h.executor.TaskChanges("", r.URL.Query().Get("session_id"))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(taskHistoryResponse("", changes))
+}
+
+// getTaskHistory returns the journaled changes of one task, oldest first.
+func (h *Handler) getTaskHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(taskHistoryResponse(id, h.executor.TaskChanges(id, "")))
+}
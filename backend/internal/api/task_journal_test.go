@@ -0,0 +1,47 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestTaskChangeJournal(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	sess := createSession(t, r, "mock", t.TempDir())
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body)))
+		return w
+	}
+	if w := post("/api/sessions/"+sess.ID+"/task-changes", `{"task_id":"T1","op":"claim","before":"{\"status\":\"open\"}"}`); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := post("/api/sessions/"+sess.ID+"/task-changes", `{"task_id":"T1","op":"delete"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown op to be rejected, got %d", w.Code)
+	}
+	if w := post("/api/sessions/missing/task-changes", `{"task_id":"T1","op":"claim"}`); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown session, got %d", w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/T1/history", nil))
+	var history apiTypes.TaskHistoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if history.TaskID != "T1" || len(history.Changes) != 1 || history.Changes[0].SessionID != sess.ID || history.Changes[0].Op != "claim" {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	// The test server has no task source that can run strand commands.
+	if w := post("/api/v1/tasks/changes/undo", ""); w.Code != http.StatusConflict {
+		t.Fatalf("expected 409 without a task command runner, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func (h *Handler) listTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.executor.Templates()
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	resp := apiTypes.TemplateListResponse{Templates: make([]apiTypes.TemplateResponse, len(templates))}
+	for i := range templates {
+		resp.Templates[i] = templateToResponse(&templates[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) getTemplate(w http.ResponseWriter, r *http.Request) {
+	t, err := h.executor.Template(chi.URLParam(r, "id"))
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(templateToResponse(t))
+}
+
+func (h *Handler) createTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.executor.CreateTemplate(t)
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(templateToResponse(created))
+}
+
+func (h *Handler) updateTemplate(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+	updated, err := h.executor.UpdateTemplate(chi.URLParam(r, "id"), t)
+	if err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(templateToResponse(updated))
+}
+
+func (h *Handler) deleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if err := h.executor.DeleteTemplate(chi.URLParam(r, "id")); err != nil {
+		writeTemplateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeTemplate reads a TemplateRequest, checking that its agent exists.
+// It answers the request itself when it fails.
+func (h *Handler) decodeTemplate(w http.ResponseWriter, r *http.Request) (domain.PromptTemplate, bool) {
+	var req apiTypes.TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return domain.PromptTemplate{}, false
+	}
+	if req.AgentID != "" && h.agentStorage != nil {
+		if _, err := h.agentStorage.Get(req.AgentID); err != nil {
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeAgentNotFound, "agent not found", err.Error())
+			return domain.PromptTemplate{}, false
+		}
+	}
+
+	t := domain.PromptTemplate{
+		Name:         strings.TrimSpace(req.Name),
+		Description:  req.Description,
+		Prompt:       req.Prompt,
+		SystemPrompt: req.SystemPrompt,
+		ProviderType: strings.TrimSpace(req.ProviderType),
+		AgentID:      req.AgentID,
+	}
+	for _, v := range req.Variables {
+		t.Variables = append(t.Variables, domain.PromptTemplateVariable{
+			Name:        strings.TrimSpace(v.Name),
+			Description: v.Description,
+			Default:     v.Default,
+		})
+	}
+	return t, true
+}
+
+// renderMessageTemplate renders the prompt of the message's template for
+// the session, followed by the message's content. It answers the request
+// itself when it fails.
+func (h *Handler) renderMessageTemplate(w http.ResponseWriter, sessionID string, req apiTypes.SendMessageRequest) (string, bool) {
+	t, err := h.executor.Template(req.TemplateID)
+	if err != nil {
+		writeTemplateError(w, err)
+		return "", false
+	}
+	if strings.TrimSpace(t.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, "template has no prompt", "")
+		return "", false
+	}
+	sess, err := h.executor.GetSession(sessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return "", false
+	}
+	snap := sess.Snapshot()
+	// The current task reads "<id> - <title>" for sessions with both.
+	taskTitle := snap.CurrentTask
+	if snap.TaskID != "" {
+		taskTitle = strings.TrimPrefix(strings.TrimPrefix(taskTitle, snap.TaskID), " - ")
+	}
+	vars := h.executor.TemplateVariables(snap.TaskID, taskTitle, snap.ProjectID, snap.WorkingDir, snap.ProviderType, req.TemplateVariables)
+	content, err := t.Render(t.Prompt, vars)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "cannot render template", err.Error())
+		return "", false
+	}
+	if extra := strings.TrimSpace(req.Content); extra != "" {
+		content += "\n\n" + extra
+	}
+	return content, true
+}
+
+// templateRequestError is why a session request's template could not be
+// applied.
+func templateRequestError(err error) *sessionRequestError {
+	switch {
+	case errors.Is(err, service.ErrTemplatesDisabled):
+		return &sessionRequestError{status: http.StatusNotImplemented, message: err.Error()}
+	case errors.Is(err, storage.ErrTemplateNotFound):
+		return &sessionRequestError{status: http.StatusNotFound, code: apiTypes.ErrorCodeTemplateNotFound, message: "template not found", details: err.Error()}
+	default:
+		return &sessionRequestError{status: http.StatusInternalServerError, message: "failed to load template", details: err.Error()}
+	}
+}
+
+func writeTemplateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrTemplatesDisabled):
+		writeError(w, http.StatusNotImplemented, err.Error(), "")
+	case errors.Is(err, service.ErrInvalidTemplate):
+		writeError(w, http.StatusBadRequest, "invalid template", err.Error())
+	case errors.Is(err, storage.ErrTemplateNotFound):
+		writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeTemplateNotFound, "template not found", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "template operation failed", err.Error())
+	}
+}
+
+func templateToResponse(t *domain.PromptTemplate) apiTypes.TemplateResponse {
+	resp := apiTypes.TemplateResponse{
+		TemplateRequest: apiTypes.TemplateRequest{
+			Name:         t.Name,
+			Description:  t.Description,
+			Prompt:       t.Prompt,
+			SystemPrompt: t.SystemPrompt,
+			ProviderType: t.ProviderType,
+			AgentID:      t.AgentID,
+		},
+		ID:        t.ID,
+		CreatedAt: t.CreatedAt,
+		UpdatedAt: t.UpdatedAt,
+	}
+	for _, v := range t.Variables {
+		resp.Variables = append(resp.Variables, apiTypes.TemplateVariable{Name: v.Name, Description: v.Description, Default: v.Default})
+	}
+	return resp
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestTemplates_RenderIntoSessionsAndMessages(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(data)))
+		return w
+	}
+	if w := do(http.MethodPost, "/api/v1/templates", apiTypes.TemplateRequest{Name: "empty"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a template without a prompt, got %d", w.Code)
+	}
+	if w := do(http.MethodPost, "/api/v1/templates", apiTypes.TemplateRequest{Name: "bad", Prompt: "hi", ProviderType: "nope"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown provider, got %d", w.Code)
+	}
+
+	w := do(http.MethodPost, "/api/v1/templates", apiTypes.TemplateRequest{
+		Name:         "fix bug",
+		SystemPrompt: "You fix bugs in {{.repo}}.",
+		Prompt:       "Fix {{.task_title}} ({{ .severity }}).",
+		ProviderType: "mock",
+		Variables:    []apiTypes.TemplateVariable{{Name: "severity", Default: "minor"}},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var tmpl apiTypes.TemplateResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &tmpl)
+
+	workingDir := filepath.Join(t.TempDir(), "widgets")
+	w = do(http.MethodPost, "/api/sessions/dry-run", apiTypes.SessionRequest{TemplateID: tmpl.ID, WorkingDir: workingDir})
+	var dry apiTypes.SessionDryRunResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &dry)
+	if dry.ProviderType != "mock" || dry.SystemPrompt != "You fix bugs in widgets." {
+		t.Fatalf("expected the template's presets and rendered system prompt, got %+v", dry)
+	}
+	if w := do(http.MethodPost, "/api/sessions", apiTypes.SessionRequest{TemplateID: "nope", ProviderType: "mock", WorkingDir: workingDir}); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown template, got %d", w.Code)
+	}
+
+	w = do(http.MethodPost, "/api/sessions", apiTypes.SessionRequest{TemplateID: tmpl.ID, WorkingDir: workingDir, TaskID: "T-7", TaskTitle: "the login redirect"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = do(http.MethodPost, "/api/sessions/"+created.ID+"/messages", apiTypes.SendMessageRequest{
+		TemplateID:        tmpl.ID,
+		TemplateVariables: map[string]string{"severity": "critical"},
+		Content:           "It only fails on Safari.",
+	})
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	sess, err := env.executor.GetSession(created.ID)
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	waitForState(t, sess, domain.SessionStateRunning)
+	env.lastMock.mu.Lock()
+	input := env.lastMock.lastInput
+	env.lastMock.mu.Unlock()
+	if want := "Fix the login redirect (critical).\n\nIt only fails on Safari."; input != want {
+		t.Fatalf("expected %q, got %q", want, input)
+	}
+
+	w = do(http.MethodPut, "/api/v1/templates/"+tmpl.ID, apiTypes.TemplateRequest{Name: "fix bug", Prompt: "Fix {{.component}}."})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/sessions/"+created.ID+"/messages", apiTypes.SendMessageRequest{TemplateID: tmpl.ID}); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a variable without a value, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if w := do(http.MethodDelete, "/api/v1/templates/"+tmpl.ID, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/api/v1/templates/"+tmpl.ID, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", w.Code)
+	}
+}
@@ -43,7 +43,7 @@ func (h *Handler) getTerminalSnapshotByID(w http.ResponseWriter, r *http.Request
 			if terminalKnown {
 				writeError(w, http.StatusNotFound, "terminal snapshot not available", "")
 			} else {
-				writeError(w, http.StatusNotFound, "terminal not found", "")
+				writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeTerminalNotFound, "terminal not found", "")
 			}
 		default:
 			writeError(w, http.StatusInternalServerError, "failed to get terminal snapshot", err.Error())
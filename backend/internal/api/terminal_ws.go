@@ -7,13 +7,17 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/websocket"
 
 	"github.com/ricochet1k/orbitmesh/internal/service"
+	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/terminal"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
 	"github.com/ricochet1k/termemu"
 )
 
@@ -47,6 +51,8 @@ func (e *terminalInputError) Error() string {
 	return e.message
 }
 
+func supportsTerminal(c session.Capabilities) bool { return c.Terminal }
+
 func (h *Handler) terminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	if !defaultPermissions.CanInspectSessions {
 		writeError(w, http.StatusForbidden, "session inspection not allowed", "")
@@ -56,30 +62,38 @@ func (h *Handler) terminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
 	if _, err := h.executor.GetSession(sessionID); err != nil {
 		if errors.Is(err, service.ErrSessionNotFound) {
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 			return
 		}
 		writeError(w, http.StatusInternalServerError, "failed to look up session", err.Error())
 		return
 	}
+	if !h.requireCapability(w, sessionID, "terminals", supportsTerminal) {
+		return
+	}
 
 	hub, err := h.executor.TerminalHub(sessionID)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrTerminalNotSupported):
-			writeError(w, http.StatusConflict, "terminal not available", "")
+			writeErrorCode(w, http.StatusConflict, apiTypes.ErrorCodeInvalidState, "terminal not available", "")
 		case errors.Is(err, service.ErrSessionNotFound):
-			writeError(w, http.StatusNotFound, "session not found", "")
+			writeErrorCode(w, http.StatusNotFound, apiTypes.ErrorCodeSessionNotFound, "session not found", "")
 		default:
 			writeError(w, http.StatusInternalServerError, "failed to start terminal", err.Error())
 		}
 		return
 	}
 
-	allowInput := terminalWriteRequested(r)
+	role, ok := terminalRoleRequested(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid terminal role", "role must be read_only or read_write")
+		return
+	}
+	allowInput := role == service.TerminalRoleReadWrite
 	allowRaw := allowInput && terminalRawRequested(r)
-	if allowInput && !csrfTokenMatches(r) {
-		writeError(w, http.StatusForbidden, "invalid CSRF token", "csrf header mismatch")
+	if allowInput && !isEmbeddedClient(r) && !csrfTokenMatches(r) {
+		writeErrorCode(w, http.StatusForbidden, apiTypes.ErrorCodeInvalidCSRFToken, "invalid CSRF token", "csrf header mismatch")
 		return
 	}
 
@@ -87,13 +101,31 @@ func (h *Handler) terminalWebSocket(w http.ResponseWriter, r *http.Request) {
 		CheckOrigin: func(r *http.Request) bool { return true },
 	}
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	ws, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
-	defer conn.Close()
+	defer ws.Close()
+	conn := &terminalConn{Conn: ws}
 
-	updates, cancel := hub.Subscribe(0)
+	viewer, updates, cancel := hub.Attach(terminalViewerName(r), role, 0)
+	// The joined message is written before the writer starts so it is the
+	// first the client sees. It is not part of the sequenced stream.
+	joined := terminalEnvelope{
+		Version:   terminalProtocolVersion,
+		Type:      "terminal.joined",
+		SessionID: sessionID,
+		TS:        time.Now().UTC(),
+		Data: map[string]any{
+			"viewer_id": viewer.ID,
+			"role":      viewer.Role,
+			"viewers":   terminalViewersPayload(hub.Viewers()),
+		},
+	}
+	if err := conn.WriteJSON(joined); err != nil {
+		cancel()
+		return
+	}
 
 	writeDone := make(chan struct{})
 	go func() {
@@ -116,7 +148,7 @@ func (h *Handler) terminalWebSocket(w http.ResponseWriter, r *http.Request) {
 		if len(data) == 0 {
 			continue
 		}
-		if err := handleTerminalInput(r.Context(), hub, sessionID, allowInput, allowRaw, data, conn); err != nil {
+		if err := handleTerminalInput(r.Context(), hub, sessionID, viewer, allowRaw, data, conn); err != nil {
 			break
 		}
 	}
@@ -125,7 +157,21 @@ func (h *Handler) terminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	<-writeDone
 }
 
-func handleTerminalInput(ctx context.Context, hub *service.TerminalHub, sessionID string, allowInput, allowRaw bool, data []byte, conn *websocket.Conn) error {
+// terminalConn serializes writes to a terminal websocket. The writer
+// goroutine and the read loop (which reports input errors) both write to the
+// connection, and gorilla/websocket allows only one concurrent writer.
+type terminalConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *terminalConn) WriteJSON(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Conn.WriteJSON(v)
+}
+
+func handleTerminalInput(ctx context.Context, hub *service.TerminalHub, sessionID string, viewer service.TerminalViewer, allowRaw bool, data []byte, conn *terminalConn) error {
 	var msg terminalInboundMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
 		return sendTerminalError(conn, sessionID, hub.NextSeq(), "bad_request", "invalid message")
@@ -137,7 +183,7 @@ func handleTerminalInput(ctx context.Context, hub *service.TerminalHub, sessionI
 	if !strings.HasPrefix(msg.Type, "input.") {
 		return sendTerminalError(conn, sessionID, hub.NextSeq(), "unsupported", "unsupported message type")
 	}
-	if !allowInput {
+	if viewer.Role != service.TerminalRoleReadWrite {
 		return sendTerminalError(conn, sessionID, hub.NextSeq(), "forbidden", "terminal input not allowed")
 	}
 
@@ -149,7 +195,7 @@ func handleTerminalInput(ctx context.Context, hub *service.TerminalHub, sessionI
 		return sendTerminalError(conn, sessionID, hub.NextSeq(), "bad_request", err.Error())
 	}
 
-	if err := hub.HandleInput(ctx, input); err != nil {
+	if err := hub.HandleViewerInput(ctx, viewer, input); err != nil {
 		return sendTerminalError(conn, sessionID, hub.NextSeq(), "input_failed", err.Error())
 	}
 	return nil
@@ -471,69 +517,111 @@ func firstRune(value string) rune {
 	return []rune(value)[0]
 }
 
-func writeTerminalEvent(conn *websocket.Conn, sessionID string, event service.TerminalEvent) error {
-	update := event.Update
-	var (
-		messageType string
-		payload     any
-	)
+func writeTerminalEvent(conn *terminalConn, sessionID string, event service.TerminalEvent) error {
+	messageType, payload, ok := terminalEventPayload(event)
+	if !ok {
+		return nil
+	}
+	envelope := terminalEnvelope{
+		Version:   terminalProtocolVersion,
+		Type:      messageType,
+		SessionID: sessionID,
+		Seq:       event.Seq,
+		TS:        time.Now().UTC(),
+		Data:      payload,
+	}
+	return conn.WriteJSON(envelope)
+}
 
+// terminalEventPayload returns the message type and payload of a terminal
+// event, shared by the terminal websocket and the realtime stream. ok is
+// false for events that are not sent to clients.
+func terminalEventPayload(event service.TerminalEvent) (string, realtimeTypes.TerminalOutputData, bool) {
+	switch {
+	case event.Presence != nil:
+		return "terminal.viewers", realtimeTypes.TerminalViewersPayload{Viewers: terminalViewersPayload(event.Presence.Viewers)}, true
+	case event.Input != nil:
+		return "terminal.input", realtimeTypes.TerminalInputPayload{
+			ViewerID: event.Input.ViewerID,
+			Name:     event.Input.Name,
+			Input:    terminalInputTypeName(event.Input.Kind),
+		}, true
+	}
+
+	update := event.Update
 	switch update.Kind {
 	case terminal.UpdateSnapshot:
-		messageType = "terminal.snapshot"
-		if update.Snapshot != nil {
-			payload = map[string]any{
-				"rows":  update.Snapshot.Rows,
-				"cols":  update.Snapshot.Cols,
-				"lines": update.Snapshot.Lines,
-			}
+		if update.Snapshot == nil {
+			return "terminal.snapshot", nil, true
 		}
+		return "terminal.snapshot", realtimeTypes.TerminalSnapshot{
+			Rows:  update.Snapshot.Rows,
+			Cols:  update.Snapshot.Cols,
+			Lines: update.Snapshot.Lines,
+		}, true
 	case terminal.UpdateDiff:
-		messageType = "terminal.diff"
-		if update.Diff != nil {
-			payload = map[string]any{
-				"region": map[string]int{
-					"x":  update.Diff.Region.X,
-					"y":  update.Diff.Region.Y,
-					"x2": update.Diff.Region.X2,
-					"y2": update.Diff.Region.Y2,
-				},
-				"lines":  update.Diff.Lines,
-				"reason": update.Diff.Reason,
-			}
+		if update.Diff == nil {
+			return "terminal.diff", nil, true
 		}
+		region := update.Diff.Region
+		return "terminal.diff", realtimeTypes.TerminalDiffPayload{
+			Region: realtimeTypes.TerminalRegion{X: region.X, Y: region.Y, X2: region.X2, Y2: region.Y2},
+			Lines:  update.Diff.Lines,
+			Reason: update.Diff.Reason,
+		}, true
 	case terminal.UpdateCursor:
-		messageType = "terminal.cursor"
-		if update.Cursor != nil {
-			payload = map[string]int{"x": update.Cursor.X, "y": update.Cursor.Y}
+		if update.Cursor == nil {
+			return "terminal.cursor", nil, true
 		}
+		return "terminal.cursor", realtimeTypes.TerminalCursorPayload{X: update.Cursor.X, Y: update.Cursor.Y}, true
 	case terminal.UpdateBell:
-		messageType = "terminal.bell"
+		return "terminal.bell", nil, true
 	case terminal.UpdateError:
-		messageType = "terminal.error"
-		if update.Error != nil {
-			payload = map[string]any{
-				"code":    update.Error.Code,
-				"message": update.Error.Message,
-				"resync":  update.Error.Resync,
-			}
+		if update.Error == nil {
+			return "terminal.error", nil, true
 		}
-	default:
-		return nil
+		return "terminal.error", realtimeTypes.TerminalErrorPayload{
+			Code:    update.Error.Code,
+			Message: update.Error.Message,
+			Resync:  update.Error.Resync,
+		}, true
 	}
+	return "", nil, false
+}
 
-	envelope := terminalEnvelope{
-		Version:   terminalProtocolVersion,
-		Type:      messageType,
-		SessionID: sessionID,
-		Seq:       event.Seq,
-		TS:        time.Now().UTC(),
-		Data:      payload,
+func terminalViewersPayload(viewers []service.TerminalViewer) []realtimeTypes.TerminalViewer {
+	out := make([]realtimeTypes.TerminalViewer, 0, len(viewers))
+	for _, v := range viewers {
+		out = append(out, realtimeTypes.TerminalViewer{
+			ViewerID: v.ID,
+			Name:     v.Name,
+			Role:     string(v.Role),
+			JoinedAt: v.JoinedAt,
+		})
+	}
+	return out
+}
+
+func terminalInputTypeName(kind terminal.InputKind) string {
+	switch kind {
+	case terminal.InputKey:
+		return "input.key"
+	case terminal.InputText:
+		return "input.text"
+	case terminal.InputMouse:
+		return "input.mouse"
+	case terminal.InputResize:
+		return "input.resize"
+	case terminal.InputControl:
+		return "input.control"
+	case terminal.InputRaw:
+		return "input.raw"
+	default:
+		return "input.unknown"
 	}
-	return conn.WriteJSON(envelope)
 }
 
-func sendTerminalError(conn *websocket.Conn, sessionID string, seq int64, code, message string) error {
+func sendTerminalError(conn *terminalConn, sessionID string, seq int64, code, message string) error {
 	envelope := terminalEnvelope{
 		Version:   terminalProtocolVersion,
 		Type:      "terminal.error",
@@ -559,6 +647,32 @@ func terminalWriteRequested(r *http.Request) bool {
 	return strings.EqualFold(q.Get("mode"), "write")
 }
 
+// terminalRoleRequested negotiates the viewer role from the role query
+// parameter. Without it, the legacy write flags ask for read_write.
+func terminalRoleRequested(r *http.Request) (service.TerminalRole, bool) {
+	switch role := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("role"))); role {
+	case "":
+		if terminalWriteRequested(r) {
+			return service.TerminalRoleReadWrite, true
+		}
+		return service.TerminalRoleReadOnly, true
+	case string(service.TerminalRoleReadOnly), string(service.TerminalRoleReadWrite):
+		return service.TerminalRole(role), true
+	default:
+		return "", false
+	}
+}
+
+const maxTerminalViewerName = 64
+
+func terminalViewerName(r *http.Request) string {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if runes := []rune(name); len(runes) > maxTerminalViewerName {
+		name = string(runes[:maxTerminalViewerName])
+	}
+	return name
+}
+
 func terminalRawRequested(r *http.Request) bool {
 	q := r.URL.Query()
 	return strings.EqualFold(q.Get("allow_raw"), "true")
@@ -65,6 +65,10 @@ func (m *mockTerminalProvider) Status() session.Status {
 	return session.Status{State: m.state}
 }
 
+func (m *mockTerminalProvider) Capabilities() session.Capabilities {
+	return session.Capabilities{Steering: true, Terminal: true}
+}
+
 func (m *mockTerminalProvider) SendInput(_ context.Context, _ session.Config, _ string) (<-chan domain.Event, error) {
 	return m.events, nil
 }
@@ -313,3 +317,76 @@ func TestTerminalWebSocket_Disconnect(t *testing.T) {
 		t.Fatalf("expected subscriber to be removed, got %d", hub.SubscriberCount())
 	}
 }
+
+func TestTerminalWebSocket_SharedViewers(t *testing.T) {
+	env := newTerminalTestEnv(t)
+	server := httptest.NewServer(env.router())
+	defer server.Close()
+	_ = startTerminalSession(t, env)
+
+	base := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/sessions/session-1/terminal/ws"
+	if _, resp, err := websocket.DefaultDialer.Dial(base+"?role=owner", nil); err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown role, got %v", resp)
+	}
+
+	readJSON := func(conn *websocket.Conn, wantType string) terminalEnvelope {
+		t.Helper()
+		for {
+			var envelope struct {
+				terminalEnvelope
+				Data map[string]any `json:"data"`
+			}
+			_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			if err := conn.ReadJSON(&envelope); err != nil {
+				t.Fatalf("waiting for %s: %v", wantType, err)
+			}
+			if envelope.Type == wantType {
+				envelope.terminalEnvelope.Data = envelope.Data
+				return envelope.terminalEnvelope
+			}
+		}
+	}
+
+	reader, _, err := websocket.DefaultDialer.Dial(base+"?role=read_only&name=bo", nil)
+	if err != nil {
+		t.Fatalf("dial reader: %v", err)
+	}
+	defer reader.Close()
+	joined := readJSON(reader, "terminal.joined").Data.(map[string]any)
+	if joined["role"] != "read_only" {
+		t.Fatalf("reader joined as %v", joined["role"])
+	}
+
+	const token = "test-csrf-ws-token"
+	jar := &mockCookieJar{cookies: map[string][]*http.Cookie{
+		server.URL: {{Name: csrfCookieName, Value: token}},
+	}}
+	writer, _, err := (&websocket.Dialer{Jar: jar}).Dial(base+"?role=read_write&name=ana&csrf_token="+token, nil)
+	if err != nil {
+		t.Fatalf("dial writer: %v", err)
+	}
+	defer writer.Close()
+	writerID := readJSON(writer, "terminal.joined").Data.(map[string]any)["viewer_id"]
+
+	// The reader first sees its own arrival, then the writer's.
+	readJSON(reader, "terminal.viewers")
+	viewers := readJSON(reader, "terminal.viewers").Data.(map[string]any)["viewers"].([]any)
+	if len(viewers) != 2 {
+		t.Fatalf("reader sees %d viewers, want 2", len(viewers))
+	}
+
+	if err := reader.WriteJSON(map[string]any{"type": "input.text", "data": map[string]string{"text": "ls"}}); err != nil {
+		t.Fatalf("reader write: %v", err)
+	}
+	if code := readJSON(reader, "terminal.error").Data.(map[string]any)["code"]; code != "forbidden" {
+		t.Fatalf("reader input error code = %v, want forbidden", code)
+	}
+
+	if err := writer.WriteJSON(map[string]any{"type": "input.text", "data": map[string]string{"text": "ls"}}); err != nil {
+		t.Fatalf("writer write: %v", err)
+	}
+	input := readJSON(reader, "terminal.input").Data.(map[string]any)
+	if input["viewer_id"] != writerID || input["name"] != "ana" || input["input"] != "input.text" {
+		t.Fatalf("unexpected input attribution %v", input)
+	}
+}
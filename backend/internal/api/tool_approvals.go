@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// parseToolApproval converts a session request's tool approval policy.
+func parseToolApproval(req *apiTypes.ToolApproval) (*domain.ToolApproval, error) {
+	if req == nil {
+		return nil, nil
+	}
+	if !domain.ValidToolApprovalTimeoutAction(req.OnTimeout) {
+		return nil, fmt.Errorf("on_timeout must be %q or %q", domain.ToolApprovalTimeoutDeny, domain.ToolApprovalTimeoutAllow)
+	}
+	approval := &domain.ToolApproval{AutoAllow: req.AutoAllow, OnTimeout: req.OnTimeout}
+	if req.Timeout != "" {
+		timeout, err := time.ParseDuration(req.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("timeout must be a positive duration, got %q", req.Timeout)
+		}
+		approval.Timeout = timeout
+	}
+	return approval, nil
+}
+
+func toolApprovalToResponse(sessionID string, a domain.ToolApprovalRecord) apiTypes.ToolApprovalResponse {
+	return apiTypes.ToolApprovalResponse{
+		ID:           a.ID,
+		SessionID:    sessionID,
+		ToolCallID:   a.ToolCallID,
+		ToolName:     a.ToolName,
+		Input:        a.Input,
+		Description:  a.Description,
+		Status:       apiTypes.ToolApprovalStatus(a.Status),
+		RequestedAt:  a.RequestedAt,
+		DecidedBy:    a.DecidedBy,
+		DecidedAt:    a.DecidedAt,
+		Reason:       a.Reason,
+		UpdatedInput: a.UpdatedInput,
+	}
+}
+
+func writeToolApprovalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrToolApprovalNotFound):
+		writeError(w, http.StatusNotFound, "tool approval not found", "")
+	case errors.Is(err, service.ErrToolApprovalDecided):
+		writeError(w, http.StatusConflict, err.Error(), "")
+	default:
+		writeSessionError(w, err)
+	}
+}
+
+// listToolApprovals returns the tool calls the session asked permission
+// for, oldest first. ?status=pending lists only the undecided ones.
+func (h *Handler) listToolApprovals(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	status := r.URL.Query().Get("status")
+	switch apiTypes.ToolApprovalStatus(status) {
+	case "", apiTypes.ToolApprovalStatusPending, apiTypes.ToolApprovalStatusAllowed, apiTypes.ToolApprovalStatusDenied:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid status", status)
+		return
+	}
+	approvals, err := h.executor.SessionToolApprovals(id)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	resp := apiTypes.ToolApprovalListResponse{Approvals: make([]apiTypes.ToolApprovalResponse, 0, len(approvals))}
+	for _, a := range approvals {
+		if status == "" || string(a.Status) == status {
+			resp.Approvals = append(resp.Approvals, toolApprovalToResponse(id, a))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// decideToolApproval allows or denies a pending tool call, releasing the
+// provider waiting on it.
+func (h *Handler) decideToolApproval(w http.ResponseWriter, r *http.Request) {
+	var req apiTypes.ToolApprovalDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	var allow bool
+	switch req.Decision {
+	case "allow":
+		allow = true
+	case "deny":
+		if req.Input != nil {
+			writeError(w, http.StatusBadRequest, "input can only be given when allowing", "")
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, `decision must be "allow" or "deny"`, req.Decision)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	a, err := h.executor.DecideToolApproval(id, chi.URLParam(r, "approvalID"), allow, req.Input, req.Reason, requestUser(r))
+	if err != nil {
+		writeToolApprovalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(toolApprovalToResponse(id, a))
+}
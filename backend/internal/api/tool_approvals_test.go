@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestToolApprovals_PolicyAndDecisionValidation(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, policy := range []string{`{"timeout":"soon"}`, `{"on_timeout":"ask"}`} {
+		body, _ := json.Marshal(map[string]any{"provider_type": "mock", "working_dir": "/tmp", "tool_approval": json.RawMessage(policy)})
+		if w := do(http.MethodPost, "/api/sessions", string(body)); w.Code != http.StatusBadRequest {
+			t.Errorf("create with tool_approval %s = %d, want 400", policy, w.Code)
+		}
+	}
+
+	body, _ := json.Marshal(apiTypes.SessionRequest{
+		ProviderType: "mock",
+		WorkingDir:   "/tmp",
+		ToolApproval: &apiTypes.ToolApproval{AutoAllow: []string{"Read"}, Timeout: "5m", OnTimeout: "allow"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var created apiTypes.SessionResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != http.StatusCreated || created.ToolApproval == nil || created.ToolApproval.Timeout != "5m0s" || created.ToolApproval.OnTimeout != "allow" {
+		t.Fatalf("create = %d %+v", w.Code, created.ToolApproval)
+	}
+	base := "/api/sessions/" + created.ID
+
+	w = do(http.MethodGet, base+"/approvals?status=pending", "")
+	var list apiTypes.ToolApprovalListResponse
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || list.Approvals == nil || len(list.Approvals) != 0 {
+		t.Fatalf("list = %d %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, base+"/approvals?status=maybe", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("list with bad status = %d, want 400", w.Code)
+	}
+	if w := do(http.MethodPost, base+"/approvals/missing/decision", `{"decision":"maybe"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad decision = %d, want 400", w.Code)
+	}
+	if w := do(http.MethodPost, base+"/approvals/missing/decision", `{"decision":"allow"}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown approval = %d, want 404", w.Code)
+	}
+}
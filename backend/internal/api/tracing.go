@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware traces each request, continuing a trace the caller
+// propagated. Spans are named by method and route pattern once the router
+// has matched the request, so requests for different sessions group
+// together.
+func TracingMiddleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if pattern := routePattern(r); pattern != "" {
+			trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.route", pattern))
+		}
+	}), "http.request", otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+		// otelhttp names the span again after the request is served, by
+		// which time the router has filled in the pattern.
+		if pattern := routePattern(r); pattern != "" {
+			return r.Method + " " + pattern
+		}
+		return operation
+	}))
+}
+
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil {
+		return ""
+	}
+	return rctx.RoutePattern()
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
+)
+
+// parseVisibilityProfile looks up a named visibility profile. An empty name
+// returns nil, meaning nothing is filtered.
+func parseVisibilityProfile(name string) (*domain.VisibilityProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	profile, ok := domain.LookupVisibilityProfile(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown visibility profile: %s (want one of %s)", name, strings.Join(domain.VisibilityProfileNames(), ", "))
+	}
+	return &profile, nil
+}
+
+// visibilityProfileFromRequest reads the "profile" query parameter.
+func visibilityProfileFromRequest(r *http.Request) (*domain.VisibilityProfile, error) {
+	return parseVisibilityProfile(r.URL.Query().Get("profile"))
+}
+
+// applyVisibilityToSnapshot drops the messages a sessions.activity snapshot
+// should not show under profile.
+func applyVisibilityToSnapshot(snapshot any, profile *domain.VisibilityProfile) any {
+	activity, ok := snapshot.(realtimeTypes.SessionActivitySnapshot)
+	if !ok || profile == nil {
+		return snapshot
+	}
+	messages := make([]realtimeTypes.SessionMessage, 0, len(activity.Messages))
+	for _, msg := range activity.Messages {
+		if profile.ShowsKind(domain.MessageKind(msg.Kind)) {
+			messages = append(messages, msg)
+		}
+	}
+	activity.Messages = messages
+	return activity
+}
+
+// eventVisibleTo returns the realtime visibility check for event.
+func eventVisibleTo(event domain.Event) func(profile string) bool {
+	return func(name string) bool {
+		profile, ok := domain.LookupVisibilityProfile(name)
+		return !ok || profile.ShowsEvent(event)
+	}
+}
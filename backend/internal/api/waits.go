@@ -0,0 +1,17 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/presentation"
+)
+
+// listWaits returns everything blocking on a human or an external system
+// across all sessions, most urgent first.
+func (h *Handler) listWaits(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(presentation.Waits(h.executor.PendingWaits(now), now))
+}
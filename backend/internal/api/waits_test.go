@@ -0,0 +1,27 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+func TestListWaits_Empty(t *testing.T) {
+	env := newTestEnv(t)
+	r := env.router()
+	createSession(t, r, "mock", "/tmp")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/waits", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	var resp apiTypes.WaitListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("waits = %d %s", w.Code, w.Body.String())
+	}
+	if resp.Waits == nil || len(resp.Waits) != 0 {
+		t.Errorf("waits = %+v, want an empty list", resp.Waits)
+	}
+}
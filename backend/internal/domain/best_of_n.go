@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// SessionKindBestOfN marks a candidate session of a best-of-N group.
+const SessionKindBestOfN = "best_of_n"
+
+// Best-of-N group statuses.
+const (
+	BestOfNRunning   = "running"
+	BestOfNMerged    = "merged"
+	BestOfNDiscarded = "discarded"
+)
+
+// BestOfN is a parent session's best-of-N group: one prompt run by several
+// candidate sessions, each in its own git worktree branched from BaseRef,
+// until one candidate is picked and merged into the parent's working
+// directory.
+type BestOfN struct {
+	Prompt     string             `json:"prompt"`
+	BaseRef    string             `json:"base_ref"`
+	Candidates []BestOfNCandidate `json:"candidates"`
+	Status     string             `json:"status"`
+	CreatedAt  time.Time          `json:"created_at"`
+	// WinnerID is the candidate session that was merged.
+	WinnerID  string     `json:"winner_id,omitempty"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// BestOfNCandidate is one run of a best-of-N group.
+type BestOfNCandidate struct {
+	SessionID    string `json:"session_id"`
+	ProviderType string `json:"provider_type"`
+	ProviderID   string `json:"provider_id,omitempty"`
+	Branch       string `json:"branch"`
+	Worktree     string `json:"worktree"`
+}
+
+func (b *BestOfN) clone() *BestOfN {
+	if b == nil {
+		return nil
+	}
+	out := *b
+	out.Candidates = append([]BestOfNCandidate(nil), b.Candidates...)
+	return &out
+}
+
+// GetBestOfN returns a copy of the session's best-of-N group, if any.
+func (s *Session) GetBestOfN() *BestOfN {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.BestOfN.clone()
+}
+
+// SetBestOfN replaces the session's best-of-N group.
+func (s *Session) SetBestOfN(b *BestOfN) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.BestOfN = b.clone()
+	s.UpdatedAt = time.Now()
+}
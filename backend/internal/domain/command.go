@@ -0,0 +1,224 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WaitKindCommandApproval marks a run suspended on a command the agent
+// proposed in a session that requires command approval.
+const WaitKindCommandApproval = "command_approval"
+
+// MaxSessionCommands bounds a session's command history; the oldest
+// commands are dropped first.
+const MaxSessionCommands = 500
+
+// CommandStatus is where a shell command an agent ran is in its lifecycle.
+type CommandStatus string
+
+const (
+	CommandStatusProposed  CommandStatus = "proposed"
+	CommandStatusRejected  CommandStatus = "rejected"
+	CommandStatusRunning   CommandStatus = "running"
+	CommandStatusCompleted CommandStatus = "completed"
+	CommandStatusFailed    CommandStatus = "failed"
+)
+
+// CommandApprovalDecision records how a proposed command was let through
+// or stopped.
+type CommandApprovalDecision string
+
+const (
+	// CommandApprovalAuto marks a command the session's policy let through
+	// without asking.
+	CommandApprovalAuto     CommandApprovalDecision = "auto"
+	CommandApprovalApproved CommandApprovalDecision = "approved"
+	CommandApprovalRejected CommandApprovalDecision = "rejected"
+)
+
+// CommandTools are the tool names whose calls run a shell command taken from
+// the "command" field of their input.
+var CommandTools = []string{"Bash", "bash", "shell", "run_shell_command"}
+
+// CommandApproval makes the agent propose each shell command and wait for a
+// human to approve it before it runs.
+type CommandApproval struct {
+	// AutoApprove lists commands that run without asking. A command matches
+	// an entry equal to it or followed by arguments, e.g. "go test" matches
+	// "go test ./...". Commands chaining others with shell operators are
+	// never auto-approved.
+	AutoApprove []string `json:"auto_approve,omitempty"`
+}
+
+var shellOperatorRegex = regexp.MustCompile("[;&|<>`\\n]|\\$\\(")
+
+// AutoApproves returns the entry that lets command run without asking.
+func (a *CommandApproval) AutoApproves(command string) (string, bool) {
+	command = strings.TrimSpace(command)
+	if a == nil || command == "" || shellOperatorRegex.MatchString(command) {
+		return "", false
+	}
+	for _, prefix := range a.AutoApprove {
+		prefix = strings.TrimSpace(prefix)
+		if prefix != "" && (command == prefix || strings.HasPrefix(command, prefix+" ")) {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+func (a *CommandApproval) clone() *CommandApproval {
+	if a == nil {
+		return nil
+	}
+	return &CommandApproval{AutoApprove: slices.Clone(a.AutoApprove)}
+}
+
+// CommandRecord is one shell command in a session's command history: what
+// ran, who let it run, and how it ended.
+type CommandRecord struct {
+	ID string `json:"id"`
+	// ToolCallID is the provider's ID for the tool call running the command.
+	ToolCallID string        `json:"tool_call_id,omitempty"`
+	Command    string        `json:"command"`
+	Status     CommandStatus `json:"status"`
+	ProposedAt time.Time     `json:"proposed_at"`
+	// Approval is empty for commands that ran without being proposed.
+	Approval  CommandApprovalDecision `json:"approval,omitempty"`
+	DecidedBy string                  `json:"decided_by,omitempty"`
+	DecidedAt *time.Time              `json:"decided_at,omitempty"`
+	// Reason explains the decision: the matching auto-approve entry or the
+	// rejection message given to the agent.
+	Reason      string     `json:"reason,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// ExitCode is set once the command finished, when it is known.
+	ExitCode   *int  `json:"exit_code,omitempty"`
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
+// Pending reports whether the command is waiting for a decision.
+func (c CommandRecord) Pending() bool {
+	return c.Status == CommandStatusProposed
+}
+
+var exitCodeRegex = regexp.MustCompile(`(?i)exit (?:code|status):? (-?\d+)`)
+
+// CommandExitCode returns the exit code of a finished command: 0 when it
+// succeeded, otherwise the code its tool output reports, if any.
+func CommandExitCode(failed bool, output any) *int {
+	if !failed {
+		code := 0
+		return &code
+	}
+	text, _ := output.(string)
+	m := exitCodeRegex.FindStringSubmatch(text)
+	if m == nil {
+		return nil
+	}
+	code, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil
+	}
+	return &code
+}
+
+// ToolCommand returns the shell command a tool call runs, if it is one.
+func ToolCommand(name string, input any) (string, bool) {
+	if !slices.Contains(CommandTools, name) {
+		return "", false
+	}
+	fields, _ := input.(map[string]any)
+	command, _ := fields["command"].(string)
+	return command, command != ""
+}
+
+func (s *Session) SetCommandApproval(approval *CommandApproval) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CommandApproval = approval.clone()
+	s.UpdatedAt = time.Now()
+}
+
+// GetCommandApproval returns a copy of the session's command approval
+// policy, or nil when commands run without approval.
+func (s *Session) GetCommandApproval() *CommandApproval {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.CommandApproval.clone()
+}
+
+// GetCommands returns a copy of the session's command history, oldest
+// first.
+func (s *Session) GetCommands() []CommandRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]CommandRecord, len(s.Commands))
+	for i, c := range s.Commands {
+		out[i] = c.clone()
+	}
+	return out
+}
+
+// GetCommand returns the command with the given ID.
+func (s *Session) GetCommand(id string) (CommandRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := s.commandIndex(id, ""); i >= 0 {
+		return s.Commands[i].clone(), true
+	}
+	return CommandRecord{}, false
+}
+
+// UpdateCommand applies update to the command with the given ID, or to the
+// one run by toolCallID when id is empty, and returns the result. When no
+// command matches and create is set, a new record from create is added
+// first.
+func (s *Session) UpdateCommand(id, toolCallID string, create *CommandRecord, update func(*CommandRecord)) (CommandRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.commandIndex(id, toolCallID)
+	if i < 0 {
+		if create == nil {
+			return CommandRecord{}, false
+		}
+		s.Commands = append(s.Commands, create.clone())
+		if over := len(s.Commands) - MaxSessionCommands; over > 0 {
+			s.Commands = slices.Delete(s.Commands, 0, over)
+		}
+		i = len(s.Commands) - 1
+	}
+	if update != nil {
+		update(&s.Commands[i])
+	}
+	s.UpdatedAt = time.Now()
+	return s.Commands[i].clone(), true
+}
+
+// commandIndex must be called with s.mu held. It searches newest first.
+func (s *Session) commandIndex(id, toolCallID string) int {
+	for i := len(s.Commands) - 1; i >= 0; i-- {
+		c := s.Commands[i]
+		if (id != "" && c.ID == id) || (id == "" && toolCallID != "" && c.ToolCallID == toolCallID) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c CommandRecord) clone() CommandRecord {
+	for _, p := range []**time.Time{&c.DecidedAt, &c.StartedAt, &c.CompletedAt} {
+		if *p != nil {
+			t := **p
+			*p = &t
+		}
+	}
+	if c.ExitCode != nil {
+		code := *c.ExitCode
+		c.ExitCode = &code
+	}
+	return c
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "testing"
+
+func TestCommandApproval_AutoApproves(t *testing.T) {
+	approval := &CommandApproval{AutoApprove: []string{"go test", "git status"}}
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"go test", true},
+		{"go test ./...", true},
+		{"git status --short", true},
+		{"go testify", false},
+		{"go test ./... && rm -rf /", false},
+		{"go test $(cat pkgs)", false},
+		{"go test > out.txt", false},
+		{"rm -rf build", false},
+	}
+	for _, tt := range tests {
+		if _, got := approval.AutoApproves(tt.command); got != tt.want {
+			t.Errorf("AutoApproves(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+	if _, ok := (*CommandApproval)(nil).AutoApproves("go test"); ok {
+		t.Error("a nil policy should not auto-approve")
+	}
+}
+
+func TestCommandExitCode(t *testing.T) {
+	if code := CommandExitCode(false, nil); code == nil || *code != 0 {
+		t.Fatalf("success exit code = %v, want 0", code)
+	}
+	if code := CommandExitCode(true, "Exit code 127\nsh: foo: not found"); code == nil || *code != 127 {
+		t.Fatalf("failure exit code = %v, want 127", code)
+	}
+	if code := CommandExitCode(true, "permission denied"); code != nil {
+		t.Fatalf("unknown exit code = %v, want nil", *code)
+	}
+}
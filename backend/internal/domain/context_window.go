@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ContextWindowWarnFraction is the share of its context window a session's
+// conversation can fill before it is reported as nearing the limit, where
+// providers start compacting or dropping context.
+const ContextWindowWarnFraction = 0.8
+
+// modelContextWindows maps model name prefixes to the context window of the
+// models, in tokens. The longest matching prefix wins.
+var modelContextWindows = map[string]int64{
+	"claude-":          200_000,
+	"sonnet":           200_000,
+	"opus":             200_000,
+	"haiku":            200_000,
+	"gpt-4o":           128_000,
+	"gpt-4.1":          1_047_576,
+	"gpt-5":            400_000,
+	"o1":               200_000,
+	"o3":               200_000,
+	"o4-mini":          200_000,
+	"gemini-1.5-pro":   2_097_152,
+	"gemini-1.5-flash": 1_048_576,
+	"gemini-2":         1_048_576,
+	"gemini-3":         1_048_576,
+}
+
+// ModelContextWindow returns the context window of model in tokens, or 0
+// when the model is unknown. Claude models with a "[1m]" suffix use the
+// extended one-million-token window.
+func ModelContextWindow(model string) int64 {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if strings.HasSuffix(model, "[1m]") {
+		return 1_000_000
+	}
+	var window int64
+	longest := -1
+	for prefix, w := range modelContextWindows {
+		if strings.HasPrefix(model, prefix) && len(prefix) > longest {
+			window, longest = w, len(prefix)
+		}
+	}
+	return window
+}
+
+// ContextWindowUsage is how much of its model's context window a session's
+// latest request filled.
+type ContextWindowUsage struct {
+	Model string `json:"model,omitempty"`
+	// UsedTokens is the latest request's input, including cached input, and
+	// output.
+	UsedTokens int64 `json:"used_tokens"`
+	// LimitTokens is the model's context window, 0 when it is unknown.
+	LimitTokens int64     `json:"limit_tokens,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+// Fraction returns the share of the context window in use, or 0 when the
+// window is unknown.
+func (u ContextWindowUsage) Fraction() float64 {
+	if u.LimitTokens <= 0 {
+		return 0
+	}
+	return float64(u.UsedTokens) / float64(u.LimitTokens)
+}
+
+// NearLimit reports whether the usage has reached ContextWindowWarnFraction.
+func (u ContextWindowUsage) NearLimit() bool {
+	return u.Fraction() >= ContextWindowWarnFraction
+}
+
+// SetContextModel records the model a session's runs use, which decides its
+// context window.
+func (s *Session) SetContextModel(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ContextWindow == nil {
+		s.ContextWindow = &ContextWindowUsage{}
+	}
+	s.ContextWindow.Model = model
+	s.ContextWindow.LimitTokens = ModelContextWindow(model)
+}
+
+// RecordContextUsage records the tokens the latest request filled of the
+// context window and returns the updated usage along with the usage before.
+func (s *Session) RecordContextUsage(usedTokens int64, now time.Time) (before, after ContextWindowUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ContextWindow == nil {
+		s.ContextWindow = &ContextWindowUsage{}
+	}
+	before = *s.ContextWindow
+	s.ContextWindow.UsedTokens = usedTokens
+	s.ContextWindow.UpdatedAt = now
+	return before, *s.ContextWindow
+}
+
+// GetContextWindow returns the session's context window usage, if any
+// request reported it.
+func (s *Session) GetContextWindow() *ContextWindowUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.ContextWindow == nil {
+		return nil
+	}
+	u := *s.ContextWindow
+	return &u
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestModelContextWindow(t *testing.T) {
+	cases := map[string]int64{
+		"claude-sonnet-4-5-20250929": 200_000,
+		"claude-opus-4-1[1m]":        1_000_000,
+		"gpt-4o-mini":                128_000,
+		"gpt-4.1":                    1_047_576,
+		"gemini-2.5-flash":           1_048_576,
+		"gemini-1.5-pro-002":         2_097_152,
+		"llama-3":                    0,
+	}
+	for model, want := range cases {
+		if got := ModelContextWindow(model); got != want {
+			t.Errorf("ModelContextWindow(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestContextWindowUsage_NearLimit(t *testing.T) {
+	u := ContextWindowUsage{UsedTokens: 150_000, LimitTokens: 200_000}
+	if u.Fraction() != 0.75 || u.NearLimit() {
+		t.Fatalf("fraction = %v, near limit = %v", u.Fraction(), u.NearLimit())
+	}
+	u.UsedTokens = 160_000
+	if !u.NearLimit() {
+		t.Fatal("80% of the window should be near the limit")
+	}
+	if (ContextWindowUsage{UsedTokens: 1_000_000}).NearLimit() {
+		t.Fatal("an unknown window is never near its limit")
+	}
+}
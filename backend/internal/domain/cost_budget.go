@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Cost budget actions: what happens to a run once its session's or
+// project's cost budget is used up.
+const (
+	// CostBudgetSuspend stops the run and suspends the session until the
+	// budget is raised.
+	CostBudgetSuspend = "suspend"
+	// CostBudgetCancel cancels the run and leaves the session idle.
+	CostBudgetCancel = "cancel"
+)
+
+// WaitKindCostBudget marks a run suspended because its session or project
+// used up its cost budget. The run resumes when the budget is raised.
+const WaitKindCostBudget = "cost_budget"
+
+// CostBudget limits the tokens or dollars a session or project may spend.
+// A zero limit is not enforced; once either is reached no new runs start and
+// the running ones are suspended or cancelled, by Action.
+type CostBudget struct {
+	LimitUSD    float64 `json:"limit_usd,omitempty"`
+	LimitTokens int64   `json:"limit_tokens,omitempty"`
+	// Action is CostBudgetSuspend (the default) or CostBudgetCancel.
+	Action string `json:"action,omitempty"`
+}
+
+// Validate reports whether the budget sets a limit and a known action. A nil
+// budget is valid.
+func (b *CostBudget) Validate() error {
+	if b == nil {
+		return nil
+	}
+	if b.LimitUSD < 0 || b.LimitTokens < 0 {
+		return errors.New("cost budget limits must not be negative")
+	}
+	if b.LimitUSD == 0 && b.LimitTokens == 0 {
+		return errors.New("cost budget needs limit_usd or limit_tokens")
+	}
+	if b.Action != "" && b.Action != CostBudgetSuspend && b.Action != CostBudgetCancel {
+		return fmt.Errorf("unknown cost budget action %q", b.Action)
+	}
+	return nil
+}
+
+// Exceeded reports whether usage has reached one of the budget's limits, and
+// describes the limit reached.
+func (b *CostBudget) Exceeded(usage UsageStats) (string, bool) {
+	if b == nil {
+		return "", false
+	}
+	if b.LimitUSD > 0 && usage.CostUSD >= b.LimitUSD {
+		return "$" + strconv.FormatFloat(b.LimitUSD, 'f', 2, 64), true
+	}
+	if b.LimitTokens > 0 && usage.InputTokens+usage.OutputTokens >= b.LimitTokens {
+		return strconv.FormatInt(b.LimitTokens, 10) + " tokens", true
+	}
+	return "", false
+}
+
+// SuspendsRuns reports whether runs over the budget are suspended rather
+// than cancelled.
+func (b *CostBudget) SuspendsRuns() bool {
+	return b.Action != CostBudgetCancel
+}
+
+func (b *CostBudget) clone() *CostBudget {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	return &c
+}
+
+// SetCostBudget sets the session's own cost budget; nil removes it.
+func (s *Session) SetCostBudget(budget *CostBudget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.CostBudget = budget.clone()
+	s.UpdatedAt = time.Now()
+}
+
+// GetCostBudget returns a copy of the session's own cost budget, or nil.
+func (s *Session) GetCostBudget() *CostBudget {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.CostBudget.clone()
+}
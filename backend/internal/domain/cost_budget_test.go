@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestCostBudget_Exceeded(t *testing.T) {
+	var none *CostBudget
+	if _, ok := none.Exceeded(UsageStats{CostUSD: 100}); ok || none.Validate() != nil {
+		t.Fatal("a nil budget is valid and never exceeded")
+	}
+
+	b := &CostBudget{LimitUSD: 2, LimitTokens: 1000}
+	if err := b.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if _, ok := b.Exceeded(UsageStats{InputTokens: 600, OutputTokens: 300, CostUSD: 1.99}); ok {
+		t.Fatal("usage below both limits must not exceed the budget")
+	}
+	if limit, ok := b.Exceeded(UsageStats{CostUSD: 2}); !ok || limit != "$2.00" {
+		t.Fatalf("Exceeded = %q, %v, want the dollar limit", limit, ok)
+	}
+	if limit, ok := b.Exceeded(UsageStats{InputTokens: 700, OutputTokens: 300}); !ok || limit != "1000 tokens" {
+		t.Fatalf("Exceeded = %q, %v, want the token limit", limit, ok)
+	}
+	if !b.SuspendsRuns() || (&CostBudget{LimitUSD: 1, Action: CostBudgetCancel}).SuspendsRuns() {
+		t.Fatal("budgets suspend runs unless they cancel them")
+	}
+
+	for _, bad := range []CostBudget{{}, {LimitUSD: -1}, {LimitTokens: 10, Action: "pause"}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", bad)
+		}
+	}
+}
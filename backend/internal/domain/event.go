@@ -16,6 +16,8 @@ const (
 	EventTypeToolCall // Structured tool call information
 	EventTypeThought  // Agent reasoning/thinking
 	EventTypePlan     // Agent execution plans
+	EventTypeProgress // Estimated run progress
+	EventTypeLiveness // Periodic sign of life for a running session
 )
 
 func (t EventType) String() string {
@@ -36,13 +38,31 @@ func (t EventType) String() string {
 		return "thought"
 	case EventTypePlan:
 		return "plan"
+	case EventTypeProgress:
+		return "progress"
+	case EventTypeLiveness:
+		return "liveness"
 	default:
 		return "unknown"
 	}
 }
 
+// ParseEventType returns the event type named name, as String spells it.
+func ParseEventType(name string) (EventType, bool) {
+	for t := EventTypeStatusChange; t <= EventTypeLiveness; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
 type Event struct {
-	ID        int64
+	ID int64
+	// Seq is the event's offset in its session's event journal, numbering
+	// the session's events from 1 across restarts; zero when the event was
+	// not journaled.
+	Seq       int64
 	Type      EventType
 	Timestamp time.Time
 	SessionID string
@@ -55,6 +75,8 @@ type StatusChangeData struct {
 	OldState SessionState
 	NewState SessionState
 	Reason   string
+	// Notice is the reason as a code, when it has one.
+	Notice *Notice
 }
 
 func (e Event) StatusChange() (StatusChangeData, bool) {
@@ -97,6 +119,16 @@ func (e Event) Plan() (PlanData, bool) {
 	return d, ok
 }
 
+func (e Event) Progress() (ProgressData, bool) {
+	d, ok := e.Data.(ProgressData)
+	return d, ok
+}
+
+func (e Event) Liveness() (LivenessData, bool) {
+	d, ok := e.Data.(LivenessData)
+	return d, ok
+}
+
 func NewStatusChangeEvent(sessionID string, oldState, newState SessionState, reason string, raw json.RawMessage) Event {
 	return Event{
 		Type:      EventTypeStatusChange,
@@ -111,6 +143,16 @@ func NewStatusChangeEvent(sessionID string, oldState, newState SessionState, rea
 	}
 }
 
+// NewNoticeStatusChangeEvent is NewStatusChangeEvent with the notice's text
+// as the reason.
+func NewNoticeStatusChangeEvent(sessionID string, oldState, newState SessionState, notice Notice) Event {
+	event := NewStatusChangeEvent(sessionID, oldState, newState, notice.Text(), nil)
+	data := event.Data.(StatusChangeData)
+	data.Notice = &notice
+	event.Data = data
+	return event
+}
+
 type OutputData struct {
 	Content string
 	IsDelta bool // If true, this content should be appended to the previous message in storage
@@ -120,6 +162,12 @@ type MetricData struct {
 	TokensIn     int64
 	TokensOut    int64
 	RequestCount int64
+	// CacheReadTokens and CacheCreationTokens are the prompt-cache portions
+	// of a request's input, for providers that report them.
+	CacheReadTokens     int64
+	CacheCreationTokens int64
+	// CostUSD is the cost of the usage, for providers that report it.
+	CostUSD float64
 }
 
 type ErrorData struct {
@@ -139,6 +187,16 @@ type ToolCallData struct {
 	Title  string
 	Input  any
 	Output any
+	// ParentID is the tool call that spawned this one, e.g. the Task call of
+	// the subagent that issued it. Empty for top-level calls.
+	ParentID string
+	// Usage holds the token totals of a subagent tool call once it finishes.
+	Usage *TokenUsage
+}
+
+type TokenUsage struct {
+	InputTokens  int64
+	OutputTokens int64
 }
 
 type ThoughtData struct {
@@ -156,6 +214,22 @@ type PlanStep struct {
 	Status      string
 }
 
+// ProgressData is a coarse, executor-derived estimate of how far a run has
+// got. Percent is 0-100; Phase is a short human-readable label.
+type ProgressData struct {
+	Percent int
+	Phase   string
+}
+
+// LivenessData is a running session's recent provider activity, published
+// periodically so clients can tell a thinking agent from a hung one.
+type LivenessData struct {
+	// LastActivityAt is when the provider last emitted an event, or when the
+	// run started if it has not emitted any yet.
+	LastActivityAt   time.Time
+	TokensLastMinute int64
+}
+
 func NewOutputEvent(sessionID, content string, raw json.RawMessage) Event {
 	return Event{
 		Type:      EventTypeOutput,
@@ -191,6 +265,18 @@ func NewMetricEvent(sessionID string, tokensIn, tokensOut, requestCount int64, r
 	}
 }
 
+// NewMetricDataEvent creates a metric event from fully populated data,
+// including prompt cache usage.
+func NewMetricDataEvent(sessionID string, data MetricData, raw json.RawMessage) Event {
+	return Event{
+		Type:      EventTypeMetric,
+		Timestamp: time.Now(),
+		SessionID: sessionID,
+		Raw:       raw,
+		Data:      data,
+	}
+}
+
 func NewErrorEvent(sessionID, message, code string, raw json.RawMessage) Event {
 	return Event{
 		Type:      EventTypeError,
@@ -217,6 +303,28 @@ func NewMetadataEvent(sessionID, key string, value any, raw json.RawMessage) Eve
 	}
 }
 
+// MetadataKeyStderr is the metadata key of provider stderr output. Its value
+// is the text read, line endings included.
+const MetadataKeyStderr = "stderr"
+
+// MetadataKeyConversationID is the metadata key providers report their own
+// conversation ID under, so the session's next run can continue it (see
+// session.Config ConversationID). Its value is the ID as a string.
+const MetadataKeyConversationID = "conversation_id"
+
+// MetadataKeyModel is the metadata key of the model a run uses. Its value is
+// the model name as a string.
+const MetadataKeyModel = "model"
+
+// MetadataKeyContextWindow is the metadata key of a session's context window
+// usage, published as each request reports its token usage.
+const MetadataKeyContextWindow = "context_window"
+
+// NewStderrEvent reports output the provider process wrote to stderr.
+func NewStderrEvent(sessionID, text string) Event {
+	return NewMetadataEvent(sessionID, MetadataKeyStderr, text, nil)
+}
+
 func NewToolCallEvent(sessionID string, data ToolCallData, raw json.RawMessage) Event {
 	return Event{
 		Type:      EventTypeToolCall,
@@ -246,3 +354,21 @@ func NewPlanEvent(sessionID string, data PlanData, raw json.RawMessage) Event {
 		Data:      data,
 	}
 }
+
+func NewProgressEvent(sessionID string, data ProgressData) Event {
+	return Event{
+		Type:      EventTypeProgress,
+		Timestamp: time.Now(),
+		SessionID: sessionID,
+		Data:      data,
+	}
+}
+
+func NewLivenessEvent(sessionID string, data LivenessData) Event {
+	return Event{
+		Type:      EventTypeLiveness,
+		Timestamp: time.Now(),
+		SessionID: sessionID,
+		Data:      data,
+	}
+}
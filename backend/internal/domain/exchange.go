@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"time"
+)
+
+// Limits on a session's exchange area. It holds small structured results,
+// not files.
+const (
+	MaxExchangeEntries   = 64
+	MaxExchangeValueSize = 64 << 10
+)
+
+var (
+	ErrInvalidExchangeKey   = errors.New("invalid exchange key")
+	ErrInvalidExchangeValue = errors.New("invalid exchange value")
+	ErrExchangeFull         = errors.New("exchange area is full")
+)
+
+var exchangeKeyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// ExchangeEntry is one value in a session's exchange area: a key-value
+// store that agents and humans write to hand structured results, such as a
+// JSON report, out of a session without parsing the transcript.
+type ExchangeEntry struct {
+	Value     json.RawMessage `json:"value"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	// UpdatedBy is "agent" for writes from the session's agent, otherwise
+	// the user who wrote the value.
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+// SetExchangeEntry stores a JSON value under key, replacing any previous
+// value.
+func (s *Session) SetExchangeEntry(key string, value json.RawMessage, updatedBy string) (ExchangeEntry, error) {
+	if !exchangeKeyRegex.MatchString(key) {
+		return ExchangeEntry{}, fmt.Errorf("%w: %q", ErrInvalidExchangeKey, key)
+	}
+	if len(value) > MaxExchangeValueSize {
+		return ExchangeEntry{}, fmt.Errorf("%w: %d bytes exceeds %d", ErrInvalidExchangeValue, len(value), MaxExchangeValueSize)
+	}
+	if len(value) == 0 || !json.Valid(value) {
+		return ExchangeEntry{}, fmt.Errorf("%w: not valid JSON", ErrInvalidExchangeValue)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.Exchange[key]; !exists && len(s.Exchange) >= MaxExchangeEntries {
+		return ExchangeEntry{}, fmt.Errorf("%w: %d entries", ErrExchangeFull, MaxExchangeEntries)
+	}
+	if s.Exchange == nil {
+		s.Exchange = make(map[string]ExchangeEntry)
+	}
+	entry := ExchangeEntry{
+		Value:     append(json.RawMessage(nil), value...),
+		UpdatedAt: time.Now(),
+		UpdatedBy: updatedBy,
+	}
+	s.Exchange[key] = entry
+	s.UpdatedAt = entry.UpdatedAt
+	return entry, nil
+}
+
+// DeleteExchangeEntry removes key and reports whether it was set.
+func (s *Session) DeleteExchangeEntry(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Exchange[key]; !ok {
+		return false
+	}
+	delete(s.Exchange, key)
+	s.UpdatedAt = time.Now()
+	return true
+}
+
+// ExchangeEntries returns a copy of the session's exchange area.
+func (s *Session) ExchangeEntries() map[string]ExchangeEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.Exchange)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// MetadataKeyGitCommit is the metadata key of a commit made on a session's
+// tracked branch. Its value holds the commit's "sha", "message" and
+// "branch".
+const MetadataKeyGitCommit = "git_commit"
+
+// GitTracking is the git branch a session works on and the commits made on
+// it, so reviewers can see exactly what the session changed.
+type GitTracking struct {
+	Branch string `json:"branch"`
+	// BaseRef is the commit the branch started from; the session's
+	// commits are those after it.
+	BaseRef string          `json:"base_ref"`
+	Commits []TrackedCommit `json:"commits,omitempty"`
+}
+
+// TrackedCommit is a commit made on a session's tracked branch.
+type TrackedCommit struct {
+	SHA     string    `json:"sha"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+func (g *GitTracking) clone() *GitTracking {
+	if g == nil {
+		return nil
+	}
+	out := *g
+	out.Commits = slices.Clone(g.Commits)
+	return &out
+}
+
+// GetGitTracking returns a copy of the session's git tracking, if any.
+func (s *Session) GetGitTracking() *GitTracking {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.GitTracking.clone()
+}
+
+// SetGitTracking replaces the session's git tracking.
+func (s *Session) SetGitTracking(g *GitTracking) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.GitTracking = g.clone()
+	s.UpdatedAt = time.Now()
+}
+
+// AddTrackedCommits records the commits the session's git tracking has not
+// seen yet and returns them, oldest first as given.
+func (s *Session) AddTrackedCommits(commits []TrackedCommit) []TrackedCommit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.GitTracking == nil {
+		return nil
+	}
+	var added []TrackedCommit
+	for _, c := range commits {
+		if slices.ContainsFunc(s.GitTracking.Commits, func(seen TrackedCommit) bool { return seen.SHA == c.SHA }) {
+			continue
+		}
+		s.GitTracking.Commits = append(s.GitTracking.Commits, c)
+		added = append(added, c)
+	}
+	if len(added) > 0 {
+		s.UpdatedAt = time.Now()
+	}
+	return added
+}
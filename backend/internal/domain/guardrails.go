@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GuardrailAction is what happens to agent output that matches a guardrail
+// rule.
+type GuardrailAction string
+
+const (
+	// GuardrailOff ignores matches.
+	GuardrailOff GuardrailAction = "off"
+	// GuardrailFlag keeps the output and quarantines a copy for review.
+	GuardrailFlag GuardrailAction = "flag"
+	// GuardrailBlock replaces the output with GuardrailBlockedTombstone
+	// before it is persisted or broadcast and quarantines the original.
+	GuardrailBlock GuardrailAction = "block"
+)
+
+// Guardrail categories. Built-in rules cover secrets and PII; custom rules
+// and classifiers may use any category.
+const (
+	GuardrailCategorySecret  = "secret"
+	GuardrailCategoryPII     = "pii"
+	GuardrailCategoryContent = "content"
+)
+
+// GuardrailBlockedTombstone replaces blocked output.
+const GuardrailBlockedTombstone = "[blocked by guardrail]"
+
+// GuardrailRule is a custom pattern checked in addition to the built-in
+// rules. An empty Action uses the action of the rule's category.
+type GuardrailRule struct {
+	Name     string          `json:"name"`
+	Category string          `json:"category,omitempty"`
+	Pattern  string          `json:"pattern"`
+	Action   GuardrailAction `json:"action,omitempty"`
+}
+
+// GuardrailPolicy configures output scanning. Actions maps a category to its
+// action; categories it leaves out fall back to the server's policy.
+type GuardrailPolicy struct {
+	Actions map[string]GuardrailAction `json:"actions,omitempty"`
+	Rules   []GuardrailRule            `json:"rules,omitempty"`
+}
+
+// ParseGuardrailAction validates an action name. The empty string is
+// allowed and means "use the default".
+func ParseGuardrailAction(s string) (GuardrailAction, error) {
+	switch a := GuardrailAction(strings.TrimSpace(s)); a {
+	case "", GuardrailOff, GuardrailFlag, GuardrailBlock:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown guardrail action %q (want %s, %s or %s)", s, GuardrailOff, GuardrailFlag, GuardrailBlock)
+	}
+}
+
+// Validate checks the policy's actions and compiles its rule patterns.
+func (p *GuardrailPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	for category, action := range p.Actions {
+		if strings.TrimSpace(category) == "" {
+			return fmt.Errorf("guardrail action for an empty category")
+		}
+		if _, err := ParseGuardrailAction(string(action)); err != nil {
+			return err
+		}
+	}
+	for _, rule := range p.Rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("guardrail rule name is required")
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil || rule.Pattern == "" {
+			return fmt.Errorf("guardrail rule %q: invalid pattern %q", rule.Name, rule.Pattern)
+		}
+		if _, err := ParseGuardrailAction(string(rule.Action)); err != nil {
+			return fmt.Errorf("guardrail rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
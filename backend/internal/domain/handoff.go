@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Handoff statuses.
+const (
+	HandoffSummarizing = "summarizing"
+	HandoffStarted     = "started"
+	HandoffFailed      = "failed"
+)
+
+// Handoff moves a session to another provider. The current provider first
+// summarizes its working context into a HandoffDocument; the next run, on
+// the new provider, starts from that document instead of a replay of the
+// whole history.
+type Handoff struct {
+	Status           string           `json:"status"`
+	FromProviderType string           `json:"from_provider_type"`
+	FromProviderID   string           `json:"from_provider_id,omitempty"`
+	ToProviderType   string           `json:"to_provider_type"`
+	ToProviderID     string           `json:"to_provider_id,omitempty"`
+	Instructions     string           `json:"instructions,omitempty"`
+	Document         *HandoffDocument `json:"document,omitempty"`
+	RequestedBy      string           `json:"requested_by,omitempty"`
+	RequestedAt      time.Time        `json:"requested_at"`
+	CompletedAt      *time.Time       `json:"completed_at,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
+
+// HandoffDocument is a provider's summary of its working context.
+type HandoffDocument struct {
+	Summary       string   `json:"summary"`
+	OpenQuestions []string `json:"open_questions,omitempty"`
+	Decisions     []string `json:"decisions,omitempty"`
+	Files         []string `json:"files,omitempty"`
+	NextSteps     []string `json:"next_steps,omitempty"`
+}
+
+// handoffSection is a heading a handoff summary is asked to use, with the
+// document list it fills. Summary has no list.
+type handoffSection struct {
+	heading string
+	list    func(d *HandoffDocument) *[]string
+}
+
+var handoffSections = []handoffSection{
+	{"Summary", nil},
+	{"Open questions", func(d *HandoffDocument) *[]string { return &d.OpenQuestions }},
+	{"Decisions", func(d *HandoffDocument) *[]string { return &d.Decisions }},
+	{"Files", func(d *HandoffDocument) *[]string { return &d.Files }},
+	{"Next steps", func(d *HandoffDocument) *[]string { return &d.NextSteps }},
+}
+
+// HandoffSummaryPrompt asks the current provider for its working context in
+// the format ParseHandoffDocument reads.
+func HandoffSummaryPrompt() string {
+	var b strings.Builder
+	b.WriteString("Another agent is about to take over this session. Do not make further changes. ")
+	writeHandoffRequest(&b)
+	return b.String()
+}
+
+// writeHandoffRequest asks for the working context in the sections
+// ParseHandoffDocument reads.
+func writeHandoffRequest(b *strings.Builder) {
+	b.WriteString("Summarize your working context for it in markdown with exactly these sections, ")
+	b.WriteString("using bullet points under each section except Summary:\n\n")
+	for _, s := range handoffSections {
+		b.WriteString("## " + s.heading + "\n")
+	}
+	b.WriteString("\nList open questions you could not resolve, decisions you made and why, ")
+	b.WriteString("the files you read or changed, and what should happen next.")
+}
+
+// ParseHandoffDocument reads a summary written in response to
+// HandoffSummaryPrompt. Text before the first known heading, or all of it
+// when there are none, becomes the summary.
+func ParseHandoffDocument(text string) HandoffDocument {
+	var doc HandoffDocument
+	var summary []string
+	var list *[]string
+	inSummary := true
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(trimmed, "#"); ok {
+			heading = strings.TrimSpace(strings.TrimLeft(heading, "#"))
+			i := slices.IndexFunc(handoffSections, func(s handoffSection) bool {
+				return strings.EqualFold(s.heading, heading)
+			})
+			if i >= 0 {
+				inSummary = handoffSections[i].list == nil
+				list = nil
+				if !inSummary {
+					list = handoffSections[i].list(&doc)
+				}
+				continue
+			}
+		}
+		switch {
+		case inSummary:
+			summary = append(summary, line)
+		case trimmed == "":
+		case list != nil:
+			item := strings.TrimSpace(strings.TrimLeft(trimmed, "-*• "))
+			if item != "" {
+				*list = append(*list, item)
+			}
+		}
+	}
+	doc.Summary = strings.TrimSpace(strings.Join(summary, "\n"))
+	return doc
+}
+
+// Empty reports whether the document has no content.
+func (d HandoffDocument) Empty() bool {
+	return d.Summary == "" && len(d.OpenQuestions) == 0 && len(d.Decisions) == 0 && len(d.Files) == 0 && len(d.NextSteps) == 0
+}
+
+// Prompt renders the document as the first message of the run on the new
+// provider, followed by instructions when given.
+func (d HandoffDocument) Prompt(fromProvider, instructions string) string {
+	var b strings.Builder
+	b.WriteString("You are taking over this session from another agent (" + fromProvider + "). ")
+	b.WriteString("This is its handoff of the work so far.\n")
+	d.writeSections(&b)
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		b.WriteString("\n## Instructions\n" + instructions + "\n")
+	} else {
+		b.WriteString("\nContinue the work from here.\n")
+	}
+	return b.String()
+}
+
+// writeSections renders the document's non-empty sections.
+func (d HandoffDocument) writeSections(b *strings.Builder) {
+	for _, s := range handoffSections {
+		if s.list == nil {
+			if d.Summary != "" {
+				b.WriteString("\n## Summary\n" + d.Summary + "\n")
+			}
+			continue
+		}
+		items := *s.list(&d)
+		if len(items) == 0 {
+			continue
+		}
+		b.WriteString("\n## " + s.heading + "\n")
+		for _, item := range items {
+			b.WriteString("- " + item + "\n")
+		}
+	}
+}
+
+func (h *Handoff) clone() *Handoff {
+	if h == nil {
+		return nil
+	}
+	out := *h
+	if h.Document != nil {
+		doc := *h.Document
+		doc.OpenQuestions = slices.Clone(doc.OpenQuestions)
+		doc.Decisions = slices.Clone(doc.Decisions)
+		doc.Files = slices.Clone(doc.Files)
+		doc.NextSteps = slices.Clone(doc.NextSteps)
+		out.Document = &doc
+	}
+	return &out
+}
+
+// GetHandoff returns a copy of the session's latest handoff, if any.
+func (s *Session) GetHandoff() *Handoff {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Handoff.clone()
+}
+
+// SetHandoff replaces the session's latest handoff.
+func (s *Session) SetHandoff(h *Handoff) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Handoff = h.clone()
+	s.UpdatedAt = time.Now()
+}
+
+// SetProviderType switches the provider later runs use.
+func (s *Session) SetProviderType(providerType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ProviderType = providerType
+	s.UpdatedAt = time.Now()
+}
@@ -0,0 +1,14 @@
+package domain
+
+// SessionKindImported marks a session imported from another tool's log. It
+// holds the log's history but never runs: its provider conversation lives
+// elsewhere.
+const SessionKindImported = "imported"
+
+// IsImported reports whether the session was imported from a log and is
+// therefore read-only.
+func (s *Session) IsImported() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Kind == SessionKindImported
+}
@@ -0,0 +1,10 @@
+package domain
+
+// MCPServer is an MCP server started with each of a session's runs. Env
+// values are templates rendered for every run; see session.MCPEnv.
+type MCPServer struct {
+	Name    string            `json:"name"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
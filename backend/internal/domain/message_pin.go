@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Limits on a session's message pins.
+const (
+	MaxMessagePins       = 100
+	MaxMessagePinNoteLen = 1000
+)
+
+var (
+	ErrInvalidMessagePin  = errors.New("invalid message pin")
+	ErrMessagePinNotFound = errors.New("message pin not found")
+	ErrTooManyMessagePins = errors.New("too many pinned messages")
+)
+
+// MessagePin marks a message, or an excerpt of it, as a key outcome of the
+// session, such as a decision or a final answer, so it does not drown in
+// streaming output.
+type MessagePin struct {
+	ID        string      `json:"id"`
+	MessageID string      `json:"message_id"`
+	Kind      MessageKind `json:"kind"`
+	// Excerpt is the pinned part of the message's contents; all of them
+	// unless a part was chosen.
+	Excerpt   string    `json:"excerpt"`
+	Note      string    `json:"note,omitempty"`
+	MessageAt time.Time `json:"message_at"`
+	PinnedBy  string    `json:"pinned_by,omitempty"`
+	PinnedAt  time.Time `json:"pinned_at"`
+}
+
+// NewMessagePin pins msg, or excerpt when it is set. The excerpt must be
+// part of the message's contents.
+func NewMessagePin(id string, msg Message, excerpt, note, pinnedBy string, now time.Time) (MessagePin, error) {
+	if msg.Redacted {
+		return MessagePin{}, fmt.Errorf("%w: message %s is redacted", ErrInvalidMessagePin, msg.ID)
+	}
+	excerpt = strings.TrimSpace(excerpt)
+	switch {
+	case excerpt == "":
+		excerpt = msg.Contents
+	case !strings.Contains(msg.Contents, excerpt):
+		return MessagePin{}, fmt.Errorf("%w: excerpt is not part of message %s", ErrInvalidMessagePin, msg.ID)
+	}
+	note = strings.TrimSpace(note)
+	if len(note) > MaxMessagePinNoteLen {
+		return MessagePin{}, fmt.Errorf("%w: note exceeds %d bytes", ErrInvalidMessagePin, MaxMessagePinNoteLen)
+	}
+	return MessagePin{
+		ID:        id,
+		MessageID: msg.ID,
+		Kind:      msg.Kind,
+		Excerpt:   excerpt,
+		Note:      note,
+		MessageAt: msg.Timestamp,
+		PinnedBy:  pinnedBy,
+		PinnedAt:  now,
+	}, nil
+}
+
+// AddMessagePin adds pin, or returns the existing pin of the same excerpt
+// of the same message.
+func (s *Session) AddMessagePin(pin MessagePin) (MessagePin, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.MessagePins {
+		if p.MessageID == pin.MessageID && p.Excerpt == pin.Excerpt {
+			return p, nil
+		}
+	}
+	if len(s.MessagePins) >= MaxMessagePins {
+		return MessagePin{}, fmt.Errorf("%w: %d pins", ErrTooManyMessagePins, MaxMessagePins)
+	}
+	s.MessagePins = append(s.MessagePins, pin)
+	s.UpdatedAt = pin.PinnedAt
+	return pin, nil
+}
+
+// RemoveMessagePin unpins a message and reports whether the pin existed.
+func (s *Session) RemoveMessagePin(pinID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.MessagePins, func(p MessagePin) bool { return p.ID == pinID })
+	if i < 0 {
+		return false
+	}
+	s.MessagePins = slices.Delete(s.MessagePins, i, i+1)
+	s.UpdatedAt = time.Now()
+	return true
+}
+
+// GetMessagePins returns the session's pins, oldest first.
+func (s *Session) GetMessagePins() []MessagePin {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.MessagePins)
+}
+
+// RedactMessagePins replaces the excerpts of the pins of the given messages
+// with RedactedTombstone. It returns how many pins changed.
+func (s *Session) RedactMessagePins(messageIDs []string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := 0
+	for i := range s.MessagePins {
+		pin := &s.MessagePins[i]
+		if pin.Excerpt != RedactedTombstone && slices.Contains(messageIDs, pin.MessageID) {
+			pin.Excerpt = RedactedTombstone
+			changed++
+		}
+	}
+	return changed
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// MaxQueuedMessages caps the messages a session holds while it is busy.
+const MaxQueuedMessages = 50
+
+var (
+	ErrMessageQueueFull      = errors.New("message queue is full")
+	ErrQueuedMessageNotFound = errors.New("queued message not found")
+)
+
+// QueuedMessage is a message sent while the session was running or
+// suspended, held until the run ends or the suspension resolves. It keeps
+// the settings it was sent with for the run it will start.
+type QueuedMessage struct {
+	MessageID    string        `json:"message_id"`
+	Content      string        `json:"content"`
+	ProviderID   string        `json:"provider_id,omitempty"`
+	ProviderType string        `json:"provider_type,omitempty"`
+	Deadline     time.Duration `json:"deadline,omitempty"`
+	LowPriority  bool          `json:"low_priority,omitempty"`
+	TokenBudget  int64         `json:"token_budget,omitempty"`
+	BudgetAction string        `json:"budget_action,omitempty"`
+	QueuedBy     string        `json:"queued_by,omitempty"`
+	QueuedAt     time.Time     `json:"queued_at"`
+}
+
+// EnqueueMessage appends msg to the session's queue and returns its
+// 1-based position.
+func (s *Session) EnqueueMessage(msg QueuedMessage) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.QueuedMessages) >= MaxQueuedMessages {
+		return 0, fmt.Errorf("%w: %d messages", ErrMessageQueueFull, MaxQueuedMessages)
+	}
+	s.QueuedMessages = append(s.QueuedMessages, msg)
+	s.UpdatedAt = time.Now()
+	return len(s.QueuedMessages), nil
+}
+
+// PopQueuedMessage removes and returns the oldest queued message.
+func (s *Session) PopQueuedMessage() (QueuedMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.QueuedMessages) == 0 {
+		return QueuedMessage{}, false
+	}
+	msg := s.QueuedMessages[0]
+	s.QueuedMessages = slices.Delete(s.QueuedMessages, 0, 1)
+	s.UpdatedAt = time.Now()
+	return msg, true
+}
+
+// RequeueMessage puts back a popped message that could not be delivered
+// yet, ahead of the rest of the queue.
+func (s *Session) RequeueMessage(msg QueuedMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.QueuedMessages = slices.Insert(s.QueuedMessages, 0, msg)
+	s.UpdatedAt = time.Now()
+}
+
+// RemoveQueuedMessage drops a message from the queue and reports whether it
+// was queued.
+func (s *Session) RemoveQueuedMessage(messageID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.QueuedMessages, func(m QueuedMessage) bool { return m.MessageID == messageID })
+	if i < 0 {
+		return false
+	}
+	s.QueuedMessages = slices.Delete(s.QueuedMessages, i, i+1)
+	s.UpdatedAt = time.Now()
+	return true
+}
+
+// ClearQueuedMessages empties the queue and returns what it held.
+func (s *Session) ClearQueuedMessages() []QueuedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := s.QueuedMessages
+	s.QueuedMessages = nil
+	if len(dropped) > 0 {
+		s.UpdatedAt = time.Now()
+	}
+	return dropped
+}
+
+// GetQueuedMessages returns the queued messages, oldest first.
+func (s *Session) GetQueuedMessages() []QueuedMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.QueuedMessages)
+}
+
+// QueuePosition returns the 1-based queue position of a message, or 0 when
+// it is not queued.
+func (s *Session) QueuePosition(messageID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if messageID == "" {
+		return 0
+	}
+	return slices.IndexFunc(s.QueuedMessages, func(m QueuedMessage) bool { return m.MessageID == messageID }) + 1
+}
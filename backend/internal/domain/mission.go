@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Limits on a mission's log, which keeps its latest entries.
+const (
+	MaxMissionLogEntries = 500
+	MaxMissionLogText    = 16 << 10
+)
+
+// Mission statuses, derived from the states of the mission's sessions.
+const (
+	// MissionStatusPending is a mission with no sessions yet.
+	MissionStatusPending = "pending"
+	// MissionStatusRunning is a mission with at least one running session.
+	MissionStatusRunning = "running"
+	// MissionStatusWaiting is a mission whose sessions are all idle or
+	// suspended, at least one of them suspended.
+	MissionStatusWaiting = "waiting"
+	// MissionStatusIdle is a mission whose sessions are all idle.
+	MissionStatusIdle = "idle"
+)
+
+// Mission groups the sessions of a team of agents working toward one goal.
+// The sessions share the mission's log and spend from its cost budget
+// together. A session belongs to at most one mission.
+type Mission struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Goal      string `json:"goal,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	// CostBudget limits what the mission's sessions may spend together,
+	// counted from when they joined.
+	CostBudget *CostBudget `json:"cost_budget,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	// Log is the mission's shared log, oldest first, up to
+	// MaxMissionLogEntries.
+	Log []MissionLogEntry `json:"log,omitempty"`
+}
+
+// MissionLogEntry is one entry of a mission's shared log.
+type MissionLogEntry struct {
+	At time.Time `json:"at"`
+	// Author is "agent" for entries a session's agent wrote, "system" for
+	// membership changes, otherwise the user who wrote it.
+	Author string `json:"author"`
+	// SessionID is the session the entry is about or came from, if any.
+	SessionID string `json:"session_id,omitempty"`
+	Text      string `json:"text"`
+}
+
+// Validate checks the mission's name and cost budget.
+func (m *Mission) Validate() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("mission name is required")
+	}
+	return m.CostBudget.Validate()
+}
+
+// AddLog appends an entry to the mission's log, dropping the oldest beyond
+// MaxMissionLogEntries.
+func (m *Mission) AddLog(entry MissionLogEntry) error {
+	if strings.TrimSpace(entry.Text) == "" {
+		return fmt.Errorf("mission log text is required")
+	}
+	if len(entry.Text) > MaxMissionLogText {
+		return fmt.Errorf("mission log text of %d bytes exceeds %d", len(entry.Text), MaxMissionLogText)
+	}
+	m.Log = append(m.Log, entry)
+	if extra := len(m.Log) - MaxMissionLogEntries; extra > 0 {
+		m.Log = append([]MissionLogEntry(nil), m.Log[extra:]...)
+	}
+	return nil
+}
+
+// DeriveMissionStatus derives a mission's status from the states of its
+// sessions.
+func DeriveMissionStatus(states []SessionState) string {
+	if len(states) == 0 {
+		return MissionStatusPending
+	}
+	status := MissionStatusIdle
+	for _, s := range states {
+		switch s {
+		case SessionStateRunning:
+			return MissionStatusRunning
+		case SessionStateSuspended:
+			status = MissionStatusWaiting
+		}
+	}
+	return status
+}
+
+// SetMissionID moves the session into a mission; "" removes it from its
+// mission.
+func (s *Session) SetMissionID(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MissionID = id
+	s.UpdatedAt = time.Now()
+}
+
+// GetMissionID returns the ID of the session's mission, if any.
+func (s *Session) GetMissionID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.MissionID
+}
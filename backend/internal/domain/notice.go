@@ -0,0 +1,342 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Notice is a system message or status reason as a stable code with
+// parameters, so frontends can localize and restyle it without parsing the
+// English text. Text renders the English fallback that is stored alongside
+// it as the message contents or transition reason.
+type Notice struct {
+	Code   string            `json:"code"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Status reason codes. Reasons of suspended sessions start with their wait
+// kind, as "<wait kind>: <detail>".
+const (
+	NoticeStatusStarted             = "status.started"
+	NoticeStatusRunCompleted        = "status.run_completed"
+	NoticeStatusStopped             = "status.stopped"
+	NoticeStatusKilled              = "status.killed"
+	NoticeStatusCancelled           = "status.cancelled"
+	NoticeStatusResumed             = "status.resumed"
+	NoticeStatusNoContinuation      = "status.continuation_unavailable"
+	NoticeStatusQuestionAnswered    = "status.question_answered"
+	NoticeStatusPlanApproved        = "status.plan_approved"
+	NoticeStatusRecovery            = "status.recovery"
+	NoticeStatusTimeboxed           = "status.timeboxed"
+	NoticeStatusBatchSubmitted      = "status.batch_submitted"
+	NoticeStatusBatchCompleted      = "status.batch_completed"
+	NoticeStatusWorkingHoursStarted = "status.working_hours_started"
+	NoticeStatusCommandDecided      = "status.command_decided"
+	NoticeStatusBudgetExceeded      = "status.budget_exceeded"
+	NoticeStatusBudgetContinued     = "status.budget_continued"
+	NoticeStatusToolDecided         = "status.tool_decided"
+	NoticeStatusCostBudget          = "status.cost_budget_exceeded"
+
+	NoticeWaitToolCall     = "wait.tool_call"
+	NoticeWaitQueuedRemote = "wait.queued_remote"
+	NoticeWaitHuman        = "wait.waiting_on_human"
+	NoticeWaitPlanApproval = "wait.plan_approval"
+	NoticeWaitWorkingHours = "wait.working_hours"
+	NoticeWaitShutdown     = "wait.server_shutdown"
+	NoticeWaitCommand      = "wait.command_approval"
+	NoticeWaitToolApproval = "wait.tool_approval"
+	NoticeWaitCostBudget   = "wait.cost_budget"
+)
+
+// System message codes.
+const (
+	NoticeRunCancelled             = "run.cancelled"
+	NoticeRunPanicked              = "run.panicked"
+	NoticeResumeUnavailable        = "resume.continuation_unavailable"
+	NoticeResumePartial            = "resume.partial"
+	NoticeQuestionAsked            = "question.asked"
+	NoticeQuestionAnswer           = "question.answer"
+	NoticeQuestionCancelled        = "question.cancelled"
+	NoticePlanApproved             = "plan.approved"
+	NoticeBestOfNStarted           = "best_of_n.started"
+	NoticeBestOfNMerged            = "best_of_n.merged"
+	NoticeBestOfNDiscarded         = "best_of_n.discarded"
+	NoticeHandoffSummarizing       = "handoff.summarizing"
+	NoticeHandoffStarted           = "handoff.started"
+	NoticeHandoffFailed            = "handoff.failed"
+	NoticeHandoffSent              = "handoff.sent"
+	NoticeHandoffReceived          = "handoff.received"
+	NoticeRecoveryInterrupted      = "recovery.interrupted"
+	NoticeRecoverySkippedRepeated  = "recovery.skipped_repeated"
+	NoticeRecoverySkippedNoMessage = "recovery.skipped_no_message"
+	NoticeRecoveryFailed           = "recovery.failed"
+	NoticeRecoveryResuming         = "recovery.resuming"
+	NoticeRecoveryRetrying         = "recovery.retrying"
+	NoticeTimeboxWarning           = "timebox.warning"
+	NoticeTimeboxStopped           = "timebox.stopped"
+	NoticeCleanupResult            = "cleanup.result"
+	NoticeWorkingHoursSuspended    = "working_hours.suspended"
+	NoticeWorkingHoursStarted      = "working_hours.started"
+	NoticeCommandProposed          = "command.proposed"
+	NoticeCommandApproved          = "command.approved"
+	NoticeCommandRejected          = "command.rejected"
+	NoticeBudgetWarning            = "budget.warning"
+	NoticeBudgetStopped            = "budget.stopped"
+	NoticeBudgetSummarizing        = "budget.summarizing"
+	NoticeBudgetContinued          = "budget.continued"
+	NoticeBudgetContinueFailed     = "budget.continue_failed"
+	NoticeTakeoverStarted          = "takeover.started"
+	NoticeTakeoverHandedBack       = "takeover.handed_back"
+	NoticeToolApprovalRequested    = "tool_approval.requested"
+	NoticeToolApprovalAllowed      = "tool_approval.allowed"
+	NoticeToolApprovalDenied       = "tool_approval.denied"
+	NoticeQueueDiscarded           = "queue.discarded"
+	NoticeQueueDeliveryFailed      = "queue.delivery_failed"
+	NoticeContextNearLimit         = "context.near_limit"
+	NoticeCostBudgetSuspended      = "cost_budget.suspended"
+	NoticeCostBudgetCancelled      = "cost_budget.cancelled"
+	NoticeCostBudgetResumed        = "cost_budget.resumed"
+)
+
+type noticeParams map[string]string
+
+// noticeText renders each code's English text. Optional parameters are
+// omitted from the text when empty.
+var noticeText = map[string]func(p noticeParams) string{
+	NoticeStatusStarted:             func(noticeParams) string { return "session started" },
+	NoticeStatusRunCompleted:        func(noticeParams) string { return "session run completed" },
+	NoticeStatusStopped:             func(noticeParams) string { return "session stopped" },
+	NoticeStatusKilled:              func(noticeParams) string { return "session killed" },
+	NoticeStatusCancelled:           func(noticeParams) string { return "run cancelled by user" },
+	NoticeStatusResumed:             func(noticeParams) string { return "resumed from suspension" },
+	NoticeStatusNoContinuation:      func(noticeParams) string { return "resume token accepted; provider continuation unavailable" },
+	NoticeStatusQuestionAnswered:    func(noticeParams) string { return "question answered" },
+	NoticeStatusPlanApproved:        func(noticeParams) string { return "plan approved" },
+	NoticeStatusRecovery:            func(noticeParams) string { return "startup recovery" },
+	NoticeStatusTimeboxed:           func(p noticeParams) string { return fmt.Sprintf("run exceeded its %s deadline", p["deadline"]) },
+	NoticeStatusBatchSubmitted:      func(noticeParams) string { return "submitted to batch queue" },
+	NoticeStatusBatchCompleted:      func(noticeParams) string { return "batch run completed" },
+	NoticeStatusWorkingHoursStarted: func(noticeParams) string { return "working hours started" },
+	NoticeStatusCommandDecided:      func(p noticeParams) string { return "command " + p["decision"] },
+	NoticeStatusBudgetExceeded:      func(p noticeParams) string { return fmt.Sprintf("run exceeded its %s token budget", p["budget"]) },
+	NoticeStatusBudgetContinued: func(p noticeParams) string {
+		return fmt.Sprintf("run reached its %s token budget; continuing in a fresh run", p["budget"])
+	},
+	NoticeStatusToolDecided: func(p noticeParams) string { return "tool call " + p["decision"] },
+	NoticeStatusCostBudget: func(p noticeParams) string {
+		return fmt.Sprintf("run stopped at the %s cost budget of %s", p["scope"], p["limit"])
+	},
+
+	NoticeWaitToolCall:     func(p noticeParams) string { return "waiting for tool result: " + p["refs"] },
+	NoticeWaitQueuedRemote: func(p noticeParams) string { return WaitKindQueuedRemote + ": " + p["ref"] },
+	NoticeWaitHuman:        func(p noticeParams) string { return WaitKindWaitingOnHuman + ": " + p["question"] },
+	NoticeWaitPlanApproval: func(noticeParams) string { return WaitKindPlanApproval + ": plan ready for review" },
+	NoticeWaitWorkingHours: func(p noticeParams) string { return WaitKindWorkingHours + ": resumes " + p["resumes_at"] },
+	NoticeWaitShutdown:     func(noticeParams) string { return WaitKindShutdown + ": server shutting down" },
+	NoticeWaitCommand:      func(p noticeParams) string { return WaitKindCommandApproval + ": " + p["command"] },
+	NoticeWaitToolApproval: func(p noticeParams) string { return WaitKindToolApproval + ": " + p["tool"] },
+	NoticeWaitCostBudget: func(p noticeParams) string {
+		return WaitKindCostBudget + ": " + p["scope"] + " budget of " + p["limit"]
+	},
+
+	NoticeRunCancelled: func(noticeParams) string { return "Run cancelled by user" },
+	NoticeRunPanicked:  func(p noticeParams) string { return "Panic recovered: " + p["panic"] },
+	NoticeResumeUnavailable: func(noticeParams) string {
+		return "[resume] Resume token accepted. Provider continuation is unavailable; send a new message to continue."
+	},
+	NoticeResumePartial: func(p noticeParams) string {
+		return fmt.Sprintf("[resume] Tool result for %s received; waiting on %s more.", p["ref"], p["remaining"])
+	},
+	NoticeQuestionAsked: func(p noticeParams) string {
+		text := "[question] " + p["question"]
+		if p["options"] != "" {
+			text += "\nOptions: " + p["options"]
+		}
+		return text
+	},
+	NoticeQuestionAnswer:    func(p noticeParams) string { return fmt.Sprintf("[answer] %s: %s", p["answered_by"], p["answer"]) },
+	NoticeQuestionCancelled: func(noticeParams) string { return "[question] cancelled: the run ended before it was answered" },
+	NoticePlanApproved: func(p noticeParams) string {
+		text := "Plan approved"
+		if p["approved_by"] != "" {
+			text += " by " + p["approved_by"]
+		}
+		if p["edited"] == "true" {
+			text += " with edits"
+		}
+		return text
+	},
+	NoticeBestOfNStarted: func(p noticeParams) string {
+		return fmt.Sprintf("[best-of-n] Started %s candidate runs from %s", p["count"], p["base"])
+	},
+	NoticeBestOfNMerged:    func(p noticeParams) string { return "[best-of-n] Merged candidate " + p["candidate"] },
+	NoticeBestOfNDiscarded: func(noticeParams) string { return "[best-of-n] Discarded all candidates" },
+	NoticeHandoffSummarizing: func(p noticeParams) string {
+		return fmt.Sprintf("[handoff] Asking %s to summarize the session for %s", p["from"], p["to"])
+	},
+	NoticeHandoffStarted: func(p noticeParams) string {
+		return fmt.Sprintf("[handoff] Handed off from %s to %s", p["from"], p["to"])
+	},
+	NoticeHandoffFailed: func(p noticeParams) string {
+		return fmt.Sprintf("[handoff] Handoff to %s failed: %s", p["to"], p["error"])
+	},
+	NoticeHandoffSent: func(p noticeParams) string {
+		return fmt.Sprintf("[handoff] Sent message %s to session %s", p["message_id"], p["session"])
+	},
+	NoticeHandoffReceived: func(p noticeParams) string {
+		return fmt.Sprintf("[handoff] Received message %s from session %s", p["message_id"], p["session"])
+	},
+	NoticeRecoveryInterrupted: func(p noticeParams) string {
+		text := "[recovery] startup recovery: interrupted while running"
+		if p["wait_kind"] != "" {
+			text = "[recovery] startup recovery: interrupted while waiting for " + p["wait_kind"]
+			if p["wait_ref"] != "" {
+				text += ": " + p["wait_ref"]
+			}
+		}
+		if p["attempt"] != "" {
+			text += fmt.Sprintf(" (attempt=%s)", p["attempt"])
+		}
+		return text
+	},
+	NoticeRecoverySkippedRepeated: func(p noticeParams) string {
+		return fmt.Sprintf("[recovery] %s skipped: the last %s runs were all interrupted", p["policy"], p["count"])
+	},
+	NoticeRecoverySkippedNoMessage: func(p noticeParams) string {
+		return fmt.Sprintf("[recovery] %s skipped: no user message to retry", p["policy"])
+	},
+	NoticeRecoveryFailed:   func(p noticeParams) string { return fmt.Sprintf("[recovery] %s failed: %s", p["policy"], p["error"]) },
+	NoticeRecoveryResuming: func(noticeParams) string { return "[recovery] resuming the interrupted run" },
+	NoticeRecoveryRetrying: func(noticeParams) string { return "[recovery] retrying the last message" },
+	NoticeTimeboxWarning: func(p noticeParams) string {
+		return fmt.Sprintf("[timebox] This run will be stopped in %s when its deadline is reached. Wrap up and leave the work in a consistent state.", p["remaining"])
+	},
+	NoticeTimeboxStopped: func(p noticeParams) string {
+		return fmt.Sprintf("[timebox] Run stopped: run exceeded its %s deadline", p["deadline"])
+	},
+	NoticeCleanupResult: func(p noticeParams) string {
+		var result string
+		switch p["outcome"] {
+		case "succeeded", "timed out":
+			result = p["outcome"]
+		case "exited":
+			result = "exited with status " + p["exit_code"]
+		default:
+			result = "failed: " + p["error"]
+		}
+		text := fmt.Sprintf("[cleanup] %s (on %s) %s in %s", p["command"], p["trigger"], result, p["duration"])
+		if p["output"] != "" {
+			text += "\n" + p["output"]
+		}
+		return text
+	},
+	NoticeWorkingHoursSuspended: func(p noticeParams) string {
+		return "[working hours] Run suspended outside working hours; it resumes " + p["resumes_at"]
+	},
+	NoticeWorkingHoursStarted: func(noticeParams) string { return "[working hours] Working hours started; resuming the run" },
+	NoticeCommandProposed:     func(p noticeParams) string { return "[command] Proposed: " + p["command"] },
+	NoticeCommandApproved: func(p noticeParams) string {
+		if p["rule"] != "" {
+			return fmt.Sprintf("[command] Auto-approved by %q: %s", p["rule"], p["command"])
+		}
+		return fmt.Sprintf("[command] Approved by %s: %s", p["decided_by"], p["command"])
+	},
+	NoticeBudgetWarning: func(p noticeParams) string {
+		return fmt.Sprintf("[budget] This run has used %s of its %s token budget and will be stopped when it runs out. Wrap up and leave the work in a consistent state.", p["used"], p["budget"])
+	},
+	NoticeBudgetStopped: func(p noticeParams) string {
+		return fmt.Sprintf("[budget] Run stopped: run exceeded its %s token budget", p["budget"])
+	},
+	NoticeBudgetSummarizing: func(p noticeParams) string {
+		return fmt.Sprintf("[budget] Token budget of %s reached; asking the agent to summarize before continuing", p["budget"])
+	},
+	NoticeBudgetContinued: func(p noticeParams) string {
+		return fmt.Sprintf("[budget] Continuing in a fresh run from the summary of run %s (continuation %s)", p["attempt"], p["continuation"])
+	},
+	NoticeBudgetContinueFailed: func(p noticeParams) string {
+		return "[budget] Could not continue after the token budget: " + p["error"]
+	},
+	NoticeToolApprovalRequested: func(p noticeParams) string { return "[tool approval] Requested: " + p["tool"] },
+	NoticeToolApprovalAllowed: func(p noticeParams) string {
+		return fmt.Sprintf("[tool approval] Allowed by %s: %s", p["decided_by"], p["tool"])
+	},
+	NoticeToolApprovalDenied: func(p noticeParams) string {
+		text := fmt.Sprintf("[tool approval] Denied by %s: %s", p["decided_by"], p["tool"])
+		if p["reason"] != "" {
+			text += "\nReason: " + p["reason"]
+		}
+		return text
+	},
+	NoticeTakeoverStarted: func(p noticeParams) string {
+		text := fmt.Sprintf("[takeover] %s took over the session; agent input is suspended", p["by"])
+		if p["reason"] != "" {
+			text += "\nReason: " + p["reason"]
+		}
+		return text
+	},
+	NoticeTakeoverHandedBack: func(p noticeParams) string {
+		return fmt.Sprintf("[takeover] %s handed the session back to the agent", p["by"])
+	},
+	NoticeCommandRejected: func(p noticeParams) string {
+		text := fmt.Sprintf("[command] Rejected by %s: %s", p["decided_by"], p["command"])
+		if p["reason"] != "" {
+			text += "\nReason: " + p["reason"]
+		}
+		return text
+	},
+	NoticeQueueDiscarded: func(p noticeParams) string {
+		return fmt.Sprintf("[queue] Discarded %s queued messages: %s", p["count"], p["reason"])
+	},
+	NoticeQueueDeliveryFailed: func(p noticeParams) string {
+		return fmt.Sprintf("[queue] Queued message %s was not delivered: %s", p["message_id"], p["error"])
+	},
+	NoticeContextNearLimit: func(p noticeParams) string {
+		return fmt.Sprintf("[context] The conversation fills %s%% of the %s-token context window of %s; the provider may compact it soon.", p["percent"], p["limit"], p["model"])
+	},
+	NoticeCostBudgetSuspended: func(p noticeParams) string {
+		return fmt.Sprintf("[budget] Run suspended: the %s used up its cost budget of %s. Raise the budget to resume it.", p["scope"], p["limit"])
+	},
+	NoticeCostBudgetCancelled: func(p noticeParams) string {
+		return fmt.Sprintf("[budget] Run cancelled: the %s used up its cost budget of %s.", p["scope"], p["limit"])
+	},
+	NoticeCostBudgetResumed: func(noticeParams) string { return "[budget] Cost budget raised; resuming the run" },
+}
+
+// NewNotice returns a notice with the given code and key/value parameters.
+// Empty values are dropped.
+func NewNotice(code string, kv ...string) Notice {
+	n := Notice{Code: code}
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] == "" {
+			continue
+		}
+		if n.Params == nil {
+			n.Params = make(map[string]string)
+		}
+		n.Params[kv[i]] = kv[i+1]
+	}
+	return n
+}
+
+// Text renders the notice's English text. Unknown codes render as the code
+// followed by their parameters.
+func (n Notice) Text() string {
+	if render, ok := noticeText[n.Code]; ok {
+		return render(n.Params)
+	}
+	if len(n.Params) == 0 {
+		return n.Code
+	}
+	parts := make([]string, 0, len(n.Params))
+	for _, k := range slices.Sorted(maps.Keys(n.Params)) {
+		parts = append(parts, k+"="+n.Params[k])
+	}
+	return n.Code + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// NoticeCodes returns every code Text knows, sorted.
+func NoticeCodes() []string {
+	return slices.Sorted(maps.Keys(noticeText))
+}
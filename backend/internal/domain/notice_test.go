@@ -0,0 +1,42 @@
+package domain
+
+import "testing"
+
+func TestNoticeText(t *testing.T) {
+	tests := []struct {
+		notice Notice
+		want   string
+	}{
+		{NewNotice(NoticeStatusStarted), "session started"},
+		{NewNotice(NoticeWaitHuman, "question", "Deploy?"), "waiting_on_human: Deploy?"},
+		{NewNotice(NoticeQuestionAsked, "question", "Deploy?", "options", ""), "[question] Deploy?"},
+		{NewNotice(NoticeQuestionAsked, "question", "Deploy?", "options", "yes, no"), "[question] Deploy?\nOptions: yes, no"},
+		{NewNotice(NoticePlanApproved, "approved_by", "alice", "edited", "false"), "Plan approved by alice"},
+		{NewNotice(NoticeRecoveryInterrupted, "attempt", "a1"), "[recovery] startup recovery: interrupted while running (attempt=a1)"},
+		{NewNotice(NoticeCleanupResult, "command", "make clean", "trigger", "stop", "outcome", "exited", "exit_code", "2", "duration", "1s"), "[cleanup] make clean (on stop) exited with status 2 in 1s"},
+		{NewNotice("custom.code", "b", "2", "a", "1"), "custom.code (a=1, b=2)"},
+	}
+	for _, tt := range tests {
+		if got := tt.notice.Text(); got != tt.want {
+			t.Errorf("%s.Text() = %q, want %q", tt.notice.Code, got, tt.want)
+		}
+	}
+}
+
+func TestNewNoticeDropsEmptyParams(t *testing.T) {
+	n := NewNotice(NoticePlanApproved, "approved_by", "", "edited", "true")
+	if len(n.Params) != 1 || n.Params["edited"] != "true" {
+		t.Fatalf("params = %v, want only edited", n.Params)
+	}
+}
+
+func TestTransitionWithNotice(t *testing.T) {
+	s := NewSession("s1", "test", "/tmp")
+	if err := s.TransitionWithNotice(SessionStateRunning, NewNotice(NoticeStatusStarted)); err != nil {
+		t.Fatalf("transition: %v", err)
+	}
+	tr := s.Transitions[len(s.Transitions)-1]
+	if tr.Reason != "session started" || tr.Notice == nil || tr.Notice.Code != NoticeStatusStarted {
+		t.Fatalf("transition = %+v, want reason and notice", tr)
+	}
+}
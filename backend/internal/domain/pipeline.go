@@ -0,0 +1,314 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// SessionKindPipeline marks a session started for a pipeline step.
+const SessionKindPipeline = "pipeline"
+
+const (
+	// MaxPipelineRuns is how many of its latest runs a pipeline keeps.
+	MaxPipelineRuns = 20
+	// MaxPipelineStepOutput caps the output a step passes downstream.
+	MaxPipelineStepOutput = 64 << 10
+)
+
+// Pipeline run and step statuses. A step is skipped when a step it depends
+// on failed or was skipped.
+const (
+	PipelineStatusPending   = "pending"
+	PipelineStatusRunning   = "running"
+	PipelineStatusCompleted = "completed"
+	PipelineStatusFailed    = "failed"
+	PipelineStatusSkipped   = "skipped"
+)
+
+// Pipeline is a DAG of steps. Each run starts a new session per step once
+// the steps it depends on complete, prompting it with their outputs.
+type Pipeline struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	ProjectID  string         `json:"project_id,omitempty"`
+	WorkingDir string         `json:"working_dir"`
+	Steps      []PipelineStep `json:"steps"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	// Runs are the latest runs, oldest first, up to MaxPipelineRuns.
+	Runs []PipelineRun `json:"runs,omitempty"`
+}
+
+// PipelineStep is one step of a pipeline.
+type PipelineStep struct {
+	// Name identifies the step in DependsOn and prompt templates. It is made
+	// of letters, digits and underscores and starts with a letter.
+	Name         string `json:"name"`
+	ProviderType string `json:"provider_type,omitempty"`
+	// ProviderID and AgentID name the stored provider and agent configs
+	// the step's session starts with. ProviderType defaults to the provider
+	// config's.
+	ProviderID string `json:"provider_id,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	// Prompt is a text/template rendered with a PipelineInput, e.g.
+	// "Review this plan:\n\n{{.Steps.plan.Output}}".
+	Prompt string `json:"prompt"`
+	// Role, if set, is the step session's system prompt.
+	Role      string   `json:"role,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// PipelineInput is the data step prompts are rendered with: the run's
+// input and the outputs of the steps completed so far.
+type PipelineInput struct {
+	Input string
+	Steps map[string]PipelineStepOutput
+}
+
+// PipelineStepOutput is what a completed step passes downstream: its
+// session's last output.
+type PipelineStepOutput struct {
+	SessionID string
+	Output    string
+}
+
+// PipelineRun is one run of a pipeline.
+type PipelineRun struct {
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Input       string            `json:"input,omitempty"`
+	Steps       []PipelineStepRun `json:"steps"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt time.Time         `json:"completed_at,omitzero"`
+}
+
+// PipelineStepRun is the state of one step in a run.
+type PipelineStepRun struct {
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Output      string    `json:"output,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	CompletedAt time.Time `json:"completed_at,omitzero"`
+}
+
+// Validate checks the pipeline's name and steps, that every dependency
+// names another step and that they form no cycle.
+func (p *Pipeline) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("pipeline name is required")
+	}
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("pipeline needs at least one step")
+	}
+	names := make(map[string]bool, len(p.Steps))
+	for _, s := range p.Steps {
+		if !validPipelineStepName(s.Name) {
+			return fmt.Errorf("pipeline step name %q must be letters, digits and underscores, starting with a letter", s.Name)
+		}
+		if names[s.Name] {
+			return fmt.Errorf("duplicate pipeline step %q", s.Name)
+		}
+		names[s.Name] = true
+		if strings.TrimSpace(s.ProviderType) == "" && strings.TrimSpace(s.ProviderID) == "" {
+			return fmt.Errorf("pipeline step %q: provider_type or provider_id is required", s.Name)
+		}
+		if strings.TrimSpace(s.Prompt) == "" {
+			return fmt.Errorf("pipeline step %q: prompt is required", s.Name)
+		}
+		if _, err := s.template(); err != nil {
+			return fmt.Errorf("pipeline step %q: invalid prompt: %w", s.Name, err)
+		}
+	}
+	for _, s := range p.Steps {
+		for _, dep := range s.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("pipeline step %q depends on unknown step %q", s.Name, dep)
+			}
+		}
+	}
+	return p.checkAcyclic()
+}
+
+func validPipelineStepName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// checkAcyclic reports a cycle in the steps' dependencies.
+func (p *Pipeline) checkAcyclic() error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(p.Steps))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("pipeline steps depend on each other in a cycle through %q", name)
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		step, _ := p.Step(name)
+		for _, dep := range step.DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for _, s := range p.Steps {
+		if err := visit(s.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step returns the step with the given name.
+func (p *Pipeline) Step(name string) (PipelineStep, bool) {
+	for _, s := range p.Steps {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return PipelineStep{}, false
+}
+
+// RenderPrompt renders the step's prompt with in.
+func (s PipelineStep) RenderPrompt(in PipelineInput) (string, error) {
+	tmpl, err := s.template()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, in); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (s PipelineStep) template() (*template.Template, error) {
+	return template.New(s.Name).Option("missingkey=error").Parse(s.Prompt)
+}
+
+// NewRun returns a running run of the pipeline with every step pending.
+func (p *Pipeline) NewRun(id, input string, now time.Time) PipelineRun {
+	run := PipelineRun{ID: id, Status: PipelineStatusRunning, Input: input, StartedAt: now}
+	for _, s := range p.Steps {
+		run.Steps = append(run.Steps, PipelineStepRun{Name: s.Name, Status: PipelineStatusPending})
+	}
+	return run
+}
+
+// AddRun appends a run, dropping the oldest beyond MaxPipelineRuns.
+func (p *Pipeline) AddRun(run PipelineRun) {
+	p.Runs = append(p.Runs, run)
+	if extra := len(p.Runs) - MaxPipelineRuns; extra > 0 {
+		p.Runs = append([]PipelineRun(nil), p.Runs[extra:]...)
+	}
+}
+
+// Run returns the run with the given ID.
+func (p *Pipeline) Run(id string) *PipelineRun {
+	for i := range p.Runs {
+		if p.Runs[i].ID == id {
+			return &p.Runs[i]
+		}
+	}
+	return nil
+}
+
+// Step returns the state of the named step.
+func (r *PipelineRun) Step(name string) *PipelineStepRun {
+	for i := range r.Steps {
+		if r.Steps[i].Name == name {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+// PromptInput is the data the run's next prompts are rendered with.
+func (r *PipelineRun) PromptInput() PipelineInput {
+	in := PipelineInput{Input: r.Input, Steps: make(map[string]PipelineStepOutput)}
+	for _, s := range r.Steps {
+		if s.Status == PipelineStatusCompleted {
+			in.Steps[s.Name] = PipelineStepOutput{SessionID: s.SessionID, Output: s.Output}
+		}
+	}
+	return in
+}
+
+// Advance skips the pending steps that can no longer run and returns the
+// names of those whose dependencies have all completed. Once no step is
+// pending or running it completes the run, as failed if any step did not
+// complete.
+func (r *PipelineRun) Advance(p *Pipeline, now time.Time) []string {
+	for changed := true; changed; {
+		changed = false
+		for i := range r.Steps {
+			s := &r.Steps[i]
+			if s.Status != PipelineStatusPending {
+				continue
+			}
+			step, _ := p.Step(s.Name)
+			for _, dep := range step.DependsOn {
+				if d := r.Step(dep); d == nil || d.Status == PipelineStatusFailed || d.Status == PipelineStatusSkipped {
+					s.Status = PipelineStatusSkipped
+					s.Error = "step " + dep + " did not complete"
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var ready []string
+	active := false
+	failed := false
+	for _, s := range r.Steps {
+		switch s.Status {
+		case PipelineStatusRunning:
+			active = true
+		case PipelineStatusPending:
+			active = true
+			step, _ := p.Step(s.Name)
+			if r.dependenciesCompleted(step) {
+				ready = append(ready, s.Name)
+			}
+		case PipelineStatusFailed, PipelineStatusSkipped:
+			failed = true
+		}
+	}
+	if !active && r.Status == PipelineStatusRunning {
+		r.Status = PipelineStatusCompleted
+		if failed {
+			r.Status = PipelineStatusFailed
+		}
+		r.CompletedAt = now
+	}
+	return ready
+}
+
+func (r *PipelineRun) dependenciesCompleted(step PipelineStep) bool {
+	for _, dep := range step.DependsOn {
+		if d := r.Step(dep); d == nil || d.Status != PipelineStatusCompleted {
+			return false
+		}
+	}
+	return true
+}
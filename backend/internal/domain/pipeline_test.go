@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipeline_Validate(t *testing.T) {
+	step := func(name string, deps ...string) PipelineStep {
+		return PipelineStep{Name: name, ProviderType: "claude", Prompt: "do it", DependsOn: deps}
+	}
+	for name, tc := range map[string]struct {
+		steps []PipelineStep
+		want  string
+	}{
+		"no steps":       {nil, "at least one step"},
+		"bad name":       {[]PipelineStep{step("1st")}, "must be letters"},
+		"duplicate":      {[]PipelineStep{step("a"), step("a")}, "duplicate"},
+		"unknown dep":    {[]PipelineStep{step("a", "b")}, "unknown step"},
+		"cycle":          {[]PipelineStep{step("a", "c"), step("b", "a"), step("c", "b")}, "cycle"},
+		"bad template":   {[]PipelineStep{{Name: "a", ProviderType: "claude", Prompt: "{{.Input"}}, "invalid prompt"},
+		"missing prompt": {[]PipelineStep{{Name: "a", ProviderType: "claude"}}, "prompt is required"},
+	} {
+		p := Pipeline{Name: "p", Steps: tc.steps}
+		if err := p.Validate(); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, tc.want, err)
+		}
+	}
+	p := Pipeline{Name: "p", Steps: []PipelineStep{step("plan"), step("build", "plan"), step("test", "plan"), step("review", "build", "test")}}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("expected a valid diamond, got %v", err)
+	}
+}
+
+func TestPipelineRun_Advance(t *testing.T) {
+	p := &Pipeline{Name: "p", Steps: []PipelineStep{
+		{Name: "plan", ProviderType: "claude", Prompt: "Plan {{.Input}}"},
+		{Name: "build", ProviderType: "claude", Prompt: "Build {{.Steps.plan.Output}}", DependsOn: []string{"plan"}},
+		{Name: "docs", ProviderType: "claude", Prompt: "Document it", DependsOn: []string{"plan"}},
+		{Name: "review", ProviderType: "claude", Prompt: "Review", DependsOn: []string{"build", "docs"}},
+	}}
+	now := time.Now()
+	run := p.NewRun("r1", "the parser", now)
+	if ready := run.Advance(p, now); strings.Join(ready, ",") != "plan" {
+		t.Fatalf("expected only plan to be ready, got %v", ready)
+	}
+
+	plan := run.Step("plan")
+	plan.Status, plan.Output = PipelineStatusCompleted, "step one"
+	if ready := run.Advance(p, now); strings.Join(ready, ",") != "build,docs" {
+		t.Fatalf("expected build and docs to be ready, got %v", ready)
+	}
+	build, _ := p.Step("build")
+	if prompt, err := build.RenderPrompt(run.PromptInput()); err != nil || prompt != "Build step one" {
+		t.Fatalf("unexpected prompt %q: %v", prompt, err)
+	}
+	review, _ := p.Step("review")
+	review.Prompt = "{{.Steps.build.Output}}"
+	if _, err := review.RenderPrompt(run.PromptInput()); err == nil {
+		t.Fatal("expected the output of an unfinished step to be an error")
+	}
+
+	run.Step("build").Status = PipelineStatusFailed
+	run.Step("docs").Status = PipelineStatusCompleted
+	if ready := run.Advance(p, now); len(ready) != 0 {
+		t.Fatalf("expected nothing ready, got %v", ready)
+	}
+	if s := run.Step("review"); s.Status != PipelineStatusSkipped {
+		t.Fatalf("expected review to be skipped, got %+v", s)
+	}
+	if run.Status != PipelineStatusFailed || run.CompletedAt.IsZero() {
+		t.Fatalf("expected the run to fail, got %s", run.Status)
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import "time"
+
+// WaitKindPlanApproval marks a run that stopped after the agent's first plan
+// in a session that requires plan approval. It is used as the run attempt
+// wait kind and prefixes the suspension transition reason.
+const WaitKindPlanApproval = "plan_approval"
+
+// PlanStatus is where a session's plan is in the approval flow.
+type PlanStatus string
+
+const (
+	PlanStatusProposed PlanStatus = "proposed"
+	PlanStatusApproved PlanStatus = "approved"
+)
+
+// SessionPlan is the plan an agent proposed in a session that requires plan
+// approval, and who approved it.
+type SessionPlan struct {
+	Content    string     `json:"content"`
+	Status     PlanStatus `json:"status"`
+	ProposedAt time.Time  `json:"proposed_at"`
+	// Edited is set when the approver replaced the agent's plan.
+	Edited     bool       `json:"edited,omitempty"`
+	ApprovedBy string     `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
+
+func (s *Session) SetPlanApproval(required bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PlanApproval = required
+	s.UpdatedAt = time.Now()
+}
+
+// NeedsPlanApproval reports whether runs must stop at the agent's plan: the
+// session requires plan approval and no plan has been approved yet.
+func (s *Session) NeedsPlanApproval() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.PlanApproval && (s.Plan == nil || s.Plan.Status != PlanStatusApproved)
+}
+
+// GetPlan returns a copy of the session's plan, or nil.
+func (s *Session) GetPlan() *SessionPlan {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Plan.clone()
+}
+
+// ProposePlan records a plan awaiting approval, replacing any earlier
+// proposal.
+func (s *Session) ProposePlan(content string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Plan = &SessionPlan{Content: content, Status: PlanStatusProposed, ProposedAt: at}
+	s.UpdatedAt = time.Now()
+}
+
+// ApprovePlan approves the proposed plan, replacing its content when edited
+// is not empty. It reports false when no plan is awaiting approval.
+func (s *Session) ApprovePlan(edited, approvedBy string, at time.Time) (SessionPlan, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Plan == nil || s.Plan.Status != PlanStatusProposed {
+		return SessionPlan{}, false
+	}
+	if edited != "" && edited != s.Plan.Content {
+		s.Plan.Content = edited
+		s.Plan.Edited = true
+	}
+	s.Plan.Status = PlanStatusApproved
+	s.Plan.ApprovedBy = approvedBy
+	s.Plan.ApprovedAt = &at
+	s.UpdatedAt = time.Now()
+	return *s.Plan.clone(), true
+}
+
+func (p *SessionPlan) clone() *SessionPlan {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	if p.ApprovedAt != nil {
+		at := *p.ApprovedAt
+		c.ApprovedAt = &at
+	}
+	return &c
+}
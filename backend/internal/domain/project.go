@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"path/filepath"
+	"time"
+)
 
 type Project struct {
 	ID        string
@@ -8,4 +11,35 @@ type Project struct {
 	Path      string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	// CleanupCommands run in the session's working directory when a session
+	// in this project is stopped, killed or cleaned up.
+	CleanupCommands []string
+	// StorageDir, if set, keeps the project's session history there instead
+	// of the server's base directory. Relative paths are resolved against
+	// Path, so ".orbitmesh" keeps it inside the repository.
+	StorageDir string
+	// Guardrails overrides the server's output scanning policy for the
+	// project's sessions.
+	Guardrails *GuardrailPolicy
+	// WorkingHours, if set, suspends the project's sessions outside the
+	// configured hours and resumes them when the next window opens.
+	WorkingHours *WorkingHours
+	// Watch, if set, starts sessions when files under the project path
+	// change.
+	Watch *Watch
+	// ToolPolicy decides the tool calls of the project's sessions by rule.
+	ToolPolicy *ToolPolicy
+	// CostBudget limits what the project's sessions may spend together.
+	CostBudget *CostBudget
+	// PullRequests, if set, lets the project's sessions open pull requests.
+	PullRequests *PullRequestConfig
+}
+
+// StorageRoot returns the resolved StorageDir, or "" if the project uses the
+// server's base directory.
+func (p Project) StorageRoot() string {
+	if p.StorageDir == "" || filepath.IsAbs(p.StorageDir) {
+		return p.StorageDir
+	}
+	return filepath.Join(p.Path, p.StorageDir)
 }
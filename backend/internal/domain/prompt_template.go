@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// PromptTemplate is a named, reusable prompt. Prompt and SystemPrompt are
+// text/template templates over the variables, e.g. {{.repo}}.
+// Prompt is what a message referencing the template sends; SystemPrompt and
+// the ProviderType and AgentID presets apply to sessions created with it.
+type PromptTemplate struct {
+	ID           string                   `json:"id"`
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description,omitempty"`
+	Prompt       string                   `json:"prompt,omitempty"`
+	SystemPrompt string                   `json:"system_prompt,omitempty"`
+	ProviderType string                   `json:"provider_type,omitempty"`
+	AgentID      string                   `json:"agent_id,omitempty"`
+	Variables    []PromptTemplateVariable `json:"variables,omitempty"`
+	CreatedAt    time.Time                `json:"created_at"`
+	UpdatedAt    time.Time                `json:"updated_at"`
+}
+
+// PromptTemplateVariable documents a variable of a template. Default is
+// used when a render supplies no value for it.
+type PromptTemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// Built-in template variables, filled from the session a template is
+// rendered for. Values supplied with the render take precedence.
+const (
+	TemplateVarTaskID       = "task_id"
+	TemplateVarTaskTitle    = "task_title"
+	TemplateVarRepo         = "repo"
+	TemplateVarProject      = "project"
+	TemplateVarWorkingDir   = "working_dir"
+	TemplateVarProviderType = "provider_type"
+	TemplateVarDate         = "date"
+)
+
+var templateVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks the template's name, that it has a prompt or system
+// prompt that parse, and that its variables are named once each.
+func (t *PromptTemplate) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("template name is required")
+	}
+	if strings.TrimSpace(t.Prompt) == "" && strings.TrimSpace(t.SystemPrompt) == "" {
+		return fmt.Errorf("template needs a prompt or system_prompt")
+	}
+	if _, err := t.parse(t.Prompt); err != nil {
+		return fmt.Errorf("invalid prompt: %w", err)
+	}
+	if _, err := t.parse(t.SystemPrompt); err != nil {
+		return fmt.Errorf("invalid system_prompt: %w", err)
+	}
+	seen := make(map[string]bool, len(t.Variables))
+	for _, v := range t.Variables {
+		if !templateVariableName.MatchString(v.Name) {
+			return fmt.Errorf("template variable name %q must be letters, digits and underscores", v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("duplicate template variable %q", v.Name)
+		}
+		seen[v.Name] = true
+	}
+	return nil
+}
+
+// Render executes text with vars, falling back to the template's variable
+// defaults. It fails on a variable left without a value.
+func (t *PromptTemplate) Render(text string, vars map[string]string) (string, error) {
+	tmpl, err := t.parse(text)
+	if err != nil {
+		return "", err
+	}
+	data := make(map[string]string, len(t.Variables)+len(vars))
+	for _, v := range t.Variables {
+		if v.Default != "" {
+			data[v.Name] = v.Default
+		}
+	}
+	maps.Copy(data, vars)
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (t *PromptTemplate) parse(text string) (*template.Template, error) {
+	return template.New(t.Name).Option("missingkey=error").Parse(text)
+}
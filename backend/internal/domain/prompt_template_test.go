@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPromptTemplate_Render(t *testing.T) {
+	tmpl := PromptTemplate{
+		Name:      "review",
+		Prompt:    "Review {{.repo}} for {{ .task_title }} at {{.level}}{{if .extra}}; {{.extra}}{{end}}",
+		Variables: []PromptTemplateVariable{{Name: "level", Default: "normal"}, {Name: "extra"}},
+	}
+	if err := tmpl.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	got, err := tmpl.Render(tmpl.Prompt, map[string]string{"repo": "widgets", "task_title": "the login fix", "extra": ""})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if want := "Review widgets for the login fix at normal"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	_, err = tmpl.Render(tmpl.Prompt, nil)
+	if err == nil || !strings.Contains(err.Error(), `"repo"`) {
+		t.Fatalf("expected the missing variable named, got %v", err)
+	}
+
+	for name, bad := range map[string]PromptTemplate{
+		"no name":        {Prompt: "hi"},
+		"no prompt":      {Name: "x"},
+		"bad prompt":     {Name: "x", Prompt: "{{.repo"},
+		"old syntax":     {Name: "x", SystemPrompt: "Fix {{repo}}."},
+		"bad variable":   {Name: "x", Prompt: "hi", Variables: []PromptTemplateVariable{{Name: "my-var"}}},
+		"duplicate vars": {Name: "x", Prompt: "hi", Variables: []PromptTemplateVariable{{Name: "a"}, {Name: "a"}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("%s: expected a validation error", name)
+		}
+	}
+}
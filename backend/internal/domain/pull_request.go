@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	PullRequestGitHub = "github"
+	PullRequestGitLab = "gitlab"
+
+	// MetadataKeyPullRequest is the metadata event published when a
+	// session's branch is opened as a pull request.
+	MetadataKeyPullRequest = "pull_request"
+)
+
+// PullRequestConfig is how a project's sessions open pull requests. The
+// provider's API host and token are server configuration, never part of a
+// project.
+type PullRequestConfig struct {
+	// Provider is PullRequestGitHub or PullRequestGitLab.
+	Provider string `json:"provider"`
+	// Repo is the GitHub "owner/name" or the GitLab project path.
+	Repo string `json:"repo"`
+	// Remote is the git remote the branch is pushed to, "origin" by default.
+	Remote string `json:"remote,omitempty"`
+	// Base is the branch pull requests target, the remote's default branch
+	// when empty.
+	Base string `json:"base,omitempty"`
+}
+
+// Validate reports whether the config names a known provider and a
+// repository. A nil config is valid.
+func (c *PullRequestConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.Provider != PullRequestGitHub && c.Provider != PullRequestGitLab {
+		return fmt.Errorf("unknown pull request provider %q", c.Provider)
+	}
+	if c.Repo == "" || strings.HasPrefix(c.Repo, "/") || strings.Contains(c.Repo, "..") {
+		return errors.New("pull request repo must be a repository path like owner/name")
+	}
+	if strings.HasPrefix(c.Remote, "-") || strings.HasPrefix(c.Base, "-") {
+		return errors.New("pull request remote and base must not start with '-'")
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// RedactedTombstone replaces the contents of redacted messages.
+const RedactedTombstone = "[redacted]"
+
+var ErrMessageNotFound = errors.New("message not found")
+
+// RedactMessages replaces the contents of every message with the same kind
+// and contents as one of targets with RedactedTombstone, and drops their
+// raw provider payloads. Matching by content rather than ID lets callers
+// pass messages read back from the message log, whose IDs differ. It
+// returns how many messages changed.
+func (s *Session) RedactMessages(targets []Message) int {
+	type messageKey struct {
+		kind     MessageKind
+		contents string
+	}
+	match := make(map[messageKey]bool, len(targets))
+	for _, target := range targets {
+		match[messageKey{target.Kind, target.Contents}] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := 0
+	for i := range s.Messages {
+		msg := &s.Messages[i]
+		if msg.Redacted || !match[messageKey{msg.Kind, msg.Contents}] {
+			continue
+		}
+		msg.Contents = RedactedTombstone
+		msg.Raw = nil
+		msg.Notice = nil
+		msg.Redacted = true
+		changed++
+	}
+	if changed > 0 {
+		s.UpdatedAt = time.Now()
+	}
+	return changed
+}
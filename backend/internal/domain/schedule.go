@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SessionKindSchedule marks a session started by a schedule.
+const SessionKindSchedule = "schedule"
+
+// MaxScheduleRuns is how many of its latest runs a schedule keeps.
+const MaxScheduleRuns = 20
+
+// MinScheduleInterval is the shortest interval a schedule may repeat at.
+const MinScheduleInterval = time.Minute
+
+// Schedule starts a new session with a prompt on a cron schedule or at a
+// fixed interval.
+type Schedule struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Cron is a five-field cron expression ("minute hour day-of-month
+	// month day-of-week") or one of @hourly, @daily, @midnight, @weekly,
+	// @monthly and @yearly. Exactly one of Cron and Interval is set.
+	Cron string `json:"cron,omitempty"`
+	// Interval is a Go duration such as "6h", counted from the previous run.
+	Interval string `json:"interval,omitempty"`
+	// TimeZone is an IANA zone name for Cron; empty uses the server's
+	// local time.
+	TimeZone     string `json:"time_zone,omitempty"`
+	ProviderType string `json:"provider_type,omitempty"`
+	// ProviderID and AgentID name the stored provider and agent configs
+	// whose settings the schedule's sessions start with, as for sessions
+	// created through the API. ProviderType defaults to the provider
+	// config's.
+	ProviderID string `json:"provider_id,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+	ProjectID  string `json:"project_id,omitempty"`
+	WorkingDir string `json:"working_dir"`
+	Prompt     string `json:"prompt"`
+	// Paused schedules start no sessions until resumed.
+	Paused    bool      `json:"paused,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// NextRunAt is when the schedule is next due; zero while paused.
+	NextRunAt time.Time `json:"next_run_at,omitzero"`
+	LastRunAt time.Time `json:"last_run_at,omitzero"`
+	// Runs are the latest runs, oldest first, up to MaxScheduleRuns.
+	Runs []ScheduleRun `json:"runs,omitempty"`
+}
+
+// ScheduleRun records a session a schedule started, or why it started none.
+type ScheduleRun struct {
+	At        time.Time `json:"at"`
+	SessionID string    `json:"session_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	// Skipped is set when the previous session was still running.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// Validate checks the timing, provider, working directory and prompt.
+func (s *Schedule) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("schedule name is required")
+	}
+	switch {
+	case s.Cron == "" && s.Interval == "":
+		return fmt.Errorf("schedule needs a cron expression or an interval")
+	case s.Cron != "" && s.Interval != "":
+		return fmt.Errorf("schedule takes a cron expression or an interval, not both")
+	case s.Cron != "":
+		if _, err := ParseCron(s.Cron); err != nil {
+			return err
+		}
+	default:
+		d, err := time.ParseDuration(s.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid schedule interval %q", s.Interval)
+		}
+		if d < MinScheduleInterval {
+			return fmt.Errorf("schedule interval must be at least %s", MinScheduleInterval)
+		}
+	}
+	if s.TimeZone != "" {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return fmt.Errorf("invalid schedule time zone %q", s.TimeZone)
+		}
+	}
+	if s.ProviderType == "" && s.ProviderID == "" {
+		return fmt.Errorf("schedule provider_type or provider_id is required")
+	}
+	if s.WorkingDir == "" {
+		return fmt.Errorf("schedule working_dir is required")
+	}
+	if strings.TrimSpace(s.Prompt) == "" {
+		return fmt.Errorf("schedule prompt is required")
+	}
+	return nil
+}
+
+// Next returns when the schedule is due after t. The schedule must be
+// valid.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.Interval != "" {
+		d, _ := time.ParseDuration(s.Interval)
+		return t.Add(d)
+	}
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	loc := time.Local
+	if s.TimeZone != "" {
+		if l, err := time.LoadLocation(s.TimeZone); err == nil {
+			loc = l
+		}
+	}
+	return cron.Next(t.In(loc))
+}
+
+// AddRun appends a run, dropping the oldest beyond MaxScheduleRuns.
+func (s *Schedule) AddRun(run ScheduleRun) {
+	s.Runs = append(s.Runs, run)
+	if len(s.Runs) > MaxScheduleRuns {
+		s.Runs = s.Runs[len(s.Runs)-MaxScheduleRuns:]
+	}
+	if !run.Skipped {
+		s.LastRunAt = run.At
+	}
+}
+
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// Cron is a parsed cron expression: the minutes, hours, days of the month,
+// months and weekdays it matches.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny record a "*" day field; when both day fields are
+	// restricted, a day matching either one matches, as in cron.
+	domAny, dowAny bool
+}
+
+// ParseCron parses a five-field cron expression or macro. Fields take "*",
+// numbers, ranges "a-b", lists "a,b" and steps "*/n" or "a-b/n"; weekday 7
+// is Sunday like 0.
+func ParseCron(expr string) (Cron, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Cron{}, fmt.Errorf("invalid cron expression %q: want 5 fields", expr)
+	}
+	var c Cron
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return Cron{}, fmt.Errorf("invalid cron minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return Cron{}, fmt.Errorf("invalid cron hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return Cron{}, fmt.Errorf("invalid cron day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return Cron{}, fmt.Errorf("invalid cron month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return Cron{}, fmt.Errorf("invalid cron day of week: %w", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+func parseCronField(field string, lo, hi int) (uint64, error) {
+	var bits uint64
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+			step = n
+		}
+		start, end := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if start, err = strconv.Atoi(a); err != nil {
+				return 0, fmt.Errorf("invalid value %q", a)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(b); err != nil {
+					return 0, fmt.Errorf("invalid value %q", b)
+				}
+			} else if hasStep {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("%q is out of range %d-%d", part, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first minute after t that the expression matches, in
+// t's location, or the zero time if none does within five years.
+func (c Cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
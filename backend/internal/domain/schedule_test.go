@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCron_Next(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	for _, tc := range []struct {
+		expr, after, want string
+	}{
+		{"@daily", "2026-03-10 12:00", "2026-03-11 00:00"},
+		{"30 2 * * *", "2026-03-10 02:30", "2026-03-11 02:30"},
+		{"*/15 * * * *", "2026-03-10 12:07", "2026-03-10 12:15"},
+		{"0 9 * * 1-5", "2026-03-13 10:00", "2026-03-16 09:00"}, // Friday to Monday
+		{"0 0 1 */3 *", "2026-02-10 00:00", "2026-04-01 00:00"},
+		{"0 0 31 * *", "2026-04-01 00:00", "2026-05-31 00:00"},
+		{"0 0 * * 7", "2026-03-10 00:00", "2026-03-15 00:00"},
+		// Both day fields restricted: either one matches.
+		{"0 0 13 * 5", "2026-03-01 00:00", "2026-03-06 00:00"},
+	} {
+		c, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) failed: %v", tc.expr, err)
+		}
+		if got := c.Next(at(tc.after)); !got.Equal(at(tc.want)) {
+			t.Errorf("%q after %s = %s, want %s", tc.expr, tc.after, got.Format("2006-01-02 15:04"), tc.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) should fail", expr)
+		}
+	}
+}
+
+func TestSchedule_Validate(t *testing.T) {
+	base := Schedule{Name: "nightly", Cron: "@daily", ProviderType: "claude", WorkingDir: "/repo", Prompt: "fix tests"}
+	if err := base.Validate(); err != nil {
+		t.Fatalf("valid schedule rejected: %v", err)
+	}
+	for name, mutate := range map[string]func(*Schedule){
+		"no timing":      func(s *Schedule) { s.Cron = "" },
+		"both timings":   func(s *Schedule) { s.Interval = "1h" },
+		"short interval": func(s *Schedule) { s.Cron, s.Interval = "", "10s" },
+		"bad time zone":  func(s *Schedule) { s.TimeZone = "Mars/Olympus" },
+		"no prompt":      func(s *Schedule) { s.Prompt = " " },
+		"no provider":    func(s *Schedule) { s.ProviderType = "" },
+	} {
+		s := base
+		mutate(&s)
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	s := base
+	s.Cron, s.Interval = "", "6h"
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	if got := s.Next(now); !got.Equal(now.Add(6 * time.Hour)) {
+		t.Errorf("interval Next = %s", got)
+	}
+	s.Cron, s.Interval, s.TimeZone = "0 2 * * *", "", "America/New_York"
+	if got := s.Next(now); !got.Equal(time.Date(2026, 3, 11, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("zoned cron Next = %s", got.UTC())
+	}
+
+	for i := range MaxScheduleRuns + 5 {
+		s.AddRun(ScheduleRun{At: now.Add(time.Duration(i) * time.Minute)})
+	}
+	if len(s.Runs) != MaxScheduleRuns || !s.LastRunAt.Equal(now.Add((MaxScheduleRuns+4)*time.Minute)) {
+		t.Errorf("runs = %d, last run %s", len(s.Runs), s.LastRunAt)
+	}
+}
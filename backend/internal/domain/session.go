@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 )
@@ -20,6 +22,26 @@ const (
 	SessionKindDock = "dock"
 )
 
+// WaitKindQueuedRemote marks a suspended session whose run was submitted to a
+// provider's batch queue and is waiting for the remote result. It is used as
+// the run attempt wait kind and prefixes the suspension transition reason.
+const WaitKindQueuedRemote = "queued_remote"
+
+// WaitKindWaitingOnHuman marks a run suspended on a question the agent asked
+// a human. The agent process stays alive, blocked until the question is
+// answered. It prefixes the suspension transition reason, followed by the
+// question.
+const WaitKindWaitingOnHuman = "waiting_on_human"
+
+// WaitKindToolCall marks a run suspended on one or more external tool calls.
+// Each call is fulfilled with its own resume token.
+const WaitKindToolCall = "tool_call"
+
+// WaitKindShutdown marks a run the server suspended while shutting down. Its
+// attempt is left open for startup recovery, which applies the session's
+// recovery policy. It prefixes the suspension transition reason.
+const WaitKindShutdown = "server_shutdown"
+
 func (s SessionState) String() string {
 	switch s {
 	case SessionStateIdle:
@@ -103,6 +125,8 @@ type StateTransition struct {
 	To        SessionState `json:"to"`
 	Reason    string       `json:"reason"`
 	Timestamp time.Time    `json:"timestamp"`
+	// Notice is the reason as a code, when it has one.
+	Notice *Notice `json:"notice,omitempty"`
 }
 
 // MessageKind identifies the type of a persisted session message.
@@ -117,6 +141,9 @@ const (
 	MessageKindSystem  MessageKind = "system"
 	MessageKindPlan    MessageKind = "plan"
 	MessageKindMetric  MessageKind = "metric"
+	// MessageKindDiagnostic holds provider stderr, kept for troubleshooting
+	// and shown collapsed.
+	MessageKindDiagnostic MessageKind = "diagnostic"
 )
 
 // Message is a single entry in a session's conversation history.
@@ -128,6 +155,12 @@ type Message struct {
 	// Raw holds the original provider-specific bytes that produced this message,
 	// preserved verbatim so callers can re-parse fields not originally extracted.
 	Raw json.RawMessage `json:"raw,omitempty"`
+	// Redacted marks a message whose contents were replaced with
+	// RedactedTombstone.
+	Redacted bool `json:"redacted,omitempty"`
+	// Notice is set on system messages that have a code; Contents holds
+	// its English text.
+	Notice *Notice `json:"notice,omitempty"`
 }
 
 type Session struct {
@@ -144,10 +177,79 @@ type Session struct {
 	// ProviderCustom preserves the original provider-specific config (e.g.
 	// acp_command) so it can be re-supplied when starting a new run on an
 	// idle session via SendMessage.
-	ProviderCustom    map[string]any
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
-	CurrentTask       string
+	ProviderCustom map[string]any
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	CurrentTask    string
+	// TaskID is the strand task the session was created for, if any. Its
+	// details are injected into the context of the session's runs.
+	TaskID string
+	// Pinned sessions are listed first and exempt from stale-session cleanup.
+	Pinned bool
+	// CleanupCommands are the termination hooks run when the session is
+	// stopped, killed or cleaned up.
+	CleanupCommands []string
+	// RecoveryPolicy overrides the provider's startup recovery policy for
+	// this session. Empty means use the provider default.
+	RecoveryPolicy string
+	// PlanApproval stops runs after the agent's first plan until a human
+	// approves it; Plan is that plan.
+	PlanApproval bool
+	Plan         *SessionPlan
+	// Features are the provider-neutral feature flags every run is started
+	// with.
+	Features map[string]bool
+	// Exchange is the session's key-value exchange area.
+	Exchange map[string]ExchangeEntry
+	// BestOfN is the best-of-N group this session is the parent of.
+	BestOfN *BestOfN
+	// Workspace is the checkout made for the session, if it asked for one.
+	Workspace *Workspace
+	// GitTracking is the branch the session works on, if it tracks one.
+	GitTracking *GitTracking
+	// StartCommit is the HEAD of the working directory when the session was
+	// created, if it was a git checkout: what the session's changes are
+	// diffed against.
+	StartCommit string
+	// Handoff is the session's latest switch to another provider.
+	Handoff *Handoff
+	// Takeovers are the windows in which a human drove the session by hand,
+	// oldest first. The last one is still open while the session is taken
+	// over.
+	Takeovers []Takeover
+	// CommandApproval, when set, holds the agent's shell commands for
+	// approval; Commands is the history of commands the agent ran.
+	CommandApproval *CommandApproval
+	Commands        []CommandRecord
+	// ToolApproval, when set, holds the tool calls the provider asks
+	// permission for until a human decides; ToolApprovals is their history.
+	ToolApproval  *ToolApproval
+	ToolApprovals []ToolApprovalRecord
+	// MCPServers are started with every run; ProjectPath is the project's
+	// root when the session was created, for their env templates.
+	MCPServers  []MCPServer
+	ProjectPath string
+	// MessagePins mark the session's key messages, oldest first.
+	MessagePins []MessagePin
+	// QueuedMessages wait for the current run to end, oldest first.
+	QueuedMessages []QueuedMessage
+	// Conversation is the provider conversation the next run continues.
+	Conversation *ProviderConversation
+	// ContextWindow is how full the model's context window was at the
+	// latest request.
+	ContextWindow *ContextWindowUsage
+	// CostBudget limits what the session may spend across its runs.
+	CostBudget *CostBudget
+	// Metadata holds key-value pairs external systems stamp onto the
+	// session, such as ticket numbers or CI run IDs.
+	Metadata map[string]string
+	// MissionID is the mission the session works in, if any.
+	MissionID string
+	// PromptPrefix is the system prompt plus project context, fixed when the
+	// session is created so every run sends a byte-identical, cacheable prefix.
+	PromptPrefix      string
+	PromptCache       *PromptCacheStats
+	Usage             *UsageStats
 	Transitions       []StateTransition
 	Messages          []Message
 	SuspensionContext any // *session.SuspensionContext (to avoid circular import)
@@ -170,6 +272,15 @@ func NewSession(id, providerType, workingDir string) *Session {
 }
 
 func (s *Session) TransitionTo(newState SessionState, reason string) error {
+	return s.transitionTo(newState, reason, nil)
+}
+
+// TransitionWithNotice is TransitionTo with the notice's text as the reason.
+func (s *Session) TransitionWithNotice(newState SessionState, notice Notice) error {
+	return s.transitionTo(newState, notice.Text(), &notice)
+}
+
+func (s *Session) transitionTo(newState SessionState, reason string, notice *Notice) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -182,6 +293,7 @@ func (s *Session) TransitionTo(newState SessionState, reason string) error {
 		To:        newState,
 		Reason:    reason,
 		Timestamp: time.Now(),
+		Notice:    notice,
 	}
 
 	s.Transitions = append(s.Transitions, transition)
@@ -218,6 +330,116 @@ func (s *Session) SetTitle(title string) {
 	s.UpdatedAt = time.Now()
 }
 
+func (s *Session) SetPinned(pinned bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Pinned = pinned
+	s.UpdatedAt = time.Now()
+}
+
+func (s *Session) SetRecoveryPolicy(policy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.RecoveryPolicy = policy
+	s.UpdatedAt = time.Now()
+}
+
+func (s *Session) GetRecoveryPolicy() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.RecoveryPolicy
+}
+
+func (s *Session) IsPinned() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Pinned
+}
+
+func (s *Session) GetPromptPrefix() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.PromptPrefix
+}
+
+// GetFeatures returns a copy of the session's feature flags.
+func (s *Session) GetFeatures() map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.Features)
+}
+
+// GetMCPServers returns the MCP servers started with every run.
+func (s *Session) GetMCPServers() []MCPServer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.MCPServers)
+}
+
+// ProviderConversation is a provider's own conversation, such as a Claude
+// session, that a later run of the same provider type can continue.
+type ProviderConversation struct {
+	ProviderType string `json:"provider_type"`
+	ID           string `json:"id"`
+}
+
+// SetConversation records the conversation a run of providerType reported,
+// for the session's next run to continue.
+func (s *Session) SetConversation(providerType, id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Conversation = &ProviderConversation{ProviderType: providerType, ID: id}
+	s.UpdatedAt = time.Now()
+}
+
+// ConversationFor returns the conversation a run of providerType should
+// continue, or "" when there is none or another provider reported it.
+func (s *Session) ConversationFor(providerType string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.Conversation == nil || s.Conversation.ProviderType != providerType {
+		return ""
+	}
+	return s.Conversation.ID
+}
+
+// RecordPromptCacheUsage adds one request's input token usage to the
+// session's prompt cache totals.
+func (s *Session) RecordPromptCacheUsage(inputTokens, cacheReadTokens, cacheCreationTokens int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.PromptCache == nil {
+		s.PromptCache = &PromptCacheStats{}
+	}
+	s.PromptCache.Requests++
+	s.PromptCache.InputTokens += inputTokens
+	s.PromptCache.CacheReadTokens += cacheReadTokens
+	s.PromptCache.CacheCreationTokens += cacheCreationTokens
+}
+
+// RecordUsage adds the tokens and cost of a metric event to the session's
+// usage totals.
+func (s *Session) RecordUsage(tokensIn, tokensOut int64, costUSD float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Usage == nil {
+		s.Usage = &UsageStats{}
+	}
+	s.Usage.InputTokens += tokensIn
+	s.Usage.OutputTokens += tokensOut
+	s.Usage.CostUSD += costUSD
+}
+
+// GetUsage returns the session's usage totals.
+func (s *Session) GetUsage() UsageStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.Usage == nil {
+		return UsageStats{}
+	}
+	return *s.Usage
+}
+
 func (s *Session) SetPreferredProviderID(providerID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -244,18 +466,38 @@ func (s *Session) AppendMessageRaw(kind MessageKind, contents string, raw json.R
 	s.UpdatedAt = time.Now()
 }
 
+// AppendNotice appends a message with the notice's text as its contents.
+func (s *Session) AppendNotice(kind MessageKind, notice Notice) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Messages = append(s.Messages, Message{
+		ID:        fmt.Sprintf("%s_%d", kind, time.Now().UnixNano()),
+		Kind:      kind,
+		Contents:  notice.Text(),
+		Timestamp: time.Now(),
+		Notice:    &notice,
+	})
+	s.UpdatedAt = time.Now()
+}
+
 // AppendOutputDelta appends streaming text to the last output message if one
 // exists, or creates a new output message. This accumulates delta chunks into a
 // single coherent message rather than producing one entry per chunk.
 func (s *Session) AppendOutputDelta(delta string) {
+	s.AppendDelta(MessageKindOutput, delta)
+}
+
+// AppendDelta appends text to the last message if it has the given kind, or
+// creates a new message of that kind.
+func (s *Session) AppendDelta(kind MessageKind, delta string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if n := len(s.Messages); n > 0 && s.Messages[n-1].Kind == MessageKindOutput {
+	if n := len(s.Messages); n > 0 && s.Messages[n-1].Kind == kind {
 		s.Messages[n-1].Contents += delta
 	} else {
 		s.Messages = append(s.Messages, Message{
-			ID:        fmt.Sprintf("%s_%d", MessageKindOutput, time.Now().UnixNano()),
-			Kind:      MessageKindOutput,
+			ID:        fmt.Sprintf("%s_%d", kind, time.Now().UnixNano()),
+			Kind:      kind,
 			Contents:  delta,
 			Timestamp: time.Now(),
 		})
@@ -296,19 +538,49 @@ type SessionSnapshot struct {
 	ProviderType        string `json:"provider_type"`
 	PreferredProviderID string `json:"preferred_provider_id,omitempty"`
 	// AgentID is the ID of the AgentConfig applied to this session (if any).
-	AgentID           string            `json:"agent_id,omitempty"`
-	Kind              string            `json:"kind,omitempty"`
-	Title             string            `json:"title,omitempty"`
-	State             SessionState      `json:"state"`
-	WorkingDir        string            `json:"working_dir"`
-	ProjectID         string            `json:"project_id,omitempty"`
-	ProviderCustom    map[string]any    `json:"provider_custom,omitempty"`
-	CreatedAt         time.Time         `json:"created_at"`
-	UpdatedAt         time.Time         `json:"updated_at"`
-	CurrentTask       string            `json:"current_task,omitempty"`
-	Transitions       []StateTransition `json:"transitions"`
-	Messages          []Message         `json:"messages,omitempty"`
-	SuspensionContext any               `json:"-"` // *session.SuspensionContext
+	AgentID           string                   `json:"agent_id,omitempty"`
+	Kind              string                   `json:"kind,omitempty"`
+	Title             string                   `json:"title,omitempty"`
+	State             SessionState             `json:"state"`
+	WorkingDir        string                   `json:"working_dir"`
+	ProjectID         string                   `json:"project_id,omitempty"`
+	ProviderCustom    map[string]any           `json:"provider_custom,omitempty"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         time.Time                `json:"updated_at"`
+	CurrentTask       string                   `json:"current_task,omitempty"`
+	TaskID            string                   `json:"task_id,omitempty"`
+	Pinned            bool                     `json:"pinned,omitempty"`
+	PromptPrefix      string                   `json:"prompt_prefix,omitempty"`
+	PromptCache       *PromptCacheStats        `json:"prompt_cache,omitempty"`
+	Usage             *UsageStats              `json:"usage,omitempty"`
+	CleanupCommands   []string                 `json:"cleanup_commands,omitempty"`
+	RecoveryPolicy    string                   `json:"recovery_policy,omitempty"`
+	PlanApproval      bool                     `json:"plan_approval,omitempty"`
+	Plan              *SessionPlan             `json:"plan,omitempty"`
+	Features          map[string]bool          `json:"features,omitempty"`
+	Exchange          map[string]ExchangeEntry `json:"exchange,omitempty"`
+	BestOfN           *BestOfN                 `json:"best_of_n,omitempty"`
+	Workspace         *Workspace               `json:"workspace,omitempty"`
+	GitTracking       *GitTracking             `json:"git_tracking,omitempty"`
+	StartCommit       string                   `json:"start_commit,omitempty"`
+	Handoff           *Handoff                 `json:"handoff,omitempty"`
+	Takeovers         []Takeover               `json:"takeovers,omitempty"`
+	CommandApproval   *CommandApproval         `json:"command_approval,omitempty"`
+	Commands          []CommandRecord          `json:"commands,omitempty"`
+	ToolApproval      *ToolApproval            `json:"tool_approval,omitempty"`
+	ToolApprovals     []ToolApprovalRecord     `json:"tool_approvals,omitempty"`
+	MCPServers        []MCPServer              `json:"mcp_servers,omitempty"`
+	ProjectPath       string                   `json:"project_path,omitempty"`
+	MessagePins       []MessagePin             `json:"message_pins,omitempty"`
+	QueuedMessages    []QueuedMessage          `json:"queued_messages,omitempty"`
+	Conversation      *ProviderConversation    `json:"conversation,omitempty"`
+	ContextWindow     *ContextWindowUsage      `json:"context_window,omitempty"`
+	CostBudget        *CostBudget              `json:"cost_budget,omitempty"`
+	Metadata          map[string]string        `json:"metadata,omitempty"`
+	MissionID         string                   `json:"mission_id,omitempty"`
+	Transitions       []StateTransition        `json:"transitions"`
+	Messages          []Message                `json:"messages,omitempty"`
+	SuspensionContext any                      `json:"-"` // *session.SuspensionContext
 }
 
 // Snapshot returns an atomic copy of the session under its read lock.
@@ -322,6 +594,31 @@ func (s *Session) Snapshot() SessionSnapshot {
 	messages := make([]Message, len(s.Messages))
 	copy(messages, s.Messages)
 
+	var commands []CommandRecord
+	for _, c := range s.Commands {
+		commands = append(commands, c.clone())
+	}
+	var toolApprovals []ToolApprovalRecord
+	for _, r := range s.ToolApprovals {
+		toolApprovals = append(toolApprovals, r.clone())
+	}
+
+	var promptCache *PromptCacheStats
+	if s.PromptCache != nil {
+		stats := *s.PromptCache
+		promptCache = &stats
+	}
+	var contextWindow *ContextWindowUsage
+	if s.ContextWindow != nil {
+		u := *s.ContextWindow
+		contextWindow = &u
+	}
+	var usage *UsageStats
+	if s.Usage != nil {
+		stats := *s.Usage
+		usage = &stats
+	}
+
 	return SessionSnapshot{
 		ID:                  s.ID,
 		ProviderType:        s.ProviderType,
@@ -336,6 +633,36 @@ func (s *Session) Snapshot() SessionSnapshot {
 		CreatedAt:           s.CreatedAt,
 		UpdatedAt:           s.UpdatedAt,
 		CurrentTask:         s.CurrentTask,
+		TaskID:              s.TaskID,
+		Pinned:              s.Pinned,
+		PromptPrefix:        s.PromptPrefix,
+		PromptCache:         promptCache,
+		Usage:               usage,
+		CleanupCommands:     s.CleanupCommands,
+		RecoveryPolicy:      s.RecoveryPolicy,
+		PlanApproval:        s.PlanApproval,
+		Plan:                s.Plan.clone(),
+		Features:            maps.Clone(s.Features),
+		Exchange:            maps.Clone(s.Exchange),
+		BestOfN:             s.BestOfN.clone(),
+		Workspace:           s.Workspace.clone(),
+		GitTracking:         s.GitTracking.clone(),
+		StartCommit:         s.StartCommit,
+		Handoff:             s.Handoff.clone(),
+		Takeovers:           slices.Clone(s.Takeovers),
+		CommandApproval:     s.CommandApproval.clone(),
+		Commands:            commands,
+		ToolApproval:        s.ToolApproval.clone(),
+		ToolApprovals:       toolApprovals,
+		MCPServers:          slices.Clone(s.MCPServers),
+		ProjectPath:         s.ProjectPath,
+		MessagePins:         slices.Clone(s.MessagePins),
+		QueuedMessages:      slices.Clone(s.QueuedMessages),
+		Conversation:        s.Conversation,
+		ContextWindow:       contextWindow,
+		CostBudget:          s.CostBudget.clone(),
+		Metadata:            maps.Clone(s.Metadata),
+		MissionID:           s.MissionID,
 		Transitions:         transitions,
 		Messages:            messages,
 		SuspensionContext:   s.SuspensionContext,
@@ -357,7 +684,54 @@ func SessionFromSnapshot(snap SessionSnapshot) *Session {
 		CreatedAt:           snap.CreatedAt,
 		UpdatedAt:           snap.UpdatedAt,
 		CurrentTask:         snap.CurrentTask,
+		TaskID:              snap.TaskID,
+		Pinned:              snap.Pinned,
+		PromptPrefix:        snap.PromptPrefix,
+		PromptCache:         snap.PromptCache,
+		Usage:               snap.Usage,
+		CleanupCommands:     snap.CleanupCommands,
+		RecoveryPolicy:      snap.RecoveryPolicy,
+		PlanApproval:        snap.PlanApproval,
+		Plan:                snap.Plan,
+		Features:            snap.Features,
+		Exchange:            snap.Exchange,
+		BestOfN:             snap.BestOfN,
+		Workspace:           snap.Workspace,
+		GitTracking:         snap.GitTracking,
+		StartCommit:         snap.StartCommit,
+		Handoff:             snap.Handoff,
+		Takeovers:           snap.Takeovers,
+		CommandApproval:     snap.CommandApproval,
+		Commands:            snap.Commands,
+		ToolApproval:        snap.ToolApproval,
+		ToolApprovals:       snap.ToolApprovals,
+		MCPServers:          snap.MCPServers,
+		ProjectPath:         snap.ProjectPath,
+		MessagePins:         snap.MessagePins,
+		QueuedMessages:      snap.QueuedMessages,
+		Conversation:        snap.Conversation,
+		ContextWindow:       snap.ContextWindow,
+		CostBudget:          snap.CostBudget,
+		Metadata:            snap.Metadata,
+		MissionID:           snap.MissionID,
 		Transitions:         snap.Transitions,
 		Messages:            snap.Messages,
 	}
 }
+
+// UsageStats totals the tokens and cost a session's provider reported. Cost
+// is only known for providers that report it.
+type UsageStats struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+}
+
+// PromptCacheStats accumulates per-request input token usage for a session,
+// split by how the provider's prompt cache served it.
+type PromptCacheStats struct {
+	Requests            int64 `json:"requests"`
+	InputTokens         int64 `json:"input_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens"`
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"time"
+)
+
+// Limits on a session's metadata, which external systems use to stamp their
+// own correlation IDs onto sessions.
+const (
+	MaxMetadataEntries  = 32
+	MaxMetadataKeyLen   = 64
+	MaxMetadataValueLen = 256
+)
+
+// ErrInvalidMetadata reports session metadata over the limits.
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+var metadataKeyRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+// ValidateMetadata reports whether metadata is within the limits. Keys are
+// letters, digits and "._:-"; values must not be empty.
+func ValidateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataEntries {
+		return fmt.Errorf("%w: %d entries exceeds %d", ErrInvalidMetadata, len(metadata), MaxMetadataEntries)
+	}
+	for k, v := range metadata {
+		if len(k) > MaxMetadataKeyLen || !metadataKeyRegex.MatchString(k) {
+			return fmt.Errorf("%w: key %q", ErrInvalidMetadata, k)
+		}
+		if v == "" || len(v) > MaxMetadataValueLen {
+			return fmt.Errorf("%w: value of %q must be 1 to %d bytes", ErrInvalidMetadata, k, MaxMetadataValueLen)
+		}
+	}
+	return nil
+}
+
+// MatchesMetadata reports whether metadata holds every entry of filter
+// exactly.
+func MatchesMetadata(metadata, filter map[string]string) bool {
+	for k, v := range filter {
+		if got, ok := metadata[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PatchMetadata applies patch to the session's metadata: a nil value removes
+// its key, any other sets it. The session is left unchanged when the result
+// is over the limits.
+func (s *Session) PatchMetadata(patch map[string]*string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metadata := maps.Clone(s.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string, len(patch))
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(metadata, k)
+		} else {
+			metadata[k] = *v
+		}
+	}
+	if err := ValidateMetadata(metadata); err != nil {
+		return err
+	}
+	if len(metadata) == 0 {
+		metadata = nil
+	}
+	s.Metadata = metadata
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// GetMetadata returns a copy of the session's metadata.
+func (s *Session) GetMetadata() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.Metadata)
+}
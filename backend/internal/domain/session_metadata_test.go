@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSession_PatchMetadata(t *testing.T) {
+	s := NewSession("s1", "mock", "/tmp")
+	v := "ENG-12"
+	if err := s.PatchMetadata(map[string]*string{"ticket": &v}); err != nil {
+		t.Fatalf("PatchMetadata: %v", err)
+	}
+	if !MatchesMetadata(s.GetMetadata(), map[string]string{"ticket": "ENG-12"}) || MatchesMetadata(s.GetMetadata(), map[string]string{"ticket": "ENG-1"}) {
+		t.Fatalf("unexpected metadata %v", s.GetMetadata())
+	}
+
+	long := strings.Repeat("x", MaxMetadataValueLen+1)
+	if err := s.PatchMetadata(map[string]*string{"ticket": nil, "long": &long}); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expected a long value to be rejected, got %v", err)
+	}
+	if s.GetMetadata()["ticket"] != "ENG-12" {
+		t.Fatal("a rejected patch must leave the metadata unchanged")
+	}
+
+	full := make(map[string]*string)
+	for i := range MaxMetadataEntries {
+		full[fmt.Sprintf("k%d", i)] = &v
+	}
+	if err := s.PatchMetadata(full); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expected too many entries to be rejected, got %v", err)
+	}
+	if err := s.PatchMetadata(map[string]*string{"ticket": nil}); err != nil || s.GetMetadata() != nil {
+		t.Fatalf("expected the last key's removal to clear the metadata, got %v %v", err, s.GetMetadata())
+	}
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxBriefingMessages and maxBriefingMessageLen bound how much of what
+// happened during a takeover its briefing quotes.
+const (
+	maxBriefingMessages   = 20
+	maxBriefingMessageLen = 500
+)
+
+// Takeover is a window in which a human drove a session by hand. While it
+// is open the agent gets no input except from the human who took over; on
+// hand-back the agent is briefed on what happened in the meantime.
+type Takeover struct {
+	By        string    `json:"by"`
+	Reason    string    `json:"reason,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	// StartMessage is how many messages the session had when the takeover
+	// started; the briefing covers the messages after it.
+	StartMessage int        `json:"start_message"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	HandedBackBy string     `json:"handed_back_by,omitempty"`
+	Note         string     `json:"note,omitempty"`
+	Briefing     string     `json:"briefing,omitempty"`
+	// BriefingError is why the briefing could not be given to the agent.
+	BriefingError string `json:"briefing_error,omitempty"`
+}
+
+// Active reports whether the takeover has not been handed back yet.
+func (t *Takeover) Active() bool {
+	return t != nil && t.EndedAt == nil
+}
+
+// TakeoverBriefing tells the agent a human drove the session during t and
+// what happened meanwhile: the messages added during the takeover and the
+// note the human left when handing back.
+func TakeoverBriefing(t Takeover, messages []Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "A human (%s) took over this session", t.By)
+	if t.EndedAt != nil {
+		fmt.Fprintf(&b, " for %s", t.EndedAt.Sub(t.StartedAt).Round(time.Second))
+	}
+	b.WriteString(" and has handed it back to you. Your input was suspended meanwhile, ")
+	b.WriteString("so the working directory, the terminal and any running processes may have changed. ")
+	b.WriteString("Check their current state before you continue.\n")
+	if t.Reason != "" {
+		b.WriteString("\n## Why they took over\n" + t.Reason + "\n")
+	}
+
+	var during []string
+	for _, m := range messages[min(t.StartMessage, len(messages)):] {
+		if m.Redacted {
+			continue
+		}
+		var who string
+		switch m.Kind {
+		case MessageKindUser:
+			who = t.By
+		case MessageKindOutput:
+			who = "agent"
+		default:
+			continue
+		}
+		text := strings.TrimSpace(m.Contents)
+		if runes := []rune(text); len(runes) > maxBriefingMessageLen {
+			text = string(runes[:maxBriefingMessageLen]) + "…"
+		}
+		during = append(during, fmt.Sprintf("- %s: %s", who, strings.ReplaceAll(text, "\n", "\n  ")))
+	}
+	if len(during) > 0 {
+		b.WriteString("\n## What happened during the takeover\n")
+		if skipped := len(during) - maxBriefingMessages; skipped > 0 {
+			fmt.Fprintf(&b, "(%d earlier messages omitted)\n", skipped)
+			during = during[skipped:]
+		}
+		b.WriteString(strings.Join(during, "\n") + "\n")
+	}
+	if t.Note != "" {
+		fmt.Fprintf(&b, "\n## Note from %s\n%s\n", t.HandedBackBy, t.Note)
+	}
+	return b.String()
+}
+
+// ActiveTakeover returns the session's open takeover, or nil.
+func (s *Session) ActiveTakeover() *Takeover {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n := len(s.Takeovers); n > 0 && s.Takeovers[n-1].Active() {
+		t := s.Takeovers[n-1]
+		return &t
+	}
+	return nil
+}
+
+// GetTakeovers returns the session's takeovers, oldest first.
+func (s *Session) GetTakeovers() []Takeover {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.Takeovers)
+}
+
+// StartTakeover opens t unless a takeover is already open.
+func (s *Session) StartTakeover(t Takeover) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.Takeovers); n > 0 && s.Takeovers[n-1].Active() {
+		return false
+	}
+	s.Takeovers = append(s.Takeovers, t)
+	s.UpdatedAt = time.Now()
+	return true
+}
+
+// EndTakeover closes the open takeover, recording who handed the session
+// back and their note, and returns it. It reports false if no takeover is
+// open.
+func (s *Session) EndTakeover(by, note string, at time.Time) (Takeover, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.Takeovers)
+	if n == 0 || !s.Takeovers[n-1].Active() {
+		return Takeover{}, false
+	}
+	t := &s.Takeovers[n-1]
+	t.EndedAt = &at
+	t.HandedBackBy = by
+	t.Note = note
+	s.UpdatedAt = time.Now()
+	return *t, true
+}
+
+// UpdateTakeover replaces the session's latest takeover.
+func (s *Session) UpdateTakeover(t Takeover) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n := len(s.Takeovers); n > 0 {
+		s.Takeovers[n-1] = t
+		s.UpdatedAt = time.Now()
+	}
+}
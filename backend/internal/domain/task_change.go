@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// Task change operations, the task mutations the strand MCP tools journal.
+const (
+	TaskChangeAdd      = "add"
+	TaskChangeClaim    = "claim"
+	TaskChangeComplete = "complete"
+	TaskChangeEdit     = "edit"
+)
+
+// MaxTaskChanges is how many task changes the journal keeps; older ones are
+// dropped and can no longer be undone.
+const MaxTaskChanges = 1000
+
+// TaskChange is one task mutation made through the strand MCP tools. Before
+// and After are the task as `strand show --format json` printed it around
+// the change; Before is empty for a created task.
+type TaskChange struct {
+	ID     string `json:"id"`
+	TaskID string `json:"task_id"`
+	Op     string `json:"op"`
+	// Todo is the 1-based todo a complete change completed, if not the
+	// whole task.
+	Todo      int    `json:"todo,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+	// WorkingDir and ProjectDir locate the strand project the change was
+	// made in, so it is undone in the same one.
+	WorkingDir string    `json:"working_dir,omitempty"`
+	ProjectDir string    `json:"project_dir,omitempty"`
+	Summary    string    `json:"summary,omitempty"`
+	Before     string    `json:"before,omitempty"`
+	After      string    `json:"after,omitempty"`
+	At         time.Time `json:"at"`
+	// UndoneAt and UndoneBy are set once the change has been reverted.
+	UndoneAt *time.Time `json:"undone_at,omitempty"`
+	UndoneBy string     `json:"undone_by,omitempty"`
+}
+
+// Undone reports whether the change has been reverted.
+func (c TaskChange) Undone() bool {
+	return c.UndoneAt != nil
+}
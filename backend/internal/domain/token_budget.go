@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Token budget actions: what happens when a run uses up its token budget.
+const (
+	// BudgetStop stops the run.
+	BudgetStop = "stop"
+	// BudgetContinue finalizes the run with a summary of its working
+	// context and continues in a fresh run that starts from the summary.
+	BudgetContinue = "continue"
+)
+
+// ValidBudgetAction reports whether action is empty (BudgetStop) or a known
+// token budget action.
+func ValidBudgetAction(action string) bool {
+	return action == "" || action == BudgetStop || action == BudgetContinue
+}
+
+// BudgetSummaryNote asks a running agent that used up its token budget to
+// stop and summarize its working context for the run that continues it.
+func BudgetSummaryNote(budget int64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "This run has used its token budget of %d tokens and will continue in a fresh run without your current context. ", budget)
+	b.WriteString("Do not make further changes. ")
+	writeHandoffRequest(&b)
+	return b.String()
+}
+
+// ContinuationPrompt renders the document as the first message of the run
+// that continues one stopped at its token budget. request is the message
+// that started the work, repeated so the fresh run keeps the goal.
+func (d HandoffDocument) ContinuationPrompt(request string) string {
+	var b strings.Builder
+	b.WriteString("You are continuing work from an earlier run of this session that reached its token budget. ")
+	b.WriteString("This is its summary of the work so far.\n")
+	d.writeSections(&b)
+	if request = strings.TrimSpace(request); request != "" {
+		b.WriteString("\n## Original request\n" + request + "\n")
+	}
+	b.WriteString("\nContinue the work from here.\n")
+	return b.String()
+}
+
+// maxFallbackOutput bounds the agent output quoted in a fallback summary.
+const maxFallbackOutput = 4000
+
+// BudgetFallbackDocument summarizes a run that was stopped before it could
+// summarize itself, from the messages it added: its latest output and the
+// tools it used.
+func BudgetFallbackDocument(messages []Message) HandoffDocument {
+	var output string
+	var tools []string
+	seen := make(map[string]bool)
+	for _, m := range messages {
+		if m.Redacted {
+			continue
+		}
+		switch m.Kind {
+		case MessageKindOutput:
+			if strings.TrimSpace(m.Contents) != "" {
+				output = m.Contents
+			}
+		case MessageKindToolUse:
+			name, _, _ := strings.Cut(m.Contents, ":")
+			if name != "" && !seen[name] {
+				seen[name] = true
+				tools = append(tools, name)
+			}
+		}
+	}
+	summary := "The previous run was stopped at its token budget before it could summarize its work."
+	if output = strings.TrimSpace(output); output != "" {
+		if len(output) > maxFallbackOutput {
+			output = "..." + strings.ToValidUTF8(output[len(output)-maxFallbackOutput:], "")
+		}
+		summary += " Its last output was:\n\n" + output
+	}
+	if len(tools) > 0 {
+		summary += "\n\nTools it used: " + strings.Join(tools, ", ") + "."
+	}
+	return HandoffDocument{
+		Summary:   summary,
+		NextSteps: []string{"Check the working directory for changes the previous run made before continuing."},
+	}
+}
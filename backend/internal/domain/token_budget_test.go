@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContinuationPrompt(t *testing.T) {
+	doc := ParseHandoffDocument("## Summary\nSplit the lexer out.\n## Next steps\n- Move the tests\n")
+	prompt := doc.ContinuationPrompt("  refactor the parser ")
+	for _, want := range []string{"reached its token budget", "## Summary\nSplit the lexer out.", "## Next steps\n- Move the tests", "## Original request\nrefactor the parser\n"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(HandoffDocument{Summary: "x"}.ContinuationPrompt(""), "Original request") {
+		t.Error("empty request should have no section")
+	}
+}
+
+func TestBudgetFallbackDocument(t *testing.T) {
+	doc := BudgetFallbackDocument([]Message{
+		{Kind: MessageKindUser, Contents: "refactor the parser"},
+		{Kind: MessageKindToolUse, Contents: "Edit: lexer.go"},
+		{Kind: MessageKindOutput, Contents: "first output"},
+		{Kind: MessageKindToolUse, Contents: "Edit: parser.go"},
+		{Kind: MessageKindOutput, Contents: "secret", Redacted: true},
+		{Kind: MessageKindOutput, Contents: "moved the lexer"},
+	})
+	if !strings.Contains(doc.Summary, "moved the lexer") || strings.Contains(doc.Summary, "first output") || strings.Contains(doc.Summary, "secret") {
+		t.Errorf("summary should quote only the last unredacted output: %q", doc.Summary)
+	}
+	if !strings.Contains(doc.Summary, "Tools it used: Edit.") {
+		t.Errorf("summary should list tools once: %q", doc.Summary)
+	}
+	if len(doc.NextSteps) == 0 {
+		t.Error("fallback should suggest a next step")
+	}
+
+	long := BudgetFallbackDocument([]Message{{Kind: MessageKindOutput, Contents: strings.Repeat("é", maxFallbackOutput)}})
+	if len(long.Summary) > maxFallbackOutput+200 || !strings.Contains(long.Summary, "...") {
+		t.Errorf("long output should be truncated, got %d bytes", len(long.Summary))
+	}
+}
@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// WaitKindToolApproval marks a run suspended on a tool call its provider
+// asked permission for, in a session that requires tool approval.
+const WaitKindToolApproval = "tool_approval"
+
+// MaxSessionToolApprovals bounds a session's tool approval history; the
+// oldest approvals are dropped first.
+const MaxSessionToolApprovals = 500
+
+// What happens to a tool call nobody decided on within the timeout.
+const (
+	ToolApprovalTimeoutDeny  = "deny"
+	ToolApprovalTimeoutAllow = "allow"
+)
+
+// ValidToolApprovalTimeoutAction reports whether action is a known timeout
+// action. Empty means ToolApprovalTimeoutDeny.
+func ValidToolApprovalTimeoutAction(action string) bool {
+	return action == "" || action == ToolApprovalTimeoutDeny || action == ToolApprovalTimeoutAllow
+}
+
+// ToolApproval makes each tool call the provider asks permission for wait
+// for a human to allow or deny it.
+type ToolApproval struct {
+	// AutoAllow lists tool names that run without asking.
+	AutoAllow []string `json:"auto_allow,omitempty"`
+	// Timeout is how long a call waits for a decision. Zero waits until
+	// the run ends.
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// OnTimeout is ToolApprovalTimeoutDeny (the default) or
+	// ToolApprovalTimeoutAllow.
+	OnTimeout string `json:"on_timeout,omitempty"`
+}
+
+// AutoAllows reports whether calls of tool run without asking.
+func (a *ToolApproval) AutoAllows(tool string) bool {
+	return a == nil || slices.Contains(a.AutoAllow, tool)
+}
+
+func (a *ToolApproval) clone() *ToolApproval {
+	if a == nil {
+		return nil
+	}
+	out := *a
+	out.AutoAllow = slices.Clone(a.AutoAllow)
+	return &out
+}
+
+// ToolApprovalStatus is pending until the call is allowed or denied.
+type ToolApprovalStatus string
+
+const (
+	ToolApprovalPending ToolApprovalStatus = "pending"
+	ToolApprovalAllowed ToolApprovalStatus = "allowed"
+	ToolApprovalDenied  ToolApprovalStatus = "denied"
+)
+
+// ToolApprovalDecidedByTimeout is the DecidedBy of a call decided by the
+// session's timeout action.
+const ToolApprovalDecidedByTimeout = "timeout"
+
+// ToolApprovalRecord is one tool call that asked a human for permission.
+type ToolApprovalRecord struct {
+	ID string `json:"id"`
+	// ToolCallID is the provider's ID for the tool call.
+	ToolCallID  string             `json:"tool_call_id,omitempty"`
+	ToolName    string             `json:"tool_name"`
+	Input       map[string]any     `json:"input,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Status      ToolApprovalStatus `json:"status"`
+	RequestedAt time.Time          `json:"requested_at"`
+	DecidedBy   string             `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time         `json:"decided_at,omitempty"`
+	// Reason explains the decision; a denial's reason is given to the
+	// agent.
+	Reason string `json:"reason,omitempty"`
+	// UpdatedInput replaces Input when the approver edited the call.
+	UpdatedInput map[string]any `json:"updated_input,omitempty"`
+}
+
+// Pending reports whether the call is waiting for a decision.
+func (r ToolApprovalRecord) Pending() bool {
+	return r.Status == ToolApprovalPending
+}
+
+func (r ToolApprovalRecord) clone() ToolApprovalRecord {
+	r.Input = maps.Clone(r.Input)
+	r.UpdatedInput = maps.Clone(r.UpdatedInput)
+	if r.DecidedAt != nil {
+		t := *r.DecidedAt
+		r.DecidedAt = &t
+	}
+	return r
+}
+
+func (s *Session) SetToolApproval(approval *ToolApproval) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ToolApproval = approval.clone()
+	s.UpdatedAt = time.Now()
+}
+
+// GetToolApproval returns a copy of the session's tool approval policy, or
+// nil when tools run without approval.
+func (s *Session) GetToolApproval() *ToolApproval {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ToolApproval.clone()
+}
+
+// GetToolApprovals returns a copy of the session's tool approval history,
+// oldest first.
+func (s *Session) GetToolApprovals() []ToolApprovalRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]ToolApprovalRecord, len(s.ToolApprovals))
+	for i, r := range s.ToolApprovals {
+		out[i] = r.clone()
+	}
+	return out
+}
+
+// GetToolApprovalRecord returns the tool approval with the given ID.
+func (s *Session) GetToolApprovalRecord(id string) (ToolApprovalRecord, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if i := s.toolApprovalIndex(id); i >= 0 {
+		return s.ToolApprovals[i].clone(), true
+	}
+	return ToolApprovalRecord{}, false
+}
+
+// AddToolApprovalRecord appends r to the session's tool approval history.
+func (s *Session) AddToolApprovalRecord(r ToolApprovalRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ToolApprovals = append(s.ToolApprovals, r.clone())
+	if over := len(s.ToolApprovals) - MaxSessionToolApprovals; over > 0 {
+		s.ToolApprovals = slices.Delete(s.ToolApprovals, 0, over)
+	}
+	s.UpdatedAt = time.Now()
+}
+
+// UpdateToolApprovalRecord applies update to the tool approval with the
+// given ID and returns the result.
+func (s *Session) UpdateToolApprovalRecord(id string, update func(*ToolApprovalRecord)) (ToolApprovalRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.toolApprovalIndex(id)
+	if i < 0 {
+		return ToolApprovalRecord{}, false
+	}
+	update(&s.ToolApprovals[i])
+	s.UpdatedAt = time.Now()
+	return s.ToolApprovals[i].clone(), true
+}
+
+// toolApprovalIndex must be called with s.mu held. It searches newest
+// first.
+func (s *Session) toolApprovalIndex(id string) int {
+	for i := len(s.ToolApprovals) - 1; i >= 0; i-- {
+		if s.ToolApprovals[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
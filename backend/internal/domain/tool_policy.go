@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// FileWriteTools are the tool names whose calls modify the file named in
+// the "file_path" or "notebook_path" field of their input.
+var FileWriteTools = []string{"Edit", "Write", "MultiEdit", "NotebookEdit"}
+
+// ToolWritePaths returns the files a tool call modifies, if it is one of
+// FileWriteTools.
+func ToolWritePaths(name string, input any) []string {
+	if !slices.Contains(FileWriteTools, name) {
+		return nil
+	}
+	fields, _ := input.(map[string]any)
+	for _, key := range []string{"file_path", "notebook_path"} {
+		if path, _ := fields[key].(string); path != "" {
+			return []string{path}
+		}
+	}
+	return nil
+}
+
+// ToolPolicyOutcome is what a tool policy decided about a call. An empty
+// outcome means no rule applied and the call goes on to the session's
+// approvals, if any.
+type ToolPolicyOutcome string
+
+const (
+	ToolPolicyAllow ToolPolicyOutcome = "allow"
+	ToolPolicyDeny  ToolPolicyOutcome = "deny"
+)
+
+// ToolPolicy decides tool calls by rule so agents can run without a human
+// approving each call. Rules are checked in order: denied tools, denied
+// commands, write paths, allowed commands, allowed tools, then Default.
+type ToolPolicy struct {
+	// Allow and Deny list tool names. ACP agents' tools are named by kind,
+	// e.g. "edit" or "execute".
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+	// WritePaths, if set, denies file edits outside them. Each is a path or
+	// glob relative to the session's working directory, or absolute; a
+	// trailing "/**" matches everything under a directory. Shell commands
+	// are not covered: a command that may write (tee, cp, a script) is
+	// only limited by the command rules.
+	WritePaths []string `json:"write_paths,omitempty"`
+	// AllowCommands and DenyCommands are regular expressions matched
+	// against shell commands. AllowCommands never allows a command with
+	// shell operators (;, &, |, backticks, $(, ${, redirects, newlines).
+	AllowCommands []string `json:"allow_commands,omitempty"`
+	DenyCommands  []string `json:"deny_commands,omitempty"`
+	// Default decides calls no rule matches: ToolPolicyAllow,
+	// ToolPolicyDeny, or empty to leave them to the session's approvals.
+	Default ToolPolicyOutcome `json:"default,omitempty"`
+}
+
+// Validate checks the policy's default and compiles its command patterns.
+func (p *ToolPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	switch p.Default {
+	case "", ToolPolicyAllow, ToolPolicyDeny:
+	default:
+		return fmt.Errorf("unknown default %q (want %s or %s)", p.Default, ToolPolicyAllow, ToolPolicyDeny)
+	}
+	for _, pattern := range slices.Concat(p.AllowCommands, p.DenyCommands) {
+		if _, err := regexp.Compile(pattern); err != nil || pattern == "" {
+			return fmt.Errorf("invalid command pattern %q", pattern)
+		}
+	}
+	for _, path := range p.WritePaths {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("empty write path")
+		}
+	}
+	return nil
+}
+
+// ToolPolicyCall is a tool call as tool policies see it.
+type ToolPolicyCall struct {
+	ToolCallID string
+	Tool       string
+	// Command is the shell command the call runs, if any.
+	Command string
+	// WritePaths are the files the call modifies.
+	WritePaths []string
+}
+
+// NewToolPolicyCall describes a call of a tool that takes its command or
+// file from the usual input fields; see ToolCommand and ToolWritePaths.
+func NewToolPolicyCall(toolCallID, name string, input any) ToolPolicyCall {
+	command, _ := ToolCommand(name, input)
+	return ToolPolicyCall{
+		ToolCallID: toolCallID,
+		Tool:       name,
+		Command:    command,
+		WritePaths: ToolWritePaths(name, input),
+	}
+}
+
+// ToolPolicyDecision is the outcome of a session's tool policies for one
+// call, and which policy and rule produced it.
+type ToolPolicyDecision struct {
+	Outcome ToolPolicyOutcome
+	// Source is "project" or "agent".
+	Source string
+	Rule   string
+}
+
+// Reason explains a denial to the agent.
+func (d ToolPolicyDecision) Reason() string {
+	return fmt.Sprintf("denied by the %s tool policy (%s)", d.Source, d.Rule)
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"slices"
+	"sort"
+)
+
+// VisibilityProfile selects which message kinds and metadata keys a consumer
+// sees, so people, downstream agents and exports can each get the verbosity
+// they need from the same session.
+type VisibilityProfile struct {
+	Name string
+	// Kinds lists the message kinds shown; nil shows every kind.
+	Kinds []MessageKind
+	// MetadataKeys lists the metadata event keys shown; nil shows every key
+	// and an empty list hides metadata events.
+	MetadataKeys []string
+	// Raw keeps the raw provider payloads of messages.
+	Raw bool
+}
+
+const (
+	VisibilityProfileChat  = "chat"
+	VisibilityProfileDebug = "debug"
+	VisibilityProfileAudit = "audit"
+)
+
+var visibilityProfiles = map[string]VisibilityProfile{
+	// chat is the conversation as a person reads it.
+	VisibilityProfileChat: {
+		Name:         VisibilityProfileChat,
+		Kinds:        []MessageKind{MessageKindUser, MessageKindOutput, MessageKindError, MessageKindPlan},
+		MetadataKeys: []string{},
+	},
+	// audit is what the agent was told and did, without its reasoning or
+	// usage metrics.
+	VisibilityProfileAudit: {
+		Name:         VisibilityProfileAudit,
+		Kinds:        []MessageKind{MessageKindUser, MessageKindOutput, MessageKindToolUse, MessageKindError, MessageKindSystem, MessageKindDiagnostic, MessageKindPlan},
+		MetadataKeys: []string{},
+	},
+	// debug is everything, including raw provider payloads.
+	VisibilityProfileDebug: {
+		Name: VisibilityProfileDebug,
+		Raw:  true,
+	},
+}
+
+// LookupVisibilityProfile returns the named profile.
+func LookupVisibilityProfile(name string) (VisibilityProfile, bool) {
+	p, ok := visibilityProfiles[name]
+	return p, ok
+}
+
+// VisibilityProfileNames returns the profile names, sorted.
+func VisibilityProfileNames() []string {
+	names := make([]string, 0, len(visibilityProfiles))
+	for name := range visibilityProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p VisibilityProfile) ShowsKind(kind MessageKind) bool {
+	return p.Kinds == nil || slices.Contains(p.Kinds, kind)
+}
+
+func (p VisibilityProfile) ShowsMetadataKey(key string) bool {
+	return p.MetadataKeys == nil || slices.Contains(p.MetadataKeys, key)
+}
+
+// ShowsEvent reports whether a live event belongs in the profile. Events are
+// matched by the message kind they produce; status changes and progress are
+// always shown.
+func (p VisibilityProfile) ShowsEvent(event Event) bool {
+	switch event.Type {
+	case EventTypeOutput:
+		return p.ShowsKind(MessageKindOutput)
+	case EventTypeThought:
+		return p.ShowsKind(MessageKindThought)
+	case EventTypeToolCall:
+		return p.ShowsKind(MessageKindToolUse)
+	case EventTypeError:
+		return p.ShowsKind(MessageKindError)
+	case EventTypePlan:
+		return p.ShowsKind(MessageKindPlan)
+	case EventTypeMetric:
+		return p.ShowsKind(MessageKindMetric)
+	case EventTypeMetadata:
+		data, ok := event.Metadata()
+		return ok && p.ShowsMetadataKey(data.Key)
+	default:
+		return true
+	}
+}
+
+// FilterMessages returns the messages the profile shows, without their raw
+// payloads unless the profile keeps them.
+func (p VisibilityProfile) FilterMessages(messages []Message) []Message {
+	out := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if !p.ShowsKind(msg.Kind) {
+			continue
+		}
+		if !p.Raw {
+			msg.Raw = nil
+		}
+		out = append(out, msg)
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// SessionKindWatch marks a session started by a project watch rule.
+const SessionKindWatch = "watch"
+
+// DefaultWatchPrompt is the prompt of watch rules that do not set one.
+const DefaultWatchPrompt = "Files in this project changed:\n\n{{.Summary}}\n\nLook at the changes and act on them."
+
+// Watch runs sessions when files in a project change. Each rule whose globs
+// match a changed file starts a new session with the rule's prompt.
+type Watch struct {
+	Rules []WatchRule `json:"rules"`
+}
+
+// WatchRule is one trigger of a project watch.
+type WatchRule struct {
+	Name string `json:"name"`
+	// Globs are slash-separated patterns relative to the project path, as
+	// for path.Match, where a "**" segment matches any number of
+	// directories, e.g. "issues/*.md" or "**/test-output.txt".
+	Globs []string `json:"globs"`
+	// Prompt is a text/template rendered with a WatchChange, e.g.
+	// "Fix the failing tests:\n{{.Summary}}". Empty uses DefaultWatchPrompt.
+	Prompt       string `json:"prompt,omitempty"`
+	ProviderType string `json:"provider_type,omitempty"`
+	// ProviderID and AgentID name the stored provider and agent configs
+	// the rule's sessions start with. ProviderType defaults to the provider
+	// config's.
+	ProviderID string `json:"provider_id,omitempty"`
+	AgentID    string `json:"agent_id,omitempty"`
+}
+
+// WatchChange is what one scan found changed under a rule's globs. It is
+// the data a rule's prompt template is rendered with.
+type WatchChange struct {
+	Rule     string
+	Added    []string
+	Modified []string
+	Removed  []string
+}
+
+// Files returns every changed path.
+func (c WatchChange) Files() []string {
+	files := make([]string, 0, len(c.Added)+len(c.Modified)+len(c.Removed))
+	files = append(files, c.Added...)
+	files = append(files, c.Modified...)
+	return append(files, c.Removed...)
+}
+
+// Empty reports whether nothing changed.
+func (c WatchChange) Empty() bool {
+	return len(c.Added) == 0 && len(c.Modified) == 0 && len(c.Removed) == 0
+}
+
+// Summary lists the changed paths, one per line.
+func (c WatchChange) Summary() string {
+	var lines []string
+	for _, group := range []struct {
+		verb  string
+		paths []string
+	}{{"added", c.Added}, {"modified", c.Modified}, {"removed", c.Removed}} {
+		for _, p := range group.paths {
+			lines = append(lines, "- "+group.verb+": "+p)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks that every rule is named uniquely, has a provider and
+// valid globs, and that its prompt parses.
+func (w *Watch) Validate() error {
+	if w == nil {
+		return nil
+	}
+	names := make(map[string]bool, len(w.Rules))
+	for _, rule := range w.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("watch rule name is required")
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate watch rule %q", rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.ProviderType == "" && rule.ProviderID == "" {
+			return fmt.Errorf("watch rule %q: provider_type or provider_id is required", rule.Name)
+		}
+		if len(rule.Globs) == 0 {
+			return fmt.Errorf("watch rule %q: at least one glob is required", rule.Name)
+		}
+		for _, glob := range rule.Globs {
+			if err := validateWatchGlob(glob); err != nil {
+				return fmt.Errorf("watch rule %q: %w", rule.Name, err)
+			}
+		}
+		if _, err := rule.template(); err != nil {
+			return fmt.Errorf("watch rule %q: invalid prompt: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateWatchGlob(glob string) error {
+	if glob == "" || path.IsAbs(glob) || glob != path.Clean(glob) || strings.HasPrefix(glob, "../") || glob == ".." {
+		return fmt.Errorf("glob %q must be a clean path relative to the project", glob)
+	}
+	for _, segment := range strings.Split(glob, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("glob %q: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether any of the rule's globs matches rel, a
+// slash-separated path relative to the project.
+func (r WatchRule) Matches(rel string) bool {
+	for _, glob := range r.Globs {
+		if matchWatchGlob(strings.Split(glob, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchWatchGlob(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchWatchGlob(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchWatchGlob(pattern[1:], name[1:])
+}
+
+// WatchRoot returns the leading directories of glob that contain no
+// pattern characters; only files under it can match.
+func WatchRoot(glob string) string {
+	segments := strings.Split(glob, "/")
+	root := []string{}
+	for _, segment := range segments[:len(segments)-1] {
+		if segment == "**" || strings.ContainsAny(segment, `*?[\`) {
+			break
+		}
+		root = append(root, segment)
+	}
+	if len(root) == 0 {
+		return "."
+	}
+	return strings.Join(root, "/")
+}
+
+// RenderPrompt renders the rule's prompt for change.
+func (r WatchRule) RenderPrompt(change WatchChange) (string, error) {
+	tmpl, err := r.template()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, change); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (r WatchRule) template() (*template.Template, error) {
+	prompt := r.Prompt
+	if prompt == "" {
+		prompt = DefaultWatchPrompt
+	}
+	return template.New(r.Name).Option("missingkey=error").Parse(prompt)
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "testing"
+
+func TestWatchRule_Matches(t *testing.T) {
+	rule := WatchRule{Globs: []string{"issues/*.md", "**/test-output.txt"}}
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"issues/new.md", true},
+		{"issues/sub/new.md", false},
+		{"issues/new.txt", false},
+		{"test-output.txt", true},
+		{"build/ci/test-output.txt", true},
+		{"build/test-output.txt.bak", false},
+	} {
+		if got := rule.Matches(tc.path); got != tc.want {
+			t.Errorf("Matches(%q) = %v, want %v", tc.path, got, tc.want)
+		}
+	}
+
+	for glob, want := range map[string]string{
+		"issues/*.md":        "issues",
+		"**/test-output.txt": ".",
+		"a/b/c.txt":          "a/b",
+		"a/*/c.txt":          "a",
+	} {
+		if got := WatchRoot(glob); got != want {
+			t.Errorf("WatchRoot(%q) = %q, want %q", glob, got, want)
+		}
+	}
+}
+
+func TestWatchRule_RenderPrompt(t *testing.T) {
+	change := WatchChange{Rule: "issues", Added: []string{"issues/a.md"}, Removed: []string{"issues/b.md"}}
+	got, err := WatchRule{Name: "issues"}.RenderPrompt(change)
+	if err != nil {
+		t.Fatalf("RenderPrompt failed: %v", err)
+	}
+	want := "Files in this project changed:\n\n- added: issues/a.md\n- removed: issues/b.md\n\nLook at the changes and act on them."
+	if got != want {
+		t.Errorf("RenderPrompt = %q, want %q", got, want)
+	}
+}
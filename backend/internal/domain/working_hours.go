@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WaitKindWorkingHours marks a run suspended because it was outside its
+// project's working hours. The run is resumed when the next window opens.
+const WaitKindWorkingHours = "outside_working_hours"
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// WorkingHours limits when a project's sessions may run unattended. Outside
+// the window running sessions are suspended, and they resume when it opens.
+type WorkingHours struct {
+	// Days are three-letter weekday names ("mon", "tue", ...). Empty means
+	// Monday to Friday.
+	Days []string `json:"days,omitempty"`
+	// Start and End are "15:04" times of day. An End at or before Start
+	// makes the window run past midnight into the next day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// TimeZone is an IANA zone name; empty uses the server's local time.
+	TimeZone string `json:"time_zone,omitempty"`
+	// SessionKinds limits the policy to sessions of these kinds; empty
+	// applies it to every session of the project.
+	SessionKinds []string `json:"session_kinds,omitempty"`
+}
+
+// Validate checks the days, times and time zone.
+func (w *WorkingHours) Validate() error {
+	if w == nil {
+		return nil
+	}
+	for _, d := range w.Days {
+		if !slices.Contains(weekdayNames, strings.ToLower(strings.TrimSpace(d))) {
+			return fmt.Errorf("unknown working day %q", d)
+		}
+	}
+	if _, err := parseClock(w.Start); err != nil {
+		return fmt.Errorf("invalid working hours start: %w", err)
+	}
+	if _, err := parseClock(w.End); err != nil {
+		return fmt.Errorf("invalid working hours end: %w", err)
+	}
+	if w.TimeZone != "" {
+		if _, err := time.LoadLocation(w.TimeZone); err != nil {
+			return fmt.Errorf("invalid working hours time zone %q", w.TimeZone)
+		}
+	}
+	return nil
+}
+
+// Applies reports whether the policy covers sessions of kind.
+func (w WorkingHours) Applies(kind string) bool {
+	return len(w.SessionKinds) == 0 || slices.Contains(w.SessionKinds, kind)
+}
+
+// Contains reports whether t falls inside a working window.
+func (w WorkingHours) Contains(t time.Time) bool {
+	start, end, ok := w.window(t)
+	return ok && !t.Before(start) && t.Before(end)
+}
+
+// NextStart returns when the next working window opens after t, or the zero
+// time if the policy has no working days.
+func (w WorkingHours) NextStart(t time.Time) time.Time {
+	t = t.In(w.location())
+	startMin, _ := parseClock(w.Start)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i <= 7; i++ {
+		d := day.AddDate(0, 0, i)
+		if !w.workingDay(d.Weekday()) {
+			continue
+		}
+		if start := atClock(d, startMin); start.After(t) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+// window returns the working window covering t: the one starting today, or
+// yesterday's if it runs past midnight and t is still inside it.
+func (w WorkingHours) window(t time.Time) (time.Time, time.Time, bool) {
+	t = t.In(w.location())
+	startMin, err := parseClock(w.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endMin, err := parseClock(w.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	length := endMin - startMin
+	if length <= 0 {
+		length += 24 * 60
+	}
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for _, d := range []time.Time{today, today.AddDate(0, 0, -1)} {
+		if !w.workingDay(d.Weekday()) {
+			continue
+		}
+		start := atClock(d, startMin)
+		end := start.Add(time.Duration(length) * time.Minute)
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func (w WorkingHours) workingDay(d time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return d >= time.Monday && d <= time.Friday
+	}
+	for _, name := range w.Days {
+		if strings.ToLower(strings.TrimSpace(name)) == weekdayNames[d] {
+			return true
+		}
+	}
+	return false
+}
+
+func (w WorkingHours) location() *time.Location {
+	if w.TimeZone != "" {
+		if loc, err := time.LoadLocation(w.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
+
+func atClock(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location())
+}
+
+// parseClock parses a "15:04" time of day into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
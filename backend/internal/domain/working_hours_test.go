@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkingHours_Contains(t *testing.T) {
+	office := WorkingHours{Start: "09:00", End: "17:30", TimeZone: "UTC"}
+	night := WorkingHours{Days: []string{"fri"}, Start: "22:00", End: "06:00", TimeZone: "UTC"}
+	at := func(day, clock string) time.Time {
+		// 2026-10-12 is a Monday.
+		ts, err := time.Parse("2006-01-02 15:04", "2026-10-"+day+" "+clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+
+	for _, tc := range []struct {
+		hours WorkingHours
+		t     time.Time
+		want  bool
+	}{
+		{office, at("12", "09:00"), true},
+		{office, at("12", "17:30"), false},
+		{office, at("12", "08:59"), false},
+		{office, at("17", "12:00"), false}, // Saturday
+		{night, at("16", "23:00"), true},
+		{night, at("17", "05:59"), true}, // Friday's window past midnight
+		{night, at("17", "23:00"), false},
+	} {
+		if got := tc.hours.Contains(tc.t); got != tc.want {
+			t.Errorf("%+v Contains(%s) = %v, want %v", tc.hours, tc.t.Format(time.RFC1123), got, tc.want)
+		}
+	}
+
+	if got, want := office.NextStart(at("16", "18:00")), at("19", "09:00"); !got.Equal(want) {
+		t.Errorf("NextStart after Friday close = %s, want %s", got, want)
+	}
+	if got, want := office.NextStart(at("13", "07:00")), at("13", "09:00"); !got.Equal(want) {
+		t.Errorf("NextStart before opening = %s, want %s", got, want)
+	}
+}
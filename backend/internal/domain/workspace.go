@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Workspace modes.
+const (
+	// WorkspaceWorktree checks the session out as a git worktree of its
+	// repository, sharing the repository's objects and branches.
+	WorkspaceWorktree = "worktree"
+	// WorkspaceClone checks the session out as a git clone of its
+	// repository.
+	WorkspaceClone = "clone"
+)
+
+// Workspace is a checkout made for one session under the managed workspace
+// directory, so sessions working in the same repository do not trample each
+// other's changes. The session runs in Path, and the checkout is removed
+// when the session is deleted.
+type Workspace struct {
+	Mode string `json:"mode"`
+	// Repo is the repository checked out: a local checkout for worktrees,
+	// anything git can clone for clones.
+	Repo string `json:"repo"`
+	// BaseRef is the commit Branch started from.
+	BaseRef string `json:"base_ref"`
+	// Branch is the branch created for the session.
+	Branch    string    `json:"branch"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (w *Workspace) clone() *Workspace {
+	if w == nil {
+		return nil
+	}
+	out := *w
+	return &out
+}
+
+// GetWorkspace returns a copy of the session's workspace, if it has one.
+func (s *Session) GetWorkspace() *Workspace {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Workspace.clone()
+}
+
+// SetWorkspace replaces the session's workspace.
+func (s *Session) SetWorkspace(w *Workspace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Workspace = w.clone()
+}
@@ -0,0 +1,16 @@
+package presentation
+
+import (
+	"maps"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// Notice converts a message or status notice; nil stays nil.
+func Notice(n *domain.Notice) *apiTypes.Notice {
+	if n == nil {
+		return nil
+	}
+	return &apiTypes.Notice{Code: n.Code, Params: maps.Clone(n.Params)}
+}
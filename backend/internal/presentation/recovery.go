@@ -0,0 +1,55 @@
+package presentation
+
+import (
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// RecoveryReport converts a startup recovery report; nil stays nil.
+func RecoveryReport(r *storage.RecoveryReport) *apiTypes.RecoveryReport {
+	if r == nil {
+		return nil
+	}
+	sessions := make([]apiTypes.RecoveredSession, len(r.Sessions))
+	for i, s := range r.Sessions {
+		sessions[i] = apiTypes.RecoveredSession{
+			SessionID:      s.SessionID,
+			AttemptsClosed: s.AttemptsClosed,
+			Policy:         s.Policy,
+			Scheduled:      s.Scheduled,
+			Note:           s.Note,
+		}
+	}
+	return &apiTypes.RecoveryReport{
+		StartedAt:        r.StartedAt,
+		FinishedAt:       r.FinishedAt,
+		Status:           string(r.Status),
+		SessionsTotal:    r.SessionsTotal,
+		SessionsScanned:  r.SessionsScanned,
+		AttemptsClosed:   r.AttemptsClosed,
+		RunsScheduled:    r.RunsScheduled,
+		Sessions:         sessions,
+		Error:            r.Error,
+		PreviousShutdown: ShutdownReport(r.PreviousShutdown),
+	}
+}
+
+func ShutdownReport(r *storage.ShutdownReport) *apiTypes.ShutdownReport {
+	if r == nil {
+		return nil
+	}
+	out := &apiTypes.ShutdownReport{
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+		Clean:      r.Clean,
+		Phases:     make([]apiTypes.ShutdownPhase, len(r.Phases)),
+		Sessions:   make([]apiTypes.ShutdownSession, len(r.Sessions)),
+	}
+	for i, p := range r.Phases {
+		out.Phases[i] = apiTypes.ShutdownPhase(p)
+	}
+	for i, s := range r.Sessions {
+		out.Sessions[i] = apiTypes.ShutdownSession(s)
+	}
+	return out
+}
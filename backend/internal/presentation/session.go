@@ -19,5 +19,127 @@ func SessionResponseFromSnapshot(s domain.SessionSnapshot) apiTypes.SessionRespo
 		CreatedAt:           s.CreatedAt,
 		UpdatedAt:           s.UpdatedAt,
 		CurrentTask:         s.CurrentTask,
+		Pinned:              s.Pinned,
+		RecoveryPolicy:      s.RecoveryPolicy,
+		PlanApproval:        s.PlanApproval,
+		Plan:                sessionPlanResponse(s.Plan),
+		CommandApproval:     commandApprovalResponse(s.CommandApproval),
+		Features:            s.Features,
+		TakenOverBy:         takenOverBy(s.Takeovers),
+		ToolApproval:        toolApprovalResponse(s.ToolApproval),
+		MessagePins:         MessagePins(s.MessagePins),
+		QueuedMessages:      len(s.QueuedMessages),
+		CostBudget:          CostBudget(s.CostBudget),
+		Metadata:            s.Metadata,
+		MissionID:           s.MissionID,
+		Workspace:           workspaceResponse(s.Workspace),
+		Git:                 gitTrackingResponse(s.GitTracking),
 	}
 }
+
+// MessagePins converts a session's message pins.
+func MessagePins(pins []domain.MessagePin) []apiTypes.MessagePin {
+	if len(pins) == 0 {
+		return nil
+	}
+	out := make([]apiTypes.MessagePin, len(pins))
+	for i, p := range pins {
+		out[i] = apiTypes.MessagePin{
+			ID:        p.ID,
+			MessageID: p.MessageID,
+			Kind:      string(p.Kind),
+			Excerpt:   p.Excerpt,
+			Note:      p.Note,
+			MessageAt: p.MessageAt,
+			PinnedBy:  p.PinnedBy,
+			PinnedAt:  p.PinnedAt,
+		}
+	}
+	return out
+}
+
+func takenOverBy(takeovers []domain.Takeover) string {
+	if n := len(takeovers); n > 0 && takeovers[n-1].Active() {
+		return takeovers[n-1].By
+	}
+	return ""
+}
+
+func sessionPlanResponse(p *domain.SessionPlan) *apiTypes.SessionPlan {
+	if p == nil {
+		return nil
+	}
+	return &apiTypes.SessionPlan{
+		Content:    p.Content,
+		Status:     apiTypes.PlanStatus(p.Status),
+		ProposedAt: p.ProposedAt,
+		Edited:     p.Edited,
+		ApprovedBy: p.ApprovedBy,
+		ApprovedAt: p.ApprovedAt,
+	}
+}
+
+func commandApprovalResponse(a *domain.CommandApproval) *apiTypes.CommandApproval {
+	if a == nil {
+		return nil
+	}
+	return &apiTypes.CommandApproval{AutoApprove: a.AutoApprove}
+}
+
+func workspaceResponse(w *domain.Workspace) *apiTypes.SessionWorkspace {
+	if w == nil {
+		return nil
+	}
+	return &apiTypes.SessionWorkspace{Mode: w.Mode, Repo: w.Repo, BaseRef: w.BaseRef, Branch: w.Branch, Path: w.Path}
+}
+
+func gitTrackingResponse(g *domain.GitTracking) *apiTypes.SessionGit {
+	if g == nil {
+		return nil
+	}
+	return &apiTypes.SessionGit{Branch: g.Branch, BaseRef: g.BaseRef, CommitCount: len(g.Commits)}
+}
+
+// toolApprovalResponse converts a session's tool approval policy.
+func toolApprovalResponse(a *domain.ToolApproval) *apiTypes.ToolApproval {
+	if a == nil {
+		return nil
+	}
+	resp := &apiTypes.ToolApproval{AutoAllow: a.AutoAllow, OnTimeout: a.OnTimeout}
+	if a.Timeout > 0 {
+		resp.Timeout = a.Timeout.String()
+	}
+	return resp
+}
+
+// CostBudget converts a session's or project's cost budget.
+func CostBudget(b *domain.CostBudget) *apiTypes.CostBudget {
+	if b == nil {
+		return nil
+	}
+	return &apiTypes.CostBudget{LimitUSD: b.LimitUSD, LimitTokens: b.LimitTokens, Action: b.Action}
+}
+
+// QueuedMessages converts a session's queued messages.
+func QueuedMessages(queued []domain.QueuedMessage) []apiTypes.QueuedMessage {
+	out := make([]apiTypes.QueuedMessage, len(queued))
+	for i, m := range queued {
+		out[i] = apiTypes.QueuedMessage{
+			MessageID:    m.MessageID,
+			Content:      m.Content,
+			ProviderID:   m.ProviderID,
+			ProviderType: m.ProviderType,
+			TokenBudget:  m.TokenBudget,
+			OnBudget:     m.BudgetAction,
+			QueuedBy:     m.QueuedBy,
+			QueuedAt:     m.QueuedAt,
+		}
+		if m.Deadline > 0 {
+			out[i].Deadline = m.Deadline.String()
+		}
+		if m.LowPriority {
+			out[i].Priority = "low"
+		}
+	}
+	return out
+}
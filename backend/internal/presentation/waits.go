@@ -0,0 +1,26 @@
+package presentation
+
+import (
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/service"
+	apiTypes "github.com/ricochet1k/orbitmesh/pkg/api"
+)
+
+// Waits converts pending waits, with their ages at now.
+func Waits(waits []service.PendingWait, now time.Time) apiTypes.WaitListResponse {
+	out := apiTypes.WaitListResponse{Waits: make([]apiTypes.WaitResponse, len(waits))}
+	for i, w := range waits {
+		out.Waits[i] = apiTypes.WaitResponse{
+			Kind:         w.Kind,
+			SessionID:    w.SessionID,
+			SessionTitle: w.SessionTitle,
+			Ref:          w.Ref,
+			Summary:      w.Summary,
+			Since:        w.Since,
+			AgeSeconds:   int64(max(now.Sub(w.Since), 0) / time.Second),
+			Priority:     string(w.Priority),
+		}
+	}
+	return out
+}
@@ -11,6 +11,7 @@ import (
 
 	acpsdk "github.com/coder/acp-go-sdk"
 	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
 )
 
 // acpClientAdapter implements acpsdk.Client for OrbitMesh.
@@ -30,19 +31,7 @@ func newACPClientAdapter(session *Session) *acpClientAdapter {
 
 // ReadTextFile handles file read requests from the agent.
 func (a *acpClientAdapter) ReadTextFile(ctx context.Context, req acpsdk.ReadTextFileRequest) (acpsdk.ReadTextFileResponse, error) {
-	// Ensure path is absolute
-	path := req.Path
-	if !filepath.IsAbs(path) {
-		// Make relative to working directory
-		if a.session.sessionConfig.WorkingDir != "" {
-			path = filepath.Join(a.session.sessionConfig.WorkingDir, path)
-		} else {
-			return acpsdk.ReadTextFileResponse{}, fmt.Errorf("path must be absolute: %s", req.Path)
-		}
-	}
-
-	// Read the file
-	data, err := os.ReadFile(path)
+	data, err := a.readFile(ctx, req.Path)
 	if err != nil {
 		return acpsdk.ReadTextFileResponse{}, err
 	}
@@ -80,27 +69,7 @@ func (a *acpClientAdapter) ReadTextFile(ctx context.Context, req acpsdk.ReadText
 
 // WriteTextFile handles file write requests from the agent.
 func (a *acpClientAdapter) WriteTextFile(ctx context.Context, req acpsdk.WriteTextFileRequest) (acpsdk.WriteTextFileResponse, error) {
-	// Ensure path is absolute
-	path := req.Path
-	if !filepath.IsAbs(path) {
-		// Make relative to working directory
-		if a.session.sessionConfig.WorkingDir != "" {
-			path = filepath.Join(a.session.sessionConfig.WorkingDir, path)
-		} else {
-			return acpsdk.WriteTextFileResponse{}, fmt.Errorf("path must be absolute: %s", req.Path)
-		}
-	}
-
-	// Create parent directory if needed
-	dir := filepath.Dir(path)
-	if dir != "" {
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return acpsdk.WriteTextFileResponse{}, err
-		}
-	}
-
-	// Write the file
-	if err := os.WriteFile(path, []byte(req.Content), 0o644); err != nil {
+	if err := a.writeFile(ctx, req.Path, []byte(req.Content)); err != nil {
 		return acpsdk.WriteTextFileResponse{}, err
 	}
 
@@ -113,7 +82,56 @@ func (a *acpClientAdapter) WriteTextFile(ctx context.Context, req acpsdk.WriteTe
 	return acpsdk.WriteTextFileResponse{}, nil
 }
 
-// RequestPermission handles permission requests from the agent.
+// resolvePath makes a local path absolute against the working directory.
+func (a *acpClientAdapter) resolvePath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	if a.session.sessionConfig.WorkingDir == "" {
+		return "", fmt.Errorf("path must be absolute: %s", path)
+	}
+	return filepath.Join(a.session.sessionConfig.WorkingDir, path), nil
+}
+
+// readFile reads path from the agent's machine: locally, or over SSH when
+// the working directory is remote.
+func (a *acpClientAdapter) readFile(ctx context.Context, path string) ([]byte, error) {
+	if workingDir := a.session.sessionConfig.WorkingDir; remote.IsRemote(workingDir) {
+		target, err := remote.Parse(workingDir)
+		if err != nil {
+			return nil, err
+		}
+		return target.ReadFile(ctx, path)
+	}
+	path, err := a.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// writeFile writes path on the agent's machine, creating parent directories.
+func (a *acpClientAdapter) writeFile(ctx context.Context, path string, data []byte) error {
+	if workingDir := a.session.sessionConfig.WorkingDir; remote.IsRemote(workingDir) {
+		target, err := remote.Parse(workingDir)
+		if err != nil {
+			return err
+		}
+		return target.WriteFile(ctx, path, data)
+	}
+	path, err := a.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// RequestPermission handles permission requests from the agent. They are
+// decided by the provider config's PermissionHandler; without one the first
+// option offered is selected.
 func (a *acpClientAdapter) RequestPermission(ctx context.Context, req acpsdk.RequestPermissionRequest) (acpsdk.RequestPermissionResponse, error) {
 	title := ""
 	if req.ToolCall.Title != nil {
@@ -127,25 +145,73 @@ func (a *acpClientAdapter) RequestPermission(ctx context.Context, req acpsdk.Req
 		"options":   req.Options,
 	})
 
-	// For now, auto-approve the first option if available
-	// TODO: Integrate with OrbitMesh permission system
+	cancelled := acpsdk.RequestPermissionResponse{
+		Outcome: acpsdk.RequestPermissionOutcome{
+			Cancelled: &acpsdk.RequestPermissionOutcomeCancelled{},
+		},
+	}
 	if len(req.Options) == 0 {
-		return acpsdk.RequestPermissionResponse{
-			Outcome: acpsdk.RequestPermissionOutcome{
-				Cancelled: &acpsdk.RequestPermissionOutcomeCancelled{},
-			},
-		}, nil
+		return cancelled, nil
+	}
+	option := req.Options[0]
+	if handler := a.session.providerConfig.PermissionHandler; handler != nil {
+		allow, reason := handler(ctx, permissionRequest(req.ToolCall, title))
+		kinds := []acpsdk.PermissionOptionKind{acpsdk.PermissionOptionKindAllowOnce, acpsdk.PermissionOptionKindAllowAlways}
+		if !allow {
+			a.emitMetadata("permission_denied", map[string]any{
+				"tool_call_id": string(req.ToolCall.ToolCallId),
+				"reason":       reason,
+			})
+			kinds = []acpsdk.PermissionOptionKind{acpsdk.PermissionOptionKindRejectOnce, acpsdk.PermissionOptionKindRejectAlways}
+		}
+		var ok bool
+		if option, ok = pickPermissionOption(req.Options, kinds); !ok {
+			if !allow {
+				return cancelled, nil
+			}
+			option = req.Options[0]
+		}
 	}
 
 	return acpsdk.RequestPermissionResponse{
 		Outcome: acpsdk.RequestPermissionOutcome{
 			Selected: &acpsdk.RequestPermissionOutcomeSelected{
-				OptionId: req.Options[0].OptionId,
+				OptionId: option.OptionId,
 			},
 		},
 	}, nil
 }
 
+// permissionRequest describes a tool call for a PermissionHandler.
+func permissionRequest(call acpsdk.RequestPermissionToolCall, title string) PermissionRequest {
+	req := PermissionRequest{ToolCallID: string(call.ToolCallId), Title: title}
+	if call.Kind != nil {
+		req.Kind = string(*call.Kind)
+	}
+	req.Input, _ = call.RawInput.(map[string]any)
+	switch req.Kind {
+	case string(acpsdk.ToolKindExecute):
+		req.Command, _ = req.Input["command"].(string)
+	case string(acpsdk.ToolKindEdit), string(acpsdk.ToolKindDelete), string(acpsdk.ToolKindMove):
+		for _, loc := range call.Locations {
+			req.WritePaths = append(req.WritePaths, loc.Path)
+		}
+	}
+	return req
+}
+
+// pickPermissionOption returns the first option of the first kind offered.
+func pickPermissionOption(options []acpsdk.PermissionOption, kinds []acpsdk.PermissionOptionKind) (acpsdk.PermissionOption, bool) {
+	for _, kind := range kinds {
+		for _, o := range options {
+			if o.Kind == kind {
+				return o, true
+			}
+		}
+	}
+	return acpsdk.PermissionOption{}, false
+}
+
 // SessionUpdate handles session update notifications from the agent.
 func (a *acpClientAdapter) SessionUpdate(ctx context.Context, notif acpsdk.SessionNotification) error {
 	update := notif.Update
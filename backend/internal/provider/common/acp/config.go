@@ -1,6 +1,10 @@
 package acp
 
-import "github.com/ricochet1k/orbitmesh/internal/session"
+import (
+	"context"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
 
 // Config defines configuration for an ACP provider instance.
 type Config struct {
@@ -15,6 +19,10 @@ type Config struct {
 
 	// Environment variables to set for the agent process
 	Environment map[string]string `json:"environment,omitempty"`
+
+	// PermissionHandler decides the agent's permission requests. Without
+	// one, the first option offered is selected.
+	PermissionHandler PermissionHandler `json:"-"`
 }
 
 // SessionConfig extends the base session.Config with ACP-specific settings.
@@ -27,3 +35,19 @@ type SessionConfig struct {
 	// ACPArgs overrides the default args if set
 	ACPArgs []string `json:"acp_args,omitempty"`
 }
+
+// PermissionRequest is a tool call an ACP agent asks permission for.
+type PermissionRequest struct {
+	ToolCallID string
+	// Kind is the ACP tool kind, e.g. "edit" or "execute".
+	Kind  string
+	Title string
+	Input map[string]any
+	// Command is the shell command of an "execute" call, if known.
+	Command string
+	// WritePaths are the files an "edit", "delete" or "move" call touches.
+	WritePaths []string
+}
+
+// PermissionHandler decides whether a tool call may run, and why not.
+type PermissionHandler func(ctx context.Context, req PermissionRequest) (allow bool, reason string)
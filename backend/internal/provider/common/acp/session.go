@@ -15,8 +15,10 @@ import (
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/provider/buffer"
 	"github.com/ricochet1k/orbitmesh/internal/provider/circuit"
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
 	"github.com/ricochet1k/orbitmesh/internal/provider/native"
 	"github.com/ricochet1k/orbitmesh/internal/provider/process"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
 	"github.com/ricochet1k/orbitmesh/internal/service"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/terminal"
@@ -158,6 +160,12 @@ func (s *Session) start(config session.Config) error {
 	environment := maps.Clone(s.providerConfig.Environment)
 	maps.Copy(environment, config.Environment)
 
+	kubeConfig, err := kube.FromCustom(config.Custom)
+	if err != nil {
+		s.handleFailure(err)
+		return err
+	}
+
 	// Initialize terminal manager
 	s.terminalManager = NewTerminalManager(s.sessionID, workingDir, s.ctx)
 
@@ -167,6 +175,7 @@ func (s *Session) start(config session.Config) error {
 		Args:        args,
 		WorkingDir:  workingDir,
 		Environment: environment,
+		Kubernetes:  kubeConfig,
 	})
 	if err != nil {
 		s.handleFailure(err)
@@ -266,6 +275,14 @@ func (s *Session) createACPSession() error {
 		if err != nil {
 			cwd = "."
 		}
+	} else if remote.IsRemote(cwd) {
+		// The agent runs on the remote host, where only the path means
+		// anything.
+		target, err := remote.Parse(cwd)
+		if err != nil {
+			return err
+		}
+		cwd = target.Dir
 	}
 
 	// Convert OrbitMesh MCP server config to ACP format
@@ -375,6 +392,12 @@ func (s *Session) Status() session.Status {
 	return s.state.Status()
 }
 
+// Capabilities implements session.Session; ACP agents take follow-up prompts and run workspace commands in
+// terminals.
+func (s *Session) Capabilities() session.Capabilities {
+	return session.Capabilities{Suspend: true, Steering: true, Terminal: true}
+}
+
 // processStderr reads error output from the agent's stderr.
 func (s *Session) processStderr() {
 	defer s.wg.Done()
@@ -396,10 +419,7 @@ func (s *Session) processStderr() {
 			continue
 		}
 
-		// Emit stderr output as metadata
-		s.events.Emit(domain.NewMetadataEvent(s.sessionID, "stderr", map[string]any{
-			"line": line,
-		}, nil))
+		s.events.Emit(domain.NewStderrEvent(s.sessionID, line+"\n"))
 	}
 }
 
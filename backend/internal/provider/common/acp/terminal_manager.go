@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
 	"github.com/ricochet1k/orbitmesh/internal/terminal"
 	"github.com/ricochet1k/termemu"
 )
@@ -81,19 +82,36 @@ func (tm *TerminalManager) Create(id, command string, args []string, cwd *string
 	}
 
 	// Build command
-	cmd := exec.Command(command, args...)
-
-	// Set working directory
-	if cwd != nil && *cwd != "" {
-		cmd.Dir = *cwd
+	var cmd *exec.Cmd
+	if remote.IsRemote(tm.workingDir) {
+		// Run on the agent's host; the local pty bridges the remote one.
+		target, err := remote.Parse(tm.workingDir)
+		if err != nil {
+			return nil, err
+		}
+		if cwd != nil && *cwd != "" {
+			target.Dir = *cwd
+		}
+		cmd, err = target.Command(context.Background(), remote.Spec{Command: command, Args: args, Env: env, TTY: true})
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = os.Environ()
 	} else {
-		cmd.Dir = tm.workingDir
-	}
+		cmd = exec.Command(command, args...)
+
+		// Set working directory
+		if cwd != nil && *cwd != "" {
+			cmd.Dir = *cwd
+		} else {
+			cmd.Dir = tm.workingDir
+		}
 
-	// Set environment
-	cmd.Env = os.Environ()
-	for k, v := range env {
-		cmd.Env = append(cmd.Env, k+"="+v)
+		// Set environment
+		cmd.Env = os.Environ()
+		for k, v := range env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
 	}
 
 	// Create PTY backend
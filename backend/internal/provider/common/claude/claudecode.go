@@ -5,15 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"maps"
-	"os"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/provider/buffer"
 	"github.com/ricochet1k/orbitmesh/internal/provider/circuit"
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
 	"github.com/ricochet1k/orbitmesh/internal/provider/native"
 	"github.com/ricochet1k/orbitmesh/internal/provider/process"
 	"github.com/ricochet1k/orbitmesh/internal/session"
@@ -24,6 +22,7 @@ var (
 	ErrAlreadyStarted = errors.New("claude provider already started")
 	ErrNotPaused      = errors.New("claude provider not paused")
 	ErrAlreadyPaused  = errors.New("claude provider already paused")
+	ErrNoConversation = errors.New("claude provider has no conversation to resume")
 )
 
 // ClaudeCodeProvider implements the session.Session interface for the claude CLI
@@ -34,6 +33,9 @@ type ClaudeCodeProvider struct {
 	state     *native.ProviderState
 	events    *native.EventAdapter
 	config    session.Config
+	// translator is kept for the provider's life so result costs, which
+	// the CLI accumulates, are reported as deltas.
+	translator *Translator
 
 	processMgr     *process.Manager
 	inputBuffer    *buffer.InputBuffer
@@ -53,6 +55,7 @@ func NewClaudeCodeProvider(sessionID string) *ClaudeCodeProvider {
 		sessionID:      sessionID,
 		state:          native.NewProviderState(),
 		events:         native.NewEventAdapter(sessionID, 100),
+		translator:     NewTranslator(sessionID),
 		inputBuffer:    buffer.NewInputBuffer(10),
 		circuitBreaker: circuit.NewBreaker(3, 30*time.Second),
 	}
@@ -76,6 +79,30 @@ func (p *ClaudeCodeProvider) SendInput(ctx context.Context, config session.Confi
 	return p.events.Events(), nil
 }
 
+// Prewarm implements session.Prewarmer by spawning the Claude process ahead
+// of the first prompt, so its boot overlaps the wait for the user.
+func (p *ClaudeCodeProvider) Prewarm(ctx context.Context, config session.Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return nil
+	}
+	return p.start(config)
+}
+
+// resumePrompt is sent when continuing a conversation after a restart.
+const resumePrompt = "Your previous run was interrupted by a server restart. Continue where you left off."
+
+// ResumeRun implements session.RunResumer. It resumes the session's
+// conversation, and fails without one rather than guess which conversation
+// in the working directory was the session's.
+func (p *ClaudeCodeProvider) ResumeRun(ctx context.Context, config session.Config) (<-chan domain.Event, error) {
+	if config.ConversationID == "" {
+		return nil, ErrNoConversation
+	}
+	return p.SendInput(ctx, config, resumePrompt)
+}
+
 // start initializes the Claude process with streaming JSON I/O.
 // Caller must hold p.mu (write lock).
 func (p *ClaudeCodeProvider) start(config session.Config) error {
@@ -102,21 +129,20 @@ func (p *ClaudeCodeProvider) start(config session.Config) error {
 		return err
 	}
 
-	// Set up environment
-	env := make(map[string]string)
-	for _, kv := range os.Environ() {
-		if kvs := strings.SplitN(kv, "=", 2); len(kvs) == 2 {
-			env[kvs[0]] = kvs[1]
-		}
+	kubeConfig, err := kube.FromCustom(config.Custom)
+	if err != nil {
+		p.handleFailure(err)
+		return err
 	}
-	maps.Copy(env, config.Environment)
 
-	// Start the process using ProcessManager
+	// Start the process using ProcessManager; it layers config.Environment
+	// over this process's own environment when running locally.
 	processMgr, err := process.Start(p.ctx, process.Config{
 		Command:     "claude",
 		Args:        args,
 		WorkingDir:  config.WorkingDir,
-		Environment: env,
+		Environment: config.Environment,
+		Kubernetes:  kubeConfig,
 	})
 	if err != nil {
 		p.handleFailure(err)
@@ -203,6 +229,11 @@ func (p *ClaudeCodeProvider) Status() session.Status {
 	return p.state.Status()
 }
 
+// Capabilities implements session.Session; claude can pick up its last conversation with --resume.
+func (p *ClaudeCodeProvider) Capabilities() session.Capabilities {
+	return session.Capabilities{Suspend: true, Steering: true, ResumeAfterRestart: true}
+}
+
 // processStdout reads and parses JSON messages from Claude's stdout.
 func (p *ClaudeCodeProvider) processStdout() {
 	defer p.wg.Done()
@@ -241,7 +272,10 @@ func (p *ClaudeCodeProvider) processStdout() {
 		}
 
 		// Translate to OrbitMesh event
-		if event, ok := TranslateToOrbitMeshEvent(p.sessionID, msg); ok {
+		if event, ok := p.translator.Translate(msg); ok {
+			p.emitEvent(event)
+		}
+		for _, event := range InitEvents(p.sessionID, msg) {
 			p.emitEvent(event)
 		}
 
@@ -275,10 +309,7 @@ func (p *ClaudeCodeProvider) processStderr() {
 			continue
 		}
 
-		// Emit stderr output as metadata
-		p.events.Emit(domain.NewMetadataEvent(p.sessionID, "stderr", map[string]any{
-			"line": line,
-		}, nil))
+		p.events.Emit(domain.NewStderrEvent(p.sessionID, line+"\n"))
 	}
 }
 
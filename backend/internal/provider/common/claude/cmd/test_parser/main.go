@@ -29,6 +29,7 @@ func main() {
 	scanner.Buffer(buf, maxCapacity)
 
 	sessionID := "test-session-1"
+	translator := claude.NewTranslator(sessionID)
 	lineNum := 0
 
 	fmt.Println("=== Testing OrbitMesh Claude Provider Parser ===")
@@ -50,7 +51,7 @@ func main() {
 		}
 
 		// Translate to OrbitMesh event
-		event, shouldEmit := claude.TranslateToOrbitMeshEvent(sessionID, msg)
+		event, shouldEmit := translator.Translate(msg)
 
 		if shouldEmit {
 			printEvent(lineNum, msg.Type, event)
@@ -8,6 +8,29 @@ import (
 	"github.com/ricochet1k/orbitmesh/internal/session"
 )
 
+// SystemPromptArgs returns the system prompt flags for config. Only an
+// explicit system_prompt replaces Claude's default system prompt; the
+// session's SystemPrompt (agent, role and project context) is appended to
+// it, followed by append_system_prompt.
+func SystemPromptArgs(config session.Config) []string {
+	var args []string
+	appendPrompt := config.SystemPrompt
+	if systemPrompt, ok := config.Custom["system_prompt"].(string); ok && systemPrompt != "" {
+		args = append(args, "--system-prompt", systemPrompt)
+		appendPrompt = ""
+	}
+	if extra, ok := config.Custom["append_system_prompt"].(string); ok && extra != "" {
+		if appendPrompt != "" {
+			appendPrompt += "\n\n"
+		}
+		appendPrompt += extra
+	}
+	if appendPrompt != "" {
+		args = append(args, "--append-system-prompt", appendPrompt)
+	}
+	return args
+}
+
 // buildCommandArgs constructs command-line arguments for the claude CLI
 // based on the session configuration.
 func buildCommandArgs(config session.Config) ([]string, error) {
@@ -18,21 +41,10 @@ func buildCommandArgs(config session.Config) ([]string, error) {
 		"--include-partial-messages",
 	}
 
-	if config.Custom == nil {
-		return args, nil
-	}
+	// Custom may be nil; reads from a nil map are safe.
 
 	// System prompt configuration
-	if systemPrompt, ok := config.Custom["system_prompt"].(string); ok && systemPrompt != "" {
-		args = append(args, "--system-prompt", systemPrompt)
-	} else if config.SystemPrompt != "" {
-		// Fall back to config.SystemPrompt if custom is not set
-		args = append(args, "--system-prompt", config.SystemPrompt)
-	}
-
-	if appendPrompt, ok := config.Custom["append_system_prompt"].(string); ok && appendPrompt != "" {
-		args = append(args, "--append-system-prompt", appendPrompt)
-	}
+	args = append(args, SystemPromptArgs(config)...)
 
 	// Model selection
 	if model, ok := config.Custom["model"].(string); ok && model != "" {
@@ -70,11 +82,29 @@ func buildCommandArgs(config session.Config) ([]string, error) {
 		}
 	}
 
+	// Feature flags
+	allowed, disallowed := FeatureToolRules(config)
+	for _, tool := range allowed {
+		args = append(args, "--allowed-tools", tool)
+	}
+	for _, tool := range disallowed {
+		args = append(args, "--disallowed-tools", tool)
+	}
+	if verbose, _ := config.Feature(session.FeatureVerboseTools); verbose {
+		args = append(args, "--verbose")
+	}
+
 	// Permission mode
 	if permMode, ok := config.Custom["permission_mode"].(string); ok && permMode != "" {
 		args = append(args, "--permission-mode", permMode)
 	}
 
+	// MCP tool that decides permission prompts, e.g.
+	// "mcp__orbitmesh-commands__approve_command"
+	if promptTool, ok := config.Custom["permission_prompt_tool"].(string); ok && promptTool != "" {
+		args = append(args, "--permission-prompt-tool", promptTool)
+	}
+
 	// JSON schema for structured output
 	if jsonSchema, ok := config.Custom["json_schema"]; ok {
 		schemaJSON, err := json.Marshal(jsonSchema)
@@ -84,6 +114,14 @@ func buildCommandArgs(config session.Config) ([]string, error) {
 		args = append(args, "--json-schema", string(schemaJSON))
 	}
 
+	// Continue the session's conversation from its last run, or else the
+	// most recent conversation in the working directory
+	if config.ConversationID != "" {
+		args = append(args, "--resume", config.ConversationID)
+	} else if cont, ok := config.Custom["continue"].(bool); ok && cont {
+		args = append(args, "--continue")
+	}
+
 	// Session persistence
 	if noPersist, ok := config.Custom["no_session_persistence"].(bool); ok && noPersist {
 		args = append(args, "--no-session-persistence")
@@ -127,8 +165,8 @@ func buildCommandArgs(config session.Config) ([]string, error) {
 		args = append(args, "--agent", agent)
 	}
 
-	// Session ID
-	if sessionID, ok := config.Custom["session_id"].(string); ok && sessionID != "" {
+	// Session ID, which a resumed conversation already has
+	if sessionID, ok := config.Custom["session_id"].(string); ok && sessionID != "" && config.ConversationID == "" {
 		args = append(args, "--session-id", sessionID)
 	}
 
@@ -161,6 +199,34 @@ func buildCommandArgs(config session.Config) ([]string, error) {
 	return args, nil
 }
 
+// networkToolRules are the Claude tools that reach the network.
+var networkToolRules = []string{"WebFetch", "WebSearch", "Bash(curl:*)", "Bash(wget:*)"}
+
+// FeatureToolRules translates the session's feature flags into Claude tool
+// permission rules. Unset flags add no rules, and a disallowed rule beats an
+// allowed one, so turning network tools off also turns off web search.
+func FeatureToolRules(config session.Config) (allowed, disallowed []string) {
+	network, networkSet := config.Feature(session.FeatureNetworkTools)
+	if networkSet {
+		if network {
+			allowed = append(allowed, "WebFetch")
+		} else {
+			disallowed = append(disallowed, networkToolRules...)
+		}
+	}
+	if search, set := config.Feature(session.FeatureWebSearch); set {
+		switch {
+		case !search:
+			if !networkSet || network {
+				disallowed = append(disallowed, "WebSearch")
+			}
+		case !networkSet || network:
+			allowed = append(allowed, "WebSearch")
+		}
+	}
+	return allowed, disallowed
+}
+
 // parseMCPConfig handles various formats of MCP configuration.
 func parseMCPConfig(mcpConfig any) ([]string, error) {
 	switch v := mcpConfig.(type) {
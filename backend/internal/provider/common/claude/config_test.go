@@ -2,6 +2,7 @@ package claude
 
 import (
 	"encoding/json"
+	"slices"
 	"testing"
 
 	"github.com/ricochet1k/orbitmesh/internal/session"
@@ -32,6 +33,60 @@ func TestBuildCommandArgs(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "continue previous conversation",
+			config: session.Config{
+				Custom: map[string]any{"continue": true},
+			},
+			wantArgs: []string{
+				"-p",
+				"--output-format=stream-json",
+				"--input-format=stream-json",
+				"--include-partial-messages",
+				"--continue",
+			},
+			wantErr: false,
+		},
+		{
+			name: "resume session conversation",
+			config: session.Config{
+				ConversationID: "claude-abc",
+				Custom:         map[string]any{"continue": true, "session_id": "ignored"},
+			},
+			wantArgs: []string{
+				"-p",
+				"--output-format=stream-json",
+				"--input-format=stream-json",
+				"--include-partial-messages",
+				"--resume",
+				"claude-abc",
+			},
+			wantErr: false,
+		},
+		{
+			name: "feature flags",
+			config: session.Config{
+				Custom: map[string]any{"allowed_tools": []string{"Read"}},
+				Features: map[string]bool{
+					session.FeatureWebSearch:    true,
+					session.FeatureNetworkTools: false,
+					session.FeatureVerboseTools: true,
+				},
+			},
+			wantArgs: []string{
+				"-p",
+				"--output-format=stream-json",
+				"--input-format=stream-json",
+				"--include-partial-messages",
+				"--allowed-tools", "Read",
+				"--disallowed-tools", "WebFetch",
+				"--disallowed-tools", "WebSearch",
+				"--disallowed-tools", "Bash(curl:*)",
+				"--disallowed-tools", "Bash(wget:*)",
+				"--verbose",
+			},
+			wantErr: false,
+		},
 		{
 			name: "with MCP config",
 			config: session.Config{
@@ -121,7 +176,7 @@ func TestBuildCommandArgs(t *testing.T) {
 				"--output-format=stream-json",
 				"--input-format=stream-json",
 				"--include-partial-messages",
-				"--system-prompt", "Default system prompt",
+				"--append-system-prompt", "Default system prompt",
 			},
 			wantErr: false,
 		},
@@ -143,6 +198,21 @@ func TestBuildCommandArgs(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "session prompt appended before append_system_prompt",
+			config: session.Config{
+				SystemPrompt: "Project context",
+				Custom:       map[string]any{"append_system_prompt": "Additional instructions"},
+			},
+			wantArgs: []string{
+				"-p",
+				"--output-format=stream-json",
+				"--input-format=stream-json",
+				"--include-partial-messages",
+				"--append-system-prompt", "Project context\n\nAdditional instructions",
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -169,6 +239,40 @@ func TestBuildCommandArgs(t *testing.T) {
 	}
 }
 
+func TestFeatureToolRules(t *testing.T) {
+	tests := []struct {
+		name           string
+		features       map[string]bool
+		wantAllowed    []string
+		wantDisallowed []string
+	}{
+		{name: "unset", features: nil},
+		{
+			name:        "web search on",
+			features:    map[string]bool{session.FeatureWebSearch: true},
+			wantAllowed: []string{"WebSearch"},
+		},
+		{
+			name:           "web search off",
+			features:       map[string]bool{session.FeatureWebSearch: false, session.FeatureNetworkTools: true},
+			wantAllowed:    []string{"WebFetch"},
+			wantDisallowed: []string{"WebSearch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, disallowed := FeatureToolRules(session.Config{Features: tt.features})
+			if !slices.Equal(allowed, tt.wantAllowed) {
+				t.Errorf("allowed = %v, want %v", allowed, tt.wantAllowed)
+			}
+			if !slices.Equal(disallowed, tt.wantDisallowed) {
+				t.Errorf("disallowed = %v, want %v", disallowed, tt.wantDisallowed)
+			}
+		})
+	}
+}
+
 func TestParseMCPConfig(t *testing.T) {
 	tests := []struct {
 		name    string
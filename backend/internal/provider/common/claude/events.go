@@ -48,6 +48,9 @@ func TranslateToOrbitMeshEvent(sessionID string, msg Message) (domain.Event, boo
 	case "assistant":
 		event, ok = handleAssistantMessage(sessionID, msg)
 
+	case "result":
+		event, ok = handleResultMessage(sessionID, msg, &CostTracker{})
+
 	default:
 		// Unknown message type - emit as metadata for debugging
 		event, ok = domain.NewMetadataEvent(sessionID, "unknown_message_type", map[string]any{
@@ -62,7 +65,13 @@ func TranslateToOrbitMeshEvent(sessionID string, msg Message) (domain.Event, boo
 func handleMessageStart(sessionID string, msg Message) (domain.Event, bool) {
 	// Extract usage if available
 	if usage, ok := msg.ExtractUsage(); ok && (usage.InputTokens > 0 || usage.OutputTokens > 0) {
-		return domain.NewMetricEvent(sessionID, usage.InputTokens, usage.OutputTokens, 1, msg.Raw()), true
+		return domain.NewMetricDataEvent(sessionID, domain.MetricData{
+			TokensIn:            usage.InputTokens,
+			TokensOut:           usage.OutputTokens,
+			RequestCount:        1,
+			CacheReadTokens:     usage.CacheReadInputTokens,
+			CacheCreationTokens: usage.CacheCreationInputTokens,
+		}, msg.Raw()), true
 	}
 
 	// Emit metadata about message start
@@ -84,11 +93,12 @@ func handleContentBlockStart(sessionID string, msg Message) (domain.Event, bool)
 		return domain.Event{}, false
 
 	case ContentBlockTypeToolUse:
-		// Tool use started - emit metadata
-		return domain.NewMetadataEvent(sessionID, "tool_use_start", map[string]any{
-			"tool_name": block.ToolUseName,
-			"tool_id":   block.ToolUseID,
-			"index":     block.Index,
+		return domain.NewToolCallEvent(sessionID, domain.ToolCallData{
+			ID:     block.ToolUseID,
+			Name:   block.ToolUseName,
+			Status: "started",
+			Title:  fmt.Sprintf("tool #%d", block.Index),
+			Input:  block.ToolInput,
 		}, msg.Raw()), true
 
 	default:
@@ -227,54 +237,121 @@ func handleSystemMessage(sessionID string, msg Message) (domain.Event, bool) {
 	return domain.NewMetadataEvent(sessionID, "system_init", metadata, msg.Raw()), true
 }
 
-// handleUserMessage processes user messages (typically tool results).
+// Translator converts the messages of one Claude stream to OrbitMesh events.
+// Unlike TranslateToOrbitMeshEvent it remembers the stream's cost, so each
+// result reports only what its run added.
+type Translator struct {
+	sessionID string
+	costs     CostTracker
+}
+
+// NewTranslator returns a Translator for the stream of a session.
+func NewTranslator(sessionID string) *Translator {
+	return &Translator{sessionID: sessionID}
+}
+
+// Translate converts msg like TranslateToOrbitMeshEvent.
+func (t *Translator) Translate(msg Message) (domain.Event, bool) {
+	if msg.Type == "result" {
+		return handleResultMessage(t.sessionID, msg, &t.costs)
+	}
+	return TranslateToOrbitMeshEvent(t.sessionID, msg)
+}
+
+// CostTracker turns the total_cost_usd of result messages, which the CLI
+// accumulates over its process, into what each run cost.
+type CostTracker struct {
+	total float64
+}
+
+// Delta records total and returns how much it grew since the last one. A
+// total below the last means the CLI restarted its count, so it is all new.
+func (c *CostTracker) Delta(total float64) float64 {
+	delta := total - c.total
+	if delta < 0 {
+		delta = total
+	}
+	c.total = total
+	return delta
+}
+
+// handleResultMessage processes the result message that ends a run. What its
+// total_cost_usd grew by since the last result becomes a metric event; the
+// tokens were already counted from the stream's message_delta events, so the
+// event reports only the cost.
+func handleResultMessage(sessionID string, msg Message, costs *CostTracker) (domain.Event, bool) {
+	if total, ok := msg.GetFloat("total_cost_usd"); ok && total > 0 {
+		if cost := costs.Delta(total); cost > 0 {
+			return domain.NewMetricDataEvent(sessionID, domain.MetricData{CostUSD: cost}, msg.Raw()), true
+		}
+	}
+	metadata := make(map[string]any)
+	if subtype, ok := msg.GetString("subtype"); ok {
+		metadata["subtype"] = subtype
+	}
+	if numTurns, ok := msg.GetInt("num_turns"); ok {
+		metadata["num_turns"] = numTurns
+	}
+	return domain.NewMetadataEvent(sessionID, "result", metadata, msg.Raw()), true
+}
+
+// InitEvents reports what a system init message says about the run: the
+// Claude session ID, as the conversation the session's next run resumes, and
+// the model.
+func InitEvents(sessionID string, msg Message) []domain.Event {
+	if msg.Type != "system" {
+		return nil
+	}
+	if subtype, _ := msg.GetString("subtype"); subtype != "init" {
+		return nil
+	}
+	var events []domain.Event
+	if id, ok := msg.GetString("session_id"); ok && id != "" {
+		events = append(events, domain.NewMetadataEvent(sessionID, domain.MetadataKeyConversationID, id, nil))
+	}
+	if model, ok := msg.GetString("model"); ok && model != "" {
+		events = append(events, domain.NewMetadataEvent(sessionID, domain.MetadataKeyModel, model, nil))
+	}
+	return events
+}
+
+// handleUserMessage processes user messages, which carry tool results. A
+// result ends its tool call, completed or failed.
 func handleUserMessage(sessionID string, msg Message) (domain.Event, bool) {
-	// Extract message content
 	messageMap, ok := msg.GetMap("message")
 	if !ok {
 		return domain.Event{}, false
 	}
-
-	role, _ := messageMap["role"].(string)
-	if role != "user" {
+	if role, _ := messageMap["role"].(string); role != "user" {
 		return domain.Event{}, false
 	}
-
-	// Check for tool results
 	content, ok := messageMap["content"].([]any)
-	if !ok || len(content) == 0 {
+	if !ok {
 		return domain.Event{}, false
 	}
+	parentID, _ := msg.GetString("parent_tool_use_id")
 
-	metadata := make(map[string]any)
-	metadata["role"] = role
-
-	// Extract tool result data
 	for _, item := range content {
 		itemMap, ok := item.(map[string]any)
-		if !ok {
+		if !ok || itemMap["type"] != "tool_result" {
 			continue
 		}
-
-		if itemType, ok := itemMap["type"].(string); ok && itemType == "tool_result" {
-			toolResult := make(map[string]any)
-
-			if toolUseID, ok := itemMap["tool_use_id"].(string); ok {
-				toolResult["tool_use_id"] = toolUseID
-			}
-			if resultContent, ok := itemMap["content"].(string); ok {
-				toolResult["content"] = resultContent
-			}
-			if isError, ok := itemMap["is_error"].(bool); ok {
-				toolResult["is_error"] = isError
-			}
-
-			metadata["tool_result"] = toolResult
-			break
+		toolUseID, _ := itemMap["tool_use_id"].(string)
+		if toolUseID == "" {
+			continue
+		}
+		status := "completed"
+		if isError, _ := itemMap["is_error"].(bool); isError {
+			status = "failed"
 		}
+		return domain.NewToolCallEvent(sessionID, domain.ToolCallData{
+			ID:       toolUseID,
+			Status:   status,
+			Output:   itemMap["content"],
+			ParentID: parentID,
+		}, msg.Raw()), true
 	}
-
-	return domain.NewMetadataEvent(sessionID, "tool_result", metadata, msg.Raw()), true
+	return domain.Event{}, false
 }
 
 // handleAssistantMessage processes assistant snapshot messages.
@@ -285,6 +362,10 @@ func handleAssistantMessage(sessionID string, msg Message) (domain.Event, bool)
 		return domain.Event{}, false
 	}
 
+	if plan, ok := exitPlanModePlan(messageMap); ok {
+		return domain.NewPlanEvent(sessionID, domain.PlanData{Description: plan}, msg.Raw()), true
+	}
+
 	metadata := make(map[string]any)
 
 	if role, ok := messageMap["role"].(string); ok {
@@ -296,6 +377,9 @@ func handleAssistantMessage(sessionID string, msg Message) (domain.Event, bool)
 	if msgID, ok := messageMap["id"].(string); ok {
 		metadata["message_id"] = msgID
 	}
+	if parentID, ok := msg.GetString("parent_tool_use_id"); ok && parentID != "" {
+		metadata["parent_tool_use_id"] = parentID
+	}
 	if stopReason, ok := messageMap["stop_reason"].(string); ok && stopReason != "" {
 		metadata["stop_reason"] = stopReason
 	}
@@ -348,3 +432,20 @@ func handleAssistantMessage(sessionID string, msg Message) (domain.Event, bool)
 
 	return domain.NewMetadataEvent(sessionID, "assistant_snapshot", metadata, msg.Raw()), true
 }
+
+// exitPlanModePlan returns the plan from an ExitPlanMode tool call, which
+// Claude makes in plan mode once its plan is ready.
+func exitPlanModePlan(messageMap map[string]any) (string, bool) {
+	content, _ := messageMap["content"].([]any)
+	for _, item := range content {
+		itemMap, ok := item.(map[string]any)
+		if !ok || itemMap["type"] != "tool_use" || itemMap["name"] != "ExitPlanMode" {
+			continue
+		}
+		input, _ := itemMap["input"].(map[string]any)
+		if plan, ok := input["plan"].(string); ok && plan != "" {
+			return plan, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,86 @@
+package claude
+
+import (
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestTranslateToOrbitMeshEvent_ExitPlanModeIsPlan(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"Here is my plan"},{"type":"tool_use","id":"t1","name":"ExitPlanMode","input":{"plan":"1. Read main.go\n2. Fix the bug"}}]}}`))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	event, ok := TranslateToOrbitMeshEvent("s1", msg)
+	if !ok || event.Type != domain.EventTypePlan {
+		t.Fatalf("event = %+v, want a plan event", event)
+	}
+	if plan, _ := event.Plan(); plan.Description != "1. Read main.go\n2. Fix the bug" {
+		t.Fatalf("plan = %q", plan.Description)
+	}
+
+	msg, _ = ParseMessage([]byte(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"main.go"}}]}}`))
+	if event, _ := TranslateToOrbitMeshEvent("s1", msg); event.Type == domain.EventTypePlan {
+		t.Fatal("a plain tool call should not become a plan event")
+	}
+}
+
+func TestInitEvents_SystemInit(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{"type":"system","subtype":"init","session_id":"claude-abc","model":"claude-sonnet-4-5","tools":[]}`))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	got := map[string]any{}
+	for _, event := range InitEvents("s1", msg) {
+		data, _ := event.Metadata()
+		got[data.Key] = data.Value
+	}
+	if got[domain.MetadataKeyConversationID] != "claude-abc" || got[domain.MetadataKeyModel] != "claude-sonnet-4-5" {
+		t.Fatalf("init events = %v", got)
+	}
+
+	msg, _ = ParseMessage([]byte(`{"type":"result","subtype":"success","session_id":"claude-abc"}`))
+	if events := InitEvents("s1", msg); len(events) != 0 {
+		t.Fatalf("only system init messages report the run, got %v", events)
+	}
+}
+
+func TestTranslateToOrbitMeshEvent_ResultReportsCost(t *testing.T) {
+	msg, err := ParseMessage([]byte(`{"type":"result","subtype":"success","total_cost_usd":0.042,"usage":{"input_tokens":100,"output_tokens":20}}`))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	event, ok := TranslateToOrbitMeshEvent("s1", msg)
+	data, isMetric := event.Metric()
+	if !ok || !isMetric {
+		t.Fatalf("event = %+v, want a metric event", event)
+	}
+	if data.CostUSD != 0.042 || data.TokensIn != 0 || data.TokensOut != 0 {
+		t.Fatalf("metric = %+v, want only the cost", data)
+	}
+}
+
+func TestTranslator_ResultReportsCostDelta(t *testing.T) {
+	translator := NewTranslator("s1")
+	var costs []float64
+	for _, line := range []string{
+		`{"type":"result","subtype":"success","total_cost_usd":0.04}`,
+		`{"type":"result","subtype":"success","total_cost_usd":0.1}`,
+		`{"type":"result","subtype":"success","total_cost_usd":0.1}`,
+		`{"type":"result","subtype":"success","total_cost_usd":0.03}`,
+	} {
+		msg, err := ParseMessage([]byte(line))
+		if err != nil {
+			t.Fatalf("ParseMessage: %v", err)
+		}
+		event, _ := translator.Translate(msg)
+		data, _ := event.Metric()
+		costs = append(costs, data.CostUSD)
+	}
+	want := []float64{0.04, 0.06, 0, 0.03}
+	for i := range want {
+		if diff := costs[i] - want[i]; diff > 1e-9 || diff < -1e-9 {
+			t.Fatalf("costs = %v, want %v", costs, want)
+		}
+	}
+}
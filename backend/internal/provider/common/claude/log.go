@@ -0,0 +1,206 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// LogEntry is one entry of a Claude Code log: a prompt the user sent, or an
+// event as the provider would have emitted it live.
+type LogEntry struct {
+	// Prompt is set for prompts; Event is then zero.
+	Prompt string
+	Event  domain.Event
+	// At is when the line was logged, zero when the log does not say.
+	At time.Time
+}
+
+// Log is a Claude Code session read back from its NDJSON output.
+type Log struct {
+	// ConversationID is Claude's own session ID.
+	ConversationID string
+	Model          string
+	WorkingDir     string
+	Entries        []LogEntry
+	// Skipped counts the lines that were not Claude messages, such as the
+	// summaries and file snapshots transcripts keep.
+	Skipped int
+}
+
+// ReadLog reads a Claude Code NDJSON log: the stream-json output of the
+// CLI, a claude-ws capture, or a transcript Claude Code keeps under
+// ~/.claude/projects. Each message is translated as the live provider
+// translates it. The provider takes assistant text from the partial message
+// stream; logs without one carry it only in their assistant messages, so it
+// is taken from there, along with their token usage.
+func ReadLog(sessionID string, r io.Reader) (*Log, error) {
+	var messages []Message
+	log := &Log{}
+	streamed := false
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			msg, parseErr := ParseMessage(line)
+			if parseErr != nil || !isMessageType(msg.Type) {
+				log.Skipped++
+			} else {
+				messages = append(messages, msg)
+				streamed = streamed || isStreamType(msg.Type)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read log: %w", err)
+		}
+	}
+	if len(messages) == 0 {
+		return nil, errors.New("log has no Claude messages")
+	}
+
+	counted := make(map[string]bool)
+	translator := NewTranslator(sessionID)
+	for _, msg := range messages {
+		at := messageTime(msg)
+		log.noteSession(msg)
+		if prompt, ok := userPrompt(msg); ok {
+			log.Entries = append(log.Entries, LogEntry{Prompt: prompt, At: at})
+			continue
+		}
+		var events []domain.Event
+		if msg.Type == "assistant" && !streamed {
+			events = append(events, assistantEvents(sessionID, msg, counted)...)
+		}
+		if event, ok := translator.Translate(msg); ok {
+			events = append(events, event)
+		}
+		events = append(events, InitEvents(sessionID, msg)...)
+		for _, event := range events {
+			if !at.IsZero() {
+				event.Timestamp = at
+			}
+			log.Entries = append(log.Entries, LogEntry{Event: event, At: at})
+		}
+	}
+	return log, nil
+}
+
+// isMessageType reports whether t is a type of message the CLI streams.
+func isMessageType(t MessageType) bool {
+	switch t {
+	case "system", "user", "assistant", "result", MessageTypeError, MessageTypePing:
+		return true
+	}
+	return isStreamType(t)
+}
+
+// isStreamType reports whether messages of type t are part of the partial
+// message stream.
+func isStreamType(t MessageType) bool {
+	switch t {
+	case MessageTypeMessageStart, MessageTypeContentBlockStart, MessageTypeContentBlockDelta,
+		MessageTypeContentBlockStop, MessageTypeMessageDelta, MessageTypeMessageStop:
+		return true
+	}
+	return false
+}
+
+// noteSession records what msg says about the session: init messages and
+// transcript lines both carry the conversation and working directory.
+func (l *Log) noteSession(msg Message) {
+	for _, key := range []string{"session_id", "sessionId"} {
+		if id, ok := msg.GetString(key); ok && id != "" && l.ConversationID == "" {
+			l.ConversationID = id
+		}
+	}
+	if cwd, ok := msg.GetString("cwd"); ok && cwd != "" && l.WorkingDir == "" {
+		l.WorkingDir = cwd
+	}
+	if model, ok := msg.GetString("model"); ok && model != "" && msg.Type == "system" {
+		l.Model = model
+	}
+	if model, ok := msg.GetString("message", "model"); ok && model != "" && l.Model == "" {
+		l.Model = model
+	}
+}
+
+// messageTime is the timestamp transcripts stamp on each line.
+func messageTime(msg Message) time.Time {
+	s, _ := msg.GetString("timestamp")
+	at, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return at
+}
+
+// userPrompt returns the text of a user message the user typed, as opposed
+// to one carrying tool results back to Claude.
+func userPrompt(msg Message) (string, bool) {
+	if msg.Type != "user" {
+		return "", false
+	}
+	if text, ok := msg.GetString("message", "content"); ok {
+		return text, text != ""
+	}
+	content, _ := msg.GetArray("message", "content")
+	var texts []string
+	for _, item := range content {
+		block, _ := item.(map[string]any)
+		switch block["type"] {
+		case "text":
+			if text, _ := block["text"].(string); text != "" {
+				texts = append(texts, text)
+			}
+		case "tool_result":
+			return "", false
+		}
+	}
+	return strings.Join(texts, "\n"), len(texts) > 0
+}
+
+// assistantEvents are the output, thoughts and usage of an assistant
+// message. Transcripts log a message once per content block, repeating its
+// usage, so usage is counted once per message ID.
+func assistantEvents(sessionID string, msg Message, counted map[string]bool) []domain.Event {
+	var events []domain.Event
+	content, _ := msg.GetArray("message", "content")
+	for _, item := range content {
+		block, _ := item.(map[string]any)
+		switch block["type"] {
+		case "text":
+			if text, _ := block["text"].(string); text != "" {
+				events = append(events, domain.NewOutputEvent(sessionID, text, msg.Raw()))
+			}
+		case "thinking":
+			if text, _ := block["thinking"].(string); text != "" {
+				events = append(events, domain.NewThoughtEvent(sessionID, text, msg.Raw()))
+			}
+		}
+	}
+
+	id, _ := msg.GetString("message", "id")
+	if id != "" && counted[id] {
+		return events
+	}
+	counted[id] = true
+	if usage, ok := msg.ExtractUsage(); ok && (usage.InputTokens > 0 || usage.OutputTokens > 0) {
+		events = append(events, domain.NewMetricDataEvent(sessionID, domain.MetricData{
+			TokensIn:            usage.InputTokens,
+			TokensOut:           usage.OutputTokens,
+			RequestCount:        1,
+			CacheReadTokens:     usage.CacheReadInputTokens,
+			CacheCreationTokens: usage.CacheCreationInputTokens,
+		}, msg.Raw()))
+	}
+	return events
+}
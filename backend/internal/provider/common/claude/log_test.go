@@ -0,0 +1,90 @@
+package claude
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestReadLog_StreamedOutput(t *testing.T) {
+	f, err := os.Open("claude_review.ndjson")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	log, err := ReadLog("s1", f)
+	if err != nil {
+		t.Fatalf("ReadLog: %v", err)
+	}
+	if log.ConversationID != "966c16b5-543f-4e25-a469-2bc5252a4144" || log.WorkingDir != "/Users/matt/mycode/orbitmesh/frontend" {
+		t.Fatalf("conversation = %q, working dir = %q", log.ConversationID, log.WorkingDir)
+	}
+	if log.Model != "claude-sonnet-4-5-20250929" {
+		t.Fatalf("model = %q", log.Model)
+	}
+	var deltas, whole int
+	for _, entry := range log.Entries {
+		if out, ok := entry.Event.Output(); ok {
+			if out.IsDelta {
+				deltas++
+			} else {
+				whole++
+			}
+		}
+	}
+	// The stream already carries the text, so assistant snapshots must not
+	// repeat it.
+	if deltas == 0 || whole != 0 {
+		t.Fatalf("deltas = %d, whole outputs = %d", deltas, whole)
+	}
+}
+
+func TestReadLog_Transcript(t *testing.T) {
+	transcript := strings.Join([]string{
+		`{"type":"summary","summary":"Fix the bug","leafUuid":"u3"}`,
+		`{"type":"user","sessionId":"claude-abc","cwd":"/work","timestamp":"2026-01-02T03:04:05.000Z","message":{"role":"user","content":"Fix the bug"}}`,
+		`{"type":"assistant","sessionId":"claude-abc","timestamp":"2026-01-02T03:04:06.000Z","message":{"id":"m1","role":"assistant","model":"claude-opus","content":[{"type":"thinking","thinking":"Look at main.go"}],"usage":{"input_tokens":10,"output_tokens":5}}}`,
+		`{"type":"assistant","sessionId":"claude-abc","timestamp":"2026-01-02T03:04:07.000Z","message":{"id":"m1","role":"assistant","model":"claude-opus","content":[{"type":"text","text":"Fixed it."}],"usage":{"input_tokens":10,"output_tokens":5}}}`,
+		`{"type":"user","sessionId":"claude-abc","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}`,
+		`not json`,
+	}, "\n")
+
+	log, err := ReadLog("s1", strings.NewReader(transcript))
+	if err != nil {
+		t.Fatalf("ReadLog: %v", err)
+	}
+	if log.Skipped != 2 {
+		t.Errorf("skipped = %d, want the summary and the bad line", log.Skipped)
+	}
+	if log.ConversationID != "claude-abc" || log.WorkingDir != "/work" || log.Model != "claude-opus" {
+		t.Errorf("log = %+v", log)
+	}
+	if len(log.Entries) == 0 || log.Entries[0].Prompt != "Fix the bug" || log.Entries[0].At.IsZero() {
+		t.Fatalf("first entry = %+v, want the timestamped prompt", log.Entries)
+	}
+
+	var thought, output string
+	var tokensIn int64
+	for _, entry := range log.Entries[1:] {
+		if entry.Prompt != "" {
+			t.Errorf("tool result read as prompt %q", entry.Prompt)
+		}
+		switch data := entry.Event.Data.(type) {
+		case domain.ThoughtData:
+			thought = data.Content
+		case domain.OutputData:
+			output = data.Content
+		case domain.MetricData:
+			tokensIn += data.TokensIn
+		}
+	}
+	if thought != "Look at main.go" || output != "Fixed it." {
+		t.Errorf("thought = %q, output = %q", thought, output)
+	}
+	if tokensIn != 10 {
+		t.Errorf("tokens in = %d, want message m1 counted once", tokensIn)
+	}
+}
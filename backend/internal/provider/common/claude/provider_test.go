@@ -2,6 +2,7 @@ package claude
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"testing"
 
@@ -206,3 +207,10 @@ func TestClaudeCodeProvider_HandleFailure(t *testing.T) {
 		t.Error("After failure: error should be set")
 	}
 }
+
+func TestClaudeCodeProvider_ResumeRunNeedsConversation(t *testing.T) {
+	provider := NewClaudeCodeProvider("test-session")
+	if _, err := provider.ResumeRun(context.Background(), session.Config{WorkingDir: t.TempDir()}); !errors.Is(err, ErrNoConversation) {
+		t.Fatalf("ResumeRun without a conversation = %v, want ErrNoConversation", err)
+	}
+}
@@ -173,8 +173,10 @@ func (m Message) Raw() []byte {
 
 // UsageData represents token usage information from Claude.
 type UsageData struct {
-	InputTokens  int64
-	OutputTokens int64
+	InputTokens              int64
+	OutputTokens             int64
+	CacheReadInputTokens     int64
+	CacheCreationInputTokens int64
 }
 
 // ExtractUsage extracts usage information from a message.
@@ -189,6 +191,12 @@ func (m Message) ExtractUsage() (UsageData, bool) {
 		if outputTokens, ok := usageMap["output_tokens"].(float64); ok {
 			usage.OutputTokens = int64(outputTokens)
 		}
+		if cacheRead, ok := usageMap["cache_read_input_tokens"].(float64); ok {
+			usage.CacheReadInputTokens = int64(cacheRead)
+		}
+		if cacheCreation, ok := usageMap["cache_creation_input_tokens"].(float64); ok {
+			usage.CacheCreationInputTokens = int64(cacheCreation)
+		}
 		return usage, true
 	}
 
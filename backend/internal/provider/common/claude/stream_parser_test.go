@@ -198,6 +198,16 @@ func TestMessage_ExtractUsage(t *testing.T) {
 			},
 			wantOk: true,
 		},
+		{
+			name:  "message_start with cache usage",
+			input: `{"type":"message_start","message":{"usage":{"input_tokens":3,"cache_read_input_tokens":1200,"cache_creation_input_tokens":40}}}`,
+			want: UsageData{
+				InputTokens:              3,
+				CacheReadInputTokens:     1200,
+				CacheCreationInputTokens: 40,
+			},
+			wantOk: true,
+		},
 		{
 			name: "message_delta format",
 			input: `{"type":"message_delta","delta":{"usage":{"output_tokens":5}}}`,
@@ -244,6 +254,9 @@ func TestMessage_ExtractUsage(t *testing.T) {
 				if got.OutputTokens != tt.want.OutputTokens {
 					t.Errorf("ExtractUsage() OutputTokens = %v, want %v", got.OutputTokens, tt.want.OutputTokens)
 				}
+				if got.CacheReadInputTokens != tt.want.CacheReadInputTokens || got.CacheCreationInputTokens != tt.want.CacheCreationInputTokens {
+					t.Errorf("ExtractUsage() cache = %v/%v, want %v/%v", got.CacheReadInputTokens, got.CacheCreationInputTokens, tt.want.CacheReadInputTokens, tt.want.CacheCreationInputTokens)
+				}
 			}
 		})
 	}
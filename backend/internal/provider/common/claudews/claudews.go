@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"maps"
-	"os"
 	"strings"
 	"sync"
 	"time"
@@ -17,6 +15,7 @@ import (
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/provider/buffer"
 	"github.com/ricochet1k/orbitmesh/internal/provider/circuit"
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
 	"github.com/ricochet1k/orbitmesh/internal/provider/native"
 	"github.com/ricochet1k/orbitmesh/internal/provider/process"
 	"github.com/ricochet1k/orbitmesh/internal/session"
@@ -66,6 +65,12 @@ type ClaudeWSProvider struct {
 	// claudeSessionID is received from the CLI's system/init message.
 	claudeSessionID string
 
+	subagents subagentTracker
+
+	// costUSD is the last total_cost_usd the CLI reported; it accumulates
+	// over the process, so each result's cost is the growth since then.
+	costUSD float64
+
 	connReady chan struct{} // closed when wsConn is established
 
 	started bool
@@ -105,6 +110,30 @@ func (p *ClaudeWSProvider) SendInput(ctx context.Context, config session.Config,
 	return p.events.Events(), nil
 }
 
+// Prewarm implements session.Prewarmer by starting the WebSocket server and
+// the Claude subprocess and waiting for it to connect, without sending a
+// prompt. SendInput blocks on p.mu until a warm-up in progress finishes.
+func (p *ClaudeWSProvider) Prewarm(ctx context.Context, config session.Config) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started {
+		return nil
+	}
+	return p.start(ctx, config)
+}
+
+// InjectSystemNote implements session.SystemNoter by queueing the note as a
+// system-reminder user message for the running agent.
+func (p *ClaudeWSProvider) InjectSystemNote(ctx context.Context, note string) error {
+	p.mu.RLock()
+	started := p.started
+	p.mu.RUnlock()
+	if !started {
+		return ErrNotStarted
+	}
+	return p.inputBuffer.Send(ctx, "<system-reminder>"+note+"</system-reminder>")
+}
+
 // start launches the WebSocket server and the Claude subprocess.
 // Caller must hold p.mu (write lock).
 func (p *ClaudeWSProvider) start(ctx context.Context, config session.Config) error {
@@ -138,23 +167,25 @@ func (p *ClaudeWSProvider) start(ctx context.Context, config session.Config) err
 		return err
 	}
 
-	// ── 3. Set up environment ────────────────────────────────────────────────
-	env := make(map[string]string)
-	for _, kv := range os.Environ() {
-		if parts := strings.SplitN(kv, "=", 2); len(parts) == 2 {
-			env[parts[0]] = parts[1]
-		}
-	}
-	maps.Copy(env, config.Environment)
-
 	log.Printf("[claudews] Starting claude in %q with args %q", config.WorkingDir, args)
 
-	// ── 4. Spawn the CLI process ─────────────────────────────────────────────
+	// ── 3. Spawn the CLI process ─────────────────────────────────────────────
+	// process.Start layers config.Environment over this process's own
+	// environment; on a remote host the listener is tunnelled so the same
+	// --sdk-url works there. Kubernetes jobs cannot reach the listener, so
+	// process.Start rejects them.
+	kubeConfig, err := kube.FromCustom(config.Custom)
+	if err != nil {
+		p.handleFailure(err)
+		return err
+	}
 	mgr, err := process.Start(p.ctx, process.Config{
 		Command:     "claude",
 		Args:        args,
 		WorkingDir:  config.WorkingDir,
-		Environment: env,
+		Environment: config.Environment,
+		Forwards:    []string{srv.ln.Addr().String()},
+		Kubernetes:  kubeConfig,
 	})
 	if err != nil {
 		p.handleFailure(err)
@@ -165,7 +196,7 @@ func (p *ClaudeWSProvider) start(ctx context.Context, config session.Config) err
 	// Drain stderr in a goroutine so the process doesn't block.
 	p.wg.Go(p.drainStderr)
 
-	// ── 5. Wait for the CLI to connect (up to 15 s) ──────────────────────────
+	// ── 4. Wait for the CLI to connect (up to 15 s) ──────────────────────────
 	select {
 	case <-p.connReady:
 		// Connection established; state transition happens in handleConnection.
@@ -176,7 +207,7 @@ func (p *ClaudeWSProvider) start(ctx context.Context, config session.Config) err
 		return fmt.Errorf("context cancelled before claude CLI connected")
 	}
 
-	// ── 6. Start the input forwarding goroutine ───────────────────────────────
+	// ── 5. Start the input forwarding goroutine ───────────────────────────────
 	p.wg.Go(p.processInput)
 
 	p.state.SetState(session.StateRunning)
@@ -265,6 +296,11 @@ func (p *ClaudeWSProvider) Status() session.Status {
 	return p.state.Status()
 }
 
+// Capabilities implements session.Session; the WebSocket protocol can interrupt a turn.
+func (p *ClaudeWSProvider) Capabilities() session.Capabilities {
+	return session.Capabilities{Suspend: true, Interrupt: true, Steering: true}
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Internal goroutines
 // ─────────────────────────────────────────────────────────────────────────────
@@ -322,6 +358,8 @@ func (p *ClaudeWSProvider) dispatchMessage(data []byte) {
 		p.handleSystemMsg(rm)
 	case "assistant":
 		p.handleAssistantMsg(rm)
+	case "user":
+		p.handleUserMsg(rm)
 	case "stream_event":
 		p.handleStreamEvent(rm)
 	case "result":
@@ -375,6 +413,12 @@ func (p *ClaudeWSProvider) handleSystemMsg(rm RawMessage) {
 			"tools":               tools,
 			"mcp_servers":         mcpServers,
 		}, rm.Raw))
+		if msg.SessionID != "" {
+			p.events.Emit(domain.NewMetadataEvent(p.sessionID, domain.MetadataKeyConversationID, msg.SessionID, nil))
+		}
+		if msg.Model != "" {
+			p.events.Emit(domain.NewMetadataEvent(p.sessionID, domain.MetadataKeyModel, msg.Model, nil))
+		}
 
 	case "status":
 		var msg SystemStatusMessage
@@ -425,6 +469,10 @@ func (p *ClaudeWSProvider) handleAssistantMsg(rm RawMessage) {
 	metadata := map[string]any{
 		"role": "assistant",
 	}
+	if msg.ParentToolUseID != nil && *msg.ParentToolUseID != "" {
+		metadata["parent_tool_use_id"] = *msg.ParentToolUseID
+		p.handleSubagentAssistant(*msg.ParentToolUseID, inner, rm.Raw)
+	}
 	if model, ok := inner["model"].(string); ok {
 		metadata["model"] = model
 	}
@@ -481,13 +529,19 @@ func (p *ClaudeWSProvider) handleStreamEvent(rm RawMessage) {
 		return
 	}
 
+	parentID := ""
+	if se.ParentToolUseID != nil {
+		parentID = *se.ParentToolUseID
+	}
+
 	// Use the outer rm.Raw as raw for all inner events — it's the full WS message.
-	p.dispatchInnerStreamEvent(inner.Type, innerData, rm.Raw)
+	p.dispatchInnerStreamEvent(inner.Type, innerData, parentID, rm.Raw)
 }
 
 // dispatchInnerStreamEvent handles the unwrapped Anthropic streaming event types.
+// parentID is the delegating tool call when the event comes from a subagent.
 // raw is the original wire bytes (outer WS message) to attach to every emitted event.
-func (p *ClaudeWSProvider) dispatchInnerStreamEvent(eventType string, data map[string]any, raw []byte) {
+func (p *ClaudeWSProvider) dispatchInnerStreamEvent(eventType string, data map[string]any, parentID string, raw []byte) {
 	switch eventType {
 	case "content_block_delta":
 		// Extract text delta for real-time streaming output.
@@ -503,13 +557,7 @@ func (p *ClaudeWSProvider) dispatchInnerStreamEvent(eventType string, data map[s
 		if cb, ok := data["content_block"].(map[string]any); ok {
 			if cbType, ok := cb["type"].(string); ok && cbType == "tool_use" {
 				idx, _ := data["index"].(float64)
-				p.events.Emit(domain.NewToolCallEvent(p.sessionID, domain.ToolCallData{
-					ID:     fmt.Sprint(cb["id"]),
-					Name:   fmt.Sprint(cb["name"]),
-					Status: "started",
-					Title:  fmt.Sprintf("tool #%d", int64(idx)),
-					Input:  cb["input"],
-				}, raw))
+				p.emitToolUseStart(cb, int64(idx), parentID, raw)
 			}
 		}
 
@@ -525,8 +573,16 @@ func (p *ClaudeWSProvider) dispatchInnerStreamEvent(eventType string, data map[s
 			if usageMap, ok := msgMap["usage"].(map[string]any); ok {
 				in, _ := usageMap["input_tokens"].(float64)
 				out, _ := usageMap["output_tokens"].(float64)
+				cacheRead, _ := usageMap["cache_read_input_tokens"].(float64)
+				cacheCreation, _ := usageMap["cache_creation_input_tokens"].(float64)
 				if in > 0 || out > 0 {
-					p.emitEvent(domain.NewMetricEvent(p.sessionID, int64(in), int64(out), 1, raw), raw)
+					p.emitEvent(domain.NewMetricDataEvent(p.sessionID, domain.MetricData{
+						TokensIn:            int64(in),
+						TokensOut:           int64(out),
+						RequestCount:        1,
+						CacheReadTokens:     int64(cacheRead),
+						CacheCreationTokens: int64(cacheCreation),
+					}, raw), raw)
 				}
 			}
 		}
@@ -569,9 +625,19 @@ func (p *ClaudeWSProvider) handleResultMsg(rm RawMessage) {
 		return
 	}
 
-	// Emit final token metrics.
-	if msg.Usage.InputTokens > 0 || msg.Usage.OutputTokens > 0 {
-		p.emitEvent(domain.NewMetricEvent(p.sessionID, msg.Usage.InputTokens, msg.Usage.OutputTokens, 0, rm.Raw), rm.Raw)
+	// Emit final token metrics and the run's cost. A total below the last
+	// means the CLI restarted its count.
+	cost := msg.TotalCostUSD - p.costUSD
+	if cost < 0 {
+		cost = msg.TotalCostUSD
+	}
+	p.costUSD = msg.TotalCostUSD
+	if msg.Usage.InputTokens > 0 || msg.Usage.OutputTokens > 0 || cost > 0 {
+		p.emitEvent(domain.NewMetricDataEvent(p.sessionID, domain.MetricData{
+			TokensIn:  msg.Usage.InputTokens,
+			TokensOut: msg.Usage.OutputTokens,
+			CostUSD:   cost,
+		}, rm.Raw), rm.Raw)
 	}
 
 	metadata := map[string]any{
@@ -619,7 +685,8 @@ func (p *ClaudeWSProvider) handleControlRequest(rm RawMessage) {
 
 	switch inner.Subtype {
 	case "can_use_tool":
-		p.handleCanUseTool(req, rm.Raw)
+		// A permission handler may wait on a human; keep reading meanwhile.
+		p.wg.Go(func() { p.handleCanUseTool(req, rm.Raw) })
 	default:
 		// Unknown control subtype — emit as metadata, send empty success.
 		p.events.Emit(domain.NewMetadataEvent(p.sessionID, "unknown_control_request", map[string]any{
@@ -763,7 +830,7 @@ func (p *ClaudeWSProvider) drainStderr() {
 		}
 		n, err := p.processMgr.Stderr().Read(buf)
 		if n > 0 {
-			p.events.Emit(domain.NewMetadataEvent(p.sessionID, "stderr", map[string]any{"stderr": string(buf[:n])}, nil))
+			p.events.Emit(domain.NewStderrEvent(p.sessionID, string(buf[:n])))
 		}
 		if err != nil {
 			return
@@ -787,7 +854,7 @@ func (p *ClaudeWSProvider) emitEvent(event domain.Event, raw []byte) {
 	case domain.EventTypeMetric:
 		if data, ok := event.Metric(); ok {
 			p.state.AddTokens(data.TokensIn, data.TokensOut)
-			p.events.Emit(domain.NewMetricEvent(p.sessionID, data.TokensIn, data.TokensOut, data.RequestCount, raw))
+			p.events.Emit(domain.NewMetricDataEvent(p.sessionID, data, raw))
 		}
 	case domain.EventTypeError:
 		if data, ok := event.Error(); ok {
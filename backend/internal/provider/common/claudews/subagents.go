@@ -0,0 +1,181 @@
+package claudews
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// subagentToolNames are the Claude Code tools that delegate work to a
+// subagent. Messages produced by the subagent carry the delegating call's ID
+// in parent_tool_use_id.
+var subagentToolNames = map[string]bool{
+	"Task":  true,
+	"Agent": true,
+}
+
+type subagentRun struct {
+	name  string
+	usage domain.TokenUsage
+	// seen holds nested tool_use IDs already emitted, since the same call can
+	// arrive both as a stream event and in the assistant snapshot.
+	seen map[string]bool
+}
+
+// subagentTracker follows in-flight subagent tool calls so nested activity
+// can be attributed to them and their token usage totalled.
+type subagentTracker struct {
+	mu   sync.Mutex
+	runs map[string]*subagentRun
+}
+
+func (t *subagentTracker) start(id, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.runs == nil {
+		t.runs = make(map[string]*subagentRun)
+	}
+	if _, ok := t.runs[id]; !ok {
+		t.runs[id] = &subagentRun{name: name, seen: make(map[string]bool)}
+	}
+}
+
+func (t *subagentTracker) addUsage(parentID string, in, out int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if run, ok := t.runs[parentID]; ok {
+		run.usage.InputTokens += in
+		run.usage.OutputTokens += out
+	}
+}
+
+// markSeen records a nested tool call and reports whether it is new.
+func (t *subagentTracker) markSeen(parentID, toolID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	run, ok := t.runs[parentID]
+	if !ok {
+		return true
+	}
+	if run.seen[toolID] {
+		return false
+	}
+	run.seen[toolID] = true
+	return true
+}
+
+func (t *subagentTracker) finish(id string) (subagentRun, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	run, ok := t.runs[id]
+	if !ok {
+		return subagentRun{}, false
+	}
+	delete(t.runs, id)
+	return *run, true
+}
+
+// emitToolUseStart reports a tool_use content block, registering it as a
+// subagent when it delegates.
+func (p *ClaudeWSProvider) emitToolUseStart(block map[string]any, index int64, parentID string, raw []byte) {
+	id := fmt.Sprint(block["id"])
+	name := fmt.Sprint(block["name"])
+	if parentID != "" && !p.subagents.markSeen(parentID, id) {
+		return
+	}
+	if subagentToolNames[name] {
+		p.subagents.start(id, name)
+	}
+	p.events.Emit(domain.NewToolCallEvent(p.sessionID, domain.ToolCallData{
+		ID:       id,
+		Name:     name,
+		Status:   "started",
+		Title:    fmt.Sprintf("tool #%d", index),
+		Input:    block["input"],
+		ParentID: parentID,
+	}, raw))
+}
+
+// handleSubagentAssistant attributes an assistant message emitted inside a
+// subagent to its parent call: usage is accumulated and nested tool calls are
+// surfaced with ParentID set.
+func (p *ClaudeWSProvider) handleSubagentAssistant(parentID string, inner map[string]any, raw []byte) {
+	if usageMap, ok := inner["usage"].(map[string]any); ok {
+		in, _ := usageMap["input_tokens"].(float64)
+		out, _ := usageMap["output_tokens"].(float64)
+		p.subagents.addUsage(parentID, int64(in), int64(out))
+	}
+
+	content, _ := inner["content"].([]any)
+	for i, item := range content {
+		block, ok := item.(map[string]any)
+		if !ok || block["type"] != "tool_use" {
+			continue
+		}
+		p.emitToolUseStart(block, int64(i), parentID, raw)
+	}
+}
+
+// handleUserMsg processes tool results sent back to the model. Results that
+// close a subagent or a call made inside one are reported as tool call
+// completions; other results are surfaced as metadata.
+func (p *ClaudeWSProvider) handleUserMsg(rm RawMessage) {
+	var msg struct {
+		Message struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+		ParentToolUseID *string `json:"parent_tool_use_id"`
+	}
+	if err := json.Unmarshal(rm.Raw, &msg); err != nil {
+		return
+	}
+
+	var items []struct {
+		Type      string `json:"type"`
+		ToolUseID string `json:"tool_use_id"`
+		Content   any    `json:"content"`
+		IsError   bool   `json:"is_error"`
+	}
+	if err := json.Unmarshal(msg.Message.Content, &items); err != nil {
+		// Plain string content (a prompt echo) carries no tool results.
+		return
+	}
+
+	parentID := ""
+	if msg.ParentToolUseID != nil {
+		parentID = *msg.ParentToolUseID
+	}
+
+	for _, item := range items {
+		if item.Type != "tool_result" || item.ToolUseID == "" {
+			continue
+		}
+		status := "completed"
+		if item.IsError {
+			status = "failed"
+		}
+
+		if run, ok := p.subagents.finish(item.ToolUseID); ok {
+			usage := run.usage
+			p.events.Emit(domain.NewToolCallEvent(p.sessionID, domain.ToolCallData{
+				ID:       item.ToolUseID,
+				Name:     run.name,
+				Status:   status,
+				Title:    "subagent finished",
+				Output:   item.Content,
+				ParentID: parentID,
+				Usage:    &usage,
+			}, rm.Raw))
+			continue
+		}
+
+		p.events.Emit(domain.NewToolCallEvent(p.sessionID, domain.ToolCallData{
+			ID:       item.ToolUseID,
+			Status:   status,
+			Output:   item.Content,
+			ParentID: parentID,
+		}, rm.Raw))
+	}
+}
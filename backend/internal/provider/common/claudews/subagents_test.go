@@ -0,0 +1,62 @@
+package claudews
+
+import (
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func drainToolCalls(p *ClaudeWSProvider) []domain.ToolCallData {
+	var calls []domain.ToolCallData
+	for {
+		select {
+		case ev := <-p.events.Events():
+			if tc, ok := ev.Data.(domain.ToolCallData); ok {
+				calls = append(calls, tc)
+			}
+		default:
+			return calls
+		}
+	}
+}
+
+func TestClaudeWS_SubagentToolCallsAreNested(t *testing.T) {
+	p := NewClaudeWSProvider("sess", nil)
+
+	msgs := []string{
+		// Parent spawns a subagent via the Task tool.
+		`{"type":"stream_event","parent_tool_use_id":null,"event":{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_task","name":"Task","input":{}}}}`,
+		// Subagent turn: usage plus a nested Bash call.
+		`{"type":"assistant","parent_tool_use_id":"toolu_task","message":{"role":"assistant","usage":{"input_tokens":100,"output_tokens":20},"content":[{"type":"tool_use","id":"toolu_bash","name":"Bash","input":{"command":"ls"}}]}}`,
+		// Repeated snapshot must not duplicate the nested call.
+		`{"type":"assistant","parent_tool_use_id":"toolu_task","message":{"role":"assistant","usage":{"input_tokens":5,"output_tokens":7},"content":[{"type":"tool_use","id":"toolu_bash","name":"Bash","input":{"command":"ls"}}]}}`,
+		`{"type":"user","parent_tool_use_id":"toolu_task","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_bash","content":"file.go"}]}}`,
+		`{"type":"user","parent_tool_use_id":null,"message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_task","content":"done"}]}}`,
+	}
+	for _, m := range msgs {
+		p.dispatchMessage([]byte(m))
+	}
+
+	calls := drainToolCalls(p)
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 tool call events, got %d: %+v", len(calls), calls)
+	}
+
+	if calls[0].ID != "toolu_task" || calls[0].ParentID != "" {
+		t.Errorf("unexpected task start: %+v", calls[0])
+	}
+	if calls[1].ID != "toolu_bash" || calls[1].ParentID != "toolu_task" || calls[1].Status != "started" {
+		t.Errorf("unexpected nested start: %+v", calls[1])
+	}
+	if calls[2].ID != "toolu_bash" || calls[2].ParentID != "toolu_task" || calls[2].Status != "completed" {
+		t.Errorf("unexpected nested completion: %+v", calls[2])
+	}
+
+	done := calls[3]
+	if done.ID != "toolu_task" || done.Name != "Task" || done.Status != "completed" {
+		t.Fatalf("unexpected subagent completion: %+v", done)
+	}
+	if done.Usage == nil || done.Usage.InputTokens != 105 || done.Usage.OutputTokens != 27 {
+		t.Errorf("unexpected subagent usage: %+v", done.Usage)
+	}
+}
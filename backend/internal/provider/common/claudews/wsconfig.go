@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/ricochet1k/orbitmesh/internal/provider/common/claude"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 )
 
@@ -19,9 +20,11 @@ func buildWSCommandArgs(sdkURL string, config session.Config) ([]string, error)
 		"--verbose", // include stream_event messages for streaming
 	}
 
-	if config.Custom == nil {
-		return args, nil
-	}
+	// Custom may be nil; reads from a nil map are safe.
+
+	// System prompt: the session's stable prompt prefix is appended to
+	// Claude's default; only an explicit system_prompt replaces it.
+	args = append(args, claude.SystemPromptArgs(config)...)
 
 	// Model selection
 	if model, ok := config.Custom["model"].(string); ok && model != "" {
@@ -46,6 +49,16 @@ func buildWSCommandArgs(sdkURL string, config session.Config) ([]string, error)
 		}
 	}
 
+	// Feature flags map to the same tool rules as the claude provider;
+	// verbose_tools needs nothing since --verbose is always on here.
+	allowed, disallowed := claude.FeatureToolRules(config)
+	for _, tool := range allowed {
+		args = append(args, "--allowedTools", tool)
+	}
+	for _, tool := range disallowed {
+		args = append(args, "--disallowedTools", tool)
+	}
+
 	// Budget cap
 	if maxBudget, ok := parseFloat(config.Custom["max_budget_usd"]); ok {
 		args = append(args, "--max-budget-usd", strconv.FormatFloat(maxBudget, 'f', -1, 64))
@@ -56,9 +69,12 @@ func buildWSCommandArgs(sdkURL string, config session.Config) ([]string, error)
 		args = append(args, "--max-turns", strconv.Itoa(int(maxTurns)))
 	}
 
-	// Session resume
+	// Session resume: an explicit resume_session_id, or the conversation
+	// of the session's last run
 	if resumeID, ok := config.Custom["resume_session_id"].(string); ok && resumeID != "" {
 		args = append(args, "--resume", resumeID)
+	} else if config.ConversationID != "" {
+		args = append(args, "--resume", config.ConversationID)
 	}
 
 	// Fork mode (resume but with new session ID)
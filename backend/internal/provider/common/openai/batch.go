@@ -0,0 +1,181 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+var _ session.BatchRunner = (*Session)(nil)
+
+// batchCustomID names the one request of a run's batch.
+const batchCustomID = "orbitmesh-run"
+
+// batchLine is a line of a batch's output or error file.
+type batchLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// SubmitBatch implements session.BatchRunner. The run goes to the Batch API
+// as one Chat Completions request with the system prompt and input. Tools
+// cannot run inside a batch, so the model answers without them.
+func (s *Session) SubmitBatch(ctx context.Context, config session.Config, input string) (string, error) {
+	s.mu.Lock()
+	err := s.connect(config)
+	client := s.client
+	s.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	var messages []openai.ChatCompletionMessageParamUnion
+	if strings.TrimSpace(config.SystemPrompt) != "" {
+		messages = append(messages, openai.SystemMessage(config.SystemPrompt))
+	}
+	messages = append(messages, openai.UserMessage(input))
+	line, err := json.Marshal(map[string]any{
+		"custom_id": batchCustomID,
+		"method":    "POST",
+		"url":       string(openai.BatchNewParamsEndpointV1ChatCompletions),
+		"body":      openai.ChatCompletionNewParams{Model: s.config.Model, Messages: messages},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	file, err := client.Files.New(ctx, openai.FileNewParams{
+		File:    openai.File(bytes.NewReader(append(line, '\n')), "orbitmesh-batch.jsonl", "application/jsonl"),
+		Purpose: openai.FilePurposeBatch,
+	})
+	if err != nil {
+		return "", s.sanitizeError(fmt.Errorf("upload batch input: %w", err))
+	}
+	batch, err := client.Batches.New(ctx, openai.BatchNewParams{
+		CompletionWindow: openai.BatchNewParamsCompletionWindow24h,
+		Endpoint:         openai.BatchNewParamsEndpointV1ChatCompletions,
+		InputFileID:      file.ID,
+	})
+	if err != nil {
+		return "", s.sanitizeError(fmt.Errorf("create batch: %w", err))
+	}
+	return batch.ID, nil
+}
+
+// PollBatch implements session.BatchRunner. A batch that failed, expired or
+// was cancelled is done with an error event. After a restart the session
+// has not submitted the batch itself, so the client comes from the
+// provider config and the server's environment.
+func (s *Session) PollBatch(ctx context.Context, ref string) ([]domain.Event, bool, error) {
+	s.mu.Lock()
+	var err error
+	if s.apiKey == "" {
+		err = s.connect(session.Config{})
+	}
+	client := s.client
+	s.mu.Unlock()
+	if err != nil {
+		return nil, false, err
+	}
+
+	batch, err := client.Batches.Get(ctx, ref)
+	if err != nil {
+		return nil, false, s.sanitizeError(err)
+	}
+	switch batch.Status {
+	case openai.BatchStatusCompleted:
+		fileID := batch.OutputFileID
+		if fileID == "" {
+			fileID = batch.ErrorFileID
+		}
+		if fileID == "" {
+			return []domain.Event{domain.NewErrorEvent(s.sessionID, "batch "+ref+" completed without output", "OPENAI_BATCH_EMPTY", nil)}, true, nil
+		}
+		events, err := s.batchEvents(ctx, client, fileID)
+		if err != nil {
+			return nil, false, err
+		}
+		return events, true, nil
+	case openai.BatchStatusFailed, openai.BatchStatusExpired, openai.BatchStatusCancelled:
+		msg := fmt.Sprintf("batch %s %s", ref, batch.Status)
+		if len(batch.Errors.Data) > 0 {
+			msg += ": " + batch.Errors.Data[0].Message
+		}
+		return []domain.Event{domain.NewErrorEvent(s.sessionID, msg, "OPENAI_BATCH_"+strings.ToUpper(string(batch.Status)), nil)}, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// batchEvents reads the run's line of a batch output or error file into
+// the events a live run would have ended with.
+func (s *Session) batchEvents(ctx context.Context, client openai.Client, fileID string) ([]domain.Event, error) {
+	resp, err := client.Files.Content(ctx, fileID)
+	if err != nil {
+		return nil, s.sanitizeError(fmt.Errorf("read batch output: %w", err))
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var line batchLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.CustomID != batchCustomID {
+			continue
+		}
+		if line.Error != nil {
+			return []domain.Event{domain.NewErrorEvent(s.sessionID, line.Error.Message, "OPENAI_BATCH_"+strings.ToUpper(line.Error.Code), nil)}, nil
+		}
+		if line.Response == nil {
+			break
+		}
+		raw := line.Response.Body
+		if line.Response.StatusCode != 200 {
+			return []domain.Event{domain.NewErrorEvent(s.sessionID, fmt.Sprintf("batch request failed with status %d: %s", line.Response.StatusCode, raw), "OPENAI_API_ERROR", raw)}, nil
+		}
+		var completion openai.ChatCompletion
+		if err := json.Unmarshal(raw, &completion); err != nil {
+			return nil, fmt.Errorf("parse batch output: %w", err)
+		}
+		var events []domain.Event
+		if usage := completion.Usage; usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+			s.state.AddTokens(usage.PromptTokens, usage.CompletionTokens)
+			events = append(events, domain.NewMetricDataEvent(s.sessionID, domain.MetricData{
+				TokensIn:        usage.PromptTokens,
+				TokensOut:       usage.CompletionTokens,
+				RequestCount:    1,
+				CacheReadTokens: usage.PromptTokensDetails.CachedTokens,
+			}, nil))
+		}
+		if len(completion.Choices) > 0 {
+			msg := completion.Choices[0].Message
+			if msg.Content != "" {
+				s.state.SetOutput(msg.Content)
+				events = append(events, domain.NewOutputEvent(s.sessionID, msg.Content, raw))
+			}
+			if msg.Refusal != "" {
+				events = append(events, domain.NewErrorEvent(s.sessionID, msg.Refusal, "OPENAI_REFUSAL", nil))
+			}
+		}
+		return events, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch output: %w", err)
+	}
+	return []domain.Event{domain.NewErrorEvent(s.sessionID, "batch output has no result for the run", "OPENAI_BATCH_EMPTY", nil)}, nil
+}
@@ -0,0 +1,119 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestSession_BatchRoundTrip(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		input  string
+		status = "in_progress"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("upload: %v", err)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			input = string(data)
+			fmt.Fprint(w, `{"id":"file-in","object":"file","purpose":"batch"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/batches":
+			fmt.Fprint(w, `{"id":"batch-1","object":"batch","status":"validating"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/batches/batch-1":
+			out := ""
+			if status == "completed" {
+				out = "file-out"
+			}
+			fmt.Fprintf(w, `{"id":"batch-1","object":"batch","status":%q,"output_file_id":%q}`, status, out)
+		case r.Method == http.MethodGet && r.URL.Path == "/files/file-out/content":
+			w.Header().Set("Content-Type", "application/jsonl")
+			fmt.Fprintf(w, `{"custom_id":%q,"response":{"status_code":200,"body":{"id":"c1","object":"chat.completion","model":"gpt-test","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"batched answer"}}],"usage":{"prompt_tokens":7,"completion_tokens":3,"total_tokens":10}}}}`+"\n", batchCustomID)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := NewSession("s1", Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-test"})
+	ref, err := s.SubmitBatch(context.Background(), session.Config{SystemPrompt: "be brief"}, "summarize")
+	if err != nil {
+		t.Fatalf("SubmitBatch: %v", err)
+	}
+	if ref != "batch-1" {
+		t.Fatalf("ref = %q", ref)
+	}
+	var line map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(input)), &line); err != nil {
+		t.Fatalf("batch input %q: %v", input, err)
+	}
+	body, _ := line["body"].(map[string]any)
+	if line["url"] != "/v1/chat/completions" || body["model"] != "gpt-test" {
+		t.Fatalf("batch input = %v", line)
+	}
+	if messages, _ := body["messages"].([]any); len(messages) != 2 {
+		t.Fatalf("messages = %v", body["messages"])
+	}
+
+	if events, done, err := s.PollBatch(context.Background(), ref); err != nil || done || len(events) != 0 {
+		t.Fatalf("in-progress poll = %v, %v, %v", events, done, err)
+	}
+
+	mu.Lock()
+	status = "completed"
+	mu.Unlock()
+	// A fresh session stands in for the one that submitted before a restart.
+	resumed := NewSession("s1", Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-test"})
+	events, done, err := resumed.PollBatch(context.Background(), ref)
+	if err != nil || !done {
+		t.Fatalf("completed poll: done=%v err=%v", done, err)
+	}
+	var output string
+	var metric bool
+	for _, ev := range events {
+		switch ev.Type {
+		case domain.EventTypeOutput:
+			output = ev.Data.(domain.OutputData).Content
+		case domain.EventTypeMetric:
+			metric = true
+		}
+	}
+	if output != "batched answer" || !metric {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestSession_BatchFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"batch-1","object":"batch","status":"failed","errors":{"data":[{"code":"invalid","message":"bad model"}]}}`)
+	}))
+	defer server.Close()
+
+	s := NewSession("s1", Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-test"})
+	events, done, err := s.PollBatch(context.Background(), "batch-1")
+	if err != nil || !done || len(events) != 1 || events[0].Type != domain.EventTypeError {
+		t.Fatalf("poll = %+v, %v, %v", events, done, err)
+	}
+	if msg := events[0].Data.(domain.ErrorData).Message; !strings.Contains(msg, "bad model") {
+		t.Fatalf("error = %q", msg)
+	}
+}
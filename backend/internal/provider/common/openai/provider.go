@@ -1,39 +1,49 @@
 package openai
 
 import (
+	"errors"
+
 	"github.com/openai/openai-go/v3"
-	"github.com/openai/openai-go/v3/option"
+
 	"github.com/ricochet1k/orbitmesh/internal/provider"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 )
 
+var (
+	ErrAPIKey          = errors.New("OpenAI API key not configured")
+	ErrProviderStopped = errors.New("provider is stopped")
+)
+
+const (
+	DefaultModel = openai.ChatModelGPT5_2
+	// DefaultMaxTurns caps the model calls one input may make while the
+	// model keeps calling tools.
+	DefaultMaxTurns   = 50
+	DefaultBufferSize = 100
+)
+
+// Config configures the OpenAI provider. Empty fields fall back to the
+// session's OPENAI_API_KEY and OPENAI_BASE_URL environment, then the
+// server's.
 type Config struct {
-	APIKey string `json:"api_key"`
+	APIKey   string `json:"api_key"`
+	BaseURL  string `json:"base_url"`
+	Model    string `json:"model"`
+	MaxTurns int    `json:"max_turns"`
 }
 
+// Provider creates sessions that talk to the OpenAI Chat Completions API,
+// or any server compatible with it.
 type Provider struct {
-	client openai.Client
+	config Config
 }
 
 var _ provider.Provider = (*Provider)(nil)
 
 func NewProvider(config Config) *Provider {
-	return &Provider{
-		client: openai.NewClient(
-			option.WithBaseURL(""),
-			option.WithAPIKey(""),
-			// option.WithOrganization()
-			// option.WithProject()
-			// option.WithHTTPClient(),
-			// option.WithHeader("key", "value"),
-			// option.WithMiddleware()
-			// option.WithMaxRetries()
-		),
-	}
+	return &Provider{config: config}
 }
 
 func (o *Provider) CreateSession(sessionID string, config session.Config) (session.Session, error) {
-	return &Session{
-		provider: o,
-	}, nil
+	return NewSession(sessionID, o.config), nil
 }
@@ -2,137 +2,346 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 
 	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
-	"github.com/openai/openai-go/v3/packages/ssestream"
-	"github.com/openai/openai-go/v3/responses"
+
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/provider/native"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 )
 
+// Session runs an agent on the OpenAI Chat Completions API. Each input
+// streams a response; while the model calls tools, their results are sent
+// back until it answers without one. The run ends after that answer.
 type Session struct {
-	provider     *Provider
-	sessionID    string
-	model        string
-	conversation []*session.Message
+	mu        sync.Mutex
+	sessionID string
+	config    Config
+	state     *native.ProviderState
+	events    *native.EventAdapter
 
-	liveStream *session.Stream
+	client   openai.Client
+	tools    toolset
+	messages []openai.ChatCompletionMessageParamUnion
+	// pending holds input sent while a response streams; it joins the
+	// conversation before the next model call.
+	pending []string
+	apiKey  string
 
-	mu     sync.Mutex
-	events *native.EventAdapter
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	started bool
+	stopped bool
 }
 
 var _ session.Session = (*Session)(nil)
 
-// SendInput implements session.Session.  It fires an OpenAI streaming request
-// and forwards events onto the returned channel.
+func NewSession(sessionID string, config Config) *Session {
+	if config.Model == "" {
+		config.Model = DefaultModel
+	}
+	if config.MaxTurns <= 0 {
+		config.MaxTurns = DefaultMaxTurns
+	}
+	return &Session{
+		sessionID: sessionID,
+		config:    config,
+		state:     native.NewProviderState(),
+		events:    native.NewEventAdapter(sessionID, DefaultBufferSize),
+		done:      make(chan struct{}),
+	}
+}
+
+// SendInput implements session.Session. The first call starts the run;
+// later calls add input to the conversation of the run in progress.
 func (s *Session) SendInput(ctx context.Context, config session.Config, input string) (<-chan domain.Event, error) {
 	s.mu.Lock()
-	if s.events == nil {
-		// Use session ID from config on first call.
-		sessID := config.TaskID
-		if sessID == "" {
-			sessID = "openai"
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return nil, ErrProviderStopped
+	}
+	if s.started {
+		s.pending = append(s.pending, input)
+		return s.events.Events(), nil
+	}
+	if err := s.start(ctx, config); err != nil {
+		return nil, err
+	}
+	s.messages = append(s.messages, openai.UserMessage(input))
+	go s.run()
+	return s.events.Events(), nil
+}
+
+// start creates the API client and the session's tools. Caller must hold
+// s.mu.
+func (s *Session) start(ctx context.Context, config session.Config) error {
+	if err := s.connect(config); err != nil {
+		s.state.SetError(err)
+		return err
+	}
+
+	s.state.SetState(session.StateStarting)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	if s.tools == nil && len(config.MCPServers) > 0 {
+		tools, err := newMCPToolset(ctx, config)
+		if err != nil {
+			s.cancel()
+			s.state.SetError(err)
+			return fmt.Errorf("failed to setup tools: %w", err)
 		}
-		s.sessionID = sessID
-		s.events = native.NewEventAdapter(sessID, 100)
+		s.tools = tools
+	}
+	if strings.TrimSpace(config.SystemPrompt) != "" {
+		s.messages = append(s.messages, openai.SystemMessage(config.SystemPrompt))
 	}
-	s.mu.Unlock()
 
-	stream := s.provider.client.Responses.NewStreaming(ctx, responses.ResponseNewParams{
-		Background:   param.NewOpt(true),
-		Instructions: param.NewOpt(""),
-		ContextManagement: []responses.ResponseNewParamsContextManagement{
-			{Type: "compaction", CompactThreshold: param.NewOpt[int64](2000000)},
-		},
-		Input: responses.ResponseNewParamsInputUnion{
-			OfInputItemList: responses.ResponseInputParam{
-				{OfInputMessage: &responses.ResponseInputItemMessageParam{
-					Role: "system",
-					Content: responses.ResponseInputMessageContentListParam{
-						{OfInputText: &responses.ResponseInputTextParam{Text: ""}},
-					},
-				}},
-				{OfMessage: &responses.EasyInputMessageParam{
-					Role:    "user",
-					Content: responses.EasyInputMessageContentUnionParam{OfString: param.NewOpt(input)},
-				}},
-			},
-		},
-		Model: openai.ChatModelGPT5_2,
-	})
+	s.state.SetState(session.StateRunning)
+	s.events.Emit(domain.NewMetadataEvent(s.sessionID, domain.MetadataKeyModel, s.config.Model, nil))
+	s.started = true
+	return nil
+}
 
-	s.liveStream = session.NewStream()
-	go s.handleStream(stream)
-	return s.events.Events(), nil
+// connect creates the API client from the provider config, then the
+// session's environment, then the server's. Caller must hold s.mu.
+func (s *Session) connect(config session.Config) error {
+	s.apiKey = firstNonEmpty(s.config.APIKey, config.Environment["OPENAI_API_KEY"], os.Getenv("OPENAI_API_KEY"))
+	if s.apiKey == "" {
+		return ErrAPIKey
+	}
+	opts := []option.RequestOption{option.WithAPIKey(s.apiKey)}
+	if baseURL := firstNonEmpty(s.config.BaseURL, config.Environment["OPENAI_BASE_URL"], os.Getenv("OPENAI_BASE_URL")); baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	s.client = openai.NewClient(opts...)
+	return nil
 }
 
-func (s *Session) handleStream(stream *ssestream.Stream[responses.ResponseStreamEventUnion]) {
+func (s *Session) run() {
+	defer close(s.done)
 	defer s.events.Close()
+	defer func() {
+		if s.tools != nil {
+			s.tools.close()
+		}
+	}()
 
-	messageId := "TODO"
-	currentMessage := session.Message{
-		ID:       messageId,
-		Kind:     session.MKAssistant,
-		Contents: "",
+	for turn := 0; ; turn++ {
+		if turn == s.config.MaxTurns {
+			s.events.Emit(domain.NewErrorEvent(s.sessionID, fmt.Sprintf("stopped after %d model calls", turn), "OPENAI_MAX_TURNS", nil))
+			break
+		}
+		calls, err := s.complete()
+		if err != nil {
+			if s.ctx.Err() == nil {
+				err = s.sanitizeError(err)
+				s.state.SetError(err)
+				s.events.Emit(domain.NewErrorEvent(s.sessionID, err.Error(), "OPENAI_API_ERROR", nil))
+			}
+			break
+		}
+		if len(calls) > 0 {
+			s.runTools(calls)
+			continue
+		}
+		if !s.takePending() {
+			break
+		}
 	}
-	s.conversation = append(s.conversation, &currentMessage)
 
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	if s.state.GetState() != session.StateError {
+		s.state.SetState(session.StateStopped)
+	}
+}
+
+// complete streams one model response into the conversation and returns
+// the tool calls it makes.
+func (s *Session) complete() ([]openai.ChatCompletionMessageToolCallUnion, error) {
+	s.mu.Lock()
+	params := openai.ChatCompletionNewParams{
+		Model:    s.config.Model,
+		Messages: s.messages,
+		StreamOptions: openai.ChatCompletionStreamOptionsParam{
+			IncludeUsage: param.NewOpt(true),
+		},
+	}
+	s.mu.Unlock()
+	if s.tools != nil {
+		params.Tools = s.tools.definitions()
+	}
+
+	stream := s.client.Chat.Completions.NewStreaming(s.ctx, params)
+	defer stream.Close()
+
+	var acc openai.ChatCompletionAccumulator
 	for stream.Next() {
-		data := stream.Current()
-		switch data.AsAny().(type) {
-		case responses.ResponseTextDeltaEvent:
-			currentMessage.Contents += data.Delta
-			s.liveStream.MessageAppend(session.Message{
-				ID:       messageId,
-				Contents: data.Delta,
-			})
-			s.events.Emit(domain.NewOutputEvent(s.sessionID, data.Delta, nil))
-		case responses.ResponseTextDoneEvent:
-			currentMessage.Contents = data.Text
-			s.liveStream.MessageReplace(session.Message{
-				ID:       messageId,
-				Contents: data.Text,
-			})
-		default:
-			msg := fmt.Sprintf("Unhandled stream event: %#v", data)
-			s.liveStream.MessageNew(session.Message{
-				ID:       messageId,
-				Kind:     session.MKError,
-				Contents: msg,
-			})
+		chunk := stream.Current()
+		acc.AddChunk(chunk)
+		raw := json.RawMessage(chunk.RawJSON())
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			s.events.Emit(domain.NewDeltaOutputEvent(s.sessionID, chunk.Choices[0].Delta.Content, raw))
+		}
+		if usage := chunk.Usage; usage.PromptTokens > 0 || usage.CompletionTokens > 0 {
+			s.state.AddTokens(usage.PromptTokens, usage.CompletionTokens)
+			s.events.Emit(domain.NewMetricDataEvent(s.sessionID, domain.MetricData{
+				TokensIn:        usage.PromptTokens,
+				TokensOut:       usage.CompletionTokens,
+				RequestCount:    1,
+				CacheReadTokens: usage.PromptTokensDetails.CachedTokens,
+			}, raw))
 		}
 	}
-
 	if err := stream.Err(); err != nil {
-		errMsg := session.Message{
-			ID:       messageId,
-			Kind:     session.MKError,
-			Contents: fmt.Sprintf("stream error: %v", err),
+		return nil, err
+	}
+	if len(acc.Choices) == 0 {
+		return nil, nil
+	}
+
+	msg := acc.Choices[0].Message
+	if msg.Content != "" {
+		s.state.SetOutput(msg.Content)
+	}
+	if msg.Refusal != "" {
+		s.events.Emit(domain.NewErrorEvent(s.sessionID, msg.Refusal, "OPENAI_REFUSAL", nil))
+	}
+	s.mu.Lock()
+	s.messages = append(s.messages, msg.ToParam())
+	s.mu.Unlock()
+	return msg.ToolCalls, nil
+}
+
+// runTools runs the tool calls of a response and adds their results to the
+// conversation.
+func (s *Session) runTools(calls []openai.ChatCompletionMessageToolCallUnion) {
+	for _, call := range calls {
+		var args map[string]any
+		if call.Function.Arguments != "" {
+			_ = json.Unmarshal([]byte(call.Function.Arguments), &args)
 		}
-		s.conversation = append(s.conversation, &errMsg)
-		s.liveStream.MessageNew(errMsg)
-		s.events.Emit(domain.NewErrorEvent(s.sessionID, err.Error(), "OPENAI_STREAM_ERROR", nil))
+		s.events.Emit(domain.NewToolCallEvent(s.sessionID, domain.ToolCallData{
+			ID:     call.ID,
+			Name:   call.Function.Name,
+			Status: "started",
+			Title:  call.Function.Name,
+			Input:  args,
+		}, json.RawMessage(call.RawJSON())))
+
+		var (
+			output string
+			failed bool
+			err    error
+		)
+		if s.tools == nil {
+			err = fmt.Errorf("unknown tool %q", call.Function.Name)
+		} else {
+			output, failed, err = s.tools.call(s.ctx, call.Function.Name, args)
+		}
+		if err != nil {
+			output, failed = err.Error(), true
+		}
+		status := "completed"
+		if failed {
+			status = "failed"
+		}
+		s.events.Emit(domain.NewToolCallEvent(s.sessionID, domain.ToolCallData{
+			ID:     call.ID,
+			Name:   call.Function.Name,
+			Status: status,
+			Output: output,
+		}, nil))
+
+		s.mu.Lock()
+		s.messages = append(s.messages, openai.ToolMessage(output, call.ID))
+		s.mu.Unlock()
+	}
+}
+
+// takePending moves input sent during the response into the conversation.
+// Without any, the run is over and later input is refused.
+func (s *Session) takePending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 || s.ctx.Err() != nil {
+		s.stopped = true
+		return false
 	}
+	for _, input := range s.pending {
+		s.messages = append(s.messages, openai.UserMessage(input))
+	}
+	s.pending = nil
+	return true
+}
+
+func (s *Session) sanitizeError(err error) error {
+	if s.apiKey == "" || !strings.Contains(err.Error(), s.apiKey) {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), s.apiKey, "[REDACTED]"))
+}
+
+// Stop cancels the response in progress and waits for the run to end.
+func (s *Session) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.stopped = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.mu.Unlock()
+
+	if !started {
+		s.events.Close()
+		return nil
+	}
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
 }
 
 func (s *Session) Kill() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if s.events != nil {
+	s.stopped = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if !s.started {
 		s.events.Close()
 	}
 	return nil
 }
 
 func (s *Session) Status() session.Status {
-	return session.Status{State: session.StateRunning}
+	return s.state.Status()
 }
 
-func (s *Session) Stop(ctx context.Context) error {
-	return s.Kill()
+// Capabilities implements session.Session; input sent during a run joins its
+// conversation before the next model call.
+func (s *Session) Capabilities() session.Capabilities {
+	return session.Capabilities{Steering: true}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
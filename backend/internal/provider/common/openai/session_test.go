@@ -0,0 +1,167 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+type fakeToolset struct {
+	mu    sync.Mutex
+	calls []map[string]any
+}
+
+func (f *fakeToolset) definitions() []openai.ChatCompletionToolUnionParam { return nil }
+
+func (f *fakeToolset) call(ctx context.Context, name string, args map[string]any) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, args)
+	return "main.go", false, nil
+}
+
+func (f *fakeToolset) close() {}
+
+// streamChunks writes chat completion chunks as a server-sent event stream.
+func streamChunks(w http.ResponseWriter, chunks ...string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, chunk := range chunks {
+		fmt.Fprintf(w, "data: %s\n\n", chunk)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func TestSession_ToolCallRound(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []map[string]any
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, body)
+		n := len(requests)
+		mu.Unlock()
+
+		if n == 1 {
+			streamChunks(w,
+				`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"files__list","arguments":""}}]}}]}`,
+				`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"dir\":\".\"}"}}]},"finish_reason":"tool_calls"}]}`,
+				`{"id":"c1","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+			)
+			return
+		}
+		streamChunks(w,
+			`{"id":"c2","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[{"index":0,"delta":{"role":"assistant","content":"Found "}}]}`,
+			`{"id":"c2","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[{"index":0,"delta":{"content":"main.go"},"finish_reason":"stop"}]}`,
+			`{"id":"c2","object":"chat.completion.chunk","created":1,"model":"gpt-test","choices":[],"usage":{"prompt_tokens":20,"completion_tokens":3,"total_tokens":23}}`,
+		)
+	}))
+	defer server.Close()
+
+	tools := &fakeToolset{}
+	s := NewSession("s1", Config{APIKey: "test-key", BaseURL: server.URL, Model: "gpt-test"})
+	s.tools = tools
+
+	events, err := s.SendInput(context.Background(), session.Config{SystemPrompt: "Be brief."}, "list the files")
+	if err != nil {
+		t.Fatalf("SendInput failed: %v", err)
+	}
+
+	var (
+		output    strings.Builder
+		toolCalls []domain.ToolCallData
+		tokensIn  int64
+		tokensOut int64
+	)
+	timeout := time.After(5 * time.Second)
+collect:
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				break collect
+			}
+			if data, ok := event.Output(); ok {
+				output.WriteString(data.Content)
+			}
+			if data, ok := event.ToolCall(); ok {
+				toolCalls = append(toolCalls, data)
+			}
+			if data, ok := event.Metric(); ok {
+				tokensIn += data.TokensIn
+				tokensOut += data.TokensOut
+			}
+			if data, ok := event.Error(); ok {
+				t.Fatalf("unexpected error event: %s", data.Message)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for the run to end")
+		}
+	}
+
+	if got := output.String(); got != "Found main.go" {
+		t.Errorf("output = %q", got)
+	}
+	if len(toolCalls) != 2 || toolCalls[0].Status != "started" || toolCalls[1].Status != "completed" {
+		t.Fatalf("tool calls = %+v, want started then completed", toolCalls)
+	}
+	if toolCalls[0].ID != "call_1" || toolCalls[0].Name != "files__list" || toolCalls[1].Output != "main.go" {
+		t.Errorf("tool calls = %+v", toolCalls)
+	}
+	if len(tools.calls) != 1 || tools.calls[0]["dir"] != "." {
+		t.Errorf("tool arguments = %+v", tools.calls)
+	}
+	if tokensIn != 30 || tokensOut != 8 {
+		t.Errorf("tokens = %d in, %d out; want 30, 8", tokensIn, tokensOut)
+	}
+
+	// The second request carries the system prompt, the tool call and its result.
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	messages, _ := requests[1]["messages"].([]any)
+	var roles []string
+	for _, m := range messages {
+		roles = append(roles, m.(map[string]any)["role"].(string))
+	}
+	if got := strings.Join(roles, ","); got != "system,user,assistant,tool" {
+		t.Errorf("roles = %s", got)
+	}
+
+	status := s.Status()
+	if status.State != session.StateStopped || status.Metrics.TokensIn != 30 || status.Output != "Found main.go" {
+		t.Errorf("status = %+v", status)
+	}
+	if _, err := s.SendInput(context.Background(), session.Config{}, "again"); err != ErrProviderStopped {
+		t.Errorf("SendInput after the run = %v, want ErrProviderStopped", err)
+	}
+}
+
+func TestSession_MissingAPIKey(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	s := NewSession("s1", Config{})
+	if _, err := s.SendInput(context.Background(), session.Config{}, "hi"); err != ErrAPIKey {
+		t.Fatalf("SendInput = %v, want ErrAPIKey", err)
+	}
+	if s.Status().State != session.StateError {
+		t.Errorf("state = %v, want error", s.Status().State)
+	}
+}
@@ -0,0 +1,121 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+	"github.com/openai/openai-go/v3/shared"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// toolset runs the tools the model may call.
+type toolset interface {
+	definitions() []openai.ChatCompletionToolUnionParam
+	// call runs a tool and returns its output, and whether the tool
+	// reported a failure.
+	call(ctx context.Context, name string, args map[string]any) (output string, failed bool, err error)
+	close()
+}
+
+// invalidToolName matches the characters OpenAI does not allow in function
+// names.
+var invalidToolName = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// mcpToolset exposes the tools of the session's MCP servers as functions,
+// named <server>__<tool>.
+type mcpToolset struct {
+	sessions []*mcp.ClientSession
+	tools    map[string]mcpTool
+	defs     []openai.ChatCompletionToolUnionParam
+}
+
+type mcpTool struct {
+	session *mcp.ClientSession
+	name    string
+}
+
+func newMCPToolset(ctx context.Context, config session.Config) (*mcpToolset, error) {
+	ts := &mcpToolset{tools: make(map[string]mcpTool)}
+	client := mcp.NewClient(&mcp.Implementation{Name: "orbitmesh"}, nil)
+	for _, server := range config.MCPServers {
+		cmd := exec.Command(server.Command, server.Args...)
+		cmd.Dir = config.WorkingDir
+		cmd.Env = os.Environ()
+		for k, v := range config.Environment {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		for k, v := range server.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+		cs, err := client.Connect(ctx, &mcp.CommandTransport{Command: cmd}, nil)
+		if err != nil {
+			ts.close()
+			return nil, fmt.Errorf("failed to start MCP server %s: %w", server.Name, err)
+		}
+		ts.sessions = append(ts.sessions, cs)
+
+		for tool, err := range cs.Tools(ctx, nil) {
+			if err != nil {
+				ts.close()
+				return nil, fmt.Errorf("failed to list tools of MCP server %s: %w", server.Name, err)
+			}
+			name := invalidToolName.ReplaceAllString(server.Name+"__"+tool.Name, "_")
+			name = name[:min(len(name), 64)]
+			ts.tools[name] = mcpTool{session: cs, name: tool.Name}
+
+			var params shared.FunctionParameters
+			if schema, err := json.Marshal(tool.InputSchema); err == nil {
+				_ = json.Unmarshal(schema, &params)
+			}
+			ts.defs = append(ts.defs, openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+				Name:        name,
+				Description: param.NewOpt(tool.Description),
+				Parameters:  params,
+			}))
+		}
+	}
+	return ts, nil
+}
+
+func (ts *mcpToolset) definitions() []openai.ChatCompletionToolUnionParam {
+	return ts.defs
+}
+
+func (ts *mcpToolset) call(ctx context.Context, name string, args map[string]any) (string, bool, error) {
+	tool, ok := ts.tools[name]
+	if !ok {
+		return "", true, fmt.Errorf("unknown tool %q", name)
+	}
+	result, err := tool.session.CallTool(ctx, &mcp.CallToolParams{Name: tool.name, Arguments: args})
+	if err != nil {
+		return "", true, err
+	}
+	var out []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			out = append(out, text.Text)
+		}
+	}
+	if len(out) == 0 && result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil {
+			out = append(out, string(data))
+		}
+	}
+	return strings.Join(out, "\n"), result.IsError, nil
+}
+
+func (ts *mcpToolset) close() {
+	for _, cs := range ts.sessions {
+		_ = cs.Close()
+	}
+	ts.sessions = nil
+}
@@ -0,0 +1,216 @@
+// Package demo provides in-process providers that need no external agent or
+// API key: "echo" repeats each message back and "replay" plays a scripted
+// transcript. They back public playground instances and local smoke tests.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// Provider types registered for the demo providers.
+const (
+	EchoProviderType   = "echo"
+	ReplayProviderType = "replay"
+)
+
+const defaultReplayDelay = 400 * time.Millisecond
+
+// Step is one scripted agent action. Exactly one of Thought, Tool or Output
+// is normally set; Delay is waited before the step is emitted.
+type Step struct {
+	Delay   time.Duration
+	Thought string
+	Tool    string
+	Output  string
+}
+
+// Session runs a script of steps for each input. Each instance serves a
+// single run: the event channel closes once the script finishes or the
+// session is stopped.
+type Session struct {
+	id     string
+	script func(input string) []Step
+
+	mu      sync.Mutex
+	state   session.State
+	output  string
+	metrics session.Metrics
+	cancel  context.CancelFunc
+}
+
+// NewEchoSession returns a session that answers each message with the
+// message itself.
+func NewEchoSession(sessionID string) *Session {
+	return &Session{
+		id: sessionID,
+		script: func(input string) []Step {
+			return []Step{{Output: "Echo: " + input}}
+		},
+	}
+}
+
+// NewReplaySession returns a session that replays lines as output, one per
+// delay. With no lines it replays a short built-in transcript that exercises
+// thoughts, tool calls and output.
+func NewReplaySession(sessionID string, lines []string, delay time.Duration) *Session {
+	if delay <= 0 {
+		delay = defaultReplayDelay
+	}
+	return &Session{
+		id: sessionID,
+		script: func(input string) []Step {
+			if len(lines) == 0 {
+				return builtinTranscript(input, delay)
+			}
+			steps := make([]Step, 0, len(lines))
+			for _, line := range lines {
+				steps = append(steps, Step{Delay: delay, Output: line})
+			}
+			return steps
+		},
+	}
+}
+
+func builtinTranscript(input string, delay time.Duration) []Step {
+	return []Step{
+		{Delay: delay, Thought: fmt.Sprintf("The user asked: %q. Let me look around the project first.", input)},
+		{Delay: delay, Tool: "list_files"},
+		{Delay: delay, Thought: "This is a demo workspace, so I'll describe what a real agent would do."},
+		{Delay: delay, Output: "This is a replayed demo transcript. Connect a real provider to run agents against your own projects."},
+	}
+}
+
+// ReplayConfig reads the replay script from a provider's custom settings:
+// "replay_lines" (a list of strings) and "replay_delay_ms".
+func ReplayConfig(custom map[string]any) ([]string, time.Duration) {
+	var lines []string
+	if raw, ok := custom["replay_lines"].([]any); ok {
+		for _, v := range raw {
+			if s, ok := v.(string); ok {
+				lines = append(lines, s)
+			}
+		}
+	}
+	var delay time.Duration
+	switch ms := custom["replay_delay_ms"].(type) {
+	case float64:
+		delay = time.Duration(ms) * time.Millisecond
+	case int:
+		delay = time.Duration(ms) * time.Millisecond
+	}
+	return lines, delay
+}
+
+func (s *Session) SendInput(ctx context.Context, config session.Config, input string) (<-chan domain.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != session.StateCreated {
+		return nil, fmt.Errorf("demo session %s is %s", s.id, s.state)
+	}
+
+	// The run outlives ctx, which only bounds startup.
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.state = session.StateRunning
+
+	events := make(chan domain.Event, 16)
+	go s.run(runCtx, events, s.script(input), input)
+	return events, nil
+}
+
+func (s *Session) run(ctx context.Context, events chan<- domain.Event, steps []Step, input string) {
+	defer close(events)
+	defer s.finish()
+
+	emit := func(event domain.Event) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var out strings.Builder
+	for i, step := range steps {
+		if step.Delay > 0 {
+			timer := time.NewTimer(step.Delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+		var event domain.Event
+		switch {
+		case step.Thought != "":
+			event = domain.NewThoughtEvent(s.id, step.Thought, nil)
+		case step.Tool != "":
+			event = domain.NewToolCallEvent(s.id, domain.ToolCallData{
+				ID:     fmt.Sprintf("demo-%d", i),
+				Name:   step.Tool,
+				Status: "completed",
+				Title:  step.Tool,
+			}, nil)
+		default:
+			out.WriteString(step.Output)
+			event = domain.NewOutputEvent(s.id, step.Output, nil)
+		}
+		if !emit(event) {
+			return
+		}
+	}
+
+	tokensIn, tokensOut := int64(len(strings.Fields(input))), int64(len(strings.Fields(out.String())))
+	s.mu.Lock()
+	s.output = out.String()
+	s.metrics = session.Metrics{TokensIn: tokensIn, TokensOut: tokensOut, RequestCount: 1, LastActivityAt: time.Now()}
+	s.mu.Unlock()
+	emit(domain.NewMetricEvent(s.id, tokensIn, tokensOut, 1, nil))
+}
+
+func (s *Session) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = session.StateStopped
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+func (s *Session) Stop(ctx context.Context) error {
+	return s.Kill()
+}
+
+func (s *Session) Kill() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.state = session.StateStopped
+	return nil
+}
+
+func (s *Session) Status() session.Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return session.Status{
+		State:   s.state,
+		Output:  s.output,
+		Metrics: s.metrics,
+	}
+}
+
+// Capabilities implements session.Session; demo sessions replay a fixed script.
+func (s *Session) Capabilities() session.Capabilities {
+	return session.Capabilities{}
+}
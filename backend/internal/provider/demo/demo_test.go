@@ -0,0 +1,87 @@
+package demo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func collect(t *testing.T, events <-chan domain.Event) []domain.Event {
+	t.Helper()
+	var out []domain.Event
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return out
+			}
+			out = append(out, ev)
+		case <-timeout:
+			t.Fatal("event channel was not closed")
+		}
+	}
+}
+
+func TestEchoSession(t *testing.T) {
+	s := NewEchoSession("s1")
+	events, err := s.SendInput(context.Background(), session.Config{}, "hello there")
+	if err != nil {
+		t.Fatalf("SendInput: %v", err)
+	}
+	got := collect(t, events)
+	if len(got) != 2 || got[0].Type != domain.EventTypeOutput || got[1].Type != domain.EventTypeMetric {
+		t.Fatalf("events = %+v", got)
+	}
+	if data, _ := got[0].Data.(domain.OutputData); data.Content != "Echo: hello there" {
+		t.Fatalf("output = %+v", got[0].Data)
+	}
+
+	status := s.Status()
+	if status.State != session.StateStopped || status.Metrics.TokensIn != 2 || status.Metrics.TokensOut != 3 {
+		t.Fatalf("status = %+v", status)
+	}
+	if _, err := s.SendInput(context.Background(), session.Config{}, "again"); err == nil {
+		t.Fatal("finished demo session accepted more input")
+	}
+}
+
+func TestReplaySession(t *testing.T) {
+	lines, delay := ReplayConfig(map[string]any{
+		"replay_lines":    []any{"one", "two"},
+		"replay_delay_ms": float64(1),
+	})
+	if delay != time.Millisecond {
+		t.Fatalf("delay = %v", delay)
+	}
+	events, err := NewReplaySession("s1", lines, delay).SendInput(context.Background(), session.Config{}, "go")
+	if err != nil {
+		t.Fatalf("SendInput: %v", err)
+	}
+	got := collect(t, events)
+	if len(got) != 3 {
+		t.Fatalf("events = %+v", got)
+	}
+	for i, want := range lines {
+		if data, _ := got[i].Data.(domain.OutputData); data.Content != want {
+			t.Errorf("event %d = %+v, want %q", i, got[i].Data, want)
+		}
+	}
+}
+
+func TestReplaySession_KillClosesChannel(t *testing.T) {
+	s := NewReplaySession("s1", nil, time.Hour)
+	events, err := s.SendInput(context.Background(), session.Config{}, "go")
+	if err != nil {
+		t.Fatalf("SendInput: %v", err)
+	}
+	if err := s.Kill(); err != nil {
+		t.Fatalf("Kill: %v", err)
+	}
+	if got := collect(t, events); len(got) != 0 {
+		t.Fatalf("killed replay emitted %+v", got)
+	}
+}
@@ -119,6 +119,9 @@ type mockSession struct{}
 func (m *mockSession) Stop(ctx context.Context) error { return nil }
 func (m *mockSession) Kill() error                    { return nil }
 func (m *mockSession) Status() session.Status         { return session.Status{} }
+func (m *mockSession) Capabilities() session.Capabilities {
+	return session.Capabilities{}
+}
 func (m *mockSession) SendInput(ctx context.Context, config session.Config, input string) (<-chan domain.Event, error) {
 	return nil, nil
 }
@@ -0,0 +1,444 @@
+// Package kube runs provider subprocesses as Kubernetes Jobs, so agent
+// fleets scale with the cluster instead of the OrbitMesh host.
+//
+// A provider opts in with a "kubernetes" entry in its custom config naming
+// the image and, optionally, resources and volumes. Each run creates a
+// one-pod Job whose container runs the provider command with stdin open;
+// the provider's own stdio protocol is then streamed through kubectl attach,
+// so providers need no changes to run in the cluster. Jobs are created with
+// the local kubectl, using the user's kubeconfig and credentials.
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CustomKey is the provider custom config key holding a Config.
+const CustomKey = "kubernetes"
+
+// ContainerName names the provider container in every Job.
+const ContainerName = "agent"
+
+// KubectlCommand is the kubectl binary.
+var KubectlCommand = "kubectl"
+
+// ErrInvalidConfig is returned for malformed kubernetes provider configs.
+var ErrInvalidConfig = errors.New("invalid kubernetes config")
+
+// AllowHostPath lets configs mount host_path volumes. Provider configs are
+// editable through the API, so only the server's administrator can turn
+// it on.
+var AllowHostPath = false
+
+// defaultTTLSeconds is how long finished Jobs are kept before the cluster
+// deletes them, when the config does not say.
+const defaultTTLSeconds = 300
+
+// defaultStartTimeoutSeconds bounds how long attach waits for the pod to be
+// scheduled and started, which includes pulling the image.
+const defaultStartTimeoutSeconds = 300
+
+var dnsLabel = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// Config describes where and how provider Jobs run.
+type Config struct {
+	// Context selects a kubeconfig context; empty uses the current one.
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Image     string `json:"image"`
+	// ImagePullPolicy is Always, IfNotPresent or Never.
+	ImagePullPolicy string `json:"image_pull_policy,omitempty"`
+	ServiceAccount  string `json:"service_account,omitempty"`
+	// WorkingDir is the container's working directory, typically where a
+	// volume with the project is mounted. Empty uses the image default.
+	WorkingDir   string            `json:"working_dir,omitempty"`
+	Resources    Resources         `json:"resources,omitzero"`
+	Volumes      []Volume          `json:"volumes,omitempty"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	// StartTimeoutSeconds bounds the wait for the pod to start running.
+	StartTimeoutSeconds int `json:"start_timeout_seconds,omitempty"`
+	// ActiveDeadlineSeconds, when set, makes the cluster kill runs that
+	// last longer.
+	ActiveDeadlineSeconds int64 `json:"active_deadline_seconds,omitempty"`
+	// TTLSecondsAfterFinished overrides how long finished Jobs are kept.
+	TTLSecondsAfterFinished *int32 `json:"ttl_seconds_after_finished,omitempty"`
+}
+
+// Resources are container resource requests and limits, in Kubernetes
+// quantity syntax such as {"cpu": "500m", "memory": "1Gi"}.
+type Resources struct {
+	Requests map[string]string `json:"requests,omitempty"`
+	Limits   map[string]string `json:"limits,omitempty"`
+}
+
+// Volume mounts one volume source into the provider container. Exactly one
+// of ClaimName, HostPath, ConfigMap, Secret and EmptyDir must be set.
+type Volume struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mount_path"`
+	SubPath   string `json:"sub_path,omitempty"`
+	ReadOnly  bool   `json:"read_only,omitempty"`
+
+	ClaimName string `json:"claim_name,omitempty"`
+	HostPath  string `json:"host_path,omitempty"`
+	ConfigMap string `json:"config_map,omitempty"`
+	Secret    string `json:"secret,omitempty"`
+	EmptyDir  bool   `json:"empty_dir,omitempty"`
+}
+
+// FromCustom reads the Config stored under CustomKey in a provider's custom
+// config. It returns nil when the provider does not run on Kubernetes.
+func FromCustom(custom map[string]any) (*Config, error) {
+	raw, ok := custom[CustomKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the fields the cluster would otherwise reject only after
+// a run has started.
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.Image) == "" {
+		return fmt.Errorf("%w: image is required", ErrInvalidConfig)
+	}
+	if c.Namespace != "" && !dnsLabel.MatchString(c.Namespace) {
+		return fmt.Errorf("%w: namespace %q is not a valid name", ErrInvalidConfig, c.Namespace)
+	}
+	switch c.ImagePullPolicy {
+	case "", "Always", "IfNotPresent", "Never":
+	default:
+		return fmt.Errorf("%w: unknown image_pull_policy %q", ErrInvalidConfig, c.ImagePullPolicy)
+	}
+	if c.WorkingDir != "" && !path.IsAbs(c.WorkingDir) {
+		return fmt.Errorf("%w: working_dir must be absolute", ErrInvalidConfig)
+	}
+	if c.StartTimeoutSeconds < 0 || c.ActiveDeadlineSeconds < 0 {
+		return fmt.Errorf("%w: timeouts must not be negative", ErrInvalidConfig)
+	}
+	seen := make(map[string]bool, len(c.Volumes))
+	for _, v := range c.Volumes {
+		if !dnsLabel.MatchString(v.Name) {
+			return fmt.Errorf("%w: volume name %q is not a valid name", ErrInvalidConfig, v.Name)
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("%w: duplicate volume %q", ErrInvalidConfig, v.Name)
+		}
+		seen[v.Name] = true
+		if !path.IsAbs(v.MountPath) {
+			return fmt.Errorf("%w: volume %q mount_path must be absolute", ErrInvalidConfig, v.Name)
+		}
+		sources := 0
+		for _, set := range []bool{v.ClaimName != "", v.HostPath != "", v.ConfigMap != "", v.Secret != "", v.EmptyDir} {
+			if set {
+				sources++
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("%w: volume %q needs exactly one source", ErrInvalidConfig, v.Name)
+		}
+		if v.HostPath != "" && !AllowHostPath {
+			return fmt.Errorf("%w: volume %q: host_path volumes are disabled on this server", ErrInvalidConfig, v.Name)
+		}
+	}
+	return nil
+}
+
+// Spec describes a command to run in a Job.
+type Spec struct {
+	Command string
+	Args    []string
+	// Env is the container environment; the local environment is not
+	// forwarded. It is stored in a Secret owned by the Job, never in the
+	// Job spec.
+	Env map[string]string
+	// TTY allocates a terminal in the container; start the attach command
+	// on a local pty so the terminal hub sees the pod's screen.
+	TTY bool
+}
+
+// Job is a provider Job created in the cluster, with the Secret holding its
+// environment when it has one. Both are named Name.
+type Job struct {
+	Name   string
+	config *Config
+	secret bool
+}
+
+// Launch creates a Job running spec and returns it with the kubectl attach
+// command that streams the container's stdio. Output written before attach
+// connects is only available from the pod's logs. Delete the Job once the
+// run is over; finished Jobs are also garbage collected by the cluster.
+func (c *Config) Launch(ctx context.Context, spec Spec) (*Job, *exec.Cmd, error) {
+	if spec.Command == "" {
+		return nil, nil, errors.New("command cannot be empty")
+	}
+	name, err := jobName()
+	if err != nil {
+		return nil, nil, err
+	}
+	job := &Job{Name: name, config: c, secret: len(spec.Env) > 0}
+	if job.secret {
+		secret, err := json.Marshal(c.secretManifest(name, spec.Env))
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := c.kubectl(ctx, secret, "create", "-f", "-"); err != nil {
+			return nil, nil, fmt.Errorf("create secret %s: %w", name, err)
+		}
+	}
+	manifest, err := json.Marshal(c.manifest(name, spec))
+	if err != nil {
+		_ = job.Delete(context.WithoutCancel(ctx))
+		return nil, nil, err
+	}
+	uid, err := c.kubectl(ctx, manifest, "create", "-f", "-", "-o", "jsonpath={.metadata.uid}")
+	if err != nil {
+		_ = job.Delete(context.WithoutCancel(ctx))
+		return nil, nil, fmt.Errorf("create job %s: %w", name, err)
+	}
+	if job.secret {
+		// Owned by the Job, the Secret is garbage collected with it.
+		owner, _ := json.Marshal(map[string]any{"metadata": map[string]any{"ownerReferences": []any{map[string]any{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"name":       name,
+			"uid":        uid,
+		}}}})
+		if _, err := c.kubectl(ctx, nil, "patch", "secret", name, "--type=merge", "-p", string(owner)); err != nil {
+			_ = job.Delete(context.WithoutCancel(ctx))
+			return nil, nil, fmt.Errorf("patch secret %s: %w", name, err)
+		}
+	}
+
+	return job, exec.CommandContext(ctx, KubectlCommand, job.attachArgs(spec.TTY)...), nil
+}
+
+// Delete removes the Job, its pod and its Secret, stopping the run if it
+// is still going.
+func (j *Job) Delete(ctx context.Context) error {
+	resources := []string{"job/" + j.Name}
+	if j.secret {
+		resources = append(resources, "secret/"+j.Name)
+	}
+	args := append(append([]string{"delete"}, resources...), "--ignore-not-found", "--wait=false", "--cascade=background")
+	if _, err := j.config.kubectl(ctx, nil, args...); err != nil {
+		return fmt.Errorf("delete job %s: %w", j.Name, err)
+	}
+	return nil
+}
+
+// kubectl runs kubectl with args and stdin, returning its standard output.
+func (c *Config) kubectl(ctx context.Context, stdin []byte, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, KubectlCommand, c.kubectlArgs(args...)...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (j *Job) attachArgs(tty bool) []string {
+	timeout := j.config.StartTimeoutSeconds
+	if timeout == 0 {
+		timeout = defaultStartTimeoutSeconds
+	}
+	args := []string{"attach", "job/" + j.Name, "-c", ContainerName, "-i", "--quiet", "--pod-running-timeout=" + strconv.Itoa(timeout) + "s"}
+	if tty {
+		args = append(args, "-t")
+	}
+	return j.config.kubectlArgs(args...)
+}
+
+// kubectlArgs prefixes args with the context and namespace flags.
+func (c *Config) kubectlArgs(args ...string) []string {
+	var global []string
+	if c.Context != "" {
+		global = append(global, "--context", c.Context)
+	}
+	if c.Namespace != "" {
+		global = append(global, "--namespace", c.Namespace)
+	}
+	return append(global, args...)
+}
+
+// manifest builds the batch/v1 Job for spec. The pod never restarts and
+// the Job never retries: a failed run is reported back to the session like
+// any other provider failure.
+func (c *Config) manifest(name string, spec Spec) map[string]any {
+	container := map[string]any{
+		"name":      ContainerName,
+		"image":     c.Image,
+		"command":   []string{spec.Command},
+		"stdin":     true,
+		"stdinOnce": true,
+		"tty":       spec.TTY,
+	}
+	if len(spec.Args) > 0 {
+		container["args"] = spec.Args
+	}
+	if c.ImagePullPolicy != "" {
+		container["imagePullPolicy"] = c.ImagePullPolicy
+	}
+	if c.WorkingDir != "" {
+		container["workingDir"] = c.WorkingDir
+	}
+	if len(spec.Env) > 0 {
+		keys := make([]string, 0, len(spec.Env))
+		for k := range spec.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		env := make([]any, 0, len(keys))
+		for _, k := range keys {
+			env = append(env, map[string]any{"name": k, "valueFrom": map[string]any{
+				"secretKeyRef": map[string]any{"name": name, "key": k},
+			}})
+		}
+		container["env"] = env
+	}
+	if resources := map[string]any{}; len(c.Resources.Requests) > 0 || len(c.Resources.Limits) > 0 {
+		if len(c.Resources.Requests) > 0 {
+			resources["requests"] = c.Resources.Requests
+		}
+		if len(c.Resources.Limits) > 0 {
+			resources["limits"] = c.Resources.Limits
+		}
+		container["resources"] = resources
+	}
+
+	podSpec := map[string]any{
+		"restartPolicy": "Never",
+		"containers":    []any{container},
+	}
+	if c.ServiceAccount != "" {
+		podSpec["serviceAccountName"] = c.ServiceAccount
+	}
+	if len(c.NodeSelector) > 0 {
+		podSpec["nodeSelector"] = c.NodeSelector
+	}
+	if len(c.Volumes) > 0 {
+		volumes := make([]any, 0, len(c.Volumes))
+		mounts := make([]any, 0, len(c.Volumes))
+		for _, v := range c.Volumes {
+			volumes = append(volumes, v.source())
+			mount := map[string]any{"name": v.Name, "mountPath": v.MountPath}
+			if v.SubPath != "" {
+				mount["subPath"] = v.SubPath
+			}
+			if v.ReadOnly {
+				mount["readOnly"] = true
+			}
+			mounts = append(mounts, mount)
+		}
+		podSpec["volumes"] = volumes
+		container["volumeMounts"] = mounts
+	}
+
+	labels := c.labels()
+	ttl := int32(defaultTTLSeconds)
+	if c.TTLSecondsAfterFinished != nil {
+		ttl = *c.TTLSecondsAfterFinished
+	}
+	jobSpec := map[string]any{
+		"backoffLimit":            0,
+		"ttlSecondsAfterFinished": ttl,
+		"template": map[string]any{
+			"metadata": map[string]any{"labels": labels},
+			"spec":     podSpec,
+		},
+	}
+	if c.ActiveDeadlineSeconds > 0 {
+		jobSpec["activeDeadlineSeconds"] = c.ActiveDeadlineSeconds
+	}
+
+	metadata := map[string]any{"name": name, "labels": labels}
+	if c.Namespace != "" {
+		metadata["namespace"] = c.Namespace
+	}
+	return map[string]any{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata":   metadata,
+		"spec":       jobSpec,
+	}
+}
+
+// secretManifest builds the v1 Secret holding a Job's environment. The
+// Job's containers read each key with secretKeyRef.
+func (c *Config) secretManifest(name string, env map[string]string) map[string]any {
+	metadata := map[string]any{"name": name, "labels": c.labels()}
+	if c.Namespace != "" {
+		metadata["namespace"] = c.Namespace
+	}
+	return map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   metadata,
+		"type":       "Opaque",
+		"stringData": env,
+	}
+}
+
+func (c *Config) labels() map[string]string {
+	labels := map[string]string{"app.kubernetes.io/managed-by": "orbitmesh"}
+	maps.Copy(labels, c.Labels)
+	return labels
+}
+
+func (v Volume) source() map[string]any {
+	source := map[string]any{"name": v.Name}
+	switch {
+	case v.ClaimName != "":
+		source["persistentVolumeClaim"] = map[string]any{"claimName": v.ClaimName, "readOnly": v.ReadOnly}
+	case v.HostPath != "":
+		source["hostPath"] = map[string]any{"path": v.HostPath}
+	case v.ConfigMap != "":
+		source["configMap"] = map[string]any{"name": v.ConfigMap}
+	case v.Secret != "":
+		source["secret"] = map[string]any{"secretName": v.Secret}
+	default:
+		source["emptyDir"] = map[string]any{}
+	}
+	return source
+}
+
+func jobName() (string, error) {
+	var b [5]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "orbitmesh-" + hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,195 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromCustom(t *testing.T) {
+	if cfg, err := FromCustom(map[string]any{"model": "x"}); cfg != nil || err != nil {
+		t.Fatalf("FromCustom without kubernetes = %+v, %v", cfg, err)
+	}
+
+	cfg, err := FromCustom(map[string]any{CustomKey: map[string]any{
+		"namespace": "agents",
+		"image":     "ghcr.io/acme/agent:1",
+		"resources": map[string]any{"limits": map[string]any{"cpu": "2"}},
+		"volumes": []any{
+			map[string]any{"name": "work", "mount_path": "/work", "claim_name": "agent-work"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("FromCustom: %v", err)
+	}
+	if cfg.Image != "ghcr.io/acme/agent:1" || cfg.Resources.Limits["cpu"] != "2" || cfg.Volumes[0].ClaimName != "agent-work" {
+		t.Fatalf("FromCustom = %+v", cfg)
+	}
+
+	for name, bad := range map[string]map[string]any{
+		"missing image":  {"namespace": "agents"},
+		"unknown field":  {"image": "a", "imagee": "b"},
+		"bad namespace":  {"image": "a", "namespace": "Agents"},
+		"relative mount": {"image": "a", "volumes": []any{map[string]any{"name": "w", "mount_path": "work", "empty_dir": true}}},
+		"two sources":    {"image": "a", "volumes": []any{map[string]any{"name": "w", "mount_path": "/w", "empty_dir": true, "secret": "s"}}},
+		"no source":      {"image": "a", "volumes": []any{map[string]any{"name": "w", "mount_path": "/w"}}},
+		"host path":      {"image": "a", "volumes": []any{map[string]any{"name": "w", "mount_path": "/w", "host_path": "/"}}},
+	} {
+		if _, err := FromCustom(map[string]any{CustomKey: bad}); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: error = %v, want ErrInvalidConfig", name, err)
+		}
+	}
+
+	defer func() { AllowHostPath = false }()
+	AllowHostPath = true
+	hostPath := map[string]any{"image": "a", "volumes": []any{map[string]any{"name": "w", "mount_path": "/w", "host_path": "/srv"}}}
+	if _, err := FromCustom(map[string]any{CustomKey: hostPath}); err != nil {
+		t.Fatalf("host_path with AllowHostPath: %v", err)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	cfg := &Config{
+		Namespace:  "agents",
+		Image:      "agent:1",
+		WorkingDir: "/work",
+		Resources:  Resources{Requests: map[string]string{"memory": "1Gi"}},
+		Volumes:    []Volume{{Name: "work", MountPath: "/work", ClaimName: "agent-work", ReadOnly: true}},
+		Labels:     map[string]string{"team": "infra"},
+	}
+	data, err := json.Marshal(cfg.manifest("orbitmesh-test", Spec{
+		Command: "claude",
+		Args:    []string{"-p"},
+		Env:     map[string]string{"B": "2", "A": "1"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var job struct {
+		Kind     string
+		Metadata struct {
+			Name, Namespace string
+			Labels          map[string]string
+		}
+		Spec struct {
+			BackoffLimit int
+			Template     struct {
+				Spec struct {
+					RestartPolicy string
+					Containers    []struct {
+						Name, Image, WorkingDir string
+						Command, Args           []string
+						Stdin, StdinOnce, TTY   bool
+						Env                     []struct {
+							Name, Value string
+							ValueFrom   struct {
+								SecretKeyRef struct{ Name, Key string }
+							}
+						}
+						Resources    struct{ Requests map[string]string }
+						VolumeMounts []struct {
+							Name, MountPath string
+							ReadOnly        bool
+						}
+					}
+					Volumes []struct {
+						Name                  string
+						PersistentVolumeClaim struct{ ClaimName string }
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &job); err != nil {
+		t.Fatal(err)
+	}
+
+	if job.Kind != "Job" || job.Metadata.Name != "orbitmesh-test" || job.Metadata.Namespace != "agents" {
+		t.Fatalf("metadata = %s %+v", job.Kind, job.Metadata)
+	}
+	if job.Metadata.Labels["team"] != "infra" || job.Metadata.Labels["app.kubernetes.io/managed-by"] != "orbitmesh" {
+		t.Fatalf("labels = %v", job.Metadata.Labels)
+	}
+	pod := job.Spec.Template.Spec
+	if job.Spec.BackoffLimit != 0 || pod.RestartPolicy != "Never" || len(pod.Containers) != 1 {
+		t.Fatalf("job spec = %s", data)
+	}
+	c := pod.Containers[0]
+	if c.Name != ContainerName || c.Image != "agent:1" || c.WorkingDir != "/work" || !c.Stdin || !c.StdinOnce || c.TTY {
+		t.Fatalf("container = %+v", c)
+	}
+	if !reflect.DeepEqual(c.Command, []string{"claude"}) || !reflect.DeepEqual(c.Args, []string{"-p"}) {
+		t.Fatalf("command = %q %q", c.Command, c.Args)
+	}
+	if len(c.Env) != 2 || c.Env[0].Name != "A" || c.Env[1].ValueFrom.SecretKeyRef != (struct{ Name, Key string }{"orbitmesh-test", "B"}) {
+		t.Fatalf("env = %+v", c.Env)
+	}
+	if strings.Contains(string(data), `"value"`) {
+		t.Fatalf("environment values in the Job spec: %s", data)
+	}
+	if c.Resources.Requests["memory"] != "1Gi" {
+		t.Fatalf("resources = %+v", c.Resources)
+	}
+	if len(c.VolumeMounts) != 1 || !c.VolumeMounts[0].ReadOnly || pod.Volumes[0].PersistentVolumeClaim.ClaimName != "agent-work" {
+		t.Fatalf("volumes = %+v %+v", c.VolumeMounts, pod.Volumes)
+	}
+}
+
+func TestAttachArgs(t *testing.T) {
+	job := &Job{Name: "orbitmesh-abc", config: &Config{Context: "prod", Namespace: "agents", StartTimeoutSeconds: 60}}
+	got := job.attachArgs(true)
+	want := []string{
+		"--context", "prod", "--namespace", "agents",
+		"attach", "job/orbitmesh-abc", "-c", "agent", "-i", "--quiet", "--pod-running-timeout=60s", "-t",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("attachArgs =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestLaunchStoresEnvInSecret(t *testing.T) {
+	// Stand in for kubectl: log each call and its stdin, and answer the
+	// Job's creation with its uid.
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	fake := filepath.Join(dir, "kubectl")
+	script := "#!/bin/sh\necho \"$*\" >> " + log + "\ncase \"$*\" in *'create -f -'*) cat >> " + log + "; echo >> " + log + ";; esac\ncase \"$*\" in *jsonpath*) printf uid-1;; esac\n"
+	if err := os.WriteFile(fake, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(old string) { KubectlCommand = old }(KubectlCommand)
+	KubectlCommand = fake
+
+	cfg := &Config{Namespace: "agents", Image: "agent:1"}
+	job, _, err := cfg.Launch(context.Background(), Spec{Command: "claude", Env: map[string]string{"API_KEY": "s3cret"}})
+	if err != nil {
+		t.Fatalf("Launch: %v", err)
+	}
+	if err := job.Delete(context.Background()); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	data, _ := os.ReadFile(log)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("kubectl calls:\n%s", data)
+	}
+	if !strings.Contains(lines[1], `"kind":"Secret"`) || !strings.Contains(lines[1], "s3cret") {
+		t.Fatalf("expected the Secret created first, got %s", lines[1])
+	}
+	if !strings.Contains(lines[3], `"kind":"Job"`) || strings.Contains(lines[3], "s3cret") {
+		t.Fatalf("expected a Job without the secret value, got %s", lines[3])
+	}
+	if !strings.Contains(lines[4], "patch secret "+job.Name) || !strings.Contains(lines[4], `"uid":"uid-1"`) {
+		t.Fatalf("expected the Secret owned by the Job, got %s", lines[4])
+	}
+	if !strings.Contains(lines[5], "delete job/"+job.Name+" secret/"+job.Name) {
+		t.Fatalf("expected Delete to remove the Secret, got %s", lines[5])
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"google.golang.org/adk/runner"
 	adksession "google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/geminitool"
 	"google.golang.org/adk/tool/mcptoolset"
 	"google.golang.org/genai"
 
@@ -173,6 +174,7 @@ func (p *ADKSession) start(config session.Config) error {
 		Description: "OrbitMesh managed agent",
 		Instruction: config.SystemPrompt,
 		Toolsets:    toolsets,
+		Tools:       adkFeatureTools(config),
 		AfterModelCallbacks: []llmagent.AfterModelCallback{
 			p.afterModelCallback,
 		},
@@ -214,7 +216,7 @@ func (p *ADKSession) start(config session.Config) error {
 
 	p.state.SetState(session.StateRunning)
 	// Provider is now running; we've already emitted idle->running at startup
-	p.events.Emit(domain.NewMetadataEvent(p.sessionID, "model", p.config.Model, nil))
+	p.events.Emit(domain.NewMetadataEvent(p.sessionID, domain.MetadataKeyModel, p.config.Model, nil))
 	p.started = true
 
 	return nil
@@ -234,6 +236,18 @@ func (p *ADKSession) createModel(apiKey string) (model.LLM, error) {
 	return gemini.NewModel(p.ctx, p.config.Model, clientCfg)
 }
 
+// adkFeatureTools returns the built-in Gemini tools the session's feature
+// flags turn on. Web search needs network tools, so allow_network_tools=false
+// keeps it off.
+func adkFeatureTools(config session.Config) []tool.Tool {
+	search, _ := config.Feature(session.FeatureWebSearch)
+	network, networkSet := config.Feature(session.FeatureNetworkTools)
+	if !search || (networkSet && !network) {
+		return nil
+	}
+	return []tool.Tool{geminitool.GoogleSearch{}}
+}
+
 func (p *ADKSession) setupMCPToolsets(config session.Config) ([]tool.Toolset, error) {
 	var toolsets []tool.Toolset
 
@@ -476,6 +490,11 @@ func (p *ADKSession) Status() session.Status {
 	return p.state.Status()
 }
 
+// Capabilities implements session.Session; follow-up input runs another prompt on the same runner.
+func (p *ADKSession) Capabilities() session.Capabilities {
+	return session.Capabilities{Suspend: true, Steering: true}
+}
+
 func (p *ADKSession) sanitizeError(err error, apiKey string) error {
 	if err == nil || apiKey == "" {
 		return err
@@ -6,16 +6,29 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"syscall"
+	"sync"
 	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
 )
 
 // Config holds configuration for starting a process.
 type Config struct {
-	Command     string
-	Args        []string
+	Command string
+	Args    []string
+	// WorkingDir may be an ssh:// URL, in which case the process runs on
+	// that host over SSH with only Environment set on top of the remote
+	// login environment.
 	WorkingDir  string
 	Environment map[string]string
+	// Forwards lists local host:port addresses a remote process must reach;
+	// they are tunnelled to the same address on the remote host.
+	Forwards []string
+	// Kubernetes, when set, runs the process as a Job in the cluster with
+	// only Environment set; WorkingDir is ignored in favour of the Job's
+	// own working directory.
+	Kubernetes *kube.Config
 }
 
 // Manager handles process lifecycle management with graceful shutdown.
@@ -24,6 +37,9 @@ type Manager struct {
 	stdin  io.WriteCloser
 	stdout io.ReadCloser
 	stderr io.ReadCloser
+	// release frees resources held outside the process, such as a
+	// Kubernetes Job.
+	release func()
 }
 
 // Start creates and starts a process with stdin/stdout/stderr pipes.
@@ -32,17 +48,55 @@ func Start(ctx context.Context, config Config) (*Manager, error) {
 		return nil, fmt.Errorf("command cannot be empty")
 	}
 
-	cmd := exec.CommandContext(ctx, config.Command, config.Args...)
-
-	// Set working directory
-	if config.WorkingDir != "" {
-		cmd.Dir = config.WorkingDir
-	}
-
-	// Set up environment
-	cmd.Env = os.Environ()
-	for k, v := range config.Environment {
-		cmd.Env = append(cmd.Env, k+"="+v)
+	var cmd *exec.Cmd
+	var release func()
+	if config.Kubernetes != nil {
+		if len(config.Forwards) > 0 {
+			return nil, fmt.Errorf("providers that need forwarded ports cannot run as Kubernetes jobs")
+		}
+		job, attach, err := config.Kubernetes.Launch(ctx, kube.Spec{
+			Command: config.Command,
+			Args:    config.Args,
+			Env:     config.Environment,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cmd = attach
+		cmd.Env = os.Environ()
+		release = sync.OnceFunc(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			_ = job.Delete(ctx)
+		})
+	} else if remote.IsRemote(config.WorkingDir) {
+		target, err := remote.Parse(config.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		cmd, err = target.Command(ctx, remote.Spec{
+			Command:  config.Command,
+			Args:     config.Args,
+			Env:      config.Environment,
+			Forwards: config.Forwards,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = os.Environ()
+	} else {
+		cmd = exec.CommandContext(ctx, config.Command, config.Args...)
+
+		// Set working directory
+		if config.WorkingDir != "" {
+			cmd.Dir = config.WorkingDir
+		}
+
+		// Set up environment
+		cmd.Env = os.Environ()
+		for k, v := range config.Environment {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
 	}
 
 	// Create pipes
@@ -69,14 +123,18 @@ func Start(ctx context.Context, config Config) (*Manager, error) {
 		_ = stdin.Close()
 		_ = stdout.Close()
 		_ = stderr.Close()
+		if release != nil {
+			release()
+		}
 		return nil, fmt.Errorf("failed to start process: %w", err)
 	}
 
 	return &Manager{
-		cmd:    cmd,
-		stdin:  stdin,
-		stdout: stdout,
-		stderr: stderr,
+		cmd:     cmd,
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+		release: release,
 	}, nil
 }
 
@@ -108,10 +166,15 @@ func (m *Manager) Wait() error {
 	if m.cmd == nil {
 		return nil
 	}
-	return m.cmd.Wait()
+	err := m.cmd.Wait()
+	if m.release != nil {
+		m.release()
+	}
+	return err
 }
 
-// Stop gracefully terminates the process with SIGTERM, then SIGKILL after timeout.
+// Stop closes stdin and asks the process to exit (SIGTERM on Unix), then
+// kills it if it is still running after timeout.
 func (m *Manager) Stop(timeout time.Duration) error {
 	if m.cmd == nil || m.cmd.Process == nil {
 		return nil
@@ -123,8 +186,8 @@ func (m *Manager) Stop(timeout time.Duration) error {
 		m.stdin = nil
 	}
 
-	// Send SIGTERM for graceful shutdown
-	if err := m.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+	// Ask for a graceful shutdown
+	if err := Terminate(m.cmd.Process); err != nil {
 		// Process might already be dead
 		return nil
 	}
@@ -150,7 +213,7 @@ func (m *Manager) Stop(timeout time.Duration) error {
 	return nil
 }
 
-// Kill immediately terminates the process with SIGKILL.
+// Kill immediately terminates the process.
 func (m *Manager) Kill() error {
 	if m.cmd == nil || m.cmd.Process == nil {
 		return nil
@@ -161,8 +224,11 @@ func (m *Manager) Kill() error {
 	return err
 }
 
-// cleanup closes all pipes.
+// cleanup closes all pipes and releases resources held outside the process.
 func (m *Manager) cleanup() {
+	if m.release != nil {
+		m.release()
+	}
 	if m.stdin != nil {
 		_ = m.stdin.Close()
 		m.stdin = nil
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package process
+
+import (
+	"os"
+	"syscall"
+)
+
+// Terminate asks p to exit gracefully with SIGTERM.
+func Terminate(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package process
+
+import "os"
+
+// Terminate asks p to exit gracefully. Windows has no SIGTERM, and console
+// control events cannot target a single child, so nothing is sent: callers
+// close the child's stdin or pseudo console, wait, and then fall back to
+// Kill.
+func Terminate(p *os.Process) error {
+	return nil
+}
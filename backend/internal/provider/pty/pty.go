@@ -4,16 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/provider/circuit"
+	"github.com/ricochet1k/orbitmesh/internal/provider/kube"
 	"github.com/ricochet1k/orbitmesh/internal/provider/native"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/terminal"
 	"github.com/ricochet1k/termemu"
@@ -33,8 +35,9 @@ type PTYProvider struct {
 	events    *native.EventAdapter
 	config    session.Config
 
-	cmd                 *exec.Cmd
-	backend             *termemu.PTYBackend
+	process             *os.Process
+	backend             termemu.Backend
+	job                 *kube.Job
 	teeBackend          *termemu.TeeBackend
 	terminal            termemu.Terminal
 	outputLog           syncCloser
@@ -110,17 +113,48 @@ func (p *PTYProvider) start(config session.Config) error {
 		// PTY provider might not support MCP servers directly in this phase
 	}
 
-	cmd := exec.Command(command, args...)
-	if config.WorkingDir != "" {
-		cmd.Dir = config.WorkingDir
+	kubeConfig, err := kube.FromCustom(config.Custom)
+	if err != nil {
+		return err
 	}
-	cmd.Env = os.Environ()
-	for k, v := range config.Environment {
-		cmd.Env = append(cmd.Env, k+"="+v)
+
+	var cmd *exec.Cmd
+	if kubeConfig != nil {
+		// The local pty runs kubectl attach, which bridges it to the pod's tty.
+		job, attach, err := kubeConfig.Launch(context.Background(), kube.Spec{Command: command, Args: args, Env: config.Environment, TTY: true})
+		if err != nil {
+			p.handleFailure(err)
+			return err
+		}
+		p.job = job
+		cmd = attach
+		cmd.Env = os.Environ()
+	} else if remote.IsRemote(config.WorkingDir) {
+		// The local pty runs ssh, which bridges it to a remote pty.
+		target, err := remote.Parse(config.WorkingDir)
+		if err != nil {
+			return err
+		}
+		cmd, err = target.Command(context.Background(), remote.Spec{Command: command, Args: args, Env: config.Environment, TTY: true})
+		if err != nil {
+			p.handleFailure(err)
+			return err
+		}
+		cmd.Env = os.Environ()
+	} else {
+		cmd = exec.Command(command, args...)
+		if config.WorkingDir != "" {
+			cmd.Dir = config.WorkingDir
+		}
+		cmd.Env = os.Environ()
+		for k, v := range config.Environment {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
 	}
 
-	backend := &termemu.PTYBackend{}
-	if err := backend.StartCommand(cmd); err != nil {
+	backend, proc, err := startPTY(cmd)
+	if err != nil {
+		p.deleteJob()
 		p.handleFailure(err)
 		return err
 	}
@@ -145,7 +179,7 @@ func (p *PTYProvider) start(config session.Config) error {
 		return err
 	}
 
-	p.cmd = cmd
+	p.process = proc
 	p.backend = backend
 	p.teeBackend = teeBackend
 	p.terminal = terminal
@@ -170,12 +204,29 @@ func (p *PTYProvider) start(config session.Config) error {
 // waitForExit waits for the PTY command to terminate and closes the event channel.
 func (p *PTYProvider) waitForExit() {
 	defer p.wg.Done()
-	if p.cmd != nil {
-		_ = p.cmd.Wait()
+	if p.process != nil {
+		_, _ = p.process.Wait()
 	}
+	// A ConPTY's output only reaches EOF once the console is closed.
+	if closer, ok := p.backend.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	p.deleteJob()
 	p.events.Close()
 }
 
+// deleteJob removes the Kubernetes Job backing the terminal, if any.
+func (p *PTYProvider) deleteJob() {
+	if p.job == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := p.job.Delete(ctx); err != nil {
+		p.events.Emit(domain.NewMetadataEvent(p.sessionID, "kubernetes_warning", map[string]any{"error": err.Error()}, nil))
+	}
+}
+
 func resolvePTYCommand(config session.Config) (string, []string, error) {
 	command := "claude"
 	var args []string
@@ -351,8 +402,8 @@ func (p *PTYProvider) Stop(ctx context.Context) error {
 	p.state.SetState(session.StateStopping)
 	p.cancel()
 
-	if p.cmd != nil && p.cmd.Process != nil {
-		_ = p.cmd.Process.Signal(syscall.SIGTERM)
+	if p.process != nil {
+		hangUp(p.process, p.backend)
 	}
 	if p.outputLog != nil {
 		_ = p.outputLog.Sync()
@@ -385,8 +436,8 @@ func (p *PTYProvider) Kill() error {
 	defer p.mu.Unlock()
 
 	p.cancel()
-	if p.cmd != nil && p.cmd.Process != nil {
-		_ = p.cmd.Process.Kill()
+	if p.process != nil {
+		_ = p.process.Kill()
 	}
 	if p.outputLog != nil {
 		_ = p.outputLog.Sync()
@@ -413,6 +464,11 @@ func (p *PTYProvider) Status() session.Status {
 	return p.state.Status()
 }
 
+// Capabilities implements session.Session; input is typed into the terminal while the agent runs.
+func (p *PTYProvider) Capabilities() session.Capabilities {
+	return session.Capabilities{Suspend: true, Steering: true, Terminal: true}
+}
+
 func (p *PTYProvider) TerminalSnapshot() (terminal.Snapshot, error) {
 	p.mu.RLock()
 	term := p.terminal
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/ricochet1k/orbitmesh/internal/provider/process"
+	"github.com/ricochet1k/termemu"
+)
+
+// startPTY starts cmd attached to a new pseudo-terminal.
+func startPTY(cmd *exec.Cmd) (termemu.Backend, *os.Process, error) {
+	backend := &termemu.PTYBackend{}
+	if err := backend.StartCommand(cmd); err != nil {
+		return nil, nil, err
+	}
+	return backend, cmd.Process, nil
+}
+
+// hangUp asks the process on the terminal to exit without waiting for it.
+func hangUp(proc *os.Process, _ termemu.Backend) {
+	_ = process.Terminate(proc)
+}
@@ -0,0 +1,177 @@
+//go:build windows
+
+package pty
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/ricochet1k/termemu"
+	"golang.org/x/sys/windows"
+)
+
+// conPTY is a termemu backend over a Windows pseudo console.
+type conPTY struct {
+	console windows.Handle
+	in      *os.File // writes reach the console's input
+	out     *os.File // reads come from the console's output
+
+	closeOnce sync.Once
+}
+
+func (c *conPTY) Read(b []byte) (int, error)  { return c.out.Read(b) }
+func (c *conPTY) Write(b []byte) (int, error) { return c.in.Write(b) }
+
+func (c *conPTY) SetSize(w, h int) error {
+	return windows.ResizePseudoConsole(c.console, windows.Coord{X: int16(w), Y: int16(h)})
+}
+
+// Close closes the pseudo console, which ends its processes and lets
+// pending reads finish with EOF.
+func (c *conPTY) Close() error {
+	c.closeOnce.Do(func() {
+		windows.ClosePseudoConsole(c.console)
+		_ = c.in.Close()
+		_ = c.out.Close()
+	})
+	return nil
+}
+
+// startPTY starts cmd attached to a new ConPTY pseudo console. os/exec
+// cannot attach a pseudo console, so the process is created directly from
+// cmd's path, arguments, environment and directory.
+func startPTY(cmd *exec.Cmd) (termemu.Backend, *os.Process, error) {
+	if cmd.Err != nil {
+		return nil, nil, cmd.Err
+	}
+	inRead, inWrite, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	outRead, outWrite, err := os.Pipe()
+	if err != nil {
+		_ = inRead.Close()
+		_ = inWrite.Close()
+		return nil, nil, err
+	}
+
+	var console windows.Handle
+	err = windows.CreatePseudoConsole(windows.Coord{X: 80, Y: 24}, windows.Handle(inRead.Fd()), windows.Handle(outWrite.Fd()), 0, &console)
+	if err == nil {
+		var proc *os.Process
+		proc, err = startInConsole(cmd, console)
+		// The console holds its own copies of these ends.
+		_ = inRead.Close()
+		_ = outWrite.Close()
+		if err == nil {
+			return &conPTY{console: console, in: inWrite, out: outRead}, proc, nil
+		}
+		windows.ClosePseudoConsole(console)
+	} else {
+		_ = inRead.Close()
+		_ = outWrite.Close()
+	}
+	_ = inWrite.Close()
+	_ = outRead.Close()
+	return nil, nil, err
+}
+
+func startInConsole(cmd *exec.Cmd, console windows.Handle) (*os.Process, error) {
+	attrs, err := windows.NewProcThreadAttributeList(1)
+	if err != nil {
+		return nil, err
+	}
+	defer attrs.Delete()
+	// The attribute value is the console handle itself, not a pointer to it.
+	if err := attrs.Update(windows.PROC_THREAD_ATTRIBUTE_PSEUDOCONSOLE, *(*unsafe.Pointer)(unsafe.Pointer(&console)), unsafe.Sizeof(console)); err != nil {
+		return nil, err
+	}
+
+	appName, err := windows.UTF16PtrFromString(cmd.Path)
+	if err != nil {
+		return nil, err
+	}
+	cmdLine, err := windows.UTF16PtrFromString(windows.ComposeCommandLine(cmd.Args))
+	if err != nil {
+		return nil, err
+	}
+	var dir *uint16
+	if cmd.Dir != "" {
+		if dir, err = windows.UTF16PtrFromString(cmd.Dir); err != nil {
+			return nil, err
+		}
+	}
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	envBlock, err := environmentBlock(env)
+	if err != nil {
+		return nil, err
+	}
+
+	si := &windows.StartupInfoEx{ProcThreadAttributeList: attrs.List()}
+	si.Cb = uint32(unsafe.Sizeof(*si))
+	// Empty standard handles keep the child from writing to ours instead of
+	// the console when the server's own output is redirected.
+	si.Flags = windows.STARTF_USESTDHANDLES
+
+	var pi windows.ProcessInformation
+	flags := uint32(windows.EXTENDED_STARTUPINFO_PRESENT | windows.CREATE_UNICODE_ENVIRONMENT)
+	if err := windows.CreateProcess(appName, cmdLine, nil, nil, false, flags, envBlock, dir, &si.StartupInfo, &pi); err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(pi.Thread)
+	defer windows.CloseHandle(pi.Process)
+	return os.FindProcess(int(pi.ProcessId))
+}
+
+// environmentBlock encodes env as a CreateProcess environment block. Later
+// entries win over earlier ones with the same (case-insensitive) name, as
+// with os/exec.
+func environmentBlock(env []string) (*uint16, error) {
+	seen := make(map[string]bool, len(env))
+	kept := make([]string, 0, len(env))
+	for i := len(env) - 1; i >= 0; i-- {
+		kv := env[i]
+		if kv == "" {
+			continue
+		}
+		if strings.IndexByte(kv, 0) >= 0 {
+			return nil, errors.New("environment variable contains NUL")
+		}
+		// Skip the first byte: hidden per-drive variables like "=C:" start
+		// with "=".
+		rest, _, _ := strings.Cut(kv[1:], "=")
+		name := strings.ToUpper(kv[:1] + rest)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		kept = append(kept, kv)
+	}
+
+	var block []uint16
+	for i := len(kept) - 1; i >= 0; i-- {
+		block = append(block, utf16.Encode([]rune(kept[i]))...)
+		block = append(block, 0)
+	}
+	if len(block) == 0 {
+		block = append(block, 0)
+	}
+	block = append(block, 0)
+	return &block[0], nil
+}
+
+// hangUp closes the pseudo console, which sends its processes
+// CTRL_CLOSE_EVENT, the console equivalent of a hangup.
+func hangUp(_ *os.Process, backend termemu.Backend) {
+	if c, ok := backend.(*conPTY); ok {
+		_ = c.Close()
+	}
+}
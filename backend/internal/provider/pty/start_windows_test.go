@@ -0,0 +1,62 @@
+//go:build windows
+
+package pty
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestWindowsEnvironmentBlock_LastEntryWins(t *testing.T) {
+	block, err := environmentBlock([]string{"Path=a", "FOO=1", "PATH=b", "=C:=C:\\"})
+	if err != nil {
+		t.Fatalf("environmentBlock: %v", err)
+	}
+	var entries []string
+	var cur []rune
+	for p := block; ; p = (*uint16)(unsafe.Add(unsafe.Pointer(p), 2)) {
+		if *p == 0 {
+			if len(cur) == 0 {
+				break
+			}
+			entries = append(entries, string(cur))
+			cur = cur[:0]
+			continue
+		}
+		cur = append(cur, rune(*p))
+	}
+	if got := strings.Join(entries, ";"); got != "FOO=1;PATH=b;=C:=C:\\" {
+		t.Fatalf("block = %q", got)
+	}
+}
+
+func TestWindowsStartPTY_RunsCommandInConsole(t *testing.T) {
+	backend, proc, err := startPTY(exec.Command("cmd", "/C", "echo hello-conpty"))
+	if err != nil {
+		t.Fatalf("startPTY: %v", err)
+	}
+	if _, err := proc.Wait(); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(&out, backend)
+		close(done)
+	}()
+	hangUp(proc, backend)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("console output did not reach EOF")
+	}
+	if !strings.Contains(out.String(), "hello-conpty") {
+		t.Fatalf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,256 @@
+// Package remote runs provider subprocesses on another machine over SSH,
+// for sessions whose working directory is an ssh:// URL such as
+// ssh://user@build-box:2222/home/user/project.
+//
+// Commands are started with the local ssh client, so hosts must be reachable
+// with the user's SSH configuration and keys; password prompts are disabled
+// except for interactive terminals. The remote login shell must be
+// POSIX-compatible.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Scheme prefixes remote working directories.
+const Scheme = "ssh://"
+
+// SSHCommand is the ssh client binary.
+var SSHCommand = "ssh"
+
+// ErrInvalidTarget is returned for malformed ssh:// working directories.
+var ErrInvalidTarget = errors.New("invalid ssh working directory")
+
+// Target is a directory on a host reached over SSH.
+type Target struct {
+	User string
+	Host string
+	Port string
+	// Dir is the remote directory. A leading "~" is the remote user's home;
+	// empty means the home directory itself.
+	Dir string
+}
+
+// IsRemote reports whether workingDir names a remote directory.
+func IsRemote(workingDir string) bool {
+	return strings.HasPrefix(workingDir, Scheme)
+}
+
+// Parse parses an ssh://[user@]host[:port][/path] working directory. Paths
+// under the remote home are written ssh://host/~/project.
+func Parse(workingDir string) (Target, error) {
+	if !IsRemote(workingDir) {
+		return Target{}, fmt.Errorf("%w: %q does not start with %s", ErrInvalidTarget, workingDir, Scheme)
+	}
+	u, err := url.Parse(workingDir)
+	if err != nil {
+		return Target{}, fmt.Errorf("%w: %v", ErrInvalidTarget, err)
+	}
+	if u.Hostname() == "" || u.RawQuery != "" || u.Fragment != "" {
+		return Target{}, fmt.Errorf("%w: %q", ErrInvalidTarget, workingDir)
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return Target{}, fmt.Errorf("%w: passwords are not supported, use SSH keys", ErrInvalidTarget)
+	}
+
+	dir := u.Path
+	if dir == "/~" || strings.HasPrefix(dir, "/~/") {
+		dir = dir[1:]
+	}
+	if dir == "/" {
+		dir = ""
+	}
+	return Target{User: u.User.Username(), Host: u.Hostname(), Port: u.Port(), Dir: dir}, nil
+}
+
+// String returns the target as an ssh:// working directory.
+func (t Target) String() string {
+	host := t.Host
+	if t.Port != "" {
+		host = net.JoinHostPort(t.Host, t.Port)
+	}
+	if t.User != "" {
+		host = t.User + "@" + host
+	}
+	dir := t.Dir
+	if dir != "" && !strings.HasPrefix(dir, "/") {
+		dir = "/" + dir
+	}
+	return Scheme + host + dir
+}
+
+// Spec describes a command to run on a target.
+type Spec struct {
+	Command string
+	Args    []string
+	// Env is set for the command on top of the remote login environment.
+	// The local environment is not forwarded, and Env is never put on a
+	// command line.
+	Env map[string]string
+	// TTY allocates a remote pseudo-terminal; start the ssh command on a
+	// local pty so the terminal hub sees the remote screen.
+	TTY bool
+	// Forwards lists local host:port addresses the command connects to.
+	// Each is tunnelled so the same address works on the remote.
+	Forwards []string
+}
+
+// Command returns the ssh invocation that runs spec in t.Dir. spec.Env is
+// first copied to a 0600 temporary file on the target, which the command
+// sources and deletes, so values never appear on a command line where any
+// user of either host could read them.
+func (t Target) Command(ctx context.Context, spec Spec) (*exec.Cmd, error) {
+	envFile := ""
+	if len(spec.Env) > 0 {
+		var err error
+		if envFile, err = t.sendEnv(ctx, spec.Env); err != nil {
+			return nil, err
+		}
+	}
+	return exec.CommandContext(ctx, SSHCommand, t.sshArgs(spec, envFile)...), nil
+}
+
+// sendEnv writes env as a shell script to a new 0600 temporary file on the
+// target, over ssh's stdin, and returns the file's path.
+func (t Target) sendEnv(ctx context.Context, env map[string]string) (string, error) {
+	host := Target{User: t.User, Host: t.Host, Port: t.Port}
+	cmd := exec.CommandContext(ctx, SSHCommand, host.sshArgs(Spec{
+		Command: "sh",
+		Args:    []string{"-c", `umask 077 && f=$(mktemp) && cat > "$f" && printf %s "$f"`},
+	}, "")...)
+	cmd.Stdin = strings.NewReader(envScript(env))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("send environment to %s: %w: %s", t.Host, err, strings.TrimSpace(stderr.String()))
+	}
+	path := strings.TrimSpace(string(out))
+	if path == "" {
+		return "", fmt.Errorf("send environment to %s: mktemp printed no path", t.Host)
+	}
+	return path, nil
+}
+
+// envScript exports env, in key order.
+func envScript(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString("export ")
+		b.WriteString(Quote(k + "=" + env[k]))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func (t Target) sshArgs(spec Spec, envFile string) []string {
+	var args []string
+	if spec.TTY {
+		args = append(args, "-tt")
+	} else {
+		args = append(args, "-T", "-o", "BatchMode=yes")
+	}
+	if t.Port != "" {
+		args = append(args, "-p", t.Port)
+	}
+	for _, addr := range spec.Forwards {
+		if _, port, err := net.SplitHostPort(addr); err == nil {
+			args = append(args, "-R", port+":"+addr)
+		}
+	}
+	dest := t.Host
+	if t.User != "" {
+		dest = t.User + "@" + dest
+	}
+	return append(args, "--", dest, t.script(spec, envFile))
+}
+
+// script is the remote shell command line: source and delete envFile, if
+// any, change to the target directory, then exec the command.
+func (t Target) script(spec Spec, envFile string) string {
+	var b strings.Builder
+	if envFile != "" {
+		f := Quote(envFile)
+		b.WriteString(". " + f + "; rm -f -- " + f + "; ")
+	}
+	if t.Dir != "" {
+		b.WriteString("cd ")
+		b.WriteString(quoteDir(t.Dir))
+		b.WriteString(" && ")
+	}
+	b.WriteString("exec ")
+	b.WriteString(Quote(spec.Command))
+	for _, arg := range spec.Args {
+		b.WriteByte(' ')
+		b.WriteString(Quote(arg))
+	}
+	return b.String()
+}
+
+// Quote quotes s for a POSIX shell.
+func Quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-./=:,+@%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteDir quotes dir, leaving a leading "~" unquoted so the remote shell
+// expands it.
+func quoteDir(dir string) string {
+	if dir == "~" {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		return "~/" + Quote(rest)
+	}
+	return Quote(dir)
+}
+
+// ReadFile reads a file on the target; relative names are resolved against
+// t.Dir.
+func (t Target) ReadFile(ctx context.Context, name string) ([]byte, error) {
+	cmd, err := t.Command(ctx, Spec{Command: "cat", Args: []string{"--", name}})
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("read %s on %s: %w: %s", name, t.Host, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// WriteFile writes data to a file on the target, creating its parent
+// directories; relative names are resolved against t.Dir.
+func (t Target) WriteFile(ctx context.Context, name string, data []byte) error {
+	cmd, err := t.Command(ctx, Spec{
+		Command: "sh",
+		Args:    []string{"-c", `mkdir -p -- "$(dirname -- "$1")" && cat > "$1"`, "sh", name},
+	})
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("write %s on %s: %w: %s", name, t.Host, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want Target
+	}{
+		{"ssh://build/srv/app", Target{Host: "build", Dir: "/srv/app"}},
+		{"ssh://me@build:2222/srv/app", Target{User: "me", Host: "build", Port: "2222", Dir: "/srv/app"}},
+		{"ssh://me@build/~/src/app", Target{User: "me", Host: "build", Dir: "~/src/app"}},
+		{"ssh://build", Target{Host: "build"}},
+	} {
+		got, err := Parse(tc.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.in, got, tc.want)
+		}
+		if got.String() != tc.in {
+			t.Errorf("String() = %q, want %q", got.String(), tc.in)
+		}
+	}
+
+	for _, bad := range []string{"/local/dir", "ssh:///no-host", "ssh://me:pw@build/x", "ssh://build/x?y=1"} {
+		if _, err := Parse(bad); !errors.Is(err, ErrInvalidTarget) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidTarget", bad, err)
+		}
+	}
+}
+
+func TestTargetSSHArgs(t *testing.T) {
+	target := Target{User: "me", Host: "build", Port: "2222", Dir: "~/my app"}
+
+	got := target.sshArgs(Spec{
+		Command:  "claude",
+		Args:     []string{"-p", "it's"},
+		Env:      map[string]string{"B": "2", "A": "x y"},
+		Forwards: []string{"127.0.0.1:4100"},
+	}, "/tmp/tmp.abc")
+	want := []string{
+		"-T", "-o", "BatchMode=yes", "-p", "2222", "-R", "4100:127.0.0.1:4100", "--", "me@build",
+		`. /tmp/tmp.abc; rm -f -- /tmp/tmp.abc; cd ~/'my app' && exec claude -p 'it'\''s'`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sshArgs =\n%q\nwant\n%q", got, want)
+	}
+
+	got = Target{Host: "build"}.sshArgs(Spec{Command: "bash", TTY: true}, "")
+	want = []string{"-tt", "--", "build", "exec bash"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("tty sshArgs = %q, want %q", got, want)
+	}
+}
+
+func TestTargetCommandKeepsEnvOffTheCommandLine(t *testing.T) {
+	// Stand in for ssh: run the remote command line locally.
+	bin := t.TempDir()
+	fakeSSH := filepath.Join(bin, "ssh")
+	if err := os.WriteFile(fakeSSH, []byte("#!/bin/sh\nfor last; do :; done\nexec sh -c \"$last\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(old string) { SSHCommand = old }(SSHCommand)
+	SSHCommand = fakeSSH
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	env := map[string]string{"SECRET": "it's a secret"}
+	cmd, err := Target{Host: "build", Dir: t.TempDir()}.Command(context.Background(), Spec{
+		Command: "sh",
+		Args:    []string{"-c", `printf %s "$SECRET"`},
+		Env:     env,
+	})
+	if err != nil {
+		t.Fatalf("Command: %v", err)
+	}
+	if strings.Contains(strings.Join(cmd.Args, " "), "secret") {
+		t.Fatalf("environment on the command line: %q", cmd.Args)
+	}
+	out, err := cmd.Output()
+	if err != nil || string(out) != env["SECRET"] {
+		t.Fatalf("output = %q, %v", out, err)
+	}
+	if left, _ := os.ReadDir(tmp); len(left) != 0 {
+		t.Fatalf("environment file left behind: %v", left)
+	}
+}
@@ -15,15 +15,22 @@ type Client struct {
 	send   chan realtimeTypes.ServerEnvelope
 	mu     sync.RWMutex
 	topics map[string]struct{}
-	close  sync.Once
+	// filters restricts event delivery on a topic to the listed event
+	// types; topics without an entry receive every event.
+	filters map[string]map[string]struct{}
+	// profiles holds the visibility profile name chosen per topic.
+	profiles map[string]string
+	close    sync.Once
 }
 
 func NewClient(id string, conn *websocket.Conn) *Client {
 	return &Client{
-		id:     id,
-		conn:   conn,
-		send:   make(chan realtimeTypes.ServerEnvelope, outboundBufferSize),
-		topics: make(map[string]struct{}),
+		id:       id,
+		conn:     conn,
+		send:     make(chan realtimeTypes.ServerEnvelope, outboundBufferSize),
+		topics:   make(map[string]struct{}),
+		filters:  make(map[string]map[string]struct{}),
+		profiles: make(map[string]string),
 	}
 }
 
@@ -56,10 +63,29 @@ func (c *Client) Close() {
 }
 
 func (c *Client) Subscribe(topics []string) {
+	c.SubscribeFiltered(topics, nil)
+}
+
+// SubscribeFiltered subscribes to topics, delivering only events whose type
+// is in eventTypes. An empty eventTypes clears any earlier filter.
+func (c *Client) SubscribeFiltered(topics []string, eventTypes []string) {
+	var filter map[string]struct{}
+	if len(eventTypes) > 0 {
+		filter = make(map[string]struct{}, len(eventTypes))
+		for _, eventType := range eventTypes {
+			filter[eventType] = struct{}{}
+		}
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for _, topic := range topics {
 		c.topics[topic] = struct{}{}
+		if filter != nil {
+			c.filters[topic] = filter
+		} else {
+			delete(c.filters, topic)
+		}
 	}
 }
 
@@ -68,12 +94,51 @@ func (c *Client) Unsubscribe(topics []string) {
 	defer c.mu.Unlock()
 	for _, topic := range topics {
 		delete(c.topics, topic)
+		delete(c.filters, topic)
+		delete(c.profiles, topic)
+	}
+}
+
+// SetProfile sets the visibility profile for topics. An empty profile
+// clears it.
+func (c *Client) SetProfile(topics []string, profile string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, topic := range topics {
+		if profile != "" {
+			c.profiles[topic] = profile
+		} else {
+			delete(c.profiles, topic)
+		}
 	}
 }
 
+// Profile returns the visibility profile for topic, or "".
+func (c *Client) Profile(topic string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.profiles[topic]
+}
+
 func (c *Client) IsSubscribed(topic string) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	_, ok := c.topics[topic]
 	return ok
 }
+
+// Accepts reports whether an event of eventType on topic should be delivered
+// to the client. Messages without an event type pass any filter.
+func (c *Client) Accepts(topic, eventType string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := c.topics[topic]; !ok {
+		return false
+	}
+	filter, ok := c.filters[topic]
+	if !ok || eventType == "" {
+		return true
+	}
+	_, ok = filter[eventType]
+	return ok
+}
@@ -35,6 +35,19 @@ func (h *Hub) Unregister(clientID string) {
 }
 
 func (h *Hub) Publish(topic string, msg realtimeTypes.ServerEnvelope) {
+	h.PublishEvent(topic, "", msg)
+}
+
+// PublishEvent publishes msg to clients subscribed to topic whose event type
+// filter admits eventType.
+func (h *Hub) PublishEvent(topic, eventType string, msg realtimeTypes.ServerEnvelope) {
+	h.PublishVisible(topic, eventType, nil, msg)
+}
+
+// PublishVisible is PublishEvent that also skips clients whose visibility
+// profile for topic is not admitted by visible. A nil visible admits every
+// profile.
+func (h *Hub) PublishVisible(topic, eventType string, visible func(profile string) bool, msg realtimeTypes.ServerEnvelope) {
 	h.mu.RLock()
 	clients := make([]*Client, 0, len(h.clients))
 	for _, client := range h.clients {
@@ -43,7 +56,10 @@ func (h *Hub) Publish(topic string, msg realtimeTypes.ServerEnvelope) {
 	h.mu.RUnlock()
 
 	for _, client := range clients {
-		if !client.IsSubscribed(topic) {
+		if !client.Accepts(topic, eventType) {
+			continue
+		}
+		if visible != nil && !visible(client.Profile(topic)) {
 			continue
 		}
 		if client.Queue(msg) {
@@ -54,13 +70,31 @@ func (h *Hub) Publish(topic string, msg realtimeTypes.ServerEnvelope) {
 }
 
 func (h *Hub) Subscribe(clientID string, topics []string) bool {
+	return h.SubscribeFiltered(clientID, topics, nil)
+}
+
+// SubscribeFiltered subscribes a client to topics, restricted to eventTypes
+// when non-empty.
+func (h *Hub) SubscribeFiltered(clientID string, topics, eventTypes []string) bool {
+	h.mu.RLock()
+	client, ok := h.clients[clientID]
+	h.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	client.SubscribeFiltered(topics, eventTypes)
+	return true
+}
+
+// SetProfile sets a client's visibility profile for topics.
+func (h *Hub) SetProfile(clientID string, topics []string, profile string) bool {
 	h.mu.RLock()
 	client, ok := h.clients[clientID]
 	h.mu.RUnlock()
 	if !ok {
 		return false
 	}
-	client.Subscribe(topics)
+	client.SetProfile(topics, profile)
 	return true
 }
 
@@ -0,0 +1,89 @@
+package realtime
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	realtimeTypes "github.com/ricochet1k/orbitmesh/pkg/realtime"
+)
+
+// ApprovalNotification builds the notification for a session that is
+// suspended waiting on a human: a question notification when its agent asked
+// one with ask_human, otherwise an approval request. The dedupe key is tied
+// to the suspension transition so repeated snapshots of the same wait
+// collapse into one alert.
+func ApprovalNotification(snap domain.SessionSnapshot) (realtimeTypes.Notification, bool) {
+	if snap.State != domain.SessionStateSuspended {
+		return realtimeTypes.Notification{}, false
+	}
+
+	suspendedAt := snap.UpdatedAt
+	reason := ""
+	for i := len(snap.Transitions) - 1; i >= 0; i-- {
+		if snap.Transitions[i].To == domain.SessionStateSuspended {
+			suspendedAt = snap.Transitions[i].Timestamp
+			reason = snap.Transitions[i].Reason
+			break
+		}
+	}
+	// Runs waiting on a remote batch queue need no human attention.
+	if strings.HasPrefix(reason, domain.WaitKindQueuedRemote) {
+		return realtimeTypes.Notification{}, false
+	}
+
+	if question, ok := strings.CutPrefix(reason, domain.WaitKindWaitingOnHuman+": "); ok {
+		return realtimeTypes.Notification{
+			DedupeKey: "question:" + snap.ID + ":" + strconv.FormatInt(suspendedAt.UnixNano(), 36),
+			Kind:      realtimeTypes.NotificationKindQuestion,
+			SessionID: snap.ID,
+			Title:     fmt.Sprintf("%s asked a question", sessionLabel(snap)),
+			Body:      question,
+			Timestamp: suspendedAt,
+		}, true
+	}
+
+	title, body := fmt.Sprintf("%s is waiting for input", sessionLabel(snap)), reason
+	if command, ok := strings.CutPrefix(reason, domain.WaitKindCommandApproval+": "); ok {
+		title, body = fmt.Sprintf("%s wants to run a command", sessionLabel(snap)), command
+	}
+	if tool, ok := strings.CutPrefix(reason, domain.WaitKindToolApproval+": "); ok {
+		title, body = fmt.Sprintf("%s wants to use a tool", sessionLabel(snap)), tool
+	}
+	return realtimeTypes.Notification{
+		DedupeKey: "approval:" + snap.ID + ":" + strconv.FormatInt(suspendedAt.UnixNano(), 36),
+		Kind:      realtimeTypes.NotificationKindApproval,
+		SessionID: snap.ID,
+		Title:     title,
+		Body:      body,
+		Timestamp: suspendedAt,
+	}, true
+}
+
+// FailureNotification builds the notification for an error event.
+func FailureNotification(snap domain.SessionSnapshot, event domain.Event) (realtimeTypes.Notification, bool) {
+	data, ok := event.Data.(domain.ErrorData)
+	if !ok {
+		return realtimeTypes.Notification{}, false
+	}
+
+	return realtimeTypes.Notification{
+		DedupeKey: "failure:" + event.SessionID + ":" + strconv.FormatInt(event.ID, 10),
+		Kind:      realtimeTypes.NotificationKindFailure,
+		SessionID: event.SessionID,
+		Title:     fmt.Sprintf("%s failed", sessionLabel(snap)),
+		Body:      data.Message,
+		Timestamp: event.Timestamp,
+	}, true
+}
+
+func sessionLabel(snap domain.SessionSnapshot) string {
+	if snap.Title != "" {
+		return snap.Title
+	}
+	if snap.ID != "" {
+		return "Session " + snap.ID
+	}
+	return "Session"
+}
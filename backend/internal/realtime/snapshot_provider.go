@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/ricochet1k/orbitmesh/internal/presentation"
 	"github.com/ricochet1k/orbitmesh/internal/provider/pty"
@@ -33,6 +34,15 @@ func (p *SnapshotProvider) Snapshot(topic string) (any, error) {
 		return p.sessionsStateSnapshot(), nil
 	case TopicTerminalsState:
 		return p.terminalsStateSnapshot(), nil
+	case TopicNotifications:
+		return p.notificationsSnapshot(), nil
+	case TopicSystemRecovery:
+		return realtimeTypes.RecoverySnapshot{Report: presentation.RecoveryReport(p.executor.RecoveryReport())}, nil
+	case TopicSessionsSync:
+		return realtimeTypes.SessionsSyncSnapshot{Revision: p.executor.SessionRevision()}, nil
+	case TopicWaits:
+		now := time.Now()
+		return presentation.Waits(p.executor.PendingWaits(now), now), nil
 	default:
 		if sessionID, ok := SessionIDFromActivityTopic(topic); ok {
 			return p.sessionsActivitySnapshot(sessionID)
@@ -57,6 +67,22 @@ func (p *SnapshotProvider) sessionsStateSnapshot() realtimeTypes.SessionsStateSn
 	return realtimeTypes.SessionsStateSnapshot{Sessions: out}
 }
 
+// notificationsSnapshot reports sessions that are currently waiting on a
+// human so a freshly connected client can catch up on pending approvals.
+func (p *SnapshotProvider) notificationsSnapshot() realtimeTypes.NotificationsSnapshot {
+	out := make([]realtimeTypes.Notification, 0)
+	for _, s := range p.executor.ListSessions() {
+		snap := s.Snapshot()
+		if derived, err := p.executor.DeriveSessionState(s.ID); err == nil {
+			snap.State = derived
+		}
+		if n, ok := ApprovalNotification(snap); ok {
+			out = append(out, n)
+		}
+	}
+	return realtimeTypes.NotificationsSnapshot{Notifications: out}
+}
+
 func (p *SnapshotProvider) sessionsActivitySnapshot(sessionID string) (realtimeTypes.SessionActivitySnapshot, error) {
 	if _, err := p.executor.GetSession(sessionID); err != nil {
 		return realtimeTypes.SessionActivitySnapshot{}, err
@@ -83,6 +109,7 @@ func (p *SnapshotProvider) sessionsActivitySnapshot(sessionID string) (realtimeT
 				Kind:      string(msg.Kind),
 				Contents:  msg.Contents,
 				Timestamp: msg.Timestamp,
+				Notice:    presentation.Notice(msg.Notice),
 			}
 		}
 	}
@@ -2,6 +2,10 @@ package realtime
 
 const TopicSessionsState = "sessions.state"
 const TopicTerminalsState = "terminals.state"
+const TopicNotifications = "notifications"
+const TopicSystemRecovery = "system.recovery"
+const TopicSessionsSync = "sessions.sync"
+const TopicWaits = "waits"
 
 const sessionsActivityPrefix = "sessions.activity:"
 const terminalsOutputPrefix = "terminals.output:"
@@ -12,6 +16,14 @@ func IsSupportedTopic(topic string) bool {
 		return true
 	case TopicTerminalsState:
 		return true
+	case TopicNotifications:
+		return true
+	case TopicSystemRecovery:
+		return true
+	case TopicSessionsSync:
+		return true
+	case TopicWaits:
+		return true
 	default:
 		if _, ok := SessionIDFromActivityTopic(topic); ok {
 			return true
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// DefaultBatchPollInterval is how often queued remote runs are polled.
+const DefaultBatchPollInterval = 30 * time.Second
+
+// batchSuitable reports whether a low-priority message can be routed through
+// a batch queue. Dock sessions are interactive, and timeboxed and budgeted
+// runs need a live provider to interrupt, so they always run live.
+func batchSuitable(sess *domain.Session, opts SendMessageOptions) bool {
+	return opts.LowPriority && opts.Deadline == 0 && opts.TokenBudget == 0 && sess.Kind != domain.SessionKindDock
+}
+
+// startQueuedRemoteRun records the run attempt and user message, then submits
+// the run to the provider's batch queue in the background. Callers must hold
+// e.mu.
+func (e *AgentExecutor) startQueuedRemoteRun(sc *sessionContext, batch session.BatchRunner, config session.Config, content, providerID, messageID string) (*domain.Session, error) {
+	e.startRunAttempt(sc, config.ProviderType, providerID, messageID)
+	e.appendSessionMessage(sc.session, domain.MessageKindUser, content, time.Now())
+	if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+
+	e.wg.Go(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				e.handlePanic(sc, r)
+			}
+		}()
+		e.runQueuedRemote(sc, batch, config, content)
+	})
+	return sc.session, nil
+}
+
+func (e *AgentExecutor) runQueuedRemote(sc *sessionContext, batch session.BatchRunner, config session.Config, content string) {
+
+	submitCtx, cancel := context.WithTimeout(e.ctx, e.opTimeout)
+	ref, err := batch.SubmitBatch(submitCtx, config, content)
+	cancel()
+	e.markMessageDelivery(sc, err)
+	if err != nil {
+		e.failQueuedRemote(sc, fmt.Sprintf("Batch submission failed: %v", err), "BATCH_SUBMIT_FAILED")
+		return
+	}
+
+	attemptID := e.markRunAttemptQueuedRemote(sc, ref)
+	e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusBatchSubmitted))
+	e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitQueuedRemote, "ref", ref))
+	e.pollQueuedRemote(sc, batch, ref, attemptID)
+}
+
+// resumeQueuedRemote picks up polling for a batch submitted before a
+// restart. It reports false when the session's provider cannot poll it, in
+// which case the attempt is left for recovery to close.
+func (e *AgentExecutor) resumeQueuedRemote(sess *domain.Session, attempt *storage.RunAttemptMetadata) bool {
+	if e.sessionFactory == nil || attempt.ProviderType == "" {
+		return false
+	}
+	prov, err := e.sessionFactory(attempt.ProviderType, sess.ID, e.runConfig(sess.ID, sess, attempt.ProviderType))
+	if err != nil {
+		return false
+	}
+	batch, ok := prov.(session.BatchRunner)
+	if !ok {
+		return false
+	}
+	sc, err := e.ensureSessionContext(sess.ID)
+	if err != nil {
+		return false
+	}
+	sc.amMu.Lock()
+	sc.attempt = attempt
+	sc.amMu.Unlock()
+
+	e.wg.Go(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				e.handlePanic(sc, r)
+			}
+		}()
+		e.pollQueuedRemote(sc, batch, attempt.WaitRef, attempt.AttemptID)
+	})
+	return true
+}
+
+// pollQueuedRemote waits for batch ref to finish and applies its events as
+// the end of attempt attemptID.
+func (e *AgentExecutor) pollQueuedRemote(sc *sessionContext, batch session.BatchRunner, ref, attemptID string) {
+	id := sc.session.ID
+	ticker := time.NewTicker(e.batchPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			// Left waiting; startup recovery resumes polling.
+			return
+		case <-ticker.C:
+		}
+
+		// A stop or cancel ends the attempt; stop polling for it.
+		if !e.runAttemptOpen(sc, attemptID) {
+			return
+		}
+
+		pollCtx, cancel := context.WithTimeout(e.ctx, e.opTimeout)
+		events, done, err := batch.PollBatch(pollCtx, ref)
+		cancel()
+		if err != nil {
+			log.Printf("session %s: polling batch %s: %v", id, ref, err)
+			continue
+		}
+		if !done {
+			continue
+		}
+
+		e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+			a.WaitKind = ""
+			a.WaitRef = ""
+		})
+		for _, event := range events {
+			event = e.screenEvent(sc, event)
+			e.broadcaster.Broadcast(event)
+			e.updateSessionFromEvent(sc, event)
+		}
+		e.finalizeRunAttempt(sc, "completed", "")
+		e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusBatchCompleted))
+		e.deliverQueuedMessages(sc)
+		return
+	}
+}
+
+func (e *AgentExecutor) failQueuedRemote(sc *sessionContext, errMsg, code string) {
+	log.Printf("session %s: %s", sc.session.ID, errMsg)
+	e.appendSessionMessage(sc.session, domain.MessageKindError, errMsg, time.Now())
+	e.finalizeRunAttempt(sc, "failed", errMsg)
+	if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+	e.broadcaster.Broadcast(domain.NewErrorEvent(sc.session.ID, errMsg, code, nil))
+}
+
+// markRunAttemptQueuedRemote records that the current attempt is waiting on
+// a remote batch and returns its ID.
+func (e *AgentExecutor) markRunAttemptQueuedRemote(sc *sessionContext, ref string) string {
+	var attemptID string
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.WaitKind = domain.WaitKindQueuedRemote
+		a.WaitRef = ref
+		a.HeartbeatAt = time.Now().UTC()
+		attemptID = a.AttemptID
+	})
+	return attemptID
+}
+
+func (e *AgentExecutor) runAttemptOpen(sc *sessionContext, attemptID string) bool {
+	if attemptID == "" {
+		// Without attempt storage the session state is all there is.
+		return sc.session.GetState() == domain.SessionStateSuspended
+	}
+	sc.amMu.Lock()
+	defer sc.amMu.Unlock()
+	return sc.attempt != nil && sc.attempt.AttemptID == attemptID && sc.attempt.EndedAt == nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+type batchProvider struct {
+	*mockProvider
+	mu        sync.Mutex
+	submitted []string
+	ready     bool
+}
+
+func (p *batchProvider) SubmitBatch(ctx context.Context, config session.Config, input string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.submitted = append(p.submitted, input)
+	return "batch-1", nil
+}
+
+func (p *batchProvider) PollBatch(ctx context.Context, ref string) ([]domain.Event, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.ready {
+		return nil, false, nil
+	}
+	return []domain.Event{domain.NewOutputEvent("batched", "batched answer", nil)}, true, nil
+}
+
+func (p *batchProvider) setReady() {
+	p.mu.Lock()
+	p.ready = true
+	p.mu.Unlock()
+}
+
+func TestAgentExecutor_LowPriorityRunsThroughBatchQueue(t *testing.T) {
+	prov := &batchProvider{mockProvider: newMockProvider()}
+	store := newMockStorage()
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return prov, nil
+		},
+		OperationTimeout:  5 * time.Second,
+		BatchPollInterval: 20 * time.Millisecond,
+	})
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "batched", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "batched", "summarize", "", "", SendMessageOptions{LowPriority: true}); err != nil {
+		t.Fatalf("SendMessageWithOptions: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		state, err := executor.DeriveSessionState("batched")
+		return err == nil && state == domain.SessionStateSuspended
+	})
+	attempt := waitForRunAttempt(t, store, "batched", false)
+	if attempt.WaitKind != domain.WaitKindQueuedRemote || attempt.WaitRef != "batch-1" {
+		t.Fatalf("attempt wait = %q/%q, want queued_remote/batch-1", attempt.WaitKind, attempt.WaitRef)
+	}
+
+	prov.setReady()
+	attempt = waitForRunAttempt(t, store, "batched", true)
+	if attempt.TerminalReason != "completed" || attempt.WaitKind != "" {
+		t.Fatalf("attempt = %+v, want completed without wait", attempt)
+	}
+
+	sess, _ := executor.GetSession("batched")
+	if state := sess.GetState(); state != domain.SessionStateIdle {
+		t.Fatalf("state = %s, want idle", state)
+	}
+	found := false
+	for _, msg := range sess.Snapshot().Messages {
+		if msg.Kind == domain.MessageKindOutput && msg.Contents == "batched answer" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("batch result was not folded into the message log")
+	}
+
+	prov.mu.Lock()
+	submitted := len(prov.submitted)
+	prov.mu.Unlock()
+	if submitted != 1 {
+		t.Fatalf("submitted %d batches, want 1", submitted)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for condition")
+}
+
+func TestAgentExecutor_StartupResumesQueuedRemoteRun(t *testing.T) {
+	prov := &batchProvider{mockProvider: newMockProvider(), ready: true}
+	store := newMockStorage()
+
+	sess := domain.NewSession("batched", "mock", "/tmp")
+	_ = sess.TransitionWithNotice(domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusBatchSubmitted))
+	_ = sess.TransitionWithNotice(domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitQueuedRemote, "ref", "batch-1"))
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("save session: %v", err)
+	}
+	if err := store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:    "attempt-batch",
+		SessionID:    "batched",
+		ProviderType: "mock",
+		StartedAt:    time.Now().UTC().Add(-time.Hour),
+		HeartbeatAt:  time.Now().UTC().Add(-time.Hour),
+		WaitKind:     domain.WaitKindQueuedRemote,
+		WaitRef:      "batch-1",
+	}); err != nil {
+		t.Fatalf("save attempt: %v", err)
+	}
+
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return prov, nil
+		},
+		OperationTimeout:  5 * time.Second,
+		BatchPollInterval: 20 * time.Millisecond,
+	})
+	defer executor.Shutdown(context.Background())
+	if err := executor.Startup(context.Background()); err != nil {
+		t.Fatalf("startup recovery: %v", err)
+	}
+
+	attempt := waitForRunAttempt(t, store, "batched", true)
+	if attempt.TerminalReason != "completed" {
+		t.Fatalf("attempt = %+v, want the batch polled to completion", attempt)
+	}
+	if report := executor.RecoveryReport(); report == nil || report.AttemptsClosed != 0 {
+		t.Fatalf("recovery report = %+v, want no attempts closed", report)
+	}
+	waitFor(t, func() bool {
+		state, err := executor.DeriveSessionState("batched")
+		return err == nil && state == domain.SessionStateIdle
+	})
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// MaxBestOfNCandidates caps the parallel runs of one best-of-N group.
+const MaxBestOfNCandidates = 8
+
+var (
+	ErrInvalidBestOfN = errors.New("invalid best-of-N request")
+	ErrNoBestOfN      = errors.New("session has no running best-of-N group")
+	ErrNotCandidate   = errors.New("session is not a candidate of the best-of-N group")
+	ErrMergeConflict  = errors.New("merge conflict")
+)
+
+// BestOfNCandidateSpec picks the provider of one candidate run. Empty fields
+// use the parent session's provider.
+type BestOfNCandidateSpec struct {
+	ProviderType string
+	ProviderID   string
+}
+
+// BestOfNComparison summarizes what one candidate changed relative to the
+// group's base commit, untracked files included.
+type BestOfNComparison struct {
+	SessionID  string
+	State      domain.SessionState
+	Files      []string
+	Insertions int
+	Deletions  int
+	DiffStat   string
+	// LastOutput is the candidate's final agent output.
+	LastOutput string
+	// Error is set when the worktree could not be diffed.
+	Error string
+}
+
+// StartBestOfN runs prompt in one candidate session per spec, each in a new
+// git worktree of the parent's working directory branched from its HEAD.
+// The candidates run in parallel until SelectBestOfN merges one of them or
+// DiscardBestOfN drops them all.
+func (e *AgentExecutor) StartBestOfN(ctx context.Context, parentID, prompt string, specs []BestOfNCandidateSpec) (*domain.BestOfN, error) {
+	e.bestOfNMu.Lock()
+	defer e.bestOfNMu.Unlock()
+
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return nil, fmt.Errorf("%w: prompt is required", ErrInvalidBestOfN)
+	}
+	if len(specs) < 2 || len(specs) > MaxBestOfNCandidates {
+		return nil, fmt.Errorf("%w: between 2 and %d candidates are required", ErrInvalidBestOfN, MaxBestOfNCandidates)
+	}
+	sc, err := e.ensureSessionContext(parentID)
+	if err != nil {
+		return nil, err
+	}
+	parent := sc.session
+	if group := parent.GetBestOfN(); group != nil && group.Status == domain.BestOfNRunning {
+		return nil, fmt.Errorf("%w: session already has a running best-of-N group", ErrInvalidState)
+	}
+	if sc.getRun() != nil {
+		return nil, fmt.Errorf("%w: session is running", ErrInvalidState)
+	}
+
+	dir := parent.WorkingDir
+	base, err := runGit(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%w: working directory is not a git checkout with commits: %v", ErrInvalidBestOfN, err)
+	}
+	commonDir, err := runGit(ctx, dir, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBestOfN, err)
+	}
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+
+	snap := parent.Snapshot()
+	group := &domain.BestOfN{
+		Prompt:    prompt,
+		BaseRef:   base,
+		Status:    domain.BestOfNRunning,
+		CreatedAt: time.Now().UTC(),
+	}
+	for i, spec := range specs {
+		c := domain.BestOfNCandidate{
+			SessionID:    newSessionID(),
+			ProviderType: spec.ProviderType,
+			ProviderID:   spec.ProviderID,
+			Branch:       fmt.Sprintf("orbitmesh/best-of-n/%s/%d", parentID, i+1),
+		}
+		if c.ProviderType == "" {
+			c.ProviderType = snap.ProviderType
+		}
+		if c.ProviderID == "" && spec.ProviderType == "" {
+			c.ProviderID = snap.PreferredProviderID
+		}
+		c.Worktree = filepath.Join(commonDir, "orbitmesh-worktrees", parentID, c.SessionID)
+		if err := e.startBestOfNCandidate(ctx, snap, group, c, i+1, len(specs)); err != nil {
+			e.dropBestOfNCandidates(ctx, dir, append(group.Candidates, c), "")
+			return nil, err
+		}
+		group.Candidates = append(group.Candidates, c)
+	}
+
+	parent.SetBestOfN(group)
+	e.appendNotice(parent, domain.MessageKindSystem, domain.NewNotice(domain.NoticeBestOfNStarted, "count", strconv.Itoa(len(group.Candidates)), "base", shortRef(base)), time.Now())
+	if e.storage != nil {
+		if err := e.saveSession(parent); err != nil {
+			return group, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return group, nil
+}
+
+func (e *AgentExecutor) startBestOfNCandidate(ctx context.Context, parent domain.SessionSnapshot, group *domain.BestOfN, c domain.BestOfNCandidate, n, total int) error {
+	if err := addWorktree(ctx, parent.WorkingDir, c.Branch, c.Worktree, group.BaseRef); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+
+	custom := maps.Clone(parent.ProviderCustom)
+	if custom == nil {
+		custom = map[string]any{}
+	}
+	custom["worktree_isolation"] = true
+	if _, err := e.CreateSession(ctx, c.SessionID, session.Config{
+		ProviderType:    c.ProviderType,
+		AgentID:         parent.AgentID,
+		WorkingDir:      c.Worktree,
+		ProjectID:       parent.ProjectID,
+		SystemPrompt:    parent.PromptPrefix,
+		Custom:          custom,
+		SessionKind:     domain.SessionKindBestOfN,
+		Title:           fmt.Sprintf("Best of %d: candidate %d", total, n),
+		CleanupCommands: parent.CleanupCommands,
+		Features:        parent.Features,
+		Metadata:        parent.Metadata,
+	}); err != nil {
+		return err
+	}
+	if _, err := e.sendMessage(ctx, c.SessionID, group.Prompt, c.ProviderID, "", SendMessageOptions{}); err != nil {
+		return fmt.Errorf("failed to start candidate %d: %w", n, err)
+	}
+	return nil
+}
+
+// CompareBestOfN returns the session's best-of-N group with a comparison of
+// its candidates.
+func (e *AgentExecutor) CompareBestOfN(ctx context.Context, parentID string) (*domain.BestOfN, []BestOfNComparison, error) {
+	parent, err := e.GetSession(parentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	group := parent.GetBestOfN()
+	if group == nil {
+		return nil, nil, ErrNoBestOfN
+	}
+
+	out := make([]BestOfNComparison, 0, len(group.Candidates))
+	for _, c := range group.Candidates {
+		cmp := BestOfNComparison{SessionID: c.SessionID}
+		if sess, err := e.GetSession(c.SessionID); err == nil {
+			cmp.State = sess.GetState()
+			cmp.LastOutput = lastOutputMessage(sess)
+		}
+		if group.Status == domain.BestOfNRunning {
+			if err := diffWorktree(ctx, c.Worktree, group.BaseRef, &cmp); err != nil {
+				cmp.Error = err.Error()
+			}
+		}
+		out = append(out, cmp)
+	}
+	return group, out, nil
+}
+
+// SelectBestOfN commits the winning candidate's worktree and merges its
+// branch into the parent's working directory, then stops the other
+// candidates and removes every worktree. The winner must have finished its
+// run.
+func (e *AgentExecutor) SelectBestOfN(ctx context.Context, parentID, winnerID, actor string) (*domain.BestOfN, error) {
+	e.bestOfNMu.Lock()
+	defer e.bestOfNMu.Unlock()
+
+	parent, group, err := e.runningBestOfN(parentID)
+	if err != nil {
+		return nil, err
+	}
+	var winner *domain.BestOfNCandidate
+	for i := range group.Candidates {
+		if group.Candidates[i].SessionID == winnerID {
+			winner = &group.Candidates[i]
+		}
+	}
+	if winner == nil {
+		return nil, ErrNotCandidate
+	}
+	if sess, err := e.GetSession(winnerID); err == nil && sess.GetState() != domain.SessionStateIdle {
+		return nil, fmt.Errorf("%w: candidate is still %s", ErrInvalidState, sess.GetState())
+	}
+
+	if err := commitWorktree(ctx, winner.Worktree, "Best-of-N candidate: "+firstLine(group.Prompt)); err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("Merge best-of-N candidate %s", winnerID)
+	if out, err := runGit(ctx, parent.WorkingDir, "merge", "--no-ff", "-m", msg, winner.Branch); err != nil {
+		_, _ = runGit(ctx, parent.WorkingDir, "merge", "--abort")
+		return nil, fmt.Errorf("%w: %v %s", ErrMergeConflict, err, out)
+	}
+
+	e.dropBestOfNCandidates(ctx, parent.WorkingDir, group.Candidates, winnerID)
+	now := time.Now().UTC()
+	group.Status = domain.BestOfNMerged
+	group.WinnerID = winnerID
+	group.DecidedBy = actor
+	group.DecidedAt = &now
+	return group, e.saveBestOfN(parent, group, domain.NewNotice(domain.NoticeBestOfNMerged, "candidate", winnerID))
+}
+
+// DiscardBestOfN stops every candidate and removes their worktrees and
+// branches without merging anything.
+func (e *AgentExecutor) DiscardBestOfN(ctx context.Context, parentID, actor string) (*domain.BestOfN, error) {
+	e.bestOfNMu.Lock()
+	defer e.bestOfNMu.Unlock()
+
+	parent, group, err := e.runningBestOfN(parentID)
+	if err != nil {
+		return nil, err
+	}
+	e.dropBestOfNCandidates(ctx, parent.WorkingDir, group.Candidates, "")
+	now := time.Now().UTC()
+	group.Status = domain.BestOfNDiscarded
+	group.DecidedBy = actor
+	group.DecidedAt = &now
+	return group, e.saveBestOfN(parent, group, domain.NewNotice(domain.NoticeBestOfNDiscarded))
+}
+
+func (e *AgentExecutor) runningBestOfN(parentID string) (*domain.Session, *domain.BestOfN, error) {
+	parent, err := e.GetSession(parentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	group := parent.GetBestOfN()
+	if group == nil || group.Status != domain.BestOfNRunning {
+		return nil, nil, ErrNoBestOfN
+	}
+	return parent, group, nil
+}
+
+func (e *AgentExecutor) saveBestOfN(parent *domain.Session, group *domain.BestOfN, note domain.Notice) error {
+	parent.SetBestOfN(group)
+	e.appendNotice(parent, domain.MessageKindSystem, note, time.Now())
+	if e.storage == nil {
+		return nil
+	}
+	if err := e.saveSession(parent); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// dropBestOfNCandidates stops the candidates' runs and removes their
+// worktrees and branches. The kept candidate's branch has been merged, so
+// it is deleted the safe way.
+func (e *AgentExecutor) dropBestOfNCandidates(ctx context.Context, dir string, candidates []domain.BestOfNCandidate, kept string) {
+	for _, c := range candidates {
+		if sess, err := e.GetSession(c.SessionID); err == nil && sess.GetState() != domain.SessionStateIdle {
+			if err := e.StopSession(ctx, c.SessionID); err != nil {
+				log.Printf("best-of-n: stopping %s: %v", c.SessionID, err)
+			}
+		}
+		if err := removeWorktree(ctx, dir, c.Worktree); err != nil {
+			log.Printf("best-of-n: removing worktree %s: %v", c.Worktree, err)
+		}
+		flag := "-D"
+		if c.SessionID == kept {
+			flag = "-d"
+		}
+		_, _ = runGit(ctx, dir, "branch", flag, c.Branch)
+	}
+}
+
+// diffWorktree diffs everything in dir, untracked files included, against
+// base.
+func diffWorktree(ctx context.Context, dir, base string, cmp *BestOfNComparison) error {
+	return withScratchIndex(ctx, dir, func(env []string) error {
+		numstat, err := runGitEnv(ctx, dir, env, "diff", "--cached", "--numstat", base)
+		if err != nil {
+			return err
+		}
+		for _, f := range parseNumstat(numstat) {
+			cmp.Insertions += f.Insertions
+			cmp.Deletions += f.Deletions
+			cmp.Files = append(cmp.Files, f.Path)
+		}
+		cmp.DiffStat, err = runGitEnv(ctx, dir, env, "diff", "--cached", "--stat", base)
+		return err
+	})
+}
+
+// withScratchIndex stages everything in dir, untracked files included, into
+// a throwaway index and calls fn with the environment that selects it, so
+// the worktree's own index is left alone.
+func withScratchIndex(ctx context.Context, dir string, fn func(env []string) error) error {
+	index, err := os.CreateTemp("", "orbitmesh-index-*")
+	if err != nil {
+		return err
+	}
+	indexPath := index.Name()
+	index.Close()
+	// Git treats a missing index file as empty.
+	os.Remove(indexPath)
+	defer os.Remove(indexPath)
+
+	env := []string{"GIT_INDEX_FILE=" + indexPath}
+	if _, err := runGitEnv(ctx, dir, env, "add", "-A"); err != nil {
+		return err
+	}
+	return fn(env)
+}
+
+// parseNumstat parses the output of git diff --numstat.
+func parseNumstat(numstat string) []DiffFile {
+	var files []DiffFile
+	for _, line := range strings.Split(numstat, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// Binary files report "-" for both counts.
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		files = append(files, DiffFile{
+			Path:       fields[2],
+			Insertions: added,
+			Deletions:  deleted,
+			Binary:     fields[0] == "-",
+		})
+	}
+	return files
+}
+
+// commitWorktree commits everything in dir, if anything changed. A
+// fallback identity is used when git has none configured.
+func commitWorktree(ctx context.Context, dir, message string) error {
+	if _, err := runGit(ctx, dir, "add", "-A"); err != nil {
+		return err
+	}
+	if _, err := runGit(ctx, dir, "diff", "--cached", "--quiet"); err == nil {
+		return nil
+	}
+	args := []string{"commit", "-m", message}
+	if email, _ := runGit(ctx, dir, "config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=OrbitMesh", "-c", "user.email=orbitmesh@localhost"}, args...)
+	}
+	if _, err := runGit(ctx, dir, args...); err != nil {
+		return fmt.Errorf("failed to commit candidate: %w", err)
+	}
+	return nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	return runGitEnv(ctx, dir, nil, args...)
+}
+
+func runGitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return strings.TrimSpace(out.String()), fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+func lastOutputMessage(sess *domain.Session) string {
+	messages := sess.Snapshot().Messages
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Kind == domain.MessageKindOutput && !messages[i].Redacted {
+			return messages[i].Contents
+		}
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if r := []rune(line); len(r) > 60 {
+		line = string(r[:60]) + "…"
+	}
+	return line
+}
+
+func shortRef(ref string) string {
+	if len(ref) > 12 {
+		return ref[:12]
+	}
+	return ref
+}
+
+func newSessionID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
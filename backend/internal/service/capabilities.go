@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// ErrCapabilityUnsupported is returned when a session's provider lacks the
+// capability an operation needs.
+var ErrCapabilityUnsupported = errors.New("operation not supported by provider")
+
+// ProviderCapabilities reports what runners of providerType support. Runner
+// constructors only allocate, so it builds a throwaway runner to ask.
+func (e *AgentExecutor) ProviderCapabilities(providerType string) (session.Capabilities, error) {
+	if e.sessionFactory == nil || providerType == "" {
+		return session.Capabilities{}, ErrProviderNotFound
+	}
+	runner, err := e.sessionFactory(providerType, "", session.Config{ProviderType: providerType})
+	if err != nil {
+		return session.Capabilities{}, ErrProviderNotFound
+	}
+	return runner.Capabilities(), nil
+}
+
+// SessionCapabilities reports what the session's runner supports: the live
+// run's runner if there is one, otherwise any runner of its provider type.
+func (e *AgentExecutor) SessionCapabilities(id string) (session.Capabilities, error) {
+	e.mu.RLock()
+	sc, exists := e.sessions[id]
+	e.mu.RUnlock()
+	if !exists {
+		return session.Capabilities{}, ErrSessionNotFound
+	}
+	if run := sc.getRun(); run != nil {
+		return run.Session.Capabilities(), nil
+	}
+	return e.ProviderCapabilities(sc.session.ProviderType)
+}
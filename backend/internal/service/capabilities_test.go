@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_ProviderCapabilities(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	caps, err := executor.ProviderCapabilities("test")
+	if err != nil {
+		t.Fatalf("ProviderCapabilities failed: %v", err)
+	}
+	if !caps.Suspend || !caps.Steering || caps.Terminal {
+		t.Fatalf("unexpected capabilities: %+v", caps)
+	}
+	if _, err := executor.ProviderCapabilities("unknown"); !errors.Is(err, ErrProviderNotFound) {
+		t.Fatalf("expected ErrProviderNotFound, got %v", err)
+	}
+}
+
+func TestAgentExecutor_SessionCapabilitiesWithoutRun(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.SessionCapabilities("missing"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("expected ErrSessionNotFound, got %v", err)
+	}
+	if _, err := executor.CreateSession(context.Background(), "idle", session.Config{ProviderType: "test", WorkingDir: "/tmp"}); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	caps, err := executor.SessionCapabilities("idle")
+	if err != nil {
+		t.Fatalf("SessionCapabilities failed: %v", err)
+	}
+	if !caps.Steering {
+		t.Fatalf("expected the provider's capabilities before the first run, got %+v", caps)
+	}
+}
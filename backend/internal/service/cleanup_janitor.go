@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// CleanupAction selects what the janitor does with a stale session.
+type CleanupAction string
+
+const (
+	CleanupActionArchive CleanupAction = "archive"
+	CleanupActionDelete  CleanupAction = "delete"
+)
+
+// CleanupPolicy configures the stale-session janitor. A zero MaxIdle disables
+// session expiry; orphan, token and attempt cleanup still run. A zero
+// Interval disables the background loop, leaving only on-demand runs.
+type CleanupPolicy struct {
+	MaxIdle  time.Duration
+	Action   CleanupAction
+	Interval time.Duration
+}
+
+// CleanupReport records what a single janitor pass did (or, for dry runs,
+// would have done).
+type CleanupReport struct {
+	StartedAt           time.Time `json:"started_at"`
+	FinishedAt          time.Time `json:"finished_at"`
+	DryRun              bool      `json:"dry_run"`
+	ArchivedSessions    []string  `json:"archived_sessions"`
+	DeletedSessions     []string  `json:"deleted_sessions"`
+	OrphanedTerminals   []string  `json:"orphaned_terminals"`
+	ExpiredResumeTokens []string  `json:"expired_resume_tokens"`
+	DanglingAttempts    []string  `json:"dangling_attempts"`
+	Errors              []string  `json:"errors,omitempty"`
+}
+
+func newCleanupReport(dryRun bool) *CleanupReport {
+	return &CleanupReport{
+		StartedAt:           time.Now().UTC(),
+		DryRun:              dryRun,
+		ArchivedSessions:    []string{},
+		DeletedSessions:     []string{},
+		OrphanedTerminals:   []string{},
+		ExpiredResumeTokens: []string{},
+		DanglingAttempts:    []string{},
+	}
+}
+
+func (r *CleanupReport) addError(format string, args ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// CleanupPolicy returns the policy the executor was configured with.
+func (e *AgentExecutor) CleanupPolicy() CleanupPolicy {
+	return e.cleanupPolicy
+}
+
+// LastCleanupReport returns the report of the most recent non-dry-run pass,
+// or nil if the janitor has not run yet.
+func (e *AgentExecutor) LastCleanupReport() *CleanupReport {
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+	return e.lastCleanup
+}
+
+func (e *AgentExecutor) startCleanupJanitor() {
+	interval := e.cleanupPolicy.Interval
+	if interval <= 0 {
+		return
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := e.RunCleanup(e.ctx, false); err != nil {
+					log.Printf("cleanup janitor: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// RunCleanup performs one janitor pass using the configured policy. Unpinned
+// idle sessions older than MaxIdle are archived or deleted, then terminals,
+// run-attempt directories and resume tokens that no longer belong to a live
+// session are removed. With dryRun set nothing is modified.
+func (e *AgentExecutor) RunCleanup(ctx context.Context, dryRun bool) (*CleanupReport, error) {
+	if e.storage == nil {
+		return nil, fmt.Errorf("cleanup requires session storage")
+	}
+
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+
+	report := newCleanupReport(dryRun)
+	cleaner, _ := e.storage.(storage.CleanupStorage)
+
+	// A session that fails to load must not have its files treated as
+	// orphans, so any list error aborts the pass.
+	sessions, err := e.storage.List()
+	if err != nil {
+		return nil, fmt.Errorf("cleanup list sessions: %w", err)
+	}
+
+	policy := e.cleanupPolicy
+	cutoff := report.StartedAt.Add(-policy.MaxIdle)
+	remaining := make(map[string]bool, len(sessions))
+	for _, sess := range sessions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if sess == nil || sess.ID == "" {
+			continue
+		}
+		remaining[sess.ID] = true
+		if policy.MaxIdle <= 0 || !e.isStaleSession(sess, cutoff) {
+			continue
+		}
+
+		archive := policy.Action != CleanupActionDelete
+		if !dryRun {
+			err := e.removeStaleSession(cleaner, sess.ID, archive, cutoff)
+			if errors.Is(err, errSessionNotStale) {
+				continue
+			}
+			if err != nil {
+				report.addError("session %s: %v", sess.ID, err)
+				continue
+			}
+		}
+		delete(remaining, sess.ID)
+		if archive {
+			report.ArchivedSessions = append(report.ArchivedSessions, sess.ID)
+		} else {
+			report.DeletedSessions = append(report.DeletedSessions, sess.ID)
+		}
+	}
+
+	e.mu.RLock()
+	for id := range e.sessions {
+		remaining[id] = true
+	}
+	e.mu.RUnlock()
+
+	if e.terminalStorage != nil {
+		terms, err := e.terminalStorage.ListTerminals()
+		if err != nil {
+			report.addError("list terminals: %v", err)
+		}
+		for _, term := range terms {
+			if term == nil || term.SessionID == "" || remaining[term.SessionID] {
+				continue
+			}
+			if !dryRun {
+				if err := e.terminalStorage.DeleteTerminal(term.ID); err != nil {
+					report.addError("terminal %s: %v", term.ID, err)
+					continue
+				}
+			}
+			report.OrphanedTerminals = append(report.OrphanedTerminals, term.ID)
+		}
+	}
+
+	if cleaner != nil {
+		attemptSessions, err := cleaner.ListAttemptSessionIDs()
+		if err != nil {
+			report.addError("list run attempts: %v", err)
+		}
+		for _, sid := range attemptSessions {
+			if remaining[sid] {
+				continue
+			}
+			if !dryRun {
+				if err := cleaner.DeleteRunAttempts(sid); err != nil {
+					report.addError("run attempts %s: %v", sid, err)
+					continue
+				}
+			}
+			report.DanglingAttempts = append(report.DanglingAttempts, sid)
+		}
+
+		tokens, err := cleaner.ListResumeTokens()
+		if err != nil {
+			report.addError("list resume tokens: %v", err)
+		}
+		for _, token := range tokens {
+			if token == nil {
+				continue
+			}
+			if report.StartedAt.Before(token.ExpiresAt) && remaining[token.SessionID] {
+				continue
+			}
+			if !dryRun {
+				if err := cleaner.DeleteResumeToken(token.TokenID); err != nil {
+					report.addError("resume token %s: %v", token.TokenID, err)
+					continue
+				}
+			}
+			report.ExpiredResumeTokens = append(report.ExpiredResumeTokens, token.TokenID)
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	if !dryRun {
+		e.lastCleanup = report
+	}
+	return report, nil
+}
+
+// isStaleSession reports whether sess is unpinned, idle, has no live run and
+// has not been updated since cutoff.
+func (e *AgentExecutor) isStaleSession(sess *domain.Session, cutoff time.Time) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isStaleSessionLocked(sess, cutoff)
+}
+
+// isStaleSessionLocked is isStaleSession for callers holding e.mu.
+func (e *AgentExecutor) isStaleSessionLocked(sess *domain.Session, cutoff time.Time) bool {
+	if sc, live := e.sessions[sess.ID]; live && sc != nil {
+		if sc.getRun() != nil {
+			return false
+		}
+		sess = sc.session
+	}
+
+	snap := sess.Snapshot()
+	if snap.Pinned || snap.State != domain.SessionStateIdle {
+		return false
+	}
+	return snap.UpdatedAt.Before(cutoff)
+}
+
+// errSessionNotStale is returned by removeStaleSession when a session picked
+// by the janitor was used again before it could be removed.
+var errSessionNotStale = errors.New("session is no longer stale")
+
+// removeStaleSession deletes the session, or archives it when archive is set.
+// A non-zero cutoff first re-checks that the session is still stale, in the
+// same critical section that marks it as being removed, so a run cannot start
+// between the check and the removal.
+func (e *AgentExecutor) removeStaleSession(cleaner storage.CleanupStorage, id string, archive bool, cutoff time.Time) error {
+	sess, err := e.claimForRemoval(id, cutoff)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		e.mu.Lock()
+		delete(e.removing, id)
+		e.mu.Unlock()
+	}()
+
+	if sess != nil && len(sess.CleanupCommands) > 0 {
+		e.runTerminationHooks(sess, HookTriggerCleanup)
+		// Save so an archived session keeps the hook output.
+		_ = e.saveSession(sess)
+	}
+
+	switch {
+	case cleaner != nil && archive:
+		err = cleaner.ArchiveSession(id)
+	case cleaner != nil:
+		err = cleaner.PurgeSession(id)
+	case archive:
+		return fmt.Errorf("storage does not support archiving")
+	default:
+		err = e.storage.Delete(id)
+	}
+	if err != nil {
+		return err
+	}
+	if !archive {
+		if err := e.dropEventLog(id); err != nil {
+			log.Printf("cleanup: session %s: %v", id, err)
+		}
+		if sess != nil {
+			e.removeWorkspace(e.ctx, sess.GetWorkspace())
+		}
+	}
+
+	e.mu.Lock()
+	delete(e.sessions, id)
+	e.mu.Unlock()
+	e.changes.remove(id)
+	e.suggest.remove(id)
+	e.readState.forget(id)
+	e.questions.forget(id)
+	e.commandWaiters.forget(id)
+	e.toolApprovalWaiters.forget(id)
+	e.gitCredentials.forget(id)
+	e.apiTokens.forget(id)
+	e.warm.discard(id)
+	return nil
+}
+
+// ResetSessions kills every live run and permanently removes all sessions,
+// then runs a cleanup pass so their terminals, run attempts and resume
+// tokens go too. Pinned sessions are not spared. It returns the removed IDs.
+func (e *AgentExecutor) ResetSessions(ctx context.Context) ([]string, error) {
+	if e.storage == nil {
+		return nil, fmt.Errorf("reset requires session storage")
+	}
+
+	e.mu.RLock()
+	live := make(map[string]*session.Run)
+	for id, sc := range e.sessions {
+		if run := sc.getRun(); run != nil {
+			live[id] = run
+		}
+	}
+	e.mu.RUnlock()
+	for id, run := range live {
+		if err := e.KillSession(id); err != nil {
+			log.Printf("reset: kill session %s: %v", id, err)
+		}
+		// A run that is still starting reads as idle and is skipped by
+		// KillSession, so cancel it directly, then let the event loop exit so
+		// it cannot save the session back.
+		_ = run.Session.Kill()
+		run.Cancel()
+		select {
+		case <-run.EventsDone:
+		case <-time.After(e.opTimeout):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Hooks started by the kills save their session when done; wait so
+	// they cannot recreate it after it is removed below.
+	e.hookWG.Wait()
+
+	sessions, err := e.storage.List()
+	if err != nil {
+		return nil, fmt.Errorf("reset list sessions: %w", err)
+	}
+	cleaner, _ := e.storage.(storage.CleanupStorage)
+	removed := []string{}
+	e.cleanupMu.Lock()
+	for _, sess := range sessions {
+		if sess == nil || sess.ID == "" {
+			continue
+		}
+		if err := e.removeStaleSession(cleaner, sess.ID, false, time.Time{}); err != nil {
+			log.Printf("reset: remove session %s: %v", sess.ID, err)
+			continue
+		}
+		removed = append(removed, sess.ID)
+	}
+	e.cleanupMu.Unlock()
+
+	if _, err := e.RunCleanup(ctx, false); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
+// claimForRemoval marks id as being removed and returns the session to run
+// cleanup hooks for. With a non-zero cutoff a session that is no longer stale
+// is refused with errSessionNotStale.
+func (e *AgentExecutor) claimForRemoval(id string, cutoff time.Time) (*domain.Session, error) {
+	sess := e.sessionForCleanup(id)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.removing[id] {
+		return nil, fmt.Errorf("%w: session is already being removed", ErrInvalidState)
+	}
+	// The session may have been loaded since the lookup above.
+	if sc, live := e.sessions[id]; live && sc != nil {
+		sess = sc.session
+	}
+	if !cutoff.IsZero() && (sess == nil || !e.isStaleSessionLocked(sess, cutoff)) {
+		return nil, errSessionNotStale
+	}
+	e.removing[id] = true
+	return sess, nil
+}
+
+// sessionForCleanup returns the live session for id, falling back to the
+// stored copy.
+func (e *AgentExecutor) sessionForCleanup(id string) *domain.Session {
+	e.mu.RLock()
+	sc, live := e.sessions[id]
+	e.mu.RUnlock()
+	if live && sc != nil {
+		return sc.session
+	}
+	sess, err := e.storage.Load(id)
+	if err != nil {
+		return nil
+	}
+	return sess
+}
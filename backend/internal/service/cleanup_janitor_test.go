@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_RunCleanup(t *testing.T) {
+	store, err := storage.NewJSONFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+
+	old := time.Now().Add(-10 * 24 * time.Hour)
+	stale := domain.NewSession("stale", "mock", "/tmp")
+	stale.UpdatedAt = old
+	fresh := domain.NewSession("fresh", "mock", "/tmp")
+	pinned := domain.NewSession("pinned", "mock", "/tmp")
+	pinned.Pinned = true
+	pinned.UpdatedAt = old
+	for _, s := range []*domain.Session{stale, fresh, pinned} {
+		if err := store.Save(s); err != nil {
+			t.Fatalf("save %s: %v", s.ID, err)
+		}
+	}
+
+	_ = store.SaveTerminal(domain.NewTerminal("stale", "stale", domain.TerminalKindPTY))
+	_ = store.SaveTerminal(domain.NewTerminal("fresh", "fresh", domain.TerminalKindPTY))
+	_ = store.SaveRunAttempt(&storage.RunAttemptMetadata{AttemptID: "a1", SessionID: "gone", StartedAt: old})
+	_ = store.SaveRunAttempt(&storage.RunAttemptMetadata{AttemptID: "a1", SessionID: "fresh", StartedAt: old})
+	_ = store.SaveResumeToken(&storage.ResumeTokenMetadata{TokenID: "expired", SessionID: "fresh", AttemptID: "a1", ExpiresAt: time.Now().Add(-time.Hour)})
+	_ = store.SaveResumeToken(&storage.ResumeTokenMetadata{TokenID: "valid", SessionID: "fresh", AttemptID: "a1", ExpiresAt: time.Now().Add(time.Hour)})
+
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:         store,
+		TerminalStorage: store,
+		CleanupPolicy:   CleanupPolicy{MaxIdle: 7 * 24 * time.Hour, Action: CleanupActionArchive},
+	})
+	defer func() { _ = executor.Shutdown(context.Background()) }()
+
+	dry, err := executor.RunCleanup(context.Background(), true)
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if len(dry.ArchivedSessions) != 1 || executor.LastCleanupReport() != nil {
+		t.Fatalf("unexpected dry-run result: %+v", dry)
+	}
+	if _, err := store.Load("stale"); err != nil {
+		t.Fatalf("dry run must not modify storage: %v", err)
+	}
+
+	report, err := executor.RunCleanup(context.Background(), false)
+	if err != nil {
+		t.Fatalf("RunCleanup: %v", err)
+	}
+	if len(report.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", report.Errors)
+	}
+	if got := report.ArchivedSessions; len(got) != 1 || got[0] != "stale" {
+		t.Errorf("ArchivedSessions = %v", got)
+	}
+	if got := report.OrphanedTerminals; len(got) != 1 || got[0] != "stale" {
+		t.Errorf("OrphanedTerminals = %v", got)
+	}
+	if got := report.DanglingAttempts; len(got) != 1 || got[0] != "gone" {
+		t.Errorf("DanglingAttempts = %v", got)
+	}
+	if got := report.ExpiredResumeTokens; len(got) != 1 || got[0] != "expired" {
+		t.Errorf("ExpiredResumeTokens = %v", got)
+	}
+
+	if _, err := store.Load("stale"); err != storage.ErrSessionNotFound {
+		t.Errorf("stale session should be archived, got err=%v", err)
+	}
+	if _, err := store.Load("fresh"); err != nil {
+		t.Errorf("fresh session should remain: %v", err)
+	}
+	if _, err := store.Load("pinned"); err != nil {
+		t.Errorf("pinned session should be exempt: %v", err)
+	}
+	if _, err := store.LoadResumeToken("valid"); err != nil {
+		t.Errorf("valid token should remain: %v", err)
+	}
+	if executor.LastCleanupReport() != report {
+		t.Error("LastCleanupReport should return the latest pass")
+	}
+}
+
+func TestAgentExecutor_RunCleanupAbortsOnListError(t *testing.T) {
+	dir := t.TempDir()
+	store, err := storage.NewJSONFileStorage(dir)
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	if err := store.Save(domain.NewSession("fresh", "mock", "/tmp")); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sessions", "broken.json"), []byte("{not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.SaveRunAttempt(&storage.RunAttemptMetadata{AttemptID: "a1", SessionID: "broken", StartedAt: time.Now()})
+
+	executor := NewAgentExecutor(ExecutorConfig{Storage: store, TerminalStorage: store})
+	defer func() { _ = executor.Shutdown(context.Background()) }()
+
+	if _, err := executor.RunCleanup(context.Background(), false); err == nil {
+		t.Fatal("expected cleanup to abort when a session fails to load")
+	}
+	if attempts, _ := store.ListRunAttempts("broken"); len(attempts) != 1 {
+		t.Fatalf("the unloadable session's run attempt was removed: %v", attempts)
+	}
+	if _, err := executor.ResetSessions(context.Background()); err == nil {
+		t.Fatal("expected reset to abort when a session fails to load")
+	}
+	if _, err := store.Load("fresh"); err != nil {
+		t.Fatalf("reset removed sessions despite the list error: %v", err)
+	}
+}
+
+func TestAgentExecutor_ResetSessions(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer func() { _ = executor.Shutdown(context.Background()) }()
+
+	for _, id := range []string{"live", "idle"} {
+		if _, err := executor.CreateSession(context.Background(), id, session.Config{ProviderType: "mock", WorkingDir: "/tmp"}); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	if _, err := executor.SetSessionPinned("idle", true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "live", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitForRunAttempt(t, store, "live", false)
+
+	removed, err := executor.ResetSessions(context.Background())
+	if err != nil {
+		t.Fatalf("ResetSessions: %v", err)
+	}
+	if len(removed) != 2 {
+		t.Fatalf("removed = %v, want both sessions", removed)
+	}
+	if got := executor.ListSessions(); len(got) != 0 {
+		t.Fatalf("sessions after reset = %d", len(got))
+	}
+	if _, err := executor.GetSession("live"); err == nil {
+		t.Fatal("live session survived reset")
+	}
+	if got := executor.SuggestSessions("", 0); len(got) != 0 {
+		t.Fatalf("suggestions after reset = %+v", got)
+	}
+}
+
+func TestAgentExecutor_RemoveStaleSessionRechecks(t *testing.T) {
+	t.Run("skips a session used since it was picked", func(t *testing.T) {
+		executor, store := createTestExecutor(newMockProvider())
+		defer func() { _ = executor.Shutdown(context.Background()) }()
+
+		if _, err := executor.CreateSession(context.Background(), "s1", session.Config{ProviderType: "mock", WorkingDir: "/tmp"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		// The janitor's cutoff is in the past, but the session was updated
+		// after it: the removal must back off.
+		cutoff := time.Now().Add(-time.Hour)
+		err := executor.removeStaleSession(nil, "s1", false, cutoff)
+		if !errors.Is(err, errSessionNotStale) {
+			t.Fatalf("removeStaleSession = %v, want errSessionNotStale", err)
+		}
+		if _, err := store.Load("s1"); err != nil {
+			t.Fatalf("session should remain: %v", err)
+		}
+		if _, err := executor.SendMessage(context.Background(), "s1", "hello", "", ""); err != nil {
+			t.Fatalf("SendMessage after a skipped removal: %v", err)
+		}
+	})
+
+	t.Run("refuses to start a run while removing", func(t *testing.T) {
+		executor, _ := createTestExecutor(newMockProvider())
+		defer func() { _ = executor.Shutdown(context.Background()) }()
+
+		if _, err := executor.CreateSession(context.Background(), "s1", session.Config{ProviderType: "mock", WorkingDir: "/tmp"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if _, err := executor.claimForRemoval("s1", time.Time{}); err != nil {
+			t.Fatalf("claimForRemoval: %v", err)
+		}
+		if _, err := executor.SendMessage(context.Background(), "s1", "hello", "", ""); !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("SendMessage = %v, want ErrInvalidState", err)
+		}
+		if sc := executor.sessions["s1"]; sc == nil || sc.getRun() != nil {
+			t.Fatal("a run started on a session being removed")
+		}
+		if err := executor.removeStaleSession(nil, "s1", false, time.Time{}); !errors.Is(err, ErrInvalidState) {
+			t.Fatalf("second removal = %v, want ErrInvalidState", err)
+		}
+	})
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+var (
+	ErrInvalidCommand  = errors.New("invalid command")
+	ErrCommandNotFound = errors.New("command not found")
+	ErrCommandDecided  = errors.New("command is no longer awaiting approval")
+)
+
+// commandWaiters wakes the agents waiting on proposed commands. Proposals
+// are saved with the session, but an agent waiting on one does not survive
+// a restart.
+type commandWaiters struct {
+	mu        sync.Mutex
+	bySession map[string]map[string]chan struct{}
+}
+
+func newCommandWaiters() *commandWaiters {
+	return &commandWaiters{bySession: make(map[string]map[string]chan struct{})}
+}
+
+func (w *commandWaiters) add(sessionID, commandID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.bySession[sessionID] == nil {
+		w.bySession[sessionID] = make(map[string]chan struct{})
+	}
+	if _, ok := w.bySession[sessionID][commandID]; !ok {
+		w.bySession[sessionID][commandID] = make(chan struct{})
+	}
+}
+
+// get returns the channel closed once the command is decided, or nil.
+func (w *commandWaiters) get(sessionID, commandID string) chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.bySession[sessionID][commandID]
+}
+
+func (w *commandWaiters) wake(sessionID, commandID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ch, ok := w.bySession[sessionID][commandID]; ok {
+		close(ch)
+		delete(w.bySession[sessionID], commandID)
+	}
+}
+
+func (w *commandWaiters) forget(sessionID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ch := range w.bySession[sessionID] {
+		close(ch)
+	}
+	delete(w.bySession, sessionID)
+}
+
+// ProposeCommand records a shell command the session's agent is about to
+// run. In a session that requires command approval, the command is held
+// and the run suspended until a human decides, unless an auto-approve entry
+// matches it. toolCallID links the proposal to the tool call's events.
+func (e *AgentExecutor) ProposeCommand(id, toolCallID, command string) (domain.CommandRecord, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return domain.CommandRecord{}, fmt.Errorf("%w: command is required", ErrInvalidCommand)
+	}
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return domain.CommandRecord{}, err
+	}
+	if sc.getRun() == nil || sc.session.GetState() != domain.SessionStateRunning {
+		return domain.CommandRecord{}, fmt.Errorf("%w: only a running session can propose a command", ErrInvalidState)
+	}
+
+	now := time.Now().UTC()
+	policy := sc.session.GetCommandApproval()
+	rule, auto := policy.AutoApproves(command)
+	create := &domain.CommandRecord{ID: newAttemptID(), ToolCallID: toolCallID, Command: command}
+	cmd, _ := sc.session.UpdateCommand("", toolCallID, create, func(c *domain.CommandRecord) {
+		c.Command = command
+		c.ProposedAt = now
+		switch {
+		case policy == nil:
+			c.Status = domain.CommandStatusRunning
+			c.StartedAt = &now
+		case auto:
+			c.Status = domain.CommandStatusRunning
+			c.Approval = domain.CommandApprovalAuto
+			c.DecidedAt = &now
+			c.Reason = rule
+			c.StartedAt = &now
+		default:
+			c.Status = domain.CommandStatusProposed
+			c.StartedAt = nil
+		}
+	})
+
+	switch {
+	case policy == nil:
+	case auto:
+		notice := domain.NewNotice(domain.NoticeCommandApproved, "command", command, "rule", rule)
+		e.appendNotice(sc.session, domain.MessageKindSystem, notice, now)
+	default:
+		e.commandWaiters.add(id, cmd.ID)
+		e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeCommandProposed, "command", command), now)
+		e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+			a.WaitKind = domain.WaitKindCommandApproval
+			a.WaitRef = cmd.ID
+			a.HeartbeatAt = now
+		})
+		reason := command
+		if r := []rune(reason); len(r) > maxQuestionReasonLen {
+			reason = string(r[:maxQuestionReasonLen]) + "…"
+		}
+		e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitCommand, "command", reason))
+		return cmd, nil
+	}
+	if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+	return cmd, nil
+}
+
+// DecideCommand approves or rejects a proposed command and resumes the
+// suspended run. The reason of a rejection is passed on to the agent.
+func (e *AgentExecutor) DecideCommand(id, commandID string, approve bool, reason, decidedBy string) (domain.CommandRecord, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return domain.CommandRecord{}, err
+	}
+	now := time.Now().UTC()
+	decided := false
+	cmd, ok := sc.session.UpdateCommand(commandID, "", nil, func(c *domain.CommandRecord) {
+		if !c.Pending() {
+			return
+		}
+		decided = true
+		c.DecidedBy = decidedBy
+		c.DecidedAt = &now
+		c.Reason = strings.TrimSpace(reason)
+		if approve {
+			c.Approval = domain.CommandApprovalApproved
+			c.Status = domain.CommandStatusRunning
+			c.StartedAt = &now
+		} else {
+			c.Approval = domain.CommandApprovalRejected
+			c.Status = domain.CommandStatusRejected
+		}
+	})
+	switch {
+	case !ok:
+		return domain.CommandRecord{}, ErrCommandNotFound
+	case !decided:
+		return domain.CommandRecord{}, ErrCommandDecided
+	}
+
+	notice := domain.NewNotice(domain.NoticeCommandApproved, "command", cmd.Command, "decided_by", decidedBy)
+	if !approve {
+		notice = domain.NewNotice(domain.NoticeCommandRejected, "command", cmd.Command, "decided_by", decidedBy, "reason", cmd.Reason)
+	}
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, now)
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		if a.WaitKind == domain.WaitKindCommandApproval && a.WaitRef == commandID {
+			a.WaitKind = ""
+			a.WaitRef = ""
+		}
+		a.HeartbeatAt = now
+	})
+	if sc.getRun() != nil {
+		e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusCommandDecided, "decision", string(cmd.Approval)))
+	} else if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+	e.commandWaiters.wake(id, commandID)
+	return cmd, nil
+}
+
+// WaitForCommand blocks until a proposed command is decided, or ctx is
+// done, and returns its latest state.
+func (e *AgentExecutor) WaitForCommand(ctx context.Context, id, commandID string) (domain.CommandRecord, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return domain.CommandRecord{}, err
+	}
+	cmd, ok := sess.GetCommand(commandID)
+	if !ok {
+		return domain.CommandRecord{}, ErrCommandNotFound
+	}
+	if !cmd.Pending() {
+		return cmd, nil
+	}
+	if ch := e.commandWaiters.get(id, commandID); ch != nil {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+		}
+	}
+	cmd, _ = sess.GetCommand(commandID)
+	return cmd, nil
+}
+
+// AwaitCommandApproval proposes a command and waits for its decision. It
+// reports whether the command may run, and otherwise why not.
+func (e *AgentExecutor) AwaitCommandApproval(ctx context.Context, id, toolCallID, command string) (bool, string) {
+	cmd, err := e.ProposeCommand(id, toolCallID, command)
+	if err != nil {
+		// Without command approval, failing to record a command must not
+		// stop it.
+		if sess, getErr := e.GetSession(id); getErr == nil && sess.GetCommandApproval() == nil {
+			return true, ""
+		}
+		return false, err.Error()
+	}
+	if cmd, err = e.WaitForCommand(ctx, id, cmd.ID); err != nil {
+		return false, err.Error()
+	}
+	switch cmd.Status {
+	case domain.CommandStatusProposed:
+		return false, "the command was not approved in time"
+	case domain.CommandStatusRejected:
+		if cmd.Reason != "" {
+			return false, "the command was rejected: " + cmd.Reason
+		}
+		return false, "the command was rejected"
+	}
+	return true, ""
+}
+
+// SessionCommands returns the session's command history, oldest first.
+func (e *AgentExecutor) SessionCommands(id string) ([]domain.CommandRecord, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	return sess.GetCommands(), nil
+}
+
+// recordCommandEvent adds the shell commands tool call events show running
+// to the session's command history, and completes them from the calls'
+// results.
+func (e *AgentExecutor) recordCommandEvent(sc *sessionContext, event domain.Event) {
+	at := event.Timestamp.UTC()
+	switch data := event.Data.(type) {
+	case domain.ToolCallData:
+		if strings.HasPrefix(data.Status, "permission_") || data.ID == "" {
+			return
+		}
+		if data.Status == "completed" || toolCallFailureStatuses[data.Status] {
+			e.finishCommand(sc, data.ID, data.Status != "completed", data.Output, at)
+			return
+		}
+		command, ok := domain.ToolCommand(data.Name, data.Input)
+		if !ok {
+			return
+		}
+		create := &domain.CommandRecord{
+			ID:         newAttemptID(),
+			ToolCallID: data.ID,
+			Command:    command,
+			Status:     domain.CommandStatusRunning,
+			ProposedAt: at,
+			StartedAt:  &at,
+		}
+		sc.session.UpdateCommand("", data.ID, create, nil)
+	case domain.MetadataData:
+		if data.Key != "tool_result" {
+			return
+		}
+		result, _ := data.Value.(map[string]any)
+		if nested, ok := result["tool_result"].(map[string]any); ok {
+			result = nested
+		}
+		toolCallID, _ := result["tool_use_id"].(string)
+		failed, _ := result["is_error"].(bool)
+		if toolCallID != "" {
+			e.finishCommand(sc, toolCallID, failed, result["content"], at)
+		}
+	}
+}
+
+func (e *AgentExecutor) finishCommand(sc *sessionContext, toolCallID string, failed bool, output any, at time.Time) {
+	sc.session.UpdateCommand("", toolCallID, nil, func(c *domain.CommandRecord) {
+		if c.Status != domain.CommandStatusRunning {
+			return
+		}
+		c.Status = domain.CommandStatusCompleted
+		if failed {
+			c.Status = domain.CommandStatusFailed
+		}
+		c.CompletedAt = &at
+		c.ExitCode = domain.CommandExitCode(failed, output)
+		if c.StartedAt != nil && at.After(*c.StartedAt) {
+			c.DurationMS = at.Sub(*c.StartedAt).Milliseconds()
+		}
+	})
+}
+
+// rejectPendingCommands rejects the proposals of a run that ended before
+// they were decided, releasing the agents waiting on them.
+func (e *AgentExecutor) rejectPendingCommands(sc *sessionContext) {
+	if sc == nil || sc.session == nil {
+		return
+	}
+	now := time.Now().UTC()
+	for _, cmd := range sc.session.GetCommands() {
+		if !cmd.Pending() {
+			continue
+		}
+		sc.session.UpdateCommand(cmd.ID, "", nil, func(c *domain.CommandRecord) {
+			c.Status = domain.CommandStatusRejected
+			c.Approval = domain.CommandApprovalRejected
+			c.DecidedAt = &now
+			c.Reason = "the run ended before it was decided"
+		})
+		e.commandWaiters.wake(sc.session.ID, cmd.ID)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_CommandApprovalHoldsCommands(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		prov *mockProvider
+	)
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			prov = newMockProvider()
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	defer executor.Shutdown(context.Background())
+
+	_, err := executor.CreateSession(context.Background(), "cmds", session.Config{
+		ProviderType:    "mock",
+		WorkingDir:      "/tmp/test",
+		CommandApproval: &domain.CommandApproval{AutoApprove: []string{"go test"}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "cmds", "fix the build", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	sess, _ := executor.GetSession("cmds")
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	auto, err := executor.ProposeCommand("cmds", "call-1", "go test ./...")
+	if err != nil {
+		t.Fatalf("ProposeCommand: %v", err)
+	}
+	if auto.Status != domain.CommandStatusRunning || auto.Approval != domain.CommandApprovalAuto || auto.Reason != "go test" {
+		t.Fatalf("auto-approved command = %+v", auto)
+	}
+
+	held, err := executor.ProposeCommand("cmds", "call-2", "go test ./... && rm -rf build")
+	if err != nil {
+		t.Fatalf("ProposeCommand: %v", err)
+	}
+	if held.Status != domain.CommandStatusProposed {
+		t.Fatalf("chained command = %+v, want proposed", held)
+	}
+	if sess.GetState() != domain.SessionStateSuspended {
+		t.Fatalf("state = %s, want suspended", sess.GetState())
+	}
+
+	decided := make(chan domain.CommandRecord, 1)
+	go func() {
+		cmd, _ := executor.WaitForCommand(context.Background(), "cmds", held.ID)
+		decided <- cmd
+	}()
+	if _, err := executor.DecideCommand("cmds", held.ID, false, "keep the build dir", "alice"); err != nil {
+		t.Fatalf("DecideCommand: %v", err)
+	}
+	select {
+	case cmd := <-decided:
+		if cmd.Status != domain.CommandStatusRejected || cmd.DecidedBy != "alice" || cmd.Reason != "keep the build dir" {
+			t.Fatalf("rejected command = %+v", cmd)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("WaitForCommand did not return after the decision")
+	}
+	if sess.GetState() != domain.SessionStateRunning {
+		t.Fatalf("state = %s, want running after the decision", sess.GetState())
+	}
+	if _, err := executor.DecideCommand("cmds", held.ID, true, "", "bob"); !errors.Is(err, ErrCommandDecided) {
+		t.Fatalf("second decision = %v, want ErrCommandDecided", err)
+	}
+
+	mu.Lock()
+	p := prov
+	mu.Unlock()
+	p.SendEvent(domain.NewMetadataEvent("cmds", "tool_result", map[string]any{"tool_use_id": "call-1", "is_error": false}, nil))
+	waitFor(t, func() bool {
+		cmd, _ := sess.GetCommand(auto.ID)
+		return cmd.Status == domain.CommandStatusCompleted
+	})
+	if cmd, _ := sess.GetCommand(auto.ID); cmd.ExitCode == nil || *cmd.ExitCode != 0 {
+		t.Fatalf("completed command = %+v, want exit code 0", cmd)
+	}
+}
+
+func TestAgentExecutor_RecordsCommandsFromToolCalls(t *testing.T) {
+	prov := newMockProvider()
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "history", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "history", "run the tests", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	sess, _ := executor.GetSession("history")
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	start := time.Now()
+	prov.SendEvent(domain.Event{Type: domain.EventTypeToolCall, Timestamp: start, SessionID: "history", Data: domain.ToolCallData{
+		ID: "t1", Name: "Bash", Status: "started", Input: map[string]any{"command": "make test"},
+	}})
+	prov.SendEvent(domain.Event{Type: domain.EventTypeToolCall, Timestamp: start, SessionID: "history", Data: domain.ToolCallData{
+		ID: "t2", Name: "Read", Status: "started", Input: map[string]any{"file_path": "go.mod"},
+	}})
+	prov.SendEvent(domain.Event{Type: domain.EventTypeToolCall, Timestamp: start.Add(1500 * time.Millisecond), SessionID: "history", Data: domain.ToolCallData{
+		ID: "t1", Status: "failed", Output: "Exit code 2\nFAIL",
+	}})
+
+	waitFor(t, func() bool {
+		commands, _ := executor.SessionCommands("history")
+		return len(commands) == 1 && commands[0].Status == domain.CommandStatusFailed
+	})
+	commands, _ := executor.SessionCommands("history")
+	cmd := commands[0]
+	if cmd.Command != "make test" || cmd.Approval != "" || cmd.ExitCode == nil || *cmd.ExitCode != 2 || cmd.DurationMS != 1500 {
+		t.Fatalf("recorded command = %+v", cmd)
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// recordContextModel keeps the model a run reported, which decides the size
+// of the session's context window.
+func (e *AgentExecutor) recordContextModel(sc *sessionContext, value any) {
+	model, _ := value.(string)
+	if model == "" {
+		return
+	}
+	sc.session.SetContextModel(model)
+}
+
+// recordContextUsage records how much of the context window a request
+// filled and publishes it as a "context_window" metadata event. A request
+// that first takes the conversation past domain.ContextWindowWarnFraction
+// adds a notice, since the provider may soon compact it.
+func (e *AgentExecutor) recordContextUsage(sc *sessionContext, data domain.MetricData, at time.Time) {
+	// Like prompt cache usage, only per-request usage carries the request's
+	// full input.
+	if data.RequestCount == 0 {
+		return
+	}
+	used := data.TokensIn + data.CacheReadTokens + data.CacheCreationTokens + data.TokensOut
+	before, after := sc.session.RecordContextUsage(used, at)
+
+	e.broadcaster.Broadcast(domain.NewMetadataEvent(sc.session.ID, domain.MetadataKeyContextWindow, contextWindowValue(after), nil))
+	if after.NearLimit() && !before.NearLimit() {
+		e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeContextNearLimit,
+			"percent", strconv.Itoa(int(after.Fraction()*100)),
+			"limit", strconv.FormatInt(after.LimitTokens, 10),
+			"model", after.Model,
+		), at)
+	}
+}
+
+// contextWindowValue is the metadata value of a context window usage.
+func contextWindowValue(u domain.ContextWindowUsage) map[string]any {
+	value := map[string]any{
+		"model":       u.Model,
+		"used_tokens": u.UsedTokens,
+	}
+	if u.LimitTokens > 0 {
+		value["limit_tokens"] = u.LimitTokens
+		value["percent"] = u.Fraction() * 100
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_ContextWindowUsage(t *testing.T) {
+	prov := newMockProvider()
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "ctx", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	sub := executor.broadcaster.Subscribe("context-window", "ctx")
+	defer executor.broadcaster.Unsubscribe("context-window")
+	if _, err := executor.SendMessage(context.Background(), "ctx", "go", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	sess, _ := executor.GetSession("ctx")
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	prov.SendEvent(domain.NewMetadataEvent("ctx", domain.MetadataKeyModel, "claude-sonnet-4-5", nil))
+	prov.SendEvent(domain.NewMetricDataEvent("ctx", domain.MetricData{
+		TokensIn: 10_000, TokensOut: 500, RequestCount: 1, CacheReadTokens: 140_000,
+	}, nil))
+	// Output-only deltas do not describe the context.
+	prov.SendEvent(domain.NewMetricEvent("ctx", 0, 2_000, 0, nil))
+	waitFor(t, func() bool {
+		u := sess.GetContextWindow()
+		return u != nil && u.UsedTokens == 150_500
+	})
+	if u := sess.GetContextWindow(); u.LimitTokens != 200_000 || u.NearLimit() {
+		t.Fatalf("usage = %+v, want a 200k window below the limit", u)
+	}
+
+	prov.SendEvent(domain.NewMetricDataEvent("ctx", domain.MetricData{
+		TokensIn: 1_000, TokensOut: 200, RequestCount: 1, CacheReadTokens: 165_000,
+	}, nil))
+	waitFor(t, func() bool { return sess.GetContextWindow().NearLimit() })
+	prov.SendEvent(domain.NewMetricDataEvent("ctx", domain.MetricData{
+		TokensIn: 1_000, TokensOut: 200, RequestCount: 1, CacheReadTokens: 170_000,
+	}, nil))
+	waitFor(t, func() bool { return sess.GetContextWindow().UsedTokens == 171_200 })
+
+	notices := 0
+	for _, m := range sess.Snapshot().Messages {
+		if m.Notice != nil && m.Notice.Code == domain.NoticeContextNearLimit {
+			notices++
+			if m.Notice.Params["percent"] != "83" || m.Notice.Params["model"] != "claude-sonnet-4-5" {
+				t.Fatalf("notice params = %v", m.Notice.Params)
+			}
+		}
+	}
+	if notices != 1 {
+		t.Fatalf("near-limit notices = %d, want one when the limit is first reached", notices)
+	}
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event := <-sub.Events:
+			data, ok := event.Metadata()
+			if !ok || data.Key != domain.MetadataKeyContextWindow {
+				continue
+			}
+			value, _ := data.Value.(map[string]any)
+			if value["limit_tokens"] != int64(200_000) || value["used_tokens"] != int64(150_500) {
+				t.Fatalf("context_window event = %v", value)
+			}
+			return
+		case <-timeout:
+			t.Fatal("no context_window event")
+		}
+	}
+}
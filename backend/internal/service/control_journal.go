@@ -0,0 +1,98 @@
+package service
+
+import (
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// Control operations journaled on the current run attempt.
+const (
+	ControlOpStop   = "stop"
+	ControlOpCancel = "cancel"
+	ControlOpKill   = "kill"
+	ControlOpResume = "resume"
+)
+
+// Control operation outcomes.
+const (
+	ControlOutcomeApplied  = "applied"
+	ControlOutcomeNoop     = "noop"
+	ControlOutcomeRejected = "rejected"
+)
+
+// journalControl serialises control operations on a session and records each
+// in the current attempt's journal. If op was already applied to the current
+// attempt, apply is skipped and the recorded (successful) outcome is returned,
+// so retried requests that race with their own effect are no-ops.
+func (e *AgentExecutor) journalControl(sc *sessionContext, op string, apply func() (string, error)) error {
+	sc.ctlMu.Lock()
+	defer sc.ctlMu.Unlock()
+
+	if recorded, ok := e.appliedControlOp(sc, op); ok {
+		e.appendControlOp(sc, storage.ControlOperation{Op: op, Outcome: recorded.Outcome, Replayed: true})
+		return nil
+	}
+
+	outcome, err := apply()
+	entry := storage.ControlOperation{Op: op, Outcome: outcome}
+	if err != nil {
+		entry.Outcome = ControlOutcomeRejected
+		entry.Error = err.Error()
+	}
+	e.appendControlOp(sc, entry)
+	return err
+}
+
+// appliedControlOp returns the journal entry for op if it was applied to the
+// current attempt and nothing since has superseded it. Stop, cancel and kill
+// are settled once the attempt has ended; resume is settled until the
+// session suspends again.
+func (e *AgentExecutor) appliedControlOp(sc *sessionContext, op string) (storage.ControlOperation, bool) {
+	sc.amMu.Lock()
+	defer sc.amMu.Unlock()
+	a := sc.attempt
+	if a == nil || len(a.Operations) == 0 {
+		return storage.ControlOperation{}, false
+	}
+	last := a.Operations[len(a.Operations)-1]
+	if last.Op != op || last.Outcome != ControlOutcomeApplied {
+		return storage.ControlOperation{}, false
+	}
+	if op == ControlOpResume {
+		return last, sc.session.GetState() != domain.SessionStateSuspended
+	}
+	return last, a.EndedAt != nil
+}
+
+func (e *AgentExecutor) appendControlOp(sc *sessionContext, entry storage.ControlOperation) {
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		if sc.opSeq == 0 && len(a.Operations) > 0 {
+			sc.opSeq = a.Operations[len(a.Operations)-1].Seq
+		}
+		sc.opSeq++
+		entry.Seq = sc.opSeq
+		entry.RequestedAt = time.Now().UTC()
+		a.Operations = append(a.Operations, entry)
+	})
+}
+
+// RunAttempts returns a session's run attempts, oldest first, including
+// their control operation journals.
+func (e *AgentExecutor) RunAttempts(id string) ([]*storage.RunAttemptMetadata, error) {
+	if _, err := e.GetSession(id); err != nil {
+		return nil, err
+	}
+	if e.attemptStorage == nil {
+		return []*storage.RunAttemptMetadata{}, nil
+	}
+	return e.attemptStorage.ListRunAttempts(id)
+}
+
+// AttemptAbandoned reports whether the attempt was left open by an earlier
+// server process, so it ended no later than its last heartbeat with no
+// reason recorded. A read-only mirror cannot tell and reports none.
+func (e *AgentExecutor) AttemptAbandoned(a *storage.RunAttemptMetadata) bool {
+	return a.EndedAt == nil && a.BootID != e.bootID && !e.readOnlyMirror
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_RepeatedCancelReplaysJournal(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "journal", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "journal", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if err := executor.CancelRun(context.Background(), "journal"); err != nil {
+		t.Fatalf("first CancelRun: %v", err)
+	}
+	if err := executor.CancelRun(context.Background(), "journal"); err != nil {
+		t.Fatalf("repeated CancelRun should replay the recorded outcome, got %v", err)
+	}
+	if err := executor.StopSession(context.Background(), "journal"); err != nil {
+		t.Fatalf("StopSession: %v", err)
+	}
+	if err := executor.KillSession("journal"); err != nil {
+		t.Fatalf("KillSession: %v", err)
+	}
+	if err := executor.CancelRun(context.Background(), "journal"); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("cancel after other operations = %v, want ErrInvalidState", err)
+	}
+
+	attempt := waitForRunAttempt(t, store, "journal", true)
+	if attempt.TerminalReason != "cancelled" {
+		t.Fatalf("terminal reason = %q, want cancelled", attempt.TerminalReason)
+	}
+	want := []struct {
+		op, outcome string
+		replayed    bool
+	}{
+		{ControlOpCancel, ControlOutcomeApplied, false},
+		{ControlOpCancel, ControlOutcomeApplied, true},
+		{ControlOpStop, ControlOutcomeNoop, false},
+		{ControlOpKill, ControlOutcomeNoop, false},
+		{ControlOpCancel, ControlOutcomeRejected, false},
+	}
+	if len(attempt.Operations) != len(want) {
+		t.Fatalf("journal = %+v, want %d entries", attempt.Operations, len(want))
+	}
+	for i, w := range want {
+		got := attempt.Operations[i]
+		if got.Seq != int64(i+1) || got.Op != w.op || got.Outcome != w.outcome || got.Replayed != w.replayed {
+			t.Errorf("journal[%d] = %+v, want seq %d %s/%s replayed=%v", i, got, i+1, w.op, w.outcome, w.replayed)
+		}
+	}
+
+	attempts, err := executor.RunAttempts("journal")
+	if err != nil || len(attempts) != 1 {
+		t.Fatalf("RunAttempts = %d, %v", len(attempts), err)
+	}
+	sess, _ := executor.GetSession("journal")
+	if state := sess.GetState(); state != domain.SessionStateIdle {
+		t.Fatalf("state = %s, want idle", state)
+	}
+}
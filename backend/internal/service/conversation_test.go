@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// conversationProvider records the config of each run and ends a run when
+// its events channel is closed.
+type conversationProvider struct {
+	*mockProvider
+	mu      sync.Mutex
+	configs []session.Config
+	runs    []chan domain.Event
+}
+
+func (p *conversationProvider) SendInput(ctx context.Context, config session.Config, input string) (<-chan domain.Event, error) {
+	events := make(chan domain.Event, 10)
+	p.mu.Lock()
+	p.configs = append(p.configs, config)
+	p.runs = append(p.runs, events)
+	p.mu.Unlock()
+	return events, nil
+}
+
+func (p *conversationProvider) run(i int) (session.Config, chan domain.Event, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i >= len(p.runs) {
+		return session.Config{}, nil, false
+	}
+	return p.configs[i], p.runs[i], true
+}
+
+func TestAgentExecutor_ContinuesProviderConversation(t *testing.T) {
+	prov := &conversationProvider{mockProvider: newMockProvider()}
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "conv", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "conv", "first", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	var (
+		config session.Config
+		events chan domain.Event
+	)
+	waitFor(t, func() bool {
+		var ok bool
+		config, events, ok = prov.run(0)
+		return ok
+	})
+	if config.ConversationID != "" {
+		t.Fatalf("first run conversation = %q, want none", config.ConversationID)
+	}
+	events <- domain.NewMetadataEvent("conv", domain.MetadataKeyConversationID, "claude-abc", nil)
+	close(events)
+
+	sess, _ := executor.GetSession("conv")
+	waitFor(t, func() bool {
+		return sess.ConversationFor("mock") == "claude-abc" && sess.GetState() == domain.SessionStateIdle
+	})
+
+	if _, err := executor.SendMessage(context.Background(), "conv", "second", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitFor(t, func() bool {
+		var ok bool
+		config, events, ok = prov.run(1)
+		return ok
+	})
+	if config.ConversationID != "claude-abc" {
+		t.Fatalf("second run conversation = %q, want claude-abc", config.ConversationID)
+	}
+	close(events)
+
+	if sess.ConversationFor("other") != "" {
+		t.Fatal("a conversation should not carry over to another provider type")
+	}
+}
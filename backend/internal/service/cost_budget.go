@@ -0,0 +1,260 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// costBudgetResumePrompt continues a run suspended over its cost budget on
+// a provider that cannot resume runs.
+const costBudgetResumePrompt = "The cost budget has been raised. Continue the task where you left off."
+
+// projectCosts keeps the usage counters and cost budgets of projects, or of
+// missions. The counters are persisted, batched by usageSaveDelay, so a
+// project's spend survives deleting its sessions.
+type projectCosts struct {
+	mu      sync.Mutex
+	store   *storage.ProjectUsageStorage
+	usage   storage.ProjectUsage
+	budgets map[string]domain.CostBudget
+	saver   *debouncedSave
+}
+
+func newProjectCosts(store *storage.ProjectUsageStorage) *projectCosts {
+	p := &projectCosts{
+		store:   store,
+		usage:   storage.ProjectUsage{},
+		budgets: make(map[string]domain.CostBudget),
+	}
+	if store != nil {
+		if loaded, err := store.Load(); err != nil {
+			log.Printf("project usage: %v", err)
+		} else {
+			p.usage = loaded
+		}
+	}
+	p.saver = newDebouncedSave(usageSaveDelay, p.save)
+	return p
+}
+
+func (p *projectCosts) save() {
+	if p.store == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.store.Save(p.usage); err != nil {
+		log.Printf("project usage: %v", err)
+	}
+}
+
+func (p *projectCosts) add(projectID string, data domain.MetricData) {
+	if projectID == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	u := p.usage[projectID]
+	u.InputTokens += data.TokensIn
+	u.OutputTokens += data.TokensOut
+	u.CostUSD += data.CostUSD
+	p.usage[projectID] = u
+	if p.store != nil {
+		p.saver.mark()
+	}
+}
+
+func (p *projectCosts) get(projectID string) (domain.UsageStats, *domain.CostBudget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var budget *domain.CostBudget
+	if b, ok := p.budgets[projectID]; ok {
+		budget = &b
+	}
+	return p.usage[projectID], budget
+}
+
+// ProjectUsageReport is the usage of a project against its cost budget.
+type ProjectUsageReport struct {
+	// Usage counts every session the project ever had.
+	Usage  domain.UsageStats
+	Budget *domain.CostBudget
+	// Exceeded names the budget limit reached, if any.
+	Exceeded string
+	// Sessions is the usage of the project's current sessions, most
+	// expensive first.
+	Sessions []UsageTotal
+}
+
+// ProjectUsage reports the usage of a project and its sessions.
+func (e *AgentExecutor) ProjectUsage(projectID string) ProjectUsageReport {
+	usage, budget := e.projectCosts.get(projectID)
+	report := ProjectUsageReport{Usage: usage, Budget: budget, Sessions: []UsageTotal{}}
+	report.Exceeded, _ = budget.Exceeded(usage)
+	for _, sess := range e.ListSessions() {
+		if sess.ProjectID != projectID {
+			continue
+		}
+		report.Sessions = append(report.Sessions, UsageTotal{Label: sess.ID, Sessions: 1, UsageStats: sess.GetUsage()})
+	}
+	slices.SortFunc(report.Sessions, func(a, b UsageTotal) int {
+		return cmp.Or(
+			cmp.Compare(b.CostUSD, a.CostUSD),
+			cmp.Compare(b.InputTokens+b.OutputTokens, a.InputTokens+a.OutputTokens),
+			cmp.Compare(a.Label, b.Label),
+		)
+	})
+	return report
+}
+
+// SetProjectCostBudget limits what the project's sessions may spend
+// together; a nil budget lifts the limit. Sessions suspended over the
+// project's budget resume once it allows them to.
+func (e *AgentExecutor) SetProjectCostBudget(projectID string, budget *domain.CostBudget) error {
+	if err := budget.Validate(); err != nil {
+		return err
+	}
+	e.projectCosts.mu.Lock()
+	if budget == nil {
+		delete(e.projectCosts.budgets, projectID)
+	} else {
+		e.projectCosts.budgets[projectID] = *budget
+	}
+	e.projectCosts.mu.Unlock()
+
+	e.resumeWithinCostBudget(func(sess *domain.Session) bool { return sess.ProjectID == projectID })
+	return nil
+}
+
+// SetSessionCostBudget limits what the session may spend across its runs;
+// a nil budget lifts the limit. A session suspended over its budget resumes
+// once it allows it to.
+func (e *AgentExecutor) SetSessionCostBudget(id string, budget *domain.CostBudget) (*domain.Session, error) {
+	if err := budget.Validate(); err != nil {
+		return nil, err
+	}
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	sess.SetCostBudget(budget)
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	e.resumeWithinCostBudget(func(s *domain.Session) bool { return s.ID == id })
+	return sess, nil
+}
+
+// costBudgetExceeded reports the first budget of sess that is used up: the
+// session's own, then its project's, then its mission's. scope is
+// "session", "project" or "mission", and limit describes the limit reached.
+func (e *AgentExecutor) costBudgetExceeded(sess *domain.Session) (scope, limit string, budget *domain.CostBudget) {
+	if b := sess.GetCostBudget(); b != nil {
+		if limit, ok := b.Exceeded(sess.GetUsage()); ok {
+			return "session", limit, b
+		}
+	}
+	if sess.ProjectID != "" {
+		usage, b := e.projectCosts.get(sess.ProjectID)
+		if limit, ok := b.Exceeded(usage); ok {
+			return "project", limit, b
+		}
+	}
+	if missionID := sess.GetMissionID(); missionID != "" {
+		usage, b := e.missionCosts.get(missionID)
+		if limit, ok := b.Exceeded(usage); ok {
+			return "mission", limit, b
+		}
+	}
+	return "", "", nil
+}
+
+// checkCostBudget rejects starting a run of sess once it, its project or its
+// mission used up its cost budget.
+func (e *AgentExecutor) checkCostBudget(sess *domain.Session) error {
+	if scope, limit, budget := e.costBudgetExceeded(sess); budget != nil {
+		return fmt.Errorf("%w: the %s used up its cost budget of %s", ErrInvalidState, scope, limit)
+	}
+	return nil
+}
+
+// checkCostBudgetOfRun stops the session's current run once it, its project
+// or its mission used up its cost budget.
+func (e *AgentExecutor) checkCostBudgetOfRun(sc *sessionContext) {
+	run := sc.getRun()
+	if run == nil || sc.session.GetState() != domain.SessionStateRunning {
+		return
+	}
+	scope, limit, budget := e.costBudgetExceeded(sc.session)
+	if budget == nil || sc.costStopped.Swap(run) == run {
+		return
+	}
+	suspend := budget.SuspendsRuns()
+	e.wg.Go(func() { e.stopRunOverCostBudget(sc, run, scope, limit, suspend) })
+}
+
+// stopRunOverCostBudget stops a run over a cost budget. A suspended run
+// keeps a resume token and continues once the budget is raised; a cancelled
+// one ends as "cost_budget_exceeded" and drops the queued messages.
+func (e *AgentExecutor) stopRunOverCostBudget(sc *sessionContext, run *session.Run, scope, limit string, suspend bool) {
+	if sc.getRun() != run {
+		return
+	}
+	if suspend {
+		e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+			a.WaitKind = domain.WaitKindCostBudget
+			a.WaitRef = scope
+			a.ResumeTokenID = e.mintResumeTokenForAttempt(a)
+			a.HeartbeatAt = time.Now().UTC()
+		})
+	}
+
+	run.Cancel()
+	if err := run.Session.Kill(); err != nil {
+		log.Printf("session %s: failed to stop run over its cost budget: %v", sc.session.ID, err)
+	}
+
+	e.closeTerminalHub(sc.session.ID)
+	if suspend {
+		e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeCostBudgetSuspended, "scope", scope, "limit", limit), time.Now())
+		e.finalizeRunAttempt(sc, "interrupted", "cost budget used up")
+		e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitCostBudget, "scope", scope, "limit", limit))
+		return
+	}
+	reason := domain.NewNotice(domain.NoticeStatusCostBudget, "scope", scope, "limit", limit)
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeCostBudgetCancelled, "scope", scope, "limit", limit), time.Now())
+	e.discardQueuedMessages(sc, "cost budget used up")
+	e.finalizeRunAttempt(sc, "cost_budget_exceeded", reason.Text())
+	e.transitionWithSave(sc, domain.SessionStateIdle, reason)
+}
+
+// resumeWithinCostBudget resumes the suspended sessions matching match
+// whose run was stopped over a cost budget that now allows it.
+func (e *AgentExecutor) resumeWithinCostBudget(match func(*domain.Session) bool) {
+	if e.readOnlyMirror {
+		return
+	}
+	for _, sess := range e.ListSessions() {
+		if !match(sess) || sess.GetState() != domain.SessionStateSuspended {
+			continue
+		}
+		if _, _, budget := e.costBudgetExceeded(sess); budget != nil {
+			continue
+		}
+		_, err := e.resumeWaitingRun(e.ctx, sess.ID, domain.WaitKindCostBudget,
+			domain.NewNotice(domain.NoticeCostBudgetResumed), domain.NewNotice(domain.NoticeStatusResumed), costBudgetResumePrompt)
+		if err != nil {
+			log.Printf("session %s: resuming within cost budget: %v", sess.ID, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_SessionCostBudgetSuspendsRun(t *testing.T) {
+	executor, store, provs := newRecordingExecutor(t, ExecutorConfig{}, newMockProvider)
+
+	sess, err := executor.CreateSession(context.Background(), "budget", session.Config{
+		ProviderType: "test", WorkingDir: "/tmp",
+		CostBudget: &domain.CostBudget{LimitUSD: 1},
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), sess.ID, "work on it", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	provs()[0].SendEvent(domain.NewMetricDataEvent(sess.ID, domain.MetricData{TokensIn: 100, TokensOut: 10, CostUSD: 0.6}, nil))
+	provs()[0].SendEvent(domain.NewMetricDataEvent(sess.ID, domain.MetricData{CostUSD: 0.5}, nil))
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateSuspended })
+
+	attempt := waitForRunAttempt(t, store, sess.ID, true)
+	if attempt.WaitKind != domain.WaitKindCostBudget || attempt.ResumeTokenID == "" {
+		t.Fatalf("expected a cost budget wait with a resume token, got %+v", attempt)
+	}
+	if err := executor.checkCostBudget(sess); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected new runs to be rejected over the budget, got %v", err)
+	}
+
+	sc, _ := executor.ensureSessionContext(sess.ID)
+	waitFor(t, func() bool { return sc.getRun() == nil })
+	if _, err := executor.SetSessionCostBudget(sess.ID, &domain.CostBudget{LimitUSD: 0.5}); err != nil {
+		t.Fatalf("SetSessionCostBudget failed: %v", err)
+	}
+	if sess.GetState() != domain.SessionStateSuspended {
+		t.Fatalf("a budget that is still used up must not resume the run, got %s", sess.GetState())
+	}
+	if _, err := executor.SetSessionCostBudget(sess.ID, &domain.CostBudget{LimitUSD: 5}); err != nil {
+		t.Fatalf("SetSessionCostBudget failed: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	if last := lastUserMessage(sess); last != costBudgetResumePrompt {
+		t.Errorf("expected the run to continue with the resume prompt, got %q", last)
+	}
+
+	if _, err := executor.SetSessionCostBudget(sess.ID, &domain.CostBudget{LimitUSD: 1, Action: "pause"}); err == nil {
+		t.Error("expected an unknown action to be rejected")
+	}
+}
+
+func TestAgentExecutor_ProjectCostBudgetCancelsRun(t *testing.T) {
+	dir := t.TempDir()
+	executor, _, provs := newRecordingExecutor(t, ExecutorConfig{ProjectUsage: storage.NewProjectUsageStorage(dir)}, newMockProvider)
+
+	if err := executor.SetProjectCostBudget("proj", &domain.CostBudget{LimitTokens: 1000, Action: domain.CostBudgetCancel}); err != nil {
+		t.Fatalf("SetProjectCostBudget failed: %v", err)
+	}
+	sess, _ := executor.CreateSession(context.Background(), "spender", session.Config{ProviderType: "test", WorkingDir: "/tmp", ProjectID: "proj"})
+	if _, err := executor.SendMessage(context.Background(), sess.ID, "work on it", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	provs()[0].SendEvent(domain.NewMetricDataEvent(sess.ID, domain.MetricData{TokensIn: 900, TokensOut: 200, CostUSD: 0.25}, nil))
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateIdle })
+
+	report := executor.ProjectUsage("proj")
+	if report.Usage.InputTokens != 900 || report.Usage.CostUSD != 0.25 || report.Exceeded != "1000 tokens" {
+		t.Fatalf("unexpected project usage %+v", report)
+	}
+	if len(report.Sessions) != 1 || report.Sessions[0].Label != sess.ID {
+		t.Fatalf("expected the project's session in the report, got %+v", report.Sessions)
+	}
+
+	other, _ := executor.CreateSession(context.Background(), "other", session.Config{ProviderType: "test", WorkingDir: "/tmp", ProjectID: "proj"})
+	if _, err := executor.SendMessage(context.Background(), other.ID, "hi", "", ""); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected runs over the project budget to be rejected, got %v", err)
+	}
+
+	executor.projectCosts.saver.flush()
+	usage, err := storage.NewProjectUsageStorage(dir).Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if usage["proj"].OutputTokens != 200 {
+		t.Fatalf("expected the project usage to be persisted, got %+v", usage)
+	}
+}
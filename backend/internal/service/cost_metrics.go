@@ -0,0 +1,115 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+const (
+	// DefaultCostMetricsTopSessions is how many sessions CostMetrics reports
+	// on their own unless told otherwise.
+	DefaultCostMetricsTopSessions = 10
+
+	// OtherSessionsLabel labels the usage of the sessions outside the top
+	// N, and NoProjectLabel the usage of sessions without a project.
+	OtherSessionsLabel = "other"
+	NoProjectLabel     = "none"
+)
+
+// UsageTotal is the usage of the sessions sharing a label.
+type UsageTotal struct {
+	Label    string
+	Sessions int
+	domain.UsageStats
+}
+
+// CostMetrics is the token and cost usage of every session, totalled per
+// project, per provider type, and for the sessions that spent the most.
+// Sessions beyond the top N are folded into one OtherSessionsLabel total,
+// so the number of session labels stays bounded.
+type CostMetrics struct {
+	Projects  []UsageTotal
+	Providers []UsageTotal
+	Sessions  []UsageTotal
+}
+
+func (e *AgentExecutor) recordUsage(sc *sessionContext, data domain.MetricData) {
+	if data.TokensIn == 0 && data.TokensOut == 0 && data.CostUSD == 0 {
+		return
+	}
+	sc.session.RecordUsage(data.TokensIn, data.TokensOut, data.CostUSD)
+	e.projectCosts.add(sc.session.ProjectID, data)
+	e.missionCosts.add(sc.session.GetMissionID(), data)
+}
+
+// CostMetrics totals the usage of every session, reporting the topSessions
+// sessions with the highest cost, then tokens, individually.
+func (e *AgentExecutor) CostMetrics(topSessions int) CostMetrics {
+	if topSessions < 0 {
+		topSessions = 0
+	}
+	projects := make(map[string]*UsageTotal)
+	providers := make(map[string]*UsageTotal)
+	var sessions []UsageTotal
+	for _, sess := range e.ListSessions() {
+		usage := sess.GetUsage()
+		if usage == (domain.UsageStats{}) {
+			continue
+		}
+		snap := sess.Snapshot()
+		project := snap.ProjectID
+		if project == "" {
+			project = NoProjectLabel
+		}
+		addUsage(projects, project, usage)
+		addUsage(providers, snap.ProviderType, usage)
+		sessions = append(sessions, UsageTotal{Label: snap.ID, Sessions: 1, UsageStats: usage})
+	}
+
+	slices.SortFunc(sessions, func(a, b UsageTotal) int {
+		return cmp.Or(
+			cmp.Compare(b.CostUSD, a.CostUSD),
+			cmp.Compare(b.InputTokens+b.OutputTokens, a.InputTokens+a.OutputTokens),
+			cmp.Compare(a.Label, b.Label),
+		)
+	})
+	if len(sessions) > topSessions {
+		other := UsageTotal{Label: OtherSessionsLabel}
+		for _, s := range sessions[topSessions:] {
+			other.Sessions++
+			other.InputTokens += s.InputTokens
+			other.OutputTokens += s.OutputTokens
+			other.CostUSD += s.CostUSD
+		}
+		sessions = append(sessions[:topSessions:topSessions], other)
+	}
+
+	return CostMetrics{
+		Projects:  sortedUsage(projects),
+		Providers: sortedUsage(providers),
+		Sessions:  sessions,
+	}
+}
+
+func addUsage(totals map[string]*UsageTotal, label string, usage domain.UsageStats) {
+	t, ok := totals[label]
+	if !ok {
+		t = &UsageTotal{Label: label}
+		totals[label] = t
+	}
+	t.Sessions++
+	t.InputTokens += usage.InputTokens
+	t.OutputTokens += usage.OutputTokens
+	t.CostUSD += usage.CostUSD
+}
+
+func sortedUsage(totals map[string]*UsageTotal) []UsageTotal {
+	out := make([]UsageTotal, 0, len(totals))
+	for _, t := range totals {
+		out = append(out, *t)
+	}
+	slices.SortFunc(out, func(a, b UsageTotal) int { return cmp.Compare(a.Label, b.Label) })
+	return out
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_CostMetrics(t *testing.T) {
+	prov := newMockProvider()
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	for _, s := range []struct {
+		id, project string
+	}{{"cheap", "web"}, {"pricey", "web"}, {"loose", ""}} {
+		if _, err := executor.CreateSession(context.Background(), s.id, session.Config{ProviderType: "mock", WorkingDir: "/tmp/test", ProjectID: s.project}); err != nil {
+			t.Fatalf("create %s: %v", s.id, err)
+		}
+	}
+	if _, err := executor.SendMessage(context.Background(), "pricey", "go", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	pricey, _ := executor.GetSession("pricey")
+	waitFor(t, func() bool { return pricey.GetState() == domain.SessionStateRunning })
+	prov.SendEvent(domain.NewMetricDataEvent("pricey", domain.MetricData{TokensIn: 1000, TokensOut: 200, CostUSD: 0.5}, nil))
+	waitFor(t, func() bool { return pricey.GetUsage().CostUSD == 0.5 })
+
+	cheap, _ := executor.GetSession("cheap")
+	cheap.RecordUsage(100, 10, 0.01)
+	loose, _ := executor.GetSession("loose")
+	loose.RecordUsage(50, 5, 0)
+
+	metrics := executor.CostMetrics(1)
+	if len(metrics.Sessions) != 2 || metrics.Sessions[0].Label != "pricey" {
+		t.Fatalf("sessions = %+v, want pricey then other", metrics.Sessions)
+	}
+	if other := metrics.Sessions[1]; other.Label != OtherSessionsLabel || other.Sessions != 2 || other.InputTokens != 150 || other.CostUSD != 0.01 {
+		t.Fatalf("other = %+v", other)
+	}
+	if len(metrics.Projects) != 2 || metrics.Projects[0].Label != NoProjectLabel || metrics.Projects[1].Label != "web" || metrics.Projects[1].OutputTokens != 210 {
+		t.Fatalf("projects = %+v", metrics.Projects)
+	}
+	if len(metrics.Providers) != 1 || metrics.Providers[0].Label != "mock" || metrics.Providers[0].Sessions != 3 {
+		t.Fatalf("providers = %+v", metrics.Providers)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// usageSaveDelay is how long usage counters wait after a change before they
+// are written, so a burst of events costs one write.
+const usageSaveDelay = 2 * time.Second
+
+// debouncedSave runs save once per delay after the first of a burst of
+// changes, and on flush.
+type debouncedSave struct {
+	mu    sync.Mutex
+	delay time.Duration
+	save  func()
+	timer *time.Timer
+}
+
+func newDebouncedSave(delay time.Duration, save func()) *debouncedSave {
+	return &debouncedSave{delay: delay, save: save}
+}
+
+// mark schedules a save unless one is already pending.
+func (d *debouncedSave) mark() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.delay, d.fire)
+	}
+}
+
+func (d *debouncedSave) fire() {
+	d.mu.Lock()
+	d.timer = nil
+	d.mu.Unlock()
+	d.save()
+}
+
+// flush runs a pending save now.
+func (d *debouncedSave) flush() {
+	d.mu.Lock()
+	timer := d.timer
+	d.timer = nil
+	d.mu.Unlock()
+	if timer != nil && timer.Stop() {
+		d.save()
+	}
+}
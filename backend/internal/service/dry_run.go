@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// dryRunSessionID stands in for the ID of the session a dry run does not
+// create.
+const dryRunSessionID = "dry-run"
+
+// redactedValue replaces secrets in a dry run's config.
+const redactedValue = "[redacted]"
+
+// SessionDryRun is what creating a session from a config and starting its
+// first run would do, resolved without doing either.
+type SessionDryRun struct {
+	// Config is the config the first run's provider would be built with:
+	// the session's prompt prefix, provider custom settings, rendered MCP
+	// servers and injected environment. Secrets are redacted.
+	Config session.Config
+	// Capabilities is nil when the provider type is not registered.
+	Capabilities *session.Capabilities
+	// Problems are why the session could not be created or its first run
+	// would be rejected. A dry run without problems would start.
+	Problems []string
+	// Warnings are surprises that do not stop the run.
+	Warnings []string
+}
+
+// DryRunSession resolves config the way CreateSession and the session's
+// first run would and checks it against the session factory and the
+// executor's policies. Nothing is created or saved, and no tokens are
+// minted: MCP server and git credential tokens appear redacted.
+func (e *AgentExecutor) DryRunSession(config session.Config, missionID string) SessionDryRun {
+	var result SessionDryRun
+	if e.draining.Load() {
+		result.Problems = append(result.Problems, ErrExecutorShutdown.Error())
+	}
+	if e.readOnlyMirror {
+		result.Problems = append(result.Problems, ErrReadOnlyMirror.Error())
+	}
+	if config.Workspace != nil {
+		ws := *config.Workspace
+		if ws.Mode == domain.WorkspaceWorktree {
+			ref := ws.BaseRef
+			if ref == "" {
+				ref = "HEAD"
+			}
+			if _, err := resolveCommit(context.Background(), ws.Repo, ref); err != nil {
+				result.Problems = append(result.Problems, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err).Error())
+			}
+		}
+		// The checkout is not made; runs would start where it would be.
+		ws.Path = filepath.Join(e.workspaceDir, dryRunSessionID)
+		config = workspaceConfig(config, &ws)
+	}
+
+	sess := newSessionFromConfig(dryRunSessionID, config)
+	sess.SetMissionID(missionID)
+	run := e.runConfig(dryRunSessionID, sess, config.ProviderType)
+	if e.gitCredConfig.enabled() {
+		maps.Copy(run.Environment, e.gitCredConfig.env(redactedValue))
+	}
+	if len(run.MCPServers) > 0 {
+		env := e.mcpEnv(sess)
+		env.APIToken = redactedValue
+		if servers, err := session.RenderMCPServers(run.MCPServers, env); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("mcp_servers are passed unrendered: %v", err))
+		} else {
+			run.MCPServers = servers
+		}
+	}
+	if dropped := droppedEnvKeys(config.Environment, run.Environment); len(dropped) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("environment %s is not passed to runs", strings.Join(dropped, ", ")))
+	}
+
+	// A run whose runner cannot be built fails the same way; runner
+	// constructors only allocate.
+	var runner session.Session
+	if e.sessionFactory != nil && config.ProviderType != "" {
+		runner, _ = e.sessionFactory(config.ProviderType, "", run)
+	}
+	if runner == nil {
+		result.Problems = append(result.Problems, fmt.Sprintf("%v: %q", ErrProviderNotFound, config.ProviderType))
+	} else {
+		caps := runner.Capabilities()
+		result.Capabilities = &caps
+	}
+	e.mu.RLock()
+	policyErrs := []error{
+		e.workingDirConflict(dryRunSessionID, sess.WorkingDir, sess.ProviderCustom),
+		e.checkWorkingHours(sess, time.Now()),
+		e.checkCostBudget(sess),
+	}
+	e.mu.RUnlock()
+	for _, err := range policyErrs {
+		if err != nil {
+			result.Problems = append(result.Problems, err.Error())
+		}
+	}
+
+	result.Config = redactConfig(run)
+	return result
+}
+
+// droppedEnvKeys lists the keys of requested that are missing from run.
+func droppedEnvKeys(requested, run map[string]string) []string {
+	var dropped []string
+	for k := range requested {
+		if _, ok := run[k]; !ok {
+			dropped = append(dropped, k)
+		}
+	}
+	slices.Sort(dropped)
+	return dropped
+}
+
+// redactConfig replaces the secrets of a run config: environment and
+// custom values whose names look secret, and values that look like keys or
+// tokens.
+func redactConfig(config session.Config) session.Config {
+	config.Environment = redactEnv(config.Environment)
+	if config.Custom != nil {
+		custom := make(map[string]any, len(config.Custom))
+		for k, v := range config.Custom {
+			if s, ok := v.(string); ok && isSecret(k, s) {
+				v = redactedValue
+			}
+			custom[k] = v
+		}
+		config.Custom = custom
+	}
+	servers := make([]session.MCPServerConfig, len(config.MCPServers))
+	for i, s := range config.MCPServers {
+		s.Env = redactEnv(s.Env)
+		servers[i] = s
+	}
+	if config.MCPServers != nil {
+		config.MCPServers = servers
+	}
+	return config
+}
+
+func redactEnv(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		if isSecret(k, v) {
+			v = redactedValue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// isSecret reports whether a setting named name holds a secret, by its name
+// or by its value matching a secret guardrail rule.
+func isSecret(name, value string) bool {
+	upper := strings.ToUpper(name)
+	for _, word := range []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "APIKEY", "API_KEY"} {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	if upper == "KEY" || strings.HasSuffix(upper, "_KEY") {
+		return true
+	}
+	for _, rule := range builtinGuardrailRules {
+		if rule.category == domain.GuardrailCategorySecret && rule.re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// MaxEmbedTokenTTL caps how long an embed token stays valid.
+const MaxEmbedTokenTTL = 365 * 24 * time.Hour
+
+var (
+	ErrEmbedTokensDisabled = errors.New("embed tokens are not configured")
+	ErrInvalidEmbedToken   = errors.New("invalid embed token")
+	ErrInvalidEmbedRequest = errors.New("invalid embed token request")
+)
+
+// EmbedTokenRequest describes a new embed token. A zero TTL never expires.
+type EmbedTokenRequest struct {
+	Label   string
+	Origins []string
+	TTL     time.Duration
+}
+
+// CreateEmbedToken mints a read-only token for the session's embed
+// endpoints. The secret is returned once; only its hash is stored.
+func (e *AgentExecutor) CreateEmbedToken(sessionID, actor string, req EmbedTokenRequest) (string, storage.EmbedToken, error) {
+	if e.embedTokens == nil {
+		return "", storage.EmbedToken{}, ErrEmbedTokensDisabled
+	}
+	if _, err := e.GetSession(sessionID); err != nil {
+		return "", storage.EmbedToken{}, err
+	}
+	if req.TTL < 0 || req.TTL > MaxEmbedTokenTTL {
+		return "", storage.EmbedToken{}, fmt.Errorf("%w: ttl must be between 0 and %s", ErrInvalidEmbedRequest, MaxEmbedTokenTTL)
+	}
+	origins := make([]string, 0, len(req.Origins))
+	for _, raw := range req.Origins {
+		origin, err := normalizeEmbedOrigin(raw)
+		if err != nil {
+			return "", storage.EmbedToken{}, err
+		}
+		origins = append(origins, origin)
+	}
+
+	var buf [32]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", storage.EmbedToken{}, fmt.Errorf("failed to generate embed token: %w", err)
+	}
+	secret := "ome_" + base64.RawURLEncoding.EncodeToString(buf[:])
+	now := time.Now().UTC()
+	token := storage.EmbedToken{
+		ID:        newEmbedTokenID(),
+		SessionID: sessionID,
+		Hash:      hashEmbedToken(secret),
+		Label:     strings.TrimSpace(req.Label),
+		Origins:   origins,
+		CreatedBy: actor,
+		CreatedAt: now,
+	}
+	if req.TTL > 0 {
+		expires := now.Add(req.TTL)
+		token.ExpiresAt = &expires
+	}
+	if err := e.embedTokens.Add(token); err != nil {
+		return "", storage.EmbedToken{}, err
+	}
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    "embed_token_create",
+		SessionID: sessionID,
+		Targets:   []string{token.ID},
+		Reason:    token.Label,
+	})
+	return secret, token, nil
+}
+
+// EmbedTokens lists the session's embed tokens, revoked ones included.
+func (e *AgentExecutor) EmbedTokens(sessionID string) ([]storage.EmbedToken, error) {
+	if e.embedTokens == nil {
+		return nil, ErrEmbedTokensDisabled
+	}
+	return e.embedTokens.List(sessionID)
+}
+
+// RevokeEmbedToken revokes one of the session's embed tokens.
+func (e *AgentExecutor) RevokeEmbedToken(sessionID, id, actor string) (storage.EmbedToken, error) {
+	if e.embedTokens == nil {
+		return storage.EmbedToken{}, ErrEmbedTokensDisabled
+	}
+	token, err := e.embedTokens.Revoke(sessionID, id)
+	if err != nil {
+		return storage.EmbedToken{}, err
+	}
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    "embed_token_revoke",
+		SessionID: sessionID,
+		Targets:   []string{id},
+	})
+	return token, nil
+}
+
+// ResolveEmbedToken returns the active token matching secret.
+func (e *AgentExecutor) ResolveEmbedToken(secret string) (storage.EmbedToken, error) {
+	if e.embedTokens == nil || !strings.HasPrefix(secret, "ome_") {
+		return storage.EmbedToken{}, ErrInvalidEmbedToken
+	}
+	token, err := e.embedTokens.FindByHash(hashEmbedToken(secret))
+	if err != nil || !token.Active(time.Now()) {
+		return storage.EmbedToken{}, ErrInvalidEmbedToken
+	}
+	return token, nil
+}
+
+func hashEmbedToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmbedOrigin reduces raw to scheme://host[:port], the form
+// browsers send in the Origin header.
+func normalizeEmbedOrigin(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: %q must be an http(s) origin", ErrInvalidEmbedRequest, raw)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+		return "", fmt.Errorf("%w: %q must not have a path, query or credentials", ErrInvalidEmbedRequest, raw)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+func newEmbedTokenID() string {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// ErrEventLogDisabled is returned for event history when no event log is
+// configured.
+var ErrEventLogDisabled = errors.New("event log is not configured")
+
+// EventLogEnabled reports whether session events are journaled, so event
+// streams can resume from journal offsets.
+func (e *AgentExecutor) EventLogEnabled() bool {
+	return e.eventLog != nil
+}
+
+// EventHistory returns up to limit of the session's persisted events after
+// sinceSeq, oldest first, and whether more follow.
+func (e *AgentExecutor) EventHistory(id string, sinceSeq int64, limit int) ([]storage.StoredEvent, bool, error) {
+	if e.eventLog == nil {
+		return nil, false, ErrEventLogDisabled
+	}
+	if _, err := e.GetSession(id); err != nil {
+		return nil, false, err
+	}
+	return e.eventLog.Since(id, sinceSeq, limit)
+}
+
+// dropEventLog deletes the session's persisted events.
+func (e *AgentExecutor) dropEventLog(id string) error {
+	if e.eventLog == nil {
+		return nil
+	}
+	return e.eventLog.Delete(id)
+}
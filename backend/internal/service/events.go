@@ -2,6 +2,7 @@ package service
 
 import (
 	"log"
+	"slices"
 	"sync"
 
 	"github.com/ricochet1k/orbitmesh/internal/domain"
@@ -13,6 +14,13 @@ type Subscriber struct {
 	Events    chan domain.Event
 }
 
+// EventLog persists events beyond the broadcaster's in-memory replay
+// window. Append returns the event's offset in its session's journal, set
+// as the broadcast event's Seq.
+type EventLog interface {
+	Append(event domain.Event) (int64, error)
+}
+
 type EventBroadcaster struct {
 	subscribers   map[string]*Subscriber
 	mu            sync.RWMutex
@@ -22,6 +30,7 @@ type EventBroadcaster struct {
 	historySize   int
 	nextID        int64
 	droppedEvents int64
+	eventLog      EventLog
 }
 
 func NewEventBroadcaster(bufferSize int) *EventBroadcaster {
@@ -36,6 +45,13 @@ func NewEventBroadcaster(bufferSize int) *EventBroadcaster {
 	}
 }
 
+// SetEventLog persists every broadcast event except liveness events to log.
+func (b *EventBroadcaster) SetEventLog(log EventLog) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.eventLog = log
+}
+
 func (b *EventBroadcaster) Subscribe(subscriberID, sessionID string) *Subscriber {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -71,6 +87,13 @@ func (b *EventBroadcaster) Broadcast(event domain.Event) {
 
 	b.nextID++
 	event.ID = b.nextID
+	if b.eventLog != nil && event.SessionID != "" && event.Type != domain.EventTypeLiveness {
+		seq, err := b.eventLog.Append(event)
+		if err != nil {
+			log.Printf("event log: session %s: %v", event.SessionID, err)
+		}
+		event.Seq = seq
+	}
 	b.appendHistoryLocked(event)
 
 	for _, sub := range b.subscribers {
@@ -87,6 +110,18 @@ func (b *EventBroadcaster) Broadcast(event domain.Event) {
 	}
 }
 
+// DropHistory forgets a session's events kept for replay, so reconnecting
+// subscribers no longer receive them.
+func (b *EventBroadcaster) DropHistory(sessionID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.history, sessionID)
+	b.globalHistory = slices.DeleteFunc(b.globalHistory, func(event domain.Event) bool {
+		return event.SessionID == sessionID
+	})
+}
+
 func (b *EventBroadcaster) DroppedEventCount() int64 {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -141,7 +176,9 @@ func (b *EventBroadcaster) replayLocked(sessionID string, lastEventID, maxID int
 }
 
 func (b *EventBroadcaster) appendHistoryLocked(event domain.Event) {
-	if b.historySize <= 0 {
+	// Liveness events are only meaningful live and would crowd real events
+	// out of the replay window.
+	if b.historySize <= 0 || event.Type == domain.EventTypeLiveness {
 		return
 	}
 	history := append(b.history[event.SessionID], event)
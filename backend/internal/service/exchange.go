@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// ExchangeAgent is the writer recorded for values set by a session's agent.
+const ExchangeAgent = "agent"
+
+// SetExchangeEntry stores value under key in the session's exchange area.
+func (e *AgentExecutor) SetExchangeEntry(id, key string, value json.RawMessage, updatedBy string) (domain.ExchangeEntry, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return domain.ExchangeEntry{}, err
+	}
+	entry, err := sess.SetExchangeEntry(key, value, updatedBy)
+	if err != nil {
+		return domain.ExchangeEntry{}, err
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return domain.ExchangeEntry{}, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return entry, nil
+}
+
+// DeleteExchangeEntry removes key from the session's exchange area and
+// reports whether it was set.
+func (e *AgentExecutor) DeleteExchangeEntry(id, key string) (bool, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return false, err
+	}
+	if !sess.DeleteExchangeEntry(key) {
+		return false, nil
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return true, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return true, nil
+}
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"maps"
+	"strings"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/storage"
@@ -28,8 +33,14 @@ func (e *AgentExecutor) handleEvents(ctx context.Context, sc *sessionContext, ru
 
 	checkpointTicker := time.NewTicker(e.checkpointInterval)
 	defer checkpointTicker.Stop()
+	progressTicker := time.NewTicker(e.progressInterval)
+	defer progressTicker.Stop()
+	livenessTicker := time.NewTicker(e.livenessInterval)
+	defer livenessTicker.Stop()
 
 	var checkpointMu sync.Mutex
+	progress := &progressEstimator{}
+	liveness := newLivenessTracker(time.Now())
 
 	for {
 		select {
@@ -42,12 +53,44 @@ func (e *AgentExecutor) handleEvents(ctx context.Context, sc *sessionContext, ru
 					checkpointMu.Unlock()
 				})
 			}
+		case <-progressTicker.C:
+			e.publishProgress(sc, progress)
+		case <-livenessTicker.C:
+			e.publishLiveness(sc, liveness)
 		case event, ok := <-events:
 			if !ok {
 				return
 			}
+			event = e.screenEvent(sc, event)
 			e.broadcaster.Broadcast(event)
+			progress.observe(event)
+			liveness.observe(event)
 			e.updateSessionFromEvent(sc, event)
+			if ref, ok := externalToolWait(event); ok {
+				refs := []string{ref}
+				// Parallel tool calls arrive back to back; take the ones
+				// already delivered so the run waits on all of them.
+				for drained := false; !drained; {
+					select {
+					case event, ok := <-events:
+						if !ok {
+							drained = true
+							continue
+						}
+						event = e.screenEvent(sc, event)
+						e.broadcaster.Broadcast(event)
+						progress.observe(event)
+						liveness.observe(event)
+						e.updateSessionFromEvent(sc, event)
+						if ref, ok := externalToolWait(event); ok {
+							refs = append(refs, ref)
+						}
+					default:
+						drained = true
+					}
+				}
+				e.suspendSession(sc, refs...)
+			}
 		}
 	}
 }
@@ -56,7 +99,7 @@ func (e *AgentExecutor) checkpointSession(sc *sessionContext) {
 	if e.storage == nil || sc == nil || sc.session == nil {
 		return
 	}
-	_ = e.storage.Save(sc.session)
+	_ = e.saveSession(sc.session)
 	e.touchRunAttempt(sc)
 }
 
@@ -69,9 +112,15 @@ func (e *AgentExecutor) StopSession(ctx context.Context, id string) error {
 		return ErrSessionNotFound
 	}
 
+	return e.journalControl(sc, ControlOpStop, func() (string, error) {
+		return e.stopSession(ctx, sc)
+	})
+}
+
+func (e *AgentExecutor) stopSession(ctx context.Context, sc *sessionContext) (string, error) {
 	currentState := sc.session.GetState()
 	if currentState == domain.SessionStateIdle {
-		return nil
+		return ControlOutcomeNoop, nil
 	}
 
 	if currentState == domain.SessionStateRunning || currentState == domain.SessionStateSuspended {
@@ -84,14 +133,16 @@ func (e *AgentExecutor) StopSession(ctx context.Context, id string) error {
 			stopErr = run.Session.Stop(stopCtx)
 			run.Cancel()
 		}
-		e.closeTerminalHub(id)
+		e.closeTerminalHub(sc.session.ID)
+		e.discardQueuedMessages(sc, "session stopped")
 		e.finalizeRunAttempt(sc, "cancelled", "session stopped")
-		e.transitionWithSave(sc, domain.SessionStateIdle, "session stopped")
+		e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusStopped))
+		e.startTerminationHooks(sc, HookTriggerStop)
 
-		return stopErr
+		return ControlOutcomeApplied, stopErr
 	}
 
-	return nil
+	return ControlOutcomeNoop, nil
 }
 
 func (e *AgentExecutor) KillSession(id string) error {
@@ -103,23 +154,31 @@ func (e *AgentExecutor) KillSession(id string) error {
 		return ErrSessionNotFound
 	}
 
+	return e.journalControl(sc, ControlOpKill, func() (string, error) {
+		return e.killSession(sc)
+	})
+}
+
+func (e *AgentExecutor) killSession(sc *sessionContext) (string, error) {
 	currentState := sc.session.GetState()
 	if currentState == domain.SessionStateIdle {
-		return nil
+		return ControlOutcomeNoop, nil
 	}
 
 	run := sc.getRun()
 	if run != nil {
 		if err := run.Session.Kill(); err != nil {
-			return fmt.Errorf("failed to kill provider: %w", err)
+			return "", fmt.Errorf("failed to kill provider: %w", err)
 		}
 		run.Cancel()
 	}
 
-	e.closeTerminalHub(id)
+	e.closeTerminalHub(sc.session.ID)
+	e.discardQueuedMessages(sc, "session killed")
 	e.finalizeRunAttempt(sc, "interrupted", "session killed")
-	e.transitionWithSave(sc, domain.SessionStateIdle, "session killed")
-	return nil
+	e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusKilled))
+	e.startTerminationHooks(sc, HookTriggerKill)
+	return ControlOutcomeApplied, nil
 }
 
 func (e *AgentExecutor) CancelRun(ctx context.Context, id string) error {
@@ -131,24 +190,44 @@ func (e *AgentExecutor) CancelRun(ctx context.Context, id string) error {
 		return ErrSessionNotFound
 	}
 
+	return e.journalControl(sc, ControlOpCancel, func() (string, error) {
+		return e.cancelRun(sc)
+	})
+}
+
+func (e *AgentExecutor) cancelRun(sc *sessionContext) (string, error) {
 	currentState := sc.session.GetState()
 	if currentState == domain.SessionStateIdle {
-		return fmt.Errorf("%w: session is already idle", ErrInvalidState)
+		if reason := e.lastTerminalReason(sc); reason != "" {
+			return "", fmt.Errorf("%w: session is already idle (last run %s)", ErrInvalidState, reason)
+		}
+		return "", fmt.Errorf("%w: session is already idle", ErrInvalidState)
 	}
 
 	run := sc.getRun()
 	if run != nil {
 		run.Cancel()
 		if err := run.Session.Kill(); err != nil {
-			return fmt.Errorf("failed to cancel provider: %w", err)
+			return "", fmt.Errorf("failed to cancel provider: %w", err)
 		}
 	}
 
-	e.closeTerminalHub(id)
-	e.appendSessionMessage(sc.session, domain.MessageKindSystem, "Run cancelled by user", time.Now())
+	e.closeTerminalHub(sc.session.ID)
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeRunCancelled), time.Now())
+	e.discardQueuedMessages(sc, "run cancelled")
 	e.finalizeRunAttempt(sc, "cancelled", "run cancelled by user")
-	e.transitionWithSave(sc, domain.SessionStateIdle, "run cancelled by user")
-	return nil
+	e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusCancelled))
+	return ControlOutcomeApplied, nil
+}
+
+// lastTerminalReason reports how the current attempt ended, if it has.
+func (e *AgentExecutor) lastTerminalReason(sc *sessionContext) string {
+	sc.amMu.Lock()
+	defer sc.amMu.Unlock()
+	if sc.attempt == nil || sc.attempt.EndedAt == nil {
+		return ""
+	}
+	return sc.attempt.TerminalReason
 }
 
 func (e *AgentExecutor) ResumeSession(ctx context.Context, id string) (*domain.Session, error) {
@@ -168,47 +247,69 @@ func (e *AgentExecutor) resumeSessionValidated(ctx context.Context, id string, t
 		return nil, err
 	}
 
+	err = e.journalControl(sc, ControlOpResume, func() (string, error) {
+		if err := e.resumeSession(ctx, sc, tokenID); err != nil {
+			return "", err
+		}
+		return ControlOutcomeApplied, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// A resume without a continuation leaves the session idle.
+	e.deliverQueuedMessages(sc)
+	return sc.session, nil
+}
+
+func (e *AgentExecutor) resumeSession(ctx context.Context, sc *sessionContext, tokenID string) error {
+	id := sc.session.ID
 	if tokenID == "" {
 		currentState := sc.session.GetState()
 		if currentState != domain.SessionStateSuspended {
-			return nil, fmt.Errorf("%w: session is not suspended (current state: %s)", ErrInvalidState, currentState)
+			return fmt.Errorf("%w: session is not suspended (current state: %s)", ErrInvalidState, currentState)
 		}
 
 		suspensionCtx := sc.session.GetSuspensionContext()
 		if suspensionCtx == nil {
-			return nil, fmt.Errorf("no suspension context found for session %s", id)
+			return fmt.Errorf("no suspension context found for session %s", id)
 		}
 
 		providerSuspensionCtx, ok := suspensionCtx.(*session.SuspensionContext)
 		if !ok {
-			return nil, fmt.Errorf("invalid suspension context type")
+			return fmt.Errorf("invalid suspension context type")
 		}
 
 		run := sc.getRun()
 		if run != nil {
 			suspendable, supportsResume := run.Session.(session.Suspendable)
 			if !supportsResume {
-				return nil, fmt.Errorf("provider does not support resumption")
+				return fmt.Errorf("provider does not support resumption")
 			}
 			if err := suspendable.Resume(ctx, providerSuspensionCtx); err != nil {
-				return nil, fmt.Errorf("failed to resume provider: %w", err)
+				return fmt.Errorf("failed to resume provider: %w", err)
 			}
 		}
 
 		sc.session.SetSuspensionContext(nil)
-		e.transitionWithSave(sc, domain.SessionStateRunning, "resumed from suspension")
-		return sc.session, nil
+		e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusResumed))
+		return nil
 	}
 
 	attempt, err := e.latestPersistedAttempt(id)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if attempt == nil {
-		return nil, ErrInvalidResumeToken
+		return ErrInvalidResumeToken
 	}
 	if err := e.validateAndConsumeResumeToken(id, tokenID, attempt); err != nil {
-		return nil, err
+		return err
+	}
+	if len(attempt.Waits) > 0 {
+		waiting, err := e.fulfillRunWait(sc, attempt, tokenID)
+		if err != nil || waiting {
+			return err
+		}
 	}
 
 	if attempt != nil {
@@ -216,15 +317,19 @@ func (e *AgentExecutor) resumeSessionValidated(ctx context.Context, id string, t
 		attempt.WaitKind = ""
 		attempt.WaitRef = ""
 		attempt.ResumeTokenID = ""
+		attempt.Waits = nil
+		attempt.WaitQuorum = 0
 		attempt.HeartbeatAt = now
 		if err := e.attemptStorage.SaveRunAttempt(attempt); err != nil {
-			return nil, fmt.Errorf("failed to clear waiting metadata: %w", err)
+			return fmt.Errorf("failed to clear waiting metadata: %w", err)
 		}
 		sc.amMu.Lock()
 		if sc.attempt != nil && sc.attempt.AttemptID == attempt.AttemptID {
 			sc.attempt.WaitKind = ""
 			sc.attempt.WaitRef = ""
 			sc.attempt.ResumeTokenID = ""
+			sc.attempt.Waits = nil
+			sc.attempt.WaitQuorum = 0
 			sc.attempt.HeartbeatAt = now
 		}
 		sc.amMu.Unlock()
@@ -238,27 +343,27 @@ func (e *AgentExecutor) resumeSessionValidated(ctx context.Context, id string, t
 			suspendable, supportsResume := run.Session.(session.Suspendable)
 			if supportsResume {
 				if err := suspendable.Resume(ctx, providerSuspensionCtx); err != nil {
-					return nil, fmt.Errorf("failed to resume provider: %w", err)
+					return fmt.Errorf("failed to resume provider: %w", err)
 				}
 				sc.session.SetSuspensionContext(nil)
-				e.transitionWithSave(sc, domain.SessionStateRunning, "resumed from suspension")
-				return sc.session, nil
+				e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusResumed))
+				return nil
 			}
 		}
 	}
 
 	sc.session.SetSuspensionContext(nil)
 	if sc.session.GetState() == domain.SessionStateSuspended {
-		e.transitionWithSave(sc, domain.SessionStateIdle, "resume token accepted; provider continuation unavailable")
+		e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusNoContinuation))
 	}
-	e.appendSessionMessage(sc.session, domain.MessageKindSystem, "[resume] Resume token accepted. Provider continuation is unavailable; send a new message to continue.", time.Now())
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeResumeUnavailable), time.Now())
 	if e.storage != nil {
-		if err := e.storage.Save(sc.session); err != nil {
-			return nil, fmt.Errorf("failed to save session: %w", err)
+		if err := e.saveSession(sc.session); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
 		}
 	}
 
-	return sc.session, nil
+	return nil
 }
 
 func (e *AgentExecutor) validateAndConsumeResumeToken(sessionID, tokenID string, attempt *storage.RunAttemptMetadata) error {
@@ -276,7 +381,7 @@ func (e *AgentExecutor) validateAndConsumeResumeToken(sessionID, tokenID string,
 	if token.SessionID != sessionID || token.AttemptID != attempt.AttemptID {
 		return ErrInvalidResumeToken
 	}
-	if attempt.ResumeTokenID == "" || attempt.ResumeTokenID != tokenID {
+	if !attemptHoldsToken(attempt, tokenID) {
 		return ErrInvalidResumeToken
 	}
 	if token.ConsumedAt != nil {
@@ -322,12 +427,44 @@ func (e *AgentExecutor) ensureSessionContext(id string) (*sessionContext, error)
 	return sc, nil
 }
 
-func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess *domain.Session, content string, providerID string, providerType string) (*domain.Session, error) {
+func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess *domain.Session, content string, providerID string, providerType string, opts SendMessageOptions) (*domain.Session, error) {
+	taskContext := e.taskContext(ctx, sess, opts.resume)
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if sc, exists := e.sessions[id]; exists && sc.getRun() != nil {
-		return sess, fmt.Errorf("session is already running")
+	if e.draining.Load() {
+		return sess, ErrExecutorShutdown
+	}
+	if e.readOnlyMirror {
+		return sess, ErrReadOnlyMirror
+	}
+	if e.removing[id] {
+		return sess, fmt.Errorf("%w: session is being removed", ErrInvalidState)
+	}
+	if err := checkImported(sess); err != nil {
+		return sess, err
+	}
+	if err := checkTakeover(sess, opts.Actor); err != nil {
+		return sess, err
+	}
+	if sc, exists := e.sessions[id]; exists {
+		// A retry that raced its original is answered by the original's run.
+		if _, ok := sc.attemptMessageReceipt(opts.MessageID); ok {
+			return sess, nil
+		}
+		if sc.getRun() != nil {
+			return sess, fmt.Errorf("%w: session is already running", ErrInvalidState)
+		}
+	}
+	if err := e.workingDirConflict(id, sess.WorkingDir, sess.ProviderCustom); err != nil {
+		return sess, err
+	}
+	if err := e.checkWorkingHours(sess, time.Now()); err != nil {
+		return sess, err
+	}
+	if err := e.checkCostBudget(sess); err != nil {
+		return sess, err
 	}
 
 	pType := sess.ProviderType
@@ -338,41 +475,75 @@ func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess
 	if providerID != "" {
 		sess.SetPreferredProviderID(providerID)
 		if e.storage != nil {
-			if err := e.storage.Save(sess); err != nil {
+			if err := e.saveSession(sess); err != nil {
 				return sess, fmt.Errorf("failed to save session with provider preference: %w", err)
 			}
 		}
 	}
 
-	config := session.Config{
-		ProviderType: pType,
-		WorkingDir:   sess.WorkingDir,
-		ProjectID:    sess.ProjectID,
-		SessionKind:  sess.Kind,
-		Title:        sess.Title,
-		Custom:       sess.ProviderCustom,
+	config := e.runConfig(id, sess, pType)
+	if taskContext != "" {
+		// The prompt no longer matches any warm runner's, so one is not
+		// claimed.
+		config.SystemPrompt = strings.TrimSpace(config.SystemPrompt + "\n\n" + taskContext)
 	}
-
-	prov, err := e.sessionFactory(pType, id, config)
-	if err != nil {
-		return sess, fmt.Errorf("%w: %s", ErrProviderNotFound, pType)
+	var (
+		prov session.Session
+		err  error
+	)
+	if key, ok := warmConfigKey(config); ok && !opts.resume {
+		if w := e.warm.claim(id, pType, key); w != nil {
+			prov, config = w.runner, w.config
+		}
+	}
+	if prov == nil {
+		maps.Copy(config.Environment, e.gitCredentialEnv(id))
+		e.renderMCPServers(sess, &config)
+		prov, err = e.sessionFactory(pType, id, config)
+		if err != nil {
+			return sess, fmt.Errorf("%w: %s", ErrProviderNotFound, pType)
+		}
+	}
+	resumer, canResume := prov.(session.RunResumer)
+	if opts.resume && !canResume {
+		return sess, fmt.Errorf("%w: %s", ErrResumeUnsupported, pType)
 	}
 
 	if _, exists := e.sessions[id]; !exists {
 		e.sessions[id] = &sessionContext{session: sess, run: nil}
 	}
 	sc := e.sessions[id]
-	e.startRunAttempt(sc, pType, providerID)
+
+	if batch, ok := prov.(session.BatchRunner); ok && batchSuitable(sess, opts) {
+		return e.startQueuedRemoteRun(sc, batch, config, content, providerID, opts.MessageID)
+	}
+
+	e.startRunAttempt(sc, pType, providerID, opts.MessageID)
+	runCtx := e.startRunSpan(ctx, sc, pType, opts.resume)
 
 	run := session.NewProviderRun(prov, e.ctx)
 	sc.setRun(run)
+	budget := e.startRunBudget(sc, run, content, opts)
 
-	e.appendSessionMessage(sess, domain.MessageKindUser, content, time.Now())
+	if !opts.resume && !opts.retry {
+		e.appendSessionMessage(sess, domain.MessageKindUser, content, time.Now())
+	}
 	if e.storage != nil {
-		_ = e.storage.Save(sess)
+		_ = e.saveSession(sess)
 	}
 
 	e.wg.Go(func() {
+		// Deferred first so it runs last, after afterRun or the budget
+		// may have started a follow-up run.
+		defer e.deliverQueuedMessages(sc)
+		if budget != nil {
+			defer e.finishRunBudget(sc, budget)
+		}
+		completed := false
+		if opts.afterRun != nil {
+			defer func() { opts.afterRun(completed) }()
+		}
+		defer e.trackGitCommits(sc)
 		defer func() {
 			if r := recover(); r != nil {
 				e.handlePanic(sc, r)
@@ -383,8 +554,17 @@ func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess
 
 		startCtx, startCancel := context.WithTimeout(run.Ctx, e.opTimeout)
 		defer startCancel()
-
-		events, err := run.Session.SendInput(startCtx, config, content)
+		_, startSpan := tracer.Start(runCtx, "provider.start", trace.WithAttributes(attribute.String("provider.type", pType)))
+		startCtx = trace.ContextWithSpan(startCtx, startSpan)
+
+		var events <-chan domain.Event
+		if opts.resume {
+			events, err = resumer.ResumeRun(startCtx, config)
+		} else {
+			events, err = run.Session.SendInput(startCtx, config, content)
+		}
+		endSpan(startSpan, err)
+		e.markMessageDelivery(sc, err)
 		if err != nil {
 			errMsg := fmt.Sprintf("Provider failed to start: %v", err)
 			log.Printf("SESSION START FAILED: %v", errMsg)
@@ -393,7 +573,7 @@ func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess
 			run.SetError(err)
 
 			if e.storage != nil {
-				_ = e.storage.Save(sc.session)
+				_ = e.saveSession(sc.session)
 			}
 
 			e.broadcaster.Broadcast(domain.NewErrorEvent(id, errMsg, "SESSION_START_FAILED", nil))
@@ -405,15 +585,20 @@ func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess
 		}
 
 		run.MarkActive()
-		e.transitionWithSave(sc, domain.SessionStateRunning, "session started")
+		e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusStarted))
 		e.ensureTerminalHubForPTY(sc)
+		if opts.Deadline > 0 {
+			e.wg.Go(func() { e.watchRunDeadline(sc, run, opts.Deadline) })
+		}
 
 		e.wg.Add(1)
 		e.handleEvents(run.Ctx, sc, run, events)
 
 		if run.Ctx.Err() == nil {
-			e.finalizeRunAttempt(sc, "completed", "")
-			e.transitionWithSave(sc, domain.SessionStateIdle, "session run completed")
+			reason, notice := budget.completion()
+			e.finalizeRunAttempt(sc, reason, "")
+			e.transitionWithSave(sc, domain.SessionStateIdle, notice)
+			completed = sc.session.GetState() == domain.SessionStateIdle
 		}
 
 		e.mu.Lock()
@@ -424,28 +609,54 @@ func (e *AgentExecutor) startRunWithMessage(ctx context.Context, id string, sess
 	return sess, nil
 }
 
-func (e *AgentExecutor) transitionWithSave(sc *sessionContext, newState domain.SessionState, reason string) {
+// runConfig builds the provider config for a run of sess, without the
+// per-run git credentials and with its MCP servers' env unrendered. The run
+// continues the conversation of the session's last run of pType.
+func (e *AgentExecutor) runConfig(id string, sess *domain.Session, pType string) session.Config {
+	config := session.Config{
+		ProviderType: pType,
+		WorkingDir:   sess.WorkingDir,
+		ProjectID:    sess.ProjectID,
+		SessionKind:  sess.Kind,
+		Title:        sess.Title,
+		SystemPrompt: sess.GetPromptPrefix(),
+		Custom:       runProviderCustom(sess),
+		Features:     sess.GetFeatures(),
+		MCPServers:   mcpServersFromDomain(sess.GetMCPServers()),
+		// Lets tools the agent runs, such as the exchange MCP server, find
+		// their session.
+		Environment: map[string]string{"ORBITMESH_SESSION_ID": id},
+	}
+	config.ConversationID = sess.ConversationFor(pType)
+	return config
+}
+
+func (e *AgentExecutor) transitionWithSave(sc *sessionContext, newState domain.SessionState, notice domain.Notice) {
 	oldState := sc.session.GetState()
 
-	if err := sc.session.TransitionTo(newState, reason); err != nil {
+	if err := sc.session.TransitionWithNotice(newState, notice); err != nil {
 		return
 	}
 
 	if e.storage != nil {
-		_ = e.storage.Save(sc.session)
+		_ = e.saveSession(sc.session)
 	}
 
-	e.broadcastStateChange(sc.session, oldState, newState, reason)
+	sc.traceStateChange(oldState, newState, notice)
+	e.broadcastStateChange(sc.session, oldState, newState, notice)
 }
 
-func (e *AgentExecutor) broadcastStateChange(session *domain.Session, oldState, newState domain.SessionState, reason string) {
-	event := domain.NewStatusChangeEvent(session.ID, oldState, newState, reason, nil)
+func (e *AgentExecutor) broadcastStateChange(session *domain.Session, oldState, newState domain.SessionState, notice domain.Notice) {
+	event := domain.NewNoticeStatusChangeEvent(session.ID, oldState, newState, notice)
 	e.broadcaster.Broadcast(event)
 }
 
-func (e *AgentExecutor) suspendSession(sc *sessionContext, toolCallID string) {
+// suspendSession suspends the run until its pending external tool calls are
+// fulfilled. With several calls each gets its own resume token; see
+// resumeRunWait.
+func (e *AgentExecutor) suspendSession(sc *sessionContext, toolCallIDs ...string) {
 	run := sc.getRun()
-	if sc == nil || sc.session == nil || run == nil {
+	if sc == nil || sc.session == nil || run == nil || len(toolCallIDs) == 0 {
 		return
 	}
 
@@ -459,17 +670,26 @@ func (e *AgentExecutor) suspendSession(sc *sessionContext, toolCallID string) {
 		return
 	}
 
-	if suspensionCtx != nil && toolCallID != "" {
-		suspensionCtx.ToolCallID = toolCallID
+	refs := strings.Join(toolCallIDs, ", ")
+	if suspensionCtx != nil {
+		suspensionCtx.ToolCallID = toolCallIDs[0]
+		if len(toolCallIDs) > 1 {
+			suspensionCtx.ToolCallIDs = toolCallIDs
+		}
 	}
 
-	e.markRunAttemptWaiting(sc, "tool_call", toolCallID)
-	e.finalizeRunAttempt(sc, "interrupted", fmt.Sprintf("waiting for tool result: %s", toolCallID))
+	if len(toolCallIDs) == 1 {
+		e.markRunAttemptWaiting(sc, domain.WaitKindToolCall, toolCallIDs[0])
+	} else {
+		e.markRunAttemptWaitingOnAll(sc, toolCallIDs, waitQuorum(sc.session.ProviderCustom, len(toolCallIDs)))
+	}
+	notice := domain.NewNotice(domain.NoticeWaitToolCall, "refs", refs)
+	e.finalizeRunAttempt(sc, "interrupted", notice.Text())
 	sc.session.SetSuspensionContext(suspensionCtx)
-	_ = sc.session.TransitionTo(domain.SessionStateSuspended, fmt.Sprintf("waiting for tool result: %s", toolCallID))
+	_ = sc.session.TransitionWithNotice(domain.SessionStateSuspended, notice)
 
 	if e.storage != nil {
-		_ = e.storage.Save(sc.session)
+		_ = e.saveSession(sc.session)
 	}
 
 	if run := sc.getRun(); run != nil {
@@ -478,15 +698,16 @@ func (e *AgentExecutor) suspendSession(sc *sessionContext, toolCallID string) {
 }
 
 func (e *AgentExecutor) handlePanic(sc *sessionContext, r any) {
-	errMsg := fmt.Sprintf("Panic recovered: %v", r)
+	notice := domain.NewNotice(domain.NoticeRunPanicked, "panic", fmt.Sprint(r))
+	errMsg := notice.Text()
 	log.Printf("PANIC: %v", errMsg)
 
-	e.appendSessionMessage(sc.session, domain.MessageKindError, errMsg, time.Now())
+	e.appendNotice(sc.session, domain.MessageKindError, notice, time.Now())
 	e.finalizeRunAttempt(sc, "failed", errMsg)
-	_ = sc.session.TransitionTo(domain.SessionStateIdle, errMsg)
+	_ = sc.session.TransitionWithNotice(domain.SessionStateIdle, notice)
 
 	if e.storage != nil {
-		_ = e.storage.Save(sc.session)
+		_ = e.saveSession(sc.session)
 	}
 
 	event := domain.NewErrorEvent(sc.session.ID, errMsg, "PANIC", nil)
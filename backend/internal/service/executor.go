@@ -4,9 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 	"github.com/ricochet1k/orbitmesh/internal/storage"
@@ -38,6 +46,19 @@ type sessionContext struct {
 	runMu   sync.RWMutex
 	attempt *storage.RunAttemptMetadata
 	amMu    sync.Mutex
+	ctlMu   sync.Mutex // serialises journaled control operations
+	queueMu sync.Mutex // serialises delivering queued messages
+	opSeq   int64      // last control operation sequence number
+	stderr  stderrCapture
+	// budget tracks the current run's token budget, if it has one.
+	budget atomic.Pointer[runBudget]
+	// costStopped is the last run stopped over a cost budget.
+	costStopped atomic.Pointer[session.Run]
+	// tracing holds the current run's spans.
+	tracing runTrace
+	// guardrailTail is the end of the recent streamed output the
+	// guardrails scan each delta with.
+	guardrailTail guardrailTail
 }
 
 func (sc *sessionContext) getRun() *session.Run {
@@ -67,6 +88,9 @@ type AgentExecutor struct {
 	sessionFactory     SessionFactory
 	opTimeout          time.Duration
 	checkpointInterval time.Duration
+	progressInterval   time.Duration
+	livenessInterval   time.Duration
+	batchPollInterval  time.Duration
 	terminalHubs       map[string]*TerminalHub
 	terminalObservers  map[int64]TerminalObserver
 	terminalObserverID int64
@@ -77,6 +101,103 @@ type AgentExecutor struct {
 
 	recovery *recoveryManager
 
+	cleanupPolicy CleanupPolicy
+	cleanupMu     sync.Mutex
+	lastCleanup   *CleanupReport
+	// removing holds the sessions being removed, which no run may start.
+	// Guarded by mu.
+	removing map[string]bool
+
+	workingDirLock bool
+
+	hookTimeout time.Duration
+	hookWG      sync.WaitGroup
+
+	defaultRecovery  RecoveryPolicy
+	recoveryPolicies map[string]RecoveryPolicy
+
+	toolStats *toolStatsTracker
+	auditLog  *storage.AuditLogStorage
+	readState *readStateTracker
+	questions *questionTracker
+	suggest   *suggestIndex
+
+	commandWaiters *commandWaiters
+	// toolApprovalWaiters wakes the runs waiting on tool approvals.
+	toolApprovalWaiters *commandWaiters
+
+	gitCredConfig  GitCredentialConfig
+	gitCredentials *gitCredentialTracker
+	// pullRequestForges is ExecutorConfig.PullRequestForges.
+	pullRequestForges map[string]PullRequestForge
+
+	// apiBaseURL and apiTokens are what runs' MCP server env templates get
+	// as APIBaseURL and APIToken.
+	apiBaseURL string
+	apiTokens  *gitCredentialTracker
+
+	warm *warmPool
+
+	guardrails *guardrails
+
+	toolPolicies *toolPolicies
+
+	projectCosts *projectCosts
+
+	embedTokens *storage.EmbedTokenStorage
+
+	workingHours         *workingHoursPolicies
+	workingHoursInterval time.Duration
+
+	watches       *watchPolicies
+	watchInterval time.Duration
+
+	changes *sessionChangeLog
+
+	health    *providerHealth
+	startedAt time.Time
+
+	tasks       TaskSource
+	taskJournal *taskJournal
+
+	eventLog *storage.EventLogStorage
+
+	schedules        *storage.ScheduleStorage
+	scheduleInterval time.Duration
+	// scheduleMu serializes changes to schedules with their runs.
+	scheduleMu sync.Mutex
+
+	// bestOfNMu serializes starting and deciding best-of-N groups.
+	bestOfNMu sync.Mutex
+
+	missions     *storage.MissionStorage
+	missionCosts *projectCosts
+	// missionMu serializes changes to missions with their members.
+	missionMu sync.Mutex
+
+	pipelines *storage.PipelineStorage
+	// pipelineMu serializes changes to pipelines with their runs.
+	pipelineMu sync.Mutex
+
+	templates *storage.TemplateStorage
+	// templateMu serializes changes to prompt templates.
+	templateMu sync.Mutex
+
+	providerConfigs *storage.ProviderConfigStorage
+	agentConfigs    *storage.AgentConfigStorage
+	projects        *storage.ProjectStorage
+
+	workspaceDir string
+
+	// readOnlyMirror is set when the sessions are replicated from another
+	// instance; no runs are started.
+	readOnlyMirror bool
+
+	// draining is set once Shutdown starts; no new runs are accepted.
+	draining         atomic.Bool
+	shutdownTimeouts ShutdownTimeouts
+	shutdownReports  *storage.ShutdownReportStorage
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -89,9 +210,103 @@ type ExecutorConfig struct {
 	ProviderFactory    SessionFactory
 	OperationTimeout   time.Duration
 	CheckpointInterval time.Duration
+	ProgressInterval   time.Duration
+	LivenessInterval   time.Duration
+	BatchPollInterval  time.Duration
 	RunAttemptStorage  storage.RunAttemptStorage
 	ResumeTokenStorage storage.ResumeTokenStorage
 	ResumeTokenTTL     time.Duration
+	CleanupPolicy      CleanupPolicy
+	ToolStatsStorage   *storage.ToolStatsStorage
+	ReadStateStorage   *storage.ReadStateStorage
+	AuditLogStorage    *storage.AuditLogStorage
+	// RecoveryReports keeps the report of the latest startup recovery.
+	RecoveryReports *storage.RecoveryReportStorage
+	// GitCredentials enables delegated git credentials for runs; see
+	// GitCredentialConfig.
+	GitCredentials GitCredentialConfig
+	// PullRequestForges is the API host and token of each pull request
+	// provider, keyed by domain.PullRequestGitHub or PullRequestGitLab.
+	PullRequestForges map[string]PullRequestForge
+	// WorkingDirLock rejects runs in a working directory that another
+	// session is already running in, unless either session sets
+	// worktree_isolation in its provider config.
+	WorkingDirLock bool
+	// CleanupHookTimeout bounds each session cleanup command. Defaults to
+	// DefaultCleanupHookTimeout.
+	CleanupHookTimeout time.Duration
+	// RecoveryPolicy is what startup recovery does with interrupted runs
+	// (default RecoveryMarkInterrupted). RecoveryPolicies overrides it per
+	// provider type; sessions may override both.
+	RecoveryPolicy   RecoveryPolicy
+	RecoveryPolicies map[string]RecoveryPolicy
+	// WarmPool pre-starts provider runners for new sessions; see
+	// WarmPoolConfig.
+	WarmPool WarmPoolConfig
+	// Guardrails scans agent output for secrets, PII and disallowed
+	// content; see GuardrailConfig.
+	Guardrails GuardrailConfig
+	// WorkingHoursInterval is how often project working hours are
+	// enforced. Defaults to DefaultWorkingHoursInterval.
+	WorkingHoursInterval time.Duration
+	// WatchInterval is how often project watches scan for changed files.
+	// Defaults to DefaultWatchInterval.
+	WatchInterval time.Duration
+	// EmbedTokens stores the read-only tokens of session embed widgets.
+	// Embed tokens are unavailable without it.
+	EmbedTokens *storage.EmbedTokenStorage
+	// ShutdownTimeouts bounds each phase of Shutdown.
+	ShutdownTimeouts ShutdownTimeouts
+	// ShutdownReports keeps the report of the last shutdown until the next
+	// startup recovery picks it up.
+	ShutdownReports *storage.ShutdownReportStorage
+	// TaskSource reads the task a session is linked to, whose details are
+	// injected into the session's first run and resumed runs. Nil disables
+	// task context.
+	TaskSource TaskSource
+	// EventLog persists every session event for replay after a restart.
+	// Event history is unavailable without it.
+	EventLog *storage.EventLogStorage
+	// Schedules stores the schedules that start sessions on a cron
+	// expression or an interval. Schedules are unavailable without it.
+	Schedules *storage.ScheduleStorage
+	// ScheduleInterval is how often schedules are checked for due runs.
+	// Defaults to DefaultScheduleInterval.
+	ScheduleInterval time.Duration
+	// ReadOnlyMirror holds sessions replicated from another instance with
+	// MirrorSession: sessions cannot be created or run, startup recovery
+	// and background jobs are skipped, and session states are reported as
+	// the mirrored instance last saved them.
+	ReadOnlyMirror bool
+	// APIBaseURL is where agent processes reach the API, given to MCP
+	// server env templates as {{.APIBaseURL}}.
+	APIBaseURL string
+	// ProjectUsage persists the usage counters of projects, which keep
+	// counting after their sessions are deleted.
+	ProjectUsage *storage.ProjectUsageStorage
+	// TaskJournal persists the task changes made through the strand MCP
+	// tools, which can then be undone.
+	TaskJournal *storage.TaskJournalStorage
+	// Missions stores the missions that group sessions working toward one
+	// goal. Missions are unavailable without it.
+	Missions *storage.MissionStorage
+	// MissionUsage persists the usage counters of missions.
+	MissionUsage *storage.ProjectUsageStorage
+	// Pipelines stores the pipelines that run sessions as the steps of a
+	// DAG. Pipelines are unavailable without it.
+	Pipelines *storage.PipelineStorage
+	// Templates stores the prompt templates sessions and messages may
+	// reference. Templates are unavailable without it.
+	Templates *storage.TemplateStorage
+	// ProviderConfigs, AgentConfigs and Projects are what
+	// ResolveSessionRequest merges into the sessions it resolves.
+	ProviderConfigs *storage.ProviderConfigStorage
+	AgentConfigs    *storage.AgentConfigStorage
+	Projects        *storage.ProjectStorage
+	// WorkspaceDir is where sessions that ask for a workspace get their
+	// checkouts. Defaults to the workspaces directory under
+	// storage.DefaultBaseDir.
+	WorkspaceDir string
 }
 
 func NewAgentExecutor(cfg ExecutorConfig) *AgentExecutor {
@@ -107,24 +322,73 @@ func NewAgentExecutor(cfg ExecutorConfig) *AgentExecutor {
 		checkpointInterval = DefaultCheckpointInterval
 	}
 
+	progressInterval := cfg.ProgressInterval
+	if progressInterval <= 0 {
+		progressInterval = DefaultProgressInterval
+	}
+
+	livenessInterval := cfg.LivenessInterval
+	if livenessInterval <= 0 {
+		livenessInterval = DefaultLivenessInterval
+	}
+
+	batchPollInterval := cfg.BatchPollInterval
+	if batchPollInterval <= 0 {
+		batchPollInterval = DefaultBatchPollInterval
+	}
+
 	exec := &AgentExecutor{
 		sessions:           make(map[string]*sessionContext),
+		removing:           make(map[string]bool),
 		storage:            cfg.Storage,
 		terminalStorage:    cfg.TerminalStorage,
 		broadcaster:        cfg.Broadcaster,
 		sessionFactory:     cfg.ProviderFactory,
 		opTimeout:          opTimeout,
 		checkpointInterval: checkpointInterval,
+		progressInterval:   progressInterval,
+		livenessInterval:   livenessInterval,
+		batchPollInterval:  batchPollInterval,
 		terminalHubs:       make(map[string]*TerminalHub),
 		terminalObservers:  make(map[int64]TerminalObserver),
 		attemptStorage:     cfg.RunAttemptStorage,
 		resumeTokenStorage: cfg.ResumeTokenStorage,
 		bootID:             newBootID(),
 		resumeTokenTTL:     cfg.ResumeTokenTTL,
+		cleanupPolicy:      cfg.CleanupPolicy,
+		workingDirLock:     cfg.WorkingDirLock,
+		hookTimeout:        cfg.CleanupHookTimeout,
+		defaultRecovery:    cfg.RecoveryPolicy,
+		recoveryPolicies:   cfg.RecoveryPolicies,
+		toolStats:          newToolStatsTracker(cfg.ToolStatsStorage),
+		readState:          newReadStateTracker(cfg.ReadStateStorage),
+		auditLog:           cfg.AuditLogStorage,
+		questions:          newQuestionTracker(),
+		commandWaiters:     newCommandWaiters(),
+		suggest:            newSuggestIndex(),
+		gitCredConfig:      cfg.GitCredentials,
+		gitCredentials:     newGitCredentialTracker(),
+		warm:               newWarmPool(cfg.WarmPool),
+		guardrails:         newGuardrails(cfg.Guardrails),
+		workingHours:       newWorkingHoursPolicies(),
+		watches:            newWatchPolicies(),
+		embedTokens:        cfg.EmbedTokens,
+		shutdownTimeouts:   cfg.ShutdownTimeouts,
+		shutdownReports:    cfg.ShutdownReports,
+		changes:            newSessionChangeLog(),
+		health:             newProviderHealth(),
+		startedAt:          time.Now(),
+		tasks:              cfg.TaskSource,
+		eventLog:           cfg.EventLog,
+		schedules:          cfg.Schedules,
 		ctx:                ctx,
 		cancel:             cancel,
 	}
 
+	if exec.eventLog != nil && exec.broadcaster != nil {
+		exec.broadcaster.SetEventLog(exec.eventLog)
+	}
+
 	if exec.attemptStorage == nil {
 		if as, ok := cfg.Storage.(storage.RunAttemptStorage); ok {
 			exec.attemptStorage = as
@@ -141,34 +405,139 @@ func NewAgentExecutor(cfg ExecutorConfig) *AgentExecutor {
 		exec.resumeTokenTTL = 24 * time.Hour
 	}
 
-	exec.recovery = newRecoveryManager(exec)
+	if exec.hookTimeout <= 0 {
+		exec.hookTimeout = DefaultCleanupHookTimeout
+	}
+
+	exec.workingHoursInterval = cfg.WorkingHoursInterval
+	if exec.workingHoursInterval <= 0 {
+		exec.workingHoursInterval = DefaultWorkingHoursInterval
+	}
+
+	exec.watchInterval = cfg.WatchInterval
+	if exec.watchInterval <= 0 {
+		exec.watchInterval = DefaultWatchInterval
+	}
+
+	exec.scheduleInterval = cfg.ScheduleInterval
+	if exec.scheduleInterval <= 0 {
+		exec.scheduleInterval = DefaultScheduleInterval
+	}
+	exec.readOnlyMirror = cfg.ReadOnlyMirror
+	exec.toolApprovalWaiters = newCommandWaiters()
+	exec.toolPolicies = newToolPolicies()
+	exec.projectCosts = newProjectCosts(cfg.ProjectUsage)
+	exec.missions = cfg.Missions
+	exec.missionCosts = newProjectCosts(cfg.MissionUsage)
+	exec.loadMissionBudgets()
+	exec.pipelines = cfg.Pipelines
+	exec.failInterruptedPipelineRuns()
+	exec.templates = cfg.Templates
+	exec.providerConfigs = cfg.ProviderConfigs
+	exec.agentConfigs = cfg.AgentConfigs
+	exec.projects = cfg.Projects
+	exec.taskJournal = newTaskJournal(cfg.TaskJournal)
+	exec.apiBaseURL = strings.TrimRight(cfg.APIBaseURL, "/")
+	exec.apiTokens = newGitCredentialTracker()
+	exec.pullRequestForges = cfg.PullRequestForges
+	exec.workspaceDir = cfg.WorkspaceDir
+	if exec.workspaceDir == "" {
+		exec.workspaceDir = filepath.Join(storage.DefaultBaseDir(), "workspaces")
+	}
+
+	exec.recovery = newRecoveryManager(exec, cfg.RecoveryReports)
 	return exec
 }
 
 func (e *AgentExecutor) Startup(ctx context.Context) error {
-	if e == nil || e.recovery == nil {
+	if e == nil || e.recovery == nil || e.readOnlyMirror {
 		return nil
 	}
-	return e.recovery.OnStartup(ctx)
+	if err := e.recovery.OnStartup(ctx); err != nil {
+		return err
+	}
+	e.startCleanupJanitor()
+	e.startWorkingHoursEnforcer()
+	e.startWatchMode()
+	e.startScheduler()
+	return nil
 }
 
 // CreateSession creates a new session in idle state without starting a provider.
 // The session persists and waits for the first message to be sent before the provider starts.
-func (e *AgentExecutor) CreateSession(ctx context.Context, id string, config session.Config) (*domain.Session, error) {
+func (e *AgentExecutor) CreateSession(ctx context.Context, id string, config session.Config) (_ *domain.Session, err error) {
+	_, span := tracer.Start(ctx, "session.create", trace.WithAttributes(
+		attribute.String("session.id", id),
+		attribute.String("provider.type", config.ProviderType),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if config.Workspace != nil || config.GitTracking != nil {
+		// Checkouts can take a while; make them before locking.
+		if err := e.checkCanCreate(id); err != nil {
+			return nil, err
+		}
+		var ws *domain.Workspace
+		if config, ws, err = e.prepareCheckout(ctx, id, config); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				e.removeWorkspace(ctx, ws)
+			}
+		}()
+	}
+
+	start := startCommit(ctx, config.WorkingDir)
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	select {
-	case <-e.ctx.Done():
-		return nil, ErrExecutorShutdown
-	default:
+	if err := e.checkCanCreateLocked(id); err != nil {
+		return nil, err
 	}
 
+	// Create session in idle state without instantiating a provider
+	session := newSessionFromConfig(id, config)
+	session.StartCommit = start
+
+	if e.storage != nil {
+		if err := e.saveSession(session); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+
+	sc := &sessionContext{session: session, run: nil}
+	e.sessions[id] = sc
+	e.suggest.put(session)
+	e.warmSession(session)
+
+	return session, nil
+}
+
+// checkCanCreate reports why a session could not be created under id, if
+// it could not.
+func (e *AgentExecutor) checkCanCreate(id string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.checkCanCreateLocked(id)
+}
+
+func (e *AgentExecutor) checkCanCreateLocked(id string) error {
+	if e.draining.Load() {
+		return ErrExecutorShutdown
+	}
+	if e.readOnlyMirror {
+		return ErrReadOnlyMirror
+	}
 	if _, exists := e.sessions[id]; exists {
-		return nil, ErrSessionExists
+		return ErrSessionExists
 	}
+	return nil
+}
 
-	// Create session in idle state without instantiating a provider
+// newSessionFromConfig builds an idle session from its creation config.
+func newSessionFromConfig(id string, config session.Config) *domain.Session {
 	session := domain.NewSession(id, config.ProviderType, config.WorkingDir)
 	session.ProjectID = config.ProjectID
 	if config.AgentID != "" {
@@ -184,6 +553,20 @@ func (e *AgentExecutor) CreateSession(ctx context.Context, id string, config ses
 	if config.Title != "" {
 		session.SetTitle(config.Title)
 	}
+	session.SetWorkspace(config.Workspace)
+	session.SetGitTracking(config.GitTracking)
+	session.PromptPrefix = buildPromptPrefix(config.SystemPrompt, config.ProjectContext)
+	session.MCPServers = mcpServersToDomain(config.MCPServers)
+	session.ProjectPath = config.ProjectPath
+	session.CleanupCommands = config.CleanupCommands
+	session.RecoveryPolicy = config.RecoveryPolicy
+	session.PlanApproval = config.PlanApproval
+	session.SetCommandApproval(config.CommandApproval)
+	session.SetToolApproval(config.ToolApproval)
+	session.SetCostBudget(config.CostBudget)
+	session.Metadata = maps.Clone(config.Metadata)
+	session.Features = maps.Clone(config.Features)
+	session.TaskID = config.TaskID
 	if taskRef := formatTaskReference(config.TaskID, config.TaskTitle); taskRef != "" {
 		session.SetCurrentTask(taskRef)
 	}
@@ -200,17 +583,7 @@ func (e *AgentExecutor) CreateSession(ctx context.Context, id string, config ses
 		}
 		session.SetMessages(messages)
 	}
-
-	if e.storage != nil {
-		if err := e.storage.Save(session); err != nil {
-			return nil, fmt.Errorf("failed to save session: %w", err)
-		}
-	}
-
-	sc := &sessionContext{session: session, run: nil}
-	e.sessions[id] = sc
-
-	return session, nil
+	return session
 }
 
 // StartSession is deprecated. Use CreateSession for new code.
@@ -271,19 +644,75 @@ func (e *AgentExecutor) ListSessions() []*domain.Session {
 	}
 	e.mu.RUnlock()
 
-	if e.storage == nil {
-		return sessions
+	if e.storage != nil {
+		stored, _ := e.storage.List()
+		for _, session := range stored {
+			if _, exists := ids[session.ID]; exists {
+				continue
+			}
+			sessions = append(sessions, session)
+		}
 	}
 
-	stored, _ := e.storage.List()
-	for _, session := range stored {
-		if _, exists := ids[session.ID]; exists {
-			continue
+	sortSessionsForList(sessions)
+	return sessions
+}
+
+// sortSessionsForList orders pinned sessions first, then newest first.
+func sortSessionsForList(sessions []*domain.Session) {
+	type key struct {
+		pinned  bool
+		created time.Time
+	}
+	keys := make(map[*domain.Session]key, len(sessions))
+	for _, s := range sessions {
+		snap := s.Snapshot()
+		keys[s] = key{pinned: snap.Pinned, created: snap.CreatedAt}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		a, b := keys[sessions[i]], keys[sessions[j]]
+		if a.pinned != b.pinned {
+			return a.pinned
+		}
+		return a.created.After(b.created)
+	})
+}
+
+// SetSessionPinned pins or unpins a session. Pinned sessions sort first in
+// listings and are never removed by the cleanup janitor.
+func (e *AgentExecutor) SetSessionPinned(id string, pinned bool) (*domain.Session, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.SetPinned(pinned)
+	e.suggest.put(sess)
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
 		}
-		sessions = append(sessions, session)
 	}
+	return sess, nil
+}
 
-	return sessions
+// PatchSessionMetadata sets the session's metadata entries in patch, and
+// removes those whose value is nil.
+func (e *AgentExecutor) PatchSessionMetadata(id string, patch map[string]*string) (*domain.Session, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sess.PatchMetadata(patch); err != nil {
+		return nil, err
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return sess, nil
 }
 
 // DeleteProjectSessions stops all live sessions for the given project and
@@ -320,18 +749,46 @@ func (e *AgentExecutor) DeleteProjectSessions(ctx context.Context, projectID str
 		}
 		return firstErr
 	}
+	cleaner, _ := e.storage.(storage.CleanupStorage)
 	for _, s := range all {
-		if s.ProjectID == projectID {
-			if err := e.storage.Delete(s.ID); err != nil && firstErr == nil {
-				firstErr = err
-			}
+		if s.ProjectID != projectID {
+			continue
+		}
+		if err := e.removeStaleSession(cleaner, s.ID, false, time.Time{}); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
 
 	return firstErr
 }
 
-func (e *AgentExecutor) SendInput(ctx context.Context, id string, input string, providerID string, providerType string) error {
+// DeleteSession stops the session if it is running and removes it and its
+// stored history permanently.
+func (e *AgentExecutor) DeleteSession(ctx context.Context, id, actor string) error {
+	if _, err := e.GetSession(id); err != nil {
+		return err
+	}
+	if e.storage == nil {
+		return fmt.Errorf("deleting sessions requires session storage")
+	}
+	if err := e.StopSession(ctx, id); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	cleaner, _ := e.storage.(storage.CleanupStorage)
+	if err := e.removeStaleSession(cleaner, id, false, time.Time{}); err != nil {
+		return err
+	}
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    "session_delete",
+		SessionID: id,
+	})
+	return nil
+}
+
+// SendInput sends input to the session's live run. actor is the human
+// sending it; while the session is taken over only its taker may.
+func (e *AgentExecutor) SendInput(ctx context.Context, id string, input string, providerID string, providerType string, actor string) error {
 	e.mu.RLock()
 	sc, exists := e.sessions[id]
 	e.mu.RUnlock()
@@ -339,12 +796,15 @@ func (e *AgentExecutor) SendInput(ctx context.Context, id string, input string,
 	if !exists {
 		return ErrSessionNotFound
 	}
+	if err := checkTakeover(sc.session, actor); err != nil {
+		return err
+	}
 
 	// Store the provider preference if specified
 	if providerID != "" {
 		sc.session.SetPreferredProviderID(providerID)
 		if e.storage != nil {
-			if err := e.storage.Save(sc.session); err != nil {
+			if err := e.saveSession(sc.session); err != nil {
 				return fmt.Errorf("failed to save session with provider preference: %w", err)
 			}
 		}
@@ -366,10 +826,85 @@ func (e *AgentExecutor) SendInput(ctx context.Context, id string, input string,
 }
 
 // SendMessage sends a message to a session, starting a new run if the session is idle.
-// If the session is idle: resolves the provider and starts a new run with the message as first input.
-// If the session is running: returns a 409 Conflict error.
+// If the session is idle: resolves the provider and starts a new run with the message as first input,
+// unless earlier messages are still queued or the previous run is winding down, in which case it queues.
+// If the session is running: queues the message for delivery when the run ends.
 // If the session is suspended: queues the message for delivery after suspension resolves.
 func (e *AgentExecutor) SendMessage(ctx context.Context, id string, content string, providerID string, providerType string) (*domain.Session, error) {
+	return e.sendMessage(ctx, id, content, providerID, providerType, SendMessageOptions{queue: true})
+}
+
+// SendMessageOptions carries optional per-run settings for SendMessageWithOptions.
+type SendMessageOptions struct {
+	// Deadline timeboxes the run started by the message. Zero means no limit.
+	Deadline time.Duration
+	// LowPriority routes the run through the provider's batch queue when the
+	// provider supports it and the run is suitable; otherwise it runs live.
+	LowPriority bool
+	// MessageID identifies the message for its delivery receipt. A message
+	// whose ID the session already has a receipt for is not sent again, so
+	// clients can safely retry.
+	MessageID string
+	// TokenBudget caps the input and output tokens the run started by the
+	// message may use. Zero means no limit.
+	TokenBudget int64
+	// BudgetAction is what happens when the run uses up TokenBudget:
+	// domain.BudgetStop (the default) or domain.BudgetContinue.
+	BudgetAction string
+	// Actor is the human sending the message. While the session is taken
+	// over only messages from the human who took it over are sent.
+	Actor string
+
+	// budgetRequest, budgetContinuations and continuedFrom carry a token
+	// budget over to the run that continues one that used it up.
+	budgetRequest       string
+	budgetContinuations int
+	continuedFrom       string
+
+	// resume starts the run with session.RunResumer instead of sending the
+	// message content; used by startup recovery.
+	resume bool
+	// retry runs the message again without adding it to the transcript,
+	// which already holds it; used by startup recovery.
+	retry bool
+	// queue holds the message while the session is running or suspended
+	// instead of failing; set for messages sent through the public API.
+	queue bool
+	// afterRun, when set, is called once the live run has ended and the
+	// session is free to start another, with whether the run completed.
+	afterRun func(completed bool)
+}
+
+// SendMessageWithOptions behaves like SendMessage, applying opts to the run it
+// starts.
+func (e *AgentExecutor) SendMessageWithOptions(ctx context.Context, id string, content string, providerID string, providerType string, opts SendMessageOptions) (*domain.Session, error) {
+	if opts.Deadline < 0 {
+		return nil, fmt.Errorf("deadline must not be negative")
+	}
+	if opts.TokenBudget < 0 {
+		return nil, fmt.Errorf("token budget must not be negative")
+	}
+	if !domain.ValidBudgetAction(opts.BudgetAction) {
+		return nil, fmt.Errorf("unknown budget action %q", opts.BudgetAction)
+	}
+	opts.queue = true
+	return e.sendMessage(ctx, id, content, providerID, providerType, opts)
+}
+
+func (e *AgentExecutor) sendMessage(ctx context.Context, id string, content string, providerID string, providerType string, opts SendMessageOptions) (*domain.Session, error) {
+	ctx, span := tracer.Start(ctx, "session.message", trace.WithAttributes(
+		attribute.String("session.id", id),
+		attribute.String("message.id", opts.MessageID),
+		attribute.Int("message.length", len(content)),
+	))
+	sess, err := e.routeMessage(ctx, id, content, providerID, providerType, opts)
+	endSpan(span, err)
+	return sess, err
+}
+
+// routeMessage starts a run with the message, queues it or refuses it,
+// depending on the session's state.
+func (e *AgentExecutor) routeMessage(ctx context.Context, id string, content string, providerID string, providerType string, opts SendMessageOptions) (*domain.Session, error) {
 	e.mu.RLock()
 	sc, exists := e.sessions[id]
 	e.mu.RUnlock()
@@ -387,56 +922,38 @@ func (e *AgentExecutor) SendMessage(ctx context.Context, id string, content stri
 		sess = sc.session
 	}
 
+	if _, ok := e.findMessageReceipt(id, opts.MessageID); ok {
+		return sess, nil
+	}
+
 	state := sess.GetState()
 
 	// Handle based on session state
 	switch state {
 	case domain.SessionStateIdle:
+		// Messages already waiting, or a run still winding down, go first
+		if opts.queue && (len(sess.GetQueuedMessages()) > 0 || (exists && sc.getRun() != nil)) {
+			return e.queueMessage(id, sess, content, providerID, providerType, opts)
+		}
 		// For idle sessions, start a new run with this message
-		return e.startRunWithMessage(ctx, id, sess, content, providerID, providerType)
+		return e.startRunWithMessage(ctx, id, sess, content, providerID, providerType, opts)
 
 	case domain.SessionStateRunning:
-		// Session is running - reject with conflict error
-		return sess, fmt.Errorf("cannot send message to running session - session is currently running")
+		// Queue the message for delivery when the run ends
+		if opts.queue {
+			return e.queueMessage(id, sess, content, providerID, providerType, opts)
+		}
+		return sess, fmt.Errorf("%w: cannot send message to running session - session is currently running", ErrInvalidState)
 
 	case domain.SessionStateSuspended:
-		// For suspended sessions, queue the message for delivery after suspension resolves
-		// For now, we'll return an error as queueing requires additional infrastructure
-		return sess, fmt.Errorf("cannot send message to suspended session - session is waiting for a response")
+		// Queue the message for delivery after the suspension resolves
+		if opts.queue {
+			return e.queueMessage(id, sess, content, providerID, providerType, opts)
+		}
+		return sess, fmt.Errorf("%w: cannot send message to suspended session - session is waiting for a response", ErrInvalidState)
 
 	default:
-		return sess, fmt.Errorf("invalid session state: %v", state)
-	}
-}
-
-func (e *AgentExecutor) Shutdown(ctx context.Context) error {
-	e.cancel()
-
-	e.mu.RLock()
-	sessionIDs := make([]string, 0, len(e.sessions))
-	for id := range e.sessions {
-		sessionIDs = append(sessionIDs, id)
-	}
-	e.mu.RUnlock()
-
-	for _, id := range sessionIDs {
-		_ = e.StopSession(ctx, id)
-	}
-
-	done := make(chan struct{})
-	go func() {
-		e.wg.Wait()
-		close(done)
-	}()
-
-	select {
-	case <-done:
-		return nil
-	case <-ctx.Done():
-		for _, id := range sessionIDs {
-			_ = e.KillSession(id)
-		}
-		return ctx.Err()
+		return sess, fmt.Errorf("%w: %v", ErrInvalidState, state)
 	}
 }
 
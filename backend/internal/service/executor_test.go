@@ -90,6 +90,10 @@ func (m *mockProvider) Status() session.Status {
 	return session.Status{State: m.state}
 }
 
+func (m *mockProvider) Capabilities() session.Capabilities {
+	return session.Capabilities{Suspend: true, Steering: true}
+}
+
 func (m *mockProvider) SendEvent(e domain.Event) {
 	m.events <- e
 }
@@ -276,6 +280,43 @@ func createTestExecutor(prov *mockProvider) (*AgentExecutor, *mockStorage) {
 	return NewAgentExecutor(cfg), storage
 }
 
+// newRecordingExecutor returns an executor built from cfg, with a mock
+// storage and broadcaster, whose factory gives every run a fresh provider
+// from newProvider. The returned function lists the providers created so
+// far, in the order their runs started.
+func newRecordingExecutor[P session.Session](t *testing.T, cfg ExecutorConfig, newProvider func() P) (*AgentExecutor, *mockStorage, func() []P) {
+	t.Helper()
+	var mu sync.Mutex
+	var created []P
+	store := newMockStorage()
+	cfg.Storage = store
+	cfg.Broadcaster = NewEventBroadcaster(100)
+	cfg.ProviderFactory = func(providerType, sessionID string, config session.Config) (session.Session, error) {
+		p := newProvider()
+		mu.Lock()
+		created = append(created, p)
+		mu.Unlock()
+		return p, nil
+	}
+	if cfg.OperationTimeout == 0 {
+		cfg.OperationTimeout = 5 * time.Second
+	}
+	executor := NewAgentExecutor(cfg)
+	t.Cleanup(func() { _ = executor.Shutdown(context.Background()) })
+	return executor, store, func() []P {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]P(nil), created...)
+	}
+}
+
+// waitForProvider waits for the provider of the n-th run (from 0).
+func waitForProvider[P session.Session](t *testing.T, created func() []P, n int) P {
+	t.Helper()
+	waitFor(t, func() bool { return len(created()) > n })
+	return created()[n]
+}
+
 func waitForRunAttempt(t *testing.T, store *mockStorage, sessionID string, requireEnded bool) *storage.RunAttemptMetadata {
 	t.Helper()
 
@@ -1454,46 +1495,6 @@ func TestAgentExecutor_NonExistentSessionOperations(t *testing.T) {
 
 // Tests for SendMessage method
 
-func TestAgentExecutor_SendMessage_RunningSession_Error(t *testing.T) {
-	prov := newMockProvider()
-	executor, _ := createTestExecutor(prov)
-	defer executor.Shutdown(context.Background())
-
-	config := session.Config{
-		ProviderType: "mock",
-		WorkingDir:   "/tmp/test",
-	}
-
-	sess, err := executor.StartSession(context.Background(), "test-id", config)
-	if err != nil {
-		t.Fatalf("failed to create session: %v", err)
-	}
-
-	// Send first message to transition to running
-	_, err = executor.SendMessage(context.Background(), "test-id", "hello", "", "")
-	if err != nil {
-		t.Fatalf("failed to send first message: %v", err)
-	}
-
-	// Give the session time to be running
-	time.Sleep(100 * time.Millisecond)
-
-	// Try to send message to running session
-	_, err = executor.SendMessage(context.Background(), "test-id", "hello again", "", "")
-	if err == nil {
-		t.Errorf("expected error for running session, got nil")
-	}
-	if !strings.Contains(err.Error(), "running session") {
-		t.Errorf("expected 'running session' error, got: %v", err)
-	}
-
-	// Session should still exist and be in running state
-	retrieved, _ := executor.GetSession("test-id")
-	if retrieved.ID != sess.ID {
-		t.Errorf("session ID mismatch")
-	}
-}
-
 func TestAgentExecutor_SendMessage_IdleSession_OK(t *testing.T) {
 	prov := newMockProvider()
 	executor, store := createTestExecutor(prov)
@@ -1524,7 +1525,7 @@ func TestAgentExecutor_SendMessage_IdleSession_OK(t *testing.T) {
 	}
 }
 
-func TestAgentExecutor_SendMessage_SuspendedSession_Error(t *testing.T) {
+func TestAgentExecutor_SendMessage_SuspendedSession_Queues(t *testing.T) {
 	prov := newMockProvider()
 	executor, store := createTestExecutor(prov)
 	defer executor.Shutdown(context.Background())
@@ -1534,13 +1535,14 @@ func TestAgentExecutor_SendMessage_SuspendedSession_Error(t *testing.T) {
 	suspSess.State = domain.SessionStateSuspended
 	_ = store.Save(suspSess)
 
-	// Try to send message to suspended session (should error for now)
-	_, err := executor.SendMessage(context.Background(), "susp-test", "hello", "", "")
-	if err == nil {
-		t.Errorf("expected error for suspended session, got nil")
+	// The message waits for the suspension to resolve
+	sess, err := executor.SendMessage(context.Background(), "susp-test", "hello", "", "")
+	if err != nil {
+		t.Fatalf("expected the message to be queued, got: %v", err)
 	}
-	if !strings.Contains(err.Error(), "suspended") {
-		t.Errorf("expected 'suspended' error, got: %v", err)
+	queued := sess.GetQueuedMessages()
+	if len(queued) != 1 || queued[0].Content != "hello" || sess.GetState() != domain.SessionStateSuspended {
+		t.Errorf("expected one queued message on a still suspended session, got %+v in state %s", queued, sess.GetState())
 	}
 }
 
@@ -2220,6 +2222,13 @@ func TestAgentExecutor_DeleteProjectSessions(t *testing.T) {
 			t.Error("expected s-a3 to be deleted from storage")
 		}
 
+		// Live proj-a sessions should be forgotten by the executor too
+		for _, id := range []string{"s-a1", "s-a2"} {
+			if _, err := executor.GetSession(id); !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("expected %s to be removed, got %v", id, err)
+			}
+		}
+
 		// Proj-b session should still be in storage
 		if _, err := store.Load("s-b1"); err != nil {
 			t.Errorf("s-b1 should still exist: %v", err)
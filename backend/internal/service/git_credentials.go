@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+const (
+	// DefaultGitCredentialTTL is how long a session's delegated git
+	// credential token stays valid when GitCredentialConfig.TTL is unset.
+	DefaultGitCredentialTTL = time.Hour
+
+	// gitTokenCommandTimeout bounds GitCredentialConfig.TokenCommand.
+	gitTokenCommandTimeout = 30 * time.Second
+
+	AuditActionGitCredential       = "git_credential"
+	AuditActionGitCredentialDenied = "git_credential_denied"
+)
+
+var (
+	ErrGitCredentialUnauthorized = errors.New("invalid or expired git credential token")
+	ErrGitCredentialScope        = errors.New("git credential request is out of scope")
+)
+
+// GitCredentialConfig lets sessions push over HTTPS without holding the
+// upstream credential. Each run gets a short-lived token bound to its
+// session, and git's credential helper trades it for the upstream
+// credential at the helper endpoint, one audited request at a time.
+type GitCredentialConfig struct {
+	// Host is the git host credentials are issued for, e.g. github.com.
+	Host string
+	// PathPrefix, when set, limits credentials to repositories under it,
+	// e.g. "my-org/".
+	PathPrefix string
+	// Username defaults to x-access-token, which GitHub accepts for tokens.
+	Username string
+	// Token is a static credential. It is only ever used by the server
+	// itself, e.g. to open pull requests, and never handed to sessions.
+	Token string
+	// TokenCommand is run through sh on every request and prints a
+	// short-lived upstream credential, e.g. a script minting a GitHub App
+	// installation token. Sessions get no credentials without it.
+	TokenCommand string
+	// TTL is how long a run's delegated token is valid. Defaults to
+	// DefaultGitCredentialTTL.
+	TTL time.Duration
+	// HelperURL is the credential helper endpoint sessions call back to.
+	HelperURL string
+}
+
+func (c GitCredentialConfig) enabled() bool {
+	return c.Host != "" && c.HelperURL != "" && c.TokenCommand != ""
+}
+
+// GitCredentialRequest is the part of a git credential helper request the
+// scope is checked against.
+type GitCredentialRequest struct {
+	Protocol string
+	Host     string
+	Path     string
+}
+
+func (r GitCredentialRequest) String() string {
+	s := r.Protocol + "://" + r.Host
+	if r.Path != "" {
+		s += "/" + r.Path
+	}
+	return s
+}
+
+type GitCredential struct {
+	Username string
+	Password string
+}
+
+type delegatedGitToken struct {
+	sessionID string
+	expiresAt time.Time
+}
+
+// gitCredentialTracker holds the delegated tokens of live runs. Tokens are
+// not persisted; runs do not survive a restart either.
+type gitCredentialTracker struct {
+	mu      sync.Mutex
+	byToken map[string]delegatedGitToken
+}
+
+func newGitCredentialTracker() *gitCredentialTracker {
+	return &gitCredentialTracker{byToken: make(map[string]delegatedGitToken)}
+}
+
+// mint replaces the session's token with a new one valid until expiresAt.
+func (t *gitCredentialTracker) mint(sessionID string, expiresAt time.Time) (string, error) {
+	var buf [32]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf[:])
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.revokeLocked(sessionID)
+	t.byToken[token] = delegatedGitToken{sessionID: sessionID, expiresAt: expiresAt}
+	return token, nil
+}
+
+func (t *gitCredentialTracker) lookup(token string, now time.Time) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	d, ok := t.byToken[token]
+	if !ok {
+		return "", false
+	}
+	if !now.Before(d.expiresAt) {
+		delete(t.byToken, token)
+		return "", false
+	}
+	return d.sessionID, true
+}
+
+func (t *gitCredentialTracker) forget(sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.revokeLocked(sessionID)
+}
+
+func (t *gitCredentialTracker) revokeLocked(sessionID string) {
+	for token, d := range t.byToken {
+		if d.sessionID == sessionID {
+			delete(t.byToken, token)
+		}
+	}
+}
+
+// gitCredentialEnv mints a delegated token for a new run and returns the
+// environment that points git at the credential helper endpoint. It returns
+// nil when delegated credentials are not configured.
+func (e *AgentExecutor) gitCredentialEnv(sessionID string) map[string]string {
+	cfg := e.gitCredConfig
+	if !cfg.enabled() {
+		return nil
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultGitCredentialTTL
+	}
+	token, err := e.gitCredentials.mint(sessionID, time.Now().Add(ttl))
+	if err != nil {
+		return nil
+	}
+	return cfg.env(token)
+}
+
+// env is the environment that points git at the credential helper endpoint
+// with token.
+func (cfg GitCredentialConfig) env(token string) map[string]string {
+	// The helper only answers "get"; git's store and erase calls are
+	// no-ops. useHttpPath sends the repository path so it can be scoped.
+	helper := `!f() { test "$1" = get || exit 0; curl -sf -X POST` +
+		` -H "Authorization: Bearer $ORBITMESH_GIT_CREDENTIAL_TOKEN"` +
+		` -H "X-Orbitmesh-Internal: git-credential"` +
+		` --data-binary @- "$ORBITMESH_GIT_CREDENTIAL_URL"; }; f`
+	return map[string]string{
+		"ORBITMESH_GIT_CREDENTIAL_TOKEN": token,
+		"ORBITMESH_GIT_CREDENTIAL_URL":   cfg.HelperURL,
+		"GIT_TERMINAL_PROMPT":            "0",
+		"GIT_CONFIG_COUNT":               "2",
+		"GIT_CONFIG_KEY_0":               "credential.https://" + cfg.Host + ".helper",
+		"GIT_CONFIG_VALUE_0":             helper,
+		"GIT_CONFIG_KEY_1":               "credential.https://" + cfg.Host + ".useHttpPath",
+		"GIT_CONFIG_VALUE_1":             "true",
+	}
+}
+
+// IssueGitCredential trades a session's delegated token for the upstream
+// git credential when the request is in scope. Every grant and denial for a
+// valid token is recorded in the audit log.
+func (e *AgentExecutor) IssueGitCredential(ctx context.Context, token string, req GitCredentialRequest) (GitCredential, error) {
+	sessionID, ok := e.gitCredentials.lookup(token, time.Now())
+	if !ok || !e.gitCredConfig.enabled() {
+		return GitCredential{}, ErrGitCredentialUnauthorized
+	}
+
+	cred, err := e.upstreamGitCredential(ctx, req)
+	entry := storage.AuditEntry{
+		Actor:     "session:" + sessionID,
+		Action:    AuditActionGitCredential,
+		SessionID: sessionID,
+		Targets:   []string{req.String()},
+	}
+	if err != nil {
+		entry.Action = AuditActionGitCredentialDenied
+		entry.Reason = err.Error()
+	}
+	e.recordAudit(entry)
+	return cred, err
+}
+
+func (e *AgentExecutor) upstreamGitCredential(ctx context.Context, req GitCredentialRequest) (GitCredential, error) {
+	cfg := e.gitCredConfig
+	switch {
+	case req.Protocol != "https":
+		return GitCredential{}, fmt.Errorf("%w: protocol %q", ErrGitCredentialScope, req.Protocol)
+	case !strings.EqualFold(req.Host, cfg.Host):
+		return GitCredential{}, fmt.Errorf("%w: host %q", ErrGitCredentialScope, req.Host)
+	case cfg.PathPrefix != "" && (!strings.HasPrefix(req.Path, cfg.PathPrefix) || strings.Contains(req.Path, "..")):
+		return GitCredential{}, fmt.Errorf("%w: repository %q", ErrGitCredentialScope, req.Path)
+	}
+
+	username := cfg.Username
+	if username == "" {
+		username = "x-access-token"
+	}
+	if cfg.TokenCommand == "" {
+		return GitCredential{}, errors.New("no git token command is configured; static tokens are never issued")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitTokenCommandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "sh", "-c", cfg.TokenCommand).Output()
+	if err != nil {
+		return GitCredential{}, fmt.Errorf("git token command failed: %w", err)
+	}
+	password := strings.TrimSpace(string(out))
+	if password == "" {
+		return GitCredential{}, errors.New("git token command printed no token")
+	}
+	return GitCredential{Username: username, Password: password}, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_DelegatedGitCredentials(t *testing.T) {
+	var runEnv map[string]string
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:         newMockStorage(),
+		Broadcaster:     NewEventBroadcaster(100),
+		AuditLogStorage: storage.NewAuditLogStorage(t.TempDir()),
+		GitCredentials: GitCredentialConfig{
+			Host:         "github.com",
+			PathPrefix:   "acme/",
+			Token:        "long-lived",
+			TokenCommand: "echo minted",
+			HelperURL:    "http://127.0.0.1:8080/api/git/credential",
+		},
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			runEnv = config.Environment
+			return newMockProvider(), nil
+		},
+	})
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "pusher", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "pusher", "push it", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	token := runEnv["ORBITMESH_GIT_CREDENTIAL_TOKEN"]
+	if token == "" || runEnv["GIT_CONFIG_KEY_0"] != "credential.https://github.com.helper" {
+		t.Fatalf("run environment = %v", runEnv)
+	}
+	for _, v := range runEnv {
+		if v == "long-lived" {
+			t.Fatal("the upstream token leaked into the run environment")
+		}
+	}
+
+	ctx := context.Background()
+	cred, err := executor.IssueGitCredential(ctx, token, GitCredentialRequest{Protocol: "https", Host: "github.com", Path: "acme/app.git"})
+	if err != nil || cred.Password != "minted" || cred.Username != "x-access-token" {
+		t.Fatalf("in-scope credential = %+v, %v", cred, err)
+	}
+	for _, req := range []GitCredentialRequest{
+		{Protocol: "https", Host: "gitlab.com", Path: "acme/app.git"},
+		{Protocol: "https", Host: "github.com", Path: "other/app.git"},
+		{Protocol: "https", Host: "github.com", Path: "acme/../other/app.git"},
+		{Protocol: "http", Host: "github.com", Path: "acme/app.git"},
+	} {
+		if _, err := executor.IssueGitCredential(ctx, token, req); !errors.Is(err, ErrGitCredentialScope) {
+			t.Fatalf("%s: err = %v, want ErrGitCredentialScope", req, err)
+		}
+	}
+	if _, err := executor.IssueGitCredential(ctx, "forged", GitCredentialRequest{Protocol: "https", Host: "github.com", Path: "acme/app.git"}); !errors.Is(err, ErrGitCredentialUnauthorized) {
+		t.Fatalf("forged token err = %v", err)
+	}
+
+	entries, err := executor.AuditEntries("pusher")
+	if err != nil || len(entries) != 5 {
+		t.Fatalf("audit entries = %+v, %v", entries, err)
+	}
+	if e := entries[0]; e.Action != AuditActionGitCredential || e.Targets[0] != "https://github.com/acme/app.git" {
+		t.Fatalf("grant entry = %+v", e)
+	}
+	if e := entries[1]; e.Action != AuditActionGitCredentialDenied || e.Reason == "" {
+		t.Fatalf("denial entry = %+v", e)
+	}
+
+	executor.gitCredentials.forget("pusher")
+	if _, err := executor.IssueGitCredential(ctx, token, GitCredentialRequest{Protocol: "https", Host: "github.com", Path: "acme/app.git"}); !errors.Is(err, ErrGitCredentialUnauthorized) {
+		t.Fatalf("revoked token err = %v", err)
+	}
+}
+
+func TestAgentExecutor_StaticGitTokenNeverIssued(t *testing.T) {
+	var runEnv map[string]string
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		GitCredentials: GitCredentialConfig{
+			Host:      "github.com",
+			Token:     "long-lived",
+			HelperURL: "http://127.0.0.1:8080/api/git/credential",
+		},
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			runEnv = config.Environment
+			return newMockProvider(), nil
+		},
+	})
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "pusher", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "pusher", "push it", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if token := runEnv["ORBITMESH_GIT_CREDENTIAL_TOKEN"]; token != "" {
+		t.Fatalf("expected no delegated token without a token command, got run environment %v", runEnv)
+	}
+	if _, err := executor.upstreamGitCredential(context.Background(), GitCredentialRequest{Protocol: "https", Host: "github.com", Path: "acme/app.git"}); err == nil {
+		t.Fatal("expected the static token to be refused")
+	}
+}
+
+func TestGitCredentialTrackerExpiry(t *testing.T) {
+	tracker := newGitCredentialTracker()
+	now := time.Now()
+	token, err := tracker.mint("s", now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if id, ok := tracker.lookup(token, now); !ok || id != "s" {
+		t.Fatalf("lookup = %q, %v", id, ok)
+	}
+	if _, ok := tracker.lookup(token, now.Add(time.Minute)); ok {
+		t.Fatal("expired token was accepted")
+	}
+
+	first, _ := tracker.mint("s", now.Add(time.Minute))
+	if _, err := tracker.mint("s", now.Add(time.Minute)); err != nil {
+		t.Fatalf("mint: %v", err)
+	}
+	if _, ok := tracker.lookup(first, now); ok {
+		t.Fatal("minting a new token should revoke the session's previous one")
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// ErrInvalidGitBranch rejects git tracking outside a git checkout, or on a
+// branch git cannot check out.
+var ErrInvalidGitBranch = errors.New("invalid git branch")
+
+// gitBranchName is the default tracked branch of session id, named after
+// its task when it has one.
+func gitBranchName(id, taskID string) string {
+	if taskID == "" {
+		return "orbitmesh/session/" + id
+	}
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '-'
+	}, taskID)
+	return "orbitmesh/task/" + strings.Trim(safe, ".-")
+}
+
+// prepareCheckout makes the workspace config asks for and puts it on its
+// tracked branch, returning config pointed at them. A session in a
+// workspace tracks the workspace's branch. Nothing is left behind on error.
+func (e *AgentExecutor) prepareCheckout(ctx context.Context, id string, config session.Config) (session.Config, *domain.Workspace, error) {
+	if config.GitTracking != nil && config.GitTracking.Branch == "" {
+		g := *config.GitTracking
+		g.Branch = gitBranchName(id, config.TaskID)
+		config.GitTracking = &g
+	}
+	var ws *domain.Workspace
+	if config.Workspace != nil {
+		spec := *config.Workspace
+		if spec.Branch == "" && config.GitTracking != nil {
+			spec.Branch = config.GitTracking.Branch
+		}
+		var err error
+		if ws, err = e.createWorkspace(ctx, id, spec); err != nil {
+			return config, nil, err
+		}
+		config = workspaceConfig(config, ws)
+	}
+	if config.GitTracking != nil {
+		g, err := startGitTracking(ctx, config)
+		if err != nil {
+			e.removeWorkspace(ctx, ws)
+			return config, nil, err
+		}
+		config.GitTracking = g
+	}
+	return config, ws, nil
+}
+
+// startGitTracking checks the tracked branch out in the session's working
+// directory, creating it from HEAD, or resuming it when it exists; only
+// commits made from then on are the session's.
+func startGitTracking(ctx context.Context, config session.Config) (*domain.GitTracking, error) {
+	if ws := config.Workspace; ws != nil {
+		return &domain.GitTracking{Branch: ws.Branch, BaseRef: ws.BaseRef}, nil
+	}
+	dir := config.WorkingDir
+	g := &domain.GitTracking{Branch: config.GitTracking.Branch}
+	if _, err := runGit(ctx, dir, "check-ref-format", "--branch", g.Branch); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGitBranch, err)
+	}
+	if tip, err := resolveCommit(ctx, dir, "refs/heads/"+g.Branch); err == nil {
+		if _, err := runGit(ctx, dir, "checkout", "--quiet", g.Branch); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGitBranch, err)
+		}
+		g.BaseRef = tip
+		return g, nil
+	}
+	base, err := resolveCommit(ctx, dir, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("%w: working directory is not a git checkout with commits: %v", ErrInvalidGitBranch, err)
+	}
+	if _, err := runGit(ctx, dir, "checkout", "--quiet", "-b", g.Branch); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGitBranch, err)
+	}
+	g.BaseRef = base
+	return g, nil
+}
+
+// trackGitCommits records the commits made on the session's tracked branch
+// since it was last checked, publishing each as a "git_commit" metadata
+// event.
+func (e *AgentExecutor) trackGitCommits(sc *sessionContext) {
+	g := sc.session.GetGitTracking()
+	if g == nil {
+		return
+	}
+	out, err := runGit(e.ctx, sc.session.WorkingDir, "log", "--reverse", "--format=%H%x1f%cI%x1f%s", g.BaseRef+".."+g.Branch)
+	if err != nil {
+		log.Printf("git tracking: session %s: %v", sc.session.ID, err)
+		return
+	}
+	var commits []domain.TrackedCommit
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339, fields[1])
+		commits = append(commits, domain.TrackedCommit{SHA: fields[0], Message: fields[2], At: at})
+	}
+	added := sc.session.AddTrackedCommits(commits)
+	if len(added) == 0 {
+		return
+	}
+	for _, c := range added {
+		e.broadcaster.Broadcast(domain.NewMetadataEvent(sc.session.ID, domain.MetadataKeyGitCommit, map[string]any{
+			"sha":     c.SHA,
+			"message": c.Message,
+			"branch":  g.Branch,
+		}, nil))
+	}
+	if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+const (
+	// AuditActionGuardrailFlag and AuditActionGuardrailBlock are the audit
+	// actions for output a guardrail flagged or blocked.
+	AuditActionGuardrailFlag  = "guardrail_flag"
+	AuditActionGuardrailBlock = "guardrail_block"
+
+	// DefaultClassifierTimeout bounds each classifier call.
+	DefaultClassifierTimeout = 5 * time.Second
+
+	// maxQuarantineContent caps the output copied into the quarantine.
+	maxQuarantineContent = 16 * 1024
+
+	// guardrailWindow is how much of a session's recent streamed output is
+	// scanned with each delta, so a secret split across deltas is caught.
+	guardrailWindow = 512
+
+	// classifierQueueSize bounds the output waiting for the classifier;
+	// output arriving while it is full is not classified.
+	classifierQueueSize = 64
+)
+
+// GuardrailFinding is one guardrail match in agent output. Match is masked so
+// the finding itself does not leak the secret.
+type GuardrailFinding struct {
+	Rule     string
+	Category string
+	Action   domain.GuardrailAction
+	Match    string
+}
+
+// OutputScanner inspects agent output beyond the regex rules, e.g. a model
+// that classifies disallowed content. Findings with an empty Action take the
+// action of their category.
+type OutputScanner interface {
+	Scan(ctx context.Context, text string) ([]GuardrailFinding, error)
+}
+
+// GuardrailConfig enables scanning agent output before it is persisted or
+// broadcast. Policy sets the server-wide category actions and custom rules;
+// projects may override them with SetProjectGuardrails. Categories without
+// an action use DefaultGuardrailActions. The classifier runs in the
+// background, so its findings are quarantined and audited but cannot block
+// output that was already sent.
+type GuardrailConfig struct {
+	Policy     domain.GuardrailPolicy
+	Classifier OutputScanner
+	// ClassifierTimeout defaults to DefaultClassifierTimeout.
+	ClassifierTimeout time.Duration
+	// Quarantine keeps a copy of flagged and blocked output for review.
+	Quarantine *storage.QuarantineStorage
+}
+
+// DefaultGuardrailActions flags secrets and content a classifier objects to
+// and ignores PII, which agents routinely and legitimately print (emails in
+// git logs, for example).
+var DefaultGuardrailActions = map[string]domain.GuardrailAction{
+	domain.GuardrailCategorySecret:  domain.GuardrailFlag,
+	domain.GuardrailCategoryPII:     domain.GuardrailOff,
+	domain.GuardrailCategoryContent: domain.GuardrailFlag,
+}
+
+type guardrailRule struct {
+	name     string
+	category string
+	re       *regexp.Regexp
+	action   domain.GuardrailAction
+}
+
+var builtinGuardrailRules = []guardrailRule{
+	{name: "aws_access_key", category: domain.GuardrailCategorySecret, re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "github_token", category: domain.GuardrailCategorySecret, re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{name: "slack_token", category: domain.GuardrailCategorySecret, re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{name: "api_secret_key", category: domain.GuardrailCategorySecret, re: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{name: "private_key", category: domain.GuardrailCategorySecret, re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{name: "email", category: domain.GuardrailCategoryPII, re: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	{name: "us_ssn", category: domain.GuardrailCategoryPII, re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+}
+
+// guardrailPolicy is a compiled policy: the effective category actions and
+// the rules to run.
+type guardrailPolicy struct {
+	actions map[string]domain.GuardrailAction
+	rules   []guardrailRule
+}
+
+func (p guardrailPolicy) actionFor(category string, override domain.GuardrailAction) domain.GuardrailAction {
+	if override != "" {
+		return override
+	}
+	if a, ok := p.actions[category]; ok && a != "" {
+		return a
+	}
+	return domain.GuardrailOff
+}
+
+// compileGuardrailPolicy layers p over base. Project rules add to the
+// server's rules rather than replacing them.
+func compileGuardrailPolicy(base guardrailPolicy, p *domain.GuardrailPolicy) (guardrailPolicy, error) {
+	out := guardrailPolicy{actions: make(map[string]domain.GuardrailAction, len(base.actions)), rules: base.rules}
+	for k, v := range base.actions {
+		out.actions[k] = v
+	}
+	if p == nil {
+		return out, nil
+	}
+	if err := p.Validate(); err != nil {
+		return guardrailPolicy{}, err
+	}
+	for k, v := range p.Actions {
+		if v != "" {
+			out.actions[k] = v
+		}
+	}
+	if len(p.Rules) > 0 {
+		out.rules = append([]guardrailRule(nil), base.rules...)
+		for _, r := range p.Rules {
+			category := r.Category
+			if category == "" {
+				category = domain.GuardrailCategoryContent
+			}
+			out.rules = append(out.rules, guardrailRule{
+				name:     r.Name,
+				category: category,
+				re:       regexp.MustCompile(r.Pattern),
+				action:   r.Action,
+			})
+		}
+	}
+	return out, nil
+}
+
+type guardrails struct {
+	classifier        OutputScanner
+	classifierTimeout time.Duration
+	quarantine        *storage.QuarantineStorage
+	classifierJobs    chan classifierJob
+	classifierStart   sync.Once
+
+	mu       sync.RWMutex
+	server   guardrailPolicy
+	projects map[string]guardrailPolicy
+}
+
+func newGuardrails(cfg GuardrailConfig) *guardrails {
+	base := guardrailPolicy{actions: DefaultGuardrailActions, rules: builtinGuardrailRules}
+	server, err := compileGuardrailPolicy(base, &cfg.Policy)
+	if err != nil {
+		log.Printf("guardrails: ignoring invalid server policy: %v", err)
+		server, _ = compileGuardrailPolicy(base, nil)
+	}
+	timeout := cfg.ClassifierTimeout
+	if timeout <= 0 {
+		timeout = DefaultClassifierTimeout
+	}
+	return &guardrails{
+		classifier:        cfg.Classifier,
+		classifierTimeout: timeout,
+		quarantine:        cfg.Quarantine,
+		classifierJobs:    make(chan classifierJob, classifierQueueSize),
+		server:            server,
+		projects:          make(map[string]guardrailPolicy),
+	}
+}
+
+func (g *guardrails) policyFor(projectID string) guardrailPolicy {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if p, ok := g.projects[projectID]; ok && projectID != "" {
+		return p
+	}
+	return g.server
+}
+
+// scanRules returns the findings of the regex rules whose action is not
+// off. Only matches ending past from count, so a window that still holds
+// output scanned before does not report its matches again.
+func (p guardrailPolicy) scanRules(text string, from int) []GuardrailFinding {
+	var findings []GuardrailFinding
+	for _, rule := range p.rules {
+		action := p.actionFor(rule.category, rule.action)
+		if action == domain.GuardrailOff {
+			continue
+		}
+		for _, loc := range rule.re.FindAllStringIndex(text, -1) {
+			if loc[1] <= from {
+				continue
+			}
+			findings = append(findings, GuardrailFinding{
+				Rule:     rule.name,
+				Category: rule.category,
+				Action:   action,
+				Match:    maskGuardrailMatch(text[loc[0]:loc[1]]),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// classify returns the classifier's findings in text whose action is not
+// off.
+func (g *guardrails) classify(ctx context.Context, projectID, text string) []GuardrailFinding {
+	policy := g.policyFor(projectID)
+	cctx, cancel := context.WithTimeout(ctx, g.classifierTimeout)
+	extra, err := g.classifier.Scan(cctx, text)
+	cancel()
+	if err != nil {
+		log.Printf("guardrails: classifier: %v", err)
+	}
+	var findings []GuardrailFinding
+	for _, f := range extra {
+		if f.Category == "" {
+			f.Category = domain.GuardrailCategoryContent
+		}
+		f.Action = policy.actionFor(f.Category, f.Action)
+		if f.Action == domain.GuardrailOff {
+			continue
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// classifierJob is output waiting for the classifier.
+type classifierJob struct {
+	sessionID string
+	projectID string
+	source    string
+	text      string
+}
+
+// guardrailTail keeps the end of a session's recent streamed output, per
+// source, for the next delta to be scanned with.
+type guardrailTail struct {
+	mu       sync.Mutex
+	bySource map[string]string
+}
+
+// extend appends text to the source's tail and returns the window to scan
+// and where text starts in it.
+func (t *guardrailTail) extend(source, text string) (string, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev := t.bySource[source]
+	window := prev + text
+	if t.bySource == nil {
+		t.bySource = make(map[string]string)
+	}
+	t.bySource[source] = window[max(0, len(window)-guardrailWindow):]
+	return window, len(prev)
+}
+
+// reset drops the source's tail once its output was reported, so a match
+// that keeps growing is not reported with every delta.
+func (t *guardrailTail) reset(source string) {
+	t.mu.Lock()
+	delete(t.bySource, source)
+	t.mu.Unlock()
+}
+
+// maskGuardrailMatch keeps a few characters of each end of a match so a
+// reviewer can recognize it.
+func maskGuardrailMatch(s string) string {
+	r := []rune(s)
+	if len(r) <= 8 {
+		return strings.Repeat("*", len(r))
+	}
+	return string(r[:4]) + strings.Repeat("*", len(r)-8) + string(r[len(r)-4:])
+}
+
+// SetProjectGuardrails sets the guardrail policy for a project's sessions,
+// layered over the server policy. A nil policy reverts the project to the
+// server policy.
+func (e *AgentExecutor) SetProjectGuardrails(projectID string, policy *domain.GuardrailPolicy) error {
+	if policy == nil {
+		e.guardrails.mu.Lock()
+		delete(e.guardrails.projects, projectID)
+		e.guardrails.mu.Unlock()
+		return nil
+	}
+	e.guardrails.mu.RLock()
+	server := e.guardrails.server
+	e.guardrails.mu.RUnlock()
+	compiled, err := compileGuardrailPolicy(server, policy)
+	if err != nil {
+		return err
+	}
+	e.guardrails.mu.Lock()
+	e.guardrails.projects[projectID] = compiled
+	e.guardrails.mu.Unlock()
+	return nil
+}
+
+// QuarantineItems returns the flagged and blocked output of a session, or of
+// every session when id is empty. A non-empty status filters by status.
+func (e *AgentExecutor) QuarantineItems(id, status string) ([]storage.QuarantineItem, error) {
+	if id != "" {
+		if _, err := e.GetSession(id); err != nil {
+			return nil, err
+		}
+	}
+	if e.guardrails.quarantine == nil {
+		return []storage.QuarantineItem{}, nil
+	}
+	return e.guardrails.quarantine.List(id, status)
+}
+
+// ResolveQuarantineItem marks a quarantine item as dismissed (a false
+// positive) or confirmed.
+func (e *AgentExecutor) ResolveQuarantineItem(itemID, status, actor string) (storage.QuarantineItem, error) {
+	if status != storage.QuarantineDismissed && status != storage.QuarantineConfirmed {
+		return storage.QuarantineItem{}, fmt.Errorf("unknown resolution %q", status)
+	}
+	if e.guardrails.quarantine == nil {
+		return storage.QuarantineItem{}, storage.ErrQuarantineItemNotFound
+	}
+	return e.guardrails.quarantine.Resolve(itemID, status, actor)
+}
+
+// screenEvent runs the guardrail rules over an event's agent output before
+// it is broadcast or persisted, returning the event with blocked output
+// replaced. Streamed output and thoughts are scanned together with the end
+// of the output before them, so a secret split across deltas is blocked in
+// the delta that completes it. Each event's output is also handed to the
+// classifier in the background.
+func (e *AgentExecutor) screenEvent(sc *sessionContext, event domain.Event) domain.Event {
+	var source, text string
+	switch d := event.Data.(type) {
+	case domain.OutputData:
+		source, text = "output", d.Content
+	case domain.ThoughtData:
+		source, text = "thought", d.Content
+	case domain.ToolCallData:
+		source, text = "tool_call", guardrailText(d.Output)
+	default:
+		return event
+	}
+	if text == "" {
+		return event
+	}
+
+	projectID := sc.session.ProjectID
+	window, from := text, 0
+	if source != "tool_call" {
+		window, from = sc.guardrailTail.extend(source, text)
+	}
+	e.queueClassifier(classifierJob{sessionID: event.SessionID, projectID: projectID, source: source, text: text})
+	findings := e.guardrails.policyFor(projectID).scanRules(window, from)
+	if len(findings) == 0 {
+		return event
+	}
+	sc.guardrailTail.reset(source)
+
+	action, rules := e.recordGuardrailFindings(event.SessionID, projectID, source, window, findings)
+	if action != domain.GuardrailBlock {
+		return event
+	}
+	tombstone := fmt.Sprintf("%s (%s)", domain.GuardrailBlockedTombstone, strings.Join(rules, ", "))
+	switch d := event.Data.(type) {
+	case domain.OutputData:
+		d.Content = tombstone
+		event.Data = d
+	case domain.ThoughtData:
+		d.Content = tombstone
+		event.Data = d
+	case domain.ToolCallData:
+		d.Output = tombstone
+		event.Data = d
+	}
+	event.Raw = nil
+	return event
+}
+
+// recordGuardrailFindings quarantines the output and audits the findings,
+// returning the strongest action among them and the rules that matched.
+func (e *AgentExecutor) recordGuardrailFindings(sessionID, projectID, source, text string, findings []GuardrailFinding) (domain.GuardrailAction, []string) {
+	action := domain.GuardrailFlag
+	rules := make([]string, 0, len(findings))
+	quarantined := make([]storage.QuarantineFinding, 0, len(findings))
+	for _, f := range findings {
+		if f.Action == domain.GuardrailBlock {
+			action = domain.GuardrailBlock
+		}
+		rules = append(rules, f.Rule)
+		quarantined = append(quarantined, storage.QuarantineFinding{
+			Rule:     f.Rule,
+			Category: f.Category,
+			Action:   string(f.Action),
+			Match:    f.Match,
+		})
+	}
+
+	itemID := newQuarantineID()
+	if q := e.guardrails.quarantine; q != nil {
+		content := text
+		if len(content) > maxQuarantineContent {
+			content = content[:maxQuarantineContent]
+		}
+		if err := q.Append(storage.QuarantineItem{
+			ID:        itemID,
+			SessionID: sessionID,
+			ProjectID: projectID,
+			Source:    source,
+			Action:    string(action),
+			Findings:  quarantined,
+			Content:   content,
+		}); err != nil {
+			log.Printf("guardrails: quarantine: %v", err)
+		}
+	}
+	auditAction := AuditActionGuardrailFlag
+	if action == domain.GuardrailBlock {
+		auditAction = AuditActionGuardrailBlock
+	}
+	e.recordAudit(storage.AuditEntry{
+		Action:    auditAction,
+		SessionID: sessionID,
+		Targets:   []string{itemID},
+		Reason:    strings.Join(rules, ", "),
+	})
+	return action, rules
+}
+
+// queueClassifier hands output to the classifier without waiting for it.
+// Output is dropped while the classifier is behind, so a slow classifier
+// never stalls a session.
+func (e *AgentExecutor) queueClassifier(job classifierJob) {
+	g := e.guardrails
+	if g.classifier == nil || strings.TrimSpace(job.text) == "" {
+		return
+	}
+	g.classifierStart.Do(func() { e.wg.Go(e.runClassifier) })
+	select {
+	case g.classifierJobs <- job:
+	default:
+		log.Printf("guardrails: classifier is behind; skipping output of session %s", job.sessionID)
+	}
+}
+
+// runClassifier classifies queued output until the executor stops.
+func (e *AgentExecutor) runClassifier() {
+	g := e.guardrails
+	for {
+		select {
+		case <-e.ctx.Done():
+			return
+		case job := <-g.classifierJobs:
+			if findings := g.classify(e.ctx, job.projectID, job.text); len(findings) > 0 {
+				e.recordGuardrailFindings(job.sessionID, job.projectID, job.source, job.text, findings)
+			}
+		}
+	}
+}
+
+func guardrailText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
+
+func newQuarantineID() string {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
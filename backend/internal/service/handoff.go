@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+var ErrInvalidHandoff = errors.New("invalid handoff request")
+
+// StartHandoff moves an idle session to another provider. The current
+// provider is first asked to summarize its working context; once that run
+// completes, the summary is parsed into a handoff document and the next run
+// starts on the new provider with the document as its first message. The
+// returned handoff is still summarizing; its outcome is recorded on the
+// session.
+func (e *AgentExecutor) StartHandoff(ctx context.Context, id, providerType, providerID, instructions, actor string) (*domain.Handoff, error) {
+	if providerType == "" {
+		return nil, fmt.Errorf("%w: provider_type or provider_id is required", ErrInvalidHandoff)
+	}
+	if _, err := e.ProviderCapabilities(providerType); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, providerType)
+	}
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return nil, err
+	}
+	sess := sc.session
+	snap := sess.Snapshot()
+	if providerType == snap.ProviderType && (providerID == "" || providerID == snap.PreferredProviderID) {
+		return nil, fmt.Errorf("%w: session already uses this provider", ErrInvalidHandoff)
+	}
+	if previous := snap.Handoff; previous != nil && previous.Status == domain.HandoffSummarizing {
+		return nil, fmt.Errorf("%w: a handoff is already in progress", ErrInvalidState)
+	}
+
+	handoff := &domain.Handoff{
+		Status:           domain.HandoffSummarizing,
+		FromProviderType: snap.ProviderType,
+		FromProviderID:   snap.PreferredProviderID,
+		ToProviderType:   providerType,
+		ToProviderID:     providerID,
+		Instructions:     strings.TrimSpace(instructions),
+		RequestedBy:      actor,
+		RequestedAt:      time.Now().UTC(),
+	}
+	if state := sess.GetState(); state != domain.SessionStateIdle {
+		return nil, fmt.Errorf("%w: cannot hand off a %s session", ErrInvalidState, state)
+	}
+	sess.SetHandoff(handoff)
+	e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeHandoffSummarizing, "from", handoff.FromProviderType, "to", handoff.ToProviderType), time.Now())
+	start := len(sess.Snapshot().Messages)
+	opts := SendMessageOptions{afterRun: func(completed bool) { e.finishHandoff(sc, start, completed) }}
+	if _, err := e.sendMessage(ctx, id, domain.HandoffSummaryPrompt(), "", "", opts); err != nil {
+		e.failHandoff(sess, handoff, err.Error())
+		return nil, err
+	}
+	return handoff, nil
+}
+
+// finishHandoff runs after the summary run ends. It parses the output the
+// run added after message start and starts the new provider with it.
+func (e *AgentExecutor) finishHandoff(sc *sessionContext, start int, completed bool) {
+	sess := sc.session
+	handoff := sess.GetHandoff()
+	if handoff == nil || handoff.Status != domain.HandoffSummarizing {
+		return
+	}
+	if !completed {
+		e.failHandoff(sess, handoff, "the summary run did not complete")
+		return
+	}
+
+	var outputs []string
+	messages := sess.Snapshot().Messages
+	for _, m := range messages[min(start, len(messages)):] {
+		if m.Kind == domain.MessageKindOutput && !m.Redacted {
+			outputs = append(outputs, m.Contents)
+		}
+	}
+	doc := domain.ParseHandoffDocument(strings.Join(outputs, "\n"))
+	if doc.Empty() {
+		e.failHandoff(sess, handoff, "the provider returned an empty summary")
+		return
+	}
+	handoff.Document = &doc
+
+	sess.SetProviderType(handoff.ToProviderType)
+	sess.SetPreferredProviderID(handoff.ToProviderID)
+	prompt := doc.Prompt(handoff.FromProviderType, handoff.Instructions)
+	if _, err := e.sendMessage(e.ctx, sess.ID, prompt, handoff.ToProviderID, handoff.ToProviderType, SendMessageOptions{}); err != nil {
+		sess.SetProviderType(handoff.FromProviderType)
+		sess.SetPreferredProviderID(handoff.FromProviderID)
+		e.failHandoff(sess, handoff, err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	handoff.Status = domain.HandoffStarted
+	handoff.CompletedAt = &now
+	sess.SetHandoff(handoff)
+	e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeHandoffStarted, "from", handoff.FromProviderType, "to", handoff.ToProviderType), now)
+	if e.storage != nil {
+		_ = e.saveSession(sess)
+	}
+}
+
+func (e *AgentExecutor) failHandoff(sess *domain.Session, handoff *domain.Handoff, reason string) {
+	log.Printf("handoff of session %s to %s failed: %s", sess.ID, handoff.ToProviderType, reason)
+	now := time.Now().UTC()
+	handoff.Status = domain.HandoffFailed
+	handoff.CompletedAt = &now
+	handoff.Error = reason
+	sess.SetHandoff(handoff)
+	e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeHandoffFailed, "to", handoff.ToProviderType, "error", reason), now)
+	if e.storage != nil {
+		_ = e.saveSession(sess)
+	}
+}
+
+// SessionMessage is a message one session handed off into another's queue.
+type SessionMessage struct {
+	FromSessionID string
+	ToSessionID   string
+	MessageID     string
+	Content       string
+}
+
+// SendToSession posts a message from session fromID to session toID, which
+// starts a run with it when idle and queues it otherwise. content defaults
+// to fromID's last output; instructions are appended to it. Both sessions'
+// message logs record the handoff with the message's ID.
+func (e *AgentExecutor) SendToSession(ctx context.Context, fromID, toID, content, instructions, actor string) (*SessionMessage, error) {
+	if toID == fromID {
+		return nil, fmt.Errorf("%w: a session cannot hand off to itself", ErrInvalidHandoff)
+	}
+	from, err := e.ensureSessionContext(fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := e.ensureSessionContext(toID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, fmt.Errorf("%w: target session %s not found", ErrInvalidHandoff, toID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	content = strings.TrimSpace(content)
+	if content == "" {
+		content = strings.TrimSpace(lastOutputMessage(from.session))
+	}
+	if content == "" {
+		return nil, fmt.Errorf("%w: session %s has no output to hand off", ErrInvalidHandoff, fromID)
+	}
+	header := "Message from session " + fromID
+	if title := from.session.Snapshot().Title; title != "" {
+		header += " (" + title + ")"
+	}
+	prompt := header + ":\n\n" + content
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		prompt += "\n\n" + instructions
+	}
+
+	msg := &SessionMessage{FromSessionID: fromID, ToSessionID: toID, MessageID: newAttemptID(), Content: prompt}
+	if _, err := e.SendMessageWithOptions(ctx, toID, prompt, "", "", SendMessageOptions{MessageID: msg.MessageID, Actor: actor}); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	e.appendNotice(from.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeHandoffSent, "session", toID, "message_id", msg.MessageID), now)
+	e.appendNotice(to.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeHandoffReceived, "session", fromID, "message_id", msg.MessageID), now)
+	return msg, nil
+}
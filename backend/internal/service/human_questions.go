@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// QuestionStatus is where a human question is in its lifecycle.
+type QuestionStatus string
+
+const (
+	QuestionPending   QuestionStatus = "pending"
+	QuestionAnswered  QuestionStatus = "answered"
+	QuestionCancelled QuestionStatus = "cancelled"
+)
+
+// maxQuestionReasonLen bounds how much of a question goes into the
+// suspension reason, which notifications show.
+const maxQuestionReasonLen = 200
+
+var (
+	ErrInvalidQuestion  = errors.New("invalid question")
+	ErrQuestionNotFound = errors.New("question not found")
+	ErrQuestionClosed   = errors.New("question is no longer pending")
+	ErrInvalidAnswer    = errors.New("invalid answer")
+)
+
+// HumanQuestion is a question an agent asked a human through the ask_human
+// tool. The agent's run is suspended until it is answered, or cancelled
+// when the run ends first.
+type HumanQuestion struct {
+	ID         string         `json:"id"`
+	SessionID  string         `json:"session_id"`
+	Question   string         `json:"question"`
+	Options    []string       `json:"options,omitempty"`
+	Status     QuestionStatus `json:"status"`
+	AskedAt    time.Time      `json:"asked_at"`
+	Answer     string         `json:"answer,omitempty"`
+	AnsweredBy string         `json:"answered_by,omitempty"`
+	AnsweredAt *time.Time     `json:"answered_at,omitempty"`
+
+	// closed is closed once the question is answered or cancelled.
+	closed chan struct{}
+}
+
+// questionTracker holds the questions asked during live runs. Questions are
+// not persisted: the agent waiting on one does not survive a restart.
+type questionTracker struct {
+	mu        sync.Mutex
+	bySession map[string][]*HumanQuestion
+}
+
+func newQuestionTracker() *questionTracker {
+	return &questionTracker{bySession: make(map[string][]*HumanQuestion)}
+}
+
+func (t *questionTracker) pending(sessionID string) *HumanQuestion {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, q := range t.bySession[sessionID] {
+		if q.Status == QuestionPending {
+			return q
+		}
+	}
+	return nil
+}
+
+// cancel closes the session's pending questions and reports how many there
+// were.
+func (t *questionTracker) cancel(sessionID string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, q := range t.bySession[sessionID] {
+		if q.Status == QuestionPending {
+			q.Status = QuestionCancelled
+			close(q.closed)
+			n++
+		}
+	}
+	return n
+}
+
+func (t *questionTracker) forget(sessionID string) {
+	t.cancel(sessionID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.bySession, sessionID)
+}
+
+// find returns the question and a copy of it safe to hand out.
+func (t *questionTracker) find(sessionID, questionID string) (*HumanQuestion, HumanQuestion, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, q := range t.bySession[sessionID] {
+		if q.ID == questionID {
+			return q, q.copy(), true
+		}
+	}
+	return nil, HumanQuestion{}, false
+}
+
+// copy must be called with t.mu held.
+func (q *HumanQuestion) copy() HumanQuestion {
+	c := *q
+	c.Options = slices.Clone(q.Options)
+	c.closed = nil
+	return c
+}
+
+// AskHuman records a question from the session's agent and suspends the run
+// until a human answers it. Only running sessions can ask, one question at
+// a time.
+func (e *AgentExecutor) AskHuman(id, question string, options []string) (HumanQuestion, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return HumanQuestion{}, fmt.Errorf("%w: question is required", ErrInvalidQuestion)
+	}
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return HumanQuestion{}, err
+	}
+	if sc.getRun() == nil || sc.session.GetState() != domain.SessionStateRunning {
+		return HumanQuestion{}, fmt.Errorf("%w: only a running session can ask a question", ErrInvalidState)
+	}
+
+	q := &HumanQuestion{
+		ID:        newAttemptID(),
+		SessionID: id,
+		Question:  question,
+		Options:   slices.DeleteFunc(slices.Clone(options), func(o string) bool { return strings.TrimSpace(o) == "" }),
+		Status:    QuestionPending,
+		AskedAt:   time.Now().UTC(),
+		closed:    make(chan struct{}),
+	}
+	e.questions.mu.Lock()
+	for _, other := range e.questions.bySession[id] {
+		if other.Status == QuestionPending {
+			e.questions.mu.Unlock()
+			return HumanQuestion{}, fmt.Errorf("%w: session is already waiting on a question", ErrInvalidState)
+		}
+	}
+	e.questions.bySession[id] = append(e.questions.bySession[id], q)
+	asked := q.copy()
+	e.questions.mu.Unlock()
+
+	notice := domain.NewNotice(domain.NoticeQuestionAsked, "question", question, "options", strings.Join(asked.Options, ", "))
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, time.Now())
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.WaitKind = domain.WaitKindWaitingOnHuman
+		a.WaitRef = q.ID
+		a.HeartbeatAt = time.Now().UTC()
+	})
+	reason := question
+	if r := []rune(reason); len(r) > maxQuestionReasonLen {
+		reason = string(r[:maxQuestionReasonLen]) + "…"
+	}
+	e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitHuman, "question", reason))
+	return asked, nil
+}
+
+// AnswerQuestion answers a pending question and resumes the suspended run.
+// Questions with options only accept one of them.
+func (e *AgentExecutor) AnswerQuestion(id, questionID, answer, answeredBy string) (HumanQuestion, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return HumanQuestion{}, err
+	}
+	answer = strings.TrimSpace(answer)
+
+	e.questions.mu.Lock()
+	var q *HumanQuestion
+	for _, candidate := range e.questions.bySession[id] {
+		if candidate.ID == questionID {
+			q = candidate
+		}
+	}
+	switch {
+	case q == nil:
+		e.questions.mu.Unlock()
+		return HumanQuestion{}, ErrQuestionNotFound
+	case q.Status != QuestionPending:
+		e.questions.mu.Unlock()
+		return HumanQuestion{}, ErrQuestionClosed
+	case answer == "":
+		e.questions.mu.Unlock()
+		return HumanQuestion{}, fmt.Errorf("%w: answer is required", ErrInvalidAnswer)
+	case len(q.Options) > 0 && !slices.Contains(q.Options, answer):
+		e.questions.mu.Unlock()
+		return HumanQuestion{}, fmt.Errorf("%w: must be one of %s", ErrInvalidAnswer, strings.Join(q.Options, ", "))
+	}
+	now := time.Now().UTC()
+	q.Status = QuestionAnswered
+	q.Answer = answer
+	q.AnsweredBy = answeredBy
+	q.AnsweredAt = &now
+	close(q.closed)
+	answered := q.copy()
+	e.questions.mu.Unlock()
+
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeQuestionAnswer, "answered_by", answeredBy, "answer", answer), time.Now())
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		if a.WaitKind == domain.WaitKindWaitingOnHuman && a.WaitRef == questionID {
+			a.WaitKind = ""
+			a.WaitRef = ""
+		}
+		a.HeartbeatAt = time.Now().UTC()
+	})
+	if sc.getRun() != nil {
+		e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusQuestionAnswered))
+	}
+	return answered, nil
+}
+
+// WaitForAnswer blocks until the question is answered or cancelled, or ctx
+// is done, and returns its latest state.
+func (e *AgentExecutor) WaitForAnswer(ctx context.Context, id, questionID string) (HumanQuestion, error) {
+	q, current, ok := e.questions.find(id, questionID)
+	if !ok {
+		return HumanQuestion{}, ErrQuestionNotFound
+	}
+	if current.Status != QuestionPending {
+		return current, nil
+	}
+	select {
+	case <-q.closed:
+	case <-ctx.Done():
+	}
+	_, current, _ = e.questions.find(id, questionID)
+	return current, nil
+}
+
+// SessionQuestions returns every question asked in the session's live runs,
+// oldest first.
+func (e *AgentExecutor) SessionQuestions(id string) ([]HumanQuestion, error) {
+	if _, err := e.GetSession(id); err != nil {
+		return nil, err
+	}
+	e.questions.mu.Lock()
+	defer e.questions.mu.Unlock()
+	out := make([]HumanQuestion, 0, len(e.questions.bySession[id]))
+	for _, q := range e.questions.bySession[id] {
+		out = append(out, q.copy())
+	}
+	return out, nil
+}
+
+// PendingQuestions returns the unanswered questions of all sessions, oldest
+// first.
+func (e *AgentExecutor) PendingQuestions() []HumanQuestion {
+	e.questions.mu.Lock()
+	defer e.questions.mu.Unlock()
+	out := make([]HumanQuestion, 0)
+	for _, questions := range e.questions.bySession {
+		for _, q := range questions {
+			if q.Status == QuestionPending {
+				out = append(out, q.copy())
+			}
+		}
+	}
+	slices.SortFunc(out, func(a, b HumanQuestion) int { return a.AskedAt.Compare(b.AskedAt) })
+	return out
+}
+
+// cancelQuestions cancels the questions of a run that ended before they
+// were answered, so agents and pollers waiting on them are released.
+func (e *AgentExecutor) cancelQuestions(sc *sessionContext) {
+	if sc == nil || sc.session == nil {
+		return
+	}
+	if n := e.questions.cancel(sc.session.ID); n > 0 {
+		e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeQuestionCancelled), time.Now())
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// DegradedProviderFailures is how many run attempts of a provider type must
+// fail in a row for it to be reported degraded.
+const DegradedProviderFailures = 3
+
+// DegradedProvider is a provider type whose recent runs keep failing.
+type DegradedProvider struct {
+	ProviderType        string
+	ConsecutiveFailures int
+	LastError           string
+	LastFailureAt       time.Time
+}
+
+// providerHealth counts the run attempts that failed in a row, by provider
+// type. A completed attempt clears the count; other endings leave it.
+type providerHealth struct {
+	mu     sync.Mutex
+	byType map[string]*DegradedProvider
+}
+
+func newProviderHealth() *providerHealth {
+	return &providerHealth{byType: make(map[string]*DegradedProvider)}
+}
+
+func (p *providerHealth) record(providerType, terminalReason, reason string, at time.Time) {
+	if p == nil || providerType == "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch terminalReason {
+	case "completed":
+		delete(p.byType, providerType)
+	case "failed":
+		f := p.byType[providerType]
+		if f == nil {
+			f = &DegradedProvider{ProviderType: providerType}
+			p.byType[providerType] = f
+		}
+		f.ConsecutiveFailures++
+		f.LastError = reason
+		f.LastFailureAt = at
+	}
+}
+
+func (p *providerHealth) degraded() []DegradedProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]DegradedProvider, 0)
+	for _, f := range p.byType {
+		if f.ConsecutiveFailures >= DegradedProviderFailures {
+			out = append(out, *f)
+		}
+	}
+	slices.SortFunc(out, func(a, b DegradedProvider) int { return b.LastFailureAt.Compare(a.LastFailureAt) })
+	return out
+}
+
+// InstanceOverview summarises what the server is doing, for an operations
+// dashboard.
+type InstanceOverview struct {
+	StartedAt time.Time
+	// ActiveRuns counts sessions with a live run; QueueDepth those whose
+	// run waits in a provider's batch queue.
+	ActiveRuns int
+	QueueDepth int
+	// PendingApprovals counts plans and commands waiting on a decision.
+	PendingApprovals  int
+	PendingQuestions  int
+	DegradedProviders []DegradedProvider
+}
+
+// InstanceOverview returns the executor's current activity.
+func (e *AgentExecutor) InstanceOverview() InstanceOverview {
+	o := InstanceOverview{
+		StartedAt:         e.startedAt,
+		PendingQuestions:  len(e.PendingQuestions()),
+		DegradedProviders: e.health.degraded(),
+	}
+
+	e.mu.RLock()
+	contexts := make([]*sessionContext, 0, len(e.sessions))
+	for _, sc := range e.sessions {
+		contexts = append(contexts, sc)
+	}
+	e.mu.RUnlock()
+
+	for _, sc := range contexts {
+		if sc.getRun() != nil {
+			o.ActiveRuns++
+		}
+		sc.amMu.Lock()
+		waitKind := ""
+		if sc.attempt != nil && sc.attempt.EndedAt == nil {
+			waitKind = sc.attempt.WaitKind
+		}
+		sc.amMu.Unlock()
+		switch waitKind {
+		case domain.WaitKindQueuedRemote:
+			o.QueueDepth++
+		case domain.WaitKindPlanApproval:
+			o.PendingApprovals++
+		}
+	}
+
+	e.commandWaiters.mu.Lock()
+	for _, waiting := range e.commandWaiters.bySession {
+		o.PendingApprovals += len(waiting)
+	}
+	e.commandWaiters.mu.Unlock()
+	e.toolApprovalWaiters.mu.Lock()
+	for _, waiting := range e.toolApprovalWaiters.bySession {
+		o.PendingApprovals += len(waiting)
+	}
+	e.toolApprovalWaiters.mu.Unlock()
+	return o
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestProviderHealth_Degraded(t *testing.T) {
+	h := newProviderHealth()
+	now := time.Now()
+	for i := range DegradedProviderFailures - 1 {
+		h.record("claude", "failed", "boom", now.Add(time.Duration(i)*time.Second))
+	}
+	h.record("claude", "cancelled", "", now)
+	if got := h.degraded(); len(got) != 0 {
+		t.Fatalf("degraded = %+v, want none below the threshold", got)
+	}
+	h.record("claude", "failed", "still broken", now.Add(time.Minute))
+	got := h.degraded()
+	if len(got) != 1 || got[0].ProviderType != "claude" || got[0].ConsecutiveFailures != DegradedProviderFailures || got[0].LastError != "still broken" {
+		t.Fatalf("degraded = %+v", got)
+	}
+	h.record("claude", "completed", "", now.Add(2*time.Minute))
+	if got := h.degraded(); len(got) != 0 {
+		t.Fatalf("degraded after a completed run = %+v, want none", got)
+	}
+}
+
+func TestAgentExecutor_InstanceOverview(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "s", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "s", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitForRunAttempt(t, store, "s", false)
+
+	o := executor.InstanceOverview()
+	if o.ActiveRuns != 1 || o.QueueDepth != 0 || o.PendingApprovals != 0 {
+		t.Fatalf("overview = %+v, want one active run", o)
+	}
+	if o.StartedAt.IsZero() || time.Since(o.StartedAt) > time.Minute {
+		t.Fatalf("started at = %v", o.StartedAt)
+	}
+
+	for range DegradedProviderFailures {
+		executor.health.record("mock", "failed", "boom", time.Now())
+	}
+	if got := executor.InstanceOverview().DegradedProviders; len(got) != 1 || got[0].ProviderType != "mock" {
+		t.Fatalf("degraded providers = %+v", got)
+	}
+}
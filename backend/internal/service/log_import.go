@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// ErrImportedSession refuses runs of a session imported from a log.
+var ErrImportedSession = errors.New("imported session is read-only")
+
+// ImportedLog is the history of a session another tool ran, such as a
+// Claude Code log.
+type ImportedLog struct {
+	ProviderType string
+	WorkingDir   string
+	Title        string
+	Metadata     map[string]string
+	Entries      []ImportedEntry
+}
+
+// ImportedEntry is a prompt the user sent, or an event the provider
+// emitted. At is when it happened, zero when the log does not say.
+type ImportedEntry struct {
+	Prompt string
+	Event  domain.Event
+	At     time.Time
+}
+
+// ImportLog creates a read-only session under newID holding the log's
+// history, projected into messages as a live run's events would be. The
+// session never runs; messages sent to it fail with ErrImportedSession.
+func (e *AgentExecutor) ImportLog(newID string, log ImportedLog) (*domain.Session, error) {
+	if e.draining.Load() {
+		return nil, ErrExecutorShutdown
+	}
+	if e.readOnlyMirror {
+		return nil, ErrReadOnlyMirror
+	}
+
+	sess := domain.NewSession(newID, log.ProviderType, log.WorkingDir)
+	sess.SetKind(domain.SessionKindImported)
+	sess.SetTitle(log.Title)
+	sess.Metadata = maps.Clone(log.Metadata)
+
+	e.mu.Lock()
+	if _, exists := e.sessions[newID]; exists {
+		e.mu.Unlock()
+		return nil, ErrSessionExists
+	}
+	sc := &sessionContext{session: sess}
+	e.sessions[newID] = sc
+	e.mu.Unlock()
+
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			e.mu.Lock()
+			delete(e.sessions, newID)
+			e.mu.Unlock()
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	for _, entry := range log.Entries {
+		if entry.Prompt != "" {
+			e.appendSessionMessage(sess, domain.MessageKindUser, entry.Prompt, entry.At)
+			continue
+		}
+		e.projectImportedEvent(sc, entry.Event)
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	e.suggest.put(sess)
+	return sess, nil
+}
+
+// projectImportedEvent records an imported event the way
+// updateSessionFromEvent records a live one, without acting on it: no
+// budgets are checked, plans are not held for approval, and nothing is
+// broadcast.
+func (e *AgentExecutor) projectImportedEvent(sc *sessionContext, event domain.Event) {
+	sess := sc.session
+	switch data := event.Data.(type) {
+	case domain.OutputData:
+		if data.IsDelta {
+			e.appendOutputDelta(sess, data.Content, event.Raw, event.Timestamp)
+		} else {
+			e.appendSessionMessageRaw(sess, domain.MessageKindOutput, data.Content, event.Raw, event.Timestamp)
+		}
+	case domain.ThoughtData:
+		e.appendSessionMessageRaw(sess, domain.MessageKindThought, data.Content, event.Raw, event.Timestamp)
+	case domain.ErrorData:
+		e.appendSessionMessageRaw(sess, domain.MessageKindError, data.Message, event.Raw, event.Timestamp)
+	case domain.ToolCallData:
+		e.appendSessionMessageRaw(sess, domain.MessageKindToolUse, toolUseContents(data), event.Raw, event.Timestamp)
+	case domain.MetadataData:
+		e.appendSessionMessageRaw(sess, domain.MessageKindSystem, data.Key, event.Raw, event.Timestamp)
+	case domain.MetricData:
+		e.recordPromptCacheUsage(sc, data)
+		e.recordUsage(sc, data)
+		e.appendSessionMessageRaw(sess, domain.MessageKindMetric, metricContents(data), event.Raw, event.Timestamp)
+	case domain.PlanData:
+		e.appendSessionMessageRaw(sess, domain.MessageKindPlan, planContents(data), event.Raw, event.Timestamp)
+	}
+}
+
+// checkImported refuses runs of imported sessions.
+func checkImported(sess *domain.Session) error {
+	if sess.IsImported() {
+		return ErrImportedSession
+	}
+	return nil
+}
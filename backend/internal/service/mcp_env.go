@@ -0,0 +1,79 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// DefaultAPITokenTTL bounds the API token of a run's MCP servers. Tokens are
+// revoked when the run ends; the TTL only limits a leaked token of a run
+// that never does.
+const DefaultAPITokenTTL = 12 * time.Hour
+
+// apiTokenPrefix marks the API tokens minted for runs, so they can be told
+// apart from other bearer tokens.
+const apiTokenPrefix = "omrun_"
+
+// IsRunAPIToken reports whether token has the form of a run's API token.
+func IsRunAPIToken(token string) bool {
+	return strings.HasPrefix(token, apiTokenPrefix)
+}
+
+// ResolveRunAPIToken returns the session a run's API token is scoped to, if
+// the token is live.
+func (e *AgentExecutor) ResolveRunAPIToken(token string) (string, bool) {
+	raw, ok := strings.CutPrefix(token, apiTokenPrefix)
+	if !ok || raw == "" {
+		return "", false
+	}
+	return e.apiTokens.lookup(raw, time.Now())
+}
+
+// renderMCPServers renders the env templates of a new run's MCP servers,
+// minting the run's API token.
+func (e *AgentExecutor) renderMCPServers(sess *domain.Session, config *session.Config) {
+	if len(config.MCPServers) == 0 {
+		return
+	}
+	env := e.mcpEnv(sess)
+	if token, err := e.apiTokens.mint(sess.ID, time.Now().Add(DefaultAPITokenTTL)); err == nil {
+		env.APIToken = apiTokenPrefix + token
+	}
+	servers, err := session.RenderMCPServers(config.MCPServers, env)
+	if err != nil {
+		log.Printf("session %s: %v", sess.ID, err)
+		return
+	}
+	config.MCPServers = servers
+}
+
+// mcpEnv is the template data of sess's MCP servers, without an API token.
+func (e *AgentExecutor) mcpEnv(sess *domain.Session) session.MCPEnv {
+	return session.MCPEnv{
+		SessionID:   sess.ID,
+		ProjectID:   sess.ProjectID,
+		ProjectPath: sess.ProjectPath,
+		WorkingDir:  sess.WorkingDir,
+		APIBaseURL:  e.apiBaseURL,
+	}
+}
+
+func mcpServersToDomain(servers []session.MCPServerConfig) []domain.MCPServer {
+	var out []domain.MCPServer
+	for _, s := range servers {
+		out = append(out, domain.MCPServer{Name: s.Name, Command: s.Command, Args: s.Args, Env: s.Env})
+	}
+	return out
+}
+
+func mcpServersFromDomain(servers []domain.MCPServer) []session.MCPServerConfig {
+	var out []session.MCPServerConfig
+	for _, s := range servers {
+		out = append(out, session.MCPServerConfig{Name: s.Name, Command: s.Command, Args: s.Args, Env: s.Env})
+	}
+	return out
+}
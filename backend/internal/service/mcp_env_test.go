@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_RendersMCPServerEnv(t *testing.T) {
+	var runServers []session.MCPServerConfig
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		APIBaseURL:  "http://orbitmesh.internal:9000/",
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			runServers = config.MCPServers
+			return newMockProvider(), nil
+		},
+	})
+	defer executor.Shutdown(context.Background())
+
+	cfg := session.Config{
+		ProviderType: "mock",
+		WorkingDir:   "/work/repo/app",
+		ProjectID:    "proj1",
+		ProjectPath:  "/work/repo",
+		MCPServers: []session.MCPServerConfig{{
+			Name:    "tools",
+			Command: "tools-mcp",
+			Env: map[string]string{
+				"API":     "{{.APIBaseURL}}/api/sessions/{{.SessionID}}",
+				"TOKEN":   "{{.APIToken}}",
+				"PROJECT": "{{.ProjectID}}:{{.ProjectPath}}",
+				"PLAIN":   "as-is",
+			},
+		}},
+	}
+	if _, err := executor.CreateSession(context.Background(), "s1", cfg); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "s1", "go", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	if len(runServers) != 1 {
+		t.Fatalf("run MCP servers = %+v", runServers)
+	}
+	env := runServers[0].Env
+	if env["API"] != "http://orbitmesh.internal:9000/api/sessions/s1" || env["PROJECT"] != "proj1:/work/repo" || env["PLAIN"] != "as-is" {
+		t.Fatalf("rendered env = %v", env)
+	}
+	if !IsRunAPIToken(env["TOKEN"]) {
+		t.Fatalf("TOKEN = %q", env["TOKEN"])
+	}
+	if id, ok := executor.ResolveRunAPIToken(env["TOKEN"]); !ok || id != "s1" {
+		t.Fatalf("ResolveRunAPIToken = %q, %v", id, ok)
+	}
+	sess, _ := executor.GetSession("s1")
+	if got := sess.GetMCPServers()[0].Env["TOKEN"]; got != "{{.APIToken}}" {
+		t.Fatalf("stored template = %q, want it unrendered", got)
+	}
+
+	executor.apiTokens.forget("s1")
+	if _, ok := executor.ResolveRunAPIToken(env["TOKEN"]); ok {
+		t.Fatal("revoked token was accepted")
+	}
+	if _, ok := executor.ResolveRunAPIToken("omrun_forged"); ok {
+		t.Fatal("forged token was accepted")
+	}
+}
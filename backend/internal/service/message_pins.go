@@ -0,0 +1,72 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// PinMessage pins a message of the session, or excerpt of it when set, as a
+// key outcome. messageID is an ID the messages API returns. Pinning the same
+// excerpt again returns the existing pin.
+func (e *AgentExecutor) PinMessage(id, messageID, excerpt, note, pinnedBy string) (domain.MessagePin, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return domain.MessagePin{}, err
+	}
+	messages, err := e.apiMessages(sess)
+	if err != nil {
+		return domain.MessagePin{}, err
+	}
+	var msg *domain.Message
+	for i := range messages {
+		if messages[i].ID == messageID {
+			msg = &messages[i]
+			break
+		}
+	}
+	if msg == nil {
+		return domain.MessagePin{}, fmt.Errorf("%w: %s", domain.ErrMessageNotFound, messageID)
+	}
+
+	pin, err := domain.NewMessagePin(newAttemptID(), *msg, excerpt, note, pinnedBy, time.Now().UTC())
+	if err != nil {
+		return domain.MessagePin{}, err
+	}
+	if pin, err = sess.AddMessagePin(pin); err != nil {
+		return domain.MessagePin{}, err
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return domain.MessagePin{}, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return pin, nil
+}
+
+// UnpinMessage removes a pin from the session.
+func (e *AgentExecutor) UnpinMessage(id, pinID string) error {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return err
+	}
+	if !sess.RemoveMessagePin(pinID) {
+		return domain.ErrMessagePinNotFound
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return nil
+}
+
+// MessagePins returns the session's pinned messages, oldest first.
+func (e *AgentExecutor) MessagePins(id string) ([]domain.MessagePin, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	return sess.GetMessagePins(), nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_MessagePins(t *testing.T) {
+	store, err := storage.NewJSONFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return newMockProvider(), nil
+		},
+	})
+	defer executor.Shutdown(context.Background())
+
+	sess, err := executor.CreateSession(context.Background(), "s1", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	now := time.Now()
+	executor.appendSessionMessage(sess, domain.MessageKindUser, "which database?", now)
+	executor.appendSessionMessage(sess, domain.MessageKindSystem, "Decision: use Postgres. The token is hunter2.", now)
+	_ = store.Save(sess)
+	messages, err := store.GetMessages("s1")
+	if err != nil || len(messages) != 2 {
+		t.Fatalf("GetMessages = %+v, %v", messages, err)
+	}
+
+	if _, err := executor.PinMessage("s1", "missing", "", "", "alice"); !errors.Is(err, domain.ErrMessageNotFound) {
+		t.Fatalf("pin unknown message = %v, want ErrMessageNotFound", err)
+	}
+	if _, err := executor.PinMessage("s1", messages[1].ID, "use MySQL", "", "alice"); !errors.Is(err, domain.ErrInvalidMessagePin) {
+		t.Fatalf("pin foreign excerpt = %v, want ErrInvalidMessagePin", err)
+	}
+
+	pin, err := executor.PinMessage("s1", messages[1].ID, "Decision: use Postgres.", "final call", "alice")
+	if err != nil {
+		t.Fatalf("PinMessage: %v", err)
+	}
+	if pin.MessageID != messages[1].ID || pin.Kind != domain.MessageKindSystem || pin.PinnedBy != "alice" || pin.Note != "final call" {
+		t.Fatalf("pin = %+v", pin)
+	}
+	again, err := executor.PinMessage("s1", messages[1].ID, "Decision: use Postgres.", "", "bob")
+	if err != nil || again.ID != pin.ID {
+		t.Fatalf("repinning = %+v, %v; want the existing pin", again, err)
+	}
+	whole, err := executor.PinMessage("s1", messages[0].ID, "", "", "alice")
+	if err != nil || whole.Excerpt != "which database?" {
+		t.Fatalf("whole-message pin = %+v, %v", whole, err)
+	}
+
+	bundle, err := executor.ExportSessionBundle("s1")
+	if err != nil {
+		t.Fatalf("ExportSessionBundle: %v", err)
+	}
+	if len(bundle.MessagePins) != 2 || bundle.MessagePins[0].ID != pin.ID {
+		t.Fatalf("bundle pins = %+v", bundle.MessagePins)
+	}
+
+	if err := executor.UnpinMessage("s1", whole.ID); err != nil {
+		t.Fatalf("UnpinMessage: %v", err)
+	}
+	if err := executor.UnpinMessage("s1", whole.ID); !errors.Is(err, domain.ErrMessagePinNotFound) {
+		t.Fatalf("second unpin = %v, want ErrMessagePinNotFound", err)
+	}
+
+	if _, err := executor.RedactMessages("s1", []string{messages[1].ID}, "", "alice"); err != nil {
+		t.Fatalf("RedactMessages: %v", err)
+	}
+	pins, err := executor.MessagePins("s1")
+	if err != nil || len(pins) != 1 || pins[0].Excerpt != domain.RedactedTombstone {
+		t.Fatalf("pins after redaction = %+v, %v", pins, err)
+	}
+	if _, err := executor.PinMessage("s1", messages[1].ID, "", "", "alice"); !errors.Is(err, domain.ErrInvalidMessagePin) {
+		t.Fatalf("pin redacted message = %v, want ErrInvalidMessagePin", err)
+	}
+
+	loaded, err := store.Load("s1")
+	if err != nil || len(loaded.GetMessagePins()) != 1 {
+		t.Fatalf("persisted pins = %+v, %v", loaded, err)
+	}
+}
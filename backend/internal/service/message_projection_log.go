@@ -13,6 +13,12 @@ func (e *AgentExecutor) appendSessionMessage(session *domain.Session, kind domai
 	e.appendToMessageLog(session.ID, storage.MessageProjectionAppend, kind, contents, nil, at)
 }
 
+// appendNotice appends a system message that has a notice code.
+func (e *AgentExecutor) appendNotice(session *domain.Session, kind domain.MessageKind, notice domain.Notice, at time.Time) {
+	session.AppendNotice(kind, notice)
+	e.appendNoticeToMessageLog(session.ID, kind, notice, at)
+}
+
 func (e *AgentExecutor) appendSessionMessageRaw(session *domain.Session, kind domain.MessageKind, contents string, raw json.RawMessage, at time.Time) {
 	session.AppendMessageRaw(kind, contents, raw)
 	e.appendToMessageLog(session.ID, storage.MessageProjectionAppendRaw, kind, contents, raw, at)
@@ -36,3 +42,17 @@ func (e *AgentExecutor) appendToMessageLog(sessionID string, projection storage.
 	}
 	_ = appender.AppendMessageLog(sessionID, projection, kind, contents, raw, at)
 }
+
+// appendNoticeToMessageLog logs a notice message, as its text alone when the
+// storage cannot keep the code.
+func (e *AgentExecutor) appendNoticeToMessageLog(sessionID string, kind domain.MessageKind, notice domain.Notice, at time.Time) {
+	appender, ok := e.storage.(storage.MessageNoticeAppender)
+	if !ok {
+		e.appendToMessageLog(sessionID, storage.MessageProjectionAppend, kind, notice.Text(), nil, at)
+		return
+	}
+	if at.IsZero() {
+		at = time.Now()
+	}
+	_ = appender.AppendNoticeLog(sessionID, kind, notice, at)
+}
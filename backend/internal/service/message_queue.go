@@ -0,0 +1,150 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// queueMessage holds a message sent to a busy session until its run ends or
+// its suspension resolves.
+func (e *AgentExecutor) queueMessage(id string, sess *domain.Session, content, providerID, providerType string, opts SendMessageOptions) (*domain.Session, error) {
+	if e.draining.Load() {
+		return sess, ErrExecutorShutdown
+	}
+	if e.readOnlyMirror {
+		return sess, ErrReadOnlyMirror
+	}
+	if err := checkImported(sess); err != nil {
+		return sess, err
+	}
+	if err := checkTakeover(sess, opts.Actor); err != nil {
+		return sess, err
+	}
+	messageID := opts.MessageID
+	if messageID == "" {
+		messageID = newAttemptID()
+	}
+	if _, err := sess.EnqueueMessage(domain.QueuedMessage{
+		MessageID:    messageID,
+		Content:      content,
+		ProviderID:   providerID,
+		ProviderType: providerType,
+		Deadline:     opts.Deadline,
+		LowPriority:  opts.LowPriority,
+		TokenBudget:  opts.TokenBudget,
+		BudgetAction: opts.BudgetAction,
+		QueuedBy:     opts.Actor,
+		QueuedAt:     time.Now().UTC(),
+	}); err != nil {
+		return sess, err
+	}
+	e.saveQueue(sess)
+
+	// The run may have ended while the message was being queued.
+	if sc, err := e.ensureSessionContext(id); err == nil {
+		e.deliverQueuedMessages(sc)
+	}
+	return sess, nil
+}
+
+// deliverQueuedMessages starts a run with the session's oldest queued
+// message once it is idle. A message the session cannot take yet is put
+// back; one it rejects is dropped with a notice and the next one is tried.
+// Deliveries to one session are serialised, so two callers never pop
+// messages for the same idle session and put one back out of order.
+func (e *AgentExecutor) deliverQueuedMessages(sc *sessionContext) {
+	sc.queueMu.Lock()
+	defer sc.queueMu.Unlock()
+	id := sc.session.ID
+	for sc.session.GetState() == domain.SessionStateIdle && sc.getRun() == nil {
+		msg, ok := sc.session.PopQueuedMessage()
+		if !ok {
+			return
+		}
+		opts := SendMessageOptions{
+			Deadline:     msg.Deadline,
+			LowPriority:  msg.LowPriority,
+			MessageID:    msg.MessageID,
+			TokenBudget:  msg.TokenBudget,
+			BudgetAction: msg.BudgetAction,
+			Actor:        msg.QueuedBy,
+		}
+		_, err := e.startRunWithMessage(e.ctx, id, sc.session, msg.Content, msg.ProviderID, msg.ProviderType, opts)
+		if errors.Is(err, ErrInvalidState) || errors.Is(err, ErrExecutorShutdown) {
+			sc.session.RequeueMessage(msg)
+			e.saveQueue(sc.session)
+			return
+		}
+		if err == nil {
+			e.saveQueue(sc.session)
+			return
+		}
+		log.Printf("session %s: queued message %s: %v", id, msg.MessageID, err)
+		e.appendNotice(sc.session, domain.MessageKindError, domain.NewNotice(domain.NoticeQueueDeliveryFailed, "message_id", msg.MessageID, "error", err.Error()), time.Now())
+		e.saveQueue(sc.session)
+	}
+}
+
+// discardQueuedMessages drops the session's queue when an operator stops
+// its run, so stopping a session does not start the next instruction.
+func (e *AgentExecutor) discardQueuedMessages(sc *sessionContext, reason string) {
+	dropped := sc.session.ClearQueuedMessages()
+	if len(dropped) == 0 {
+		return
+	}
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeQueueDiscarded, "count", strconv.Itoa(len(dropped)), "reason", reason), time.Now())
+	e.saveQueue(sc.session)
+}
+
+// saveQueue persists the session and publishes its queue length as a
+// "queued_messages" metadata event.
+func (e *AgentExecutor) saveQueue(sess *domain.Session) {
+	if e.storage != nil {
+		_ = e.saveSession(sess)
+	}
+	e.broadcaster.Broadcast(domain.NewMetadataEvent(sess.ID, "queued_messages", len(sess.GetQueuedMessages()), nil))
+}
+
+// QueuedMessages returns the messages waiting for the session's current run
+// to end, oldest first.
+func (e *AgentExecutor) QueuedMessages(id string) ([]domain.QueuedMessage, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	return sess.GetQueuedMessages(), nil
+}
+
+// RemoveQueuedMessage drops a message from the session's queue before it is
+// delivered.
+func (e *AgentExecutor) RemoveQueuedMessage(id, messageID string) error {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return err
+	}
+	if !sess.RemoveQueuedMessage(messageID) {
+		return fmt.Errorf("%w: %s", domain.ErrQueuedMessageNotFound, messageID)
+	}
+	e.saveQueue(sess)
+	return nil
+}
+
+// queuedMessageReceipt reports a message still waiting in the session's
+// queue.
+func (e *AgentExecutor) queuedMessageReceipt(id, messageID string) (MessageReceipt, bool) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return MessageReceipt{}, false
+	}
+	for _, msg := range sess.GetQueuedMessages() {
+		if msg.MessageID == messageID {
+			return MessageReceipt{MessageID: messageID, SessionID: id, SentAt: msg.QueuedAt, Delivery: DeliveryQueued}, true
+		}
+	}
+	return MessageReceipt{}, false
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_SendMessageQueuesWhileRunning(t *testing.T) {
+	executor, _, provs := newRecordingExecutor(t, ExecutorConfig{}, newMockProvider)
+	sess, err := executor.CreateSession(context.Background(), "s1", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "s1", "first", "", ""); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	for _, m := range []struct{ id, content string }{{"m2", "second"}, {"m3", "third"}, {"m4", "never mind"}} {
+		if _, err := executor.SendMessageWithOptions(context.Background(), "s1", m.content, "", "", SendMessageOptions{MessageID: m.id, Actor: "alice"}); err != nil {
+			t.Fatalf("queue %s: %v", m.id, err)
+		}
+	}
+	if _, err := executor.sendMessage(context.Background(), "s1", "internal", "", "", SendMessageOptions{}); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("internal send to a running session = %v, want ErrInvalidState", err)
+	}
+	if err := executor.RemoveQueuedMessage("s1", "m4"); err != nil {
+		t.Fatalf("RemoveQueuedMessage: %v", err)
+	}
+	if err := executor.RemoveQueuedMessage("s1", "m4"); !errors.Is(err, domain.ErrQueuedMessageNotFound) {
+		t.Fatalf("second removal = %v, want ErrQueuedMessageNotFound", err)
+	}
+
+	queued, err := executor.QueuedMessages("s1")
+	if err != nil || len(queued) != 2 || queued[0].MessageID != "m2" || queued[1].QueuedBy != "alice" {
+		t.Fatalf("queue = %+v, %v", queued, err)
+	}
+	if r, err := executor.MessageReceipt("s1", "m2"); err != nil || r.Delivery != DeliveryQueued {
+		t.Fatalf("receipt of a queued message = %+v, %v", r, err)
+	}
+	// Retrying a queued message does not queue it twice.
+	if _, err := executor.SendMessageWithOptions(context.Background(), "s1", "second", "", "", SendMessageOptions{MessageID: "m2"}); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if n := len(sess.GetQueuedMessages()); n != 2 {
+		t.Fatalf("queue after retry = %d messages, want 2", n)
+	}
+
+	close(waitForProvider(t, provs, 0).events)
+	waitForProvider(t, provs, 1)
+	waitFor(t, func() bool { return lastUserMessage(sess) == "second" })
+	if r := waitForDelivery(t, executor, "s1", "m2"); r.Delivery != DeliveryDelivered {
+		t.Fatalf("receipt = %+v, want delivered", r)
+	}
+	if queued := sess.GetQueuedMessages(); len(queued) != 1 || queued[0].MessageID != "m3" {
+		t.Fatalf("queue after first delivery = %+v", queued)
+	}
+
+	close(waitForProvider(t, provs, 1).events)
+	waitForProvider(t, provs, 2)
+	waitFor(t, func() bool { return lastUserMessage(sess) == "third" })
+	close(waitForProvider(t, provs, 2).events)
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateIdle })
+	if n := len(sess.GetQueuedMessages()); n != 0 {
+		t.Fatalf("queue after delivery = %d messages, want none", n)
+	}
+}
+
+func TestAgentExecutor_StopDiscardsQueuedMessages(t *testing.T) {
+	executor, _, _ := newRecordingExecutor(t, ExecutorConfig{}, newMockProvider)
+	sess, err := executor.CreateSession(context.Background(), "s1", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "s1", "first", "", ""); err != nil {
+		t.Fatalf("first message: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	if _, err := executor.SendMessage(context.Background(), "s1", "next", "", ""); err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+
+	if err := executor.StopSession(context.Background(), "s1"); err != nil {
+		t.Fatalf("StopSession: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateIdle })
+	time.Sleep(50 * time.Millisecond)
+	if n := len(sess.GetQueuedMessages()); n != 0 || lastUserMessage(sess) != "first" || sess.GetState() != domain.SessionStateIdle {
+		t.Fatalf("after stop: %d queued, last message %q, state %s", n, lastUserMessage(sess), sess.GetState())
+	}
+}
+
+func TestAgentExecutor_StartupDeliversQueuedMessages(t *testing.T) {
+	store := newMockStorage()
+	stored := domain.NewSession("s1", "mock", "/tmp/test")
+	if _, err := stored.EnqueueMessage(domain.QueuedMessage{MessageID: "m1", Content: "queued before restart", QueuedAt: time.Now().UTC()}); err != nil {
+		t.Fatalf("EnqueueMessage: %v", err)
+	}
+	if err := store.Save(stored); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	started := make(chan struct{}, 1)
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			started <- struct{}{}
+			return newMockProvider(), nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	t.Cleanup(func() { executor.Shutdown(context.Background()) })
+
+	if err := executor.Startup(context.Background()); err != nil {
+		t.Fatalf("Startup: %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected recovery to deliver the queued message")
+	}
+	sess, err := executor.GetSession("s1")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	waitFor(t, func() bool { return lastUserMessage(sess) == "queued before restart" })
+	if n := len(sess.GetQueuedMessages()); n != 0 {
+		t.Fatalf("queue after recovery = %d messages, want none", n)
+	}
+}
+
+func TestAgentExecutor_ConcurrentQueueDeliveryKeepsOrder(t *testing.T) {
+	executor, _, provs := newRecordingExecutor(t, ExecutorConfig{}, newMockProvider)
+	sess, err := executor.CreateSession(context.Background(), "s1", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, id := range []string{"m1", "m2", "m3", "m4"} {
+		if _, err := sess.EnqueueMessage(domain.QueuedMessage{MessageID: id, Content: id, QueuedAt: time.Now().UTC()}); err != nil {
+			t.Fatalf("EnqueueMessage: %v", err)
+		}
+	}
+	sc, err := executor.ensureSessionContext("s1")
+	if err != nil {
+		t.Fatalf("ensureSessionContext: %v", err)
+	}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() { executor.deliverQueuedMessages(sc) })
+	}
+	wg.Wait()
+
+	waitForProvider(t, provs, 0)
+	if got := lastUserMessage(sess); got != "m1" {
+		t.Fatalf("expected m1 delivered first, got %q", got)
+	}
+	var ids []string
+	for _, msg := range sess.GetQueuedMessages() {
+		ids = append(ids, msg.MessageID)
+	}
+	if !slices.Equal(ids, []string{"m2", "m3", "m4"}) {
+		t.Fatalf("expected m2, m3 and m4 still queued in order, got %v", ids)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+var ErrMessageNotFound = errors.New("message not found")
+
+// Delivery states of a sent message.
+const (
+	// DeliveryQueued: the message was accepted but has not reached the
+	// provider yet.
+	DeliveryQueued    = "queued"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// MessageReceipt reports whether a message sent with SendMessageOptions
+// MessageID reached the provider.
+type MessageReceipt struct {
+	MessageID string
+	SessionID string
+	AttemptID string
+	SentAt    time.Time
+	Delivery  string
+	Error     string
+}
+
+func receiptFromAttempt(a *storage.RunAttemptMetadata) MessageReceipt {
+	r := MessageReceipt{
+		MessageID: a.MessageID,
+		SessionID: a.SessionID,
+		AttemptID: a.AttemptID,
+		SentAt:    a.StartedAt,
+		Delivery:  a.Delivery,
+		Error:     a.DeliveryError,
+	}
+	// An attempt that ended before its message was handed over, such as one
+	// interrupted by a restart, never delivers it.
+	if r.Delivery == DeliveryQueued && a.EndedAt != nil {
+		r.Delivery = DeliveryFailed
+		r.Error = a.InterruptionReason
+	}
+	return r
+}
+
+// MessageReceipt returns the delivery receipt of a message sent to a
+// session.
+func (e *AgentExecutor) MessageReceipt(id, messageID string) (MessageReceipt, error) {
+	if _, err := e.GetSession(id); err != nil {
+		return MessageReceipt{}, err
+	}
+	if r, ok := e.findMessageReceipt(id, messageID); ok {
+		return r, nil
+	}
+	return MessageReceipt{}, ErrMessageNotFound
+}
+
+func (e *AgentExecutor) findMessageReceipt(id, messageID string) (MessageReceipt, bool) {
+	if messageID == "" {
+		return MessageReceipt{}, false
+	}
+	if r, ok := e.currentMessageReceipt(id, messageID); ok {
+		return r, true
+	}
+	if r, ok := e.queuedMessageReceipt(id, messageID); ok {
+		return r, true
+	}
+	if e.attemptStorage == nil {
+		return MessageReceipt{}, false
+	}
+	attempts, err := e.attemptStorage.ListRunAttempts(id)
+	if err != nil {
+		return MessageReceipt{}, false
+	}
+	for _, a := range attempts {
+		if a.MessageID == messageID {
+			return receiptFromAttempt(a), true
+		}
+	}
+	return MessageReceipt{}, false
+}
+
+// currentMessageReceipt checks the session's current attempt only, without
+// reading storage.
+func (e *AgentExecutor) currentMessageReceipt(id, messageID string) (MessageReceipt, bool) {
+	e.mu.RLock()
+	sc, ok := e.sessions[id]
+	e.mu.RUnlock()
+	if !ok {
+		return MessageReceipt{}, false
+	}
+	return sc.attemptMessageReceipt(messageID)
+}
+
+func (sc *sessionContext) attemptMessageReceipt(messageID string) (MessageReceipt, bool) {
+	if messageID == "" {
+		return MessageReceipt{}, false
+	}
+	sc.amMu.Lock()
+	defer sc.amMu.Unlock()
+	if sc.attempt == nil || sc.attempt.MessageID != messageID {
+		return MessageReceipt{}, false
+	}
+	return receiptFromAttempt(sc.attempt), true
+}
+
+// markMessageDelivery records whether the current attempt's message reached
+// the provider.
+func (e *AgentExecutor) markMessageDelivery(sc *sessionContext, err error) {
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		if a.MessageID == "" {
+			return
+		}
+		a.Delivery = DeliveryDelivered
+		if err != nil {
+			a.Delivery = DeliveryFailed
+			a.DeliveryError = err.Error()
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func waitForDelivery(t *testing.T, executor *AgentExecutor, sessionID, messageID string) MessageReceipt {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		r, err := executor.MessageReceipt(sessionID, messageID)
+		if err != nil {
+			t.Fatalf("MessageReceipt: %v", err)
+		}
+		if r.Delivery != DeliveryQueued {
+			return r
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for delivery of %s", messageID)
+	return MessageReceipt{}
+}
+
+func TestAgentExecutor_MessageReceipt(t *testing.T) {
+	prov := newMockProvider()
+	prov.startDelay = 100 * time.Millisecond
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "s", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	opts := SendMessageOptions{MessageID: "m1"}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "s", "hello", "", "", opts); err != nil {
+		t.Fatalf("SendMessageWithOptions: %v", err)
+	}
+	if r, err := executor.MessageReceipt("s", "m1"); err != nil || r.Delivery != DeliveryQueued {
+		t.Fatalf("receipt before delivery = %+v, %v; want queued", r, err)
+	}
+	if r := waitForDelivery(t, executor, "s", "m1"); r.Delivery != DeliveryDelivered || r.AttemptID == "" {
+		t.Fatalf("receipt = %+v, want delivered", r)
+	}
+
+	// A retry of a delivered message is not sent again, even though the
+	// session is now running.
+	if _, err := executor.SendMessageWithOptions(context.Background(), "s", "hello", "", "", opts); err != nil {
+		t.Fatalf("retried SendMessageWithOptions: %v", err)
+	}
+	attempts, _ := executor.RunAttempts("s")
+	if len(attempts) != 1 {
+		t.Fatalf("attempts = %d, want 1", len(attempts))
+	}
+
+	if _, err := executor.MessageReceipt("s", "unknown"); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf("unknown message: err = %v, want ErrMessageNotFound", err)
+	}
+	if _, err := executor.MessageReceipt("missing", "m1"); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("unknown session: err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestAgentExecutor_MessageReceipt_Failed(t *testing.T) {
+	prov := newMockProvider()
+	prov.startErr = errors.New("boom")
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "s", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "s", "hello", "", "", SendMessageOptions{MessageID: "m1"}); err != nil {
+		t.Fatalf("SendMessageWithOptions: %v", err)
+	}
+	if r := waitForDelivery(t, executor, "s", "m1"); r.Delivery != DeliveryFailed || r.Error != "boom" {
+		t.Fatalf("receipt = %+v, want failed with boom", r)
+	}
+}
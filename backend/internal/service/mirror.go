@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// ErrReadOnlyMirror is returned for operations a read-only mirror refuses:
+// creating, importing and running sessions.
+var ErrReadOnlyMirror = errors.New("instance is a read-only mirror")
+
+// MirrorSession stores bundle as the local copy of a session replicated
+// from another instance, under the session's own ID and replacing any
+// earlier copy. The executor must be a read-only mirror.
+func (e *AgentExecutor) MirrorSession(bundle *SessionBundle) (*domain.Session, error) {
+	if !e.readOnlyMirror {
+		return nil, fmt.Errorf("mirroring sessions requires a read-only mirror executor")
+	}
+	if bundle == nil || bundle.Session.ID == "" {
+		return nil, fmt.Errorf("%w: missing session", ErrInvalidSessionBundle)
+	}
+	if bundle.Version > SessionBundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSessionBundle, bundle.Version)
+	}
+
+	sess, err := e.storeSessionBundle(bundle.Session.ID, bundle, true)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.sessions[sess.ID] = &sessionContext{session: sess}
+	e.mu.Unlock()
+	return sess, nil
+}
+
+// UnmirrorSession removes the local copy of a session deleted on the
+// mirrored instance.
+func (e *AgentExecutor) UnmirrorSession(id string) error {
+	if !e.readOnlyMirror {
+		return fmt.Errorf("mirroring sessions requires a read-only mirror executor")
+	}
+	if _, err := e.GetSession(id); err != nil {
+		return err
+	}
+	if e.storage == nil {
+		return fmt.Errorf("deleting sessions requires session storage")
+	}
+	cleaner, _ := e.storage.(storage.CleanupStorage)
+	return e.removeStaleSession(cleaner, id, false, time.Time{})
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// ErrMissionsDisabled is returned for mission operations when no mission
+// storage is configured.
+var ErrMissionsDisabled = errors.New("missions are not configured")
+
+// ErrInvalidMission wraps a mission, membership change or log entry that
+// fails validation.
+var ErrInvalidMission = errors.New("invalid mission")
+
+// MissionMember is one session of a mission.
+type MissionMember struct {
+	SessionID string
+	Title     string
+	State     domain.SessionState
+	Usage     domain.UsageStats
+}
+
+// MissionReport is a mission with its sessions, the status derived from
+// them, and what they spent together against the mission's budget.
+type MissionReport struct {
+	Mission domain.Mission
+	Status  string
+	// Usage counts what the sessions spent since joining the mission,
+	// including sessions since removed or deleted.
+	Usage domain.UsageStats
+	// Exceeded names the budget limit reached, if any.
+	Exceeded string
+	Members  []MissionMember
+}
+
+// loadMissionBudgets enforces the budgets of the stored missions.
+func (e *AgentExecutor) loadMissionBudgets() {
+	if e.missions == nil {
+		return
+	}
+	missions, err := e.missions.List()
+	if err != nil {
+		log.Printf("missions: %v", err)
+		return
+	}
+	for _, m := range missions {
+		e.setMissionBudget(m.ID, m.CostBudget)
+	}
+}
+
+func (e *AgentExecutor) setMissionBudget(id string, budget *domain.CostBudget) {
+	e.missionCosts.mu.Lock()
+	defer e.missionCosts.mu.Unlock()
+	if budget == nil {
+		delete(e.missionCosts.budgets, id)
+	} else {
+		e.missionCosts.budgets[id] = *budget
+	}
+}
+
+// Missions reports every mission, in the order they were created.
+func (e *AgentExecutor) Missions() ([]MissionReport, error) {
+	if e.missions == nil {
+		return nil, ErrMissionsDisabled
+	}
+	missions, err := e.missions.List()
+	if err != nil {
+		return nil, err
+	}
+	sessions := e.ListSessions()
+	reports := make([]MissionReport, len(missions))
+	for i, m := range missions {
+		reports[i] = e.missionReport(m, sessions)
+	}
+	return reports, nil
+}
+
+// Mission reports one mission.
+func (e *AgentExecutor) Mission(id string) (MissionReport, error) {
+	if e.missions == nil {
+		return MissionReport{}, ErrMissionsDisabled
+	}
+	m, err := e.missions.Get(id)
+	if err != nil {
+		return MissionReport{}, err
+	}
+	return e.missionReport(*m, e.ListSessions()), nil
+}
+
+func (e *AgentExecutor) missionReport(m domain.Mission, sessions []*domain.Session) MissionReport {
+	usage, budget := e.missionCosts.get(m.ID)
+	report := MissionReport{Mission: m, Usage: usage, Members: []MissionMember{}}
+	report.Exceeded, _ = budget.Exceeded(usage)
+	var states []domain.SessionState
+	for _, sess := range sessions {
+		if sess.GetMissionID() != m.ID {
+			continue
+		}
+		snap := sess.Snapshot()
+		if derived, err := e.DeriveSessionState(snap.ID); err == nil {
+			snap.State = derived
+		}
+		states = append(states, snap.State)
+		member := MissionMember{SessionID: snap.ID, Title: snap.Title, State: snap.State}
+		if snap.Usage != nil {
+			member.Usage = *snap.Usage
+		}
+		report.Members = append(report.Members, member)
+	}
+	slices.SortFunc(report.Members, func(a, b MissionMember) int { return cmp.Compare(a.SessionID, b.SessionID) })
+	report.Status = domain.DeriveMissionStatus(states)
+	return report
+}
+
+// CreateMission stores a new mission with an empty log.
+func (e *AgentExecutor) CreateMission(m domain.Mission) (*domain.Mission, error) {
+	if e.missions == nil {
+		return nil, ErrMissionsDisabled
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMission, err)
+	}
+	e.missionMu.Lock()
+	defer e.missionMu.Unlock()
+
+	now := time.Now().UTC()
+	m.ID = newSessionID()
+	m.CreatedAt = now
+	m.UpdatedAt = now
+	m.Log = nil
+	if err := e.missions.Save(m); err != nil {
+		return nil, err
+	}
+	e.setMissionBudget(m.ID, m.CostBudget)
+	return &m, nil
+}
+
+// UpdateMission replaces the name, goal, project and budget of a mission,
+// keeping its log. Sessions suspended over the mission's budget resume once
+// it allows them to.
+func (e *AgentExecutor) UpdateMission(id string, m domain.Mission) (*domain.Mission, error) {
+	if e.missions == nil {
+		return nil, ErrMissionsDisabled
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMission, err)
+	}
+	e.missionMu.Lock()
+	existing, err := e.missions.Get(id)
+	if err != nil {
+		e.missionMu.Unlock()
+		return nil, err
+	}
+	m.ID = existing.ID
+	m.CreatedAt = existing.CreatedAt
+	m.UpdatedAt = time.Now().UTC()
+	m.Log = existing.Log
+	err = e.missions.Save(m)
+	if err == nil {
+		e.setMissionBudget(id, m.CostBudget)
+	}
+	e.missionMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	e.resumeWithinCostBudget(func(sess *domain.Session) bool { return sess.GetMissionID() == id })
+	return &m, nil
+}
+
+// DeleteMission removes a mission. Its sessions are kept and leave it.
+func (e *AgentExecutor) DeleteMission(id string) error {
+	if e.missions == nil {
+		return ErrMissionsDisabled
+	}
+	e.missionMu.Lock()
+	defer e.missionMu.Unlock()
+	if err := e.missions.Delete(id); err != nil {
+		return err
+	}
+	e.setMissionBudget(id, nil)
+	for _, sess := range e.ListSessions() {
+		if sess.GetMissionID() != id {
+			continue
+		}
+		sess.SetMissionID("")
+		if e.storage != nil {
+			if err := e.saveSession(sess); err != nil {
+				log.Printf("session %s: leaving deleted mission: %v", sess.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// AddMissionSession moves a session into a mission, noting it in the
+// mission's log. A session already in another mission must leave it first.
+// Only what the session spends from now on counts against the mission's
+// budget.
+func (e *AgentExecutor) AddMissionSession(id, sessionID, author string) (*domain.Mission, error) {
+	return e.changeMissionMember(id, sessionID, author, true)
+}
+
+// RemoveMissionSession takes a session out of a mission, noting it in the
+// mission's log. What it spent keeps counting against the mission's budget.
+func (e *AgentExecutor) RemoveMissionSession(id, sessionID, author string) (*domain.Mission, error) {
+	return e.changeMissionMember(id, sessionID, author, false)
+}
+
+func (e *AgentExecutor) changeMissionMember(id, sessionID, author string, join bool) (*domain.Mission, error) {
+	if e.missions == nil {
+		return nil, ErrMissionsDisabled
+	}
+	e.missionMu.Lock()
+	defer e.missionMu.Unlock()
+
+	m, err := e.missions.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := e.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	current := sess.GetMissionID()
+	text := "Session " + sessionID + " joined the mission."
+	switch {
+	case join && current == id, !join && current != id:
+		return m, nil
+	case join && current != "":
+		return nil, fmt.Errorf("%w: session %s is in mission %s", ErrInvalidMission, sessionID, current)
+	case join:
+		sess.SetMissionID(id)
+	default:
+		sess.SetMissionID("")
+		text = "Session " + sessionID + " left the mission."
+	}
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+
+	if author == "" {
+		author = "system"
+	}
+	now := time.Now().UTC()
+	_ = m.AddLog(domain.MissionLogEntry{At: now, Author: author, SessionID: sessionID, Text: text})
+	m.UpdatedAt = now
+	if err := e.missions.Save(*m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AppendMissionLog adds an entry to a mission's shared log.
+func (e *AgentExecutor) AppendMissionLog(id string, entry domain.MissionLogEntry) (domain.MissionLogEntry, error) {
+	if e.missions == nil {
+		return domain.MissionLogEntry{}, ErrMissionsDisabled
+	}
+	e.missionMu.Lock()
+	defer e.missionMu.Unlock()
+
+	m, err := e.missions.Get(id)
+	if err != nil {
+		return domain.MissionLogEntry{}, err
+	}
+	entry.At = time.Now().UTC()
+	if err := m.AddLog(entry); err != nil {
+		return domain.MissionLogEntry{}, fmt.Errorf("%w: %v", ErrInvalidMission, err)
+	}
+	m.UpdatedAt = entry.At
+	if err := e.missions.Save(*m); err != nil {
+		return domain.MissionLogEntry{}, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_MissionSharesBudgetAndLog(t *testing.T) {
+	var mu sync.Mutex
+	providers := map[string]*mockProvider{}
+	dir := t.TempDir()
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			providers[sessionID] = newMockProvider()
+			return providers[sessionID], nil
+		},
+		OperationTimeout: 5 * time.Second,
+		Missions:         storage.NewMissionStorage(dir),
+		MissionUsage:     storage.NewMissionUsageStorage(dir),
+	})
+	t.Cleanup(func() { executor.Shutdown(context.Background()) })
+	provider := func(id string) *mockProvider {
+		mu.Lock()
+		defer mu.Unlock()
+		return providers[id]
+	}
+
+	if _, err := executor.CreateMission(domain.Mission{}); !errors.Is(err, ErrInvalidMission) {
+		t.Fatalf("expected a mission without a name to be rejected, got %v", err)
+	}
+	mission, err := executor.CreateMission(domain.Mission{
+		Name:       "ship it",
+		CostBudget: &domain.CostBudget{LimitUSD: 1, Action: domain.CostBudgetCancel},
+	})
+	if err != nil {
+		t.Fatalf("CreateMission failed: %v", err)
+	}
+
+	ctx := context.Background()
+	var sessions []*domain.Session
+	for _, id := range []string{"a", "b"} {
+		sess, err := executor.CreateSession(ctx, id, session.Config{ProviderType: "test", WorkingDir: "/tmp"})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		if _, err := executor.AddMissionSession(mission.ID, id, ""); err != nil {
+			t.Fatalf("AddMissionSession failed: %v", err)
+		}
+		sessions = append(sessions, sess)
+	}
+	other, _ := executor.CreateMission(domain.Mission{Name: "other"})
+	if _, err := executor.AddMissionSession(other.ID, "a", ""); !errors.Is(err, ErrInvalidMission) {
+		t.Fatalf("expected a session to be in one mission at a time, got %v", err)
+	}
+	if report, _ := executor.Mission(mission.ID); report.Status != domain.MissionStatusIdle || len(report.Members) != 2 {
+		t.Fatalf("expected an idle mission of two sessions, got %+v", report)
+	}
+
+	a, b := sessions[0], sessions[1]
+	if _, err := executor.SendMessage(ctx, "a", "plan", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	waitFor(t, func() bool { return a.GetState() == domain.SessionStateRunning })
+	if report, _ := executor.Mission(mission.ID); report.Status != domain.MissionStatusRunning {
+		t.Fatalf("expected the mission to run with a member, got %s", report.Status)
+	}
+	provider("a").SendEvent(domain.NewMetricDataEvent("a", domain.MetricData{CostUSD: 0.6}, nil))
+	close(provider("a").events)
+	waitFor(t, func() bool { return a.GetState() == domain.SessionStateIdle })
+
+	if _, err := executor.SendMessage(ctx, "b", "build", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	waitFor(t, func() bool { return b.GetState() == domain.SessionStateRunning })
+	provider("b").SendEvent(domain.NewMetricDataEvent("b", domain.MetricData{CostUSD: 0.5}, nil))
+	waitFor(t, func() bool { return b.GetState() == domain.SessionStateIdle })
+
+	report, _ := executor.Mission(mission.ID)
+	if report.Exceeded != "$1.00" || report.Usage.CostUSD != 1.1 {
+		t.Fatalf("expected the members' spend to use up the mission budget, got %+v", report)
+	}
+	if _, err := executor.SendMessage(ctx, "a", "more", "", ""); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("expected new runs of any member to be rejected, got %v", err)
+	}
+
+	if _, err := executor.AppendMissionLog(mission.ID, domain.MissionLogEntry{Author: "agent", SessionID: "a", Text: "API done"}); err != nil {
+		t.Fatalf("AppendMissionLog failed: %v", err)
+	}
+	if _, err := executor.RemoveMissionSession(mission.ID, "b", ""); err != nil {
+		t.Fatalf("RemoveMissionSession failed: %v", err)
+	}
+	report, _ = executor.Mission(mission.ID)
+	if len(report.Members) != 1 || len(report.Mission.Log) != 4 || report.Mission.Log[2].Text != "API done" {
+		t.Fatalf("expected joins, the entry and the leave in the log, got %+v", report.Mission.Log)
+	}
+
+	if err := executor.DeleteMission(mission.ID); err != nil {
+		t.Fatalf("DeleteMission failed: %v", err)
+	}
+	if a.GetMissionID() != "" {
+		t.Fatal("expected the sessions to leave a deleted mission")
+	}
+}
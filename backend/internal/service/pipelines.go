@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// ErrPipelinesDisabled is returned for pipeline operations when no pipeline
+// storage is configured.
+var ErrPipelinesDisabled = errors.New("pipelines are not configured")
+
+// ErrInvalidPipeline wraps a pipeline that fails validation.
+var ErrInvalidPipeline = errors.New("invalid pipeline")
+
+// Pipelines returns every pipeline, in the order they were created.
+func (e *AgentExecutor) Pipelines() ([]domain.Pipeline, error) {
+	if e.pipelines == nil {
+		return nil, ErrPipelinesDisabled
+	}
+	return e.pipelines.List()
+}
+
+// Pipeline returns one pipeline with its latest runs.
+func (e *AgentExecutor) Pipeline(id string) (*domain.Pipeline, error) {
+	if e.pipelines == nil {
+		return nil, ErrPipelinesDisabled
+	}
+	return e.pipelines.Get(id)
+}
+
+// CreatePipeline stores a new pipeline with no runs.
+func (e *AgentExecutor) CreatePipeline(p domain.Pipeline) (*domain.Pipeline, error) {
+	if e.pipelines == nil {
+		return nil, ErrPipelinesDisabled
+	}
+	if err := e.validatePipeline(&p); err != nil {
+		return nil, err
+	}
+	e.pipelineMu.Lock()
+	defer e.pipelineMu.Unlock()
+
+	now := time.Now().UTC()
+	p.ID = newSessionID()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	p.Runs = nil
+	if err := e.pipelines.Save(p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdatePipeline replaces a pipeline's definition, keeping its runs. It is
+// refused while a run is in progress.
+func (e *AgentExecutor) UpdatePipeline(id string, p domain.Pipeline) (*domain.Pipeline, error) {
+	if e.pipelines == nil {
+		return nil, ErrPipelinesDisabled
+	}
+	if err := e.validatePipeline(&p); err != nil {
+		return nil, err
+	}
+	e.pipelineMu.Lock()
+	defer e.pipelineMu.Unlock()
+
+	existing, err := e.pipelines.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range existing.Runs {
+		if run.Status == domain.PipelineStatusRunning {
+			return nil, fmt.Errorf("%w: run %s of the pipeline is in progress", ErrInvalidState, run.ID)
+		}
+	}
+	p.ID = existing.ID
+	p.CreatedAt = existing.CreatedAt
+	p.UpdatedAt = time.Now().UTC()
+	p.Runs = existing.Runs
+	if err := e.pipelines.Save(p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeletePipeline removes a pipeline. The sessions of its runs are kept;
+// steps still running finish without starting the steps after them.
+func (e *AgentExecutor) DeletePipeline(id string) error {
+	if e.pipelines == nil {
+		return ErrPipelinesDisabled
+	}
+	e.pipelineMu.Lock()
+	defer e.pipelineMu.Unlock()
+	return e.pipelines.Delete(id)
+}
+
+func (e *AgentExecutor) validatePipeline(p *domain.Pipeline) error {
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPipeline, err)
+	}
+	for _, s := range p.Steps {
+		if s.ProviderType == "" {
+			continue
+		}
+		if _, err := e.ProviderCapabilities(s.ProviderType); err != nil {
+			return fmt.Errorf("%w: step %q: unknown provider type %q", ErrInvalidPipeline, s.Name, s.ProviderType)
+		}
+	}
+	return nil
+}
+
+// StartPipelineRun starts a run of the pipeline with input, starting a
+// session for each step that depends on no other. The run advances as its
+// steps' runs end.
+func (e *AgentExecutor) StartPipelineRun(ctx context.Context, id, input string) (*domain.PipelineRun, error) {
+	if e.pipelines == nil {
+		return nil, ErrPipelinesDisabled
+	}
+	if e.draining.Load() {
+		return nil, ErrExecutorShutdown
+	}
+	if e.readOnlyMirror {
+		return nil, ErrReadOnlyMirror
+	}
+	e.pipelineMu.Lock()
+	defer e.pipelineMu.Unlock()
+
+	p, err := e.pipelines.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	p.AddRun(p.NewRun(newAttemptID(), input, time.Now().UTC()))
+	run := &p.Runs[len(p.Runs)-1]
+	e.startPipelineSteps(ctx, p, run)
+	if err := e.pipelines.Save(*p); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// startPipelineSteps starts every step of the run that is ready. A step
+// that cannot be started fails, which may skip the steps after it.
+func (e *AgentExecutor) startPipelineSteps(ctx context.Context, p *domain.Pipeline, run *domain.PipelineRun) {
+	for {
+		now := time.Now().UTC()
+		ready := run.Advance(p, now)
+		if len(ready) == 0 {
+			return
+		}
+		for _, name := range ready {
+			if err := e.startPipelineStep(ctx, p, run, name); err != nil {
+				log.Printf("pipeline %s run %s: step %s: %v", p.ID, run.ID, name, err)
+				s := run.Step(name)
+				s.Status = domain.PipelineStatusFailed
+				s.Error = err.Error()
+				s.CompletedAt = now
+			}
+		}
+	}
+}
+
+// startPipelineStep starts the step's session with its rendered prompt.
+// The session is resolved like one created through the API, so it gets its
+// provider config, agent and project settings.
+func (e *AgentExecutor) startPipelineStep(ctx context.Context, p *domain.Pipeline, run *domain.PipelineRun, name string) error {
+	step, _ := p.Step(name)
+	prompt, err := step.RenderPrompt(run.PromptInput())
+	if err != nil {
+		return fmt.Errorf("rendering prompt: %w", err)
+	}
+	config, err := e.ResolveSessionRequest(SessionRequest{
+		ProviderType: step.ProviderType,
+		ProviderID:   step.ProviderID,
+		AgentID:      step.AgentID,
+		WorkingDir:   p.WorkingDir,
+		ProjectID:    p.ProjectID,
+		SystemPrompt: step.Role,
+		SessionKind:  domain.SessionKindPipeline,
+		Title:        "Pipeline: " + p.Name + " / " + name,
+	})
+	if err != nil {
+		return err
+	}
+	id := newSessionID()
+	if _, err := e.CreateSession(ctx, id, config); err != nil {
+		return err
+	}
+	s := run.Step(name)
+	s.SessionID = id
+	s.Status = domain.PipelineStatusRunning
+	s.StartedAt = time.Now().UTC()
+
+	pipelineID, runID := p.ID, run.ID
+	opts := SendMessageOptions{afterRun: func(completed bool) { e.finishPipelineStep(pipelineID, runID, name, id, completed) }}
+	_, err = e.sendMessage(ctx, id, prompt, "", "", opts)
+	return err
+}
+
+// finishPipelineStep records the end of a step's run, passing its session's
+// last output downstream, and starts the steps that were waiting on it.
+func (e *AgentExecutor) finishPipelineStep(pipelineID, runID, name, sessionID string, completed bool) {
+	e.pipelineMu.Lock()
+	defer e.pipelineMu.Unlock()
+
+	p, err := e.pipelines.Get(pipelineID)
+	if err != nil {
+		return
+	}
+	run := p.Run(runID)
+	if run == nil {
+		return
+	}
+	s := run.Step(name)
+	if s == nil || s.Status != domain.PipelineStatusRunning || s.SessionID != sessionID {
+		return
+	}
+	s.CompletedAt = time.Now().UTC()
+	if !completed {
+		s.Status = domain.PipelineStatusFailed
+		s.Error = "the step's run did not complete"
+	} else {
+		s.Status = domain.PipelineStatusCompleted
+		if sess, err := e.GetSession(sessionID); err == nil {
+			s.Output = lastOutputMessage(sess)
+		}
+		if len(s.Output) > domain.MaxPipelineStepOutput {
+			s.Output = strings.ToValidUTF8(s.Output[:domain.MaxPipelineStepOutput], "")
+		}
+	}
+	e.startPipelineSteps(e.ctx, p, run)
+	if err := e.pipelines.Save(*p); err != nil {
+		log.Printf("pipeline %s run %s: %v", pipelineID, runID, err)
+	}
+}
+
+// failInterruptedPipelineRuns fails the steps that were running when the
+// server stopped, since their runs' ends will never be seen, and the runs
+// with them.
+func (e *AgentExecutor) failInterruptedPipelineRuns() {
+	if e.pipelines == nil {
+		return
+	}
+	pipelines, err := e.pipelines.List()
+	if err != nil {
+		log.Printf("pipelines: %v", err)
+		return
+	}
+	now := time.Now().UTC()
+	for i := range pipelines {
+		p := &pipelines[i]
+		changed := false
+		for j := range p.Runs {
+			run := &p.Runs[j]
+			if run.Status != domain.PipelineStatusRunning {
+				continue
+			}
+			for k := range run.Steps {
+				s := &run.Steps[k]
+				switch s.Status {
+				case domain.PipelineStatusRunning, domain.PipelineStatusPending:
+					s.Status = domain.PipelineStatusFailed
+					s.Error = "interrupted by a server restart"
+					s.CompletedAt = now
+				}
+			}
+			run.Advance(p, now)
+			changed = true
+		}
+		if changed {
+			if err := e.pipelines.Save(*p); err != nil {
+				log.Printf("pipeline %s: %v", p.ID, err)
+			}
+		}
+	}
+}
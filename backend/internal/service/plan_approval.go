@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+var ErrNoPendingPlan = errors.New("no plan is awaiting approval")
+
+// runProviderCustom returns the provider config for a new run. Until a plan
+// is approved, runs ask Claude-based providers for plan mode so the agent
+// plans without editing anything.
+func runProviderCustom(sess *domain.Session) map[string]any {
+	if !sess.NeedsPlanApproval() {
+		return sess.ProviderCustom
+	}
+	custom := maps.Clone(sess.ProviderCustom)
+	if custom == nil {
+		custom = map[string]any{}
+	}
+	custom["permission_mode"] = "plan"
+	delete(custom, "dangerously_skip_permissions")
+	return custom
+}
+
+// holdForPlanApproval stops the run at the agent's plan when the session
+// requires plan approval, leaving it suspended until ApprovePlan.
+func (e *AgentExecutor) holdForPlanApproval(sc *sessionContext, plan string) {
+	run := sc.getRun()
+	if run == nil || !sc.session.NeedsPlanApproval() {
+		return
+	}
+
+	sc.session.ProposePlan(plan, time.Now().UTC())
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.WaitKind = domain.WaitKindPlanApproval
+		a.HeartbeatAt = time.Now().UTC()
+	})
+	e.finalizeRunAttempt(sc, "interrupted", "waiting for plan approval")
+	e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitPlanApproval))
+
+	run.Cancel()
+	e.wg.Go(func() { _ = run.Session.Kill() })
+}
+
+// ApprovePlan approves the plan a session is waiting on and starts a run
+// that carries it out. A non-empty edited plan replaces the agent's.
+func (e *AgentExecutor) ApprovePlan(ctx context.Context, id, edited, approvedBy string) (*domain.Session, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return nil, err
+	}
+	if sc.getRun() != nil {
+		return nil, fmt.Errorf("%w: session is still planning", ErrInvalidState)
+	}
+
+	plan, ok := sc.session.ApprovePlan(strings.TrimSpace(edited), approvedBy, time.Now().UTC())
+	if !ok {
+		return nil, ErrNoPendingPlan
+	}
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		if a.WaitKind == domain.WaitKindPlanApproval {
+			a.WaitKind = ""
+		}
+	})
+	notice := domain.NewNotice(domain.NoticePlanApproved, "approved_by", approvedBy, "edited", strconv.FormatBool(plan.Edited))
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, time.Now())
+	e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusPlanApproved))
+
+	return e.sendMessage(ctx, id, planApprovedPrompt(plan), "", "", SendMessageOptions{})
+}
+
+func planApprovedPrompt(plan domain.SessionPlan) string {
+	intro := "Your plan was approved. Carry it out now."
+	if plan.Edited {
+		intro = "Your plan was approved with edits. Carry out this version now."
+	}
+	return intro + "\n\n" + plan.Content
+}
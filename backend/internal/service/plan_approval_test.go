@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_PlanApprovalHoldsRunUntilApproved(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		provs   []*mockProvider
+		customs []map[string]any
+	)
+	store := newMockStorage()
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			prov := newMockProvider()
+			provs = append(provs, prov)
+			customs = append(customs, config.Custom)
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	defer executor.Shutdown(context.Background())
+
+	_, err := executor.CreateSession(context.Background(), "planned", session.Config{
+		ProviderType: "mock",
+		WorkingDir:   "/tmp/test",
+		Custom:       map[string]any{"dangerously_skip_permissions": true},
+		PlanApproval: true,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.ApprovePlan(context.Background(), "planned", "", "alice"); !errors.Is(err, ErrNoPendingPlan) {
+		t.Fatalf("approve without a plan = %v, want ErrNoPendingPlan", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "planned", "fix the bug", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitFor(t, func() bool {
+		sess, _ := executor.GetSession("planned")
+		return sess.GetState() == domain.SessionStateRunning
+	})
+
+	mu.Lock()
+	planning, planningCustom := provs[0], customs[0]
+	mu.Unlock()
+	if planningCustom["permission_mode"] != "plan" || planningCustom["dangerously_skip_permissions"] != nil {
+		t.Fatalf("planning run custom = %v, want plan mode", planningCustom)
+	}
+
+	planning.SendEvent(domain.NewPlanEvent("planned", domain.PlanData{Description: "1. find it\n2. fix it"}, nil))
+	waitFor(t, func() bool {
+		state, err := executor.DeriveSessionState("planned")
+		return err == nil && state == domain.SessionStateSuspended
+	})
+	attempt := waitForRunAttempt(t, store, "planned", true)
+	if attempt.WaitKind != domain.WaitKindPlanApproval {
+		t.Fatalf("attempt wait kind = %q, want plan_approval", attempt.WaitKind)
+	}
+	sess, _ := executor.GetSession("planned")
+	if plan := sess.GetPlan(); plan == nil || plan.Status != domain.PlanStatusProposed || plan.Content != "1. find it\n2. fix it" {
+		t.Fatalf("plan = %+v", plan)
+	}
+
+	if _, err := executor.ApprovePlan(context.Background(), "planned", "1. find it\n2. fix it\n3. add a test", "alice"); err != nil {
+		t.Fatalf("ApprovePlan: %v", err)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(provs) == 2 && sess.GetState() == domain.SessionStateRunning
+	})
+	mu.Lock()
+	executing, executingCustom := provs[1], customs[1]
+	mu.Unlock()
+	if executingCustom["permission_mode"] != nil || executingCustom["dangerously_skip_permissions"] != true {
+		t.Fatalf("approved run custom = %v, want the session's own config", executingCustom)
+	}
+	plan := sess.GetPlan()
+	if plan.Status != domain.PlanStatusApproved || !plan.Edited || plan.ApprovedBy != "alice" {
+		t.Fatalf("approved plan = %+v", plan)
+	}
+	messages := sess.Snapshot().Messages
+	if last := messages[len(messages)-1]; last.Kind != domain.MessageKindUser || !strings.HasSuffix(last.Contents, "3. add a test") {
+		t.Fatalf("last message = %+v, want the approved plan", last)
+	}
+
+	// Later plans in the approved session do not stop the run.
+	executing.SendEvent(domain.NewPlanEvent("planned", domain.PlanData{Description: "revised"}, nil))
+	time.Sleep(50 * time.Millisecond)
+	if state := sess.GetState(); state != domain.SessionStateRunning {
+		t.Fatalf("state after a later plan = %s, want running", state)
+	}
+}
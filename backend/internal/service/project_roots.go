@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// ErrProjectRootsUnsupported is returned when the session storage cannot keep
+// a project's sessions under a storage root of its own.
+var ErrProjectRootsUnsupported = errors.New("session storage does not support project storage roots")
+
+// ValidateProjectStorageRoot reports whether SetProjectStorageRoot could
+// route a project's sessions to dir, creating it if needed.
+func (e *AgentExecutor) ValidateProjectStorageRoot(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if _, ok := e.storage.(storage.ProjectRootStorage); !ok {
+		return ErrProjectRootsUnsupported
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("invalid storage root %q: %w", dir, err)
+	}
+	return nil
+}
+
+// SetProjectStorageRoot routes the sessions of projectID to dir, moving the
+// ones already stored under the project's previous root. An empty dir routes
+// them back to the base directory.
+func (e *AgentExecutor) SetProjectStorageRoot(projectID, dir string) error {
+	router, ok := e.storage.(storage.ProjectRootStorage)
+	if !ok {
+		if dir == "" {
+			return nil
+		}
+		return ErrProjectRootsUnsupported
+	}
+	return router.SetProjectRoot(projectID, dir)
+}
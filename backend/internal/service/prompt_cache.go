@@ -0,0 +1,79 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// Prompt cache pricing relative to uncached input tokens, as billed by
+// Anthropic: cache reads cost a tenth, cache writes a quarter more.
+const (
+	cacheReadPriceFactor  = 0.1
+	cacheWritePriceFactor = 1.25
+)
+
+// PromptCacheReport summarises how well a session's stable prompt prefix was
+// served from the provider's prompt cache.
+type PromptCacheReport struct {
+	PrefixHash string
+	PrefixLen  int
+	domain.PromptCacheStats
+	// HitRate is the fraction of prompt input tokens read from the cache.
+	HitRate float64
+	// SavedInputTokens is the uncached-input-token equivalent saved by
+	// caching, net of the surcharge paid for cache writes.
+	SavedInputTokens int64
+}
+
+// buildPromptPrefix joins the system prompt and project context into the
+// prefix that is sent unchanged on every run of a session.
+func buildPromptPrefix(systemPrompt, projectContext string) string {
+	parts := make([]string, 0, 2)
+	if s := strings.TrimSpace(systemPrompt); s != "" {
+		parts = append(parts, s)
+	}
+	if s := strings.TrimSpace(projectContext); s != "" {
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (e *AgentExecutor) recordPromptCacheUsage(sc *sessionContext, data domain.MetricData) {
+	// Only per-request usage (message_start) carries a request's full input;
+	// running totals and output-only deltas would double count.
+	if data.RequestCount == 0 {
+		return
+	}
+	sc.session.RecordPromptCacheUsage(data.TokensIn, data.CacheReadTokens, data.CacheCreationTokens)
+}
+
+// PromptCacheReport returns the prompt cache usage of a session.
+func (e *AgentExecutor) PromptCacheReport(id string) (PromptCacheReport, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return PromptCacheReport{}, err
+	}
+	snap := sess.Snapshot()
+
+	report := PromptCacheReport{PrefixLen: len(snap.PromptPrefix)}
+	if snap.PromptPrefix != "" {
+		sum := sha256.Sum256([]byte(snap.PromptPrefix))
+		report.PrefixHash = hex.EncodeToString(sum[:8])
+	}
+	if snap.PromptCache == nil {
+		return report, nil
+	}
+
+	stats := *snap.PromptCache
+	report.PromptCacheStats = stats
+	if total := stats.InputTokens + stats.CacheReadTokens + stats.CacheCreationTokens; total > 0 {
+		report.HitRate = float64(stats.CacheReadTokens) / float64(total)
+	}
+	saved := float64(stats.CacheReadTokens)*(1-cacheReadPriceFactor) -
+		float64(stats.CacheCreationTokens)*(cacheWritePriceFactor-1)
+	report.SavedInputTokens = int64(saved)
+	return report, nil
+}
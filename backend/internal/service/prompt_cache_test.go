@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestBuildPromptPrefix(t *testing.T) {
+	if got := buildPromptPrefix("  Be terse. ", "Project: demo"); got != "Be terse.\n\nProject: demo" {
+		t.Fatalf("prefix = %q", got)
+	}
+	if got := buildPromptPrefix("", "Project: demo"); got != "Project: demo" {
+		t.Fatalf("prefix without system prompt = %q", got)
+	}
+	if got := buildPromptPrefix("", " "); got != "" {
+		t.Fatalf("empty prefix = %q", got)
+	}
+}
+
+func TestAgentExecutor_PromptCacheReport(t *testing.T) {
+	prov := newMockProvider()
+	var mu sync.Mutex
+	var systemPrompts []string
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			systemPrompts = append(systemPrompts, config.SystemPrompt)
+			mu.Unlock()
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	defer executor.Shutdown(context.Background())
+
+	config := session.Config{
+		ProviderType:   "mock",
+		WorkingDir:     "/tmp/test",
+		SystemPrompt:   "Be terse.",
+		ProjectContext: "Project: demo",
+	}
+	if _, err := executor.CreateSession(context.Background(), "cached", config); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "cached", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	mu.Lock()
+	if len(systemPrompts) != 1 || systemPrompts[0] != "Be terse.\n\nProject: demo" {
+		t.Fatalf("provider system prompts = %q", systemPrompts)
+	}
+	mu.Unlock()
+
+	executor.mu.RLock()
+	sc := executor.sessions["cached"]
+	executor.mu.RUnlock()
+
+	executor.updateSessionFromEvent(sc, domain.NewMetricDataEvent("cached", domain.MetricData{TokensIn: 100, RequestCount: 1, CacheCreationTokens: 400}, nil))
+	executor.updateSessionFromEvent(sc, domain.NewMetricDataEvent("cached", domain.MetricData{TokensIn: 100, RequestCount: 1, CacheReadTokens: 400}, nil))
+	// Running totals carry no request count and must not be counted again.
+	executor.updateSessionFromEvent(sc, domain.NewMetricDataEvent("cached", domain.MetricData{TokensIn: 200, TokensOut: 50, CacheReadTokens: 400}, nil))
+
+	report, err := executor.PromptCacheReport("cached")
+	if err != nil {
+		t.Fatalf("PromptCacheReport: %v", err)
+	}
+	if report.PrefixHash == "" || report.PrefixLen != len("Be terse.\n\nProject: demo") {
+		t.Fatalf("prefix = %q/%d", report.PrefixHash, report.PrefixLen)
+	}
+	want := domain.PromptCacheStats{Requests: 2, InputTokens: 200, CacheReadTokens: 400, CacheCreationTokens: 400}
+	if report.PromptCacheStats != want {
+		t.Fatalf("stats = %+v, want %+v", report.PromptCacheStats, want)
+	}
+	if report.HitRate != 0.4 {
+		t.Fatalf("hit rate = %v, want 0.4", report.HitRate)
+	}
+	if report.SavedInputTokens != 260 {
+		t.Fatalf("saved = %d, want 260", report.SavedInputTokens)
+	}
+}
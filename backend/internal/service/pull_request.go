@@ -0,0 +1,347 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+const (
+	// pullRequestTimeout bounds pushing a session's branch and opening its
+	// pull request.
+	pullRequestTimeout = 2 * time.Minute
+
+	AuditActionPullRequest = "pull_request"
+)
+
+var (
+	// ErrInvalidPullRequest rejects a pull request that cannot be opened:
+	// no branch to push, no token, or a branch that is its own base.
+	ErrInvalidPullRequest = errors.New("invalid pull request")
+	// ErrPullRequestFailed reports a push or provider API call that failed.
+	ErrPullRequestFailed = errors.New("failed to open pull request")
+)
+
+// PullRequestSpec is what OpenPullRequest opens. Base overrides the
+// config's target branch.
+type PullRequestSpec struct {
+	Config domain.PullRequestConfig
+	Title  string
+	Body   string
+	Base   string
+	Draft  bool
+}
+
+// PullRequest is a pull request (or GitLab merge request) opened for a
+// session's branch.
+type PullRequest struct {
+	Provider string
+	URL      string
+	Number   int
+	Branch   string
+	Base     string
+}
+
+// PullRequestBranch is the branch a session's pull request is opened from:
+// its tracked branch, or whatever its working directory has checked out.
+func (e *AgentExecutor) PullRequestBranch(ctx context.Context, id string) (string, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return "", err
+	}
+	if g := sess.GetGitTracking(); g != nil {
+		return g.Branch, nil
+	}
+	if sess.WorkingDir == "" || remote.IsRemote(sess.WorkingDir) {
+		return "", fmt.Errorf("%w: session is not in a local git checkout", ErrInvalidPullRequest)
+	}
+	branch, err := runGit(ctx, sess.WorkingDir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil || branch == "" {
+		return "", fmt.Errorf("%w: session's working directory has no branch checked out", ErrInvalidPullRequest)
+	}
+	return branch, nil
+}
+
+// OpenPullRequest pushes the session's branch and opens a pull request for
+// it, publishing a "pull_request" metadata event and recording it in the
+// audit log.
+func (e *AgentExecutor) OpenPullRequest(ctx context.Context, id string, spec PullRequestSpec) (*PullRequest, error) {
+	cfg := spec.Config
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPullRequest, err)
+	}
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	branch, err := e.PullRequestBranch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, pullRequestTimeout)
+	defer cancel()
+	dir := sess.WorkingDir
+	remoteName := cfg.Remote
+	if remoteName == "" {
+		remoteName = "origin"
+	}
+	base := spec.Base
+	if base == "" {
+		base = cfg.Base
+	}
+	if base == "" {
+		base = defaultRemoteBranch(ctx, dir, remoteName)
+	}
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("%w: base must be a branch name", ErrInvalidPullRequest)
+	}
+	if base == branch {
+		return nil, fmt.Errorf("%w: branch %s is the pull request's base", ErrInvalidPullRequest, branch)
+	}
+	forge := e.pullRequestForges[cfg.Provider]
+	token, err := e.pullRequestToken(ctx, cfg.Provider, forge)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pushBranch(ctx, dir, remoteName, branch, pushCredentialEnv(cfg.Provider, forge.Host(cfg.Provider), token)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPullRequestFailed, err)
+	}
+	pr := &PullRequest{Provider: cfg.Provider, Branch: branch, Base: base}
+	if cfg.Provider == domain.PullRequestGitLab {
+		err = openGitLabMergeRequest(ctx, forge.API(cfg.Provider), cfg, token, spec, pr)
+	} else {
+		err = openGitHubPullRequest(ctx, forge.API(cfg.Provider), cfg, token, spec, pr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPullRequestFailed, err)
+	}
+
+	e.broadcaster.Broadcast(domain.NewMetadataEvent(id, domain.MetadataKeyPullRequest, map[string]any{
+		"url":    pr.URL,
+		"number": pr.Number,
+		"branch": pr.Branch,
+		"base":   pr.Base,
+	}, nil))
+	e.recordAudit(storage.AuditEntry{
+		Actor:     "session:" + id,
+		Action:    AuditActionPullRequest,
+		SessionID: id,
+		Targets:   []string{pr.URL},
+	})
+	return pr, nil
+}
+
+// defaultRemoteBranch is the branch the remote's HEAD points at, or "main"
+// when the clone does not know it.
+func defaultRemoteBranch(ctx context.Context, dir, remoteName string) string {
+	ref, err := runGit(ctx, dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remoteName+"/HEAD")
+	if err != nil {
+		return "main"
+	}
+	if branch, ok := strings.CutPrefix(ref, remoteName+"/"); ok && branch != "" {
+		return branch
+	}
+	return "main"
+}
+
+// PullRequestForge is the server's configuration for one pull request
+// provider. Projects only name a provider and repository, so the API host a
+// token is sent to is never chosen by a project.
+type PullRequestForge struct {
+	// APIURL defaults to https://api.github.com or https://gitlab.com/api/v4.
+	APIURL string
+	// Token, or the output of TokenCommand, authenticates pushes and API
+	// calls. Without either, the server's git credential is used when its
+	// Host is this forge's host.
+	Token        string
+	TokenCommand string
+}
+
+// API returns the forge's API base URL, without a trailing slash.
+func (f PullRequestForge) API(provider string) string {
+	if f.APIURL != "" {
+		return strings.TrimSuffix(f.APIURL, "/")
+	}
+	if provider == domain.PullRequestGitLab {
+		return "https://gitlab.com/api/v4"
+	}
+	return "https://api.github.com"
+}
+
+// Host is the forge's git host: the API URL's host, with GitHub's "api."
+// prefix removed.
+func (f PullRequestForge) Host(provider string) string {
+	u, err := url.Parse(f.API(provider))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Host, "api.")
+}
+
+// pullRequestToken returns the forge's token. The server's git credential
+// is only a fallback for the host it was configured for.
+func (e *AgentExecutor) pullRequestToken(ctx context.Context, provider string, forge PullRequestForge) (string, error) {
+	token, command := forge.Token, forge.TokenCommand
+	if token == "" && command == "" {
+		if e.gitCredConfig.Host == "" || e.gitCredConfig.Host != forge.Host(provider) {
+			return "", fmt.Errorf("%w: no %s token is configured", ErrInvalidPullRequest, provider)
+		}
+		token, command = e.gitCredConfig.Token, e.gitCredConfig.TokenCommand
+	}
+	if command == "" {
+		if token == "" {
+			return "", fmt.Errorf("%w: no %s token is configured", ErrInvalidPullRequest, provider)
+		}
+		return token, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, gitTokenCommandTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "sh", "-c", command).Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: token command failed: %v", ErrPullRequestFailed, err)
+	}
+	token = strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("%w: token command printed no token", ErrPullRequestFailed)
+	}
+	return token, nil
+}
+
+// pushBranch pushes branch from the checkout in dir to remoteName's URL
+// with credentials in env. The checkout belongs to the agent, so the push
+// runs from a server-owned bare repository that borrows its objects: the
+// checkout's hooks never see the credentials, and its config can neither
+// rewrite the URL nor change how it is reached.
+func pushBranch(ctx context.Context, dir, remoteName, branch string, env []string) error {
+	pushURL, err := runGit(ctx, dir, "config", "--get", "remote."+remoteName+".url")
+	if err != nil || pushURL == "" {
+		return fmt.Errorf("remote %s has no URL", remoteName)
+	}
+	if strings.HasPrefix(pushURL, "-") {
+		return fmt.Errorf("remote %s has an invalid URL", remoteName)
+	}
+	sha, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("branch %s has no commit", branch)
+	}
+	objects, err := runGit(ctx, dir, "rev-parse", "--path-format=absolute", "--git-path", "objects")
+	if err != nil {
+		return err
+	}
+
+	staging, err := os.MkdirTemp("", "orbitmesh-push-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	isolated := []string{"GIT_CONFIG_NOSYSTEM=1"}
+	if _, err := runGitEnv(ctx, staging, isolated, "init", "--quiet", "--bare"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(staging, "objects", "info", "alternates"), []byte(objects+"\n"), 0o644); err != nil {
+		return err
+	}
+	if _, err := runGitEnv(ctx, staging, isolated, "update-ref", "refs/heads/"+branch, sha); err != nil {
+		return err
+	}
+	_, err = runGitEnv(ctx, staging, append(isolated, env...), "-c", "core.hooksPath=/dev/null", "push", "--quiet", pushURL, "refs/heads/"+branch+":refs/heads/"+branch)
+	return err
+}
+
+// pushCredentialEnv answers git's HTTPS credential requests for host with
+// token, and nothing else. The token is passed in the environment so it
+// never appears in a command line.
+func pushCredentialEnv(provider, host, token string) []string {
+	username := "x-access-token"
+	if provider == domain.PullRequestGitLab {
+		username = "oauth2"
+	}
+	helper := `!f() { test "$1" = get || exit 0; echo username=` + username + `; echo "password=$ORBITMESH_PULL_REQUEST_TOKEN"; }; f`
+	return []string{
+		"ORBITMESH_PULL_REQUEST_TOKEN=" + token,
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=credential.https://" + host + ".helper",
+		"GIT_CONFIG_VALUE_0=" + helper,
+	}
+}
+
+func openGitHubPullRequest(ctx context.Context, api string, cfg domain.PullRequestConfig, token string, spec PullRequestSpec, pr *PullRequest) error {
+	var resp struct {
+		HTMLURL string `json:"html_url"`
+		Number  int    `json:"number"`
+	}
+	header := http.Header{
+		"Authorization": {"Bearer " + token},
+		"Accept":        {"application/vnd.github+json"},
+	}
+	err := postPullRequestAPI(ctx, api+"/repos/"+cfg.Repo+"/pulls", header, map[string]any{
+		"title": spec.Title,
+		"body":  spec.Body,
+		"head":  pr.Branch,
+		"base":  pr.Base,
+		"draft": spec.Draft,
+	}, &resp)
+	pr.URL, pr.Number = resp.HTMLURL, resp.Number
+	return err
+}
+
+func openGitLabMergeRequest(ctx context.Context, api string, cfg domain.PullRequestConfig, token string, spec PullRequestSpec, pr *PullRequest) error {
+	var resp struct {
+		WebURL string `json:"web_url"`
+		IID    int    `json:"iid"`
+	}
+	title := spec.Title
+	if spec.Draft {
+		title = "Draft: " + title
+	}
+	err := postPullRequestAPI(ctx, api+"/projects/"+url.PathEscape(cfg.Repo)+"/merge_requests", http.Header{"Private-Token": {token}}, map[string]any{
+		"title":         title,
+		"description":   spec.Body,
+		"source_branch": pr.Branch,
+		"target_branch": pr.Base,
+	}, &resp)
+	pr.URL, pr.Number = resp.WebURL, resp.IID
+	return err
+}
+
+// postPullRequestAPI posts body as JSON and decodes a 2xx response into out.
+func postPullRequestAPI(ctx context.Context, endpoint string, header http.Header, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s: %s", endpoint, resp.Status, strings.TrimSpace(string(raw)))
+	}
+	return json.Unmarshal(raw, out)
+}
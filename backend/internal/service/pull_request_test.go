@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestPullRequestToken_GitCredentialOnlyForItsHost(t *testing.T) {
+	e := &AgentExecutor{gitCredConfig: GitCredentialConfig{Host: "github.com", Token: "git-token"}}
+	ctx := context.Background()
+
+	token, err := e.pullRequestToken(ctx, domain.PullRequestGitHub, PullRequestForge{})
+	if err != nil || token != "git-token" {
+		t.Fatalf("expected the git token for api.github.com, got %q, %v", token, err)
+	}
+	forge := PullRequestForge{APIURL: "https://evil.example.com/api"}
+	if _, err := e.pullRequestToken(ctx, domain.PullRequestGitHub, forge); !errors.Is(err, ErrInvalidPullRequest) {
+		t.Fatalf("expected the git token withheld from another host, got %v", err)
+	}
+	if _, err := e.pullRequestToken(ctx, domain.PullRequestGitLab, PullRequestForge{}); !errors.Is(err, ErrInvalidPullRequest) {
+		t.Fatalf("expected the git token withheld from gitlab.com, got %v", err)
+	}
+	forge.Token = "forge-token"
+	if token, err := e.pullRequestToken(ctx, domain.PullRequestGitHub, forge); err != nil || token != "forge-token" {
+		t.Fatalf("expected the forge's own token, got %q, %v", token, err)
+	}
+}
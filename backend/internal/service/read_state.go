@@ -0,0 +1,131 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// DefaultReader is the user read marks are recorded for when a client does
+// not identify itself.
+const DefaultReader = "default"
+
+// readStateTracker keeps each user's last-read message position per session.
+type readStateTracker struct {
+	mu     sync.Mutex
+	store  *storage.ReadStateStorage
+	states storage.ReadStates
+}
+
+func newReadStateTracker(store *storage.ReadStateStorage) *readStateTracker {
+	t := &readStateTracker{store: store, states: storage.ReadStates{}}
+	if store != nil {
+		if loaded, err := store.Load(); err != nil {
+			log.Printf("read state: %v", err)
+		} else {
+			t.states = loaded
+		}
+	}
+	return t
+}
+
+func (t *readStateTracker) mark(sessionID, user string, mark storage.ReadMark) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	byUser := t.states[sessionID]
+	if byUser == nil {
+		byUser = make(map[string]storage.ReadMark)
+		t.states[sessionID] = byUser
+	}
+	byUser[user] = mark
+	t.saveLocked()
+}
+
+func (t *readStateTracker) get(sessionID, user string) storage.ReadMark {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.states[sessionID][user]
+}
+
+func (t *readStateTracker) forSession(sessionID string) map[string]storage.ReadMark {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]storage.ReadMark, len(t.states[sessionID]))
+	for user, mark := range t.states[sessionID] {
+		out[user] = mark
+	}
+	return out
+}
+
+func (t *readStateTracker) forget(sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.states[sessionID]; !ok {
+		return
+	}
+	delete(t.states, sessionID)
+	t.saveLocked()
+}
+
+func (t *readStateTracker) saveLocked() {
+	if t.store == nil {
+		return
+	}
+	if err := t.store.Save(t.states); err != nil {
+		log.Printf("read state: %v", err)
+	}
+}
+
+// MarkSessionRead records that user has seen the session's first position
+// messages. A negative position, or one past the end, marks every current
+// message read.
+func (e *AgentExecutor) MarkSessionRead(id, user string, position int) (storage.ReadMark, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return storage.ReadMark{}, err
+	}
+	if n := len(sess.Snapshot().Messages); position < 0 || position > n {
+		position = n
+	}
+	mark := storage.ReadMark{Position: position, ReadAt: time.Now().UTC()}
+	e.readState.mark(id, user, mark)
+	return mark, nil
+}
+
+// SessionReadState returns user's read mark for a session and how many agent
+// messages arrived after it.
+func (e *AgentExecutor) SessionReadState(snap domain.SessionSnapshot, user string) (storage.ReadMark, int) {
+	mark := e.readState.get(snap.ID, user)
+	return mark, unreadMessages(snap.Messages, mark.Position)
+}
+
+// SessionUnreadCounts returns, for every user who has read the session, how
+// many agent messages arrived since they last did.
+func (e *AgentExecutor) SessionUnreadCounts(snap domain.SessionSnapshot) map[string]int {
+	marks := e.readState.forSession(snap.ID)
+	if len(marks) == 0 {
+		return nil
+	}
+	out := make(map[string]int, len(marks))
+	for user, mark := range marks {
+		out[user] = unreadMessages(snap.Messages, mark.Position)
+	}
+	return out
+}
+
+// unreadMessages counts the agent-authored messages from position on; the
+// human's own messages and metrics are not news to them.
+func unreadMessages(messages []domain.Message, position int) int {
+	n := 0
+	for _, msg := range messages[min(max(position, 0), len(messages)):] {
+		if msg.Kind != domain.MessageKindUser && msg.Kind != domain.MessageKindMetric {
+			n++
+		}
+	}
+	return n
+}
@@ -3,19 +3,43 @@ package service
 import (
 	"context"
 	"fmt"
+	"log"
+	"slices"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/storage"
 )
 
+// recoveryProgressInterval throttles recovery progress notifications; the
+// start, every interrupted session and the end are always reported.
+const recoveryProgressInterval = 250 * time.Millisecond
+
+// RecoveryObserver is told how startup recovery is progressing. It is called
+// synchronously from recovery and must not block.
+type RecoveryObserver interface {
+	OnRecoveryProgress(report storage.RecoveryReport)
+}
+
 type recoveryManager struct {
 	executor *AgentExecutor
+	reports  *storage.RecoveryReportStorage
+
+	mu         sync.Mutex
+	report     *storage.RecoveryReport
+	lastNotify time.Time
+	observers  map[int64]RecoveryObserver
+	observerID int64
 }
 
-func newRecoveryManager(executor *AgentExecutor) *recoveryManager {
-	return &recoveryManager{executor: executor}
+func newRecoveryManager(executor *AgentExecutor, reports *storage.RecoveryReportStorage) *recoveryManager {
+	return &recoveryManager{
+		executor:  executor,
+		reports:   reports,
+		observers: make(map[int64]RecoveryObserver),
+	}
 }
 
 func (r *recoveryManager) OnStartup(ctx context.Context) error {
@@ -29,28 +53,30 @@ func (r *recoveryManager) OnStartup(ctx context.Context) error {
 		return nil
 	}
 
+	r.begin()
 	sessions, err := r.executor.storage.List()
 	if err != nil {
-		return fmt.Errorf("recovery list sessions: %w", err)
+		return r.finish(fmt.Errorf("recovery list sessions: %w", err))
 	}
+	r.update(true, func(report *storage.RecoveryReport) {
+		report.SessionsTotal = len(sessions)
+	})
 
 	now := time.Now().UTC()
 	for _, sess := range sessions {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return r.finish(ctx.Err())
 		default:
 		}
-		if sess == nil || sess.ID == "" {
-			continue
-		}
-		if r.executor.hasLiveRun(sess.ID) {
+		if sess == nil || sess.ID == "" || r.executor.hasLiveRun(sess.ID) {
+			r.update(false, func(report *storage.RecoveryReport) { report.SessionsScanned++ })
 			continue
 		}
 
 		attempts, err := r.executor.attemptStorage.ListRunAttempts(sess.ID)
 		if err != nil {
-			return fmt.Errorf("recovery list attempts for %s: %w", sess.ID, err)
+			return r.finish(fmt.Errorf("recovery list attempts for %s: %w", sess.ID, err))
 		}
 		sort.Slice(attempts, func(i, j int) bool {
 			if attempts[i].StartedAt.Equal(attempts[j].StartedAt) {
@@ -59,10 +85,16 @@ func (r *recoveryManager) OnStartup(ctx context.Context) error {
 			return attempts[i].StartedAt.Before(attempts[j].StartedAt)
 		})
 
+		rec := storage.RecoveredSession{SessionID: sess.ID}
 		for _, attempt := range attempts {
 			if attempt == nil || attempt.AttemptID == "" || attempt.EndedAt != nil {
 				continue
 			}
+			// A submitted batch keeps running remotely; wait for it again
+			// rather than count the run as lost.
+			if attempt.WaitKind == domain.WaitKindQueuedRemote && attempt.WaitRef != "" && r.executor.resumeQueuedRemote(sess, attempt) {
+				continue
+			}
 
 			reason := interruptionReasonForRecovery(attempt)
 			attempt.EndedAt = &now
@@ -70,14 +102,161 @@ func (r *recoveryManager) OnStartup(ctx context.Context) error {
 			attempt.InterruptionReason = reason
 			attempt.HeartbeatAt = now
 			if err := r.executor.attemptStorage.SaveRunAttempt(attempt); err != nil {
-				return fmt.Errorf("recovery save attempt %s/%s: %w", sess.ID, attempt.AttemptID, err)
+				return r.finish(fmt.Errorf("recovery save attempt %s/%s: %w", sess.ID, attempt.AttemptID, err))
 			}
+			rec.AttemptsClosed++
+
+			r.executor.appendNoticeToMessageLog(sess.ID, domain.MessageKindSystem, recoveryNoticeForAttempt(attempt), now)
+		}
+		if rec.AttemptsClosed > 0 {
+			r.executor.recoverInterruptedRun(ctx, sess, attempts, &rec)
+		}
+		// Messages queued before the restart would otherwise wait for a
+		// run that never comes.
+		if len(sess.GetQueuedMessages()) > 0 && !r.executor.hasLiveRun(sess.ID) {
+			if sc, err := r.executor.ensureSessionContext(sess.ID); err == nil {
+				r.executor.deliverQueuedMessages(sc)
+			}
+		}
+		r.update(rec.AttemptsClosed > 0, func(report *storage.RecoveryReport) {
+			report.SessionsScanned++
+			if rec.AttemptsClosed == 0 {
+				return
+			}
+			report.AttemptsClosed += rec.AttemptsClosed
+			if rec.Scheduled {
+				report.RunsScheduled++
+			}
+			report.Sessions = append(report.Sessions, rec)
+		})
+	}
+
+	return r.finish(nil)
+}
+
+// Report returns a copy of the current or most recent recovery report, or
+// nil if recovery never ran.
+func (r *recoveryManager) Report() *storage.RecoveryReport {
+	r.mu.Lock()
+	report := r.report
+	r.mu.Unlock()
+	if report != nil {
+		copied := copyRecoveryReport(*report)
+		return &copied
+	}
+	if r.reports == nil {
+		return nil
+	}
+	loaded, err := r.reports.Load()
+	if err != nil {
+		return nil
+	}
+	return loaded
+}
 
-			r.executor.appendToMessageLog(sess.ID, storage.MessageProjectionAppend, domain.MessageKindSystem, recoveryMessageForAttempt(attempt), nil, now)
+func (r *recoveryManager) begin() {
+	var previous *storage.ShutdownReport
+	if shutdowns := r.executor.shutdownReports; shutdowns != nil {
+		var err error
+		if previous, err = shutdowns.Take(); err != nil {
+			log.Printf("recovery: loading shutdown report: %v", err)
 		}
 	}
 
-	return nil
+	r.mu.Lock()
+	r.report = &storage.RecoveryReport{
+		StartedAt:        time.Now().UTC(),
+		Status:           storage.RecoveryStatusRunning,
+		Sessions:         []storage.RecoveredSession{},
+		PreviousShutdown: previous,
+	}
+	r.mu.Unlock()
+	r.persist()
+	r.update(true, func(*storage.RecoveryReport) {})
+}
+
+// update applies fn to the running report and notifies observers, at most
+// once per recoveryProgressInterval unless force is set.
+func (r *recoveryManager) update(force bool, fn func(*storage.RecoveryReport)) {
+	r.mu.Lock()
+	fn(r.report)
+	now := time.Now()
+	if !force && now.Sub(r.lastNotify) < recoveryProgressInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastNotify = now
+	report := copyRecoveryReport(*r.report)
+	observers := make([]RecoveryObserver, 0, len(r.observers))
+	for _, observer := range r.observers {
+		observers = append(observers, observer)
+	}
+	r.mu.Unlock()
+
+	for _, observer := range observers {
+		observer.OnRecoveryProgress(report)
+	}
+}
+
+// finish closes the report with err's outcome, persists it and returns err.
+func (r *recoveryManager) finish(err error) error {
+	r.update(true, func(report *storage.RecoveryReport) {
+		finishedAt := time.Now().UTC()
+		report.FinishedAt = &finishedAt
+		report.Status = storage.RecoveryStatusCompleted
+		if err != nil {
+			report.Status = storage.RecoveryStatusFailed
+			report.Error = err.Error()
+		}
+	})
+	r.persist()
+	return err
+}
+
+func (r *recoveryManager) persist() {
+	if r.reports == nil {
+		return
+	}
+	r.mu.Lock()
+	report := copyRecoveryReport(*r.report)
+	r.mu.Unlock()
+	_ = r.reports.Save(report)
+}
+
+func (r *recoveryManager) register(observer RecoveryObserver) func() {
+	r.mu.Lock()
+	r.observerID++
+	id := r.observerID
+	r.observers[id] = observer
+	r.mu.Unlock()
+	return func() {
+		r.mu.Lock()
+		delete(r.observers, id)
+		r.mu.Unlock()
+	}
+}
+
+func copyRecoveryReport(report storage.RecoveryReport) storage.RecoveryReport {
+	report.Sessions = slices.Clone(report.Sessions)
+	return report
+}
+
+// RecoveryReport returns the current or most recent startup recovery
+// report, or nil if recovery never ran.
+func (e *AgentExecutor) RecoveryReport() *storage.RecoveryReport {
+	if e == nil || e.recovery == nil {
+		return nil
+	}
+	return e.recovery.Report()
+}
+
+// RegisterRecoveryObserver subscribes observer to startup recovery progress
+// and returns a function that unsubscribes it.
+func (e *AgentExecutor) RegisterRecoveryObserver(observer RecoveryObserver) func() {
+	if observer == nil || e.recovery == nil {
+		return func() {}
+	}
+	return e.recovery.register(observer)
 }
 
 func interruptionReasonForRecovery(attempt *storage.RunAttemptMetadata) string {
@@ -90,10 +269,10 @@ func interruptionReasonForRecovery(attempt *storage.RunAttemptMetadata) string {
 	return "startup recovery: interrupted while running"
 }
 
-func recoveryMessageForAttempt(attempt *storage.RunAttemptMetadata) string {
-	reason := interruptionReasonForRecovery(attempt)
-	if attempt == nil || attempt.AttemptID == "" {
-		return fmt.Sprintf("[recovery] %s", reason)
+func recoveryNoticeForAttempt(attempt *storage.RunAttemptMetadata) domain.Notice {
+	if attempt == nil {
+		return domain.NewNotice(domain.NoticeRecoveryInterrupted)
 	}
-	return fmt.Sprintf("[recovery] %s (attempt=%s)", reason, attempt.AttemptID)
+	return domain.NewNotice(domain.NoticeRecoveryInterrupted,
+		"wait_kind", attempt.WaitKind, "wait_ref", attempt.WaitRef, "attempt", attempt.AttemptID)
 }
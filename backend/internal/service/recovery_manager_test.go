@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+type recordingRecoveryObserver struct {
+	mu      sync.Mutex
+	reports []storage.RecoveryReport
+}
+
+func (o *recordingRecoveryObserver) OnRecoveryProgress(report storage.RecoveryReport) {
+	o.mu.Lock()
+	o.reports = append(o.reports, report)
+	o.mu.Unlock()
+}
+
+func TestAgentExecutor_StartupRecovery_Report(t *testing.T) {
+	store := newMockStorage()
+	for _, id := range []string{"interrupted", "finished"} {
+		if err := store.Save(domain.NewSession(id, "claude", "/tmp")); err != nil {
+			t.Fatalf("save session failed: %v", err)
+		}
+	}
+	if err := store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID: "attempt-1",
+		SessionID: "interrupted",
+		StartedAt: time.Now().UTC().Add(-time.Minute),
+	}); err != nil {
+		t.Fatalf("save attempt failed: %v", err)
+	}
+
+	reports := storage.NewRecoveryReportStorage(t.TempDir())
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:         store,
+		Broadcaster:     NewEventBroadcaster(100),
+		RecoveryReports: reports,
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return newMockProvider(), nil
+		},
+	})
+	t.Cleanup(func() { executor.Shutdown(context.Background()) })
+
+	if executor.RecoveryReport() != nil {
+		t.Fatal("expected no report before recovery")
+	}
+	observer := &recordingRecoveryObserver{}
+	executor.RegisterRecoveryObserver(observer)
+	if err := executor.Startup(context.Background()); err != nil {
+		t.Fatalf("startup recovery failed: %v", err)
+	}
+
+	observer.mu.Lock()
+	progress := observer.reports
+	observer.mu.Unlock()
+	if len(progress) < 2 || progress[0].Status != storage.RecoveryStatusRunning {
+		t.Fatalf("expected running then final progress, got %+v", progress)
+	}
+
+	report := executor.RecoveryReport()
+	if report == nil || report.Status != storage.RecoveryStatusCompleted || report.FinishedAt == nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.SessionsTotal != 2 || report.SessionsScanned != 2 || report.AttemptsClosed != 1 || report.RunsScheduled != 0 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+	if len(report.Sessions) != 1 || report.Sessions[0].SessionID != "interrupted" || report.Sessions[0].Policy != string(RecoveryMarkInterrupted) {
+		t.Fatalf("unexpected sessions: %+v", report.Sessions)
+	}
+	if last := progress[len(progress)-1]; last.Status != storage.RecoveryStatusCompleted {
+		t.Fatalf("last progress status = %s", last.Status)
+	}
+
+	persisted, err := reports.Load()
+	if err != nil || persisted == nil || persisted.AttemptsClosed != 1 {
+		t.Fatalf("persisted report = %+v, err = %v", persisted, err)
+	}
+}
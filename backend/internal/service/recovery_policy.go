@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// RecoveryPolicy selects what startup recovery does with a run that was
+// still in flight when the server stopped. The attempt is always recorded as
+// interrupted first.
+type RecoveryPolicy string
+
+const (
+	// RecoveryMarkInterrupted only records the interruption; a human decides
+	// how to continue.
+	RecoveryMarkInterrupted RecoveryPolicy = "mark_interrupted"
+	// RecoveryAutoRetry sends the session's last user message again.
+	RecoveryAutoRetry RecoveryPolicy = "auto_retry"
+	// RecoveryAutoResume continues the interrupted conversation, for
+	// providers that implement session.RunResumer.
+	RecoveryAutoResume RecoveryPolicy = "auto_resume"
+)
+
+// maxRecoveryRestarts bounds how many interrupted attempts in a row startup
+// recovery restarts, so a run that crashes the server is not retried forever.
+const maxRecoveryRestarts = 2
+
+// ErrResumeUnsupported is returned when a run is resumed on a provider that
+// does not implement session.RunResumer.
+var ErrResumeUnsupported = errors.New("provider does not support resuming runs")
+
+// ParseRecoveryPolicy validates a policy name. The empty string is allowed
+// and means "use the default".
+func ParseRecoveryPolicy(s string) (RecoveryPolicy, error) {
+	switch p := RecoveryPolicy(strings.TrimSpace(s)); p {
+	case "", RecoveryMarkInterrupted, RecoveryAutoRetry, RecoveryAutoResume:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown recovery policy %q (want %s, %s or %s)", s, RecoveryMarkInterrupted, RecoveryAutoRetry, RecoveryAutoResume)
+	}
+}
+
+// recoveryPolicyFor resolves the policy for a session: its own override,
+// then the provider type's configured policy, then the executor default.
+func (e *AgentExecutor) recoveryPolicyFor(sess *domain.Session) RecoveryPolicy {
+	if p := RecoveryPolicy(sess.GetRecoveryPolicy()); p != "" {
+		return p
+	}
+	if p, ok := e.recoveryPolicies[sess.ProviderType]; ok && p != "" {
+		return p
+	}
+	if e.defaultRecovery != "" {
+		return e.defaultRecovery
+	}
+	return RecoveryMarkInterrupted
+}
+
+// SetSessionRecoveryPolicy sets or, with "", clears a session's recovery
+// policy override.
+func (e *AgentExecutor) SetSessionRecoveryPolicy(id string, policy RecoveryPolicy) (*domain.Session, error) {
+	if _, err := ParseRecoveryPolicy(string(policy)); err != nil {
+		return nil, err
+	}
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.SetRecoveryPolicy(string(policy))
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return sess, nil
+}
+
+// recoverInterruptedRun applies the session's recovery policy after its
+// latest attempt was marked interrupted; attempts are oldest first. What it
+// does is noted in the session's message log and recorded in rec.
+func (e *AgentExecutor) recoverInterruptedRun(ctx context.Context, sess *domain.Session, attempts []*storage.RunAttemptMetadata, rec *storage.RecoveredSession) {
+	policy := e.recoveryPolicyFor(sess)
+	rec.Policy = string(policy)
+	if policy == RecoveryMarkInterrupted {
+		return
+	}
+	note := func(notice domain.Notice) {
+		rec.Note = strings.TrimPrefix(notice.Text(), "[recovery] ")
+		e.appendNoticeToMessageLog(sess.ID, domain.MessageKindSystem, notice, time.Now())
+	}
+	if n := trailingRecoveryInterruptions(attempts); n > maxRecoveryRestarts {
+		note(domain.NewNotice(domain.NoticeRecoverySkippedRepeated, "policy", string(policy), "count", strconv.Itoa(n)))
+		return
+	}
+
+	var content string
+	if policy == RecoveryAutoRetry {
+		if content = lastUserMessage(sess); content == "" {
+			note(domain.NewNotice(domain.NoticeRecoverySkippedNoMessage, "policy", string(policy)))
+			return
+		}
+	}
+
+	sc, err := e.ensureSessionContext(sess.ID)
+	if err != nil {
+		note(domain.NewNotice(domain.NoticeRecoveryFailed, "policy", string(policy), "error", err.Error()))
+		return
+	}
+	if sc.session.GetState() != domain.SessionStateIdle {
+		e.transitionWithSave(sc, domain.SessionStateIdle, domain.NewNotice(domain.NoticeStatusRecovery))
+	}
+
+	if policy == RecoveryAutoResume {
+		note(domain.NewNotice(domain.NoticeRecoveryResuming))
+	} else {
+		note(domain.NewNotice(domain.NoticeRecoveryRetrying))
+	}
+	opts := SendMessageOptions{resume: policy == RecoveryAutoResume, retry: policy == RecoveryAutoRetry}
+	if _, err := e.startRunWithMessage(ctx, sess.ID, sc.session, content, sc.session.PreferredProviderID, "", opts); err != nil {
+		note(domain.NewNotice(domain.NoticeRecoveryFailed, "policy", string(policy), "error", err.Error()))
+		return
+	}
+	rec.Scheduled = true
+}
+
+// trailingRecoveryInterruptions counts the attempts at the end of attempts
+// that startup recovery marked interrupted.
+func trailingRecoveryInterruptions(attempts []*storage.RunAttemptMetadata) int {
+	n := 0
+	for i := len(attempts) - 1; i >= 0; i-- {
+		a := attempts[i]
+		if a == nil || a.TerminalReason != "interrupted" || !strings.HasPrefix(a.InterruptionReason, "startup recovery") {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+func lastUserMessage(sess *domain.Session) string {
+	messages := sess.Snapshot().Messages
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Kind == domain.MessageKindUser {
+			return messages[i].Contents
+		}
+	}
+	return ""
+}
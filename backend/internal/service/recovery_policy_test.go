@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// recordingProvider records how each run was started.
+type recordingProvider struct {
+	*mockProvider
+	mu      sync.Mutex
+	inputs  []string
+	resumed int
+}
+
+func (p *recordingProvider) SendInput(ctx context.Context, config session.Config, input string) (<-chan domain.Event, error) {
+	p.mu.Lock()
+	p.inputs = append(p.inputs, input)
+	p.mu.Unlock()
+	return p.mockProvider.SendInput(ctx, config, input)
+}
+
+func (p *recordingProvider) started() ([]string, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.inputs...), p.resumed
+}
+
+type resumableProvider struct {
+	*recordingProvider
+}
+
+func (p resumableProvider) ResumeRun(ctx context.Context, config session.Config) (<-chan domain.Event, error) {
+	p.mu.Lock()
+	p.resumed++
+	p.mu.Unlock()
+	return p.mockProvider.SendInput(ctx, config, "")
+}
+
+// startupWithInterruptedRun stores a session with one user message and
+// priorInterruptions earlier startup-interrupted attempts plus an unfinished
+// one, then runs startup recovery.
+func startupWithInterruptedRun(t *testing.T, cfg ExecutorConfig, prov session.Session, override RecoveryPolicy, priorInterruptions int) (*AgentExecutor, *mockStorage) {
+	t.Helper()
+	store := newMockStorage()
+	cfg.Storage = store
+	cfg.Broadcaster = NewEventBroadcaster(100)
+	cfg.ProviderFactory = func(providerType, sessionID string, config session.Config) (session.Session, error) {
+		return prov, nil
+	}
+	cfg.OperationTimeout = 5 * time.Second
+
+	sess := domain.NewSession("recover", "claude", "/tmp")
+	sess.AppendMessage(domain.MessageKindUser, "hello again")
+	sess.SetRecoveryPolicy(string(override))
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("save session failed: %v", err)
+	}
+	started := time.Now().UTC().Add(-time.Hour)
+	for i := range priorInterruptions {
+		ended := started.Add(time.Duration(i)*time.Minute + time.Second)
+		if err := store.SaveRunAttempt(&storage.RunAttemptMetadata{
+			AttemptID:          fmt.Sprintf("attempt-%d", i),
+			SessionID:          "recover",
+			StartedAt:          started.Add(time.Duration(i) * time.Minute),
+			EndedAt:            &ended,
+			TerminalReason:     "interrupted",
+			InterruptionReason: "startup recovery: interrupted while running",
+		}); err != nil {
+			t.Fatalf("save attempt failed: %v", err)
+		}
+	}
+	if err := store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID: "attempt-last",
+		SessionID: "recover",
+		StartedAt: started.Add(30 * time.Minute),
+	}); err != nil {
+		t.Fatalf("save attempt failed: %v", err)
+	}
+
+	executor := NewAgentExecutor(cfg)
+	t.Cleanup(func() { executor.Shutdown(context.Background()) })
+	if err := executor.Startup(context.Background()); err != nil {
+		t.Fatalf("startup recovery failed: %v", err)
+	}
+	return executor, store
+}
+
+func recoveryNotes(store *mockStorage) []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	var notes []string
+	for _, entry := range store.log {
+		if strings.HasPrefix(entry.contents, "[recovery] ") {
+			notes = append(notes, entry.contents)
+		}
+	}
+	return notes
+}
+
+func TestAgentExecutor_StartupRecovery_AutoRetry(t *testing.T) {
+	prov := &recordingProvider{mockProvider: newMockProvider()}
+	executor, store := startupWithInterruptedRun(t, ExecutorConfig{
+		RecoveryPolicies: map[string]RecoveryPolicy{"claude": RecoveryAutoRetry},
+	}, prov, "", 0)
+
+	waitFor(t, func() bool {
+		inputs, _ := prov.started()
+		return len(inputs) == 1
+	})
+	if inputs, _ := prov.started(); inputs[0] != "hello again" {
+		t.Fatalf("retried input = %q", inputs[0])
+	}
+	sess, err := executor.GetSession("recover")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	users := 0
+	for _, msg := range sess.Snapshot().Messages {
+		if msg.Kind == domain.MessageKindUser {
+			users++
+		}
+	}
+	if users != 1 {
+		t.Fatalf("transcript holds %d user messages, want the retried one once", users)
+	}
+	if attempt := waitForRunAttempt(t, store, "recover", false); attempt.AttemptID == "attempt-last" {
+		t.Fatal("expected a new run attempt")
+	}
+}
+
+func TestAgentExecutor_StartupRecovery_AutoResume(t *testing.T) {
+	t.Run("session override wins", func(t *testing.T) {
+		prov := resumableProvider{&recordingProvider{mockProvider: newMockProvider()}}
+		startupWithInterruptedRun(t, ExecutorConfig{
+			RecoveryPolicy:   RecoveryMarkInterrupted,
+			RecoveryPolicies: map[string]RecoveryPolicy{"claude": RecoveryAutoRetry},
+		}, prov, RecoveryAutoResume, 0)
+
+		waitFor(t, func() bool {
+			_, resumed := prov.started()
+			return resumed == 1
+		})
+		if inputs, _ := prov.started(); len(inputs) != 0 {
+			t.Fatalf("expected no input to be sent, got %q", inputs)
+		}
+	})
+
+	t.Run("provider without resume support", func(t *testing.T) {
+		prov := &recordingProvider{mockProvider: newMockProvider()}
+		_, store := startupWithInterruptedRun(t, ExecutorConfig{RecoveryPolicy: RecoveryAutoResume}, prov, "", 0)
+
+		notes := recoveryNotes(store)
+		if len(notes) == 0 || !strings.Contains(notes[len(notes)-1], ErrResumeUnsupported.Error()) {
+			t.Fatalf("expected resume failure note, got %q", notes)
+		}
+		if inputs, _ := prov.started(); len(inputs) != 0 {
+			t.Fatalf("expected no run, got inputs %q", inputs)
+		}
+	})
+}
+
+func TestAgentExecutor_StartupRecovery_RestartCap(t *testing.T) {
+	prov := &recordingProvider{mockProvider: newMockProvider()}
+	_, store := startupWithInterruptedRun(t, ExecutorConfig{RecoveryPolicy: RecoveryAutoRetry}, prov, "", maxRecoveryRestarts)
+
+	notes := recoveryNotes(store)
+	if len(notes) == 0 || !strings.Contains(notes[len(notes)-1], "skipped") {
+		t.Fatalf("expected skip note, got %q", notes)
+	}
+	if inputs, _ := prov.started(); len(inputs) != 0 {
+		t.Fatalf("expected no retry, got inputs %q", inputs)
+	}
+}
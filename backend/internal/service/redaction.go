@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// AuditActionRedactMessages is the audit action for message redactions.
+const AuditActionRedactMessages = "redact_messages"
+
+// RedactMessages replaces the given messages with a tombstone for cases
+// where a secret was pasted into a session. IDs are those the messages API
+// returns. Their contents and raw payloads are scrubbed from the saved
+// session, its message log, its pins and the event replay history, and the
+// redaction is recorded in the audit log. It returns how many messages were newly
+// redacted.
+func (e *AgentExecutor) RedactMessages(id string, messageIDs []string, reason, actor string) (int, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return 0, err
+	}
+	redacted, err := e.messagesToRedact(sc, messageIDs)
+	if err != nil || len(redacted) == 0 {
+		return 0, err
+	}
+	sc.session.RedactMessages(redacted)
+	targets := make([]string, 0, len(redacted))
+	for _, msg := range redacted {
+		targets = append(targets, msg.ID)
+	}
+	sc.session.RedactMessagePins(targets)
+
+	if e.storage != nil {
+		if err := e.saveSession(sc.session); err != nil {
+			return 0, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	if redactor, ok := e.storage.(storage.MessageLogRedactor); ok {
+		if _, err := redactor.RedactMessageLog(id, redacted, domain.RedactedTombstone); err != nil {
+			return 0, fmt.Errorf("failed to scrub message log: %w", err)
+		}
+	}
+	if e.broadcaster != nil {
+		e.broadcaster.DropHistory(id)
+	}
+	if err := e.dropEventLog(id); err != nil {
+		return 0, err
+	}
+
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    AuditActionRedactMessages,
+		SessionID: id,
+		Targets:   targets,
+		Reason:    reason,
+	})
+	return len(redacted), nil
+}
+
+// messagesToRedact looks the IDs up in the messages as the API serves them
+// and returns those not redacted yet. Every ID must exist.
+func (e *AgentExecutor) messagesToRedact(sc *sessionContext, messageIDs []string) ([]domain.Message, error) {
+	messages, err := e.apiMessages(sc.session)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]domain.Message, len(messages))
+	for _, msg := range messages {
+		byID[msg.ID] = msg
+	}
+
+	var out []domain.Message
+	seen := make(map[string]bool, len(messageIDs))
+	for _, msgID := range messageIDs {
+		msg, ok := byID[msgID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", domain.ErrMessageNotFound, msgID)
+		}
+		if msg.Redacted || seen[msgID] {
+			continue
+		}
+		seen[msgID] = true
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+// apiMessages returns the session's messages as the messages API serves
+// them, with the IDs it serves.
+func (e *AgentExecutor) apiMessages(sess *domain.Session) ([]domain.Message, error) {
+	if e.storage == nil {
+		return sess.Snapshot().Messages, nil
+	}
+	messages, err := e.storage.GetMessages(sess.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load messages: %w", err)
+	}
+	return messages, nil
+}
+
+func (e *AgentExecutor) recordAudit(entry storage.AuditEntry) {
+	if e.auditLog == nil {
+		return
+	}
+	if err := e.auditLog.Append(entry); err != nil {
+		log.Printf("audit log: %v", err)
+	}
+}
+
+// AuditEntries returns the audit log entries for a session, or every entry
+// when id is empty, oldest first.
+func (e *AgentExecutor) AuditEntries(id string) ([]storage.AuditEntry, error) {
+	if id != "" {
+		if _, err := e.GetSession(id); err != nil {
+			return nil, err
+		}
+	}
+	if e.auditLog == nil {
+		return []storage.AuditEntry{}, nil
+	}
+	return e.auditLog.List(id)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_RedactMessagesScrubsEverywhere(t *testing.T) {
+	baseDir := t.TempDir()
+	store, err := storage.NewJSONFileStorage(baseDir)
+	if err != nil {
+		t.Fatalf("storage: %v", err)
+	}
+	broadcaster := NewEventBroadcaster(100)
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:         store,
+		Broadcaster:     broadcaster,
+		AuditLogStorage: storage.NewAuditLogStorage(baseDir),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return newMockProvider(), nil
+		},
+	})
+	defer executor.Shutdown(context.Background())
+
+	sess, err := executor.CreateSession(context.Background(), "leaky", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	now := time.Now()
+	executor.appendSessionMessage(sess, domain.MessageKindUser, "my token is hunter2", now)
+	executor.appendOutputDelta(sess, "echo: hun", json.RawMessage(`{"delta":"hun"}`), now)
+	executor.appendOutputDelta(sess, "ter2", json.RawMessage(`{"delta":"ter2"}`), now)
+	executor.appendSessionMessage(sess, domain.MessageKindSystem, "harmless", now)
+	broadcaster.Broadcast(domain.NewOutputEvent("leaky", "hunter2", nil))
+	_ = store.Save(sess)
+
+	messages, err := store.GetMessages("leaky")
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if _, err := executor.RedactMessages("leaky", []string{messages[0].ID, "missing"}, "", "alice"); !errors.Is(err, domain.ErrMessageNotFound) {
+		t.Fatalf("redact with unknown id = %v, want ErrMessageNotFound", err)
+	}
+	n, err := executor.RedactMessages("leaky", []string{messages[0].ID, messages[1].ID}, "pasted a password", "alice")
+	if err != nil || n != 2 {
+		t.Fatalf("redact = %d, %v", n, err)
+	}
+	if n, _ := executor.RedactMessages("leaky", []string{messages[0].ID}, "", "alice"); n != 0 {
+		t.Fatalf("second redaction = %d, want 0", n)
+	}
+
+	saved, err := store.GetMessages("leaky")
+	if err != nil {
+		t.Fatalf("GetMessages: %v", err)
+	}
+	if saved[0].Contents != domain.RedactedTombstone || !saved[1].Redacted || saved[1].Raw != nil || saved[2].Contents != "harmless" {
+		t.Fatalf("saved messages = %+v", saved)
+	}
+
+	logData, err := os.ReadFile(filepath.Join(baseDir, "sessions", "leaky.messages.jsonl"))
+	if err != nil {
+		t.Fatalf("read message log: %v", err)
+	}
+	if strings.Contains(string(logData), "hunter2") || strings.Contains(string(logData), "hun") {
+		t.Fatalf("message log still holds the secret:\n%s", logData)
+	}
+	logged, err := store.ReadMessagesFromJSONL("leaky")
+	if err != nil || len(logged) != 3 || logged[1].Contents != domain.RedactedTombstone || logged[2].Contents != "harmless" {
+		t.Fatalf("rebuilt log = %+v, %v", logged, err)
+	}
+
+	if _, replay := broadcaster.SubscribeWithReplay("sub", "leaky", 0); len(replay) != 0 {
+		t.Fatalf("replay after redaction = %d events, want none", len(replay))
+	}
+
+	entries, err := executor.AuditEntries("leaky")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("audit entries = %+v, %v", entries, err)
+	}
+	if e := entries[0]; e.Action != AuditActionRedactMessages || e.Actor != "alice" || e.Reason != "pasted a password" || len(e.Targets) != 2 {
+		t.Fatalf("audit entry = %+v", e)
+	}
+}
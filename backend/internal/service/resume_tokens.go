@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// ErrResumeTokensUnlisted is returned when the resume token store cannot
+// list its tokens.
+var ErrResumeTokensUnlisted = errors.New("resume token storage does not support listing")
+
+// Resume token statuses, as reported by ResumeTokenStatus.
+const (
+	ResumeTokenActive   = "active"
+	ResumeTokenConsumed = "consumed"
+	ResumeTokenRevoked  = "revoked"
+	ResumeTokenExpired  = "expired"
+)
+
+// ResumeTokenStatus reports whether token can still resume its attempt at
+// now, or why not.
+func ResumeTokenStatus(token *storage.ResumeTokenMetadata, now time.Time) string {
+	switch {
+	case token.ConsumedAt != nil:
+		return ResumeTokenConsumed
+	case token.RevokedAt != nil:
+		return ResumeTokenRevoked
+	case !token.ExpiresAt.IsZero() && now.After(token.ExpiresAt):
+		return ResumeTokenExpired
+	default:
+		return ResumeTokenActive
+	}
+}
+
+// ResumeTokens lists the resume tokens minted for the session's run
+// attempts, oldest first, spent ones included.
+func (e *AgentExecutor) ResumeTokens(sessionID string) ([]*storage.ResumeTokenMetadata, error) {
+	if _, err := e.GetSession(sessionID); err != nil {
+		return nil, err
+	}
+	lister, ok := e.resumeTokenStorage.(storage.ResumeTokenLister)
+	if !ok {
+		return nil, ErrResumeTokensUnlisted
+	}
+	all, err := lister.ListResumeTokens()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resume tokens: %w", err)
+	}
+	tokens := make([]*storage.ResumeTokenMetadata, 0, len(all))
+	for _, t := range all {
+		if t.SessionID == sessionID {
+			tokens = append(tokens, t)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].TokenID < tokens[j].TokenID
+		}
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// RevokeResumeToken invalidates a resume token so it can no longer resume
+// its attempt. The session stays suspended. Revoking a token that is
+// already spent changes nothing.
+func (e *AgentExecutor) RevokeResumeToken(tokenID, reason, actor string) (*storage.ResumeTokenMetadata, error) {
+	if e.resumeTokenStorage == nil {
+		return nil, storage.ErrResumeTokenNotFound
+	}
+	token, err := e.resumeTokenStorage.LoadResumeToken(tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if token.ConsumedAt != nil || token.RevokedAt != nil {
+		return token, nil
+	}
+
+	reason = strings.TrimSpace(reason)
+	now := time.Now().UTC()
+	token.RevokedAt = &now
+	token.RevocationReason = "revoked by operator"
+	if reason != "" {
+		token.RevocationReason = reason
+	}
+	if err := e.resumeTokenStorage.SaveResumeToken(token); err != nil {
+		return nil, fmt.Errorf("failed to update resume token: %w", err)
+	}
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    "resume_token_revoke",
+		SessionID: token.SessionID,
+		Targets:   []string{token.TokenID},
+		Reason:    reason,
+	})
+	return token, nil
+}
@@ -32,7 +32,7 @@ func newResumeTokenID() string {
 	return hex.EncodeToString(b[:])
 }
 
-func (e *AgentExecutor) startRunAttempt(sc *sessionContext, providerType, providerID string) {
+func (e *AgentExecutor) startRunAttempt(sc *sessionContext, providerType, providerID, messageID string) {
 	if e == nil || e.attemptStorage == nil || sc == nil || sc.session == nil {
 		return
 	}
@@ -47,6 +47,10 @@ func (e *AgentExecutor) startRunAttempt(sc *sessionContext, providerType, provid
 		HeartbeatAt:   now,
 		BootID:        e.bootID,
 	}
+	if messageID != "" {
+		attempt.MessageID = messageID
+		attempt.Delivery = DeliveryQueued
+	}
 	if attempt.AttemptID == "" {
 		attempt.AttemptID = now.Format("20060102150405")
 	}
@@ -75,6 +79,12 @@ func (e *AgentExecutor) markRunAttemptWaiting(sc *sessionContext, kind, ref stri
 }
 
 func (e *AgentExecutor) mintResumeTokenForAttempt(attempt *storage.RunAttemptMetadata) string {
+	return e.mintResumeTokenUntil(attempt, time.Now().UTC().Add(e.resumeTokenTTL))
+}
+
+// mintResumeTokenUntil mints a resume token for attempt that expires at
+// expiresAt instead of after the configured TTL.
+func (e *AgentExecutor) mintResumeTokenUntil(attempt *storage.RunAttemptMetadata, expiresAt time.Time) string {
 	if e == nil || e.resumeTokenStorage == nil || attempt == nil {
 		return ""
 	}
@@ -84,7 +94,7 @@ func (e *AgentExecutor) mintResumeTokenForAttempt(attempt *storage.RunAttemptMet
 		SessionID: attempt.SessionID,
 		AttemptID: attempt.AttemptID,
 		CreatedAt: now,
-		ExpiresAt: now.Add(e.resumeTokenTTL),
+		ExpiresAt: expiresAt.UTC(),
 	}
 	if token.TokenID == "" {
 		token.TokenID = now.Format("20060102150405")
@@ -96,6 +106,14 @@ func (e *AgentExecutor) mintResumeTokenForAttempt(attempt *storage.RunAttemptMet
 }
 
 func (e *AgentExecutor) finalizeRunAttempt(sc *sessionContext, terminalReason, interruptionReason string) {
+	e.cancelQuestions(sc)
+	e.rejectPendingCommands(sc)
+	e.denyPendingToolApprovals(sc)
+	if sc != nil && sc.session != nil {
+		e.gitCredentials.forget(sc.session.ID)
+		e.apiTokens.forget(sc.session.ID)
+		sc.endRunSpan(terminalReason, interruptionReason)
+	}
 	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
 		if a.EndedAt != nil {
 			return
@@ -105,6 +123,7 @@ func (e *AgentExecutor) finalizeRunAttempt(sc *sessionContext, terminalReason, i
 		a.TerminalReason = terminalReason
 		a.InterruptionReason = interruptionReason
 		a.HeartbeatAt = now
+		e.health.record(a.ProviderType, terminalReason, interruptionReason, now)
 	})
 }
 
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// MaxBudgetContinuations is how many fresh runs a token budget may continue
+// into; once they are used up the budget stops the run.
+const MaxBudgetContinuations = 5
+
+// budgetWarningPercent is how much of a stopping budget a run uses before
+// the agent is warned.
+const budgetWarningPercent = 90
+
+// budgetSummaryGrace is how far past its budget, in percent, a run may go
+// while writing its summary before it is stopped without one.
+const budgetSummaryGrace = 25
+
+// runBudget tracks a run's token usage against the budget it was started
+// with.
+type runBudget struct {
+	run    *session.Run
+	limit  int64
+	action string
+	// base is the session's token usage when the run started, and
+	// messages its message count, so the run's own share can be told.
+	base     int64
+	messages int
+	// request is the message that started the work, carried through every
+	// continuation.
+	request       string
+	continuations int
+
+	mu sync.Mutex
+	// attemptID is the run's attempt, which the continuation links back to.
+	attemptID string
+	warned    bool
+	// summarizing is set once the agent was asked to summarize, at
+	// summaryStart messages; continuing once the run should continue.
+	summarizing  bool
+	summaryStart int
+	continuing   bool
+	stopped      bool
+	// stopping is done once stopBudgetRun has settled the stopped run.
+	stopping sync.WaitGroup
+}
+
+func budgetTokens(u domain.UsageStats) int64 {
+	return u.InputTokens + u.OutputTokens
+}
+
+// startRunBudget tracks the run's usage when opts give it a token budget.
+// It returns nil otherwise.
+func (e *AgentExecutor) startRunBudget(sc *sessionContext, run *session.Run, content string, opts SendMessageOptions) *runBudget {
+	if opts.TokenBudget <= 0 {
+		return nil
+	}
+	b := &runBudget{
+		run:           run,
+		limit:         opts.TokenBudget,
+		action:        opts.BudgetAction,
+		base:          budgetTokens(sc.session.GetUsage()),
+		messages:      len(sc.session.Snapshot().Messages),
+		request:       content,
+		continuations: opts.budgetContinuations,
+	}
+	if opts.budgetRequest != "" {
+		b.request = opts.budgetRequest
+	}
+	sc.amMu.Lock()
+	if sc.attempt != nil {
+		b.attemptID = sc.attempt.AttemptID
+		if opts.continuedFrom != "" {
+			sc.attempt.ContinuedFrom = opts.continuedFrom
+		}
+	}
+	sc.amMu.Unlock()
+	if opts.continuedFrom != "" {
+		e.updateRunAttempt(sc, func(*storage.RunAttemptMetadata) {})
+	}
+	sc.budget.Store(b)
+	return b
+}
+
+// checkRunBudget acts on the usage of the session's current run: it warns
+// the agent near a stopping budget, and once the budget is used up either
+// stops the run or has it summarize for a fresh run.
+func (e *AgentExecutor) checkRunBudget(sc *sessionContext) {
+	b := sc.budget.Load()
+	if b == nil || sc.getRun() != b.run {
+		return
+	}
+	used := budgetTokens(sc.session.GetUsage()) - b.base
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return
+	}
+	canContinue := b.action == domain.BudgetContinue && b.continuations < MaxBudgetContinuations
+	switch {
+	case used >= b.limit+b.limit*budgetSummaryGrace/100 && b.summarizing:
+		b.summarizing = false
+		e.stopRunOverBudget(sc, b, true)
+	case used >= b.limit && !canContinue:
+		e.stopRunOverBudget(sc, b, false)
+	case used >= b.limit && !b.continuing:
+		b.continuing = true
+		noter, ok := b.run.Session.(session.SystemNoter)
+		if !ok {
+			e.stopRunOverBudget(sc, b, true)
+			return
+		}
+		b.summarizing = true
+		b.summaryStart = len(sc.session.Snapshot().Messages)
+		e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeBudgetSummarizing, "budget", strconv.FormatInt(b.limit, 10)), time.Now())
+		e.wg.Go(func() {
+			ctx, cancel := context.WithTimeout(b.run.Ctx, e.opTimeout)
+			defer cancel()
+			if err := noter.InjectSystemNote(ctx, domain.BudgetSummaryNote(b.limit)); err != nil {
+				log.Printf("session %s: failed to ask for a budget summary: %v", sc.session.ID, err)
+			}
+		})
+	case !canContinue && !b.warned && used*100 >= b.limit*budgetWarningPercent:
+		b.warned = true
+		e.wg.Go(func() { e.warnRunBudget(sc, b, used) })
+	}
+}
+
+func (e *AgentExecutor) warnRunBudget(sc *sessionContext, b *runBudget, used int64) {
+	notice := domain.NewNotice(domain.NoticeBudgetWarning, "used", strconv.FormatInt(used, 10), "budget", strconv.FormatInt(b.limit, 10))
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, time.Now())
+
+	noter, ok := b.run.Session.(session.SystemNoter)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(b.run.Ctx, e.opTimeout)
+	defer cancel()
+	if err := noter.InjectSystemNote(ctx, strings.TrimPrefix(notice.Text(), "[budget] ")); err != nil {
+		log.Printf("session %s: failed to inject budget warning: %v", sc.session.ID, err)
+	}
+}
+
+// stopRunOverBudget marks b stopped and stops its run in the background.
+// Callers must hold b.mu.
+func (e *AgentExecutor) stopRunOverBudget(sc *sessionContext, b *runBudget, continuing bool) {
+	b.stopped = true
+	b.stopping.Add(1)
+	e.wg.Go(func() {
+		defer b.stopping.Done()
+		e.stopBudgetRun(sc, b, continuing)
+	})
+}
+
+// stopBudgetRun interrupts a run that used up its budget. A continuing run
+// ends as "budget_continued" and picks up in a fresh run once it is over;
+// otherwise it ends as "budget_exceeded".
+func (e *AgentExecutor) stopBudgetRun(sc *sessionContext, b *runBudget, continuing bool) {
+	if sc.getRun() != b.run {
+		return
+	}
+
+	b.run.Cancel()
+	if err := b.run.Session.Kill(); err != nil {
+		log.Printf("session %s: failed to stop run over its token budget: %v", sc.session.ID, err)
+	}
+
+	budget := strconv.FormatInt(b.limit, 10)
+	e.closeTerminalHub(sc.session.ID)
+	if continuing {
+		reason := domain.NewNotice(domain.NoticeStatusBudgetContinued, "budget", budget)
+		e.finalizeRunAttempt(sc, "budget_continued", reason.Text())
+		e.transitionWithSave(sc, domain.SessionStateIdle, reason)
+		return
+	}
+	reason := domain.NewNotice(domain.NoticeStatusBudgetExceeded, "budget", budget)
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeBudgetStopped, "budget", budget), time.Now())
+	e.finalizeRunAttempt(sc, "budget_exceeded", reason.Text())
+	e.transitionWithSave(sc, domain.SessionStateIdle, reason)
+}
+
+// completion is the terminal reason and status notice of a run that
+// finished on its own: one that summarized for a continuation ends as
+// "budget_continued".
+func (b *runBudget) completion() (string, domain.Notice) {
+	if b != nil {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.continuing {
+			return "budget_continued", domain.NewNotice(domain.NoticeStatusBudgetContinued, "budget", strconv.FormatInt(b.limit, 10))
+		}
+	}
+	return "completed", domain.NewNotice(domain.NoticeStatusRunCompleted)
+}
+
+// finishRunBudget runs once a budgeted run is over and the session is free.
+// A run that reached a continuing budget is continued in a fresh run whose
+// first message is the run's summary, or a summary of its messages when it
+// wrote none.
+func (e *AgentExecutor) finishRunBudget(sc *sessionContext, b *runBudget) {
+	sc.budget.CompareAndSwap(b, nil)
+	b.stopping.Wait()
+
+	b.mu.Lock()
+	continuing, summarizing, summaryStart := b.continuing, b.summarizing, b.summaryStart
+	b.mu.Unlock()
+	if !continuing || e.ctx.Err() != nil || e.draining.Load() {
+		return
+	}
+
+	sess := sc.session
+	messages := sess.Snapshot().Messages
+	var doc domain.HandoffDocument
+	if summarizing {
+		var outputs []string
+		for _, m := range messages[min(summaryStart, len(messages)):] {
+			if m.Kind == domain.MessageKindOutput && !m.Redacted {
+				outputs = append(outputs, m.Contents)
+			}
+		}
+		doc = domain.ParseHandoffDocument(strings.Join(outputs, "\n"))
+	}
+	if doc.Empty() {
+		doc = domain.BudgetFallbackDocument(messages[min(b.messages, len(messages)):])
+	}
+
+	continuation := b.continuations + 1
+	e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeBudgetContinued, "attempt", b.attemptID, "continuation", strconv.Itoa(continuation)), time.Now())
+	opts := SendMessageOptions{
+		TokenBudget:         b.limit,
+		BudgetAction:        b.action,
+		budgetRequest:       b.request,
+		budgetContinuations: continuation,
+		continuedFrom:       b.attemptID,
+	}
+	if _, err := e.sendMessage(e.ctx, sess.ID, doc.ContinuationPrompt(b.request), "", "", opts); err != nil {
+		log.Printf("session %s: failed to continue after token budget: %v", sess.ID, err)
+		e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeBudgetContinueFailed, "error", err.Error()), time.Now())
+		if e.storage != nil {
+			_ = e.saveSession(sess)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// inputProvider is a notingProvider that records the input of its run.
+type inputProvider struct {
+	*notingProvider
+	input string
+}
+
+func (p *inputProvider) SendInput(ctx context.Context, config session.Config, input string) (<-chan domain.Event, error) {
+	p.mu.Lock()
+	p.input = input
+	p.mu.Unlock()
+	return p.mockProvider.SendInput(ctx, config, input)
+}
+
+func newInputProvider() *inputProvider {
+	return &inputProvider{notingProvider: &notingProvider{mockProvider: newMockProvider()}}
+}
+
+func TestAgentExecutor_TokenBudget_Stops(t *testing.T) {
+	executor, store, provs := newRecordingExecutor(t, ExecutorConfig{}, newInputProvider)
+
+	if _, err := executor.CreateSession(context.Background(), "budgeted", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	opts := SendMessageOptions{TokenBudget: 1000}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "budgeted", "hello", "", "", opts); err != nil {
+		t.Fatalf("SendMessageWithOptions: %v", err)
+	}
+	waitFor(t, func() bool {
+		sess, _ := executor.GetSession("budgeted")
+		return len(provs()) == 1 && sess.GetState() == domain.SessionStateRunning
+	})
+	prov := provs()[0]
+
+	prov.SendEvent(domain.NewMetricEvent("budgeted", 800, 150, 1, nil))
+	waitFor(t, func() bool {
+		prov.mu.Lock()
+		defer prov.mu.Unlock()
+		return len(prov.notes) == 1
+	})
+	prov.SendEvent(domain.NewMetricEvent("budgeted", 100, 50, 1, nil))
+
+	attempt := waitForRunAttempt(t, store, "budgeted", true)
+	if attempt.TerminalReason != "budget_exceeded" {
+		t.Fatalf("terminal reason = %q, want budget_exceeded", attempt.TerminalReason)
+	}
+	waitFor(t, func() bool {
+		sess, _ := executor.GetSession("budgeted")
+		return sess.GetState() == domain.SessionStateIdle
+	})
+	if n := len(provs()); n != 1 {
+		t.Fatalf("started %d runs, want 1", n)
+	}
+}
+
+func TestAgentExecutor_TokenBudget_Continues(t *testing.T) {
+	executor, store, provs := newRecordingExecutor(t, ExecutorConfig{}, newInputProvider)
+
+	if _, err := executor.CreateSession(context.Background(), "continued", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	opts := SendMessageOptions{TokenBudget: 1000, BudgetAction: domain.BudgetContinue}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "continued", "refactor the parser", "", "", opts); err != nil {
+		t.Fatalf("SendMessageWithOptions: %v", err)
+	}
+	waitFor(t, func() bool {
+		sess, _ := executor.GetSession("continued")
+		return len(provs()) == 1 && sess.GetState() == domain.SessionStateRunning
+	})
+	first := provs()[0]
+
+	first.SendEvent(domain.NewMetricEvent("continued", 900, 200, 1, nil))
+	waitFor(t, func() bool {
+		first.mu.Lock()
+		defer first.mu.Unlock()
+		return len(first.notes) == 1
+	})
+	first.SendEvent(domain.NewOutputEvent("continued", "## Summary\nSplit the lexer out.\n## Next steps\n- Move the tests", nil))
+	time.Sleep(50 * time.Millisecond)
+	_ = first.Kill()
+
+	waitFor(t, func() bool { return len(provs()) == 2 })
+	attempts, err := store.ListRunAttempts("continued")
+	if err != nil {
+		t.Fatalf("ListRunAttempts: %v", err)
+	}
+	var firstID string
+	for _, a := range attempts {
+		if a.ContinuedFrom == "" {
+			firstID = a.AttemptID
+			if a.TerminalReason != "budget_continued" {
+				t.Errorf("first attempt terminal reason = %q, want budget_continued", a.TerminalReason)
+			}
+		}
+	}
+	waitFor(t, func() bool {
+		latest := waitForRunAttempt(t, store, "continued", false)
+		return latest.AttemptID != firstID && latest.ContinuedFrom == firstID
+	})
+
+	second := provs()[1]
+	second.mu.Lock()
+	input := second.input
+	second.mu.Unlock()
+	for _, want := range []string{"Split the lexer out.", "- Move the tests", "## Original request\nrefactor the parser"} {
+		if !strings.Contains(input, want) {
+			t.Errorf("continuation prompt missing %q:\n%s", want, input)
+		}
+	}
+}
+
+func TestAgentExecutor_SendMessageWithOptions_RejectsBadBudget(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "s", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, opts := range []SendMessageOptions{{TokenBudget: -1}, {TokenBudget: 10, BudgetAction: "pause"}} {
+		if _, err := executor.SendMessageWithOptions(context.Background(), "s", "hello", "", "", opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
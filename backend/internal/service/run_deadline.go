@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// maxDeadlineWarningLead caps how far ahead of a run deadline the agent is
+// warned; shorter deadlines are warned at 90% of their duration.
+const maxDeadlineWarningLead = 5 * time.Minute
+
+func deadlineWarningLead(deadline time.Duration) time.Duration {
+	return min(deadline/10, maxDeadlineWarningLead)
+}
+
+// watchRunDeadline warns the agent shortly before the run's deadline and
+// interrupts the run once it passes, finalizing it as "timeboxed". It returns
+// when the run ends by any other means.
+func (e *AgentExecutor) watchRunDeadline(sc *sessionContext, run *session.Run, deadline time.Duration) {
+	warnTimer := time.NewTimer(deadline - deadlineWarningLead(deadline))
+	defer warnTimer.Stop()
+	expireTimer := time.NewTimer(deadline)
+	defer expireTimer.Stop()
+
+	for {
+		select {
+		case <-run.Ctx.Done():
+			return
+		case <-warnTimer.C:
+			e.warnRunDeadline(sc, run, deadlineWarningLead(deadline))
+		case <-expireTimer.C:
+			e.timeboxRun(sc, run, deadline)
+			return
+		}
+	}
+}
+
+func (e *AgentExecutor) warnRunDeadline(sc *sessionContext, run *session.Run, remaining time.Duration) {
+	notice := domain.NewNotice(domain.NoticeTimeboxWarning, "remaining", remaining.Round(time.Second).String())
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, time.Now())
+	note := strings.TrimPrefix(notice.Text(), "[timebox] ")
+
+	noter, ok := run.Session.(session.SystemNoter)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(run.Ctx, e.opTimeout)
+	defer cancel()
+	if err := noter.InjectSystemNote(ctx, note); err != nil {
+		log.Printf("session %s: failed to inject deadline warning: %v", sc.session.ID, err)
+	}
+}
+
+func (e *AgentExecutor) timeboxRun(sc *sessionContext, run *session.Run, deadline time.Duration) {
+	if sc.getRun() != run {
+		return
+	}
+
+	run.Cancel()
+	if err := run.Session.Kill(); err != nil {
+		log.Printf("session %s: failed to stop timeboxed run: %v", sc.session.ID, err)
+	}
+
+	reason := domain.NewNotice(domain.NoticeStatusTimeboxed, "deadline", deadline.String())
+	e.closeTerminalHub(sc.session.ID)
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeTimeboxStopped, "deadline", deadline.String()), time.Now())
+	e.finalizeRunAttempt(sc, "timeboxed", reason.Text())
+	e.transitionWithSave(sc, domain.SessionStateIdle, reason)
+}
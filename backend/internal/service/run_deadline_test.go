@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+type notingProvider struct {
+	*mockProvider
+	mu    sync.Mutex
+	notes []string
+}
+
+func (p *notingProvider) InjectSystemNote(ctx context.Context, note string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.notes = append(p.notes, note)
+	return nil
+}
+
+func TestAgentExecutor_SendMessageWithDeadline_Timeboxes(t *testing.T) {
+	prov := &notingProvider{mockProvider: newMockProvider()}
+	store := newMockStorage()
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "timeboxed", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	opts := SendMessageOptions{Deadline: 200 * time.Millisecond}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "timeboxed", "hello", "", "", opts); err != nil {
+		t.Fatalf("SendMessageWithOptions: %v", err)
+	}
+
+	attempt := waitForRunAttempt(t, store, "timeboxed", true)
+	if attempt.TerminalReason != "timeboxed" {
+		t.Fatalf("terminal reason = %q, want timeboxed", attempt.TerminalReason)
+	}
+
+	sess, _ := executor.GetSession("timeboxed")
+	if state := sess.GetState(); state != domain.SessionStateIdle {
+		t.Fatalf("state = %s, want idle", state)
+	}
+
+	prov.mu.Lock()
+	notes := len(prov.notes)
+	prov.mu.Unlock()
+	if notes != 1 {
+		t.Fatalf("injected %d deadline warnings, want 1", notes)
+	}
+}
+
+func TestAgentExecutor_SendMessageWithOptions_RejectsNegativeDeadline(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "s", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "s", "hello", "", "", SendMessageOptions{Deadline: -time.Second}); err == nil {
+		t.Fatal("expected error for negative deadline")
+	}
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// RunExplanation gathers everything known about one run attempt into a
+// single document for bug reports.
+type RunExplanation struct {
+	GeneratedAt time.Time                   `json:"generated_at"`
+	SessionID   string                      `json:"session_id"`
+	Attempt     *storage.RunAttemptMetadata `json:"attempt"`
+	// WindowStart and WindowEnd bound the run; records outside them belong
+	// to other runs. An open attempt left by an earlier server process
+	// ends at its last heartbeat and is Abandoned.
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+	Open        bool      `json:"open,omitempty"`
+	Abandoned   bool      `json:"abandoned,omitempty"`
+	Outcome     string    `json:"outcome"`
+
+	Config   RunExplainConfig  `json:"config"`
+	Timeline []RunExplainEntry `json:"timeline"`
+	Errors   []RunExplainError `json:"errors"`
+	Stderr   string            `json:"stderr,omitempty"`
+	Records  RunExplainRecords `json:"records"`
+	Notes    []string          `json:"notes,omitempty"`
+}
+
+// RunExplainConfig is the configuration the run was started with, as far
+// as the session records it.
+type RunExplainConfig struct {
+	ProviderType    string          `json:"provider_type"`
+	ProviderID      string          `json:"provider_id,omitempty"`
+	AgentID         string          `json:"agent_id,omitempty"`
+	WorkingDir      string          `json:"working_dir"`
+	ProjectID       string          `json:"project_id,omitempty"`
+	TaskID          string          `json:"task_id,omitempty"`
+	Features        map[string]bool `json:"features,omitempty"`
+	RecoveryPolicy  RecoveryPolicy  `json:"recovery_policy"`
+	PlanApproval    bool            `json:"plan_approval,omitempty"`
+	CommandApproval bool            `json:"command_approval,omitempty"`
+	// ProviderCustomKeys lists the provider-specific settings given; their
+	// values are left out as they may hold credentials.
+	ProviderCustomKeys []string `json:"provider_custom_keys,omitempty"`
+	SystemPromptBytes  int      `json:"system_prompt_bytes,omitempty"`
+}
+
+// RunExplainEntry is one thing that happened during the run.
+type RunExplainEntry struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"`
+	Summary string    `json:"summary"`
+	Code    string    `json:"code,omitempty"`
+}
+
+// RunExplainError is an error the run hit. Source is where it was found:
+// event, message or delivery.
+type RunExplainError struct {
+	At      time.Time `json:"at"`
+	Source  string    `json:"source"`
+	Code    string    `json:"code,omitempty"`
+	Message string    `json:"message"`
+}
+
+// RunExplainRecords are the other stored records tied to the run.
+type RunExplainRecords struct {
+	Session      domain.SessionSnapshot         `json:"session"`
+	ResumeTokens []*storage.ResumeTokenMetadata `json:"resume_tokens,omitempty"`
+	Commands     []domain.CommandRecord         `json:"commands,omitempty"`
+	Messages     int                            `json:"messages"`
+	Events       int                            `json:"events"`
+}
+
+// ExplainRun builds the explanation of one of the session's run attempts.
+// It returns storage.ErrRunAttemptNotFound for an unknown attempt.
+func (e *AgentExecutor) ExplainRun(id, attemptID string) (*RunExplanation, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	if e.attemptStorage == nil {
+		return nil, storage.ErrRunAttemptNotFound
+	}
+	attempt, err := e.attemptStorage.LoadRunAttempt(id, attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	ex := &RunExplanation{
+		GeneratedAt: now,
+		SessionID:   id,
+		Attempt:     attempt,
+		WindowStart: attempt.StartedAt,
+		Timeline:    []RunExplainEntry{},
+		Errors:      []RunExplainError{},
+	}
+	switch {
+	case attempt.EndedAt != nil:
+		ex.WindowEnd = *attempt.EndedAt
+	case attempt.BootID != e.bootID:
+		ex.WindowEnd = attempt.HeartbeatAt
+		ex.Abandoned = true
+	default:
+		ex.WindowEnd = now
+		ex.Open = true
+	}
+	ex.Outcome = runOutcome(ex, attempt)
+	inWindow := func(t time.Time) bool {
+		return !t.Before(ex.WindowStart) && !t.After(ex.WindowEnd)
+	}
+
+	snap := sess.Snapshot()
+	ex.Config = e.runExplainConfig(sess, snap, attempt)
+
+	for _, t := range snap.Transitions {
+		if !inWindow(t.Timestamp) {
+			continue
+		}
+		entry := RunExplainEntry{At: t.Timestamp, Kind: "state", Summary: fmt.Sprintf("%s -> %s", t.From, t.To)}
+		if t.Reason != "" {
+			entry.Summary += ": " + t.Reason
+		}
+		if t.Notice != nil {
+			entry.Code = t.Notice.Code
+		}
+		ex.Timeline = append(ex.Timeline, entry)
+	}
+	for _, op := range attempt.Operations {
+		summary := op.Op + ": " + op.Outcome
+		if op.Error != "" {
+			summary += " (" + op.Error + ")"
+		}
+		ex.Timeline = append(ex.Timeline, RunExplainEntry{At: op.RequestedAt, Kind: "control", Summary: summary})
+	}
+	if attempt.DeliveryError != "" {
+		ex.Errors = append(ex.Errors, RunExplainError{At: attempt.StartedAt, Source: "delivery", Message: attempt.DeliveryError})
+	}
+
+	messages := snap.Messages
+	if e.storage != nil {
+		if stored, err := e.storage.GetMessages(id); err == nil {
+			messages = stored
+		}
+	}
+	var stderr strings.Builder
+	for _, msg := range messages {
+		if !inWindow(msg.Timestamp) {
+			continue
+		}
+		ex.Records.Messages++
+		switch msg.Kind {
+		case domain.MessageKindDiagnostic:
+			stderr.WriteString(msg.Contents)
+		case domain.MessageKindError:
+			if e.eventLog == nil {
+				ex.Errors = append(ex.Errors, RunExplainError{At: msg.Timestamp, Source: "message", Message: msg.Contents})
+			}
+		}
+	}
+	ex.Stderr = stderr.String()
+
+	if e.eventLog == nil {
+		ex.Notes = append(ex.Notes, "event log is not configured; tool calls and error codes are missing")
+	} else if err := e.explainEvents(ex, inWindow); err != nil {
+		log.Printf("session %s: explain run %s: %v", id, attemptID, err)
+		ex.Notes = append(ex.Notes, "event log could not be read: "+err.Error())
+	}
+
+	for _, cmd := range snap.Commands {
+		if !inWindow(cmd.ProposedAt) {
+			continue
+		}
+		ex.Records.Commands = append(ex.Records.Commands, cmd)
+		ex.Timeline = append(ex.Timeline, RunExplainEntry{At: cmd.ProposedAt, Kind: "command", Summary: fmt.Sprintf("%s (%s)", cmd.Command, cmd.Status)})
+	}
+
+	if e.resumeTokenStorage != nil {
+		tokenIDs := []string{attempt.ResumeTokenID}
+		for _, w := range attempt.Waits {
+			tokenIDs = append(tokenIDs, w.ResumeTokenID)
+		}
+		for _, tokenID := range tokenIDs {
+			if tokenID == "" {
+				continue
+			}
+			if token, err := e.resumeTokenStorage.LoadResumeToken(tokenID); err == nil {
+				ex.Records.ResumeTokens = append(ex.Records.ResumeTokens, token)
+			}
+		}
+	}
+
+	snap.Messages = nil
+	snap.ProviderCustom = nil
+	ex.Records.Session = snap
+
+	sort.SliceStable(ex.Timeline, func(i, j int) bool { return ex.Timeline[i].At.Before(ex.Timeline[j].At) })
+	sort.SliceStable(ex.Errors, func(i, j int) bool { return ex.Errors[i].At.Before(ex.Errors[j].At) })
+	return ex, nil
+}
+
+// explainEvents adds the run's tool calls, errors and metadata from the
+// session's event log.
+func (e *AgentExecutor) explainEvents(ex *RunExplanation, inWindow func(time.Time) bool) error {
+	events, _, err := e.eventLog.Since(ex.SessionID, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, stored := range events {
+		event := stored.Event
+		if !inWindow(event.Timestamp) {
+			continue
+		}
+		ex.Records.Events++
+		switch event.Type {
+		case domain.EventTypeToolCall:
+			if d, ok := event.ToolCall(); ok {
+				ex.Timeline = append(ex.Timeline, RunExplainEntry{At: event.Timestamp, Kind: "tool_call", Summary: fmt.Sprintf("%s %s (%s)", d.Name, d.ID, d.Status)})
+			}
+		case domain.EventTypeError:
+			if d, ok := event.Error(); ok {
+				ex.Timeline = append(ex.Timeline, RunExplainEntry{At: event.Timestamp, Kind: "error", Summary: d.Message, Code: d.Code})
+				ex.Errors = append(ex.Errors, RunExplainError{At: event.Timestamp, Source: "event", Code: d.Code, Message: d.Message})
+			}
+		case domain.EventTypeMetadata:
+			if d, ok := event.Metadata(); ok {
+				ex.Timeline = append(ex.Timeline, RunExplainEntry{At: event.Timestamp, Kind: "metadata", Summary: d.Key})
+			}
+		case domain.EventTypePlan:
+			ex.Timeline = append(ex.Timeline, RunExplainEntry{At: event.Timestamp, Kind: "plan", Summary: "plan updated"})
+		}
+	}
+	return nil
+}
+
+func (e *AgentExecutor) runExplainConfig(sess *domain.Session, snap domain.SessionSnapshot, attempt *storage.RunAttemptMetadata) RunExplainConfig {
+	cfg := RunExplainConfig{
+		ProviderType:      attempt.ProviderType,
+		ProviderID:        attempt.ProviderID,
+		AgentID:           snap.AgentID,
+		WorkingDir:        snap.WorkingDir,
+		ProjectID:         snap.ProjectID,
+		TaskID:            snap.TaskID,
+		Features:          snap.Features,
+		RecoveryPolicy:    e.recoveryPolicyFor(sess),
+		PlanApproval:      snap.PlanApproval,
+		CommandApproval:   snap.CommandApproval != nil,
+		SystemPromptBytes: len(snap.PromptPrefix),
+	}
+	for key := range snap.ProviderCustom {
+		cfg.ProviderCustomKeys = append(cfg.ProviderCustomKeys, key)
+	}
+	sort.Strings(cfg.ProviderCustomKeys)
+	return cfg
+}
+
+// runOutcome summarises how the run ended.
+func runOutcome(ex *RunExplanation, attempt *storage.RunAttemptMetadata) string {
+	switch {
+	case ex.Open && attempt.WaitKind != "":
+		return "waiting: " + attempt.WaitKind
+	case ex.Open:
+		return "running"
+	case ex.Abandoned:
+		return "abandoned: the server stopped during the run"
+	case attempt.InterruptionReason != "":
+		return "interrupted: " + attempt.InterruptionReason
+	case attempt.TerminalReason != "":
+		return attempt.TerminalReason
+	default:
+		return "ended"
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+const (
+	// DefaultLivenessInterval is how often a running session publishes a
+	// liveness event.
+	DefaultLivenessInterval = 10 * time.Second
+
+	// livenessTokenWindow is the window tokens are counted over.
+	livenessTokenWindow = time.Minute
+)
+
+type tokenSample struct {
+	at     time.Time
+	tokens int64
+}
+
+// livenessTracker follows one run's provider activity for liveness events.
+// It is only used from the run's event loop and is not thread-safe.
+type livenessTracker struct {
+	lastActivity time.Time
+	samples      []tokenSample
+}
+
+func newLivenessTracker(start time.Time) *livenessTracker {
+	return &livenessTracker{lastActivity: start}
+}
+
+func (l *livenessTracker) observe(event domain.Event) {
+	at := event.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	l.lastActivity = at
+	if data, ok := event.Metric(); ok {
+		if tokens := data.TokensIn + data.TokensOut; tokens > 0 {
+			l.samples = append(l.samples, tokenSample{at: at, tokens: tokens})
+		}
+	}
+}
+
+func (l *livenessTracker) snapshot(now time.Time) domain.LivenessData {
+	cutoff := now.Add(-livenessTokenWindow)
+	kept := l.samples[:0]
+	var tokens int64
+	for _, s := range l.samples {
+		if s.at.After(cutoff) {
+			kept = append(kept, s)
+			tokens += s.tokens
+		}
+	}
+	l.samples = kept
+	return domain.LivenessData{LastActivityAt: l.lastActivity, TokensLastMinute: tokens}
+}
+
+// publishLiveness broadcasts a liveness event while the session is running;
+// suspended runs are waiting on purpose and are not reported.
+func (e *AgentExecutor) publishLiveness(sc *sessionContext, l *livenessTracker) {
+	if sc.session.GetState() != domain.SessionStateRunning {
+		return
+	}
+	e.broadcaster.Broadcast(domain.NewLivenessEvent(sc.session.ID, l.snapshot(time.Now())))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestLivenessTracker(t *testing.T) {
+	start := time.Now()
+	l := newLivenessTracker(start)
+	if got := l.snapshot(start); !got.LastActivityAt.Equal(start) || got.TokensLastMinute != 0 {
+		t.Fatalf("initial liveness = %+v", got)
+	}
+
+	old := domain.NewMetricEvent("s", 100, 50, 1, nil)
+	old.Timestamp = start.Add(-2 * time.Minute)
+	l.observe(old)
+	recent := domain.NewMetricEvent("s", 10, 5, 1, nil)
+	recent.Timestamp = start.Add(-10 * time.Second)
+	l.observe(recent)
+	output := domain.NewOutputEvent("s", "hi", nil)
+	output.Timestamp = start.Add(-time.Second)
+	l.observe(output)
+
+	got := l.snapshot(start)
+	if got.TokensLastMinute != 15 || !got.LastActivityAt.Equal(output.Timestamp) {
+		t.Fatalf("liveness = %+v, want 15 tokens and last activity at the output", got)
+	}
+	if len(l.samples) != 1 {
+		t.Fatalf("samples outside the window were kept: %d", len(l.samples))
+	}
+}
+
+func TestAgentExecutor_PublishesLivenessEvents(t *testing.T) {
+	prov := newMockProvider()
+	broadcaster := NewEventBroadcaster(100)
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: broadcaster,
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+		LivenessInterval: 20 * time.Millisecond,
+	})
+	defer executor.Shutdown(context.Background())
+
+	sub := broadcaster.Subscribe("liveness-test", "liveness")
+	defer broadcaster.Unsubscribe("liveness-test")
+
+	if _, err := executor.CreateSession(context.Background(), "liveness", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "liveness", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.Events:
+			if data, ok := event.Liveness(); ok {
+				if data.LastActivityAt.IsZero() {
+					t.Fatalf("liveness = %+v, want a last activity time", data)
+				}
+				_, replay := broadcaster.SubscribeWithReplay("liveness-replay", "liveness", 0)
+				broadcaster.Unsubscribe("liveness-replay")
+				for _, ev := range replay {
+					if ev.Type == domain.EventTypeLiveness {
+						t.Fatal("liveness events should not be kept for replay")
+					}
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for liveness event")
+		}
+	}
+}
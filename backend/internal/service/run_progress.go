@@ -0,0 +1,128 @@
+package service
+
+import (
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+const (
+	// DefaultProgressInterval is how often a running session's progress
+	// estimate is re-evaluated and, if it changed, published.
+	DefaultProgressInterval = 5 * time.Second
+
+	// progressIdleAfter is how long without tool calls before the phase is
+	// reported as thinking rather than working.
+	progressIdleAfter = 30 * time.Second
+
+	// progressToolHalfway is the tool call count at which a run without a
+	// plan is estimated to be halfway to its (never reached) 90% ceiling.
+	progressToolHalfway = 10
+)
+
+// progressEstimator derives a coarse completion estimate for one run from the
+// latest plan or todo list and, when neither is available, from tool-call
+// cadence. It is only used from the run's event loop and is not thread-safe.
+type progressEstimator struct {
+	steps      []domain.PlanStep
+	toolCalls  int
+	lastToolAt time.Time
+	last       domain.ProgressData
+	published  bool
+}
+
+func (p *progressEstimator) observe(event domain.Event) {
+	switch data := event.Data.(type) {
+	case domain.PlanData:
+		if len(data.Steps) > 0 {
+			p.steps = data.Steps
+		}
+	case domain.ToolCallData:
+		if data.Name == "TodoWrite" {
+			if steps := todoSteps(data.Input); len(steps) > 0 {
+				p.steps = steps
+			}
+		}
+		if data.Status == "completed" || toolCallFailureStatuses[data.Status] {
+			p.toolCalls++
+			p.lastToolAt = event.Timestamp
+		}
+	}
+}
+
+// todoSteps reads the todo list from a TodoWrite tool call input.
+func todoSteps(input any) []domain.PlanStep {
+	m, ok := input.(map[string]any)
+	if !ok {
+		return nil
+	}
+	todos, ok := m["todos"].([]any)
+	if !ok {
+		return nil
+	}
+	steps := make([]domain.PlanStep, 0, len(todos))
+	for _, raw := range todos {
+		todo, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		step := domain.PlanStep{}
+		step.ID, _ = todo["id"].(string)
+		step.Description, _ = todo["content"].(string)
+		step.Status, _ = todo["status"].(string)
+		if active, _ := todo["activeForm"].(string); active != "" && step.Status == "in_progress" {
+			step.Description = active
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func (p *progressEstimator) estimate(now time.Time) domain.ProgressData {
+	if len(p.steps) > 0 {
+		done := 0
+		phase := ""
+		for _, step := range p.steps {
+			switch step.Status {
+			case "completed", "done":
+				done++
+			default:
+				if phase == "" || step.Status == "in_progress" {
+					phase = step.Description
+				}
+			}
+		}
+		if phase == "" {
+			phase = "finishing"
+		}
+		// A finished plan still leaves the run to wrap up, so hold at 99%.
+		return domain.ProgressData{Percent: min(done*100/len(p.steps), 99), Phase: phase}
+	}
+
+	if p.toolCalls == 0 {
+		return domain.ProgressData{Percent: 0, Phase: "starting"}
+	}
+	phase := "working"
+	if now.Sub(p.lastToolAt) > progressIdleAfter {
+		phase = "thinking"
+	}
+	return domain.ProgressData{Percent: 90 * p.toolCalls / (p.toolCalls + progressToolHalfway), Phase: phase}
+}
+
+// next returns the current estimate and whether it differs from the last one
+// published.
+func (p *progressEstimator) next(now time.Time) (domain.ProgressData, bool) {
+	est := p.estimate(now)
+	if p.published && est == p.last {
+		return est, false
+	}
+	p.last = est
+	p.published = true
+	return est, true
+}
+
+func (e *AgentExecutor) publishProgress(sc *sessionContext, p *progressEstimator) {
+	if est, changed := p.next(time.Now()); changed {
+		e.broadcaster.Broadcast(domain.NewProgressEvent(sc.session.ID, est))
+	}
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestProgressEstimator(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	p := &progressEstimator{}
+
+	if got := p.estimate(now); got != (domain.ProgressData{Percent: 0, Phase: "starting"}) {
+		t.Fatalf("initial estimate = %+v", got)
+	}
+
+	for i := range 10 {
+		p.observe(domain.Event{Timestamp: now, Data: domain.ToolCallData{ID: string(rune('a' + i)), Name: "Bash", Status: "completed"}})
+	}
+	if got := p.estimate(now); got != (domain.ProgressData{Percent: 45, Phase: "working"}) {
+		t.Fatalf("cadence estimate = %+v", got)
+	}
+	if got := p.estimate(now.Add(time.Minute)); got.Phase != "thinking" {
+		t.Fatalf("idle phase = %q, want thinking", got.Phase)
+	}
+
+	p.observe(domain.Event{Timestamp: now, Data: domain.ToolCallData{
+		ID:     "todo",
+		Name:   "TodoWrite",
+		Status: "started",
+		Input: map[string]any{"todos": []any{
+			map[string]any{"content": "Write tests", "status": "completed"},
+			map[string]any{"content": "Fix bug", "activeForm": "Fixing bug", "status": "in_progress"},
+			map[string]any{"content": "Ship", "status": "pending"},
+			map[string]any{"content": "Celebrate", "status": "pending"},
+		}},
+	}})
+	if got := p.estimate(now); got != (domain.ProgressData{Percent: 25, Phase: "Fixing bug"}) {
+		t.Fatalf("todo estimate = %+v", got)
+	}
+
+	p.observe(domain.Event{Timestamp: now, Data: domain.PlanData{Steps: []domain.PlanStep{
+		{ID: "1", Description: "a", Status: "completed"},
+		{ID: "2", Description: "b", Status: "completed"},
+	}}})
+	if got := p.estimate(now); got != (domain.ProgressData{Percent: 99, Phase: "finishing"}) {
+		t.Fatalf("finished plan estimate = %+v", got)
+	}
+
+	if _, changed := p.next(now); !changed {
+		t.Fatal("first estimate should be published")
+	}
+	if _, changed := p.next(now); changed {
+		t.Fatal("unchanged estimate should not be republished")
+	}
+}
+
+func TestAgentExecutor_PublishesProgressEvents(t *testing.T) {
+	prov := newMockProvider()
+	broadcaster := NewEventBroadcaster(100)
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: broadcaster,
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+		ProgressInterval: 20 * time.Millisecond,
+	})
+	defer executor.Shutdown(context.Background())
+
+	sub := broadcaster.Subscribe("progress-test", "progress")
+	defer broadcaster.Unsubscribe("progress-test")
+
+	if _, err := executor.CreateSession(context.Background(), "progress", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "progress", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-sub.Events:
+			if data, ok := event.Progress(); ok {
+				if data.Phase != "starting" {
+					t.Fatalf("progress = %+v, want starting phase", data)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for progress event")
+		}
+	}
+}
@@ -20,37 +20,107 @@ func (e *AgentExecutor) updateSessionFromEvent(sc *sessionContext, event domain.
 	case domain.ErrorData:
 		e.appendSessionMessageRaw(sc.session, domain.MessageKindError, data.Message, event.Raw, event.Timestamp)
 	case domain.ToolCallData:
-		e.appendSessionMessageRaw(sc.session, domain.MessageKindToolUse, fmt.Sprintf("%s: %s", data.Name, data.ID), event.Raw, event.Timestamp)
-		if data.Status == "pending" || data.Status == "waiting" {
-			e.suspendSession(sc, data.ID)
+		e.appendSessionMessageRaw(sc.session, domain.MessageKindToolUse, toolUseContents(data), event.Raw, event.Timestamp)
+		e.toolStats.record(sc.session.AgentID, event.SessionID, data, event.Timestamp)
+		sc.traceToolCall(data, event.Timestamp)
+		e.recordCommandEvent(sc, event)
+		if data.Status == "completed" {
+			// Agents commit through their tools.
+			e.trackGitCommits(sc)
 		}
 	case domain.MetadataData:
+		if data.Key == domain.MetadataKeyStderr {
+			text, _ := data.Value.(string)
+			e.captureStderr(sc, text, event.Timestamp)
+			break
+		}
+		e.recordCommandEvent(sc, event)
+		if data.Key == domain.MetadataKeyConversationID {
+			e.recordConversation(sc, data.Value)
+		}
+		if data.Key == domain.MetadataKeyModel {
+			e.recordContextModel(sc, data.Value)
+		}
 		if data.Key == "current_task" {
 			if task, ok := data.Value.(string); ok {
 				sc.session.SetCurrentTask(task)
+				e.suggest.put(sc.session)
 			}
 		}
 		e.appendSessionMessageRaw(sc.session, domain.MessageKindSystem, data.Key, event.Raw, event.Timestamp)
 	case domain.MetricData:
-		e.appendSessionMessageRaw(sc.session, domain.MessageKindMetric,
-			fmt.Sprintf("in=%d out=%d requests=%d", data.TokensIn, data.TokensOut, data.RequestCount), event.Raw, event.Timestamp)
+		e.recordPromptCacheUsage(sc, data)
+		e.recordUsage(sc, data)
+		e.recordContextUsage(sc, data, event.Timestamp)
+		e.checkRunBudget(sc)
+		e.checkCostBudgetOfRun(sc)
+		e.appendSessionMessageRaw(sc.session, domain.MessageKindMetric, metricContents(data), event.Raw, event.Timestamp)
 	case domain.StatusChangeData:
 		e.appendSessionMessageRaw(sc.session, domain.MessageKindSystem,
 			fmt.Sprintf("status: %s -> %s", data.OldState, data.NewState), event.Raw, event.Timestamp)
 	case domain.PlanData:
-		steps := make([]string, 0, len(data.Steps))
-		for _, step := range data.Steps {
-			steps = append(steps, fmt.Sprintf("%s: %s", step.ID, step.Description))
-		}
-		content := data.Description
-		if len(steps) > 0 {
-			content = fmt.Sprintf("%s\n%s", data.Description, strings.Join(steps, "\n"))
-		}
+		content := planContents(data)
 		e.appendSessionMessageRaw(sc.session, domain.MessageKindPlan, content, event.Raw, event.Timestamp)
+		e.holdForPlanApproval(sc, content)
 	}
 
 	if e.storage != nil {
-		_ = e.storage.Save(sc.session)
+		_ = e.saveSession(sc.session)
 	}
 	e.touchRunAttempt(sc)
 }
+
+// recordConversation keeps the conversation ID the run's provider reported,
+// so the session's next run continues the conversation instead of starting
+// a fresh one.
+func (e *AgentExecutor) recordConversation(sc *sessionContext, value any) {
+	id, _ := value.(string)
+	sc.amMu.Lock()
+	var providerType string
+	if sc.attempt != nil {
+		providerType = sc.attempt.ProviderType
+	}
+	sc.amMu.Unlock()
+	if id == "" || providerType == "" {
+		return
+	}
+	sc.session.SetConversation(providerType, id)
+}
+
+// metricContents is the message text recorded for a metric event.
+func metricContents(data domain.MetricData) string {
+	return fmt.Sprintf("in=%d out=%d requests=%d", data.TokensIn, data.TokensOut, data.RequestCount)
+}
+
+// planContents is the message text recorded for a plan event.
+func planContents(data domain.PlanData) string {
+	steps := make([]string, 0, len(data.Steps))
+	for _, step := range data.Steps {
+		steps = append(steps, fmt.Sprintf("%s: %s", step.ID, step.Description))
+	}
+	if len(steps) == 0 {
+		return data.Description
+	}
+	return fmt.Sprintf("%s\n%s", data.Description, strings.Join(steps, "\n"))
+}
+
+// toolUseContents is the message text recorded for a tool call event.
+func toolUseContents(data domain.ToolCallData) string {
+	contents := fmt.Sprintf("%s: %s", data.Name, data.ID)
+	if data.ParentID != "" {
+		contents += fmt.Sprintf(" (subagent %s)", data.ParentID)
+	}
+	return contents
+}
+
+// parseToolUseContents reverses toolUseContents.
+func parseToolUseContents(contents string) (name, id, parentID string, ok bool) {
+	if rest, parent, found := strings.Cut(contents, " (subagent "); found && strings.HasSuffix(parent, ")") {
+		contents, parentID = rest, strings.TrimSuffix(parent, ")")
+	}
+	i := strings.LastIndex(contents, ": ")
+	if i < 0 || i+2 == len(contents) {
+		return "", "", "", false
+	}
+	return contents[:i], contents[i+2:], parentID, true
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// WaitSet is the set of external tool calls a suspended run waits on.
+type WaitSet struct {
+	Kind string
+	// Quorum is how many of Waits must be fulfilled before the run resumes.
+	Quorum int
+	Waits  []storage.RunWait
+}
+
+// externalToolWait reports the tool call a run must wait for, if event is
+// one the agent handed off to an external party.
+func externalToolWait(event domain.Event) (string, bool) {
+	data, ok := event.Data.(domain.ToolCallData)
+	if !ok || (data.Status != "pending" && data.Status != "waiting") {
+		return "", false
+	}
+	return data.ID, true
+}
+
+// waitQuorum reads the session's wait_quorum provider setting: how many of
+// n parallel tool calls must be fulfilled before the run resumes. It
+// defaults to all of them.
+func waitQuorum(custom map[string]any, n int) int {
+	var quorum int
+	switch v := custom["wait_quorum"].(type) {
+	case int:
+		quorum = v
+	case float64:
+		quorum = int(v)
+	}
+	if quorum <= 0 || quorum > n {
+		return n
+	}
+	return quorum
+}
+
+// markRunAttemptWaitingOnAll records that the current attempt waits on
+// several tool calls, minting a resume token for each.
+func (e *AgentExecutor) markRunAttemptWaitingOnAll(sc *sessionContext, refs []string, quorum int) {
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.Waits = make([]storage.RunWait, len(refs))
+		for i, ref := range refs {
+			a.Waits[i] = storage.RunWait{Ref: ref, ResumeTokenID: e.mintResumeTokenForAttempt(a)}
+		}
+		a.WaitKind = domain.WaitKindToolCall
+		a.WaitRef = refs[0]
+		a.WaitQuorum = quorum
+		a.ResumeTokenID = ""
+		a.HeartbeatAt = time.Now().UTC()
+	})
+}
+
+// attemptHoldsToken reports whether tokenID resumes attempt, either as its
+// single wait token or as the token of one of its waits.
+func attemptHoldsToken(attempt *storage.RunAttemptMetadata, tokenID string) bool {
+	if tokenID == "" {
+		return false
+	}
+	if attempt.ResumeTokenID == tokenID {
+		return true
+	}
+	for _, wait := range attempt.Waits {
+		if wait.ResumeTokenID == tokenID {
+			return true
+		}
+	}
+	return false
+}
+
+// fulfillRunWait marks the wait resumed by tokenID fulfilled. It reports
+// whether the run must keep waiting because the quorum is not reached yet;
+// once it is, the tokens of the waits left over are revoked.
+func (e *AgentExecutor) fulfillRunWait(sc *sessionContext, attempt *storage.RunAttemptMetadata, tokenID string) (bool, error) {
+	now := time.Now().UTC()
+	fulfilled := 0
+	var ref string
+	for i := range attempt.Waits {
+		wait := &attempt.Waits[i]
+		if wait.ResumeTokenID == tokenID {
+			wait.FulfilledAt = &now
+			ref = wait.Ref
+		}
+		if wait.FulfilledAt != nil {
+			fulfilled++
+		}
+	}
+	quorum := attempt.WaitQuorum
+	if quorum <= 0 {
+		quorum = len(attempt.Waits)
+	}
+
+	if fulfilled >= quorum {
+		for _, wait := range attempt.Waits {
+			if wait.FulfilledAt == nil {
+				e.revokeResumeToken(wait.ResumeTokenID, "wait quorum reached")
+			}
+		}
+		return false, nil
+	}
+
+	attempt.HeartbeatAt = now
+	if err := e.attemptStorage.SaveRunAttempt(attempt); err != nil {
+		return false, fmt.Errorf("failed to record fulfilled wait: %w", err)
+	}
+	sc.amMu.Lock()
+	if sc.attempt != nil && sc.attempt.AttemptID == attempt.AttemptID {
+		sc.attempt.Waits = attempt.Waits
+		sc.attempt.HeartbeatAt = now
+	}
+	sc.amMu.Unlock()
+
+	e.appendNotice(sc.session, domain.MessageKindSystem,
+		domain.NewNotice(domain.NoticeResumePartial, "ref", ref, "remaining", strconv.Itoa(quorum-fulfilled)), now)
+	if e.storage != nil {
+		if err := e.saveSession(sc.session); err != nil {
+			return false, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return true, nil
+}
+
+func (e *AgentExecutor) revokeResumeToken(tokenID, reason string) {
+	if e.resumeTokenStorage == nil || tokenID == "" {
+		return
+	}
+	token, err := e.resumeTokenStorage.LoadResumeToken(tokenID)
+	if err != nil || token.RevokedAt != nil {
+		return
+	}
+	now := time.Now().UTC()
+	token.RevokedAt = &now
+	token.RevocationReason = reason
+	_ = e.resumeTokenStorage.SaveResumeToken(token)
+}
+
+// SessionWaitSet returns the external tool calls the session's suspended
+// run waits on, or nil if it is not waiting on any.
+func (e *AgentExecutor) SessionWaitSet(id string) (*WaitSet, error) {
+	attempt, err := e.latestPersistedAttempt(id)
+	if err != nil || attempt == nil || attempt.WaitKind != domain.WaitKindToolCall {
+		return nil, err
+	}
+	if len(attempt.Waits) == 0 {
+		return &WaitSet{
+			Kind:   attempt.WaitKind,
+			Quorum: 1,
+			Waits:  []storage.RunWait{{Ref: attempt.WaitRef}},
+		}, nil
+	}
+	quorum := attempt.WaitQuorum
+	if quorum <= 0 {
+		quorum = len(attempt.Waits)
+	}
+	return &WaitSet{Kind: attempt.WaitKind, Quorum: quorum, Waits: attempt.Waits}, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// waitForRunWaits returns the session's latest attempt once it waits on n
+// tool calls.
+func waitForRunWaits(t *testing.T, store *mockStorage, sessionID string, n int) *storage.RunAttemptMetadata {
+	t.Helper()
+	var attempt *storage.RunAttemptMetadata
+	waitFor(t, func() bool {
+		attempt = waitForRunAttempt(t, store, sessionID, true)
+		return len(attempt.Waits) == n
+	})
+	return attempt
+}
+
+func TestAgentExecutor_ParallelToolCallsWaitOnAll(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "fan-in", session.Config{ProviderType: "test", WorkingDir: "/tmp"}); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	// Both calls are delivered together, as a provider does for parallel
+	// tool use.
+	prov.SendEvent(domain.NewToolCallEvent("fan-in", domain.ToolCallData{ID: "call-a", Name: "lookup", Status: "pending"}, nil))
+	prov.SendEvent(domain.NewToolCallEvent("fan-in", domain.ToolCallData{ID: "call-b", Name: "lookup", Status: "pending"}, nil))
+	if _, err := executor.SendMessage(context.Background(), "fan-in", "go", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	attempt := waitForRunWaits(t, store, "fan-in", 2)
+	if attempt.WaitKind != domain.WaitKindToolCall || attempt.WaitQuorum != 2 || attempt.ResumeTokenID != "" {
+		t.Fatalf("unexpected wait metadata: %+v", attempt)
+	}
+	first, second := attempt.Waits[0], attempt.Waits[1]
+	if first.Ref != "call-a" || second.Ref != "call-b" || first.ResumeTokenID == "" || first.ResumeTokenID == second.ResumeTokenID {
+		t.Fatalf("expected a distinct token per call, got %+v", attempt.Waits)
+	}
+
+	if _, err := executor.ResumeSessionWithToken(context.Background(), "fan-in", first.ResumeTokenID); err != nil {
+		t.Fatalf("ResumeSessionWithToken(first) failed: %v", err)
+	}
+	ws, err := executor.SessionWaitSet("fan-in")
+	if err != nil || ws == nil {
+		t.Fatalf("SessionWaitSet = %+v, %v", ws, err)
+	}
+	if ws.Quorum != 2 || ws.Waits[0].FulfilledAt == nil || ws.Waits[1].FulfilledAt != nil {
+		t.Fatalf("expected only call-a fulfilled, got %+v", ws)
+	}
+	if state, _ := executor.DeriveSessionState("fan-in"); state != domain.SessionStateSuspended {
+		t.Fatalf("expected the run to keep waiting, got %s", state)
+	}
+	if _, err := executor.ResumeSessionWithToken(context.Background(), "fan-in", first.ResumeTokenID); !errors.Is(err, ErrRevokedResumeToken) {
+		t.Fatalf("expected a fulfilled token to be spent, got %v", err)
+	}
+
+	if _, err := executor.ResumeSessionWithToken(context.Background(), "fan-in", second.ResumeTokenID); err != nil {
+		t.Fatalf("ResumeSessionWithToken(second) failed: %v", err)
+	}
+	updated, err := store.LoadRunAttempt("fan-in", attempt.AttemptID)
+	if err != nil {
+		t.Fatalf("LoadRunAttempt failed: %v", err)
+	}
+	if updated.WaitKind != "" || len(updated.Waits) != 0 {
+		t.Fatalf("expected wait set cleared, got %+v", updated)
+	}
+	if ws, _ := executor.SessionWaitSet("fan-in"); ws != nil {
+		t.Fatalf("expected no wait set after resume, got %+v", ws)
+	}
+}
+
+func TestAgentExecutor_WaitQuorumRevokesLeftoverTokens(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	_, err := executor.CreateSession(context.Background(), "quorum", session.Config{
+		ProviderType: "test",
+		WorkingDir:   "/tmp",
+		Custom:       map[string]any{"wait_quorum": float64(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "quorum", "go", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	executor.mu.RLock()
+	sc := executor.sessions["quorum"]
+	executor.mu.RUnlock()
+	executor.suspendSession(sc, "call-a", "call-b", "call-c")
+
+	attempt := waitForRunWaits(t, store, "quorum", 3)
+	if attempt.WaitQuorum != 1 {
+		t.Fatalf("WaitQuorum = %d, want 1", attempt.WaitQuorum)
+	}
+	if _, err := executor.ResumeSessionWithToken(context.Background(), "quorum", attempt.Waits[1].ResumeTokenID); err != nil {
+		t.Fatalf("ResumeSessionWithToken failed: %v", err)
+	}
+	if ws, _ := executor.SessionWaitSet("quorum"); ws != nil {
+		t.Fatalf("expected the quorum to end the wait, got %+v", ws)
+	}
+	for _, i := range []int{0, 2} {
+		tok, err := store.LoadResumeToken(attempt.Waits[i].ResumeTokenID)
+		if err != nil {
+			t.Fatalf("LoadResumeToken failed: %v", err)
+		}
+		if tok.RevokedAt == nil || tok.RevocationReason != "wait quorum reached" {
+			t.Fatalf("expected leftover token revoked, got %+v", tok)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// DefaultScheduleInterval is how often schedules are checked for due runs.
+const DefaultScheduleInterval = 30 * time.Second
+
+// ErrSchedulesDisabled is returned for schedule operations when no schedule
+// storage is configured.
+var ErrSchedulesDisabled = errors.New("schedules are not configured")
+
+// ErrInvalidSchedule wraps a schedule that fails validation.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedules returns every schedule.
+func (e *AgentExecutor) Schedules() ([]domain.Schedule, error) {
+	if e.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+	return e.schedules.List()
+}
+
+// Schedule returns one schedule.
+func (e *AgentExecutor) Schedule(id string) (*domain.Schedule, error) {
+	if e.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+	return e.schedules.Get(id)
+}
+
+// CreateSchedule stores a new schedule, first due at its next time from
+// now.
+func (e *AgentExecutor) CreateSchedule(sched domain.Schedule) (*domain.Schedule, error) {
+	if e.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+	if err := sched.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+
+	now := time.Now().UTC()
+	sched.ID = newSessionID()
+	sched.CreatedAt = now
+	sched.LastRunAt = time.Time{}
+	sched.Runs = nil
+	sched.NextRunAt = nextScheduleRun(&sched, now)
+	if err := e.schedules.Save(sched); err != nil {
+		return nil, err
+	}
+	return &sched, nil
+}
+
+// UpdateSchedule replaces the settings of a schedule, keeping its run
+// history, and recomputes when it is next due.
+func (e *AgentExecutor) UpdateSchedule(id string, sched domain.Schedule) (*domain.Schedule, error) {
+	if e.schedules == nil {
+		return nil, ErrSchedulesDisabled
+	}
+	if err := sched.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+
+	existing, err := e.schedules.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	sched.ID = existing.ID
+	sched.CreatedAt = existing.CreatedAt
+	sched.LastRunAt = existing.LastRunAt
+	sched.Runs = existing.Runs
+	sched.NextRunAt = nextScheduleRun(&sched, time.Now().UTC())
+	if err := e.schedules.Save(sched); err != nil {
+		return nil, err
+	}
+	return &sched, nil
+}
+
+// DeleteSchedule removes a schedule. Sessions it started are kept.
+func (e *AgentExecutor) DeleteSchedule(id string) error {
+	if e.schedules == nil {
+		return ErrSchedulesDisabled
+	}
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+	return e.schedules.Delete(id)
+}
+
+// RunSchedule starts a session of the schedule now, whether or not it is
+// due or paused, without moving its next run.
+func (e *AgentExecutor) RunSchedule(ctx context.Context, id string) (domain.ScheduleRun, error) {
+	if e.schedules == nil {
+		return domain.ScheduleRun{}, ErrSchedulesDisabled
+	}
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+
+	sched, err := e.schedules.Get(id)
+	if err != nil {
+		return domain.ScheduleRun{}, err
+	}
+	run := e.launchSchedule(ctx, sched)
+	sched.AddRun(run)
+	if err := e.schedules.Save(*sched); err != nil {
+		return run, err
+	}
+	return run, nil
+}
+
+func (e *AgentExecutor) startScheduler() {
+	if e.schedules == nil {
+		return
+	}
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.scheduleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+				e.RunDueSchedules(e.ctx, time.Now().UTC())
+			}
+		}
+	}()
+}
+
+// RunDueSchedules starts a session for every unpaused schedule due at now
+// and returns the runs. A schedule that missed several runs while the
+// server was down runs once. A run is skipped while the schedule's previous
+// session is still busy.
+func (e *AgentExecutor) RunDueSchedules(ctx context.Context, now time.Time) []domain.ScheduleRun {
+	if e.schedules == nil || e.draining.Load() {
+		return nil
+	}
+	e.scheduleMu.Lock()
+	defer e.scheduleMu.Unlock()
+
+	schedules, err := e.schedules.List()
+	if err != nil {
+		log.Printf("schedules: %v", err)
+		return nil
+	}
+	var runs []domain.ScheduleRun
+	for i := range schedules {
+		sched := &schedules[i]
+		if sched.Paused || sched.NextRunAt.IsZero() || sched.NextRunAt.After(now) {
+			continue
+		}
+		var run domain.ScheduleRun
+		if last := lastScheduleSession(sched); e.watchSessionBusy(last) {
+			run = domain.ScheduleRun{At: now, Skipped: true, Error: "previous session " + last + " is still running"}
+		} else {
+			run = e.launchSchedule(ctx, sched)
+		}
+		sched.AddRun(run)
+		sched.NextRunAt = nextScheduleRun(sched, now)
+		if err := e.schedules.Save(*sched); err != nil {
+			log.Printf("schedule %s: %v", sched.ID, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// launchSchedule starts a new session of the schedule with its prompt. The
+// session is resolved like one created through the API, so it gets its
+// provider config, agent and project settings.
+func (e *AgentExecutor) launchSchedule(ctx context.Context, sched *domain.Schedule) domain.ScheduleRun {
+	run := domain.ScheduleRun{At: time.Now().UTC()}
+	err := func() error {
+		config, err := e.ResolveSessionRequest(SessionRequest{
+			ProviderType: sched.ProviderType,
+			ProviderID:   sched.ProviderID,
+			AgentID:      sched.AgentID,
+			WorkingDir:   sched.WorkingDir,
+			ProjectID:    sched.ProjectID,
+			SessionKind:  domain.SessionKindSchedule,
+			Title:        "Schedule: " + sched.Name,
+		})
+		if err != nil {
+			return err
+		}
+		id := newSessionID()
+		if _, err := e.CreateSession(ctx, id, config); err != nil {
+			return err
+		}
+		run.SessionID = id
+		_, err = e.sendMessage(ctx, id, sched.Prompt, "", "", SendMessageOptions{})
+		return err
+	}()
+	if err != nil {
+		run.Error = err.Error()
+		log.Printf("schedule %s (%s): %v", sched.ID, sched.Name, err)
+	}
+	return run
+}
+
+// nextScheduleRun is when sched is next due after now, or zero while it
+// is paused.
+func nextScheduleRun(sched *domain.Schedule, now time.Time) time.Time {
+	if sched.Paused {
+		return time.Time{}
+	}
+	return sched.Next(now).UTC()
+}
+
+// lastScheduleSession is the session of the schedule's latest started run.
+func lastScheduleSession(sched *domain.Schedule) string {
+	for i := len(sched.Runs) - 1; i >= 0; i-- {
+		if id := sched.Runs[i].SessionID; id != "" {
+			return id
+		}
+	}
+	return ""
+}
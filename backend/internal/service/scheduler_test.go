@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_RunDueSchedules(t *testing.T) {
+	var mu sync.Mutex
+	providers := map[string]*mockProvider{}
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			providers[sessionID] = newMockProvider()
+			return providers[sessionID], nil
+		},
+		OperationTimeout: 5 * time.Second,
+		Schedules:        storage.NewScheduleStorage(t.TempDir()),
+	})
+	defer executor.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	sched, err := executor.CreateSchedule(domain.Schedule{
+		Name:         "test-fixer",
+		Interval:     "1h",
+		ProviderType: "test",
+		WorkingDir:   dir,
+		Prompt:       "Fix the failing tests",
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule failed: %v", err)
+	}
+	if runs := executor.RunDueSchedules(context.Background(), time.Now().UTC()); len(runs) != 0 {
+		t.Fatalf("expected nothing due yet, got %+v", runs)
+	}
+
+	// Several missed hours start a single run.
+	due := sched.NextRunAt.Add(3 * time.Hour)
+	runs := executor.RunDueSchedules(context.Background(), due)
+	if len(runs) != 1 || runs[0].Error != "" || runs[0].SessionID == "" {
+		t.Fatalf("expected one run, got %+v", runs)
+	}
+	sess, err := executor.GetSession(runs[0].SessionID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	if sess.Kind != domain.SessionKindSchedule || sess.WorkingDir != dir {
+		t.Errorf("unexpected session %s/%s", sess.Kind, sess.WorkingDir)
+	}
+	if got := lastUserMessage(sess); got != "Fix the failing tests" {
+		t.Errorf("expected the schedule prompt, got %q", got)
+	}
+
+	stored, err := executor.Schedule(sched.ID)
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	if !stored.NextRunAt.Equal(due.Add(time.Hour)) || len(stored.Runs) != 1 {
+		t.Fatalf("expected the next run an hour later and one run recorded, got %+v", stored)
+	}
+
+	runs = executor.RunDueSchedules(context.Background(), stored.NextRunAt)
+	if len(runs) != 1 || !runs[0].Skipped {
+		t.Fatalf("expected a skipped run while the session is busy, got %+v", runs)
+	}
+
+	mu.Lock()
+	close(providers[sess.ID].events)
+	mu.Unlock()
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateIdle })
+
+	run, err := executor.RunSchedule(context.Background(), sched.ID)
+	if err != nil || run.SessionID == "" || run.SessionID == sess.ID {
+		t.Fatalf("RunSchedule = %+v, %v", run, err)
+	}
+	stored, _ = executor.Schedule(sched.ID)
+	if len(stored.Runs) != 3 || lastScheduleSession(stored) != run.SessionID {
+		t.Errorf("expected three runs ending with the manual one, got %+v", stored.Runs)
+	}
+}
+
+func TestAgentExecutor_ScheduleResolvesProviderAndAgent(t *testing.T) {
+	configs := make(chan session.Config, 1)
+	providers := storage.NewProviderConfigStorage(t.TempDir())
+	agents := storage.NewAgentConfigStorage(t.TempDir())
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			configs <- config
+			return newMockProvider(), nil
+		},
+		OperationTimeout: 5 * time.Second,
+		Schedules:        storage.NewScheduleStorage(t.TempDir()),
+		ProviderConfigs:  providers,
+		AgentConfigs:     agents,
+	})
+	defer executor.Shutdown(context.Background())
+
+	if err := providers.Save(storage.ProviderConfig{ID: "p1", Name: "p1", Type: "test", Custom: map[string]any{"model": "fast"}}); err != nil {
+		t.Fatalf("Save provider: %v", err)
+	}
+	if err := agents.Save(storage.AgentConfig{ID: "a1", Name: "a1", SystemPrompt: "You fix tests."}); err != nil {
+		t.Fatalf("Save agent: %v", err)
+	}
+	sched, err := executor.CreateSchedule(domain.Schedule{
+		Name:       "test-fixer",
+		Interval:   "1h",
+		ProviderID: "p1",
+		AgentID:    "a1",
+		WorkingDir: t.TempDir(),
+		Prompt:     "Fix the failing tests",
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule failed: %v", err)
+	}
+	run, err := executor.RunSchedule(context.Background(), sched.ID)
+	if err != nil || run.Error != "" {
+		t.Fatalf("RunSchedule = %+v, %v", run, err)
+	}
+	config := <-configs
+	if config.ProviderType != "test" || config.Custom["model"] != "fast" || config.SystemPrompt != "You fix tests." {
+		t.Errorf("expected the provider and agent configs applied, got %+v", config)
+	}
+	if sess, _ := executor.GetSession(run.SessionID); sess == nil || sess.AgentID != "a1" {
+		t.Errorf("expected the session to record its agent, got %+v", sess)
+	}
+
+	sched.AgentID = "missing"
+	if _, err := executor.UpdateSchedule(sched.ID, *sched); err != nil {
+		t.Fatalf("UpdateSchedule failed: %v", err)
+	}
+	if run, _ := executor.RunSchedule(context.Background(), sched.ID); run.Error == "" || run.SessionID != "" {
+		t.Errorf("expected a missing agent to fail the run, got %+v", run)
+	}
+}
+
+func TestAgentExecutor_SchedulesRequireStorage(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.Schedules(); !errors.Is(err, ErrSchedulesDisabled) {
+		t.Errorf("expected ErrSchedulesDisabled, got %v", err)
+	}
+	if runs := executor.RunDueSchedules(context.Background(), time.Now()); runs != nil {
+		t.Errorf("expected no runs, got %+v", runs)
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+	"github.com/ricochet1k/orbitmesh/internal/terminal"
+)
+
+// SessionBundleVersion is the schema version written into exported bundles.
+const SessionBundleVersion = 1
+
+var ErrInvalidSessionBundle = errors.New("invalid session bundle")
+
+// SessionBundle is a portable, self-contained copy of a session used to move
+// work between orbitmesh instances. Resume tokens are intentionally excluded:
+// they are bound to the instance that minted them.
+type SessionBundle struct {
+	Version    int                           `json:"version"`
+	ExportedAt time.Time                     `json:"exported_at"`
+	Session    domain.SessionSnapshot        `json:"session"`
+	Messages   []domain.Message              `json:"messages"`
+	Attempts   []*storage.RunAttemptMetadata `json:"attempts,omitempty"`
+	Terminal   *SessionBundleTerminal        `json:"terminal,omitempty"`
+	// MessagePins repeats the session's pins so readers of the bundle find
+	// its key outcomes first; imports use the session's.
+	MessagePins []domain.MessagePin `json:"message_pins,omitempty"`
+}
+
+// SessionBundleTerminal carries the persisted terminal record for a session.
+type SessionBundleTerminal struct {
+	Kind          domain.TerminalKind `json:"terminal_kind"`
+	CreatedAt     time.Time           `json:"created_at"`
+	LastUpdatedAt time.Time           `json:"last_updated_at"`
+	LastSeq       int64               `json:"last_seq,omitempty"`
+	Rows          int                 `json:"rows,omitempty"`
+	Cols          int                 `json:"cols,omitempty"`
+	Lines         []string            `json:"lines,omitempty"`
+}
+
+// ExportSessionBundle collects the session, its full message history, run
+// attempts and terminal record into a single bundle.
+func (e *AgentExecutor) ExportSessionBundle(id string) (*SessionBundle, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := sess.Snapshot()
+	messages := snap.Messages
+	if e.storage != nil {
+		if stored, err := e.storage.GetMessages(id); err == nil {
+			messages = stored
+		}
+	}
+	if messages == nil {
+		messages = []domain.Message{}
+	}
+	snap.Messages = nil
+
+	bundle := &SessionBundle{
+		Version:    SessionBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Session:    snap,
+		Messages:   messages,
+	}
+	bundle.MessagePins = snap.MessagePins
+
+	if e.attemptStorage != nil {
+		attempts, err := e.attemptStorage.ListRunAttempts(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list run attempts: %w", err)
+		}
+		bundle.Attempts = attempts
+	}
+
+	if e.terminalStorage != nil {
+		if term, err := e.terminalStorage.LoadTerminal(id); err == nil {
+			bt := &SessionBundleTerminal{
+				Kind:          term.Kind,
+				CreatedAt:     term.CreatedAt,
+				LastUpdatedAt: term.LastUpdatedAt,
+				LastSeq:       term.LastSeq,
+			}
+			if term.LastSnapshot != nil {
+				bt.Rows = term.LastSnapshot.Rows
+				bt.Cols = term.LastSnapshot.Cols
+				bt.Lines = term.LastSnapshot.Lines
+			}
+			bundle.Terminal = bt
+		}
+	}
+
+	return bundle, nil
+}
+
+// ImportSessionBundle recreates a bundled session under newID. All records
+// are remapped to the new ID; open attempts are closed as interrupted since
+// the provider process did not travel with the bundle. The session is stored
+// under its project's storage root, if the project has one.
+func (e *AgentExecutor) ImportSessionBundle(ctx context.Context, newID string, bundle *SessionBundle) (*domain.Session, error) {
+	if bundle == nil || bundle.Session.ID == "" {
+		return nil, fmt.Errorf("%w: missing session", ErrInvalidSessionBundle)
+	}
+	if bundle.Version > SessionBundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSessionBundle, bundle.Version)
+	}
+
+	if e.draining.Load() {
+		return nil, ErrExecutorShutdown
+	}
+	if e.readOnlyMirror {
+		return nil, ErrReadOnlyMirror
+	}
+
+	e.mu.Lock()
+	if _, exists := e.sessions[newID]; exists {
+		e.mu.Unlock()
+		return nil, ErrSessionExists
+	}
+	e.mu.Unlock()
+
+	sess, err := e.storeSessionBundle(newID, bundle, false)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, exists := e.sessions[newID]; exists {
+		return nil, ErrSessionExists
+	}
+	e.sessions[newID] = &sessionContext{session: sess}
+	return sess, nil
+}
+
+// storeSessionBundle saves the bundle's session, attempts and terminal under
+// newID. An imported session starts idle with its open attempts interrupted; a
+// mirrored one keeps the state and attempts of the instance it mirrors.
+func (e *AgentExecutor) storeSessionBundle(newID string, bundle *SessionBundle, mirror bool) (*domain.Session, error) {
+	snap := bundle.Session
+	snap.ID = newID
+	if !mirror {
+		snap.State = domain.SessionStateIdle
+		// The provider's conversation, and with it the context window it
+		// filled, stays on the instance that ran it.
+		snap.Conversation = nil
+		snap.ContextWindow = nil
+	}
+	snap.Messages = bundle.Messages
+	snap.SuspensionContext = nil
+	// Bundles may come from another instance; never import commands or
+	// messages to run.
+	snap.CleanupCommands = nil
+	snap.QueuedMessages = nil
+	// The workspace stays with the session it was made for.
+	snap.Workspace = nil
+	if snap.Transitions == nil {
+		snap.Transitions = []domain.StateTransition{}
+	}
+	if snap.Messages == nil {
+		snap.Messages = []domain.Message{}
+	}
+	sess := domain.SessionFromSnapshot(snap)
+
+	if e.storage != nil {
+		if err := e.saveSession(sess); err != nil {
+			return nil, fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	e.suggest.put(sess)
+
+	now := time.Now().UTC()
+	if e.attemptStorage != nil {
+		for _, src := range bundle.Attempts {
+			if src == nil || src.AttemptID == "" {
+				continue
+			}
+			attempt := *src
+			attempt.SessionID = newID
+			attempt.ResumeTokenID = ""
+			attempt.BootID = ""
+			if !mirror {
+				if attempt.EndedAt == nil {
+					attempt.EndedAt = &now
+					attempt.TerminalReason = "interrupted"
+					attempt.InterruptionReason = "session imported from bundle"
+				}
+				attempt.WaitKind = ""
+				attempt.WaitRef = ""
+				attempt.Waits = nil
+				attempt.WaitQuorum = 0
+			}
+			if err := e.attemptStorage.SaveRunAttempt(&attempt); err != nil {
+				return nil, fmt.Errorf("failed to save run attempt %s: %w", attempt.AttemptID, err)
+			}
+		}
+	}
+
+	if e.terminalStorage != nil && bundle.Terminal != nil {
+		bt := bundle.Terminal
+		term := domain.NewTerminal(newID, newID, bt.Kind)
+		term.CreatedAt = bt.CreatedAt
+		term.LastUpdatedAt = bt.LastUpdatedAt
+		term.LastSeq = bt.LastSeq
+		if bt.Rows > 0 || bt.Cols > 0 || len(bt.Lines) > 0 {
+			term.LastSnapshot = &terminal.Snapshot{Rows: bt.Rows, Cols: bt.Cols, Lines: bt.Lines}
+		}
+		if term.Kind == "" {
+			term.Kind = domain.TerminalKindAdHoc
+		}
+		if err := e.terminalStorage.SaveTerminal(term); err != nil {
+			return nil, fmt.Errorf("failed to save terminal: %w", err)
+		}
+	}
+	return sess, nil
+}
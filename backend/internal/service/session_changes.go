@@ -0,0 +1,187 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// maxSessionTombstones bounds how many deletions the change log remembers.
+// Clients further behind than the oldest one get the full list instead.
+const maxSessionTombstones = 10000
+
+// SessionChanges is what changed in the session list after a revision.
+type SessionChanges struct {
+	// Revision is the current revision, to pass to the next call.
+	Revision int64
+	// Reset means the revision asked about is unknown, because it predates
+	// the oldest remembered deletion or a server restart. Changed then lists
+	// every session and the client must replace its list.
+	Reset   bool
+	Changed []string
+	Deleted []string
+}
+
+// sessionChangeLog gives every session change a revision so clients can
+// fetch only the sessions changed since the revision they last saw.
+type sessionChangeLog struct {
+	mu      sync.Mutex
+	rev     int64
+	floor   int64
+	changed map[string]int64
+	deleted map[string]int64
+	subs    map[int64]chan struct{}
+	subID   int64
+	closed  bool
+}
+
+func newSessionChangeLog() *sessionChangeLog {
+	// Revisions start at the current time so they keep increasing across
+	// restarts; a revision handed out by an earlier run is below floor.
+	start := time.Now().UnixMicro()
+	return &sessionChangeLog{
+		rev:     start,
+		floor:   start,
+		changed: make(map[string]int64),
+		deleted: make(map[string]int64),
+		subs:    make(map[int64]chan struct{}),
+	}
+}
+
+func (l *sessionChangeLog) touch(id string) { l.record(id, false) }
+
+func (l *sessionChangeLog) remove(id string) { l.record(id, true) }
+
+func (l *sessionChangeLog) record(id string, deleted bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rev++
+	if deleted {
+		delete(l.changed, id)
+		l.deleted[id] = l.rev
+		if len(l.deleted) > maxSessionTombstones {
+			l.dropOldestTombstoneLocked()
+		}
+	} else {
+		delete(l.deleted, id)
+		l.changed[id] = l.rev
+	}
+	for _, ch := range l.subs {
+		select {
+		case ch <- struct{}{}:
+		default: // a wakeup is already pending
+		}
+	}
+}
+
+func (l *sessionChangeLog) dropOldestTombstoneLocked() {
+	var (
+		oldestID  string
+		oldestRev int64
+	)
+	for id, rev := range l.deleted {
+		if oldestID == "" || rev < oldestRev {
+			oldestID, oldestRev = id, rev
+		}
+	}
+	delete(l.deleted, oldestID)
+	l.floor = oldestRev
+}
+
+func (l *sessionChangeLog) since(rev int64) SessionChanges {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	changes := SessionChanges{Revision: l.rev}
+	if rev < l.floor || rev > l.rev {
+		changes.Reset = true
+		return changes
+	}
+	for id, r := range l.changed {
+		if r > rev {
+			changes.Changed = append(changes.Changed, id)
+		}
+	}
+	for id, r := range l.deleted {
+		if r > rev {
+			changes.Deleted = append(changes.Deleted, id)
+		}
+	}
+	sort.Slice(changes.Changed, func(i, j int) bool { return l.changed[changes.Changed[i]] < l.changed[changes.Changed[j]] })
+	sort.Strings(changes.Deleted)
+	return changes
+}
+
+func (l *sessionChangeLog) revision() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rev
+}
+
+func (l *sessionChangeLog) subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	l.subID++
+	id := l.subID
+	l.subs[id] = ch
+	l.mu.Unlock()
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.subs, id)
+		l.mu.Unlock()
+	}
+}
+
+// close closes every subscriber channel; later subscribers get a closed one.
+func (l *sessionChangeLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.closed = true
+	for id, ch := range l.subs {
+		close(ch)
+		delete(l.subs, id)
+	}
+}
+
+// saveSession persists sess and records the change for session list sync.
+func (e *AgentExecutor) saveSession(sess *domain.Session) error {
+	err := e.storage.Save(sess)
+	e.changes.touch(sess.ID)
+	return err
+}
+
+// SessionChangesSince reports the sessions created, updated or deleted after
+// revision rev. With Reset set, Changed holds every session in list order.
+func (e *AgentExecutor) SessionChangesSince(rev int64) SessionChanges {
+	changes := e.changes.since(rev)
+	if changes.Reset {
+		for _, sess := range e.ListSessions() {
+			changes.Changed = append(changes.Changed, sess.ID)
+		}
+	}
+	return changes
+}
+
+// SessionRevision returns the current session list revision.
+func (e *AgentExecutor) SessionRevision() int64 {
+	return e.changes.revision()
+}
+
+// SubscribeSessionChanges returns a channel that receives a wakeup after
+// sessions change, coalescing bursts, and a function that unsubscribes it.
+// Wakeups are sent from wherever a session is saved, so readers should
+// fetch the changes with SessionChangesSince rather than act inline. The
+// channel is closed on Shutdown.
+func (e *AgentExecutor) SubscribeSessionChanges() (<-chan struct{}, func()) {
+	return e.changes.subscribe()
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_SessionChangesSince(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	wake, unsubscribe := executor.SubscribeSessionChanges()
+	defer unsubscribe()
+
+	start := executor.SessionRevision()
+	for _, id := range []string{"keep", "drop"} {
+		if _, err := executor.CreateSession(context.Background(), id, session.Config{ProviderType: "test", WorkingDir: "/tmp", ProjectID: id}); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", id, err)
+		}
+	}
+	select {
+	case <-wake:
+	default:
+		t.Fatal("expected a wakeup after sessions changed")
+	}
+
+	changes := executor.SessionChangesSince(start)
+	if changes.Reset || len(changes.Changed) != 2 || len(changes.Deleted) != 0 {
+		t.Fatalf("unexpected changes after create: %+v", changes)
+	}
+
+	mid := changes.Revision
+	if err := executor.DeleteProjectSessions(context.Background(), "drop"); err != nil {
+		t.Fatalf("DeleteProjectSessions failed: %v", err)
+	}
+	changes = executor.SessionChangesSince(mid)
+	if changes.Reset || len(changes.Changed) != 0 || len(changes.Deleted) != 1 || changes.Deleted[0] != "drop" {
+		t.Fatalf("unexpected changes after delete: %+v", changes)
+	}
+	if again := executor.SessionChangesSince(changes.Revision); len(again.Changed)+len(again.Deleted) != 0 {
+		t.Fatalf("expected no changes at the current revision, got %+v", again)
+	}
+
+	// A revision from before this run is unknown, so the client resets.
+	changes = executor.SessionChangesSince(1)
+	if !changes.Reset || !slices.Contains(changes.Changed, "keep") {
+		t.Fatalf("expected a reset listing every session, got %+v", changes)
+	}
+}
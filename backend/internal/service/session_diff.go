@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
+)
+
+// MaxSessionDiffBytes caps the unified diff of a session's changes.
+const MaxSessionDiffBytes = 1 << 20
+
+// ErrNoDiffBase reports a session that did not start in a local git
+// checkout, so there is nothing to diff its changes against.
+var ErrNoDiffBase = errors.New("session did not start in a git checkout")
+
+// DiffFile is one file changed in a diff. Binary files have no line counts.
+type DiffFile struct {
+	Path       string
+	Insertions int
+	Deletions  int
+	Binary     bool
+}
+
+// SessionDiff is what changed in a session's working directory since the
+// session was created: commits, uncommitted changes and untracked files.
+type SessionDiff struct {
+	BaseRef    string
+	Files      []DiffFile
+	Insertions int
+	Deletions  int
+	// Diff is the unified diff, cut at a line boundary once it reaches
+	// MaxSessionDiffBytes; Truncated is then set.
+	Diff      string
+	Truncated bool
+}
+
+// startCommit is the HEAD of a new session's working directory, or "" when
+// it is not a local git checkout.
+func startCommit(ctx context.Context, dir string) string {
+	if dir == "" || remote.IsRemote(dir) {
+		return ""
+	}
+	head, err := resolveCommit(ctx, dir, "HEAD")
+	if err != nil {
+		return ""
+	}
+	return head
+}
+
+// SessionDiff diffs the session's working directory against the commit it
+// started from.
+func (e *AgentExecutor) SessionDiff(ctx context.Context, id string) (*SessionDiff, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	if sess.StartCommit == "" {
+		return nil, ErrNoDiffBase
+	}
+
+	dir := sess.WorkingDir
+	diff := &SessionDiff{BaseRef: sess.StartCommit}
+	err = withScratchIndex(ctx, dir, func(env []string) error {
+		numstat, err := runGitEnv(ctx, dir, env, "diff", "--cached", "--numstat", diff.BaseRef)
+		if err != nil {
+			return err
+		}
+		diff.Files = parseNumstat(numstat)
+		for _, f := range diff.Files {
+			diff.Insertions += f.Insertions
+			diff.Deletions += f.Deletions
+		}
+		patch, err := runGitEnv(ctx, dir, env, "diff", "--cached", "--no-color", diff.BaseRef)
+		if err != nil {
+			return err
+		}
+		if len(patch) > MaxSessionDiffBytes {
+			patch = patch[:MaxSessionDiffBytes]
+			if i := strings.LastIndexByte(patch, '\n'); i >= 0 {
+				patch = patch[:i+1]
+			}
+			diff.Truncated = true
+		}
+		diff.Diff = patch
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
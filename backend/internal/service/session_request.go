@@ -0,0 +1,333 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// SessionRequest is a session to create before its template, provider
+// config, project and agent are merged in. Sessions created through the API
+// and those started by schedules, watch rules and pipelines are all
+// resolved from one, so they get the same defaults.
+type SessionRequest struct {
+	ProviderType string
+	// ProviderID names a stored provider config whose env, API key and
+	// custom settings fill gaps in the request.
+	ProviderID string
+	// AgentID names a stored agent config whose system prompt, custom
+	// settings, MCP servers, features and cleanup commands fill gaps.
+	AgentID string
+	// TemplateID names a prompt template whose provider type, agent and
+	// rendered system prompt fill gaps, with TemplateVariables.
+	TemplateID        string
+	TemplateVariables map[string]string
+	// WorkingDir defaults to the project's path.
+	WorkingDir      string
+	ProjectID       string
+	Environment     map[string]string
+	SystemPrompt    string
+	MCPServers      []session.MCPServerConfig
+	Custom          map[string]any
+	TaskID          string
+	TaskTitle       string
+	SessionKind     string
+	Title           string
+	RecoveryPolicy  string
+	PlanApproval    bool
+	CommandApproval *domain.CommandApproval
+	ToolApproval    *domain.ToolApproval
+	CostBudget      *domain.CostBudget
+	Metadata        map[string]string
+	Features        map[string]bool
+	// Workspace's Repo defaults to the working directory.
+	Workspace *domain.Workspace
+	Git       *domain.GitTracking
+}
+
+// SessionRequestError is why a SessionRequest could not be resolved.
+// Missing names what the request referenced that does not exist:
+// "provider", "project" or "agent".
+type SessionRequestError struct {
+	Message string
+	Details string
+	Missing string
+}
+
+func (e *SessionRequestError) Error() string {
+	if e.Details == "" {
+		return e.Message
+	}
+	return e.Message + ": " + e.Details
+}
+
+// ResolveSessionRequest validates req and resolves it into a session
+// config, merging in its template, provider config, project and agent.
+// Request fields take priority over each of them. Errors are
+// *SessionRequestError, or the template lookup's error.
+func (e *AgentExecutor) ResolveSessionRequest(req SessionRequest) (session.Config, error) {
+	invalid := func(message, details string) (session.Config, error) {
+		return session.Config{}, &SessionRequestError{Message: message, Details: details}
+	}
+	notFound := func(missing string, err error) (session.Config, error) {
+		return session.Config{}, &SessionRequestError{Message: missing + " not found", Details: err.Error(), Missing: missing}
+	}
+
+	recoveryPolicy, err := ParseRecoveryPolicy(req.RecoveryPolicy)
+	if err != nil {
+		return invalid("invalid recovery_policy", err.Error())
+	}
+	if err := req.CostBudget.Validate(); err != nil {
+		return invalid("invalid cost_budget", err.Error())
+	}
+	if err := domain.ValidateMetadata(req.Metadata); err != nil {
+		return invalid("invalid metadata", err.Error())
+	}
+
+	// Apply the template's presets; the request's own fields take priority.
+	var template *domain.PromptTemplate
+	if req.TemplateID != "" {
+		t, err := e.Template(req.TemplateID)
+		if err != nil {
+			return session.Config{}, err
+		}
+		template = t
+		if req.ProviderType == "" && req.ProviderID == "" {
+			req.ProviderType = t.ProviderType
+		}
+		if req.AgentID == "" {
+			req.AgentID = t.AgentID
+		}
+	}
+
+	var providerConfig *storage.ProviderConfig
+	if req.ProviderID != "" {
+		if e.providerConfigs == nil {
+			return notFound("provider", fmt.Errorf("provider configs are not configured"))
+		}
+		cfg, err := e.providerConfigs.Get(req.ProviderID)
+		if err != nil {
+			return notFound("provider", err)
+		}
+		providerConfig = cfg
+		if req.ProviderType == "" {
+			req.ProviderType = cfg.Type
+		} else if req.ProviderType != cfg.Type {
+			return invalid("provider_type does not match provider config", "")
+		}
+	}
+
+	// Resolve working directory: explicit > project path.
+	workingDir := req.WorkingDir
+	projectContext, projectPath := "", ""
+	var cleanupCommands []string
+	if req.ProjectID != "" && e.projects != nil {
+		proj, err := e.projects.Get(req.ProjectID)
+		if err != nil {
+			return notFound("project", err)
+		}
+		if workingDir == "" {
+			workingDir = proj.Path
+		}
+		projectContext = fmt.Sprintf("Project: %s\nProject root: %s", proj.Name, proj.Path)
+		projectPath = proj.Path
+		cleanupCommands = proj.CleanupCommands
+	}
+	if workingDir == "" {
+		return invalid("working_dir is required", "")
+	}
+	if remote.IsRemote(workingDir) {
+		if _, err := remote.Parse(workingDir); err != nil {
+			return invalid("invalid working_dir", err.Error())
+		}
+	}
+	var workspace *domain.Workspace
+	if req.Workspace != nil {
+		ws := *req.Workspace
+		if ws.Mode != domain.WorkspaceWorktree && ws.Mode != domain.WorkspaceClone {
+			return invalid("invalid workspace", "mode must be worktree or clone")
+		}
+		explicitRepo := ws.Repo != ""
+		if !explicitRepo {
+			ws.Repo = workingDir
+		}
+		if ws.Mode == domain.WorkspaceWorktree && remote.IsRemote(ws.Repo) {
+			return invalid("invalid workspace", "worktrees need a local repository")
+		}
+		if !explicitRepo && remote.IsRemote(ws.Repo) {
+			return invalid("invalid workspace", "repo is required for a remote working_dir")
+		}
+		workspace = &ws
+	}
+	var gitTracking *domain.GitTracking
+	if req.Git != nil {
+		if workspace == nil && remote.IsRemote(workingDir) {
+			return invalid("invalid git", "git tracking needs a local working_dir or a workspace")
+		}
+		gitTracking = &domain.GitTracking{Branch: req.Git.Branch}
+	}
+
+	var agentConfig *storage.AgentConfig
+	if req.AgentID != "" && e.agentConfigs != nil {
+		cfg, err := e.agentConfigs.Get(req.AgentID)
+		if err != nil {
+			return notFound("agent", err)
+		}
+		agentConfig = cfg
+	}
+
+	config := session.Config{
+		ProviderType:    req.ProviderType,
+		AgentID:         req.AgentID,
+		WorkingDir:      workingDir,
+		ProjectID:       req.ProjectID,
+		Environment:     maps.Clone(req.Environment),
+		SystemPrompt:    req.SystemPrompt,
+		Custom:          maps.Clone(req.Custom),
+		TaskID:          req.TaskID,
+		TaskTitle:       req.TaskTitle,
+		SessionKind:     req.SessionKind,
+		Title:           req.Title,
+		ProjectContext:  projectContext,
+		ProjectPath:     projectPath,
+		RecoveryPolicy:  string(recoveryPolicy),
+		PlanApproval:    req.PlanApproval,
+		CommandApproval: req.CommandApproval,
+		ToolApproval:    req.ToolApproval,
+		CostBudget:      req.CostBudget,
+		Metadata:        req.Metadata,
+		Features:        maps.Clone(req.Features),
+		Workspace:       workspace,
+		GitTracking:     gitTracking,
+	}
+
+	// The template's system prompt comes before the agent's.
+	if template != nil && config.SystemPrompt == "" && template.SystemPrompt != "" {
+		vars := e.TemplateVariables(req.TaskID, req.TaskTitle, req.ProjectID, workingDir, req.ProviderType, req.TemplateVariables)
+		prompt, err := template.Render(template.SystemPrompt, vars)
+		if err != nil {
+			return invalid("cannot render template", err.Error())
+		}
+		config.SystemPrompt = prompt
+	}
+
+	// Apply agent config defaults (agent values only fill gaps left by the request).
+	if agentConfig != nil {
+		if config.SystemPrompt == "" && agentConfig.SystemPrompt != "" {
+			config.SystemPrompt = agentConfig.SystemPrompt
+		}
+		config.Custom = fillGaps(config.Custom, agentConfig.Custom)
+		// Agent MCP servers are only used when the request doesn't supply its own list
+		// and the session is not a dock session (dock servers are always overridden below).
+		if len(req.MCPServers) == 0 && req.SessionKind != domain.SessionKindDock && len(agentConfig.MCPServers) > 0 {
+			config.MCPServers = agentConfig.MCPServers
+		}
+		config.Features = fillGaps(config.Features, agentConfig.Features)
+		cleanupCommands = append(cleanupCommands, agentConfig.CleanupCommands...)
+	}
+	if err := session.ValidateFeatures(config.Features); err != nil {
+		return invalid("invalid features", err.Error())
+	}
+	config.CleanupCommands = cleanupCommands
+
+	if providerConfig != nil {
+		config.Environment = fillGaps(config.Environment, providerConfig.Env)
+		if providerConfig.APIKey != "" {
+			envKey := ""
+			switch providerConfig.Type {
+			case "adk":
+				envKey = "GOOGLE_API_KEY"
+			case "anthropic", "claude", "claude-ws", "acp":
+				envKey = "ANTHROPIC_API_KEY"
+			case "openai":
+				envKey = "OPENAI_API_KEY"
+			}
+			if envKey != "" {
+				config.Environment = fillGaps(config.Environment, map[string]string{envKey: providerConfig.APIKey})
+			}
+		}
+		config.Custom = fillGaps(config.Custom, providerConfig.Custom)
+		if providerConfig.Type == "pty" && len(providerConfig.Command) > 0 {
+			command := map[string]any{"command": providerConfig.Command[0]}
+			if len(providerConfig.Command) > 1 {
+				command["args"] = providerConfig.Command[1:]
+			}
+			config.Custom = fillGaps(config.Custom, command)
+		}
+	}
+	if req.SessionKind == domain.SessionKindDock {
+		config.MCPServers = dockMCPServers()
+	} else if len(req.MCPServers) > 0 {
+		config.MCPServers = req.MCPServers
+	}
+	if err := session.ValidateMCPServers(config.MCPServers); err != nil {
+		return invalid("invalid mcp_servers", err.Error())
+	}
+	return config, nil
+}
+
+// fillGaps sets the keys of defaults that dst lacks, allocating dst if
+// needed.
+func fillGaps[V any](dst, defaults map[string]V) map[string]V {
+	for k, v := range defaults {
+		if _, ok := dst[k]; ok {
+			continue
+		}
+		if dst == nil {
+			dst = make(map[string]V, len(defaults))
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+// dockMCPServers is the MCP server of dock sessions, pointed at this
+// server's API with the run's token.
+func dockMCPServers() []session.MCPServerConfig {
+	return []session.MCPServerConfig{
+		{
+			Name:    "orbitmesh-mcp",
+			Command: "orbitmesh-mcp",
+			Args:    []string{"dock"},
+			Env: map[string]string{
+				"ORBITMESH_DOCK_SESSION_ID": "{{.SessionID}}",
+				"ORBITMESH_API_BASE_URL":    "{{.APIBaseURL}}",
+				"ORBITMESH_API_TOKEN":       "{{.APIToken}}",
+				"ORBITMESH_PROJECT_PATH":    "{{.ProjectPath}}",
+			},
+		},
+	}
+}
+
+// TemplateVariables returns the built-in template variables of a session
+// overlaid with the supplied ones. Built-ins without a value are left out so
+// a template's defaults apply.
+func (e *AgentExecutor) TemplateVariables(taskID, taskTitle, projectID, workingDir, providerType string, supplied map[string]string) map[string]string {
+	repo := workingDir
+	vars := map[string]string{
+		domain.TemplateVarTaskID:       taskID,
+		domain.TemplateVarTaskTitle:    taskTitle,
+		domain.TemplateVarWorkingDir:   workingDir,
+		domain.TemplateVarProviderType: providerType,
+		domain.TemplateVarDate:         time.Now().UTC().Format(time.DateOnly),
+	}
+	if projectID != "" && e.projects != nil {
+		if p, err := e.projects.Get(projectID); err == nil {
+			vars[domain.TemplateVarProject] = p.Name
+			repo = p.Path
+		}
+	}
+	if repo != "" {
+		vars[domain.TemplateVarRepo] = filepath.Base(strings.TrimRight(repo, "/"))
+	}
+	maps.DeleteFunc(vars, func(_, v string) bool { return v == "" })
+	maps.Copy(vars, supplied)
+	return vars
+}
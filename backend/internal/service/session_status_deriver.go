@@ -8,13 +8,21 @@ import (
 )
 
 // DeriveSessionState projects session state from live runtime presence and
-// persisted run-attempt metadata.
+// persisted run-attempt metadata. A read-only mirror reports the state the
+// mirrored instance saved.
 func (e *AgentExecutor) DeriveSessionState(id string) (domain.SessionState, error) {
-	if _, err := e.GetSession(id); err != nil {
+	sess, err := e.GetSession(id)
+	if err != nil {
 		return domain.SessionStateIdle, err
 	}
+	if e.readOnlyMirror {
+		return sess.GetState(), nil
+	}
 
 	if e.hasLiveRun(id) {
+		if e.questions.pending(id) != nil {
+			return domain.SessionStateSuspended, nil
+		}
 		return domain.SessionStateRunning, nil
 	}
 
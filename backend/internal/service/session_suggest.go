@@ -0,0 +1,194 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// Suggestion limits for SuggestSessions.
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+)
+
+// SessionSuggestion is one typeahead match. Field names which session field
+// matched: "id", "title", "task" or "tag".
+type SessionSuggestion struct {
+	ID          string
+	Title       string
+	CurrentTask string
+	Kind        string
+	Pinned      bool
+	UpdatedAt   time.Time
+	Field       string
+}
+
+// suggestIndex is a small in-memory index of the fields typeahead matches
+// on. It is seeded from storage on first use and kept current by the
+// executor paths that change those fields, so queries never touch disk.
+type suggestIndex struct {
+	mu      sync.RWMutex
+	load    sync.Once
+	entries map[string]suggestEntry
+}
+
+type suggestEntry struct {
+	suggestion SessionSuggestion
+	id         string
+	title      []string // lowercased full title followed by its words
+	task       []string
+	tags       []string // lowercased kind and provider type
+}
+
+func newSuggestIndex() *suggestIndex {
+	return &suggestIndex{entries: make(map[string]suggestEntry)}
+}
+
+func (idx *suggestIndex) put(sess *domain.Session) {
+	snap := sess.Snapshot()
+	entry := suggestEntry{
+		suggestion: SessionSuggestion{
+			ID:          snap.ID,
+			Title:       snap.Title,
+			CurrentTask: snap.CurrentTask,
+			Kind:        snap.Kind,
+			Pinned:      snap.Pinned,
+			UpdatedAt:   snap.UpdatedAt,
+		},
+		id:    strings.ToLower(snap.ID),
+		title: suggestTerms(snap.Title),
+		task:  suggestTerms(snap.CurrentTask),
+	}
+	for _, tag := range []string{snap.Kind, snap.ProviderType} {
+		if tag != "" {
+			entry.tags = append(entry.tags, strings.ToLower(tag))
+		}
+	}
+
+	idx.mu.Lock()
+	idx.entries[snap.ID] = entry
+	idx.mu.Unlock()
+}
+
+func (idx *suggestIndex) remove(id string) {
+	idx.mu.Lock()
+	delete(idx.entries, id)
+	idx.mu.Unlock()
+}
+
+// suggestTerms returns the lowercased text followed by its words, so both
+// whole-field and word prefixes match.
+func suggestTerms(text string) []string {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return nil
+	}
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r > 127)
+	})
+	return append([]string{text}, words...)
+}
+
+func hasPrefixTerm(terms []string, q string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(term, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// match ranks an entry against q; lower is better and -1 means no match.
+func (entry suggestEntry) match(q string) (int, string) {
+	switch {
+	case entry.id == q:
+		return 0, "id"
+	case len(entry.title) > 0 && strings.HasPrefix(entry.title[0], q):
+		return 1, "title"
+	case hasPrefixTerm(entry.title, q):
+		return 2, "title"
+	case strings.HasPrefix(entry.id, q):
+		return 3, "id"
+	case hasPrefixTerm(entry.task, q):
+		return 4, "task"
+	case hasPrefixTerm(entry.tags, q):
+		return 5, "tag"
+	default:
+		return -1, ""
+	}
+}
+
+// SuggestSessions returns sessions whose ID, title, current task or tags
+// (kind, provider type) start with q, best matches first. It is meant for
+// typeahead and answers from memory.
+func (e *AgentExecutor) SuggestSessions(q string, limit int) []SessionSuggestion {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	limit = min(limit, MaxSuggestLimit)
+
+	e.loadSuggestIndex()
+
+	type ranked struct {
+		SessionSuggestion
+		rank int
+	}
+	var matches []ranked
+	e.suggest.mu.RLock()
+	for _, entry := range e.suggest.entries {
+		rank, field := 0, ""
+		if q != "" {
+			if rank, field = entry.match(q); rank < 0 {
+				continue
+			}
+		}
+		s := entry.suggestion
+		s.Field = field
+		matches = append(matches, ranked{SessionSuggestion: s, rank: rank})
+	}
+	e.suggest.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.Pinned != b.Pinned {
+			return a.Pinned
+		}
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	out := make([]SessionSuggestion, 0, min(limit, len(matches)))
+	for _, m := range matches[:min(limit, len(matches))] {
+		out = append(out, m.SessionSuggestion)
+	}
+	return out
+}
+
+// loadSuggestIndex seeds the index from storage once. Entries already put by
+// the executor are newer than their stored copies and are kept.
+func (e *AgentExecutor) loadSuggestIndex() {
+	e.suggest.load.Do(func() {
+		for _, sess := range e.ListSessions() {
+			e.suggest.putIfAbsent(sess)
+		}
+	})
+}
+
+func (idx *suggestIndex) putIfAbsent(sess *domain.Session) {
+	idx.mu.RLock()
+	_, exists := idx.entries[sess.ID]
+	idx.mu.RUnlock()
+	if !exists {
+		idx.put(sess)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_SuggestSessions(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	// A session only in storage is picked up when the index is seeded.
+	stored := domain.NewSession("stored-1", "mock", "/tmp")
+	stored.SetTitle("Refactor billing")
+	if err := store.Save(stored); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	for _, tc := range []struct{ id, title, taskID, taskTitle string }{
+		{"alpha", "Fix login redirect", "", ""},
+		{"bravo", "Deploy pipeline", "T-1", "Fix flaky deploy"},
+		{"fixer", "Docs", "", ""},
+	} {
+		if _, err := executor.CreateSession(context.Background(), tc.id, session.Config{
+			ProviderType: "mock",
+			WorkingDir:   "/tmp",
+			Title:        tc.title,
+			TaskID:       tc.taskID,
+			TaskTitle:    tc.taskTitle,
+		}); err != nil {
+			t.Fatalf("create %s: %v", tc.id, err)
+		}
+	}
+
+	got := executor.SuggestSessions("fix", 0)
+	want := []struct{ id, field string }{
+		{"alpha", "title"},
+		{"fixer", "id"},
+		{"bravo", "task"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("suggestions = %+v, want %d", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].ID != w.id || got[i].Field != w.field {
+			t.Errorf("suggestion[%d] = %s/%s, want %s/%s", i, got[i].ID, got[i].Field, w.id, w.field)
+		}
+	}
+
+	if got := executor.SuggestSessions("BILL", 0); len(got) != 1 || got[0].ID != "stored-1" {
+		t.Fatalf("stored session suggestions = %+v", got)
+	}
+	if got := executor.SuggestSessions("", 2); len(got) != 2 {
+		t.Fatalf("empty query with limit 2 returned %d", len(got))
+	}
+
+	if _, err := executor.SetSessionPinned("fixer", true); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if got := executor.SuggestSessions("", 1); len(got) != 1 || got[0].ID != "fixer" {
+		t.Fatalf("pinned session should sort first, got %+v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// TimelineSpan is one bar of a session timeline. End is nil while the span
+// is still open; Duration then runs up to the time the timeline was built.
+type TimelineSpan struct {
+	ID       string
+	Label    string
+	Detail   string
+	Start    time.Time
+	End      *time.Time
+	Duration time.Duration
+	// Lane is the row the span goes on within its bucket so that spans
+	// overlapping in time, like parallel tool calls, do not share one.
+	Lane int
+}
+
+// SessionTimeline buckets a session's history into spans for a Gantt-style
+// view. Each bucket is ordered by start time.
+type SessionTimeline struct {
+	SessionID   string
+	Start       time.Time
+	End         time.Time
+	Runs        []TimelineSpan
+	Suspensions []TimelineSpan
+	ToolCalls   []TimelineSpan
+	HumanWaits  []TimelineSpan
+	Takeovers   []TimelineSpan
+}
+
+// SessionTimeline builds the session's timeline from its run attempts, state
+// transitions, tool call messages and takeovers.
+func (e *AgentExecutor) SessionTimeline(id string) (*SessionTimeline, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := e.RunAttempts(id)
+	if err != nil {
+		return nil, err
+	}
+	snap := sess.Snapshot()
+	messages := snap.Messages
+	if e.storage != nil {
+		if stored, err := e.storage.GetMessages(id); err == nil {
+			messages = stored
+		}
+	}
+
+	now := time.Now().UTC()
+	tl := &SessionTimeline{SessionID: id, Start: snap.CreatedAt, End: now}
+
+	for _, a := range attempts {
+		label := a.ProviderType
+		if a.ProviderID != "" {
+			label = a.ProviderID
+		}
+		detail := a.TerminalReason
+		if a.InterruptionReason != "" {
+			detail = a.InterruptionReason
+		}
+		end := a.EndedAt
+		if e.AttemptAbandoned(a) {
+			// Left open by an earlier server process; it ended no later
+			// than its last heartbeat.
+			hb := a.HeartbeatAt
+			end = &hb
+		}
+		tl.Runs = append(tl.Runs, newTimelineSpan(a.AttemptID, label, detail, a.StartedAt, end, now))
+	}
+
+	var open *domain.StateTransition
+	closeWait := func(at *time.Time) {
+		if open == nil {
+			return
+		}
+		kind, detail := waitFromReason(open.Reason)
+		span := newTimelineSpan("", kind, detail, open.Timestamp, at, now)
+		if kind == domain.WaitKindWaitingOnHuman || kind == domain.WaitKindPlanApproval || kind == domain.WaitKindCommandApproval || kind == domain.WaitKindToolApproval {
+			tl.HumanWaits = append(tl.HumanWaits, span)
+		} else {
+			tl.Suspensions = append(tl.Suspensions, span)
+		}
+		open = nil
+	}
+	for i, t := range snap.Transitions {
+		if t.From == domain.SessionStateSuspended {
+			at := t.Timestamp
+			closeWait(&at)
+		}
+		if t.To == domain.SessionStateSuspended {
+			open = &snap.Transitions[i]
+		}
+	}
+	closeWait(nil)
+
+	calls := make(map[string]int)
+	for _, msg := range messages {
+		if msg.Kind != domain.MessageKindToolUse {
+			continue
+		}
+		name, callID, parentID, ok := parseToolUseContents(msg.Contents)
+		if !ok {
+			continue
+		}
+		at := msg.Timestamp
+		if i, seen := calls[callID]; seen {
+			span := &tl.ToolCalls[i]
+			if span.Label == "" {
+				span.Label = name
+			}
+			span.End = &at
+			span.Duration = at.Sub(span.Start)
+			continue
+		}
+		calls[callID] = len(tl.ToolCalls)
+		tl.ToolCalls = append(tl.ToolCalls, newTimelineSpan(callID, name, parentID, at, &at, now))
+	}
+
+	for _, t := range snap.Takeovers {
+		tl.Takeovers = append(tl.Takeovers, newTimelineSpan("", t.By, t.Reason, t.StartedAt, t.EndedAt, now))
+	}
+
+	for _, bucket := range [][]TimelineSpan{tl.Runs, tl.Suspensions, tl.ToolCalls, tl.HumanWaits, tl.Takeovers} {
+		assignTimelineLanes(bucket)
+		for _, span := range bucket {
+			if tl.Start.IsZero() || span.Start.Before(tl.Start) {
+				tl.Start = span.Start
+			}
+		}
+	}
+	return tl, nil
+}
+
+func newTimelineSpan(id, label, detail string, start time.Time, end *time.Time, now time.Time) TimelineSpan {
+	span := TimelineSpan{ID: id, Label: label, Detail: detail, Start: start, End: end}
+	if end != nil {
+		span.Duration = end.Sub(start)
+	} else {
+		span.Duration = now.Sub(start)
+	}
+	if span.Duration < 0 {
+		span.Duration = 0
+	}
+	return span
+}
+
+// waitFromReason splits a suspension reason into the wait kind and the rest.
+// Tool call suspensions predate the wait kind prefix and are matched on
+// their wording.
+func waitFromReason(reason string) (kind, detail string) {
+	for _, k := range []string{domain.WaitKindWaitingOnHuman, domain.WaitKindQueuedRemote, domain.WaitKindPlanApproval, domain.WaitKindCommandApproval, domain.WaitKindToolApproval, domain.WaitKindWorkingHours, domain.WaitKindShutdown} {
+		if rest, ok := strings.CutPrefix(reason, k+":"); ok {
+			return k, strings.TrimSpace(rest)
+		}
+	}
+	if rest, ok := strings.CutPrefix(reason, "waiting for tool result:"); ok {
+		return domain.WaitKindToolCall, strings.TrimSpace(rest)
+	}
+	return "suspended", reason
+}
+
+// assignTimelineLanes sorts spans by start and puts each on the lowest lane
+// free at its start.
+func assignTimelineLanes(spans []TimelineSpan) {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+	var laneEnds []time.Time
+	for i := range spans {
+		end := spans[i].Start.Add(spans[i].Duration)
+		lane := 0
+		for lane < len(laneEnds) && laneEnds[lane].After(spans[i].Start) {
+			lane++
+		}
+		if lane == len(laneEnds) {
+			laneEnds = append(laneEnds, end)
+		} else {
+			laneEnds[lane] = end
+		}
+		spans[i].Lane = lane
+	}
+}
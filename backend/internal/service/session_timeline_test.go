@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_SessionTimeline(t *testing.T) {
+	executor, store := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	sess, err := executor.CreateSession(context.Background(), "timeline", session.Config{ProviderType: "test", WorkingDir: "/tmp"})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	started := time.Now().UTC().Add(-time.Minute)
+	_ = store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:    "attempt1",
+		SessionID:    sess.ID,
+		ProviderType: "test",
+		StartedAt:    started,
+		BootID:       executor.bootID,
+	})
+
+	_ = sess.TransitionTo(domain.SessionStateRunning, "")
+	_ = sess.TransitionTo(domain.SessionStateSuspended, domain.WaitKindWaitingOnHuman+": which branch?")
+	_ = sess.TransitionTo(domain.SessionStateRunning, "answered")
+	sess.AppendMessage(domain.MessageKindToolUse, toolUseContents(domain.ToolCallData{ID: "a", Name: "Bash"}))
+	sess.AppendMessage(domain.MessageKindToolUse, toolUseContents(domain.ToolCallData{ID: "b", Name: "Read", ParentID: "a"}))
+	time.Sleep(5 * time.Millisecond)
+	sess.AppendMessage(domain.MessageKindToolUse, toolUseContents(domain.ToolCallData{ID: "a"}))
+	_ = sess.TransitionTo(domain.SessionStateSuspended, "waiting for tool result: a")
+
+	tl, err := executor.SessionTimeline(sess.ID)
+	if err != nil {
+		t.Fatalf("SessionTimeline failed: %v", err)
+	}
+
+	if len(tl.Runs) != 1 || tl.Runs[0].End != nil || tl.Runs[0].Duration < time.Minute {
+		t.Fatalf("expected one open run of at least a minute, got %+v", tl.Runs)
+	}
+	if !tl.Start.Equal(started) {
+		t.Errorf("Start = %v, want the run start %v", tl.Start, started)
+	}
+	if len(tl.HumanWaits) != 1 || tl.HumanWaits[0].End == nil || tl.HumanWaits[0].Detail != "which branch?" {
+		t.Fatalf("expected one closed human wait, got %+v", tl.HumanWaits)
+	}
+	if len(tl.Suspensions) != 1 || tl.Suspensions[0].End != nil || tl.Suspensions[0].Label != domain.WaitKindToolCall {
+		t.Fatalf("expected one open tool call suspension, got %+v", tl.Suspensions)
+	}
+
+	if len(tl.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %+v", tl.ToolCalls)
+	}
+	bash, read := tl.ToolCalls[0], tl.ToolCalls[1]
+	if bash.ID != "a" || bash.Label != "Bash" || bash.Duration <= 0 {
+		t.Errorf("unexpected first call: %+v", bash)
+	}
+	if read.ID != "b" || read.Detail != "a" || read.Lane != 1 {
+		t.Errorf("expected the overlapping subagent call on lane 1, got %+v", read)
+	}
+}
+
+func TestParseToolUseContents(t *testing.T) {
+	for _, data := range []domain.ToolCallData{
+		{ID: "call_1", Name: "Bash"},
+		{ID: "call_2", Name: "mcp: search", ParentID: "task_1"},
+		{ID: "call_3"},
+	} {
+		name, id, parent, ok := parseToolUseContents(toolUseContents(data))
+		if !ok || name != data.Name || id != data.ID || parent != data.ParentID {
+			t.Errorf("round trip of %+v = %q %q %q %v", data, name, id, parent, ok)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// Default per-phase shutdown timeouts.
+const (
+	DefaultShutdownSuspendTimeout = 10 * time.Second
+	DefaultShutdownStopTimeout    = 10 * time.Second
+	DefaultShutdownKillTimeout    = 5 * time.Second
+)
+
+var errNotSuspendable = errors.New("provider cannot suspend")
+
+// ShutdownTimeouts bounds the phases of Shutdown. Zero fields use the
+// defaults. The context passed to Shutdown bounds them all.
+type ShutdownTimeouts struct {
+	Suspend time.Duration
+	Stop    time.Duration
+	Kill    time.Duration
+}
+
+func (t ShutdownTimeouts) withDefaults() ShutdownTimeouts {
+	if t.Suspend <= 0 {
+		t.Suspend = DefaultShutdownSuspendTimeout
+	}
+	if t.Stop <= 0 {
+		t.Stop = DefaultShutdownStopTimeout
+	}
+	if t.Kill <= 0 {
+		t.Kill = DefaultShutdownKillTimeout
+	}
+	return t
+}
+
+// Shutdown stops the executor in phases, each with its own timeout:
+//
+//  1. drain: new sessions and runs are refused.
+//  2. suspend: runs of providers that implement session.Suspendable are
+//     checkpointed and suspended. Their attempts stay open, so startup
+//     recovery applies the session's recovery policy to them.
+//  3. stop: the other runs are stopped gracefully.
+//  4. kill: runs that are still alive are killed.
+//
+// Sessions that are already waiting without a live run are left as they
+// are. Pending usage counters and tool stats are written. The outcome is
+// logged and saved as a storage.ShutdownReport for the next startup's
+// recovery report.
+func (e *AgentExecutor) Shutdown(ctx context.Context) error {
+	defer e.changes.close()
+	timeouts := e.shutdownTimeouts.withDefaults()
+	report := storage.ShutdownReport{StartedAt: time.Now().UTC(), Clean: true, Sessions: []storage.ShutdownSession{}}
+
+	start := time.Now()
+	e.draining.Store(true)
+	e.warm.drain()
+	live := e.liveRunSessions()
+	e.recordShutdownPhase(&report, storage.ShutdownPhaseDrain, start, len(live), false)
+
+	live = e.shutdownPhase(ctx, &report, storage.ShutdownPhaseSuspend, timeouts.Suspend, live, storage.ShutdownSuspended, e.suspendForShutdown)
+	live = e.shutdownPhase(ctx, &report, storage.ShutdownPhaseStop, timeouts.Stop, live, storage.ShutdownStopped, func(ctx context.Context, sc *sessionContext) error {
+		return e.StopSession(ctx, sc.session.ID)
+	})
+
+	// Cancelling the executor context ends every run and background loop.
+	start = time.Now()
+	e.cancel()
+	for _, sc := range live {
+		outcome := storage.ShutdownSession{SessionID: sc.session.ID, Outcome: storage.ShutdownKilled, Phase: storage.ShutdownPhaseKill}
+		if err := e.KillSession(sc.session.ID); err != nil {
+			outcome.Outcome = storage.ShutdownFailed
+			outcome.Error = err.Error()
+		}
+		report.Sessions = append(report.Sessions, outcome)
+		report.Clean = false
+	}
+	killCtx, cancel := context.WithTimeout(ctx, timeouts.Kill)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-killCtx.Done():
+		err = ctx.Err()
+		if err == nil {
+			err = fmt.Errorf("shutdown: background work still running after %s", timeouts.Kill)
+		}
+	}
+	e.recordShutdownPhase(&report, storage.ShutdownPhaseKill, start, len(live), err != nil)
+	e.projectCosts.saver.flush()
+	e.missionCosts.saver.flush()
+	e.toolStats.saver.flush()
+
+	report.FinishedAt = time.Now().UTC()
+	e.saveShutdownReport(report)
+	return err
+}
+
+// shutdownPhase runs op on every session concurrently until the phase times
+// out. Sessions op succeeded on are recorded with outcome; the rest are
+// returned for the next phase.
+func (e *AgentExecutor) shutdownPhase(ctx context.Context, report *storage.ShutdownReport, name string, timeout time.Duration, sessions []*sessionContext, outcome string, op func(context.Context, *sessionContext) error) []*sessionContext {
+	start := time.Now()
+	if len(sessions) == 0 {
+		e.recordShutdownPhase(report, name, start, 0, false)
+		return nil
+	}
+	phaseCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		sc  *sessionContext
+		err error
+	}
+	results := make(chan result, len(sessions))
+	for _, sc := range sessions {
+		go func() { results <- result{sc, op(phaseCtx, sc)} }()
+	}
+
+	done := make(map[*sessionContext]bool, len(sessions))
+	timedOut := false
+	for received := 0; received < len(sessions) && !timedOut; {
+		select {
+		case r := <-results:
+			received++
+			if r.err != nil {
+				if !errors.Is(r.err, errNotSuspendable) {
+					log.Printf("shutdown: %s %s: %v", name, r.sc.session.ID, r.err)
+				}
+				continue
+			}
+			done[r.sc] = true
+			report.Sessions = append(report.Sessions, storage.ShutdownSession{SessionID: r.sc.session.ID, Outcome: outcome, Phase: name})
+		case <-phaseCtx.Done():
+			timedOut = true
+		}
+	}
+
+	var rest []*sessionContext
+	for _, sc := range sessions {
+		if !done[sc] {
+			rest = append(rest, sc)
+		}
+	}
+	e.recordShutdownPhase(report, name, start, len(done), timedOut)
+	return rest
+}
+
+// suspendForShutdown checkpoints and suspends one run. The provider is
+// killed after its state is captured; the run attempt is left open for
+// startup recovery.
+func (e *AgentExecutor) suspendForShutdown(ctx context.Context, sc *sessionContext) error {
+	run := sc.getRun()
+	if run == nil {
+		return nil
+	}
+	suspendable, ok := run.Session.(session.Suspendable)
+	if !ok {
+		return errNotSuspendable
+	}
+	suspension, err := suspendable.Suspend(ctx)
+	if err != nil {
+		return err
+	}
+
+	run.Cancel()
+	sc.session.SetSuspensionContext(suspension)
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.WaitKind = domain.WaitKindShutdown
+		a.WaitRef = ""
+		a.HeartbeatAt = time.Now().UTC()
+	})
+	if sc.session.GetState() == domain.SessionStateRunning {
+		e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitShutdown))
+	} else if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+	e.closeTerminalHub(sc.session.ID)
+	if err := run.Session.Kill(); err != nil {
+		log.Printf("shutdown: killing suspended %s: %v", sc.session.ID, err)
+	}
+	return nil
+}
+
+func (e *AgentExecutor) liveRunSessions() []*sessionContext {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var live []*sessionContext
+	for _, sc := range e.sessions {
+		if sc.getRun() != nil {
+			live = append(live, sc)
+		}
+	}
+	return live
+}
+
+func (e *AgentExecutor) recordShutdownPhase(report *storage.ShutdownReport, name string, start time.Time, sessions int, timedOut bool) {
+	report.Phases = append(report.Phases, storage.ShutdownPhase{
+		Name:       name,
+		DurationMS: time.Since(start).Milliseconds(),
+		Sessions:   sessions,
+		TimedOut:   timedOut,
+	})
+	if timedOut {
+		report.Clean = false
+	}
+}
+
+func (e *AgentExecutor) saveShutdownReport(report storage.ShutdownReport) {
+	counts := map[string]int{}
+	for _, s := range report.Sessions {
+		counts[s.Outcome]++
+		if s.Error != "" {
+			log.Printf("shutdown: session %s %s: %s", s.SessionID, s.Outcome, s.Error)
+		}
+	}
+	log.Printf("shutdown: clean=%t suspended=%d stopped=%d killed=%d failed=%d in %s",
+		report.Clean, counts[storage.ShutdownSuspended], counts[storage.ShutdownStopped], counts[storage.ShutdownKilled], counts[storage.ShutdownFailed],
+		report.FinishedAt.Sub(report.StartedAt).Round(time.Millisecond))
+	if e.shutdownReports == nil {
+		return
+	}
+	if err := e.shutdownReports.Save(report); err != nil {
+		log.Printf("shutdown: saving report: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_ShutdownSuspendsAndReports(t *testing.T) {
+	store := newMockStorage()
+	reports := storage.NewShutdownReportStorage(t.TempDir())
+	newExecutor := func() *AgentExecutor {
+		return NewAgentExecutor(ExecutorConfig{
+			Storage:         store,
+			Broadcaster:     NewEventBroadcaster(100),
+			ShutdownReports: reports,
+			ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+				return newMockProvider(), nil
+			},
+		})
+	}
+
+	executor := newExecutor()
+	if _, err := executor.StartSession(context.Background(), "s1", session.Config{ProviderType: "test", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("start session: %v", err)
+	}
+	sess, err := executor.SendMessage(context.Background(), "s1", "hello", "", "")
+	if err != nil {
+		t.Fatalf("send message: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := executor.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "s1", "again", "", ""); err == nil {
+		t.Fatal("expected runs to be refused after shutdown")
+	}
+
+	if state := sess.GetState(); state != domain.SessionStateSuspended {
+		t.Fatalf("state = %s, want suspended", state)
+	}
+	attempt := waitForRunAttempt(t, store, "s1", false)
+	if attempt.EndedAt != nil || attempt.WaitKind != domain.WaitKindShutdown {
+		t.Fatalf("attempt = %+v, want open and waiting for %s", attempt, domain.WaitKindShutdown)
+	}
+
+	next := newExecutor()
+	t.Cleanup(func() { next.Shutdown(context.Background()) })
+	if err := next.Startup(context.Background()); err != nil {
+		t.Fatalf("startup: %v", err)
+	}
+	report := next.RecoveryReport()
+	if report == nil || report.PreviousShutdown == nil {
+		t.Fatalf("expected the previous shutdown in the recovery report, got %+v", report)
+	}
+	previous := report.PreviousShutdown
+	if !previous.Clean || len(previous.Phases) != 4 {
+		t.Fatalf("unexpected shutdown report: %+v", previous)
+	}
+	if len(previous.Sessions) != 1 || previous.Sessions[0].SessionID != "s1" || previous.Sessions[0].Outcome != storage.ShutdownSuspended {
+		t.Fatalf("unexpected shutdown sessions: %+v", previous.Sessions)
+	}
+	if report.AttemptsClosed != 1 {
+		t.Fatalf("attempts closed = %d, want 1", report.AttemptsClosed)
+	}
+
+	// The report is consumed by the startup that read it.
+	if again, err := reports.Take(); err != nil || again != nil {
+		t.Fatalf("report should be consumed, got %+v, err %v", again, err)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// MaxStderrCapture caps how much provider stderr one run keeps in the
+// message log.
+const MaxStderrCapture = 64 << 10
+
+// stderrCapture counts the stderr a session's current run has logged.
+type stderrCapture struct {
+	mu        sync.Mutex
+	run       *session.Run
+	bytes     int
+	truncated bool
+}
+
+// take returns the part of text that fits the run's budget, and a note to
+// log once the budget is spent.
+func (c *stderrCapture) take(run *session.Run, text string) (kept, note string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.run != run {
+		c.run, c.bytes, c.truncated = run, 0, false
+	}
+	if c.truncated {
+		return "", ""
+	}
+	if room := MaxStderrCapture - c.bytes; len(text) > room {
+		text = strings.ToValidUTF8(text[:room], "")
+		c.truncated = true
+		note = fmt.Sprintf("[stderr truncated: this run wrote more than %d KiB]\n", MaxStderrCapture>>10)
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			note = "\n" + note
+		}
+	}
+	c.bytes += len(text)
+	return text, note
+}
+
+// captureStderr adds provider stderr to the session's message log as a
+// diagnostic message. Consecutive stderr extends the same message; text
+// keeps the line endings the provider read.
+func (e *AgentExecutor) captureStderr(sc *sessionContext, text string, at time.Time) {
+	kept, note := sc.stderr.take(sc.getRun(), text)
+	if kept += note; kept == "" {
+		return
+	}
+	sc.session.AppendDelta(domain.MessageKindDiagnostic, kept)
+	e.appendToMessageLog(sc.session.ID, storage.MessageProjectionDelta, domain.MessageKindDiagnostic, kept, nil, at)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_StderrCapture(t *testing.T) {
+	prov := newMockProvider()
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.StartSession(context.Background(), "stderr", session.Config{ProviderType: "test", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("start session: %v", err)
+	}
+	sess, err := executor.SendMessage(context.Background(), "stderr", "hi", "", "")
+	if err != nil {
+		t.Fatalf("send message: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	diagnostics := func() []domain.Message {
+		var out []domain.Message
+		for _, m := range sess.Snapshot().Messages {
+			if m.Kind == domain.MessageKindDiagnostic {
+				out = append(out, m)
+			}
+		}
+		return out
+	}
+
+	prov.SendEvent(domain.NewStderrEvent("stderr", "warning: one\n"))
+	prov.SendEvent(domain.NewStderrEvent("stderr", "warning: two\n"))
+	waitFor(t, func() bool {
+		d := diagnostics()
+		return len(d) == 1 && d[0].Contents == "warning: one\nwarning: two\n"
+	})
+
+	prov.SendEvent(domain.NewStderrEvent("stderr", strings.Repeat("x", MaxStderrCapture)))
+	prov.SendEvent(domain.NewStderrEvent("stderr", "dropped\n"))
+	prov.SendEvent(domain.NewOutputEvent("stderr", "done", nil))
+	waitFor(t, func() bool {
+		msgs := sess.Snapshot().Messages
+		return len(msgs) > 0 && msgs[len(msgs)-1].Kind == domain.MessageKindOutput
+	})
+
+	d := diagnostics()
+	if len(d) != 1 {
+		t.Fatalf("expected one diagnostic message, got %d", len(d))
+	}
+	if !strings.HasSuffix(d[0].Contents, "\n[stderr truncated: this run wrote more than 64 KiB]\n") || strings.Contains(d[0].Contents, "dropped") {
+		t.Fatalf("stderr was not truncated: ...%q", d[0].Contents[len(d[0].Contents)-80:])
+	}
+	if n := len(d[0].Contents); n > MaxStderrCapture+100 {
+		t.Fatalf("captured %d bytes, want at most about %d", n, MaxStderrCapture)
+	}
+}
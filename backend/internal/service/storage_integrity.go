@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// ErrIntegrityUnsupported is returned when the session storage cannot check
+// its own integrity.
+var ErrIntegrityUnsupported = errors.New("session storage does not support integrity checks")
+
+// IntegrityReport is the result of a storage integrity check.
+type IntegrityReport struct {
+	CheckedAt time.Time                `json:"checked_at"`
+	Repair    bool                     `json:"repair"`
+	Issues    []storage.IntegrityIssue `json:"issues"`
+}
+
+// CheckStorageIntegrity validates the session storage's invariants and, with
+// repair set, applies the safe repairs. Sessions with a live run are never
+// repaired. It shares the cleanup lock so it never races a janitor pass.
+func (e *AgentExecutor) CheckStorageIntegrity(repair bool) (*IntegrityReport, error) {
+	checker, ok := e.storage.(storage.IntegrityStorage)
+	if !ok {
+		return nil, ErrIntegrityUnsupported
+	}
+
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+
+	// Collected up front: the check holds the storage lock, which must not
+	// be held while taking e.mu.
+	live := make(map[string]bool)
+	e.mu.RLock()
+	for id, sc := range e.sessions {
+		if sc != nil && sc.getRun() != nil {
+			live[id] = true
+		}
+	}
+	e.mu.RUnlock()
+
+	report := &IntegrityReport{CheckedAt: time.Now().UTC(), Repair: repair}
+	issues, err := checker.CheckIntegrity(repair, func(id string) bool { return live[id] })
+	if err != nil {
+		return nil, err
+	}
+	report.Issues = issues
+	return report, nil
+}
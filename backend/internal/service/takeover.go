@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// ErrTakenOver is returned for input to a taken-over session from anyone
+// but the human who took it over.
+var ErrTakenOver = errors.New("session is taken over")
+
+// StartTakeover puts a session in manual mode for actor. Until the session
+// is handed back, messages and input from anyone else, including schedules,
+// watch mode and recovery, are refused with ErrTakenOver. A live run that
+// exposes a terminal keeps running for the human to drive; any other run is
+// cancelled so the agent stops acting on its own.
+func (e *AgentExecutor) StartTakeover(ctx context.Context, id, actor, reason string) (*domain.Takeover, error) {
+	if e.readOnlyMirror {
+		return nil, ErrReadOnlyMirror
+	}
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return nil, err
+	}
+	sess := sc.session
+
+	now := time.Now().UTC()
+	takeover := domain.Takeover{
+		By:           actor,
+		Reason:       strings.TrimSpace(reason),
+		StartedAt:    now,
+		StartMessage: len(sess.Snapshot().Messages),
+	}
+	if !sess.StartTakeover(takeover) {
+		return nil, fmt.Errorf("%w: session is already taken over", ErrInvalidState)
+	}
+	e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeTakeoverStarted, "by", actor, "reason", takeover.Reason), now)
+
+	if sess.GetState() != domain.SessionStateIdle && !runHasTerminal(sc.getRun()) {
+		if err := e.CancelRun(ctx, id); err != nil && !errors.Is(err, ErrInvalidState) {
+			log.Printf("takeover of session %s: failed to cancel the agent's run: %v", id, err)
+		}
+	}
+	if e.storage != nil {
+		_ = e.saveSession(sess)
+	}
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    "session_takeover",
+		SessionID: id,
+		Reason:    takeover.Reason,
+	})
+	return &takeover, nil
+}
+
+// HandBack ends a session's takeover and briefs the agent on what happened
+// during it. An idle session starts a new run from the briefing; a run the
+// human kept driving gets it as a system note, or as input when its
+// provider takes no notes. The takeover is ended even if the briefing
+// cannot be given; BriefingError then says why.
+func (e *AgentExecutor) HandBack(ctx context.Context, id, actor, note string) (*domain.Takeover, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return nil, err
+	}
+	sess := sc.session
+	if sess.ActiveTakeover() == nil {
+		return nil, fmt.Errorf("%w: session is not taken over", ErrInvalidState)
+	}
+	if state := sess.GetState(); state == domain.SessionStateSuspended {
+		return nil, fmt.Errorf("%w: the session is waiting on a response; answer it before handing back", ErrInvalidState)
+	}
+
+	now := time.Now().UTC()
+	takeover, ok := sess.EndTakeover(actor, strings.TrimSpace(note), now)
+	if !ok {
+		return nil, fmt.Errorf("%w: session is not taken over", ErrInvalidState)
+	}
+	takeover.Briefing = domain.TakeoverBriefing(takeover, sess.Snapshot().Messages)
+	e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeTakeoverHandedBack, "by", actor), now)
+	if err := e.briefAfterTakeover(ctx, sc, takeover.Briefing); err != nil {
+		log.Printf("hand-back of session %s: failed to brief the agent: %v", id, err)
+		takeover.BriefingError = err.Error()
+	}
+	sess.UpdateTakeover(takeover)
+	if e.storage != nil {
+		_ = e.saveSession(sess)
+	}
+	e.recordAudit(storage.AuditEntry{
+		Actor:     actor,
+		Action:    "session_hand_back",
+		SessionID: id,
+		Reason:    takeover.Note,
+	})
+	return &takeover, nil
+}
+
+// briefAfterTakeover gives the agent the hand-back briefing.
+func (e *AgentExecutor) briefAfterTakeover(ctx context.Context, sc *sessionContext, briefing string) error {
+	run := sc.getRun()
+	if run == nil || sc.session.GetState() == domain.SessionStateIdle {
+		_, err := e.sendMessage(ctx, sc.session.ID, briefing, "", "", SendMessageOptions{})
+		return err
+	}
+	if noter, ok := run.Session.(session.SystemNoter); ok {
+		return noter.InjectSystemNote(ctx, briefing)
+	}
+	cfg := session.Config{
+		ProviderType: sc.session.ProviderType,
+		WorkingDir:   sc.session.WorkingDir,
+		ProjectID:    sc.session.ProjectID,
+	}
+	_, err := run.Session.SendInput(ctx, cfg, briefing)
+	return err
+}
+
+// checkTakeover refuses input from anyone but the human who took the
+// session over.
+func checkTakeover(sess *domain.Session, actor string) error {
+	if t := sess.ActiveTakeover(); t != nil && actor != t.By {
+		return fmt.Errorf("%w by %s", ErrTakenOver, t.By)
+	}
+	return nil
+}
+
+func runHasTerminal(run *session.Run) bool {
+	if run == nil {
+		return false
+	}
+	_, ok := run.Session.(TerminalProvider)
+	return ok
+}
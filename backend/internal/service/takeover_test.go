@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_Takeover_SuspendsAgentAndBriefsOnHandBack(t *testing.T) {
+	executor, _, provs := newRecordingExecutor(t, ExecutorConfig{}, newInputProvider)
+
+	if _, err := executor.CreateSession(context.Background(), "manual", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.StartTakeover(context.Background(), "manual", "alice", "the agent is stuck on the build"); err != nil {
+		t.Fatalf("StartTakeover: %v", err)
+	}
+	if _, err := executor.StartTakeover(context.Background(), "manual", "bob", ""); !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("second StartTakeover error = %v, want ErrInvalidState", err)
+	}
+
+	if _, err := executor.SendMessage(context.Background(), "manual", "scheduled prompt", "", ""); !errors.Is(err, ErrTakenOver) {
+		t.Fatalf("automated SendMessage error = %v, want ErrTakenOver", err)
+	}
+	if _, err := executor.SendMessageWithOptions(context.Background(), "manual", "fix the makefile", "", "", SendMessageOptions{Actor: "alice"}); err != nil {
+		t.Fatalf("SendMessage as taker: %v", err)
+	}
+	waitFor(t, func() bool { return len(provs()) == 1 })
+	first := provs()[0]
+	first.SendEvent(domain.NewOutputEvent("manual", "Fixed the missing tab in the makefile.", nil))
+	_ = first.Kill()
+	waitFor(t, func() bool {
+		sc, err := executor.ensureSessionContext("manual")
+		return err == nil && sc.getRun() == nil
+	})
+
+	takeover, err := executor.HandBack(context.Background(), "manual", "alice", "the build passes now")
+	if err != nil {
+		t.Fatalf("HandBack: %v", err)
+	}
+	if takeover.Active() || takeover.BriefingError != "" {
+		t.Fatalf("takeover = %+v, want ended and briefed", takeover)
+	}
+	var input string
+	waitFor(t, func() bool {
+		if len(provs()) < 2 {
+			return false
+		}
+		second := provs()[1]
+		second.mu.Lock()
+		defer second.mu.Unlock()
+		input = second.input
+		return input != ""
+	})
+	for _, want := range []string{"A human (alice) took over", "the agent is stuck on the build", "- alice: fix the makefile", "- agent: Fixed the missing tab", "the build passes now"} {
+		if !strings.Contains(input, want) {
+			t.Errorf("briefing missing %q:\n%s", want, input)
+		}
+	}
+	if _, err := executor.HandBack(context.Background(), "manual", "alice", ""); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("second HandBack error = %v, want ErrInvalidState", err)
+	}
+
+	tl, err := executor.SessionTimeline("manual")
+	if err != nil {
+		t.Fatalf("SessionTimeline: %v", err)
+	}
+	if len(tl.Takeovers) != 1 || tl.Takeovers[0].Label != "alice" || tl.Takeovers[0].End == nil {
+		t.Errorf("timeline takeovers = %+v, want one closed span by alice", tl.Takeovers)
+	}
+}
+
+func TestAgentExecutor_Takeover_CancelsAgentRun(t *testing.T) {
+	executor, store, provs := newRecordingExecutor(t, ExecutorConfig{}, newInputProvider)
+
+	if _, err := executor.CreateSession(context.Background(), "busy", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "busy", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitFor(t, func() bool {
+		sess, _ := executor.GetSession("busy")
+		return len(provs()) == 1 && sess.GetState() == domain.SessionStateRunning
+	})
+
+	if _, err := executor.StartTakeover(context.Background(), "busy", "alice", ""); err != nil {
+		t.Fatalf("StartTakeover: %v", err)
+	}
+	sess, _ := executor.GetSession("busy")
+	if state := sess.GetState(); state != domain.SessionStateIdle {
+		t.Fatalf("state after takeover = %v, want idle", state)
+	}
+	if attempt := waitForRunAttempt(t, store, "busy", true); attempt.TerminalReason != "cancelled" {
+		t.Errorf("terminal reason = %q, want cancelled", attempt.TerminalReason)
+	}
+	if err := executor.SendInput(context.Background(), "busy", "y\n", "", "", "bob"); !errors.Is(err, ErrTakenOver) {
+		t.Errorf("SendInput from bob error = %v, want ErrTakenOver", err)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// RecentTaskComments is how many of a task's latest comments are injected
+// into a run's context.
+const RecentTaskComments = 5
+
+// TaskComment is one comment on a task.
+type TaskComment struct {
+	Author    string    `json:"author,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+}
+
+// TaskDetails is the definition of a task a session works on.
+type TaskDetails struct {
+	ID                 string        `json:"id"`
+	Title              string        `json:"title,omitempty"`
+	Body               string        `json:"body,omitempty"`
+	AcceptanceCriteria []string      `json:"acceptance_criteria,omitempty"`
+	Comments           []TaskComment `json:"comments,omitempty"`
+}
+
+// TaskSource looks up the current definition of a task.
+type TaskSource interface {
+	Task(ctx context.Context, workingDir, taskID string) (TaskDetails, error)
+}
+
+// StrandTaskSource reads tasks with the strand CLI. ProjectDir, when set, is
+// passed as --project; otherwise strand runs in the session's working
+// directory.
+type StrandTaskSource struct {
+	ProjectDir string
+}
+
+// Task runs `strand show --format json`. Output that is not JSON is taken
+// as the task body.
+func (s StrandTaskSource) Task(ctx context.Context, workingDir, taskID string) (TaskDetails, error) {
+	args := []string{"show", "--format", "json", taskID}
+	if s.ProjectDir != "" {
+		args = append([]string{"--project", s.ProjectDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "strand", args...)
+	if s.ProjectDir == "" {
+		cmd.Dir = workingDir
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return TaskDetails{}, fmt.Errorf("strand show %s: %w", taskID, err)
+	}
+	var task TaskDetails
+	if err := json.Unmarshal(out, &task); err != nil {
+		return TaskDetails{ID: taskID, Body: strings.TrimSpace(string(out))}, nil
+	}
+	if task.ID == "" {
+		task.ID = taskID
+	}
+	return task, nil
+}
+
+// formatTaskContext renders a task as a system prompt section.
+func formatTaskContext(task TaskDetails) string {
+	var b strings.Builder
+	b.WriteString("# Current task\n\n")
+	if task.Title != "" {
+		fmt.Fprintf(&b, "%s: %s\n", task.ID, task.Title)
+	} else {
+		fmt.Fprintf(&b, "%s\n", task.ID)
+	}
+	if body := strings.TrimSpace(task.Body); body != "" {
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	if len(task.AcceptanceCriteria) > 0 {
+		b.WriteString("\n## Acceptance criteria\n\n")
+		for _, c := range task.AcceptanceCriteria {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	comments := task.Comments
+	if len(comments) > RecentTaskComments {
+		comments = comments[len(comments)-RecentTaskComments:]
+	}
+	if len(comments) > 0 {
+		b.WriteString("\n## Recent comments\n\n")
+		for _, c := range comments {
+			author := c.Author
+			if author == "" {
+				author = "unknown"
+			}
+			if c.CreatedAt.IsZero() {
+				fmt.Fprintf(&b, "- %s: %s\n", author, strings.TrimSpace(c.Body))
+			} else {
+				fmt.Fprintf(&b, "- %s (%s): %s\n", author, c.CreatedAt.UTC().Format(time.RFC3339), strings.TrimSpace(c.Body))
+			}
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// taskContext returns the task section to add to the system prompt of the
+// session's next run: on its first run, and again on resume so the agent
+// sees the latest definition. It is empty when the session has no task or
+// the task can't be read; the agent can still fetch it itself.
+func (e *AgentExecutor) taskContext(ctx context.Context, sess *domain.Session, resume bool) string {
+	if e.tasks == nil {
+		return ""
+	}
+	snap := sess.Snapshot()
+	if snap.TaskID == "" {
+		return ""
+	}
+	if !resume {
+		for _, m := range snap.Messages {
+			if m.Kind == domain.MessageKindUser {
+				return ""
+			}
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.opTimeout)
+	defer cancel()
+	task, err := e.tasks.Task(ctx, snap.WorkingDir, snap.TaskID)
+	if err != nil {
+		log.Printf("session %s: task context for %s: %v", snap.ID, snap.TaskID, err)
+		return ""
+	}
+	return formatTaskContext(task)
+}
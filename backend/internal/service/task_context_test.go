@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+type fakeTaskSource struct {
+	mu    sync.Mutex
+	task  TaskDetails
+	calls int
+}
+
+func (f *fakeTaskSource) Task(ctx context.Context, workingDir, taskID string) (TaskDetails, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	return f.task, nil
+}
+
+func TestFormatTaskContext(t *testing.T) {
+	task := TaskDetails{
+		ID:                 "t1",
+		Title:              "Fix login",
+		Body:               "Users can't log in.\n",
+		AcceptanceCriteria: []string{"login works", "test added"},
+	}
+	for i := range RecentTaskComments + 1 {
+		task.Comments = append(task.Comments, TaskComment{Author: "ana", Body: string(rune('a' + i))})
+	}
+	got := formatTaskContext(task)
+	want := "# Current task\n\nt1: Fix login\n\nUsers can't log in.\n\n" +
+		"## Acceptance criteria\n\n- login works\n- test added\n\n" +
+		"## Recent comments\n\n- ana: b\n- ana: c\n- ana: d\n- ana: e\n- ana: f"
+	if got != want {
+		t.Fatalf("context = %q, want %q", got, want)
+	}
+}
+
+func TestAgentExecutor_TaskContext(t *testing.T) {
+	prov := newMockProvider()
+	tasks := &fakeTaskSource{task: TaskDetails{ID: "t1", Title: "Fix login", Body: "Users can't log in."}}
+	var mu sync.Mutex
+	var systemPrompts []string
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			systemPrompts = append(systemPrompts, config.SystemPrompt)
+			mu.Unlock()
+			return prov, nil
+		},
+		OperationTimeout: 5 * time.Second,
+		TaskSource:       tasks,
+	})
+	defer executor.Shutdown(context.Background())
+
+	config := session.Config{ProviderType: "mock", WorkingDir: "/tmp/test", SystemPrompt: "Be terse.", TaskID: "t1"}
+	sess, err := executor.CreateSession(context.Background(), "s", config)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if sess.Snapshot().TaskID != "t1" {
+		t.Fatalf("task id = %q, want t1", sess.Snapshot().TaskID)
+	}
+	if _, err := executor.SendMessage(context.Background(), "s", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	mu.Lock()
+	if len(systemPrompts) != 1 || !strings.HasPrefix(systemPrompts[0], "Be terse.\n\n# Current task\n\nt1: Fix login") {
+		t.Fatalf("provider system prompts = %q", systemPrompts)
+	}
+	mu.Unlock()
+
+	// Later runs carry the conversation already; only a resume refreshes
+	// the task.
+	if got := executor.taskContext(context.Background(), sess, false); got != "" {
+		t.Fatalf("context after first run = %q, want none", got)
+	}
+	tasks.mu.Lock()
+	tasks.task.Body = "Users can't log in on mobile."
+	tasks.mu.Unlock()
+	if got := executor.taskContext(context.Background(), sess, true); !strings.Contains(got, "on mobile") {
+		t.Fatalf("context on resume = %q, want the latest body", got)
+	}
+
+	plain := domain.NewSession("plain", "mock", "/tmp/test")
+	if got := executor.taskContext(context.Background(), plain, true); got != "" {
+		t.Fatalf("context without a task = %q, want none", got)
+	}
+}
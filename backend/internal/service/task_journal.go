@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+var (
+	ErrInvalidTaskChange     = errors.New("invalid task change")
+	ErrNoTaskChange          = errors.New("no task change to undo")
+	ErrTaskChangeNotUndoable = errors.New("task change cannot be undone")
+)
+
+// TaskAgent names a session's agent as the one who undid a task change.
+const TaskAgent = "agent"
+
+// TaskCommandRunner is implemented by task sources that can also change
+// tasks, which undoing a task change needs.
+type TaskCommandRunner interface {
+	// RunTaskCommand runs a task command in the project at projectDir, or
+	// in workingDir when projectDir is empty, and returns its output.
+	RunTaskCommand(ctx context.Context, workingDir, projectDir string, args ...string) (string, error)
+}
+
+// RunTaskCommand runs `strand args...`. projectDir, or else the source's
+// ProjectDir, is passed as --project.
+func (s StrandTaskSource) RunTaskCommand(ctx context.Context, workingDir, projectDir string, args ...string) (string, error) {
+	if projectDir == "" {
+		projectDir = s.ProjectDir
+	}
+	if projectDir != "" {
+		args = append([]string{"--project", projectDir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "strand", args...)
+	if projectDir == "" {
+		cmd.Dir = workingDir
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("strand %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// taskJournal keeps the task changes made through the strand MCP tools,
+// oldest first.
+type taskJournal struct {
+	mu      sync.Mutex
+	store   *storage.TaskJournalStorage
+	changes []domain.TaskChange
+}
+
+func newTaskJournal(store *storage.TaskJournalStorage) *taskJournal {
+	j := &taskJournal{store: store}
+	if store != nil {
+		if loaded, err := store.Load(); err != nil {
+			log.Printf("task journal: %v", err)
+		} else {
+			j.changes = loaded
+		}
+	}
+	return j
+}
+
+// save persists the journal. The caller holds j.mu.
+func (j *taskJournal) save() {
+	if j.store == nil {
+		return
+	}
+	if err := j.store.Save(j.changes); err != nil {
+		log.Printf("task journal: %v", err)
+	}
+}
+
+// RecordTaskChange adds a task change to the journal.
+func (e *AgentExecutor) RecordTaskChange(change domain.TaskChange) (domain.TaskChange, error) {
+	change.TaskID = strings.TrimSpace(change.TaskID)
+	if change.TaskID == "" {
+		return domain.TaskChange{}, fmt.Errorf("%w: task_id is required", ErrInvalidTaskChange)
+	}
+	switch change.Op {
+	case domain.TaskChangeAdd, domain.TaskChangeClaim, domain.TaskChangeComplete, domain.TaskChangeEdit:
+	default:
+		return domain.TaskChange{}, fmt.Errorf("%w: unknown op %q", ErrInvalidTaskChange, change.Op)
+	}
+	change.ID = newAttemptID()
+	change.At = time.Now().UTC()
+	change.UndoneAt, change.UndoneBy = nil, ""
+
+	j := e.taskJournal
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.changes = append(j.changes, change)
+	if over := len(j.changes) - domain.MaxTaskChanges; over > 0 {
+		j.changes = append([]domain.TaskChange(nil), j.changes[over:]...)
+	}
+	j.save()
+	return change, nil
+}
+
+// TaskChanges returns the journaled changes of a task, or of all tasks when
+// taskID is empty, limited to one session's when sessionID is set. They are
+// oldest first.
+func (e *AgentExecutor) TaskChanges(taskID, sessionID string) []domain.TaskChange {
+	j := e.taskJournal
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := []domain.TaskChange{}
+	for _, c := range j.changes {
+		if (taskID == "" || c.TaskID == taskID) && (sessionID == "" || c.SessionID == sessionID) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// UndoLastTaskChange reverts the latest change not yet undone, of the
+// session when sessionID is set, and marks it undone by actor. Calling it
+// again walks further back through the journal. Changes that cannot be
+// reverted, such as a completed todo, are passed over.
+func (e *AgentExecutor) UndoLastTaskChange(ctx context.Context, sessionID, actor string) (domain.TaskChange, error) {
+	runner, ok := e.tasks.(TaskCommandRunner)
+	if !ok {
+		return domain.TaskChange{}, fmt.Errorf("%w: no task source can change tasks", ErrTaskChangeNotUndoable)
+	}
+
+	// Undoes are serialized so two callers cannot revert the same change.
+	j := e.taskJournal
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	idx := -1
+	var args []string
+	for i := len(j.changes) - 1; i >= 0 && idx < 0; i-- {
+		c := j.changes[i]
+		if c.Undone() || (sessionID != "" && c.SessionID != sessionID) {
+			continue
+		}
+		var err error
+		if args, err = taskUndoArgs(c); err == nil {
+			idx = i
+		} else if !errors.Is(err, ErrTaskChangeNotUndoable) {
+			return c, err
+		}
+	}
+	if idx < 0 {
+		return domain.TaskChange{}, ErrNoTaskChange
+	}
+	change := j.changes[idx]
+	if _, err := runner.RunTaskCommand(ctx, change.WorkingDir, change.ProjectDir, args...); err != nil {
+		return change, fmt.Errorf("undo %s of %s: %w", change.Op, change.TaskID, err)
+	}
+
+	now := time.Now().UTC()
+	change.UndoneAt, change.UndoneBy = &now, actor
+	j.changes[idx] = change
+	j.save()
+	return change, nil
+}
+
+// taskUndoArgs returns the strand command that reverts a change: a created
+// task is cancelled, a claimed or completed one gets back the status it had
+// before, and an edited one the fields it had before.
+func taskUndoArgs(change domain.TaskChange) ([]string, error) {
+	var before struct {
+		Status   string `json:"status"`
+		Title    string `json:"title"`
+		Priority string `json:"priority"`
+		Role     string `json:"role"`
+	}
+	parsed := json.Unmarshal([]byte(change.Before), &before) == nil
+	switch change.Op {
+	case domain.TaskChangeAdd:
+		return []string{"cancel", change.TaskID, "Undone: created by mistake"}, nil
+	case domain.TaskChangeComplete:
+		if change.Todo > 0 {
+			return nil, fmt.Errorf("%w: completing todo %d of %s cannot be reverted", ErrTaskChangeNotUndoable, change.Todo, change.TaskID)
+		}
+	case domain.TaskChangeEdit:
+		args := []string{"edit", change.TaskID}
+		for _, f := range []struct{ flag, value string }{
+			{"--title", before.Title},
+			{"--priority", before.Priority},
+			{"--role", before.Role},
+			{"--status", before.Status},
+		} {
+			if f.value != "" {
+				args = append(args, f.flag, f.value)
+			}
+		}
+		if !parsed || len(args) == 2 {
+			return nil, fmt.Errorf("%w: the task before editing %s was not recorded", ErrTaskChangeNotUndoable, change.TaskID)
+		}
+		return args, nil
+	}
+	status := "open"
+	if parsed && before.Status != "" {
+		status = before.Status
+	}
+	return []string{"edit", change.TaskID, "--status", status}, nil
+}
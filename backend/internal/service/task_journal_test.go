@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// recordingTaskSource is a task source that records the task commands run.
+type recordingTaskSource struct {
+	fakeTaskSource
+	commands []string
+}
+
+func (r *recordingTaskSource) RunTaskCommand(ctx context.Context, workingDir, projectDir string, args ...string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, projectDir+": "+strings.Join(args, " "))
+	return "", nil
+}
+
+func TestAgentExecutor_UndoTaskChanges(t *testing.T) {
+	dir := t.TempDir()
+	tasks := &recordingTaskSource{}
+	executor := NewAgentExecutor(ExecutorConfig{
+		Broadcaster: NewEventBroadcaster(10),
+		TaskSource:  tasks,
+		TaskJournal: storage.NewTaskJournalStorage(dir),
+	})
+	defer executor.Shutdown(context.Background())
+
+	record := func(change domain.TaskChange) {
+		t.Helper()
+		change.ProjectDir = "/proj"
+		if _, err := executor.RecordTaskChange(change); err != nil {
+			t.Fatalf("RecordTaskChange: %v", err)
+		}
+	}
+	record(domain.TaskChange{TaskID: "T1", Op: domain.TaskChangeAdd, SessionID: "s1"})
+	record(domain.TaskChange{TaskID: "T2", Op: domain.TaskChangeClaim, SessionID: "s1", Before: `{"id":"T2","status":"blocked"}`})
+	record(domain.TaskChange{TaskID: "T3", Op: domain.TaskChangeComplete, SessionID: "s2"})
+	record(domain.TaskChange{TaskID: "T2", Op: domain.TaskChangeComplete, SessionID: "s1", Before: "not json"})
+	if _, err := executor.RecordTaskChange(domain.TaskChange{TaskID: "T1", Op: "delete"}); !errors.Is(err, ErrInvalidTaskChange) {
+		t.Fatalf("expected an unknown op to be rejected, got %v", err)
+	}
+
+	for range 3 {
+		if _, err := executor.UndoLastTaskChange(context.Background(), "s1", "ana"); err != nil {
+			t.Fatalf("UndoLastTaskChange: %v", err)
+		}
+	}
+	want := []string{"/proj: edit T2 --status open", "/proj: edit T2 --status blocked", "/proj: cancel T1 Undone: created by mistake"}
+	if !slices.Equal(tasks.commands, want) {
+		t.Fatalf("commands = %q, want %q", tasks.commands, want)
+	}
+	if _, err := executor.UndoLastTaskChange(context.Background(), "s1", "ana"); !errors.Is(err, ErrNoTaskChange) {
+		t.Fatalf("expected nothing left to undo for s1, got %v", err)
+	}
+
+	// The journal survives a restart, undone changes included.
+	reloaded := NewAgentExecutor(ExecutorConfig{Broadcaster: NewEventBroadcaster(10), TaskJournal: storage.NewTaskJournalStorage(dir)})
+	defer reloaded.Shutdown(context.Background())
+	history := reloaded.TaskChanges("T2", "")
+	if len(history) != 2 || !history[0].Undone() || history[1].UndoneBy != "ana" {
+		t.Fatalf("history = %+v", history)
+	}
+	if got := reloaded.TaskChanges("", "s2"); len(got) != 1 || got[0].Undone() {
+		t.Fatalf("s2 changes = %+v", got)
+	}
+	if _, err := reloaded.UndoLastTaskChange(context.Background(), "", "ana"); !errors.Is(err, ErrTaskChangeNotUndoable) {
+		t.Fatalf("expected undo to need a task command runner, got %v", err)
+	}
+
+	// Changes that cannot be reverted are passed over rather than blocking
+	// the ones before them.
+	tasks.commands = nil
+	record(domain.TaskChange{TaskID: "T4", Op: domain.TaskChangeEdit, SessionID: "s3", Before: `{"id":"T4","title":"Old title","priority":"low","status":"open"}`})
+	record(domain.TaskChange{TaskID: "T4", Op: domain.TaskChangeComplete, Todo: 2, SessionID: "s3"})
+	record(domain.TaskChange{TaskID: "T4", Op: domain.TaskChangeEdit, SessionID: "s3"})
+	if _, err := executor.UndoLastTaskChange(context.Background(), "s3", "ana"); err != nil {
+		t.Fatalf("UndoLastTaskChange: %v", err)
+	}
+	if want := []string{"/proj: edit T4 --title Old title --priority low --status open"}; !slices.Equal(tasks.commands, want) {
+		t.Fatalf("commands = %q, want %q", tasks.commands, want)
+	}
+	if _, err := executor.UndoLastTaskChange(context.Background(), "s3", "ana"); !errors.Is(err, ErrNoTaskChange) {
+		t.Fatalf("expected only changes that cannot be reverted left, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// ErrTemplatesDisabled is returned for template operations when no template
+// storage is configured.
+var ErrTemplatesDisabled = errors.New("templates are not configured")
+
+// ErrInvalidTemplate wraps a template that fails validation or cannot be
+// rendered.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// Templates returns every prompt template, in the order they were created.
+func (e *AgentExecutor) Templates() ([]domain.PromptTemplate, error) {
+	if e.templates == nil {
+		return nil, ErrTemplatesDisabled
+	}
+	return e.templates.List()
+}
+
+// Template returns one prompt template.
+func (e *AgentExecutor) Template(id string) (*domain.PromptTemplate, error) {
+	if e.templates == nil {
+		return nil, ErrTemplatesDisabled
+	}
+	return e.templates.Get(id)
+}
+
+// CreateTemplate stores a new prompt template.
+func (e *AgentExecutor) CreateTemplate(t domain.PromptTemplate) (*domain.PromptTemplate, error) {
+	if e.templates == nil {
+		return nil, ErrTemplatesDisabled
+	}
+	if err := e.validateTemplate(&t); err != nil {
+		return nil, err
+	}
+	e.templateMu.Lock()
+	defer e.templateMu.Unlock()
+
+	now := time.Now().UTC()
+	t.ID = newSessionID()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	if err := e.templates.Save(t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UpdateTemplate replaces a prompt template.
+func (e *AgentExecutor) UpdateTemplate(id string, t domain.PromptTemplate) (*domain.PromptTemplate, error) {
+	if e.templates == nil {
+		return nil, ErrTemplatesDisabled
+	}
+	if err := e.validateTemplate(&t); err != nil {
+		return nil, err
+	}
+	e.templateMu.Lock()
+	defer e.templateMu.Unlock()
+
+	existing, err := e.templates.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	t.ID = existing.ID
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now().UTC()
+	if err := e.templates.Save(t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTemplate removes a prompt template. Sessions created from it keep
+// what it rendered.
+func (e *AgentExecutor) DeleteTemplate(id string) error {
+	if e.templates == nil {
+		return ErrTemplatesDisabled
+	}
+	e.templateMu.Lock()
+	defer e.templateMu.Unlock()
+	return e.templates.Delete(id)
+}
+
+func (e *AgentExecutor) validateTemplate(t *domain.PromptTemplate) error {
+	if err := t.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	if t.ProviderType != "" {
+		if _, err := e.ProviderCapabilities(t.ProviderType); err != nil {
+			return fmt.Errorf("%w: unknown provider type %q", ErrInvalidTemplate, t.ProviderType)
+		}
+	}
+	return nil
+}
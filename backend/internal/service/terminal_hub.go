@@ -3,13 +3,20 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ricochet1k/orbitmesh/internal/terminal"
 )
 
-var ErrTerminalNotSupported = errors.New("terminal not supported")
+var (
+	ErrTerminalNotSupported = errors.New("terminal not supported")
+	ErrTerminalReadOnly     = errors.New("terminal viewer is read-only")
+)
 
 const (
 	terminalHubUpdateBuffer   = 128
@@ -22,9 +29,43 @@ type TerminalProvider interface {
 	HandleTerminalInput(ctx context.Context, input terminal.Input) error
 }
 
+// TerminalRole is what a viewer attached to a terminal may do.
+type TerminalRole string
+
+const (
+	TerminalRoleReadOnly  TerminalRole = "read_only"
+	TerminalRoleReadWrite TerminalRole = "read_write"
+)
+
+// TerminalViewer is a client attached to a terminal hub.
+type TerminalViewer struct {
+	ID       string
+	Name     string
+	Role     TerminalRole
+	JoinedAt time.Time
+}
+
+// TerminalPresence lists the viewers attached to a terminal.
+type TerminalPresence struct {
+	Viewers []TerminalViewer
+}
+
+// TerminalInputSource attributes terminal input to the viewer that sent it.
+type TerminalInputSource struct {
+	ViewerID string
+	Name     string
+	Kind     terminal.InputKind
+}
+
+// TerminalEvent is one sequenced event of a terminal hub. Presence and Input
+// events carry no Update.
 type TerminalEvent struct {
 	Seq    int64
 	Update terminal.Update
+	// Presence is set when a viewer attaches or detaches.
+	Presence *TerminalPresence
+	// Input is set when a viewer's input was sent to the terminal.
+	Input *TerminalInputSource
 }
 
 type TerminalObserver interface {
@@ -42,11 +83,12 @@ type TerminalHub struct {
 	provider  TerminalProvider
 	observer  TerminalObserver
 
-	mu     sync.Mutex
-	subs   map[int64]chan TerminalEvent
-	seq    int64
-	subSeq int64
-	closed bool
+	mu      sync.Mutex
+	subs    map[int64]chan TerminalEvent
+	viewers map[int64]TerminalViewer
+	seq     int64
+	subSeq  int64
+	closed  bool
 
 	lastSnapshot    terminal.Snapshot
 	lastSnapshotSet bool
@@ -60,6 +102,7 @@ func NewTerminalHub(sessionID string, provider TerminalProvider, observer Termin
 		provider:  provider,
 		observer:  observer,
 		subs:      make(map[int64]chan TerminalEvent),
+		viewers:   make(map[int64]TerminalViewer),
 	}
 	updates, cancel := provider.SubscribeTerminalUpdates(terminalHubUpdateBuffer)
 	h.updateCancel = cancel
@@ -117,6 +160,20 @@ func (h *TerminalHub) Close() {
 // subsequent events are dropped; clients should detect sequence gaps and fetch
 // a fresh snapshot from the REST endpoint to resync.
 func (h *TerminalHub) Subscribe(buffer int) (<-chan TerminalEvent, func()) {
+	ch, _, cancel := h.subscribe(nil, buffer)
+	return ch, cancel
+}
+
+// Attach subscribes like Subscribe as a viewer with the given name and
+// role, and returns the viewer with its assigned ID. Every subscriber gets
+// a presence event when a viewer attaches or detaches.
+func (h *TerminalHub) Attach(name string, role TerminalRole, buffer int) (TerminalViewer, <-chan TerminalEvent, func()) {
+	viewer := TerminalViewer{Name: name, Role: role}
+	ch, viewer, cancel := h.subscribe(&viewer, buffer)
+	return viewer, ch, cancel
+}
+
+func (h *TerminalHub) subscribe(viewer *TerminalViewer, buffer int) (<-chan TerminalEvent, TerminalViewer, func()) {
 	if buffer <= 0 {
 		buffer = terminalHubSubscriberBuff
 	}
@@ -127,34 +184,68 @@ func (h *TerminalHub) Subscribe(buffer int) (<-chan TerminalEvent, func()) {
 	if h.closed {
 		h.mu.Unlock()
 		close(ch)
-		return ch, func() {}
+		return ch, TerminalViewer{}, func() {}
 	}
 	h.subs[id] = ch
-	snap, snapErr := h.snapshotLocked()
+	// The snapshot is taken fresh, not from the cache diffs have moved on
+	// from, and carries the sequence number of the last event it includes
+	// so other subscribers see no gap.
+	snap, snapErr := h.provider.TerminalSnapshot()
+	if snapErr == nil {
+		h.lastSnapshot = snap
+		h.lastSnapshotSet = true
+	} else {
+		snap, snapErr = h.snapshotLocked()
+	}
 	var initialEvent *TerminalEvent
 	if snapErr == nil {
-		seq := h.nextSeqLocked()
-		ev := TerminalEvent{Seq: seq, Update: terminal.Update{Kind: terminal.UpdateSnapshot, Snapshot: &snap}}
+		ev := TerminalEvent{Seq: h.seq, Update: terminal.Update{Kind: terminal.UpdateSnapshot, Snapshot: &snap}}
 		initialEvent = &ev
 		select {
 		case ch <- ev:
 		default:
 		}
 	}
+	var attached TerminalViewer
+	var presence *TerminalEvent
+	if viewer != nil {
+		attached = *viewer
+		attached.ID = fmt.Sprintf("viewer-%d", id)
+		attached.JoinedAt = time.Now().UTC()
+		h.viewers[id] = attached
+		ev := h.presenceEventLocked()
+		presence = &ev
+	}
 	observer := h.observer
 	h.mu.Unlock()
 
 	if initialEvent != nil && observer != nil {
 		observer.OnTerminalEvent(h.sessionID, *initialEvent)
 	}
+	if presence != nil && observer != nil {
+		observer.OnTerminalEvent(h.sessionID, *presence)
+	}
 
-	return ch, func() {
+	return ch, attached, func() {
 		h.mu.Lock()
-		if existing, ok := h.subs[id]; ok {
-			delete(h.subs, id)
-			close(existing)
+		existing, ok := h.subs[id]
+		if !ok {
+			h.mu.Unlock()
+			return
+		}
+		delete(h.subs, id)
+		close(existing)
+		var presence *TerminalEvent
+		if _, isViewer := h.viewers[id]; isViewer && !h.closed {
+			delete(h.viewers, id)
+			ev := h.presenceEventLocked()
+			presence = &ev
 		}
+		observer := h.observer
 		h.mu.Unlock()
+		if presence != nil && observer != nil {
+			observer.OnTerminalEvent(h.sessionID, *presence)
+		}
 	}
 }
 
@@ -162,6 +253,55 @@ func (h *TerminalHub) HandleInput(ctx context.Context, input terminal.Input) err
 	return h.provider.HandleTerminalInput(ctx, input)
 }
 
+// HandleViewerInput sends input from an attached viewer, which must have
+// the read-write role, and tells every subscriber who sent it.
+func (h *TerminalHub) HandleViewerInput(ctx context.Context, viewer TerminalViewer, input terminal.Input) error {
+	if viewer.Role != TerminalRoleReadWrite {
+		return ErrTerminalReadOnly
+	}
+	if err := h.provider.HandleTerminalInput(ctx, input); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil
+	}
+	event := TerminalEvent{Input: &TerminalInputSource{ViewerID: viewer.ID, Name: viewer.Name, Kind: input.Kind}}
+	h.sendLocked(&event)
+	observer := h.observer
+	h.mu.Unlock()
+
+	if observer != nil {
+		observer.OnTerminalEvent(h.sessionID, event)
+	}
+	return nil
+}
+
+// Viewers returns the attached viewers in the order they attached.
+func (h *TerminalHub) Viewers() []TerminalViewer {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.viewersLocked()
+}
+
+func (h *TerminalHub) viewersLocked() []TerminalViewer {
+	ids := slices.Sorted(maps.Keys(h.viewers))
+	viewers := make([]TerminalViewer, 0, len(ids))
+	for _, id := range ids {
+		viewers = append(viewers, h.viewers[id])
+	}
+	return viewers
+}
+
+// presenceEventLocked sends the current viewers to every subscriber.
+func (h *TerminalHub) presenceEventLocked() TerminalEvent {
+	event := TerminalEvent{Presence: &TerminalPresence{Viewers: h.viewersLocked()}}
+	h.sendLocked(&event)
+	return event
+}
+
 func (h *TerminalHub) SubscriberCount() int {
 	h.mu.Lock()
 	defer h.mu.Unlock()
@@ -184,22 +324,27 @@ func (h *TerminalHub) broadcast(update terminal.Update) {
 		h.lastSnapshot = *update.Snapshot
 		h.lastSnapshotSet = true
 	}
-	seq := h.nextSeqLocked()
-	event := TerminalEvent{Seq: seq, Update: update}
+	event := TerminalEvent{Update: update}
+	h.sendLocked(&event)
+	observer := h.observer
+	h.mu.Unlock()
+
+	if observer != nil {
+		observer.OnTerminalEvent(h.sessionID, event)
+	}
+}
+
+// sendLocked numbers the event and fans it out to every subscriber.
+func (h *TerminalHub) sendLocked(event *TerminalEvent) {
+	event.Seq = h.nextSeqLocked()
 	for _, ch := range h.subs {
 		select {
-		case ch <- event:
+		case ch <- *event:
 		default:
 			// Subscriber is slow; drop the event. The client detects the
 			// sequence gap and fetches a fresh snapshot to resync.
 		}
 	}
-	observer := h.observer
-	h.mu.Unlock()
-
-	if observer != nil {
-		observer.OnTerminalEvent(h.sessionID, event)
-	}
 }
 
 func (h *TerminalHub) snapshotLocked() (terminal.Snapshot, error) {
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -218,3 +219,80 @@ func TestTerminalHub_MultipleSubscribers(t *testing.T) {
 		t.Fatalf("subscribers received different seq: %d vs %d", ev1.Seq, ev2.Seq)
 	}
 }
+
+// TestTerminalHub_LateViewerCausesNoGap verifies that a viewer attaching
+// mid-stream gets a fresh snapshot without breaking the sequence seen by
+// viewers already attached.
+func TestTerminalHub_LateViewerCausesNoGap(t *testing.T) {
+	provider := newMockTerminalProvider()
+	defer close(provider.updates)
+
+	hub := NewTerminalHub("session-1", provider, nil)
+	_, first, cancel1 := hub.Attach("ana", TerminalRoleReadWrite, 8)
+	defer cancel1()
+	snap := requireEventKind(t, first, terminal.UpdateSnapshot)
+	presence := requireTerminalEvent(t, first)
+	if presence.Presence == nil || presence.Seq != snap.Seq+1 {
+		t.Fatalf("expected presence at seq %d, got %+v", snap.Seq+1, presence)
+	}
+
+	sendDiff(provider, "x")
+	diff := requireEventKind(t, first, terminal.UpdateDiff)
+	provider.mu.Lock()
+	provider.snapshot = terminal.Snapshot{Rows: 1, Cols: 4, Lines: []string{"x"}}
+	provider.mu.Unlock()
+
+	_, second, cancel2 := hub.Attach("bo", TerminalRoleReadOnly, 8)
+	defer cancel2()
+	late := requireEventKind(t, second, terminal.UpdateSnapshot)
+	if late.Seq != diff.Seq || late.Update.Snapshot.Lines[0] != "x" {
+		t.Fatalf("late snapshot = seq %d %v, want seq %d with the diff applied", late.Seq, late.Update.Snapshot.Lines, diff.Seq)
+	}
+
+	joined := requireTerminalEvent(t, first)
+	if joined.Seq != diff.Seq+1 || joined.Presence == nil || len(joined.Presence.Viewers) != 2 {
+		t.Fatalf("expected presence of two viewers at seq %d, got %+v", diff.Seq+1, joined)
+	}
+	if got := requireTerminalEvent(t, second); got.Seq != joined.Seq {
+		t.Fatalf("late viewer presence seq = %d, want %d", got.Seq, joined.Seq)
+	}
+
+	cancel2()
+	left := requireTerminalEvent(t, first)
+	if left.Presence == nil || len(left.Presence.Viewers) != 1 || left.Presence.Viewers[0].Name != "ana" {
+		t.Fatalf("expected presence of ana alone, got %+v", left)
+	}
+}
+
+// TestTerminalHub_ViewerInput verifies that read-only viewers can't send
+// input and that input is attributed to its viewer.
+func TestTerminalHub_ViewerInput(t *testing.T) {
+	provider := newMockTerminalProvider()
+	defer close(provider.updates)
+
+	hub := NewTerminalHub("session-1", provider, nil)
+	writer, _, cancel1 := hub.Attach("ana", TerminalRoleReadWrite, 8)
+	defer cancel1()
+	reader, updates, cancel2 := hub.Attach("bo", TerminalRoleReadOnly, 8)
+	defer cancel2()
+	requireEventKind(t, updates, terminal.UpdateSnapshot)
+	requireTerminalEvent(t, updates) // presence
+
+	input := terminal.Input{Kind: terminal.InputText, Text: &terminal.TextInput{Text: "ls\n"}}
+	if err := hub.HandleViewerInput(context.Background(), reader, input); !errors.Is(err, ErrTerminalReadOnly) {
+		t.Fatalf("read-only input error = %v, want ErrTerminalReadOnly", err)
+	}
+	if err := hub.HandleViewerInput(context.Background(), writer, input); err != nil {
+		t.Fatalf("read-write input: %v", err)
+	}
+
+	ev := requireTerminalEvent(t, updates)
+	if ev.Input == nil || ev.Input.ViewerID != writer.ID || ev.Input.Name != "ana" || ev.Input.Kind != terminal.InputText {
+		t.Fatalf("expected input attributed to %s, got %+v", writer.ID, ev.Input)
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	if len(provider.inputs) != 1 {
+		t.Fatalf("provider got %d inputs, want 1", len(provider.inputs))
+	}
+}
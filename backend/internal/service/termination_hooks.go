@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/remote"
+)
+
+// DefaultCleanupHookTimeout bounds each termination hook command.
+const DefaultCleanupHookTimeout = 2 * time.Minute
+
+// maxHookOutput caps how much of a hook's output is kept in its message.
+const maxHookOutput = 8 << 10
+
+// Termination hook triggers, exposed to commands as ORBITMESH_CLEANUP_TRIGGER.
+const (
+	HookTriggerStop    = "stop"
+	HookTriggerKill    = "kill"
+	HookTriggerCleanup = "cleanup"
+)
+
+// startTerminationHooks runs the session's cleanup commands in the
+// background, so stopping or killing a session does not wait on them.
+func (e *AgentExecutor) startTerminationHooks(sc *sessionContext, trigger string) {
+	if len(sc.session.CleanupCommands) == 0 {
+		return
+	}
+	e.wg.Add(1)
+	e.hookWG.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer e.hookWG.Done()
+		e.runTerminationHooks(sc.session, trigger)
+		if e.storage != nil {
+			_ = e.saveSession(sc.session)
+		}
+	}()
+}
+
+// runTerminationHooks runs sess's cleanup commands one after another in its
+// working directory and records each command's result and output as a system
+// message. A failing command does not stop the rest. Hooks are not tied to
+// the executor context, so they still run while it shuts down.
+func (e *AgentExecutor) runTerminationHooks(sess *domain.Session, trigger string) {
+	for _, command := range sess.CleanupCommands {
+		ctx, cancel := context.WithTimeout(context.Background(), e.hookTimeout)
+		started := time.Now()
+		var out []byte
+		cmd, err := hookCommand(ctx, sess, command, trigger)
+		if err == nil {
+			out, err = cmd.CombinedOutput()
+		}
+		cancel()
+
+		output := strings.TrimSpace(string(out))
+		if len(output) > maxHookOutput {
+			output = "…" + output[len(output)-maxHookOutput:]
+		}
+		params := append([]string{
+			"command", command,
+			"trigger", trigger,
+			"duration", time.Since(started).Round(time.Millisecond).String(),
+			"output", output,
+		}, hookResult(ctx, err)...)
+		if err != nil {
+			log.Printf("session %s: cleanup command %q failed: %v", sess.ID, command, err)
+		}
+		e.appendNotice(sess, domain.MessageKindSystem, domain.NewNotice(domain.NoticeCleanupResult, params...), time.Now())
+	}
+}
+
+// hookCommand builds the command that runs a cleanup hook in the session's
+// working directory: over SSH for remote sessions, otherwise with the
+// platform shell (sh on Unix, cmd.exe on Windows).
+func hookCommand(ctx context.Context, sess *domain.Session, command, trigger string) (*exec.Cmd, error) {
+	env := map[string]string{
+		"ORBITMESH_SESSION_ID":      sess.ID,
+		"ORBITMESH_CLEANUP_TRIGGER": trigger,
+	}
+	if remote.IsRemote(sess.WorkingDir) {
+		if target, err := remote.Parse(sess.WorkingDir); err == nil {
+			return target.Command(ctx, remote.Spec{Command: "sh", Args: []string{"-c", command}, Env: env})
+		}
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = sess.WorkingDir
+	cmd.Env = os.Environ()
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	return cmd, nil
+}
+
+func hookResult(ctx context.Context, err error) []string {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return []string{"outcome", "succeeded"}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return []string{"outcome", "timed out"}
+	case errors.As(err, &exitErr):
+		return []string{"outcome", "exited", "exit_code", strconv.Itoa(exitErr.ExitCode())}
+	default:
+		return []string{"outcome", "failed", "error", err.Error()}
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func hookMessages(sess *domain.Session) []string {
+	var out []string
+	for _, m := range sess.Snapshot().Messages {
+		if m.Kind == domain.MessageKindSystem && strings.HasPrefix(m.Contents, "[cleanup]") {
+			out = append(out, m.Contents)
+		}
+	}
+	return out
+}
+
+func TestAgentExecutor_StopRunsTerminationHooks(t *testing.T) {
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	sess, err := executor.CreateSession(context.Background(), "hooks", session.Config{
+		ProviderType: "mock",
+		WorkingDir:   dir,
+		CleanupCommands: []string{
+			`echo "down $ORBITMESH_CLEANUP_TRIGGER $ORBITMESH_SESSION_ID in $(pwd)"`,
+			"exit 3",
+		},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Stopping an idle session is a no-op and runs no hooks.
+	if err := executor.StopSession(context.Background(), "hooks"); err != nil {
+		t.Fatalf("idle StopSession: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "hooks", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	if err := executor.StopSession(context.Background(), "hooks"); err != nil {
+		t.Fatalf("StopSession: %v", err)
+	}
+	executor.hookWG.Wait()
+
+	got := hookMessages(sess)
+	if len(got) != 2 {
+		t.Fatalf("hook messages = %q, want 2", got)
+	}
+	if !strings.Contains(got[0], "succeeded") || !strings.Contains(got[0], "down stop hooks in "+dir) {
+		t.Errorf("first hook message = %q", got[0])
+	}
+	if !strings.Contains(got[1], "exited with status 3") {
+		t.Errorf("second hook message = %q", got[1])
+	}
+	stored, err := store.Load("hooks")
+	if err != nil || len(hookMessages(stored)) != 2 {
+		t.Fatalf("stored session hook messages = %v, %v", stored, err)
+	}
+}
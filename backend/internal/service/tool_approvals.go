@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+var (
+	ErrToolApprovalNotFound = errors.New("tool approval not found")
+	ErrToolApprovalDecided  = errors.New("tool call is no longer awaiting approval")
+)
+
+// AwaitToolApproval asks a human whether a tool call the provider asked
+// permission for may run, and waits for the decision. It reports whether
+// the call may run, the input to run it with when the approver edited it,
+// and otherwise why not. Calls run without asking in sessions without tool
+// approval, for tools the policy auto-allows, and for shell commands
+// already decided by the session's command approval. A call nobody decides
+// on within the policy's timeout gets the policy's timeout action.
+func (e *AgentExecutor) AwaitToolApproval(ctx context.Context, id, toolCallID, toolName string, input map[string]any, description string) (bool, map[string]any, string) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return false, nil, err.Error()
+	}
+	policy := sess.GetToolApproval()
+	if policy.AutoAllows(toolName) {
+		return true, nil, ""
+	}
+	if _, ok := domain.ToolCommand(toolName, input); ok && sess.GetCommandApproval() != nil {
+		return true, nil, ""
+	}
+
+	record, err := e.requestToolApproval(id, toolCallID, toolName, input, description)
+	if err != nil {
+		return false, nil, err.Error()
+	}
+	waitCtx := ctx
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+	if record, err = e.WaitForToolApproval(waitCtx, id, record.ID); err != nil {
+		return false, nil, err.Error()
+	}
+	if record.Pending() && ctx.Err() == nil {
+		allow := policy.OnTimeout == domain.ToolApprovalTimeoutAllow
+		reason := fmt.Sprintf("no decision within %s", policy.Timeout)
+		if decided, err := e.DecideToolApproval(id, record.ID, allow, nil, reason, domain.ToolApprovalDecidedByTimeout); err == nil {
+			record = decided
+		} else if errors.Is(err, ErrToolApprovalDecided) {
+			record, _ = sess.GetToolApprovalRecord(record.ID)
+		}
+	}
+
+	switch record.Status {
+	case domain.ToolApprovalAllowed:
+		return true, record.UpdatedInput, ""
+	case domain.ToolApprovalDenied:
+		if record.Reason != "" {
+			return false, nil, "the tool call was denied: " + record.Reason
+		}
+		return false, nil, "the tool call was denied"
+	}
+	return false, nil, "the tool call was not approved in time"
+}
+
+// requestToolApproval records a pending tool call and suspends the run
+// until it is decided.
+func (e *AgentExecutor) requestToolApproval(id, toolCallID, toolName string, input map[string]any, description string) (domain.ToolApprovalRecord, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return domain.ToolApprovalRecord{}, err
+	}
+	if sc.getRun() == nil {
+		return domain.ToolApprovalRecord{}, fmt.Errorf("%w: only a running session can ask for tool approval", ErrInvalidState)
+	}
+
+	now := time.Now().UTC()
+	record := domain.ToolApprovalRecord{
+		ID:          newAttemptID(),
+		ToolCallID:  toolCallID,
+		ToolName:    toolName,
+		Input:       input,
+		Description: strings.TrimSpace(description),
+		Status:      domain.ToolApprovalPending,
+		RequestedAt: now,
+	}
+	sc.session.AddToolApprovalRecord(record)
+	e.toolApprovalWaiters.add(id, record.ID)
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeToolApprovalRequested, "tool", toolName), now)
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.WaitKind = domain.WaitKindToolApproval
+		a.WaitRef = record.ID
+		a.HeartbeatAt = now
+	})
+	if sc.session.GetState() == domain.SessionStateRunning {
+		e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitToolApproval, "tool", toolName))
+	} else if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+	return record, nil
+}
+
+// DecideToolApproval allows or denies a pending tool call and resumes the
+// suspended run once no other call waits. An allowed call runs with input
+// when it is set; the reason of a denial is passed on to the agent.
+func (e *AgentExecutor) DecideToolApproval(id, approvalID string, allow bool, input map[string]any, reason, decidedBy string) (domain.ToolApprovalRecord, error) {
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return domain.ToolApprovalRecord{}, err
+	}
+	now := time.Now().UTC()
+	decided := false
+	record, ok := sc.session.UpdateToolApprovalRecord(approvalID, func(r *domain.ToolApprovalRecord) {
+		if !r.Pending() {
+			return
+		}
+		decided = true
+		r.DecidedBy = decidedBy
+		r.DecidedAt = &now
+		r.Reason = strings.TrimSpace(reason)
+		if allow {
+			r.Status = domain.ToolApprovalAllowed
+			r.UpdatedInput = input
+		} else {
+			r.Status = domain.ToolApprovalDenied
+		}
+	})
+	switch {
+	case !ok:
+		return domain.ToolApprovalRecord{}, ErrToolApprovalNotFound
+	case !decided:
+		return domain.ToolApprovalRecord{}, ErrToolApprovalDecided
+	}
+
+	notice := domain.NewNotice(domain.NoticeToolApprovalAllowed, "tool", record.ToolName, "decided_by", decidedBy)
+	if !allow {
+		notice = domain.NewNotice(domain.NoticeToolApprovalDenied, "tool", record.ToolName, "decided_by", decidedBy, "reason", record.Reason)
+	}
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, now)
+
+	var waiting *domain.ToolApprovalRecord
+	for _, r := range sc.session.GetToolApprovals() {
+		if r.Pending() {
+			waiting = &r
+		}
+	}
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		if a.WaitKind == domain.WaitKindToolApproval && a.WaitRef == approvalID {
+			a.WaitKind, a.WaitRef = "", ""
+			if waiting != nil {
+				a.WaitKind, a.WaitRef = domain.WaitKindToolApproval, waiting.ID
+			}
+		}
+		a.HeartbeatAt = now
+	})
+	if sc.getRun() != nil && waiting == nil && sc.session.GetState() == domain.SessionStateSuspended {
+		e.transitionWithSave(sc, domain.SessionStateRunning, domain.NewNotice(domain.NoticeStatusToolDecided, "decision", string(record.Status)))
+	} else if e.storage != nil {
+		_ = e.saveSession(sc.session)
+	}
+	e.toolApprovalWaiters.wake(id, approvalID)
+	e.recordAudit(storage.AuditEntry{
+		Actor:     decidedBy,
+		Action:    "tool_approval_" + string(record.Status),
+		SessionID: id,
+		Targets:   []string{record.ToolName},
+		Reason:    record.Reason,
+	})
+	return record, nil
+}
+
+// WaitForToolApproval blocks until a tool call is decided, or ctx is done,
+// and returns its latest state.
+func (e *AgentExecutor) WaitForToolApproval(ctx context.Context, id, approvalID string) (domain.ToolApprovalRecord, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return domain.ToolApprovalRecord{}, err
+	}
+	record, ok := sess.GetToolApprovalRecord(approvalID)
+	if !ok {
+		return domain.ToolApprovalRecord{}, ErrToolApprovalNotFound
+	}
+	if !record.Pending() {
+		return record, nil
+	}
+	if ch := e.toolApprovalWaiters.get(id, approvalID); ch != nil {
+		select {
+		case <-ch:
+		case <-ctx.Done():
+		}
+	}
+	record, _ = sess.GetToolApprovalRecord(approvalID)
+	return record, nil
+}
+
+// SessionToolApprovals returns the tool calls the session asked a human
+// about, oldest first.
+func (e *AgentExecutor) SessionToolApprovals(id string) ([]domain.ToolApprovalRecord, error) {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return nil, err
+	}
+	return sess.GetToolApprovals(), nil
+}
+
+// denyPendingToolApprovals denies the tool calls of a run that ended before
+// they were decided, releasing the provider waiting on them.
+func (e *AgentExecutor) denyPendingToolApprovals(sc *sessionContext) {
+	if sc == nil || sc.session == nil {
+		return
+	}
+	now := time.Now().UTC()
+	for _, r := range sc.session.GetToolApprovals() {
+		if !r.Pending() {
+			continue
+		}
+		sc.session.UpdateToolApprovalRecord(r.ID, func(r *domain.ToolApprovalRecord) {
+			r.Status = domain.ToolApprovalDenied
+			r.DecidedAt = &now
+			r.Reason = "the run ended before it was decided"
+		})
+		e.toolApprovalWaiters.wake(sc.session.ID, r.ID)
+	}
+}
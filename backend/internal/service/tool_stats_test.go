@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/provider/common/claude"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestToolStatsTracker_AggregatesAndPersists(t *testing.T) {
+	store := storage.NewToolStatsStorage(t.TempDir())
+	tracker := newToolStatsTracker(store)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "t1", Name: "Bash", Status: "started"}, base)
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "t1", Status: "completed"}, base.Add(200*time.Millisecond))
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "t2", Name: "Bash", Status: "started"}, base)
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "t2", Name: "Bash", Status: "failed"}, base.Add(400*time.Millisecond))
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "t3", Name: "Read", Status: "completed"}, base)
+	tracker.record("agent-2", "s2", domain.ToolCallData{ID: "t1", Name: "Bash", Status: "completed"}, base)
+	tracker.record("", "s3", domain.ToolCallData{ID: "t1", Name: "Bash", Status: "completed"}, base)
+
+	stats := tracker.forAgent("agent-1")
+	bash := stats["Bash"]
+	if bash.Calls != 2 || bash.Failures != 1 {
+		t.Fatalf("Bash calls/failures = %d/%d, want 2/1", bash.Calls, bash.Failures)
+	}
+	if bash.LatencySamples != 2 || bash.TotalLatencyMS != 600 {
+		t.Fatalf("Bash latency = %dms over %d, want 600ms over 2", bash.TotalLatencyMS, bash.LatencySamples)
+	}
+	read := stats["Read"]
+	if read.Calls != 1 || read.LatencySamples != 0 {
+		t.Fatalf("Read = %+v, want 1 call without latency sample", read)
+	}
+
+	tracker.saver.flush()
+	reloaded := newToolStatsTracker(store).forAgent("agent-1")
+	if reloaded["Bash"].Calls != 2 || reloaded["Read"].Calls != 1 {
+		t.Fatalf("reloaded stats = %+v", reloaded)
+	}
+	if got := newToolStatsTracker(store).forAgent("agent-2")["Bash"].Calls; got != 1 {
+		t.Fatalf("agent-2 Bash calls = %d, want 1", got)
+	}
+}
+
+func TestToolStatsTracker_ForgetsStalePendingCalls(t *testing.T) {
+	tracker := newToolStatsTracker(nil)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "lost", Name: "Bash", Status: "started"}, base)
+	tracker.record("agent-1", "s1", domain.ToolCallData{ID: "next", Name: "Read", Status: "started"}, base.Add(pendingToolCallTTL+time.Minute))
+
+	tracker.mu.Lock()
+	_, lost := tracker.pending["s1/lost"]
+	n := len(tracker.pending)
+	tracker.mu.Unlock()
+	if lost || n != 1 {
+		t.Fatalf("pending holds %d calls, stale call kept: %v", n, lost)
+	}
+}
+
+func TestAgentExecutor_ToolStatsFromClaudeStream(t *testing.T) {
+	prov := newMockProvider()
+	executor, _ := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	if _, err := executor.CreateSession(context.Background(), "stats", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test", AgentID: "agent1"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := executor.SendMessage(context.Background(), "stats", "run the tests", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	sess, _ := executor.GetSession("stats")
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	translator := claude.NewTranslator("stats")
+	for _, line := range []string{
+		`{"type":"stream_event","event":{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"Bash","input":{}}}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"ok"}]}}`,
+		`{"type":"stream_event","event":{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_2","name":"Bash","input":{}}}}`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_2","content":"exit status 1","is_error":true}]}}`,
+	} {
+		msg, err := claude.ParseMessage([]byte(line))
+		if err != nil {
+			t.Fatalf("parse %s: %v", line, err)
+		}
+		event, ok := translator.Translate(msg)
+		if !ok {
+			t.Fatalf("no event for %s", line)
+		}
+		prov.SendEvent(event)
+	}
+
+	waitFor(t, func() bool { return executor.AgentToolStats("agent1")["Bash"].Calls == 2 })
+	bash := executor.AgentToolStats("agent1")["Bash"]
+	if bash.Failures != 1 || bash.LatencySamples != 2 {
+		t.Fatalf("Bash = %+v, want 1 failure and 2 latency samples", bash)
+	}
+	executor.toolStats.mu.Lock()
+	pending := len(executor.toolStats.pending)
+	executor.toolStats.mu.Unlock()
+	if pending != 0 {
+		t.Fatalf("%d tool calls left in flight", pending)
+	}
+}
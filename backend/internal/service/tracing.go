@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// tracer records spans for session creation, each message sent to a
+// session, and each run with its provider start and tool calls. It uses the
+// global tracer provider, which exports nothing unless one is installed.
+var tracer = otel.Tracer("github.com/ricochet1k/orbitmesh/internal/service")
+
+// runTrace holds the span of a session's current run and of its tool calls
+// in flight, keyed by tool call ID.
+type runTrace struct {
+	mu    sync.Mutex
+	span  trace.Span
+	tools map[string]trace.Span
+}
+
+// endSpan ends span, marking it failed when err is set.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startRunSpan starts the span of a run of sc, a child of the span in ctx,
+// and returns a context carrying it but not ctx's cancellation, since the
+// run outlives the request that started it.
+func (e *AgentExecutor) startRunSpan(ctx context.Context, sc *sessionContext, providerType string, resume bool) context.Context {
+	attrs := []attribute.KeyValue{
+		attribute.String("session.id", sc.session.ID),
+		attribute.String("provider.type", providerType),
+		attribute.Bool("run.resume", resume),
+	}
+	sc.amMu.Lock()
+	if sc.attempt != nil {
+		attrs = append(attrs, attribute.String("run.attempt_id", sc.attempt.AttemptID))
+	}
+	sc.amMu.Unlock()
+	if sc.session.ProjectID != "" {
+		attrs = append(attrs, attribute.String("project.id", sc.session.ProjectID))
+	}
+	_, span := tracer.Start(ctx, "session.run", trace.WithAttributes(attrs...))
+
+	t := &sc.tracing
+	t.mu.Lock()
+	t.endLocked("superseded", "", time.Now())
+	t.span = span
+	t.mu.Unlock()
+	return trace.ContextWithSpan(context.Background(), span)
+}
+
+// endRunSpan ends the span of sc's run, and of tool calls it left open,
+// with the run's outcome.
+func (sc *sessionContext) endRunSpan(terminalReason, interruptionReason string) {
+	if sc == nil {
+		return
+	}
+	t := &sc.tracing
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endLocked(terminalReason, interruptionReason, time.Now())
+}
+
+func (t *runTrace) endLocked(terminalReason, interruptionReason string, at time.Time) {
+	for id, span := range t.tools {
+		span.SetStatus(codes.Error, "run ended before the tool call finished")
+		span.End(trace.WithTimestamp(at))
+		delete(t.tools, id)
+	}
+	if t.span == nil {
+		return
+	}
+	t.span.SetAttributes(attribute.String("run.terminal_reason", terminalReason))
+	if terminalReason == "failed" || terminalReason == "interrupted" {
+		t.span.SetStatus(codes.Error, interruptionReason)
+	}
+	t.span.End(trace.WithTimestamp(at))
+	t.span = nil
+}
+
+// traceStateChange records a state transition on sc's run span.
+func (sc *sessionContext) traceStateChange(oldState, newState domain.SessionState, notice domain.Notice) {
+	t := &sc.tracing
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.span == nil {
+		return
+	}
+	t.span.AddEvent("state_change", trace.WithAttributes(
+		attribute.String("state.from", oldState.String()),
+		attribute.String("state.to", newState.String()),
+		attribute.String("notice", notice.Code),
+	))
+}
+
+// traceToolCall starts a span for a tool call the first time it is seen
+// and ends it with the event that finishes it. A subagent's tool calls are
+// children of the call that spawned them.
+func (sc *sessionContext) traceToolCall(data domain.ToolCallData, at time.Time) {
+	if data.ID == "" {
+		return
+	}
+	t := &sc.tracing
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.span == nil {
+		return
+	}
+
+	span, ok := t.tools[data.ID]
+	if !ok {
+		parent := t.span
+		if p, ok := t.tools[data.ParentID]; ok {
+			parent = p
+		}
+		_, span = tracer.Start(trace.ContextWithSpan(context.Background(), parent), "tool_call "+data.Name,
+			trace.WithTimestamp(at),
+			trace.WithAttributes(
+				attribute.String("tool.name", data.Name),
+				attribute.String("tool.call_id", data.ID),
+			))
+		if t.tools == nil {
+			t.tools = make(map[string]trace.Span)
+		}
+		t.tools[data.ID] = span
+	}
+
+	failed := toolCallFailureStatuses[data.Status]
+	if data.Status != "completed" && !failed {
+		return
+	}
+	span.SetAttributes(attribute.String("tool.status", data.Status))
+	if failed {
+		span.SetStatus(codes.Error, data.Status)
+	}
+	span.End(trace.WithTimestamp(at))
+	delete(t.tools, data.ID)
+}
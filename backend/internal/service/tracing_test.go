@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// spanRecorder installs the global tracer provider once, as the package
+// tracer keeps delegating to the first one set.
+var spanRecorder = sync.OnceValue(func() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+})
+
+func TestAgentExecutor_TracesRuns(t *testing.T) {
+	recorder := spanRecorder()
+	prov := newMockProvider()
+	executor, store := createTestExecutor(prov)
+	defer executor.Shutdown(context.Background())
+
+	ctx := context.Background()
+	if _, err := executor.CreateSession(ctx, "s1", session.Config{ProviderType: "mock"}); err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if _, err := executor.SendMessage(ctx, "s1", "hi", "", ""); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	prov.events <- domain.NewToolCallEvent("s1", domain.ToolCallData{ID: "t1", Name: "Bash", Status: "started"}, nil)
+	prov.events <- domain.NewToolCallEvent("s1", domain.ToolCallData{ID: "t2", Name: "Read", Status: "started", ParentID: "t1"}, nil)
+	prov.events <- domain.NewToolCallEvent("s1", domain.ToolCallData{ID: "t2", Status: "failed"}, nil)
+	prov.events <- domain.NewToolCallEvent("s1", domain.ToolCallData{ID: "t1", Status: "completed"}, nil)
+	close(prov.events)
+	waitForRunAttempt(t, store, "s1", true)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	waitFor(t, func() bool {
+		for _, s := range recorder.Ended() {
+			// Spans of earlier runs of the test are older than the run's.
+			if prev := spans[s.Name()]; prev == nil || s.StartTime().After(prev.StartTime()) {
+				spans[s.Name()] = s
+			}
+		}
+		return spans["session.run"] != nil
+	})
+	for _, name := range []string{"session.create", "session.message", "provider.start", "tool_call Bash", "tool_call Read"} {
+		if spans[name] == nil {
+			t.Fatalf("missing span %q, got %v", name, spans)
+		}
+	}
+
+	run := spans["session.run"]
+	if run.Parent().SpanID() != spans["session.message"].SpanContext().SpanID() {
+		t.Error("expected the run to be a child of the message that started it")
+	}
+	if !hasAttribute(run, attribute.String("run.terminal_reason", "completed")) {
+		t.Errorf("run attributes = %v", run.Attributes())
+	}
+	if spans["provider.start"].Parent().SpanID() != run.SpanContext().SpanID() {
+		t.Error("expected the provider start to be a child of the run")
+	}
+	if spans["tool_call Bash"].Parent().SpanID() != run.SpanContext().SpanID() {
+		t.Error("expected the tool call to be a child of the run")
+	}
+	read := spans["tool_call Read"]
+	if read.Parent().SpanID() != spans["tool_call Bash"].SpanContext().SpanID() || read.Status().Code != codes.Error {
+		t.Errorf("expected the failed subagent call under its parent, got status %v", read.Status())
+	}
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, want attribute.KeyValue) bool {
+	for _, kv := range span.Attributes() {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
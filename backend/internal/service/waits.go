@@ -0,0 +1,200 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// WaitKindExpiredResumeToken marks an external tool call whose resume token
+// expired: the run can no longer be resumed by the party it waits on.
+const WaitKindExpiredResumeToken = "expired_resume_token"
+
+// StaleWaitAge is how long something may wait before it is reported with
+// high priority.
+const StaleWaitAge = 24 * time.Hour
+
+// maxWaitSummaryLen bounds the summary of a pending wait.
+const maxWaitSummaryLen = 200
+
+// WaitPriority orders pending waits by how urgently they need attention.
+type WaitPriority string
+
+const (
+	WaitPriorityHigh   WaitPriority = "high"
+	WaitPriorityNormal WaitPriority = "normal"
+	WaitPriorityLow    WaitPriority = "low"
+)
+
+func (p WaitPriority) rank() int {
+	switch p {
+	case WaitPriorityHigh:
+		return 0
+	case WaitPriorityNormal:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// PendingWait is one thing a session is blocked on: a human decision or
+// answer, or an external system.
+type PendingWait struct {
+	// Kind is a run wait kind, such as domain.WaitKindToolApproval, or
+	// WaitKindExpiredResumeToken.
+	Kind         string
+	SessionID    string
+	SessionTitle string
+	// Ref identifies what is waited on: the question, command or tool
+	// approval ID, or the external tool call.
+	Ref      string
+	Summary  string
+	Since    time.Time
+	Priority WaitPriority
+}
+
+// PendingWaits lists everything blocking on a human or an external system
+// across all sessions, most urgent first and then oldest first. Human waits
+// and external tool calls are normal priority and batch queue runs low;
+// expired resume tokens and anything waiting StaleWaitAge or longer are
+// high.
+func (e *AgentExecutor) PendingWaits(now time.Time) []PendingWait {
+	titles := make(map[string]string)
+	out := make([]PendingWait, 0)
+	add := func(w PendingWait) {
+		if w.Priority != WaitPriorityHigh && now.Sub(w.Since) >= StaleWaitAge {
+			w.Priority = WaitPriorityHigh
+		}
+		w.Summary = truncateWaitSummary(w.Summary)
+		out = append(out, w)
+	}
+
+	for _, s := range e.ListSessions() {
+		snap := s.Snapshot()
+		titles[snap.ID] = snap.Title
+		wait := PendingWait{SessionID: snap.ID, SessionTitle: snap.Title, Priority: WaitPriorityNormal}
+
+		if snap.Plan != nil && snap.Plan.Status == domain.PlanStatusProposed {
+			w := wait
+			w.Kind, w.Summary, w.Since = domain.WaitKindPlanApproval, snap.Plan.Content, snap.Plan.ProposedAt
+			add(w)
+		}
+		for _, c := range snap.Commands {
+			if c.Pending() {
+				w := wait
+				w.Kind, w.Ref, w.Summary, w.Since = domain.WaitKindCommandApproval, c.ID, c.Command, c.ProposedAt
+				add(w)
+			}
+		}
+		for _, a := range snap.ToolApprovals {
+			if a.Pending() {
+				w := wait
+				w.Kind, w.Ref, w.Summary, w.Since = domain.WaitKindToolApproval, a.ID, a.ToolName, a.RequestedAt
+				if a.Description != "" {
+					w.Summary = a.ToolName + ": " + a.Description
+				}
+				add(w)
+			}
+		}
+		if snap.State == domain.SessionStateSuspended {
+			for _, w := range e.externalWaits(wait, snap, now) {
+				add(w)
+			}
+		}
+	}
+	for _, q := range e.PendingQuestions() {
+		add(PendingWait{
+			Kind:         domain.WaitKindWaitingOnHuman,
+			SessionID:    q.SessionID,
+			SessionTitle: titles[q.SessionID],
+			Ref:          q.ID,
+			Summary:      q.Question,
+			Since:        q.AskedAt,
+			Priority:     WaitPriorityNormal,
+		})
+	}
+
+	slices.SortStableFunc(out, func(a, b PendingWait) int {
+		if d := a.Priority.rank() - b.Priority.rank(); d != 0 {
+			return d
+		}
+		return a.Since.Compare(b.Since)
+	})
+	return out
+}
+
+// externalWaits reports the external tool calls and batch queue a suspended
+// session's run waits on.
+func (e *AgentExecutor) externalWaits(wait PendingWait, snap domain.SessionSnapshot, now time.Time) []PendingWait {
+	attempt, err := e.latestPersistedAttempt(snap.ID)
+	if err != nil || attempt == nil || attempt.EndedAt != nil {
+		return nil
+	}
+	wait.Since = suspendedSince(snap)
+
+	switch attempt.WaitKind {
+	case domain.WaitKindQueuedRemote:
+		wait.Kind, wait.Ref, wait.Priority = domain.WaitKindQueuedRemote, attempt.WaitRef, WaitPriorityLow
+		wait.Summary = "waiting on the provider's batch queue"
+		return []PendingWait{wait}
+	case domain.WaitKindToolCall:
+	default:
+		return nil
+	}
+
+	waits := attempt.Waits
+	if len(waits) == 0 {
+		waits = []storage.RunWait{{Ref: attempt.WaitRef, ResumeTokenID: attempt.ResumeTokenID}}
+	}
+	out := make([]PendingWait, 0, len(waits))
+	for _, rw := range waits {
+		if rw.FulfilledAt != nil {
+			continue
+		}
+		w := wait
+		w.Kind, w.Ref, w.Summary = domain.WaitKindToolCall, rw.Ref, "waiting for tool result: "+rw.Ref
+		if token := e.loadResumeToken(rw.ResumeTokenID); token != nil {
+			if ResumeTokenStatus(token, now) == ResumeTokenExpired {
+				w.Kind, w.Priority = WaitKindExpiredResumeToken, WaitPriorityHigh
+				w.Summary = "resume token for " + rw.Ref + " expired at " + token.ExpiresAt.UTC().Format(time.RFC3339)
+			}
+		}
+		out = append(out, w)
+	}
+	return out
+}
+
+func (e *AgentExecutor) loadResumeToken(tokenID string) *storage.ResumeTokenMetadata {
+	if e.resumeTokenStorage == nil || tokenID == "" {
+		return nil
+	}
+	token, err := e.resumeTokenStorage.LoadResumeToken(tokenID)
+	if err != nil {
+		return nil
+	}
+	return token
+}
+
+// suspendedSince returns when the session was last suspended.
+func suspendedSince(snap domain.SessionSnapshot) time.Time {
+	for i := len(snap.Transitions) - 1; i >= 0; i-- {
+		if snap.Transitions[i].To == domain.SessionStateSuspended {
+			return snap.Transitions[i].Timestamp
+		}
+	}
+	return snap.UpdatedAt
+}
+
+func truncateWaitSummary(s string) string {
+	s = strings.TrimSpace(s)
+	if first, _, ok := strings.Cut(s, "\n"); ok {
+		s = first
+	}
+	if len(s) > maxWaitSummaryLen {
+		s = strings.ToValidUTF8(s[:maxWaitSummaryLen], "") + "…"
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_PendingWaits(t *testing.T) {
+	executor, _, _ := startToolApprovalSession(t, "tools", &domain.ToolApproval{})
+	awaitToolAsync(executor, "tools", "Write", nil)
+	pendingToolApproval(t, executor, "tools")
+
+	store := executor.storage.(*mockStorage)
+	if _, err := executor.CreateSession(context.Background(), "external", session.Config{ProviderType: "mock", WorkingDir: "/tmp/test", Title: "deploy"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	sess, _ := executor.GetSession("external")
+	_ = sess.TransitionTo(domain.SessionStateRunning, "")
+	_ = sess.TransitionTo(domain.SessionStateSuspended, "waiting for tool result: tool-x")
+	started := time.Now().UTC().Add(-time.Hour)
+	if err := store.SaveRunAttempt(&storage.RunAttemptMetadata{
+		AttemptID:     "attempt-external",
+		SessionID:     "external",
+		ProviderType:  "mock",
+		StartedAt:     started,
+		HeartbeatAt:   started,
+		WaitKind:      domain.WaitKindToolCall,
+		WaitRef:       "tool-x",
+		ResumeTokenID: "token-x",
+	}); err != nil {
+		t.Fatalf("SaveRunAttempt: %v", err)
+	}
+	if err := store.SaveResumeToken(&storage.ResumeTokenMetadata{
+		TokenID:   "token-x",
+		SessionID: "external",
+		AttemptID: "attempt-external",
+		CreatedAt: started,
+		ExpiresAt: started.Add(time.Minute),
+	}); err != nil {
+		t.Fatalf("SaveResumeToken: %v", err)
+	}
+
+	waits := executor.PendingWaits(time.Now())
+	if len(waits) != 2 {
+		t.Fatalf("waits = %+v, want two", waits)
+	}
+	if w := waits[0]; w.Kind != WaitKindExpiredResumeToken || w.SessionTitle != "deploy" || w.Ref != "tool-x" || w.Priority != WaitPriorityHigh {
+		t.Errorf("first wait = %+v, want the expired resume token", w)
+	}
+	if w := waits[1]; w.Kind != domain.WaitKindToolApproval || w.Summary != "Write" || w.Priority != WaitPriorityNormal {
+		t.Errorf("second wait = %+v, want the tool approval", w)
+	}
+
+	for _, w := range executor.PendingWaits(time.Now().Add(StaleWaitAge)) {
+		if w.Kind == domain.WaitKindToolApproval && w.Priority != WaitPriorityHigh {
+			t.Errorf("stale wait = %+v, want high priority", w)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// DefaultWarmRunnerTTL is how long a warm runner waits for its session's
+// first message before it is stopped.
+const DefaultWarmRunnerTTL = 5 * time.Minute
+
+// WarmPoolConfig enables pre-starting provider runners for new sessions, so
+// the first message skips provider startup (process boot, handshakes). Only
+// runners implementing session.Prewarmer are warmed.
+type WarmPoolConfig struct {
+	// Sizes caps the warm runners kept per provider type; the oldest is
+	// stopped to make room. Provider types without an entry are not warmed.
+	Sizes map[string]int
+	// TTL defaults to DefaultWarmRunnerTTL.
+	TTL time.Duration
+}
+
+// WarmPoolStats reports warm pool activity since the executor started.
+type WarmPoolStats struct {
+	// Idle counts warm runners waiting for a first message, by provider
+	// type.
+	Idle    map[string]int
+	Started int64
+	// Hits counts first runs served by a warm runner; Misses counts first
+	// runs of warmed sessions that had to start cold (the runner failed,
+	// expired, was evicted or no longer matched the session's config).
+	Hits    int64
+	Misses  int64
+	Expired int64
+	Evicted int64
+	Failed  int64
+	// HitRate is Hits / (Hits + Misses).
+	HitRate float64
+}
+
+type warmRunner struct {
+	sessionID    string
+	providerType string
+	key          string
+	config       session.Config
+	runner       session.Session
+	createdAt    time.Time
+	expiry       *time.Timer
+}
+
+// warmPool holds at most one warm runner per session. Runners are bound to
+// their session when created, so the per-session environment is correct;
+// a runner whose session's config changed before the first message is
+// discarded rather than reused.
+type warmPool struct {
+	cfg WarmPoolConfig
+
+	mu      sync.Mutex
+	runners map[string]*warmRunner
+	// pending marks warmed sessions whose first run has not started, so
+	// later runs do not count as misses.
+	pending map[string]bool
+	stats   WarmPoolStats
+}
+
+func newWarmPool(cfg WarmPoolConfig) *warmPool {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultWarmRunnerTTL
+	}
+	return &warmPool{
+		cfg:     cfg,
+		runners: make(map[string]*warmRunner),
+		pending: make(map[string]bool),
+	}
+}
+
+func (p *warmPool) enabled(providerType string) bool {
+	return p.cfg.Sizes[providerType] > 0
+}
+
+// add registers a warm runner, evicting the oldest of its provider type if
+// the pool is full.
+func (p *warmPool) add(w *warmRunner) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var oldest *warmRunner
+	count := 0
+	for _, other := range p.runners {
+		if other.providerType != w.providerType {
+			continue
+		}
+		count++
+		if oldest == nil || other.createdAt.Before(oldest.createdAt) {
+			oldest = other
+		}
+	}
+	if oldest != nil && count >= p.cfg.Sizes[w.providerType] {
+		p.removeLocked(oldest)
+		p.stats.Evicted++
+	}
+
+	p.runners[w.sessionID] = w
+	p.pending[w.sessionID] = true
+	p.stats.Started++
+	w.expiry = time.AfterFunc(p.cfg.TTL, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.runners[w.sessionID] == w {
+			p.removeLocked(w)
+			p.stats.Expired++
+		}
+	})
+}
+
+// fail drops a runner whose warm-up failed, unless it was already claimed.
+func (p *warmPool) fail(w *warmRunner) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.runners[w.sessionID] == w {
+		p.removeLocked(w)
+		p.stats.Failed++
+	}
+}
+
+// claim hands over the session's warm runner if it was started for key. A
+// runner still warming up is handed over too; its SendInput waits for the
+// warm-up to finish.
+func (p *warmPool) claim(sessionID, providerType, key string) *warmRunner {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending := p.pending[sessionID]
+	delete(p.pending, sessionID)
+
+	w := p.runners[sessionID]
+	if w != nil && w.providerType == providerType && w.key == key {
+		delete(p.runners, sessionID)
+		w.expiry.Stop()
+		p.stats.Hits++
+		return w
+	}
+	if w != nil {
+		p.removeLocked(w)
+	}
+	if pending {
+		p.stats.Misses++
+	}
+	return nil
+}
+
+// discard stops the session's warm runner, if any.
+func (p *warmPool) discard(sessionID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, sessionID)
+	if w := p.runners[sessionID]; w != nil {
+		p.removeLocked(w)
+	}
+}
+
+// drain stops every warm runner.
+func (p *warmPool) drain() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, w := range p.runners {
+		p.removeLocked(w)
+	}
+	clear(p.pending)
+}
+
+// removeLocked stops w and drops it from the pool. Caller must hold p.mu.
+func (p *warmPool) removeLocked(w *warmRunner) {
+	delete(p.runners, w.sessionID)
+	if w.expiry != nil {
+		w.expiry.Stop()
+	}
+	// Kill must not block, but a runner still warming up may hold its own
+	// lock until the warm-up returns.
+	go func() { _ = w.runner.Kill() }()
+}
+
+func (p *warmPool) snapshot() WarmPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Idle = make(map[string]int, len(p.cfg.Sizes))
+	for providerType := range p.cfg.Sizes {
+		stats.Idle[providerType] = 0
+	}
+	for _, w := range p.runners {
+		stats.Idle[w.providerType]++
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// warmConfigKey fingerprints the parts of a run config that shape how the
+// provider starts. The environment is left out: it is rebuilt for every run
+// and a warm runner keeps the one it was started with.
+func warmConfigKey(config session.Config) (string, bool) {
+	config.Environment = nil
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// warmSession starts a runner for a new session in the background, if its
+// provider type is pooled and the runner supports warming.
+func (e *AgentExecutor) warmSession(sess *domain.Session) {
+	providerType := sess.ProviderType
+	if !e.warm.enabled(providerType) {
+		return
+	}
+	config := e.runConfig(sess.ID, sess, providerType)
+	key, ok := warmConfigKey(config)
+	if !ok {
+		return
+	}
+	maps.Copy(config.Environment, e.gitCredentialEnv(sess.ID))
+	e.renderMCPServers(sess, &config)
+
+	runner, err := e.sessionFactory(providerType, sess.ID, config)
+	if err != nil {
+		return
+	}
+	prewarmer, ok := runner.(session.Prewarmer)
+	if !ok {
+		return
+	}
+
+	w := &warmRunner{
+		sessionID:    sess.ID,
+		providerType: providerType,
+		key:          key,
+		config:       config,
+		runner:       runner,
+		createdAt:    time.Now(),
+	}
+	e.warm.add(w)
+
+	e.wg.Go(func() {
+		ctx, cancel := context.WithTimeout(e.ctx, e.opTimeout)
+		defer cancel()
+		if err := prewarmer.Prewarm(ctx, config); err != nil {
+			log.Printf("warm pool: failed to warm %s runner for session %s: %v", providerType, sess.ID, err)
+			e.warm.fail(w)
+		}
+	})
+}
+
+// WarmPoolStats reports warm pool activity.
+func (e *AgentExecutor) WarmPoolStats() WarmPoolStats {
+	return e.warm.snapshot()
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+type prewarmingProvider struct {
+	*mockProvider
+	prewarmed atomic.Int32
+}
+
+func (p *prewarmingProvider) Prewarm(ctx context.Context, config session.Config) error {
+	p.prewarmed.Add(1)
+	return nil
+}
+
+func newPrewarmingProvider() *prewarmingProvider {
+	return &prewarmingProvider{mockProvider: newMockProvider()}
+}
+
+// newWarmPoolExecutor returns an executor warming runners as cfg says and
+// the runners its factory has created so far.
+func newWarmPoolExecutor(t *testing.T, cfg WarmPoolConfig) (*AgentExecutor, func() []*prewarmingProvider) {
+	t.Helper()
+	e, _, created := newRecordingExecutor(t, ExecutorConfig{WarmPool: cfg}, newPrewarmingProvider)
+	return e, created
+}
+
+func TestWarmPool_FirstMessageUsesWarmRunner(t *testing.T) {
+	e, created := newWarmPoolExecutor(t, WarmPoolConfig{Sizes: map[string]int{"mock": 2}})
+
+	if _, err := e.CreateSession(context.Background(), "warm", session.Config{ProviderType: "mock", WorkingDir: "/tmp"}); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	waitFor(t, func() bool {
+		runners := created()
+		return len(runners) == 1 && runners[0].prewarmed.Load() == 1
+	})
+	if stats := e.WarmPoolStats(); stats.Idle["mock"] != 1 || stats.Started != 1 {
+		t.Fatalf("stats before first message = %+v", stats)
+	}
+
+	if _, err := e.SendMessage(context.Background(), "warm", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if runners := created(); len(runners) != 1 {
+		t.Fatalf("expected the warm runner to be reused, factory made %d runners", len(runners))
+	}
+	stats := e.WarmPoolStats()
+	if stats.Hits != 1 || stats.Misses != 0 || stats.HitRate != 1 || stats.Idle["mock"] != 0 {
+		t.Fatalf("stats after first message = %+v", stats)
+	}
+}
+
+func TestWarmPool_ExpiredRunnerStartsCold(t *testing.T) {
+	e, created := newWarmPoolExecutor(t, WarmPoolConfig{
+		Sizes: map[string]int{"mock": 1},
+		TTL:   20 * time.Millisecond,
+	})
+
+	if _, err := e.CreateSession(context.Background(), "cold", session.Config{ProviderType: "mock", WorkingDir: "/tmp"}); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	waitFor(t, func() bool { return e.WarmPoolStats().Expired == 1 })
+	waitFor(t, func() bool {
+		return created()[0].Status().State == session.StateStopped
+	})
+
+	if _, err := e.SendMessage(context.Background(), "cold", "hello", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if runners := created(); len(runners) != 2 {
+		t.Fatalf("expected a cold runner, factory made %d runners", len(runners))
+	}
+	if stats := e.WarmPoolStats(); stats.Hits != 0 || stats.Misses != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestWarmPool_EvictsOldestWhenFull(t *testing.T) {
+	e, created := newWarmPoolExecutor(t, WarmPoolConfig{Sizes: map[string]int{"mock": 1}})
+
+	for _, id := range []string{"first", "second"} {
+		if _, err := e.CreateSession(context.Background(), id, session.Config{ProviderType: "mock", WorkingDir: "/tmp"}); err != nil {
+			t.Fatalf("CreateSession(%s) failed: %v", id, err)
+		}
+	}
+	if stats := e.WarmPoolStats(); stats.Evicted != 1 || stats.Idle["mock"] != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	waitFor(t, func() bool {
+		return created()[0].Status().State == session.StateStopped
+	})
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// DefaultWatchInterval is how often project watches scan for changed files.
+const DefaultWatchInterval = 5 * time.Second
+
+// WatchTrigger records a session a watch rule started, or why it could not.
+type WatchTrigger struct {
+	Rule      string
+	SessionID string
+	Files     []string
+	At        time.Time
+	Error     string
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type projectWatch struct {
+	dir   string
+	watch domain.Watch
+	// seen holds, per rule, the files its globs matched when the rule last
+	// acted. A rule has no entry until the first scan after it is set.
+	seen map[string]map[string]fileStamp
+	last map[string]WatchTrigger
+}
+
+type watchPolicies struct {
+	mu        sync.Mutex
+	byProject map[string]*projectWatch
+}
+
+func newWatchPolicies() *watchPolicies {
+	return &watchPolicies{byProject: make(map[string]*projectWatch)}
+}
+
+// SetProjectWatch starts sessions when files under dir that match the
+// watch's rules change. The first scan only records the files already
+// there. A nil watch, or one without rules, stops watching the project.
+func (e *AgentExecutor) SetProjectWatch(projectID, dir string, watch *domain.Watch) error {
+	if err := watch.Validate(); err != nil {
+		return err
+	}
+	e.watches.mu.Lock()
+	defer e.watches.mu.Unlock()
+	if watch == nil || len(watch.Rules) == 0 {
+		delete(e.watches.byProject, projectID)
+		return nil
+	}
+	pw := &projectWatch{
+		dir:   dir,
+		watch: *watch,
+		seen:  make(map[string]map[string]fileStamp),
+		last:  make(map[string]WatchTrigger),
+	}
+	if old, ok := e.watches.byProject[projectID]; ok {
+		// Keep the history of rules that are still there.
+		for _, rule := range watch.Rules {
+			if t, ok := old.last[rule.Name]; ok {
+				pw.last[rule.Name] = t
+			}
+		}
+	}
+	e.watches.byProject[projectID] = pw
+	return nil
+}
+
+// ProjectWatchTriggers returns the latest trigger of each of the project's
+// watch rules that has fired.
+func (e *AgentExecutor) ProjectWatchTriggers(projectID string) []WatchTrigger {
+	e.watches.mu.Lock()
+	defer e.watches.mu.Unlock()
+	pw, ok := e.watches.byProject[projectID]
+	if !ok {
+		return nil
+	}
+	triggers := make([]WatchTrigger, 0, len(pw.last))
+	for _, rule := range pw.watch.Rules {
+		if t, ok := pw.last[rule.Name]; ok {
+			triggers = append(triggers, t)
+		}
+	}
+	return triggers
+}
+
+func (e *AgentExecutor) startWatchMode() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+				e.ScanWatches(e.ctx)
+			}
+		}
+	}()
+}
+
+// ScanWatches checks every watched project for changed files and starts a
+// session for each rule whose files changed. Changes seen while the rule's
+// previous session is still busy wait for a later scan. It returns the
+// triggers of this scan.
+func (e *AgentExecutor) ScanWatches(ctx context.Context) []WatchTrigger {
+	if e.draining.Load() {
+		return nil
+	}
+	e.watches.mu.Lock()
+	defer e.watches.mu.Unlock()
+
+	var triggers []WatchTrigger
+	for _, projectID := range slices.Sorted(maps.Keys(e.watches.byProject)) {
+		pw := e.watches.byProject[projectID]
+		files, err := scanWatchFiles(pw.dir, pw.watch.Rules)
+		if err != nil {
+			log.Printf("watch %s: %v", projectID, err)
+			continue
+		}
+		for _, rule := range pw.watch.Rules {
+			current := make(map[string]fileStamp)
+			for rel, stamp := range files {
+				if rule.Matches(rel) {
+					current[rel] = stamp
+				}
+			}
+			previous, primed := pw.seen[rule.Name]
+			if !primed {
+				pw.seen[rule.Name] = current
+				continue
+			}
+			change := diffWatchFiles(previous, current)
+			if change.Empty() {
+				continue
+			}
+			if last, ok := pw.last[rule.Name]; ok && e.watchSessionBusy(last.SessionID) {
+				continue
+			}
+			change.Rule = rule.Name
+			trigger := e.triggerWatchRule(ctx, projectID, pw.dir, rule, change)
+			pw.seen[rule.Name] = current
+			pw.last[rule.Name] = trigger
+			triggers = append(triggers, trigger)
+		}
+	}
+	return triggers
+}
+
+// scanWatchFiles stamps the regular files under dir that any rule matches,
+// keyed by slash-separated path relative to dir. Only the directories the
+// globs are rooted in are walked, and .git directories are skipped.
+func scanWatchFiles(dir string, rules []domain.WatchRule) (map[string]fileStamp, error) {
+	roots := make(map[string]bool)
+	for _, rule := range rules {
+		for _, glob := range rule.Globs {
+			roots[domain.WatchRoot(glob)] = true
+		}
+	}
+
+	files := make(map[string]fileStamp)
+	for root := range roots {
+		err := filepath.WalkDir(filepath.Join(dir, filepath.FromSlash(root)), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if _, ok := files[rel]; ok || !slices.ContainsFunc(rules, func(r domain.WatchRule) bool { return r.Matches(rel) }) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			files[rel] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func diffWatchFiles(previous, current map[string]fileStamp) domain.WatchChange {
+	var change domain.WatchChange
+	for _, rel := range slices.Sorted(maps.Keys(current)) {
+		old, ok := previous[rel]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, rel)
+		case old != current[rel]:
+			change.Modified = append(change.Modified, rel)
+		}
+	}
+	for _, rel := range slices.Sorted(maps.Keys(previous)) {
+		if _, ok := current[rel]; !ok {
+			change.Removed = append(change.Removed, rel)
+		}
+	}
+	return change
+}
+
+func (e *AgentExecutor) watchSessionBusy(id string) bool {
+	if id == "" {
+		return false
+	}
+	sess, err := e.GetSession(id)
+	return err == nil && sess.GetState() != domain.SessionStateIdle
+}
+
+// triggerWatchRule starts a new session of the rule with its prompt
+// rendered for change. The session is resolved like one created through
+// the API, so it gets its provider config, agent and project settings.
+func (e *AgentExecutor) triggerWatchRule(ctx context.Context, projectID, dir string, rule domain.WatchRule, change domain.WatchChange) WatchTrigger {
+	trigger := WatchTrigger{Rule: rule.Name, Files: change.Files(), At: time.Now().UTC()}
+	err := func() error {
+		prompt, err := rule.RenderPrompt(change)
+		if err != nil {
+			return err
+		}
+		config, err := e.ResolveSessionRequest(SessionRequest{
+			ProviderType: rule.ProviderType,
+			ProviderID:   rule.ProviderID,
+			AgentID:      rule.AgentID,
+			WorkingDir:   dir,
+			ProjectID:    projectID,
+			SessionKind:  domain.SessionKindWatch,
+			Title:        "Watch: " + rule.Name,
+		})
+		if err != nil {
+			return err
+		}
+		id := newSessionID()
+		if _, err := e.CreateSession(ctx, id, config); err != nil {
+			return err
+		}
+		trigger.SessionID = id
+		_, err = e.sendMessage(ctx, id, prompt, "", "", SendMessageOptions{})
+		return err
+	}()
+	if err != nil {
+		trigger.Error = err.Error()
+		log.Printf("watch %s rule %q: %v", projectID, rule.Name, err)
+	}
+	return trigger
+}
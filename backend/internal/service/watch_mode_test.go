@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+func TestAgentExecutor_ScanWatchesTriggersRuns(t *testing.T) {
+	var mu sync.Mutex
+	providers := map[string]*mockProvider{}
+	agents := storage.NewAgentConfigStorage(t.TempDir())
+	if err := agents.Save(storage.AgentConfig{ID: "triager", Name: "triager", SystemPrompt: "You triage issues."}); err != nil {
+		t.Fatal(err)
+	}
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			providers[sessionID] = newMockProvider()
+			return providers[sessionID], nil
+		},
+		OperationTimeout: 5 * time.Second,
+		AgentConfigs:     agents,
+	})
+	defer executor.Shutdown(context.Background())
+
+	dir := t.TempDir()
+	write := func(rel string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(rel), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("issues/old.md")
+	if err := executor.SetProjectWatch("proj", dir, &domain.Watch{Rules: []domain.WatchRule{{
+		Name:         "issues",
+		Globs:        []string{"issues/*.md"},
+		Prompt:       "Triage{{range .Added}} {{.}}{{end}}",
+		ProviderType: "test",
+		AgentID:      "triager",
+	}}}); err != nil {
+		t.Fatalf("SetProjectWatch failed: %v", err)
+	}
+	if triggers := executor.ScanWatches(context.Background()); len(triggers) != 0 {
+		t.Fatalf("the first scan must only record existing files, got %+v", triggers)
+	}
+
+	write("issues/a.md")
+	write("issues/notes.txt")
+	triggers := executor.ScanWatches(context.Background())
+	if len(triggers) != 1 || triggers[0].Error != "" || !slices.Equal(triggers[0].Files, []string{"issues/a.md"}) {
+		t.Fatalf("expected one trigger for issues/a.md, got %+v", triggers)
+	}
+	sess, err := executor.GetSession(triggers[0].SessionID)
+	if err != nil {
+		t.Fatalf("GetSession failed: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	if sess.Kind != domain.SessionKindWatch || sess.ProjectID != "proj" || sess.WorkingDir != dir || sess.AgentID != "triager" {
+		t.Errorf("unexpected session %s/%s/%s/%s", sess.Kind, sess.ProjectID, sess.WorkingDir, sess.AgentID)
+	}
+	if !strings.Contains(sess.PromptPrefix, "You triage issues.") {
+		t.Errorf("expected the agent's system prompt, got %q", sess.PromptPrefix)
+	}
+	if got := lastUserMessage(sess); got != "Triage issues/a.md" {
+		t.Errorf("expected the rendered prompt, got %q", got)
+	}
+
+	write("issues/b.md")
+	if triggers := executor.ScanWatches(context.Background()); len(triggers) != 0 {
+		t.Fatalf("expected changes to wait while the rule's session runs, got %+v", triggers)
+	}
+	mu.Lock()
+	close(providers[sess.ID].events)
+	mu.Unlock()
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateIdle })
+
+	triggers = executor.ScanWatches(context.Background())
+	if len(triggers) != 1 || !slices.Equal(triggers[0].Files, []string{"issues/b.md"}) {
+		t.Fatalf("expected the pending change to trigger, got %+v", triggers)
+	}
+	if last := executor.ProjectWatchTriggers("proj"); len(last) != 1 || last[0].SessionID != triggers[0].SessionID {
+		t.Errorf("expected the latest trigger to be reported, got %+v", last)
+	}
+}
+
+func TestAgentExecutor_SetProjectWatchRejectsInvalidRules(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	for _, rule := range []domain.WatchRule{
+		{Globs: []string{"*.md"}, ProviderType: "test"},
+		{Name: "escape", Globs: []string{"../*.md"}, ProviderType: "test"},
+		{Name: "prompt", Globs: []string{"*.md"}, ProviderType: "test", Prompt: "{{.Nope"},
+	} {
+		if err := executor.SetProjectWatch("proj", t.TempDir(), &domain.Watch{Rules: []domain.WatchRule{rule}}); err == nil {
+			t.Errorf("expected %+v to be rejected", rule)
+		}
+	}
+}
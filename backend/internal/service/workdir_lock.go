@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrWorkingDirBusy is returned when the working-directory lock is enabled and
+// another session is already running in the requested directory.
+var ErrWorkingDirBusy = errors.New("working directory is in use by another session")
+
+// WorkingDirConflictError identifies the session holding a working directory.
+type WorkingDirConflictError struct {
+	WorkingDir string
+	SessionID  string
+}
+
+func (e *WorkingDirConflictError) Error() string {
+	return fmt.Sprintf("%s: %s is used by session %s", ErrWorkingDirBusy, e.WorkingDir, e.SessionID)
+}
+
+func (e *WorkingDirConflictError) Unwrap() error {
+	return ErrWorkingDirBusy
+}
+
+// worktreeIsolated reports whether a session runs in its own worktree and can
+// therefore share a working directory with other sessions.
+func worktreeIsolated(custom map[string]any) bool {
+	isolated, _ := custom["worktree_isolation"].(bool)
+	return isolated
+}
+
+// workingDirConflict returns the conflict, if any, that prevents the session
+// from starting a run in workingDir. Callers must hold e.mu.
+func (e *AgentExecutor) workingDirConflict(id, workingDir string, custom map[string]any) error {
+	if !e.workingDirLock || workingDir == "" || worktreeIsolated(custom) {
+		return nil
+	}
+	dir := filepath.Clean(workingDir)
+	for otherID, sc := range e.sessions {
+		if otherID == id || sc.getRun() == nil {
+			continue
+		}
+		if worktreeIsolated(sc.session.ProviderCustom) || sc.session.WorkingDir == "" {
+			continue
+		}
+		if sameDir(sc.session.WorkingDir, dir) {
+			return &WorkingDirConflictError{WorkingDir: dir, SessionID: otherID}
+		}
+	}
+	return nil
+}
+
+// sameDir reports whether a and b name the same directory. Windows paths are
+// case-insensitive.
+func sameDir(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
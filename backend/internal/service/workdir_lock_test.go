@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_WorkingDirLock(t *testing.T) {
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     newMockStorage(),
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return newMockProvider(), nil
+		},
+		OperationTimeout: 5 * time.Second,
+		WorkingDirLock:   true,
+	})
+	defer executor.Shutdown(context.Background())
+	ctx := context.Background()
+
+	create := func(id, dir string, custom map[string]any) {
+		t.Helper()
+		if _, err := executor.CreateSession(ctx, id, session.Config{ProviderType: "mock", WorkingDir: dir, Custom: custom}); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	create("first", "/tmp/repo", nil)
+	create("second", "/tmp/repo/", nil)
+	create("isolated", "/tmp/repo", map[string]any{"worktree_isolation": true})
+	create("elsewhere", "/tmp/other", nil)
+
+	if _, err := executor.SendMessage(ctx, "first", "hello", "", ""); err != nil {
+		t.Fatalf("first SendMessage: %v", err)
+	}
+
+	_, err := executor.SendMessage(ctx, "second", "hello", "", "")
+	var conflict *WorkingDirConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrWorkingDirBusy) {
+		t.Fatalf("expected working dir conflict, got %v", err)
+	}
+	if conflict.SessionID != "first" || conflict.WorkingDir != "/tmp/repo" {
+		t.Fatalf("conflict = %+v, want session first in /tmp/repo", conflict)
+	}
+
+	if _, err := executor.SendMessage(ctx, "isolated", "hello", "", ""); err != nil {
+		t.Fatalf("isolated session should bypass the lock: %v", err)
+	}
+	if _, err := executor.SendMessage(ctx, "elsewhere", "hello", "", ""); err != nil {
+		t.Fatalf("session in another directory should start: %v", err)
+	}
+}
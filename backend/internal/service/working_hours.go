@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+	"github.com/ricochet1k/orbitmesh/internal/storage"
+)
+
+// DefaultWorkingHoursInterval is how often working hours are enforced.
+const DefaultWorkingHoursInterval = time.Minute
+
+// workingHoursResumePrompt continues a run suspended outside working hours
+// on a provider that cannot resume runs.
+const workingHoursResumePrompt = "Working hours have started again. Continue the task where you left off."
+
+type workingHoursPolicies struct {
+	mu        sync.RWMutex
+	byProject map[string]domain.WorkingHours
+}
+
+func newWorkingHoursPolicies() *workingHoursPolicies {
+	return &workingHoursPolicies{byProject: make(map[string]domain.WorkingHours)}
+}
+
+// SetProjectWorkingHours limits when the project's sessions may run. Runs
+// outside the window are suspended and resumed when it opens; a nil hours
+// lifts the limit.
+func (e *AgentExecutor) SetProjectWorkingHours(projectID string, hours *domain.WorkingHours) error {
+	if err := hours.Validate(); err != nil {
+		return err
+	}
+	e.workingHours.mu.Lock()
+	defer e.workingHours.mu.Unlock()
+	if hours == nil {
+		delete(e.workingHours.byProject, projectID)
+	} else {
+		e.workingHours.byProject[projectID] = *hours
+	}
+	return nil
+}
+
+func (e *AgentExecutor) workingHoursFor(sess *domain.Session) (domain.WorkingHours, bool) {
+	if sess.ProjectID == "" {
+		return domain.WorkingHours{}, false
+	}
+	e.workingHours.mu.RLock()
+	hours, ok := e.workingHours.byProject[sess.ProjectID]
+	e.workingHours.mu.RUnlock()
+	if !ok || !hours.Applies(sess.Kind) {
+		return domain.WorkingHours{}, false
+	}
+	return hours, true
+}
+
+// checkWorkingHours rejects starting a run of sess outside its project's
+// working hours.
+func (e *AgentExecutor) checkWorkingHours(sess *domain.Session, now time.Time) error {
+	hours, ok := e.workingHoursFor(sess)
+	if !ok || hours.Contains(now) {
+		return nil
+	}
+	return fmt.Errorf("%w: outside the project's working hours until %s", ErrInvalidState, hours.NextStart(now).Format(time.RFC3339))
+}
+
+func (e *AgentExecutor) startWorkingHoursEnforcer() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.workingHoursInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case now := <-ticker.C:
+				e.EnforceWorkingHours(e.ctx, now)
+			}
+		}
+	}()
+}
+
+// EnforceWorkingHours suspends the running sessions that are outside their
+// project's working hours at now, and resumes the sessions it suspended
+// whose window has opened. It returns the IDs of both.
+func (e *AgentExecutor) EnforceWorkingHours(ctx context.Context, now time.Time) (suspended, resumed []string) {
+	e.mu.RLock()
+	contexts := make([]*sessionContext, 0, len(e.sessions))
+	for _, sc := range e.sessions {
+		contexts = append(contexts, sc)
+	}
+	e.mu.RUnlock()
+
+	for _, sc := range contexts {
+		hours, ok := e.workingHoursFor(sc.session)
+		if !ok || hours.Contains(now) {
+			continue
+		}
+		run := sc.getRun()
+		if run == nil || sc.session.GetState() != domain.SessionStateRunning {
+			continue
+		}
+		if e.suspendForWorkingHours(sc, run, hours.NextStart(now)) {
+			suspended = append(suspended, sc.session.ID)
+		}
+	}
+
+	for _, sess := range e.ListSessions() {
+		hours, ok := e.workingHoursFor(sess)
+		if !ok || !hours.Contains(now) || sess.GetState() != domain.SessionStateSuspended {
+			continue
+		}
+		started, err := e.resumeAfterWorkingHours(ctx, sess.ID)
+		if err != nil {
+			log.Printf("session %s: resuming after working hours: %v", sess.ID, err)
+		}
+		if started {
+			resumed = append(resumed, sess.ID)
+		}
+	}
+	return suspended, resumed
+}
+
+// suspendForWorkingHours stops the run and suspends the session with a
+// resume token that stays valid until after the next window opens.
+func (e *AgentExecutor) suspendForWorkingHours(sc *sessionContext, run *session.Run, until time.Time) bool {
+	if sc.getRun() != run {
+		return false
+	}
+
+	expires := until.Add(e.resumeTokenTTL)
+	if minExpiry := time.Now().Add(e.resumeTokenTTL); expires.Before(minExpiry) {
+		expires = minExpiry
+	}
+	e.updateRunAttempt(sc, func(a *storage.RunAttemptMetadata) {
+		a.WaitKind = domain.WaitKindWorkingHours
+		a.WaitRef = until.UTC().Format(time.RFC3339)
+		a.ResumeTokenID = e.mintResumeTokenUntil(a, expires)
+		a.HeartbeatAt = time.Now().UTC()
+	})
+
+	run.Cancel()
+	if err := run.Session.Kill(); err != nil {
+		log.Printf("session %s: failed to stop run outside working hours: %v", sc.session.ID, err)
+	}
+
+	resumesAt := until.Format("Mon 15:04 MST")
+	e.closeTerminalHub(sc.session.ID)
+	e.appendNotice(sc.session, domain.MessageKindSystem, domain.NewNotice(domain.NoticeWorkingHoursSuspended, "resumes_at", resumesAt), time.Now())
+	e.finalizeRunAttempt(sc, "interrupted", "outside working hours")
+	e.transitionWithSave(sc, domain.SessionStateSuspended, domain.NewNotice(domain.NoticeWaitWorkingHours, "resumes_at", resumesAt))
+	return true
+}
+
+// resumeAfterWorkingHours redeems the resume token of a session suspended
+// outside working hours and continues its run.
+func (e *AgentExecutor) resumeAfterWorkingHours(ctx context.Context, id string) (bool, error) {
+	return e.resumeWaitingRun(ctx, id, domain.WaitKindWorkingHours,
+		domain.NewNotice(domain.NoticeWorkingHoursStarted), domain.NewNotice(domain.NoticeStatusWorkingHoursStarted), workingHoursResumePrompt)
+}
+
+// resumeWaitingRun redeems the resume token of a session whose run the
+// server suspended with waitKind, and continues the run: in place when the
+// provider can resume runs, and by sending prompt otherwise. It reports
+// false without an error when the session is not suspended that way.
+func (e *AgentExecutor) resumeWaitingRun(ctx context.Context, id, waitKind string, notice, status domain.Notice, prompt string) (bool, error) {
+	attempt, err := e.latestPersistedAttempt(id)
+	if err != nil || attempt == nil || attempt.WaitKind != waitKind {
+		return false, err
+	}
+	sc, err := e.ensureSessionContext(id)
+	if err != nil {
+		return false, err
+	}
+	// The stopped run may still be winding down; try again on the next
+	// pass.
+	if sc.session.GetState() != domain.SessionStateSuspended || sc.getRun() != nil {
+		return false, nil
+	}
+	if err := e.validateAndConsumeResumeToken(id, attempt.ResumeTokenID, attempt); err != nil {
+		return false, err
+	}
+
+	attempt.WaitKind = ""
+	attempt.WaitRef = ""
+	attempt.ResumeTokenID = ""
+	if err := e.attemptStorage.SaveRunAttempt(attempt); err != nil {
+		return false, fmt.Errorf("failed to clear waiting metadata: %w", err)
+	}
+	sc.amMu.Lock()
+	if sc.attempt != nil && sc.attempt.AttemptID == attempt.AttemptID {
+		sc.attempt.WaitKind = ""
+		sc.attempt.WaitRef = ""
+		sc.attempt.ResumeTokenID = ""
+	}
+	sc.amMu.Unlock()
+
+	e.appendNotice(sc.session, domain.MessageKindSystem, notice, time.Now())
+	e.transitionWithSave(sc, domain.SessionStateIdle, status)
+
+	providerID := sc.session.PreferredProviderID
+	_, err = e.startRunWithMessage(ctx, id, sc.session, "", providerID, "", SendMessageOptions{resume: true})
+	if errors.Is(err, ErrResumeUnsupported) {
+		_, err = e.startRunWithMessage(ctx, id, sc.session, prompt, providerID, "", SendMessageOptions{})
+	}
+	return err == nil, err
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+func TestAgentExecutor_EnforceWorkingHours(t *testing.T) {
+	store := newMockStorage()
+	executor := NewAgentExecutor(ExecutorConfig{
+		Storage:     store,
+		Broadcaster: NewEventBroadcaster(100),
+		ProviderFactory: func(providerType, sessionID string, config session.Config) (session.Session, error) {
+			return newMockProvider(), nil
+		},
+		OperationTimeout: 5 * time.Second,
+	})
+	defer executor.Shutdown(context.Background())
+
+	sess, err := executor.CreateSession(context.Background(), "overnight", session.Config{ProviderType: "test", WorkingDir: "/tmp", ProjectID: "proj"})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	// A two hour window around the current time, every day.
+	now := time.Now().UTC()
+	hours := &domain.WorkingHours{
+		Days:     []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"},
+		Start:    now.Add(-time.Hour).Format("15:04"),
+		End:      now.Add(time.Hour).Format("15:04"),
+		TimeZone: "UTC",
+	}
+	if err := executor.SetProjectWorkingHours("proj", hours); err != nil {
+		t.Fatalf("SetProjectWorkingHours failed: %v", err)
+	}
+
+	if _, err := executor.SendMessage(context.Background(), sess.ID, "work on it", "", ""); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+
+	night := now.Add(12 * time.Hour)
+	suspended, _ := executor.EnforceWorkingHours(context.Background(), night)
+	if len(suspended) != 1 || sess.GetState() != domain.SessionStateSuspended {
+		t.Fatalf("expected the run to be suspended at night, got %v in state %s", suspended, sess.GetState())
+	}
+	attempt := waitForRunAttempt(t, store, sess.ID, true)
+	if attempt.WaitKind != domain.WaitKindWorkingHours || attempt.ResumeTokenID == "" {
+		t.Fatalf("expected a working hours wait with a resume token, got %+v", attempt)
+	}
+	token, err := store.LoadResumeToken(attempt.ResumeTokenID)
+	if err != nil || token.ExpiresAt.Before(hours.NextStart(night)) {
+		t.Fatalf("resume token must outlive the night, got %+v (%v)", token, err)
+	}
+
+	if _, resumed := executor.EnforceWorkingHours(context.Background(), night.Add(time.Minute)); len(resumed) != 0 {
+		t.Fatalf("resumed %v before working hours", resumed)
+	}
+	sc, _ := executor.ensureSessionContext(sess.ID)
+	waitFor(t, func() bool { return sc.getRun() == nil })
+	if _, resumed := executor.EnforceWorkingHours(context.Background(), time.Now()); len(resumed) != 1 {
+		t.Fatalf("expected the session to resume in working hours, got %v", resumed)
+	}
+	waitFor(t, func() bool { return sess.GetState() == domain.SessionStateRunning })
+	if last := lastUserMessage(sess); last != workingHoursResumePrompt {
+		t.Errorf("expected the run to continue with the resume prompt, got %q", last)
+	}
+	if token, _ := store.LoadResumeToken(attempt.ResumeTokenID); token.ConsumedAt == nil {
+		t.Error("expected the resume token to be consumed")
+	}
+}
+
+func TestAgentExecutor_WorkingHoursRejectRunsOutsideWindow(t *testing.T) {
+	executor, _ := createTestExecutor(newMockProvider())
+	defer executor.Shutdown(context.Background())
+
+	now := time.Now().UTC()
+	if err := executor.SetProjectWorkingHours("proj", &domain.WorkingHours{
+		Days:         []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"},
+		Start:        now.Add(2 * time.Hour).Format("15:04"),
+		End:          now.Add(3 * time.Hour).Format("15:04"),
+		TimeZone:     "UTC",
+		SessionKinds: []string{"nightly"},
+	}); err != nil {
+		t.Fatalf("SetProjectWorkingHours failed: %v", err)
+	}
+
+	gated, _ := executor.CreateSession(context.Background(), "gated", session.Config{ProviderType: "test", WorkingDir: "/tmp", ProjectID: "proj", SessionKind: "nightly"})
+	if _, err := executor.SendMessage(context.Background(), gated.ID, "hi", "", ""); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("expected a run outside working hours to be rejected, got %v", err)
+	}
+	other, _ := executor.CreateSession(context.Background(), "other", session.Config{ProviderType: "test", WorkingDir: "/tmp", ProjectID: "proj"})
+	if _, err := executor.SendMessage(context.Background(), other.ID, "hi", "", ""); err != nil {
+		t.Errorf("sessions of other kinds must not be limited, got %v", err)
+	}
+
+	if err := executor.SetProjectWorkingHours("proj", &domain.WorkingHours{Start: "9am", End: "17:00"}); err == nil {
+		t.Error("expected an invalid start time to be rejected")
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+	"github.com/ricochet1k/orbitmesh/internal/session"
+)
+
+// ErrInvalidWorkspace rejects a workspace that cannot be checked out.
+var ErrInvalidWorkspace = errors.New("invalid workspace")
+
+// createWorkspace checks spec.Repo out for session id under the workspace
+// directory, on a new branch started from spec.BaseRef (HEAD when empty).
+func (e *AgentExecutor) createWorkspace(ctx context.Context, id string, spec domain.Workspace) (*domain.Workspace, error) {
+	ws := spec
+	switch ws.Mode {
+	case domain.WorkspaceWorktree, domain.WorkspaceClone:
+	default:
+		return nil, fmt.Errorf("%w: mode must be %s or %s", ErrInvalidWorkspace, domain.WorkspaceWorktree, domain.WorkspaceClone)
+	}
+	if err := validateWorkspaceRepo(ws.Repo); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+	}
+	if strings.HasPrefix(ws.BaseRef, "-") || strings.HasPrefix(ws.Branch, "-") {
+		return nil, fmt.Errorf("%w: base_ref and branch may not start with '-'", ErrInvalidWorkspace)
+	}
+	if ws.Branch == "" {
+		ws.Branch = "orbitmesh/session/" + id
+	}
+	ref := ws.BaseRef
+	if ref == "" {
+		ref = "HEAD"
+	}
+	ws.Path = filepath.Join(e.workspaceDir, id)
+	if err := os.MkdirAll(e.workspaceDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create workspace directory: %w", err)
+	}
+
+	switch ws.Mode {
+	case domain.WorkspaceWorktree:
+		base, err := resolveCommit(ctx, ws.Repo, ref)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+		}
+		if err := addWorktree(ctx, ws.Repo, ws.Branch, ws.Path, base); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+		}
+		ws.BaseRef = base
+	case domain.WorkspaceClone:
+		if _, err := runGit(ctx, "", "clone", "--quiet", "--", ws.Repo, ws.Path); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+		}
+		base, err := resolveCommit(ctx, ws.Path, ref)
+		if err != nil {
+			// A branch of the cloned repository is only a remote branch
+			// of the clone.
+			base, err = resolveCommit(ctx, ws.Path, "origin/"+ref)
+		}
+		if err == nil {
+			_, err = runGit(ctx, ws.Path, "checkout", "--quiet", "-b", ws.Branch, base)
+		}
+		if err != nil {
+			os.RemoveAll(ws.Path)
+			return nil, fmt.Errorf("%w: %v", ErrInvalidWorkspace, err)
+		}
+		ws.BaseRef = base
+	}
+	ws.CreatedAt = time.Now().UTC()
+	return &ws, nil
+}
+
+// removeWorkspace deletes a session's checkout. A worktree's branch goes
+// with it unless the session committed to it; a clone goes whole, commits
+// and all. Checkouts outside the workspace directory, which only a
+// tampered session could name, are left alone.
+func (e *AgentExecutor) removeWorkspace(ctx context.Context, ws *domain.Workspace) {
+	if ws == nil || ws.Path == "" {
+		return
+	}
+	if rel, err := filepath.Rel(e.workspaceDir, ws.Path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		log.Printf("workspace: not removing %s: outside %s", ws.Path, e.workspaceDir)
+		return
+	}
+	if ws.Mode == domain.WorkspaceWorktree {
+		if err := removeWorktree(ctx, ws.Repo, ws.Path); err != nil {
+			log.Printf("workspace: removing worktree %s: %v", ws.Path, err)
+		}
+		ahead, err := runGit(ctx, ws.Repo, "rev-list", "--count", ws.BaseRef+".."+ws.Branch)
+		if err == nil && ahead == "0" {
+			_, _ = runGit(ctx, ws.Repo, "branch", "-D", ws.Branch)
+		} else if err == nil {
+			log.Printf("workspace: keeping branch %s with %s commits", ws.Branch, ahead)
+		}
+	}
+	if err := os.RemoveAll(ws.Path); err != nil {
+		log.Printf("workspace: removing %s: %v", ws.Path, err)
+	}
+}
+
+// workspaceConfig points config at its workspace. Sessions in workspaces of
+// their own never share a working directory, so the working directory lock
+// is waived.
+func workspaceConfig(config session.Config, ws *domain.Workspace) session.Config {
+	config.Workspace = ws
+	config.WorkingDir = ws.Path
+	config.Custom = maps.Clone(config.Custom)
+	if config.Custom == nil {
+		config.Custom = map[string]any{}
+	}
+	config.Custom["worktree_isolation"] = true
+	return config
+}
+
+// workspaceRepoSchemes are the URL schemes a workspace may clone from.
+// Others, file:// and the ext:: style transports among them, could read
+// the server's files or run commands on it.
+var workspaceRepoSchemes = []string{"https", "ssh", "git"}
+
+// validateWorkspaceRepo checks that repo is an absolute local path, an
+// scp-like user@host:path or a URL of one of workspaceRepoSchemes.
+func validateWorkspaceRepo(repo string) error {
+	switch {
+	case repo == "":
+		return fmt.Errorf("repo is required")
+	case strings.HasPrefix(repo, "-"):
+		return fmt.Errorf("repo may not start with '-'")
+	case strings.Contains(repo, "::"):
+		return fmt.Errorf("repo may not name a transport helper")
+	}
+	if scheme, _, ok := strings.Cut(repo, "://"); ok {
+		if !slices.Contains(workspaceRepoSchemes, strings.ToLower(scheme)) {
+			return fmt.Errorf("repo scheme %q is not allowed; use one of %s", scheme, strings.Join(workspaceRepoSchemes, ", "))
+		}
+		return nil
+	}
+	if filepath.IsAbs(repo) {
+		return nil
+	}
+	// scp-like syntax has a colon before any slash.
+	if host, _, ok := strings.Cut(repo, ":"); ok && host != "" && !strings.Contains(host, "/") {
+		return nil
+	}
+	return fmt.Errorf("repo must be an absolute path or a %s URL", strings.Join(workspaceRepoSchemes, ", "))
+}
+
+// addWorktree checks base out at path as a worktree of repo, on a new
+// branch.
+func addWorktree(ctx context.Context, repo, branch, path, base string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create worktree directory: %w", err)
+	}
+	_, err := runGit(ctx, repo, "worktree", "add", "-b", branch, "--", path, base)
+	return err
+}
+
+// removeWorktree removes the worktree of repo at path, discarding its
+// changes. Its branch is left for the caller.
+func removeWorktree(ctx context.Context, repo, path string) error {
+	_, err := runGit(ctx, repo, "worktree", "remove", "--force", "--", path)
+	return err
+}
+
+func resolveCommit(ctx context.Context, dir, ref string) (string, error) {
+	return runGit(ctx, dir, "rev-parse", "--verify", ref+"^{commit}")
+}
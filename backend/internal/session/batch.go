@@ -0,0 +1,21 @@
+package session
+
+import (
+	"context"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// BatchRunner is implemented by runners that can execute a non-interactive
+// run through a provider's batched API, trading latency for cost. The
+// executor uses it for low-priority messages instead of SendInput.
+type BatchRunner interface {
+	// SubmitBatch queues the run remotely and returns the provider's
+	// reference for it.
+	SubmitBatch(ctx context.Context, config Config, input string) (string, error)
+
+	// PollBatch checks on a queued run. While the run is still queued it
+	// returns done == false; once finished it returns the run's events in
+	// order so they can be folded into the session's message log.
+	PollBatch(ctx context.Context, ref string) (events []domain.Event, done bool, err error)
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Feature flags are provider-neutral switches on a session. Each provider
+// translates them into its own CLI flags or settings, so callers need not
+// know every provider's Custom keys. Explicit Custom keys still win where a
+// provider has one for the same setting.
+const (
+	// FeatureWebSearch lets the agent search the web.
+	FeatureWebSearch = "enable_web_search"
+	// FeatureNetworkTools lets the agent use tools that reach the network,
+	// such as fetching URLs. Turning it off also turns off web search.
+	FeatureNetworkTools = "allow_network_tools"
+	// FeatureVerboseTools asks the provider for detailed tool call output.
+	FeatureVerboseTools = "verbose_tools"
+)
+
+var knownFeatures = map[string]bool{
+	FeatureWebSearch:    true,
+	FeatureNetworkTools: true,
+	FeatureVerboseTools: true,
+}
+
+// FeatureNames returns the known feature flags, sorted.
+func FeatureNames() []string {
+	names := make([]string, 0, len(knownFeatures))
+	for name := range knownFeatures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateFeatures reports the first unknown flag in features.
+func ValidateFeatures(features map[string]bool) error {
+	names := make([]string, 0, len(features))
+	for name := range features {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !knownFeatures[name] {
+			return fmt.Errorf("unknown feature flag %q", name)
+		}
+	}
+	return nil
+}
+
+// Feature returns the flag's value and whether it was set at all; unset
+// flags leave the provider's default behavior alone.
+func (c Config) Feature(name string) (on, set bool) {
+	on, set = c.Features[name]
+	return on, set
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"text/template"
+)
+
+// MCPEnv is what the env values of a session's MCP servers are rendered
+// with for each run. Values are text/template templates, e.g.
+// "{{.APIBaseURL}}"; values without "{{" are used as they are.
+type MCPEnv struct {
+	SessionID   string
+	ProjectID   string
+	ProjectPath string
+	WorkingDir  string
+	// APIBaseURL is where agent processes reach the OrbitMesh API.
+	APIBaseURL string
+	// APIToken is an API token scoped to the session, valid until the run
+	// ends.
+	APIToken string
+}
+
+// ValidateMCPServers checks that the env values of servers are valid
+// templates.
+func ValidateMCPServers(servers []MCPServerConfig) error {
+	_, err := RenderMCPServers(servers, MCPEnv{})
+	return err
+}
+
+// RenderMCPServers returns copies of servers with their env values
+// rendered with env.
+func RenderMCPServers(servers []MCPServerConfig, env MCPEnv) ([]MCPServerConfig, error) {
+	if servers == nil {
+		return nil, nil
+	}
+	out := make([]MCPServerConfig, len(servers))
+	for i, s := range servers {
+		s.Env = maps.Clone(s.Env)
+		for key, value := range s.Env {
+			rendered, err := renderMCPEnvValue(value, env)
+			if err != nil {
+				return nil, fmt.Errorf("mcp server %q env %s: %w", s.Name, key, err)
+			}
+			s.Env[key] = rendered
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+func renderMCPEnvValue(value string, env MCPEnv) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	tmpl, err := template.New("env").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, env); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
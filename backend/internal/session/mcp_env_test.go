@@ -0,0 +1,28 @@
+package session
+
+import "testing"
+
+func TestRenderMCPServers(t *testing.T) {
+	servers := []MCPServerConfig{{Name: "dock", Env: map[string]string{"ID": "{{.SessionID}}", "DIR": "{{.WorkingDir}}/x"}}}
+	out, err := RenderMCPServers(servers, MCPEnv{SessionID: "s1", WorkingDir: "/w"})
+	if err != nil {
+		t.Fatalf("RenderMCPServers: %v", err)
+	}
+	if out[0].Env["ID"] != "s1" || out[0].Env["DIR"] != "/w/x" {
+		t.Fatalf("env = %v", out[0].Env)
+	}
+	if servers[0].Env["ID"] != "{{.SessionID}}" {
+		t.Fatal("the templates were modified")
+	}
+}
+
+func TestValidateMCPServers(t *testing.T) {
+	for _, value := range []string{"{{.Nope}}", "{{.SessionID", "{{template \"x\"}}"} {
+		if err := ValidateMCPServers([]MCPServerConfig{{Name: "s", Env: map[string]string{"K": value}}}); err == nil {
+			t.Errorf("ValidateMCPServers(%q) succeeded", value)
+		}
+	}
+	if err := ValidateMCPServers([]MCPServerConfig{{Name: "s", Env: map[string]string{"K": "{{.APIToken}}", "L": "plain"}}}); err != nil {
+		t.Fatalf("ValidateMCPServers: %v", err)
+	}
+}
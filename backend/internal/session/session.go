@@ -38,6 +38,7 @@ func (s State) String() string {
 }
 
 // MCPServerConfig describes an MCP server that can be attached to a session.
+// Its env values are templates rendered for each run; see MCPEnv.
 type MCPServerConfig struct {
 	Name    string
 	Command string
@@ -51,8 +52,10 @@ type Config struct {
 	AgentID        string
 	WorkingDir     string
 	ProjectID      string
+	ProjectPath    string // The project's root, if the session has a project
 	Environment    map[string]string
 	SystemPrompt   string
+	ProjectContext string // With SystemPrompt, forms the stable (cacheable) prompt prefix
 	MCPServers     []MCPServerConfig
 	Custom         map[string]any
 	TaskID         string
@@ -60,6 +63,41 @@ type Config struct {
 	SessionKind    string
 	Title          string
 	ResumeMessages []Message // Message history to resume from (for session resumption)
+	// CleanupCommands run in WorkingDir when the session is stopped, killed
+	// or cleaned up (project commands first, then agent commands).
+	CleanupCommands []string
+	// RecoveryPolicy overrides the provider's startup recovery policy.
+	RecoveryPolicy string
+	// PlanApproval stops runs after the agent's first plan until a human
+	// approves it.
+	PlanApproval bool
+	// CommandApproval holds the agent's shell commands until a human
+	// approves them; nil runs them without asking.
+	CommandApproval *domain.CommandApproval
+	// ToolApproval holds the tool calls the provider asks permission for
+	// until a human decides; nil lets them run.
+	ToolApproval *domain.ToolApproval
+	// Features are provider-neutral feature flags (see FeatureWebSearch and
+	// friends) that each provider translates to its own settings.
+	Features map[string]bool
+	// ConversationID continues the provider's own conversation from an
+	// earlier run, as it reported in a domain.MetadataKeyConversationID
+	// event; empty starts a fresh conversation.
+	ConversationID string
+	// CostBudget limits what the session may spend across its runs.
+	CostBudget *domain.CostBudget
+	// Metadata holds key-value pairs external systems stamp onto the
+	// session.
+	Metadata map[string]string
+	// Workspace asks for a checkout of Workspace.Repo of the session's own,
+	// made under the executor's workspace directory and used instead of
+	// WorkingDir. Only its Mode, Repo, BaseRef and Branch are read; BaseRef
+	// and Branch may be empty.
+	Workspace *domain.Workspace
+	// GitTracking puts the session on a branch of its own in its working
+	// directory, or its workspace's branch, and records the commits made
+	// on it. Only its Branch is read, and may be empty.
+	GitTracking *domain.GitTracking
 }
 
 type Metrics struct {
@@ -77,6 +115,24 @@ type Status struct {
 	Metrics     Metrics
 }
 
+// Capabilities lists the optional operations a runner supports, so callers
+// can reject unsupported requests up front instead of discovering them
+// through a failed type assertion mid-run.
+type Capabilities struct {
+	// Suspend: the runner implements Suspendable.
+	Suspend bool `json:"suspend"`
+	// Interrupt: the current turn can be aborted without killing the runner.
+	Interrupt bool `json:"interrupt"`
+	// Steering: SendInput may be called again while a run is in progress.
+	Steering bool `json:"steering"`
+	// Terminal: the runner exposes a terminal (service.TerminalProvider).
+	Terminal bool `json:"terminal"`
+	// Images: input may carry image attachments.
+	Images bool `json:"images"`
+	// ResumeAfterRestart: the runner implements RunResumer.
+	ResumeAfterRestart bool `json:"resume_after_restart"`
+}
+
 // Session is the interface implemented by every agent runner (ACP, Claude, PTY, ADK, …).
 //
 // Lifecycle:
@@ -114,4 +170,29 @@ type Session interface {
 	// Status returns the current status of the runner.
 	// It must be thread-safe.
 	Status() Status
+
+	// Capabilities reports the optional operations the runner supports.
+	// It must not depend on whether the runner has started.
+	Capabilities() Capabilities
+}
+
+// RunResumer is implemented by runners that can continue an interrupted
+// conversation (for example from the agent's own session log) rather than
+// starting over. ResumeRun starts the runner like a first SendInput call.
+type RunResumer interface {
+	ResumeRun(ctx context.Context, config Config) (<-chan domain.Event, error)
+}
+
+// Prewarmer is implemented by runners that can pay their startup cost
+// (process boot, handshakes) before the first input arrives. After Prewarm,
+// the first SendInput with the same config only delivers the input. A
+// SendInput that races a Prewarm still in progress must wait for it.
+type Prewarmer interface {
+	Prewarm(ctx context.Context, config Config) error
+}
+
+// SystemNoter is implemented by runners that can inject an out-of-band note
+// (such as a deadline warning) into a running agent's context.
+type SystemNoter interface {
+	InjectSystemNote(ctx context.Context, note string) error
 }
@@ -35,6 +35,10 @@ type SuspensionContext struct {
 	// ToolCallID is the ID of the tool call we're waiting for, if applicable
 	ToolCallID string `json:"tool_call_id,omitempty"`
 
+	// ToolCallIDs lists every tool call we're waiting for when there are
+	// several; ToolCallID is the first of them
+	ToolCallIDs []string `json:"tool_call_ids,omitempty"`
+
 	// PendingInput contains queued messages received while suspended
 	PendingInput []string `json:"pending_input,omitempty"`
 
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/ricochet1k/orbitmesh/internal/domain"
 	"github.com/ricochet1k/orbitmesh/internal/session"
 )
 
@@ -19,6 +20,14 @@ type AgentConfig struct {
 	SystemPrompt string                    `json:"system_prompt,omitempty"`
 	MCPServers   []session.MCPServerConfig `json:"mcp_servers,omitempty"`
 	Custom       map[string]any            `json:"custom,omitempty"`
+	// CleanupCommands run in the session's working directory when a session
+	// using this agent is stopped, killed or cleaned up.
+	CleanupCommands []string `json:"cleanup_commands,omitempty"`
+	// Features are the default feature flags of sessions using this agent.
+	Features map[string]bool `json:"features,omitempty"`
+	// ToolPolicy decides the tool calls of sessions using this agent by
+	// rule.
+	ToolPolicy *domain.ToolPolicy `json:"tool_policy,omitempty"`
 }
 
 // AgentConfigStorage manages agent configurations on disk.
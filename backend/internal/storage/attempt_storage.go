@@ -32,6 +32,42 @@ type RunAttemptMetadata struct {
 	ResumeTokenID      string     `json:"resume_token_id,omitempty"`
 	HeartbeatAt        time.Time  `json:"heartbeat_at"`
 	BootID             string     `json:"boot_id,omitempty"`
+	// Waits is set when the run waits on several external tool calls at
+	// once; each has its own resume token, and ResumeTokenID is empty. The
+	// run resumes when WaitQuorum of them (all when zero) are fulfilled.
+	Waits      []RunWait `json:"waits,omitempty"`
+	WaitQuorum int       `json:"wait_quorum,omitempty"`
+	// Operations journals control requests made while this attempt was
+	// current, in sequence order.
+	Operations []ControlOperation `json:"operations,omitempty"`
+
+	// MessageID identifies the sent message that started the attempt, and
+	// Delivery whether it reached the provider: queued, delivered or failed.
+	MessageID     string `json:"message_id,omitempty"`
+	Delivery      string `json:"delivery,omitempty"`
+	DeliveryError string `json:"delivery_error,omitempty"`
+	// ContinuedFrom is the attempt this one continues after it used up its
+	// token budget.
+	ContinuedFrom string `json:"continued_from,omitempty"`
+}
+
+// RunWait is one outstanding external tool call of a multi-wait run.
+type RunWait struct {
+	Ref           string     `json:"ref"`
+	ResumeTokenID string     `json:"resume_token_id,omitempty"`
+	FulfilledAt   *time.Time `json:"fulfilled_at,omitempty"`
+}
+
+// ControlOperation is one journaled stop, cancel, kill or resume request.
+type ControlOperation struct {
+	Seq         int64     `json:"seq"`
+	Op          string    `json:"op"`
+	RequestedAt time.Time `json:"requested_at"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	// Replayed marks a repeat of an already-applied operation that was
+	// answered from the journal instead of being applied again.
+	Replayed bool `json:"replayed,omitempty"`
 }
 
 func (s *JSONFileStorage) attemptsSessionDir(sessionID string) string {
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a sensitive change made to a session, such as a
+// redaction.
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// Actor is the user who made the change.
+	Actor     string `json:"actor,omitempty"`
+	Action    string `json:"action"`
+	SessionID string `json:"session_id,omitempty"`
+	// Targets identifies what the action applied to, e.g. message IDs.
+	Targets []string `json:"targets,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// AuditLogStorage appends audit entries to a single JSONL file. Entries are
+// never rewritten.
+type AuditLogStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewAuditLogStorage creates an audit log rooted at baseDir.
+func NewAuditLogStorage(baseDir string) *AuditLogStorage {
+	return &AuditLogStorage{baseDir: baseDir}
+}
+
+func (s *AuditLogStorage) path() string {
+	return filepath.Join(s.baseDir, "audit.jsonl")
+}
+
+// Append writes one entry, stamping it with the current time if unset.
+func (s *AuditLogStorage) Append(entry AuditEntry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.baseDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return f.Sync()
+}
+
+// List returns the entries for sessionID, or every entry when it is empty,
+// oldest first.
+func (s *AuditLogStorage) List(sessionID string) ([]AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []AuditEntry{}
+	f, err := os.Open(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+		if sessionID == "" || entry.SessionID == sessionID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// BlobMinSize is the smallest payload worth moving to the blob store;
+// smaller ones stay inline, where a reference would save nothing.
+const BlobMinSize = 512
+
+// ErrBlobNotFound is returned for a blob reference with no stored blob.
+var ErrBlobNotFound = errors.New("blob not found")
+
+const blobRefPrefix = "sha256:"
+
+// BlobStore keeps payloads once by content hash under baseDir/blobs, shared
+// by every session that stores them. Each blob counts its references and
+// is deleted with the last one. Reference changes are appended to a journal
+// that is compacted when the store is first used.
+type BlobStore struct {
+	dir  string
+	mu   sync.Mutex
+	refs map[string]int64
+}
+
+// NewBlobStore creates a blob store rooted at baseDir.
+func NewBlobStore(baseDir string) *BlobStore {
+	return &BlobStore{dir: filepath.Join(baseDir, "blobs")}
+}
+
+func (b *BlobStore) journalPath() string {
+	return filepath.Join(b.dir, "refs.log")
+}
+
+func (b *BlobStore) blobPath(hash string) string {
+	return filepath.Join(b.dir, hash[:2], hash)
+}
+
+// Put stores data, or adds a reference to the identical blob already
+// stored, and returns its reference.
+func (b *BlobStore) Put(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.loadLocked(); err != nil {
+		return "", err
+	}
+
+	path := b.blobPath(hash)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return "", fmt.Errorf("failed to create blob directory: %w", err)
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, data, 0o600); err != nil {
+			return "", fmt.Errorf("failed to write blob: %w", err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			_ = os.Remove(tmp)
+			return "", fmt.Errorf("failed to write blob: %w", err)
+		}
+	} else if err != nil {
+		return "", fmt.Errorf("failed to stat blob: %w", err)
+	}
+
+	if err := b.journalLocked("+" + hash); err != nil {
+		return "", err
+	}
+	b.refs[hash]++
+	return blobRefPrefix + hash, nil
+}
+
+// Get returns the blob ref refers to.
+func (b *BlobStore) Get(ref string) ([]byte, error) {
+	hash, err := blobHash(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(b.blobPath(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, ref)
+	}
+	return data, err
+}
+
+// Release drops one reference to the blob, deleting it with the last.
+func (b *BlobStore) Release(ref string) error {
+	hash, err := blobHash(ref)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.loadLocked(); err != nil {
+		return err
+	}
+	if b.refs[hash] <= 0 {
+		return nil
+	}
+	if err := b.journalLocked("-" + hash); err != nil {
+		return err
+	}
+	b.refs[hash]--
+	if b.refs[hash] > 0 {
+		return nil
+	}
+	delete(b.refs, hash)
+	if err := os.Remove(b.blobPath(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+// References returns how many references the blob has.
+func (b *BlobStore) References(ref string) (int64, error) {
+	hash, err := blobHash(ref)
+	if err != nil {
+		return 0, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.loadLocked(); err != nil {
+		return 0, err
+	}
+	return b.refs[hash], nil
+}
+
+// loadLocked replays the reference journal on first use and rewrites it
+// with one line per live reference.
+func (b *BlobStore) loadLocked() error {
+	if b.refs != nil {
+		return nil
+	}
+	refs := make(map[string]int64)
+	f, err := os.Open(b.journalPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return fmt.Errorf("failed to open blob journal: %w", err)
+	default:
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if len(line) < 2 {
+				continue
+			}
+			switch line[0] {
+			case '+':
+				refs[line[1:]]++
+			case '-':
+				if refs[line[1:]]--; refs[line[1:]] <= 0 {
+					delete(refs, line[1:])
+				}
+			}
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read blob journal: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(b.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	var compacted strings.Builder
+	for hash, n := range refs {
+		for range n {
+			compacted.WriteString("+" + hash + "\n")
+		}
+	}
+	tmp := b.journalPath() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(compacted.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to compact blob journal: %w", err)
+	}
+	if err := os.Rename(tmp, b.journalPath()); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to compact blob journal: %w", err)
+	}
+	b.refs = refs
+	return nil
+}
+
+func (b *BlobStore) journalLocked(line string) error {
+	f, err := os.OpenFile(b.journalPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open blob journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		return fmt.Errorf("failed to write blob journal: %w", err)
+	}
+	return nil
+}
+
+func blobHash(ref string) (string, error) {
+	hash, ok := strings.CutPrefix(ref, blobRefPrefix)
+	if !ok || len(hash) != sha256.Size*2 {
+		return "", fmt.Errorf("%w: invalid reference %q", ErrBlobNotFound, ref)
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", fmt.Errorf("%w: invalid reference %q", ErrBlobNotFound, ref)
+	}
+	return hash, nil
+}
+
+// storeRaw moves raw to blobs when it is large enough, returning the
+// payload to keep inline and the blob reference.
+func storeRaw(blobs *BlobStore, raw []byte) ([]byte, string, error) {
+	if blobs == nil || len(raw) < BlobMinSize {
+		return raw, "", nil
+	}
+	ref, err := blobs.Put(raw)
+	if err != nil {
+		return nil, "", err
+	}
+	return nil, ref, nil
+}
+
+// loadRaw returns the inline payload, or the blob it refers to. A missing
+// blob yields no payload rather than failing the whole read.
+func loadRaw(blobs *BlobStore, raw []byte, ref string) []byte {
+	if ref == "" || blobs == nil {
+		return raw
+	}
+	data, err := blobs.Get(ref)
+	if err != nil {
+		return nil
+	}
+	return data
+}
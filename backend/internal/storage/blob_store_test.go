@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestBlobStore_ReferenceCounting(t *testing.T) {
+	dir := t.TempDir()
+	blobs := NewBlobStore(dir)
+
+	ref, err := blobs.Put([]byte("payload"))
+	if err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	if again, err := blobs.Put([]byte("payload")); err != nil || again != ref {
+		t.Fatalf("second put = %q, %v; want %q", again, err, ref)
+	}
+
+	// References survive a restart.
+	blobs = NewBlobStore(dir)
+	if n, err := blobs.References(ref); err != nil || n != 2 {
+		t.Fatalf("references = %d, %v; want 2", n, err)
+	}
+	if err := blobs.Release(ref); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if data, err := blobs.Get(ref); err != nil || string(data) != "payload" {
+		t.Fatalf("get = %q, %v", data, err)
+	}
+	if err := blobs.Release(ref); err != nil {
+		t.Fatalf("last release: %v", err)
+	}
+	if _, err := blobs.Get(ref); err == nil {
+		t.Fatal("expected the blob to be deleted with its last reference")
+	}
+	if _, err := blobs.Get("sha256:../../etc/passwd"); err == nil {
+		t.Fatal("expected an invalid reference to be rejected")
+	}
+}
+
+func TestJSONFileStorage_MessageLogBlobs(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewJSONFileStorage(dir)
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+	blobs := NewBlobStore(dir)
+	s.SetBlobStore(blobs)
+
+	raw := json.RawMessage(fmt.Sprintf(`{"type":"system","tools":%q}`, strings.Repeat("x", BlobMinSize)))
+	ts := time.Now().UTC()
+	for _, id := range []string{"blob-a", "blob-b"} {
+		if err := s.Save(domain.NewSession(id, "mock", "/tmp")); err != nil {
+			t.Fatalf("save %s: %v", id, err)
+		}
+		if err := s.AppendMessageLog(id, MessageProjectionAppendRaw, domain.MessageKindSystem, "init", raw, ts); err != nil {
+			t.Fatalf("append %s: %v", id, err)
+		}
+		if err := s.AppendMessageLog(id, MessageProjectionAppendRaw, domain.MessageKindOutput, "hi", json.RawMessage(`{"small":true}`), ts); err != nil {
+			t.Fatalf("append small %s: %v", id, err)
+		}
+	}
+
+	logData, err := os.ReadFile(s.messageLogPath("blob-a"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	if strings.Contains(string(logData), strings.Repeat("x", BlobMinSize)) || !strings.Contains(string(logData), `"small":true`) {
+		t.Fatalf("log = %s, want the large payload moved out and the small one inline", logData)
+	}
+	messages, err := s.ReadMessagesFromJSONL("blob-a")
+	if err != nil || len(messages) != 2 || string(messages[0].Raw) != string(raw) {
+		t.Fatalf("messages = %+v, %v; want the raw payload restored", messages, err)
+	}
+
+	var rec messageLogRecord
+	_ = json.Unmarshal([]byte(strings.SplitN(string(logData), "\n", 2)[0]), &rec)
+	if n, _ := blobs.References(rec.RawBlob); n != 2 {
+		t.Fatalf("references = %d, want 2 shared by both sessions", n)
+	}
+
+	if err := s.PurgeSession("blob-a"); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if n, _ := blobs.References(rec.RawBlob); n != 1 {
+		t.Fatalf("references after purge = %d, want 1", n)
+	}
+	if _, err := s.RedactMessageLog("blob-b", []domain.Message{{Kind: domain.MessageKindSystem, Contents: "init"}}, "[redacted]"); err != nil {
+		t.Fatalf("redact: %v", err)
+	}
+	if _, err := blobs.Get(rec.RawBlob); err == nil {
+		t.Fatal("expected the blob to be deleted once no message refers to it")
+	}
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CleanupStorage is implemented by stores that support the stale-session
+// janitor. All methods are best-effort removals of on-disk records.
+type CleanupStorage interface {
+	// ArchiveSession moves the session record, its message log and its run
+	// attempts under sessions/archive so they no longer appear in List.
+	ArchiveSession(id string) error
+	// PurgeSession permanently removes the session record, its message log
+	// and its run attempts.
+	PurgeSession(id string) error
+	ListAttemptSessionIDs() ([]string, error)
+	DeleteRunAttempts(sessionID string) error
+	ListResumeTokens() ([]*ResumeTokenMetadata, error)
+	DeleteResumeToken(tokenID string) error
+}
+
+func (s *JSONFileStorage) archiveDir() string {
+	return filepath.Join(s.baseDir, "sessions", "archive")
+}
+
+func (s *JSONFileStorage) ArchiveSession(id string) error {
+	if err := validateSessionID(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Lstat(s.sessionPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	archive := s.archiveDir()
+	if err := os.MkdirAll(filepath.Join(archive, "attempts"), 0o700); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	moves := [][2]string{
+		{s.messageLogPath(id), filepath.Join(archive, id+".messages.jsonl")},
+		{s.attemptsSessionDir(id), filepath.Join(archive, "attempts", id)},
+		// The session record moves last so a partial archive stays visible.
+		{s.sessionPath(id), filepath.Join(archive, id+".json")},
+	}
+	for _, m := range moves {
+		// Project roots may be on another filesystem than the archive.
+		if err := moveFile(m[0], m[1]); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", filepath.Base(m[0]), err)
+		}
+	}
+	delete(s.located, id)
+	return nil
+}
+
+func (s *JSONFileStorage) PurgeSession(id string) error {
+	if err := validateSessionID(id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.sessionPath(id)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to delete session file: %w", err)
+	}
+	if err := s.releaseMessageLogBlobsLocked(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.messageLogPath(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete message log: %w", err)
+	}
+	if err := os.RemoveAll(s.attemptsSessionDir(id)); err != nil {
+		return fmt.Errorf("failed to delete run attempts: %w", err)
+	}
+	delete(s.located, id)
+	return nil
+}
+
+// ListAttemptSessionIDs returns the session IDs that have a run-attempt
+// directory, whether or not the session record still exists.
+func (s *JSONFileStorage) ListAttemptSessionIDs() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(filepath.Join(s.baseDir, "sessions", "attempts"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || validateSessionID(entry.Name()) != nil {
+			continue
+		}
+		ids = append(ids, entry.Name())
+	}
+	return ids, nil
+}
+
+func (s *JSONFileStorage) DeleteRunAttempts(sessionID string) error {
+	if err := validateSessionID(sessionID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return os.RemoveAll(s.attemptsSessionDir(sessionID))
+}
+
+func (s *JSONFileStorage) ListResumeTokens() ([]*ResumeTokenMetadata, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries, err := os.ReadDir(s.resumeTokensDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*ResumeTokenMetadata{}, nil
+		}
+		return nil, err
+	}
+
+	tokens := make([]*ResumeTokenMetadata, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		tokenID := entry.Name()[:len(entry.Name())-5]
+		if err := validateResumeTokenID(tokenID); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(s.resumeTokenPath(tokenID))
+		if err != nil {
+			continue
+		}
+		var item ResumeTokenMetadata
+		if err := json.Unmarshal(data, &item); err != nil {
+			continue
+		}
+		tokens = append(tokens, &item)
+	}
+	return tokens, nil
+}
+
+func (s *JSONFileStorage) DeleteResumeToken(tokenID string) error {
+	if err := validateResumeTokenID(tokenID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.resumeTokenPath(tokenID)); err != nil {
+		if os.IsNotExist(err) {
+			return ErrResumeTokenNotFound
+		}
+		return err
+	}
+	return nil
+}
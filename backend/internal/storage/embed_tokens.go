@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var ErrEmbedTokenNotFound = errors.New("embed token not found")
+
+// EmbedToken is a read-only credential for one session's embed endpoints.
+// Only a hash of the secret is kept.
+type EmbedToken struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	// Hash is the hex SHA-256 of the token secret.
+	Hash  string `json:"hash"`
+	Label string `json:"label,omitempty"`
+	// Origins are the pages allowed to read the endpoints cross-origin and
+	// to frame the widget. Empty allows no cross-origin use.
+	Origins   []string   `json:"origins,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the token is neither revoked nor expired at now.
+func (t EmbedToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
+
+// EmbedTokenStorage keeps embed tokens in a single JSON file.
+type EmbedTokenStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewEmbedTokenStorage creates an embed token store rooted at baseDir.
+func NewEmbedTokenStorage(baseDir string) *EmbedTokenStorage {
+	return &EmbedTokenStorage{baseDir: baseDir}
+}
+
+func (s *EmbedTokenStorage) path() string {
+	return filepath.Join(s.baseDir, "embed_tokens.json")
+}
+
+// Add stores a new token.
+func (s *EmbedTokenStorage) Add(token EmbedToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.readUnlocked()
+	if err != nil {
+		return err
+	}
+	return s.writeUnlocked(append(tokens, token))
+}
+
+// List returns the tokens of sessionID, or every token when it is empty,
+// oldest first.
+func (s *EmbedTokenStorage) List(sessionID string) ([]EmbedToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.readUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	tokens := []EmbedToken{}
+	for _, t := range all {
+		if sessionID == "" || t.SessionID == sessionID {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+// FindByHash returns the token with the given secret hash.
+func (s *EmbedTokenStorage) FindByHash(hash string) (EmbedToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.readUnlocked()
+	if err != nil {
+		return EmbedToken{}, err
+	}
+	for _, t := range tokens {
+		if t.Hash == hash {
+			return t, nil
+		}
+	}
+	return EmbedToken{}, ErrEmbedTokenNotFound
+}
+
+// Revoke marks a token of sessionID revoked. Revoking twice keeps the first
+// revocation time.
+func (s *EmbedTokenStorage) Revoke(sessionID, id string) (EmbedToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.readUnlocked()
+	if err != nil {
+		return EmbedToken{}, err
+	}
+	for i := range tokens {
+		if tokens[i].ID != id || tokens[i].SessionID != sessionID {
+			continue
+		}
+		if tokens[i].RevokedAt == nil {
+			now := time.Now().UTC()
+			tokens[i].RevokedAt = &now
+			if err := s.writeUnlocked(tokens); err != nil {
+				return EmbedToken{}, err
+			}
+		}
+		return tokens[i], nil
+	}
+	return EmbedToken{}, ErrEmbedTokenNotFound
+}
+
+func (s *EmbedTokenStorage) readUnlocked() ([]EmbedToken, error) {
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read embed tokens: %w", err)
+	}
+	var tokens []EmbedToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse embed tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+func (s *EmbedTokenStorage) writeUnlocked(tokens []EmbedToken) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal embed tokens: %w", err)
+	}
+	if err := os.MkdirAll(s.baseDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create embed token directory: %w", err)
+	}
+	tmp := s.path() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write embed tokens: %w", err)
+	}
+	if err := os.Rename(tmp, s.path()); err != nil {
+		return fmt.Errorf("failed to replace embed tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,468 @@
+package storage
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// StoredEvent is a session event read back from the event log. Seq numbers
+// a session's events from 1 and, unlike Event.ID, survives restarts.
+type StoredEvent struct {
+	Seq   int64
+	Event domain.Event
+}
+
+type eventLogRecord struct {
+	Seq       int64           `json:"seq"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Raw       json.RawMessage `json:"raw,omitempty"`
+	RawBlob   string          `json:"raw_blob,omitempty"`
+}
+
+// Event log rotation defaults: once a session's journal reaches
+// DefaultEventLogSegmentSize bytes it is sealed into a segment, and the
+// oldest segments beyond DefaultEventLogMaxSegments are deleted.
+const (
+	DefaultEventLogSegmentSize = 16 << 20
+	DefaultEventLogMaxSegments = 8
+)
+
+// maxOpenEventLogs caps the journals kept open for appending; past it, one
+// is closed before another is opened.
+const maxOpenEventLogs = 64
+
+// EventLogStorage appends every event of a session to its own JSONL
+// journal, so the full stream can be replayed after a restart and streams
+// can resume from any offset. The journal being written is <id>.jsonl;
+// sealed segments are <id>.<last seq>.jsonl, so replays from an offset skip
+// the segments before it without reading them.
+type EventLogStorage struct {
+	baseDir string
+	mu      sync.Mutex
+	// lastSeq caches each session's highest sequence number once read.
+	lastSeq     map[string]int64
+	open        map[string]*eventLogFile
+	segmentSize int64
+	maxSegments int
+	blobs       *BlobStore
+}
+
+// eventLogFile is a journal open for appending and its size.
+type eventLogFile struct {
+	f    *os.File
+	size int64
+}
+
+// eventLogSegment is a sealed journal segment.
+type eventLogSegment struct {
+	path    string
+	lastSeq int64
+}
+
+// NewEventLogStorage creates an event log rooted at baseDir, rotating at the
+// default segment size and count.
+func NewEventLogStorage(baseDir string) *EventLogStorage {
+	return &EventLogStorage{
+		baseDir:     baseDir,
+		lastSeq:     make(map[string]int64),
+		open:        make(map[string]*eventLogFile),
+		segmentSize: DefaultEventLogSegmentSize,
+		maxSegments: DefaultEventLogMaxSegments,
+	}
+}
+
+// SetBlobStore moves the raw payloads of events logged from now on to
+// blobs.
+func (s *EventLogStorage) SetBlobStore(blobs *BlobStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs = blobs
+}
+
+// SetRotation seals a session's journal into a segment once it reaches
+// segmentSize bytes, keeping the newest maxSegments segments. A segmentSize
+// of 0 or less never rotates, and a maxSegments of 0 or less keeps every
+// segment.
+func (s *EventLogStorage) SetRotation(segmentSize int64, maxSegments int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.segmentSize = segmentSize
+	s.maxSegments = maxSegments
+}
+
+func (s *EventLogStorage) dir() string {
+	return filepath.Join(s.baseDir, "sessions", "events")
+}
+
+func (s *EventLogStorage) path(sessionID string) string {
+	return filepath.Join(s.dir(), sessionID+".jsonl")
+}
+
+func (s *EventLogStorage) segmentPath(sessionID string, lastSeq int64) string {
+	return filepath.Join(s.dir(), fmt.Sprintf("%s.%d.jsonl", sessionID, lastSeq))
+}
+
+// Append writes the event to its session's journal and returns its sequence
+// number. A journal that fails to rotate after the write still returns the
+// event's sequence number along with the error.
+func (s *EventLogStorage) Append(event domain.Event) (int64, error) {
+	if err := validateSessionID(event.SessionID); err != nil {
+		return 0, err
+	}
+	record := eventLogRecord{
+		Type:      event.Type.String(),
+		Timestamp: event.Timestamp,
+		Raw:       event.Raw,
+	}
+	if event.Data != nil {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal event data: %w", err)
+		}
+		record.Data = data
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq, err := s.lastSeqLocked(event.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	record.Seq = seq + 1
+	if record.Raw, record.RawBlob, err = storeRaw(s.blobs, record.Raw); err != nil {
+		return 0, err
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal event log record: %w", err)
+	}
+	file, err := s.openLocked(event.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := file.f.Write(append(line, '\n'))
+	file.size += int64(n)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write event log record: %w", err)
+	}
+	s.lastSeq[event.SessionID] = record.Seq
+	if s.segmentSize > 0 && file.size >= s.segmentSize {
+		if err := s.sealLocked(event.SessionID, record.Seq); err != nil {
+			return record.Seq, fmt.Errorf("failed to rotate event log: %w", err)
+		}
+	}
+	return record.Seq, nil
+}
+
+// Since returns up to limit of the session's events after sinceSeq, oldest
+// first, and whether more follow. A limit of 0 or less returns them all.
+// Events in segments deleted by rotation are gone; the replay starts at the
+// oldest event kept.
+func (s *EventLogStorage) Since(sessionID string, sinceSeq int64, limit int) ([]StoredEvent, bool, error) {
+	if err := validateSessionID(sessionID); err != nil {
+		return nil, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segments, err := s.segmentsLocked(sessionID)
+	if err != nil {
+		return nil, false, err
+	}
+	paths := make([]string, 0, len(segments)+1)
+	for _, segment := range segments {
+		if segment.lastSeq > sinceSeq {
+			paths = append(paths, segment.path)
+		}
+	}
+	paths = append(paths, s.path(sessionID))
+
+	events := []StoredEvent{}
+	for _, path := range paths {
+		records, err := readEventLogFile(path)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, record := range records {
+			if record.Seq <= sinceSeq {
+				continue
+			}
+			if limit > 0 && len(events) == limit {
+				return events, true, nil
+			}
+			record.Raw = loadRaw(s.blobs, record.Raw, record.RawBlob)
+			events = append(events, StoredEvent{Seq: record.Seq, Event: record.event(sessionID)})
+		}
+	}
+	return events, false, nil
+}
+
+// Delete removes the session's journal and its segments.
+func (s *EventLogStorage) Delete(sessionID string) error {
+	if err := validateSessionID(sessionID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeLocked(sessionID)
+	segments, err := s.segmentsLocked(sessionID)
+	if err != nil {
+		return err
+	}
+	paths := []string{s.path(sessionID)}
+	for _, segment := range segments {
+		paths = append(paths, segment.path)
+	}
+	for _, path := range paths {
+		if err := s.removeFileLocked(path); err != nil {
+			return err
+		}
+	}
+	delete(s.lastSeq, sessionID)
+	return nil
+}
+
+// Close closes the journals open for appending. Appends after Close reopen
+// them.
+func (s *EventLogStorage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var errs []error
+	for sessionID, file := range s.open {
+		errs = append(errs, file.f.Close())
+		delete(s.open, sessionID)
+	}
+	return errors.Join(errs...)
+}
+
+// lastSeqLocked returns the session's highest sequence number, reading it
+// from the journal, or the newest segment when the journal is empty, the
+// first time.
+func (s *EventLogStorage) lastSeqLocked(sessionID string) (int64, error) {
+	if seq, ok := s.lastSeq[sessionID]; ok {
+		return seq, nil
+	}
+	records, err := readEventLogFile(s.path(sessionID))
+	if err != nil {
+		return 0, err
+	}
+	var seq int64
+	for _, record := range records {
+		seq = max(seq, record.Seq)
+	}
+	if seq == 0 {
+		segments, err := s.segmentsLocked(sessionID)
+		if err != nil {
+			return 0, err
+		}
+		if len(segments) > 0 {
+			seq = segments[len(segments)-1].lastSeq
+		}
+	}
+	s.lastSeq[sessionID] = seq
+	return seq, nil
+}
+
+// openLocked returns the session's journal open for appending.
+func (s *EventLogStorage) openLocked(sessionID string) (*eventLogFile, error) {
+	if file, ok := s.open[sessionID]; ok {
+		return file, nil
+	}
+	if len(s.open) >= maxOpenEventLogs {
+		for id := range s.open {
+			s.closeLocked(id)
+			break
+		}
+	}
+	if err := os.MkdirAll(s.dir(), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path(sessionID), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to stat event log: %w", err)
+	}
+	file := &eventLogFile{f: f, size: info.Size()}
+	s.open[sessionID] = file
+	return file, nil
+}
+
+func (s *EventLogStorage) closeLocked(sessionID string) {
+	if file, ok := s.open[sessionID]; ok {
+		_ = file.f.Close()
+		delete(s.open, sessionID)
+	}
+}
+
+// sealLocked renames the session's journal to a segment ending at lastSeq
+// and deletes the oldest segments beyond the limit.
+func (s *EventLogStorage) sealLocked(sessionID string, lastSeq int64) error {
+	s.closeLocked(sessionID)
+	if err := os.Rename(s.path(sessionID), s.segmentPath(sessionID, lastSeq)); err != nil {
+		return err
+	}
+	if s.maxSegments <= 0 {
+		return nil
+	}
+	segments, err := s.segmentsLocked(sessionID)
+	if err != nil {
+		return err
+	}
+	for len(segments) > s.maxSegments {
+		if err := s.removeFileLocked(segments[0].path); err != nil {
+			return err
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
+
+// segmentsLocked lists the session's sealed segments, oldest first.
+func (s *EventLogStorage) segmentsLocked(sessionID string) ([]eventLogSegment, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir(), sessionID+".*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]eventLogSegment, 0, len(matches))
+	for _, path := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), sessionID+"."), ".jsonl")
+		lastSeq, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, eventLogSegment{path: path, lastSeq: lastSeq})
+	}
+	slices.SortFunc(segments, func(a, b eventLogSegment) int { return cmp.Compare(a.lastSeq, b.lastSeq) })
+	return segments, nil
+}
+
+// removeFileLocked deletes a journal file, releasing the blobs its records
+// refer to.
+func (s *EventLogStorage) removeFileLocked(path string) error {
+	if s.blobs != nil {
+		records, err := readEventLogFile(path)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if record.RawBlob == "" {
+				continue
+			}
+			if err := s.blobs.Release(record.RawBlob); err != nil {
+				return fmt.Errorf("failed to release event log blob: %w", err)
+			}
+		}
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete event log: %w", err)
+	}
+	return nil
+}
+
+// readEventLogFile reads every record of a journal file, skipping corrupt
+// lines. A missing file has no records.
+func readEventLogFile(path string) ([]eventLogRecord, error) {
+	records := []eventLogRecord{}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return records, nil
+		}
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record eventLogRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// event rebuilds the domain event, decoding its data into the type the
+// event type carries. Data that no longer decodes is kept as raw JSON.
+func (r eventLogRecord) event(sessionID string) domain.Event {
+	eventType, _ := domain.ParseEventType(r.Type)
+	event := domain.Event{
+		Seq:       r.Seq,
+		Type:      eventType,
+		Timestamp: r.Timestamp,
+		SessionID: sessionID,
+		Raw:       r.Raw,
+	}
+	if len(r.Data) == 0 {
+		return event
+	}
+	data, err := decodeEventData(eventType, r.Data)
+	if err != nil {
+		event.Data = r.Data
+		return event
+	}
+	event.Data = data
+	return event
+}
+
+func decodeEventData(t domain.EventType, raw json.RawMessage) (any, error) {
+	switch t {
+	case domain.EventTypeStatusChange:
+		return decodeAs[domain.StatusChangeData](raw)
+	case domain.EventTypeOutput:
+		return decodeAs[domain.OutputData](raw)
+	case domain.EventTypeMetric:
+		return decodeAs[domain.MetricData](raw)
+	case domain.EventTypeError:
+		return decodeAs[domain.ErrorData](raw)
+	case domain.EventTypeMetadata:
+		return decodeAs[domain.MetadataData](raw)
+	case domain.EventTypeToolCall:
+		return decodeAs[domain.ToolCallData](raw)
+	case domain.EventTypeThought:
+		return decodeAs[domain.ThoughtData](raw)
+	case domain.EventTypePlan:
+		return decodeAs[domain.PlanData](raw)
+	case domain.EventTypeProgress:
+		return decodeAs[domain.ProgressData](raw)
+	case domain.EventTypeLiveness:
+		return decodeAs[domain.LivenessData](raw)
+	}
+	return nil, fmt.Errorf("unknown event type %d", t)
+}
+
+func decodeAs[T any](raw json.RawMessage) (any, error) {
+	var v T
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
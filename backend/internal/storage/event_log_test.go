@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestEventLogStorage_AppendAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	log := NewEventLogStorage(dir)
+
+	events := []domain.Event{
+		domain.NewStatusChangeEvent("s1", domain.SessionStateIdle, domain.SessionStateRunning, "started", nil),
+		domain.NewDeltaOutputEvent("s1", "hel", nil),
+		domain.NewToolCallEvent("s1", domain.ToolCallData{ID: "t1", Name: "bash", Status: "completed"}, nil),
+		domain.NewOutputEvent("s2", "other session", nil),
+	}
+	for i, event := range events {
+		if _, err := log.Append(event); err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+	}
+
+	// A fresh instance, as after a restart, continues the sequence.
+	log = NewEventLogStorage(dir)
+	seq, err := log.Append(domain.NewMetricDataEvent("s1", domain.MetricData{TokensIn: 10}, nil))
+	if err != nil || seq != 4 {
+		t.Fatalf("append after restart = %d, %v; want seq 4", seq, err)
+	}
+
+	got, more, err := log.Since("s1", 0, 0)
+	if err != nil || more || len(got) != 4 {
+		t.Fatalf("since 0 = %d events, more %v, err %v; want 4", len(got), more, err)
+	}
+	for i, stored := range got {
+		if stored.Seq != int64(i+1) || stored.Event.SessionID != "s1" {
+			t.Fatalf("event %d = seq %d session %q", i, stored.Seq, stored.Event.SessionID)
+		}
+	}
+	if d, ok := got[0].Event.StatusChange(); !ok || d.NewState != domain.SessionStateRunning || d.Reason != "started" {
+		t.Fatalf("status change = %+v, %v", got[0].Event.Data, ok)
+	}
+	if d, ok := got[1].Event.Output(); !ok || d.Content != "hel" || !d.IsDelta {
+		t.Fatalf("output = %+v, %v", got[1].Event.Data, ok)
+	}
+	if d, ok := got[2].Event.ToolCall(); !ok || d.Name != "bash" {
+		t.Fatalf("tool call = %+v, %v", got[2].Event.Data, ok)
+	}
+	if got[0].Event.Timestamp.IsZero() || time.Since(got[0].Event.Timestamp) > time.Minute {
+		t.Fatalf("timestamp = %v", got[0].Event.Timestamp)
+	}
+
+	page, more, err := log.Since("s1", 1, 2)
+	if err != nil || !more || len(page) != 2 || page[0].Seq != 2 {
+		t.Fatalf("page = %+v, more %v, err %v; want seqs 2-3 and more", page, more, err)
+	}
+
+	if err := log.Delete("s1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got, _, _ := log.Since("s1", 0, 0); len(got) != 0 {
+		t.Fatalf("events after delete = %d, want 0", len(got))
+	}
+	if _, err := log.Append(domain.Event{SessionID: "../x"}); err == nil {
+		t.Fatal("expected an invalid session ID to be rejected")
+	}
+}
+
+func TestEventLogStorage_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	log := NewEventLogStorage(dir)
+	log.SetRotation(512, 2)
+	defer log.Close()
+
+	for i := range 40 {
+		if seq, err := log.Append(domain.NewDeltaOutputEvent("s1", fmt.Sprintf("chunk %02d of a streamed reply", i), nil)); err != nil || seq != int64(i+1) {
+			t.Fatalf("append %d = %d, %v", i, seq, err)
+		}
+	}
+	segments, err := filepath.Glob(filepath.Join(dir, "sessions", "events", "s1.*.jsonl"))
+	if err != nil || len(segments) != 2 {
+		t.Fatalf("segments = %v, %v; want the newest 2 kept", segments, err)
+	}
+
+	all, more, err := log.Since("s1", 0, 0)
+	if err != nil || more || len(all) == 0 {
+		t.Fatalf("since 0 = %d events, more %v, err %v", len(all), more, err)
+	}
+	if all[0].Seq == 1 || all[len(all)-1].Seq != 40 {
+		t.Fatalf("kept seqs %d..%d, want the oldest rotated away and 40 last", all[0].Seq, all[len(all)-1].Seq)
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i].Seq != all[i-1].Seq+1 {
+			t.Fatalf("seq %d follows %d", all[i].Seq, all[i-1].Seq)
+		}
+	}
+
+	// Resuming from an offset within the kept segments starts right after it.
+	from := all[len(all)/2].Seq
+	page, _, err := log.Since("s1", from, 3)
+	if err != nil || len(page) != 3 || page[0].Seq != from+1 || page[0].Event.Seq != from+1 {
+		t.Fatalf("since %d = %+v, %v", from, page, err)
+	}
+
+	// A fresh instance continues the sequence even when the journal being
+	// written is empty.
+	log.Close()
+	log = NewEventLogStorage(dir)
+	if seq, err := log.Append(domain.NewOutputEvent("s1", "after restart", nil)); err != nil || seq != 41 {
+		t.Fatalf("append after restart = %d, %v; want 41", seq, err)
+	}
+
+	if err := log.Delete("s1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "sessions", "events", "s1*")); len(left) != 0 {
+		t.Fatalf("files after delete = %v", left)
+	}
+}
+
+// BenchmarkEventLogStorage_AppendDeltas measures journal write throughput
+// under a heavy stream of small output deltas, the bulk of a streaming
+// run's events.
+func BenchmarkEventLogStorage_AppendDeltas(b *testing.B) {
+	log := NewEventLogStorage(b.TempDir())
+	defer log.Close()
+	event := domain.NewDeltaOutputEvent("s1", "a token or two ", nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if _, err := log.Append(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "events/s")
+}
@@ -0,0 +1,383 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Integrity issue kinds reported by CheckIntegrity.
+const (
+	IntegrityUnreadableSession     = "unreadable_session"
+	IntegrityCorruptMessageLog     = "corrupt_message_log"
+	IntegrityUnreadableMessageLog  = "unreadable_message_log"
+	IntegrityOrphanMessageLog      = "orphan_message_log"
+	IntegrityOrphanAttempts        = "orphan_attempts"
+	IntegrityUnreadableAttempt     = "unreadable_attempt"
+	IntegrityUnreadableResumeToken = "unreadable_resume_token"
+	IntegrityOrphanResumeToken     = "orphan_resume_token"
+	IntegrityStaleTempFile         = "stale_temp_file"
+)
+
+// IntegrityIssue is one broken storage invariant. Repair describes the safe
+// fix; repairs move files under sessions/quarantine instead of deleting
+// them, except leftover temp files.
+type IntegrityIssue struct {
+	Kind      string `json:"kind"`
+	SessionID string `json:"session_id,omitempty"`
+	// Path is relative to the storage base directory.
+	Path        string `json:"path"`
+	Detail      string `json:"detail"`
+	Repair      string `json:"repair,omitempty"`
+	Repaired    bool   `json:"repaired,omitempty"`
+	RepairError string `json:"repair_error,omitempty"`
+}
+
+// IntegrityStorage is implemented by stores that can validate, and safely
+// repair, their own on-disk invariants.
+type IntegrityStorage interface {
+	// CheckIntegrity reports broken invariants and, with repair set, fixes
+	// them. Sessions for which skip returns true are reported but never
+	// repaired; skip may be nil.
+	CheckIntegrity(repair bool, skip func(sessionID string) bool) ([]IntegrityIssue, error)
+}
+
+type integrityCheck struct {
+	s          *JSONFileStorage
+	repair     bool
+	skip       func(string) bool
+	quarantine string
+	issues     []IntegrityIssue
+}
+
+func (s *JSONFileStorage) CheckIntegrity(repair bool, skip func(sessionID string) bool) ([]IntegrityIssue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := &integrityCheck{
+		s:          s,
+		repair:     repair,
+		skip:       skip,
+		quarantine: filepath.Join(s.baseDir, "sessions", "quarantine", time.Now().UTC().Format("20060102T150405Z")),
+		issues:     []IntegrityIssue{},
+	}
+	sessions, err := c.checkSessions()
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := c.checkAttempts(sessions)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkResumeTokens(sessions, attempts); err != nil {
+		return nil, err
+	}
+	return c.issues, nil
+}
+
+// checkSessions checks session records and message logs and returns the IDs
+// of the readable sessions.
+func (c *integrityCheck) checkSessions() (map[string]bool, error) {
+	dir := filepath.Join(c.s.baseDir, "sessions")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	sessions := make(map[string]bool)
+	var logs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(name, ".tmp"):
+			c.staleTempFile(filepath.Join(dir, name))
+		case strings.HasSuffix(name, ".messages.jsonl"):
+			logs = append(logs, strings.TrimSuffix(name, ".messages.jsonl"))
+		case filepath.Ext(name) == ".json":
+			id := strings.TrimSuffix(name, ".json")
+			if validateSessionID(id) != nil {
+				continue
+			}
+			if _, err := c.s.loadUnlocked(id); err != nil {
+				c.report(IntegrityIssue{
+					Kind:      IntegrityUnreadableSession,
+					SessionID: id,
+					Path:      c.rel(c.s.sessionPath(id)),
+					Detail:    err.Error(),
+					Repair:    "quarantine the session record, message log and run attempts",
+				}, func() error { return c.quarantineSession(id) })
+				continue
+			}
+			sessions[id] = true
+		}
+	}
+
+	for _, id := range logs {
+		if validateSessionID(id) != nil {
+			continue
+		}
+		path := c.s.messageLogPath(id)
+		if !sessions[id] {
+			if _, err := os.Lstat(c.s.sessionPath(id)); err == nil {
+				continue // unreadable session, already reported
+			}
+			c.report(IntegrityIssue{
+				Kind:      IntegrityOrphanMessageLog,
+				SessionID: id,
+				Path:      c.rel(path),
+				Detail:    "message log has no session record",
+				Repair:    "quarantine the message log",
+			}, func() error { return c.move(path) })
+			continue
+		}
+		_, err := c.s.readMessagesFromJSONLUnlocked(id)
+		var corrupt *MessageLogCorruptionError
+		switch {
+		case err == nil:
+		case errors.As(err, &corrupt):
+			c.report(IntegrityIssue{
+				Kind:      IntegrityCorruptMessageLog,
+				SessionID: id,
+				Path:      c.rel(path),
+				Detail:    corrupt.Error(),
+				Repair:    "rewrite the log without its corrupt lines, quarantining the original",
+			}, func() error { return c.rewriteMessageLog(id) })
+		default:
+			c.report(IntegrityIssue{
+				Kind:      IntegrityUnreadableMessageLog,
+				SessionID: id,
+				Path:      c.rel(path),
+				Detail:    err.Error(),
+				Repair:    "quarantine the log; messages fall back to the session record",
+			}, func() error { return c.move(path) })
+		}
+	}
+	return sessions, nil
+}
+
+// checkAttempts checks run attempts and returns the readable attempts'
+// "sessionID/attemptID" keys.
+func (c *integrityCheck) checkAttempts(sessions map[string]bool) (map[string]bool, error) {
+	dir := filepath.Join(c.s.baseDir, "sessions", "attempts")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]bool{}, nil
+		}
+		return nil, fmt.Errorf("failed to read attempts directory: %w", err)
+	}
+
+	attempts := make(map[string]bool)
+	for _, entry := range entries {
+		sid := entry.Name()
+		if !entry.IsDir() || validateSessionID(sid) != nil {
+			continue
+		}
+		sessionDir := c.s.attemptsSessionDir(sid)
+		if !sessions[sid] {
+			if _, err := os.Lstat(c.s.sessionPath(sid)); err == nil {
+				continue // unreadable session, already reported
+			}
+			c.report(IntegrityIssue{
+				Kind:      IntegrityOrphanAttempts,
+				SessionID: sid,
+				Path:      c.rel(sessionDir),
+				Detail:    "run attempts have no session record",
+				Repair:    "quarantine the run attempts",
+			}, func() error { return c.move(sessionDir) })
+			continue
+		}
+
+		files, err := os.ReadDir(sessionDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attempts for %s: %w", sid, err)
+		}
+		for _, f := range files {
+			name := f.Name()
+			path := filepath.Join(sessionDir, name)
+			if strings.HasSuffix(name, ".tmp") {
+				c.staleTempFile(path)
+				continue
+			}
+			attemptID := strings.TrimSuffix(name, ".json")
+			if f.IsDir() || filepath.Ext(name) != ".json" || validateRunAttemptID(attemptID) != nil {
+				continue
+			}
+			var attempt RunAttemptMetadata
+			if err := readIntegrityJSON(path, &attempt); err != nil {
+				c.report(IntegrityIssue{
+					Kind:      IntegrityUnreadableAttempt,
+					SessionID: sid,
+					Path:      c.rel(path),
+					Detail:    err.Error(),
+					Repair:    "quarantine the run attempt",
+				}, func() error { return c.move(path) })
+				continue
+			}
+			attempts[sid+"/"+attemptID] = true
+		}
+	}
+	return attempts, nil
+}
+
+func (c *integrityCheck) checkResumeTokens(sessions, attempts map[string]bool) error {
+	dir := c.s.resumeTokensDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read resume tokens directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		if strings.HasSuffix(name, ".tmp") {
+			c.staleTempFile(path)
+			continue
+		}
+		if entry.IsDir() || filepath.Ext(name) != ".json" || validateResumeTokenID(strings.TrimSuffix(name, ".json")) != nil {
+			continue
+		}
+		var token ResumeTokenMetadata
+		if err := readIntegrityJSON(path, &token); err != nil {
+			c.report(IntegrityIssue{
+				Kind:   IntegrityUnreadableResumeToken,
+				Path:   c.rel(path),
+				Detail: err.Error(),
+				Repair: "quarantine the resume token",
+			}, func() error { return c.move(path) })
+			continue
+		}
+
+		var detail string
+		switch {
+		case !sessions[token.SessionID]:
+			detail = fmt.Sprintf("session %q does not exist", token.SessionID)
+		case token.AttemptID != "" && !attempts[token.SessionID+"/"+token.AttemptID]:
+			detail = fmt.Sprintf("run attempt %q does not exist", token.AttemptID)
+		default:
+			continue
+		}
+		c.report(IntegrityIssue{
+			Kind:      IntegrityOrphanResumeToken,
+			SessionID: token.SessionID,
+			Path:      c.rel(path),
+			Detail:    detail,
+			Repair:    "quarantine the resume token",
+		}, func() error { return c.move(path) })
+	}
+	return nil
+}
+
+// staleTempFile reports a temp file left by an interrupted write. Writers
+// hold the storage lock, so none can be in progress during the check.
+func (c *integrityCheck) staleTempFile(path string) {
+	c.report(IntegrityIssue{
+		Kind:   IntegrityStaleTempFile,
+		Path:   c.rel(path),
+		Detail: "temp file left by an interrupted write",
+		Repair: "delete the temp file",
+	}, func() error { return os.Remove(path) })
+}
+
+// report records issue, applying fix first when repairing and the issue's
+// session is not skipped.
+func (c *integrityCheck) report(issue IntegrityIssue, fix func() error) {
+	if c.repair {
+		switch {
+		case issue.SessionID != "" && c.skip != nil && c.skip(issue.SessionID):
+			issue.RepairError = "skipped: session is in use"
+		default:
+			if err := fix(); err != nil {
+				issue.RepairError = err.Error()
+			} else {
+				issue.Repaired = true
+			}
+		}
+	}
+	c.issues = append(c.issues, issue)
+}
+
+func (c *integrityCheck) rel(path string) string {
+	if rel, err := filepath.Rel(c.s.baseDir, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// move moves path under the quarantine directory, keeping its location
+// relative to the sessions directory.
+func (c *integrityCheck) move(path string) error {
+	rel, err := filepath.Rel(filepath.Join(c.s.baseDir, "sessions"), path)
+	if err != nil {
+		return err
+	}
+	dest := filepath.Join(c.quarantine, rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	if err := os.Rename(path, dest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to quarantine %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (c *integrityCheck) quarantineSession(id string) error {
+	// The session record moves last so a partial repair stays visible.
+	for _, path := range []string{c.s.messageLogPath(id), c.s.attemptsSessionDir(id), c.s.sessionPath(id)} {
+		if err := c.move(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteMessageLog quarantines a session's message log and writes back only
+// its valid records.
+func (c *integrityCheck) rewriteMessageLog(id string) error {
+	path := c.s.messageLogPath(id)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var kept strings.Builder
+	for line := range strings.SplitSeq(string(data), "\n") {
+		var rec messageLogRecord
+		if json.Unmarshal([]byte(strings.TrimSpace(line)), &rec) != nil || rec.Sequence <= 0 || rec.Timestamp.IsZero() {
+			continue
+		}
+		kept.WriteString(strings.TrimSpace(line))
+		kept.WriteByte('\n')
+	}
+
+	if err := c.move(path); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(kept.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write message log: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename message log: %w", err)
+	}
+	return nil
+}
+
+func readIntegrityJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
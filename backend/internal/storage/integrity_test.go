@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestJSONFileStorage_CheckIntegrity(t *testing.T) {
+	tmpDir := t.TempDir()
+	s, err := NewJSONFileStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+	sessionsDir := filepath.Join(tmpDir, "sessions")
+	write := func(rel, data string) {
+		t.Helper()
+		path := filepath.Join(sessionsDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := s.Save(domain.NewSession("good", "mock", "/tmp")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.AppendMessageLog("good", MessageProjectionAppend, domain.MessageKindUser, "hello", nil, time.Now().UTC()); err != nil {
+		t.Fatalf("AppendMessageLog failed: %v", err)
+	}
+	f, _ := os.OpenFile(s.messageLogPath("good"), os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = f.WriteString("{\"seq\":2,\"kind\n")
+	_ = f.Close()
+	if err := s.SaveRunAttempt(&RunAttemptMetadata{AttemptID: "a1", SessionID: "good", StartedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveRunAttempt failed: %v", err)
+	}
+	write("attempts/good/a2.json", "{")
+	write("broken.json", `{"id": "broken",`)
+	write("attempts/gone/a1.json", `{"attempt_id": "a1", "session_id": "gone"}`)
+	write("resume_tokens/t1.json", `{"token_id": "t1", "session_id": "good", "attempt_id": "missing"}`)
+	write("good.123.tmp", "{")
+
+	issues, err := s.CheckIntegrity(false, nil)
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	var kinds []string
+	for _, issue := range issues {
+		if issue.Repaired {
+			t.Errorf("check without repair repaired %+v", issue)
+		}
+		kinds = append(kinds, issue.Kind)
+	}
+	slices.Sort(kinds)
+	want := []string{
+		IntegrityCorruptMessageLog,
+		IntegrityOrphanAttempts,
+		IntegrityOrphanResumeToken,
+		IntegrityStaleTempFile,
+		IntegrityUnreadableAttempt,
+		IntegrityUnreadableSession,
+	}
+	if !slices.Equal(kinds, want) {
+		t.Fatalf("issue kinds = %v, want %v", kinds, want)
+	}
+
+	issues, err = s.CheckIntegrity(true, nil)
+	if err != nil {
+		t.Fatalf("CheckIntegrity repair failed: %v", err)
+	}
+	for _, issue := range issues {
+		if !issue.Repaired {
+			t.Errorf("issue not repaired: %+v", issue)
+		}
+	}
+	if issues, _ := s.CheckIntegrity(false, nil); len(issues) != 0 {
+		t.Fatalf("issues after repair: %+v", issues)
+	}
+
+	messages, err := s.ReadMessagesFromJSONL("good")
+	if err != nil || len(messages) != 1 || messages[0].Contents != "hello" {
+		t.Fatalf("message log after repair = %+v, err = %v", messages, err)
+	}
+	if _, err := s.LoadRunAttempt("good", "a1"); err != nil {
+		t.Fatalf("valid attempt lost: %v", err)
+	}
+	quarantined, _ := filepath.Glob(filepath.Join(sessionsDir, "quarantine", "*", "broken.json"))
+	if len(quarantined) != 1 {
+		t.Fatalf("expected broken session in quarantine, got %v", quarantined)
+	}
+}
+
+func TestJSONFileStorage_CheckIntegritySkipsSessions(t *testing.T) {
+	tmpDir := t.TempDir()
+	s, err := NewJSONFileStorage(tmpDir)
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+	path := filepath.Join(tmpDir, "sessions", "attempts", "busy", "a1.json")
+	_ = os.MkdirAll(filepath.Dir(path), 0o700)
+	_ = os.WriteFile(path, []byte(`{}`), 0o600)
+
+	issues, err := s.CheckIntegrity(true, func(id string) bool { return id == "busy" })
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Repaired || issues[0].RepairError == "" {
+		t.Fatalf("expected a skipped repair, got %+v", issues)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("skipped session was modified: %v", err)
+	}
+}
@@ -19,12 +19,33 @@ const (
 	MessageProjectionAppend      MessageProjection = "append"
 	MessageProjectionAppendRaw   MessageProjection = "append_raw"
 	MessageProjectionOutputDelta MessageProjection = "append_output_delta"
+	// MessageProjectionDelta extends the last message if it has the
+	// record's kind, like MessageProjectionOutputDelta does for output.
+	MessageProjectionDelta MessageProjection = "append_delta"
 )
 
 type MessageLogAppender interface {
 	AppendMessageLog(sessionID string, projection MessageProjection, kind domain.MessageKind, contents string, raw json.RawMessage, timestamp time.Time) error
 }
 
+// MessageNoticeAppender appends a message that has a notice code. The
+// notice's text is logged as the message contents.
+type MessageNoticeAppender interface {
+	AppendNoticeLog(sessionID string, kind domain.MessageKind, notice domain.Notice, timestamp time.Time) error
+}
+
+// MessagePager reads a page of a session's messages: up to limit messages
+// starting at offset, and whether more follow. Storages that implement it
+// avoid loading the whole history for one page.
+type MessagePager interface {
+	GetMessagesPage(id string, offset, limit int) (messages []domain.Message, more bool, err error)
+}
+
+// MessageLogRedactor scrubs redacted messages from a session's message log.
+type MessageLogRedactor interface {
+	RedactMessageLog(sessionID string, messages []domain.Message, tombstone string) (int, error)
+}
+
 type messageLogRecord struct {
 	Sequence   int64              `json:"seq"`
 	Timestamp  time.Time          `json:"timestamp"`
@@ -32,6 +53,10 @@ type messageLogRecord struct {
 	Kind       domain.MessageKind `json:"kind"`
 	Contents   string             `json:"contents"`
 	Raw        json.RawMessage    `json:"raw,omitempty"`
+	Redacted   bool               `json:"redacted,omitempty"`
+	Notice     *domain.Notice     `json:"notice,omitempty"`
+	// RawBlob refers to the raw payload in the blob store instead.
+	RawBlob string `json:"raw_blob,omitempty"`
 }
 
 type MessageLogCorruptionError struct {
@@ -44,15 +69,35 @@ func (e *MessageLogCorruptionError) Error() string {
 }
 
 func (s *JSONFileStorage) messageLogPath(id string) string {
-	return filepath.Join(s.baseDir, "sessions", id+".messages.jsonl")
+	return filepath.Join(s.sessionsDir(id), id+".messages.jsonl")
 }
 
 func (s *JSONFileStorage) AppendMessageLog(sessionID string, projection MessageProjection, kind domain.MessageKind, contents string, raw json.RawMessage, timestamp time.Time) error {
+	return s.appendMessageLogRecord(sessionID, messageLogRecord{
+		Timestamp:  timestamp,
+		Projection: projection,
+		Kind:       kind,
+		Contents:   contents,
+		Raw:        raw,
+	})
+}
+
+func (s *JSONFileStorage) AppendNoticeLog(sessionID string, kind domain.MessageKind, notice domain.Notice, timestamp time.Time) error {
+	return s.appendMessageLogRecord(sessionID, messageLogRecord{
+		Timestamp:  timestamp,
+		Projection: MessageProjectionAppend,
+		Kind:       kind,
+		Contents:   notice.Text(),
+		Notice:     &notice,
+	})
+}
+
+func (s *JSONFileStorage) appendMessageLogRecord(sessionID string, record messageLogRecord) error {
 	if err := validateSessionID(sessionID); err != nil {
 		return err
 	}
-	if timestamp.IsZero() {
-		timestamp = time.Now()
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
 	}
 
 	s.mu.Lock()
@@ -62,14 +107,9 @@ func (s *JSONFileStorage) AppendMessageLog(sessionID string, projection MessageP
 	if err != nil {
 		return err
 	}
-
-	record := messageLogRecord{
-		Sequence:   seq,
-		Timestamp:  timestamp,
-		Projection: projection,
-		Kind:       kind,
-		Contents:   contents,
-		Raw:        raw,
+	record.Sequence = seq
+	if record.Raw, record.RawBlob, err = storeRaw(s.blobs, record.Raw); err != nil {
+		return err
 	}
 
 	line, err := json.Marshal(record)
@@ -92,6 +132,7 @@ func (s *JSONFileStorage) AppendMessageLog(sessionID string, projection MessageP
 		return fmt.Errorf("failed to sync message log file: %w", err)
 	}
 
+	s.index.appended(sessionID, record)
 	return nil
 }
 
@@ -107,6 +148,13 @@ func (s *JSONFileStorage) ReadMessagesFromJSONL(sessionID string) ([]domain.Mess
 }
 
 func (s *JSONFileStorage) readMessagesFromJSONLUnlocked(sessionID string) ([]domain.Message, error) {
+	return s.readMessageLogUnlocked(sessionID, 0)
+}
+
+// readMessageLogUnlocked rebuilds the session's messages from its log. With
+// stopAfter > 0 it stops reading once more than stopAfter messages are
+// complete, so the result holds stopAfter+1 messages at most.
+func (s *JSONFileStorage) readMessageLogUnlocked(sessionID string, stopAfter int) ([]domain.Message, error) {
 	path := s.messageLogPath(sessionID)
 	file, err := os.Open(path)
 	if err != nil {
@@ -121,7 +169,7 @@ func (s *JSONFileStorage) readMessagesFromJSONLUnlocked(sessionID string) ([]dom
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
 
-	records := make([]messageLogRecord, 0)
+	messages := make([]domain.Message, 0)
 	corruptLines := 0
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -137,13 +185,19 @@ func (s *JSONFileStorage) readMessagesFromJSONLUnlocked(sessionID string) ([]dom
 			corruptLines++
 			continue
 		}
-		records = append(records, rec)
+		rec.Raw = loadRaw(s.blobs, rec.Raw, rec.RawBlob)
+		messages = appendMessageLogRecord(messages, rec)
+		if stopAfter > 0 && len(messages) > stopAfter+1 {
+			// The last message may still grow from deltas; the ones
+			// before it are complete.
+			messages = messages[:stopAfter+1]
+			break
+		}
 	}
 	if err := scanner.Err(); err != nil {
 		return nil, err
 	}
 
-	messages := rebuildMessagesFromLogRecords(records)
 	if corruptLines > 0 {
 		return messages, &MessageLogCorruptionError{SessionID: sessionID, CorruptLines: corruptLines}
 	}
@@ -151,26 +205,30 @@ func (s *JSONFileStorage) readMessagesFromJSONLUnlocked(sessionID string) ([]dom
 	return messages, nil
 }
 
-func rebuildMessagesFromLogRecords(records []messageLogRecord) []domain.Message {
-	messages := make([]domain.Message, 0, len(records))
-	for _, rec := range records {
-		if rec.Projection == MessageProjectionOutputDelta {
-			n := len(messages)
-			if n > 0 && messages[n-1].Kind == domain.MessageKindOutput {
-				messages[n-1].Contents += rec.Contents
-				continue
-			}
-		}
+func (p MessageProjection) isDelta() bool {
+	return p == MessageProjectionOutputDelta || p == MessageProjectionDelta
+}
 
-		messages = append(messages, domain.Message{
-			ID:        fmt.Sprintf("log_%d", rec.Sequence),
-			Kind:      rec.Kind,
-			Contents:  rec.Contents,
-			Timestamp: rec.Timestamp,
-			Raw:       rec.Raw,
-		})
+// appendMessageLogRecord adds rec to the rebuilt messages, merging deltas
+// into the message they continue.
+func appendMessageLogRecord(messages []domain.Message, rec messageLogRecord) []domain.Message {
+	if rec.Projection.isDelta() {
+		n := len(messages)
+		if n > 0 && messages[n-1].Kind == rec.Kind {
+			messages[n-1].Contents += rec.Contents
+			return messages
+		}
 	}
-	return messages
+
+	return append(messages, domain.Message{
+		ID:        fmt.Sprintf("log_%d", rec.Sequence),
+		Kind:      rec.Kind,
+		Contents:  rec.Contents,
+		Timestamp: rec.Timestamp,
+		Raw:       rec.Raw,
+		Redacted:  rec.Redacted,
+		Notice:    rec.Notice,
+	})
 }
 
 func (s *JSONFileStorage) nextMessageSequenceLocked(sessionID string) (int64, error) {
@@ -208,3 +266,145 @@ func (s *JSONFileStorage) nextMessageSequenceLocked(sessionID string) (int64, er
 
 	return maxSeq + 1, nil
 }
+
+// releaseMessageLogBlobsLocked drops the blob references of the session's
+// message log, before it is deleted.
+func (s *JSONFileStorage) releaseMessageLogBlobsLocked(sessionID string) error {
+	if s.blobs == nil {
+		return nil
+	}
+	file, err := os.Open(s.messageLogPath(sessionID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec messageLogRecord
+		if json.Unmarshal(scanner.Bytes(), &rec) != nil || rec.RawBlob == "" {
+			continue
+		}
+		if err := s.blobs.Release(rec.RawBlob); err != nil {
+			return fmt.Errorf("failed to release message log blob: %w", err)
+		}
+	}
+	return scanner.Err()
+}
+
+// RedactMessageLog scrubs the log records behind the given messages, matched
+// by kind and contents as the log rebuilds them. The first record of each
+// message gets tombstone as its contents, later output deltas are emptied
+// and raw payloads are dropped. It returns how many records were scrubbed.
+func (s *JSONFileStorage) RedactMessageLog(sessionID string, messages []domain.Message, tombstone string) (int, error) {
+	if err := validateSessionID(sessionID); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.messageLogPath(sessionID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	type logLine struct {
+		text   string
+		record *messageLogRecord
+	}
+	var lines []logLine
+	for text := range strings.SplitSeq(strings.TrimRight(string(data), "\n"), "\n") {
+		line := logLine{text: text}
+		var rec messageLogRecord
+		if strings.TrimSpace(text) != "" && json.Unmarshal([]byte(text), &rec) == nil {
+			line.record = &rec
+		}
+		lines = append(lines, line)
+	}
+
+	// Group records into messages the way appendMessageLogRecord does.
+	type logMessage struct {
+		kind     domain.MessageKind
+		contents string
+		lines    []int
+	}
+	var rebuilt []logMessage
+	for i, line := range lines {
+		rec := line.record
+		if rec == nil {
+			continue
+		}
+		if n := len(rebuilt); rec.Projection.isDelta() && n > 0 && rebuilt[n-1].kind == rec.Kind {
+			rebuilt[n-1].contents += rec.Contents
+			rebuilt[n-1].lines = append(rebuilt[n-1].lines, i)
+			continue
+		}
+		rebuilt = append(rebuilt, logMessage{kind: rec.Kind, contents: rec.Contents, lines: []int{i}})
+	}
+
+	type messageKey struct {
+		kind     domain.MessageKind
+		contents string
+	}
+	targets := make(map[messageKey]bool, len(messages))
+	for _, msg := range messages {
+		targets[messageKey{msg.Kind, msg.Contents}] = true
+	}
+
+	scrubbed := 0
+	for _, msg := range rebuilt {
+		if !targets[messageKey{msg.kind, msg.contents}] {
+			continue
+		}
+		for j, i := range msg.lines {
+			rec := *lines[i].record
+			rec.Contents = ""
+			if j == 0 {
+				rec.Contents = tombstone
+				rec.Redacted = true
+			}
+			if rec.RawBlob != "" && s.blobs != nil {
+				if err := s.blobs.Release(rec.RawBlob); err != nil {
+					return 0, err
+				}
+			}
+			rec.Raw = nil
+			rec.RawBlob = ""
+			rec.Notice = nil
+			text, err := json.Marshal(rec)
+			if err != nil {
+				return 0, fmt.Errorf("failed to marshal message log record: %w", err)
+			}
+			lines[i].text = string(text)
+			scrubbed++
+		}
+	}
+	if scrubbed == 0 {
+		return 0, nil
+	}
+
+	var out strings.Builder
+	for _, line := range lines {
+		out.WriteString(line.text)
+		out.WriteByte('\n')
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(out.String()), 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write message log file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, fmt.Errorf("failed to replace message log file: %w", err)
+	}
+	s.index.invalidate(sessionID)
+	return scrubbed, nil
+}
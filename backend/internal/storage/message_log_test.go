@@ -102,6 +102,47 @@ func TestJSONFileStorage_GetMessages_PrefersJSONL(t *testing.T) {
 	}
 }
 
+func TestJSONFileStorage_GetMessagesPage(t *testing.T) {
+	s, err := NewJSONFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+
+	id := "session-paged"
+	appendLog := func(projection MessageProjection, kind domain.MessageKind, contents string) {
+		t.Helper()
+		if err := s.AppendMessageLog(id, projection, kind, contents, nil, time.Now()); err != nil {
+			t.Fatalf("AppendMessageLog failed: %v", err)
+		}
+	}
+	appendLog(MessageProjectionAppend, domain.MessageKindUser, "one")
+	appendLog(MessageProjectionOutputDelta, domain.MessageKindOutput, "tw")
+	appendLog(MessageProjectionOutputDelta, domain.MessageKindOutput, "o")
+	appendLog(MessageProjectionAppend, domain.MessageKindUser, "three")
+	appendLog(MessageProjectionOutputDelta, domain.MessageKindOutput, "fo")
+	appendLog(MessageProjectionOutputDelta, domain.MessageKindOutput, "ur")
+
+	page, more, err := s.GetMessagesPage(id, 0, 2)
+	if err != nil {
+		t.Fatalf("GetMessagesPage failed: %v", err)
+	}
+	if len(page) != 2 || page[1].Contents != "two" || !more {
+		t.Fatalf("first page = %+v, more = %v", page, more)
+	}
+
+	page, more, err = s.GetMessagesPage(id, 2, 2)
+	if err != nil {
+		t.Fatalf("GetMessagesPage failed: %v", err)
+	}
+	if len(page) != 2 || page[0].Contents != "three" || page[1].Contents != "four" || more {
+		t.Fatalf("last page = %+v, more = %v", page, more)
+	}
+
+	if page, more, _ = s.GetMessagesPage(id, 10, 2); len(page) != 0 || more {
+		t.Fatalf("page past the end = %+v, more = %v", page, more)
+	}
+}
+
 func TestJSONFileStorage_MessageLogCorruptionHandling(t *testing.T) {
 	tmpDir := t.TempDir()
 	s, err := NewJSONFileStorage(tmpDir)
@@ -162,3 +203,26 @@ func splitLines(in string) []string {
 	}
 	return out
 }
+
+func TestJSONFileStorage_NoticeLogReadback(t *testing.T) {
+	s, err := NewJSONFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+	notice := domain.NewNotice(domain.NoticeBestOfNMerged, "candidate", "c2")
+	if err := s.AppendNoticeLog("session-notice", domain.MessageKindSystem, notice, time.Now()); err != nil {
+		t.Fatalf("AppendNoticeLog failed: %v", err)
+	}
+
+	messages, err := s.ReadMessagesFromJSONL("session-notice")
+	if err != nil {
+		t.Fatalf("ReadMessagesFromJSONL failed: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.Contents != "[best-of-n] Merged candidate c2" || msg.Notice == nil || msg.Notice.Params["candidate"] != "c2" {
+		t.Fatalf("unexpected notice message: %+v", msg)
+	}
+}
@@ -0,0 +1,472 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+const (
+	DefaultMessageSearchLimit = 20
+	MaxMessageSearchLimit     = 100
+
+	// snippetBefore and snippetAfter are how many runes of context a
+	// snippet keeps around the first matching word.
+	snippetBefore = 60
+	snippetAfter  = 100
+)
+
+// MessageQuery selects the messages containing every word of Text. Words
+// match whole words, ignoring case.
+type MessageQuery struct {
+	Text         string
+	ProjectID    string
+	ProviderType string
+	// Metadata selects sessions holding each of its entries exactly.
+	Metadata map[string]string
+	// Limit caps the hits returned; 0 means DefaultMessageSearchLimit.
+	Limit int
+}
+
+func (q MessageQuery) limit() int {
+	if q.Limit <= 0 {
+		return DefaultMessageSearchLimit
+	}
+	return min(q.Limit, MaxMessageSearchLimit)
+}
+
+// MessageHit is a message matching a search, with a snippet of its contents
+// around the match.
+type MessageHit struct {
+	SessionID string
+	Index     int
+	Message   domain.Message
+	Snippet   string
+}
+
+// MessageSearchResult holds a search's hits, newest first, and whether more
+// messages matched than were returned.
+type MessageSearchResult struct {
+	Hits []MessageHit
+	More bool
+}
+
+// MessageSearcher searches the messages of every session.
+type MessageSearcher interface {
+	SearchMessages(query MessageQuery) (MessageSearchResult, error)
+}
+
+// ScanMessages searches the messages of every session in a storage that is
+// not a MessageSearcher by reading them all.
+func ScanMessages(store Storage, query MessageQuery) (MessageSearchResult, error) {
+	result := MessageSearchResult{Hits: []MessageHit{}}
+	terms := SearchTerms(query.Text)
+	if len(terms) == 0 {
+		return result, nil
+	}
+	sessions, err := store.List()
+	if err != nil && len(sessions) == 0 {
+		return MessageSearchResult{}, err
+	}
+	for _, sess := range sessions {
+		if (query.ProjectID != "" && sess.ProjectID != query.ProjectID) || (query.ProviderType != "" && sess.ProviderType != query.ProviderType) ||
+			!domain.MatchesMetadata(sess.Metadata, query.Metadata) {
+			continue
+		}
+		messages, err := store.GetMessages(sess.ID)
+		if err != nil {
+			continue
+		}
+		for i, msg := range messages {
+			if !msg.Redacted && MatchesMessage(msg.Contents, terms) {
+				result.Hits = append(result.Hits, MessageHit{SessionID: sess.ID, Index: i, Message: msg})
+			}
+		}
+	}
+	slices.SortFunc(result.Hits, func(a, b MessageHit) int {
+		if c := b.Message.Timestamp.Compare(a.Message.Timestamp); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.SessionID, b.SessionID); c != 0 {
+			return c
+		}
+		return b.Index - a.Index
+	})
+	if limit := query.limit(); len(result.Hits) > limit {
+		result.Hits, result.More = result.Hits[:limit], true
+	}
+	for i := range result.Hits {
+		result.Hits[i].Snippet = MessageSnippet(result.Hits[i].Message.Contents, terms)
+	}
+	return result, nil
+}
+
+// SearchTerms splits text into the lowercase words searches match.
+func SearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// MatchesMessage reports whether contents hold every one of terms.
+func MatchesMessage(contents string, terms []string) bool {
+	words := make(map[string]bool)
+	for _, w := range SearchTerms(contents) {
+		words[w] = true
+	}
+	for _, t := range terms {
+		if !words[t] {
+			return false
+		}
+	}
+	return true
+}
+
+// MessageSnippet returns the part of contents around the first of terms it
+// holds, on one line.
+func MessageSnippet(contents string, terms []string) string {
+	runes := []rune(contents)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	at := 0
+	for _, t := range terms {
+		if i := runeIndex(lower, []rune(t)); i >= 0 {
+			at = i
+			break
+		}
+	}
+	start, end := max(at-snippetBefore, 0), min(at+snippetAfter, len(runes))
+	snippet := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+func runeIndex(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SearchMessages implements MessageSearcher with an in-memory inverted index
+// of the sessions' messages. The index is built on the first search and
+// kept current as messages are logged.
+func (s *JSONFileStorage) SearchMessages(query MessageQuery) (MessageSearchResult, error) {
+	terms := SearchTerms(query.Text)
+	if len(terms) == 0 {
+		return MessageSearchResult{Hits: []MessageHit{}}, nil
+	}
+	limit := query.limit()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	candidates, err := s.index.search(s, terms, query)
+	if err != nil {
+		return MessageSearchResult{}, err
+	}
+
+	// The index may hold words a message lost as deltas extended it, so
+	// candidates are checked against the messages themselves.
+	result := MessageSearchResult{Hits: []MessageHit{}}
+	loaded := make(map[string][]domain.Message)
+	for _, ref := range candidates {
+		messages, ok := loaded[ref.sessionID]
+		if !ok {
+			messages, _ = s.readMessagesUnlocked(ref.sessionID)
+			loaded[ref.sessionID] = messages
+		}
+		if ref.index >= len(messages) {
+			continue
+		}
+		msg := messages[ref.index]
+		if msg.Redacted || !MatchesMessage(msg.Contents, terms) {
+			continue
+		}
+		if len(result.Hits) == limit {
+			result.More = true
+			break
+		}
+		result.Hits = append(result.Hits, MessageHit{
+			SessionID: ref.sessionID,
+			Index:     ref.index,
+			Message:   msg,
+			Snippet:   MessageSnippet(msg.Contents, terms),
+		})
+	}
+	return result, nil
+}
+
+type messageRef struct {
+	sessionID string
+	index     int
+	at        time.Time
+}
+
+// indexedSession is what the index knows of one session.
+type indexedSession struct {
+	projectID    string
+	providerType string
+	metadata     map[string]string
+	// fromRecord marks sessions indexed from the messages in their record,
+	// which a message log replaces once one is written.
+	fromRecord bool
+	times      []time.Time
+	terms      map[string]struct{}
+	// lastKind and lastWord are the kind of the last message and the word
+	// it ends with, which a delta record may continue.
+	lastKind domain.MessageKind
+	lastWord string
+}
+
+// messageIndex maps words to the messages holding them. Sessions changed
+// other than by appending to their log are marked stale and re-read on the
+// next search. Callers hold the storage lock, so the index lock is always
+// taken second.
+type messageIndex struct {
+	mu       sync.Mutex
+	built    bool
+	sessions map[string]*indexedSession
+	stale    map[string]bool
+	postings map[string]map[string][]int
+}
+
+func newMessageIndex() *messageIndex {
+	idx := &messageIndex{}
+	idx.reset()
+	return idx
+}
+
+// reset drops the index so the next search rebuilds it.
+func (idx *messageIndex) reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.built = false
+	idx.sessions = make(map[string]*indexedSession)
+	idx.stale = make(map[string]bool)
+	idx.postings = make(map[string]map[string][]int)
+}
+
+func (idx *messageIndex) search(s *JSONFileStorage, terms []string, query MessageQuery) ([]messageRef, error) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.built {
+		if err := idx.buildLocked(s); err != nil {
+			return nil, err
+		}
+	}
+	for id := range idx.stale {
+		idx.removeLocked(id)
+		if err := idx.indexSessionLocked(s, id); err != nil {
+			return nil, err
+		}
+		delete(idx.stale, id)
+	}
+
+	var refs []messageRef
+	for id, indexes := range idx.postings[terms[0]] {
+		sess := idx.sessions[id]
+		if sess == nil || (query.ProjectID != "" && sess.projectID != query.ProjectID) || (query.ProviderType != "" && sess.providerType != query.ProviderType) ||
+			!domain.MatchesMetadata(sess.metadata, query.Metadata) {
+			continue
+		}
+		for _, i := range indexes {
+			if idx.hasAllLocked(id, i, terms[1:]) {
+				refs = append(refs, messageRef{sessionID: id, index: i, at: sess.times[i]})
+			}
+		}
+	}
+	slices.SortFunc(refs, func(a, b messageRef) int {
+		if c := b.at.Compare(a.at); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.sessionID, b.sessionID); c != 0 {
+			return c
+		}
+		return b.index - a.index
+	})
+	return refs, nil
+}
+
+func (idx *messageIndex) hasAllLocked(sessionID string, i int, terms []string) bool {
+	for _, t := range terms {
+		if _, ok := slices.BinarySearch(idx.postings[t][sessionID], i); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// buildLocked indexes every session in the storage's session directories.
+func (idx *messageIndex) buildLocked(s *JSONFileStorage) error {
+	for _, sessionsDir := range s.sessionDirsUnlocked() {
+		entries, err := os.ReadDir(sessionsDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+			id := strings.TrimSuffix(entry.Name(), ".json")
+			if validateSessionID(id) != nil || s.sessionsDir(id) != sessionsDir {
+				continue
+			}
+			if err := idx.indexSessionLocked(s, id); err != nil {
+				return err
+			}
+		}
+	}
+	idx.built = true
+	clear(idx.stale)
+	return nil
+}
+
+// indexSessionLocked reads a session and its messages into the index. A
+// session that cannot be read is left out.
+func (idx *messageIndex) indexSessionLocked(s *JSONFileStorage, id string) error {
+	sess, err := s.loadUnlocked(id)
+	if err != nil {
+		return nil
+	}
+	messages, logErr := s.readMessageLogUnlocked(id, 0)
+	fromRecord := false
+	var corruptionErr *MessageLogCorruptionError
+	switch {
+	case logErr == nil, errors.As(logErr, &corruptionErr) && len(messages) > 0:
+	default:
+		fromRecord = true
+		if messages, err = s.getMessagesUnlocked(id); err != nil {
+			return nil
+		}
+	}
+
+	entry := &indexedSession{
+		projectID:    sess.ProjectID,
+		providerType: sess.ProviderType,
+		metadata:     sess.GetMetadata(),
+		fromRecord:   fromRecord,
+		terms:        make(map[string]struct{}),
+	}
+	idx.sessions[id] = entry
+	for _, msg := range messages {
+		idx.addMessageLocked(id, entry, msg.Kind, msg.Contents, msg.Timestamp)
+	}
+	return nil
+}
+
+func (idx *messageIndex) addMessageLocked(id string, entry *indexedSession, kind domain.MessageKind, contents string, at time.Time) {
+	entry.times = append(entry.times, at)
+	entry.lastKind = kind
+	entry.lastWord = ""
+	idx.addWordsLocked(id, entry, contents)
+}
+
+// addWordsLocked indexes the words of contents under the session's last
+// message, and remembers the word it ends with.
+func (idx *messageIndex) addWordsLocked(id string, entry *indexedSession, contents string) {
+	i := len(entry.times) - 1
+	words := SearchTerms(contents)
+	for _, t := range words {
+		if idx.postings[t] == nil {
+			idx.postings[t] = make(map[string][]int)
+		}
+		if list := idx.postings[t][id]; len(list) == 0 || list[len(list)-1] != i {
+			idx.postings[t][id] = append(list, i)
+		}
+		entry.terms[t] = struct{}{}
+	}
+	entry.lastWord = ""
+	if r := []rune(contents); len(words) > 0 && (unicode.IsLetter(r[len(r)-1]) || unicode.IsDigit(r[len(r)-1])) {
+		entry.lastWord = words[len(words)-1]
+	}
+}
+
+func (idx *messageIndex) removeLocked(id string) {
+	entry, ok := idx.sessions[id]
+	if !ok {
+		return
+	}
+	for t := range entry.terms {
+		delete(idx.postings[t], id)
+		if len(idx.postings[t]) == 0 {
+			delete(idx.postings, t)
+		}
+	}
+	delete(idx.sessions, id)
+}
+
+// appended indexes a record written to a session's message log, merging
+// deltas into the message they continue as the log does.
+func (idx *messageIndex) appended(id string, rec messageLogRecord) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.built {
+		return
+	}
+	entry, ok := idx.sessions[id]
+	if !ok || entry.fromRecord || idx.stale[id] {
+		idx.stale[id] = true
+		return
+	}
+	if rec.Projection.isDelta() && len(entry.times) > 0 && entry.lastKind == rec.Kind {
+		// Only the last word can run on into the delta.
+		idx.addWordsLocked(id, entry, entry.lastWord+rec.Contents)
+		return
+	}
+	idx.addMessageLocked(id, entry, rec.Kind, rec.Contents, rec.Timestamp)
+}
+
+// saved updates the session's project, provider and metadata, and picks up
+// sessions new to the index.
+func (idx *messageIndex) saved(snap *domain.SessionSnapshot) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.built {
+		return
+	}
+	entry, ok := idx.sessions[snap.ID]
+	if !ok || entry.fromRecord {
+		idx.stale[snap.ID] = true
+		return
+	}
+	entry.projectID = snap.ProjectID
+	entry.providerType = snap.ProviderType
+	entry.metadata = snap.Metadata
+}
+
+// invalidate marks a session to be re-read on the next search.
+func (idx *messageIndex) invalidate(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.built {
+		idx.stale[id] = true
+	}
+}
+
+func (idx *messageIndex) remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(id)
+	delete(idx.stale, id)
+}
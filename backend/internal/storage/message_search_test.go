@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestJSONFileStorage_SearchMessages(t *testing.T) {
+	s, err := NewJSONFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+
+	alpha := domain.NewSession("alpha", "claude", "/repo")
+	alpha.ProjectID = "proj"
+	if err := s.Save(alpha); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	ts := time.Now().UTC()
+	appendLog := func(id string, projection MessageProjection, kind domain.MessageKind, contents string, at time.Time) {
+		t.Helper()
+		if err := s.AppendMessageLog(id, projection, kind, contents, nil, at); err != nil {
+			t.Fatalf("AppendMessageLog failed: %v", err)
+		}
+	}
+	appendLog("alpha", MessageProjectionAppend, domain.MessageKindUser, "Why does the Flaky test fail?", ts)
+	appendLog("alpha", MessageProjectionOutputDelta, domain.MessageKindOutput, "The flaky test races on the da", ts.Add(time.Second))
+
+	// The first search builds the index; later writes keep it current.
+	result, err := s.SearchMessages(MessageQuery{Text: "flaky test"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(result.Hits) != 2 || result.Hits[0].Index != 1 || result.Hits[1].Index != 0 {
+		t.Fatalf("hits = %+v, want both messages newest first", result.Hits)
+	}
+
+	appendLog("alpha", MessageProjectionOutputDelta, domain.MessageKindOutput, "tabase lock.", ts.Add(2*time.Second))
+	beta := domain.NewSession("beta", "pty", "/repo")
+	if err := s.Save(beta); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	appendLog("beta", MessageProjectionAppend, domain.MessageKindOutput, "database migrated", ts.Add(3*time.Second))
+
+	result, err = s.SearchMessages(MessageQuery{Text: "DATABASE"})
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(result.Hits) != 2 || result.Hits[0].SessionID != "beta" || result.Hits[1].SessionID != "alpha" {
+		t.Fatalf("hits = %+v, want beta then alpha", result.Hits)
+	}
+	if got := result.Hits[1].Snippet; got != "The flaky test races on the database lock." {
+		t.Fatalf("snippet = %q", got)
+	}
+	if result, _ = s.SearchMessages(MessageQuery{Text: "da"}); len(result.Hits) != 0 {
+		t.Fatalf("a word a delta continued still matches: %+v", result.Hits)
+	}
+
+	result, _ = s.SearchMessages(MessageQuery{Text: "database", ProjectID: "proj"})
+	if len(result.Hits) != 1 || result.Hits[0].SessionID != "alpha" {
+		t.Fatalf("project hits = %+v, want alpha only", result.Hits)
+	}
+	result, _ = s.SearchMessages(MessageQuery{Text: "database", ProviderType: "pty"})
+	if len(result.Hits) != 1 || result.Hits[0].SessionID != "beta" {
+		t.Fatalf("provider hits = %+v, want beta only", result.Hits)
+	}
+	ci := "881"
+	if err := beta.PatchMetadata(map[string]*string{"ci.run": &ci}); err != nil {
+		t.Fatalf("PatchMetadata failed: %v", err)
+	}
+	if err := s.Save(beta); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	result, _ = s.SearchMessages(MessageQuery{Text: "database", Metadata: map[string]string{"ci.run": "881"}})
+	if len(result.Hits) != 1 || result.Hits[0].SessionID != "beta" {
+		t.Fatalf("metadata hits = %+v, want beta only", result.Hits)
+	}
+	result, _ = s.SearchMessages(MessageQuery{Text: "database", Limit: 1})
+	if len(result.Hits) != 1 || !result.More {
+		t.Fatalf("limited result = %+v, want one hit and more", result)
+	}
+
+	if err := s.Delete("beta"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := s.RedactMessageLog("alpha", []domain.Message{{Kind: domain.MessageKindUser, Contents: "Why does the Flaky test fail?"}}, "[redacted]"); err != nil {
+		t.Fatalf("RedactMessageLog failed: %v", err)
+	}
+	result, _ = s.SearchMessages(MessageQuery{Text: "flaky"})
+	if len(result.Hits) != 1 || result.Hits[0].SessionID != "alpha" || result.Hits[0].Index != 1 {
+		t.Fatalf("hits after delete and redaction = %+v", result.Hits)
+	}
+}
+
+func TestMessageSnippet(t *testing.T) {
+	contents := strings.Repeat("lead ", 30) + "the\nNeedle here " + strings.Repeat("tail ", 40)
+	snippet := MessageSnippet(contents, []string{"needle"})
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "the Needle here") {
+		t.Fatalf("snippet = %q", snippet)
+	}
+}
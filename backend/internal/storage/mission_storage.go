@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+var ErrMissionNotFound = errors.New("mission not found")
+
+// MissionStorage keeps missions in a single JSON file.
+type MissionStorage struct {
+	baseDir string
+	mu      sync.RWMutex
+}
+
+// NewMissionStorage creates a mission store rooted at baseDir.
+func NewMissionStorage(baseDir string) *MissionStorage {
+	return &MissionStorage{baseDir: baseDir}
+}
+
+func (s *MissionStorage) path() string {
+	return filepath.Join(s.baseDir, "missions.json")
+}
+
+// List returns every mission, in the order they were created.
+func (s *MissionStorage) List() ([]domain.Mission, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listUnlocked()
+}
+
+// Get returns the mission with the given ID.
+func (s *MissionStorage) Get(id string) (*domain.Mission, error) {
+	missions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range missions {
+		if m.ID == id {
+			return &m, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMissionNotFound, id)
+}
+
+// Save creates or updates a mission.
+func (s *MissionStorage) Save(mission domain.Mission) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	missions, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i, m := range missions {
+		if m.ID == mission.ID {
+			missions[i] = mission
+			found = true
+			break
+		}
+	}
+	if !found {
+		missions = append(missions, mission)
+	}
+	return s.writeUnlocked(missions)
+}
+
+// Delete removes the mission with the given ID.
+func (s *MissionStorage) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	missions, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	kept := make([]domain.Mission, 0, len(missions))
+	for _, m := range missions {
+		if m.ID != id {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) == len(missions) {
+		return fmt.Errorf("%w: %s", ErrMissionNotFound, id)
+	}
+	return s.writeUnlocked(kept)
+}
+
+func (s *MissionStorage) listUnlocked() ([]domain.Mission, error) {
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []domain.Mission{}, nil
+		}
+		return nil, fmt.Errorf("failed to read missions: %w", err)
+	}
+	var missions []domain.Mission
+	if err := json.Unmarshal(data, &missions); err != nil {
+		return nil, fmt.Errorf("failed to parse missions: %w", err)
+	}
+	return missions, nil
+}
+
+func (s *MissionStorage) writeUnlocked(missions []domain.Mission) error {
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create mission directory: %w", err)
+	}
+	data, err := json.MarshalIndent(missions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal missions: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write missions: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename missions: %w", err)
+	}
+	return nil
+}
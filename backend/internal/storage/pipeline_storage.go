@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+var ErrPipelineNotFound = errors.New("pipeline not found")
+
+// PipelineStorage keeps pipelines and their latest runs in a single JSON
+// file.
+type PipelineStorage struct {
+	baseDir string
+	mu      sync.RWMutex
+}
+
+// NewPipelineStorage creates a pipeline store rooted at baseDir.
+func NewPipelineStorage(baseDir string) *PipelineStorage {
+	return &PipelineStorage{baseDir: baseDir}
+}
+
+func (s *PipelineStorage) path() string {
+	return filepath.Join(s.baseDir, "pipelines.json")
+}
+
+// List returns every pipeline, in the order they were created.
+func (s *PipelineStorage) List() ([]domain.Pipeline, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listUnlocked()
+}
+
+// Get returns the pipeline with the given ID.
+func (s *PipelineStorage) Get(id string) (*domain.Pipeline, error) {
+	pipelines, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pipelines {
+		if p.ID == id {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPipelineNotFound, id)
+}
+
+// Save creates or updates a pipeline.
+func (s *PipelineStorage) Save(pipeline domain.Pipeline) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pipelines, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i, p := range pipelines {
+		if p.ID == pipeline.ID {
+			pipelines[i] = pipeline
+			found = true
+			break
+		}
+	}
+	if !found {
+		pipelines = append(pipelines, pipeline)
+	}
+	return s.writeUnlocked(pipelines)
+}
+
+// Delete removes the pipeline with the given ID.
+func (s *PipelineStorage) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pipelines, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	kept := make([]domain.Pipeline, 0, len(pipelines))
+	for _, p := range pipelines {
+		if p.ID != id {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(pipelines) {
+		return fmt.Errorf("%w: %s", ErrPipelineNotFound, id)
+	}
+	return s.writeUnlocked(kept)
+}
+
+func (s *PipelineStorage) listUnlocked() ([]domain.Pipeline, error) {
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []domain.Pipeline{}, nil
+		}
+		return nil, fmt.Errorf("failed to read pipelines: %w", err)
+	}
+	var pipelines []domain.Pipeline
+	if err := json.Unmarshal(data, &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to parse pipelines: %w", err)
+	}
+	return pipelines, nil
+}
+
+func (s *PipelineStorage) writeUnlocked(pipelines []domain.Pipeline) error {
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create pipeline directory: %w", err)
+	}
+	data, err := json.MarshalIndent(pipelines, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pipelines: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write pipelines: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename pipelines: %w", err)
+	}
+	return nil
+}
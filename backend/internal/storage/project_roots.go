@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectRootStorage is implemented by stores that can keep a project's
+// session history under a storage root of its own, such as .orbitmesh/ inside
+// the project's repository.
+type ProjectRootStorage interface {
+	// SetProjectRoot routes the sessions of projectID to dir; an empty dir
+	// routes them back to the store's base directory. Sessions already kept
+	// under dir are picked up, and sessions under the project's previous
+	// root are moved.
+	SetProjectRoot(projectID, dir string) error
+}
+
+// SetProjectRoot implements ProjectRootStorage. Only session records and
+// message logs move; run attempts, resume tokens, terminals and archives
+// stay under the base directory.
+func (s *JSONFileStorage) SetProjectRoot(projectID, dir string) error {
+	if dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("invalid storage root %q: %w", dir, err)
+		}
+		dir = abs
+		if base, err := filepath.Abs(s.baseDir); err == nil && base == dir {
+			dir = ""
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old := s.roots[projectID]
+	if dir == "" {
+		delete(s.roots, projectID)
+	} else {
+		if err := s.adoptRootUnlocked(dir); err != nil {
+			return err
+		}
+		// The root may hold sessions the index has not seen.
+		s.index.reset()
+		s.roots[projectID] = dir
+	}
+	if old == "" || old == dir {
+		// Sessions left in the base directory move on their next save.
+		return nil
+	}
+
+	for id, root := range s.located {
+		if root != old {
+			continue
+		}
+		if sess, err := s.loadUnlocked(id); err != nil || sess.ProjectID != projectID {
+			continue
+		}
+		if err := s.relocateUnlocked(id, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// adoptRootUnlocked creates dir's sessions directory and records the
+// sessions already stored there.
+func (s *JSONFileStorage) adoptRootUnlocked(dir string) error {
+	sessionsDir := filepath.Join(dir, "sessions")
+	if err := os.MkdirAll(sessionsDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	entries, err := os.ReadDir(sessionsDir)
+	if err != nil {
+		return fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		id := entry.Name()[:len(entry.Name())-5]
+		if validateSessionID(id) != nil {
+			continue
+		}
+		if _, ok := s.located[id]; !ok {
+			s.located[id] = dir
+		}
+	}
+	return nil
+}
+
+// sessionsDir returns the directory holding the session's record and
+// message log.
+func (s *JSONFileStorage) sessionsDir(id string) string {
+	if root, ok := s.located[id]; ok {
+		return filepath.Join(root, "sessions")
+	}
+	return filepath.Join(s.baseDir, "sessions")
+}
+
+// sessionDirsUnlocked returns the base sessions directory followed by those
+// of every project root.
+func (s *JSONFileStorage) sessionDirsUnlocked() []string {
+	dirs := []string{filepath.Join(s.baseDir, "sessions")}
+	seen := make(map[string]bool)
+	for _, root := range s.roots {
+		if !seen[root] {
+			seen[root] = true
+			dirs = append(dirs, filepath.Join(root, "sessions"))
+		}
+	}
+	return dirs
+}
+
+// routeUnlocked moves a session to the root its project is routed to.
+func (s *JSONFileStorage) routeUnlocked(id, projectID string) error {
+	if s.roots[projectID] == s.located[id] {
+		return nil
+	}
+	return s.relocateUnlocked(id, s.roots[projectID])
+}
+
+// relocateUnlocked moves a session's record and message log under root, or
+// under the base directory if root is empty.
+func (s *JSONFileStorage) relocateUnlocked(id, root string) error {
+	from := s.sessionsDir(id)
+	to := filepath.Join(s.baseDir, "sessions")
+	if root != "" {
+		to = filepath.Join(root, "sessions")
+	}
+	if err := os.MkdirAll(to, 0o700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+	// The session record moves last so a partial move stays visible.
+	for _, name := range []string{id + ".messages.jsonl", id + ".json"} {
+		if err := moveFile(filepath.Join(from, name), filepath.Join(to, name)); err != nil {
+			return fmt.Errorf("failed to move %s: %w", name, err)
+		}
+	}
+	if root == "" {
+		delete(s.located, id)
+	} else {
+		s.located[id] = root
+	}
+	return nil
+}
+
+// moveFile renames src to dst, copying when they are on different
+// filesystems. A missing src is not an error.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || os.IsNotExist(err) {
+		return nil
+	}
+	data, readErr := os.ReadFile(src)
+	if readErr != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestJSONFileStorage_ProjectRootRoutesSessions(t *testing.T) {
+	baseDir := t.TempDir()
+	root := filepath.Join(t.TempDir(), ".orbitmesh")
+	s, err := NewJSONFileStorage(baseDir)
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+
+	// A session saved before the override moves on its next save.
+	early := domain.NewSession("early", "claude", "/repo")
+	early.ProjectID = "proj"
+	if err := s.Save(early); err != nil {
+		t.Fatalf("Save(early) failed: %v", err)
+	}
+	if err := s.AppendMessageLog("early", MessageProjectionAppend, domain.MessageKindUser, "hi", nil, time.Now()); err != nil {
+		t.Fatalf("AppendMessageLog failed: %v", err)
+	}
+	if err := s.SetProjectRoot("proj", root); err != nil {
+		t.Fatalf("SetProjectRoot failed: %v", err)
+	}
+	if err := s.Save(early); err != nil {
+		t.Fatalf("Save(early) after override failed: %v", err)
+	}
+	other := domain.NewSession("other", "claude", "/elsewhere")
+	if err := s.Save(other); err != nil {
+		t.Fatalf("Save(other) failed: %v", err)
+	}
+
+	for _, name := range []string{"early.json", "early.messages.jsonl"} {
+		if _, err := os.Stat(filepath.Join(root, "sessions", name)); err != nil {
+			t.Errorf("expected %s under the project root: %v", name, err)
+		}
+		if _, err := os.Stat(filepath.Join(baseDir, "sessions", name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to leave the base directory, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "sessions", "other.json")); err != nil {
+		t.Errorf("expected other.json under the base directory: %v", err)
+	}
+	if msgs, err := s.GetMessages("early"); err != nil || len(msgs) != 1 {
+		t.Fatalf("GetMessages = %v, %v", msgs, err)
+	}
+	if sessions, err := s.List(); err != nil || len(sessions) != 2 {
+		t.Fatalf("List = %d sessions, %v", len(sessions), err)
+	}
+
+	// A fresh store finds the project's sessions once the root is set.
+	reopened, _ := NewJSONFileStorage(baseDir)
+	if _, err := reopened.Load("early"); err != ErrSessionNotFound {
+		t.Fatalf("expected the session to be unknown before routing, got %v", err)
+	}
+	if err := reopened.SetProjectRoot("proj", root); err != nil {
+		t.Fatalf("SetProjectRoot failed: %v", err)
+	}
+	if loaded, err := reopened.Load("early"); err != nil || loaded.ProjectID != "proj" {
+		t.Fatalf("Load = %+v, %v", loaded, err)
+	}
+
+	// Clearing the override moves the sessions back.
+	if err := reopened.SetProjectRoot("proj", ""); err != nil {
+		t.Fatalf("SetProjectRoot(\"\") failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "sessions", "early.json")); err != nil {
+		t.Errorf("expected early.json back under the base directory: %v", err)
+	}
+	if msgs, err := reopened.GetMessages("early"); err != nil || len(msgs) != 1 {
+		t.Fatalf("GetMessages after clearing = %v, %v", msgs, err)
+	}
+}
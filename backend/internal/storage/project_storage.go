@@ -12,11 +12,20 @@ import (
 )
 
 type projectData struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Path      string    `json:"path"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Path            string    `json:"path"`
+	CleanupCommands []string  `json:"cleanup_commands,omitempty"`
+	StorageDir      string    `json:"storage_dir,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	Guardrails   *domain.GuardrailPolicy   `json:"guardrails,omitempty"`
+	WorkingHours *domain.WorkingHours      `json:"working_hours,omitempty"`
+	Watch        *domain.Watch             `json:"watch,omitempty"`
+	ToolPolicy   *domain.ToolPolicy        `json:"tool_policy,omitempty"`
+	CostBudget   *domain.CostBudget        `json:"cost_budget,omitempty"`
+	PullRequests *domain.PullRequestConfig `json:"pull_requests,omitempty"`
 }
 
 // ProjectStorage manages project configurations.
@@ -123,11 +132,19 @@ func (s *ProjectStorage) listUnlocked() ([]domain.Project, error) {
 	projects := make([]domain.Project, len(raw))
 	for i, r := range raw {
 		projects[i] = domain.Project{
-			ID:        r.ID,
-			Name:      r.Name,
-			Path:      r.Path,
-			CreatedAt: r.CreatedAt,
-			UpdatedAt: r.UpdatedAt,
+			ID:              r.ID,
+			Name:            r.Name,
+			Path:            r.Path,
+			CleanupCommands: r.CleanupCommands,
+			StorageDir:      r.StorageDir,
+			CreatedAt:       r.CreatedAt,
+			UpdatedAt:       r.UpdatedAt,
+			Guardrails:      r.Guardrails,
+			WorkingHours:    r.WorkingHours,
+			Watch:           r.Watch,
+			ToolPolicy:      r.ToolPolicy,
+			CostBudget:      r.CostBudget,
+			PullRequests:    r.PullRequests,
 		}
 	}
 	return projects, nil
@@ -137,11 +154,19 @@ func (s *ProjectStorage) writeUnlocked(projects []domain.Project) error {
 	raw := make([]projectData, len(projects))
 	for i, p := range projects {
 		raw[i] = projectData{
-			ID:        p.ID,
-			Name:      p.Name,
-			Path:      p.Path,
-			CreatedAt: p.CreatedAt,
-			UpdatedAt: p.UpdatedAt,
+			ID:              p.ID,
+			Name:            p.Name,
+			Path:            p.Path,
+			CleanupCommands: p.CleanupCommands,
+			StorageDir:      p.StorageDir,
+			CreatedAt:       p.CreatedAt,
+			UpdatedAt:       p.UpdatedAt,
+			Guardrails:      p.Guardrails,
+			WorkingHours:    p.WorkingHours,
+			Watch:           p.Watch,
+			ToolPolicy:      p.ToolPolicy,
+			CostBudget:      p.CostBudget,
+			PullRequests:    p.PullRequests,
 		}
 	}
 
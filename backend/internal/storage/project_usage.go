@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// ProjectUsage maps project ID to the usage of all its sessions, including
+// deleted ones.
+type ProjectUsage map[string]domain.UsageStats
+
+// ProjectUsageStorage persists per-project usage counters in a single file.
+// Missions keep theirs the same way, in a file of their own.
+type ProjectUsageStorage struct {
+	baseDir string
+	file    string
+	mu      sync.Mutex
+}
+
+// NewProjectUsageStorage creates a project usage storage rooted at baseDir.
+func NewProjectUsageStorage(baseDir string) *ProjectUsageStorage {
+	return &ProjectUsageStorage{baseDir: baseDir, file: "project_usage.json"}
+}
+
+// NewMissionUsageStorage creates a storage for the usage counters of
+// missions, keyed by mission ID, rooted at baseDir.
+func NewMissionUsageStorage(baseDir string) *ProjectUsageStorage {
+	return &ProjectUsageStorage{baseDir: baseDir, file: "mission_usage.json"}
+}
+
+func (s *ProjectUsageStorage) path() string {
+	return filepath.Join(s.baseDir, s.file)
+}
+
+// Load returns the persisted counters, or an empty set if none exist.
+func (s *ProjectUsageStorage) Load() (ProjectUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ProjectUsage{}, nil
+		}
+		return nil, fmt.Errorf("failed to read project usage: %w", err)
+	}
+	usage := ProjectUsage{}
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("failed to parse project usage: %w", err)
+	}
+	return usage, nil
+}
+
+// Save replaces the persisted counters.
+func (s *ProjectUsageStorage) Save(usage ProjectUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(usage, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal project usage: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write project usage: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename project usage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrQuarantineItemNotFound = errors.New("quarantine item not found")
+
+// Quarantine item statuses. Items start open until a reviewer dismisses
+// them as a false positive or confirms them.
+const (
+	QuarantineOpen      = "open"
+	QuarantineDismissed = "dismissed"
+	QuarantineConfirmed = "confirmed"
+)
+
+// QuarantineItem is agent output a guardrail flagged or blocked.
+type QuarantineItem struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	ProjectID string    `json:"project_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// Source is the kind of output scanned, e.g. output or tool_call.
+	Source string `json:"source"`
+	// Action is the strongest action taken, flag or block.
+	Action   string              `json:"action"`
+	Findings []QuarantineFinding `json:"findings"`
+	// Content is the output as the agent produced it.
+	Content string `json:"content"`
+
+	Status     string     `json:"status"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// QuarantineFinding is one rule match; Match is masked.
+type QuarantineFinding struct {
+	Rule     string `json:"rule"`
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Match    string `json:"match,omitempty"`
+}
+
+// QuarantineStorage keeps quarantine items in a single JSONL file.
+// Resolving an item rewrites the file, which stays small because only
+// flagged output lands in it.
+type QuarantineStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewQuarantineStorage creates a quarantine store rooted at baseDir.
+func NewQuarantineStorage(baseDir string) *QuarantineStorage {
+	return &QuarantineStorage{baseDir: baseDir}
+}
+
+func (s *QuarantineStorage) path() string {
+	return filepath.Join(s.baseDir, "quarantine.jsonl")
+}
+
+// Append writes one item, stamping it with the current time if unset.
+func (s *QuarantineStorage) Append(item QuarantineItem) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now().UTC()
+	}
+	if item.Status == "" {
+		item.Status = QuarantineOpen
+	}
+	line, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quarantine item: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.baseDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create quarantine directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open quarantine: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write quarantine item: %w", err)
+	}
+	return f.Sync()
+}
+
+// List returns the items for sessionID, or every item when it is empty,
+// oldest first. A non-empty status keeps only items with that status.
+func (s *QuarantineStorage) List(sessionID, status string) ([]QuarantineItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.readUnlocked()
+	if err != nil {
+		return nil, err
+	}
+	items := []QuarantineItem{}
+	for _, item := range all {
+		if (sessionID == "" || item.SessionID == sessionID) && (status == "" || item.Status == status) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Resolve sets an item's status and who resolved it.
+func (s *QuarantineStorage) Resolve(id, status, actor string) (QuarantineItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items, err := s.readUnlocked()
+	if err != nil {
+		return QuarantineItem{}, err
+	}
+	for i := range items {
+		if items[i].ID != id {
+			continue
+		}
+		now := time.Now().UTC()
+		items[i].Status = status
+		items[i].ResolvedBy = actor
+		items[i].ResolvedAt = &now
+		if err := s.writeUnlocked(items); err != nil {
+			return QuarantineItem{}, err
+		}
+		return items[i], nil
+	}
+	return QuarantineItem{}, ErrQuarantineItemNotFound
+}
+
+func (s *QuarantineStorage) readUnlocked() ([]QuarantineItem, error) {
+	f, err := os.Open(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open quarantine: %w", err)
+	}
+	defer f.Close()
+
+	var items []QuarantineItem
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var item QuarantineItem
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+func (s *QuarantineStorage) writeUnlocked(items []QuarantineItem) error {
+	var buf strings.Builder
+	for _, item := range items {
+		line, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal quarantine item: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	tmp := s.path() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write quarantine: %w", err)
+	}
+	if err := os.Rename(tmp, s.path()); err != nil {
+		return fmt.Errorf("failed to replace quarantine: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ReadMark records how far a user has read a session's messages.
+type ReadMark struct {
+	// Position is the number of messages the user has seen.
+	Position int       `json:"position"`
+	ReadAt   time.Time `json:"read_at"`
+}
+
+// ReadStates maps session ID to user to read mark.
+type ReadStates map[string]map[string]ReadMark
+
+// ReadStateStorage persists per-user session read marks in a single file.
+type ReadStateStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewReadStateStorage creates a read state storage rooted at baseDir.
+func NewReadStateStorage(baseDir string) *ReadStateStorage {
+	return &ReadStateStorage{baseDir: baseDir}
+}
+
+func (s *ReadStateStorage) path() string {
+	return filepath.Join(s.baseDir, "read_state.json")
+}
+
+// Load returns the persisted read marks, or an empty set if none exist.
+func (s *ReadStateStorage) Load() (ReadStates, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ReadStates{}, nil
+		}
+		return nil, fmt.Errorf("failed to read read state: %w", err)
+	}
+	states := ReadStates{}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("failed to parse read state: %w", err)
+	}
+	return states, nil
+}
+
+// Save replaces the persisted read marks.
+func (s *ReadStateStorage) Save(states ReadStates) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal read state: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write read state: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename read state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// RecoveryStatus is the state of a startup recovery pass.
+type RecoveryStatus string
+
+const (
+	RecoveryStatusRunning   RecoveryStatus = "running"
+	RecoveryStatusCompleted RecoveryStatus = "completed"
+	RecoveryStatusFailed    RecoveryStatus = "failed"
+)
+
+// RecoveryReport records what a startup recovery pass did. A report left in
+// the running state means the server stopped before recovery finished.
+type RecoveryReport struct {
+	StartedAt     time.Time      `json:"started_at"`
+	FinishedAt    *time.Time     `json:"finished_at,omitempty"`
+	Status        RecoveryStatus `json:"status"`
+	SessionsTotal int            `json:"sessions_total"`
+	// SessionsScanned counts sessions whose attempts have been checked.
+	SessionsScanned int `json:"sessions_scanned"`
+	// AttemptsClosed counts in-flight attempts marked interrupted.
+	AttemptsClosed int `json:"attempts_closed"`
+	// RunsScheduled counts interrupted runs restarted by a retry or resume
+	// recovery policy.
+	RunsScheduled int                `json:"runs_scheduled"`
+	Sessions      []RecoveredSession `json:"sessions"`
+	Error         string             `json:"error,omitempty"`
+	// PreviousShutdown is the report the last shutdown left behind. It is
+	// nil when the server stopped without one, e.g. after a crash.
+	PreviousShutdown *ShutdownReport `json:"previous_shutdown,omitempty"`
+}
+
+// RecoveredSession is a session recovery found interrupted.
+type RecoveredSession struct {
+	SessionID      string `json:"session_id"`
+	AttemptsClosed int    `json:"attempts_closed"`
+	// Policy is the recovery policy applied to the session.
+	Policy    string `json:"policy"`
+	Scheduled bool   `json:"scheduled,omitempty"`
+	// Note explains why a restart was skipped or failed.
+	Note string `json:"note,omitempty"`
+}
+
+// RecoveryReportStorage persists the report of the latest startup recovery
+// in a single file.
+type RecoveryReportStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewRecoveryReportStorage creates a recovery report storage rooted at
+// baseDir.
+func NewRecoveryReportStorage(baseDir string) *RecoveryReportStorage {
+	return &RecoveryReportStorage{baseDir: baseDir}
+}
+
+func (s *RecoveryReportStorage) path() string {
+	return filepath.Join(s.baseDir, "recovery_report.json")
+}
+
+// Load returns the persisted report, or nil if recovery never ran.
+func (s *RecoveryReportStorage) Load() (*RecoveryReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read recovery report: %w", err)
+	}
+	var report RecoveryReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse recovery report: %w", err)
+	}
+	return &report, nil
+}
+
+// Save replaces the persisted report.
+func (s *RecoveryReportStorage) Save(report RecoveryReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recovery report: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write recovery report: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename recovery report: %w", err)
+	}
+	return nil
+}
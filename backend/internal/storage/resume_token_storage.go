@@ -16,6 +16,12 @@ type ResumeTokenStorage interface {
 	LoadResumeToken(tokenID string) (*ResumeTokenMetadata, error)
 }
 
+// ResumeTokenLister is implemented by resume token stores that can list
+// every token they hold.
+type ResumeTokenLister interface {
+	ListResumeTokens() ([]*ResumeTokenMetadata, error)
+}
+
 type ResumeTokenMetadata struct {
 	TokenID          string     `json:"token_id"`
 	SessionID        string     `json:"session_id"`
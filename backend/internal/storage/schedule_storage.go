@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+var ErrScheduleNotFound = errors.New("schedule not found")
+
+// ScheduleStorage keeps session schedules in a single JSON file.
+type ScheduleStorage struct {
+	baseDir string
+	mu      sync.RWMutex
+}
+
+// NewScheduleStorage creates a schedule store rooted at baseDir.
+func NewScheduleStorage(baseDir string) *ScheduleStorage {
+	return &ScheduleStorage{baseDir: baseDir}
+}
+
+func (s *ScheduleStorage) path() string {
+	return filepath.Join(s.baseDir, "schedules.json")
+}
+
+// List returns every schedule, in the order they were created.
+func (s *ScheduleStorage) List() ([]domain.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listUnlocked()
+}
+
+// Get returns the schedule with the given ID.
+func (s *ScheduleStorage) Get(id string) (*domain.Schedule, error) {
+	schedules, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, sched := range schedules {
+		if sched.ID == id {
+			return &sched, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+}
+
+// Save creates or updates a schedule.
+func (s *ScheduleStorage) Save(schedule domain.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i, sched := range schedules {
+		if sched.ID == schedule.ID {
+			schedules[i] = schedule
+			found = true
+			break
+		}
+	}
+	if !found {
+		schedules = append(schedules, schedule)
+	}
+	return s.writeUnlocked(schedules)
+}
+
+// Delete removes the schedule with the given ID.
+func (s *ScheduleStorage) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	kept := make([]domain.Schedule, 0, len(schedules))
+	for _, sched := range schedules {
+		if sched.ID != id {
+			kept = append(kept, sched)
+		}
+	}
+	if len(kept) == len(schedules) {
+		return fmt.Errorf("%w: %s", ErrScheduleNotFound, id)
+	}
+	return s.writeUnlocked(kept)
+}
+
+func (s *ScheduleStorage) listUnlocked() ([]domain.Schedule, error) {
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []domain.Schedule{}, nil
+		}
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var schedules []domain.Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (s *ScheduleStorage) writeUnlocked(schedules []domain.Schedule) error {
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedules: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename schedules: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Shutdown phases, in order.
+const (
+	ShutdownPhaseDrain   = "drain"
+	ShutdownPhaseSuspend = "suspend"
+	ShutdownPhaseStop    = "stop"
+	ShutdownPhaseKill    = "kill"
+)
+
+// Outcomes of a session's live run at shutdown.
+const (
+	ShutdownSuspended = "suspended"
+	ShutdownStopped   = "stopped"
+	ShutdownKilled    = "killed"
+	ShutdownFailed    = "failed"
+)
+
+// ShutdownReport records how the executor's last shutdown went.
+type ShutdownReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	// Clean is set when every phase finished in time and every live run
+	// was suspended or stopped.
+	Clean    bool              `json:"clean"`
+	Phases   []ShutdownPhase   `json:"phases"`
+	Sessions []ShutdownSession `json:"sessions"`
+}
+
+// ShutdownPhase is one phase of a shutdown.
+type ShutdownPhase struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+	// Sessions counts the sessions the phase acted on.
+	Sessions int  `json:"sessions"`
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+// ShutdownSession is what shutdown did with one session's live run.
+type ShutdownSession struct {
+	SessionID string `json:"session_id"`
+	Outcome   string `json:"outcome"`
+	Phase     string `json:"phase"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ShutdownReportStorage keeps the latest shutdown report in a single file
+// until startup recovery takes it.
+type ShutdownReportStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewShutdownReportStorage creates a shutdown report storage rooted at
+// baseDir.
+func NewShutdownReportStorage(baseDir string) *ShutdownReportStorage {
+	return &ShutdownReportStorage{baseDir: baseDir}
+}
+
+func (s *ShutdownReportStorage) path() string {
+	return filepath.Join(s.baseDir, "shutdown_report.json")
+}
+
+// Save replaces the persisted report.
+func (s *ShutdownReportStorage) Save(report ShutdownReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.baseDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal shutdown report: %w", err)
+	}
+	tmpPath := s.path() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write shutdown report: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path()); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename shutdown report: %w", err)
+	}
+	return nil
+}
+
+// Take returns the persisted report and removes it, so each report is
+// consumed by one startup. It returns nil if there is none.
+func (s *ShutdownReportStorage) Take() (*ShutdownReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read shutdown report: %w", err)
+	}
+	if err := os.Remove(s.path()); err != nil {
+		return nil, fmt.Errorf("failed to remove shutdown report: %w", err)
+	}
+	var report ShutdownReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse shutdown report: %w", err)
+	}
+	return &report, nil
+}
@@ -33,6 +33,17 @@ type Storage interface {
 type JSONFileStorage struct {
 	baseDir string
 	mu      sync.RWMutex
+	// roots maps project IDs to the storage roots their sessions are routed
+	// to (see SetProjectRoot); located maps the IDs of sessions kept under a
+	// project root to that root.
+	roots   map[string]string
+	located map[string]string
+	// index is the full-text index of the sessions' messages searched by
+	// SearchMessages.
+	index *messageIndex
+	// blobs, when set, holds the large raw payloads of message log
+	// records.
+	blobs *BlobStore
 }
 
 var (
@@ -99,9 +110,20 @@ func NewJSONFileStorage(baseDir string) (*JSONFileStorage, error) {
 
 	return &JSONFileStorage{
 		baseDir: baseDir,
+		roots:   make(map[string]string),
+		located: make(map[string]string),
+		index:   newMessageIndex(),
 	}, nil
 }
 
+// SetBlobStore moves the raw payloads of messages logged from now on to
+// blobs, shared with other sessions storing the same payload.
+func (s *JSONFileStorage) SetBlobStore(blobs *BlobStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs = blobs
+}
+
 func DefaultBaseDir() string {
 	if baseDir := os.Getenv("ORBITMESH_BASE_DIR"); baseDir != "" {
 		return baseDir
@@ -114,7 +136,7 @@ func DefaultBaseDir() string {
 }
 
 func (s *JSONFileStorage) sessionPath(id string) string {
-	return filepath.Join(s.baseDir, "sessions", id+".json")
+	return filepath.Join(s.sessionsDir(id), id+".json")
 }
 
 func (s *JSONFileStorage) Save(session *domain.Session) error {
@@ -134,7 +156,10 @@ func (s *JSONFileStorage) Save(session *domain.Session) error {
 		return fmt.Errorf("failed to marshal session: %w", err)
 	}
 
-	sessionsDir := filepath.Join(s.baseDir, "sessions")
+	if err := s.routeUnlocked(snap.ID, snap.ProjectID); err != nil {
+		return fmt.Errorf("%w: %v", ErrStorageWrite, err)
+	}
+	sessionsDir := s.sessionsDir(snap.ID)
 	f, err := os.CreateTemp(sessionsDir, snap.ID+".*.tmp")
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrStorageWrite, err)
@@ -179,6 +204,7 @@ func (s *JSONFileStorage) Save(session *domain.Session) error {
 		return fmt.Errorf("%w: %v", ErrStorageWrite, err)
 	}
 
+	s.index.saved(&snap)
 	return nil
 }
 
@@ -209,6 +235,8 @@ func (s *JSONFileStorage) Delete(id string) error {
 		}
 		return fmt.Errorf("failed to delete session file: %w", err)
 	}
+	delete(s.located, id)
+	s.index.remove(id)
 
 	return nil
 }
@@ -225,34 +253,39 @@ func (s *JSONFileStorage) List() ([]*domain.Session, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	sessionsDir := filepath.Join(s.baseDir, "sessions")
-	entries, err := os.ReadDir(sessionsDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []*domain.Session{}, nil
-		}
-		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
-	}
-
-	sessions := make([]*domain.Session, 0, len(entries))
+	sessions := []*domain.Session{}
 	var errs []error
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
-			continue
-		}
-
-		id := entry.Name()[:len(entry.Name())-5]
-		if err := validateSessionID(id); err != nil {
-			// Skip files with invalid names
-			continue
+	for _, sessionsDir := range s.sessionDirsUnlocked() {
+		entries, err := os.ReadDir(sessionsDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read sessions directory: %w", err)
 		}
 
-		session, err := s.loadUnlocked(id)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("session %s: %w", id, err))
-			continue
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+				continue
+			}
+
+			id := entry.Name()[:len(entry.Name())-5]
+			if err := validateSessionID(id); err != nil {
+				// Skip files with invalid names
+				continue
+			}
+			if s.sessionsDir(id) != sessionsDir {
+				// A stale copy; the session lives under another root.
+				continue
+			}
+
+			session, err := s.loadUnlocked(id)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("session %s: %w", id, err))
+				continue
+			}
+			sessions = append(sessions, session)
 		}
-		sessions = append(sessions, session)
 	}
 
 	if len(errs) > 0 {
@@ -270,6 +303,12 @@ func (s *JSONFileStorage) GetMessages(id string) ([]domain.Message, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	return s.readMessagesUnlocked(id)
+}
+
+// readMessagesUnlocked rebuilds the session's messages from its message log,
+// falling back to the messages saved in its record.
+func (s *JSONFileStorage) readMessagesUnlocked(id string) ([]domain.Message, error) {
 	messagesFromLog, logErr := s.readMessagesFromJSONLUnlocked(id)
 	if logErr == nil {
 		return messagesFromLog, nil
@@ -287,6 +326,45 @@ func (s *JSONFileStorage) GetMessages(id string) ([]domain.Message, error) {
 	return s.getMessagesUnlocked(id)
 }
 
+// GetMessagesPage returns up to limit messages starting at offset. Reading
+// the message log stops once the page is complete.
+func (s *JSONFileStorage) GetMessagesPage(id string, offset, limit int) ([]domain.Message, bool, error) {
+	if err := validateSessionID(id); err != nil {
+		return nil, false, err
+	}
+	if offset < 0 || limit <= 0 {
+		return nil, false, fmt.Errorf("invalid page: offset %d, limit %d", offset, limit)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages, logErr := s.readMessageLogUnlocked(id, offset+limit)
+	var corruptionErr *MessageLogCorruptionError
+	switch {
+	case logErr == nil, errors.As(logErr, &corruptionErr) && len(messages) > 0:
+	case errors.As(logErr, &corruptionErr), errors.Is(logErr, os.ErrNotExist), errors.Is(logErr, ErrSessionNotFound):
+		var err error
+		if messages, err = s.getMessagesUnlocked(id); err != nil {
+			return nil, false, err
+		}
+	default:
+		return nil, false, logErr
+	}
+	page, more := PageMessages(messages, offset, limit)
+	return page, more, nil
+}
+
+// PageMessages returns up to limit of messages starting at offset, and
+// whether more follow.
+func PageMessages(messages []domain.Message, offset, limit int) ([]domain.Message, bool) {
+	if offset >= len(messages) {
+		return []domain.Message{}, false
+	}
+	end := min(offset+limit, len(messages))
+	return messages[offset:end], end < len(messages)
+}
+
 func (s *JSONFileStorage) getMessagesUnlocked(id string) ([]domain.Message, error) {
 	filePath := s.sessionPath(id)
 
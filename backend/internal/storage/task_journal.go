@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// TaskJournalStorage persists the task change journal in a single file.
+type TaskJournalStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewTaskJournalStorage creates a task journal storage rooted at baseDir.
+func NewTaskJournalStorage(baseDir string) *TaskJournalStorage {
+	return &TaskJournalStorage{baseDir: baseDir}
+}
+
+func (s *TaskJournalStorage) path() string {
+	return filepath.Join(s.baseDir, "task_journal.json")
+}
+
+// Load returns the persisted changes, oldest first, or none if none exist.
+func (s *TaskJournalStorage) Load() ([]domain.TaskChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read task journal: %w", err)
+	}
+	var changes []domain.TaskChange
+	if err := json.Unmarshal(data, &changes); err != nil {
+		return nil, fmt.Errorf("failed to parse task journal: %w", err)
+	}
+	return changes, nil
+}
+
+// Save replaces the persisted changes.
+func (s *TaskJournalStorage) Save(changes []domain.TaskChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal task journal: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write task journal: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename task journal: %w", err)
+	}
+	return nil
+}
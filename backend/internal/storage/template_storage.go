@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+// TemplateStorage keeps prompt templates in a single JSON file.
+type TemplateStorage struct {
+	baseDir string
+	mu      sync.RWMutex
+}
+
+// NewTemplateStorage creates a template store rooted at baseDir.
+func NewTemplateStorage(baseDir string) *TemplateStorage {
+	return &TemplateStorage{baseDir: baseDir}
+}
+
+func (s *TemplateStorage) path() string {
+	return filepath.Join(s.baseDir, "templates.json")
+}
+
+// List returns every template, in the order they were created.
+func (s *TemplateStorage) List() ([]domain.PromptTemplate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listUnlocked()
+}
+
+// Get returns the template with the given ID.
+func (s *TemplateStorage) Get(id string) (*domain.PromptTemplate, error) {
+	templates, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if t.ID == id {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, id)
+}
+
+// Save creates or updates a template.
+func (s *TemplateStorage) Save(template domain.PromptTemplate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templates, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	found := false
+	for i, t := range templates {
+		if t.ID == template.ID {
+			templates[i] = template
+			found = true
+			break
+		}
+	}
+	if !found {
+		templates = append(templates, template)
+	}
+	return s.writeUnlocked(templates)
+}
+
+// Delete removes the template with the given ID.
+func (s *TemplateStorage) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templates, err := s.listUnlocked()
+	if err != nil {
+		return err
+	}
+	kept := make([]domain.PromptTemplate, 0, len(templates))
+	for _, t := range templates {
+		if t.ID != id {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) == len(templates) {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, id)
+	}
+	return s.writeUnlocked(kept)
+}
+
+func (s *TemplateStorage) listUnlocked() ([]domain.PromptTemplate, error) {
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []domain.PromptTemplate{}, nil
+		}
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+	var templates []domain.PromptTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (s *TemplateStorage) writeUnlocked(templates []domain.PromptTemplate) error {
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create template directory: %w", err)
+	}
+	data, err := json.MarshalIndent(templates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal templates: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write templates: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename templates: %w", err)
+	}
+	return nil
+}
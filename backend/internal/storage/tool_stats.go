@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ToolStat aggregates the outcomes of one tool for one agent config.
+type ToolStat struct {
+	Calls    int64 `json:"calls"`
+	Failures int64 `json:"failures"`
+	// TotalLatencyMS sums latencies of the LatencySamples calls whose start
+	// and finish were both observed.
+	TotalLatencyMS int64     `json:"total_latency_ms"`
+	LatencySamples int64     `json:"latency_samples"`
+	LastUsedAt     time.Time `json:"last_used_at"`
+}
+
+// ToolStats maps agent ID to tool name to aggregate.
+type ToolStats map[string]map[string]ToolStat
+
+// ToolStatsStorage persists per-agent tool usage aggregates in a single file.
+type ToolStatsStorage struct {
+	baseDir string
+	mu      sync.Mutex
+}
+
+// NewToolStatsStorage creates a tool stats storage rooted at baseDir.
+func NewToolStatsStorage(baseDir string) *ToolStatsStorage {
+	return &ToolStatsStorage{baseDir: baseDir}
+}
+
+func (s *ToolStatsStorage) path() string {
+	return filepath.Join(s.baseDir, "tool_stats.json")
+}
+
+// Load returns the persisted aggregates, or an empty set if none exist.
+func (s *ToolStatsStorage) Load() (ToolStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ToolStats{}, nil
+		}
+		return nil, fmt.Errorf("failed to read tool stats: %w", err)
+	}
+	stats := ToolStats{}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse tool stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Save replaces the persisted aggregates.
+func (s *ToolStatsStorage) Save(stats ToolStats) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	filePath := s.path()
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool stats: %w", err)
+	}
+	tmpPath := filePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write tool stats: %w", err)
+	}
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename tool stats: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage usage categories.
+const (
+	UsageSessions     = "sessions"
+	UsageMessages     = "messages"
+	UsageAttempts     = "attempts"
+	UsageArchive      = "archive"
+	UsageQuarantine   = "quarantine"
+	UsageResumeTokens = "resume_tokens"
+	UsageEvents       = "events"
+	UsageTerminals    = "terminals"
+	UsageSnapshots    = "snapshots"
+	UsageBlobs        = "blobs"
+	UsageOther        = "other"
+)
+
+// UsageReporter is implemented by storages that can report how many bytes
+// they hold, by category.
+type UsageReporter interface {
+	Usage() (map[string]int64, error)
+}
+
+// Usage reports the bytes on disk under the base directory and the project
+// roots' session directories, by category.
+func (s *JSONFileStorage) Usage() (map[string]int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage := make(map[string]int64)
+	err := filepath.WalkDir(s.baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(s.baseDir, path)
+		if err != nil {
+			return nil
+		}
+		usage[usageCategory(rel)] += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Project roots hold only session records and message logs.
+	for _, dir := range s.sessionDirsUnlocked()[1:] {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			usage[usageCategory(filepath.Join("sessions", entry.Name()))] += info.Size()
+		}
+	}
+	return usage, nil
+}
+
+// usageCategory classifies a file by its path relative to the base
+// directory.
+func usageCategory(rel string) string {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch parts[0] {
+	case "sessions":
+		if len(parts) > 2 {
+			switch parts[1] {
+			case "attempts":
+				return UsageAttempts
+			case "archive":
+				return UsageArchive
+			case "quarantine":
+				return UsageQuarantine
+			case "resume_tokens":
+				return UsageResumeTokens
+			case "events":
+				return UsageEvents
+			}
+			return UsageOther
+		}
+		if strings.HasSuffix(rel, ".messages.jsonl") {
+			return UsageMessages
+		}
+		return UsageSessions
+	case "terminals":
+		return UsageTerminals
+	case "snapshots":
+		return UsageSnapshots
+	case "blobs":
+		return UsageBlobs
+	}
+	return UsageOther
+}
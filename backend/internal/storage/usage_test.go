@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func TestJSONFileStorage_Usage(t *testing.T) {
+	s, err := NewJSONFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewJSONFileStorage failed: %v", err)
+	}
+	if err := s.Save(domain.NewSession("alpha", "claude", "/repo")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := s.AppendMessageLog("alpha", MessageProjectionAppend, domain.MessageKindUser, "hello", nil, time.Now()); err != nil {
+		t.Fatalf("AppendMessageLog failed: %v", err)
+	}
+	if err := s.SaveRunAttempt(&RunAttemptMetadata{AttemptID: "a1", SessionID: "alpha", StartedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveRunAttempt failed: %v", err)
+	}
+
+	usage, err := s.Usage()
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	for _, category := range []string{UsageSessions, UsageMessages, UsageAttempts} {
+		if usage[category] <= 0 {
+			t.Errorf("usage[%s] = %d, want > 0 (usage %v)", category, usage[category], usage)
+		}
+	}
+	if usage[UsageArchive] != 0 || usage[UsageTerminals] != 0 {
+		t.Errorf("usage = %v, want no archive or terminal bytes", usage)
+	}
+}
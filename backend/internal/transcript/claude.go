@@ -0,0 +1,226 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// claudeLine is the part of a line of Claude Code's stream-json output that
+// carries tool calls and their results.
+type claudeLine struct {
+	Type    string `json:"type"`
+	Message struct {
+		Role    string `json:"role"`
+		Content []struct {
+			Type      string          `json:"type"`
+			ID        string          `json:"id"`
+			Name      string          `json:"name"`
+			Input     json.RawMessage `json:"input"`
+			ToolUseID string          `json:"tool_use_id"`
+			Content   json.RawMessage `json:"content"`
+			IsError   bool            `json:"is_error"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+type claudeToolBlock struct {
+	block block
+	id    string
+}
+
+// claudeToolBlocks reads the tool calls of a Claude assistant line and the
+// tool results of a Claude user line. ok is false for anything else.
+func claudeToolBlocks(raw json.RawMessage) (tools []claudeToolBlock, ok bool) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, false
+	}
+	var line claudeLine
+	if err := json.Unmarshal(raw, &line); err != nil {
+		return nil, false
+	}
+	if (line.Type != "assistant" && line.Type != "user") || line.Message.Role != line.Type {
+		return nil, false
+	}
+	for _, content := range line.Message.Content {
+		switch content.Type {
+		case "tool_use":
+			tools = append(tools, claudeToolBlock{
+				block: block{Kind: blockToolCall, Title: content.Name, Body: indentJSON(content.Input)},
+				id:    content.ID,
+			})
+		case "tool_result":
+			tools = append(tools, claudeToolBlock{
+				block: block{Kind: blockToolResult, Body: toolResultText(content.Content), IsError: content.IsError},
+				id:    content.ToolUseID,
+			})
+		}
+	}
+	return tools, true
+}
+
+// toolResultText reads a tool result's content, which is either a string or
+// a list of content blocks.
+func toolResultText(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return string(raw)
+	}
+	var texts []string
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		} else {
+			texts = append(texts, "["+part.Type+"]")
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// WriteClaudeNDJSONMarkdown converts the stream-json output of Claude Code,
+// one JSON object per line, into Markdown. Text and tool calls come from the
+// stream events, tool results from the user lines; lines that are not JSON
+// are skipped.
+func WriteClaudeNDJSONMarkdown(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	// Increase buffer size for large lines
+	const maxCapacity = 1024 * 1024 // 1MB
+	scanner.Buffer(make([]byte, maxCapacity), maxCapacity)
+
+	c := &claudeConverter{mdWriter: mdWriter{w: w}}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var wrapper struct {
+			Type  string          `json:"type"`
+			Event json.RawMessage `json:"event"`
+		}
+		if err := json.Unmarshal(line, &wrapper); err != nil {
+			continue
+		}
+
+		switch wrapper.Type {
+		case "system":
+			c.system(line)
+		case "stream_event":
+			c.streamEvent(wrapper.Event)
+		case "user":
+			c.user(line)
+		}
+		// "assistant" lines are periodic snapshots of what the stream
+		// events already carried.
+	}
+	c.flushText()
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return c.err
+}
+
+type claudeConverter struct {
+	mdWriter
+	text    strings.Builder
+	toolUse *strings.Builder
+}
+
+func (c *claudeConverter) flushText() {
+	if c.text.Len() > 0 {
+		c.printf("%s\n", c.text.String())
+		c.text.Reset()
+	}
+}
+
+func (c *claudeConverter) system(line []byte) {
+	var init struct {
+		Subtype           string `json:"subtype"`
+		CWD               string `json:"cwd"`
+		SessionID         string `json:"session_id"`
+		Model             string `json:"model"`
+		PermissionMode    string `json:"permissionMode"`
+		ClaudeCodeVersion string `json:"claude_code_version"`
+	}
+	if err := json.Unmarshal(line, &init); err != nil || init.Subtype != "init" {
+		return
+	}
+	c.printf("# Claude Code Session\n\n")
+	c.printf("**Session ID:** `%s`\n\n", init.SessionID)
+	c.printf("**Model:** `%s`\n\n", init.Model)
+	c.printf("**Working Directory:** `%s`\n\n", init.CWD)
+	c.printf("**Version:** `%s`\n\n", init.ClaudeCodeVersion)
+	c.printf("**Permission Mode:** `%s`\n\n", init.PermissionMode)
+	c.printf("---\n")
+}
+
+// user writes the tool results of a user line, collapsed so they don't
+// clutter the transcript.
+func (c *claudeConverter) user(line []byte) {
+	tools, _ := claudeToolBlocks(line)
+	for _, tool := range tools {
+		if tool.block.Kind == blockToolResult {
+			c.toolResult(tool.block)
+		}
+	}
+}
+
+func (c *claudeConverter) streamEvent(data json.RawMessage) {
+	var event struct {
+		Type         string `json:"type"`
+		ContentBlock struct {
+			Type string `json:"type"`
+			Name string `json:"name"`
+		} `json:"content_block"`
+		Delta struct {
+			Type        string `json:"type"`
+			Text        string `json:"text"`
+			PartialJSON string `json:"partial_json"`
+		} `json:"delta"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return
+	}
+
+	switch event.Type {
+	case "message_start":
+		c.flushText()
+	case "content_block_start":
+		c.flushText()
+		if event.ContentBlock.Type == "tool_use" {
+			c.toolUse = &strings.Builder{}
+			c.printf("### Tool: `%s`\n\n", event.ContentBlock.Name)
+		}
+	case "content_block_delta":
+		switch event.Delta.Type {
+		case "text_delta":
+			c.text.WriteString(event.Delta.Text)
+		case "input_json_delta":
+			if c.toolUse != nil {
+				c.toolUse.WriteString(event.Delta.PartialJSON)
+			}
+		}
+	case "content_block_stop":
+		// A text block may be followed by more text, so only tool calls
+		// are written here.
+		if c.toolUse != nil {
+			input := c.toolUse.String()
+			if indented := indentJSON(json.RawMessage(input)); indented != "" {
+				input = indented
+			}
+			c.printf("%s", fenced("json", input))
+			c.toolUse = nil
+		}
+	case "message_stop":
+		c.flushText()
+		c.printf("---\n")
+	}
+}
@@ -0,0 +1,105 @@
+package transcript
+
+import (
+	"html/template"
+	"io"
+	"time"
+)
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"truncate": func(s string) string { return truncate(s, maxToolResult) },
+	"rfc3339":  func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.5; color: #1f2328; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: 0.25rem 1rem; }
+dt { font-weight: 600; }
+section { margin: 0.75rem 0; }
+h2 { font-size: 1rem; margin: 1.5rem 0 0.5rem; text-transform: uppercase; letter-spacing: 0.05em; color: #59636e; }
+.text { white-space: pre-wrap; }
+.thought { color: #59636e; font-style: italic; border-left: 3px solid #d1d9e0; padding-left: 0.75rem; }
+.error { color: #d1242f; border-left: 3px solid #d1242f; padding-left: 0.75rem; }
+.system, .metric { color: #59636e; font-size: 0.875rem; }
+pre { background: #f6f8fa; padding: 0.75rem; overflow-x: auto; border-radius: 6px; }
+summary { cursor: pointer; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<dl>
+<dt>Session ID</dt><dd><code>{{.SessionID}}</code></dd>
+{{- if .ProviderType}}
+<dt>Provider</dt><dd><code>{{.ProviderType}}</code></dd>
+{{- end}}
+{{- if .WorkingDir}}
+<dt>Working Directory</dt><dd><code>{{.WorkingDir}}</code></dd>
+{{- end}}
+{{- if not .CreatedAt.IsZero}}
+<dt>Created</dt><dd>{{rfc3339 .CreatedAt}}</dd>
+{{- end}}
+{{- if .Usage}}
+<dt>Usage</dt><dd>{{.Usage}}</dd>
+{{- end}}
+</dl>
+<hr>
+{{- range .Entries}}
+{{- if .Heading}}
+<h2>{{.Heading}}</h2>
+{{- end}}
+{{- with .Block}}
+{{- if or (eq .Kind "user") (eq .Kind "assistant")}}
+<section class="{{.Kind}} text">{{.Body}}</section>
+{{- else if eq .Kind "thought"}}
+<section class="thought text">{{.Body}}</section>
+{{- else if eq .Kind "tool_call"}}
+<details class="tool-call"><summary>Tool: <code>{{.Title}}</code></summary>{{if .Body}}<pre><code>{{.Body}}</code></pre>{{end}}</details>
+{{- else if eq .Kind "tool_result"}}
+<details class="tool-result"><summary>{{if .IsError}}Tool Error{{else}}Tool Result{{end}}</summary><pre>{{truncate .Body}}</pre></details>
+{{- else if eq .Kind "plan"}}
+<section class="plan"><strong>Plan</strong><div class="text">{{.Body}}</div></section>
+{{- else if eq .Kind "error"}}
+<section class="error text"><strong>Error:</strong> {{.Body}}</section>
+{{- else if eq .Kind "system"}}
+<section class="system">{{.Body}}</section>
+{{- else if eq .Kind "metric"}}
+<section class="metric">Metrics: {{.Body}}</section>
+{{- else if eq .Kind "diagnostic"}}
+<details class="diagnostic"><summary>Diagnostics</summary><pre>{{.Body}}</pre></details>
+{{- end}}
+{{- end}}
+{{- end}}
+</body>
+</html>
+`))
+
+type htmlEntry struct {
+	Heading string
+	Block   block
+}
+
+func writeHTML(w io.Writer, t Transcript) error {
+	data := struct {
+		Transcript
+		Title   string
+		Usage   string
+		Entries []htmlEntry
+	}{Transcript: t, Title: t.Title, Usage: usageLine(t)}
+	if data.Title == "" {
+		data.Title = "Session " + t.SessionID
+	}
+
+	var speaker blockKind
+	for _, b := range blocks(t.Messages) {
+		entry := htmlEntry{Block: b}
+		if (b.Kind == blockUser || b.Kind == blockAssistant) && b.Kind != speaker {
+			entry.Heading = speakerName(b.Kind)
+			speaker = b.Kind
+		}
+		data.Entries = append(data.Entries, entry)
+	}
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,127 @@
+package transcript
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// mdWriter writes Markdown, keeping the first write error.
+type mdWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (m *mdWriter) printf(format string, args ...any) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+func (m *mdWriter) toolCall(b block) {
+	m.printf("### Tool: `%s`\n\n", b.Title)
+	if b.Body != "" {
+		m.printf("%s", fenced("json", b.Body))
+	}
+}
+
+// toolResult writes a tool's result collapsed, so long outputs don't bury
+// the conversation.
+func (m *mdWriter) toolResult(b block) {
+	summary := "Tool Result"
+	if b.IsError {
+		summary = "Tool Error"
+	}
+	m.printf("<details><summary>%s</summary>\n\n", summary)
+	m.printf("%s", fenced("", truncate(b.Body, maxToolResult)))
+	m.printf("</details>\n\n")
+}
+
+// fenced puts text in a code block whose fence is longer than any run of
+// backticks in it.
+func fenced(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n\n"
+}
+
+// quoted prefixes every line of text with "> ".
+func quoted(text string) string {
+	return "> " + strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", "\n> ") + "\n\n"
+}
+
+func writeMarkdown(w io.Writer, t Transcript) error {
+	m := &mdWriter{w: w}
+	title := t.Title
+	if title == "" {
+		title = "Session " + t.SessionID
+	}
+	m.printf("# %s\n\n", title)
+	m.printf("- **Session ID:** `%s`\n", t.SessionID)
+	if t.ProviderType != "" {
+		m.printf("- **Provider:** `%s`\n", t.ProviderType)
+	}
+	if t.WorkingDir != "" {
+		m.printf("- **Working Directory:** `%s`\n", t.WorkingDir)
+	}
+	if !t.CreatedAt.IsZero() {
+		m.printf("- **Created:** %s\n", t.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	if usage := usageLine(t); usage != "" {
+		m.printf("- **Usage:** %s\n", usage)
+	}
+	m.printf("\n---\n\n")
+
+	var speaker blockKind
+	for _, b := range blocks(t.Messages) {
+		if b.Kind == blockUser || b.Kind == blockAssistant {
+			if b.Kind != speaker {
+				m.printf("## %s\n\n", speakerName(b.Kind))
+				speaker = b.Kind
+			}
+			m.printf("%s\n\n", strings.TrimRight(b.Body, "\n"))
+			continue
+		}
+		switch b.Kind {
+		case blockThought:
+			m.printf("%s", quoted("_Thinking:_ "+b.Body))
+		case blockToolCall:
+			m.toolCall(b)
+		case blockToolResult:
+			m.toolResult(b)
+		case blockPlan:
+			m.printf("### Plan\n\n%s\n\n", strings.TrimRight(b.Body, "\n"))
+		case blockError:
+			m.printf("%s", quoted("**Error:** "+b.Body))
+		case blockSystem:
+			m.printf("_%s_\n\n", strings.TrimSpace(b.Body))
+		case blockMetric:
+			m.printf("_Metrics: %s_\n\n", strings.TrimSpace(b.Body))
+		case blockDiagnostic:
+			m.printf("<details><summary>Diagnostics</summary>\n\n%s</details>\n\n", fenced("", b.Body))
+		}
+	}
+	return m.err
+}
+
+func speakerName(kind blockKind) string {
+	if kind == blockUser {
+		return "User"
+	}
+	return "Assistant"
+}
+
+// usageLine summarizes the session's token totals and cost.
+func usageLine(t Transcript) string {
+	if t.Usage == nil || (t.Usage.InputTokens == 0 && t.Usage.OutputTokens == 0 && t.Usage.CostUSD == 0) {
+		return ""
+	}
+	line := fmt.Sprintf("%d input tokens, %d output tokens", t.Usage.InputTokens, t.Usage.OutputTokens)
+	if t.Usage.CostUSD > 0 {
+		line += fmt.Sprintf(", $%.4f", t.Usage.CostUSD)
+	}
+	return line
+}
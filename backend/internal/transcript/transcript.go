@@ -0,0 +1,253 @@
+// Package transcript renders a session's message history as Markdown, HTML
+// or NDJSON, for pasting into pull requests and docs.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+// Format is an export format.
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	// FormatNDJSON writes one stored message per line, provider JSON
+	// included.
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat reads a format name. Empty means Markdown.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "markdown", "md":
+		return FormatMarkdown, nil
+	case "html":
+		return FormatHTML, nil
+	case "ndjson", "jsonl":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("unknown format %q: want markdown, html or ndjson", s)
+}
+
+// ContentType is the HTTP content type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension is the file extension of the format, without the dot.
+func (f Format) Extension() string {
+	switch f {
+	case FormatHTML:
+		return "html"
+	case FormatNDJSON:
+		return "ndjson"
+	}
+	return "md"
+}
+
+// Transcript is a session's details and message history.
+type Transcript struct {
+	SessionID    string
+	Title        string
+	ProviderType string
+	WorkingDir   string
+	CreatedAt    time.Time
+	Usage        *domain.UsageStats
+	Messages     []domain.Message
+}
+
+// Render writes the transcript in format f.
+func Render(w io.Writer, t Transcript, f Format) error {
+	switch f {
+	case FormatMarkdown:
+		return writeMarkdown(w, t)
+	case FormatHTML:
+		return writeHTML(w, t)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, msg := range t.Messages {
+			if err := enc.Encode(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", f)
+}
+
+type blockKind string
+
+const (
+	blockUser       blockKind = "user"
+	blockAssistant  blockKind = "assistant"
+	blockThought    blockKind = "thought"
+	blockToolCall   blockKind = "tool_call"
+	blockToolResult blockKind = "tool_result"
+	blockPlan       blockKind = "plan"
+	blockError      blockKind = "error"
+	blockSystem     blockKind = "system"
+	blockMetric     blockKind = "metric"
+	blockDiagnostic blockKind = "diagnostic"
+)
+
+// maxToolResult caps how much of a tool's result the Markdown and HTML
+// transcripts show.
+const maxToolResult = 4000
+
+// block is one rendered entry of a transcript. Tool calls carry their input
+// as JSON in Body.
+type block struct {
+	Kind    blockKind
+	At      time.Time
+	Title   string
+	Body    string
+	IsError bool
+}
+
+// blocks turns the messages into the entries a transcript shows. Tool calls
+// and results come from the provider JSON kept with the messages where the
+// provider's format is known; a tool call reported more than once, as it
+// starts and finishes, is shown once, with the first input reported.
+func blocks(messages []domain.Message) []block {
+	var out []block
+	seenCalls := make(map[string]int)
+	addCall := func(b block, id string) {
+		if id != "" {
+			if i, ok := seenCalls[id]; ok {
+				// A call reported as it starts streaming has no input yet.
+				if out[i].Body == "" {
+					out[i].Body = b.Body
+				}
+				return
+			}
+			seenCalls[id] = len(out)
+		}
+		out = append(out, b)
+	}
+
+	for _, msg := range messages {
+		if tools, ok := claudeToolBlocks(msg.Raw); ok {
+			for _, tb := range tools {
+				tb.block.At = msg.Timestamp
+				if tb.block.Kind == blockToolCall {
+					addCall(tb.block, tb.id)
+				} else {
+					out = append(out, tb.block)
+				}
+			}
+			if msg.Kind == domain.MessageKindSystem || msg.Kind == domain.MessageKindToolUse {
+				continue
+			}
+		}
+
+		b := block{At: msg.Timestamp, Body: msg.Contents}
+		switch msg.Kind {
+		case domain.MessageKindUser:
+			b.Kind = blockUser
+		case domain.MessageKindOutput:
+			b.Kind = blockAssistant
+		case domain.MessageKindThought:
+			b.Kind = blockThought
+		case domain.MessageKindPlan:
+			b.Kind = blockPlan
+		case domain.MessageKindError:
+			b.Kind, b.IsError = blockError, true
+		case domain.MessageKindMetric:
+			b.Kind = blockMetric
+		case domain.MessageKindDiagnostic:
+			b.Kind = blockDiagnostic
+		case domain.MessageKindToolUse:
+			name, id := splitToolUse(msg.Contents)
+			addCall(block{Kind: blockToolCall, At: msg.Timestamp, Title: name, Body: toolInput(msg.Raw)}, id)
+			continue
+		case domain.MessageKindSystem:
+			// Most system messages name the provider metadata that
+			// arrived; only status changes and notices read as history.
+			if msg.Notice == nil && !strings.HasPrefix(msg.Contents, "status: ") {
+				continue
+			}
+			b.Kind = blockSystem
+		default:
+			continue
+		}
+		if strings.TrimSpace(b.Body) == "" {
+			continue
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// splitToolUse reads the "name: id (subagent parent)" contents of a tool use
+// message.
+func splitToolUse(contents string) (name, id string) {
+	contents, _, _ = strings.Cut(contents, " (subagent ")
+	i := strings.LastIndex(contents, ": ")
+	if i < 0 {
+		return contents, ""
+	}
+	return contents[:i], contents[i+2:]
+}
+
+// toolInput finds the input of a tool call in the provider JSON of a tool use
+// message: Claude's can_use_tool requests, OpenAI function calls and ACP
+// tool calls.
+func toolInput(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var msg struct {
+		Input    json.RawMessage `json:"input"`
+		RawInput json.RawMessage `json:"rawInput"`
+		Request  struct {
+			Input json.RawMessage `json:"input"`
+		} `json:"request"`
+		Function struct {
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return ""
+	}
+	for _, candidate := range []json.RawMessage{msg.Input, msg.RawInput, msg.Request.Input, json.RawMessage(msg.Function.Arguments)} {
+		if s := indentJSON(candidate); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func indentJSON(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return ""
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "\n... (truncated)"
+}
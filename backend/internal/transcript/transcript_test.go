@@ -0,0 +1,149 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ricochet1k/orbitmesh/internal/domain"
+)
+
+func testTranscript() Transcript {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	msg := func(kind domain.MessageKind, contents, raw string) domain.Message {
+		m := domain.Message{ID: contents, Kind: kind, Contents: contents, Timestamp: at}
+		if raw != "" {
+			m.Raw = json.RawMessage(raw)
+		}
+		return m
+	}
+	return Transcript{
+		SessionID:    "s1",
+		Title:        "Fix <the> bug",
+		ProviderType: "claude",
+		CreatedAt:    at,
+		Usage:        &domain.UsageStats{InputTokens: 120, OutputTokens: 45, CostUSD: 0.0123},
+		Messages: []domain.Message{
+			msg(domain.MessageKindUser, "Please fix it", ""),
+			msg(domain.MessageKindThought, "Looking at the code", ""),
+			msg(domain.MessageKindOutput, "Here is ```code```", ""),
+			msg(domain.MessageKindToolUse, "Bash: t1", `{"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"t1","name":"Bash","input":{}}}`),
+			msg(domain.MessageKindSystem, "model", `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test"}}]}}`),
+			msg(domain.MessageKindSystem, "role", `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"FAIL <x>","is_error":true}]}}`),
+			msg(domain.MessageKindToolUse, "read_file: c2", `{"id":"c2","type":"function","function":{"name":"read_file","arguments":"{\"path\":\"main.go\"}"}}`),
+			msg(domain.MessageKindToolUse, "read_file: c2", ""),
+			msg(domain.MessageKindSystem, "tool_use_start", `{"type":"stream_event"}`),
+			msg(domain.MessageKindSystem, "status: running -> idle", ""),
+			msg(domain.MessageKindMetric, "in=120 out=45 requests=1", ""),
+			msg(domain.MessageKindOutput, "Done.", ""),
+		},
+	}
+}
+
+func TestRender_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, testTranscript(), FormatMarkdown); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Fix <the> bug\n",
+		"- **Usage:** 120 input tokens, 45 output tokens, $0.0123\n",
+		"## User\n\nPlease fix it\n",
+		"> _Thinking:_ Looking at the code\n",
+		"## Assistant\n\nHere is ```code```\n",
+		"### Tool: `Bash`\n\n```json\n{\n  \"command\": \"go test\"\n}\n```\n",
+		"<details><summary>Tool Error</summary>\n\n```\nFAIL <x>\n```\n",
+		"### Tool: `read_file`\n\n```json\n{\n  \"path\": \"main.go\"\n}\n```\n",
+		"_status: running -> idle_\n",
+		"_Metrics: in=120 out=45 requests=1_\n",
+		"Done.\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "### Tool: `Bash`"); n != 1 {
+		t.Errorf("Bash shown %d times, want once", n)
+	}
+	if n := strings.Count(out, "### Tool: `read_file`"); n != 1 {
+		t.Errorf("read_file shown %d times, want once", n)
+	}
+	if strings.Contains(out, "tool_use_start") || strings.Count(out, "## Assistant") != 1 {
+		t.Errorf("unexpected markdown:\n%s", out)
+	}
+}
+
+func TestRender_HTMLEscapes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, testTranscript(), FormatHTML); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"<title>Fix &lt;the&gt; bug</title>", "FAIL &lt;x&gt;", "Tool Error", "<h2>User</h2>", "Metrics: in=120"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<the>") {
+		t.Errorf("html does not escape the title:\n%s", out)
+	}
+}
+
+func TestRender_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	tr := testTranscript()
+	if err := Render(&buf, tr, FormatNDJSON); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(tr.Messages) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(tr.Messages))
+	}
+	var first domain.Message
+	if err := json.Unmarshal([]byte(lines[3]), &first); err != nil || len(first.Raw) == 0 {
+		t.Fatalf("line 4 = %s, %v", lines[3], err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatMarkdown, "MD": FormatMarkdown, "html": FormatHTML, "jsonl": FormatNDJSON} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected an error for pdf")
+	}
+}
+
+func TestWriteClaudeNDJSONMarkdown(t *testing.T) {
+	input := strings.Join([]string{
+		`{"type":"system","subtype":"init","session_id":"abc","model":"claude","cwd":"/w","claude_code_version":"2.0","permissionMode":"default"}`,
+		`{"type":"stream_event","event":{"type":"message_start"}}`,
+		`{"type":"stream_event","event":{"type":"content_block_start","content_block":{"type":"text"}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"Running "}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"text_delta","text":"tests"}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_start","content_block":{"type":"tool_use","name":"Bash"}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"input_json_delta","partial_json":"{\"command\":"}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_delta","delta":{"type":"input_json_delta","partial_json":"\"ls\"}"}}}`,
+		`{"type":"stream_event","event":{"type":"content_block_stop"}}`,
+		`{"type":"stream_event","event":{"type":"message_stop"}}`,
+		`not json`,
+		`{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"a.go"}]}]}}`,
+	}, "\n")
+
+	var buf bytes.Buffer
+	if err := WriteClaudeNDJSONMarkdown(strings.NewReader(input), &buf); err != nil {
+		t.Fatalf("WriteClaudeNDJSONMarkdown: %v", err)
+	}
+	want := "# Claude Code Session\n\n**Session ID:** `abc`\n\n**Model:** `claude`\n\n**Working Directory:** `/w`\n\n" +
+		"**Version:** `2.0`\n\n**Permission Mode:** `default`\n\n---\n" +
+		"Running tests\n### Tool: `Bash`\n\n```json\n{\n  \"command\": \"ls\"\n}\n```\n\n---\n" +
+		"<details><summary>Tool Result</summary>\n\n```\na.go\n```\n\n</details>\n\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("markdown =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+// ErrorCode is the stable, machine-readable identifier in ErrorResponse.Code.
+// Clients should branch on it rather than on the human-readable error text,
+// which may change. Codes are never renamed or reused; new ones may be added.
+type ErrorCode string
+
+// Generic codes, sent when no more specific code applies. Each corresponds to
+// an HTTP status.
+const (
+	ErrorCodeInvalidRequest ErrorCode = "invalid_request" // 400
+	ErrorCodeUnauthorized   ErrorCode = "unauthorized"    // 401
+	ErrorCodeForbidden      ErrorCode = "forbidden"       // 403
+	ErrorCodeNotFound       ErrorCode = "not_found"       // 404
+	ErrorCodeConflict       ErrorCode = "conflict"        // 409
+	ErrorCodeGone           ErrorCode = "gone"            // 410
+	ErrorCodeRateLimited    ErrorCode = "rate_limited"    // 429
+	ErrorCodeInternal       ErrorCode = "internal_error"  // 500
+	ErrorCodeNotImplemented ErrorCode = "not_implemented" // 501
+	ErrorCodeUnavailable    ErrorCode = "unavailable"     // 503
+	ErrorCodeTimeout        ErrorCode = "timeout"         // 504
+)
+
+// Specific codes.
+const (
+	ErrorCodeSessionNotFound     ErrorCode = "session_not_found"
+	ErrorCodeSessionExists       ErrorCode = "session_exists"
+	ErrorCodeProjectNotFound     ErrorCode = "project_not_found"
+	ErrorCodeAgentNotFound       ErrorCode = "agent_not_found"
+	ErrorCodeTemplateNotFound    ErrorCode = "template_not_found"
+	ErrorCodeProviderNotFound    ErrorCode = "provider_not_found"
+	ErrorCodeTerminalNotFound    ErrorCode = "terminal_not_found"
+	ErrorCodeInvalidState        ErrorCode = "invalid_state"
+	ErrorCodeProviderUnavailable ErrorCode = "provider_unavailable"
+	ErrorCodeQuotaExceeded       ErrorCode = "quota_exceeded"
+	ErrorCodeWorkingDirBusy      ErrorCode = "working_dir_busy"
+	ErrorCodeInvalidCSRFToken    ErrorCode = "invalid_csrf_token"
+	ErrorCodeInvalidResumeToken  ErrorCode = "invalid_resume_token"
+	ErrorCodeExpiredResumeToken  ErrorCode = "expired_resume_token"
+	ErrorCodeRevokedResumeToken  ErrorCode = "revoked_resume_token"
+	ErrorCodeOperationTimeout    ErrorCode = "operation_timeout"
+	ErrorCodeShuttingDown        ErrorCode = "shutting_down"
+	ErrorCodeStorageUnavailable  ErrorCode = "storage_unavailable"
+	ErrorCodeDemoRestricted      ErrorCode = "demo_restricted"
+	ErrorCodeDemoSessionLimit    ErrorCode = "demo_session_limit"
+	// ErrorCodeReadOnlyMirror (403) refuses a change on an instance that
+	// mirrors another; make it on the mirrored instance instead.
+	ErrorCodeReadOnlyMirror ErrorCode = "read_only_mirror"
+	// ErrorCodeReadOnlySession (403) refuses to run a session imported
+	// from another tool's log; start a new session to continue the work.
+	ErrorCodeReadOnlySession ErrorCode = "read_only_session"
+	// ErrorCodeSessionTakenOver (409) refuses input to a session a human
+	// has taken over from anyone but that human.
+	ErrorCodeSessionTakenOver ErrorCode = "session_taken_over"
+	// ErrorCodeCapabilityUnsupported (501) means the session's provider
+	// lacks the capability the request needs; see ProviderCapabilities.
+	ErrorCodeCapabilityUnsupported ErrorCode = "capability_unsupported"
+)
@@ -1,6 +1,9 @@
 package api
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type SessionState string
 
@@ -16,7 +19,9 @@ type SessionRequest struct {
 	// AgentID references a saved AgentConfig whose system_prompt, mcp_servers
 	// and custom fields are merged into the session at creation time.  Values
 	// supplied directly in the request take precedence over the agent defaults.
-	AgentID      string            `json:"agent_id,omitempty"`
+	AgentID string `json:"agent_id,omitempty"`
+	// WorkingDir may be ssh://[user@]host[:port]/path to run the provider on
+	// another machine over SSH.
 	WorkingDir   string            `json:"working_dir,omitempty"`
 	ProjectID    string            `json:"project_id,omitempty"`
 	Environment  map[string]string `json:"environment,omitempty"`
@@ -27,6 +32,118 @@ type SessionRequest struct {
 	TaskTitle    string            `json:"task_title,omitempty"`
 	SessionKind  string            `json:"session_kind,omitempty"`
 	Title        string            `json:"title,omitempty"`
+	// RecoveryPolicy overrides what startup recovery does with an interrupted
+	// run: "mark_interrupted", "auto_retry" or "auto_resume".
+	RecoveryPolicy string `json:"recovery_policy,omitempty"`
+	// PlanApproval stops the session's runs after the agent's first plan
+	// until it is approved with POST /api/sessions/{id}/plan/approve.
+	PlanApproval bool `json:"plan_approval,omitempty"`
+	// CommandApproval holds each shell command the agent proposes until it
+	// is approved with POST /api/sessions/{id}/commands/{commandID}/approve.
+	CommandApproval *CommandApproval `json:"command_approval,omitempty"`
+	// Features are provider-neutral feature flags: enable_web_search,
+	// allow_network_tools and verbose_tools. Agent flags fill in the rest.
+	Features map[string]bool `json:"features,omitempty"`
+	// ToolApproval holds each tool call the provider asks permission for
+	// until it is decided with
+	// POST /api/sessions/{id}/approvals/{approvalID}/decision.
+	ToolApproval *ToolApproval `json:"tool_approval,omitempty"`
+	// CostBudget limits what the session may spend across its runs.
+	CostBudget *CostBudget `json:"cost_budget,omitempty"`
+	// Metadata holds key-value pairs external systems stamp onto the
+	// session, such as ticket numbers or CI run IDs. List and search
+	// endpoints filter on it with metadata.<key>=<value> query parameters.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// MissionID adds the session to a mission.
+	MissionID string `json:"mission_id,omitempty"`
+	// Workspace runs the session in a checkout of its own instead of
+	// working_dir, so sessions in the same repository do not trample each
+	// other. It is removed when the session is deleted.
+	Workspace *SessionWorkspace `json:"workspace,omitempty"`
+	// Git puts the session on a git branch of its own and records every
+	// commit made on it; see GET /api/sessions/{id}/commits.
+	Git *SessionGit `json:"git,omitempty"`
+	// TemplateID applies a saved prompt template: its rendered
+	// system_prompt, provider_type and agent_id fill in what the request
+	// leaves empty.
+	TemplateID string `json:"template_id,omitempty"`
+	// TemplateVariables are the values of the template's variables.
+	// Built-ins such as task_title and repo default from the session.
+	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+}
+
+// SessionGit is the git branch a session works on.
+type SessionGit struct {
+	// Branch defaults to orbitmesh/task/<task_id>, or
+	// orbitmesh/session/<id> for sessions without a task. An existing
+	// branch is checked out as it is. Sessions in a workspace track the
+	// workspace's branch.
+	Branch string `json:"branch,omitempty"`
+	// BaseRef is the commit the session's commits follow. Set in
+	// responses only.
+	BaseRef string `json:"base_ref,omitempty"`
+	// CommitCount is how many commits the session has made. Set in
+	// responses only.
+	CommitCount int `json:"commit_count,omitempty"`
+}
+
+// SessionWorkspace is a git checkout made for one session under the
+// server's workspace directory.
+type SessionWorkspace struct {
+	// Mode is "worktree", a git worktree of a local repository, or
+	// "clone", a full clone of any repository git can clone.
+	Mode string `json:"mode"`
+	// Repo is the repository to check out. Defaults to the session's
+	// working directory.
+	Repo string `json:"repo,omitempty"`
+	// BaseRef is the commit, branch or tag the session's branch starts
+	// from; defaults to HEAD. Responses give the commit it resolved to.
+	BaseRef string `json:"base_ref,omitempty"`
+	// Branch is the branch created for the session; defaults to
+	// orbitmesh/session/<id>.
+	Branch string `json:"branch,omitempty"`
+	// Path is where the checkout was made. Set in responses only.
+	Path string `json:"path,omitempty"`
+}
+
+// SessionDryRunResponse is the response to POST /api/sessions/dry-run: the
+// config a SessionRequest resolves to once its provider config, project and
+// agent are merged in, as the session's first run would be built with it.
+// Secret environment, MCP server env and custom values are redacted.
+type SessionDryRunResponse struct {
+	// Valid is true when the session could be created and its first run
+	// would start; otherwise Problems says why not.
+	Valid        bool              `json:"valid"`
+	ProviderType string            `json:"provider_type"`
+	AgentID      string            `json:"agent_id,omitempty"`
+	ProjectID    string            `json:"project_id,omitempty"`
+	WorkingDir   string            `json:"working_dir"`
+	SessionKind  string            `json:"session_kind,omitempty"`
+	SystemPrompt string            `json:"system_prompt,omitempty"`
+	Environment  map[string]string `json:"environment,omitempty"`
+	MCPServers   []MCPServerConfig `json:"mcp_servers,omitempty"`
+	Custom       map[string]any    `json:"custom,omitempty"`
+	Features     map[string]bool   `json:"features,omitempty"`
+	// RecoveryPolicy is the effective policy, "" meaning the server
+	// default.
+	RecoveryPolicy  string      `json:"recovery_policy,omitempty"`
+	PlanApproval    bool        `json:"plan_approval,omitempty"`
+	CostBudget      *CostBudget `json:"cost_budget,omitempty"`
+	CleanupCommands []string    `json:"cleanup_commands,omitempty"`
+	// Capabilities is omitted when the provider type is not registered.
+	Capabilities *ProviderCapabilities `json:"capabilities,omitempty"`
+	Problems     []string              `json:"problems,omitempty"`
+	Warnings     []string              `json:"warnings,omitempty"`
+}
+
+// CostBudget limits the tokens or dollars a session or project may spend.
+// A zero limit is not enforced. Once either limit is reached no new runs
+// start, and running ones are suspended until the budget is raised
+// (action "suspend", the default) or cancelled ("cancel").
+type CostBudget struct {
+	LimitUSD    float64 `json:"limit_usd,omitempty"`
+	LimitTokens int64   `json:"limit_tokens,omitempty"`
+	Action      string  `json:"action,omitempty"`
 }
 
 type SessionInputRequest struct {
@@ -39,12 +156,108 @@ type SendMessageRequest struct {
 	Content      string `json:"content"`
 	ProviderID   string `json:"provider_id,omitempty"`
 	ProviderType string `json:"provider_type,omitempty"`
+	// Deadline timeboxes the run as a Go duration (e.g. "20m"). The agent is
+	// warned shortly before it passes and the run then ends as "timeboxed".
+	Deadline string `json:"deadline,omitempty"`
+	// Priority is "normal" (default) or "low". Low-priority runs go through
+	// the provider's batch queue when supported (only the openai provider
+	// has one), leaving the session suspended in a "queued_remote" wait
+	// until the result arrives. Other providers run them live.
+	Priority string `json:"priority,omitempty"`
+	// MessageID identifies the message for its delivery receipt; one is
+	// generated when empty. Resending a message ID the session already has
+	// does not deliver the message again.
+	MessageID string `json:"message_id,omitempty"`
+	// TokenBudget caps the input and output tokens the run may use. The
+	// agent is warned near it and the run then ends as "budget_exceeded".
+	TokenBudget int64 `json:"token_budget,omitempty"`
+	// OnBudget is "stop" (default) or "continue". A continuing run
+	// summarizes its work at the budget and ends as "budget_continued",
+	// and a fresh run picks up from the summary.
+	OnBudget string `json:"on_budget,omitempty"`
+	// TemplateID sends a saved prompt template's rendered prompt, followed
+	// by Content if any.
+	TemplateID string `json:"template_id,omitempty"`
+	// TemplateVariables are the values of the template's variables.
+	// Built-ins such as task_title and repo default from the session.
+	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+}
+
+// SendMessageResponse is the session a message was sent to, with the ID of
+// the message's delivery receipt. A message sent while the session is
+// running or suspended is queued; QueuePosition is its 1-based place in the
+// queue.
+type SendMessageResponse struct {
+	SessionResponse
+	MessageID     string `json:"message_id"`
+	Queued        bool   `json:"queued,omitempty"`
+	QueuePosition int    `json:"queue_position,omitempty"`
+}
+
+// QueuedMessage is a message waiting for the session's current run to end
+// or its suspension to resolve.
+type QueuedMessage struct {
+	MessageID    string    `json:"message_id"`
+	Content      string    `json:"content"`
+	ProviderID   string    `json:"provider_id,omitempty"`
+	ProviderType string    `json:"provider_type,omitempty"`
+	Deadline     string    `json:"deadline,omitempty"`
+	Priority     string    `json:"priority,omitempty"`
+	TokenBudget  int64     `json:"token_budget,omitempty"`
+	OnBudget     string    `json:"on_budget,omitempty"`
+	QueuedBy     string    `json:"queued_by,omitempty"`
+	QueuedAt     time.Time `json:"queued_at"`
+}
+
+// QueuedMessageListResponse is returned by GET /api/sessions/{id}/queue,
+// oldest message first.
+type QueuedMessageListResponse struct {
+	Messages []QueuedMessage `json:"messages"`
+}
+
+// MessageReceiptResponse is returned by GET
+// /api/sessions/{id}/messages/{messageID}/receipt. Delivery is "queued"
+// until the message reaches the provider, then "delivered" or "failed".
+type MessageReceiptResponse struct {
+	MessageID string    `json:"message_id"`
+	SessionID string    `json:"session_id"`
+	AttemptID string    `json:"attempt_id"`
+	SentAt    time.Time `json:"sent_at"`
+	Delivery  string    `json:"delivery"`
+	Error     string    `json:"error,omitempty"`
 }
 
 type ResumeSessionRequest struct {
 	TokenID string `json:"token_id"`
 }
 
+// ResumeToken is a token minted when a run was suspended, which resumes
+// that run attempt once. Status is "active", "consumed", "revoked" or
+// "expired".
+type ResumeToken struct {
+	TokenID          string     `json:"token_id"`
+	SessionID        string     `json:"session_id"`
+	AttemptID        string     `json:"attempt_id"`
+	Status           string     `json:"status"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ExpiresAt        time.Time  `json:"expires_at,omitzero"`
+	ConsumedAt       *time.Time `json:"consumed_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevocationReason string     `json:"revocation_reason,omitempty"`
+}
+
+type ResumeTokenListResponse struct {
+	Tokens []ResumeToken `json:"tokens"`
+}
+
+// RevokeResumeTokenRequest optionally records why a token was revoked.
+type RevokeResumeTokenRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// MCPServerConfig is an MCP server started with a session's runs. Env
+// values are Go templates rendered for each run with .SessionID,
+// .ProjectID, .ProjectPath, .WorkingDir, .APIBaseURL and .APIToken.
 type MCPServerConfig struct {
 	Name    string            `json:"name"`
 	Command string            `json:"command"`
@@ -52,6 +265,31 @@ type MCPServerConfig struct {
 	Env     map[string]string `json:"env,omitempty"`
 }
 
+// MCPValidateRequest is the body for POST /api/v1/mcp/validate.
+type MCPValidateRequest struct {
+	Server     MCPServerConfig `json:"server"`
+	WorkingDir string          `json:"working_dir,omitempty"`
+	// TimeoutSeconds bounds the whole handshake; defaults to 10.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// MCPValidateResponse reports the outcome of a trial MCP server launch.
+type MCPValidateResponse struct {
+	OK              bool          `json:"ok"`
+	ServerName      string        `json:"server_name,omitempty"`
+	ServerVersion   string        `json:"server_version,omitempty"`
+	ProtocolVersion string        `json:"protocol_version,omitempty"`
+	Tools           []MCPToolInfo `json:"tools"`
+	Error           string        `json:"error,omitempty"`
+	Stderr          string        `json:"stderr,omitempty"`
+	DurationMS      int64         `json:"duration_ms"`
+}
+
+type MCPToolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
 type SessionResponse struct {
 	ID                  string `json:"id"`
 	ProviderType        string `json:"provider_type"`
@@ -66,37 +304,971 @@ type SessionResponse struct {
 	CreatedAt   time.Time    `json:"created_at"`
 	UpdatedAt   time.Time    `json:"updated_at"`
 	CurrentTask string       `json:"current_task,omitempty"`
+	Pinned      bool         `json:"pinned,omitempty"`
+	// RecoveryPolicy is the session's recovery override, if any.
+	RecoveryPolicy string `json:"recovery_policy,omitempty"`
+	PlanApproval   bool   `json:"plan_approval,omitempty"`
+	// Plan is the agent's plan in a session that requires plan approval.
+	Plan            *SessionPlan     `json:"plan,omitempty"`
+	CommandApproval *CommandApproval `json:"command_approval,omitempty"`
+	Features        map[string]bool  `json:"features,omitempty"`
+	// TakenOverBy is the human driving the session by hand while it is
+	// taken over.
+	TakenOverBy string `json:"taken_over_by,omitempty"`
+	// ToolApproval is the session's tool approval policy, if any.
+	ToolApproval *ToolApproval `json:"tool_approval,omitempty"`
+	// MessagePins are the session's key messages, oldest first.
+	MessagePins []MessagePin `json:"message_pins,omitempty"`
+	// QueuedMessages counts the messages waiting for the current run to end.
+	QueuedMessages int `json:"queued_messages,omitempty"`
+	// CostBudget is the session's own cost budget, if any.
+	CostBudget *CostBudget       `json:"cost_budget,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	// MissionID is the mission the session works in, if any.
+	MissionID string `json:"mission_id,omitempty"`
+	// Workspace is the checkout the session runs in, if it asked for one.
+	Workspace *SessionWorkspace `json:"workspace,omitempty"`
+	// Git is the branch the session works on, if it tracks one.
+	Git *SessionGit `json:"git,omitempty"`
+	// WaitSet lists the external tool calls a suspended run waits on. Only
+	// GET /api/sessions/{id} fills it in.
+	WaitSet *SessionWaitSet `json:"wait_set,omitempty"`
+	// LastReadPosition is how many of the session's messages the requesting
+	// user has seen; UnreadCount is how many agent messages arrived since.
+	LastReadPosition int `json:"last_read_position,omitempty"`
+	UnreadCount      int `json:"unread_count,omitempty"`
+}
+
+// SessionWaitSet is the set of external tool calls a suspended run waits
+// on. Each is resumed with its own token; the run continues once Quorum of
+// them are fulfilled.
+type SessionWaitSet struct {
+	Kind   string        `json:"kind"`
+	Quorum int           `json:"quorum"`
+	Waits  []SessionWait `json:"waits"`
+}
+
+type SessionWait struct {
+	Ref         string     `json:"ref"`
+	Fulfilled   bool       `json:"fulfilled"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
+}
+
+type PlanStatus string
+
+const (
+	PlanStatusProposed PlanStatus = "proposed"
+	PlanStatusApproved PlanStatus = "approved"
+)
+
+// SessionPlan is an agent's plan awaiting or past approval.
+type SessionPlan struct {
+	Content    string     `json:"content"`
+	Status     PlanStatus `json:"status"`
+	ProposedAt time.Time  `json:"proposed_at"`
+	// Edited is set when the approver replaced the agent's plan.
+	Edited     bool       `json:"edited,omitempty"`
+	ApprovedBy string     `json:"approved_by,omitempty"`
+	ApprovedAt *time.Time `json:"approved_at,omitempty"`
+}
+
+// PlanApproveRequest is the body for POST /api/sessions/{id}/plan/approve.
+// A non-empty Plan replaces the agent's plan before it is carried out.
+type PlanApproveRequest struct {
+	Plan string `json:"plan,omitempty"`
+}
+
+// PRDescriptionRequest is the body for POST /api/sessions/{id}/pr-description.
+// Base is the git revision the session's changes are diffed against,
+// HEAD when empty. Push opens the draft as a pull request, as
+// POST /api/sessions/{id}/pull-request does.
+type PRDescriptionRequest struct {
+	Base string `json:"base,omitempty"`
+	Push bool   `json:"push,omitempty"`
+}
+
+// PRDescriptionResponse is a pull request title and markdown body drafted
+// from a session's title, plan, final output and working directory diff.
+type PRDescriptionResponse struct {
+	Title    string   `json:"title"`
+	Body     string   `json:"body"`
+	Base     string   `json:"base"`
+	DiffStat string   `json:"diff_stat,omitempty"`
+	Files    []string `json:"files,omitempty"`
+}
+
+// PullRequestConfig is how a project's sessions open pull requests on
+// GitHub or GitLab. The provider's API host and token are configured on
+// the server; see ORBITMESH_GITHUB_TOKEN and ORBITMESH_GITLAB_TOKEN.
+type PullRequestConfig struct {
+	// Provider is "github" or "gitlab".
+	Provider string `json:"provider"`
+	// Repo is the GitHub "owner/name" or the GitLab project path.
+	Repo string `json:"repo"`
+	// Remote is the git remote the branch is pushed to, "origin" by default.
+	Remote string `json:"remote,omitempty"`
+	// Base is the branch pull requests target, the remote's default branch
+	// when empty.
+	Base string `json:"base,omitempty"`
+}
+
+// PullRequestRequest is the body of POST /api/sessions/{id}/pull-request.
+// An empty title or body is drafted as POST /api/sessions/{id}/pr-description
+// drafts them; the body always ends with links to the session's transcript
+// and task.
+type PullRequestRequest struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+	// Base overrides the project's target branch.
+	Base  string `json:"base,omitempty"`
+	Draft bool   `json:"draft,omitempty"`
+}
+
+// PullRequestResponse is the pull request opened for a session's branch.
+type PullRequestResponse struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	Number   int    `json:"number"`
+	Branch   string `json:"branch"`
+	Base     string `json:"base"`
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+}
+
+// SessionDiffResponse is the response to GET /api/sessions/{id}/diff:
+// everything that changed in the session's working directory since the
+// session was created, against BaseRef. Commits, uncommitted changes and
+// untracked files are all included.
+type SessionDiffResponse struct {
+	BaseRef    string            `json:"base_ref"`
+	Files      []DiffFileSummary `json:"files"`
+	Insertions int               `json:"insertions"`
+	Deletions  int               `json:"deletions"`
+	// Diff is the unified diff. It is cut at a line boundary past 1 MiB,
+	// and Truncated is then set; Files always lists every file.
+	Diff      string `json:"diff"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// DiffFileSummary is one changed file. Binary files have no line counts.
+type DiffFileSummary struct {
+	Path       string `json:"path"`
+	Insertions int    `json:"insertions"`
+	Deletions  int    `json:"deletions"`
+	Binary     bool   `json:"binary,omitempty"`
+}
+
+// BestOfNRequest is the body for POST /api/sessions/{id}/best-of-n. Each
+// candidate runs Prompt in its own git worktree; without Candidates, N
+// copies of the session's own provider run.
+type BestOfNRequest struct {
+	Prompt     string                 `json:"prompt"`
+	N          int                    `json:"n,omitempty"`
+	Candidates []BestOfNCandidateSpec `json:"candidates,omitempty"`
+}
+
+// BestOfNCandidateSpec picks the provider of one best-of-N candidate.
+type BestOfNCandidateSpec struct {
+	ProviderType string `json:"provider_type,omitempty"`
+	ProviderID   string `json:"provider_id,omitempty"`
+}
+
+// BestOfNSelectRequest is the body for POST /api/sessions/{id}/best-of-n/select.
+type BestOfNSelectRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// BestOfNResponse is a session's best-of-N group. Candidates carry a diff
+// against BaseRef while the group is running.
+type BestOfNResponse struct {
+	Prompt     string             `json:"prompt"`
+	BaseRef    string             `json:"base_ref"`
+	Status     string             `json:"status"`
+	CreatedAt  time.Time          `json:"created_at"`
+	WinnerID   string             `json:"winner_id,omitempty"`
+	DecidedBy  string             `json:"decided_by,omitempty"`
+	DecidedAt  *time.Time         `json:"decided_at,omitempty"`
+	Candidates []BestOfNCandidate `json:"candidates"`
+}
+
+// BestOfNCandidate is one candidate run of a best-of-N group.
+type BestOfNCandidate struct {
+	SessionID    string   `json:"session_id"`
+	ProviderType string   `json:"provider_type"`
+	ProviderID   string   `json:"provider_id,omitempty"`
+	Branch       string   `json:"branch"`
+	State        string   `json:"state,omitempty"`
+	Files        []string `json:"files,omitempty"`
+	Insertions   int      `json:"insertions"`
+	Deletions    int      `json:"deletions"`
+	DiffStat     string   `json:"diff_stat,omitempty"`
+	LastOutput   string   `json:"last_output,omitempty"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// HandoffRequest is the body for POST /api/sessions/{id}/handoff. The
+// provider is named by ProviderID, ProviderType or both; Instructions are
+// added to the handoff document the new provider starts from.
+//
+// With TargetSessionID instead of a provider, the session hands Message
+// (its last output when empty) and Instructions to that session, which
+// starts a run with it or queues it; the response is then a
+// HandoffMessageResponse.
+type HandoffRequest struct {
+	ProviderType    string `json:"provider_type,omitempty"`
+	ProviderID      string `json:"provider_id,omitempty"`
+	Instructions    string `json:"instructions,omitempty"`
+	TargetSessionID string `json:"target_session_id,omitempty"`
+	Message         string `json:"message,omitempty"`
+}
+
+// HandoffMessageResponse is a message handed from one session to another.
+// Its delivery can be followed at
+// GET /api/sessions/{target_session_id}/messages/{message_id}/receipt.
+type HandoffMessageResponse struct {
+	SessionID       string `json:"session_id"`
+	TargetSessionID string `json:"target_session_id"`
+	MessageID       string `json:"message_id"`
+	Content         string `json:"content"`
+}
+
+// HandoffResponse is a session's latest handoff to another provider.
+// Document is set once the current provider has summarized the session.
+type HandoffResponse struct {
+	Status           string           `json:"status"`
+	FromProviderType string           `json:"from_provider_type"`
+	FromProviderID   string           `json:"from_provider_id,omitempty"`
+	ToProviderType   string           `json:"to_provider_type"`
+	ToProviderID     string           `json:"to_provider_id,omitempty"`
+	Instructions     string           `json:"instructions,omitempty"`
+	Document         *HandoffDocument `json:"document,omitempty"`
+	RequestedBy      string           `json:"requested_by,omitempty"`
+	RequestedAt      time.Time        `json:"requested_at"`
+	CompletedAt      *time.Time       `json:"completed_at,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
+
+// HandoffDocument is the working context a provider hands to the next one.
+type HandoffDocument struct {
+	Summary       string   `json:"summary"`
+	OpenQuestions []string `json:"open_questions,omitempty"`
+	Decisions     []string `json:"decisions,omitempty"`
+	Files         []string `json:"files,omitempty"`
+	NextSteps     []string `json:"next_steps,omitempty"`
+}
+
+// TakeoverRequest is the body for POST /api/sessions/{id}/takeover.
+type TakeoverRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// HandBackRequest is the body for POST /api/sessions/{id}/takeover/hand-back.
+// Note is added to the briefing the agent is given.
+type HandBackRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// TakeoverResponse is a window in which a human drove a session by hand.
+// EndedAt and Briefing are set once the session is handed back;
+// BriefingError says why the briefing could not be given to the agent.
+type TakeoverResponse struct {
+	Active        bool       `json:"active"`
+	By            string     `json:"by"`
+	Reason        string     `json:"reason,omitempty"`
+	StartedAt     time.Time  `json:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+	HandedBackBy  string     `json:"handed_back_by,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	Briefing      string     `json:"briefing,omitempty"`
+	BriefingError string     `json:"briefing_error,omitempty"`
+}
+
+// SessionReadRequest is the body for POST /api/sessions/{id}/read. Without a
+// position every current message is marked read.
+type SessionReadRequest struct {
+	Position *int `json:"position,omitempty"`
+}
+
+// SessionUpdateRequest is the body for PATCH /api/sessions/{id}. Omitted
+// fields are left unchanged.
+type SessionUpdateRequest struct {
+	Pinned *bool `json:"pinned,omitempty"`
+	// RecoveryPolicy sets the session's recovery override; "" clears it.
+	RecoveryPolicy *string `json:"recovery_policy,omitempty"`
+	// CostBudget sets the session's cost budget; one without limits
+	// removes it.
+	CostBudget *CostBudget `json:"cost_budget,omitempty"`
+	// Metadata sets the given metadata entries; a null value removes one.
+	Metadata map[string]*string `json:"metadata,omitempty"`
+}
+
+// ExchangeEntry is one value in a session's exchange area, a key-value store
+// agents and humans use to pass structured results out of a session.
+type ExchangeEntry struct {
+	Value     json.RawMessage `json:"value"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	// UpdatedBy is "agent" for values the session's agent wrote, otherwise
+	// the user who wrote them.
+	UpdatedBy string `json:"updated_by,omitempty"`
+}
+
+// ExchangeResponse is returned by GET /api/sessions/{id}/exchange.
+type ExchangeResponse struct {
+	Entries map[string]ExchangeEntry `json:"entries"`
+}
+
+// ExchangeSetRequest is the body for PUT /api/sessions/{id}/exchange/{key}.
+// Value is any JSON value.
+type ExchangeSetRequest struct {
+	Value json.RawMessage `json:"value"`
+}
+
+// QuestionStatus is pending until a human answers, or cancelled if the run
+// ended first.
+type QuestionStatus string
+
+const (
+	QuestionStatusPending   QuestionStatus = "pending"
+	QuestionStatusAnswered  QuestionStatus = "answered"
+	QuestionStatusCancelled QuestionStatus = "cancelled"
+)
+
+// QuestionResponse is a question a session's agent asked a human.
+type QuestionResponse struct {
+	ID         string         `json:"id"`
+	SessionID  string         `json:"session_id"`
+	Question   string         `json:"question"`
+	Options    []string       `json:"options,omitempty"`
+	Status     QuestionStatus `json:"status"`
+	AskedAt    time.Time      `json:"asked_at"`
+	Answer     string         `json:"answer,omitempty"`
+	AnsweredBy string         `json:"answered_by,omitempty"`
+	AnsweredAt *time.Time     `json:"answered_at,omitempty"`
+}
+
+// QuestionListResponse is returned by GET /api/questions (pending questions
+// of all sessions) and GET /api/sessions/{id}/questions.
+type QuestionListResponse struct {
+	Questions []QuestionResponse `json:"questions"`
+}
+
+// CommandApproval makes a session's agent wait for approval before each
+// shell command. AutoApprove lists commands that run without asking; an
+// entry matches the command itself or the command followed by arguments,
+// and commands chained with shell operators always ask.
+type CommandApproval struct {
+	AutoApprove []string `json:"auto_approve,omitempty"`
+}
+
+// CommandStatus is proposed until a human decides, then running, and
+// completed or failed once the command exits.
+type CommandStatus string
+
+const (
+	CommandStatusProposed  CommandStatus = "proposed"
+	CommandStatusRejected  CommandStatus = "rejected"
+	CommandStatusRunning   CommandStatus = "running"
+	CommandStatusCompleted CommandStatus = "completed"
+	CommandStatusFailed    CommandStatus = "failed"
+)
+
+// CommandResponse is one shell command in a session's command history.
+// Approval is "auto", "approved" or "rejected", or empty for commands that
+// ran without being proposed.
+type CommandResponse struct {
+	ID          string        `json:"id"`
+	SessionID   string        `json:"session_id"`
+	ToolCallID  string        `json:"tool_call_id,omitempty"`
+	Command     string        `json:"command"`
+	Status      CommandStatus `json:"status"`
+	ProposedAt  time.Time     `json:"proposed_at"`
+	Approval    string        `json:"approval,omitempty"`
+	DecidedBy   string        `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time    `json:"decided_at,omitempty"`
+	Reason      string        `json:"reason,omitempty"`
+	StartedAt   *time.Time    `json:"started_at,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	ExitCode    *int          `json:"exit_code,omitempty"`
+	DurationMS  int64         `json:"duration_ms,omitempty"`
+}
+
+// CommandListResponse is returned by GET /api/sessions/{id}/commands.
+type CommandListResponse struct {
+	Commands []CommandResponse `json:"commands"`
+}
+
+// ProposeCommandRequest is the body for POST /api/sessions/{id}/commands,
+// sent by the approve_command tool before the agent runs a command.
+type ProposeCommandRequest struct {
+	Command    string `json:"command"`
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// RejectCommandRequest is the body for
+// POST /api/sessions/{id}/commands/{commandID}/reject. Reason is passed on
+// to the agent.
+type RejectCommandRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// ToolApproval makes each tool call the session's provider asks permission
+// for wait for a human to allow or deny it. AutoAllow lists tool names that
+// run without asking. Timeout is a Go duration (e.g. "10m") after which an
+// undecided call gets OnTimeout, "deny" (default) or "allow"; without one a
+// call waits until the run ends.
+type ToolApproval struct {
+	AutoAllow []string `json:"auto_allow,omitempty"`
+	Timeout   string   `json:"timeout,omitempty"`
+	OnTimeout string   `json:"on_timeout,omitempty"`
+}
+
+// ToolApprovalStatus is pending until the tool call is allowed or denied.
+type ToolApprovalStatus string
+
+const (
+	ToolApprovalStatusPending ToolApprovalStatus = "pending"
+	ToolApprovalStatusAllowed ToolApprovalStatus = "allowed"
+	ToolApprovalStatusDenied  ToolApprovalStatus = "denied"
+)
+
+// ToolApprovalResponse is one tool call that asked for permission.
+// DecidedBy is "timeout" for calls decided by the session's timeout action.
+type ToolApprovalResponse struct {
+	ID           string             `json:"id"`
+	SessionID    string             `json:"session_id"`
+	ToolCallID   string             `json:"tool_call_id,omitempty"`
+	ToolName     string             `json:"tool_name"`
+	Input        map[string]any     `json:"input,omitempty"`
+	Description  string             `json:"description,omitempty"`
+	Status       ToolApprovalStatus `json:"status"`
+	RequestedAt  time.Time          `json:"requested_at"`
+	DecidedBy    string             `json:"decided_by,omitempty"`
+	DecidedAt    *time.Time         `json:"decided_at,omitempty"`
+	Reason       string             `json:"reason,omitempty"`
+	UpdatedInput map[string]any     `json:"updated_input,omitempty"`
+}
+
+// ToolApprovalListResponse is returned by GET /api/sessions/{id}/approvals.
+type ToolApprovalListResponse struct {
+	Approvals []ToolApprovalResponse `json:"approvals"`
+}
+
+// ToolApprovalDecisionRequest is the body for
+// POST /api/sessions/{id}/approvals/{approvalID}/decision. Decision is
+// "allow" or "deny". Input replaces the tool call's input when allowing;
+// Reason is passed on to the agent.
+type ToolApprovalDecisionRequest struct {
+	Decision string         `json:"decision"`
+	Reason   string         `json:"reason,omitempty"`
+	Input    map[string]any `json:"input,omitempty"`
+}
+
+// WaitResponse is one thing a session is blocked on. Kind is a wait kind:
+// "waiting_on_human" (a question), "plan_approval", "command_approval",
+// "tool_approval", "tool_call" (an external tool call), "queued_remote"
+// (a provider batch queue) or "expired_resume_token" (an external tool call
+// that can no longer be resumed). Ref is the question, command or approval
+// ID, or the tool call. Priority is "high", "normal" or "low"; waits older
+// than a day are high.
+type WaitResponse struct {
+	Kind         string    `json:"kind"`
+	SessionID    string    `json:"session_id"`
+	SessionTitle string    `json:"session_title,omitempty"`
+	Ref          string    `json:"ref,omitempty"`
+	Summary      string    `json:"summary,omitempty"`
+	Since        time.Time `json:"since"`
+	AgeSeconds   int64     `json:"age_seconds"`
+	Priority     string    `json:"priority"`
+}
+
+// WaitListResponse is returned by GET /api/v1/waits, most urgent first and
+// then oldest first.
+type WaitListResponse struct {
+	Waits []WaitResponse `json:"waits"`
+}
+
+// AskQuestionRequest is the body for POST /api/sessions/{id}/questions,
+// sent by the ask_human tool. With options, the answer must be one of them.
+type AskQuestionRequest struct {
+	Question string   `json:"question"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// AnswerQuestionRequest is the body for
+// POST /api/sessions/{id}/questions/{questionID}/answer.
+type AnswerQuestionRequest struct {
+	Answer string `json:"answer"`
 }
 
 // ProjectRequest is the body for create/update project endpoints.
 type ProjectRequest struct {
 	Name string `json:"name"`
 	Path string `json:"path"`
+	// CleanupCommands are shell commands run in a session's working directory
+	// when it is stopped, killed or cleaned up, e.g. "docker compose down".
+	CleanupCommands []string `json:"cleanup_commands,omitempty"`
+	// StorageDir keeps the project's session history there instead of the
+	// server's base directory; relative paths are resolved against Path,
+	// e.g. ".orbitmesh".
+	StorageDir string `json:"storage_dir,omitempty"`
+	// Guardrails overrides the server's output guardrail policy for the
+	// project's sessions.
+	Guardrails *GuardrailPolicy `json:"guardrails,omitempty"`
+	// WorkingHours suspends the project's running sessions outside these
+	// hours and resumes them when the next window opens.
+	WorkingHours *WorkingHours `json:"working_hours,omitempty"`
+	// Watch starts sessions when files under Path that match its rules
+	// change.
+	Watch *Watch `json:"watch,omitempty"`
+	// ToolPolicy allows or denies the project's sessions' tool calls by
+	// rule before any approval is asked for.
+	ToolPolicy *ToolPolicy `json:"tool_policy,omitempty"`
+	// CostBudget limits what the project's sessions may spend together.
+	CostBudget *CostBudget `json:"cost_budget,omitempty"`
+	// PullRequests lets the project's sessions open pull requests with
+	// POST /api/sessions/{id}/pull-request.
+	PullRequests *PullRequestConfig `json:"pull_requests,omitempty"`
 }
 
 // ProjectResponse is the API representation of a project.
 type ProjectResponse struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Path            string    `json:"path"`
+	CleanupCommands []string  `json:"cleanup_commands,omitempty"`
+	StorageDir      string    `json:"storage_dir,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	Guardrails   *GuardrailPolicy   `json:"guardrails,omitempty"`
+	WorkingHours *WorkingHours      `json:"working_hours,omitempty"`
+	Watch        *Watch             `json:"watch,omitempty"`
+	ToolPolicy   *ToolPolicy        `json:"tool_policy,omitempty"`
+	CostBudget   *CostBudget        `json:"cost_budget,omitempty"`
+	PullRequests *PullRequestConfig `json:"pull_requests,omitempty"`
+}
+
+// ProjectUsageResponse is returned by GET /api/v1/projects/{id}/usage.
+// Usage counts every session the project ever had, including deleted ones;
+// Sessions lists its current sessions, most expensive first.
+type ProjectUsageResponse struct {
+	ProjectID string      `json:"project_id"`
+	Usage     UsageTotals `json:"usage"`
+	// CostBudget is the project's budget, and BudgetExceeded the limit it
+	// reached, if any.
+	CostBudget     *CostBudget           `json:"cost_budget,omitempty"`
+	BudgetExceeded string                `json:"budget_exceeded,omitempty"`
+	Sessions       []SessionUsageSummary `json:"sessions"`
+}
+
+// UsageTotals are token and cost totals. Cost is only known for providers
+// that report it.
+type UsageTotals struct {
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// SessionUsageSummary is the usage of one session.
+type SessionUsageSummary struct {
+	SessionID string `json:"session_id"`
+	UsageTotals
+}
+
+// ToolPolicy allows or denies tool calls by rule. Rules are checked in
+// order: Deny, DenyCommands, WritePaths, AllowCommands, Allow, then Default.
+// Allow and Deny list tool names; ACP agents' tools are named by kind, e.g.
+// "edit" or "execute". WritePaths, if set, denies file edits outside these
+// paths or globs, relative to the session's working directory; a trailing
+// "/**" matches everything under a directory. AllowCommands and
+// DenyCommands are regular expressions matched against shell commands.
+// Default is "allow", "deny", or empty to leave unmatched calls to the
+// session's approvals.
+type ToolPolicy struct {
+	Allow         []string `json:"allow,omitempty"`
+	Deny          []string `json:"deny,omitempty"`
+	WritePaths    []string `json:"write_paths,omitempty"`
+	AllowCommands []string `json:"allow_commands,omitempty"`
+	DenyCommands  []string `json:"deny_commands,omitempty"`
+	Default       string   `json:"default,omitempty"`
+}
+
+// WorkingHours limits when a project's sessions run unattended. Days are
+// three-letter weekday names (default Monday to Friday), Start and End are
+// "15:04" times in TimeZone (default the server's), and SessionKinds limits
+// the policy to sessions of those kinds.
+type WorkingHours struct {
+	Days         []string `json:"days,omitempty"`
+	Start        string   `json:"start"`
+	End          string   `json:"end"`
+	TimeZone     string   `json:"time_zone,omitempty"`
+	SessionKinds []string `json:"session_kinds,omitempty"`
+}
+
+// Watch starts a session whenever files matching one of its rules change.
+type Watch struct {
+	Rules []WatchRule `json:"rules"`
+}
+
+// WatchRule starts a ProviderType session when files matching Globs are
+// added, modified or removed. Globs are relative to the project path and
+// "**" matches any number of directories. Prompt is a Go text/template
+// with .Rule, .Summary, .Files, .Added, .Modified and .Removed; it defaults
+// to a prompt listing the changes. ProviderID and AgentID apply a stored
+// provider and agent config to the sessions as SessionRequest's do.
+type WatchRule struct {
+	Name         string   `json:"name"`
+	Globs        []string `json:"globs"`
+	Prompt       string   `json:"prompt,omitempty"`
+	ProviderType string   `json:"provider_type,omitempty"`
+	ProviderID   string   `json:"provider_id,omitempty"`
+	AgentID      string   `json:"agent_id,omitempty"`
+}
+
+// WatchTrigger is the latest session a watch rule started, or the error
+// that stopped it.
+type WatchTrigger struct {
+	Rule      string    `json:"rule"`
+	SessionID string    `json:"session_id,omitempty"`
+	Files     []string  `json:"files"`
+	At        time.Time `json:"at"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// WatchStatusResponse is the project's watch and what each rule last
+// triggered.
+type WatchStatusResponse struct {
+	Watch    *Watch         `json:"watch,omitempty"`
+	Triggers []WatchTrigger `json:"triggers"`
+}
+
+// ScheduleRequest creates or replaces a schedule that starts a
+// ProviderType session with Prompt. Exactly one of Cron, a five-field cron
+// expression or a macro such as @daily, and Interval, a Go duration such as
+// "6h", is set. Cron is read in TimeZone (default the server's). WorkingDir
+// defaults to the project's path. ProviderID and AgentID apply a stored
+// provider and agent config to the sessions as SessionRequest's do;
+// ProviderType defaults to the provider config's.
+type ScheduleRequest struct {
+	Name         string `json:"name"`
+	Cron         string `json:"cron,omitempty"`
+	Interval     string `json:"interval,omitempty"`
+	TimeZone     string `json:"time_zone,omitempty"`
+	ProviderType string `json:"provider_type,omitempty"`
+	ProviderID   string `json:"provider_id,omitempty"`
+	AgentID      string `json:"agent_id,omitempty"`
+	ProjectID    string `json:"project_id,omitempty"`
+	WorkingDir   string `json:"working_dir,omitempty"`
+	Prompt       string `json:"prompt"`
+	Paused       bool   `json:"paused,omitempty"`
+}
+
+// ScheduleRun is a session a schedule started, or the error that stopped
+// it. Skipped runs found the previous session still running.
+type ScheduleRun struct {
+	At        time.Time `json:"at"`
+	SessionID string    `json:"session_id,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Skipped   bool      `json:"skipped,omitempty"`
+}
+
+// ScheduleResponse is the API representation of a schedule and its latest
+// runs, oldest first.
+type ScheduleResponse struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Cron         string        `json:"cron,omitempty"`
+	Interval     string        `json:"interval,omitempty"`
+	TimeZone     string        `json:"time_zone,omitempty"`
+	ProviderType string        `json:"provider_type,omitempty"`
+	ProviderID   string        `json:"provider_id,omitempty"`
+	AgentID      string        `json:"agent_id,omitempty"`
+	ProjectID    string        `json:"project_id,omitempty"`
+	WorkingDir   string        `json:"working_dir"`
+	Prompt       string        `json:"prompt"`
+	Paused       bool          `json:"paused,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	NextRunAt    *time.Time    `json:"next_run_at,omitempty"`
+	LastRunAt    *time.Time    `json:"last_run_at,omitempty"`
+	Runs         []ScheduleRun `json:"runs"`
+}
+
+// ScheduleListResponse lists every schedule.
+type ScheduleListResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+}
+
+// MissionRequest creates or replaces a mission, which groups the sessions
+// of a team of agents working toward one goal.
+type MissionRequest struct {
+	Name      string `json:"name"`
+	Goal      string `json:"goal,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	// CostBudget limits what the mission's sessions may spend together,
+	// counted from when they joined.
+	CostBudget *CostBudget `json:"cost_budget,omitempty"`
+}
+
+// MissionSession is one session of a mission.
+type MissionSession struct {
+	SessionID string       `json:"session_id"`
+	Title     string       `json:"title,omitempty"`
+	State     SessionState `json:"state"`
+	Usage     UsageTotals  `json:"usage"`
+}
+
+// MissionResponse is the API representation of a mission. Status is
+// derived from its sessions: pending (none yet), running (any running),
+// waiting (any suspended) or idle.
+type MissionResponse struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	Goal       string      `json:"goal,omitempty"`
+	ProjectID  string      `json:"project_id,omitempty"`
+	CostBudget *CostBudget `json:"cost_budget,omitempty"`
+	Status     string      `json:"status"`
+	// Usage counts what the sessions spent since joining, including
+	// sessions since removed; BudgetExceeded is the limit it reached.
+	Usage          UsageTotals      `json:"usage"`
+	BudgetExceeded string           `json:"budget_exceeded,omitempty"`
+	Sessions       []MissionSession `json:"sessions"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// MissionListResponse lists every mission.
+type MissionListResponse struct {
+	Missions []MissionResponse `json:"missions"`
+}
+
+// MissionSessionRequest is the body for POST /api/v1/missions/{id}/sessions.
+type MissionSessionRequest struct {
+	SessionID string `json:"session_id"`
+}
+
+// MissionLogEntry is one entry of a mission's shared log. Author is
+// "agent" for entries a session's agent wrote, "system" for membership
+// changes, otherwise the user who wrote it.
+type MissionLogEntry struct {
+	At        time.Time `json:"at"`
+	Author    string    `json:"author"`
+	SessionID string    `json:"session_id,omitempty"`
+	Text      string    `json:"text"`
+}
+
+// MissionLogRequest adds an entry to a mission's log.
+type MissionLogRequest struct {
+	Text string `json:"text"`
+}
+
+// MissionLogResponse is a mission's log, oldest first.
+type MissionLogResponse struct {
+	Entries []MissionLogEntry `json:"entries"`
+}
+
+// PipelineRequest creates or replaces a pipeline: a DAG of steps, each run
+// in its own session. WorkingDir defaults to the project's path.
+type PipelineRequest struct {
+	Name       string         `json:"name"`
+	ProjectID  string         `json:"project_id,omitempty"`
+	WorkingDir string         `json:"working_dir,omitempty"`
+	Steps      []PipelineStep `json:"steps"`
+}
+
+// PipelineStep is one step of a pipeline. Prompt is a Go template rendered
+// with .Input, the run's input, and .Steps.<name>.Output, the last output
+// of each step it depends on. Role, if set, is the session's system prompt.
+// ProviderID and AgentID apply a stored provider and agent config to the
+// session as SessionRequest's do.
+type PipelineStep struct {
+	Name         string   `json:"name"`
+	ProviderType string   `json:"provider_type,omitempty"`
+	ProviderID   string   `json:"provider_id,omitempty"`
+	AgentID      string   `json:"agent_id,omitempty"`
+	Prompt       string   `json:"prompt"`
+	Role         string   `json:"role,omitempty"`
+	DependsOn    []string `json:"depends_on,omitempty"`
+}
+
+// PipelineResponse is the API representation of a pipeline. LastRun is its
+// latest run, if any.
+type PipelineResponse struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	ProjectID  string               `json:"project_id,omitempty"`
+	WorkingDir string               `json:"working_dir"`
+	Steps      []PipelineStep       `json:"steps"`
+	LastRun    *PipelineRunResponse `json:"last_run,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	UpdatedAt  time.Time            `json:"updated_at"`
+}
+
+// PipelineListResponse lists every pipeline.
+type PipelineListResponse struct {
+	Pipelines []PipelineResponse `json:"pipelines"`
+}
+
+// PipelineRunRequest is the body for POST /api/v1/pipelines/{id}/runs.
+type PipelineRunRequest struct {
+	Input string `json:"input,omitempty"`
+}
+
+// PipelineRunResponse is one run of a pipeline. Status is running,
+// completed or failed.
+type PipelineRunResponse struct {
+	ID          string            `json:"id"`
+	PipelineID  string            `json:"pipeline_id"`
+	Status      string            `json:"status"`
+	Input       string            `json:"input,omitempty"`
+	Steps       []PipelineStepRun `json:"steps"`
+	StartedAt   time.Time         `json:"started_at"`
+	CompletedAt *time.Time        `json:"completed_at,omitempty"`
+}
+
+// PipelineStepRun is the state of one step in a run: pending, running,
+// completed, failed, or skipped because a step it depends on did not
+// complete.
+type PipelineStepRun struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	SessionID   string     `json:"session_id,omitempty"`
+	Output      string     `json:"output,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// PipelineRunListResponse lists a pipeline's latest runs, newest first.
+type PipelineRunListResponse struct {
+	Runs []PipelineRunResponse `json:"runs"`
+}
+
+// TemplateRequest creates or replaces a prompt template. Prompt and
+// SystemPrompt are text/template templates, e.g. {{.repo}}, over the
+// variables a session or message supplies, the declared defaults and the
+// built-ins task_id, task_title, repo, project, working_dir,
+// provider_type and date. ProviderType and AgentID are presets for
+// sessions created with the template.
+type TemplateRequest struct {
+	Name         string             `json:"name"`
+	Description  string             `json:"description,omitempty"`
+	Prompt       string             `json:"prompt,omitempty"`
+	SystemPrompt string             `json:"system_prompt,omitempty"`
+	ProviderType string             `json:"provider_type,omitempty"`
+	AgentID      string             `json:"agent_id,omitempty"`
+	Variables    []TemplateVariable `json:"variables,omitempty"`
+}
+
+// TemplateVariable documents a template variable and its default.
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+// TemplateResponse is the API representation of a prompt template.
+type TemplateResponse struct {
+	TemplateRequest
 	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Path      string    `json:"path"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// TemplateListResponse lists every prompt template.
+type TemplateListResponse struct {
+	Templates []TemplateResponse `json:"templates"`
+}
+
+// GuardrailPolicy configures output guardrails. Actions maps a category
+// (secret, pii, content or a custom one) to off, flag or block.
+type GuardrailPolicy struct {
+	Actions map[string]string `json:"actions,omitempty"`
+	Rules   []GuardrailRule   `json:"rules,omitempty"`
+}
+
+// GuardrailRule is a custom regular expression checked in addition to the
+// built-in rules.
+type GuardrailRule struct {
+	Name     string `json:"name"`
+	Category string `json:"category,omitempty"`
+	Pattern  string `json:"pattern"`
+	Action   string `json:"action,omitempty"`
+}
+
 // ProjectListResponse wraps a list of projects.
 type ProjectListResponse struct {
 	Projects []ProjectResponse `json:"projects"`
 }
 
+// SessionImportResponse is returned after importing a session bundle or a
+// Claude Code log. The imported session is assigned a fresh ID;
+// SourceSessionID records the ID it had on the exporting instance, or the
+// log's Claude session ID.
+type SessionImportResponse struct {
+	Session         SessionResponse `json:"session"`
+	SourceSessionID string          `json:"source_session_id"`
+}
+
+// DevSeedResponse is returned by POST /api/v1/dev/seed, which is only
+// available when the server runs in dev mode.
+type DevSeedResponse struct {
+	ProjectIDs []string          `json:"project_ids"`
+	AgentIDs   []string          `json:"agent_ids"`
+	Sessions   []SessionResponse `json:"sessions"`
+}
+
 type SessionListResponse struct {
 	Sessions []SessionResponse `json:"sessions"`
 }
 
+// SessionSyncResponse lists the sessions created, updated or deleted after
+// the revision a client last saw. With Reset set, Sessions is the full list
+// and the client replaces its own.
+type SessionSyncResponse struct {
+	Revision int64             `json:"revision"`
+	Since    int64             `json:"since,omitempty"`
+	Reset    bool              `json:"reset,omitempty"`
+	Sessions []SessionResponse `json:"sessions"`
+	Deleted  []string          `json:"deleted,omitempty"`
+}
+
+// MirrorStatusResponse reports how far a read-only mirror has caught up
+// with the instance it mirrors.
+type MirrorStatusResponse struct {
+	// Upstream is the mirrored instance's URL, without credentials.
+	Upstream string `json:"upstream"`
+	// Revision is the upstream session list revision last applied, at
+	// SyncedAt.
+	Revision int64     `json:"revision"`
+	SyncedAt time.Time `json:"synced_at,omitzero"`
+	// Connected is set while the mirror follows the upstream realtime
+	// sessions.sync topic; otherwise it polls.
+	Connected bool `json:"connected"`
+	// Error is the last sync or connection error, cleared by the next
+	// successful sync.
+	Error string `json:"error,omitempty"`
+}
+
 type SessionMetrics struct {
 	TokensIn       int64     `json:"tokens_in"`
 	TokensOut      int64     `json:"tokens_out"`
 	RequestCount   int64     `json:"request_count"`
 	LastActivityAt time.Time `json:"last_activity_at,omitempty"`
+	// ContextWindow is how full the model's context window was at the
+	// session's latest request.
+	ContextWindow *ContextWindowUsage `json:"context_window,omitempty"`
+}
+
+// ContextWindowUsage is how much of its model's context window a session's
+// conversation fills. LimitTokens and Percent are omitted for models whose
+// window is unknown.
+type ContextWindowUsage struct {
+	Model       string    `json:"model,omitempty"`
+	UsedTokens  int64     `json:"used_tokens"`
+	LimitTokens int64     `json:"limit_tokens,omitempty"`
+	Percent     float64   `json:"percent,omitempty"`
+	NearLimit   bool      `json:"near_limit,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
 }
 
 type SessionStatusResponse struct {
@@ -116,19 +1288,42 @@ const (
 	EventTypeToolCall     EventType = "tool_call"
 	EventTypeThought      EventType = "thought"
 	EventTypePlan         EventType = "plan"
+	EventTypeProgress     EventType = "progress"
+	EventTypeLiveness     EventType = "liveness"
 )
 
 type Event struct {
 	// EventID is the monotonic SSE event sequence number. Clients should send
 	// this back as Last-Event-ID on reconnect to resume from where they left
 	// off. Zero means the event has no persistent ID (e.g. heartbeats).
-	EventID   int64     `json:"event_id,omitempty"`
+	EventID int64 `json:"event_id,omitempty"`
+	// Seq is the event's offset in its session's event journal, the SSE id
+	// of session event streams when the journal is enabled. Zero when the
+	// event was not journaled.
+	Seq       int64     `json:"seq,omitempty"`
 	Type      EventType `json:"type"`
 	Timestamp time.Time `json:"timestamp"`
 	SessionID string    `json:"session_id"`
 	Data      any       `json:"data"`
 }
 
+// HistoricalEvent is a session event read back from the persisted event
+// log. Seq numbers the session's events from 1 and, unlike EventID, stays
+// the same across server restarts.
+type HistoricalEvent struct {
+	Seq int64 `json:"seq"`
+	Event
+}
+
+// EventHistoryResponse is a page of a session's persisted events, oldest
+// first. More is set when further events follow; pass the last Seq as
+// since_seq to read them.
+type EventHistoryResponse struct {
+	SessionID string            `json:"session_id"`
+	Events    []HistoricalEvent `json:"events"`
+	More      bool              `json:"more,omitempty"`
+}
+
 type SessionStateEvent struct {
 	EventID      int64        `json:"event_id"`
 	Type         EventType    `json:"type"`
@@ -138,12 +1333,24 @@ type SessionStateEvent struct {
 	Reason       string       `json:"reason,omitempty"`
 	Source       string       `json:"source,omitempty"`
 	RunAttemptID string       `json:"run_attempt_id,omitempty"`
+	// Notice is the reason as a code, when it has one.
+	Notice *Notice `json:"notice,omitempty"`
 }
 
 type StatusChangeData struct {
 	OldState string `json:"old_state"`
 	NewState string `json:"new_state"`
 	Reason   string `json:"reason,omitempty"`
+	// Notice is the reason as a code, when it has one.
+	Notice *Notice `json:"notice,omitempty"`
+}
+
+// Notice is a status reason or system message as a stable code with string
+// parameters. The matching reason or contents field holds its English text,
+// so clients that do not know a code can show that instead.
+type Notice struct {
+	Code   string            `json:"code"`
+	Params map[string]string `json:"params,omitempty"`
 }
 
 type OutputData struct {
@@ -154,9 +1361,14 @@ type OutputData struct {
 }
 
 type MetricData struct {
-	TokensIn     int64 `json:"tokens_in"`
-	TokensOut    int64 `json:"tokens_out"`
-	RequestCount int64 `json:"request_count"`
+	TokensIn            int64 `json:"tokens_in"`
+	TokensOut           int64 `json:"tokens_out"`
+	RequestCount        int64 `json:"request_count"`
+	CacheReadTokens     int64 `json:"cache_read_tokens,omitempty"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens,omitempty"`
+
+	// CostUSD is the cost of the usage, for providers that report it.
+	CostUSD float64 `json:"cost_usd,omitempty"`
 }
 
 type ErrorData struct {
@@ -176,6 +1388,14 @@ type ToolCallData struct {
 	Title  string `json:"title,omitempty"`
 	Input  any    `json:"input,omitempty"`
 	Output any    `json:"output,omitempty"`
+	// ParentID links calls made inside a subagent to the spawning tool call.
+	ParentID string      `json:"parent_id,omitempty"`
+	Usage    *TokenUsage `json:"usage,omitempty"`
+}
+
+type TokenUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
 }
 
 type ThoughtData struct {
@@ -193,6 +1413,19 @@ type PlanData struct {
 	Steps       []PlanStep `json:"steps,omitempty"`
 }
 
+// ProgressData is the estimated completion of a running session.
+type ProgressData struct {
+	Percent int    `json:"percent"`
+	Phase   string `json:"phase"`
+}
+
+// LivenessData is a running session's recent provider activity, sent
+// periodically on its event stream.
+type LivenessData struct {
+	LastActivityAt   time.Time `json:"last_activity_at"`
+	TokensLastMinute int64     `json:"tokens_last_minute"`
+}
+
 type ActivityEntry struct {
 	ID        string         `json:"id"`
 	SessionID string         `json:"session_id"`
@@ -213,9 +1446,190 @@ type ActivityHistoryResponse struct {
 }
 
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
-	Details any    `json:"details,omitempty"`
+	Error string `json:"error"`
+	// Code is always set; see ErrorCode for the registry.
+	Code    ErrorCode `json:"code"`
+	Details any       `json:"details,omitempty"`
+}
+
+// PromptCacheReport is returned by GET /api/sessions/{id}/prompt-cache. It
+// describes the session's stable prompt prefix and how much of its prompt
+// input providers served from their prompt cache.
+type PromptCacheReport struct {
+	SessionID string `json:"session_id"`
+	// PrefixHash identifies the prompt prefix; it never changes for a session.
+	PrefixHash          string  `json:"prefix_hash,omitempty"`
+	PrefixLength        int     `json:"prefix_length"`
+	Requests            int64   `json:"requests"`
+	InputTokens         int64   `json:"input_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	HitRate             float64 `json:"hit_rate"`
+	// SavedInputTokens is the uncached input-token equivalent saved by
+	// caching, net of the cache write surcharge.
+	SavedInputTokens int64 `json:"saved_input_tokens"`
+}
+
+// SessionSuggestResponse is returned by GET /api/v1/sessions/suggest.
+type SessionSuggestResponse struct {
+	Suggestions []SessionSuggestion `json:"suggestions"`
+}
+
+// SessionSuggestion is a typeahead match. MatchedField is "id", "title",
+// "task" or "tag", or empty when the query was empty.
+type SessionSuggestion struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title,omitempty"`
+	CurrentTask  string    `json:"current_task,omitempty"`
+	SessionKind  string    `json:"session_kind,omitempty"`
+	Pinned       bool      `json:"pinned,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	MatchedField string    `json:"matched_field,omitempty"`
+}
+
+// RunAttemptListResponse is returned by GET /api/sessions/{id}/attempts.
+type RunAttemptListResponse struct {
+	Attempts []RunAttempt `json:"attempts"`
+}
+
+// SessionTimelineResponse is returned by GET /api/sessions/{id}/timeline. It
+// buckets the session's history into spans for a Gantt-style view, each
+// bucket ordered by start time.
+type SessionTimelineResponse struct {
+	SessionID   string         `json:"session_id"`
+	Start       time.Time      `json:"start"`
+	End         time.Time      `json:"end"`
+	Runs        []TimelineSpan `json:"runs"`
+	Suspensions []TimelineSpan `json:"suspensions"`
+	ToolCalls   []TimelineSpan `json:"tool_calls"`
+	HumanWaits  []TimelineSpan `json:"human_waits"`
+	Takeovers   []TimelineSpan `json:"takeovers"`
+}
+
+// TimelineSpan is one bar of a session timeline. End is omitted while the
+// span is still open, and DurationMS then runs up to the response's End.
+// Lane is the row within the bucket, so overlapping spans do not share one.
+type TimelineSpan struct {
+	ID         string     `json:"id,omitempty"`
+	Label      string     `json:"label"`
+	Detail     string     `json:"detail,omitempty"`
+	Start      time.Time  `json:"start"`
+	End        *time.Time `json:"end,omitempty"`
+	DurationMS int64      `json:"duration_ms"`
+	Lane       int        `json:"lane"`
+}
+
+// RunAttempt describes one run of a session and the control operations
+// (stop, cancel, kill, resume) journaled against it.
+type RunAttempt struct {
+	AttemptID          string             `json:"attempt_id"`
+	ProviderType       string             `json:"provider_type"`
+	ProviderID         string             `json:"provider_id,omitempty"`
+	StartedAt          time.Time          `json:"started_at"`
+	EndedAt            *time.Time         `json:"ended_at,omitempty"`
+	TerminalReason     string             `json:"terminal_reason,omitempty"`
+	InterruptionReason string             `json:"interruption_reason,omitempty"`
+	WaitKind           string             `json:"wait_kind,omitempty"`
+	WaitRef            string             `json:"wait_ref,omitempty"`
+	Operations         []ControlOperation `json:"operations"`
+	// Abandoned marks a run left open by a server that stopped; it ended
+	// no later than HeartbeatAt.
+	Abandoned   bool       `json:"abandoned,omitempty"`
+	HeartbeatAt *time.Time `json:"heartbeat_at,omitempty"`
+	// Delivery is whether the message that started the run reached the
+	// provider: queued, delivered or failed, with DeliveryError.
+	Delivery      string `json:"delivery,omitempty"`
+	DeliveryError string `json:"delivery_error,omitempty"`
+	// ContinuedFrom is the attempt this run continues after that one used
+	// up its token budget and ended as "budget_continued".
+	ContinuedFrom string `json:"continued_from,omitempty"`
+}
+
+// ControlOperation is a journaled control request. Outcome is "applied",
+// "noop" or "rejected"; Replayed marks a repeat answered from the journal.
+type ControlOperation struct {
+	Seq         int64     `json:"seq"`
+	Op          string    `json:"op"`
+	RequestedAt time.Time `json:"requested_at"`
+	Outcome     string    `json:"outcome"`
+	Error       string    `json:"error,omitempty"`
+	Replayed    bool      `json:"replayed,omitempty"`
+}
+
+// WorkingDirConflict is the ErrorResponse detail returned with a 409 when the
+// working-directory lock rejects a message.
+type WorkingDirConflict struct {
+	WorkingDir           string `json:"working_dir"`
+	ConflictingSessionID string `json:"conflicting_session_id"`
+}
+
+// EmbeddedHandshakeRequest is posted to /api/embedded/handshake by a packaged
+// desktop client to exchange its launch nonce for a client token.
+type EmbeddedHandshakeRequest struct {
+	Nonce string `json:"nonce"`
+}
+
+// EmbeddedHandshakeResponse carries the token an embedded client sends in the
+// X-Orbitmesh-Embedded-Token header on every later request.
+type EmbeddedHandshakeResponse struct {
+	Token string `json:"token"`
+}
+
+// EmbedTokenRequest is the body for POST /api/sessions/{id}/embed-tokens.
+// Origins are the pages allowed to fetch the embed endpoints and frame the
+// widget, e.g. "https://wiki.example.com". A zero TTLSeconds never expires.
+type EmbedTokenRequest struct {
+	Label      string   `json:"label,omitempty"`
+	Origins    []string `json:"origins,omitempty"`
+	TTLSeconds int64    `json:"ttl_seconds,omitempty"`
+}
+
+// EmbedToken describes a session embed token. Token and WidgetURL carry the
+// secret and are only set in the response that created it.
+type EmbedToken struct {
+	ID        string     `json:"id"`
+	SessionID string     `json:"session_id"`
+	Label     string     `json:"label,omitempty"`
+	Origins   []string   `json:"origins,omitempty"`
+	CreatedBy string     `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Token     string     `json:"token,omitempty"`
+	WidgetURL string     `json:"widget_url,omitempty"`
+}
+
+// EmbedTokenListResponse lists a session's embed tokens.
+type EmbedTokenListResponse struct {
+	Tokens []EmbedToken `json:"tokens"`
+}
+
+// EmbedStatusResponse is the minimal session status served to embed
+// widgets at GET /embed/v1/status.
+type EmbedStatusResponse struct {
+	SessionID   string    `json:"session_id"`
+	Title       string    `json:"title,omitempty"`
+	State       string    `json:"state"`
+	CurrentTask string    `json:"current_task,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// EmbedActivityResponse is a digest of a session's recent activity served
+// at GET /embed/v1/activity.
+type EmbedActivityResponse struct {
+	SessionID    string              `json:"session_id"`
+	Messages     int                 `json:"messages"`
+	ToolCalls    int                 `json:"tool_calls"`
+	Errors       int                 `json:"errors"`
+	LastActivity *time.Time          `json:"last_activity,omitempty"`
+	Recent       []EmbedActivityItem `json:"recent"`
+}
+
+// EmbedActivityItem is one recent message, truncated.
+type EmbedActivityItem struct {
+	Kind      string    `json:"kind"`
+	Summary   string    `json:"summary"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 type DockMCPRequest struct {
@@ -232,6 +1646,54 @@ type DockMCPResponse struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// DockStatusResponse reports whether a dock session's browser tab is
+// connected. The tab counts as connected while it long-polls for requests or
+// for a short while after its last heartbeat.
+type DockStatusResponse struct {
+	Connected bool `json:"connected"`
+	// Polling is true while the tab waits for a request.
+	Polling    bool       `json:"polling"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	// PendingRequests counts requests waiting for the tab's response.
+	PendingRequests int `json:"pending_requests"`
+}
+
+// DockMultiEditPayload is the payload of a multi_edit dock request. Edits are
+// checked against the page before any is applied, and applied in order;
+// when one fails, the edits before it are rolled back where the component
+// exposes its previous value.
+type DockMultiEditPayload struct {
+	Edits []DockFieldEdit `json:"edits"`
+}
+
+type DockFieldEdit struct {
+	ComponentID string `json:"component_id"`
+	Value       string `json:"value"`
+	// Expected, when set, is the value the component must currently hold for
+	// the edits to apply.
+	Expected *string `json:"expected,omitempty"`
+}
+
+// Per-field outcomes of a multi_edit request.
+const (
+	DockEditApplied    = "applied"
+	DockEditFailed     = "failed"
+	DockEditRolledBack = "rolled_back"
+	DockEditSkipped    = "skipped"
+)
+
+type DockMultiEditResult struct {
+	OK         bool                  `json:"ok"`
+	RolledBack bool                  `json:"rolled_back,omitempty"`
+	Results    []DockFieldEditResult `json:"results"`
+}
+
+type DockFieldEditResult struct {
+	ComponentID string `json:"component_id"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
 type PermissionsResponse struct {
 	Role                                string `json:"role"`
 	CanInspectSessions                  bool   `json:"can_inspect_sessions"`
@@ -262,6 +1724,47 @@ type TaskTreeResponse struct {
 	Tasks []TaskNode `json:"tasks"`
 }
 
+// TaskChangeRequest journals a task mutation a session's agent made through
+// the strand MCP tools, at POST /api/sessions/{id}/task-changes. Before and
+// After are the task as `strand show --format json` printed it around the
+// change.
+type TaskChangeRequest struct {
+	TaskID string `json:"task_id"`
+	// Op is "add", "claim", "complete" or "edit".
+	Op   string `json:"op"`
+	Todo int    `json:"todo,omitempty"`
+	// WorkingDir and ProjectDir locate the strand project, so the change is
+	// undone in the same one.
+	WorkingDir string `json:"working_dir,omitempty"`
+	ProjectDir string `json:"project_dir,omitempty"`
+	Summary    string `json:"summary,omitempty"`
+	Before     string `json:"before,omitempty"`
+	After      string `json:"after,omitempty"`
+}
+
+// TaskChange is a journaled task mutation.
+type TaskChange struct {
+	ID        string `json:"id"`
+	SessionID string `json:"session_id,omitempty"`
+	TaskChangeRequest
+	At       time.Time  `json:"at"`
+	UndoneAt *time.Time `json:"undone_at,omitempty"`
+	UndoneBy string     `json:"undone_by,omitempty"`
+}
+
+// TaskHistoryResponse lists journaled task changes, oldest first.
+type TaskHistoryResponse struct {
+	TaskID  string       `json:"task_id,omitempty"`
+	Changes []TaskChange `json:"changes"`
+}
+
+// TaskUndoRequest is the body of POST /api/v1/tasks/changes/undo, which
+// undoes the latest task change not yet undone, of the session when
+// SessionID is set.
+type TaskUndoRequest struct {
+	SessionID string `json:"session_id,omitempty"`
+}
+
 type CommitSummary struct {
 	Sha       string    `json:"sha"`
 	Message   string    `json:"message"`
@@ -456,6 +1959,19 @@ type ProviderConfigResponse struct {
 	Env      map[string]string `json:"env,omitempty"`
 	Custom   map[string]any    `json:"custom,omitempty"`
 	IsActive bool              `json:"is_active"`
+	// Capabilities is omitted when the provider type is not registered.
+	Capabilities *ProviderCapabilities `json:"capabilities,omitempty"`
+}
+
+// ProviderCapabilities lists the optional operations a provider's sessions
+// support. Operations a provider lacks fail with 501 capability_unsupported.
+type ProviderCapabilities struct {
+	Suspend            bool `json:"suspend"`
+	Interrupt          bool `json:"interrupt"`
+	Steering           bool `json:"steering"`
+	Terminal           bool `json:"terminal"`
+	Images             bool `json:"images"`
+	ResumeAfterRestart bool `json:"resume_after_restart"`
 }
 
 type ProviderConfigListResponse struct {
@@ -467,10 +1983,170 @@ type Message struct {
 	Kind      string    `json:"kind"`
 	Contents  string    `json:"contents"`
 	Timestamp time.Time `json:"timestamp,omitempty"`
+	// Redacted marks a message whose contents were replaced with a
+	// tombstone.
+	Redacted bool `json:"redacted,omitempty"`
+	// Notice is set on system messages that have a code.
+	Notice *Notice `json:"notice,omitempty"`
+}
+
+// RedactMessagesRequest is the body for POST
+// /api/sessions/{id}/messages/redact.
+type RedactMessagesRequest struct {
+	MessageIDs []string `json:"message_ids"`
+	Reason     string   `json:"reason,omitempty"`
+}
+
+// RedactMessagesResponse reports how many messages were newly redacted.
+type RedactMessagesResponse struct {
+	Redacted int `json:"redacted"`
+}
+
+// MessagePin marks a message, or an excerpt of it, as a key outcome of a
+// session, such as a decision or a final answer. MessageID is the ID the
+// messages API serves; Excerpt is the pinned part of the contents.
+type MessagePin struct {
+	ID        string    `json:"id"`
+	MessageID string    `json:"message_id"`
+	Kind      string    `json:"kind"`
+	Excerpt   string    `json:"excerpt"`
+	Note      string    `json:"note,omitempty"`
+	MessageAt time.Time `json:"message_at"`
+	PinnedBy  string    `json:"pinned_by,omitempty"`
+	PinnedAt  time.Time `json:"pinned_at"`
+}
+
+// PinMessageRequest is the body for POST
+// /api/sessions/{id}/pinned-messages. Excerpt pins only that part of the
+// message's contents; it must appear in them verbatim.
+type PinMessageRequest struct {
+	MessageID string `json:"message_id"`
+	Excerpt   string `json:"excerpt,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// MessagePinListResponse is returned by GET
+// /api/sessions/{id}/pinned-messages.
+type MessagePinListResponse struct {
+	Pins []MessagePin `json:"pins"`
 }
 
+// SessionBatchRequest selects the sessions of a batch operation, either by
+// ID or by filter. Operation names the operation for POST
+// /api/sessions/bulk and is ignored by the per-operation batch endpoints.
+type SessionBatchRequest struct {
+	Operation  SessionBulkOperation `json:"operation,omitempty"`
+	SessionIDs []string             `json:"session_ids,omitempty"`
+	Filter     *SessionBatchFilter  `json:"filter,omitempty"`
+}
+
+type SessionBulkOperation string
+
+const (
+	SessionBulkStop   SessionBulkOperation = "stop"
+	SessionBulkCancel SessionBulkOperation = "cancel"
+	SessionBulkDelete SessionBulkOperation = "delete"
+	SessionBulkResume SessionBulkOperation = "resume"
+)
+
+// SessionBatchFilter matches sessions on every field that is set. Tag
+// matches a session's kind or provider type.
+type SessionBatchFilter struct {
+	ProjectID string       `json:"project_id,omitempty"`
+	Tag       string       `json:"tag,omitempty"`
+	State     SessionState `json:"state,omitempty"`
+}
+
+// SessionBatchResult is the outcome for one session of a batch operation.
+type SessionBatchResult struct {
+	SessionID string    `json:"session_id"`
+	OK        bool      `json:"ok"`
+	Code      ErrorCode `json:"code,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type SessionBatchResponse struct {
+	Results   []SessionBatchResult `json:"results"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+}
+
+// AuditEntry records a sensitive change made to a session, such as a
+// redaction.
+type AuditEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor,omitempty"`
+	Action    string    `json:"action"`
+	SessionID string    `json:"session_id,omitempty"`
+	Targets   []string  `json:"targets,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+type AuditLogResponse struct {
+	Entries []AuditEntry `json:"entries"`
+}
+
+// QuarantineItem is agent output a guardrail flagged or blocked. Status is
+// open until a reviewer dismisses or confirms it.
+type QuarantineItem struct {
+	ID         string              `json:"id"`
+	SessionID  string              `json:"session_id"`
+	ProjectID  string              `json:"project_id,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Source     string              `json:"source"`
+	Action     string              `json:"action"`
+	Findings   []QuarantineFinding `json:"findings"`
+	Content    string              `json:"content"`
+	Status     string              `json:"status"`
+	ResolvedBy string              `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `json:"resolved_at,omitempty"`
+}
+
+// QuarantineFinding is one guardrail match; Match is masked.
+type QuarantineFinding struct {
+	Rule     string `json:"rule"`
+	Category string `json:"category"`
+	Action   string `json:"action"`
+	Match    string `json:"match,omitempty"`
+}
+
+type QuarantineListResponse struct {
+	Items []QuarantineItem `json:"items"`
+}
+
+// ResolveQuarantineRequest is the body for
+// POST /api/guardrails/quarantine/{id}/resolve. Status is dismissed or
+// confirmed.
+type ResolveQuarantineRequest struct {
+	Status string `json:"status"`
+}
+
+// MessageListResponse is a session's messages, or one page of them.
+// NextCursor is set when more messages follow the page.
 type MessageListResponse struct {
-	Messages []Message `json:"messages"`
+	Messages   []Message `json:"messages"`
+	NextCursor *string   `json:"next_cursor,omitempty"`
+}
+
+// MessageSearchHit is a message matching GET /api/search, with the session
+// it belongs to. Index is the message's position in the session.
+type MessageSearchHit struct {
+	SessionID    string       `json:"session_id"`
+	SessionTitle string       `json:"session_title,omitempty"`
+	SessionState SessionState `json:"session_state"`
+	ProviderType string       `json:"provider_type"`
+	ProjectID    string       `json:"project_id,omitempty"`
+	Index        int          `json:"index"`
+	Message      Message      `json:"message"`
+	Snippet      string       `json:"snippet"`
+}
+
+// MessageSearchResponse lists the hits of a search, newest first. More is
+// set when further messages matched beyond the limit.
+type MessageSearchResponse struct {
+	Query string             `json:"query"`
+	Hits  []MessageSearchHit `json:"hits"`
+	More  bool               `json:"more,omitempty"`
 }
 
 // AgentConfigRequest is the request body for create/update agent endpoints.
@@ -481,15 +2157,26 @@ type AgentConfigRequest struct {
 	SystemPrompt string            `json:"system_prompt,omitempty"`
 	MCPServers   []MCPServerConfig `json:"mcp_servers,omitempty"`
 	Custom       map[string]any    `json:"custom,omitempty"`
+	// CleanupCommands run when a session using this agent is stopped, killed
+	// or cleaned up, after any project cleanup commands.
+	CleanupCommands []string `json:"cleanup_commands,omitempty"`
+	// Features are the default feature flags of sessions using this agent.
+	Features map[string]bool `json:"features,omitempty"`
+	// ToolPolicy allows or denies the tool calls of sessions using this
+	// agent by rule, together with their project's policy.
+	ToolPolicy *ToolPolicy `json:"tool_policy,omitempty"`
 }
 
 // AgentConfigResponse is returned by agent endpoints.
 type AgentConfigResponse struct {
-	ID           string            `json:"id"`
-	Name         string            `json:"name"`
-	SystemPrompt string            `json:"system_prompt,omitempty"`
-	MCPServers   []MCPServerConfig `json:"mcp_servers,omitempty"`
-	Custom       map[string]any    `json:"custom,omitempty"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	SystemPrompt    string            `json:"system_prompt,omitempty"`
+	MCPServers      []MCPServerConfig `json:"mcp_servers,omitempty"`
+	Custom          map[string]any    `json:"custom,omitempty"`
+	CleanupCommands []string          `json:"cleanup_commands,omitempty"`
+	Features        map[string]bool   `json:"features,omitempty"`
+	ToolPolicy      *ToolPolicy       `json:"tool_policy,omitempty"`
 }
 
 // AgentConfigListResponse wraps a list of agent configs.
@@ -497,6 +2184,23 @@ type AgentConfigListResponse struct {
 	Agents []AgentConfigResponse `json:"agents"`
 }
 
+// ToolStat summarises how an agent has used a single tool.
+type ToolStat struct {
+	Name         string    `json:"name"`
+	Calls        int64     `json:"calls"`
+	Failures     int64     `json:"failures"`
+	FailureRate  float64   `json:"failure_rate"`
+	AvgLatencyMS int64     `json:"avg_latency_ms"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+}
+
+// AgentToolStatsResponse is returned by GET /api/v1/agents/{id}/tool-stats,
+// with tools ordered by call count.
+type AgentToolStatsResponse struct {
+	AgentID string     `json:"agent_id"`
+	Tools   []ToolStat `json:"tools"`
+}
+
 // SessionResponse now also surfaces which agent was used.
 // We embed AgentID on SessionResponse via the extended field below so that the
 // response wire format includes it without breaking existing fields.
@@ -504,3 +2208,145 @@ type AgentConfigListResponse struct {
 // SessionAgentID is the optional agent ID stored on a session.
 // It is included in SessionResponse as agent_id.
 type SessionAgentID = string
+
+// CleanupPolicy describes the configured stale-session janitor policy.
+type CleanupPolicy struct {
+	// MaxIdleSeconds is how long an idle session may go untouched before it
+	// is archived or deleted; 0 disables session expiry.
+	MaxIdleSeconds  int64  `json:"max_idle_seconds"`
+	Action          string `json:"action"`
+	IntervalSeconds int64  `json:"interval_seconds"`
+}
+
+// CleanupReport lists what a janitor pass removed.
+type CleanupReport struct {
+	StartedAt           time.Time `json:"started_at"`
+	FinishedAt          time.Time `json:"finished_at"`
+	DryRun              bool      `json:"dry_run"`
+	ArchivedSessions    []string  `json:"archived_sessions"`
+	DeletedSessions     []string  `json:"deleted_sessions"`
+	OrphanedTerminals   []string  `json:"orphaned_terminals"`
+	ExpiredResumeTokens []string  `json:"expired_resume_tokens"`
+	DanglingAttempts    []string  `json:"dangling_attempts"`
+	Errors              []string  `json:"errors,omitempty"`
+}
+
+// CleanupStatusResponse is returned by GET /api/v1/admin/cleanup.
+type CleanupStatusResponse struct {
+	Policy     CleanupPolicy  `json:"policy"`
+	LastReport *CleanupReport `json:"last_report,omitempty"`
+}
+
+// RecoveryReport is returned by GET /api/v1/admin/recovery and published on
+// the system.recovery realtime topic while startup recovery runs. Status is
+// running, completed or failed.
+type RecoveryReport struct {
+	StartedAt       time.Time          `json:"started_at"`
+	FinishedAt      *time.Time         `json:"finished_at,omitempty"`
+	Status          string             `json:"status"`
+	SessionsTotal   int                `json:"sessions_total"`
+	SessionsScanned int                `json:"sessions_scanned"`
+	AttemptsClosed  int                `json:"attempts_closed"`
+	RunsScheduled   int                `json:"runs_scheduled"`
+	Sessions        []RecoveredSession `json:"sessions"`
+	Error           string             `json:"error,omitempty"`
+
+	// PreviousShutdown is how the server last shut down, absent after a
+	// crash.
+	PreviousShutdown *ShutdownReport `json:"previous_shutdown,omitempty"`
+}
+
+// ShutdownReport records the phases of an executor shutdown (drain,
+// suspend, stop, kill) and what happened to each session that had a run.
+type ShutdownReport struct {
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Clean      bool              `json:"clean"`
+	Phases     []ShutdownPhase   `json:"phases"`
+	Sessions   []ShutdownSession `json:"sessions"`
+}
+
+type ShutdownPhase struct {
+	Name       string `json:"name"`
+	DurationMS int64  `json:"duration_ms"`
+	Sessions   int    `json:"sessions"`
+	TimedOut   bool   `json:"timed_out,omitempty"`
+}
+
+// ShutdownSession is one session's shutdown outcome: suspended, stopped,
+// killed or failed.
+type ShutdownSession struct {
+	SessionID string `json:"session_id"`
+	Outcome   string `json:"outcome"`
+	Phase     string `json:"phase"`
+	Error     string `json:"error,omitempty"`
+}
+
+// IntegrityReport is returned by GET /api/v1/admin/integrity and POST
+// /api/v1/admin/integrity/repair.
+type IntegrityReport struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Repair    bool             `json:"repair"`
+	Issues    []IntegrityIssue `json:"issues"`
+}
+
+// IntegrityIssue is one broken storage invariant. Path is relative to the
+// storage directory; Repair describes the safe fix, which Repaired reports
+// was applied.
+type IntegrityIssue struct {
+	Kind        string `json:"kind"`
+	SessionID   string `json:"session_id,omitempty"`
+	Path        string `json:"path"`
+	Detail      string `json:"detail"`
+	Repair      string `json:"repair,omitempty"`
+	Repaired    bool   `json:"repaired,omitempty"`
+	RepairError string `json:"repair_error,omitempty"`
+}
+
+// WarmPoolStats is returned by GET /api/v1/admin/warm-pool. Idle counts
+// warm runners waiting for their session's first message by provider type;
+// HitRate is hits / (hits + misses).
+type WarmPoolStats struct {
+	Idle    map[string]int `json:"idle"`
+	Started int64          `json:"started"`
+	Hits    int64          `json:"hits"`
+	Misses  int64          `json:"misses"`
+	Expired int64          `json:"expired"`
+	Evicted int64          `json:"evicted"`
+	Failed  int64          `json:"failed"`
+	HitRate float64        `json:"hit_rate"`
+}
+
+// AdminOverviewResponse is returned by GET /api/v1/admin/overview.
+// StorageBytes is keyed by category (sessions, messages, attempts, archive,
+// ...) and is absent when the storage cannot report usage.
+type AdminOverviewResponse struct {
+	Version           string             `json:"version"`
+	Revision          string             `json:"revision,omitempty"`
+	StartedAt         time.Time          `json:"started_at"`
+	UptimeSeconds     int64              `json:"uptime_seconds"`
+	StorageBytes      map[string]int64   `json:"storage_bytes,omitempty"`
+	ActiveRuns        int                `json:"active_runs"`
+	QueueDepth        int                `json:"queue_depth"`
+	DegradedProviders []DegradedProvider `json:"degraded_providers"`
+	PendingApprovals  int                `json:"pending_approvals"`
+	PendingQuestions  int                `json:"pending_questions"`
+}
+
+// DegradedProvider is a provider type whose last ConsecutiveFailures runs
+// failed.
+type DegradedProvider struct {
+	ProviderType        string    `json:"provider_type"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailureAt       time.Time `json:"last_failure_at"`
+}
+
+// RecoveredSession is a session startup recovery found interrupted.
+type RecoveredSession struct {
+	SessionID      string `json:"session_id"`
+	AttemptsClosed int    `json:"attempts_closed"`
+	Policy         string `json:"policy"`
+	Scheduled      bool   `json:"scheduled,omitempty"`
+	Note           string `json:"note,omitempty"`
+}
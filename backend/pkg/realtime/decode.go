@@ -0,0 +1,113 @@
+package realtime
+
+import "encoding/json"
+
+// This file is excluded from the TypeScript output: the payload unions are
+// emitted from payloads.go instead.
+
+// SessionActivityData is the payload of a SessionActivityEvent. Its concrete
+// type follows the event's Type; see SessionActivityPayloads.
+type SessionActivityData interface {
+	SessionActivityType() string
+}
+
+// TerminalOutputData is the payload of a TerminalOutputEvent. Its concrete
+// type follows the event's Type; see TerminalOutputPayloads. "terminal.bell"
+// events have none.
+type TerminalOutputData interface {
+	TerminalOutputType() string
+}
+
+// newSessionActivityData returns an empty payload for an activity event
+// type, or nil for types this version does not know.
+func newSessionActivityData(eventType string) SessionActivityData {
+	switch eventType {
+	case "status_change":
+		return &StatusChangePayload{}
+	case "output":
+		return &OutputPayload{}
+	case "metric":
+		return &MetricPayload{}
+	case "error":
+		return &ErrorPayload{}
+	case "metadata":
+		return &MetadataPayload{}
+	case "tool_call":
+		return &ToolCallPayload{}
+	case "thought":
+		return &ThoughtPayload{}
+	case "plan":
+		return &PlanPayload{}
+	case "progress":
+		return &ProgressPayload{}
+	case "liveness":
+		return &LivenessPayload{}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes Data into the payload type matching Type, as a
+// pointer. Data is left nil for unknown types.
+func (e *SessionActivityEvent) UnmarshalJSON(b []byte) error {
+	type event SessionActivityEvent
+	var raw struct {
+		event
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*e = SessionActivityEvent(raw.event)
+	data := newSessionActivityData(e.Type)
+	if data == nil || len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Data, data); err != nil {
+		return err
+	}
+	e.Data = data
+	return nil
+}
+
+// newTerminalOutputData returns an empty payload for a terminal output
+// event type, or nil for types without one.
+func newTerminalOutputData(eventType string) TerminalOutputData {
+	switch eventType {
+	case "terminal.snapshot":
+		return &TerminalSnapshot{}
+	case "terminal.diff":
+		return &TerminalDiffPayload{}
+	case "terminal.cursor":
+		return &TerminalCursorPayload{}
+	case "terminal.error":
+		return &TerminalErrorPayload{}
+	case "terminal.viewers":
+		return &TerminalViewersPayload{}
+	case "terminal.input":
+		return &TerminalInputPayload{}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes Data into the payload type matching Type, as a
+// pointer. Data is left nil for types without a payload.
+func (e *TerminalOutputEvent) UnmarshalJSON(b []byte) error {
+	type event TerminalOutputEvent
+	var raw struct {
+		event
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*e = TerminalOutputEvent(raw.event)
+	data := newTerminalOutputData(e.Type)
+	if data == nil || len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Data, data); err != nil {
+		return err
+	}
+	e.Data = data
+	return nil
+}
@@ -0,0 +1,202 @@
+package realtime
+
+import "time"
+
+// PayloadVersion is the version of the event payloads below, sent as the
+// version field of SessionActivityEvent and TerminalOutputEvent. It is
+// bumped when a payload changes incompatibly, so clients can tell payloads
+// they do not understand from malformed ones.
+const PayloadVersion = 1
+
+// StatusChangePayload is the data of a "status_change" event.
+type StatusChangePayload struct {
+	OldState string  `json:"old_state"`
+	NewState string  `json:"new_state"`
+	Reason   string  `json:"reason,omitempty"`
+	Notice   *Notice `json:"notice,omitempty"`
+}
+
+// OutputPayload is the data of an "output" event. IsDelta chunks are
+// appended to the previous output message.
+type OutputPayload struct {
+	Content string `json:"content"`
+	IsDelta bool   `json:"is_delta,omitempty"`
+}
+
+// MetricPayload is the data of a "metric" event.
+type MetricPayload struct {
+	TokensIn            int64   `json:"tokens_in"`
+	TokensOut           int64   `json:"tokens_out"`
+	RequestCount        int64   `json:"request_count"`
+	CacheReadTokens     int64   `json:"cache_read_tokens,omitempty"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens,omitempty"`
+	CostUSD             float64 `json:"cost_usd,omitempty"`
+}
+
+// ErrorPayload is the data of an "error" event.
+type ErrorPayload struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+// MetadataPayload is the data of a "metadata" event. Value is whatever the
+// provider reported under Key.
+type MetadataPayload struct {
+	Key   string `json:"key"`
+	Value any    `json:"value" tstype:"unknown"`
+}
+
+// ToolCallPayload is the data of a "tool_call" event.
+type ToolCallPayload struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Input  any    `json:"input,omitempty" tstype:"unknown"`
+	Output any    `json:"output,omitempty" tstype:"unknown"`
+	// ParentID links calls made inside a subagent to the spawning tool call.
+	ParentID string             `json:"parent_id,omitempty"`
+	Usage    *TokenUsagePayload `json:"usage,omitempty"`
+}
+
+type TokenUsagePayload struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+// ThoughtPayload is the data of a "thought" event.
+type ThoughtPayload struct {
+	Content string `json:"content"`
+}
+
+// PlanPayload is the data of a "plan" event.
+type PlanPayload struct {
+	Description string            `json:"description,omitempty"`
+	Steps       []PlanStepPayload `json:"steps,omitempty"`
+}
+
+type PlanStepPayload struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Status      string `json:"status,omitempty"`
+}
+
+// ProgressPayload is the data of a "progress" event.
+type ProgressPayload struct {
+	Percent int    `json:"percent"`
+	Phase   string `json:"phase"`
+}
+
+// LivenessPayload is the data of a "liveness" event.
+type LivenessPayload struct {
+	LastActivityAt   time.Time `json:"last_activity_at"`
+	TokensLastMinute int64     `json:"tokens_last_minute"`
+}
+
+func (StatusChangePayload) SessionActivityType() string { return "status_change" }
+func (OutputPayload) SessionActivityType() string       { return "output" }
+func (MetricPayload) SessionActivityType() string       { return "metric" }
+func (ErrorPayload) SessionActivityType() string        { return "error" }
+func (MetadataPayload) SessionActivityType() string     { return "metadata" }
+func (ToolCallPayload) SessionActivityType() string     { return "tool_call" }
+func (ThoughtPayload) SessionActivityType() string      { return "thought" }
+func (PlanPayload) SessionActivityType() string         { return "plan" }
+func (ProgressPayload) SessionActivityType() string     { return "progress" }
+func (LivenessPayload) SessionActivityType() string     { return "liveness" }
+
+//tygo:emit
+var _ = `export interface SessionActivityPayloads {
+  status_change: StatusChangePayload;
+  output: OutputPayload;
+  metric: MetricPayload;
+  error: ErrorPayload;
+  metadata: MetadataPayload;
+  tool_call: ToolCallPayload;
+  thought: ThoughtPayload;
+  plan: PlanPayload;
+  progress: ProgressPayload;
+  liveness: LivenessPayload;
+}
+export type SessionActivityEventType = keyof SessionActivityPayloads;
+export type SessionActivityData = SessionActivityPayloads[SessionActivityEventType];
+/**
+ * A SessionActivityEvent whose data is narrowed by its type.
+ */
+export type TypedSessionActivityEvent = {
+  [K in SessionActivityEventType]: Omit<SessionActivityEvent, "type" | "data"> & { type: K; data: SessionActivityPayloads[K] };
+}[SessionActivityEventType];`
+
+// TerminalDiffPayload is the data of a "terminal.diff" event: Lines replace
+// the rows of Region.
+type TerminalDiffPayload struct {
+	Region TerminalRegion `json:"region"`
+	Lines  []string       `json:"lines"`
+	Reason string         `json:"reason,omitempty"`
+}
+
+type TerminalRegion struct {
+	X  int `json:"x"`
+	Y  int `json:"y"`
+	X2 int `json:"x2"`
+	Y2 int `json:"y2"`
+}
+
+// TerminalCursorPayload is the data of a "terminal.cursor" event.
+type TerminalCursorPayload struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// TerminalErrorPayload is the data of a "terminal.error" event. Resync asks
+// the client to fetch a fresh snapshot.
+type TerminalErrorPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Resync  bool   `json:"resync"`
+}
+
+// TerminalViewersPayload is the data of a "terminal.viewers" event.
+type TerminalViewersPayload struct {
+	Viewers []TerminalViewer `json:"viewers"`
+}
+
+type TerminalViewer struct {
+	ViewerID string    `json:"viewer_id"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TerminalInputPayload is the data of a "terminal.input" event: which
+// viewer sent what kind of input.
+type TerminalInputPayload struct {
+	ViewerID string `json:"viewer_id"`
+	Name     string `json:"name"`
+	Input    string `json:"input"`
+}
+
+func (TerminalSnapshot) TerminalOutputType() string       { return "terminal.snapshot" }
+func (TerminalDiffPayload) TerminalOutputType() string    { return "terminal.diff" }
+func (TerminalCursorPayload) TerminalOutputType() string  { return "terminal.cursor" }
+func (TerminalErrorPayload) TerminalOutputType() string   { return "terminal.error" }
+func (TerminalViewersPayload) TerminalOutputType() string { return "terminal.viewers" }
+func (TerminalInputPayload) TerminalOutputType() string   { return "terminal.input" }
+
+//tygo:emit
+var _ = `export interface TerminalOutputPayloads {
+  "terminal.snapshot": TerminalSnapshot;
+  "terminal.diff": TerminalDiffPayload;
+  "terminal.cursor": TerminalCursorPayload;
+  "terminal.error": TerminalErrorPayload;
+  "terminal.viewers": TerminalViewersPayload;
+  "terminal.input": TerminalInputPayload;
+  "terminal.bell": undefined;
+}
+export type TerminalOutputEventType = keyof TerminalOutputPayloads;
+export type TerminalOutputData = Exclude<TerminalOutputPayloads[TerminalOutputEventType], undefined>;
+/**
+ * A TerminalOutputEvent whose data is narrowed by its type.
+ */
+export type TypedTerminalOutputEvent = {
+  [K in TerminalOutputEventType]: Omit<TerminalOutputEvent, "type" | "data"> & { type: K; data?: TerminalOutputPayloads[K] };
+}[TerminalOutputEventType];`
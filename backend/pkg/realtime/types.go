@@ -26,6 +26,13 @@ const (
 type ClientEnvelope struct {
 	Type   ClientMessageType `json:"type"`
 	Topics []string          `json:"topics,omitempty"`
+	// EventTypes, on subscribe, limits event messages on the topics to these
+	// event types (e.g. "tool_call", "status_change"). Snapshots are always
+	// sent.
+	EventTypes []string `json:"event_types,omitempty"`
+	// Profile, on subscribe, applies a named visibility profile ("chat",
+	// "debug", "audit") to the topics' snapshots and events.
+	Profile string `json:"profile,omitempty"`
 }
 
 type ServerEnvelope struct {
@@ -41,12 +48,20 @@ type SessionsStateSnapshot struct {
 
 type Session = apiTypes.SessionResponse
 
+type Notice = apiTypes.Notice
+
 type SessionStateEvent struct {
 	EventID      int64     `json:"event_id"`
 	Timestamp    time.Time `json:"timestamp"`
 	SessionID    string    `json:"session_id"`
 	DerivedState string    `json:"derived_state"`
 	Reason       string    `json:"reason,omitempty"`
+	Pinned       bool      `json:"pinned,omitempty"`
+	// UnreadCounts maps each user who has read the session to the number of
+	// agent messages they have not seen yet.
+	UnreadCounts map[string]int `json:"unread_counts,omitempty"`
+	// Notice is the reason as a code, when it has one.
+	Notice *Notice `json:"notice,omitempty"`
 }
 
 type SessionActivitySnapshot struct {
@@ -71,14 +86,18 @@ type SessionMessage struct {
 	Kind      string    `json:"kind"`
 	Contents  string    `json:"contents"`
 	Timestamp time.Time `json:"timestamp"`
+	Notice    *Notice   `json:"notice,omitempty"`
 }
 
+// SessionActivityEvent is one event of a session; Data's type follows Type.
+// Version is PayloadVersion.
 type SessionActivityEvent struct {
-	EventID   int64     `json:"event_id"`
-	Timestamp time.Time `json:"timestamp"`
-	SessionID string    `json:"session_id"`
-	Type      string    `json:"type"`
-	Data      any       `json:"data"`
+	EventID   int64               `json:"event_id"`
+	Timestamp time.Time           `json:"timestamp"`
+	SessionID string              `json:"session_id"`
+	Type      string              `json:"type" tstype:"SessionActivityEventType"`
+	Version   int                 `json:"version"`
+	Data      SessionActivityData `json:"data" tstype:"SessionActivityData"`
 }
 
 type TerminalsStateSnapshot struct {
@@ -113,11 +132,60 @@ type TerminalOutputSnapshot struct {
 	Snapshot   TerminalSnapshot `json:"snapshot"`
 }
 
+// TerminalOutputEvent is one update of a terminal; Data's type follows Type.
+// Version is PayloadVersion.
 type TerminalOutputEvent struct {
-	TerminalID string    `json:"terminal_id"`
-	SessionID  string    `json:"session_id"`
-	Seq        int64     `json:"seq"`
-	Timestamp  time.Time `json:"timestamp"`
-	Type       string    `json:"type"`
-	Data       any       `json:"data,omitempty"`
+	TerminalID string             `json:"terminal_id"`
+	SessionID  string             `json:"session_id"`
+	Seq        int64              `json:"seq"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Type       string             `json:"type" tstype:"TerminalOutputEventType"`
+	Version    int                `json:"version"`
+	Data       TerminalOutputData `json:"data,omitempty" tstype:"TerminalOutputData"`
 }
+
+type NotificationKind string
+
+const (
+	NotificationKindApproval NotificationKind = "approval"
+	NotificationKindFailure  NotificationKind = "failure"
+	// NotificationKindQuestion is an agent's ask_human question.
+	NotificationKindQuestion NotificationKind = "question"
+)
+
+// Notification is a compact, user-facing alert published on the
+// notifications topic. Clients should drop notifications whose DedupeKey
+// they have already shown; the same key is used in snapshots and events.
+type Notification struct {
+	DedupeKey string           `json:"dedupe_key"`
+	Kind      NotificationKind `json:"kind"`
+	SessionID string           `json:"session_id"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+type NotificationsSnapshot struct {
+	Notifications []Notification `json:"notifications"`
+}
+
+// RecoverySnapshot holds the current or most recent startup recovery report;
+// events on the topic carry the report itself.
+type RecoverySnapshot struct {
+	Report *RecoveryReport `json:"report,omitempty"`
+}
+
+type RecoveryReport = apiTypes.RecoveryReport
+
+// SessionsSyncSnapshot holds the current session list revision; events on
+// the topic carry the sessions changed since the previous event, shaped like
+// GET /api/sessions/sync.
+type SessionsSyncSnapshot struct {
+	Revision int64 `json:"revision"`
+}
+
+type SessionsSyncEvent = apiTypes.SessionSyncResponse
+
+// WaitsSnapshot is the list GET /api/v1/waits returns. Events on the topic
+// carry the whole list again whenever a session's wait changes.
+type WaitsSnapshot = apiTypes.WaitListResponse
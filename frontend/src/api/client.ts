@@ -6,6 +6,9 @@ import * as terminalApi from "./terminals";
 import * as providerApi from "./providers";
 import * as taskApi from "./tasks";
 import * as projectApi from "./projects";
+import * as missionApi from "./missions";
+import * as pipelineApi from "./pipelines";
+import * as templateApi from "./templates";
 
 /**
  * Unified API client. All methods are grouped by domain in separate modules
@@ -17,20 +20,71 @@ export const apiClient = {
   listSessions: sessionApi.listSessions,
   getCachedSessions: sessionApi.getCachedSessions,
   createSession: sessionApi.createSession,
+  dryRunSession: sessionApi.dryRunSession,
   createTaskSession: sessionApi.createTaskSession,
   createDockSession: sessionApi.createDockSession,
   getSession: sessionApi.getSession,
   getActivityEntries: sessionApi.getActivityEntries,
+  getSessionTimeline: sessionApi.getSessionTimeline,
+  listRunAttempts: sessionApi.listRunAttempts,
   stopSession: sessionApi.stopSession,
   pauseSession: sessionApi.pauseSession,
   resumeSession: sessionApi.resumeSession,
+  listResumeTokens: sessionApi.listResumeTokens,
+  revokeResumeToken: sessionApi.revokeResumeToken,
+  approvePlan: sessionApi.approvePlan,
+  draftPRDescription: sessionApi.draftPRDescription,
+  listSessionCommits: sessionApi.listSessionCommits,
+  getSessionDiff: sessionApi.getSessionDiff,
+  openPullRequest: sessionApi.openPullRequest,
+  startBestOfN: sessionApi.startBestOfN,
+  getBestOfN: sessionApi.getBestOfN,
+  selectBestOfN: sessionApi.selectBestOfN,
+  discardBestOfN: sessionApi.discardBestOfN,
+  startHandoff: sessionApi.startHandoff,
+  handOffToSession: sessionApi.handOffToSession,
+  getHandoff: sessionApi.getHandoff,
+  startTakeover: sessionApi.startTakeover,
+  getTakeover: sessionApi.getTakeover,
+  handBackTakeover: sessionApi.handBackTakeover,
+  listEmbedTokens: sessionApi.listEmbedTokens,
+  createEmbedToken: sessionApi.createEmbedToken,
+  revokeEmbedToken: sessionApi.revokeEmbedToken,
+  redactMessages: sessionApi.redactMessages,
+  listMessagePins: sessionApi.listMessagePins,
+  pinMessage: sessionApi.pinMessage,
+  unpinMessage: sessionApi.unpinMessage,
+  listQueuedMessages: sessionApi.listQueuedMessages,
+  removeQueuedMessage: sessionApi.removeQueuedMessage,
+  listAuditEntries: sessionApi.listAuditEntries,
+  listQuarantine: sessionApi.listQuarantine,
+  resolveQuarantineItem: sessionApi.resolveQuarantineItem,
   cancelSession: sessionApi.cancelSession,
+  batchSessions: sessionApi.batchSessions,
+  bulkSessions: sessionApi.bulkSessions,
+  markSessionRead: sessionApi.markSessionRead,
+  listExchangeEntries: sessionApi.listExchangeEntries,
+  setExchangeEntry: sessionApi.setExchangeEntry,
+  deleteExchangeEntry: sessionApi.deleteExchangeEntry,
+  listPendingQuestions: sessionApi.listPendingQuestions,
+  listSessionQuestions: sessionApi.listSessionQuestions,
+  listWaits: sessionApi.listWaits,
+  answerQuestion: sessionApi.answerQuestion,
+  listSessionCommands: sessionApi.listSessionCommands,
+  approveCommand: sessionApi.approveCommand,
+  rejectCommand: sessionApi.rejectCommand,
+  listToolApprovals: sessionApi.listToolApprovals,
+  decideToolApproval: sessionApi.decideToolApproval,
+  searchMessages: sessionApi.searchMessages,
   sendSessionInput: sessionApi.sendSessionInput,
   sendMessage: sessionApi.sendMessage,
   getEventsUrl: sessionApi.getEventsUrl,
+  getTranscriptExportUrl: sessionApi.getTranscriptExportUrl,
   getGlobalSessionEventsUrl: sessionApi.getGlobalSessionEventsUrl,
   pollDockMcp: sessionApi.pollDockMcp,
   respondDockMcp: sessionApi.respondDockMcp,
+  sendDockHeartbeat: sessionApi.sendDockHeartbeat,
+  getDockStatus: sessionApi.getDockStatus,
 
   // Terminals
   getTerminalSnapshot: terminalApi.getTerminalSnapshot,
@@ -53,10 +107,42 @@ export const apiClient = {
   createProject: projectApi.createProject,
   updateProject: projectApi.updateProject,
   deleteProject: projectApi.deleteProject,
+  getProjectWatch: projectApi.getProjectWatch,
+  getProjectUsage: projectApi.getProjectUsage,
+
+  // Missions
+  listMissions: missionApi.listMissions,
+  getMission: missionApi.getMission,
+  createMission: missionApi.createMission,
+  updateMission: missionApi.updateMission,
+  deleteMission: missionApi.deleteMission,
+  addMissionSession: missionApi.addMissionSession,
+  removeMissionSession: missionApi.removeMissionSession,
+  getMissionLog: missionApi.getMissionLog,
+  appendMissionLog: missionApi.appendMissionLog,
+
+  // Pipelines
+  listPipelines: pipelineApi.listPipelines,
+  getPipeline: pipelineApi.getPipeline,
+  createPipeline: pipelineApi.createPipeline,
+  updatePipeline: pipelineApi.updatePipeline,
+  deletePipeline: pipelineApi.deletePipeline,
+  listPipelineRuns: pipelineApi.listPipelineRuns,
+  startPipelineRun: pipelineApi.startPipelineRun,
+  getPipelineRun: pipelineApi.getPipelineRun,
+
+  // Prompt templates
+  listTemplates: templateApi.listTemplates,
+  getTemplate: templateApi.getTemplate,
+  createTemplate: templateApi.createTemplate,
+  updateTemplate: templateApi.updateTemplate,
+  deleteTemplate: templateApi.deleteTemplate,
 
   // Tasks, commits, permissions, extractors
   getPermissions: taskApi.getPermissions,
   getTaskTree: taskApi.getTaskTree,
+  getTaskHistory: taskApi.getTaskHistory,
+  undoTaskChange: taskApi.undoTaskChange,
   listCommits: taskApi.listCommits,
   getCommit: taskApi.getCommit,
   getExtractorConfig: taskApi.getExtractorConfig,
@@ -0,0 +1,77 @@
+import type { MissionRequest, MissionResponse, MissionListResponse, MissionLogEntry, MissionLogResponse } from "../types/api";
+import { BASE_URL, withCSRFHeaders, readErrorMessage } from "./_base";
+
+export async function listMissions(): Promise<MissionListResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getMission(id: string): Promise<MissionResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function createMission(req: MissionRequest): Promise<MissionResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function updateMission(id: string, req: MissionRequest): Promise<MissionResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}`, {
+    method: "PUT",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function deleteMission(id: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
+
+export async function addMissionSession(id: string, sessionId: string): Promise<MissionResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}/sessions`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ session_id: sessionId }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function removeMissionSession(id: string, sessionId: string): Promise<MissionResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}/sessions/${sessionId}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getMissionLog(id: string): Promise<MissionLogResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}/log`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function appendMissionLog(id: string, text: string): Promise<MissionLogEntry> {
+  const resp = await fetch(`${BASE_URL}/v1/missions/${id}/log`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ text }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
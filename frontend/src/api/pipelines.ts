@@ -0,0 +1,70 @@
+import type {
+  PipelineRequest,
+  PipelineResponse,
+  PipelineListResponse,
+  PipelineRunResponse,
+  PipelineRunListResponse,
+} from "../types/api";
+import { BASE_URL, withCSRFHeaders, readErrorMessage } from "./_base";
+
+export async function listPipelines(): Promise<PipelineListResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getPipeline(id: string): Promise<PipelineResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines/${id}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function createPipeline(req: PipelineRequest): Promise<PipelineResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function updatePipeline(id: string, req: PipelineRequest): Promise<PipelineResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines/${id}`, {
+    method: "PUT",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function deletePipeline(id: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines/${id}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
+
+export async function listPipelineRuns(id: string): Promise<PipelineRunListResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines/${id}/runs`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function startPipelineRun(id: string, input?: string): Promise<PipelineRunResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines/${id}/runs`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ input }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getPipelineRun(id: string, runId: string): Promise<PipelineRunResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/pipelines/${id}/runs/${runId}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
@@ -1,4 +1,4 @@
-import type { ProjectRequest, ProjectResponse, ProjectListResponse } from "../types/api";
+import type { ProjectRequest, ProjectResponse, ProjectListResponse, ProjectUsageResponse, WatchStatusResponse } from "../types/api";
 import { BASE_URL, withCSRFHeaders, readErrorMessage } from "./_base";
 
 export async function listProjects(): Promise<ProjectListResponse> {
@@ -40,3 +40,15 @@ export async function deleteProject(id: string): Promise<void> {
   });
   if (!resp.ok) throw new Error(await readErrorMessage(resp));
 }
+
+export async function getProjectWatch(id: string): Promise<WatchStatusResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/projects/${id}/watch`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getProjectUsage(id: string): Promise<ProjectUsageResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/projects/${id}/usage`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
@@ -1,12 +1,70 @@
 import type {
   SessionRequest,
+  SessionDryRunResponse,
   SessionResponse,
   SessionListResponse,
   SessionStatusResponse,
   SessionInputRequest,
+  SessionReadRequest,
+  ExchangeEntry,
+  ExchangeResponse,
+  ExchangeSetRequest,
+  QuestionResponse,
+  PlanApproveRequest,
+  PRDescriptionRequest,
+  PRDescriptionResponse,
+  CommitListResponse,
+  SessionDiffResponse,
+  PullRequestRequest,
+  PullRequestResponse,
+  BestOfNRequest,
+  BestOfNResponse,
+  HandoffRequest,
+  SessionHandoffRequest,
+  HandoffMessageResponse,
+  HandoffResponse,
+  TakeoverRequest,
+  HandBackRequest,
+  ToolApprovalStatus,
+  ToolApprovalResponse,
+  ToolApprovalListResponse,
+  ToolApprovalDecisionRequest,
+  WaitResponse,
+  WaitListResponse,
+  TakeoverResponse,
+  EmbedToken,
+  EmbedTokenListResponse,
+  EmbedTokenRequest,
+  RedactMessagesRequest,
+  RedactMessagesResponse,
+  MessagePin,
+  MessagePinListResponse,
+  PinMessageRequest,
+  QueuedMessage,
+  QueuedMessageListResponse,
+  AuditEntry,
+  SessionBatchRequest,
+  SessionBulkOperation,
+  SessionBatchResponse,
+  AuditLogResponse,
+  QuarantineItem,
+  QuarantineListResponse,
+  QuarantineStatus,
+  QuestionListResponse,
+  AnswerQuestionRequest,
+  CommandResponse,
+  CommandListResponse,
+  RejectCommandRequest,
+  MessageSearchParams,
+  MessageSearchResponse,
   ActivityHistoryResponse,
+  SessionTimelineResponse,
+  RunAttemptListResponse,
+  ResumeToken,
+  ResumeTokenListResponse,
   DockMcpRequest,
   DockMcpResponse,
+  DockStatusResponse,
 } from "../types/api";
 import {
   BASE_URL,
@@ -82,6 +140,16 @@ export async function createSession(req: SessionRequest): Promise<SessionRespons
   return normalizeSessionResponse(session);
 }
 
+export async function dryRunSession(req: SessionRequest): Promise<SessionDryRunResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/dry-run`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
 export async function createTaskSession(params: {
   taskId: string;
   taskTitle: string;
@@ -141,6 +209,18 @@ export async function getActivityEntries(
   return resp.json();
 }
 
+export async function getSessionTimeline(id: string): Promise<SessionTimelineResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/timeline`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listRunAttempts(id: string): Promise<RunAttemptListResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/attempts`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
 export async function stopSession(id: string): Promise<void> {
   const resp = await fetch(`${BASE_URL}/sessions/${id}`, {
     method: "DELETE",
@@ -166,6 +246,273 @@ export async function resumeSession(id: string, tokenId?: string): Promise<void>
   if (!resp.ok) throw new Error(await readErrorMessage(resp));
 }
 
+export async function listResumeTokens(id: string): Promise<ResumeTokenListResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/resume-tokens`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function revokeResumeToken(tokenId: string, reason?: string): Promise<ResumeToken> {
+  const resp = await fetch(`${BASE_URL}/v1/resume-tokens/${encodeURIComponent(tokenId)}/revoke`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ reason }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function approvePlan(id: string, plan?: string): Promise<SessionResponse> {
+  const payload: PlanApproveRequest = plan ? { plan } : {};
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/plan/approve`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(payload),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return normalizeSessionResponse(await resp.json());
+}
+
+export async function draftPRDescription(
+  id: string,
+  request: PRDescriptionRequest = {},
+): Promise<PRDescriptionResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/pr-description`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listSessionCommits(id: string, limit = 25): Promise<CommitListResponse> {
+  const params = new URLSearchParams({ limit: String(limit) });
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/commits?${params.toString()}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getSessionDiff(id: string): Promise<SessionDiffResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/diff`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function openPullRequest(
+  id: string,
+  request: PullRequestRequest = {},
+): Promise<PullRequestResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/pull-request`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function startBestOfN(id: string, request: BestOfNRequest): Promise<BestOfNResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/best-of-n`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getBestOfN(id: string): Promise<BestOfNResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/best-of-n`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function selectBestOfN(id: string, sessionId: string): Promise<BestOfNResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/best-of-n/select`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ session_id: sessionId }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function discardBestOfN(id: string): Promise<BestOfNResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/best-of-n/discard`, {
+    method: "POST",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function startHandoff(id: string, request: HandoffRequest): Promise<HandoffResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/handoff`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function handOffToSession(
+  id: string,
+  request: SessionHandoffRequest,
+): Promise<HandoffMessageResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/handoff`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getHandoff(id: string): Promise<HandoffResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/handoff`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function startTakeover(id: string, request: TakeoverRequest = {}): Promise<TakeoverResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/takeover`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getTakeover(id: string): Promise<TakeoverResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/takeover`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function handBackTakeover(id: string, request: HandBackRequest = {}): Promise<TakeoverResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/takeover/hand-back`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listEmbedTokens(id: string): Promise<EmbedToken[]> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/embed-tokens`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: EmbedTokenListResponse = await resp.json();
+  return data.tokens;
+}
+
+export async function createEmbedToken(id: string, request: EmbedTokenRequest): Promise<EmbedToken> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/embed-tokens`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function revokeEmbedToken(id: string, tokenId: string): Promise<EmbedToken> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/embed-tokens/${tokenId}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function redactMessages(id: string, messageIds: string[], reason?: string): Promise<number> {
+  const payload: RedactMessagesRequest = { message_ids: messageIds, reason };
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/messages/redact`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(payload),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: RedactMessagesResponse = await resp.json();
+  return data.redacted;
+}
+
+export async function listMessagePins(id: string): Promise<MessagePin[]> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/pinned-messages`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: MessagePinListResponse = await resp.json();
+  return data.pins;
+}
+
+export async function pinMessage(id: string, req: PinMessageRequest): Promise<MessagePin> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/pinned-messages`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function unpinMessage(id: string, pinId: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/pinned-messages/${pinId}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
+
+export async function listAuditEntries(sessionId?: string): Promise<AuditEntry[]> {
+  const query = sessionId ? `?session_id=${encodeURIComponent(sessionId)}` : "";
+  const resp = await fetch(`${BASE_URL}/audit${query}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: AuditLogResponse = await resp.json();
+  return data.entries ?? [];
+}
+
+export async function searchMessages(
+  query: string,
+  { projectId, providerType, limit }: MessageSearchParams = {},
+): Promise<MessageSearchResponse> {
+  const params = new URLSearchParams({ q: query });
+  if (projectId) params.set("project_id", projectId);
+  if (providerType) params.set("provider_type", providerType);
+  if (limit) params.set("limit", String(limit));
+  const resp = await fetch(`${BASE_URL}/search?${params}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listQuarantine(
+  sessionId?: string,
+  status?: QuarantineStatus,
+): Promise<QuarantineItem[]> {
+  const params = new URLSearchParams();
+  if (sessionId) params.set("session_id", sessionId);
+  if (status) params.set("status", status);
+  const query = params.toString() ? `?${params}` : "";
+  const resp = await fetch(`${BASE_URL}/guardrails/quarantine${query}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: QuarantineListResponse = await resp.json();
+  return data.items ?? [];
+}
+
+export async function resolveQuarantineItem(
+  id: string,
+  status: Exclude<QuarantineStatus, "open">,
+): Promise<QuarantineItem> {
+  const resp = await fetch(`${BASE_URL}/guardrails/quarantine/${encodeURIComponent(id)}/resolve`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ status }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
 export async function cancelSession(id: string): Promise<void> {
   const resp = await fetch(`${BASE_URL}/sessions/${id}/cancel`, {
     method: "POST",
@@ -174,6 +521,146 @@ export async function cancelSession(id: string): Promise<void> {
   if (!resp.ok) throw new Error(await readErrorMessage(resp));
 }
 
+export async function batchSessions(
+  op: "cancel" | "stop",
+  selection: SessionBatchRequest,
+): Promise<SessionBatchResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/batch/${op}`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(selection),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function bulkSessions(
+  operation: SessionBulkOperation,
+  selection: SessionBatchRequest,
+): Promise<SessionBatchResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/bulk`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ ...selection, operation }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function markSessionRead(id: string, position?: number): Promise<SessionResponse> {
+  const payload: SessionReadRequest = { position };
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/read`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(payload),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return normalizeSessionResponse(await resp.json());
+}
+
+export async function listExchangeEntries(id: string): Promise<ExchangeResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/exchange`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function setExchangeEntry(id: string, key: string, value: unknown): Promise<ExchangeEntry> {
+  const payload: ExchangeSetRequest = { value };
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/exchange/${encodeURIComponent(key)}`, {
+    method: "PUT",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(payload),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function deleteExchangeEntry(id: string, key: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/exchange/${encodeURIComponent(key)}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
+
+export async function listPendingQuestions(): Promise<QuestionListResponse> {
+  const resp = await fetch(`${BASE_URL}/questions`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listWaits(): Promise<WaitResponse[]> {
+  const resp = await fetch(`${BASE_URL}/v1/waits`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: WaitListResponse = await resp.json();
+  return data.waits;
+}
+
+export async function listSessionQuestions(id: string): Promise<QuestionListResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/questions`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function answerQuestion(id: string, questionId: string, answer: string): Promise<QuestionResponse> {
+  const payload: AnswerQuestionRequest = { answer };
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/questions/${encodeURIComponent(questionId)}/answer`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(payload),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listSessionCommands(id: string): Promise<CommandListResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/commands`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function approveCommand(id: string, commandId: string): Promise<CommandResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/commands/${encodeURIComponent(commandId)}/approve`, {
+    method: "POST",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function rejectCommand(id: string, commandId: string, reason?: string): Promise<CommandResponse> {
+  const payload: RejectCommandRequest = { reason };
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/commands/${encodeURIComponent(commandId)}/reject`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(payload),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function listToolApprovals(id: string, status?: ToolApprovalStatus): Promise<ToolApprovalResponse[]> {
+  const query = status ? `?status=${status}` : "";
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/approvals${query}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: ToolApprovalListResponse = await resp.json();
+  return data.approvals;
+}
+
+export async function decideToolApproval(
+  id: string,
+  approvalId: string,
+  request: ToolApprovalDecisionRequest,
+): Promise<ToolApprovalResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/approvals/${encodeURIComponent(approvalId)}/decision`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(request),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
 export async function sendSessionInput(id: string, input: string): Promise<void> {
   const payload: SessionInputRequest = { input };
   const resp = await fetch(`${BASE_URL}/sessions/${id}/input`, {
@@ -187,12 +674,20 @@ export async function sendSessionInput(id: string, input: string): Promise<void>
 export async function sendMessage(
   id: string,
   content: string,
-  options?: { providerId?: string; providerType?: string },
+  options?: {
+    providerId?: string;
+    providerType?: string;
+    /** Sends the template's rendered prompt, followed by content if any. */
+    templateId?: string;
+    templateVariables?: Record<string, string>;
+  },
 ): Promise<void> {
   const payload = {
     content,
     provider_id: options?.providerId,
     provider_type: options?.providerType,
+    template_id: options?.templateId,
+    template_variables: options?.templateVariables,
   };
   const resp = await fetch(`${BASE_URL}/sessions/${id}/messages`, {
     method: "POST",
@@ -202,10 +697,30 @@ export async function sendMessage(
   if (!resp.ok) throw new Error(await readErrorMessage(resp));
 }
 
+export async function listQueuedMessages(id: string): Promise<QueuedMessage[]> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/queue`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  const data: QueuedMessageListResponse = await resp.json();
+  return data.messages;
+}
+
+export async function removeQueuedMessage(id: string, messageId: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/queue/${encodeURIComponent(messageId)}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
+
 export function getEventsUrl(id: string): string {
   return `${BASE_URL}/sessions/${id}/events`;
 }
 
+/** URL of the session's transcript, for opening or downloading it. */
+export function getTranscriptExportUrl(id: string, format: "markdown" | "html" | "ndjson" = "markdown"): string {
+  return `${BASE_URL}/sessions/${id}/export?format=${format}`;
+}
+
 export function getGlobalSessionEventsUrl(lastEventId?: number): string {
   if (lastEventId && lastEventId > 0) {
     return `${BASE_URL}/sessions/events?last_event_id=${encodeURIComponent(String(lastEventId))}`;
@@ -236,3 +751,17 @@ export async function respondDockMcp(id: string, response: DockMcpResponse): Pro
   });
   if (!resp.ok) throw new Error(await readErrorMessage(resp));
 }
+
+export async function sendDockHeartbeat(id: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/dock/heartbeat`, {
+    method: "POST",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
+
+export async function getDockStatus(id: string): Promise<DockStatusResponse> {
+  const resp = await fetch(`${BASE_URL}/sessions/${id}/dock/status`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
@@ -1,6 +1,8 @@
 import type {
   PermissionsResponse,
   TaskTreeResponse,
+  TaskChange,
+  TaskHistoryResponse,
   CommitListResponse,
   CommitDetailResponse,
   ExtractorConfig,
@@ -22,6 +24,23 @@ export async function getTaskTree(): Promise<TaskTreeResponse> {
   return resp.json();
 }
 
+export async function getTaskHistory(taskId: string): Promise<TaskHistoryResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/tasks/${encodeURIComponent(taskId)}/history`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+/** Undoes the latest task change not yet undone, of the session when given. */
+export async function undoTaskChange(sessionId?: string): Promise<TaskChange> {
+  const resp = await fetch(`${BASE_URL}/v1/tasks/changes/undo`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify({ session_id: sessionId }),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
 export async function listCommits(limit = 25): Promise<CommitListResponse> {
   const params = new URLSearchParams({ limit: String(limit) });
   const resp = await fetch(`${BASE_URL}/v1/commits?${params.toString()}`);
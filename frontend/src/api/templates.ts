@@ -0,0 +1,42 @@
+import type { TemplateRequest, TemplateResponse, TemplateListResponse } from "../types/api";
+import { BASE_URL, withCSRFHeaders, readErrorMessage } from "./_base";
+
+export async function listTemplates(): Promise<TemplateListResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/templates`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function getTemplate(id: string): Promise<TemplateResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/templates/${id}`);
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function createTemplate(req: TemplateRequest): Promise<TemplateResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/templates`, {
+    method: "POST",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function updateTemplate(id: string, req: TemplateRequest): Promise<TemplateResponse> {
+  const resp = await fetch(`${BASE_URL}/v1/templates/${id}`, {
+    method: "PUT",
+    headers: withCSRFHeaders({ "Content-Type": "application/json" }),
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+  return resp.json();
+}
+
+export async function deleteTemplate(id: string): Promise<void> {
+  const resp = await fetch(`${BASE_URL}/v1/templates/${id}`, {
+    method: "DELETE",
+    headers: withCSRFHeaders(),
+  });
+  if (!resp.ok) throw new Error(await readErrorMessage(resp));
+}
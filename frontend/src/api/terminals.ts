@@ -39,12 +39,19 @@ export async function deleteTerminal(id: string): Promise<void> {
 
 export function getTerminalWsUrl(
   id: string,
-  options?: { write?: boolean; allowRaw?: boolean },
+  options?: {
+    write?: boolean;
+    allowRaw?: boolean;
+    role?: "read_only" | "read_write";
+    name?: string;
+  },
 ): string {
   const base = getWebSocketBaseUrl();
   if (!base) return "";
   const url = new URL(`${base}${BASE_URL}/sessions/${id}/terminal/ws`);
-  if (options?.write) url.searchParams.set("write", "true");
+  if (options?.role) url.searchParams.set("role", options.role);
+  else if (options?.write) url.searchParams.set("write", "true");
+  if (options?.name) url.searchParams.set("name", options.name);
   if (options?.allowRaw) url.searchParams.set("allow_raw", "true");
   return url.toString();
 }
@@ -57,6 +57,7 @@ vi.mock("../api/client", () => ({
     createDockSession: vi.fn(),
     pollDockMcp: vi.fn(),
     respondDockMcp: vi.fn(),
+    sendDockHeartbeat: vi.fn(),
     getEventsUrl: vi.fn(),
     pauseSession: vi.fn(),
     resumeSession: vi.fn(),
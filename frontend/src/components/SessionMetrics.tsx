@@ -1,16 +1,25 @@
 import { Show } from "solid-js"
 import type { Accessor } from "solid-js"
-import type { SessionStatusResponse } from "../types/api"
+import type { ContextWindowUsage, SessionStatusResponse } from "../types/api"
 import TerminalView from "./TerminalView"
 
+// Matches the server's near-limit threshold for context window notices.
+const CONTEXT_WARN_PERCENT = 80
+
 interface SessionMetricsProps {
   sessionId: Accessor<string>
   session: Accessor<SessionStatusResponse | undefined>
   providerType: Accessor<string>
+  /** Live context window usage, preferred over the session's last status. */
+  contextWindow?: Accessor<ContextWindowUsage | null>
   onTerminalStatusChange: (status: "connecting" | "live" | "closed" | "error" | "resyncing") => void
 }
 
 export default function SessionMetrics(props: SessionMetricsProps) {
+  const contextWindow = () => props.contextWindow?.() ?? props.session()?.metrics?.context_window
+  const contextPercent = () => contextWindow()?.percent
+  const nearLimit = () => (contextPercent() ?? 0) >= CONTEXT_WARN_PERCENT
+
   return (
     <section class="session-panel">
       <div class="panel-header">
@@ -44,6 +53,23 @@ export default function SessionMetrics(props: SessionMetricsProps) {
           <span>Requests</span>
           <strong>{props.session()?.metrics?.request_count ?? "-"}</strong>
         </div>
+        <div data-testid="session-info-context" classList={{ "context-near-limit": nearLimit() }}>
+          <span>Context window</span>
+          <Show when={contextWindow()} fallback={<strong>-</strong>}>
+            {(usage) => (
+              <Show
+                when={contextPercent() !== undefined}
+                fallback={<strong>{usage().used_tokens} tokens</strong>}
+              >
+                <meter min={0} max={100} high={CONTEXT_WARN_PERCENT} value={contextPercent()} />
+                <strong title={usage().model}>
+                  {Math.round(contextPercent() ?? 0)}% of {usage().limit_tokens}
+                  {nearLimit() ? " · compaction soon" : ""}
+                </strong>
+              </Show>
+            )}
+          </Show>
+        </div>
       </div>
       <Show
         when={props.providerType() === "pty"}
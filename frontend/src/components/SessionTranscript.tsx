@@ -48,6 +48,9 @@ function TranscriptItem(props: { message: TranscriptMessage }) {
   const isLong = createMemo(() => lineCount() > COLLAPSE_LINE_THRESHOLD)
   const normalizedKind = createMemo(() => normalizeKind(props.message.kind))
   const displayLabel = createMemo(() => formatMessageLabel(props.message.type, normalizedKind()))
+  // Provider stderr is kept for troubleshooting and starts collapsed.
+  const isDiagnostic = createMemo(() => normalizedKind() === "diagnostic")
+  const stderrLines = createMemo(() => props.message.content.replace(/\n$/, "").split("\n").length)
 
   const kindClass = createMemo(() => {
     const kind = normalizedKind()
@@ -67,21 +70,31 @@ function TranscriptItem(props: { message: TranscriptMessage }) {
         <time class="transcript-time">{new Date(props.message.timestamp).toLocaleTimeString()}</time>
       </header>
 
-      <div class={`transcript-content ${isLong() && !expanded() ? "transcript-content-collapsed" : ""}`}>
-        <For each={blocks()}>
-          {(block) =>
-            block.kind === "code" ? (
-              <pre>
-                <code data-language={block.lang}>{block.content}</code>
-              </pre>
-            ) : (
-              <p>{block.content}</p>
-            )
-          }
-        </For>
-      </div>
+      <Show
+        when={!isDiagnostic()}
+        fallback={
+          <details class="transcript-diagnostic">
+            <summary>{stderrLines()} {stderrLines() === 1 ? "line" : "lines"} of provider stderr</summary>
+            <pre>{props.message.content}</pre>
+          </details>
+        }
+      >
+        <div class={`transcript-content ${isLong() && !expanded() ? "transcript-content-collapsed" : ""}`}>
+          <For each={blocks()}>
+            {(block) =>
+              block.kind === "code" ? (
+                <pre>
+                  <code data-language={block.lang}>{block.content}</code>
+                </pre>
+              ) : (
+                <p>{block.content}</p>
+              )
+            }
+          </For>
+        </div>
+      </Show>
 
-      <Show when={isLong()}>
+      <Show when={isLong() && !isDiagnostic()}>
         <button
           type="button"
           class="transcript-expand-toggle"
@@ -144,6 +157,8 @@ function formatMessageLabel(type: TranscriptMessage["type"], kind: string) {
       return "Metric"
     case "metadata":
       return "Metadata"
+    case "diagnostic":
+      return "Stderr"
     case "user_input":
       return "User"
     default:
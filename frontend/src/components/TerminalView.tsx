@@ -70,6 +70,19 @@ type TerminalErrorData = {
   resync?: boolean;
 };
 
+type TerminalViewer = {
+  viewer_id: string;
+  name?: string;
+  role: "read_only" | "read_write";
+  joined_at?: string;
+};
+
+type TerminalInputSource = {
+  viewer_id: string;
+  name?: string;
+  input: string;
+};
+
 type TerminalUpdate =
   | { type: "snapshot"; data: TerminalSnapshotData }
   | { type: "diff"; data: TerminalDiffData }
@@ -117,6 +130,9 @@ export default function TerminalView(props: TerminalViewProps) {
   const [writeError, setWriteError] = createSignal<string | null>(null);
   const [killPending, setKillPending] = createSignal(false);
   const [killError, setKillError] = createSignal<string | null>(null);
+  const [viewerId, setViewerId] = createSignal<string | null>(null);
+  const [viewers, setViewers] = createSignal<TerminalViewer[]>([]);
+  const [lastInput, setLastInput] = createSignal<TerminalInputSource | null>(null);
 
   const isWriteMode = () => Boolean(props.writeMode);
   const isConnected = () => status() === "live" || status() === "resyncing";
@@ -263,6 +279,14 @@ export default function TerminalView(props: TerminalViewProps) {
     if (!envelope) return;
     if (envelope.session_id && envelope.session_id !== props.sessionId) return;
 
+    // The joined message is not part of the sequenced stream.
+    if (envelope.type === "terminal.joined") {
+      const joined = envelope.data as { viewer_id: string; viewers?: TerminalViewer[] };
+      setViewerId(joined.viewer_id);
+      setViewers(joined.viewers ?? []);
+      return;
+    }
+
     if (typeof envelope.seq === "number") {
       const seq = envelope.seq;
       setLastSeq(seq);
@@ -301,6 +325,12 @@ export default function TerminalView(props: TerminalViewProps) {
         pendingUpdates.push({ type: "error", data: envelope.data as TerminalErrorData });
         scheduleFlush();
         break;
+      case "terminal.viewers":
+        setViewers((envelope.data as { viewers?: TerminalViewer[] }).viewers ?? []);
+        break;
+      case "terminal.input":
+        setLastInput(envelope.data as TerminalInputSource);
+        break;
       default:
         break;
     }
@@ -529,6 +559,16 @@ export default function TerminalView(props: TerminalViewProps) {
           </Show>
           <span class={`terminal-bell ${bellActive() ? "active" : ""}`}>bell</span>
           <span class="terminal-mode">{isWriteMode() ? "write" : "view"}</span>
+          <Show when={viewers().length > 1}>
+            <span class="terminal-viewers" title={viewers().map((v) => v.name || v.viewer_id).join(", ")}>
+              {`${viewers().length} viewers`}
+            </span>
+          </Show>
+          <Show when={lastInput()?.viewer_id !== viewerId() ? lastInput() : null}>
+            {(source) => (
+              <span class="terminal-input-source">{`input from ${source().name || source().viewer_id}`}</span>
+            )}
+          </Show>
           <span class={`terminal-dot ${status()}`} />
         </div>
       </div>
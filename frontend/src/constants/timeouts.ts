@@ -1,5 +1,7 @@
 export const TIMEOUTS = {
   MCP_POLL_MS: 20000,
+  // The backend treats a dock tab as disconnected 15s after it was last seen.
+  DOCK_HEARTBEAT_MS: 5000,
   STREAM_CONNECTION_MS: 10000,
   // Backend sends heartbeats every 15s; dock stream must wait longer than that
   // before declaring a connection failure on a fresh idle session.
@@ -21,7 +21,7 @@ export function useAgentDockMcp(sessionId: Accessor<string>, isDockSession: Acce
       return { ok: true, components }
     }
     if (request.kind === "multi_edit") {
-      return mcpDispatch.dispatchMultiFieldEdit(request.payload ?? {})
+      return mcpDispatch.dispatchMultiFieldEdit(request.payload ?? { edits: [] })
     }
     if (request.kind === "dispatch") {
       return mcpDispatch.dispatchAction(
@@ -68,8 +68,15 @@ export function useAgentDockMcp(sessionId: Accessor<string>, isDockSession: Acce
 
     void run()
 
+    // Heartbeats keep the dock connected while a request is being handled,
+    // so the backend can fail requests fast once this tab goes away.
+    const heartbeat = setInterval(() => {
+      apiClient.sendDockHeartbeat(activeSessionId).catch(() => {})
+    }, TIMEOUTS.DOCK_HEARTBEAT_MS)
+
     onCleanup(() => {
       cancelled = true
+      clearInterval(heartbeat)
     })
   })
 }
@@ -2,12 +2,14 @@ import { createEffect, createMemo, createResource, createSignal, onCleanup } fro
 import type { Accessor } from "solid-js"
 import type {
   ActivityEntry,
+  ContextWindowUsage,
   SessionState,
   TranscriptMessage,
 } from "../types/api"
 import { parseSSEEvent } from "../types/api"
 import { apiClient } from "../api/client"
 import { formatActivityContent } from "../utils/activityFormatting"
+import { formatNotice } from "../utils/notices"
 import { startEventStream } from "../utils/eventStream"
 import { TIMEOUTS } from "../constants/timeouts"
 import type { StreamOptions } from "./useSessionStream"
@@ -61,6 +63,8 @@ export interface SessionData {
   streamStatus: Accessor<StreamStatus>
   /** Last parsed SSE event (dock-specific UI state can read from here). */
   lastEvent: Accessor<ReturnType<typeof parseSSEEvent>>
+  /** Latest context window usage published on the stream, if any. */
+  contextWindow: Accessor<ContextWindowUsage | null>
 }
 
 // ── Stream event types ────────────────────────────────────────────────────────
@@ -92,6 +96,7 @@ export function useSessionData({
   const [messages, setMessages] = createSignal<TranscriptMessage[]>([])
   const [filter, setFilter] = createSignal("")
   const [autoScroll, setAutoScroll] = createSignal(true)
+  const [contextWindow, setContextWindow] = createSignal<ContextWindowUsage | null>(null)
   const [lastEvent, setLastEvent] = createSignal<ReturnType<typeof parseSSEEvent>>(null)
 
   const filteredMessages = createMemo(() => {
@@ -233,6 +238,30 @@ export function useSessionData({
       }
       case "metadata": {
         const { key, value } = payload.data
+        if (key === "context_window" && value && typeof value === "object") {
+          setContextWindow(value as ContextWindowUsage)
+          break
+        }
+        if (key === "stderr" && typeof value === "string") {
+          // Consecutive stderr extends one diagnostic message, as in history.
+          setMessages((prev) => {
+            const last = prev[prev.length - 1]
+            if (last?.kind === "diagnostic") {
+              return [...prev.slice(0, -1), { ...last, content: last.content + value }]
+            }
+            return [
+              ...prev,
+              {
+                id: stableId("stderr"),
+                type: "system",
+                kind: "diagnostic",
+                timestamp: payload.timestamp,
+                content: value,
+              },
+            ]
+          })
+          break
+        }
         pushMessage({
           id: stableId("metadata"),
           type: "system",
@@ -370,6 +399,7 @@ export function useSessionData({
     setPaginationCursor(undefined)
     setStreamStatus("connecting")
     setLastEvent(null)
+    setContextWindow(null)
 
     // ── Per-run coordination variables ─────────────────────────────────────
     let historySettled = false
@@ -582,6 +612,7 @@ export function useSessionData({
     loadEarlier,
     streamStatus,
     lastEvent,
+    contextWindow,
   }
 }
 
@@ -594,7 +625,8 @@ function toTranscriptFromSessionMessage(message: SessionActivitySnapshot["messag
     type: mapActivityKindToType(kind),
     kind,
     timestamp: message.timestamp,
-    content: message.contents,
+    content: formatNotice(message.notice, message.contents),
+    notice: message.notice,
   }
 }
 
@@ -44,11 +44,26 @@ export interface McpMetadataProps {
   mcpId?: string;
 }
 
-export type McpMultiFieldEditPayload =
-  | Array<{ fieldId: string; value: unknown }>
-  | Record<string, unknown>;
+export interface McpFieldEdit {
+  component_id: string;
+  value: string;
+  expected?: string;
+}
+
+export interface McpMultiFieldEditPayload {
+  edits: McpFieldEdit[];
+}
+
+export type McpFieldEditStatus = "applied" | "failed" | "rolled_back" | "skipped";
+
+export interface McpFieldEditResult {
+  component_id: string;
+  status: McpFieldEditStatus;
+  error?: string;
+}
 
 export type McpMultiFieldEditResult = {
   ok: boolean;
-  results: Record<string, { ok: boolean; error?: string }>;
+  rolled_back?: boolean;
+  results: McpFieldEditResult[];
 };
@@ -57,19 +57,20 @@ describe("createMcpDispatch", () => {
     expect(order).toEqual(["scroll", "handler"]);
   });
 
-  it("returns per-field results for multi-field edit", async () => {
+  it("rolls back earlier edits when a later edit fails", async () => {
     const registry = createMcpRegistry();
     const dispatch = createMcpDispatch(registry);
-    const updates: Record<string, string> = {};
+    const values: Record<string, string> = { "field-1": "old" };
 
     registry.register({
       id: "field-1",
       name: "Field 1",
       description: "First field",
       element: document.createElement("input"),
+      getValue: () => values["field-1"],
       actions: {
         edit: (payload: { value: string }) => {
-          updates["field-1"] = payload.value;
+          values["field-1"] = payload.value;
           return { ok: true };
         },
       },
@@ -86,23 +87,27 @@ describe("createMcpDispatch", () => {
     });
 
     const resultPromise = dispatch.dispatchMultiFieldEdit({
-      "field-1": 42,
-      "field-2": "bad",
+      edits: [
+        { component_id: "field-1", value: "new" },
+        { component_id: "field-2", value: "bad" },
+      ],
     });
     await vi.runAllTimersAsync();
     const result = await resultPromise;
 
     expect(result.ok).toBe(false);
-    expect(result.results["field-1"]).toEqual({ ok: true, error: undefined });
-    expect(result.results["field-2"]).toEqual({ ok: false, error: "Edit failed" });
-    expect(updates["field-1"]).toBe("42");
+    expect(result.rolled_back).toBe(true);
+    expect(result.results).toEqual([
+      { component_id: "field-1", status: "rolled_back" },
+      { component_id: "field-2", status: "failed", error: "Edit failed" },
+    ]);
+    expect(values["field-1"]).toBe("old");
   });
 
-  it("accepts array payloads for multi-field edit", async () => {
+  it("applies nothing when an expected value does not match", async () => {
     const registry = createMcpRegistry();
     const dispatch = createMcpDispatch(registry);
     const updates: Record<string, string> = {};
-    const order: string[] = [];
 
     registry.register({
       id: "field-1",
@@ -112,7 +117,6 @@ describe("createMcpDispatch", () => {
       actions: {
         edit: (payload: { value: string }) => {
           updates["field-1"] = payload.value;
-          order.push("field-1");
           return { ok: true };
         },
       },
@@ -123,27 +127,58 @@ describe("createMcpDispatch", () => {
       name: "Field 2",
       description: "Second field",
       element: document.createElement("input"),
+      getValue: () => "current",
       actions: {
-        edit: (payload: { value: string }) => {
-          updates["field-2"] = payload.value;
-          order.push("field-2");
-          return { ok: true };
-        },
+        edit: () => ({ ok: true }),
       },
     });
 
-    const resultPromise = dispatch.dispatchMultiFieldEdit([
-      { fieldId: "field-2", value: "second" },
-      { fieldId: "field-1", value: 3 },
-    ]);
+    const result = await dispatch.dispatchMultiFieldEdit({
+      edits: [
+        { component_id: "field-1", value: "first" },
+        { component_id: "field-2", value: "second", expected: "stale" },
+        { component_id: "missing", value: "x" },
+      ],
+    });
+
+    expect(result.ok).toBe(false);
+    expect(result.results.map((r) => r.status)).toEqual(["skipped", "failed", "failed"]);
+    expect(result.results[1].error).toContain("expected");
+    expect(result.results[2].error).toBe("Unknown MCP component");
+    expect(updates).toEqual({});
+  });
+
+  it("applies edits in order", async () => {
+    const registry = createMcpRegistry();
+    const dispatch = createMcpDispatch(registry);
+    const order: string[] = [];
+
+    for (const id of ["field-1", "field-2"]) {
+      registry.register({
+        id,
+        name: id,
+        description: id,
+        element: document.createElement("input"),
+        actions: {
+          edit: (payload: { value: string }) => {
+            order.push(`${id}=${payload.value}`);
+            return { ok: true };
+          },
+        },
+      });
+    }
+
+    const resultPromise = dispatch.dispatchMultiFieldEdit({
+      edits: [
+        { component_id: "field-2", value: "second" },
+        { component_id: "field-1", value: "3" },
+      ],
+    });
     await vi.runAllTimersAsync();
     const result = await resultPromise;
 
     expect(result.ok).toBe(true);
-    expect(result.results["field-2"]).toEqual({ ok: true, error: undefined });
-    expect(result.results["field-1"]).toEqual({ ok: true, error: undefined });
-    expect(updates["field-2"]).toBe("second");
-    expect(updates["field-1"]).toBe("3");
-    expect(order).toEqual(["field-2", "field-1"]);
+    expect(result.results.map((r) => r.status)).toEqual(["applied", "applied"]);
+    expect(order).toEqual(["field-2=second", "field-1=3"]);
   });
 });
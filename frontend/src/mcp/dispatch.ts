@@ -2,6 +2,8 @@ import type {
   McpActionKey,
   McpActionPayloads,
   McpActionResult,
+  McpFieldEdit,
+  McpFieldEditResult,
   McpMultiFieldEditPayload,
   McpMultiFieldEditResult,
 } from "./contract";
@@ -11,14 +13,10 @@ import { mcpRegistry } from "./registry";
 const pulseClassName = "mcp-pulse";
 const pulseDurationMs = 360;
 
-const normalizeEditPayload = (payload: McpMultiFieldEditPayload) => {
-  if (Array.isArray(payload)) {
-    return payload.reduce<Record<string, unknown>>((acc, entry) => {
-      acc[entry.fieldId] = entry.value;
-      return acc;
-    }, {});
-  }
-  return payload;
+const currentValue = (entry: McpRegistryEntry) => {
+  if (!entry.getValue) return undefined;
+  const value = entry.getValue();
+  return value === undefined || value === null ? "" : String(value);
 };
 
 const applyPulse = (element: HTMLElement) => {
@@ -76,22 +74,72 @@ export const createMcpDispatch = (registry: McpRegistry) => {
     }
   };
 
+  const checkEdit = (edit: McpFieldEdit) => {
+    const entry = registry.get(edit.component_id);
+    if (!entry) return "Unknown MCP component";
+    if (!entry.actions.edit) return "Action 'edit' not supported";
+    if (edit.expected === undefined) return undefined;
+    const current = currentValue(entry);
+    if (current === undefined) return "Component does not expose its current value";
+    if (current !== edit.expected) {
+      return `Current value is ${JSON.stringify(current)}, expected ${JSON.stringify(edit.expected)}`;
+    }
+    return undefined;
+  };
+
+  // Edits are checked against the page before any is applied, then applied
+  // in order. When one fails, the edits before it are restored to their
+  // previous values where the component exposes them.
   const dispatchMultiFieldEdit = async (
     payload: McpMultiFieldEditPayload,
   ): Promise<McpMultiFieldEditResult> => {
-    const normalized = normalizeEditPayload(payload);
-    const results: Record<string, { ok: boolean; error?: string }> = {};
+    const edits = payload.edits ?? [];
+    const results: McpFieldEditResult[] = edits.map((edit) => ({
+      component_id: edit.component_id,
+      status: "skipped",
+    }));
+
     let ok = true;
+    edits.forEach((edit, i) => {
+      const error = checkEdit(edit);
+      if (error) {
+        results[i] = { component_id: edit.component_id, status: "failed", error };
+        ok = false;
+      }
+    });
+    if (!ok) return { ok, results };
+
+    const previous: Array<string | undefined> = [];
+    for (const [i, edit] of edits.entries()) {
+      const entry = registry.get(edit.component_id);
+      previous[i] = entry ? currentValue(entry) : undefined;
+      const result = await dispatchAction(edit.component_id, "edit", { value: edit.value });
+      if (result.ok) {
+        results[i].status = "applied";
+        continue;
+      }
+      results[i] = { component_id: edit.component_id, status: "failed", error: result.error };
 
-    for (const [fieldId, value] of Object.entries(normalized)) {
-      const result = await dispatchAction(fieldId, "edit", {
-        value: value === undefined || value === null ? "" : String(value),
-      });
-      results[fieldId] = { ok: result.ok, error: result.error };
-      if (!result.ok) ok = false;
+      let rolledBack = true;
+      for (let j = i - 1; j >= 0; j--) {
+        const value = previous[j];
+        if (value === undefined) {
+          results[j].error = "Cannot roll back: component does not expose its previous value";
+          rolledBack = false;
+          continue;
+        }
+        const undo = await dispatchAction(edits[j].component_id, "edit", { value });
+        if (undo.ok) {
+          results[j].status = "rolled_back";
+        } else {
+          results[j].error = `Rollback failed: ${undo.error ?? "unknown error"}`;
+          rolledBack = false;
+        }
+      }
+      return { ok: false, rolled_back: i > 0 ? rolledBack : undefined, results };
     }
 
-    return { ok, results };
+    return { ok: true, results };
   };
 
   return { dispatchAction, dispatchMultiFieldEdit };
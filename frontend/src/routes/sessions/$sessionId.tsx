@@ -262,6 +262,7 @@ export default function SessionViewer(props: SessionViewerProps = {}) {
             sessionId={sessionId}
             session={session}
             providerType={providerType}
+            contextWindow={data.contextWindow}
             onTerminalStatusChange={setTerminalStatus}
           />
 
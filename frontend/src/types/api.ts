@@ -1,9 +1,17 @@
+import type { Notice, SessionMessage } from "./generated/realtime";
+
+export type { Notice };
+
 export type SessionState = "idle" | "running" | "suspended";
 
+/** What startup recovery does with a run interrupted by a server restart. */
+export type RecoveryPolicy = "mark_interrupted" | "auto_retry" | "auto_resume";
+
 export interface MCPServerConfig {
   name: string;
   command: string;
   args?: string[];
+  /** Values are Go templates rendered per run, e.g. "{{.APIBaseURL}}" or "{{.APIToken}}". */
   env?: Record<string, string>;
 }
 
@@ -23,6 +31,251 @@ export interface SessionRequest {
   task_title?: string;
   session_kind?: string;
   title?: string;
+  recovery_policy?: RecoveryPolicy;
+  /** Stop runs after the agent's first plan until it is approved. */
+  plan_approval?: boolean;
+  /** Hold the agent's shell commands until they are approved. */
+  command_approval?: CommandApproval;
+  /** Provider-neutral feature flags; agent flags fill in unset ones. */
+  features?: SessionFeatures;
+  /** Hold tool calls the provider asks permission for until decided. */
+  tool_approval?: ToolApproval;
+  /** Limits what the session may spend across its runs. */
+  cost_budget?: CostBudget;
+  /** Key-value pairs external systems stamp onto the session, e.g. ticket numbers. */
+  metadata?: Record<string, string>;
+  /** Adds the session to a mission. */
+  mission_id?: string;
+  /** Runs the session in a checkout of its own, removed with the session. */
+  workspace?: SessionWorkspace;
+  /** Puts the session on a git branch of its own and records its commits. */
+  git?: SessionGit;
+  /** Applies a prompt template's rendered system prompt and presets to what the request leaves empty. */
+  template_id?: string;
+  /** Values of the template's variables; built-ins such as task_title and repo default from the session. */
+  template_variables?: Record<string, string>;
+}
+
+/** The git branch a session works on. */
+export interface SessionGit {
+  /** Defaults to orbitmesh/task/<task_id>, or orbitmesh/session/<id>. */
+  branch?: string;
+  /** The commit the session's commits follow (responses only). */
+  base_ref?: string;
+  /** Commits the session has made (responses only). */
+  commit_count?: number;
+}
+
+/** A git checkout made for one session under the server's workspace directory. */
+export interface SessionWorkspace {
+  /** A git worktree of a local repository, or a full clone of any repository. */
+  mode: "worktree" | "clone";
+  /** Defaults to the session's working directory. */
+  repo?: string;
+  /** Defaults to HEAD; responses give the commit it resolved to. */
+  base_ref?: string;
+  /** Defaults to orbitmesh/session/<id>. */
+  branch?: string;
+  /** Where the checkout was made (responses only). */
+  path?: string;
+}
+
+/** The config a SessionRequest resolves to, from POST /api/sessions/dry-run.
+ *  Secret values are redacted. */
+export interface SessionDryRunResponse {
+  /** False when the session could not be created or its first run would not start. */
+  valid: boolean;
+  provider_type: string;
+  agent_id?: string;
+  project_id?: string;
+  working_dir: string;
+  session_kind?: string;
+  system_prompt?: string;
+  environment?: Record<string, string>;
+  mcp_servers?: MCPServerConfig[];
+  custom?: Record<string, any>;
+  features?: SessionFeatures;
+  recovery_policy?: RecoveryPolicy;
+  plan_approval?: boolean;
+  cost_budget?: CostBudget;
+  cleanup_commands?: string[];
+  /** Omitted when the provider type is not registered. */
+  capabilities?: ProviderCapabilities;
+  problems?: string[];
+  warnings?: string[];
+}
+
+export type SessionFeature = "enable_web_search" | "allow_network_tools" | "verbose_tools";
+
+export type SessionFeatures = Partial<Record<SessionFeature, boolean>>;
+
+export interface SessionReadRequest {
+  /** Defaults to every current message. */
+  position?: number;
+}
+
+export interface ExchangeEntry {
+  value: unknown;
+  updated_at: string;
+  /** "agent" for values the session's agent wrote, otherwise the user. */
+  updated_by?: string;
+}
+
+export interface ExchangeResponse {
+  entries: Record<string, ExchangeEntry>;
+}
+
+export interface ExchangeSetRequest {
+  value: unknown;
+}
+
+export type QuestionStatus = "pending" | "answered" | "cancelled";
+
+export interface QuestionResponse {
+  id: string;
+  session_id: string;
+  question: string;
+  /** When set, the answer must be one of these. */
+  options?: string[];
+  status: QuestionStatus;
+  asked_at: string;
+  answer?: string;
+  answered_by?: string;
+  answered_at?: string;
+}
+
+export interface QuestionListResponse {
+  questions: QuestionResponse[];
+}
+
+export interface AnswerQuestionRequest {
+  answer: string;
+}
+
+export interface CommandApproval {
+  /** Commands that run without asking, matched with their arguments. */
+  auto_approve?: string[];
+}
+
+export type CommandStatus = "proposed" | "rejected" | "running" | "completed" | "failed";
+
+export interface CommandResponse {
+  id: string;
+  session_id: string;
+  tool_call_id?: string;
+  command: string;
+  status: CommandStatus;
+  proposed_at: string;
+  /** Empty for commands that ran without being proposed. */
+  approval?: "auto" | "approved" | "rejected";
+  decided_by?: string;
+  decided_at?: string;
+  reason?: string;
+  started_at?: string;
+  completed_at?: string;
+  exit_code?: number;
+  duration_ms?: number;
+}
+
+export interface CommandListResponse {
+  commands: CommandResponse[];
+}
+
+export interface RejectCommandRequest {
+  reason?: string;
+}
+
+/** A zero limit is not enforced; runs over the budget are suspended until it is raised, or cancelled. */
+export interface CostBudget {
+  limit_usd?: number;
+  limit_tokens?: number;
+  action?: "suspend" | "cancel";
+}
+
+export interface ToolApproval {
+  /** Tool names that run without asking. */
+  auto_allow?: string[];
+  /** Go duration after which an undecided call gets on_timeout. */
+  timeout?: string;
+  on_timeout?: "deny" | "allow";
+}
+
+export type ToolApprovalStatus = "pending" | "allowed" | "denied";
+
+export interface ToolApprovalResponse {
+  id: string;
+  session_id: string;
+  tool_call_id?: string;
+  tool_name: string;
+  input?: Record<string, unknown>;
+  description?: string;
+  status: ToolApprovalStatus;
+  requested_at: string;
+  /** "timeout" for calls decided by the session's timeout action. */
+  decided_by?: string;
+  decided_at?: string;
+  reason?: string;
+  updated_input?: Record<string, unknown>;
+}
+
+export interface ToolApprovalListResponse {
+  approvals: ToolApprovalResponse[];
+}
+
+export type WaitKind =
+  | "waiting_on_human"
+  | "plan_approval"
+  | "command_approval"
+  | "tool_approval"
+  | "tool_call"
+  | "queued_remote"
+  | "expired_resume_token";
+
+export interface WaitResponse {
+  kind: WaitKind;
+  session_id: string;
+  session_title?: string;
+  /** Question, command or approval ID, or the external tool call. */
+  ref?: string;
+  summary?: string;
+  since: string;
+  age_seconds: number;
+  /** Waits older than a day are high. */
+  priority: "high" | "normal" | "low";
+}
+
+export interface WaitListResponse {
+  waits: WaitResponse[];
+}
+
+export interface ToolApprovalDecisionRequest {
+  decision: "allow" | "deny";
+  reason?: string;
+  /** Replaces the tool call's input when allowing. */
+  input?: Record<string, unknown>;
+}
+
+export interface MessageSearchHit {
+  session_id: string;
+  session_title?: string;
+  session_state: SessionState;
+  provider_type: string;
+  project_id?: string;
+  index: number;
+  message: SessionMessage;
+  snippet: string;
+}
+
+export interface MessageSearchResponse {
+  query: string;
+  hits: MessageSearchHit[];
+  more?: boolean;
+}
+
+export interface MessageSearchParams {
+  projectId?: string;
+  providerType?: string;
+  limit?: number;
 }
 
 export interface SessionInputRequest {
@@ -43,36 +296,749 @@ export interface SessionResponse {
   created_at: string;
   updated_at: string;
   current_task?: string;
+  pinned?: boolean;
+  recovery_policy?: RecoveryPolicy;
+  plan_approval?: boolean;
+  plan?: SessionPlan;
+  command_approval?: CommandApproval;
+  features?: SessionFeatures;
+  /** The human driving the session by hand while it is taken over. */
+  taken_over_by?: string;
+  tool_approval?: ToolApproval;
+  /** The session's key messages, oldest first. */
+  message_pins?: MessagePin[];
+  /** Messages waiting for the current run to end. */
+  queued_messages?: number;
+  cost_budget?: CostBudget;
+  metadata?: Record<string, string>;
+  mission_id?: string;
+  /** The checkout the session runs in, if it asked for one. */
+  workspace?: SessionWorkspace;
+  /** The branch the session works on, if it tracks one. */
+  git?: SessionGit;
+  /** External tool calls a suspended run waits on (single-session GET only). */
+  wait_set?: SessionWaitSet;
+  /** Messages the requesting user has seen, and agent messages since. */
+  last_read_position?: number;
+  unread_count?: number;
   output?: string;
   error_message?: string;
 }
 
+/** The run resumes once `quorum` of `waits` are fulfilled. */
+export interface SessionWaitSet {
+  kind: string;
+  quorum: number;
+  waits: SessionWait[];
+}
+
+export interface SessionWait {
+  ref: string;
+  fulfilled: boolean;
+  fulfilled_at?: string;
+}
+
+export type PlanStatus = "proposed" | "approved";
+
+export interface SessionPlan {
+  content: string;
+  status: PlanStatus;
+  proposed_at: string;
+  /** Set when the approver replaced the agent's plan. */
+  edited?: boolean;
+  approved_by?: string;
+  approved_at?: string;
+}
+
+export interface PlanApproveRequest {
+  /** Replaces the agent's plan when set. */
+  plan?: string;
+}
+
+export interface PRDescriptionRequest {
+  /** Git revision to diff against; HEAD when unset. */
+  base?: string;
+  /** Opens the draft as a pull request. */
+  push?: boolean;
+}
+
+/** Empty title or body are drafted from the session. */
+export interface PullRequestRequest {
+  title?: string;
+  body?: string;
+  /** Overrides the project's target branch. */
+  base?: string;
+  draft?: boolean;
+}
+
+export interface PullRequestResponse {
+  provider: string;
+  url: string;
+  number: number;
+  branch: string;
+  base: string;
+  title: string;
+  body: string;
+}
+
+/** The API host and token are server configuration, never part of a project. */
+export interface PullRequestConfig {
+  provider: "github" | "gitlab";
+  /** GitHub owner/name or GitLab project path. */
+  repo: string;
+  /** Git remote to push to; origin when unset. */
+  remote?: string;
+  /** Target branch; the remote's default branch when unset. */
+  base?: string;
+}
+
+export interface PRDescriptionResponse {
+  title: string;
+  body: string;
+  base: string;
+  diff_stat?: string;
+  files?: string[];
+}
+
+export interface EmbedTokenRequest {
+  label?: string;
+  /** Pages allowed to fetch the embed endpoints and frame the widget. */
+  origins?: string[];
+  /** Unset or 0 never expires. */
+  ttl_seconds?: number;
+}
+
+export interface EmbedToken {
+  id: string;
+  session_id: string;
+  label?: string;
+  origins?: string[];
+  created_by?: string;
+  created_at: string;
+  expires_at?: string;
+  revoked_at?: string;
+  /** Only returned when the token is created. */
+  token?: string;
+  widget_url?: string;
+}
+
+export interface EmbedTokenListResponse {
+  tokens: EmbedToken[];
+}
+
+export interface BestOfNCandidateSpec {
+  provider_type?: string;
+  provider_id?: string;
+}
+
+export interface BestOfNRequest {
+  prompt: string;
+  /** Number of copies of the session's provider when candidates is unset. */
+  n?: number;
+  candidates?: BestOfNCandidateSpec[];
+}
+
+export type BestOfNStatus = "running" | "merged" | "discarded";
+
+export interface BestOfNCandidate {
+  session_id: string;
+  provider_type: string;
+  provider_id?: string;
+  branch: string;
+  state?: string;
+  files?: string[];
+  insertions: number;
+  deletions: number;
+  diff_stat?: string;
+  last_output?: string;
+  error?: string;
+}
+
+export interface BestOfNResponse {
+  prompt: string;
+  base_ref: string;
+  status: BestOfNStatus;
+  created_at: string;
+  winner_id?: string;
+  decided_by?: string;
+  decided_at?: string;
+  candidates: BestOfNCandidate[];
+}
+
+export interface HandoffRequest {
+  provider_type?: string;
+  provider_id?: string;
+  /** Added to the handoff document the new provider starts from. */
+  instructions?: string;
+}
+
+/** Hands a message to another session, which runs it now or queues it. */
+export interface SessionHandoffRequest {
+  target_session_id: string;
+  /** The handing session's last output when unset. */
+  message?: string;
+  instructions?: string;
+}
+
+export interface HandoffMessageResponse {
+  session_id: string;
+  target_session_id: string;
+  /** Follow delivery at /api/sessions/{target_session_id}/messages/{message_id}/receipt. */
+  message_id: string;
+  content: string;
+}
+
+export type HandoffStatus = "summarizing" | "started" | "failed";
+
+export interface HandoffDocument {
+  summary: string;
+  open_questions?: string[];
+  decisions?: string[];
+  files?: string[];
+  next_steps?: string[];
+}
+
+export interface HandoffResponse {
+  status: HandoffStatus;
+  from_provider_type: string;
+  from_provider_id?: string;
+  to_provider_type: string;
+  to_provider_id?: string;
+  instructions?: string;
+  document?: HandoffDocument;
+  requested_by?: string;
+  requested_at: string;
+  completed_at?: string;
+  error?: string;
+}
+
+export interface TakeoverRequest {
+  reason?: string;
+}
+
+export interface HandBackRequest {
+  /** Added to the briefing the agent is given. */
+  note?: string;
+}
+
+export interface TakeoverResponse {
+  active: boolean;
+  by: string;
+  reason?: string;
+  started_at: string;
+  ended_at?: string;
+  handed_back_by?: string;
+  note?: string;
+  briefing?: string;
+  /** Why the briefing could not be given to the agent. */
+  briefing_error?: string;
+}
+
+export interface RedactMessagesRequest {
+  message_ids: string[];
+  reason?: string;
+}
+
+export interface RedactMessagesResponse {
+  /** How many messages were newly redacted. */
+  redacted: number;
+}
+
+/** A message, or an excerpt of it, pinned as a key outcome of a session. */
+export interface MessagePin {
+  id: string;
+  message_id: string;
+  kind: string;
+  excerpt: string;
+  note?: string;
+  message_at: string;
+  pinned_by?: string;
+  pinned_at: string;
+}
+
+export interface PinMessageRequest {
+  message_id: string;
+  /** Pins only this part of the message; must appear in it verbatim. */
+  excerpt?: string;
+  note?: string;
+}
+
+export interface MessagePinListResponse {
+  pins: MessagePin[];
+}
+
+/** A message waiting for the session's current run to end. */
+export interface QueuedMessage {
+  message_id: string;
+  content: string;
+  provider_id?: string;
+  provider_type?: string;
+  deadline?: string;
+  priority?: string;
+  token_budget?: number;
+  on_budget?: string;
+  queued_by?: string;
+  queued_at: string;
+}
+
+export interface QueuedMessageListResponse {
+  messages: QueuedMessage[];
+}
+
+/**
+ * Selects sessions for a batch operation, by ID or by filter. operation is
+ * only read by POST /api/sessions/bulk.
+ */
+export interface SessionBatchRequest {
+  operation?: SessionBulkOperation;
+  session_ids?: string[];
+  filter?: SessionBatchFilter;
+}
+
+export type SessionBulkOperation = "stop" | "cancel" | "delete" | "resume";
+
+export interface SessionBatchFilter {
+  project_id?: string;
+  /** Matches the session kind or provider type. */
+  tag?: string;
+  state?: SessionState;
+}
+
+export interface SessionBatchResult {
+  session_id: string;
+  ok: boolean;
+  code?: ErrorCode;
+  error?: string;
+}
+
+export interface SessionBatchResponse {
+  results: SessionBatchResult[];
+  succeeded: number;
+  failed: number;
+}
+
+export interface AuditEntry {
+  timestamp: string;
+  actor?: string;
+  action: string;
+  session_id?: string;
+  targets?: string[];
+  reason?: string;
+}
+
+export interface AuditLogResponse {
+  entries: AuditEntry[];
+}
+
+export type GuardrailAction = "off" | "flag" | "block";
+
+export interface GuardrailRule {
+  name: string;
+  category?: string;
+  /** Go regular expression. */
+  pattern: string;
+  action?: GuardrailAction;
+}
+
+/** Output guardrail policy; actions maps a category (secret, pii, content or custom) to an action. */
+export interface GuardrailPolicy {
+  actions?: Record<string, GuardrailAction>;
+  rules?: GuardrailRule[];
+}
+
+export interface QuarantineFinding {
+  rule: string;
+  category: string;
+  action: GuardrailAction;
+  /** Masked match. */
+  match?: string;
+}
+
+export type QuarantineStatus = "open" | "dismissed" | "confirmed";
+
+export interface QuarantineItem {
+  id: string;
+  session_id: string;
+  project_id?: string;
+  created_at: string;
+  source: string;
+  action: GuardrailAction;
+  findings: QuarantineFinding[];
+  content: string;
+  status: QuarantineStatus;
+  resolved_by?: string;
+  resolved_at?: string;
+}
+
+export interface QuarantineListResponse {
+  items: QuarantineItem[];
+}
+
 export interface ProjectRequest {
   name: string;
   path: string;
+  /** Shell commands run in a session's working directory when it is stopped, killed or cleaned up. */
+  cleanup_commands?: string[];
+  /** Keeps the project's session history here instead of the server's base directory; relative to path, e.g. ".orbitmesh". */
+  storage_dir?: string;
+  /** Overrides the server's output guardrail policy for the project's sessions. */
+  guardrails?: GuardrailPolicy;
+  /** Suspends the project's running sessions outside these hours and resumes them when the next window opens. */
+  working_hours?: WorkingHours;
+  /** Starts sessions when files under path that match its rules change. */
+  watch?: Watch;
+  /** Allows or denies the project's sessions' tool calls by rule before any approval is asked for. */
+  tool_policy?: ToolPolicy;
+  /** Limits what the project's sessions may spend together. */
+  cost_budget?: CostBudget;
+  pull_requests?: PullRequestConfig;
+}
+
+/** Rules are checked in order: deny, deny_commands, write_paths, allow_commands, allow, then default. */
+export interface ToolPolicy {
+  /** Tool names; ACP agents' tools are named by kind, e.g. "edit" or "execute". */
+  allow?: string[];
+  deny?: string[];
+  /** Denies file edits outside these paths or globs, relative to the working directory; "dir/**" matches everything under dir. */
+  write_paths?: string[];
+  /** Regular expressions matched against shell commands. */
+  allow_commands?: string[];
+  deny_commands?: string[];
+  /** Decides calls no rule matches; omit to leave them to the session's approvals. */
+  default?: 'allow' | 'deny';
+}
+
+/** Days are three-letter weekday names (default mon-fri); start and end are "HH:MM" in time_zone (default the server's). */
+export interface WorkingHours {
+  days?: string[];
+  start: string;
+  end: string;
+  time_zone?: string;
+  /** Limits the policy to sessions of these kinds; empty applies it to all. */
+  session_kinds?: string[];
 }
 
 export interface ProjectResponse {
   id: string;
   name: string;
   path: string;
+  cleanup_commands?: string[];
+  storage_dir?: string;
   created_at: string;
   updated_at: string;
+  guardrails?: GuardrailPolicy;
+  working_hours?: WorkingHours;
+  watch?: Watch;
+  tool_policy?: ToolPolicy;
+  cost_budget?: CostBudget;
+  pull_requests?: PullRequestConfig;
+}
+
+export interface Watch {
+  rules: WatchRule[];
+}
+
+/**
+ * Globs are relative to the project path; "**" matches any number of directories.
+ * prompt is a Go text/template with .Rule, .Summary, .Files, .Added, .Modified and .Removed.
+ */
+export interface WatchRule {
+  name: string;
+  globs: string[];
+  prompt?: string;
+  provider_type?: string;
+  /** Stored provider and agent configs applied to the rule's sessions. */
+  provider_id?: string;
+  agent_id?: string;
+}
+
+export interface WatchTrigger {
+  rule: string;
+  session_id?: string;
+  files: string[];
+  at: string;
+  error?: string;
+}
+
+export interface WatchStatusResponse {
+  watch?: Watch;
+  triggers: WatchTrigger[];
+}
+
+export interface UsageTotals {
+  input_tokens: number;
+  output_tokens: number;
+  cost_usd: number;
+}
+
+/** usage counts every session the project ever had; sessions lists the current ones, most expensive first. */
+export interface ProjectUsageResponse {
+  project_id: string;
+  usage: UsageTotals;
+  cost_budget?: CostBudget;
+  /** The budget limit reached, e.g. "$5.00" or "100000 tokens". */
+  budget_exceeded?: string;
+  sessions: (UsageTotals & { session_id: string })[];
 }
 
 export interface ProjectListResponse {
   projects: ProjectResponse[];
 }
 
+/** A mission groups the sessions of a team of agents working toward one goal. */
+export interface MissionRequest {
+  name: string;
+  goal?: string;
+  project_id?: string;
+  /** Limits what the mission's sessions may spend together, counted from when they joined. */
+  cost_budget?: CostBudget;
+}
+
+export type MissionStatus = "pending" | "running" | "waiting" | "idle";
+
+export interface MissionSession {
+  session_id: string;
+  title?: string;
+  state: SessionState;
+  usage: UsageTotals;
+}
+
+/** status is derived from the sessions; usage includes sessions since removed. */
+export interface MissionResponse extends MissionRequest {
+  id: string;
+  status: MissionStatus;
+  usage: UsageTotals;
+  budget_exceeded?: string;
+  sessions: MissionSession[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface MissionListResponse {
+  missions: MissionResponse[];
+}
+
+/** author is "agent" for a session's agent, "system" for membership changes, otherwise the user. */
+export interface MissionLogEntry {
+  at: string;
+  author: string;
+  session_id?: string;
+  text: string;
+}
+
+export interface MissionLogResponse {
+  entries: MissionLogEntry[];
+}
+
+/**
+ * A pipeline is a DAG of steps, each run in its own session. A step's prompt
+ * is a Go template over .Input and .Steps.<name>.Output.
+ */
+export interface PipelineStep {
+  name: string;
+  provider_type?: string;
+  /** Stored provider and agent configs applied to the step's session. */
+  provider_id?: string;
+  agent_id?: string;
+  prompt: string;
+  /** The step session's system prompt. */
+  role?: string;
+  depends_on?: string[];
+}
+
+/** working_dir defaults to the project's path. */
+export interface PipelineRequest {
+  name: string;
+  project_id?: string;
+  working_dir?: string;
+  steps: PipelineStep[];
+}
+
+export type PipelineRunStatus = "running" | "completed" | "failed";
+export type PipelineStepStatus = "pending" | "running" | "completed" | "failed" | "skipped";
+
+export interface PipelineStepRun {
+  name: string;
+  status: PipelineStepStatus;
+  session_id?: string;
+  output?: string;
+  error?: string;
+  started_at?: string;
+  completed_at?: string;
+}
+
+export interface PipelineRunResponse {
+  id: string;
+  pipeline_id: string;
+  status: PipelineRunStatus;
+  input?: string;
+  steps: PipelineStepRun[];
+  started_at: string;
+  completed_at?: string;
+}
+
+export interface PipelineResponse extends PipelineRequest {
+  id: string;
+  working_dir: string;
+  last_run?: PipelineRunResponse;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PipelineListResponse {
+  pipelines: PipelineResponse[];
+}
+
+/** Newest first. */
+export interface PipelineRunListResponse {
+  runs: PipelineRunResponse[];
+}
+
+/** A template variable; default is used when a render supplies no value. */
+export interface TemplateVariable {
+  name: string;
+  description?: string;
+  default?: string;
+}
+
+/**
+ * A named prompt template. prompt and system_prompt are Go text/template
+ * templates, e.g. {{.repo}}, over supplied variables, declared defaults and the
+ * built-ins task_id, task_title, repo, project, working_dir, provider_type
+ * and date. provider_type and agent_id are presets for sessions.
+ */
+export interface TemplateRequest {
+  name: string;
+  description?: string;
+  prompt?: string;
+  system_prompt?: string;
+  provider_type?: string;
+  agent_id?: string;
+  variables?: TemplateVariable[];
+}
+
+export interface TemplateResponse extends TemplateRequest {
+  id: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface TemplateListResponse {
+  templates: TemplateResponse[];
+}
+
 export interface SessionListResponse {
   sessions: SessionResponse[];
 }
 
+export interface TimelineSpan {
+  id?: string;
+  label: string;
+  detail?: string;
+  start: string;
+  end?: string;
+  duration_ms: number;
+  lane: number;
+}
+
+export interface SessionTimelineResponse {
+  session_id: string;
+  start: string;
+  end: string;
+  runs: TimelineSpan[];
+  suspensions: TimelineSpan[];
+  tool_calls: TimelineSpan[];
+  human_waits: TimelineSpan[];
+  takeovers: TimelineSpan[];
+}
+
+export interface ControlOperation {
+  seq: number;
+  op: string;
+  requested_at: string;
+  outcome: "applied" | "noop" | "rejected" | string;
+  error?: string;
+  replayed?: boolean;
+}
+
+/** One run of a session and why it ended. */
+export interface RunAttempt {
+  attempt_id: string;
+  provider_type: string;
+  provider_id?: string;
+  started_at: string;
+  ended_at?: string;
+  terminal_reason?: string;
+  interruption_reason?: string;
+  wait_kind?: string;
+  wait_ref?: string;
+  operations: ControlOperation[];
+  /** Left open by a server that stopped; it ended by heartbeat_at. */
+  abandoned?: boolean;
+  heartbeat_at?: string;
+  delivery?: "queued" | "delivered" | "failed" | string;
+  delivery_error?: string;
+  /** The attempt this one continues after it used up its token budget. */
+  continued_from?: string;
+}
+
+export interface ResumeToken {
+  token_id: string;
+  session_id: string;
+  attempt_id: string;
+  status: "active" | "consumed" | "revoked" | "expired" | string;
+  created_at: string;
+  expires_at?: string;
+  consumed_at?: string;
+  revoked_at?: string;
+  revocation_reason?: string;
+}
+
+export interface ResumeTokenListResponse {
+  tokens: ResumeToken[];
+}
+
+export interface RunAttemptListResponse {
+  attempts: RunAttempt[];
+}
+
+export interface SessionSyncResponse {
+  revision: number;
+  since?: number;
+  reset?: boolean;
+  sessions: SessionResponse[];
+  deleted?: string[];
+}
+
+export interface MirrorStatusResponse {
+  upstream: string;
+  revision: number;
+  synced_at?: string;
+  connected: boolean;
+  error?: string;
+}
+
 export interface SessionMetrics {
   tokens_in: number;
   tokens_out: number;
   request_count: number;
   last_activity_at?: string;
+  /** How full the model's context window was at the latest request. */
+  context_window?: ContextWindowUsage;
+}
+
+/**
+ * How much of its model's context window a session's conversation fills.
+ * Also published live as the value of "context_window" metadata events.
+ * limit_tokens and percent are omitted when the model's window is unknown.
+ */
+export interface ContextWindowUsage {
+  model?: string;
+  used_tokens: number;
+  limit_tokens?: number;
+  percent?: number;
+  near_limit?: boolean;
+  updated_at?: string;
 }
 
 export interface SessionStatusResponse extends SessionResponse {
@@ -85,6 +1051,8 @@ export interface StatusChangeData {
   old_state: string;
   new_state: string;
   reason?: string;
+  /** The reason as a code, when it has one. */
+  notice?: Notice;
 }
 
 export interface OutputData {
@@ -97,6 +1065,9 @@ export interface MetricData {
   tokens_in: number;
   tokens_out: number;
   request_count: number;
+  cache_read_tokens?: number;
+  cache_creation_tokens?: number;
+  cost_usd?: number;
 }
 
 export interface ErrorData {
@@ -116,6 +1087,14 @@ export interface ToolCallData {
   title?: string;
   input?: unknown;
   output?: unknown;
+  /** Tool call that spawned this one when it ran inside a subagent. */
+  parent_id?: string;
+  usage?: TokenUsage;
+}
+
+export interface TokenUsage {
+  input_tokens: number;
+  output_tokens: number;
 }
 
 export interface ThoughtData {
@@ -133,6 +1112,17 @@ export interface PlanData {
   steps?: PlanStep[];
 }
 
+export interface ProgressData {
+  percent: number;
+  phase: string;
+}
+
+/** Periodic sign of life for a running session. */
+export interface LivenessData {
+  last_activity_at: string;
+  tokens_last_minute: number;
+}
+
 export interface SessionStateStreamEvent {
   event_id: number;
   type: "session_state";
@@ -154,6 +1144,8 @@ export type SSEEvent =
   | { event_id: number; type: "tool_call";     timestamp: string; session_id: string; data: ToolCallData }
   | { event_id: number; type: "thought";       timestamp: string; session_id: string; data: ThoughtData }
   | { event_id: number; type: "plan";          timestamp: string; session_id: string; data: PlanData }
+  | { event_id: number; type: "progress";      timestamp: string; session_id: string; data: ProgressData }
+  | { event_id: number; type: "liveness";      timestamp: string; session_id: string; data: LivenessData }
 
 export type SSEEventType = SSEEvent["type"]
 
@@ -212,9 +1204,47 @@ export interface PermissionsResponse {
   requires_owner_approval_for_role_changes: boolean;
 }
 
+// Mirrors the ErrorCode registry in backend/pkg/api/errors.go.
+export type ErrorCode =
+  | "invalid_request"
+  | "unauthorized"
+  | "forbidden"
+  | "not_found"
+  | "conflict"
+  | "gone"
+  | "rate_limited"
+  | "internal_error"
+  | "not_implemented"
+  | "unavailable"
+  | "timeout"
+  | "session_not_found"
+  | "session_exists"
+  | "project_not_found"
+  | "agent_not_found"
+  | "template_not_found"
+  | "provider_not_found"
+  | "terminal_not_found"
+  | "invalid_state"
+  | "provider_unavailable"
+  | "quota_exceeded"
+  | "working_dir_busy"
+  | "invalid_csrf_token"
+  | "invalid_resume_token"
+  | "expired_resume_token"
+  | "revoked_resume_token"
+  | "operation_timeout"
+  | "shutting_down"
+  | "storage_unavailable"
+  | "demo_restricted"
+  | "demo_session_limit"
+  | "read_only_mirror"
+  | "read_only_session"
+  | "session_taken_over"
+  | "capability_unsupported";
+
 export interface ErrorResponse {
   error: string;
-  code?: string;
+  code: ErrorCode;
   details?: any;
 }
 
@@ -232,6 +1262,24 @@ export interface DockMcpResponse {
   error?: string;
 }
 
+/** The dock tab counts as connected while it polls or shortly after a heartbeat. */
+export interface DockStatusResponse {
+  connected: boolean;
+  polling: boolean;
+  last_seen_at?: string;
+  pending_requests: number;
+}
+
+export interface DockFieldEdit {
+  component_id: string;
+  value: string;
+  expected?: string;
+}
+
+export interface DockMultiEditPayload {
+  edits: DockFieldEdit[];
+}
+
 export type TaskStatus = "pending" | "in_progress" | "completed";
 
 export interface TaskNode {
@@ -247,6 +1295,30 @@ export interface TaskTreeResponse {
   tasks: TaskNode[];
 }
 
+/** A task mutation made through the strand MCP tools; before and after are `strand show --format json` output. */
+export interface TaskChange {
+  id: string;
+  session_id?: string;
+  task_id: string;
+  op: "add" | "claim" | "complete";
+  /** The todo a complete change completed, if not the whole task. */
+  todo?: number;
+  working_dir?: string;
+  project_dir?: string;
+  summary?: string;
+  before?: string;
+  after?: string;
+  at: string;
+  undone_at?: string;
+  undone_by?: string;
+}
+
+/** Journaled task changes, oldest first. */
+export interface TaskHistoryResponse {
+  task_id?: string;
+  changes: TaskChange[];
+}
+
 export interface CommitSummary {
   sha: string;
   message: string;
@@ -257,6 +1329,27 @@ export interface CommitSummary {
   session_id?: string;
 }
 
+/** Everything a session changed in its working directory since it was
+ *  created, committed or not, from GET /api/sessions/{id}/diff. */
+export interface SessionDiffResponse {
+  base_ref: string;
+  /** Every changed file, even when `diff` is truncated. */
+  files: DiffFileSummary[];
+  insertions: number;
+  deletions: number;
+  /** Unified diff, cut at a line boundary past 1 MiB. */
+  diff: string;
+  truncated?: boolean;
+}
+
+/** One changed file; binary files have no line counts. */
+export interface DiffFileSummary {
+  path: string;
+  insertions: number;
+  deletions: number;
+  binary?: boolean;
+}
+
 export interface CommitListResponse {
   commits: CommitSummary[];
 }
@@ -426,6 +1519,17 @@ export interface ProviderConfigResponse {
   env?: Record<string, string>;
   custom?: Record<string, any>;
   is_active: boolean;
+  /** Omitted when the provider type is not registered. */
+  capabilities?: ProviderCapabilities;
+}
+
+export interface ProviderCapabilities {
+  suspend: boolean;
+  interrupt: boolean;
+  steering: boolean;
+  terminal: boolean;
+  images: boolean;
+  resume_after_restart: boolean;
 }
 
 export interface ProviderConfigListResponse {
@@ -443,6 +1547,9 @@ export interface AgentConfigRequest {
   system_prompt?: string;
   mcp_servers?: MCPServerConfig[];
   custom?: Record<string, any>;
+  cleanup_commands?: string[];
+  features?: SessionFeatures;
+  tool_policy?: ToolPolicy;
 }
 
 export interface AgentConfigResponse {
@@ -451,6 +1558,9 @@ export interface AgentConfigResponse {
   system_prompt?: string;
   mcp_servers?: MCPServerConfig[];
   custom?: Record<string, any>;
+  cleanup_commands?: string[];
+  features?: SessionFeatures;
+  tool_policy?: ToolPolicy;
 }
 
 export interface AgentConfigListResponse {
@@ -470,6 +1580,8 @@ export interface TranscriptMessage {
   open?: boolean;
   /** Activity/event kind (e.g. "tool_use", "assistant", "status_change") */
   kind?: string;
+  /** Code of a system message; content holds its English text. */
+  notice?: Notice;
 }
 
 export type TranscriptMessageType = "agent" | "user" | "system" | "error";
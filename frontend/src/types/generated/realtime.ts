@@ -1,5 +1,141 @@
 // Code generated by tygo. DO NOT EDIT.
 
+//////////
+// source: payloads.go
+
+export const PayloadVersion = 1;
+export interface StatusChangePayload {
+  old_state: string;
+  new_state: string;
+  reason?: string;
+  notice?: Notice;
+}
+export interface OutputPayload {
+  content: string;
+  is_delta?: boolean;
+}
+export interface MetricPayload {
+  tokens_in: number /* int64 */;
+  tokens_out: number /* int64 */;
+  request_count: number /* int64 */;
+  cache_read_tokens?: number /* int64 */;
+  cache_creation_tokens?: number /* int64 */;
+  cost_usd?: number /* float64 */;
+}
+export interface ErrorPayload {
+  message: string;
+  code?: string;
+}
+export interface MetadataPayload {
+  key: string;
+  value: unknown;
+}
+export interface ToolCallPayload {
+  id: string;
+  name: string;
+  status?: string;
+  title?: string;
+  input?: unknown;
+  output?: unknown;
+  parent_id?: string;
+  usage?: TokenUsagePayload;
+}
+export interface TokenUsagePayload {
+  input_tokens: number /* int64 */;
+  output_tokens: number /* int64 */;
+}
+export interface ThoughtPayload {
+  content: string;
+}
+export interface PlanPayload {
+  description?: string;
+  steps?: PlanStepPayload[];
+}
+export interface PlanStepPayload {
+  id: string;
+  description: string;
+  status?: string;
+}
+export interface ProgressPayload {
+  percent: number /* int */;
+  phase: string;
+}
+export interface LivenessPayload {
+  last_activity_at: string;
+  tokens_last_minute: number /* int64 */;
+}
+export interface SessionActivityPayloads {
+  status_change: StatusChangePayload;
+  output: OutputPayload;
+  metric: MetricPayload;
+  error: ErrorPayload;
+  metadata: MetadataPayload;
+  tool_call: ToolCallPayload;
+  thought: ThoughtPayload;
+  plan: PlanPayload;
+  progress: ProgressPayload;
+  liveness: LivenessPayload;
+}
+export type SessionActivityEventType = keyof SessionActivityPayloads;
+export type SessionActivityData = SessionActivityPayloads[SessionActivityEventType];
+/**
+ * A SessionActivityEvent whose data is narrowed by its type.
+ */
+export type TypedSessionActivityEvent = {
+  [K in SessionActivityEventType]: Omit<SessionActivityEvent, "type" | "data"> & { type: K; data: SessionActivityPayloads[K] };
+}[SessionActivityEventType];
+export interface TerminalDiffPayload {
+  region: TerminalRegion;
+  lines: string[];
+  reason?: string;
+}
+export interface TerminalRegion {
+  x: number /* int */;
+  y: number /* int */;
+  x2: number /* int */;
+  y2: number /* int */;
+}
+export interface TerminalCursorPayload {
+  x: number /* int */;
+  y: number /* int */;
+}
+export interface TerminalErrorPayload {
+  code: string;
+  message: string;
+  resync: boolean;
+}
+export interface TerminalViewersPayload {
+  viewers: TerminalViewer[];
+}
+export interface TerminalViewer {
+  viewer_id: string;
+  name: string;
+  role: string;
+  joined_at: string;
+}
+export interface TerminalInputPayload {
+  viewer_id: string;
+  name: string;
+  input: string;
+}
+export interface TerminalOutputPayloads {
+  "terminal.snapshot": TerminalSnapshot;
+  "terminal.diff": TerminalDiffPayload;
+  "terminal.cursor": TerminalCursorPayload;
+  "terminal.error": TerminalErrorPayload;
+  "terminal.viewers": TerminalViewersPayload;
+  "terminal.input": TerminalInputPayload;
+  "terminal.bell": undefined;
+}
+export type TerminalOutputEventType = keyof TerminalOutputPayloads;
+export type TerminalOutputData = Exclude<TerminalOutputPayloads[TerminalOutputEventType], undefined>;
+/**
+ * A TerminalOutputEvent whose data is narrowed by its type.
+ */
+export type TypedTerminalOutputEvent = {
+  [K in TerminalOutputEventType]: Omit<TerminalOutputEvent, "type" | "data"> & { type: K; data?: TerminalOutputPayloads[K] };
+}[TerminalOutputEventType];
+
 //////////
 // source: types.go
 
@@ -15,6 +151,8 @@ export const ServerMessageTypePong: ServerMessageType = "pong";
 export interface ClientEnvelope {
   type: ClientMessageType;
   topics?: string[];
+  event_types?: string[];
+  profile?: string;
 }
 export interface ServerEnvelope {
   type: ServerMessageType;
@@ -38,6 +176,10 @@ export interface SessionState {
   created_at: string;
   updated_at: string;
   current_task?: string;
+  pinned?: boolean;
+  recovery_policy?: string;
+  last_read_position?: number /* int */;
+  unread_count?: number /* int */;
 }
 export interface SessionStateEvent {
   event_id: number /* int64 */;
@@ -45,6 +187,12 @@ export interface SessionStateEvent {
   session_id: string;
   derived_state: string;
   reason?: string;
+  pinned?: boolean;
+  unread_counts?: { [key: string]: number /* int */};
+  /**
+   * Notice is the reason as a code, when it has one.
+   */
+  notice?: Notice;
 }
 export interface SessionActivitySnapshot {
   session_id: string;
@@ -66,13 +214,15 @@ export interface SessionMessage {
   kind: string;
   contents: string;
   timestamp: string;
+  notice?: Notice;
 }
 export interface SessionActivityEvent {
   event_id: number /* int64 */;
   timestamp: string;
   session_id: string;
-  type: string;
-  data: any;
+  type: SessionActivityEventType;
+  version: number /* int */;
+  data: SessionActivityData;
 }
 export interface TerminalsStateSnapshot {
   terminals: TerminalState[];
@@ -106,6 +256,106 @@ export interface TerminalOutputEvent {
   session_id: string;
   seq: number /* int64 */;
   timestamp: string;
-  type: string;
-  data?: any;
+  type: TerminalOutputEventType;
+  version: number /* int */;
+  data?: TerminalOutputData;
+}
+export type NotificationKind = string;
+export const NotificationKindApproval: NotificationKind = "approval";
+export const NotificationKindFailure: NotificationKind = "failure";
+/**
+ * NotificationKindQuestion is an agent's ask_human question.
+ */
+export const NotificationKindQuestion: NotificationKind = "question";
+export interface Notification {
+  dedupe_key: string;
+  kind: NotificationKind;
+  session_id: string;
+  title: string;
+  body?: string;
+  timestamp: string;
+}
+export interface NotificationsSnapshot {
+  notifications: Notification[];
+}
+/**
+ * RecoverySnapshot holds the current or most recent startup recovery report;
+ * events on the topic carry the report itself.
+ */
+/**
+ * Notice is a status reason or system message as a stable code with string
+ * parameters. The matching reason or contents field holds its English text,
+ * so clients that do not know a code can show that instead.
+ */
+export interface Notice {
+  code: string;
+  params?: { [key: string]: string};
+}
+export interface RecoverySnapshot {
+  report?: RecoveryReport;
+}
+export interface RecoveryReport {
+  started_at: string;
+  finished_at?: string;
+  status: string;
+  sessions_total: number /* int */;
+  sessions_scanned: number /* int */;
+  attempts_closed: number /* int */;
+  runs_scheduled: number /* int */;
+  sessions: RecoveredSession[];
+  error?: string;
+  /**
+   * PreviousShutdown is how the server last shut down, absent after a
+   * crash.
+   */
+  previous_shutdown?: ShutdownReport;
+}
+export interface RecoveredSession {
+  session_id: string;
+  attempts_closed: number /* int */;
+  policy: string;
+  scheduled?: boolean;
+  note?: string;
+}
+/**
+ * ShutdownReport records the phases of an executor shutdown (drain,
+ * suspend, stop, kill) and what happened to each session that had a run.
+ */
+export interface ShutdownReport {
+  started_at: string;
+  finished_at: string;
+  clean: boolean;
+  phases: ShutdownPhase[];
+  sessions: ShutdownSession[];
+}
+export interface ShutdownPhase {
+  name: string;
+  duration_ms: number /* int64 */;
+  sessions: number /* int */;
+  timed_out?: boolean;
+}
+/**
+ * ShutdownSession is one session's shutdown outcome: suspended, stopped,
+ * killed or failed.
+ */
+export interface ShutdownSession {
+  session_id: string;
+  outcome: string;
+  phase: string;
+  error?: string;
+}
+/**
+ * SessionsSyncSnapshot holds the current session list revision; events on
+ * the topic carry the sessions changed since the previous event, shaped like
+ * GET /api/sessions/sync.
+ */
+export interface SessionsSyncSnapshot {
+  revision: number /* int64 */;
+}
+export interface SessionsSyncEvent {
+  revision: number /* int64 */;
+  since?: number /* int64 */;
+  reset?: boolean;
+  sessions: SessionState[];
+  deleted?: string[];
 }
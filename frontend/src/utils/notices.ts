@@ -0,0 +1,28 @@
+import type { Notice } from "../types/generated/realtime"
+
+/**
+ * A notice template: a string with {param} placeholders, or a function of
+ * the notice's params for texts with optional parts.
+ */
+export type NoticeTemplate = string | ((params: Record<string, string>) => string)
+
+const templates = new Map<string, NoticeTemplate>()
+
+/**
+ * Registers templates by notice code, replacing earlier ones. Codes without
+ * a template render as the server's English text.
+ */
+export function registerNoticeTemplates(next: Record<string, NoticeTemplate>) {
+  for (const [code, template] of Object.entries(next)) {
+    templates.set(code, template)
+  }
+}
+
+/** Renders a notice with its registered template, or returns fallback. */
+export function formatNotice(notice: Notice | undefined, fallback: string): string {
+  const template = notice && templates.get(notice.code)
+  if (!notice || !template) return fallback
+  const params = notice.params ?? {}
+  if (typeof template === "function") return template(params)
+  return template.replace(/\{(\w+)\}/g, (_, key: string) => params[key] ?? "")
+}